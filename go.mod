@@ -7,9 +7,9 @@ go 1.16
 // Also, use our fork with fixes for unimplemented methods (required for Go 1.16).
 replace github.com/denisenkom/go-mssqldb => github.com/grafana/go-mssqldb v0.0.0-20210326084033-d0ce3c521036
 
-// Override k8s.io/client-go outdated dependency, which is an indirect dependency of grafana/loki.
-// It's also present on grafana/loki's go.mod so we'll need till it gets updated.
-replace k8s.io/client-go => k8s.io/client-go v0.18.8
+// Override k8s.io/client-go outdated dependency, which is an indirect dependency of grafana/loki, with the version
+// of the Kubernetes provisioning. It's also present on grafana/loki's go.mod so we'll need till it gets updated.
+replace k8s.io/client-go => k8s.io/client-go v0.21.0
 
 require (
 	cloud.google.com/go/storage v1.14.0
@@ -19,6 +19,7 @@ require (
 	github.com/BurntSushi/toml v0.3.1
 	github.com/Masterminds/semver v1.5.0
 	github.com/VividCortex/mysqlerr v0.0.0-20170204212430-6c6b55f8796f
+	github.com/apache/arrow/go/arrow v0.0.0-20210223225224-5bea62493d91
 	github.com/aws/aws-sdk-go v1.38.34
 	github.com/beevik/etree v1.1.0
 	github.com/benbjohnson/clock v1.1.0
@@ -45,14 +46,16 @@ require (
 	github.com/go-stack/stack v1.8.0
 	github.com/gobwas/glob v0.2.3
 	github.com/golang/mock v1.5.0
-	github.com/google/go-cmp v0.5.5
+	github.com/google/go-cmp v0.5.6
 	github.com/google/uuid v1.2.0
 	github.com/gorilla/websocket v1.4.2
 	github.com/gosimple/slug v1.9.0
+	github.com/gosnmp/gosnmp v1.30.0
 	github.com/grafana/grafana-aws-sdk v0.4.0
 	github.com/grafana/grafana-live-sdk v0.0.6
 	github.com/grafana/grafana-plugin-sdk-go v0.101.0
 	github.com/grafana/loki v1.6.2-0.20210520072447-15d417efe103
+	github.com/graph-gophers/graphql-go v1.1.0
 	github.com/grpc-ecosystem/go-grpc-middleware v1.3.0
 	github.com/hashicorp/go-hclog v0.16.0
 	github.com/hashicorp/go-plugin v1.4.0
@@ -68,6 +71,7 @@ require (
 	github.com/magefile/mage v1.11.0
 	github.com/mattn/go-isatty v0.0.12
 	github.com/mattn/go-sqlite3 v1.14.7
+	github.com/microcosm-cc/bluemonday v1.0.2
 	github.com/opentracing/opentracing-go v1.2.0
 	github.com/patrickmn/go-cache v2.1.0+incompatible
 	github.com/pkg/browser v0.0.0-20210115035449-ce105d075bb4 // indirect
@@ -89,6 +93,8 @@ require (
 	github.com/unknwon/com v1.0.1
 	github.com/urfave/cli/v2 v2.3.0
 	github.com/weaveworks/common v0.0.0-20210419092856-009d1eebd624
+	github.com/xitongsys/parquet-go v1.6.2
+	github.com/xitongsys/parquet-go-source v0.0.0-20211010230925-397910c5e371
 	github.com/xorcare/pointer v1.1.0
 	github.com/yudai/gojsondiff v1.0.0
 	go.opentelemetry.io/collector v0.27.0
@@ -113,6 +119,9 @@ require (
 	gopkg.in/square/go-jose.v2 v2.5.1
 	gopkg.in/yaml.v2 v2.4.0
 	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b
+	k8s.io/api v0.21.0
+	k8s.io/apimachinery v0.21.0
+	k8s.io/client-go v12.0.0+incompatible
 	xorm.io/core v0.7.3
 	xorm.io/xorm v0.8.2
 )
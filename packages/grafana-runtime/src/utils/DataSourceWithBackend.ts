@@ -128,6 +128,12 @@ class DataSourceWithBackend<
       body.to = range.to.valueOf().toString();
     }
 
+    // identifies the panel of the last successful results returned by the server when the queries fail
+    if (request.panelId) {
+      body.dashboardId = request.dashboardId;
+      body.panelId = request.panelId;
+    }
+
     return getBackendSrv()
       .fetch<BackendDataSourceResponse>({
         url: '/api/ds/query',
@@ -178,6 +178,19 @@ export const DataSourceHttpSettings: React.FC<HttpSettingsProps> = (props) => {
                   }}
                 />
               </div>
+              <div className="gf-form-inline">
+                <Switch
+                  label="Query audit headers"
+                  labelClass="width-13"
+                  checked={dataSourceConfig.jsonData.queryAuditHeaders || false}
+                  onChange={(event) => {
+                    onSettingsChange({
+                      jsonData: { ...dataSourceConfig.jsonData, queryAuditHeaders: event!.currentTarget.checked },
+                    });
+                  }}
+                  tooltip="Sends the dashboard, panel, user and org of the queries to the data source in X-Grafana headers."
+                />
+              </div>
             </div>
           )}
         </div>
@@ -4,6 +4,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
 )
 
 func (hs *HTTPServer) AdminGetSettings(_ *models.ReqContext) response.Response {
@@ -19,3 +20,23 @@ func AdminGetStats(c *models.ReqContext) response.Response {
 
 	return response.JSON(200, statsQuery.Result)
 }
+
+// AdminGetSlowQueries returns the slow queries of the SQL data sources, the latest first, optionally of a data
+// source or an org.
+func AdminGetSlowQueries(c *models.ReqContext) response.Response {
+	datasourceID := c.QueryInt64("datasourceId")
+	orgID := c.QueryInt64("orgId")
+
+	result := make([]sqleng.SlowQuery, 0)
+	for _, query := range sqleng.GetSlowQueries() {
+		if datasourceID != 0 && query.DatasourceID != datasourceID {
+			continue
+		}
+		if orgID != 0 && query.OrgID != orgID {
+			continue
+		}
+		result = append(result, query)
+	}
+
+	return response.JSON(200, result)
+}
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// AdminGetDataSourceRecording returns the mode of the recording of the data source responses of the server, and the
+// number of recorded responses.
+func (hs *HTTPServer) AdminGetDataSourceRecording(c *models.ReqContext) response.Response {
+	status, err := hs.DataService.RecordingStatus()
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get the data source recording status", err)
+	}
+	return response.JSON(http.StatusOK, status)
+}
+
+// AdminUpdateDataSourceRecording changes the mode of the recording of the data source responses of the server, until
+// it restarts.
+func (hs *HTTPServer) AdminUpdateDataSourceRecording(c *models.ReqContext, cmd dtos.UpdateDataSourceRecordingCommand) response.Response {
+	if err := hs.DataService.SetRecordingMode(cmd.Mode); err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	return hs.AdminGetDataSourceRecording(c)
+}
+
+// AdminDeleteDataSourceRecordings deletes the recorded data source responses of the server.
+func (hs *HTTPServer) AdminDeleteDataSourceRecordings(c *models.ReqContext) response.Response {
+	deleted, err := hs.DataService.DeleteRecordings()
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to delete the data source recordings", err)
+	}
+	return response.JSON(http.StatusOK, util.DynMap{"message": "Data source recordings deleted", "deleted": deleted})
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb"
+)
+
+func TestAdminDataSourceRecording(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.DataSourceRecording = setting.DataSourceRecordingSettings{Mode: setting.DataSourceRecordingOff, Path: t.TempDir()}
+	dataService := tsdb.NewService()
+	dataService.Cfg = cfg
+	require.NoError(t, dataService.Init())
+	hs := &HTTPServer{Cfg: cfg, DataService: &dataService}
+
+	updateScenario := func(desc string, mode string, fn scenarioFunc) {
+		loggedInUserScenarioWithRole(t, desc, "GET", "/api/admin/datasource-recording", "/api/admin/datasource-recording", models.ROLE_ADMIN, func(sc *scenarioContext) {
+			sc.handlerFunc = func(c *models.ReqContext) response.Response {
+				return hs.AdminUpdateDataSourceRecording(c, dtos.UpdateDataSourceRecordingCommand{Mode: mode})
+			}
+			fn(sc)
+		})
+	}
+
+	updateScenario("When switching to the replay mode", setting.DataSourceRecordingReplay, func(sc *scenarioContext) {
+		sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+		require.Equal(t, 200, sc.resp.Code)
+
+		var status tsdb.RecordingStatus
+		require.NoError(t, json.Unmarshal(sc.resp.Body.Bytes(), &status))
+		assert.Equal(t, setting.DataSourceRecordingReplay, status.Mode)
+		assert.Equal(t, 0, status.Recordings)
+	})
+
+	updateScenario("When switching to an invalid mode", "playback", func(sc *scenarioContext) {
+		sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+		assert.Equal(t, 400, sc.resp.Code)
+	})
+}
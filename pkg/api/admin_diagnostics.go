@@ -0,0 +1,162 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/filestorage"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	cpuProfile = "cpu"
+	// defaultCPUProfileDuration is the duration of the CPU profiles without
+	// the seconds parameter, the same as the pprof HTTP handlers.
+	defaultCPUProfileDuration = 30 * time.Second
+)
+
+// diagnosticsProfiles are the profiles of the diagnostics API, besides the
+// CPU profile, by their names in the pprof package.
+var diagnosticsProfiles = map[string]bool{"heap": true, "allocs": true, "goroutine": true}
+
+// RuntimeStats is a snapshot of the runtime of the Grafana server.
+type RuntimeStats struct {
+	Time         time.Time `json:"time"`
+	GoVersion    string    `json:"goVersion"`
+	NumCPU       int       `json:"numCpu"`
+	GOMAXPROCS   int       `json:"gomaxprocs"`
+	NumGoroutine int       `json:"numGoroutine"`
+	// OpenFiles is the number of open file descriptors, only on Linux
+	OpenFiles *int           `json:"openFiles,omitempty"`
+	Memory    MemoryStats    `json:"memory"`
+	GC        RuntimeGCStats `json:"gc"`
+}
+
+type MemoryStats struct {
+	Alloc       uint64 `json:"alloc"`
+	TotalAlloc  uint64 `json:"totalAlloc"`
+	Sys         uint64 `json:"sys"`
+	HeapAlloc   uint64 `json:"heapAlloc"`
+	HeapInuse   uint64 `json:"heapInuse"`
+	HeapObjects uint64 `json:"heapObjects"`
+	StackInuse  uint64 `json:"stackInuse"`
+}
+
+type RuntimeGCStats struct {
+	NumGC         uint32     `json:"numGc"`
+	PauseTotal    string     `json:"pauseTotal"`
+	LastPause     string     `json:"lastPause"`
+	LastGC        *time.Time `json:"lastGc,omitempty"`
+	NextGC        uint64     `json:"nextGc"`
+	GCCPUFraction float64    `json:"gcCpuFraction"`
+}
+
+// AdminGetRuntimeStats returns a snapshot of the runtime stats of the server.
+func AdminGetRuntimeStats(c *models.ReqContext) response.Response {
+	return response.JSON(200, getRuntimeStats())
+}
+
+func getRuntimeStats() RuntimeStats {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	stats := RuntimeStats{
+		Time:         time.Now(),
+		GoVersion:    runtime.Version(),
+		NumCPU:       runtime.NumCPU(),
+		GOMAXPROCS:   runtime.GOMAXPROCS(0),
+		NumGoroutine: runtime.NumGoroutine(),
+		Memory: MemoryStats{
+			Alloc:       mem.Alloc,
+			TotalAlloc:  mem.TotalAlloc,
+			Sys:         mem.Sys,
+			HeapAlloc:   mem.HeapAlloc,
+			HeapInuse:   mem.HeapInuse,
+			HeapObjects: mem.HeapObjects,
+			StackInuse:  mem.StackInuse,
+		},
+		GC: RuntimeGCStats{
+			NumGC:         mem.NumGC,
+			PauseTotal:    time.Duration(mem.PauseTotalNs).String(),
+			NextGC:        mem.NextGC,
+			GCCPUFraction: mem.GCCPUFraction,
+		},
+	}
+	if mem.NumGC > 0 {
+		lastGC := time.Unix(0, int64(mem.LastGC))
+		stats.GC.LastGC = &lastGC
+		stats.GC.LastPause = time.Duration(mem.PauseNs[(mem.NumGC+255)%256]).String()
+	}
+	// the directory of the file descriptors of the process is only on Linux
+	if fds, err := os.ReadDir("/proc/self/fd"); err == nil {
+		// the descriptor of the directory itself isn't counted
+		openFiles := len(fds) - 1
+		stats.OpenFiles = &openFiles
+	}
+
+	return stats
+}
+
+// AdminGetProfile captures a profile of the server, cpu, heap, allocs or goroutine, and returns it in the pprof
+// format, or stores it with the file storage when upload is true. The CPU profiles last the seconds parameter, and
+// the other profiles are returned as text with the debug parameter.
+func (hs *HTTPServer) AdminGetProfile(c *models.ReqContext) response.Response {
+	name := c.Params(":profile")
+	debug := c.QueryInt("debug")
+
+	var buf bytes.Buffer
+	switch {
+	case name == cpuProfile:
+		duration := defaultCPUProfileDuration
+		if seconds := c.QueryInt64("seconds"); seconds > 0 {
+			duration = time.Duration(seconds) * time.Second
+		}
+		if duration > hs.Cfg.Diagnostics.MaxCPUProfileDuration {
+			return response.Error(400, fmt.Sprintf("The CPU profile can't last more than %s", hs.Cfg.Diagnostics.MaxCPUProfileDuration), nil)
+		}
+		if err := pprof.StartCPUProfile(&buf); err != nil {
+			return response.Error(409, "A CPU profile is already being captured", err)
+		}
+		select {
+		case <-time.After(duration):
+		case <-c.Req.Context().Done():
+		}
+		pprof.StopCPUProfile()
+		debug = 0
+	case diagnosticsProfiles[name]:
+		if err := pprof.Lookup(name).WriteTo(&buf, debug); err != nil {
+			return response.Error(500, "Failed to capture the profile", err)
+		}
+	default:
+		return response.Error(404, "Profile not found", nil)
+	}
+
+	contentType, extension := "application/octet-stream", "pprof"
+	if debug > 0 {
+		contentType, extension = "text/plain; charset=utf-8", "txt"
+	}
+	fileName := fmt.Sprintf("%s-%s.%s", name, time.Now().UTC().Format("20060102T150405Z"), extension)
+
+	if c.QueryBool("upload") {
+		filePath := "diagnostics/" + fileName
+		err := hs.FileStorage.UploadInstanceFile(c.Req.Context(), filePath, contentType, buf.Bytes())
+		if errors.Is(err, filestorage.ErrFileStorageUnavailable) {
+			return response.Error(400, "The file storage isn't configured", err)
+		}
+		if err != nil {
+			return response.Error(500, "Failed to upload the profile", err)
+		}
+		return response.JSON(200, util.DynMap{"message": "Profile uploaded", "path": filePath})
+	}
+
+	return response.Respond(200, buf.Bytes()).
+		SetHeader("Content-Type", contentType).
+		SetHeader("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+}
@@ -0,0 +1,265 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/crashreport"
+	"github.com/grafana/grafana/pkg/infra/filestorage"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// bundleSensitiveKeys are the patterns of the keys of the settings redacted in the diagnostics bundles, besides the
+// ones of setting.RedactedValue
+var bundleSensitiveKeys = []string{"TOKEN", "CONNSTR", "CONNECTION_STRING", "DSN"}
+
+// bundleSensitiveKeySuffixes are the suffixes of the keys of the credentials redacted in the diagnostics bundles,
+// matched as suffixes so that the settings about them, like api_key_max_seconds_to_live, are kept.
+var bundleSensitiveKeySuffixes = []string{"API_KEY", "ACCOUNT_KEY", "ACCESS_KEY"}
+
+// bundleLimitSections are the sections of the settings holding limits only, like the quotas of the API keys, whose
+// keys are never redacted by their suffix.
+var bundleLimitSections = map[string]bool{"quota": true}
+
+// bundleInfo is the info.json file of the diagnostics bundles.
+type bundleInfo struct {
+	Time         time.Time `json:"time"`
+	Version      string    `json:"version"`
+	Commit       string    `json:"commit"`
+	Branch       string    `json:"branch"`
+	IsEnterprise bool      `json:"isEnterprise"`
+	GoVersion    string    `json:"goVersion"`
+	OS           string    `json:"os"`
+	Arch         string    `json:"arch"`
+	Database     string    `json:"database,omitempty"`
+	// Errors are the parts of the bundle that couldn't be collected
+	Errors []string `json:"errors"`
+}
+
+type bundlePlugin struct {
+	Id        string                        `json:"id"`
+	Type      string                        `json:"type"`
+	Name      string                        `json:"name"`
+	Version   string                        `json:"version"`
+	Signature plugins.PluginSignatureStatus `json:"signature"`
+	Backend   bool                          `json:"backend"`
+	Core      bool                          `json:"core"`
+}
+
+type bundlePlugins struct {
+	Plugins []bundlePlugin        `json:"plugins"`
+	Errors  []plugins.PluginError `json:"errors"`
+}
+
+// AdminGetDiagnosticsBundle returns a zip archive with the diagnostics of the server, to be attached to the support
+// tickets: the latest lines of the log file, the settings with the secrets redacted, the state of the database
+// migrations, the plugins, the runtime stats, the goroutines and the latest panics recovered while serving requests.
+// The archive is stored with the file storage instead when upload is true. The parts that fail to be collected are
+// listed in info.json instead of failing the whole bundle.
+func (hs *HTTPServer) AdminGetDiagnosticsBundle(c *models.ReqContext) response.Response {
+	var buf bytes.Buffer
+	bundle := zip.NewWriter(&buf)
+	info := bundleInfo{
+		Time:         time.Now().UTC(),
+		Version:      hs.Cfg.BuildVersion,
+		Commit:       hs.Cfg.BuildCommit,
+		Branch:       hs.Cfg.BuildBranch,
+		IsEnterprise: hs.Cfg.IsEnterprise,
+		GoVersion:    runtime.Version(),
+		OS:           runtime.GOOS,
+		Arch:         runtime.GOARCH,
+		Errors:       []string{},
+	}
+	if hs.SQLStore != nil && hs.SQLStore.Dialect != nil {
+		info.Database = hs.SQLStore.Dialect.DriverName()
+	}
+
+	addFile := func(name string, collect func(w io.Writer) error) {
+		w, err := bundle.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: info.Time})
+		if err == nil {
+			err = collect(w)
+		}
+		if err != nil {
+			hs.log.Warn("Failed to collect a part of the diagnostics bundle", "file", name, "error", err)
+			info.Errors = append(info.Errors, fmt.Sprintf("%s: %s", name, err))
+		}
+	}
+	addJSON := func(name string, collect func() (interface{}, error)) {
+		addFile(name, func(w io.Writer) error {
+			v, err := collect()
+			if err != nil {
+				return err
+			}
+			encoder := json.NewEncoder(w)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(v)
+		})
+	}
+
+	addJSON("config.json", func() (interface{}, error) {
+		return bundleSettings(hs.SettingsProvider.Current()), nil
+	})
+	addJSON("migrations.json", func() (interface{}, error) {
+		return hs.SQLStore.GetMigrationState()
+	})
+	addJSON("plugins.json", func() (interface{}, error) {
+		return hs.bundlePlugins(), nil
+	})
+	addJSON("runtime.json", func() (interface{}, error) {
+		return getRuntimeStats(), nil
+	})
+	addJSON("panics.json", func() (interface{}, error) {
+		return crashreport.Recent(), nil
+	})
+	addFile("goroutines.txt", func(w io.Writer) error {
+		return pprof.Lookup("goroutine").WriteTo(w, 1)
+	})
+	if lines := hs.Cfg.Diagnostics.BundleLogLines; lines > 0 {
+		logFile := hs.Cfg.Raw.Section("log.file").Key("file_name").MustString(filepath.Join(hs.Cfg.LogsPath, "grafana.log"))
+		addFile(filepath.Base(logFile), func(w io.Writer) error {
+			return tailFile(w, logFile, lines)
+		})
+	}
+	addJSON("info.json", func() (interface{}, error) {
+		return info, nil
+	})
+
+	if err := bundle.Close(); err != nil {
+		return response.Error(500, "Failed to create the diagnostics bundle", err)
+	}
+
+	contentType := "application/zip"
+	fileName := fmt.Sprintf("diagnostics-%s.zip", info.Time.Format("20060102T150405Z"))
+
+	if c.QueryBool("upload") {
+		filePath := "diagnostics/" + fileName
+		err := hs.FileStorage.UploadInstanceFile(c.Req.Context(), filePath, contentType, buf.Bytes())
+		if errors.Is(err, filestorage.ErrFileStorageUnavailable) {
+			return response.Error(400, "The file storage isn't configured", err)
+		}
+		if err != nil {
+			return response.Error(500, "Failed to upload the diagnostics bundle", err)
+		}
+		return response.JSON(200, util.DynMap{"message": "Diagnostics bundle uploaded", "path": filePath})
+	}
+
+	return response.Respond(200, buf.Bytes()).
+		SetHeader("Content-Type", contentType).
+		SetHeader("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+}
+
+func (hs *HTTPServer) bundlePlugins() bundlePlugins {
+	result := bundlePlugins{Plugins: []bundlePlugin{}, Errors: hs.PluginManager.ScanningErrors()}
+	for _, plugin := range hs.PluginManager.Plugins() {
+		result.Plugins = append(result.Plugins, bundlePlugin{
+			Id:        plugin.Id,
+			Type:      plugin.Type,
+			Name:      plugin.Name,
+			Version:   plugin.Info.Version,
+			Signature: plugin.Signature,
+			Backend:   plugin.Backend,
+			Core:      plugin.IsCorePlugin,
+		})
+	}
+	return result
+}
+
+// bundleSettings returns the settings with the tokens, the API and storage access keys, the connection strings and
+// the credentials of the URLs redacted, on top of the secrets already redacted by the settings provider.
+func bundleSettings(settings setting.SettingsBag) setting.SettingsBag {
+	for section, keys := range settings {
+		for key, value := range keys {
+			keys[key] = bundleRedactedValue(section, key, value)
+		}
+	}
+	return settings
+}
+
+func bundleRedactedValue(section, key, value string) string {
+	if value == "" || value == setting.RedactedPassword {
+		return value
+	}
+	uppercased := strings.ToUpper(key)
+	if !strings.HasSuffix(uppercased, "_URL") {
+		for _, pattern := range bundleSensitiveKeys {
+			if strings.Contains(uppercased, pattern) {
+				return setting.RedactedPassword
+			}
+		}
+	}
+	if !bundleLimitSections[section] {
+		for _, suffix := range bundleSensitiveKeySuffixes {
+			if uppercased == suffix || strings.HasSuffix(uppercased, "_"+suffix) {
+				return setting.RedactedPassword
+			}
+		}
+	}
+	if u, err := url.Parse(value); err == nil && u.User != nil {
+		return u.Redacted()
+	}
+	return value
+}
+
+// tailFile writes the last lines of a file.
+func tailFile(w io.Writer, path string, lines int) error {
+	// It's safe to ignore gosec warning G304 since the file path comes from the settings
+	// nolint:gosec
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			plog.Warn("Failed to close file", "path", path, "err", err)
+		}
+	}()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	// read the file backwards by chunks until it has enough lines, the last line ending with a new line or not
+	const chunkSize = 64 * 1024
+	var data []byte
+	offset := stat.Size()
+	for offset > 0 && bytes.Count(bytes.TrimSuffix(data, []byte("\n")), []byte("\n")) < lines {
+		size := int64(chunkSize)
+		if offset < size {
+			size = offset
+		}
+		offset -= size
+		chunk := make([]byte, size)
+		if _, err := f.ReadAt(chunk, offset); err != nil {
+			return err
+		}
+		data = append(chunk, data...)
+	}
+
+	text := bytes.TrimSuffix(data, []byte("\n"))
+	if bytes.Count(text, []byte("\n")) >= lines {
+		// drop the lines before the last ones, the first one being partial
+		start := len(text)
+		for i := 0; i < lines; i++ {
+			start = bytes.LastIndexByte(text[:start], '\n')
+		}
+		data = data[start+1:]
+	}
+	_, err = w.Write(data)
+	return err
+}
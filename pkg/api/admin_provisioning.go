@@ -16,6 +16,12 @@ func (hs *HTTPServer) AdminProvisioningReloadDashboards(c *models.ReqContext) re
 	return response.Success("Dashboards config reloaded")
 }
 
+// AdminGetProvisioningDashboardsGitStatus returns the sync status of the repositories of the git dashboard
+// provisioners.
+func (hs *HTTPServer) AdminGetProvisioningDashboardsGitStatus(c *models.ReqContext) response.Response {
+	return response.JSON(200, hs.ProvisioningService.GetDashboardGitSyncStatus())
+}
+
 func (hs *HTTPServer) AdminProvisioningReloadDatasources(c *models.ReqContext) response.Response {
 	err := hs.ProvisioningService.ProvisionDatasources()
 	if err != nil {
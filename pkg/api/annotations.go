@@ -29,6 +29,10 @@ func GetAnnotations(c *models.ReqContext) response.Response {
 
 	repo := annotations.GetRepository()
 
+	if c.QueryBool("aggregate") {
+		return getAggregatedAnnotations(c, repo, query)
+	}
+
 	items, err := repo.Find(query)
 	if err != nil {
 		return response.Error(500, "Failed to get annotations", err)
@@ -43,6 +47,56 @@ func GetAnnotations(c *models.ReqContext) response.Response {
 	return response.JSON(200, items)
 }
 
+// GetAnnotationTags returns the tags of the annotations with the number of annotations they're on, matching the
+// tag query parameter with typos tolerated.
+// GET /api/annotations/tags
+func GetAnnotationTags(c *models.ReqContext) response.Response {
+	query := &annotations.TagsQuery{
+		OrgID: c.OrgId,
+		Tag:   c.Query("tag"),
+		Limit: c.QueryInt64("limit"),
+	}
+
+	repo := annotations.GetRepository()
+	result, err := repo.FindTags(query)
+	if err != nil {
+		return response.Error(500, "Failed to find annotation tags", err)
+	}
+
+	return response.JSON(200, annotations.GetAnnotationTagsResponse{Result: result})
+}
+
+const (
+	// defaultAnnotationBuckets is the number of buckets of the range when the interval of the aggregation isn't set
+	defaultAnnotationBuckets = 100
+	// maxAnnotationBuckets bounds the number of buckets of the range, the shorter intervals are widened
+	maxAnnotationBuckets = 1000
+)
+
+// getAggregatedAnnotations returns the number of annotations by bucket of the time range instead of the annotations,
+// for the panels showing long time ranges.
+func getAggregatedAnnotations(c *models.ReqContext, repo annotations.Repository, query *annotations.ItemQuery) response.Response {
+	if query.From <= 0 || query.To <= query.From {
+		return response.Error(400, "The aggregation of annotations requires a time range", nil)
+	}
+
+	rangeMs := query.To - query.From
+	interval := c.QueryInt64("intervalMs")
+	if interval <= 0 {
+		interval = rangeMs / defaultAnnotationBuckets
+	}
+	if minInterval := (rangeMs + maxAnnotationBuckets - 1) / maxAnnotationBuckets; interval < minInterval {
+		interval = minInterval
+	}
+
+	items, err := repo.FindAggregated(&annotations.AggregateQuery{ItemQuery: *query, Interval: interval})
+	if err != nil {
+		return response.Error(500, "Failed to aggregate annotations", err)
+	}
+
+	return response.JSON(200, items)
+}
+
 type CreateAnnotationError struct {
 	message string
 }
@@ -51,13 +105,14 @@ func (e *CreateAnnotationError) Error() string {
 	return e.message
 }
 
-func PostAnnotation(c *models.ReqContext, cmd dtos.PostAnnotationsCmd) response.Response {
+func (hs *HTTPServer) PostAnnotation(c *models.ReqContext, cmd dtos.PostAnnotationsCmd) response.Response {
 	if canSave, err := canSaveByDashboardID(c, cmd.DashboardId); err != nil || !canSave {
 		return dashboardGuardianResponse(err)
 	}
 
 	repo := annotations.GetRepository()
 
+	cmd.Text = hs.HTMLSanitizer.SanitizeHTML(c.OrgId, cmd.Text)
 	if cmd.Text == "" {
 		err := &CreateAnnotationError{"text field should not be empty"}
 		return response.Error(400, "Failed to save annotation", err)
@@ -98,7 +153,7 @@ func formatGraphiteAnnotation(what string, data string) string {
 	return text
 }
 
-func PostGraphiteAnnotation(c *models.ReqContext, cmd dtos.PostGraphiteAnnotationsCmd) response.Response {
+func (hs *HTTPServer) PostGraphiteAnnotation(c *models.ReqContext, cmd dtos.PostGraphiteAnnotationsCmd) response.Response {
 	repo := annotations.GetRepository()
 
 	if cmd.What == "" {
@@ -106,7 +161,7 @@ func PostGraphiteAnnotation(c *models.ReqContext, cmd dtos.PostGraphiteAnnotatio
 		return response.Error(400, "Failed to save Graphite annotation", err)
 	}
 
-	text := formatGraphiteAnnotation(cmd.What, cmd.Data)
+	text := hs.HTMLSanitizer.SanitizeHTML(c.OrgId, formatGraphiteAnnotation(cmd.What, cmd.Data))
 
 	// Support tags in prior to Graphite 0.10.0 format (string of tags separated by space)
 	var tagsArray []string
@@ -149,7 +204,7 @@ func PostGraphiteAnnotation(c *models.ReqContext, cmd dtos.PostGraphiteAnnotatio
 	})
 }
 
-func UpdateAnnotation(c *models.ReqContext, cmd dtos.UpdateAnnotationsCmd) response.Response {
+func (hs *HTTPServer) UpdateAnnotation(c *models.ReqContext, cmd dtos.UpdateAnnotationsCmd) response.Response {
 	annotationID := c.ParamsInt64(":annotationId")
 
 	repo := annotations.GetRepository()
@@ -164,7 +219,7 @@ func UpdateAnnotation(c *models.ReqContext, cmd dtos.UpdateAnnotationsCmd) respo
 		Id:       annotationID,
 		Epoch:    cmd.Time,
 		EpochEnd: cmd.TimeEnd,
-		Text:     cmd.Text,
+		Text:     hs.HTMLSanitizer.SanitizeHTML(c.OrgId, cmd.Text),
 		Tags:     cmd.Tags,
 	}
 
@@ -175,7 +230,7 @@ func UpdateAnnotation(c *models.ReqContext, cmd dtos.UpdateAnnotationsCmd) respo
 	return response.Success("Annotation updated")
 }
 
-func PatchAnnotation(c *models.ReqContext, cmd dtos.PatchAnnotationsCmd) response.Response {
+func (hs *HTTPServer) PatchAnnotation(c *models.ReqContext, cmd dtos.PatchAnnotationsCmd) response.Response {
 	annotationID := c.ParamsInt64(":annotationId")
 
 	repo := annotations.GetRepository()
@@ -205,7 +260,7 @@ func PatchAnnotation(c *models.ReqContext, cmd dtos.PatchAnnotationsCmd) respons
 	}
 
 	if cmd.Text != "" && cmd.Text != existing.Text {
-		existing.Text = cmd.Text
+		existing.Text = hs.HTMLSanitizer.SanitizeHTML(c.OrgId, cmd.Text)
 	}
 
 	if cmd.Time > 0 && cmd.Time != existing.Epoch {
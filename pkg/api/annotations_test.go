@@ -1,6 +1,7 @@
 package api
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 
@@ -10,7 +11,10 @@ import (
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/sanitizer"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestAnnotationsAPIEndpoint(t *testing.T) {
@@ -242,7 +246,125 @@ func TestAnnotationsAPIEndpoint(t *testing.T) {
 	})
 }
 
+func TestAnnotationTagsAPIEndpoint(t *testing.T) {
+	loggedInUserScenarioWithRole(t, "When calling GET on", "GET", "/api/annotations/tags", "/api/annotations/tags",
+		models.ROLE_VIEWER, func(sc *scenarioContext) {
+			fakeAnnoRepo = &fakeAnnotationsRepo{}
+			annotations.SetRepository(fakeAnnoRepo)
+			sc.handlerFunc = GetAnnotationTags
+			sc.fakeReqWithParams("GET", sc.url, map[string]string{"tag": "outgae", "limit": "10"}).exec()
+			require.Equal(t, 200, sc.resp.Code)
+
+			require.NotNil(t, fakeAnnoRepo.tagsQuery)
+			assert.Equal(t, annotations.TagsQuery{OrgID: testOrgID, Tag: "outgae", Limit: 10}, *fakeAnnoRepo.tagsQuery)
+
+			var result annotations.GetAnnotationTagsResponse
+			require.NoError(t, json.Unmarshal(sc.resp.Body.Bytes(), &result))
+			require.Len(t, result.Result.Tags, 1)
+			assert.Equal(t, annotations.TagsDTO{Tag: "outage", Count: 3}, *result.Result.Tags[0])
+		})
+}
+
+func TestAnnotationsAPIEndpointSanitizesText(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.HTMLSanitizer = setting.HTMLSanitizerSettings{
+		Enabled: true,
+		Policy:  setting.HTMLSanitizerPolicySettings{Policy: setting.HTMLSanitizerPolicyUGC},
+	}
+	htmlSanitizer := &sanitizer.Service{Cfg: cfg}
+	require.NoError(t, htmlSanitizer.Init())
+	hs := &HTTPServer{Cfg: cfg, HTMLSanitizer: htmlSanitizer}
+
+	loggedInUserScenarioWithRole(t, "When calling POST on", "POST", "/api/annotations", "/api/annotations",
+		models.ROLE_EDITOR, func(sc *scenarioContext) {
+			fakeAnnoRepo = &fakeAnnotationsRepo{}
+			annotations.SetRepository(fakeAnnoRepo)
+			sc.handlerFunc = func(c *models.ReqContext) response.Response {
+				return hs.PostAnnotation(c, dtos.PostAnnotationsCmd{Time: 1000, Text: `<b>deploy</b><img src=x onerror="alert(1)">`})
+			}
+			sc.m.Post(sc.url, sc.defaultHandler)
+			sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+			require.Equal(t, 200, sc.resp.Code)
+
+			require.NotNil(t, fakeAnnoRepo.savedItem)
+			assert.Equal(t, `<b>deploy</b><img src="x">`, fakeAnnoRepo.savedItem.Text)
+		})
+
+	loggedInUserScenarioWithRole(t, "When calling POST with only a script on", "POST", "/api/annotations", "/api/annotations",
+		models.ROLE_EDITOR, func(sc *scenarioContext) {
+			fakeAnnoRepo = &fakeAnnotationsRepo{}
+			annotations.SetRepository(fakeAnnoRepo)
+			sc.handlerFunc = func(c *models.ReqContext) response.Response {
+				return hs.PostAnnotation(c, dtos.PostAnnotationsCmd{Time: 1000, Text: `<script>alert(1)</script>`})
+			}
+			sc.m.Post(sc.url, sc.defaultHandler)
+			sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+			require.Equal(t, 400, sc.resp.Code)
+			assert.Nil(t, fakeAnnoRepo.savedItem)
+		})
+}
+
+func TestAggregatedAnnotationsAPIEndpoint(t *testing.T) {
+	tests := []struct {
+		desc     string
+		params   map[string]string
+		status   int
+		interval int64
+	}{
+		{
+			desc:     "the default interval splits the range in 100 buckets",
+			params:   map[string]string{"aggregate": "true", "from": "1000", "to": "101000"},
+			status:   200,
+			interval: 1000,
+		},
+		{
+			desc:     "the interval is used",
+			params:   map[string]string{"aggregate": "true", "from": "1000", "to": "101000", "intervalMs": "5000"},
+			status:   200,
+			interval: 5000,
+		},
+		{
+			desc:     "the interval is widened to 1000 buckets",
+			params:   map[string]string{"aggregate": "true", "from": "1000", "to": "101000", "intervalMs": "10"},
+			status:   200,
+			interval: 100,
+		},
+		{
+			desc:   "the range is required",
+			params: map[string]string{"aggregate": "true", "to": "101000"},
+			status: 400,
+		},
+	}
+
+	for _, tc := range tests {
+		loggedInUserScenarioWithRole(t, tc.desc, "GET", "/api/annotations", "/api/annotations",
+			models.ROLE_VIEWER, func(sc *scenarioContext) {
+				fakeAnnoRepo = &fakeAnnotationsRepo{}
+				annotations.SetRepository(fakeAnnoRepo)
+				sc.handlerFunc = GetAnnotations
+				sc.fakeReqWithParams("GET", sc.url, tc.params).exec()
+				require.Equal(t, tc.status, sc.resp.Code)
+				if tc.status != 200 {
+					assert.Nil(t, fakeAnnoRepo.aggregateQuery)
+					return
+				}
+
+				require.NotNil(t, fakeAnnoRepo.aggregateQuery)
+				assert.Equal(t, tc.interval, fakeAnnoRepo.aggregateQuery.Interval)
+				assert.Equal(t, int64(testOrgID), fakeAnnoRepo.aggregateQuery.OrgId)
+
+				var items []annotations.AggregatedItemDTO
+				require.NoError(t, json.Unmarshal(sc.resp.Body.Bytes(), &items))
+				require.Len(t, items, 1)
+				assert.Equal(t, int64(2), items[0].Count)
+			})
+	}
+}
+
 type fakeAnnotationsRepo struct {
+	tagsQuery      *annotations.TagsQuery
+	aggregateQuery *annotations.AggregateQuery
+	savedItem      *annotations.Item
 }
 
 func (repo *fakeAnnotationsRepo) Delete(params *annotations.DeleteParams) error {
@@ -250,6 +372,7 @@ func (repo *fakeAnnotationsRepo) Delete(params *annotations.DeleteParams) error
 }
 func (repo *fakeAnnotationsRepo) Save(item *annotations.Item) error {
 	item.Id = 1
+	repo.savedItem = item
 	return nil
 }
 func (repo *fakeAnnotationsRepo) Update(item *annotations.Item) error {
@@ -259,6 +382,18 @@ func (repo *fakeAnnotationsRepo) Find(query *annotations.ItemQuery) ([]*annotati
 	annotations := []*annotations.ItemDTO{{Id: 1}}
 	return annotations, nil
 }
+func (repo *fakeAnnotationsRepo) FindAggregated(query *annotations.AggregateQuery) ([]*annotations.AggregatedItemDTO, error) {
+	repo.aggregateQuery = query
+	items := []*annotations.AggregatedItemDTO{{Time: query.From, TimeEnd: query.From + query.Interval, Count: 2}}
+	return items, nil
+}
+func (repo *fakeAnnotationsRepo) FindTags(query *annotations.TagsQuery) (annotations.FindTagsResult, error) {
+	repo.tagsQuery = query
+	result := annotations.FindTagsResult{
+		Tags: []*annotations.TagsDTO{{Tag: "outage", Count: 3}},
+	}
+	return result, nil
+}
 
 var fakeAnnoRepo *fakeAnnotationsRepo
 
@@ -274,7 +409,8 @@ func postAnnotationScenario(t *testing.T, desc string, url string, routePattern
 			sc.context.OrgId = testOrgID
 			sc.context.OrgRole = role
 
-			return PostAnnotation(c, cmd)
+			hs := &HTTPServer{HTMLSanitizer: &sanitizer.Service{Cfg: setting.NewCfg()}}
+			return hs.PostAnnotation(c, cmd)
 		})
 
 		fakeAnnoRepo = &fakeAnnotationsRepo{}
@@ -298,7 +434,8 @@ func putAnnotationScenario(t *testing.T, desc string, url string, routePattern s
 			sc.context.OrgId = testOrgID
 			sc.context.OrgRole = role
 
-			return UpdateAnnotation(c, cmd)
+			hs := &HTTPServer{HTMLSanitizer: &sanitizer.Service{Cfg: setting.NewCfg()}}
+			return hs.UpdateAnnotation(c, cmd)
 		})
 
 		fakeAnnoRepo = &fakeAnnotationsRepo{}
@@ -321,7 +458,8 @@ func patchAnnotationScenario(t *testing.T, desc string, url string, routePattern
 			sc.context.OrgId = testOrgID
 			sc.context.OrgRole = role
 
-			return PatchAnnotation(c, cmd)
+			hs := &HTTPServer{HTMLSanitizer: &sanitizer.Service{Cfg: setting.NewCfg()}}
+			return hs.PatchAnnotation(c, cmd)
 		})
 
 		fakeAnnoRepo = &fakeAnnotationsRepo{}
@@ -4,7 +4,6 @@ package api
 import (
 	"time"
 
-	"github.com/go-macaron/binding"
 	"github.com/grafana/grafana/pkg/api/avatar"
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/frontendlogging"
@@ -34,7 +33,8 @@ func (hs *HTTPServer) registerRoutes() {
 	redirectFromLegacyPanelEditURL := middleware.RedirectFromLegacyPanelEditURL(hs.Cfg)
 	authorize := acmiddleware.Middleware(hs.AccessControl)
 	quota := middleware.Quota(hs.QuotaService)
-	bind := binding.Bind
+	bind := routing.Bind
+	returns := routing.Returns
 
 	r := hs.RouteRegister
 
@@ -141,6 +141,7 @@ func (hs *HTTPServer) registerRoutes() {
 			userRoute.Post("/using/:id", routing.Wrap(UserSetUsingOrg))
 			userRoute.Get("/orgs", routing.Wrap(GetSignedInUserOrgList))
 			userRoute.Get("/teams", routing.Wrap(GetSignedInUserTeamList))
+			userRoute.Get("/boot", routing.Wrap(hs.GetSignedInUserBootData))
 
 			userRoute.Post("/stars/dashboard/:id", routing.Wrap(StarDashboard))
 			userRoute.Delete("/stars/dashboard/:id", routing.Wrap(UnstarDashboard))
@@ -156,6 +157,9 @@ func (hs *HTTPServer) registerRoutes() {
 
 			userRoute.Get("/auth-tokens", routing.Wrap(hs.GetUserAuthTokens))
 			userRoute.Post("/revoke-auth-token", bind(models.RevokeAuthTokenCmd{}), routing.Wrap(hs.RevokeUserAuthToken))
+			userRoute.Get("/login-history", routing.Wrap(GetUserLoginHistory))
+
+			userRoute.Post("/impersonation/stop", routing.Wrap(StopImpersonation))
 		}, reqSignedInNoAnonymous)
 
 		// users (admin permission required)
@@ -176,8 +180,9 @@ func (hs *HTTPServer) registerRoutes() {
 		apiRoute.Group("/teams", func(teamsRoute routing.RouteRegister) {
 			teamsRoute.Post("/", bind(models.CreateTeamCommand{}), routing.Wrap(hs.CreateTeam))
 			teamsRoute.Put("/:teamId", bind(models.UpdateTeamCommand{}), routing.Wrap(hs.UpdateTeam))
+			teamsRoute.Put("/uid/:uid", routing.Wrap(hs.UpsertTeamByUID))
 			teamsRoute.Delete("/:teamId", routing.Wrap(hs.DeleteTeamByID))
-			teamsRoute.Get("/:teamId/members", routing.Wrap(hs.GetTeamMembers))
+			teamsRoute.Get("/:teamId/members", returns(200, []*models.TeamMemberDTO{}), routing.Wrap(hs.GetTeamMembers))
 			teamsRoute.Post("/:teamId/members", bind(models.AddTeamMemberCommand{}), routing.Wrap(hs.AddTeamMember))
 			teamsRoute.Put("/:teamId/members/:userId", bind(models.UpdateTeamMemberCommand{}), routing.Wrap(hs.UpdateTeamMember))
 			teamsRoute.Delete("/:teamId/members/:userId", routing.Wrap(hs.RemoveTeamMember))
@@ -187,8 +192,8 @@ func (hs *HTTPServer) registerRoutes() {
 
 		// team without requirement of user to be org admin
 		apiRoute.Group("/teams", func(teamsRoute routing.RouteRegister) {
-			teamsRoute.Get("/:teamId", routing.Wrap(hs.GetTeamByID))
-			teamsRoute.Get("/search", routing.Wrap(hs.SearchTeams))
+			teamsRoute.Get("/:teamId", returns(200, models.TeamDTO{}), routing.Wrap(hs.GetTeamByID))
+			teamsRoute.Get("/search", returns(200, models.SearchTeamQueryResult{}), routing.Wrap(hs.SearchTeams))
 		})
 
 		// org information available to all users.
@@ -216,6 +221,10 @@ func (hs *HTTPServer) registerRoutes() {
 			// prefs
 			orgRoute.Get("/preferences", reqOrgAdmin, routing.Wrap(GetOrgPreferences))
 			orgRoute.Put("/preferences", reqOrgAdmin, bind(dtos.UpdatePrefsCmd{}), routing.Wrap(UpdateOrgPreferences))
+
+			// read-only folders
+			orgRoute.Get("/read-only-folders", authorize(reqOrgAdmin, accesscontrol.ActionOrgReadOnlyFoldersRead), routing.Wrap(GetReadOnlyFolders))
+			orgRoute.Put("/read-only-folders", authorize(reqOrgAdmin, accesscontrol.ActionOrgReadOnlyFoldersWrite), bind(dtos.SetReadOnlyFoldersForm{}), routing.Wrap(SetReadOnlyFolders))
 		})
 
 		// current org without requirement of user to be org admin
@@ -270,7 +279,21 @@ func (hs *HTTPServer) registerRoutes() {
 			datasourceRoute.Delete("/uid/:uid", routing.Wrap(hs.DeleteDataSourceByUID))
 			datasourceRoute.Delete("/name/:name", routing.Wrap(hs.DeleteDataSourceByName))
 			datasourceRoute.Get("/:id", routing.Wrap(GetDataSourceById))
+			datasourceRoute.Get("/:id/connection-pool", routing.Wrap(GetDataSourceConnectionPool))
+			datasourceRoute.Get("/:id/versions", routing.Wrap(GetDataSourceVersions))
+			datasourceRoute.Get("/:id/versions/:version", routing.Wrap(GetDataSourceVersion))
+			datasourceRoute.Get("/:id/versions/:version/diff", routing.Wrap(GetDataSourceVersionDiff))
+			datasourceRoute.Post("/:id/restore", bind(dtos.RestoreDataSourceVersionCommand{}), routing.Wrap(hs.RestoreDataSourceVersion))
+			datasourceRoute.Get("/:id/explore-permissions", routing.Wrap(GetDataSourceExplorePermissions))
+			datasourceRoute.Put("/:id/explore-permissions", bind(dtos.UpdateDataSourceExplorePermissionsCommand{}), routing.Wrap(UpdateDataSourceExplorePermissions))
+			datasourceRoute.Get("/:id/secrets/rotation", routing.Wrap(GetDataSourceSecretRotation))
+			datasourceRoute.Post("/:id/secrets/rotation", bind(dtos.StageDataSourceSecretsCommand{}), routing.Wrap(StageDataSourceSecrets))
+			datasourceRoute.Delete("/:id/secrets/rotation", routing.Wrap(DiscardDataSourceSecrets))
+			datasourceRoute.Post("/:id/secrets/rotation/validate", routing.Wrap(hs.ValidateDataSourceSecrets))
+			datasourceRoute.Post("/:id/secrets/rotation/promote", routing.Wrap(hs.PromoteDataSourceSecrets))
+			datasourceRoute.Post("/:id/secrets/rotation/rollback", routing.Wrap(hs.RollbackDataSourceSecrets))
 			datasourceRoute.Get("/uid/:uid", routing.Wrap(GetDataSourceByUID))
+			datasourceRoute.Put("/uid/:uid", routing.Wrap(hs.UpsertDataSourceByUID))
 			datasourceRoute.Get("/name/:name", routing.Wrap(GetDataSourceByName))
 		}, reqOrgAdmin)
 
@@ -298,27 +321,35 @@ func (hs *HTTPServer) registerRoutes() {
 		}, reqOrgAdmin)
 
 		apiRoute.Get("/frontend/settings/", hs.GetFrontendSettings)
+		apiRoute.Post("/caches/invalidate", reqOrgAdmin, bind(dtos.InvalidateCachesCommand{}), routing.Wrap(hs.InvalidateCaches))
 		apiRoute.Any("/datasources/proxy/:id/*", reqSignedIn, hs.ProxyDataSourceRequest)
 		apiRoute.Any("/datasources/proxy/:id", reqSignedIn, hs.ProxyDataSourceRequest)
 		apiRoute.Any("/datasources/:id/resources", hs.CallDatasourceResource)
 		apiRoute.Any("/datasources/:id/resources/*", hs.CallDatasourceResource)
 		apiRoute.Any("/datasources/:id/health", routing.Wrap(hs.CheckDatasourceHealth))
+		apiRoute.Get("/datasources/:id/query-examples", routing.Wrap(hs.GetDataSourceQueryExamples))
+		apiRoute.Get("/datasources/:id/warm-up-runs", reqOrgAdmin, routing.Wrap(hs.GetDataSourceWarmUpRuns))
+		apiRoute.Post("/datasources/:id/onboarding", reqOrgAdmin, routing.Wrap(hs.ValidateDatasourceOnboarding))
 
 		// Folders
 		apiRoute.Group("/folders", func(folderRoute routing.RouteRegister) {
-			folderRoute.Get("/", routing.Wrap(hs.GetFolders))
-			folderRoute.Get("/id/:id", routing.Wrap(hs.GetFolderByID))
-			folderRoute.Post("/", bind(models.CreateFolderCommand{}), routing.Wrap(hs.CreateFolder))
+			folderRoute.Get("/", returns(200, []dtos.FolderSearchHit{}), routing.Wrap(hs.GetFolders))
+			folderRoute.Get("/id/:id", returns(200, dtos.Folder{}), routing.Wrap(hs.GetFolderByID))
+			folderRoute.Post("/", bind(models.CreateFolderCommand{}), returns(200, dtos.Folder{}), routing.Wrap(hs.CreateFolder))
 
 			folderRoute.Group("/:uid", func(folderUidRoute routing.RouteRegister) {
-				folderUidRoute.Get("/", routing.Wrap(hs.GetFolderByUID))
-				folderUidRoute.Put("/", bind(models.UpdateFolderCommand{}), routing.Wrap(hs.UpdateFolder))
+				folderUidRoute.Get("/", returns(200, dtos.Folder{}), routing.Wrap(hs.GetFolderByUID))
+				folderUidRoute.Put("/", bind(models.UpdateFolderCommand{}), returns(200, dtos.Folder{}), routing.Wrap(hs.UpdateFolder))
 				folderUidRoute.Delete("/", routing.Wrap(hs.DeleteFolder))
 
 				folderUidRoute.Group("/permissions", func(folderPermissionRoute routing.RouteRegister) {
 					folderPermissionRoute.Get("/", routing.Wrap(hs.GetFolderPermissionList))
 					folderPermissionRoute.Post("/", bind(dtos.UpdateDashboardAclCommand{}), routing.Wrap(hs.UpdateFolderPermissions))
 				})
+
+				const folderUIDScope = `folders:uid:{{ index . ":uid" }}`
+				folderUidRoute.Get("/reviewers", authorize(reqOrgAdmin, accesscontrol.ActionFoldersReviewersRead, folderUIDScope), routing.Wrap(hs.GetFolderReviewers))
+				folderUidRoute.Put("/reviewers", authorize(reqOrgAdmin, accesscontrol.ActionFoldersReviewersWrite, folderUIDScope), bind(dtos.SetFolderReviewersForm{}), routing.Wrap(hs.SetFolderReviewers))
 			})
 		})
 
@@ -326,6 +357,11 @@ func (hs *HTTPServer) registerRoutes() {
 		apiRoute.Group("/dashboards", func(dashboardRoute routing.RouteRegister) {
 			dashboardRoute.Get("/uid/:uid", routing.Wrap(hs.GetDashboard))
 			dashboardRoute.Delete("/uid/:uid", routing.Wrap(hs.DeleteDashboardByUID))
+			dashboardRoute.Get("/uid/:uid/thumbnail", routing.Wrap(hs.GetDashboardThumbnail))
+			dashboardRoute.Get("/uid/:uid/panels/:panelId", routing.Wrap(hs.GetDashboardPanel))
+			dashboardRoute.Post("/uid/:uid/resolve", bind(dtos.ResolveDashboardCommand{}), routing.Wrap(hs.ResolveDashboard))
+			dashboardRoute.Get("/uid/:uid/editors", routing.Wrap(hs.GetDashboardEditors))
+			dashboardRoute.Post("/uid/:uid/editors/takeover", routing.Wrap(hs.TakeOverDashboardEditLock))
 
 			dashboardRoute.Get("/db/:slug", routing.Wrap(hs.GetDashboard))
 			dashboardRoute.Delete("/db/:slug", routing.Wrap(hs.DeleteDashboardBySlug))
@@ -337,6 +373,14 @@ func (hs *HTTPServer) registerRoutes() {
 			dashboardRoute.Get("/home", routing.Wrap(hs.GetHomeDashboard))
 			dashboardRoute.Get("/tags", GetDashboardTags)
 			dashboardRoute.Post("/import", bind(dtos.ImportDashboardCommand{}), routing.Wrap(hs.ImportDashboard))
+			dashboardRoute.Get("/provisioning/drift", routing.Wrap(hs.GetProvisionedDashboardDrifts))
+			dashboardRoute.Get("/uid/:uid/provisioning/drift", routing.Wrap(hs.GetProvisionedDashboardDrift))
+			dashboardRoute.Delete("/uid/:uid/provisioning/drift", routing.Wrap(hs.DeleteProvisionedDashboardDrift))
+
+			dashboardRoute.Get("/revisions", routing.Wrap(GetDashboardRevisions))
+			dashboardRoute.Get("/revisions/:revisionId", routing.Wrap(GetDashboardRevision))
+			dashboardRoute.Post("/revisions/:revisionId/approve", bind(dtos.ReviewDashboardRevisionForm{}), routing.Wrap(hs.ApproveDashboardRevision))
+			dashboardRoute.Post("/revisions/:revisionId/reject", bind(dtos.ReviewDashboardRevisionForm{}), routing.Wrap(hs.RejectDashboardRevision))
 
 			dashboardRoute.Group("/id/:dashboardId", func(dashIdRoute routing.RouteRegister) {
 				dashIdRoute.Get("/versions", routing.Wrap(GetDashboardVersions))
@@ -370,6 +414,9 @@ func (hs *HTTPServer) registerRoutes() {
 		apiRoute.Get("/search/sorting", routing.Wrap(hs.ListSortOptions))
 		apiRoute.Get("/search/", routing.Wrap(Search))
 
+		// OpenAPI document of the routes
+		apiRoute.Get("/openapi.json", routing.Wrap(hs.GetOpenAPIDocument))
+
 		// metrics
 		apiRoute.Post("/tsdb/query", bind(dtos.MetricRequest{}), routing.Wrap(hs.QueryMetrics))
 		apiRoute.Get("/tsdb/testdata/gensql", reqGrafanaAdmin, routing.Wrap(GenerateSQLTestData))
@@ -377,6 +424,9 @@ func (hs *HTTPServer) registerRoutes() {
 
 		// DataSource w/ expressions
 		apiRoute.Post("/ds/query", bind(dtos.MetricRequest{}), routing.Wrap(hs.QueryMetricsV2))
+		apiRoute.Post("/ds/lint", bind(dtos.LintQueriesRequest{}), routing.Wrap(hs.LintQueries))
+		apiRoute.Post("/ds/explain", bind(dtos.MetricRequest{}), routing.Wrap(hs.ExplainQueries))
+		apiRoute.Post("/variables/options", bind(dtos.VariableOptionsRequest{}), routing.Wrap(hs.QueryVariableOptions))
 
 		apiRoute.Group("/alerts", func(alertsRoute routing.RouteRegister) {
 			alertsRoute.Post("/test", bind(dtos.AlertTestCommand{}), routing.Wrap(hs.AlertTest))
@@ -408,16 +458,20 @@ func (hs *HTTPServer) registerRoutes() {
 		})
 
 		apiRoute.Get("/annotations", routing.Wrap(GetAnnotations))
+		apiRoute.Get("/annotations/tags", routing.Wrap(GetAnnotationTags))
 		apiRoute.Post("/annotations/mass-delete", reqOrgAdmin, bind(dtos.DeleteAnnotationsCmd{}), routing.Wrap(DeleteAnnotations))
 
 		apiRoute.Group("/annotations", func(annotationsRoute routing.RouteRegister) {
-			annotationsRoute.Post("/", bind(dtos.PostAnnotationsCmd{}), routing.Wrap(PostAnnotation))
+			annotationsRoute.Post("/", bind(dtos.PostAnnotationsCmd{}), routing.Wrap(hs.PostAnnotation))
 			annotationsRoute.Delete("/:annotationId", routing.Wrap(DeleteAnnotationByID))
-			annotationsRoute.Put("/:annotationId", bind(dtos.UpdateAnnotationsCmd{}), routing.Wrap(UpdateAnnotation))
-			annotationsRoute.Patch("/:annotationId", bind(dtos.PatchAnnotationsCmd{}), routing.Wrap(PatchAnnotation))
-			annotationsRoute.Post("/graphite", reqEditorRole, bind(dtos.PostGraphiteAnnotationsCmd{}), routing.Wrap(PostGraphiteAnnotation))
+			annotationsRoute.Put("/:annotationId", bind(dtos.UpdateAnnotationsCmd{}), routing.Wrap(hs.UpdateAnnotation))
+			annotationsRoute.Patch("/:annotationId", bind(dtos.PatchAnnotationsCmd{}), routing.Wrap(hs.PatchAnnotation))
+			annotationsRoute.Post("/graphite", reqEditorRole, bind(dtos.PostGraphiteAnnotationsCmd{}), routing.Wrap(hs.PostGraphiteAnnotation))
 		})
 
+		// change events feed
+		apiRoute.Get("/events", reqOrgAdmin, routing.Wrap(SearchChangeEvents))
+
 		apiRoute.Post("/frontend-metrics", bind(metrics.PostFrontendMetricsCommand{}), routing.Wrap(hs.PostFrontendMetrics))
 
 		apiRoute.Group("/live", func(liveRoute routing.RouteRegister) {
@@ -436,6 +490,22 @@ func (hs *HTTPServer) registerRoutes() {
 
 		// short urls
 		apiRoute.Post("/short-urls", bind(dtos.CreateShortURLCmd{}), routing.Wrap(hs.createShortURL))
+
+		// files used by panels, e.g. images and geojson layers
+		apiRoute.Group("/files", func(filesRoute routing.RouteRegister) {
+			filesRoute.Get("/", routing.Wrap(hs.GetFiles))
+			filesRoute.Post("/", reqEditorRole, routing.Wrap(hs.UploadFile))
+			filesRoute.Get("/*", routing.Wrap(hs.GetFile))
+			filesRoute.Delete("/*", reqEditorRole, routing.Wrap(hs.DeleteFile))
+		})
+
+		// geojson layers and tile server proxy for geomap panels
+		apiRoute.Group("/geomap", func(geomapRoute routing.RouteRegister) {
+			geomapRoute.Get("/layers", routing.Wrap(hs.GetGeomapLayers))
+			geomapRoute.Get("/layers/*", routing.Wrap(hs.GetGeomapLayer))
+			geomapRoute.Get("/tiles", routing.Wrap(hs.GetGeomapTileServers))
+			geomapRoute.Get("/tiles/:server/:z/:x/:y", routing.Wrap(hs.GetGeomapTile))
+		})
 	}, reqSignedIn)
 
 	// admin api
@@ -443,8 +513,23 @@ func (hs *HTTPServer) registerRoutes() {
 		adminRoute.Get("/settings", reqGrafanaAdmin, routing.Wrap(hs.AdminGetSettings))
 		adminRoute.Get("/stats", reqGrafanaAdmin, routing.Wrap(AdminGetStats))
 		adminRoute.Post("/pause-all-alerts", reqGrafanaAdmin, bind(dtos.PauseAllAlertsCommand{}), routing.Wrap(PauseAllAlerts))
+		adminRoute.Get("/slow-queries", reqGrafanaAdmin, routing.Wrap(AdminGetSlowQueries))
+		adminRoute.Get("/datasource-recording", reqGrafanaAdmin, routing.Wrap(hs.AdminGetDataSourceRecording))
+		adminRoute.Put("/datasource-recording", reqGrafanaAdmin, bind(dtos.UpdateDataSourceRecordingCommand{}), routing.Wrap(hs.AdminUpdateDataSourceRecording))
+		adminRoute.Delete("/datasource-recording/recordings", reqGrafanaAdmin, routing.Wrap(hs.AdminDeleteDataSourceRecordings))
+		adminRoute.Get("/impersonation/sessions", reqGrafanaAdmin, routing.Wrap(AdminSearchImpersonationSessions))
+		adminRoute.Get("/org-role-rules", reqGrafanaAdmin, routing.Wrap(AdminGetOrgRoleRules))
+		adminRoute.Post("/org-role-rules", reqGrafanaAdmin, bind(models.CreateOrgRoleRuleCommand{}), routing.Wrap(AdminCreateOrgRoleRule))
+		adminRoute.Put("/org-role-rules/:id", reqGrafanaAdmin, bind(models.UpdateOrgRoleRuleCommand{}), routing.Wrap(AdminUpdateOrgRoleRule))
+		adminRoute.Delete("/org-role-rules/:id", reqGrafanaAdmin, routing.Wrap(AdminDeleteOrgRoleRule))
+		if hs.Cfg.Diagnostics.APIEnabled {
+			adminRoute.Get("/diagnostics/runtime", reqGrafanaAdmin, routing.Wrap(AdminGetRuntimeStats))
+			adminRoute.Get("/diagnostics/profiles/:profile", reqGrafanaAdmin, routing.Wrap(hs.AdminGetProfile))
+			adminRoute.Get("/diagnostics/bundle", reqGrafanaAdmin, routing.Wrap(hs.AdminGetDiagnosticsBundle))
+		}
 
 		adminRoute.Post("/provisioning/dashboards/reload", reqGrafanaAdmin, routing.Wrap(hs.AdminProvisioningReloadDashboards))
+		adminRoute.Get("/provisioning/dashboards/git", reqGrafanaAdmin, routing.Wrap(hs.AdminGetProvisioningDashboardsGitStatus))
 		adminRoute.Post("/provisioning/plugins/reload", reqGrafanaAdmin, routing.Wrap(hs.AdminProvisioningReloadPlugins))
 		adminRoute.Post("/provisioning/datasources/reload", reqGrafanaAdmin, routing.Wrap(hs.AdminProvisioningReloadDatasources))
 		adminRoute.Post("/provisioning/notifications/reload", reqGrafanaAdmin, routing.Wrap(hs.AdminProvisioningReloadNotifications))
@@ -466,9 +551,11 @@ func (hs *HTTPServer) registerRoutes() {
 		adminUserRoute.Get("/:id/quotas", authorize(reqGrafanaAdmin, accesscontrol.ActionUsersQuotasList, userIDScope), routing.Wrap(GetUserQuotas))
 		adminUserRoute.Put("/:id/quotas/:target", authorize(reqGrafanaAdmin, accesscontrol.ActionUsersQuotasUpdate, userIDScope), bind(models.UpdateUserQuotaCmd{}), routing.Wrap(UpdateUserQuota))
 
+		adminUserRoute.Post("/:id/impersonate", reqGrafanaAdmin, bind(dtos.StartImpersonationForm{}), routing.Wrap(AdminStartImpersonation))
 		adminUserRoute.Post("/:id/logout", authorize(reqGrafanaAdmin, accesscontrol.ActionUsersLogout, userIDScope), routing.Wrap(hs.AdminLogoutUser))
 		adminUserRoute.Get("/:id/auth-tokens", authorize(reqGrafanaAdmin, accesscontrol.ActionUsersAuthTokenList, userIDScope), routing.Wrap(hs.AdminGetUserAuthTokens))
 		adminUserRoute.Post("/:id/revoke-auth-token", authorize(reqGrafanaAdmin, accesscontrol.ActionUsersAuthTokenUpdate, userIDScope), bind(models.RevokeAuthTokenCmd{}), routing.Wrap(hs.AdminRevokeUserAuthToken))
+		adminUserRoute.Get("/:id/login-history", authorize(reqGrafanaAdmin, accesscontrol.ActionUsersAuthTokenList, userIDScope), routing.Wrap(AdminGetUserLoginHistory))
 	})
 
 	// rendering
@@ -490,6 +577,10 @@ func (hs *HTTPServer) registerRoutes() {
 
 	// Frontend logs
 	sourceMapStore := frontendlogging.NewSourceMapStore(hs.Cfg, hs.PluginManager, frontendlogging.ReadSourceMapFromFS)
+	sentryForwarder, err := frontendlogging.NewSentryForwarder(hs.Cfg.Sentry.ForwardDSNs)
+	if err != nil {
+		hs.log.Error("Failed to set up the forwarding of the frontend events", "error", err)
+	}
 	r.Post("/log", middleware.RateLimit(hs.Cfg.Sentry.EndpointRPS, hs.Cfg.Sentry.EndpointBurst, time.Now),
-		bind(frontendlogging.FrontendSentryEvent{}), routing.Wrap(NewFrontendLogMessageHandler(sourceMapStore)))
+		bind(frontendlogging.FrontendSentryEvent{}), routing.Wrap(NewFrontendLogMessageHandler(hs.Cfg, sourceMapStore, sentryForwarder)))
 }
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// InvalidateCaches handles POST /api/caches/invalidate. It removes the cached data of the data sources, the frontend
+// settings and the variable options of the org, so that the next requests read them again. The caches are those of
+// this Grafana instance only.
+func (hs *HTTPServer) InvalidateCaches(c *models.ReqContext, cmd dtos.InvalidateCachesCommand) response.Response {
+	if len(cmd.DataSourceUIDs) == 0 && !cmd.FrontendSettings && !cmd.VariableOptions {
+		return response.Error(http.StatusBadRequest, "No caches to invalidate", nil)
+	}
+
+	for _, uid := range cmd.DataSourceUIDs {
+		// reading the data source without the cache replaces its cached entries
+		ds, err := hs.DatasourceCache.GetDatasourceByUID(uid, c.SignedInUser, true)
+		if err != nil {
+			if errors.Is(err, models.ErrDataSourceNotFound) {
+				return response.Error(http.StatusNotFound, fmt.Sprintf("Data source %q not found", uid), nil)
+			}
+			return response.Error(http.StatusInternalServerError, "Failed to query data source", err)
+		}
+		models.ClearDSCache(ds.Id)
+		hs.variableOptionsCache.invalidateDataSource(ds.Id)
+	}
+
+	if cmd.FrontendSettings {
+		hs.frontendSettingsCache.invalidate(c.OrgId)
+	}
+	if cmd.VariableOptions {
+		hs.variableOptionsCache.invalidateOrg(c.OrgId)
+	}
+
+	return response.JSON(http.StatusOK, util.DynMap{
+		"message":          "Caches invalidated",
+		"datasources":      len(cmd.DataSourceUIDs),
+		"frontendSettings": cmd.FrontendSettings,
+		"variableOptions":  cmd.VariableOptions,
+	})
+}
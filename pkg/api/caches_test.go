@@ -0,0 +1,56 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func TestInvalidateCaches(t *testing.T) {
+	setup := func() (*HTTPServer, *fakeDatasourceCache, *models.ReqContext) {
+		dsCache := &fakeDatasourceCache{datasource: &models.DataSource{Id: 1, Uid: "prom", OrgId: testOrgID}}
+		hs := &HTTPServer{DatasourceCache: dsCache, frontendSettingsCache: newFrontendSettingsCache()}
+		c := &models.ReqContext{SignedInUser: &models.SignedInUser{OrgId: testOrgID, OrgRole: models.ROLE_ADMIN}}
+		return hs, dsCache, c
+	}
+
+	t.Run("invalidates the caches of the data sources", func(t *testing.T) {
+		hs, dsCache, c := setup()
+		resp := hs.InvalidateCaches(c, dtos.InvalidateCachesCommand{DataSourceUIDs: []string{"prom"}})
+		require.Equal(t, 200, resp.Status())
+		require.True(t, dsCache.skippedCache)
+	})
+
+	t.Run("invalidates the frontend settings of the org", func(t *testing.T) {
+		hs, _, c := setup()
+		builds := 0
+		build := func() (*orgFrontendSettings, error) {
+			builds++
+			return &orgFrontendSettings{}, nil
+		}
+		_, err := hs.frontendSettingsCache.get(testOrgID, build)
+		require.NoError(t, err)
+
+		resp := hs.InvalidateCaches(c, dtos.InvalidateCachesCommand{FrontendSettings: true})
+		require.Equal(t, 200, resp.Status())
+
+		_, err = hs.frontendSettingsCache.get(testOrgID, build)
+		require.NoError(t, err)
+		require.Equal(t, 2, builds)
+	})
+
+	t.Run("returns 404 for a missing data source", func(t *testing.T) {
+		hs, _, c := setup()
+		resp := hs.InvalidateCaches(c, dtos.InvalidateCachesCommand{DataSourceUIDs: []string{"missing"}})
+		require.Equal(t, 404, resp.Status())
+	})
+
+	t.Run("requires a cache to invalidate", func(t *testing.T) {
+		hs, _, c := setup()
+		resp := hs.InvalidateCaches(c, dtos.InvalidateCachesCommand{})
+		require.Equal(t, 400, resp.Status())
+	})
+}
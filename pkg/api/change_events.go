@@ -0,0 +1,134 @@
+package api
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// GET /api/events
+//
+// SearchChangeEvents returns a page of the change events of the org, the newest first, filtered by the type,
+// resourceUid, action, userId, from and to (epoch milliseconds) query parameters.
+func SearchChangeEvents(c *models.ReqContext) response.Response {
+	query := models.SearchChangeEventsQuery{
+		OrgId:       c.OrgId,
+		ResourceUid: c.Query("resourceUid"),
+		UserId:      c.QueryInt64("userId"),
+		Page:        c.QueryInt("page"),
+		PerPage:     c.QueryInt("perpage"),
+	}
+	for _, t := range c.QueryStrings("type") {
+		resourceType := models.ChangeEventResourceType(t)
+		if !resourceType.IsValid() {
+			return response.Error(400, fmt.Sprintf("Invalid resource type %q", t), nil)
+		}
+		query.ResourceTypes = append(query.ResourceTypes, resourceType)
+	}
+	for _, a := range c.QueryStrings("action") {
+		action := models.ChangeEventAction(a)
+		if !action.IsValid() {
+			return response.Error(400, fmt.Sprintf("Invalid action %q", a), nil)
+		}
+		query.Actions = append(query.Actions, action)
+	}
+	if from := c.QueryInt64("from"); from > 0 {
+		query.From = time.Unix(0, from*int64(time.Millisecond))
+	}
+	if to := c.QueryInt64("to"); to > 0 {
+		query.To = time.Unix(0, to*int64(time.Millisecond))
+	}
+
+	if err := bus.Dispatch(&query); err != nil {
+		return response.Error(500, "Failed to search change events", err)
+	}
+	return response.JSON(200, query.Result)
+}
+
+// dashboardChangeEvent returns the change event of a saved dashboard or folder, or of a deleted one when the action
+// is models.ChangeEventDeleted.
+func dashboardChangeEvent(c *models.ReqContext, dash *models.Dashboard, action models.ChangeEventAction) *models.AddChangeEventCommand {
+	resourceType := models.ChangeEventDashboard
+	if dash.IsFolder {
+		resourceType = models.ChangeEventFolder
+	}
+	return versionedChangeEvent(c, resourceType, dash.Id, dash.Uid, dash.Title, action, int64(dash.Version))
+}
+
+// folderChangeEvent returns the change event of a saved folder, or of a deleted one when the action is
+// models.ChangeEventDeleted.
+func folderChangeEvent(c *models.ReqContext, folder *models.Folder, action models.ChangeEventAction) *models.AddChangeEventCommand {
+	return versionedChangeEvent(c, models.ChangeEventFolder, folder.Id, folder.Uid, folder.Title, action, int64(folder.Version))
+}
+
+// versionedChangeEvent returns the change event of a resource whose version is incremented by every change, of the
+// version of the resource after the change, or before its deletion.
+func versionedChangeEvent(c *models.ReqContext, resourceType models.ChangeEventResourceType, id int64, uid, name string,
+	action models.ChangeEventAction, version int64) *models.AddChangeEventCommand {
+	cmd := &models.AddChangeEventCommand{
+		OrgId:          c.OrgId,
+		ResourceType:   resourceType,
+		ResourceId:     id,
+		ResourceUid:    uid,
+		ResourceName:   name,
+		Action:         action,
+		UserId:         c.UserId,
+		ImpersonatorId: c.ImpersonatorId,
+	}
+	switch action {
+	case models.ChangeEventCreated:
+		cmd.NewVersion = version
+	case models.ChangeEventDeleted:
+		cmd.OldVersion = version
+	default:
+		cmd.OldVersion = version - 1
+		cmd.NewVersion = version
+	}
+	return cmd
+}
+
+// permissionsChangeEvent returns the change event of the permissions of a dashboard or folder.
+func permissionsChangeEvent(c *models.ReqContext, id int64, uid, title string) *models.AddChangeEventCommand {
+	return &models.AddChangeEventCommand{
+		OrgId:          c.OrgId,
+		ResourceType:   models.ChangeEventPermissions,
+		ResourceId:     id,
+		ResourceUid:    uid,
+		ResourceName:   title,
+		Action:         models.ChangeEventUpdated,
+		UserId:         c.UserId,
+		ImpersonatorId: c.ImpersonatorId,
+	}
+}
+
+// dataSourceChangeEvent returns the change event of a data source, from its latest version before the change to its
+// latest version after it, see latestDataSourceVersion. The versions are equal when only the secrets are changed.
+func dataSourceChangeEvent(c *models.ReqContext, ds *models.DataSource, action models.ChangeEventAction, oldVersion int64) *models.AddChangeEventCommand {
+	cmd := &models.AddChangeEventCommand{
+		OrgId:          c.OrgId,
+		ResourceType:   models.ChangeEventDataSource,
+		ResourceId:     ds.Id,
+		ResourceUid:    ds.Uid,
+		ResourceName:   ds.Name,
+		Action:         action,
+		OldVersion:     oldVersion,
+		UserId:         c.UserId,
+		ImpersonatorId: c.ImpersonatorId,
+	}
+	if action != models.ChangeEventDeleted {
+		cmd.NewVersion = latestDataSourceVersion(c.OrgId, ds.Id)
+	}
+	return cmd
+}
+
+// latestDataSourceVersion returns the latest version of a data source, or 0 when it has none.
+func latestDataSourceVersion(orgID, dataSourceID int64) int64 {
+	query := models.GetDataSourceVersionsQuery{OrgId: orgID, DataSourceId: dataSourceID, Limit: 1}
+	if err := bus.Dispatch(&query); err != nil || len(query.Result) == 0 {
+		return 0
+	}
+	return int64(query.Result[0].Version)
+}
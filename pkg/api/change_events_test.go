@@ -0,0 +1,76 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchChangeEvents(t *testing.T) {
+	loggedInUserScenarioWithRole(t, "When calling GET on", "GET", "/api/events", "/api/events", models.ROLE_ADMIN, func(sc *scenarioContext) {
+		var query *models.SearchChangeEventsQuery
+		bus.AddHandler("test", func(q *models.SearchChangeEventsQuery) error {
+			query = q
+			q.Result = models.SearchChangeEventsQueryResult{
+				TotalCount: 1,
+				Events:     []*models.ChangeEventDTO{{Id: 1, ResourceType: models.ChangeEventDashboard, Action: models.ChangeEventUpdated}},
+				Page:       q.Page,
+				PerPage:    q.PerPage,
+			}
+			return nil
+		})
+
+		sc.handlerFunc = SearchChangeEvents
+		sc.fakeReq("GET", "/api/events?type=dashboard&type=datasource&action=deleted&resourceUid=abc&userId=2&from=1000&to=2000&page=2&perpage=10").exec()
+		require.Equal(t, 200, sc.resp.Code)
+
+		assert.Equal(t, testOrgID, query.OrgId)
+		assert.Equal(t, []models.ChangeEventResourceType{models.ChangeEventDashboard, models.ChangeEventDataSource}, query.ResourceTypes)
+		assert.Equal(t, []models.ChangeEventAction{models.ChangeEventDeleted}, query.Actions)
+		assert.Equal(t, "abc", query.ResourceUid)
+		assert.Equal(t, int64(2), query.UserId)
+		assert.True(t, query.From.Equal(time.Unix(1, 0)))
+		assert.True(t, query.To.Equal(time.Unix(2, 0)))
+		assert.Equal(t, 2, query.Page)
+		assert.Equal(t, 10, query.PerPage)
+
+		var result models.SearchChangeEventsQueryResult
+		require.NoError(t, json.NewDecoder(sc.resp.Body).Decode(&result))
+		assert.Equal(t, int64(1), result.TotalCount)
+		require.Len(t, result.Events, 1)
+		assert.Equal(t, models.ChangeEventUpdated, result.Events[0].Action)
+	})
+
+	loggedInUserScenarioWithRole(t, "When calling GET with an invalid type on", "GET", "/api/events", "/api/events", models.ROLE_ADMIN, func(sc *scenarioContext) {
+		sc.handlerFunc = SearchChangeEvents
+		sc.fakeReq("GET", "/api/events?type=user").exec()
+		assert.Equal(t, 400, sc.resp.Code)
+
+		sc.fakeReq("GET", "/api/events?action=moved").exec()
+		assert.Equal(t, 400, sc.resp.Code)
+	})
+}
+
+func TestVersionedChangeEvent(t *testing.T) {
+	c := &models.ReqContext{SignedInUser: &models.SignedInUser{OrgId: 1, UserId: 2}}
+	dash := &models.Dashboard{Id: 3, Uid: "uid", Title: "Dashboard", Version: 4}
+
+	cmd := dashboardChangeEvent(c, dash, models.ChangeEventUpdated)
+	assert.Equal(t, models.ChangeEventDashboard, cmd.ResourceType)
+	assert.Equal(t, int64(2), cmd.UserId)
+	assert.Equal(t, [2]int64{3, 4}, [2]int64{cmd.OldVersion, cmd.NewVersion})
+
+	cmd = dashboardChangeEvent(c, dash, models.ChangeEventDeleted)
+	assert.Equal(t, [2]int64{4, 0}, [2]int64{cmd.OldVersion, cmd.NewVersion})
+
+	dash.IsFolder = true
+	dash.Version = 1
+	cmd = dashboardChangeEvent(c, dash, models.ChangeEventCreated)
+	assert.Equal(t, models.ChangeEventFolder, cmd.ResourceType)
+	assert.Equal(t, [2]int64{0, 1}, [2]int64{cmd.OldVersion, cmd.NewVersion})
+}
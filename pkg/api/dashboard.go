@@ -9,6 +9,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/services/audit"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
@@ -98,6 +99,13 @@ func (hs *HTTPServer) GetDashboard(c *models.ReqContext) response.Response {
 	canEdit, _ := guardian.CanEdit()
 	canSave, _ := guardian.CanSave()
 	canAdmin, _ := guardian.CanAdmin()
+	if canSave {
+		folderID := dash.FolderId
+		if dash.IsFolder {
+			folderID = dash.Id
+		}
+		canSave = hs.checkReadOnlyFolders(c, folderID) == nil
+	}
 
 	isStarred, err := isDashboardStarredByUser(c, dash.Id)
 	if err != nil {
@@ -154,6 +162,7 @@ func (hs *HTTPServer) GetDashboard(c *models.ReqContext) response.Response {
 		if !allowUIUpdate {
 			meta.Provisioned = true
 		}
+		meta.ProvisionedOverlay = provisioningData.Overlay
 
 		meta.ProvisionedExternalId, err = filepath.Rel(
 			hs.ProvisioningService.GetDashboardProvisionerResolvedPath(provisioningData.Name),
@@ -180,6 +189,14 @@ func (hs *HTTPServer) GetDashboard(c *models.ReqContext) response.Response {
 		Meta:      meta,
 	}
 
+	// the panels of the large dashboards are fetched when they're shown, with GetDashboardPanel
+	if c.Query("panels") == "summary" {
+		if panels, ok := dash.Data.CheckGet("panels"); ok {
+			dash.Data.Set("panels", summarizePanels(panels.MustArray()))
+			dto.Meta.PanelsSummarized = true
+		}
+	}
+
 	c.TimeRequest(metrics.MApiDashboardGet)
 	return response.JSON(200, dto)
 }
@@ -238,6 +255,14 @@ func (hs *HTTPServer) deleteDashboard(c *models.ReqContext) response.Response {
 		return dashboardGuardianResponse(err)
 	}
 
+	folderID := dash.FolderId
+	if dash.IsFolder {
+		folderID = dash.Id
+	}
+	if rsp := hs.checkReadOnlyFolders(c, folderID); rsp != nil {
+		return rsp
+	}
+
 	// disconnect all library elements for this dashboard
 	err := hs.LibraryElementService.DisconnectElementsFromDashboard(c, dash.Id)
 	if err != nil {
@@ -256,6 +281,7 @@ func (hs *HTTPServer) deleteDashboard(c *models.ReqContext) response.Response {
 
 		return response.Error(500, "Failed to delete dashboard", err)
 	}
+	audit.RecordChange(dashboardChangeEvent(c, dash, models.ChangeEventDeleted))
 
 	if hs.Live != nil {
 		err := hs.Live.GrafanaScope.Dashboards.DashboardDeleted(c.OrgId, c.ToUserDisplayDTO(), dash.Uid)
@@ -275,6 +301,12 @@ func (hs *HTTPServer) PostDashboard(c *models.ReqContext, cmd models.SaveDashboa
 	var err error
 	cmd.OrgId = c.OrgId
 	cmd.UserId = c.UserId
+	// the panel summaries would replace the panels of the dashboard
+	if hasSummarizedPanels(cmd.Dashboard) {
+		return response.Error(400, "Dashboard has panel summaries instead of its panels", nil)
+	}
+
+	hs.HTMLSanitizer.SanitizeDashboard(c.OrgId, cmd.Dashboard)
 	dash := cmd.GetDashboardModel()
 	newDashboard := dash.Id == 0 && dash.Uid == ""
 	if newDashboard {
@@ -287,6 +319,30 @@ func (hs *HTTPServer) PostDashboard(c *models.ReqContext, cmd models.SaveDashboa
 		}
 	}
 
+	folderIDs, err := savedDashboardFolderIDs(c.OrgId, dash)
+	if err != nil {
+		return response.Error(500, "Failed to get dashboard", err)
+	}
+	if rsp := hs.checkReadOnlyFolders(c, folderIDs...); rsp != nil {
+		return rsp
+	}
+
+	// the edit lock is soft, the users can take it over to save anyway
+	if hs.Cfg.DashboardEditLock && hs.Live != nil && dash.Uid != "" {
+		editors := hs.Live.GrafanaScope.Dashboards.DashboardEditors(c.OrgId, dash.Uid)
+		if editors.IsLockedByAnother(c.UserId) {
+			return response.JSON(409, util.DynMap{
+				"status":   "locked",
+				"message":  fmt.Sprintf("Dashboard is being edited by %s", editors.LockedBy.Login),
+				"lockedBy": editors.LockedBy,
+			})
+		}
+	}
+
+	if rsp := hs.submitDashboardForReview(c, dash, cmd.Message, folderIDs); rsp != nil {
+		return rsp
+	}
+
 	svc := dashboards.NewProvisioningService(hs.SQLStore)
 	provisioningData, err := svc.GetProvisionedDashboardDataByDashboardID(dash.Id)
 	if err != nil {
@@ -340,9 +396,29 @@ func (hs *HTTPServer) PostDashboard(c *models.ReqContext, cmd models.SaveDashboa
 	}
 
 	if err != nil {
+		if errors.Is(err, models.ErrDashboardVersionMismatch) {
+			if conflict := hs.dashboardVersionConflict(c, dash); conflict != nil {
+				return conflict
+			}
+		}
 		return hs.dashboardSaveErrorToApiResponse(err)
 	}
 
+	action := models.ChangeEventUpdated
+	switch {
+	case cmd.RestoredFrom > 0:
+		action = models.ChangeEventRestored
+	case dashboard.Version == 1:
+		action = models.ChangeEventCreated
+	}
+	audit.RecordChange(dashboardChangeEvent(c, dashboard, action))
+
+	if provisioningData != nil {
+		if err := hs.ProvisioningService.SyncDashboardUIUpdate(provisioningData, dashboard); err != nil {
+			return response.Error(500, "Dashboard saved but failed to sync it with its provisioning file", err)
+		}
+	}
+
 	if hs.Cfg.EditorsCanAdmin && newDashboard {
 		inFolder := cmd.FolderId > 0
 		err := dashSvc.MakeUserAdmin(cmd.OrgId, cmd.UserId, dashboard.Id, !inFolder)
@@ -402,6 +478,58 @@ func (hs *HTTPServer) dashboardSaveErrorToApiResponse(err error) response.Respon
 	return response.Error(500, "Failed to save dashboard", err)
 }
 
+// dashboardVersionConflict builds a version mismatch response that includes a three-way diff between the
+// version the save was based on, the dashboard being saved and the dashboard currently stored, so clients
+// can merge the changes. Returns nil if the diff can't be computed, leaving the caller to respond with
+// the plain version mismatch error.
+func (hs *HTTPServer) dashboardVersionConflict(c *models.ReqContext, mine *models.Dashboard) response.Response {
+	query := models.GetDashboardQuery{OrgId: c.OrgId, Id: mine.Id, Uid: mine.Uid}
+	if err := bus.Dispatch(&query); err != nil {
+		return nil
+	}
+	theirs := query.Result
+
+	guardian := guardian.New(theirs.Id, c.OrgId, c.SignedInUser)
+	if canSave, err := guardian.CanSave(); err != nil || !canSave {
+		return nil
+	}
+
+	baseQuery := models.GetDashboardVersionQuery{DashboardId: theirs.Id, Version: mine.Version, OrgId: c.OrgId}
+	if err := bus.Dispatch(&baseQuery); err != nil {
+		return nil
+	}
+	base := baseQuery.Result
+
+	// ids and versions differ by definition, compare the content only
+	for _, data := range []*simplejson.Json{base.Data, mine.Data, theirs.Data} {
+		data.Del("id")
+		data.Del("version")
+	}
+
+	diff, err := dashdiffs.CalculateThreeWayDiff(base.Data, mine.Data, theirs.Data)
+	if err != nil {
+		hs.log.Warn("Failed to calculate three-way diff for version conflict", "uid", theirs.Uid, "error", err)
+		return nil
+	}
+
+	return response.JSON(models.ErrDashboardVersionMismatch.StatusCode, util.DynMap{
+		"status":  models.ErrDashboardVersionMismatch.Status,
+		"message": models.ErrDashboardVersionMismatch.Error(),
+		"conflict": util.DynMap{
+			"baseVersion":   base.Version,
+			"theirsVersion": theirs.Version,
+			"base":          base.Data,
+			"theirs":        theirs.Data,
+			"diff": util.DynMap{
+				"mine":   diff.Mine,
+				"theirs": diff.Theirs,
+			},
+			"conflicts": diff.Conflicts,
+			"merged":    diff.Merged,
+		},
+	})
+}
+
 // GetHomeDashboard returns the home dashboard.
 func (hs *HTTPServer) GetHomeDashboard(c *models.ReqContext) response.Response {
 	prefsQuery := models.GetPreferencesWithDefaultsQuery{User: c.SignedInUser}
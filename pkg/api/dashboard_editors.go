@@ -0,0 +1,46 @@
+package api
+
+import (
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/guardian"
+)
+
+// GetDashboardEditors returns the users editing a dashboard and who holds its
+// edit lock
+// GET /api/dashboards/uid/:uid/editors
+func (hs *HTTPServer) GetDashboardEditors(c *models.ReqContext) response.Response {
+	dash, rsp := getDashboardHelper(c.OrgId, "", 0, c.Params(":uid"))
+	if rsp != nil {
+		return rsp
+	}
+
+	g := guardian.New(dash.Id, c.OrgId, c.SignedInUser)
+	if canView, err := g.CanView(); err != nil || !canView {
+		return dashboardGuardianResponse(err)
+	}
+
+	return response.JSON(200, hs.Live.GrafanaScope.Dashboards.DashboardEditors(c.OrgId, dash.Uid))
+}
+
+// TakeOverDashboardEditLock makes the signed in user the holder of the edit
+// lock of a dashboard, the other editors are told through Grafana Live
+// POST /api/dashboards/uid/:uid/editors/takeover
+func (hs *HTTPServer) TakeOverDashboardEditLock(c *models.ReqContext) response.Response {
+	dash, rsp := getDashboardHelper(c.OrgId, "", 0, c.Params(":uid"))
+	if rsp != nil {
+		return rsp
+	}
+
+	g := guardian.New(dash.Id, c.OrgId, c.SignedInUser)
+	if canEdit, err := g.CanEdit(); err != nil || !canEdit {
+		return dashboardGuardianResponse(err)
+	}
+
+	channel := hs.Live.GrafanaScope.Dashboards
+	if err := channel.TakeOverDashboardEditLock(c.OrgId, c.SignedInUser.ToUserDisplayDTO(), dash.Uid); err != nil {
+		hs.log.Warn("unable to broadcast edit lock takeover", "uid", dash.Uid, "error", err)
+	}
+
+	return response.JSON(200, channel.DashboardEditors(c.OrgId, dash.Uid))
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDashboardEditLock(t *testing.T) {
+	cmd := models.SaveDashboardCommand{
+		OrgId:  1,
+		UserId: 5,
+		Dashboard: simplejson.NewFromAny(map[string]interface{}{
+			"uid":   "uid",
+			"title": "Dash",
+		}),
+	}
+	existingDashboard := func() {
+		bus.AddHandler("test", func(query *models.GetDashboardQuery) error {
+			query.Result = &models.Dashboard{Id: 2, Uid: "uid", Title: "Dash"}
+			return nil
+		})
+	}
+	lockedByAnother := func(hs *HTTPServer) {
+		hs.Cfg.DashboardEditLock = true
+		err := hs.Live.GrafanaScope.Dashboards.TakeOverDashboardEditLock(1, &models.UserDisplayDTO{Id: 7, Login: "alice"}, "uid")
+		require.NoError(t, err)
+	}
+
+	mock := &dashboards.FakeDashboardService{
+		SaveDashboardResult: &models.Dashboard{Id: 2, Uid: "uid", Title: "Dash", Slug: "dash", Version: 2},
+	}
+	postDashboardScenario(t, "When the edit lock is held by another user, calling POST on", "/api/dashboards", "/api/dashboards", mock, cmd, func(sc *scenarioContext) {
+		existingDashboard()
+		callPostDashboard(sc)
+		assert.Equal(t, 409, sc.resp.Code)
+
+		result := sc.ToJSON()
+		assert.Equal(t, "locked", result.Get("status").MustString())
+		assert.Equal(t, "Dashboard is being edited by alice", result.Get("message").MustString())
+		assert.Empty(t, mock.SavedDashboards)
+	}, lockedByAnother)
+
+	mock = &dashboards.FakeDashboardService{
+		SaveDashboardResult: &models.Dashboard{Id: 2, Uid: "uid", Title: "Dash", Slug: "dash", Version: 2},
+	}
+	postDashboardScenario(t, "When the edit lock is disabled, calling POST on", "/api/dashboards", "/api/dashboards", mock, cmd, func(sc *scenarioContext) {
+		existingDashboard()
+		callPostDashboardShouldReturnSuccess(sc)
+	}, lockedByAnother, func(hs *HTTPServer) {
+		hs.Cfg.DashboardEditLock = false
+	})
+
+	mock = &dashboards.FakeDashboardService{
+		SaveDashboardResult: &models.Dashboard{Id: 2, Uid: "uid", Title: "Dash", Slug: "dash", Version: 2},
+	}
+	postDashboardScenario(t, "When the user took over the edit lock, calling POST on", "/api/dashboards", "/api/dashboards", mock, cmd, func(sc *scenarioContext) {
+		existingDashboard()
+		callPostDashboardShouldReturnSuccess(sc)
+	}, lockedByAnother, func(hs *HTTPServer) {
+		err := hs.Live.GrafanaScope.Dashboards.TakeOverDashboardEditLock(1, &models.UserDisplayDTO{Id: 5, Login: "bob"}, "uid")
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/guardian"
+)
+
+// summaryPanelKeys are the keys of the panels kept in their summaries, enough to lay out the dashboard before the
+// panels are fetched.
+var summaryPanelKeys = []string{"id", "type", "title", "gridPos", "collapsed", "libraryPanel", "repeat", "repeatDirection"}
+
+// summarizedPanelKey flags the panel summaries, so that the dashboards with panel summaries aren't saved.
+const summarizedPanelKey = "summarized"
+
+// summarizePanels returns the summaries of the panels of a dashboard, and of the panels of its collapsed rows.
+func summarizePanels(panels []interface{}) []interface{} {
+	summaries := make([]interface{}, 0, len(panels))
+	for _, p := range panels {
+		panel, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		summary := map[string]interface{}{summarizedPanelKey: true}
+		for _, key := range summaryPanelKeys {
+			if value, ok := panel[key]; ok {
+				summary[key] = value
+			}
+		}
+		if rowPanels, ok := panel["panels"].([]interface{}); ok {
+			summary["panels"] = summarizePanels(rowPanels)
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
+
+// findPanel returns the panel of the ID among the panels of a dashboard and of its collapsed rows, nil if not found.
+func findPanel(panels []interface{}, id int64) *simplejson.Json {
+	for _, p := range panels {
+		panel := simplejson.NewFromAny(p)
+		if panel.Get("id").MustInt64() == id {
+			return panel
+		}
+		if found := findPanel(panel.Get("panels").MustArray(), id); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// hasSummarizedPanels returns true if the dashboard has panel summaries, returned in place of its panels with the
+// summary panels mode of GetDashboard.
+func hasSummarizedPanels(dashboard *simplejson.Json) bool {
+	var summarized func(panels []interface{}) bool
+	summarized = func(panels []interface{}) bool {
+		for _, p := range panels {
+			panel := simplejson.NewFromAny(p)
+			if panel.Get(summarizedPanelKey).MustBool() || summarized(panel.Get("panels").MustArray()) {
+				return true
+			}
+		}
+		return false
+	}
+	return summarized(dashboard.Get("panels").MustArray())
+}
+
+// GetDashboardPanel handles GET /api/dashboards/uid/:uid/panels/:panelId. It returns the JSON of a panel of the
+// dashboard, for the dashboards fetched with the summaries of their panels.
+func (hs *HTTPServer) GetDashboardPanel(c *models.ReqContext) response.Response {
+	panelID, err := strconv.ParseInt(c.Params(":panelId"), 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Invalid panel ID", err)
+	}
+
+	dash, rsp := getDashboardHelper(c.OrgId, "", 0, c.Params(":uid"))
+	if rsp != nil {
+		return rsp
+	}
+
+	guardian := guardian.New(dash.Id, c.OrgId, c.SignedInUser)
+	if canView, err := guardian.CanView(); err != nil || !canView {
+		return dashboardGuardianResponse(err)
+	}
+
+	if err := hs.LibraryPanelService.LoadLibraryPanelsForDashboard(c, dash); err != nil {
+		return response.Error(http.StatusInternalServerError, "Error while loading library panels", err)
+	}
+
+	panel := findPanel(dash.Data.Get("panels").MustArray(), panelID)
+	if panel == nil {
+		return response.Error(http.StatusNotFound, "Panel not found", nil)
+	}
+
+	return response.JSON(http.StatusOK, dtos.DashboardPanel{
+		Panel:   panel,
+		Version: dash.Version,
+	})
+}
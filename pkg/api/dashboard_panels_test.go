@@ -0,0 +1,126 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/provisioning"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestDashboardPanelsAPIEndpoint(t *testing.T) {
+	setUp := func() {
+		dashboard, err := simplejson.NewJson([]byte(`{
+			"title": "Large dash",
+			"panels": [
+				{"id": 1, "type": "graph", "title": "Graph", "gridPos": {"x": 0, "y": 0, "w": 12, "h": 8},
+					"targets": [{"refId": "A"}]},
+				{"id": 2, "type": "row", "title": "Row", "collapsed": true, "panels": [
+					{"id": 3, "type": "stat", "title": "Stat", "options": {"colorMode": "value"}}
+				]}
+			]
+		}`))
+		require.NoError(t, err)
+		fakeDash := models.NewDashboardFromJson(dashboard)
+		fakeDash.Id = 1
+		fakeDash.Uid = "abcdefghi"
+		fakeDash.Version = 3
+
+		bus.AddHandler("test", func(query *models.GetDashboardQuery) error {
+			query.Result = fakeDash
+			return nil
+		})
+		bus.AddHandler("test", func(query *models.GetDashboardAclInfoListQuery) error {
+			viewerRole := models.ROLE_VIEWER
+			query.Result = []*models.DashboardAclInfoDTO{{Role: &viewerRole, Permission: models.PERMISSION_VIEW}}
+			return nil
+		})
+		bus.AddHandler("test", func(query *models.GetTeamsByUserQuery) error {
+			query.Result = []*models.TeamDTO{}
+			return nil
+		})
+		bus.AddHandler("test", func(query *models.IsReadOnlyFolderQuery) error {
+			return nil
+		})
+		bus.AddHandler("test", func(query *models.GetFolderReviewersQuery) error {
+			return nil
+		})
+	}
+
+	hs := &HTTPServer{
+		Cfg:                   setting.NewCfg(),
+		LibraryPanelService:   &mockLibraryPanelService{},
+		LibraryElementService: &mockLibraryElementService{},
+		ProvisioningService:   provisioning.NewProvisioningServiceMock(),
+	}
+
+	loggedInUserScenarioWithRole(t, "When calling GET with panel summaries on", "GET", "/api/dashboards/uid/abcdefghi",
+		"/api/dashboards/uid/:uid", models.ROLE_VIEWER, func(sc *scenarioContext) {
+			setUp()
+			sc.handlerFunc = hs.GetDashboard
+			sc.fakeReqWithParams("GET", sc.url, map[string]string{"panels": "summary"}).exec()
+			require.Equal(t, 200, sc.resp.Code)
+
+			dash := dtos.DashboardFullWithMeta{}
+			require.NoError(t, json.NewDecoder(sc.resp.Body).Decode(&dash))
+			assert.True(t, dash.Meta.PanelsSummarized)
+
+			panels := dash.Dashboard.Get("panels")
+			assert.Equal(t, "Graph", panels.GetIndex(0).Get("title").MustString())
+			assert.Equal(t, 12, panels.GetIndex(0).GetPath("gridPos", "w").MustInt())
+			assert.True(t, panels.GetIndex(0).Get("summarized").MustBool())
+			_, ok := panels.GetIndex(0).CheckGet("targets")
+			assert.False(t, ok)
+
+			rowPanel := panels.GetIndex(1).Get("panels").GetIndex(0)
+			assert.Equal(t, int64(3), rowPanel.Get("id").MustInt64())
+			_, ok = rowPanel.CheckGet("options")
+			assert.False(t, ok)
+		})
+
+	loggedInUserScenarioWithRole(t, "When calling GET on", "GET", "/api/dashboards/uid/abcdefghi/panels/3",
+		"/api/dashboards/uid/:uid/panels/:panelId", models.ROLE_VIEWER, func(sc *scenarioContext) {
+			setUp()
+			sc.handlerFunc = hs.GetDashboardPanel
+			sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+			require.Equal(t, 200, sc.resp.Code)
+
+			var panel dtos.DashboardPanel
+			require.NoError(t, json.NewDecoder(sc.resp.Body).Decode(&panel))
+			assert.Equal(t, 3, panel.Version)
+			assert.Equal(t, "Stat", panel.Panel.Get("title").MustString())
+			assert.Equal(t, "value", panel.Panel.GetPath("options", "colorMode").MustString())
+		})
+
+	loggedInUserScenarioWithRole(t, "When calling GET for an unknown panel on", "GET", "/api/dashboards/uid/abcdefghi/panels/4",
+		"/api/dashboards/uid/:uid/panels/:panelId", models.ROLE_VIEWER, func(sc *scenarioContext) {
+			setUp()
+			sc.handlerFunc = hs.GetDashboardPanel
+			sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+			assert.Equal(t, 404, sc.resp.Code)
+		})
+
+	loggedInUserScenarioWithRole(t, "When calling GET for an invalid panel on", "GET", "/api/dashboards/uid/abcdefghi/panels/graph",
+		"/api/dashboards/uid/:uid/panels/:panelId", models.ROLE_VIEWER, func(sc *scenarioContext) {
+			sc.handlerFunc = hs.GetDashboardPanel
+			sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+			assert.Equal(t, 400, sc.resp.Code)
+		})
+}
+
+func TestHasSummarizedPanels(t *testing.T) {
+	dashboard, err := simplejson.NewJson([]byte(`{"panels": [{"id": 1, "panels": [{"id": 2, "summarized": true}]}]}`))
+	require.NoError(t, err)
+	assert.True(t, hasSummarizedPanels(dashboard))
+
+	dashboard, err = simplejson.NewJson([]byte(`{"panels": [{"id": 1, "panels": [{"id": 2}]}]}`))
+	require.NoError(t, err)
+	assert.False(t, hasSummarizedPanels(dashboard))
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/audit"
 	"github.com/grafana/grafana/pkg/services/guardian"
 )
 
@@ -57,7 +58,7 @@ func (hs *HTTPServer) UpdateDashboardPermissions(c *models.ReqContext, apiCmd dt
 
 	dashID := c.ParamsInt64(":dashboardId")
 
-	_, rsp := getDashboardHelper(c.OrgId, "", dashID, "")
+	dash, rsp := getDashboardHelper(c.OrgId, "", dashID, "")
 	if rsp != nil {
 		return rsp
 	}
@@ -70,14 +71,15 @@ func (hs *HTTPServer) UpdateDashboardPermissions(c *models.ReqContext, apiCmd dt
 	var items []*models.DashboardAcl
 	for _, item := range apiCmd.Items {
 		items = append(items, &models.DashboardAcl{
-			OrgID:       c.OrgId,
-			DashboardID: dashID,
-			UserID:      item.UserID,
-			TeamID:      item.TeamID,
-			Role:        item.Role,
-			Permission:  item.Permission,
-			Created:     time.Now(),
-			Updated:     time.Now(),
+			OrgID:         c.OrgId,
+			DashboardID:   dashID,
+			UserID:        item.UserID,
+			TeamID:        item.TeamID,
+			ExternalGroup: item.ExternalGroup,
+			Role:          item.Role,
+			Permission:    item.Permission,
+			Created:       time.Now(),
+			Updated:       time.Now(),
 		})
 	}
 
@@ -106,15 +108,22 @@ func (hs *HTTPServer) UpdateDashboardPermissions(c *models.ReqContext, apiCmd dt
 		}
 		return response.Error(500, "Failed to create permission", err)
 	}
+	audit.RecordChange(permissionsChangeEvent(c, dash.Id, dash.Uid, dash.Title))
 
 	return response.Success("Dashboard permissions updated")
 }
 
 func validatePermissionsUpdate(apiCmd dtos.UpdateDashboardAclCommand) error {
 	for _, item := range apiCmd.Items {
-		if (item.UserID > 0 || item.TeamID > 0) && item.Role != nil {
+		if (item.UserID > 0 || item.TeamID > 0 || item.ExternalGroup != "") && item.Role != nil {
 			return models.ErrPermissionsWithRoleNotAllowed
 		}
+		if item.ExternalGroup != "" && (item.UserID > 0 || item.TeamID > 0) {
+			return models.ErrPermissionsWithExternalGroup
+		}
+		if len(item.ExternalGroup) > models.MaxExternalGroupLength {
+			return models.ErrPermissionsExternalGroupTooLong
+		}
 	}
 	return nil
 }
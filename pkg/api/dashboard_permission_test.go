@@ -227,6 +227,37 @@ func TestDashboardPermissionAPIEndpoint(t *testing.T) {
 			}
 		})
 
+		t.Run("When trying to update external group permissions with a user or team", func(t *testing.T) {
+			cmds := []dtos.UpdateDashboardAclCommand{
+				{
+					Items: []dtos.DashboardAclUpdateItem{
+						{UserID: 1000, ExternalGroup: "cn=sre,ou=groups,dc=grafana,dc=org", Permission: models.PERMISSION_ADMIN},
+					},
+				},
+				{
+					Items: []dtos.DashboardAclUpdateItem{
+						{TeamID: 1000, ExternalGroup: "cn=sre,ou=groups,dc=grafana,dc=org", Permission: models.PERMISSION_ADMIN},
+					},
+				},
+			}
+
+			for _, cmd := range cmds {
+				updateDashboardPermissionScenario(t, updatePermissionContext{
+					desc:         "When calling POST on",
+					url:          "/api/dashboards/id/1/permissions",
+					routePattern: "/api/dashboards/id/:id/permissions",
+					cmd:          cmd,
+					fn: func(sc *scenarioContext) {
+						callUpdateDashboardPermissions(t, sc)
+						assert.Equal(t, 400, sc.resp.Code)
+						respJSON, err := jsonMap(sc.resp.Body.Bytes())
+						require.NoError(t, err)
+						assert.Equal(t, models.ErrPermissionsWithExternalGroup.Error(), respJSON["error"])
+					},
+				}, hs)
+			}
+		})
+
 		t.Run("When trying to override inherited permissions with lower precedence", func(t *testing.T) {
 			origNewGuardian := guardian.New
 			t.Cleanup(func() {
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"path/filepath"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/guardian"
+)
+
+// GetProvisionedDashboardDrifts returns the provisioned dashboards of the organization which changes from the UI are
+// kept as overlay instead of their provisioning file.
+func (hs *HTTPServer) GetProvisionedDashboardDrifts(c *models.ReqContext) response.Response {
+	query := models.GetProvisionedDashboardOverlaysQuery{OrgId: c.OrgId}
+	if err := bus.Dispatch(&query); err != nil {
+		return response.Error(500, "Failed to get provisioned dashboards", err)
+	}
+
+	result := make([]dtos.ProvisionedDashboardDrift, 0, len(query.Result))
+	if len(query.Result) == 0 {
+		return response.JSON(200, result)
+	}
+
+	provisioningByDashboardID := make(map[int64]*models.DashboardProvisioning, len(query.Result))
+	dashboardIDs := make([]int64, 0, len(query.Result))
+	for _, provisioning := range query.Result {
+		provisioningByDashboardID[provisioning.DashboardId] = provisioning
+		dashboardIDs = append(dashboardIDs, provisioning.DashboardId)
+	}
+
+	dashQuery := models.GetDashboardsQuery{DashboardIds: dashboardIDs}
+	if err := bus.Dispatch(&dashQuery); err != nil {
+		return response.Error(500, "Failed to get provisioned dashboards", err)
+	}
+
+	for _, dash := range dashQuery.Result {
+		g := guardian.New(dash.Id, c.OrgId, c.SignedInUser)
+		if canView, err := g.CanView(); err != nil || !canView {
+			continue
+		}
+
+		result = append(result, hs.provisionedDashboardDrift(dash, provisioningByDashboardID[dash.Id]))
+	}
+
+	return response.JSON(200, result)
+}
+
+// GetProvisionedDashboardDrift returns the changes from the UI of a provisioned dashboard together with the
+// dashboard from its provisioning file.
+func (hs *HTTPServer) GetProvisionedDashboardDrift(c *models.ReqContext) response.Response {
+	dash, rsp := getDashboardHelper(c.OrgId, "", 0, c.Params(":uid"))
+	if rsp != nil {
+		return rsp
+	}
+
+	g := guardian.New(dash.Id, c.OrgId, c.SignedInUser)
+	if canView, err := g.CanView(); err != nil || !canView {
+		return dashboardGuardianResponse(err)
+	}
+
+	provisioning, rsp := hs.getProvisionedDashboardOverlay(dash)
+	if rsp != nil {
+		return rsp
+	}
+
+	provisioned, err := hs.ProvisioningService.GetProvisionedDashboard(provisioning)
+	if err != nil {
+		return response.Error(500, "Failed to read provisioned dashboard", err)
+	}
+
+	// make sure db version is in sync with json model version
+	dash.Data.Set("version", dash.Version)
+
+	return response.JSON(200, dtos.ProvisionedDashboardDriftWithSource{
+		ProvisionedDashboardDrift: hs.provisionedDashboardDrift(dash, provisioning),
+		Dashboard:                 dash.Data,
+		Provisioned:               provisioned,
+	})
+}
+
+// DeleteProvisionedDashboardDrift discards the changes from the UI of a provisioned dashboard, it's provisioned
+// again from its provisioning file on the next update of its provisioner.
+func (hs *HTTPServer) DeleteProvisionedDashboardDrift(c *models.ReqContext) response.Response {
+	dash, rsp := getDashboardHelper(c.OrgId, "", 0, c.Params(":uid"))
+	if rsp != nil {
+		return rsp
+	}
+
+	g := guardian.New(dash.Id, c.OrgId, c.SignedInUser)
+	if canSave, err := g.CanSave(); err != nil || !canSave {
+		return dashboardGuardianResponse(err)
+	}
+
+	provisioning, rsp := hs.getProvisionedDashboardOverlay(dash)
+	if rsp != nil {
+		return rsp
+	}
+
+	// clearing the check sum makes the provisioner save the dashboard from its file again
+	cmd := models.UpdateDashboardProvisioningCommand{
+		DashboardId: provisioning.DashboardId,
+		Updated:     provisioning.Updated,
+	}
+	if err := bus.Dispatch(&cmd); err != nil {
+		return response.Error(500, "Failed to discard dashboard changes", err)
+	}
+
+	return response.Success("Dashboard changes discarded")
+}
+
+func (hs *HTTPServer) getProvisionedDashboardOverlay(dash *models.Dashboard) (*models.DashboardProvisioning, response.Response) {
+	svc := dashboards.NewProvisioningService(hs.SQLStore)
+	provisioning, err := svc.GetProvisionedDashboardDataByDashboardID(dash.Id)
+	if err != nil {
+		return nil, response.Error(500, "Error while checking if dashboard is provisioned", err)
+	}
+
+	if provisioning == nil || !provisioning.Overlay {
+		return nil, response.Error(404, "Dashboard has no changes from the UI kept as overlay", nil)
+	}
+
+	return provisioning, nil
+}
+
+func (hs *HTTPServer) provisionedDashboardDrift(dash *models.Dashboard, provisioning *models.DashboardProvisioning) dtos.ProvisionedDashboardDrift {
+	drift := dtos.ProvisionedDashboardDrift{
+		Id:          dash.Id,
+		Uid:         dash.Uid,
+		Title:       dash.Title,
+		Url:         dash.GetUrl(),
+		Version:     dash.Version,
+		Provisioner: provisioning.Name,
+	}
+
+	// the path is only for better UX, it's left empty if it can't be resolved
+	if externalID, err := filepath.Rel(
+		hs.ProvisioningService.GetDashboardProvisionerResolvedPath(provisioning.Name),
+		provisioning.ExternalId,
+	); err == nil {
+		drift.ProvisionedExternalId = externalID
+	}
+
+	return drift
+}
@@ -0,0 +1,271 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/variables"
+)
+
+// mixedDatasource is the data source of the panels of which each query has its own data source.
+const mixedDatasource = "-- Mixed --"
+
+// builtInDatasources are the names and UIDs of the data sources which aren't data sources of the org, they're kept
+// as they are in the resolved dashboards.
+var builtInDatasources = map[string]bool{
+	"-- Grafana --":     true,
+	"grafana":           true,
+	"-- Dashboard --":   true,
+	expr.DatasourceName: true,
+	expr.DatasourceUID:  true,
+}
+
+// ResolveDashboard handles POST /api/dashboards/uid/:uid/resolve. It returns the model of the dashboard resolved for
+// a selection of the variables and the time range, like the frontend shows it: the repeated panels and rows
+// expanded, the variables of the titles and queries interpolated, and the data sources of the panels and queries
+// replaced by their final UIDs. It's meant for the clients which don't run the dashboards themselves, e.g. the
+// reports and the previews of the alerts.
+func (hs *HTTPServer) ResolveDashboard(c *models.ReqContext, cmd dtos.ResolveDashboardCommand) response.Response {
+	dash, rsp := getDashboardHelper(c.OrgId, "", 0, c.Params(":uid"))
+	if rsp != nil {
+		return rsp
+	}
+
+	guardian := guardian.New(dash.Id, c.OrgId, c.SignedInUser)
+	if canView, err := guardian.CanView(); err != nil || !canView {
+		return dashboardGuardianResponse(err)
+	}
+
+	if err := hs.LibraryPanelService.LoadLibraryPanelsForDashboard(c, dash); err != nil {
+		return response.Error(http.StatusInternalServerError, "Error while loading library panels", err)
+	}
+
+	values, err := hs.VariablesService.ResolveValues(dash.Data, variables.DashboardState{Variables: cmd.Variables})
+	if err != nil {
+		if errors.Is(err, variables.ErrUnknownVariable) || errors.Is(err, variables.ErrInvalidVariableValue) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to resolve the variables", err)
+	}
+
+	from, to := cmd.From, cmd.To
+	if from == "" {
+		from = dash.Data.GetPath("time", "from").MustString("now-6h")
+	}
+	if to == "" {
+		to = dash.Data.GetPath("time", "to").MustString("now")
+	}
+	timeRange := hs.newTimeRange(c, from, to)
+
+	r := &dashboardResolver{
+		orgID:  c.OrgId,
+		user:   c.SignedInUser,
+		values: values,
+		builtIns: map[string][]string{
+			"__from":      {strconv.FormatInt(timeRange.GetFromAsMsEpoch(), 10)},
+			"__to":        {strconv.FormatInt(timeRange.GetToAsMsEpoch(), 10)},
+			"__dashboard": {dash.Title},
+		},
+		datasources: map[string]*models.DataSource{},
+	}
+	panels := dashboards.ExpandRepeats(dash.Data.Get("panels").MustArray(), values)
+	for _, p := range panels {
+		r.resolvePanel(p)
+	}
+	dash.Data.Set("panels", panels)
+
+	for _, v := range dash.Data.GetPath("templating", "list").MustArray() {
+		variable := simplejson.NewFromAny(v)
+		variableValues := values[variable.Get("name").MustString()]
+		var value interface{} = variableValues
+		if !variable.Get("multi").MustBool() && len(variableValues) == 1 {
+			value = variableValues[0]
+		}
+		variable.Set("current", map[string]interface{}{"text": strings.Join(variableValues, " + "), "value": value})
+	}
+	dash.Data.Set("time", map[string]interface{}{"from": from, "to": to})
+
+	return response.JSON(http.StatusOK, dtos.ResolvedDashboard{
+		Dashboard: dash.Data,
+		Variables: values,
+		From:      timeRange.GetFromAsMsEpoch(),
+		To:        timeRange.GetToAsMsEpoch(),
+		Version:   dash.Version,
+		Warnings:  r.warnings,
+	})
+}
+
+// dashboardResolver resolves the panels of a dashboard for the values of its variables.
+type dashboardResolver struct {
+	orgID int64
+	user  *models.SignedInUser
+	// values are the values of the variables of the dashboard, and builtIns those of the built-in variables
+	values   map[string][]string
+	builtIns map[string][]string
+	// datasources are the data sources looked up by reference
+	datasources map[string]*models.DataSource
+	warnings    []string
+}
+
+// resolvePanel interpolates the variables of the title and the queries of a panel, and replaces the data sources of
+// the panel and its queries by their final UIDs.
+func (r *dashboardResolver) resolvePanel(p interface{}) {
+	panel := simplejson.NewFromAny(p)
+	scope := r.scope(panel)
+	panel.Set("title", r.interpolate(panel.Get("title").MustString(), scope, ""))
+
+	if panel.Get("type").MustString() == "row" {
+		for _, nested := range panel.Get("panels").MustArray() {
+			r.resolvePanel(nested)
+		}
+		return
+	}
+
+	panelDS, err := r.datasource(panel.Get("datasource").Interface(), scope)
+	if err != nil {
+		r.warnings = append(r.warnings, fmt.Sprintf("Panel %d: %s", panel.Get("id").MustInt64(), err))
+		return
+	}
+	if panelDS != nil {
+		panel.Set("datasource", datasourceRef(panelDS))
+	}
+
+	for _, t := range panel.Get("targets").MustArray() {
+		target := simplejson.NewFromAny(t)
+		ds := panelDS
+		if ref, ok := target.CheckGet("datasource"); ok && ref.Interface() != nil {
+			if ds, err = r.datasource(ref.Interface(), scope); err != nil {
+				r.warnings = append(r.warnings, fmt.Sprintf("Panel %d, query %s: %s", panel.Get("id").MustInt64(),
+					target.Get("refId").MustString(), err))
+				continue
+			}
+		}
+
+		var datasourceType string
+		if ds != nil {
+			datasourceType = ds.Type
+		}
+		interpolateStrings(target.Interface(), func(s string) string {
+			return r.interpolate(s, scope, datasourceType)
+		})
+		if ds != nil {
+			target.Set("datasource", datasourceRef(ds))
+		}
+	}
+}
+
+// scope returns the values of the variables of a panel, the values of its scoped variables replacing those of the
+// dashboard.
+func (r *dashboardResolver) scope(panel *simplejson.Json) map[string][]string {
+	scopedVars := panel.Get("scopedVars").MustMap()
+	scope := make(map[string][]string, len(r.values)+len(r.builtIns)+len(scopedVars))
+	for name, values := range r.values {
+		scope[name] = values
+	}
+	for name, values := range r.builtIns {
+		scope[name] = values
+	}
+	for name, scopedVar := range scopedVars {
+		var values []string
+		switch v := simplejson.NewFromAny(scopedVar).Get("value").Interface().(type) {
+		case string:
+			values = []string{v}
+		case []interface{}:
+			for _, item := range v {
+				values = append(values, fmt.Sprint(item))
+			}
+		}
+		scope[name] = values
+	}
+	return scope
+}
+
+// interpolate replaces the references to the variables of the scope in a string, with the values formatted for the
+// type of the data source. The references to the unknown variables, e.g. the macros of the data sources, and to the
+// variables without values are kept as they are.
+func (r *dashboardResolver) interpolate(s string, scope map[string][]string, datasourceType string) string {
+	if !strings.Contains(s, "$") {
+		return s
+	}
+	return chainedVariableRef.ReplaceAllStringFunc(s, func(ref string) string {
+		match := chainedVariableRef.FindStringSubmatch(ref)
+		name := match[1] + match[2]
+		values, ok := scope[name]
+		if !ok || len(values) == 0 {
+			return ref
+		}
+		if strings.HasPrefix(name, "__") {
+			return strings.Join(values, ",")
+		}
+		return formatChainedValues(values, match[3], datasourceType)
+	})
+}
+
+// datasource returns the data source of a reference of a panel or a query: its name, or an object with its UID, with
+// the variables interpolated, or nil for the default data source. It returns nil for the mixed and the built-in data
+// sources.
+func (r *dashboardResolver) datasource(ref interface{}, scope map[string][]string) (*models.DataSource, error) {
+	var name, uid string
+	switch v := ref.(type) {
+	case string:
+		name = r.interpolate(v, scope, "")
+	case map[string]interface{}:
+		uid = r.interpolate(simplejson.NewFromAny(v).Get("uid").MustString(), scope, "")
+	}
+	if name == mixedDatasource || uid == mixedDatasource || builtInDatasources[name] || builtInDatasources[uid] {
+		return nil, nil
+	}
+
+	key := "uid:" + uid
+	if uid == "" {
+		key = "name:" + name
+	}
+	if ds, ok := r.datasources[key]; ok {
+		return ds, nil
+	}
+
+	var ds *models.DataSource
+	switch {
+	case uid != "":
+		query := &models.GetDataSourceQuery{Uid: uid, OrgId: r.orgID}
+		if err := bus.Dispatch(query); err != nil {
+			return nil, fmt.Errorf("data source %q: %w", uid, err)
+		}
+		ds = query.Result
+	case name != "" && name != "default":
+		query := &models.GetDataSourceQuery{Name: name, OrgId: r.orgID}
+		err := bus.Dispatch(query)
+		// the data source variables may have the UIDs of the data sources as values
+		if errors.Is(err, models.ErrDataSourceNotFound) {
+			query = &models.GetDataSourceQuery{Uid: name, OrgId: r.orgID}
+			err = bus.Dispatch(query)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("data source %q: %w", name, err)
+		}
+		ds = query.Result
+	default:
+		query := &models.GetDefaultDataSourceQuery{OrgId: r.orgID, User: r.user}
+		if err := bus.Dispatch(query); err != nil {
+			return nil, fmt.Errorf("default data source: %w", err)
+		}
+		ds = query.Result
+	}
+	r.datasources[key] = ds
+	return ds, nil
+}
+
+func datasourceRef(ds *models.DataSource) map[string]interface{} {
+	return map[string]interface{}{"uid": ds.Uid, "type": ds.Type}
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/variables"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestResolveDashboard(t *testing.T) {
+	setUp := func() {
+		dashboard, err := simplejson.NewJson([]byte(`{
+			"title": "Hosts",
+			"time": {"from": "now-1h", "to": "now"},
+			"templating": {"list": [
+				{"name": "host", "type": "query", "multi": true, "includeAll": true,
+					"current": {"value": ["$__all"]},
+					"options": [{"value": "$__all"}, {"value": "web-1"}, {"value": "web-2"}, {"value": "web-3"}]},
+				{"name": "region", "type": "custom", "multi": true, "current": {"value": ["eu", "us"]},
+					"options": [{"value": "eu"}, {"value": "us"}]},
+				{"name": "ds", "type": "datasource", "current": {"value": "Prometheus"}}
+			]},
+			"panels": [
+				{"id": 1, "type": "timeseries", "title": "CPU $host", "repeat": "host", "repeatDirection": "v",
+					"gridPos": {"x": 0, "y": 0, "w": 12, "h": 8}, "datasource": "$ds",
+					"targets": [{"refId": "A", "expr": "cpu{host=\"$host\", region=\"$region\"}[$__interval]"}]},
+				{"id": 2, "type": "table", "title": "Deployments", "gridPos": {"x": 0, "y": 8, "w": 24, "h": 8},
+					"datasource": "-- Mixed --",
+					"targets": [
+						{"refId": "A", "datasource": {"uid": "pg"}, "rawSql": "SELECT * FROM deploys WHERE host IN ($host) AND $__timeFilter(time)"},
+						{"refId": "B", "datasource": {"uid": "missing"}}
+					]},
+				{"id": 3, "type": "row", "title": "Region $region", "repeat": "region", "collapsed": false,
+					"gridPos": {"x": 0, "y": 16, "w": 24, "h": 1}},
+				{"id": 4, "type": "stat", "title": "Requests", "gridPos": {"x": 0, "y": 17, "w": 6, "h": 4},
+					"targets": [{"refId": "A", "expr": "requests{region=\"$region\"}"}]}
+			]
+		}`))
+		require.NoError(t, err)
+		fakeDash := models.NewDashboardFromJson(dashboard)
+		fakeDash.Id = 1
+		fakeDash.Uid = "abcdefghi"
+
+		bus.AddHandler("test", func(query *models.GetDashboardQuery) error {
+			query.Result = fakeDash
+			return nil
+		})
+		bus.AddHandler("test", func(query *models.GetDashboardAclInfoListQuery) error {
+			viewerRole := models.ROLE_VIEWER
+			query.Result = []*models.DashboardAclInfoDTO{{Role: &viewerRole, Permission: models.PERMISSION_VIEW}}
+			return nil
+		})
+		bus.AddHandler("test", func(query *models.GetTeamsByUserQuery) error {
+			query.Result = []*models.TeamDTO{}
+			return nil
+		})
+		bus.AddHandler("test", func(query *models.GetDataSourceQuery) error {
+			switch {
+			case query.Name == "Prometheus":
+				query.Result = &models.DataSource{Id: 1, Uid: "prom", Name: "Prometheus", Type: "prometheus"}
+			case query.Uid == "pg":
+				query.Result = &models.DataSource{Id: 2, Uid: "pg", Name: "Postgres", Type: "postgres"}
+			default:
+				return models.ErrDataSourceNotFound
+			}
+			return nil
+		})
+		bus.AddHandler("test", func(query *models.GetDefaultDataSourceQuery) error {
+			query.Result = &models.DataSource{Id: 1, Uid: "prom", Name: "Prometheus", Type: "prometheus"}
+			return nil
+		})
+	}
+
+	hs := &HTTPServer{
+		Cfg:                 setting.NewCfg(),
+		LibraryPanelService: &mockLibraryPanelService{},
+		VariablesService:    &variables.Service{},
+		log:                 log.New("test"),
+	}
+
+	resolve := func(t *testing.T, cmd dtos.ResolveDashboardCommand) (int, dtos.ResolvedDashboard) {
+		t.Helper()
+
+		var code int
+		var resolved dtos.ResolvedDashboard
+		loggedInUserScenarioWithRole(t, "When calling POST on", "GET", "/api/dashboards/uid/abcdefghi/resolve",
+			"/api/dashboards/uid/:uid/resolve", models.ROLE_VIEWER, func(sc *scenarioContext) {
+				setUp()
+				sc.handlerFunc = func(c *models.ReqContext) response.Response {
+					return hs.ResolveDashboard(c, cmd)
+				}
+				sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+				code = sc.resp.Code
+				if code == 200 {
+					require.NoError(t, json.NewDecoder(sc.resp.Body).Decode(&resolved))
+				}
+			})
+		return code, resolved
+	}
+
+	t.Run("Should expand the repeats and interpolate the queries", func(t *testing.T) {
+		code, resolved := resolve(t, dtos.ResolveDashboardCommand{
+			Variables: map[string]interface{}{"host": []interface{}{"web-1", "web-2"}},
+		})
+		require.Equal(t, 200, code)
+		assert.Equal(t, []string{"web-1", "web-2"}, resolved.Variables["host"])
+		assert.Equal(t, int64(3600000), resolved.To-resolved.From)
+
+		panels := resolved.Dashboard.Get("panels")
+		require.Len(t, panels.MustArray(), 7)
+
+		// the repeated panels are below each other
+		first, second := panels.GetIndex(0), panels.GetIndex(1)
+		assert.Equal(t, "CPU web-1", first.Get("title").MustString())
+		assert.Equal(t, "CPU web-2", second.Get("title").MustString())
+		assert.Equal(t, int64(7), second.Get("id").MustInt64())
+		assert.Equal(t, int64(1), second.Get("repeatPanelId").MustInt64())
+		assert.Equal(t, 8, second.GetPath("gridPos", "y").MustInt())
+		assert.Equal(t, `cpu{host="web-2", region="(eu|us)"}[$__interval]`,
+			second.Get("targets").GetIndex(0).Get("expr").MustString())
+		assert.Equal(t, "prom", second.GetPath("datasource", "uid").MustString())
+		assert.Equal(t, "prometheus", second.Get("targets").GetIndex(0).GetPath("datasource", "type").MustString())
+
+		// the queries of the mixed panels have their own data sources
+		mixed := panels.GetIndex(2)
+		assert.Equal(t, 16, mixed.GetPath("gridPos", "y").MustInt())
+		assert.Equal(t, "SELECT * FROM deploys WHERE host IN ('web-1','web-2') AND $__timeFilter(time)",
+			mixed.Get("targets").GetIndex(0).Get("rawSql").MustString())
+		assert.Equal(t, "pg", mixed.Get("targets").GetIndex(0).GetPath("datasource", "uid").MustString())
+		assert.Len(t, resolved.Warnings, 1)
+
+		// the repeated row is repeated with its panels
+		row := panels.GetIndex(3)
+		assert.Equal(t, "Region eu", row.Get("title").MustString())
+		assert.Equal(t, 24, row.GetPath("gridPos", "y").MustInt())
+		assert.Equal(t, `requests{region="eu"}`, panels.GetIndex(4).Get("targets").GetIndex(0).Get("expr").MustString())
+		assert.Equal(t, "Region us", panels.GetIndex(5).Get("title").MustString())
+		assert.Equal(t, 29, panels.GetIndex(5).GetPath("gridPos", "y").MustInt())
+		assert.Equal(t, `requests{region="us"}`, panels.GetIndex(6).Get("targets").GetIndex(0).Get("expr").MustString())
+		assert.Equal(t, 30, panels.GetIndex(6).GetPath("gridPos", "y").MustInt())
+	})
+
+	t.Run("Should expand the All value to the options of the variable", func(t *testing.T) {
+		code, resolved := resolve(t, dtos.ResolveDashboardCommand{})
+		require.Equal(t, 200, code)
+		assert.Equal(t, []string{"web-1", "web-2", "web-3"}, resolved.Variables["host"])
+		assert.Equal(t, "CPU web-3", resolved.Dashboard.Get("panels").GetIndex(2).Get("title").MustString())
+	})
+
+	t.Run("Should reject an invalid value of a variable", func(t *testing.T) {
+		code, _ := resolve(t, dtos.ResolveDashboardCommand{Variables: map[string]interface{}{"region": "asia"}})
+		assert.Equal(t, 400, code)
+	})
+}
@@ -0,0 +1,462 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// GET /api/folders/:uid/reviewers
+func (hs *HTTPServer) GetFolderReviewers(c *models.ReqContext) response.Response {
+	s := dashboards.NewFolderService(c.OrgId, c.SignedInUser, hs.SQLStore)
+	folder, err := s.GetFolderByUID(c.Params(":uid"))
+	if err != nil {
+		return ToFolderErrorResponse(err)
+	}
+
+	query := models.GetFolderReviewersQuery{OrgId: c.OrgId, FolderId: folder.Id}
+	if err := bus.Dispatch(&query); err != nil {
+		return response.Error(500, "Failed to get folder reviewers", err)
+	}
+	return response.JSON(200, query.Result)
+}
+
+// PUT /api/folders/:uid/reviewers
+func (hs *HTTPServer) SetFolderReviewers(c *models.ReqContext, form dtos.SetFolderReviewersForm) response.Response {
+	s := dashboards.NewFolderService(c.OrgId, c.SignedInUser, hs.SQLStore)
+	folder, err := s.GetFolderByUID(c.Params(":uid"))
+	if err != nil {
+		return ToFolderErrorResponse(err)
+	}
+
+	cmd := models.SetFolderReviewersCommand{OrgId: c.OrgId, FolderId: folder.Id, UserIds: []int64{}}
+	for _, userID := range form.UserIds {
+		query := models.GetSignedInUserQuery{UserId: userID, OrgId: c.OrgId}
+		if err := bus.DispatchCtx(c.Req.Context(), &query); err != nil {
+			if errors.Is(err, models.ErrUserNotFound) {
+				return response.Error(400, fmt.Sprintf("User %d not found", userID), err)
+			}
+			return response.Error(500, "Failed to get user", err)
+		}
+		// the users who aren't members of the org have no org
+		if query.Result.OrgId != c.OrgId {
+			return response.Error(400, fmt.Sprintf("User %d is not a member of the organization", userID), nil)
+		}
+
+		// the reviewers publish the approved revisions, so they have to be able to save the dashboards
+		canSave, err := guardian.New(folder.Id, c.OrgId, query.Result).CanSave()
+		if err != nil {
+			return response.Error(500, "Failed to check folder permissions", err)
+		}
+		if !canSave {
+			return response.Error(400, fmt.Sprintf("User %d can't save the dashboards of the folder", userID), nil)
+		}
+		cmd.UserIds = append(cmd.UserIds, userID)
+	}
+
+	if err := bus.Dispatch(&cmd); err != nil {
+		return response.Error(500, "Failed to set folder reviewers", err)
+	}
+	return response.Success("Folder reviewers updated")
+}
+
+// GET /api/dashboards/revisions
+func GetDashboardRevisions(c *models.ReqContext) response.Response {
+	status := models.DashboardRevisionStatus(c.Query("status"))
+	switch status {
+	case "":
+		status = models.DashboardRevisionPending
+	case models.DashboardRevisionPending, models.DashboardRevisionApproved, models.DashboardRevisionRejected:
+	default:
+		return response.Error(400, fmt.Sprintf("Invalid status %q", status), nil)
+	}
+
+	// the org admins review the revisions of all the protected folders
+	query := models.GetDashboardRevisionsQuery{OrgId: c.OrgId, Status: status, UserId: c.UserId}
+	if c.OrgRole == models.ROLE_ADMIN {
+		query.UserId = 0
+	}
+	if err := bus.Dispatch(&query); err != nil {
+		return response.Error(500, "Failed to get dashboard revisions", err)
+	}
+	return response.JSON(200, query.Result)
+}
+
+// GET /api/dashboards/revisions/:revisionId
+func GetDashboardRevision(c *models.ReqContext) response.Response {
+	revision, rsp := getDashboardRevision(c, false)
+	if rsp != nil {
+		return rsp
+	}
+
+	return response.JSON(200, models.DashboardRevisionDTO{
+		Id:             revision.Id,
+		DashboardId:    revision.DashboardId,
+		DashboardUid:   revision.DashboardUid,
+		FolderId:       revision.FolderId,
+		ReviewFolderId: revision.ReviewFolderId,
+		Title:          revision.Title,
+		Message:        revision.Message,
+		Status:         revision.Status,
+		CreatedBy:      getUserLogin(revision.CreatedBy),
+		Created:        revision.Created,
+		ReviewedBy:     reviewerLogin(revision),
+		Reviewed:       reviewedTime(revision),
+		ReviewComment:  revision.ReviewComment,
+		Data:           revision.Data,
+	})
+}
+
+// POST /api/dashboards/revisions/:revisionId/approve
+func (hs *HTTPServer) ApproveDashboardRevision(c *models.ReqContext, form dtos.ReviewDashboardRevisionForm) response.Response {
+	revision, rsp := getDashboardRevision(c, true)
+	if rsp != nil {
+		return rsp
+	}
+
+	dash := models.NewDashboardFromJson(revision.Data)
+	dash.FolderId = revision.FolderId
+	folderIDs, err := savedDashboardFolderIDs(c.OrgId, dash)
+	if err != nil {
+		return response.Error(500, "Failed to get dashboard", err)
+	}
+	if rsp := hs.checkReadOnlyFolders(c, folderIDs...); rsp != nil {
+		return rsp
+	}
+
+	svc := dashboards.NewProvisioningService(hs.SQLStore)
+	provisioningData, err := svc.GetProvisionedDashboardDataByDashboardID(dash.Id)
+	if err != nil {
+		return response.Error(500, "Error while checking if dashboard is provisioned", err)
+	}
+
+	allowUiUpdate := true
+	if provisioningData != nil {
+		allowUiUpdate = hs.ProvisioningService.GetAllowUIUpdatesFromConfig(provisioningData.Name)
+	}
+
+	if err := hs.LibraryPanelService.CleanLibraryPanelsForDashboard(dash); err != nil {
+		return response.Error(500, "Error while cleaning library panels", err)
+	}
+
+	// the revision is published by the reviewer, without overwriting the changes saved since it was created
+	dashboard, err := dashboards.NewService(hs.SQLStore).SaveDashboard(&dashboards.SaveDashboardDTO{
+		Dashboard: dash,
+		Message:   revision.Message,
+		OrgId:     c.OrgId,
+		User:      c.SignedInUser,
+	}, allowUiUpdate)
+	if err != nil {
+		return hs.dashboardSaveErrorToApiResponse(err)
+	}
+
+	cmd := models.ReviewDashboardRevisionCommand{
+		OrgId:      c.OrgId,
+		Id:         revision.Id,
+		Status:     models.DashboardRevisionApproved,
+		ReviewedBy: c.UserId,
+		Comment:    strings.TrimSpace(form.Comment),
+	}
+	if err := bus.Dispatch(&cmd); err != nil {
+		return response.Error(500, "Dashboard saved but failed to approve the revision", err)
+	}
+
+	if provisioningData != nil {
+		if err := hs.ProvisioningService.SyncDashboardUIUpdate(provisioningData, dashboard); err != nil {
+			return response.Error(500, "Dashboard saved but failed to sync it with its provisioning file", err)
+		}
+	}
+
+	if err := hs.LibraryPanelService.ConnectLibraryPanelsForDashboard(c, dashboard); err != nil {
+		return response.Error(500, "Error while connecting library panels", err)
+	}
+
+	hs.notifyDashboardRevisionAuthor(c, revision, dashboard, cmd)
+	return response.JSON(200, util.DynMap{
+		"status":  "success",
+		"message": "Dashboard revision approved",
+		"slug":    dashboard.Slug,
+		"version": dashboard.Version,
+		"id":      dashboard.Id,
+		"uid":     dashboard.Uid,
+		"url":     dashboard.GetUrl(),
+	})
+}
+
+// POST /api/dashboards/revisions/:revisionId/reject
+func (hs *HTTPServer) RejectDashboardRevision(c *models.ReqContext, form dtos.ReviewDashboardRevisionForm) response.Response {
+	revision, rsp := getDashboardRevision(c, true)
+	if rsp != nil {
+		return rsp
+	}
+
+	cmd := models.ReviewDashboardRevisionCommand{
+		OrgId:      c.OrgId,
+		Id:         revision.Id,
+		Status:     models.DashboardRevisionRejected,
+		ReviewedBy: c.UserId,
+		Comment:    strings.TrimSpace(form.Comment),
+	}
+	if err := bus.Dispatch(&cmd); err != nil {
+		if errors.Is(err, models.ErrDashboardRevisionNotPending) {
+			return response.Error(400, err.Error(), err)
+		}
+		return response.Error(500, "Failed to reject the dashboard revision", err)
+	}
+
+	hs.notifyDashboardRevisionAuthor(c, revision, nil, cmd)
+	return response.Success("Dashboard revision rejected")
+}
+
+// getDashboardRevision returns the revision of the request, or an error response when the user can't see it or, to
+// review it, when the user isn't one of its reviewers or it has been reviewed already. The authors of the revisions
+// can see them, but not review them unless they're reviewers.
+func getDashboardRevision(c *models.ReqContext, review bool) (*models.DashboardRevision, response.Response) {
+	query := models.GetDashboardRevisionQuery{OrgId: c.OrgId, Id: c.ParamsInt64(":revisionId")}
+	if err := bus.Dispatch(&query); err != nil {
+		if errors.Is(err, models.ErrDashboardRevisionNotFound) {
+			return nil, response.Error(404, err.Error(), err)
+		}
+		return nil, response.Error(500, "Failed to get dashboard revision", err)
+	}
+	revision := query.Result
+
+	canReview, err := canReviewFolder(c, revision.ReviewFolderId)
+	if err != nil {
+		return nil, response.Error(500, "Failed to get folder reviewers", err)
+	}
+	if !canReview && (review || revision.CreatedBy != c.UserId) {
+		return nil, response.Error(403, "Access denied, only the reviewers of the folder can review the revision", nil)
+	}
+	if review && revision.Status != models.DashboardRevisionPending {
+		return nil, response.Error(400, models.ErrDashboardRevisionNotPending.Error(), nil)
+	}
+	return revision, nil
+}
+
+// canReviewFolder returns whether the user reviews the revisions of a protected folder, the org admins review the
+// revisions of all of them.
+func canReviewFolder(c *models.ReqContext, folderID int64) (bool, error) {
+	if c.OrgRole == models.ROLE_ADMIN {
+		return true, nil
+	}
+
+	query := models.GetFolderReviewersQuery{OrgId: c.OrgId, FolderId: folderID}
+	if err := bus.Dispatch(&query); err != nil {
+		return false, err
+	}
+	return isFolderReviewer(c.UserId, query.Result), nil
+}
+
+func isFolderReviewer(userID int64, reviewers []*models.FolderReviewerDTO) bool {
+	for _, reviewer := range reviewers {
+		if reviewer.UserId == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// dashboardReviewFolder returns the first protected folder of a save of a dashboard and its reviewers, or no
+// reviewers when the save doesn't need a review: the folders aren't protected, or the user reviews one of them.
+func dashboardReviewFolder(c *models.ReqContext, folderIDs []int64) (int64, []*models.FolderReviewerDTO, error) {
+	if c.OrgRole == models.ROLE_ADMIN {
+		return 0, nil, nil
+	}
+
+	var reviewFolderID int64
+	var reviewers []*models.FolderReviewerDTO
+	for _, folderID := range folderIDs {
+		if folderID == 0 {
+			continue
+		}
+
+		query := models.GetFolderReviewersQuery{OrgId: c.OrgId, FolderId: folderID}
+		if err := bus.Dispatch(&query); err != nil {
+			return 0, nil, err
+		}
+		if len(query.Result) == 0 {
+			continue
+		}
+		if isFolderReviewer(c.UserId, query.Result) {
+			return 0, nil, nil
+		}
+		if reviewers == nil {
+			reviewFolderID, reviewers = folderID, query.Result
+		}
+	}
+	return reviewFolderID, reviewers, nil
+}
+
+// checkDashboardImportReview returns a 403 response when an import of a dashboard needs a review, or else nil. The
+// imports aren't reviewed, the users save the dashboards of the protected folders instead.
+func checkDashboardImportReview(c *models.ReqContext, folderIDs []int64) response.Response {
+	_, reviewers, err := dashboardReviewFolder(c, folderIDs)
+	if err != nil {
+		return response.Error(500, "Failed to get folder reviewers", err)
+	}
+	if reviewers != nil {
+		return response.Error(403, "Access denied, the changes to the dashboards of the folder need a review", nil)
+	}
+	return nil
+}
+
+// submitDashboardForReview stores a save of a dashboard of a protected folder as a pending revision when the user
+// isn't one of the reviewers of the folder, and returns the response telling the save is pending, or else nil.
+func (hs *HTTPServer) submitDashboardForReview(c *models.ReqContext, dash *models.Dashboard, message string, folderIDs []int64) response.Response {
+	reviewFolderID, reviewers, err := dashboardReviewFolder(c, folderIDs)
+	if err != nil {
+		return response.Error(500, "Failed to get folder reviewers", err)
+	}
+	if reviewers == nil {
+		return nil
+	}
+
+	// only the users who could save the dashboard submit revisions of it, like the dashboards service checks
+	dashboardID, dashboardUID := int64(0), dash.Uid
+	guardianIDs := []int64{dash.FolderId}
+	if dash.Id != 0 || dash.Uid != "" {
+		query := models.GetDashboardQuery{Id: dash.Id, Uid: dash.Uid, OrgId: c.OrgId}
+		if err := bus.Dispatch(&query); err == nil {
+			dashboardID, dashboardUID = query.Result.Id, query.Result.Uid
+			guardianIDs = []int64{dashboardID}
+			if query.Result.FolderId != dash.FolderId {
+				guardianIDs = append(guardianIDs, dash.FolderId)
+			}
+		} else if !errors.Is(err, models.ErrDashboardNotFound) {
+			return response.Error(500, "Failed to get dashboard", err)
+		}
+	}
+	for _, id := range guardianIDs {
+		canSave, err := guardian.New(id, c.OrgId, c.SignedInUser).CanSave()
+		if err != nil {
+			return response.Error(500, "Failed to check dashboard permissions", err)
+		}
+		if !canSave {
+			return hs.dashboardSaveErrorToApiResponse(models.ErrDashboardUpdateAccessDenied)
+		}
+	}
+
+	cmd := models.CreateDashboardRevisionCommand{
+		OrgId:          c.OrgId,
+		DashboardId:    dashboardID,
+		DashboardUid:   dashboardUID,
+		FolderId:       dash.FolderId,
+		ReviewFolderId: reviewFolderID,
+		Title:          dash.Title,
+		Data:           dash.Data,
+		Message:        message,
+		CreatedBy:      c.UserId,
+	}
+	if err := bus.Dispatch(&cmd); err != nil {
+		return response.Error(500, "Failed to create dashboard revision", err)
+	}
+
+	hs.notifyDashboardReviewers(c, cmd.Result, reviewers)
+	return response.JSON(202, util.DynMap{
+		"status":     "pending",
+		"message":    "The changes need the approval of a reviewer of the folder",
+		"revisionId": cmd.Result.Id,
+	})
+}
+
+// dashboardRevisionUrl returns the absolute URL of the dashboard of a revision, or of the dashboards for the
+// revisions of new dashboards.
+func dashboardRevisionUrl(c *models.ReqContext, revision *models.DashboardRevision, dashboard *models.Dashboard) string {
+	url := "dashboards"
+	if dashboard != nil {
+		url = strings.TrimPrefix(dashboard.GetUrl(), setting.AppSubUrl+"/")
+	} else if revision.DashboardUid != "" {
+		url = strings.TrimPrefix(models.GetDashboardUrl(revision.DashboardUid, models.SlugifyTitle(revision.Title)), setting.AppSubUrl+"/")
+	}
+	return setting.ToAbsUrl(fmt.Sprintf("%s?orgId=%d", url, c.OrgId))
+}
+
+// notifyDashboardReviewers emails the reviewers of a folder about a new revision. The revision is saved already,
+// so the failures are only logged.
+func (hs *HTTPServer) notifyDashboardReviewers(c *models.ReqContext, revision *models.DashboardRevision, reviewers []*models.FolderReviewerDTO) {
+	for _, reviewer := range reviewers {
+		if reviewer.Email == "" {
+			continue
+		}
+
+		name := reviewer.Name
+		if name == "" {
+			name = reviewer.Login
+		}
+		cmd := models.SendEmailCommand{
+			To:       []string{reviewer.Email},
+			Template: "dashboard_review_requested.html",
+			Data: map[string]interface{}{
+				"Name":           name,
+				"Author":         c.NameOrFallback(),
+				"DashboardTitle": revision.Title,
+				"Message":        revision.Message,
+				"LinkUrl":        dashboardRevisionUrl(c, revision, nil),
+			},
+		}
+		if err := bus.Dispatch(&cmd); err != nil && !errors.Is(err, models.ErrSmtpNotEnabled) {
+			hs.log.Warn("Failed to send dashboard review email", "login", reviewer.Login, "error", err)
+		}
+	}
+}
+
+// notifyDashboardRevisionAuthor emails the author of a revision it has been approved or rejected. The revision is
+// reviewed already, so the failures are only logged.
+func (hs *HTTPServer) notifyDashboardRevisionAuthor(c *models.ReqContext, revision *models.DashboardRevision, dashboard *models.Dashboard, cmd models.ReviewDashboardRevisionCommand) {
+	if revision.CreatedBy == c.UserId {
+		return
+	}
+
+	query := models.GetUserByIdQuery{Id: revision.CreatedBy}
+	if err := bus.Dispatch(&query); err != nil {
+		if !errors.Is(err, models.ErrUserNotFound) {
+			hs.log.Warn("Failed to get dashboard revision author", "userId", revision.CreatedBy, "error", err)
+		}
+		return
+	}
+	author := query.Result
+	if author.Email == "" {
+		return
+	}
+
+	email := models.SendEmailCommand{
+		To:       []string{author.Email},
+		Template: "dashboard_review_completed.html",
+		Data: map[string]interface{}{
+			"Name":           author.NameOrFallback(),
+			"ReviewedBy":     c.NameOrFallback(),
+			"Status":         string(cmd.Status),
+			"DashboardTitle": revision.Title,
+			"Comment":        cmd.Comment,
+			"LinkUrl":        dashboardRevisionUrl(c, revision, dashboard),
+		},
+	}
+	if err := bus.Dispatch(&email); err != nil && !errors.Is(err, models.ErrSmtpNotEnabled) {
+		hs.log.Warn("Failed to send dashboard review email", "login", author.Login, "error", err)
+	}
+}
+
+func reviewerLogin(revision *models.DashboardRevision) string {
+	if revision.ReviewedBy == 0 {
+		return ""
+	}
+	return getUserLogin(revision.ReviewedBy)
+}
+
+func reviewedTime(revision *models.DashboardRevision) *time.Time {
+	if revision.Reviewed.IsZero() {
+		return nil
+	}
+	return &revision.Reviewed
+}
@@ -0,0 +1,299 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	dboards "github.com/grafana/grafana/pkg/dashboards"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/provisioning"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	protectedFolderID int64 = 5
+	reviewerUserID    int64 = 7
+)
+
+func addFolderReviewerHandlers(emails *[]*models.SendEmailCommand) {
+	bus.AddHandler("test", func(query *models.GetFolderReviewersQuery) error {
+		query.Result = []*models.FolderReviewerDTO{}
+		if query.FolderId == protectedFolderID {
+			query.Result = []*models.FolderReviewerDTO{{UserId: reviewerUserID, Login: "reviewer", Email: "reviewer@example.com"}}
+		}
+		return nil
+	})
+	bus.AddHandler("test", func(cmd *models.SendEmailCommand) error {
+		*emails = append(*emails, cmd)
+		return nil
+	})
+}
+
+func mockGuardianCanSave(t *testing.T, canSave bool) {
+	origNewGuardian := guardian.New
+	t.Cleanup(func() {
+		guardian.New = origNewGuardian
+	})
+	guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanSaveValue: canSave})
+}
+
+func TestSubmitDashboardForReview(t *testing.T) {
+	cmd := models.SaveDashboardCommand{
+		OrgId:  testOrgID,
+		UserId: testUserID,
+		Dashboard: simplejson.NewFromAny(map[string]interface{}{
+			"title": "Revenue",
+		}),
+		FolderId: protectedFolderID,
+		Message:  "add the forecast",
+	}
+
+	mock := &dashboards.FakeDashboardService{}
+	postDashboardScenario(t, "When saving in a protected folder, calling POST on", "/api/dashboards", "/api/dashboards", mock, cmd, func(sc *scenarioContext) {
+		var emails []*models.SendEmailCommand
+		addFolderReviewerHandlers(&emails)
+		mockGuardianCanSave(t, true)
+		var created *models.CreateDashboardRevisionCommand
+		bus.AddHandler("test", func(cmd *models.CreateDashboardRevisionCommand) error {
+			created = cmd
+			cmd.Result = &models.DashboardRevision{Id: 3, Title: cmd.Title, Message: cmd.Message, FolderId: cmd.FolderId}
+			return nil
+		})
+
+		callPostDashboard(sc)
+		require.Equal(t, 202, sc.resp.Code)
+		result := sc.ToJSON()
+		assert.Equal(t, "pending", result.Get("status").MustString())
+		assert.Equal(t, int64(3), result.Get("revisionId").MustInt64())
+		assert.Empty(t, mock.SavedDashboards)
+
+		require.NotNil(t, created)
+		assert.Equal(t, protectedFolderID, created.FolderId)
+		assert.Equal(t, protectedFolderID, created.ReviewFolderId)
+		assert.Equal(t, "Revenue", created.Title)
+		assert.Equal(t, "add the forecast", created.Message)
+		assert.Equal(t, testUserID, created.CreatedBy)
+
+		require.Len(t, emails, 1)
+		assert.Equal(t, []string{"reviewer@example.com"}, emails[0].To)
+		assert.Equal(t, "dashboard_review_requested.html", emails[0].Template)
+		assert.Equal(t, "Revenue", emails[0].Data["DashboardTitle"])
+	})
+
+	postDashboardScenario(t, "When saving in a protected folder without permissions, calling POST on", "/api/dashboards", "/api/dashboards", mock, cmd, func(sc *scenarioContext) {
+		var emails []*models.SendEmailCommand
+		addFolderReviewerHandlers(&emails)
+		mockGuardianCanSave(t, false)
+
+		callPostDashboard(sc)
+		assert.Equal(t, 403, sc.resp.Code)
+		assert.Empty(t, emails)
+	})
+
+	cmd.UserId = reviewerUserID
+	postDashboardScenario(t, "When a reviewer saves in a protected folder, calling POST on", "/api/dashboards", "/api/dashboards", mock, cmd, func(sc *scenarioContext) {
+		var emails []*models.SendEmailCommand
+		addFolderReviewerHandlers(&emails)
+
+		callPostDashboard(sc)
+		assert.Equal(t, 200, sc.resp.Code)
+		assert.Len(t, mock.SavedDashboards, 1)
+		assert.Empty(t, emails)
+	})
+}
+
+func dashboardRevisionScenario(t *testing.T, desc string, url string, userID int64, role models.RoleType,
+	mock *dashboards.FakeDashboardService, fn scenarioFunc) {
+	t.Run(fmt.Sprintf("%s %s", desc, url), func(t *testing.T) {
+		t.Cleanup(bus.ClearBusHandlers)
+
+		cfg := setting.NewCfg()
+		hs := HTTPServer{
+			Bus:                 bus.GetBus(),
+			Cfg:                 cfg,
+			ProvisioningService: provisioning.NewProvisioningServiceMock(),
+			LibraryPanelService: &mockLibraryPanelService{},
+		}
+
+		origNewDashboardService := dashboards.NewService
+		origProvisioningService := dashboards.NewProvisioningService
+		t.Cleanup(func() {
+			dashboards.NewService = origNewDashboardService
+			dashboards.NewProvisioningService = origProvisioningService
+		})
+		dashboards.MockDashboardService(mock)
+		dashboards.NewProvisioningService = func(dboards.Store) dashboards.DashboardProvisioningService {
+			return mockDashboardProvisioningService{}
+		}
+
+		sc := setupScenarioContext(t, url)
+		sc.defaultHandler = routing.Wrap(func(c *models.ReqContext) response.Response {
+			sc.context = c
+			sc.context.SignedInUser = &models.SignedInUser{OrgId: testOrgID, UserId: userID, OrgRole: role}
+
+			form := dtos.ReviewDashboardRevisionForm{Comment: " the forecast is wrong "}
+			if c.Params(":action") == "approve" {
+				return hs.ApproveDashboardRevision(c, form)
+			}
+			return hs.RejectDashboardRevision(c, form)
+		})
+		sc.m.Post("/api/dashboards/revisions/:revisionId/:action", sc.defaultHandler)
+
+		fn(sc)
+	})
+}
+
+func TestReviewDashboardRevision(t *testing.T) {
+	setUp := func() (*[]*models.SendEmailCommand, **models.ReviewDashboardRevisionCommand) {
+		var emails []*models.SendEmailCommand
+		addFolderReviewerHandlers(&emails)
+		addReadOnlyFolderHandlers()
+
+		bus.AddHandler("test", func(query *models.GetDashboardRevisionQuery) error {
+			if query.Id != 3 {
+				return models.ErrDashboardRevisionNotFound
+			}
+			query.Result = &models.DashboardRevision{
+				Id:             3,
+				OrgId:          testOrgID,
+				DashboardId:    2,
+				DashboardUid:   "revenue",
+				FolderId:       protectedFolderID,
+				ReviewFolderId: protectedFolderID,
+				Title:          "Revenue",
+				Data:           simplejson.NewFromAny(map[string]interface{}{"id": 2, "uid": "revenue", "title": "Revenue", "version": 4}),
+				Message:        "add the forecast",
+				Status:         models.DashboardRevisionPending,
+				CreatedBy:      testUserID,
+			}
+			return nil
+		})
+		bus.AddHandler("test", func(query *models.GetDashboardQuery) error {
+			query.Result = &models.Dashboard{Id: 2, Uid: "revenue", FolderId: protectedFolderID}
+			return nil
+		})
+		bus.AddHandler("test", func(query *models.GetUserByIdQuery) error {
+			query.Result = &models.User{Id: testUserID, Login: testUserLogin, Email: "author@example.com"}
+			return nil
+		})
+
+		var reviewed *models.ReviewDashboardRevisionCommand
+		bus.AddHandler("test", func(cmd *models.ReviewDashboardRevisionCommand) error {
+			reviewed = cmd
+			return nil
+		})
+		return &emails, &reviewed
+	}
+
+	mock := &dashboards.FakeDashboardService{}
+	dashboardRevisionScenario(t, "When a reviewer approves, calling POST on", "/api/dashboards/revisions/3/approve", reviewerUserID, models.ROLE_EDITOR, mock, func(sc *scenarioContext) {
+		emails, reviewed := setUp()
+
+		sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+		require.Equal(t, 200, sc.resp.Code)
+
+		require.Len(t, mock.SavedDashboards, 1)
+		saved := mock.SavedDashboards[0]
+		assert.Equal(t, reviewerUserID, saved.User.UserId)
+		assert.Equal(t, "add the forecast", saved.Message)
+		assert.Equal(t, protectedFolderID, saved.Dashboard.FolderId)
+		assert.Equal(t, 4, saved.Dashboard.Version)
+		assert.False(t, saved.Overwrite)
+
+		require.NotNil(t, *reviewed)
+		assert.Equal(t, models.DashboardRevisionApproved, (*reviewed).Status)
+		assert.Equal(t, "the forecast is wrong", (*reviewed).Comment)
+
+		require.Len(t, *emails, 1)
+		assert.Equal(t, []string{"author@example.com"}, (*emails)[0].To)
+		assert.Equal(t, "dashboard_review_completed.html", (*emails)[0].Template)
+		assert.Equal(t, "approved", (*emails)[0].Data["Status"])
+	})
+
+	mock = &dashboards.FakeDashboardService{}
+	dashboardRevisionScenario(t, "When a reviewer rejects, calling POST on", "/api/dashboards/revisions/3/reject", reviewerUserID, models.ROLE_EDITOR, mock, func(sc *scenarioContext) {
+		emails, reviewed := setUp()
+
+		sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+		require.Equal(t, 200, sc.resp.Code)
+		assert.Empty(t, mock.SavedDashboards)
+		assert.Equal(t, models.DashboardRevisionRejected, (*reviewed).Status)
+		require.Len(t, *emails, 1)
+		assert.Equal(t, "rejected", (*emails)[0].Data["Status"])
+	})
+
+	dashboardRevisionScenario(t, "When the author approves, calling POST on", "/api/dashboards/revisions/3/approve", testUserID, models.ROLE_EDITOR, mock, func(sc *scenarioContext) {
+		_, reviewed := setUp()
+
+		sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+		assert.Equal(t, 403, sc.resp.Code)
+		assert.Empty(t, mock.SavedDashboards)
+		assert.Nil(t, *reviewed)
+	})
+
+	dashboardRevisionScenario(t, "When an org admin rejects, calling POST on", "/api/dashboards/revisions/3/reject", testUserID+100, models.ROLE_ADMIN, mock, func(sc *scenarioContext) {
+		_, reviewed := setUp()
+
+		sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+		assert.Equal(t, 200, sc.resp.Code)
+		assert.NotNil(t, *reviewed)
+	})
+
+	dashboardRevisionScenario(t, "When approving an unknown revision, calling POST on", "/api/dashboards/revisions/4/approve", reviewerUserID, models.ROLE_EDITOR, mock, func(sc *scenarioContext) {
+		setUp()
+
+		sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+		assert.Equal(t, 404, sc.resp.Code)
+	})
+}
+
+func TestGetDashboardRevisions(t *testing.T) {
+	var query *models.GetDashboardRevisionsQuery
+	setUp := func() {
+		bus.AddHandler("test", func(q *models.GetDashboardRevisionsQuery) error {
+			query = q
+			q.Result = []*models.DashboardRevisionDTO{{Id: 3, Title: "Revenue", Status: q.Status}}
+			return nil
+		})
+	}
+
+	loggedInUserScenarioWithRole(t, "When calling GET on", "GET", "/api/dashboards/revisions", "/api/dashboards/revisions",
+		models.ROLE_EDITOR, func(sc *scenarioContext) {
+			setUp()
+			sc.handlerFunc = GetDashboardRevisions
+
+			sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+			require.Equal(t, 200, sc.resp.Code)
+			assert.Equal(t, models.DashboardRevisionPending, query.Status)
+			assert.Equal(t, testUserID, query.UserId)
+
+			var revisions []models.DashboardRevisionDTO
+			require.NoError(t, json.Unmarshal(sc.resp.Body.Bytes(), &revisions))
+			require.Len(t, revisions, 1)
+
+			sc.fakeReqWithParams("GET", sc.url, map[string]string{"status": "merged"}).exec()
+			assert.Equal(t, 400, sc.resp.Code)
+		})
+
+	loggedInUserScenarioWithRole(t, "When an org admin calls GET on", "GET", "/api/dashboards/revisions", "/api/dashboards/revisions",
+		models.ROLE_ADMIN, func(sc *scenarioContext) {
+			setUp()
+			sc.handlerFunc = GetDashboardRevisions
+
+			sc.fakeReqWithParams("GET", sc.url, map[string]string{"status": "approved"}).exec()
+			require.Equal(t, 200, sc.resp.Code)
+			assert.Equal(t, models.DashboardRevisionApproved, query.Status)
+			assert.Zero(t, query.UserId)
+		})
+}
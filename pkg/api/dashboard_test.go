@@ -16,10 +16,12 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/alerting"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/guardian"
 	"github.com/grafana/grafana/pkg/services/libraryelements"
 	"github.com/grafana/grafana/pkg/services/live"
 	"github.com/grafana/grafana/pkg/services/provisioning"
 	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/services/sanitizer"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -133,6 +135,13 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 				return nil
 			})
 
+			bus.AddHandler("test", func(query *models.IsReadOnlyFolderQuery) error {
+				return nil
+			})
+			bus.AddHandler("test", func(query *models.GetFolderReviewersQuery) error {
+				return nil
+			})
+
 			return state
 		}
 
@@ -338,6 +347,13 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 				return nil
 			})
 
+			bus.AddHandler("test", func(query *models.IsReadOnlyFolderQuery) error {
+				return nil
+			})
+			bus.AddHandler("test", func(query *models.GetFolderReviewersQuery) error {
+				return nil
+			})
+
 			return state
 		}
 
@@ -819,6 +835,71 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 			})
 		})
 
+		t.Run("Given a request saving an outdated version of a dashboard", func(t *testing.T) {
+			cmd := models.SaveDashboardCommand{
+				OrgId: 1,
+				Dashboard: simplejson.NewFromAny(map[string]interface{}{
+					"id":      2,
+					"uid":     "uid",
+					"title":   "Dash",
+					"version": 1,
+					"refresh": "1m",
+				}),
+			}
+
+			mock := &dashboards.FakeDashboardService{
+				SaveDashboardError: models.ErrDashboardVersionMismatch,
+			}
+
+			postDashboardScenario(t, "When calling POST on", "/api/dashboards", "/api/dashboards", mock, cmd, func(sc *scenarioContext) {
+				origNewGuardian := guardian.New
+				t.Cleanup(func() {
+					guardian.New = origNewGuardian
+				})
+				guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanSaveValue: true})
+
+				bus.AddHandler("test", func(query *models.GetDashboardQuery) error {
+					query.Result = &models.Dashboard{
+						Id:      2,
+						Uid:     "uid",
+						Version: 2,
+						Data: simplejson.NewFromAny(map[string]interface{}{
+							"id":      2,
+							"uid":     "uid",
+							"title":   "Dash renamed",
+							"version": 2,
+						}),
+					}
+					return nil
+				})
+				bus.AddHandler("test", func(query *models.GetDashboardVersionQuery) error {
+					query.Result = &models.DashboardVersion{
+						DashboardId: 2,
+						Version:     1,
+						Data: simplejson.NewFromAny(map[string]interface{}{
+							"id":      2,
+							"uid":     "uid",
+							"title":   "Dash",
+							"version": 1,
+						}),
+					}
+					return nil
+				})
+
+				callPostDashboard(sc)
+				assert.Equal(t, 412, sc.resp.Code)
+
+				result := sc.ToJSON()
+				assert.Equal(t, "version-mismatch", result.Get("status").MustString())
+				conflict := result.Get("conflict")
+				assert.Equal(t, 1, conflict.Get("baseVersion").MustInt())
+				assert.Equal(t, 2, conflict.Get("theirsVersion").MustInt())
+				assert.Empty(t, conflict.Get("conflicts").MustArray())
+				assert.Equal(t, "Dash renamed", conflict.Get("merged").Get("title").MustString())
+				assert.Equal(t, "1m", conflict.Get("merged").Get("refresh").MustString())
+			})
+		})
+
 		// This tests that invalid requests returns expected error responses
 		t.Run("Given incorrect requests for creating a dashboard", func(t *testing.T) {
 			testCases := []struct {
@@ -1207,9 +1288,15 @@ func callPostDashboardShouldReturnSuccess(sc *scenarioContext) {
 }
 
 func postDashboardScenario(t *testing.T, desc string, url string, routePattern string,
-	mock *dashboards.FakeDashboardService, cmd models.SaveDashboardCommand, fn scenarioFunc) {
+	mock *dashboards.FakeDashboardService, cmd models.SaveDashboardCommand, fn scenarioFunc, cbs ...func(*HTTPServer)) {
 	t.Run(fmt.Sprintf("%s %s", desc, url), func(t *testing.T) {
 		t.Cleanup(bus.ClearBusHandlers)
+		bus.AddHandler("test", func(query *models.IsReadOnlyFolderQuery) error {
+			return nil
+		})
+		bus.AddHandler("test", func(query *models.GetFolderReviewersQuery) error {
+			return nil
+		})
 
 		cfg := setting.NewCfg()
 		hs := HTTPServer{
@@ -1223,6 +1310,10 @@ func postDashboardScenario(t *testing.T, desc string, url string, routePattern s
 			PluginManager:         &fakePluginManager{},
 			LibraryPanelService:   &mockLibraryPanelService{},
 			LibraryElementService: &mockLibraryElementService{},
+			HTMLSanitizer:         &sanitizer.Service{Cfg: cfg},
+		}
+		for _, cb := range cbs {
+			cb(&hs)
 		}
 
 		sc := setupScenarioContext(t, url)
@@ -1276,6 +1367,12 @@ func restoreDashboardVersionScenario(t *testing.T, desc string, url string, rout
 	mock *dashboards.FakeDashboardService, cmd dtos.RestoreDashboardVersionCommand, fn scenarioFunc) {
 	t.Run(fmt.Sprintf("%s %s", desc, url), func(t *testing.T) {
 		defer bus.ClearBusHandlers()
+		bus.AddHandler("test", func(query *models.IsReadOnlyFolderQuery) error {
+			return nil
+		})
+		bus.AddHandler("test", func(query *models.GetFolderReviewersQuery) error {
+			return nil
+		})
 
 		cfg := setting.NewCfg()
 		hs := HTTPServer{
@@ -1286,6 +1383,7 @@ func restoreDashboardVersionScenario(t *testing.T, desc string, url string, rout
 			QuotaService:          &quota.QuotaService{Cfg: cfg},
 			LibraryPanelService:   &mockLibraryPanelService{},
 			LibraryElementService: &mockLibraryElementService{},
+			HTMLSanitizer:         &sanitizer.Service{Cfg: cfg},
 		}
 
 		sc := setupScenarioContext(t, url)
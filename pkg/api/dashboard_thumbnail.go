@@ -0,0 +1,45 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/thumbnails"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// GetDashboardThumbnail returns the thumbnail of a dashboard, a stale
+// thumbnail is returned while it's rendered again in the background
+// GET /api/dashboards/uid/:uid/thumbnail
+func (hs *HTTPServer) GetDashboardThumbnail(c *models.ReqContext) response.Response {
+	if !hs.DashboardThumbnails.IsAvailable() {
+		return response.Error(404, "Dashboard thumbnails are not available", nil)
+	}
+
+	dash, rsp := getDashboardHelper(c.OrgId, "", 0, c.Params(":uid"))
+	if rsp != nil {
+		return rsp
+	}
+
+	g := guardian.New(dash.Id, c.OrgId, c.SignedInUser)
+	if canView, err := g.CanView(); err != nil || !canView {
+		return dashboardGuardianResponse(err)
+	}
+
+	file, data, err := hs.DashboardThumbnails.GetThumbnail(c.Req.Context(), dash)
+	if errors.Is(err, thumbnails.ErrThumbnailNotFound) {
+		return response.JSON(202, util.DynMap{"message": "Dashboard thumbnail is being rendered"})
+	}
+	if err != nil {
+		return fileStorageErrorToResponse(err, "Failed to get dashboard thumbnail")
+	}
+
+	return response.Respond(200, data).
+		SetHeader("Content-Type", "image/png").
+		SetHeader("X-Content-Type-Options", "nosniff").
+		SetHeader("Last-Modified", file.Updated.UTC().Format(http.TimeFormat)).
+		SetHeader("Cache-Control", "private, max-age=60")
+}
@@ -1,7 +1,23 @@
 package api
 
-import "github.com/grafana/grafana/pkg/models"
+import (
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/models"
+)
 
 func (hs *HTTPServer) ProxyDataSourceRequest(c *models.ReqContext) {
+	// the requests of no panel of a dashboard the user can view are checked against the explore permissions, like
+	// the queries, the errors of the data source being left to the proxy
+	datasourceID := c.ParamsInt64(":id")
+	if ds, err := hs.DatasourceCache.GetDatasource(datasourceID, c.SignedInUser, c.SkipCache); err == nil {
+		dashboardID, _ := strconv.ParseInt(c.Req.Header.Get("X-Dashboard-Id"), 10, 64)
+		panelID, _ := strconv.ParseInt(c.Req.Header.Get("X-Panel-Id"), 10, 64)
+		if resp := hs.checkExplorePermissions(c, dashboardID, panelID, ds); resp != nil {
+			resp.WriteTo(c)
+			return
+		}
+	}
+
 	hs.DataProxy.ProxyDataSourceRequest(c)
 }
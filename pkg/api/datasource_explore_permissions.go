@@ -0,0 +1,225 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/guardian"
+)
+
+// GET /api/datasources/:id/explore-permissions
+func GetDataSourceExplorePermissions(c *models.ReqContext) response.Response {
+	datasourceID := c.ParamsInt64(":id")
+	if rsp := checkDataSourceExists(c.OrgId, datasourceID); rsp != nil {
+		return rsp
+	}
+
+	query := models.GetDataSourceExplorePermissionsQuery{OrgId: c.OrgId, DatasourceId: datasourceID}
+	if err := bus.Dispatch(&query); err != nil {
+		return response.Error(500, "Failed to get explore permissions", err)
+	}
+	return response.JSON(200, query.Result)
+}
+
+// PUT /api/datasources/:id/explore-permissions
+func UpdateDataSourceExplorePermissions(c *models.ReqContext, apiCmd dtos.UpdateDataSourceExplorePermissionsCommand) response.Response {
+	datasourceID := c.ParamsInt64(":id")
+	if rsp := checkDataSourceExists(c.OrgId, datasourceID); rsp != nil {
+		return rsp
+	}
+
+	cmd := models.UpdateDataSourceExplorePermissionsCommand{OrgId: c.OrgId, DatasourceId: datasourceID}
+	for _, item := range apiCmd.Items {
+		granted := 0
+		if item.UserID > 0 {
+			granted++
+		}
+		if item.TeamID > 0 {
+			granted++
+		}
+		if item.Role != nil {
+			if !item.Role.IsValid() {
+				return response.Error(400, fmt.Sprintf("Invalid role %q", *item.Role), nil)
+			}
+			granted++
+		}
+		if granted != 1 {
+			return response.Error(400, models.ErrDataSourceExplorePermissionInvalid.Error(),
+				models.ErrDataSourceExplorePermissionInvalid)
+		}
+
+		cmd.Items = append(cmd.Items, &models.DataSourceExplorePermission{
+			UserId: item.UserID,
+			TeamId: item.TeamID,
+			Role:   item.Role,
+		})
+	}
+
+	if err := bus.Dispatch(&cmd); err != nil {
+		return response.Error(500, "Failed to update explore permissions", err)
+	}
+	return response.Success("Explore permissions updated")
+}
+
+func checkDataSourceExists(orgID, datasourceID int64) response.Response {
+	query := models.GetDataSourceQuery{Id: datasourceID, OrgId: orgID}
+	if err := bus.Dispatch(&query); err != nil {
+		if errors.Is(err, models.ErrDataSourceNotFound) {
+			return response.Error(404, "Data source not found", nil)
+		}
+		return response.Error(500, "Failed to query datasources", err)
+	}
+	return nil
+}
+
+// checkQueriesExplorePermissions checks the explore permissions of the data sources of queries, which are Explore
+// queries unless they're of the panel of the request.
+func (hs *HTTPServer) checkQueriesExplorePermissions(c *models.ReqContext, reqDTO dtos.MetricRequest) response.Response {
+	var datasources []*models.DataSource
+	checked := map[int64]bool{}
+	for _, query := range reqDTO.Queries {
+		if query.Get("datasource").MustString("") == expr.DatasourceName {
+			continue
+		}
+		datasourceID := query.Get("datasourceId").MustInt64(0)
+		if datasourceID == 0 || checked[datasourceID] {
+			continue
+		}
+		checked[datasourceID] = true
+
+		ds, err := hs.DatasourceCache.GetDatasource(datasourceID, c.SignedInUser, c.SkipCache)
+		if err != nil {
+			return hs.handleGetDataSourceError(err, datasourceID)
+		}
+		datasources = append(datasources, ds)
+	}
+	return hs.checkExplorePermissions(c, reqDTO.DashboardID, reqDTO.PanelID, datasources...)
+}
+
+// checkExplorePermissions returns a 403 response when the user may not explore one of the data sources, or else nil.
+// The queries of a panel of a dashboard the user can view, with the panel using the data source, aren't Explore
+// queries, and neither are the queries of the variables and annotations of the dashboard when panelID is 0. Any other
+// query is an Explore query, whatever the client tells.
+func (hs *HTTPServer) checkExplorePermissions(c *models.ReqContext, dashboardID, panelID int64,
+	datasources ...*models.DataSource) response.Response {
+	var dashboard *simplejson.Json
+	dashboardLoaded := false
+	for _, ds := range datasources {
+		canExplore := models.CanExploreDataSourceQuery{OrgId: c.OrgId, DatasourceId: ds.Id, User: c.SignedInUser}
+		if err := bus.Dispatch(&canExplore); err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to check explore permissions", err)
+		}
+		if canExplore.Result {
+			continue
+		}
+
+		if !dashboardLoaded {
+			dashboardLoaded = true
+			dashboard = hs.getViewableDashboard(c, dashboardID)
+		}
+		if dashboard != nil && dashboardUsesDataSource(dashboard, panelID, ds) {
+			continue
+		}
+		return response.Error(http.StatusForbidden, fmt.Sprintf("Access denied to explore data source %q", ds.Name),
+			models.ErrDataSourceExploreDenied)
+	}
+	return nil
+}
+
+// getViewableDashboard returns the data of a dashboard with its library panels, nil if it's not found or the user
+// can't view it.
+func (hs *HTTPServer) getViewableDashboard(c *models.ReqContext, dashboardID int64) *simplejson.Json {
+	if dashboardID <= 0 {
+		return nil
+	}
+	dash, rsp := getDashboardHelper(c.OrgId, "", dashboardID, "")
+	if rsp != nil {
+		return nil
+	}
+	if canView, err := guardian.New(dash.Id, c.OrgId, c.SignedInUser).CanView(); err != nil || !canView {
+		return nil
+	}
+	if hs.LibraryPanelService != nil {
+		if err := hs.LibraryPanelService.LoadLibraryPanelsForDashboard(c, dash); err != nil {
+			hs.log.Warn("Failed to load the library panels of the dashboard", "dashboardId", dash.Id, "err", err)
+		}
+	}
+	return dash.Data
+}
+
+// dashboardUsesDataSource returns whether the panel of a dashboard, or its variables and annotations when panelID is
+// 0, use a data source.
+func dashboardUsesDataSource(dashboard *simplejson.Json, panelID int64, ds *models.DataSource) bool {
+	var refs []*simplejson.Json
+	if panelID == 0 {
+		for _, v := range dashboard.GetPath("templating", "list").MustArray() {
+			if variable := simplejson.NewFromAny(v); variable.Get("type").MustString() == "query" {
+				refs = append(refs, variable.Get("datasource"))
+			}
+		}
+		for _, a := range dashboard.GetPath("annotations", "list").MustArray() {
+			if ref, ok := simplejson.NewFromAny(a).CheckGet("datasource"); ok {
+				refs = append(refs, ref)
+			}
+		}
+	} else {
+		// the panels of the legacy rows as well
+		panels := dashboard.Get("panels").MustArray()
+		for _, row := range dashboard.Get("rows").MustArray() {
+			panels = append(panels, simplejson.NewFromAny(row).Get("panels").MustArray()...)
+		}
+		panel := findPanel(panels, panelID)
+		if panel == nil {
+			return false
+		}
+		// the targets of the mixed panels have their own data sources
+		refs = append(refs, panel.Get("datasource"))
+		for _, t := range panel.Get("targets").MustArray() {
+			if ref, ok := simplejson.NewFromAny(t).CheckGet("datasource"); ok {
+				refs = append(refs, ref)
+			}
+		}
+	}
+
+	for _, ref := range refs {
+		if dataSourceRefMatches(dashboard, ref, ds) {
+			return true
+		}
+	}
+	return false
+}
+
+// dataSourceRefMatches returns whether a data source reference of a dashboard is a data source: its name, its UID,
+// a data source variable of its type, or none for the default data source.
+func dataSourceRefMatches(dashboard *simplejson.Json, ref *simplejson.Json, ds *models.DataSource) bool {
+	switch value := ref.Interface().(type) {
+	case nil:
+		return ds.IsDefault
+	case map[string]interface{}:
+		return ref.Get("uid").MustString() != "" && ref.Get("uid").MustString() == ds.Uid
+	case string:
+		if value == "" || value == "default" {
+			return ds.IsDefault
+		}
+		if strings.HasPrefix(value, "$") {
+			name := strings.Trim(strings.TrimPrefix(value, "$"), "{}")
+			for _, v := range dashboard.GetPath("templating", "list").MustArray() {
+				variable := simplejson.NewFromAny(v)
+				if variable.Get("type").MustString() == "datasource" && variable.Get("name").MustString() == name {
+					return variable.Get("query").MustString() == ds.Type
+				}
+			}
+			return false
+		}
+		return value == ds.Name || value == ds.Uid
+	}
+	return false
+}
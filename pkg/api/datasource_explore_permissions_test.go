@@ -0,0 +1,214 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/guardian"
+)
+
+func TestUpdateDataSourceExplorePermissions(t *testing.T) {
+	var saved *models.UpdateDataSourceExplorePermissionsCommand
+	addHandlers := func() {
+		bus.AddHandler("test", func(cmd *models.UpdateDataSourceExplorePermissionsCommand) error {
+			saved = cmd
+			return nil
+		})
+		bus.AddHandler("test", func(query *models.GetDataSourceQuery) error {
+			if query.Id != 1 {
+				return models.ErrDataSourceNotFound
+			}
+			query.Result = &models.DataSource{Id: 1, OrgId: testOrgID}
+			return nil
+		})
+	}
+
+	update := func(t *testing.T, datasourceID string, items ...dtos.DataSourceExplorePermissionItem) int {
+		t.Helper()
+		var code int
+		loggedInUserScenarioWithRole(t, "When calling PUT on", "GET", "/api/datasources/"+datasourceID+"/explore-permissions",
+			"/api/datasources/:id/explore-permissions", models.ROLE_ADMIN, func(sc *scenarioContext) {
+				addHandlers()
+				sc.handlerFunc = func(c *models.ReqContext) response.Response {
+					return UpdateDataSourceExplorePermissions(c, dtos.UpdateDataSourceExplorePermissionsCommand{Items: items})
+				}
+				sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+				code = sc.resp.Code
+			})
+		return code
+	}
+	editor := models.ROLE_EDITOR
+
+	t.Run("Should replace the explore permissions of the data source", func(t *testing.T) {
+		require.Equal(t, 200, update(t, "1", dtos.DataSourceExplorePermissionItem{UserID: 2},
+			dtos.DataSourceExplorePermissionItem{TeamID: 3}, dtos.DataSourceExplorePermissionItem{Role: &editor}))
+		require.NotNil(t, saved)
+		assert.Equal(t, int64(testOrgID), saved.OrgId)
+		assert.Equal(t, int64(1), saved.DatasourceId)
+		assert.Equal(t, []*models.DataSourceExplorePermission{{UserId: 2}, {TeamId: 3}, {Role: &editor}}, saved.Items)
+	})
+
+	t.Run("Should fail for the permissions granted to none or several principals", func(t *testing.T) {
+		saved = nil
+		assert.Equal(t, 400, update(t, "1", dtos.DataSourceExplorePermissionItem{}))
+		assert.Equal(t, 400, update(t, "1", dtos.DataSourceExplorePermissionItem{UserID: 2, TeamID: 3}))
+		assert.Equal(t, 400, update(t, "1", dtos.DataSourceExplorePermissionItem{TeamID: 3, Role: &editor}))
+		invalid := models.RoleType("Owner")
+		assert.Equal(t, 400, update(t, "1", dtos.DataSourceExplorePermissionItem{Role: &invalid}))
+		assert.Nil(t, saved)
+	})
+
+	t.Run("Should return 404 for a missing data source", func(t *testing.T) {
+		assert.Equal(t, 404, update(t, "2", dtos.DataSourceExplorePermissionItem{UserID: 2}))
+	})
+}
+
+func TestCheckExplorePermissions(t *testing.T) {
+	t.Cleanup(bus.ClearBusHandlers)
+	origNewGuardian := guardian.New
+	t.Cleanup(func() {
+		guardian.New = origNewGuardian
+	})
+
+	dashboardData := simplejson.NewFromAny(map[string]interface{}{
+		"panels": []interface{}{
+			map[string]interface{}{"id": 1, "datasource": "finance"},
+			map[string]interface{}{"id": 2, "datasource": "logs"},
+			map[string]interface{}{"id": 3, "collapsed": true, "panels": []interface{}{
+				map[string]interface{}{"id": 4, "datasource": "-- Mixed --", "targets": []interface{}{
+					map[string]interface{}{"refId": "A", "datasource": "logs"},
+					map[string]interface{}{"refId": "B", "datasource": "finance"},
+				}},
+			}},
+			map[string]interface{}{"id": 5, "datasource": "${ds}"},
+		},
+		"templating": map[string]interface{}{"list": []interface{}{
+			map[string]interface{}{"name": "ds", "type": "datasource", "query": "postgres"},
+		}},
+	})
+	bus.AddHandler("test", func(query *models.CanExploreDataSourceQuery) error {
+		query.Result = query.User.UserId == 2
+		return nil
+	})
+	bus.AddHandler("test", func(query *models.GetDashboardQuery) error {
+		if query.Id != 10 {
+			return models.ErrDashboardNotFound
+		}
+		query.Result = &models.Dashboard{Id: 10, OrgId: testOrgID, Data: dashboardData}
+		return nil
+	})
+	hs := &HTTPServer{
+		DatasourceCache: &fakeDatasourceCache{datasource: &models.DataSource{Id: 1, OrgId: testOrgID, Name: "finance", Type: "postgres"}},
+		log:             log.New("test"),
+	}
+
+	check := func(userID int64, dashboardID, panelID int64, queries ...map[string]interface{}) int {
+		c := &models.ReqContext{SignedInUser: &models.SignedInUser{OrgId: testOrgID, UserId: userID, OrgRole: models.ROLE_EDITOR}}
+		reqDTO := dtos.MetricRequest{DashboardID: dashboardID, PanelID: panelID}
+		for _, query := range queries {
+			reqDTO.Queries = append(reqDTO.Queries, simplejson.NewFromAny(query))
+		}
+		if resp := hs.checkQueriesExplorePermissions(c, reqDTO); resp != nil {
+			return resp.Status()
+		}
+		return 200
+	}
+	query := map[string]interface{}{"refId": "A", "datasourceId": 1}
+	expression := map[string]interface{}{"refId": "B", "datasource": "__expr__", "datasourceId": -100, "type": "math"}
+	guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanViewValue: true})
+
+	t.Run("Should deny the queries of no panel of the users who may not explore the data source", func(t *testing.T) {
+		assert.Equal(t, 403, check(3, 0, 0, query, expression))
+		assert.Equal(t, 403, check(3, 11, 1, query))
+		assert.Equal(t, 403, check(3, 10, 99, query))
+	})
+
+	t.Run("Should deny the queries of a panel not using the data source", func(t *testing.T) {
+		assert.Equal(t, 403, check(3, 10, 2, query))
+	})
+
+	t.Run("Should allow the queries of the users who may explore the data source", func(t *testing.T) {
+		assert.Equal(t, 200, check(2, 0, 0, query, expression))
+	})
+
+	t.Run("Should allow the queries of the panels using the data source", func(t *testing.T) {
+		assert.Equal(t, 200, check(3, 10, 1, query))
+		assert.Equal(t, 200, check(3, 10, 4, query))
+		assert.Equal(t, 200, check(3, 10, 5, query))
+	})
+
+	t.Run("Should deny the queries of the panels of the dashboards the user can't view", func(t *testing.T) {
+		guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanViewValue: false})
+		t.Cleanup(func() {
+			guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanViewValue: true})
+		})
+		assert.Equal(t, 403, check(3, 10, 1, query))
+	})
+
+	t.Run("Should fail for a missing data source", func(t *testing.T) {
+		assert.Equal(t, 400, check(2, 0, 0, map[string]interface{}{"refId": "A", "datasourceId": 2}))
+	})
+}
+
+func TestDashboardUsesDataSource(t *testing.T) {
+	ds := &models.DataSource{Name: "finance", Uid: "abc", Type: "postgres", IsDefault: true}
+	dashboard := simplejson.NewFromAny(map[string]interface{}{
+		"rows": []interface{}{
+			map[string]interface{}{"panels": []interface{}{map[string]interface{}{"id": 1}}},
+		},
+		"panels": []interface{}{
+			map[string]interface{}{"id": 2, "datasource": map[string]interface{}{"uid": "abc"}},
+			map[string]interface{}{"id": 3, "datasource": "logs"},
+		},
+		"templating": map[string]interface{}{"list": []interface{}{
+			map[string]interface{}{"name": "interval", "type": "interval"},
+			map[string]interface{}{"name": "host", "type": "query", "datasource": "finance"},
+		}},
+	})
+
+	assert.True(t, dashboardUsesDataSource(dashboard, 1, ds), "the default data source of a legacy row panel")
+	assert.True(t, dashboardUsesDataSource(dashboard, 2, ds), "the data source of the UID")
+	assert.False(t, dashboardUsesDataSource(dashboard, 3, ds))
+	assert.True(t, dashboardUsesDataSource(dashboard, 0, ds), "the data source of a variable")
+	assert.False(t, dashboardUsesDataSource(dashboard, 0, &models.DataSource{Name: "logs"}))
+}
+
+func TestProxyDataSourceRequestExplorePermissions(t *testing.T) {
+	loggedInUserScenarioWithRole(t, "When calling the proxy of a data source the user may not explore", "POST",
+		"/api/datasources/proxy/1/api/v1/labels", "/api/datasources/proxy/:id/*", models.ROLE_EDITOR,
+		func(sc *scenarioContext) {
+			bus.AddHandler("test", func(query *models.CanExploreDataSourceQuery) error {
+				query.Result = false
+				return nil
+			})
+			hs := &HTTPServer{
+				DatasourceCache: &fakeDatasourceCache{datasource: &models.DataSource{Id: 1, OrgId: testOrgID, Name: "finance"}},
+				log:             log.New("test"),
+			}
+			// the denied requests aren't proxied, the data proxy being unset
+			sc.m.Get("/api/datasources/proxy/:id/*", func(c *models.ReqContext) {
+				c.UserId = testUserID
+				c.OrgId = testOrgID
+				c.OrgRole = models.ROLE_EDITOR
+				hs.ProxyDataSourceRequest(c)
+			})
+			sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+			assert.Equal(t, 403, sc.resp.Code)
+		})
+}
+
+// addExploreAllowedHandler lets the users explore the data sources of the tests of the queries.
+func addExploreAllowedHandler() {
+	bus.AddHandler("test", func(query *models.CanExploreDataSourceQuery) error {
+		query.Result = true
+		return nil
+	})
+}
@@ -0,0 +1,222 @@
+package api
+
+import (
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/securejsondata"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/audit"
+	"github.com/grafana/grafana/pkg/services/datasources/onboarding"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// GET /api/datasources/:id/secrets/rotation
+func GetDataSourceSecretRotation(c *models.ReqContext) response.Response {
+	ds, rsp := getDataSourceForSecretRotation(c, false)
+	if rsp != nil {
+		return rsp
+	}
+
+	rotation, rsp := getDataSourceSecretRotation(c.OrgId, ds.Id)
+	if rsp != nil {
+		return rsp
+	}
+	return response.JSON(200, rotation.ToDTO(time.Now()))
+}
+
+// POST /api/datasources/:id/secrets/rotation
+//
+// StageDataSourceSecrets stages secrets of a data source, to be validated and promoted. Staging again replaces the
+// staged secrets and their validation.
+func StageDataSourceSecrets(c *models.ReqContext, apiCmd dtos.StageDataSourceSecretsCommand) response.Response {
+	ds, rsp := getDataSourceForSecretRotation(c, true)
+	if rsp != nil {
+		return rsp
+	}
+
+	cmd := models.StageDataSourceSecretsCommand{
+		OrgId:        c.OrgId,
+		DatasourceId: ds.Id,
+		UserId:       c.UserId,
+		Secrets:      apiCmd.SecureJsonData,
+	}
+	if err := bus.Dispatch(&cmd); err != nil {
+		if errors.Is(err, models.ErrDataSourceSecretsRequired) {
+			return response.Error(400, err.Error(), err)
+		}
+		return response.Error(500, "Failed to stage data source secrets", err)
+	}
+	return response.JSON(200, cmd.Result.ToDTO(time.Now()))
+}
+
+// DELETE /api/datasources/:id/secrets/rotation
+func DiscardDataSourceSecrets(c *models.ReqContext) response.Response {
+	ds, rsp := getDataSourceForSecretRotation(c, true)
+	if rsp != nil {
+		return rsp
+	}
+
+	cmd := models.DiscardDataSourceSecretsCommand{OrgId: c.OrgId, DatasourceId: ds.Id}
+	if err := bus.Dispatch(&cmd); err != nil {
+		if errors.Is(err, models.ErrDataSourceSecretsNotStaged) {
+			return response.Error(400, err.Error(), err)
+		}
+		return response.Error(500, "Failed to discard data source secrets", err)
+	}
+	return response.Success("Staged secrets discarded")
+}
+
+// POST /api/datasources/:id/secrets/rotation/validate
+//
+// ValidateDataSourceSecrets validates the staged secrets of a data source like the onboarding of the data source,
+// with the staged secrets in place of the current ones. The staged secrets pass the validation unless a step fails.
+func (hs *HTTPServer) ValidateDataSourceSecrets(c *models.ReqContext) response.Response {
+	ds, rsp := getDataSourceForSecretRotation(c, true)
+	if rsp != nil {
+		return rsp
+	}
+	rotation, rsp := getDataSourceSecretRotation(c.OrgId, ds.Id)
+	if rsp != nil {
+		return rsp
+	}
+	if len(rotation.StagedSecrets) == 0 {
+		return response.Error(400, models.ErrDataSourceSecretsNotStaged.Error(), models.ErrDataSourceSecretsNotStaged)
+	}
+
+	// the caches of the HTTP transports, the decrypted secrets and the plugin instances of the data source are
+	// invalidated by its updated time, so the candidate has its own
+	candidate := *ds
+	candidate.SecureJsonData = make(securejsondata.SecureJsonData, len(ds.SecureJsonData)+len(rotation.StagedSecrets))
+	for key, value := range ds.SecureJsonData {
+		candidate.SecureJsonData[key] = value
+	}
+	for key, value := range rotation.StagedSecrets {
+		candidate.SecureJsonData[key] = value
+	}
+	candidate.Updated = time.Now()
+	result := hs.DatasourceOnboarding.Validate(c.Req.Context(), c.SignedInUser, &candidate)
+	models.ClearDSCache(ds.Id)
+
+	validated := result.Status != onboarding.StatusError
+	cmd := models.SetDataSourceSecretsValidatedCommand{
+		OrgId:        c.OrgId,
+		DatasourceId: ds.Id,
+		Revision:     rotation.Revision,
+		Validated:    validated,
+	}
+	if err := bus.Dispatch(&cmd); err != nil {
+		if errors.Is(err, models.ErrDataSourceSecretsStagedChanged) {
+			return response.Error(409, "The staged secrets were changed during the validation. Please validate them again", err)
+		}
+		return response.Error(500, "Failed to validate data source secrets", err)
+	}
+
+	return response.JSON(200, util.DynMap{
+		"revision":   rotation.Revision,
+		"validated":  validated,
+		"validation": result,
+	})
+}
+
+// POST /api/datasources/:id/secrets/rotation/promote
+//
+// PromoteDataSourceSecrets replaces the secrets of a data source by its validated staged secrets, and keeps the
+// replaced secrets for the grace period of the secret rotation so that the promotion can be rolled back.
+func (hs *HTTPServer) PromoteDataSourceSecrets(c *models.ReqContext) response.Response {
+	ds, rsp := getDataSourceForSecretRotation(c, true)
+	if rsp != nil {
+		return rsp
+	}
+
+	oldVersion := latestDataSourceVersion(c.OrgId, ds.Id)
+	cmd := models.PromoteDataSourceSecretsCommand{
+		OrgId:        c.OrgId,
+		DatasourceId: ds.Id,
+		UserId:       c.UserId,
+		GracePeriod:  hs.Cfg.DataSourceSecretRotationGracePeriod,
+	}
+	if err := bus.Dispatch(&cmd); err != nil {
+		switch {
+		case errors.Is(err, models.ErrDataSourceSecretsNotStaged), errors.Is(err, models.ErrDataSourceSecretsNotValidated):
+			return response.Error(400, err.Error(), err)
+		case errors.Is(err, models.ErrDataSourceUpdatingOldVersion):
+			return response.Error(409, "Datasource has already been updated by someone else. Please reload and try again", err)
+		default:
+			return response.Error(500, "Failed to promote data source secrets", err)
+		}
+	}
+
+	return hs.dataSourceSecretsChanged(c, cmd.Result, oldVersion, "Staged secrets promoted")
+}
+
+// POST /api/datasources/:id/secrets/rotation/rollback
+//
+// RollbackDataSourceSecrets restores the secrets of a data source replaced by the latest promotion, during their
+// grace period.
+func (hs *HTTPServer) RollbackDataSourceSecrets(c *models.ReqContext) response.Response {
+	ds, rsp := getDataSourceForSecretRotation(c, true)
+	if rsp != nil {
+		return rsp
+	}
+
+	oldVersion := latestDataSourceVersion(c.OrgId, ds.Id)
+	cmd := models.RollbackDataSourceSecretsCommand{OrgId: c.OrgId, DatasourceId: ds.Id, UserId: c.UserId}
+	if err := bus.Dispatch(&cmd); err != nil {
+		switch {
+		case errors.Is(err, models.ErrDataSourceSecretsNoPrevious):
+			return response.Error(400, err.Error(), err)
+		case errors.Is(err, models.ErrDataSourceUpdatingOldVersion):
+			return response.Error(409, "Datasource has already been updated by someone else. Please reload and try again", err)
+		default:
+			return response.Error(500, "Failed to roll back data source secrets", err)
+		}
+	}
+
+	return hs.dataSourceSecretsChanged(c, cmd.Result, oldVersion, "Previous secrets restored")
+}
+
+// dataSourceSecretsChanged records the change of the secrets of a data source and returns the status of their
+// rotation.
+func (hs *HTTPServer) dataSourceSecretsChanged(c *models.ReqContext, ds *models.DataSource, oldVersion int64, message string) response.Response {
+	audit.RecordChange(dataSourceChangeEvent(c, ds, models.ChangeEventUpdated, oldVersion))
+	hs.Live.HandleDatasourceUpdate(c.OrgId, ds.Uid)
+
+	rotation, rsp := getDataSourceSecretRotation(c.OrgId, ds.Id)
+	if rsp != nil {
+		return rsp
+	}
+	return response.JSON(200, util.DynMap{
+		"message":  message,
+		"id":       ds.Id,
+		"version":  ds.Version,
+		"rotation": rotation.ToDTO(time.Now()),
+	})
+}
+
+// getDataSourceForSecretRotation returns the data source of the request, or else an error response. The secrets of
+// the read-only data sources can't be changed.
+func getDataSourceForSecretRotation(c *models.ReqContext, change bool) (*models.DataSource, response.Response) {
+	ds, err := getRawDataSourceById(c.ParamsInt64(":id"), c.OrgId)
+	if err != nil {
+		if errors.Is(err, models.ErrDataSourceNotFound) {
+			return nil, response.Error(404, "Data source not found", nil)
+		}
+		return nil, response.Error(500, "Failed to query datasource", err)
+	}
+	if change && ds.ReadOnly {
+		return nil, response.Error(403, "Cannot rotate the secrets of read-only data source", models.ErrDatasourceIsReadOnly)
+	}
+	return ds, nil
+}
+
+func getDataSourceSecretRotation(orgID, datasourceID int64) (*models.DataSourceSecretRotation, response.Response) {
+	query := models.GetDataSourceSecretRotationQuery{OrgId: orgID, DatasourceId: datasourceID}
+	if err := bus.Dispatch(&query); err != nil {
+		return nil, response.Error(500, "Failed to get data source secret rotation", err)
+	}
+	return query.Result, nil
+}
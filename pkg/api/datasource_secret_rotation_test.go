@@ -0,0 +1,100 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/securejsondata"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestDataSourceSecretRotation(t *testing.T) {
+	var staged *models.StageDataSourceSecretsCommand
+	addHandlers := func() {
+		bus.AddHandler("test", func(query *models.GetDataSourceQuery) error {
+			switch query.Id {
+			case 1:
+				query.Result = &models.DataSource{Id: 1, OrgId: testOrgID}
+			case 2:
+				query.Result = &models.DataSource{Id: 2, OrgId: testOrgID, ReadOnly: true}
+			default:
+				return models.ErrDataSourceNotFound
+			}
+			return nil
+		})
+		bus.AddHandler("test", func(query *models.GetDataSourceSecretRotationQuery) error {
+			query.Result = &models.DataSourceSecretRotation{
+				DatasourceId:  query.DatasourceId,
+				StagedSecrets: securejsondata.GetEncryptedJsonData(map[string]string{"password": "new"}),
+				Revision:      3,
+			}
+			return nil
+		})
+		bus.AddHandler("test", func(cmd *models.StageDataSourceSecretsCommand) error {
+			staged = cmd
+			cmd.Result = &models.DataSourceSecretRotation{DatasourceId: cmd.DatasourceId, Revision: 1}
+			return nil
+		})
+		bus.AddHandler("test", func(cmd *models.PromoteDataSourceSecretsCommand) error {
+			return models.ErrDataSourceSecretsNotValidated
+		})
+	}
+
+	call := func(t *testing.T, url, pattern string, handler func(c *models.ReqContext) response.Response) (int, []byte) {
+		t.Helper()
+		var code int
+		var body []byte
+		loggedInUserScenarioWithRole(t, "When calling POST on", "GET", url, pattern, models.ROLE_ADMIN,
+			func(sc *scenarioContext) {
+				addHandlers()
+				sc.handlerFunc = handler
+				sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+				code, body = sc.resp.Code, sc.resp.Body.Bytes()
+			})
+		return code, body
+	}
+	stage := func(c *models.ReqContext) response.Response {
+		return StageDataSourceSecrets(c, dtos.StageDataSourceSecretsCommand{SecureJsonData: map[string]string{"password": "new"}})
+	}
+
+	t.Run("Should return the keys of the staged secrets only", func(t *testing.T) {
+		code, body := call(t, "/api/datasources/1/secrets/rotation", "/api/datasources/:id/secrets/rotation",
+			GetDataSourceSecretRotation)
+		require.Equal(t, 200, code)
+		assert.Contains(t, string(body), `"stagedKeys":["password"]`)
+		assert.NotContains(t, string(body), "new")
+	})
+
+	t.Run("Should stage the secrets of a data source", func(t *testing.T) {
+		code, _ := call(t, "/api/datasources/1/secrets/rotation", "/api/datasources/:id/secrets/rotation", stage)
+		require.Equal(t, 200, code)
+		require.NotNil(t, staged)
+		assert.Equal(t, int64(1), staged.DatasourceId)
+		assert.Equal(t, map[string]string{"password": "new"}, staged.Secrets)
+	})
+
+	t.Run("Should not stage the secrets of a read-only data source", func(t *testing.T) {
+		staged = nil
+		code, _ := call(t, "/api/datasources/2/secrets/rotation", "/api/datasources/:id/secrets/rotation", stage)
+		assert.Equal(t, 403, code)
+		assert.Nil(t, staged)
+	})
+
+	t.Run("Should return 404 for a missing data source", func(t *testing.T) {
+		code, _ := call(t, "/api/datasources/3/secrets/rotation", "/api/datasources/:id/secrets/rotation", stage)
+		assert.Equal(t, 404, code)
+	})
+
+	t.Run("Should not promote staged secrets which weren't validated", func(t *testing.T) {
+		hs := &HTTPServer{Cfg: setting.NewCfg()}
+		code, _ := call(t, "/api/datasources/1/secrets/rotation/promote", "/api/datasources/:id/secrets/rotation/promote",
+			hs.PromoteDataSourceSecrets)
+		assert.Equal(t, 400, code)
+	})
+}
@@ -0,0 +1,186 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/audit"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// GET /api/datasources/:id/versions
+func GetDataSourceVersions(c *models.ReqContext) response.Response {
+	ds, rsp := getDataSourceForVersions(c)
+	if rsp != nil {
+		return rsp
+	}
+
+	query := models.GetDataSourceVersionsQuery{
+		OrgId:        c.OrgId,
+		DataSourceId: ds.Id,
+		Limit:        c.QueryInt("limit"),
+		Start:        c.QueryInt("start"),
+	}
+	if err := bus.Dispatch(&query); err != nil {
+		return response.Error(500, "Failed to get data source versions", err)
+	}
+	return response.JSON(200, query.Result)
+}
+
+// GET /api/datasources/:id/versions/:version
+func GetDataSourceVersion(c *models.ReqContext) response.Response {
+	ds, rsp := getDataSourceForVersions(c)
+	if rsp != nil {
+		return rsp
+	}
+
+	version, rsp := getDataSourceVersion(c, ds.Id, c.ParamsInt(":version"))
+	if rsp != nil {
+		return rsp
+	}
+	return response.JSON(200, version)
+}
+
+// GET /api/datasources/:id/versions/:version/diff
+//
+// GetDataSourceVersionDiff returns the diff of a version with the base query parameter version, or else with the
+// previous version, in the format of the diffType query parameter like the diffs of the dashboards.
+func GetDataSourceVersionDiff(c *models.ReqContext) response.Response {
+	ds, rsp := getDataSourceForVersions(c)
+	if rsp != nil {
+		return rsp
+	}
+
+	newVersion := c.ParamsInt(":version")
+	baseVersion := c.QueryInt("base")
+	if baseVersion == 0 {
+		baseVersion = newVersion - 1
+	}
+	if baseVersion < 1 {
+		return response.Error(400, fmt.Sprintf("Version %d has no previous version to compare with", newVersion), nil)
+	}
+
+	baseVer, rsp := getDataSourceVersion(c, ds.Id, baseVersion)
+	if rsp != nil {
+		return rsp
+	}
+	newVer, rsp := getDataSourceVersion(c, ds.Id, newVersion)
+	if rsp != nil {
+		return rsp
+	}
+
+	diffType := dashdiffs.ParseDiffType(c.Query("diffType"))
+	result, err := dashdiffs.CalculateJSONDiff(baseVer.Data, newVer.Data, diffType)
+	if err != nil {
+		if errors.Is(err, dashdiffs.ErrNilDiff) {
+			return response.Error(400, "The versions have no differences", err)
+		}
+		return response.Error(500, "Unable to compute diff", err)
+	}
+
+	if diffType == dashdiffs.DiffDelta {
+		return response.Respond(200, result.Delta).SetHeader("Content-Type", "application/json")
+	}
+	return response.Respond(200, result.Delta).SetHeader("Content-Type", "text/html")
+}
+
+// POST /api/datasources/:id/restore
+//
+// RestoreDataSourceVersion updates a data source with the configuration of a version. The passwords and the secure
+// JSON data aren't stored by the versions, so the current ones are kept.
+func (hs *HTTPServer) RestoreDataSourceVersion(c *models.ReqContext, apiCmd dtos.RestoreDataSourceVersionCommand) response.Response {
+	ds, rsp := getDataSourceForVersions(c)
+	if rsp != nil {
+		return rsp
+	}
+	if ds.ReadOnly {
+		return response.Error(403, "Cannot restore read-only data source", models.ErrDatasourceIsReadOnly)
+	}
+
+	version, rsp := getDataSourceVersion(c, ds.Id, apiCmd.Version)
+	if rsp != nil {
+		return rsp
+	}
+
+	data := version.Data
+	cmd := models.UpdateDataSourceCommand{
+		Name:              data.Get("name").MustString(),
+		Type:              data.Get("type").MustString(),
+		Access:            models.DsAccess(data.Get("access").MustString()),
+		Url:               data.Get("url").MustString(),
+		Password:          ds.Password,
+		User:              data.Get("user").MustString(),
+		Database:          data.Get("database").MustString(),
+		BasicAuth:         data.Get("basicAuth").MustBool(),
+		BasicAuthUser:     data.Get("basicAuthUser").MustString(),
+		BasicAuthPassword: ds.BasicAuthPassword,
+		WithCredentials:   data.Get("withCredentials").MustBool(),
+		IsDefault:         data.Get("isDefault").MustBool(),
+		JsonData:          data.Get("jsonData"),
+		SecureJsonData:    ds.SecureJsonData.Decrypt(),
+		Version:           ds.Version,
+		Uid:               ds.Uid,
+		OrgId:             c.OrgId,
+		Id:                ds.Id,
+		UserId:            c.UserId,
+		RestoredFrom:      version.Version,
+	}
+	if resp := validateURL(cmd.Type, cmd.Url); resp != nil {
+		return resp
+	}
+	if resp := validateJSONData(cmd.JsonData); resp != nil {
+		return resp
+	}
+
+	oldVersion := latestDataSourceVersion(c.OrgId, ds.Id)
+	if err := bus.Dispatch(&cmd); err != nil {
+		if errors.Is(err, models.ErrDataSourceUpdatingOldVersion) {
+			return response.Error(409, "Datasource has already been updated by someone else. Please reload and try again", err)
+		}
+		return response.Error(500, "Failed to restore datasource", err)
+	}
+
+	restored, err := getRawDataSourceById(ds.Id, c.OrgId)
+	if err != nil {
+		return response.Error(500, "Failed to query datasource", err)
+	}
+	audit.RecordChange(dataSourceChangeEvent(c, restored, models.ChangeEventRestored, oldVersion))
+	datasourceDTO := convertModelToDtos(restored)
+
+	hs.Live.HandleDatasourceUpdate(c.OrgId, datasourceDTO.UID)
+
+	return response.JSON(200, util.DynMap{
+		"message":    fmt.Sprintf("Datasource restored from version %d", version.Version),
+		"id":         ds.Id,
+		"name":       restored.Name,
+		"datasource": datasourceDTO,
+	})
+}
+
+// getDataSourceForVersions returns the data source of the request, or else an error response.
+func getDataSourceForVersions(c *models.ReqContext) (*models.DataSource, response.Response) {
+	ds, err := getRawDataSourceById(c.ParamsInt64(":id"), c.OrgId)
+	if err != nil {
+		if errors.Is(err, models.ErrDataSourceNotFound) {
+			return nil, response.Error(404, "Data source not found", nil)
+		}
+		return nil, response.Error(500, "Failed to query datasource", err)
+	}
+	return ds, nil
+}
+
+func getDataSourceVersion(c *models.ReqContext, dataSourceID int64, version int) (*models.DataSourceVersion, response.Response) {
+	query := models.GetDataSourceVersionQuery{OrgId: c.OrgId, DataSourceId: dataSourceID, Version: version}
+	if err := bus.Dispatch(&query); err != nil {
+		if errors.Is(err, models.ErrDataSourceVersionNotFound) {
+			return nil, response.Error(404, fmt.Sprintf("Data source version %d not found", version), nil)
+		}
+		return nil, response.Error(500, "Failed to get data source version", err)
+	}
+	return query.Result, nil
+}
@@ -10,9 +10,13 @@ import (
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/fieldmask"
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins/adapters"
+	"github.com/grafana/grafana/pkg/services/audit"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
 	"github.com/grafana/grafana/pkg/util"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
@@ -84,6 +88,47 @@ func GetDataSourceById(c *models.ReqContext) response.Response {
 	return response.JSON(200, &dtos)
 }
 
+// sqlDatasourceTypes are the types of the data sources with a connection pool.
+var sqlDatasourceTypes = map[string]bool{
+	models.DS_MYSQL: true,
+	"postgres":      true,
+	"mssql":         true,
+}
+
+// GetDataSourceConnectionPool returns the connection pool settings and statistics of a SQL data source.
+func GetDataSourceConnectionPool(c *models.ReqContext) response.Response {
+	query := models.GetDataSourceQuery{
+		Id:    c.ParamsInt64(":id"),
+		OrgId: c.OrgId,
+	}
+
+	if err := bus.Dispatch(&query); err != nil {
+		if errors.Is(err, models.ErrDataSourceNotFound) {
+			return response.Error(404, "Data source not found", nil)
+		}
+		if errors.Is(err, models.ErrDataSourceIdentifierNotSet) {
+			return response.Error(400, "Datasource id is missing", nil)
+		}
+		return response.Error(500, "Failed to query datasources", err)
+	}
+
+	ds := query.Result
+	if !sqlDatasourceTypes[ds.Type] {
+		return response.Error(400, "Data source has no connection pool", nil)
+	}
+
+	result := dtos.DataSourceConnectionPool{Settings: sqleng.PoolSettingsFromJSONData(ds.JsonData)}
+	stats, err := sqleng.GetPoolStats(ds)
+	if err != nil && !errors.Is(err, sqleng.ErrPoolNotFound) {
+		return response.Error(500, "Failed to get connection pool statistics", err)
+	}
+	if err == nil {
+		result.Stats = &stats
+	}
+
+	return response.JSON(200, result)
+}
+
 func (hs *HTTPServer) DeleteDataSourceById(c *models.ReqContext) response.Response {
 	id := c.ParamsInt64(":id")
 
@@ -104,11 +149,13 @@ func (hs *HTTPServer) DeleteDataSourceById(c *models.ReqContext) response.Respon
 	}
 
 	cmd := &models.DeleteDataSourceCommand{ID: id, OrgID: c.OrgId}
+	oldVersion := latestDataSourceVersion(c.OrgId, ds.Id)
 
 	err = bus.Dispatch(cmd)
 	if err != nil {
 		return response.Error(500, "Failed to delete datasource", err)
 	}
+	audit.RecordChange(dataSourceChangeEvent(c, ds, models.ChangeEventDeleted, oldVersion))
 
 	hs.Live.HandleDatasourceDelete(c.OrgId, ds.Uid)
 
@@ -151,11 +198,13 @@ func (hs *HTTPServer) DeleteDataSourceByUID(c *models.ReqContext) response.Respo
 	}
 
 	cmd := &models.DeleteDataSourceCommand{UID: uid, OrgID: c.OrgId}
+	oldVersion := latestDataSourceVersion(c.OrgId, ds.Id)
 
 	err = bus.Dispatch(cmd)
 	if err != nil {
 		return response.Error(500, "Failed to delete datasource", err)
 	}
+	audit.RecordChange(dataSourceChangeEvent(c, ds, models.ChangeEventDeleted, oldVersion))
 
 	hs.Live.HandleDatasourceDelete(c.OrgId, ds.Uid)
 
@@ -182,10 +231,12 @@ func (hs *HTTPServer) DeleteDataSourceByName(c *models.ReqContext) response.Resp
 	}
 
 	cmd := &models.DeleteDataSourceCommand{Name: name, OrgID: c.OrgId}
+	oldVersion := latestDataSourceVersion(c.OrgId, getCmd.Result.Id)
 	err := bus.Dispatch(cmd)
 	if err != nil {
 		return response.Error(500, "Failed to delete datasource", err)
 	}
+	audit.RecordChange(dataSourceChangeEvent(c, getCmd.Result, models.ChangeEventDeleted, oldVersion))
 
 	hs.Live.HandleDatasourceDelete(c.OrgId, getCmd.Result.Uid)
 
@@ -207,12 +258,27 @@ func validateURL(tp string, u string) response.Response {
 	return nil
 }
 
+func validateJSONData(jsonData *simplejson.Json) response.Response {
+	if _, err := models.QueryExamplesFromJSONData(jsonData); err != nil {
+		return response.Error(400, err.Error(), err)
+	}
+	if _, err := models.WarmUpQueriesFromJSONData(jsonData); err != nil {
+		return response.Error(400, err.Error(), err)
+	}
+
+	return nil
+}
+
 func AddDataSource(c *models.ReqContext, cmd models.AddDataSourceCommand) response.Response {
 	datasourcesLogger.Debug("Received command to add data source", "url", cmd.Url)
 	cmd.OrgId = c.OrgId
+	cmd.UserId = c.UserId
 	if resp := validateURL(cmd.Type, cmd.Url); resp != nil {
 		return resp
 	}
+	if resp := validateJSONData(cmd.JsonData); resp != nil {
+		return resp
+	}
 
 	if err := bus.Dispatch(&cmd); err != nil {
 		if errors.Is(err, models.ErrDataSourceNameExists) || errors.Is(err, models.ErrDataSourceUidExists) {
@@ -221,6 +287,7 @@ func AddDataSource(c *models.ReqContext, cmd models.AddDataSourceCommand) respon
 
 		return response.Error(500, "Failed to add datasource", err)
 	}
+	audit.RecordChange(dataSourceChangeEvent(c, cmd.Result, models.ChangeEventCreated, 0))
 
 	ds := convertModelToDtos(cmd.Result)
 	return response.JSON(200, util.DynMap{
@@ -234,16 +301,21 @@ func AddDataSource(c *models.ReqContext, cmd models.AddDataSourceCommand) respon
 func (hs *HTTPServer) UpdateDataSource(c *models.ReqContext, cmd models.UpdateDataSourceCommand) response.Response {
 	datasourcesLogger.Debug("Received command to update data source", "url", cmd.Url)
 	cmd.OrgId = c.OrgId
+	cmd.UserId = c.UserId
 	cmd.Id = c.ParamsInt64(":id")
 	if resp := validateURL(cmd.Type, cmd.Url); resp != nil {
 		return resp
 	}
+	if resp := validateJSONData(cmd.JsonData); resp != nil {
+		return resp
+	}
 
 	err := fillWithSecureJSONData(&cmd)
 	if err != nil {
 		return response.Error(500, "Failed to update datasource", err)
 	}
 
+	oldVersion := latestDataSourceVersion(c.OrgId, cmd.Id)
 	err = bus.Dispatch(&cmd)
 	if err != nil {
 		if errors.Is(err, models.ErrDataSourceUpdatingOldVersion) {
@@ -264,6 +336,8 @@ func (hs *HTTPServer) UpdateDataSource(c *models.ReqContext, cmd models.UpdateDa
 		return response.Error(500, "Failed to query datasource", err)
 	}
 
+	audit.RecordChange(dataSourceChangeEvent(c, query.Result, models.ChangeEventUpdated, oldVersion))
+
 	datasourceDTO := convertModelToDtos(query.Result)
 
 	hs.Live.HandleDatasourceUpdate(c.OrgId, datasourceDTO.UID)
@@ -276,6 +350,198 @@ func (hs *HTTPServer) UpdateDataSource(c *models.ReqContext, cmd models.UpdateDa
 	})
 }
 
+// dataSourceMaskFields are the fields of a data source that a field mask can
+// update.
+var dataSourceMaskFields = []string{"name", "type", "access", "url", "password", "user", "database", "basicAuth",
+	"basicAuthUser", "basicAuthPassword", "withCredentials", "isDefault", "jsonData", "secureJsonData", "version"}
+
+// upsertRetries is the number of times an upsert without a version reads the
+// resource again when it was updated by someone else in the meantime.
+const upsertRetries = 3
+
+// PUT /api/datasources/uid/:uid
+//
+// UpsertDataSourceByUID creates the data source of the UID if it does not
+// exist, or else replaces it, or only the fields of the fieldMask query
+// parameter, so that the same configuration can be applied again and again.
+// The secure JSON data fields that are not sent are kept.
+func (hs *HTTPServer) UpsertDataSourceByUID(c *models.ReqContext) response.Response {
+	uid := c.Params(":uid")
+	if !util.IsValidShortUID(uid) || len(uid) > 40 {
+		return response.Error(400, "Invalid data source UID", nil)
+	}
+
+	mask := fieldmask.Parse(c.Query(fieldmask.QueryParam))
+	if err := mask.Validate(dataSourceMaskFields...); err != nil {
+		return response.Error(400, err.Error(), err)
+	}
+
+	body, err := c.Req.Body().Bytes()
+	if err != nil {
+		return response.Error(400, "Failed to read the request body", err)
+	}
+
+	for attempt := 0; ; attempt++ {
+		ds, err := getRawDataSourceByUID(uid, c.OrgId)
+		if errors.Is(err, models.ErrDataSourceNotFound) {
+			resp, created := hs.createDataSourceWithUID(c, uid, body)
+			if created || attempt >= upsertRetries {
+				return resp
+			}
+			// created by someone else in the meantime
+			continue
+		}
+		if err != nil {
+			return response.Error(500, "Failed to query datasource", err)
+		}
+
+		resp, retry := hs.updateDataSourceWithMask(c, ds, mask, body)
+		if !retry || attempt >= upsertRetries {
+			return resp
+		}
+	}
+}
+
+// createDataSourceWithUID returns the response of the creation of the data
+// source, and false if a data source with the UID was created in the
+// meantime.
+func (hs *HTTPServer) createDataSourceWithUID(c *models.ReqContext, uid string, body []byte) (response.Response, bool) {
+	var cmd models.AddDataSourceCommand
+	if err := json.Unmarshal(body, &cmd); err != nil {
+		return response.Error(400, "Invalid data source", err), true
+	}
+	if cmd.Uid != "" && cmd.Uid != uid {
+		return response.Error(400, "The data source UID does not match the URL", nil), true
+	}
+	if cmd.Name == "" || cmd.Type == "" || cmd.Access == "" {
+		return response.Error(400, "Data source name, type and access are required", nil), true
+	}
+	cmd.Uid = uid
+	cmd.OrgId = c.OrgId
+	cmd.UserId = c.UserId
+
+	limitReached, err := hs.QuotaService.QuotaReached(c, "data_source")
+	if err != nil {
+		return response.Error(500, "Failed to get quota", err), true
+	}
+	if limitReached {
+		return response.Error(403, "Quota reached", nil), true
+	}
+
+	if resp := validateURL(cmd.Type, cmd.Url); resp != nil {
+		return resp, true
+	}
+	if resp := validateJSONData(cmd.JsonData); resp != nil {
+		return resp, true
+	}
+
+	if err := bus.Dispatch(&cmd); err != nil {
+		if errors.Is(err, models.ErrDataSourceUidExists) {
+			return response.Error(409, err.Error(), err), false
+		}
+		if errors.Is(err, models.ErrDataSourceNameExists) {
+			return response.Error(409, err.Error(), err), true
+		}
+		return response.Error(500, "Failed to add datasource", err), true
+	}
+	audit.RecordChange(dataSourceChangeEvent(c, cmd.Result, models.ChangeEventCreated, 0))
+
+	return response.JSON(200, util.DynMap{
+		"message":    "Datasource added",
+		"id":         cmd.Result.Id,
+		"name":       cmd.Result.Name,
+		"datasource": convertModelToDtos(cmd.Result),
+	}), true
+}
+
+// updateDataSourceWithMask returns the response of the update of the data
+// source, and true if it was updated by someone else in the meantime and the
+// request did not set the version.
+func (hs *HTTPServer) updateDataSourceWithMask(c *models.ReqContext, ds *models.DataSource, mask fieldmask.FieldMask, body []byte) (response.Response, bool) {
+	if ds.ReadOnly {
+		return response.Error(403, "Cannot update read-only data source", models.ErrDatasourceIsReadOnly), false
+	}
+
+	existing := models.UpdateDataSourceCommand{
+		Name:              ds.Name,
+		Type:              ds.Type,
+		Access:            ds.Access,
+		Url:               ds.Url,
+		Password:          ds.Password,
+		User:              ds.User,
+		Database:          ds.Database,
+		BasicAuth:         ds.BasicAuth,
+		BasicAuthUser:     ds.BasicAuthUser,
+		BasicAuthPassword: ds.BasicAuthPassword,
+		WithCredentials:   ds.WithCredentials,
+		IsDefault:         ds.IsDefault,
+		JsonData:          ds.JsonData,
+		Version:           ds.Version,
+		Uid:               ds.Uid,
+	}
+
+	var cmd models.UpdateDataSourceCommand
+	if err := mask.Merge(existing, json.RawMessage(body), &cmd); err != nil {
+		return response.Error(400, "Invalid data source", err), false
+	}
+	if cmd.Uid != "" && cmd.Uid != ds.Uid {
+		return response.Error(400, "The data source UID does not match the URL", nil), false
+	}
+	if cmd.Name == "" || cmd.Type == "" || cmd.Access == "" {
+		return response.Error(400, "Data source name, type and access are required", nil), false
+	}
+	cmd.Id = ds.Id
+	cmd.Uid = ds.Uid
+	cmd.OrgId = c.OrgId
+	cmd.UserId = c.UserId
+
+	// without a version the update must be of the data source that was read
+	versioned := cmd.Version != 0 && (mask.IsEmpty() || mask.Has("version"))
+	if !versioned {
+		cmd.Version = ds.Version
+	}
+
+	if resp := validateURL(cmd.Type, cmd.Url); resp != nil {
+		return resp, false
+	}
+	if resp := validateJSONData(cmd.JsonData); resp != nil {
+		return resp, false
+	}
+
+	if cmd.SecureJsonData == nil {
+		cmd.SecureJsonData = map[string]string{}
+	}
+	for k, v := range ds.SecureJsonData.Decrypt() {
+		if _, ok := cmd.SecureJsonData[k]; !ok {
+			cmd.SecureJsonData[k] = v
+		}
+	}
+
+	oldVersion := latestDataSourceVersion(c.OrgId, ds.Id)
+	if err := bus.Dispatch(&cmd); err != nil {
+		if errors.Is(err, models.ErrDataSourceUpdatingOldVersion) {
+			return response.Error(409, "Datasource has already been updated by someone else. Please reload and try again", err), !versioned
+		}
+		return response.Error(500, "Failed to update datasource", err), false
+	}
+
+	updated, err := getRawDataSourceById(cmd.Id, c.OrgId)
+	if err != nil {
+		return response.Error(500, "Failed to query datasource", err), false
+	}
+	audit.RecordChange(dataSourceChangeEvent(c, updated, models.ChangeEventUpdated, oldVersion))
+	datasourceDTO := convertModelToDtos(updated)
+
+	hs.Live.HandleDatasourceUpdate(c.OrgId, datasourceDTO.UID)
+
+	return response.JSON(200, util.DynMap{
+		"message":    "Datasource updated",
+		"id":         cmd.Id,
+		"name":       cmd.Name,
+		"datasource": datasourceDTO,
+	}), false
+}
+
 func fillWithSecureJSONData(cmd *models.UpdateDataSourceCommand) error {
 	if len(cmd.SecureJsonData) == 0 {
 		return nil
@@ -482,3 +748,99 @@ func (hs *HTTPServer) CheckDatasourceHealth(c *models.ReqContext) response.Respo
 
 	return response.JSON(200, payload)
 }
+
+// ValidateDatasourceOnboarding validates the setup of a data source step by
+// step: its settings, the connection, the health check, a sample query and
+// the permissions of the configured user.
+// POST /api/datasources/:id/onboarding
+func (hs *HTTPServer) ValidateDatasourceOnboarding(c *models.ReqContext) response.Response {
+	datasourceID := c.ParamsInt64(":id")
+
+	ds, err := hs.DatasourceCache.GetDatasource(datasourceID, c.SignedInUser, c.SkipCache)
+	if err != nil {
+		if errors.Is(err, models.ErrDataSourceNotFound) {
+			return response.Error(404, "Data source not found", err)
+		}
+		if errors.Is(err, models.ErrDataSourceAccessDenied) {
+			return response.Error(403, "Access denied to datasource", err)
+		}
+		return response.Error(500, "Unable to load datasource metadata", err)
+	}
+
+	return response.JSON(200, hs.DatasourceOnboarding.Validate(c.Req.Context(), c.SignedInUser, ds))
+}
+
+// GetDataSourceQueryExamples returns the query examples of a data source, for
+// query editors to offer them as starters, and its default query.
+// GET /api/datasources/:id/query-examples
+func (hs *HTTPServer) GetDataSourceQueryExamples(c *models.ReqContext) response.Response {
+	datasourceID := c.ParamsInt64(":id")
+
+	ds, err := hs.DatasourceCache.GetDatasource(datasourceID, c.SignedInUser, c.SkipCache)
+	if err != nil {
+		if errors.Is(err, models.ErrDataSourceNotFound) {
+			return response.Error(404, "Data source not found", err)
+		}
+		if errors.Is(err, models.ErrDataSourceAccessDenied) {
+			return response.Error(403, "Access denied to datasource", err)
+		}
+		return response.Error(500, "Unable to load datasource metadata", err)
+	}
+
+	examples, err := ds.QueryExamples()
+	if err != nil {
+		return response.Error(500, "Invalid query examples of datasource", err)
+	}
+
+	result := dtos.DataSourceQueryExamples{Examples: examples}
+	for i := range examples {
+		if examples[i].Default {
+			result.Default = &examples[i]
+		}
+	}
+
+	return response.JSON(200, result)
+}
+
+// GetDataSourceWarmUpRuns returns the last runs of the warm-up queries of a
+// data source.
+// GET /api/datasources/:id/warm-up-runs
+func (hs *HTTPServer) GetDataSourceWarmUpRuns(c *models.ReqContext) response.Response {
+	datasourceID := c.ParamsInt64(":id")
+
+	ds, err := hs.DatasourceCache.GetDatasource(datasourceID, c.SignedInUser, c.SkipCache)
+	if err != nil {
+		if errors.Is(err, models.ErrDataSourceNotFound) {
+			return response.Error(404, "Data source not found", err)
+		}
+		if errors.Is(err, models.ErrDataSourceAccessDenied) {
+			return response.Error(403, "Access denied to datasource", err)
+		}
+		return response.Error(500, "Unable to load datasource metadata", err)
+	}
+
+	queries, err := ds.WarmUpQueries()
+	if err != nil {
+		return response.Error(500, "Invalid warm-up queries of datasource", err)
+	}
+
+	runs := hs.DataSourceWarmUp.Runs(ds.Id)
+	result := make([]dtos.DataSourceWarmUpQueryRuns, 0, len(queries))
+	for _, query := range queries {
+		queryRuns := dtos.DataSourceWarmUpQueryRuns{
+			Name:     query.Name,
+			Interval: query.Interval,
+			Runs:     make([]dtos.DataSourceWarmUpRun, 0, len(runs[query.Name])),
+		}
+		for _, run := range runs[query.Name] {
+			queryRuns.Runs = append(queryRuns.Runs, dtos.DataSourceWarmUpRun{
+				Started:    run.Started,
+				DurationMs: run.Duration.Milliseconds(),
+				Error:      run.Error,
+			})
+		}
+		result = append(result, queryRuns)
+	}
+
+	return response.JSON(200, result)
+}
@@ -2,11 +2,19 @@ package api
 
 import (
 	"encoding/json"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/securejsondata"
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/live"
+	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/services/warmup"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -162,3 +170,278 @@ func TestUpdateDataSource_URLWithoutProtocol(t *testing.T) {
 
 	assert.Equal(t, 200, sc.resp.Code)
 }
+
+// Adding data sources with invalid query examples should lead to an error.
+func TestAddDataSource_InvalidQueryExamples(t *testing.T) {
+	defer bus.ClearBusHandlers()
+
+	sc := setupScenarioContext(t, "/api/datasources")
+
+	sc.m.Post(sc.url, routing.Wrap(func(c *models.ReqContext) response.Response {
+		return AddDataSource(c, models.AddDataSourceCommand{
+			Name: "Test",
+			Url:  "http://localhost:9090",
+			JsonData: simplejson.NewFromAny(map[string]interface{}{
+				"queryExamples": []interface{}{map[string]interface{}{"title": "Without query"}},
+			}),
+		})
+	}))
+
+	sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+
+	assert.Equal(t, 400, sc.resp.Code)
+}
+
+type fakeDatasourceCache struct {
+	datasource   *models.DataSource
+	skippedCache bool
+}
+
+func (c *fakeDatasourceCache) GetDatasource(datasourceID int64, user *models.SignedInUser, skipCache bool) (*models.DataSource, error) {
+	if c.datasource == nil || c.datasource.Id != datasourceID {
+		return nil, models.ErrDataSourceNotFound
+	}
+	return c.datasource, nil
+}
+
+func (c *fakeDatasourceCache) GetDatasourceByUID(datasourceUID string, user *models.SignedInUser, skipCache bool) (*models.DataSource, error) {
+	c.skippedCache = skipCache
+	if c.datasource == nil || c.datasource.Uid != datasourceUID {
+		return nil, models.ErrDataSourceNotFound
+	}
+	return c.datasource, nil
+}
+
+func TestGetDataSourceQueryExamples(t *testing.T) {
+	hs := &HTTPServer{
+		Cfg: setting.NewCfg(),
+		DatasourceCache: &fakeDatasourceCache{datasource: &models.DataSource{
+			Id: 1,
+			JsonData: simplejson.NewFromAny(map[string]interface{}{
+				"queryExamples": []interface{}{
+					map[string]interface{}{"title": "Requests", "query": map[string]interface{}{"expr": "sum(rate(requests[5m]))"}},
+					map[string]interface{}{"title": "Availability SLO", "description": "30 days availability",
+						"query": map[string]interface{}{"expr": "slo:availability:30d"}, "default": true},
+				},
+			}),
+		}},
+	}
+
+	loggedInUserScenarioWithRole(t, "When calling GET on", "GET", "/api/datasources/1/query-examples",
+		"/api/datasources/:id/query-examples", models.ROLE_VIEWER, func(sc *scenarioContext) {
+			sc.handlerFunc = hs.GetDataSourceQueryExamples
+			sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+			require.Equal(t, 200, sc.resp.Code)
+
+			result := struct {
+				Default  *models.DataSourceQueryExample  `json:"default"`
+				Examples []models.DataSourceQueryExample `json:"examples"`
+			}{}
+			require.NoError(t, json.NewDecoder(sc.resp.Body).Decode(&result))
+			require.Len(t, result.Examples, 2)
+			require.NotNil(t, result.Default)
+			assert.Equal(t, "Availability SLO", result.Default.Title)
+			assert.Equal(t, "slo:availability:30d", result.Default.Query.Get("expr").MustString())
+		})
+
+	loggedInUserScenarioWithRole(t, "When calling GET on", "GET", "/api/datasources/2/query-examples",
+		"/api/datasources/:id/query-examples", models.ROLE_VIEWER, func(sc *scenarioContext) {
+			sc.handlerFunc = hs.GetDataSourceQueryExamples
+			sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+			assert.Equal(t, 404, sc.resp.Code)
+		})
+}
+
+func TestGetDataSourceWarmUpRuns(t *testing.T) {
+	warmUp := &warmup.Service{}
+	require.NoError(t, warmUp.Init())
+	hs := &HTTPServer{
+		Cfg: setting.NewCfg(),
+		DatasourceCache: &fakeDatasourceCache{datasource: &models.DataSource{
+			Id: 1,
+			JsonData: simplejson.NewFromAny(map[string]interface{}{
+				"warmUpQueries": []interface{}{
+					map[string]interface{}{"name": "Prime", "query": map[string]interface{}{"rawSql": "SELECT 1"}, "interval": "5m"},
+				},
+			}),
+		}},
+		DataSourceWarmUp: warmUp,
+	}
+
+	loggedInUserScenarioWithRole(t, "When calling GET on", "GET", "/api/datasources/1/warm-up-runs",
+		"/api/datasources/:id/warm-up-runs", models.ROLE_ADMIN, func(sc *scenarioContext) {
+			sc.handlerFunc = hs.GetDataSourceWarmUpRuns
+			sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+			require.Equal(t, 200, sc.resp.Code)
+
+			var result []dtos.DataSourceWarmUpQueryRuns
+			require.NoError(t, json.NewDecoder(sc.resp.Body).Decode(&result))
+			require.Len(t, result, 1)
+			assert.Equal(t, "Prime", result[0].Name)
+			assert.Equal(t, "5m", result[0].Interval)
+			assert.Empty(t, result[0].Runs)
+		})
+
+	loggedInUserScenarioWithRole(t, "When calling GET on", "GET", "/api/datasources/2/warm-up-runs",
+		"/api/datasources/:id/warm-up-runs", models.ROLE_ADMIN, func(sc *scenarioContext) {
+			sc.handlerFunc = hs.GetDataSourceWarmUpRuns
+			sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+			assert.Equal(t, 404, sc.resp.Code)
+		})
+}
+
+func TestUpsertDataSourceByUID(t *testing.T) {
+	hs := &HTTPServer{
+		Cfg:          setting.NewCfg(),
+		QuotaService: &quota.QuotaService{Cfg: setting.NewCfg()},
+		Live:         &live.GrafanaLive{},
+	}
+
+	existing := func() *models.DataSource {
+		return &models.DataSource{
+			Id:             1,
+			Uid:            "prom",
+			OrgId:          testOrgID,
+			Name:           "Prometheus",
+			Type:           "prometheus",
+			Access:         models.DS_ACCESS_PROXY,
+			Url:            "http://localhost:9090",
+			JsonData:       simplejson.NewFromAny(map[string]interface{}{"httpMethod": "GET", "timeInterval": "15s"}),
+			SecureJsonData: securejsondata.GetEncryptedJsonData(map[string]string{"basicAuthPassword": "secret"}),
+			Version:        3,
+		}
+	}
+
+	upsert := func(t *testing.T, body string, queryParams string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		sc := setupScenarioContext(t, "/api/datasources/uid/prom")
+		sc.m.Put("/api/datasources/uid/:uid", routing.Wrap(func(c *models.ReqContext) response.Response {
+			c.SignedInUser = &models.SignedInUser{OrgId: testOrgID, UserId: testUserID, OrgRole: models.ROLE_ADMIN}
+			return hs.UpsertDataSourceByUID(c)
+		}))
+		sc.resp = httptest.NewRecorder()
+		sc.req = httptest.NewRequest("PUT", sc.url+queryParams, strings.NewReader(body))
+		sc.exec()
+		return sc.resp
+	}
+
+	t.Run("creates the data source if it does not exist", func(t *testing.T) {
+		t.Cleanup(bus.ClearBusHandlers)
+
+		bus.AddHandler("test", func(query *models.GetDataSourceQuery) error {
+			return models.ErrDataSourceNotFound
+		})
+		var added *models.AddDataSourceCommand
+		bus.AddHandler("test", func(cmd *models.AddDataSourceCommand) error {
+			added = cmd
+			cmd.Result = &models.DataSource{Id: 1, Uid: cmd.Uid, Name: cmd.Name, Type: cmd.Type}
+			return nil
+		})
+
+		resp := upsert(t, `{"name":"Prometheus","type":"prometheus","access":"proxy","url":"http://localhost:9090"}`, "")
+		require.Equal(t, 200, resp.Code)
+		require.NotNil(t, added)
+		assert.Equal(t, "prom", added.Uid)
+		assert.Equal(t, testOrgID, added.OrgId)
+		assert.Equal(t, "http://localhost:9090", added.Url)
+	})
+
+	t.Run("requires the name, type and access to create the data source", func(t *testing.T) {
+		t.Cleanup(bus.ClearBusHandlers)
+
+		bus.AddHandler("test", func(query *models.GetDataSourceQuery) error {
+			return models.ErrDataSourceNotFound
+		})
+
+		resp := upsert(t, `{"name":"Prometheus"}`, "")
+		assert.Equal(t, 400, resp.Code)
+	})
+
+	t.Run("updates the fields of the field mask", func(t *testing.T) {
+		t.Cleanup(bus.ClearBusHandlers)
+
+		bus.AddHandler("test", func(query *models.GetDataSourceQuery) error {
+			query.Result = existing()
+			return nil
+		})
+		var updated *models.UpdateDataSourceCommand
+		bus.AddHandler("test", func(cmd *models.UpdateDataSourceCommand) error {
+			updated = cmd
+			return nil
+		})
+
+		body := `{"url":"http://prometheus:9090","jsonData":{"httpMethod":"POST","timeInterval":"1m"}}`
+		resp := upsert(t, body, "?fieldMask=url,jsonData.httpMethod")
+		require.Equal(t, 200, resp.Code)
+		require.NotNil(t, updated)
+		assert.Equal(t, int64(1), updated.Id)
+		assert.Equal(t, "prom", updated.Uid)
+		assert.Equal(t, "Prometheus", updated.Name)
+		assert.Equal(t, "http://prometheus:9090", updated.Url)
+		assert.Equal(t, "POST", updated.JsonData.Get("httpMethod").MustString())
+		assert.Equal(t, "15s", updated.JsonData.Get("timeInterval").MustString())
+		assert.Equal(t, 3, updated.Version)
+		assert.Equal(t, map[string]string{"basicAuthPassword": "secret"}, updated.SecureJsonData)
+	})
+
+	t.Run("retries the update without a version when updated in the meantime", func(t *testing.T) {
+		t.Cleanup(bus.ClearBusHandlers)
+
+		reads := 0
+		bus.AddHandler("test", func(query *models.GetDataSourceQuery) error {
+			reads++
+			query.Result = existing()
+			query.Result.Version += reads
+			return nil
+		})
+		var versions []int
+		bus.AddHandler("test", func(cmd *models.UpdateDataSourceCommand) error {
+			versions = append(versions, cmd.Version)
+			if len(versions) == 1 {
+				return models.ErrDataSourceUpdatingOldVersion
+			}
+			return nil
+		})
+
+		resp := upsert(t, `{"url":"http://prometheus:9090"}`, "?fieldMask=url")
+		require.Equal(t, 200, resp.Code)
+		assert.Equal(t, []int{4, 5}, versions)
+	})
+
+	t.Run("does not retry the update of a version", func(t *testing.T) {
+		t.Cleanup(bus.ClearBusHandlers)
+
+		bus.AddHandler("test", func(query *models.GetDataSourceQuery) error {
+			query.Result = existing()
+			return nil
+		})
+		updates := 0
+		bus.AddHandler("test", func(cmd *models.UpdateDataSourceCommand) error {
+			updates++
+			return models.ErrDataSourceUpdatingOldVersion
+		})
+
+		resp := upsert(t, `{"url":"http://prometheus:9090","version":2}`, "?fieldMask=url,version")
+		assert.Equal(t, 409, resp.Code)
+		assert.Equal(t, 1, updates)
+	})
+
+	t.Run("does not update read-only data sources", func(t *testing.T) {
+		t.Cleanup(bus.ClearBusHandlers)
+
+		bus.AddHandler("test", func(query *models.GetDataSourceQuery) error {
+			query.Result = existing()
+			query.Result.ReadOnly = true
+			return nil
+		})
+
+		resp := upsert(t, `{"url":"http://prometheus:9090"}`, "?fieldMask=url")
+		assert.Equal(t, 403, resp.Code)
+	})
+
+	t.Run("rejects the fields that cannot be updated", func(t *testing.T) {
+		resp := upsert(t, `{"uid":"other"}`, "?fieldMask=uid")
+		assert.Equal(t, 400, resp.Code)
+	})
+}
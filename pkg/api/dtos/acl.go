@@ -7,8 +7,9 @@ type UpdateDashboardAclCommand struct {
 }
 
 type DashboardAclUpdateItem struct {
-	UserID     int64                 `json:"userId"`
-	TeamID     int64                 `json:"teamId"`
-	Role       *models.RoleType      `json:"role,omitempty"`
-	Permission models.PermissionType `json:"permission"`
+	UserID        int64                 `json:"userId"`
+	TeamID        int64                 `json:"teamId"`
+	ExternalGroup string                `json:"externalGroup,omitempty"`
+	Role          *models.RoleType      `json:"role,omitempty"`
+	Permission    models.PermissionType `json:"permission"`
 }
@@ -0,0 +1,11 @@
+package dtos
+
+// InvalidateCachesCommand selects the caches to invalidate.
+type InvalidateCachesCommand struct {
+	// DataSourceUIDs are the UIDs of the data sources of which to invalidate the cached data.
+	DataSourceUIDs []string `json:"datasourceUids"`
+	// FrontendSettings invalidates the cached frontend settings of the org.
+	FrontendSettings bool `json:"frontendSettings"`
+	// VariableOptions invalidates the cached options of the query variables of the org.
+	VariableOptions bool `json:"variableOptions"`
+}
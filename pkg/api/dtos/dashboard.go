@@ -30,6 +30,8 @@ type DashboardMeta struct {
 	FolderUrl             string    `json:"folderUrl"`
 	Provisioned           bool      `json:"provisioned"`
 	ProvisionedExternalId string    `json:"provisionedExternalId"`
+	ProvisionedOverlay    bool      `json:"provisionedOverlay"`
+	PanelsSummarized      bool      `json:"panelsSummarized,omitempty"`
 }
 
 type DashboardFullWithMeta struct {
@@ -37,11 +39,55 @@ type DashboardFullWithMeta struct {
 	Dashboard *simplejson.Json `json:"dashboard"`
 }
 
+// ResolveDashboardCommand selects the values of the variables and the time range of a resolved dashboard.
+type ResolveDashboardCommand struct {
+	// Variables are the values of the variables by name, the current values of the other variables are used
+	Variables map[string]interface{} `json:"variables"`
+	// From and To are the time range, the time range of the dashboard by default
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ResolvedDashboard is the model of a dashboard resolved for the values of its variables and a time range.
+type ResolvedDashboard struct {
+	Dashboard *simplejson.Json `json:"dashboard"`
+	// Variables are the values of the variables by name
+	Variables map[string][]string `json:"variables"`
+	// From and To are the time range in epoch milliseconds
+	From    int64 `json:"from"`
+	To      int64 `json:"to"`
+	Version int   `json:"version"`
+	// Warnings are the panels and queries which couldn't be resolved, e.g. of which the data source is missing
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// DashboardPanel is a panel of a dashboard, and the version of the dashboard.
+type DashboardPanel struct {
+	Panel   *simplejson.Json `json:"panel"`
+	Version int              `json:"version"`
+}
+
 type TrimDashboardFullWithMeta struct {
 	Meta      *simplejson.Json `json:"meta"`
 	Dashboard *simplejson.Json `json:"dashboard"`
 }
 
+type ProvisionedDashboardDrift struct {
+	Id                    int64  `json:"id"`
+	Uid                   string `json:"uid"`
+	Title                 string `json:"title"`
+	Url                   string `json:"url"`
+	Version               int    `json:"version"`
+	Provisioner           string `json:"provisioner"`
+	ProvisionedExternalId string `json:"provisionedExternalId"`
+}
+
+type ProvisionedDashboardDriftWithSource struct {
+	ProvisionedDashboardDrift
+	Dashboard   *simplejson.Json `json:"dashboard"`
+	Provisioned *simplejson.Json `json:"provisioned"`
+}
+
 type DashboardRedirect struct {
 	RedirectUri string `json:"redirectUri"`
 }
@@ -61,3 +107,9 @@ type CalculateDiffTarget struct {
 type RestoreDashboardVersionCommand struct {
 	Version int `json:"version" binding:"Required"`
 }
+
+// ReviewDashboardRevisionForm approves or rejects a pending dashboard revision, with an optional comment for its
+// author.
+type ReviewDashboardRevisionForm struct {
+	Comment string `json:"comment"`
+}
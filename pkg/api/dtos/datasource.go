@@ -2,9 +2,11 @@ package dtos
 
 import (
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
 )
 
 type DataSource struct {
@@ -62,3 +64,57 @@ func (slice DataSourceList) Less(i, j int) bool {
 func (slice DataSourceList) Swap(i, j int) {
 	slice[i], slice[j] = slice[j], slice[i]
 }
+
+// DataSourceConnectionPool is the connection pool of a SQL data source, Stats is nil until the data source is
+// queried.
+type DataSourceConnectionPool struct {
+	Settings sqleng.PoolSettings `json:"settings"`
+	Stats    *sqleng.PoolStats   `json:"stats"`
+}
+
+// DataSourceQueryExamples are the query examples of a data source, Default is nil when none of them is the default
+// query.
+type DataSourceQueryExamples struct {
+	Default  *models.DataSourceQueryExample  `json:"default"`
+	Examples []models.DataSourceQueryExample `json:"examples"`
+}
+
+// DataSourceWarmUpQueryRuns are the last runs of a warm-up query of a data source, newest first.
+type DataSourceWarmUpQueryRuns struct {
+	Name     string                `json:"name"`
+	Interval string                `json:"interval"`
+	Runs     []DataSourceWarmUpRun `json:"runs"`
+}
+
+type DataSourceWarmUpRun struct {
+	Started    time.Time `json:"started"`
+	DurationMs int64     `json:"durationMs"`
+	Error      string    `json:"error,omitempty"`
+}
+
+type RestoreDataSourceVersionCommand struct {
+	Version int `json:"version" binding:"Required"`
+}
+
+// StageDataSourceSecretsCommand stages secrets of a data source, the keys of its secure JSON data to rotate.
+type StageDataSourceSecretsCommand struct {
+	SecureJsonData map[string]string `json:"secureJsonData" binding:"Required"`
+}
+
+// UpdateDataSourceRecordingCommand changes the mode of the recording of the data source responses.
+type UpdateDataSourceRecordingCommand struct {
+	// Mode is either off, record or replay.
+	Mode string `json:"mode" binding:"Required"`
+}
+
+// UpdateDataSourceExplorePermissionsCommand replaces the explore permissions of a data source, granted each to a
+// user, a team or a role.
+type UpdateDataSourceExplorePermissionsCommand struct {
+	Items []DataSourceExplorePermissionItem `json:"items"`
+}
+
+type DataSourceExplorePermissionItem struct {
+	UserID int64            `json:"userId"`
+	TeamID int64            `json:"teamId"`
+	Role   *models.RoleType `json:"role,omitempty"`
+}
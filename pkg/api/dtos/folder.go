@@ -23,3 +23,8 @@ type FolderSearchHit struct {
 	Uid   string `json:"uid"`
 	Title string `json:"title"`
 }
+
+// SetFolderReviewersForm replaces the reviewers of the folder with the users of the IDs.
+type SetFolderReviewersForm struct {
+	UserIds []int64 `json:"userIds"`
+}
@@ -6,7 +6,10 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/querylint"
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/components/transformations"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/setting"
@@ -42,15 +45,75 @@ type CurrentUser struct {
 	HelpFlags1                 models.HelpFlags1  `json:"helpFlags1"`
 	HasEditPermissionInFolders bool               `json:"hasEditPermissionInFolders"`
 	Permissions                UserPermissionsMap `json:"permissions,omitempty"`
+	// ImpersonatorLogin is the login of the Grafana admin impersonating the user, if any
+	ImpersonatorLogin string `json:"impersonatorLogin,omitempty"`
 }
 
 type UserPermissionsMap map[string]bool
 
 type MetricRequest struct {
-	From    string             `json:"from"`
-	To      string             `json:"to"`
+	From      string             `json:"from"`
+	To        string             `json:"to"`
+	Queries   []*simplejson.Json `json:"queries"`
+	Debug     bool               `json:"debug"`
+	DataLinks *DataLinksRequest  `json:"dataLinks,omitempty"`
+	// Transformations are applied to the frames of all queries on the server,
+	// like the transformations of a panel.
+	Transformations []transformations.Transformation `json:"transformations,omitempty"`
+	// DashboardID and PanelID identify the panel of the queries, of which the
+	// last successful results are returned when the queries fail, if enabled.
+	// The queries of no panel of a dashboard the user can view are checked
+	// against the explore permissions of the data sources.
+	DashboardID int64 `json:"dashboardId,omitempty"`
+	PanelID     int64 `json:"panelId,omitempty"`
+}
+
+// DataLinksRequest asks for the data links of the returned frames to be
+// resolved on the server, e.g. for rendered panels.
+type DataLinksRequest struct {
+	// Links are added to every non-time field, e.g. the data links of the panel.
+	Links []data.DataLink `json:"links"`
+	// Variables maps dashboard variable names to their current value or values.
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// VariableOptionsRequest asks for the options of a query variable, resolved by running its query on the server.
+type VariableOptionsRequest struct {
+	DatasourceUID string `json:"datasourceUid"`
+	// DashboardID is the dashboard of the variable, the queries of the variables
+	// of no dashboard the user can view are checked against the explore
+	// permissions of the data source.
+	DashboardID int64 `json:"dashboardId,omitempty"`
+	// Query is the query of the variable, with the variables it references already replaced
+	Query *simplejson.Json `json:"query"`
+	From  string           `json:"from"`
+	To    string           `json:"to"`
+}
+
+// VariableOption is an option of a query variable.
+type VariableOption struct {
+	Text  string `json:"text"`
+	Value string `json:"value"`
+}
+
+type VariableOptionsResponse struct {
+	Options []VariableOption `json:"options"`
+	// Cached is whether the options were cached instead of resolved by the request
+	Cached bool `json:"cached"`
+}
+
+// LintQueriesRequest asks for the warnings of the static analysis of queries,
+// e.g. by the query editors.
+type LintQueriesRequest struct {
+	// Queries are the queries of a panel, the queries with a datasourceId are
+	// linted by the type of the data source, the others by their properties.
 	Queries []*simplejson.Json `json:"queries"`
-	Debug   bool               `json:"debug"`
+}
+
+// QueryLintResult is the warnings about a query.
+type QueryLintResult struct {
+	RefID    string              `json:"refId"`
+	Warnings []querylint.Warning `json:"warnings"`
 }
 
 func GetGravatarUrl(text string) string {
@@ -12,3 +12,8 @@ type UpdateOrgAddressForm struct {
 	State    string `json:"state"`
 	Country  string `json:"country"`
 }
+
+// SetReadOnlyFoldersForm replaces the read-only folders of the org with the folders of the UIDs.
+type SetReadOnlyFoldersForm struct {
+	FolderUids []string `json:"folderUids"`
+}
@@ -1,13 +1,17 @@
 package dtos
 
 type Prefs struct {
-	Theme           string `json:"theme"`
-	HomeDashboardID int64  `json:"homeDashboardId"`
-	Timezone        string `json:"timezone"`
+	Theme                string `json:"theme"`
+	HomeDashboardID      int64  `json:"homeDashboardId"`
+	Timezone             string `json:"timezone"`
+	WeekStart            string `json:"weekStart"`
+	FiscalYearStartMonth int    `json:"fiscalYearStartMonth"`
 }
 
 type UpdatePrefsCmd struct {
-	Theme           string `json:"theme"`
-	HomeDashboardID int64  `json:"homeDashboardId"`
-	Timezone        string `json:"timezone"`
+	Theme                string `json:"theme"`
+	HomeDashboardID      int64  `json:"homeDashboardId"`
+	Timezone             string `json:"timezone"`
+	WeekStart            string `json:"weekStart"`
+	FiscalYearStartMonth int    `json:"fiscalYearStartMonth"`
 }
@@ -1,5 +1,7 @@
 package dtos
 
+import "github.com/grafana/grafana/pkg/models"
+
 type SignUpForm struct {
 	Email string `json:"email" binding:"Required"`
 }
@@ -29,6 +31,11 @@ type AdminUpdateUserPermissionsForm struct {
 	IsGrafanaAdmin bool `json:"isGrafanaAdmin"`
 }
 
+type StartImpersonationForm struct {
+	Reason   string `json:"reason"`
+	Duration string `json:"duration"`
+}
+
 type SendResetPasswordEmailForm struct {
 	UserOrEmail string `json:"userOrEmail" binding:"Required"`
 }
@@ -44,3 +51,13 @@ type UserLookupDTO struct {
 	Login     string `json:"login"`
 	AvatarURL string `json:"avatarUrl"`
 }
+
+// UserBootData is what the frontend loads for the signed in user on every page load. Permissions are the scopes of
+// the fine-grained permissions by action, empty when access control is disabled.
+type UserBootData struct {
+	User              *models.UserProfileDTO `json:"user"`
+	Teams             []*models.TeamDTO      `json:"teams"`
+	Preferences       Prefs                  `json:"preferences"`
+	Permissions       map[string][]string    `json:"permissions"`
+	StarredDashboards []int64                `json:"starredDashboards"`
+}
@@ -14,3 +14,17 @@ type UserToken struct {
 	CreatedAt              time.Time `json:"createdAt"`
 	SeenAt                 time.Time `json:"seenAt"`
 }
+
+type LoginHistoryEntry struct {
+	Id                     int64     `json:"id"`
+	Success                bool      `json:"success"`
+	AuthModule             string    `json:"authModule"`
+	ClientIp               string    `json:"clientIp"`
+	Location               string    `json:"location"`
+	Device                 string    `json:"device"`
+	OperatingSystem        string    `json:"os"`
+	OperatingSystemVersion string    `json:"osVersion"`
+	Browser                string    `json:"browser"`
+	BrowserVersion         string    `json:"browserVersion"`
+	CreatedAt              time.Time `json:"createdAt"`
+}
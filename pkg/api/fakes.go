@@ -5,7 +5,9 @@ import "github.com/grafana/grafana/pkg/plugins"
 type fakePluginManager struct {
 	plugins.Manager
 
-	staticRoutes []*plugins.PluginStaticRoute
+	staticRoutes   []*plugins.PluginStaticRoute
+	plugins        []*plugins.PluginBase
+	scanningErrors []plugins.PluginError
 }
 
 func (pm *fakePluginManager) GetPlugin(id string) *plugins.PluginBase {
@@ -23,3 +25,11 @@ func (pm *fakePluginManager) Renderer() *plugins.RendererPlugin {
 func (pm *fakePluginManager) StaticRoutes() []*plugins.PluginStaticRoute {
 	return pm.staticRoutes
 }
+
+func (pm *fakePluginManager) Plugins() []*plugins.PluginBase {
+	return pm.plugins
+}
+
+func (pm *fakePluginManager) ScanningErrors() []plugins.PluginError {
+	return pm.scanningErrors
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/filestorage"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// multipart overhead allowed on top of the maximum file size
+const fileUploadFormOverhead = 1 << 20
+
+// GetFiles lists the files of the current organization
+// GET /api/files?prefix=
+func (hs *HTTPServer) GetFiles(c *models.ReqContext) response.Response {
+	files, err := hs.FileStorage.List(c.Req.Context(), c.OrgId, c.Query("prefix"))
+	if err != nil {
+		return response.Error(500, "Failed to list files", err)
+	}
+
+	used, err := hs.FileStorage.Usage(c.Req.Context(), c.OrgId)
+	if err != nil {
+		return response.Error(500, "Failed to get file storage usage", err)
+	}
+
+	return response.JSON(200, map[string]interface{}{
+		"files": files,
+		"usage": map[string]int64{
+			"used":  used,
+			"quota": hs.Cfg.FileStorage.OrgQuota,
+		},
+	})
+}
+
+// GetFile returns the content of a file
+// GET /api/files/*
+func (hs *HTTPServer) GetFile(c *models.ReqContext) response.Response {
+	file, data, err := hs.FileStorage.Get(c.Req.Context(), c.OrgId, c.Params("*"))
+	if err != nil {
+		return fileStorageErrorToResponse(err, "Failed to get file")
+	}
+
+	return response.Respond(200, data).
+		SetHeader("Content-Type", file.ContentType).
+		SetHeader("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; sandbox").
+		SetHeader("X-Content-Type-Options", "nosniff").
+		SetHeader("Cache-Control", "private, max-age=60")
+}
+
+// UploadFile stores the file of a multipart form, using the path form field or
+// the name of the uploaded file as path.
+// POST /api/files
+func (hs *HTTPServer) UploadFile(c *models.ReqContext) response.Response {
+	c.Req.Request.Body = http.MaxBytesReader(c.Resp, c.Req.Request.Body, hs.Cfg.FileStorage.MaxFileSize+fileUploadFormOverhead)
+	if err := c.Req.ParseMultipartForm(fileUploadFormOverhead); err != nil {
+		return response.Error(400, "Invalid file upload, expected a multipart form not larger than the maximum file size", err)
+	}
+
+	formFile, header, err := c.Req.FormFile("file")
+	if err != nil {
+		return response.Error(400, "Missing file", err)
+	}
+	defer func() {
+		if err := formFile.Close(); err != nil {
+			hs.log.Warn("Failed to close uploaded file", "err", err)
+		}
+	}()
+
+	data, err := ioutil.ReadAll(formFile)
+	if err != nil {
+		return response.Error(400, "Failed to read uploaded file", err)
+	}
+
+	filePath := c.Req.FormValue("path")
+	if filePath == "" {
+		filePath = header.Filename
+	}
+
+	file, err := hs.FileStorage.Upload(c.Req.Context(), c.SignedInUser, filePath, data)
+	if err != nil {
+		return fileStorageErrorToResponse(err, "Failed to upload file")
+	}
+
+	return response.JSON(200, file)
+}
+
+// DeleteFile removes a file
+// DELETE /api/files/*
+func (hs *HTTPServer) DeleteFile(c *models.ReqContext) response.Response {
+	if err := hs.FileStorage.Delete(c.Req.Context(), c.OrgId, c.Params("*")); err != nil {
+		return fileStorageErrorToResponse(err, "Failed to delete file")
+	}
+
+	return response.Success("File deleted")
+}
+
+func fileStorageErrorToResponse(err error, message string) response.Response {
+	switch {
+	case errors.Is(err, filestorage.ErrFileNotFound):
+		return response.Error(404, err.Error(), nil)
+	case errors.Is(err, filestorage.ErrInvalidPath),
+		errors.Is(err, filestorage.ErrContentTypeNotAllowed),
+		errors.Is(err, filestorage.ErrContentTypeMismatch):
+		return response.Error(400, err.Error(), nil)
+	case errors.Is(err, filestorage.ErrFileTooLarge):
+		return response.Error(413, err.Error(), nil)
+	case errors.Is(err, filestorage.ErrQuotaReached):
+		return response.Error(403, err.Error(), nil)
+	case errors.Is(err, filestorage.ErrFileStorageUnavailable):
+		return response.Error(503, err.Error(), nil)
+	}
+
+	return response.Error(500, message, err)
+}
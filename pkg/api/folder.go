@@ -6,7 +6,9 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/components/fieldmask"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/audit"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/guardian"
 	"github.com/grafana/grafana/pkg/services/libraryelements"
@@ -58,36 +60,106 @@ func (hs *HTTPServer) GetFolderByID(c *models.ReqContext) response.Response {
 
 func (hs *HTTPServer) CreateFolder(c *models.ReqContext, cmd models.CreateFolderCommand) response.Response {
 	s := dashboards.NewFolderService(c.OrgId, c.SignedInUser, hs.SQLStore)
-	folder, err := s.CreateFolder(cmd.Title, cmd.Uid)
+	folder, err := hs.createFolder(c, s, cmd.Title, cmd.Uid)
 	if err != nil {
 		return ToFolderErrorResponse(err)
 	}
 
+	g := guardian.New(folder.Id, c.OrgId, c.SignedInUser)
+	return response.JSON(200, toFolderDto(g, folder))
+}
+
+func (hs *HTTPServer) createFolder(c *models.ReqContext, s dashboards.FolderService, title, uid string) (*models.Folder, error) {
+	folder, err := s.CreateFolder(title, uid)
+	if err != nil {
+		return nil, err
+	}
+	audit.RecordChange(folderChangeEvent(c, folder, models.ChangeEventCreated))
+
 	if hs.Cfg.EditorsCanAdmin {
 		if err := s.MakeUserAdmin(c.OrgId, c.SignedInUser.UserId, folder.Id, true); err != nil {
 			hs.log.Error("Could not make user admin", "folder", folder.Title, "user",
 				c.SignedInUser.UserId, "error", err)
 		}
 	}
-
-	g := guardian.New(folder.Id, c.OrgId, c.SignedInUser)
-	return response.JSON(200, toFolderDto(g, folder))
+	return folder, nil
 }
 
+// folderMaskFields are the fields of a folder that a field mask can update.
+var folderMaskFields = []string{"title", "uid", "version"}
+
+// PUT /api/folders/:uid
+//
+// UpdateFolder creates the folder of the UID if it does not exist, or else
+// updates it, or only the fields of the fieldMask query parameter.
 func (hs *HTTPServer) UpdateFolder(c *models.ReqContext, cmd models.UpdateFolderCommand) response.Response {
-	s := dashboards.NewFolderService(c.OrgId, c.SignedInUser, hs.SQLStore)
-	err := s.UpdateFolder(c.Params(":uid"), &cmd)
-	if err != nil {
-		return ToFolderErrorResponse(err)
+	uid := c.Params(":uid")
+	mask := fieldmask.Parse(c.Query(fieldmask.QueryParam))
+	if err := mask.Validate(folderMaskFields...); err != nil {
+		return response.Error(400, err.Error(), err)
 	}
 
-	g := guardian.New(cmd.Result.Id, c.OrgId, c.SignedInUser)
-	return response.JSON(200, toFolderDto(g, cmd.Result))
+	s := dashboards.NewFolderService(c.OrgId, c.SignedInUser, hs.SQLStore)
+	for attempt := 0; ; attempt++ {
+		existing, err := s.GetFolderByUID(uid)
+		if errors.Is(err, models.ErrFolderNotFound) {
+			folder, err := hs.createFolder(c, s, cmd.Title, uid)
+			if errors.Is(err, models.ErrFolderWithSameUIDExists) && attempt < upsertRetries {
+				// created by someone else in the meantime
+				continue
+			}
+			if err != nil {
+				return ToFolderErrorResponse(err)
+			}
+
+			g := guardian.New(folder.Id, c.OrgId, c.SignedInUser)
+			return response.JSON(200, toFolderDto(g, folder))
+		}
+		if err != nil {
+			return ToFolderErrorResponse(err)
+		}
+		if rsp := hs.checkReadOnlyFolders(c, existing.Id); rsp != nil {
+			return rsp
+		}
+
+		update := cmd
+		retryable := false
+		if !mask.IsEmpty() {
+			update = models.UpdateFolderCommand{}
+			current := models.UpdateFolderCommand{Uid: existing.Uid, Title: existing.Title, Version: existing.Version}
+			if err := mask.Merge(current, cmd, &update); err != nil {
+				return response.Error(400, "Invalid folder", err)
+			}
+			update.Overwrite = cmd.Overwrite
+			// without a version the update must be of the folder that was read
+			retryable = !mask.Has("version") && !cmd.Overwrite
+		}
+
+		err = s.UpdateFolder(uid, &update)
+		if errors.Is(err, models.ErrFolderVersionMismatch) && retryable && attempt < upsertRetries {
+			continue
+		}
+		if err != nil {
+			return ToFolderErrorResponse(err)
+		}
+		audit.RecordChange(folderChangeEvent(c, update.Result, models.ChangeEventUpdated))
+
+		g := guardian.New(update.Result.Id, c.OrgId, c.SignedInUser)
+		return response.JSON(200, toFolderDto(g, update.Result))
+	}
 }
 
 func (hs *HTTPServer) DeleteFolder(c *models.ReqContext) response.Response { // temporarily adding this function to HTTPServer, will be removed from HTTPServer when librarypanels featuretoggle is removed
 	s := dashboards.NewFolderService(c.OrgId, c.SignedInUser, hs.SQLStore)
-	err := hs.LibraryElementService.DeleteLibraryElementsInFolder(c, c.Params(":uid"))
+	folder, err := s.GetFolderByUID(c.Params(":uid"))
+	if err != nil {
+		return ToFolderErrorResponse(err)
+	}
+	if rsp := hs.checkReadOnlyFolders(c, folder.Id); rsp != nil {
+		return rsp
+	}
+
+	err = hs.LibraryElementService.DeleteLibraryElementsInFolder(c, c.Params(":uid"))
 	if err != nil {
 		if errors.Is(err, libraryelements.ErrFolderHasConnectedLibraryElements) {
 			return response.Error(403, "Folder could not be deleted because it contains library elements in use", err)
@@ -99,6 +171,7 @@ func (hs *HTTPServer) DeleteFolder(c *models.ReqContext) response.Response { //
 	if err != nil {
 		return ToFolderErrorResponse(err)
 	}
+	audit.RecordChange(folderChangeEvent(c, f, models.ChangeEventDeleted))
 
 	return response.JSON(200, util.DynMap{
 		"title":   f.Title,
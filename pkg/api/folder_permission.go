@@ -7,6 +7,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/audit"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/guardian"
 	"github.com/grafana/grafana/pkg/util"
@@ -80,14 +81,15 @@ func (hs *HTTPServer) UpdateFolderPermissions(c *models.ReqContext, apiCmd dtos.
 	var items []*models.DashboardAcl
 	for _, item := range apiCmd.Items {
 		items = append(items, &models.DashboardAcl{
-			OrgID:       c.OrgId,
-			DashboardID: folder.Id,
-			UserID:      item.UserID,
-			TeamID:      item.TeamID,
-			Role:        item.Role,
-			Permission:  item.Permission,
-			Created:     time.Now(),
-			Updated:     time.Now(),
+			OrgID:         c.OrgId,
+			DashboardID:   folder.Id,
+			UserID:        item.UserID,
+			TeamID:        item.TeamID,
+			ExternalGroup: item.ExternalGroup,
+			Role:          item.Role,
+			Permission:    item.Permission,
+			Created:       time.Now(),
+			Updated:       time.Now(),
 		})
 	}
 
@@ -124,6 +126,7 @@ func (hs *HTTPServer) UpdateFolderPermissions(c *models.ReqContext, apiCmd dtos.
 
 		return response.Error(500, "Failed to create permission", err)
 	}
+	audit.RecordChange(permissionsChangeEvent(c, folder.Id, folder.Uid, folder.Title))
 
 	return response.JSON(200, util.DynMap{
 		"message": "Folder permissions updated",
@@ -81,7 +81,8 @@ func TestFoldersAPIEndpoint(t *testing.T) {
 		}
 
 		mock := &fakeFolderService{
-			UpdateFolderResult: &models.Folder{Id: 1, Uid: "uid", Title: "Folder upd"},
+			GetFolderByUIDResult: &models.Folder{Id: 1, Uid: "uid", Title: "Folder"},
+			UpdateFolderResult:   &models.Folder{Id: 1, Uid: "uid", Title: "Folder upd"},
 		}
 
 		updateFolderScenario(t, "When calling PUT on", "/api/folders/uid", "/api/folders/:uid", mock, cmd,
@@ -97,6 +98,61 @@ func TestFoldersAPIEndpoint(t *testing.T) {
 			})
 	})
 
+	t.Run("Given a request for updating a folder that does not exist", func(t *testing.T) {
+		cmd := models.UpdateFolderCommand{
+			Title: "Folder",
+		}
+
+		mock := &fakeFolderService{
+			GetFolderByUIDError: models.ErrFolderNotFound,
+			CreateFolderResult:  &models.Folder{Id: 1, Uid: "uid", Title: "Folder"},
+		}
+
+		updateFolderScenario(t, "When calling PUT on", "/api/folders/uid", "/api/folders/:uid", mock, cmd,
+			func(sc *scenarioContext) {
+				callUpdateFolder(sc)
+				require.Equal(t, 200, sc.resp.Code)
+
+				assert.Equal(t, []string{"uid"}, mock.CreatedFolderUids)
+				assert.Empty(t, mock.UpdatedFolderCmds)
+
+				folder := dtos.Folder{}
+				err := json.NewDecoder(sc.resp.Body).Decode(&folder)
+				require.NoError(t, err)
+				assert.Equal(t, int64(1), folder.Id)
+				assert.Equal(t, "uid", folder.Uid)
+			})
+	})
+
+	t.Run("Given a request for updating the fields of a field mask of a folder", func(t *testing.T) {
+		cmd := models.UpdateFolderCommand{
+			Uid:   "other",
+			Title: "Folder upd",
+		}
+
+		mock := &fakeFolderService{
+			GetFolderByUIDResult: &models.Folder{Id: 1, Uid: "uid", Title: "Folder", Version: 3},
+			UpdateFolderResult:   &models.Folder{Id: 1, Uid: "uid", Title: "Folder upd", Version: 4},
+		}
+
+		updateFolderScenario(t, "When calling PUT on", "/api/folders/uid", "/api/folders/:uid", mock, cmd,
+			func(sc *scenarioContext) {
+				sc.fakeReqWithParams("PUT", sc.url, map[string]string{"fieldMask": "title"}).exec()
+				require.Equal(t, 200, sc.resp.Code)
+
+				require.Len(t, mock.UpdatedFolderCmds, 1)
+				assert.Equal(t, "uid", mock.UpdatedFolderCmds[0].Uid)
+				assert.Equal(t, "Folder upd", mock.UpdatedFolderCmds[0].Title)
+				assert.Equal(t, 3, mock.UpdatedFolderCmds[0].Version)
+			})
+
+		updateFolderScenario(t, "When calling PUT with an invalid field mask on", "/api/folders/uid", "/api/folders/:uid", mock, cmd,
+			func(sc *scenarioContext) {
+				sc.fakeReqWithParams("PUT", sc.url, map[string]string{"fieldMask": "id"}).exec()
+				assert.Equal(t, 400, sc.resp.Code)
+			})
+	})
+
 	t.Run("Given incorrect requests for updating a folder", func(t *testing.T) {
 		testCases := []struct {
 			Error              error
@@ -119,7 +175,8 @@ func TestFoldersAPIEndpoint(t *testing.T) {
 
 		for _, tc := range testCases {
 			mock := &fakeFolderService{
-				UpdateFolderError: tc.Error,
+				GetFolderByUIDResult: &models.Folder{Id: 1, Uid: "uid", Title: "Folder"},
+				UpdateFolderError:    tc.Error,
 			}
 
 			updateFolderScenario(t, fmt.Sprintf("Expect '%s' error when calling PUT on", tc.Error.Error()),
@@ -174,6 +231,9 @@ func updateFolderScenario(t *testing.T, desc string, url string, routePattern st
 	cmd models.UpdateFolderCommand, fn scenarioFunc) {
 	t.Run(fmt.Sprintf("%s %s", desc, url), func(t *testing.T) {
 		defer bus.ClearBusHandlers()
+		bus.AddHandler("test", func(query *models.IsReadOnlyFolderQuery) error {
+			return nil
+		})
 
 		hs := HTTPServer{
 			Cfg: setting.NewCfg(),
@@ -215,6 +275,8 @@ type fakeFolderService struct {
 	DeleteFolderResult   *models.Folder
 	DeleteFolderError    error
 	DeletedFolderUids    []string
+	CreatedFolderUids    []string
+	UpdatedFolderCmds    []models.UpdateFolderCommand
 }
 
 func (s *fakeFolderService) GetFolders(limit int64) ([]*models.Folder, error) {
@@ -230,10 +292,12 @@ func (s *fakeFolderService) GetFolderByUID(uid string) (*models.Folder, error) {
 }
 
 func (s *fakeFolderService) CreateFolder(title, uid string) (*models.Folder, error) {
+	s.CreatedFolderUids = append(s.CreatedFolderUids, uid)
 	return s.CreateFolderResult, s.CreateFolderError
 }
 
 func (s *fakeFolderService) UpdateFolder(existingUID string, cmd *models.UpdateFolderCommand) error {
+	s.UpdatedFolderCmds = append(s.UpdatedFolderCmds, *cmd)
 	cmd.Result = s.UpdateFolderResult
 	return s.UpdateFolderError
 }
@@ -6,14 +6,23 @@ import (
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 var frontendLogger = log.New("frontend")
 
 type frontendLogMessageHandler func(c *models.ReqContext, event frontendlogging.FrontendSentryEvent) response.Response
 
-func NewFrontendLogMessageHandler(store *frontendlogging.SourceMapStore) frontendLogMessageHandler {
+func NewFrontendLogMessageHandler(cfg *setting.Cfg, store *frontendlogging.SourceMapStore,
+	forwarder *frontendlogging.SentryForwarder) frontendLogMessageHandler {
 	return func(c *models.ReqContext, event frontendlogging.FrontendSentryEvent) response.Response {
+		if !frontendlogging.Sampled(cfg.Sentry.BackendSampleRate) {
+			return response.Success("ok")
+		}
+		if cfg.Sentry.ScrubPII {
+			event.ScrubPII()
+		}
+
 		var msg = "unknown"
 
 		if len(event.Message) > 0 {
@@ -35,6 +44,10 @@ func NewFrontendLogMessageHandler(store *frontendlogging.SourceMapStore) fronten
 			frontendLogger.Info(msg, ctx)
 		}
 
+		if forwarder != nil {
+			forwarder.Forward(event, store)
+		}
+
 		return response.Success("ok")
 	}
 }
@@ -0,0 +1,143 @@
+package frontendlogging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// maxPendingForwards is the number of events being forwarded at the same time to each server, the events received
+// while it's reached are dropped
+const maxPendingForwards = 10
+
+// Sampled returns whether an event is kept, at a rate between 0 (none) and 1 (all)
+func Sampled(rate float64) bool {
+	return rate >= 1 || rand.Float64() < rate
+}
+
+type sentrySink struct {
+	dsn     *sentry.Dsn
+	pending chan struct{}
+}
+
+// SentryForwarder forwards the events received by the log endpoint to Sentry compatible servers, with their stack
+// traces resolved with the source maps.
+type SentryForwarder struct {
+	sinks  []*sentrySink
+	client *http.Client
+}
+
+// NewSentryForwarder returns a forwarder to the servers of the DSNs, nil when there are none.
+func NewSentryForwarder(dsns []string) (*SentryForwarder, error) {
+	if len(dsns) == 0 {
+		return nil, nil
+	}
+
+	forwarder := &SentryForwarder{client: &http.Client{Timeout: 10 * time.Second}}
+	for _, rawDSN := range dsns {
+		dsn, err := sentry.NewDsn(rawDSN)
+		if err != nil {
+			return nil, err
+		}
+		forwarder.sinks = append(forwarder.sinks, &sentrySink{dsn: dsn, pending: make(chan struct{}, maxPendingForwards)})
+	}
+	return forwarder, nil
+}
+
+// Forward sends an event to the servers in the background.
+func (f *SentryForwarder) Forward(event FrontendSentryEvent, store *SourceMapStore) {
+	payload, err := event.sentryPayload(store)
+	if err != nil {
+		logger.Error("Failed to encode frontend event for Sentry", "err", err)
+		return
+	}
+
+	for _, sink := range f.sinks {
+		select {
+		case sink.pending <- struct{}{}:
+		default:
+			logger.Warn("Dropping frontend event, too many events being forwarded", "host", sink.dsn.StoreAPIURL().Host)
+			continue
+		}
+		go func(sink *sentrySink) {
+			defer func() { <-sink.pending }()
+			if err := f.send(sink.dsn, payload); err != nil {
+				logger.Warn("Failed to forward frontend event to Sentry", "host", sink.dsn.StoreAPIURL().Host, "err", err)
+			}
+		}(sink)
+	}
+}
+
+func (f *SentryForwarder) send(dsn *sentry.Dsn, payload []byte) error {
+	req, err := http.NewRequest(http.MethodPost, dsn.StoreAPIURL().String(), bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	for name, value := range dsn.RequestHeaders() {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "err", err)
+		}
+	}()
+	if _, err := io.Copy(ioutil.Discard, resp.Body); err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// sentryPayload returns the event in the format of the store endpoint of Sentry, with the stack frames resolved with
+// the source maps when possible
+func (event *FrontendSentryEvent) sentryPayload(store *SourceMapStore) ([]byte, error) {
+	data, err := json.Marshal(event.Event)
+	if err != nil {
+		return nil, err
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, err
+	}
+
+	if payload["platform"] == nil {
+		payload["platform"] = "javascript"
+	}
+	if event.Exception != nil {
+		payload["exception"] = event.Exception.resolved(store)
+	}
+	return json.Marshal(payload)
+}
+
+// resolved returns a copy of the exception with the frames of its stack traces resolved with the source maps
+func (exception *FrontendSentryException) resolved(store *SourceMapStore) *FrontendSentryException {
+	result := &FrontendSentryException{Values: make([]FrontendSentryExceptionValue, 0, len(exception.Values))}
+	for _, value := range exception.Values {
+		frames := make([]sentry.Frame, 0, len(value.Stacktrace.Frames))
+		for _, frame := range value.Stacktrace.Frames {
+			mappedFrame, err := store.resolveSourceLocation(frame)
+			if err == nil && mappedFrame != nil {
+				mappedFrame.InApp = true
+				frame = *mappedFrame
+			}
+			frames = append(frames, frame)
+		}
+		value.Stacktrace = sentry.Stacktrace{Frames: frames, FramesOmitted: value.Stacktrace.FramesOmitted}
+		result.Values = append(result.Values, value)
+	}
+	return result
+}
@@ -0,0 +1,107 @@
+package frontendlogging
+
+import (
+	"regexp"
+	"strings"
+)
+
+// The personal data and the credentials removed from the events by ScrubPII
+var (
+	emailPattern       = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	ipv4Pattern        = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+	authPattern        = regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9\-._~+/]+=*`)
+	queryParamsPattern = regexp.MustCompile(`(?i)((?:^|[?&;])[a-z0-9_\-]*(?:token|password|passwd|secret|api_?key|auth|session)[a-z0-9_\-]*=)[^&#;\s]*`)
+)
+
+// sensitiveHeaders are the headers removed from the request of the events by ScrubPII
+var sensitiveHeaders = []string{"authorization", "cookie", "x-forwarded-for", "x-real-ip"}
+
+// ScrubPII removes the personal data and the credentials of an event: the emails, the IPv4 addresses, the
+// authorization headers, the cookies and the query parameters of the tokens and the passwords are replaced in its
+// messages, its request, its stack traces, its breadcrumbs and its contexts, and the user of the event is only kept
+// by ID.
+func (event *FrontendSentryEvent) ScrubPII() {
+	if event.Event == nil {
+		return
+	}
+
+	event.Message = scrub(event.Message)
+	event.User.Email = ""
+	event.User.IPAddress = ""
+	event.User.Username = ""
+
+	if request := event.Request; request != nil {
+		request.URL = scrub(request.URL)
+		request.QueryString = scrub(request.QueryString)
+		request.Data = scrub(request.Data)
+		request.Cookies = ""
+		for name, value := range request.Headers {
+			if isSensitiveHeader(name) {
+				delete(request.Headers, name)
+				continue
+			}
+			request.Headers[name] = scrub(value)
+		}
+	}
+
+	if event.Exception != nil {
+		for i := range event.Exception.Values {
+			value := &event.Exception.Values[i]
+			value.Value = scrub(value.Value)
+			for j := range value.Stacktrace.Frames {
+				value.Stacktrace.Frames[j].Filename = scrub(value.Stacktrace.Frames[j].Filename)
+				value.Stacktrace.Frames[j].AbsPath = scrub(value.Stacktrace.Frames[j].AbsPath)
+			}
+		}
+	}
+
+	for _, breadcrumb := range event.Breadcrumbs {
+		breadcrumb.Message = scrub(breadcrumb.Message)
+		scrubMap(breadcrumb.Data)
+	}
+	scrubMap(event.Contexts)
+	scrubMap(event.Extra)
+	for key, value := range event.Tags {
+		event.Tags[key] = scrub(value)
+	}
+}
+
+func scrub(s string) string {
+	if s == "" {
+		return s
+	}
+	s = authPattern.ReplaceAllString(s, "$1 [redacted]")
+	s = queryParamsPattern.ReplaceAllString(s, "${1}[redacted]")
+	s = emailPattern.ReplaceAllString(s, "[email]")
+	return ipv4Pattern.ReplaceAllString(s, "[ip]")
+}
+
+// scrubMap scrubs the strings of a map, recursively
+func scrubMap(m map[string]interface{}) {
+	for key, value := range m {
+		m[key] = scrubValue(value)
+	}
+}
+
+func scrubValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return scrub(v)
+	case map[string]interface{}:
+		scrubMap(v)
+	case []interface{}:
+		for i := range v {
+			v[i] = scrubValue(v[i])
+		}
+	}
+	return value
+}
+
+func isSensitiveHeader(name string) bool {
+	for _, header := range sensitiveHeaders {
+		if strings.EqualFold(name, header) {
+			return true
+		}
+	}
+	return false
+}
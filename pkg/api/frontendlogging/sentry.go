@@ -88,6 +88,8 @@ func (event *FrontendSentryEvent) ToLogContext(store *SourceMapStore) log15.Ctx
 	addEventContextToLogContext("context", ctx, event.Contexts)
 	if len(event.User.Email) > 0 {
 		ctx["user_email"] = event.User.Email
+	}
+	if len(event.User.ID) > 0 {
 		ctx["user_id"] = event.User.ID
 	}
 
@@ -15,36 +15,42 @@ import (
 	"github.com/grafana/grafana/pkg/setting"
 )
 
-func (hs *HTTPServer) getFSDataSources(c *models.ReqContext, enabledPlugins *plugins.EnabledPlugins) (map[string]interface{}, error) {
-	orgDataSources := make([]*models.DataSource, 0)
+// getOrgFrontendSettings returns the parts of the frontend settings which are the same for all the users of the org:
+// the enabled plugins and the settings of the data sources and panels.
+func (hs *HTTPServer) getOrgFrontendSettings(orgID int64) (*orgFrontendSettings, error) {
+	enabledPlugins, err := hs.PluginManager.GetEnabledPlugins(orgID)
+	if err != nil {
+		return nil, err
+	}
 
-	if c.OrgId != 0 {
-		query := models.GetDataSourcesQuery{OrgId: c.OrgId, DataSourceLimit: hs.Cfg.DataSourceLimit}
-		err := bus.Dispatch(&query)
+	settings := &orgFrontendSettings{
+		enabledPlugins:     enabledPlugins,
+		dataSources:        make([]*models.DataSource, 0),
+		dataSourceSettings: make(map[int64]map[string]interface{}),
+		builtInDataSources: make(map[string]interface{}),
+		panels:             make(map[string]interface{}),
+		appsToPreload:      []string{},
+		panelsToPreload:    []string{},
+	}
 
-		if err != nil {
-			return nil, err
+	for _, app := range enabledPlugins.Apps {
+		if app.Preload {
+			settings.appsToPreload = append(settings.appsToPreload, app.Module)
 		}
-
-		dsFilterQuery := models.DatasourcesPermissionFilterQuery{
-			User:        c.SignedInUser,
-			Datasources: query.Result,
+		if app.Id == "grafana-plugin-admin-app" {
+			settings.hasPluginManagerApp = true
 		}
+	}
 
-		if err := bus.Dispatch(&dsFilterQuery); err != nil {
-			if !errors.Is(err, bus.ErrHandlerNotFound) {
-				return nil, err
-			}
-
-			orgDataSources = query.Result
-		} else {
-			orgDataSources = dsFilterQuery.Result
+	if orgID != 0 {
+		query := models.GetDataSourcesQuery{OrgId: orgID, DataSourceLimit: hs.Cfg.DataSourceLimit}
+		if err := bus.Dispatch(&query); err != nil {
+			return nil, err
 		}
+		settings.dataSources = query.Result
 	}
 
-	dataSources := make(map[string]interface{})
-
-	for _, ds := range orgDataSources {
+	for _, ds := range settings.dataSources {
 		url := ds.Url
 
 		if ds.Access == models.DS_ACCESS_PROXY {
@@ -104,14 +110,14 @@ func (hs *HTTPServer) getFSDataSources(c *models.ReqContext, enabledPlugins *plu
 			jsonData.Set("directUrl", ds.Url)
 		}
 
-		dataSources[ds.Name] = dsMap
+		settings.dataSourceSettings[ds.Id] = dsMap
 	}
 
 	// add data sources that are built in (meaning they are not added via data sources page, nor have any entry in
 	// the datasource table)
 	for _, ds := range hs.PluginManager.DataSources() {
 		if ds.BuiltIn {
-			dataSources[ds.Name] = map[string]interface{}{
+			settings.builtInDataSources[ds.Name] = map[string]interface{}{
 				"type": ds.Type,
 				"name": ds.Name,
 				"meta": hs.PluginManager.GetDataSource(ds.Id),
@@ -119,28 +125,80 @@ func (hs *HTTPServer) getFSDataSources(c *models.ReqContext, enabledPlugins *plu
 		}
 	}
 
+	for _, panel := range enabledPlugins.Panels {
+		if panel.State == plugins.PluginStateAlpha && !hs.Cfg.PluginsEnableAlpha {
+			continue
+		}
+
+		if panel.Preload {
+			settings.panelsToPreload = append(settings.panelsToPreload, panel.Module)
+		}
+
+		settings.panels[panel.Id] = map[string]interface{}{
+			"module":        panel.Module,
+			"baseUrl":       panel.BaseUrl,
+			"name":          panel.Name,
+			"id":            panel.Id,
+			"info":          panel.Info,
+			"hideFromList":  panel.HideFromList,
+			"sort":          getPanelSort(panel.Id),
+			"skipDataQuery": panel.SkipDataQuery,
+			"state":         panel.State,
+			"signature":     panel.Signature,
+		}
+	}
+
+	return settings, nil
+}
+
+// getFSDataSources returns the settings of the data sources of the org the user has access to, and of the built in
+// data sources.
+func (hs *HTTPServer) getFSDataSources(c *models.ReqContext, orgSettings *orgFrontendSettings) (map[string]interface{}, error) {
+	orgDataSources := orgSettings.dataSources
+
+	if len(orgDataSources) > 0 {
+		dsFilterQuery := models.DatasourcesPermissionFilterQuery{
+			User:        c.SignedInUser,
+			Datasources: orgDataSources,
+		}
+
+		if err := bus.Dispatch(&dsFilterQuery); err != nil {
+			if !errors.Is(err, bus.ErrHandlerNotFound) {
+				return nil, err
+			}
+		} else {
+			orgDataSources = dsFilterQuery.Result
+		}
+	}
+
+	dataSources := make(map[string]interface{}, len(orgDataSources)+len(orgSettings.builtInDataSources))
+
+	for _, ds := range orgDataSources {
+		if dsMap, ok := orgSettings.dataSourceSettings[ds.Id]; ok {
+			dataSources[ds.Name] = dsMap
+		}
+	}
+
+	for name, dsMap := range orgSettings.builtInDataSources {
+		dataSources[name] = dsMap
+	}
+
 	return dataSources, nil
 }
 
-// getFrontendSettingsMap returns a json object with all the settings needed for front end initialisation.
+// getFrontendSettingsMap returns a json object with all the settings needed for front end initialisation. The parts
+// which are the same for all the users of the org are cached.
 func (hs *HTTPServer) getFrontendSettingsMap(c *models.ReqContext) (map[string]interface{}, error) {
-	enabledPlugins, err := hs.PluginManager.GetEnabledPlugins(c.OrgId)
+	orgSettings, err := hs.frontendSettingsCache.get(c.OrgId, func() (*orgFrontendSettings, error) {
+		return hs.getOrgFrontendSettings(c.OrgId)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	hasPluginManagerApp := false
-	pluginsToPreload := []string{}
-	for _, app := range enabledPlugins.Apps {
-		if app.Preload {
-			pluginsToPreload = append(pluginsToPreload, app.Module)
-		}
-		if app.Id == "grafana-plugin-admin-app" {
-			hasPluginManagerApp = true
-		}
-	}
+	pluginsToPreload := append([]string{}, orgSettings.appsToPreload...)
 
-	dataSources, err := hs.getFSDataSources(c, enabledPlugins)
+	dataSources, err := hs.getFSDataSources(c, orgSettings)
 	if err != nil {
 		return nil, err
 	}
@@ -158,29 +216,8 @@ func (hs *HTTPServer) getFrontendSettingsMap(c *models.ReqContext) (map[string]i
 		}
 	}
 
-	panels := map[string]interface{}{}
-	for _, panel := range enabledPlugins.Panels {
-		if panel.State == plugins.PluginStateAlpha && !hs.Cfg.PluginsEnableAlpha {
-			continue
-		}
-
-		if panel.Preload {
-			pluginsToPreload = append(pluginsToPreload, panel.Module)
-		}
-
-		panels[panel.Id] = map[string]interface{}{
-			"module":        panel.Module,
-			"baseUrl":       panel.BaseUrl,
-			"name":          panel.Name,
-			"id":            panel.Id,
-			"info":          panel.Info,
-			"hideFromList":  panel.HideFromList,
-			"sort":          getPanelSort(panel.Id),
-			"skipDataQuery": panel.SkipDataQuery,
-			"state":         panel.State,
-			"signature":     panel.Signature,
-		}
-	}
+	pluginsToPreload = append(pluginsToPreload, orgSettings.panelsToPreload...)
+	hasPluginManagerApp := orgSettings.hasPluginManagerApp
 
 	hideVersion := hs.Cfg.AnonymousHideVersion && !c.IsSignedIn
 	version := setting.BuildVersion
@@ -197,7 +234,7 @@ func (hs *HTTPServer) getFrontendSettingsMap(c *models.ReqContext) (map[string]i
 		"defaultDatasource":          defaultDS,
 		"datasources":                dataSources,
 		"minRefreshInterval":         setting.MinRefreshInterval,
-		"panels":                     panels,
+		"panels":                     orgSettings.panels,
 		"appUrl":                     hs.Cfg.AppURL,
 		"appSubUrl":                  hs.Cfg.AppSubURL,
 		"allowOrgCreate":             (setting.AllowUserOrgCreate && c.IsSignedIn) || c.IsGrafanaAdmin,
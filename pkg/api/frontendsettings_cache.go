@@ -0,0 +1,110 @@
+package api
+
+import (
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+)
+
+// frontendSettingsCacheTTL bounds the reuse of the settings of an org, since the changes of plugins made by other
+// Grafana instances aren't published to this one, nor the changes of data sources without the event bus.
+const frontendSettingsCacheTTL = time.Minute
+
+// orgFrontendSettings are the parts of the frontend settings which are the same for all the users of an org. They're
+// shared by the requests once cached, so they must not be modified.
+type orgFrontendSettings struct {
+	enabledPlugins *plugins.EnabledPlugins
+	// dataSources are the data sources of the org, before the permissions of the user are applied
+	dataSources []*models.DataSource
+	// dataSourceSettings are the settings of the data sources by ID
+	dataSourceSettings  map[int64]map[string]interface{}
+	builtInDataSources  map[string]interface{}
+	panels              map[string]interface{}
+	appsToPreload       []string
+	panelsToPreload     []string
+	hasPluginManagerApp bool
+
+	expires time.Time
+}
+
+// frontendSettingsCache caches the settings of the orgs until they expire or their data sources or plugins change.
+type frontendSettingsCache struct {
+	mu sync.Mutex
+	// generation is incremented by invalidations, so that the settings built before them aren't cached
+	generation int64
+	orgs       map[int64]*orgFrontendSettings
+	now        func() time.Time
+}
+
+func newFrontendSettingsCache() *frontendSettingsCache {
+	return &frontendSettingsCache{
+		orgs: map[int64]*orgFrontendSettings{},
+		now:  time.Now,
+	}
+}
+
+// get returns the cached settings of the org, or builds and caches them. The settings are built every time without a
+// cache.
+func (c *frontendSettingsCache) get(orgID int64, build func() (*orgFrontendSettings, error)) (*orgFrontendSettings, error) {
+	if c == nil {
+		return build()
+	}
+
+	c.mu.Lock()
+	if settings, ok := c.orgs[orgID]; ok && c.now().Before(settings.expires) {
+		c.mu.Unlock()
+		return settings, nil
+	}
+	generation := c.generation
+	c.mu.Unlock()
+
+	settings, err := build()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation == c.generation {
+		settings.expires = c.now().Add(frontendSettingsCacheTTL)
+		c.orgs[orgID] = settings
+	}
+	return settings, nil
+}
+
+// invalidate removes the cached settings of the org.
+func (c *frontendSettingsCache) invalidate(orgID int64) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	delete(c.orgs, orgID)
+}
+
+// invalidateAll removes the cached settings of all the orgs, when the installed plugins change.
+func (c *frontendSettingsCache) invalidateAll() {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.orgs = map[int64]*orgFrontendSettings{}
+}
+
+func (hs *HTTPServer) handleDataSourceChanged(event *models.DataSourceChangedEvent) error {
+	hs.frontendSettingsCache.invalidate(event.OrgId)
+	hs.variableOptionsCache.invalidateDataSource(event.Id)
+	return nil
+}
+
+func (hs *HTTPServer) handlePluginStateChanged(event *models.PluginStateChangedEvent) error {
+	hs.frontendSettingsCache.invalidate(event.OrgId)
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"net/http/httptest"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -17,6 +18,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/licensing"
 
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 
 	"gopkg.in/macaron.v1"
@@ -136,3 +138,56 @@ func TestHTTPServer_GetFrontendSettings_hideVersionAnonyomus(t *testing.T) {
 		})
 	}
 }
+
+func TestFrontendSettingsCache(t *testing.T) {
+	now := time.Now()
+	cache := newFrontendSettingsCache()
+	cache.now = func() time.Time { return now }
+
+	builds := 0
+	build := func() (*orgFrontendSettings, error) {
+		builds++
+		return &orgFrontendSettings{}, nil
+	}
+	get := func(orgID int64) *orgFrontendSettings {
+		settings, err := cache.get(orgID, build)
+		require.NoError(t, err)
+		return settings
+	}
+
+	settings := get(1)
+	require.Same(t, settings, get(1))
+	require.Equal(t, 1, builds)
+
+	get(2)
+	require.Equal(t, 2, builds)
+
+	hs := &HTTPServer{frontendSettingsCache: cache}
+	require.NoError(t, hs.handleDataSourceChanged(&models.DataSourceChangedEvent{OrgId: 1}))
+	require.NotSame(t, settings, get(1))
+	get(2)
+	require.Equal(t, 3, builds)
+
+	require.NoError(t, hs.handlePluginStateChanged(&models.PluginStateChangedEvent{OrgId: 2}))
+	get(2)
+	require.Equal(t, 4, builds)
+
+	now = now.Add(frontendSettingsCacheTTL)
+	get(1)
+	require.Equal(t, 5, builds)
+
+	cache.invalidateAll()
+	get(1)
+	get(2)
+	require.Equal(t, 7, builds)
+
+	t.Run("Settings built during an invalidation aren't cached", func(t *testing.T) {
+		_, err := cache.get(3, func() (*orgFrontendSettings, error) {
+			cache.invalidate(3)
+			return &orgFrontendSettings{}, nil
+		})
+		require.NoError(t, err)
+		get(3)
+		require.Equal(t, 8, builds)
+	})
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"errors"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/filestorage"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/geomap"
+)
+
+const geoJSONContentType = "application/geo+json"
+
+// GetGeomapLayers lists the GeoJSON files of the current organization that
+// can be used as geomap layers
+// GET /api/geomap/layers
+func (hs *HTTPServer) GetGeomapLayers(c *models.ReqContext) response.Response {
+	files, err := hs.FileStorage.List(c.Req.Context(), c.OrgId, "")
+	if err != nil {
+		return response.Error(500, "Failed to list geomap layers", err)
+	}
+
+	layers := make([]*filestorage.File, 0)
+	for _, file := range files {
+		if file.ContentType == geoJSONContentType {
+			layers = append(layers, file)
+		}
+	}
+
+	return response.JSON(200, layers)
+}
+
+// GetGeomapLayer returns the content of a GeoJSON layer
+// GET /api/geomap/layers/*
+func (hs *HTTPServer) GetGeomapLayer(c *models.ReqContext) response.Response {
+	file, data, err := hs.FileStorage.Get(c.Req.Context(), c.OrgId, c.Params("*"))
+	if err != nil {
+		return fileStorageErrorToResponse(err, "Failed to get geomap layer")
+	}
+
+	if file.ContentType != geoJSONContentType {
+		return response.Error(404, "Geomap layer not found", nil)
+	}
+
+	return response.Respond(200, data).
+		SetHeader("Content-Type", geoJSONContentType).
+		SetHeader("X-Content-Type-Options", "nosniff").
+		SetHeader("Cache-Control", "private, max-age=60")
+}
+
+// GetGeomapTileServers lists the configured tile servers
+// GET /api/geomap/tiles
+func (hs *HTTPServer) GetGeomapTileServers(c *models.ReqContext) response.Response {
+	return response.JSON(200, hs.GeomapTileProxy.TileServers())
+}
+
+// GetGeomapTile proxies a tile of a configured tile server. The y coordinate
+// may have a file extension, e.g. 3.png.
+// GET /api/geomap/tiles/:server/:z/:x/:y
+func (hs *HTTPServer) GetGeomapTile(c *models.ReqContext) response.Response {
+	y := c.Params(":y")
+	y = strings.TrimSuffix(y, path.Ext(y))
+
+	coords := make([]int, 0, 3)
+	for _, param := range []string{c.Params(":z"), c.Params(":x"), y} {
+		value, err := strconv.Atoi(param)
+		if err != nil {
+			return response.Error(400, "Invalid tile coordinates", nil)
+		}
+		coords = append(coords, value)
+	}
+
+	tile, err := hs.GeomapTileProxy.GetTile(c.Req.Context(), c.Params(":server"), coords[0], coords[1], coords[2])
+	if err != nil {
+		switch {
+		case errors.Is(err, geomap.ErrTileServerNotFound), errors.Is(err, geomap.ErrTileNotFound):
+			return response.Error(404, err.Error(), nil)
+		case errors.Is(err, geomap.ErrInvalidTile):
+			return response.Error(400, err.Error(), nil)
+		}
+		return response.Error(502, "Failed to get tile", err)
+	}
+
+	return response.Respond(200, tile.Data).
+		SetHeader("Content-Type", tile.ContentType).
+		SetHeader("X-Content-Type-Options", "nosniff").
+		SetHeader("Cache-Control", "private, max-age=3600")
+}
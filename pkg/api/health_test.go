@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/leaderelection"
 	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/setting"
@@ -167,6 +168,32 @@ func TestHealthAPI_DatabaseHealthCached(t *testing.T) {
 	require.True(t, healthy.(bool))
 }
 
+func TestHealthAPI_LeaderElection(t *testing.T) {
+	m, hs := setupHealthAPITestEnvironment(t, func(cfg *setting.Cfg) {
+		cfg.AnonymousHideVersion = true
+		cfg.LeaderElection.Enabled = true
+	})
+	// the server hasn't acquired the lease
+	hs.LeaderElection = &leaderelection.Service{Cfg: hs.Cfg}
+
+	bus.AddHandler("test", func(query *models.GetDBHealthQuery) error {
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/health", nil)
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+
+	require.Equal(t, 200, rec.Code)
+	expectedBody := `
+		{
+			"database": "ok",
+			"leader": false
+		}
+	`
+	require.JSONEq(t, expectedBody, rec.Body.String())
+}
+
 func setupHealthAPITestEnvironment(t *testing.T, cbs ...func(*setting.Cfg)) (*macaron.Macaron, *HTTPServer) {
 	t.Helper()
 
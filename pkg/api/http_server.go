@@ -13,6 +13,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/grafana/grafana/pkg/services/datasources/onboarding"
 	"github.com/grafana/grafana/pkg/services/libraryelements"
 	"github.com/grafana/grafana/pkg/services/librarypanels"
 
@@ -20,9 +21,13 @@ import (
 	httpstatic "github.com/grafana/grafana/pkg/api/static"
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/filestorage"
+	"github.com/grafana/grafana/pkg/infra/leaderelection"
 	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/middleware"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
@@ -36,18 +41,24 @@ import (
 	"github.com/grafana/grafana/pkg/services/contexthandler"
 	"github.com/grafana/grafana/pkg/services/datasourceproxy"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/geomap"
 	"github.com/grafana/grafana/pkg/services/hooks"
 	"github.com/grafana/grafana/pkg/services/live"
 	"github.com/grafana/grafana/pkg/services/live/pushhttp"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
 	"github.com/grafana/grafana/pkg/services/provisioning"
+	"github.com/grafana/grafana/pkg/services/querycost"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/sanitizer"
 	"github.com/grafana/grafana/pkg/services/schemaloader"
 	"github.com/grafana/grafana/pkg/services/search"
 	"github.com/grafana/grafana/pkg/services/shorturls"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/thumbnails"
+	"github.com/grafana/grafana/pkg/services/variables"
+	"github.com/grafana/grafana/pkg/services/warmup"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb"
 
@@ -72,6 +83,10 @@ type HTTPServer struct {
 	httpSrv     *http.Server
 	middlewares []macaron.Handler
 
+	frontendSettingsCache *frontendSettingsCache
+	variableOptionsCache  *variableOptionsCache
+	metricsGatherer       prometheus.Gatherer
+
 	PluginContextProvider  *plugincontext.Provider                 `inject:""`
 	RouteRegister          routing.RouteRegister                   `inject:""`
 	Bus                    bus.Bus                                 `inject:""`
@@ -105,12 +120,37 @@ type HTTPServer struct {
 	Alertmanager           *notifier.Alertmanager                  `inject:""`
 	LibraryPanelService    librarypanels.Service                   `inject:""`
 	LibraryElementService  libraryelements.Service                 `inject:""`
+	FileStorage            *filestorage.FileStorage                `inject:""`
+	DatasourceOnboarding   *onboarding.Service                     `inject:""`
+	GeomapTileProxy        *geomap.TileProxy                       `inject:""`
+	HTMLSanitizer          *sanitizer.Service                      `inject:""`
+	DashboardThumbnails    *thumbnails.Service                     `inject:""`
+	VariablesService       *variables.Service                      `inject:""`
+	DataSourceWarmUp       *warmup.Service                         `inject:""`
+	QueryCosts             *querycost.QueryCostService             `inject:""`
+	Tracing                *tracing.TracingService                 `inject:""`
+	LeaderElection         *leaderelection.Service                 `inject:""`
 	Listener               net.Listener
 }
 
 func (hs *HTTPServer) Init() error {
 	hs.log = log.New("http.server")
 
+	gatherer, err := metrics.NewFilteringGatherer(prometheus.DefaultGatherer, metrics.FilterOptions{
+		HighCardinalityLabels:     hs.Cfg.MetricsHighCardinalityLabels,
+		HighCardinalityLabelsMode: hs.Cfg.MetricsHighCardinalityLabelsMode,
+		Allowlist:                 hs.Cfg.MetricsEndpointAllowlist,
+	})
+	if err != nil {
+		return err
+	}
+	hs.metricsGatherer = gatherer
+
+	hs.frontendSettingsCache = newFrontendSettingsCache()
+	hs.variableOptionsCache = newVariableOptionsCache(hs.Cfg.VariableOptionsCache)
+	hs.Bus.AddEventListener(hs.handleDataSourceChanged)
+	hs.Bus.AddEventListener(hs.handlePluginStateChanged)
+
 	hs.macaron = hs.newMacaron()
 	hs.registerRoutes()
 
@@ -327,7 +367,7 @@ func (hs *HTTPServer) applyRoutes() {
 func (hs *HTTPServer) addMiddlewaresAndStaticRoutes() {
 	m := hs.macaron
 
-	m.Use(middleware.RequestTracing())
+	m.Use(middleware.RequestTracing(hs.Tracing))
 
 	m.Use(middleware.Logger(hs.Cfg))
 
@@ -363,6 +403,11 @@ func (hs *HTTPServer) addMiddlewaresAndStaticRoutes() {
 	m.Use(hs.apiHealthHandler)
 	m.Use(hs.metricsEndpoint)
 
+	// needs to be before context handler, so that the rejected requests aren't authenticated
+	if hs.Cfg.IPFilter.Enabled {
+		m.Use(middleware.IPFilter(hs.Cfg, hs.RenderService))
+	}
+
 	m.Use(hs.ContextHandler.Middleware)
 	m.Use(middleware.OrgRedirect(hs.Cfg))
 
@@ -371,8 +416,12 @@ func (hs *HTTPServer) addMiddlewaresAndStaticRoutes() {
 		m.Use(middleware.ValidateHostHeader(hs.Cfg))
 	}
 
+	if hs.Cfg.IPFilter.Enabled {
+		m.Use(middleware.OrgIPFilter(hs.Cfg))
+	}
+
 	m.Use(middleware.HandleNoCacheHeader)
-	m.Use(middleware.AddCSPHeader(hs.Cfg, hs.log))
+	m.Use(middleware.AddCSPHeader(hs.Cfg, hs.PluginManager, hs.log))
 
 	for _, mw := range hs.middlewares {
 		m.Use(mw)
@@ -393,8 +442,13 @@ func (hs *HTTPServer) metricsEndpoint(ctx *macaron.Context) {
 		return
 	}
 
+	gatherer := hs.metricsGatherer
+	if gatherer == nil {
+		gatherer = prometheus.DefaultGatherer
+	}
+
 	promhttp.
-		HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{EnableOpenMetrics: true}).
+		HandlerFor(gatherer, promhttp.HandlerOpts{EnableOpenMetrics: true}).
 		ServeHTTP(ctx.Resp, ctx.Req.Request)
 }
 
@@ -427,6 +481,9 @@ func (hs *HTTPServer) apiHealthHandler(ctx *macaron.Context) {
 		data.Set("version", hs.Cfg.BuildVersion)
 		data.Set("commit", hs.Cfg.BuildCommit)
 	}
+	if hs.Cfg.LeaderElection.Enabled {
+		data.Set("leader", hs.LeaderElection.IsLeader())
+	}
 
 	if !hs.databaseHealthy() {
 		data.Set("database", "failing")
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/middleware/cookies"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	defaultImpersonationDuration = 30 * time.Minute
+	maxImpersonationDuration     = 4 * time.Hour
+)
+
+var impersonationAuditLogger = log.New("audit.impersonation")
+
+// POST /api/admin/users/:id/impersonate
+//
+// AdminStartImpersonation starts a session of the signed in Grafana admin impersonating a user, for the duration of
+// the form, 30 minutes by default. The requests of the admin are the requests of the user until the session is
+// stopped or expires.
+func AdminStartImpersonation(c *models.ReqContext, form dtos.StartImpersonationForm) response.Response {
+	userID := c.ParamsInt64(":id")
+	if userID == c.UserId {
+		return response.Error(400, "You can't impersonate yourself", nil)
+	}
+	if c.UserToken == nil {
+		return response.Error(400, "Impersonation requires a login session", nil)
+	}
+
+	reason := strings.TrimSpace(form.Reason)
+	if reason == "" {
+		return response.Error(400, "The reason of the impersonation is required", nil)
+	}
+	duration := defaultImpersonationDuration
+	if form.Duration != "" {
+		var err error
+		if duration, err = time.ParseDuration(form.Duration); err != nil || duration <= 0 {
+			return response.Error(400, "Invalid duration, e.g. 30m", nil)
+		}
+		if duration > maxImpersonationDuration {
+			return response.Error(400, "The impersonation can't last more than "+maxImpersonationDuration.String(), nil)
+		}
+	}
+
+	userQuery := models.GetUserByIdQuery{Id: userID}
+	if err := bus.Dispatch(&userQuery); err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			return response.Error(404, models.ErrUserNotFound.Error(), nil)
+		}
+		return response.Error(500, "Failed to get user", err)
+	}
+	if userQuery.Result.IsDisabled {
+		return response.Error(400, "Disabled users can't be impersonated", nil)
+	}
+	if userQuery.Result.IsAdmin {
+		return response.Error(403, "Grafana admins can't be impersonated", nil)
+	}
+
+	key, err := util.GetRandomString(32)
+	if err != nil {
+		return response.Error(500, "Failed to start impersonation", err)
+	}
+	cmd := models.StartImpersonationCommand{
+		AdminUserId: c.UserId,
+		UserId:      userID,
+		Reason:      reason,
+		KeyHash:     models.HashImpersonationKey(key),
+		Expires:     time.Now().Add(duration),
+	}
+	if err := bus.Dispatch(&cmd); err != nil {
+		return response.Error(500, "Failed to start impersonation", err)
+	}
+
+	impersonationAuditLogger.Info("Impersonation started", "session", cmd.Result.Id, "admin", c.UserId,
+		"user", userID, "reason", reason, "expires", cmd.Expires)
+	cookies.WriteCookie(c.Resp, models.ImpersonationCookieName, key, int(duration.Seconds()), nil)
+
+	return response.JSON(200, util.DynMap{
+		"message": "Impersonation started",
+		"id":      cmd.Result.Id,
+		"expires": cmd.Expires,
+	})
+}
+
+// POST /api/user/impersonation/stop
+//
+// StopImpersonation stops the impersonation session of the request.
+func StopImpersonation(c *models.ReqContext) response.Response {
+	if c.Impersonation == nil {
+		return response.Error(400, "Not impersonating a user", nil)
+	}
+	if err := stopImpersonation(c); err != nil {
+		return response.Error(500, "Failed to stop impersonation", err)
+	}
+	return response.Success("Impersonation stopped")
+}
+
+// stopImpersonation stops the impersonation session of the request, if any, and deletes its cookie.
+func stopImpersonation(c *models.ReqContext) error {
+	if c.GetCookie(models.ImpersonationCookieName) != "" {
+		cookies.DeleteCookie(c.Resp, models.ImpersonationCookieName, nil)
+	}
+	if c.Impersonation == nil {
+		return nil
+	}
+	if err := bus.Dispatch(&models.StopImpersonationCommand{Id: c.Impersonation.Id}); err != nil {
+		return err
+	}
+	impersonationAuditLogger.Info("Impersonation stopped", "session", c.Impersonation.Id,
+		"admin", c.Impersonation.AdminUserId, "user", c.Impersonation.UserId)
+	return nil
+}
+
+// GET /api/admin/impersonation/sessions
+//
+// AdminSearchImpersonationSessions returns a page of the impersonation sessions, the newest first, filtered by the
+// userId query parameter.
+func AdminSearchImpersonationSessions(c *models.ReqContext) response.Response {
+	query := models.SearchImpersonationSessionsQuery{
+		UserId:  c.QueryInt64("userId"),
+		Page:    c.QueryInt("page"),
+		PerPage: c.QueryInt("perpage"),
+	}
+	if err := bus.Dispatch(&query); err != nil {
+		return response.Error(500, "Failed to search impersonation sessions", err)
+	}
+	return response.JSON(200, query.Result)
+}
+
+// impersonatorLogin returns the login of the Grafana admin impersonating the signed in user, if any.
+func impersonatorLogin(c *models.ReqContext) string {
+	if c.Impersonation == nil {
+		return ""
+	}
+	query := models.GetUserByIdQuery{Id: c.Impersonation.AdminUserId}
+	if err := bus.Dispatch(&query); err != nil {
+		return ""
+	}
+	return query.Result.Login
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminStartImpersonation(t *testing.T) {
+	form := dtos.StartImpersonationForm{Reason: "support ticket 42"}
+	token := &models.UserToken{Id: 1}
+
+	adminStartImpersonationScenario(t, "When calling POST on", "/api/admin/users/2/impersonate", form, token, func(sc *scenarioContext) {
+		bus.AddHandler("test", func(q *models.GetUserByIdQuery) error {
+			q.Result = &models.User{Id: q.Id, Login: "viewer"}
+			return nil
+		})
+		var cmd *models.StartImpersonationCommand
+		bus.AddHandler("test", func(c *models.StartImpersonationCommand) error {
+			cmd = c
+			c.Result = &models.ImpersonationSession{Id: 3}
+			return nil
+		})
+
+		sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+		require.Equal(t, 200, sc.resp.Code)
+
+		require.NotNil(t, cmd)
+		assert.Equal(t, testUserID, cmd.AdminUserId)
+		assert.Equal(t, int64(2), cmd.UserId)
+		assert.Equal(t, "support ticket 42", cmd.Reason)
+		assert.WithinDuration(t, time.Now().Add(30*time.Minute), cmd.Expires, time.Minute)
+
+		cookies := (&http.Response{Header: sc.resp.Header()}).Cookies()
+		require.Len(t, cookies, 1)
+		assert.Equal(t, models.ImpersonationCookieName, cookies[0].Name)
+		assert.Equal(t, 1800, cookies[0].MaxAge)
+		assert.Equal(t, models.HashImpersonationKey(cookies[0].Value), cmd.KeyHash)
+	})
+
+	adminStartImpersonationScenario(t, "When calling POST for a Grafana admin on", "/api/admin/users/2/impersonate", form, token, func(sc *scenarioContext) {
+		bus.AddHandler("test", func(q *models.GetUserByIdQuery) error {
+			q.Result = &models.User{Id: q.Id, IsAdmin: true}
+			return nil
+		})
+
+		sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+		assert.Equal(t, 403, sc.resp.Code)
+	})
+
+	adminStartImpersonationScenario(t, "When calling POST for a disabled user on", "/api/admin/users/2/impersonate", form, token, func(sc *scenarioContext) {
+		bus.AddHandler("test", func(q *models.GetUserByIdQuery) error {
+			q.Result = &models.User{Id: q.Id, IsDisabled: true}
+			return nil
+		})
+
+		sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+		assert.Equal(t, 400, sc.resp.Code)
+	})
+
+	for desc, tc := range map[string]struct {
+		url   string
+		form  dtos.StartImpersonationForm
+		token *models.UserToken
+	}{
+		"for the signed in user":       {url: fmt.Sprintf("/api/admin/users/%d/impersonate", testUserID), form: form, token: token},
+		"without a login session":      {url: "/api/admin/users/2/impersonate", form: form},
+		"without a reason":             {url: "/api/admin/users/2/impersonate", form: dtos.StartImpersonationForm{Reason: " "}, token: token},
+		"with an invalid duration":     {url: "/api/admin/users/2/impersonate", form: dtos.StartImpersonationForm{Reason: "r", Duration: "soon"}, token: token},
+		"with a duration over 4 hours": {url: "/api/admin/users/2/impersonate", form: dtos.StartImpersonationForm{Reason: "r", Duration: "5h"}, token: token},
+	} {
+		tc := tc
+		adminStartImpersonationScenario(t, "When calling POST "+desc+" on", tc.url, tc.form, tc.token, func(sc *scenarioContext) {
+			sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+			assert.Equal(t, 400, sc.resp.Code)
+		})
+	}
+}
+
+func TestStopImpersonation(t *testing.T) {
+	loggedInUserScenario(t, "When calling POST on", "/api/user/impersonation/stop", func(sc *scenarioContext) {
+		var cmd *models.StopImpersonationCommand
+		bus.AddHandler("test", func(c *models.StopImpersonationCommand) error {
+			cmd = c
+			return nil
+		})
+
+		sc.handlerFunc = StopImpersonation
+		sc.fakeReq("GET", "/api/user/impersonation/stop").exec()
+		assert.Equal(t, 400, sc.resp.Code)
+		assert.Nil(t, cmd)
+
+		sc.handlerFunc = func(c *models.ReqContext) response.Response {
+			c.Impersonation = &models.ImpersonationSession{Id: 3, AdminUserId: 2, UserId: testUserID}
+			return StopImpersonation(c)
+		}
+		sc.fakeReq("GET", "/api/user/impersonation/stop").exec()
+		require.Equal(t, 200, sc.resp.Code)
+		require.NotNil(t, cmd)
+		assert.Equal(t, int64(3), cmd.Id)
+	})
+}
+
+func adminStartImpersonationScenario(t *testing.T, desc string, url string, form dtos.StartImpersonationForm,
+	token *models.UserToken, fn scenarioFunc) {
+	t.Run(fmt.Sprintf("%s %s", desc, url), func(t *testing.T) {
+		t.Cleanup(bus.ClearBusHandlers)
+
+		sc := setupScenarioContext(t, url)
+		sc.defaultHandler = routing.Wrap(func(c *models.ReqContext) response.Response {
+			sc.context = c
+			sc.context.UserId = testUserID
+			sc.context.OrgId = testOrgID
+			sc.context.IsGrafanaAdmin = true
+			sc.context.UserToken = token
+
+			return AdminStartImpersonation(c, form)
+		})
+
+		sc.m.Post("/api/admin/users/:id/impersonate", sc.defaultHandler)
+
+		fn(sc)
+	})
+}
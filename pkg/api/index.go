@@ -436,6 +436,7 @@ func (hs *HTTPServer) setIndexViewData(c *models.ReqContext) (*dtos.IndexViewDat
 			Locale:                     locale,
 			HelpFlags1:                 c.HelpFlags1,
 			HasEditPermissionInFolders: hasEditPerm,
+			ImpersonatorLogin:          impersonatorLogin(c),
 		},
 		Settings:                settings,
 		Theme:                   prefs.Theme,
@@ -289,6 +289,9 @@ func (hs *HTTPServer) Logout(c *models.ReqContext) {
 	}
 
 	cookies.WriteSessionCookie(c, hs.Cfg, "", -1)
+	if err := stopImpersonation(c); err != nil {
+		hs.log.Error("failed to stop impersonation", "error", err)
+	}
 
 	if setting.SignoutRedirectUrl != "" {
 		c.Redirect(setting.SignoutRedirectUrl)
@@ -4,8 +4,11 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
@@ -13,7 +16,12 @@ import (
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/datalinks"
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/components/transformations"
+	"github.com/grafana/grafana/pkg/services/queryaudit"
+	"github.com/grafana/grafana/pkg/services/querycost"
+	"github.com/grafana/grafana/pkg/tsdb"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -24,12 +32,14 @@ func (hs *HTTPServer) QueryMetricsV2(c *models.ReqContext, reqDTO dtos.MetricReq
 		return response.Error(http.StatusBadRequest, "No queries found in query", nil)
 	}
 
-	timeRange := plugins.NewDataTimeRange(reqDTO.From, reqDTO.To)
-	request := plugins.DataQuery{
-		TimeRange: &timeRange,
-		Debug:     reqDTO.Debug,
-		User:      c.SignedInUser,
-		Queries:   make([]plugins.DataSubQuery, 0, len(reqDTO.Queries)),
+	timeRange := hs.newTimeRange(c, reqDTO.From, reqDTO.To)
+	request := newDataQuery(c, reqDTO, timeRange)
+
+	if resp := resolveSavedQueries(c, reqDTO.Queries); resp != nil {
+		return resp
+	}
+	if resp := hs.checkQueriesExplorePermissions(c, reqDTO); resp != nil {
+		return resp
 	}
 
 	// Loop to see if we have an expression.
@@ -39,6 +49,10 @@ func (hs *HTTPServer) QueryMetricsV2(c *models.ReqContext, reqDTO dtos.MetricReq
 		}
 	}
 
+	if hasChainedQueries(reqDTO.Queries) {
+		return hs.handleChainedQueries(c, reqDTO)
+	}
+
 	var ds *models.DataSource
 	for i, query := range reqDTO.Queries {
 		hs.log.Debug("Processing metrics query", "query", query)
@@ -59,14 +73,7 @@ func (hs *HTTPServer) QueryMetricsV2(c *models.ReqContext, reqDTO dtos.MetricReq
 			}
 		}
 
-		request.Queries = append(request.Queries, plugins.DataSubQuery{
-			RefID:         query.Get("refId").MustString("A"),
-			MaxDataPoints: query.Get("maxDataPoints").MustInt64(100),
-			IntervalMS:    query.Get("intervalMs").MustInt64(1000),
-			QueryType:     query.Get("queryType").MustString(""),
-			Model:         query,
-			DataSource:    ds,
-		})
+		request.Queries = append(request.Queries, newDataSubQuery(query, ds))
 	}
 
 	err := hs.PluginRequestValidator.Validate(ds.Url, nil)
@@ -74,19 +81,182 @@ func (hs *HTTPServer) QueryMetricsV2(c *models.ReqContext, reqDTO dtos.MetricReq
 		return response.Error(http.StatusForbidden, "Access denied", err)
 	}
 
-	resp, err := hs.DataService.HandleRequest(c.Req.Context(), ds, request)
+	var qdr *backend.QueryDataResponse
+	start := time.Now()
+	resp, err := hs.DataService.HandleRequest(auditQueries(c, reqDTO, ds, &request), ds, request)
+	duration := time.Since(start)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Metric request error", err)
+		queries := int64(len(request.Queries))
+		hs.QueryCosts.Record(ds, reqDTO.DashboardID, querycost.Cost{Queries: queries, Errors: queries, Duration: duration})
+
+		if errors.Is(err, tsdb.ErrMaxDataAgeExceeded) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		if errors.Is(err, tsdb.ErrQueryPolicyViolation) {
+			return response.Error(http.StatusForbidden, err.Error(), err)
+		}
+		if errors.Is(err, tsdb.ErrQueryBudgetExceeded) {
+			return response.Error(http.StatusServiceUnavailable, err.Error(), err)
+		}
+		if errors.Is(err, tsdb.ErrRecordingNotFound) {
+			return response.Error(http.StatusNotFound, err.Error(), err)
+		}
+
+		// the last successful results of the panel are returned instead, if retained
+		qdr = failedQueryResponse(reqDTO, err)
+		if !hs.retainQueryResults(c, reqDTO, timeRange, qdr, ds) {
+			return response.Error(http.StatusInternalServerError, "Metric request error", err)
+		}
+	} else {
+		// This is insanity... but ¯\_(ツ)_/¯, the current query path looks like:
+		//  encodeJson( decodeBase64( encodeBase64( decodeArrow( encodeArrow(frame)) ) )
+		// this will soon change to a more direct route
+		qdr, err = resp.ToBackendDataResponse()
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "error converting results", err)
+		}
+		hs.QueryCosts.Record(ds, reqDTO.DashboardID, querycost.ResponseCost(qdr, duration))
+		hs.retainQueryResults(c, reqDTO, timeRange, qdr, ds)
+	}
+	if err := transformResponse(qdr, reqDTO); err != nil {
+		return response.Error(http.StatusBadRequest, "Failed to transform the query results", err)
 	}
+	resolveDataLinks(qdr, reqDTO.DataLinks, timeRange)
+	return toMacronResponse(qdr)
+}
 
-	// This is insanity... but ¯\_(ツ)_/¯, the current query path looks like:
-	//  encodeJson( decodeBase64( encodeBase64( decodeArrow( encodeArrow(frame)) ) )
-	// this will soon change to a more direct route
-	qdr, err := resp.ToBackendDataResponse()
+// newDataQuery returns the request of the queries of a metric request to a data source, without the queries.
+func newDataQuery(c *models.ReqContext, reqDTO dtos.MetricRequest, timeRange plugins.DataTimeRange) plugins.DataQuery {
+	request := plugins.DataQuery{
+		TimeRange: &timeRange,
+		Debug:     reqDTO.Debug,
+		User:      c.SignedInUser,
+		Queries:   make([]plugins.DataSubQuery, 0, len(reqDTO.Queries)),
+		Headers:   map[string]string{},
+	}
+	// the panel of the queries, like the headers of the requests of the frontend to the data sources
+	if reqDTO.DashboardID != 0 {
+		request.Headers["X-Dashboard-Id"] = strconv.FormatInt(reqDTO.DashboardID, 10)
+	}
+	if reqDTO.PanelID != 0 {
+		request.Headers["X-Panel-Id"] = strconv.FormatInt(reqDTO.PanelID, 10)
+	}
+	return request
+}
+
+// auditQueries adds the query audit headers to the request when the queries of the data source are audited, and
+// returns the context of the request, with the audit info for the HTTP clients of the data source.
+func auditQueries(c *models.ReqContext, reqDTO dtos.MetricRequest, ds *models.DataSource, request *plugins.DataQuery) context.Context {
+	ctx := c.Req.Context()
+	if !queryaudit.Enabled(ds) {
+		return ctx
+	}
+	info := queryaudit.NewInfo(c.SignedInUser, reqDTO.DashboardID, reqDTO.PanelID)
+	for name, value := range info.Headers() {
+		request.Headers[name] = value
+	}
+	return queryaudit.WithInfo(ctx, info)
+}
+
+func newDataSubQuery(query *simplejson.Json, ds *models.DataSource) plugins.DataSubQuery {
+	return plugins.DataSubQuery{
+		RefID:         query.Get("refId").MustString("A"),
+		MaxDataPoints: query.Get("maxDataPoints").MustInt64(100),
+		IntervalMS:    query.Get("intervalMs").MustInt64(1000),
+		QueryType:     query.Get("queryType").MustString(""),
+		Model:         query,
+		DataSource:    ds,
+	}
+}
+
+// resolveSavedQueries replaces the queries referencing saved queries with the
+// queries of the saved queries.
+func resolveSavedQueries(c *models.ReqContext, queries []*simplejson.Json) response.Response {
+	for i, query := range queries {
+		if !models.IsSavedQueryReference(query) {
+			continue
+		}
+
+		resolveQuery := &models.ResolveSavedQueryQuery{OrgId: c.OrgId, SignedInUser: c.SignedInUser, Query: query}
+		if err := bus.DispatchCtx(c.Req.Context(), resolveQuery); err != nil {
+			switch {
+			case errors.Is(err, models.ErrSavedQueryNotFound):
+				return response.Error(http.StatusNotFound, err.Error(), err)
+			case errors.Is(err, models.ErrFolderAccessDenied):
+				return response.Error(http.StatusForbidden, "Access denied to saved query", err)
+			case errors.Is(err, models.ErrSavedQueryInvalidVariables):
+				return response.Error(http.StatusBadRequest, err.Error(), err)
+			}
+			return response.Error(http.StatusInternalServerError, "Failed to resolve saved query", err)
+		}
+		queries[i] = resolveQuery.Result
+	}
+	return nil
+}
+
+// transformResponse applies the transformations of the request to the frames
+// of all queries together, like the transformations of a panel. The resulting
+// frames are returned in the responses of their ref IDs.
+func transformResponse(qdr *backend.QueryDataResponse, reqDTO dtos.MetricRequest) error {
+	if len(reqDTO.Transformations) == 0 {
+		return nil
+	}
+
+	var frames data.Frames
+	seen := map[string]bool{}
+	for _, query := range reqDTO.Queries {
+		refID := query.Get("refId").MustString("A")
+		if seen[refID] {
+			continue
+		}
+		seen[refID] = true
+
+		for _, frame := range qdr.Responses[refID].Frames {
+			if frame.RefID == "" {
+				frame.RefID = refID
+			}
+			frames = append(frames, frame)
+		}
+	}
+
+	transformed, err := transformations.Apply(frames, reqDTO.Transformations)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "error converting results", err)
+		return err
+	}
+
+	responses := make(backend.Responses, len(qdr.Responses))
+	for refID, res := range qdr.Responses {
+		res.Frames = nil
+		responses[refID] = res
+	}
+	for _, frame := range transformed {
+		res := responses[frame.RefID]
+		res.Frames = append(res.Frames, frame)
+		responses[frame.RefID] = res
+	}
+	qdr.Responses = responses
+
+	return nil
+}
+
+// resolveDataLinks interpolates the data links of the frames when the request
+// asks for it.
+func resolveDataLinks(qdr *backend.QueryDataResponse, req *dtos.DataLinksRequest, timeRange plugins.DataTimeRange) {
+	if req == nil {
+		return
+	}
+
+	opts := datalinks.Options{Links: req.Links, Variables: req.Variables}
+	if from, err := timeRange.ParseFrom(); err == nil {
+		opts.From = from
+	}
+	if to, err := timeRange.ParseTo(); err == nil {
+		opts.To = to
+	}
+
+	for _, res := range qdr.Responses {
+		datalinks.Resolve(res.Frames, opts)
 	}
-	return toMacronResponse(qdr)
 }
 
 func toMacronResponse(qdr *backend.QueryDataResponse) response.Response {
@@ -100,9 +270,19 @@ func toMacronResponse(qdr *backend.QueryDataResponse) response.Response {
 	return response.JSONStreaming(statusCode, qdr)
 }
 
+// newTimeRange returns the time range of a request, with the relative times evaluated in the time zone and with the
+// first day of the week and of the fiscal year of the preferences of the user, so that they match the dashboards.
+func (hs *HTTPServer) newTimeRange(c *models.ReqContext, from, to string) plugins.DataTimeRange {
+	timeRange, err := plugins.NewDataTimeRangeForUser(c.Req.Context(), from, to, c.SignedInUser)
+	if err != nil {
+		hs.log.Warn("Failed to get the preferences of the time range", "err", err)
+	}
+	return timeRange
+}
+
 // handleExpressions handles POST /api/ds/query when there is an expression.
 func (hs *HTTPServer) handleExpressions(c *models.ReqContext, reqDTO dtos.MetricRequest) response.Response {
-	timeRange := plugins.NewDataTimeRange(reqDTO.From, reqDTO.To)
+	timeRange := hs.newTimeRange(c, reqDTO.From, reqDTO.To)
 	request := plugins.DataQuery{
 		TimeRange: &timeRange,
 		Debug:     reqDTO.Debug,
@@ -110,6 +290,7 @@ func (hs *HTTPServer) handleExpressions(c *models.ReqContext, reqDTO dtos.Metric
 		Queries:   make([]plugins.DataSubQuery, 0, len(reqDTO.Queries)),
 	}
 
+	var datasources []*models.DataSource
 	for _, query := range reqDTO.Queries {
 		hs.log.Debug("Processing metrics query", "query", query)
 		name := query.Get("datasource").MustString("")
@@ -123,9 +304,11 @@ func (hs *HTTPServer) handleExpressions(c *models.ReqContext, reqDTO dtos.Metric
 		if name != expr.DatasourceName {
 			// Expression requests have everything in one request, so need to check
 			// all data source queries for possible permission / not found issues.
-			if _, err = hs.DatasourceCache.GetDatasource(datasourceID, c.SignedInUser, c.SkipCache); err != nil {
+			ds, err := hs.DatasourceCache.GetDatasource(datasourceID, c.SignedInUser, c.SkipCache)
+			if err != nil {
 				return hs.handleGetDataSourceError(err, datasourceID)
 			}
+			datasources = append(datasources, ds)
 		}
 
 		request.Queries = append(request.Queries, plugins.DataSubQuery{
@@ -143,8 +326,17 @@ func (hs *HTTPServer) handleExpressions(c *models.ReqContext, reqDTO dtos.Metric
 	}
 	qdr, err := exprService.WrapTransformData(c.Req.Context(), request)
 	if err != nil {
-		return response.Error(500, "expression request error", err)
+		qdr = failedQueryResponse(reqDTO, err)
+		if !hs.retainQueryResults(c, reqDTO, timeRange, qdr, datasources...) {
+			return response.Error(500, "expression request error", err)
+		}
+	} else {
+		hs.retainQueryResults(c, reqDTO, timeRange, qdr, datasources...)
+	}
+	if err := transformResponse(qdr, reqDTO); err != nil {
+		return response.Error(http.StatusBadRequest, "Failed to transform the query results", err)
 	}
+	resolveDataLinks(qdr, reqDTO.DataLinks, timeRange)
 	return toMacronResponse(qdr)
 }
 
@@ -180,8 +372,11 @@ func (hs *HTTPServer) QueryMetrics(c *models.ReqContext, reqDto dtos.MetricReque
 	if err != nil {
 		return response.Error(http.StatusForbidden, "Access denied", err)
 	}
+	if resp := hs.checkQueriesExplorePermissions(c, reqDto); resp != nil {
+		return resp
+	}
 
-	timeRange := plugins.NewDataTimeRange(reqDto.From, reqDto.To)
+	timeRange := hs.newTimeRange(c, reqDto.From, reqDto.To)
 	request := plugins.DataQuery{
 		TimeRange: &timeRange,
 		Debug:     reqDto.Debug,
@@ -199,6 +394,18 @@ func (hs *HTTPServer) QueryMetrics(c *models.ReqContext, reqDto dtos.MetricReque
 	}
 
 	resp, err := hs.DataService.HandleRequest(c.Req.Context(), ds, request)
+	if errors.Is(err, tsdb.ErrMaxDataAgeExceeded) {
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	if errors.Is(err, tsdb.ErrQueryPolicyViolation) {
+		return response.Error(http.StatusForbidden, err.Error(), err)
+	}
+	if errors.Is(err, tsdb.ErrQueryBudgetExceeded) {
+		return response.Error(http.StatusServiceUnavailable, err.Error(), err)
+	}
+	if errors.Is(err, tsdb.ErrRecordingNotFound) {
+		return response.Error(http.StatusNotFound, err.Error(), err)
+	}
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, "Metric request error", err)
 	}
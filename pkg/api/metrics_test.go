@@ -0,0 +1,55 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/components/transformations"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransformResponse(t *testing.T) {
+	newResponse := func() *backend.QueryDataResponse {
+		return &backend.QueryDataResponse{Responses: backend.Responses{
+			"A": {Frames: data.Frames{data.NewFrame("",
+				data.NewField("time", nil, []time.Time{time.Unix(10, 0)}),
+				data.NewField("cpu", nil, []float64{1}),
+			)}},
+			"B": {Frames: data.Frames{data.NewFrame("",
+				data.NewField("time", nil, []time.Time{time.Unix(10, 0)}),
+				data.NewField("memory", nil, []float64{2}),
+			)}},
+		}}
+	}
+	request := dtos.MetricRequest{
+		Queries: []*simplejson.Json{
+			simplejson.NewFromAny(map[string]interface{}{"refId": "A"}),
+			simplejson.NewFromAny(map[string]interface{}{"refId": "B"}),
+		},
+	}
+
+	t.Run("Should keep the response without transformations", func(t *testing.T) {
+		qdr := newResponse()
+		require.NoError(t, transformResponse(qdr, request))
+		require.Equal(t, newResponse(), qdr)
+	})
+
+	t.Run("Should transform the frames of all queries together", func(t *testing.T) {
+		request := request
+		request.Transformations = []transformations.Transformation{
+			{ID: transformations.SeriesToColumns, Options: json.RawMessage(`{}`)},
+		}
+
+		qdr := newResponse()
+		require.NoError(t, transformResponse(qdr, request))
+
+		require.Len(t, qdr.Responses["A"].Frames, 1)
+		require.Len(t, qdr.Responses["A"].Frames[0].Fields, 3)
+		require.Empty(t, qdr.Responses["B"].Frames)
+	})
+}
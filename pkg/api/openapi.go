@@ -0,0 +1,14 @@
+package api
+
+import (
+	"github.com/grafana/grafana/pkg/api/openapi"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// GetOpenAPIDocument returns the OpenAPI document generated from the routes
+// of the HTTP API, with the routes registered by the services.
+func (hs *HTTPServer) GetOpenAPIDocument(c *models.ReqContext) response.Response {
+	info := openapi.Info{Title: "Grafana HTTP API", Version: hs.Cfg.BuildVersion}
+	return response.JSON(200, openapi.Generate(info, hs.RouteRegister.Routes()))
+}
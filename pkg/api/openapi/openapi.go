@@ -0,0 +1,429 @@
+// Package openapi generates the OpenAPI 3 document of the HTTP API from the
+// routes of the route register, so that the document cannot drift from the
+// routes served.
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+)
+
+// Version is the version of the OpenAPI specification of the documents.
+const Version = "3.0.3"
+
+// Document is an OpenAPI document.
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components Components            `json:"components"`
+	Security   []map[string][]string `json:"security,omitempty"`
+}
+
+// Info is the metadata of the API.
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// PathItem is the operations of a path by lower case HTTP method.
+type PathItem map[string]*Operation
+
+// Operation is an operation of the API.
+type Operation struct {
+	OperationID string              `json:"operationId"`
+	Tags        []string            `json:"tags,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+// Parameter is a path or query parameter of an operation.
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody is the request body of an operation.
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response is a response of an operation.
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType is the schema of a request or response body.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components are the schemas of the named types and the security schemes.
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme is a way to authenticate the requests.
+type SecurityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme"`
+}
+
+// Schema is the JSON schema of a type. The empty schema matches any value.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	durationType      = reflect.TypeOf(time.Duration(0))
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Generate returns the document of the routes under /api/. The routes
+// matching any HTTP method and the catch-all routes, like the data source
+// proxy, are left out.
+func Generate(info Info, routes []routing.Route) *Document {
+	g := &generator{
+		names:   map[reflect.Type]string{},
+		schemas: map[string]*Schema{},
+	}
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   map[string]PathItem{},
+		Components: Components{
+			Schemas: g.schemas,
+			SecuritySchemes: map[string]SecurityScheme{
+				"basic":  {Type: "http", Scheme: "basic"},
+				"bearer": {Type: "http", Scheme: "bearer"},
+			},
+		},
+		Security: []map[string][]string{{"basic": {}}, {"bearer": {}}},
+	}
+
+	operationIDs := map[string]int{}
+	for _, r := range routes {
+		if r.Method == "*" || !strings.HasPrefix(r.Pattern, "/api/") || strings.Contains(r.Pattern, "*") {
+			continue
+		}
+
+		path, params := convertPattern(r.Pattern)
+		op := &Operation{
+			OperationID: operationID(r.Method, r.Pattern),
+			Responses:   map[string]Response{},
+		}
+		if n := operationIDs[op.OperationID]; n > 0 {
+			op.OperationID += strconv.Itoa(n + 1)
+		}
+		operationIDs[op.OperationID]++
+
+		if tag := tag(r.Pattern); tag != "" {
+			op.Tags = []string{tag}
+		}
+		for _, name := range params {
+			op.Parameters = append(op.Parameters, Parameter{
+				Name:     name,
+				In:       "path",
+				Required: true,
+				Schema:   &Schema{Type: "string"},
+			})
+		}
+
+		if r.Body != nil {
+			// the requests without a body are bound from the query
+			if r.Method == http.MethodGet || r.Method == http.MethodDelete {
+				op.Parameters = append(op.Parameters, g.queryParameters(r.Body)...)
+			} else {
+				op.RequestBody = &RequestBody{
+					Required: true,
+					Content:  map[string]MediaType{"application/json": {Schema: g.schema(r.Body)}},
+				}
+			}
+		}
+
+		for status, typ := range r.Responses {
+			resp := Response{Description: http.StatusText(status)}
+			if typ != nil {
+				resp.Content = map[string]MediaType{"application/json": {Schema: g.schema(typ)}}
+			}
+			op.Responses[strconv.Itoa(status)] = resp
+		}
+		if len(op.Responses) == 0 {
+			op.Responses["default"] = Response{Description: "The response of the operation."}
+		}
+
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = PathItem{}
+			doc.Paths[path] = item
+		}
+		item[strings.ToLower(r.Method)] = op
+	}
+
+	return doc
+}
+
+// convertPattern returns the OpenAPI path of a macaron route pattern and the
+// names of its parameters, for example /api/teams/{id} for /api/teams/:id.
+func convertPattern(pattern string) (string, []string) {
+	var params []string
+	segments := strings.Split(pattern, "/")
+	for i, s := range segments {
+		if strings.HasPrefix(s, ":") {
+			name := strings.TrimPrefix(s, ":")
+			params = append(params, name)
+			segments[i] = "{" + name + "}"
+		}
+	}
+	path := strings.Join(segments, "/")
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	return path, params
+}
+
+// operationID returns the ID of the operation of a route, for example
+// getTeamsById for GET /api/teams/:id.
+func operationID(method, pattern string) string {
+	id := strings.ToLower(method)
+	for _, s := range strings.Split(strings.Trim(strings.TrimPrefix(pattern, "/api/"), "/"), "/") {
+		if strings.HasPrefix(s, ":") {
+			id += "By" + camelCase(strings.TrimPrefix(s, ":"))
+		} else {
+			id += camelCase(s)
+		}
+	}
+	return id
+}
+
+// tag returns the first segment of the route after /api/, which groups the
+// operations of a resource.
+func tag(pattern string) string {
+	s := strings.SplitN(strings.TrimPrefix(pattern, "/api/"), "/", 2)[0]
+	if strings.HasPrefix(s, ":") {
+		return ""
+	}
+	return s
+}
+
+func camelCase(s string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+type generator struct {
+	names   map[reflect.Type]string
+	schemas map[string]*Schema
+}
+
+// schema returns the schema of a type, and adds the schemas of the named
+// structs to the components.
+func (g *generator) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == durationType:
+		return &Schema{Type: "integer", Format: "int64"}
+	case t == rawMessageType:
+		return &Schema{}
+	case implements(t, jsonMarshalerType):
+		return &Schema{}
+	case implements(t, textMarshalerType):
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + g.name(t)}
+	default:
+		return &Schema{}
+	}
+}
+
+// name returns the name of the schema of a named struct, the name of the type
+// if it is unique or else prefixed by its package.
+func (g *generator) name(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+
+	name := t.Name()
+	if _, taken := g.schemas[name]; taken {
+		pkg := t.PkgPath()
+		name = pkg[strings.LastIndex(pkg, "/")+1:] + "." + t.Name()
+	}
+	for i, base := 2, name; ; i++ {
+		if _, taken := g.schemas[name]; !taken {
+			break
+		}
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+
+	// the name is set before the schema is built for the recursive types
+	g.names[t] = name
+	g.schemas[name] = &Schema{}
+	*g.schemas[name] = *g.structSchema(t)
+	return name
+}
+
+func (g *generator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	g.addFields(s, t)
+	sort.Strings(s.Required)
+	return s
+}
+
+// addFields adds the JSON fields of a struct to its schema, with the fields
+// of the embedded structs.
+func (g *generator) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts := parseTag(tag)
+
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct && !implements(ft, jsonMarshalerType) {
+			g.addFields(s, ft)
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+		if strings.Contains(opts, "string") {
+			s.Properties[name] = &Schema{Type: "string"}
+		} else {
+			s.Properties[name] = g.schema(f.Type)
+		}
+		if isRequired(f) {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// queryParameters returns the query parameters of a struct bound from the
+// query of a request.
+func (g *generator) queryParameters(t reflect.Type) []Parameter {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var params []Parameter
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" || f.Anonymous {
+			continue
+		}
+		name := f.Tag.Get("form")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		params = append(params, Parameter{
+			Name:     name,
+			In:       "query",
+			Required: isRequired(f),
+			Schema:   g.schema(f.Type),
+		})
+	}
+	return params
+}
+
+func parseTag(tag string) (string, string) {
+	if i := strings.Index(tag, ","); i >= 0 {
+		return tag[:i], tag[i+1:]
+	}
+	return tag, ""
+}
+
+// isRequired returns true if the binding of the field requires it.
+func isRequired(f reflect.StructField) bool {
+	for _, rule := range strings.Split(f.Tag.Get("binding"), ";") {
+		if strings.TrimSpace(rule) == "Required" {
+			return true
+		}
+	}
+	return false
+}
+
+func implements(t reflect.Type, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PtrTo(t).Implements(iface)
+}
@@ -0,0 +1,122 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+type base struct {
+	ID      int64     `json:"id"`
+	Created time.Time `json:"created"`
+}
+
+type team struct {
+	base
+	Name     string           `json:"name" binding:"Required"`
+	Email    string           `json:"email,omitempty"`
+	Secret   string           `json:"-"`
+	Settings *simplejson.Json `json:"settings"`
+	Labels   map[string]string
+	Parent   *team  `json:"parent"`
+	hidden   string //nolint:unused,structcheck
+	Count    int64  `json:"count,string"`
+	Avatar   []byte `json:"avatar"`
+}
+
+type searchQuery struct {
+	Query string `form:"query"`
+	Limit int    `binding:"Required"`
+}
+
+func TestGenerate(t *testing.T) {
+	noop := func() {}
+	rr := routing.NewRouteRegister()
+	rr.Get("/", noop)
+	rr.Get("/api/teams/search", routing.Bind(searchQuery{}), routing.Returns(http.StatusOK, []team{}), noop)
+	rr.Any("/api/datasources/proxy/:id", noop)
+	rr.Get("/api/plugins/:pluginId/resources/*", noop)
+	rr.Group("/api/teams", func(teams routing.RouteRegister) {
+		teams.Post("/", routing.Bind(team{}), routing.Returns(http.StatusOK, team{}), routing.Returns(http.StatusConflict, nil), noop)
+		teams.Delete("/:teamId/members/:userId", noop)
+	})
+
+	doc := Generate(Info{Title: "Grafana HTTP API", Version: "8.0.0"}, rr.Routes())
+	require.Equal(t, Version, doc.OpenAPI)
+	require.Len(t, doc.Paths, 3)
+
+	t.Run("operations of the routes", func(t *testing.T) {
+		op := doc.Paths["/api/teams/{teamId}/members/{userId}"]["delete"]
+		require.NotNil(t, op)
+		require.Equal(t, "deleteTeamsByTeamIdMembersByUserId", op.OperationID)
+		require.Equal(t, []string{"teams"}, op.Tags)
+		require.Equal(t, []Parameter{
+			{Name: "teamId", In: "path", Required: true, Schema: &Schema{Type: "string"}},
+			{Name: "userId", In: "path", Required: true, Schema: &Schema{Type: "string"}},
+		}, op.Parameters)
+		require.Nil(t, op.RequestBody)
+		require.Contains(t, op.Responses, "default")
+	})
+
+	t.Run("query parameters of the requests without body", func(t *testing.T) {
+		op := doc.Paths["/api/teams/search"]["get"]
+		require.NotNil(t, op)
+		require.Nil(t, op.RequestBody)
+		require.Equal(t, []Parameter{
+			{Name: "query", In: "query", Schema: &Schema{Type: "string"}},
+			{Name: "Limit", In: "query", Required: true, Schema: &Schema{Type: "integer", Format: "int64"}},
+		}, op.Parameters)
+		require.Equal(t, &Schema{Type: "array", Items: &Schema{Ref: "#/components/schemas/team"}},
+			op.Responses["200"].Content["application/json"].Schema)
+	})
+
+	t.Run("request and response bodies", func(t *testing.T) {
+		op := doc.Paths["/api/teams"]["post"]
+		require.NotNil(t, op)
+		require.Equal(t, "postTeams", op.OperationID)
+		require.Equal(t, &Schema{Ref: "#/components/schemas/team"}, op.RequestBody.Content["application/json"].Schema)
+		require.Equal(t, &Schema{Ref: "#/components/schemas/team"}, op.Responses["200"].Content["application/json"].Schema)
+		require.Equal(t, Response{Description: "Conflict"}, op.Responses["409"])
+	})
+
+	t.Run("schemas of the structs", func(t *testing.T) {
+		require.Equal(t, &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				"id":       {Type: "integer", Format: "int64"},
+				"created":  {Type: "string", Format: "date-time"},
+				"name":     {Type: "string"},
+				"email":    {Type: "string"},
+				"settings": {},
+				"Labels":   {Type: "object", AdditionalProperties: &Schema{Type: "string"}},
+				"parent":   {Ref: "#/components/schemas/team"},
+				"count":    {Type: "string"},
+				"avatar":   {Type: "string", Format: "byte"},
+			},
+			Required: []string{"name"},
+		}, doc.Components.Schemas["team"])
+	})
+
+	t.Run("document is valid JSON", func(t *testing.T) {
+		_, err := json.Marshal(doc)
+		require.NoError(t, err)
+	})
+}
+
+func TestSchemaNames(t *testing.T) {
+	type Folder struct{ Title string }
+
+	g := &generator{names: map[reflect.Type]string{}, schemas: map[string]*Schema{}}
+	require.Equal(t, &Schema{Ref: "#/components/schemas/Folder"}, g.schema(reflect.TypeOf(&Folder{})))
+	require.Equal(t, &Schema{Ref: "#/components/schemas/dtos.Folder"}, g.schema(reflect.TypeOf(dtos.Folder{})))
+	require.Equal(t, &Schema{Ref: "#/components/schemas/Folder"}, g.schema(reflect.TypeOf(Folder{})))
+	require.Len(t, g.schemas, 2)
+}
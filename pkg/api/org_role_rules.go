@@ -0,0 +1,68 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// GET /api/admin/org-role-rules
+func AdminGetOrgRoleRules(c *models.ReqContext) response.Response {
+	query := models.GetOrgRoleRulesQuery{}
+	if err := bus.Dispatch(&query); err != nil {
+		return response.Error(500, "Failed to get org role rules", err)
+	}
+	return response.JSON(200, query.Result)
+}
+
+// POST /api/admin/org-role-rules
+func AdminCreateOrgRoleRule(c *models.ReqContext, cmd models.CreateOrgRoleRuleCommand) response.Response {
+	cmd.Domain = models.NormalizeOrgRoleRuleDomain(cmd.Domain)
+	if err := models.ValidateOrgRoleRule(cmd.Domain, cmd.Regex, cmd.Role); err != nil {
+		return response.Error(400, err.Error(), nil)
+	}
+
+	if err := bus.Dispatch(&cmd); err != nil {
+		return orgRoleRuleError(err, "Failed to create org role rule")
+	}
+	return response.JSON(200, util.DynMap{
+		"id":      cmd.Result.Id,
+		"message": "Org role rule created",
+	})
+}
+
+// PUT /api/admin/org-role-rules/:id
+func AdminUpdateOrgRoleRule(c *models.ReqContext, cmd models.UpdateOrgRoleRuleCommand) response.Response {
+	cmd.Id = c.ParamsInt64(":id")
+	cmd.Domain = models.NormalizeOrgRoleRuleDomain(cmd.Domain)
+	if err := models.ValidateOrgRoleRule(cmd.Domain, cmd.Regex, cmd.Role); err != nil {
+		return response.Error(400, err.Error(), nil)
+	}
+
+	if err := bus.Dispatch(&cmd); err != nil {
+		return orgRoleRuleError(err, "Failed to update org role rule")
+	}
+	return response.Success("Org role rule updated")
+}
+
+// DELETE /api/admin/org-role-rules/:id
+func AdminDeleteOrgRoleRule(c *models.ReqContext) response.Response {
+	cmd := models.DeleteOrgRoleRuleCommand{Id: c.ParamsInt64(":id")}
+	if err := bus.Dispatch(&cmd); err != nil {
+		return orgRoleRuleError(err, "Failed to delete org role rule")
+	}
+	return response.Success("Org role rule deleted")
+}
+
+func orgRoleRuleError(err error, message string) response.Response {
+	if errors.Is(err, models.ErrOrgRoleRuleNotFound) {
+		return response.Error(404, err.Error(), nil)
+	}
+	if errors.Is(err, models.ErrOrgNotFound) {
+		return response.Error(400, err.Error(), nil)
+	}
+	return response.Error(500, message, err)
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminCreateOrgRoleRule(t *testing.T) {
+	cmd := models.CreateOrgRoleRuleCommand{OrgId: 2, Domain: " @Sales.Example.com", Role: models.ROLE_EDITOR}
+	adminCreateOrgRoleRuleScenario(t, "When calling POST on", cmd, func(sc *scenarioContext) {
+		var created *models.CreateOrgRoleRuleCommand
+		bus.AddHandler("test", func(c *models.CreateOrgRoleRuleCommand) error {
+			created = c
+			c.Result = &models.OrgRoleRule{Id: 3}
+			return nil
+		})
+
+		sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+		require.Equal(t, 200, sc.resp.Code)
+		require.NotNil(t, created)
+		assert.Equal(t, "sales.example.com", created.Domain)
+		assert.Equal(t, models.ROLE_EDITOR, created.Role)
+	})
+
+	adminCreateOrgRoleRuleScenario(t, "When calling POST for a missing org on", cmd, func(sc *scenarioContext) {
+		bus.AddHandler("test", func(c *models.CreateOrgRoleRuleCommand) error {
+			return models.ErrOrgNotFound
+		})
+
+		sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+		assert.Equal(t, 400, sc.resp.Code)
+	})
+
+	for desc, cmd := range map[string]models.CreateOrgRoleRuleCommand{
+		"without a domain or regex": {OrgId: 2, Role: models.ROLE_VIEWER},
+		"with a domain and a regex": {OrgId: 2, Domain: "example.com", Regex: "@example", Role: models.ROLE_VIEWER},
+		"with an email as domain":   {OrgId: 2, Domain: "jane@example.com", Role: models.ROLE_VIEWER},
+		"with an invalid regex":     {OrgId: 2, Regex: "(example", Role: models.ROLE_VIEWER},
+		"with an invalid role":      {OrgId: 2, Domain: "example.com", Role: "Owner"},
+	} {
+		cmd := cmd
+		adminCreateOrgRoleRuleScenario(t, "When calling POST "+desc+" on", cmd, func(sc *scenarioContext) {
+			sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+			assert.Equal(t, 400, sc.resp.Code)
+		})
+	}
+}
+
+func TestAdminDeleteOrgRoleRule(t *testing.T) {
+	loggedInUserScenarioWithRole(t, "When calling DELETE on", "DELETE", "/api/admin/org-role-rules/3", "/api/admin/org-role-rules/:id", models.ROLE_ADMIN, func(sc *scenarioContext) {
+		var deleted *models.DeleteOrgRoleRuleCommand
+		bus.AddHandler("test", func(c *models.DeleteOrgRoleRuleCommand) error {
+			deleted = c
+			return nil
+		})
+
+		sc.handlerFunc = AdminDeleteOrgRoleRule
+		sc.fakeReqWithParams("DELETE", sc.url, map[string]string{}).exec()
+		require.Equal(t, 200, sc.resp.Code)
+		require.NotNil(t, deleted)
+		assert.Equal(t, int64(3), deleted.Id)
+	})
+
+	loggedInUserScenarioWithRole(t, "When calling DELETE for a missing rule on", "DELETE", "/api/admin/org-role-rules/4", "/api/admin/org-role-rules/:id", models.ROLE_ADMIN, func(sc *scenarioContext) {
+		bus.AddHandler("test", func(c *models.DeleteOrgRoleRuleCommand) error {
+			return models.ErrOrgRoleRuleNotFound
+		})
+
+		sc.handlerFunc = AdminDeleteOrgRoleRule
+		sc.fakeReqWithParams("DELETE", sc.url, map[string]string{}).exec()
+		assert.Equal(t, 404, sc.resp.Code)
+	})
+}
+
+func adminCreateOrgRoleRuleScenario(t *testing.T, desc string, cmd models.CreateOrgRoleRuleCommand, fn scenarioFunc) {
+	url := "/api/admin/org-role-rules"
+	t.Run(fmt.Sprintf("%s %s", desc, url), func(t *testing.T) {
+		t.Cleanup(bus.ClearBusHandlers)
+
+		sc := setupScenarioContext(t, url)
+		sc.defaultHandler = routing.Wrap(func(c *models.ReqContext) response.Response {
+			sc.context = c
+			sc.context.UserId = testUserID
+			sc.context.OrgId = testOrgID
+			sc.context.IsGrafanaAdmin = true
+
+			return AdminCreateOrgRoleRule(c, cmd)
+		})
+
+		sc.m.Post(url, sc.defaultHandler)
+
+		fn(sc)
+	})
+}
@@ -14,6 +14,8 @@ import (
 	"time"
 
 	"github.com/grafana/grafana/pkg/api/datasource"
+	"github.com/grafana/grafana/pkg/components/querypolicy"
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/httpclient"
 	glog "github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
@@ -26,10 +28,25 @@ import (
 )
 
 var (
-	logger = glog.New("data-proxy-log")
-	client = newHTTPClient()
+	logger                 = glog.New("data-proxy-log")
+	queryPolicyAuditLogger = glog.New("audit.query_policy")
+	client                 = newHTTPClient()
 )
 
+// queryPolicyParams are the parameters of the queries of the proxied APIs, by data source type and path, which are
+// checked against the query policies of the data sources.
+var queryPolicyParams = map[string]map[string][]string{
+	models.DS_PROMETHEUS: {
+		"api/v1/query":       {"query"},
+		"api/v1/query_range": {"query"},
+		"api/v1/series":      {"match[]"},
+	},
+	"loki": {
+		"loki/api/v1/query":       {"query"},
+		"loki/api/v1/query_range": {"query"},
+	},
+}
+
 type DataSourceProxy struct {
 	ds             *models.DataSource
 	ctx            *models.ReqContext
@@ -216,6 +233,7 @@ func (proxy *DataSourceProxy) director(req *http.Request) {
 	}
 
 	applyUserHeader(proxy.cfg.SendUserHeader, req, proxy.ctx.SignedInUser)
+	applyQueryAuditHeaders(req, proxy.ds, proxy.ctx.SignedInUser)
 
 	keepCookieNames := []string{}
 	if proxy.ds.JsonData != nil {
@@ -261,6 +279,10 @@ func (proxy *DataSourceProxy) validateRequest() error {
 		}
 	}
 
+	if err := proxy.checkQueryPolicy(); err != nil {
+		return err
+	}
+
 	// found route if there are any
 	if len(proxy.plugin.Routes) > 0 {
 		for _, route := range proxy.plugin.Routes {
@@ -301,6 +323,40 @@ func (proxy *DataSourceProxy) validateRequest() error {
 	return nil
 }
 
+// checkQueryPolicy checks the queries of the proxied APIs against the query policy of the data source. The
+// violations are recorded in the audit log, and deny the requests.
+func (proxy *DataSourceProxy) checkQueryPolicy() error {
+	params, ok := queryPolicyParams[proxy.ds.Type][strings.Trim(proxy.proxyPath, "/")]
+	if !ok {
+		return nil
+	}
+	policy, err := querypolicy.New(proxy.ds.JsonData)
+	if err != nil {
+		return fmt.Errorf("invalid query policy of data source %q: %w", proxy.ds.Name, err)
+	}
+	if policy == nil {
+		return nil
+	}
+
+	values := proxy.ctx.Req.URL.Query()
+	for _, param := range params {
+		for _, expr := range values[param] {
+			violations := policy.Check(proxy.ds.Type, simplejson.NewFromAny(map[string]interface{}{"expr": expr}))
+			if len(violations) == 0 {
+				continue
+			}
+
+			for _, violation := range violations {
+				queryPolicyAuditLogger.Warn("Query denied by the query policy of the data source", "datasource",
+					proxy.ds.Name, "datasourceId", proxy.ds.Id, "orgId", proxy.ctx.OrgId, "userId", proxy.ctx.UserId,
+					"uname", proxy.ctx.Login, "path", proxy.proxyPath, "rule", violation.Rule, "message", violation.Message)
+			}
+			return fmt.Errorf("query denied by the query policy of the data source: %s", violations[0].Message)
+		}
+	}
+	return nil
+}
+
 func (proxy *DataSourceProxy) logRequest() {
 	if !setting.DataProxyLogging {
 		return
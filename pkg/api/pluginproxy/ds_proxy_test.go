@@ -935,3 +935,48 @@ func Test_PathCheck(t *testing.T) {
 	require.Nil(t, proxy.validateRequest())
 	require.Equal(t, plugin.Routes[1], proxy.route)
 }
+
+func TestDataSourceProxy_queryPolicy(t *testing.T) {
+	ds := &models.DataSource{
+		Name: "shared",
+		Type: models.DS_PROMETHEUS,
+		Url:  "http://prometheus:9090",
+		JsonData: simplejson.NewFromAny(map[string]interface{}{
+			"queryPolicy": map[string]interface{}{
+				"rules": []interface{}{map[string]interface{}{"type": "denySelector", "pattern": "^secret_"}},
+			},
+		}),
+	}
+	validate := func(t *testing.T, proxyPath string, query string) error {
+		t.Helper()
+
+		req, err := http.NewRequest("GET", "http://localhost/api/datasources/proxy/1/"+proxyPath+"?"+query, nil)
+		require.NoError(t, err)
+		ctx := &models.ReqContext{
+			Context:      &macaron.Context{Req: macaron.Request{Request: req}},
+			SignedInUser: &models.SignedInUser{OrgRole: models.ROLE_VIEWER},
+		}
+		proxy, err := NewDataSourceProxy(ds, &plugins.DataSourcePlugin{}, ctx, proxyPath, &setting.Cfg{},
+			httpclient.NewProvider())
+		require.NoError(t, err)
+		return proxy.validateRequest()
+	}
+
+	t.Run("Should allow the queries complying with the query policy", func(t *testing.T) {
+		require.NoError(t, validate(t, "api/v1/query_range", url.Values{"query": {`rate(up[5m])`}}.Encode()))
+		require.NoError(t, validate(t, "api/v1/series", url.Values{"match[]": {`up`, `node_load1`}}.Encode()))
+	})
+
+	t.Run("Should deny the queries violating the query policy", func(t *testing.T) {
+		err := validate(t, "api/v1/query", url.Values{"query": {`sum(secret_tokens)`}}.Encode())
+		require.EqualError(t, err, `query denied by the query policy of the data source: secret_tokens selects the `+
+			`denied value "secret_tokens" of the label "__name__"`)
+
+		err = validate(t, "api/v1/series", url.Values{"match[]": {`up`, `{job="api"}`}}.Encode())
+		require.Error(t, err)
+	})
+
+	t.Run("Should not check the other APIs", func(t *testing.T) {
+		require.NoError(t, validate(t, "api/v1/label/__name__/values", ""))
+	})
+}
@@ -5,11 +5,13 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 	"strings"
 	"text/template"
 
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/queryaudit"
 )
 
 // interpolateString accepts template data and return a string with substitutions
@@ -92,3 +94,21 @@ func applyUserHeader(sendUserHeader bool, req *http.Request, user *models.Signed
 		req.Header.Set("X-Grafana-User", user.Login)
 	}
 }
+
+// Set the query audit headers if the queries of the data source are audited (and remove if not), with the dashboard
+// and panel of the X-Dashboard-Id and X-Panel-Id headers of the frontend
+func applyQueryAuditHeaders(req *http.Request, ds *models.DataSource, user *models.SignedInUser) {
+	for _, name := range []string{queryaudit.HeaderDashboardUID, queryaudit.HeaderPanelID, queryaudit.HeaderOrgID} {
+		req.Header.Del(name)
+	}
+	if !queryaudit.Enabled(ds) {
+		return
+	}
+
+	dashboardID, _ := strconv.ParseInt(req.Header.Get("X-Dashboard-Id"), 10, 64)
+	panelID, _ := strconv.ParseInt(req.Header.Get("X-Panel-Id"), 10, 64)
+	info := queryaudit.NewInfo(user, dashboardID, panelID)
+	for name, value := range info.Headers() {
+		req.Header.Set(name, value)
+	}
+}
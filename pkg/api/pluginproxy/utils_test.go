@@ -1,8 +1,12 @@
 package pluginproxy
 
 import (
+	"net/http"
 	"testing"
 
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -18,3 +22,39 @@ func TestInterpolateString(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "0asd+asd", interpolated)
 }
+
+func TestApplyQueryAuditHeaders(t *testing.T) {
+	bus.AddHandler("test", func(query *models.GetDashboardRefByIdQuery) error {
+		query.Result = &models.DashboardRef{Uid: "nErXDvCkzz"}
+		return nil
+	})
+	t.Cleanup(bus.ClearBusHandlers)
+	user := &models.SignedInUser{Login: "admin", OrgId: 1}
+
+	newRequest := func(t *testing.T) *http.Request {
+		req, err := http.NewRequest(http.MethodGet, "http://localhost/api/query", nil)
+		require.NoError(t, err)
+		req.Header.Set("X-Dashboard-Id", "3")
+		req.Header.Set("X-Panel-Id", "2")
+		// headers of the frontend which must not be proxied
+		req.Header.Set("X-Grafana-Org-Id", "42")
+		return req
+	}
+
+	t.Run("When the queries of the data source are audited", func(t *testing.T) {
+		req := newRequest(t)
+		ds := &models.DataSource{JsonData: simplejson.NewFromAny(map[string]interface{}{"queryAuditHeaders": true})}
+		applyQueryAuditHeaders(req, ds, user)
+		assert.Equal(t, "nErXDvCkzz", req.Header.Get("X-Grafana-Dashboard-Uid"))
+		assert.Equal(t, "2", req.Header.Get("X-Grafana-Panel-Id"))
+		assert.Equal(t, "admin", req.Header.Get("X-Grafana-User"))
+		assert.Equal(t, "1", req.Header.Get("X-Grafana-Org-Id"))
+	})
+
+	t.Run("When the queries of the data source aren't audited", func(t *testing.T) {
+		req := newRequest(t)
+		applyQueryAuditHeaders(req, &models.DataSource{JsonData: simplejson.New()}, user)
+		assert.Empty(t, req.Header.Get("X-Grafana-Dashboard-Uid"))
+		assert.Empty(t, req.Header.Get("X-Grafana-Org-Id"))
+	})
+}
@@ -220,6 +220,22 @@ func (hs *HTTPServer) ImportDashboard(c *models.ReqContext, apiCmd dtos.ImportDa
 		}
 	}
 
+	// the dashboards of plugins are imported by the UIDs of their files
+	imported := &models.Dashboard{FolderId: apiCmd.FolderId}
+	if apiCmd.Dashboard != nil {
+		imported.Uid = apiCmd.Dashboard.Get("uid").MustString()
+	}
+	folderIDs, err := savedDashboardFolderIDs(c.OrgId, imported)
+	if err != nil {
+		return response.Error(500, "Failed to get dashboard", err)
+	}
+	if rsp := hs.checkReadOnlyFolders(c, folderIDs...); rsp != nil {
+		return rsp
+	}
+	if rsp := checkDashboardImportReview(c, folderIDs); rsp != nil {
+		return rsp
+	}
+
 	dashInfo, dash, err := hs.PluginManager.ImportDashboard(apiCmd.PluginId, apiCmd.Path, c.OrgId, apiCmd.FolderId,
 		apiCmd.Dashboard, apiCmd.Overwrite, apiCmd.Inputs, c.SignedInUser, hs.DataService)
 	if err != nil {
@@ -402,6 +418,7 @@ func (hs *HTTPServer) InstallPlugin(c *models.ReqContext, dto dtos.InstallPlugin
 
 		return response.Error(http.StatusInternalServerError, "Failed to install plugin", err)
 	}
+	hs.frontendSettingsCache.invalidateAll()
 
 	return response.JSON(http.StatusOK, []byte{})
 }
@@ -423,6 +440,8 @@ func (hs *HTTPServer) UninstallPlugin(c *models.ReqContext) response.Response {
 
 		return response.Error(http.StatusInternalServerError, "Failed to uninstall plugin", err)
 	}
+	hs.frontendSettingsCache.invalidateAll()
+
 	return response.JSON(http.StatusOK, []byte{})
 }
 
@@ -32,9 +32,11 @@ func getPreferencesFor(orgID, userID, teamID int64) response.Response {
 	}
 
 	dto := dtos.Prefs{
-		Theme:           prefsQuery.Result.Theme,
-		HomeDashboardID: prefsQuery.Result.HomeDashboardId,
-		Timezone:        prefsQuery.Result.Timezone,
+		Theme:                prefsQuery.Result.Theme,
+		HomeDashboardID:      prefsQuery.Result.HomeDashboardId,
+		Timezone:             prefsQuery.Result.Timezone,
+		WeekStart:            prefsQuery.Result.WeekStart,
+		FiscalYearStartMonth: prefsQuery.Result.FiscalYearStartMonth,
 	}
 
 	return response.JSON(200, &dto)
@@ -46,13 +48,24 @@ func UpdateUserPreferences(c *models.ReqContext, dtoCmd dtos.UpdatePrefsCmd) res
 }
 
 func updatePreferencesFor(orgID, userID, teamId int64, dtoCmd *dtos.UpdatePrefsCmd) response.Response {
+	switch dtoCmd.WeekStart {
+	case "", "browser", "monday", "saturday", "sunday":
+	default:
+		return response.Error(400, "Invalid week start, expecting browser, monday, saturday or sunday", nil)
+	}
+	if dtoCmd.FiscalYearStartMonth < 0 || dtoCmd.FiscalYearStartMonth > 12 {
+		return response.Error(400, "Invalid fiscal year start month, expecting a month from 1 to 12", nil)
+	}
+
 	saveCmd := models.SavePreferencesCommand{
-		UserId:          userID,
-		OrgId:           orgID,
-		TeamId:          teamId,
-		Theme:           dtoCmd.Theme,
-		Timezone:        dtoCmd.Timezone,
-		HomeDashboardId: dtoCmd.HomeDashboardID,
+		UserId:               userID,
+		OrgId:                orgID,
+		TeamId:               teamId,
+		Theme:                dtoCmd.Theme,
+		Timezone:             dtoCmd.Timezone,
+		HomeDashboardId:      dtoCmd.HomeDashboardID,
+		WeekStart:            dtoCmd.WeekStart,
+		FiscalYearStartMonth: dtoCmd.FiscalYearStartMonth,
 	}
 
 	if err := bus.Dispatch(&saveCmd); err != nil {
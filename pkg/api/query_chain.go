@@ -0,0 +1,361 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/querycost"
+)
+
+var (
+	// chainedVariableName matches the valid names of the variables provided by queries, the names starting with __
+	// are the ones of the macros.
+	chainedVariableName = regexp.MustCompile(`^[a-zA-Z]\w*$`)
+	// chainedVariableRef matches the $variable, ${variable} and ${variable:format} references to variables.
+	chainedVariableRef = regexp.MustCompile(`\$(\w+)|\$\{(\w+)(?::(\w+))?\}`)
+)
+
+// chainedQuery is a query of a request in which queries provide variables to the other queries.
+type chainedQuery struct {
+	refID string
+	query *simplejson.Json
+	ds    *models.DataSource
+	// variable is the name of the variable the query provides the values of, with the values of field
+	variable string
+	field    string
+	// dependencies are the variables the query references
+	dependencies []string
+}
+
+// hasChainedQueries returns whether a query provides a variable to the other queries.
+func hasChainedQueries(queries []*simplejson.Json) bool {
+	for _, query := range queries {
+		if _, ok := query.CheckGet("provides"); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// handleChainedQueries handles POST /api/ds/query when a query provides a variable to the other queries. The
+// queries are run in the order of their dependencies, the queries with their dependencies resolved are run at once
+// by data source, with the values of the variables interpolated.
+func (hs *HTTPServer) handleChainedQueries(c *models.ReqContext, reqDTO dtos.MetricRequest) response.Response {
+	timeRange := hs.newTimeRange(c, reqDTO.From, reqDTO.To)
+
+	queries, resp := hs.newChainedQueries(c, reqDTO.Queries)
+	if resp != nil {
+		return resp
+	}
+
+	for _, q := range queries {
+		if err := hs.PluginRequestValidator.Validate(q.ds.Url, nil); err != nil {
+			return response.Error(http.StatusForbidden, "Access denied", err)
+		}
+	}
+
+	qdr := backend.NewQueryDataResponse()
+	// values are the values of the variables provided by the queries run successfully
+	values := map[string][]string{}
+	// providers are the ref IDs of the queries by the variables they provide
+	providers := map[string]string{}
+	for _, q := range queries {
+		if q.variable != "" {
+			providers[q.variable] = q.refID
+		}
+	}
+
+	pending := queries
+	for len(pending) > 0 {
+		var ready, waiting []*chainedQuery
+		for _, q := range pending {
+			if dependenciesRun(q, providers, qdr) {
+				ready = append(ready, q)
+			} else {
+				waiting = append(waiting, q)
+			}
+		}
+		if len(ready) == 0 {
+			return response.Error(http.StatusBadRequest, "The queries depend on each other in a cycle", nil)
+		}
+
+		for _, group := range groupByDatasource(ready) {
+			request := newDataQuery(c, reqDTO, timeRange)
+			for _, q := range group {
+				query, err := interpolateChainedQuery(q, values)
+				if err != nil {
+					qdr.Responses[q.refID] = backend.DataResponse{Error: err}
+					continue
+				}
+				request.Queries = append(request.Queries, newDataSubQuery(query, q.ds))
+			}
+			if len(request.Queries) == 0 {
+				continue
+			}
+
+			hs.runChainedQueries(c, reqDTO, group[0].ds, request, qdr)
+		}
+
+		for _, q := range ready {
+			if res := qdr.Responses[q.refID]; q.variable != "" && res.Error == nil {
+				values[q.variable] = chainedValues(res.Frames, q.field)
+			}
+		}
+		pending = waiting
+	}
+
+	if err := transformResponse(qdr, reqDTO); err != nil {
+		return response.Error(http.StatusBadRequest, "Failed to transform the query results", err)
+	}
+	resolveDataLinks(qdr, reqDTO.DataLinks, timeRange)
+	return toMacronResponse(qdr)
+}
+
+// newChainedQueries returns the queries of a request with their data sources and the variables they provide and
+// reference.
+func (hs *HTTPServer) newChainedQueries(c *models.ReqContext, queries []*simplejson.Json) ([]*chainedQuery, response.Response) {
+	result := make([]*chainedQuery, 0, len(queries))
+	variables := map[string]bool{}
+	for _, query := range queries {
+		datasourceID, err := query.Get("datasourceId").Int64()
+		if err != nil {
+			return nil, response.Error(http.StatusBadRequest, "Query missing data source ID", nil)
+		}
+		ds, err := hs.DatasourceCache.GetDatasource(datasourceID, c.SignedInUser, c.SkipCache)
+		if err != nil {
+			return nil, hs.handleGetDataSourceError(err, datasourceID)
+		}
+
+		q := &chainedQuery{
+			refID:    query.Get("refId").MustString("A"),
+			query:    query,
+			ds:       ds,
+			variable: query.GetPath("provides", "variable").MustString(),
+			field:    query.GetPath("provides", "field").MustString(),
+		}
+		if _, ok := query.CheckGet("provides"); ok {
+			if !chainedVariableName.MatchString(q.variable) {
+				return nil, response.Error(http.StatusBadRequest,
+					fmt.Sprintf("Query %s provides an invalid variable name %q", q.refID, q.variable), nil)
+			}
+			if variables[q.variable] {
+				return nil, response.Error(http.StatusBadRequest,
+					fmt.Sprintf("Variable %q is provided by several queries", q.variable), nil)
+			}
+			variables[q.variable] = true
+		}
+		result = append(result, q)
+	}
+
+	for _, q := range result {
+		model, err := q.query.Encode()
+		if err != nil {
+			return nil, response.Error(http.StatusBadRequest, "Invalid query", err)
+		}
+
+		seen := map[string]bool{}
+		for _, match := range chainedVariableRef.FindAllStringSubmatch(string(model), -1) {
+			name := match[1] + match[2]
+			if variables[name] && !seen[name] {
+				seen[name] = true
+				q.dependencies = append(q.dependencies, name)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// dependenciesRun returns whether the queries providing the variables a query depends on have run.
+func dependenciesRun(q *chainedQuery, providers map[string]string, qdr *backend.QueryDataResponse) bool {
+	for _, variable := range q.dependencies {
+		if _, ok := qdr.Responses[providers[variable]]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// groupByDatasource groups queries by data source, in the order of the queries.
+func groupByDatasource(queries []*chainedQuery) [][]*chainedQuery {
+	var groups [][]*chainedQuery
+	index := map[int64]int{}
+	for _, q := range queries {
+		i, ok := index[q.ds.Id]
+		if !ok {
+			i = len(groups)
+			index[q.ds.Id] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], q)
+	}
+	return groups
+}
+
+// runChainedQueries runs the queries of a request to a data source, adding their responses to qdr.
+// nolint: staticcheck // plugins.DataQuery deprecated
+func (hs *HTTPServer) runChainedQueries(c *models.ReqContext, reqDTO dtos.MetricRequest, ds *models.DataSource,
+	request plugins.DataQuery, qdr *backend.QueryDataResponse) {
+	start := time.Now()
+	resp, err := hs.DataService.HandleRequest(auditQueries(c, reqDTO, ds, &request), ds, request)
+	duration := time.Since(start)
+	if err == nil {
+		var groupQDR *backend.QueryDataResponse
+		if groupQDR, err = resp.ToBackendDataResponse(); err == nil {
+			hs.QueryCosts.Record(ds, reqDTO.DashboardID, querycost.ResponseCost(groupQDR, duration))
+			for _, query := range request.Queries {
+				qdr.Responses[query.RefID] = groupQDR.Responses[query.RefID]
+			}
+			return
+		}
+	}
+
+	queries := int64(len(request.Queries))
+	hs.QueryCosts.Record(ds, reqDTO.DashboardID, querycost.Cost{Queries: queries, Errors: queries, Duration: duration})
+	for _, query := range request.Queries {
+		qdr.Responses[query.RefID] = backend.DataResponse{Error: err}
+	}
+}
+
+// interpolateChainedQuery returns the query with the values of the variables it depends on interpolated. It fails
+// when a query providing a variable failed or returned no values.
+func interpolateChainedQuery(q *chainedQuery, values map[string][]string) (*simplejson.Json, error) {
+	for _, variable := range q.dependencies {
+		if len(values[variable]) == 0 {
+			return nil, fmt.Errorf("no values of the variable %q, provided by a query which failed or returned none",
+				variable)
+		}
+	}
+	if len(q.dependencies) == 0 {
+		return q.query, nil
+	}
+
+	var model interface{}
+	b, err := q.query.Encode()
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &model); err != nil {
+		return nil, err
+	}
+
+	interpolate := func(s string) string {
+		return chainedVariableRef.ReplaceAllStringFunc(s, func(ref string) string {
+			match := chainedVariableRef.FindStringSubmatch(ref)
+			variableValues, ok := values[match[1]+match[2]]
+			if !ok {
+				// a variable which isn't provided by a query, e.g. a macro
+				return ref
+			}
+			return formatChainedValues(variableValues, match[3], q.ds.Type)
+		})
+	}
+	return simplejson.NewFromAny(interpolateStrings(model, interpolate)), nil
+}
+
+// interpolateStrings interpolates the strings of a JSON value.
+func interpolateStrings(value interface{}, interpolate func(string) string) interface{} {
+	switch v := value.(type) {
+	case string:
+		return interpolate(v)
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = interpolateStrings(item, interpolate)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = interpolateStrings(item, interpolate)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// formatChainedValues formats the values of a variable, like the values of the multi-value variables. The values
+// are quoted for the SQL data sources and a regular expression for the Prometheus and Loki ones by default.
+func formatChainedValues(values []string, format string, datasourceType string) string {
+	if format == "" {
+		switch {
+		case sqlDatasourceTypes[datasourceType]:
+			format = "sqlstring"
+		case datasourceType == "prometheus" || datasourceType == "loki":
+			format = "regex"
+		default:
+			format = "csv"
+		}
+	}
+
+	formatted := make([]string, len(values))
+	switch format {
+	case "sqlstring":
+		for i, value := range values {
+			formatted[i] = "'" + strings.ReplaceAll(value, "'", "''") + "'"
+		}
+		return strings.Join(formatted, ",")
+	case "regex":
+		for i, value := range values {
+			formatted[i] = regexp.QuoteMeta(value)
+		}
+		if len(formatted) == 1 {
+			return formatted[0]
+		}
+		return "(" + strings.Join(formatted, "|") + ")"
+	case "pipe":
+		return strings.Join(values, "|")
+	case "json":
+		b, _ := json.Marshal(values)
+		return string(b)
+	default:
+		return strings.Join(values, ",")
+	}
+}
+
+// chainedValues returns the distinct values a query provides: the values of the field, or of the label of the
+// fields, with the name, or of the first string field of the frames when it has no name.
+func chainedValues(frames data.Frames, name string) []string {
+	values := []string{}
+	seen := map[string]bool{}
+	add := func(value string) {
+		if !seen[value] {
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+
+	for _, frame := range frames {
+		for _, field := range frame.Fields {
+			if name == "" && field.Type().NonNullableType() != data.FieldTypeString {
+				continue
+			}
+			if name != "" && field.Name != name {
+				if value, ok := field.Labels[name]; ok {
+					add(value)
+				}
+				continue
+			}
+
+			for i := 0; i < field.Len(); i++ {
+				if value, ok := field.ConcreteAt(i); ok {
+					add(fmt.Sprint(value))
+				}
+			}
+			if name == "" {
+				break
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,226 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/plugins/manager"
+	"github.com/grafana/grafana/pkg/services/querycost"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+type fakeDatasourcesCache struct {
+	datasources map[int64]*models.DataSource
+}
+
+func (c *fakeDatasourcesCache) GetDatasource(datasourceID int64, user *models.SignedInUser, skipCache bool) (*models.DataSource, error) {
+	if ds, ok := c.datasources[datasourceID]; ok {
+		return ds, nil
+	}
+	return nil, models.ErrDataSourceNotFound
+}
+
+func (c *fakeDatasourcesCache) GetDatasourceByUID(string, *models.SignedInUser, bool) (*models.DataSource, error) {
+	return nil, models.ErrDataSourceNotFound
+}
+
+type fakePluginRequestValidator struct{}
+
+func (fakePluginRequestValidator) Validate(string, *http.Request) error {
+	return nil
+}
+
+type fakeBackendPM struct {
+	backendplugin.Manager
+}
+
+func (pm fakeBackendPM) GetDataPlugin(string) interface{} {
+	return nil
+}
+
+// nolint: staticcheck // plugins.DataPlugin deprecated
+type fakeChainedDataPlugin struct {
+	queries []plugins.DataQuery
+	frames  func(query plugins.DataSubQuery) data.Frames
+}
+
+// nolint: staticcheck // plugins.DataPlugin deprecated
+func (p *fakeChainedDataPlugin) DataQuery(_ context.Context, _ *models.DataSource, query plugins.DataQuery) (plugins.DataResponse, error) {
+	p.queries = append(p.queries, query)
+	resp := plugins.DataResponse{Results: map[string]plugins.DataQueryResult{}}
+	for _, q := range query.Queries {
+		resp.Results[q.RefID] = plugins.DataQueryResult{RefID: q.RefID, Dataframes: plugins.NewDecodedDataFrames(p.frames(q))}
+	}
+	return resp, nil
+}
+
+func TestChainedQueries(t *testing.T) {
+	prometheus := &fakeChainedDataPlugin{frames: func(q plugins.DataSubQuery) data.Frames {
+		hosts := []string{"web-1", "web-2", "db'1"}
+		if q.Model.Get("expr").MustString() == "none" {
+			hosts = []string{}
+		}
+		return data.Frames{data.NewFrame("", data.NewField("host", nil, hosts))}
+	}}
+	postgres := &fakeChainedDataPlugin{frames: func(q plugins.DataSubQuery) data.Frames {
+		return data.Frames{data.NewFrame("", data.NewField("host", nil, []string{"web-1"}))}
+	}}
+
+	dataService := tsdb.NewService()
+	dataService.PluginManager = &manager.PluginManager{BackendPluginManager: fakeBackendPM{}}
+	// nolint: staticcheck // plugins.DataPlugin deprecated
+	dataService.RegisterQueryHandler("prometheus", func(*models.DataSource) (plugins.DataPlugin, error) {
+		return prometheus, nil
+	})
+	// nolint: staticcheck // plugins.DataPlugin deprecated
+	dataService.RegisterQueryHandler("postgres", func(*models.DataSource) (plugins.DataPlugin, error) {
+		return postgres, nil
+	})
+
+	hs := &HTTPServer{
+		Cfg: setting.NewCfg(),
+		DatasourceCache: &fakeDatasourcesCache{datasources: map[int64]*models.DataSource{
+			1: {Id: 1, Type: "prometheus"},
+			2: {Id: 2, Type: "postgres"},
+		}},
+		DataService:            &dataService,
+		PluginRequestValidator: fakePluginRequestValidator{},
+		QueryCosts:             &querycost.QueryCostService{Cfg: setting.NewCfg()},
+		log:                    log.New("test"),
+	}
+
+	query := func(t *testing.T, queries ...map[string]interface{}) (int, map[string]interface{}) {
+		t.Helper()
+
+		reqDTO := dtos.MetricRequest{From: "now-1h", To: "now"}
+		for _, q := range queries {
+			reqDTO.Queries = append(reqDTO.Queries, simplejson.NewFromAny(q))
+		}
+
+		var code int
+		body := map[string]interface{}{}
+		loggedInUserScenarioWithRole(t, "When calling POST on", "GET", "/api/ds/query", "/api/ds/query",
+			models.ROLE_VIEWER, func(sc *scenarioContext) {
+				addExploreAllowedHandler()
+				sc.handlerFunc = func(c *models.ReqContext) response.Response {
+					return hs.QueryMetricsV2(c, reqDTO)
+				}
+				sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+				code = sc.resp.Code
+				require.NoError(t, json.NewDecoder(sc.resp.Body).Decode(&body))
+			})
+		return code, body
+	}
+
+	topHosts := map[string]interface{}{
+		"refId": "A", "datasourceId": 1, "expr": "topk(10, sum by (host) (rate(requests_total[5m])))",
+		"provides": map[string]interface{}{"variable": "hosts", "field": "host"},
+	}
+
+	t.Run("Should interpolate the values provided by a query in the queries depending on it", func(t *testing.T) {
+		prometheus.queries, postgres.queries = nil, nil
+		// the dependent queries are before the query they depend on
+		code, _ := query(t,
+			map[string]interface{}{"refId": "B", "datasourceId": 2, "rawSql": "SELECT * FROM hosts WHERE host IN ($hosts)"},
+			map[string]interface{}{"refId": "C", "datasourceId": 1, "expr": `up{host=~"${hosts}"}`},
+			map[string]interface{}{"refId": "D", "datasourceId": 2, "rawSql": "SELECT '${hosts:csv}' AS hosts"},
+			topHosts,
+		)
+		require.Equal(t, 200, code)
+
+		require.Len(t, prometheus.queries, 2)
+		assert.Equal(t, "A", prometheus.queries[0].Queries[0].RefID)
+		assert.Equal(t, `up{host=~"(web-1|web-2|db'1)"}`, prometheus.queries[1].Queries[0].Model.Get("expr").MustString())
+		// the queries of a data source are run together
+		require.Len(t, postgres.queries, 1)
+		require.Len(t, postgres.queries[0].Queries, 2)
+		assert.Equal(t, "SELECT * FROM hosts WHERE host IN ('web-1','web-2','db''1')",
+			postgres.queries[0].Queries[0].Model.Get("rawSql").MustString())
+		assert.Equal(t, "SELECT 'web-1,web-2,db'1' AS hosts", postgres.queries[0].Queries[1].Model.Get("rawSql").MustString())
+	})
+
+	t.Run("Should chain queries providing the values of their first string field", func(t *testing.T) {
+		prometheus.queries, postgres.queries = nil, nil
+		code, _ := query(t,
+			map[string]interface{}{"refId": "A", "datasourceId": 2, "rawSql": "SELECT host FROM hosts",
+				"provides": map[string]interface{}{"variable": "host"}},
+			map[string]interface{}{"refId": "B", "datasourceId": 1, "expr": `up{host="$host"}`},
+		)
+		require.Equal(t, 200, code)
+		assert.Equal(t, `up{host="web-1"}`, prometheus.queries[0].Queries[0].Model.Get("expr").MustString())
+	})
+
+	t.Run("Should fail the queries depending on a query without values", func(t *testing.T) {
+		prometheus.queries, postgres.queries = nil, nil
+		code, body := query(t,
+			map[string]interface{}{"refId": "A", "datasourceId": 1, "expr": "none",
+				"provides": map[string]interface{}{"variable": "hosts", "field": "host"}},
+			map[string]interface{}{"refId": "B", "datasourceId": 2, "rawSql": "SELECT * FROM hosts WHERE host IN ($hosts)"},
+		)
+		require.Equal(t, 400, code)
+		assert.Empty(t, postgres.queries)
+		results := body["results"].(map[string]interface{})
+		assert.Contains(t, results["B"].(map[string]interface{})["error"], `no values of the variable "hosts"`)
+	})
+
+	t.Run("Should fail when the queries depend on each other", func(t *testing.T) {
+		code, _ := query(t,
+			map[string]interface{}{"refId": "A", "datasourceId": 1, "expr": `up{job="$jobs"}`,
+				"provides": map[string]interface{}{"variable": "hosts", "field": "host"}},
+			map[string]interface{}{"refId": "B", "datasourceId": 1, "expr": `up{host="$hosts"}`,
+				"provides": map[string]interface{}{"variable": "jobs", "field": "job"}},
+		)
+		require.Equal(t, 400, code)
+	})
+
+	t.Run("Should fail when a variable is provided by several queries", func(t *testing.T) {
+		code, _ := query(t, topHosts, map[string]interface{}{"refId": "B", "datasourceId": 1, "expr": "up",
+			"provides": map[string]interface{}{"variable": "hosts"}})
+		require.Equal(t, 400, code)
+	})
+}
+
+func TestChainedValues(t *testing.T) {
+	frames := data.Frames{
+		data.NewFrame("",
+			data.NewField("time", nil, []time.Time{{}, {}}),
+			data.NewField("Value", data.Labels{"host": "web-1", "job": "api"}, []float64{1, 2})),
+		data.NewFrame("",
+			data.NewField("time", nil, []time.Time{{}}),
+			data.NewField("Value", data.Labels{"host": "web-2", "job": "api"}, []float64{1})),
+		data.NewFrame("",
+			data.NewField("job", nil, []string{"db", "api"})),
+	}
+
+	assert.Equal(t, []string{"web-1", "web-2"}, chainedValues(frames, "host"))
+	assert.Equal(t, []string{"api", "db"}, chainedValues(frames, "job"))
+	assert.Equal(t, []string{"db", "api"}, chainedValues(frames, ""))
+	assert.Empty(t, chainedValues(frames, "instance"))
+}
+
+func TestFormatChainedValues(t *testing.T) {
+	values := []string{"web.1", "web'2"}
+
+	assert.Equal(t, "'web.1','web''2'", formatChainedValues(values, "", "mysql"))
+	assert.Equal(t, `(web\.1|web'2)`, formatChainedValues(values, "", "prometheus"))
+	assert.Equal(t, `web\.1`, formatChainedValues(values[:1], "regex", "postgres"))
+	assert.Equal(t, "web.1,web'2", formatChainedValues(values, "", "elasticsearch"))
+	assert.Equal(t, "web.1|web'2", formatChainedValues(values, "pipe", "mysql"))
+	assert.Equal(t, `["web.1","web'2"]`, formatChainedValues(values, "json", "mysql"))
+}
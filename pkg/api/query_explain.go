@@ -0,0 +1,69 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb"
+)
+
+// ExplainQueries explains what the queries of a data source will do, their macros, the statements or requests sent
+// to the data source with the secrets redacted and the frames of the response, without running them.
+// POST /api/ds/explain
+func (hs *HTTPServer) ExplainQueries(c *models.ReqContext, reqDTO dtos.MetricRequest) response.Response {
+	if len(reqDTO.Queries) == 0 {
+		return response.Error(http.StatusBadRequest, "No queries found in query", nil)
+	}
+	if resp := resolveSavedQueries(c, reqDTO.Queries); resp != nil {
+		return resp
+	}
+	if resp := hs.checkQueriesExplorePermissions(c, reqDTO); resp != nil {
+		return resp
+	}
+
+	timeRange := hs.newTimeRange(c, reqDTO.From, reqDTO.To)
+	request := newDataQuery(c, reqDTO, timeRange)
+
+	// like the queries run, the queries are of the data source of the first query
+	var ds *models.DataSource
+	for i, query := range reqDTO.Queries {
+		if query.Get("datasource").MustString("") == expr.DatasourceName {
+			return response.Error(http.StatusBadRequest, "Expressions can't be explained", nil)
+		}
+
+		datasourceID, err := query.Get("datasourceId").Int64()
+		if err != nil {
+			return response.Error(http.StatusBadRequest, "Query missing data source ID", nil)
+		}
+		if i == 0 {
+			ds, err = hs.DatasourceCache.GetDatasource(datasourceID, c.SignedInUser, c.SkipCache)
+			if err != nil {
+				return hs.handleGetDataSourceError(err, datasourceID)
+			}
+		}
+
+		request.Queries = append(request.Queries, newDataSubQuery(query, ds))
+	}
+
+	if err := hs.PluginRequestValidator.Validate(ds.Url, nil); err != nil {
+		return response.Error(http.StatusForbidden, "Access denied", err)
+	}
+
+	explanations, err := hs.DataService.ExplainRequest(c.Req.Context(), ds, request)
+	if err != nil {
+		switch {
+		case errors.Is(err, tsdb.ErrExplainNotSupported), errors.Is(err, tsdb.ErrMaxDataAgeExceeded):
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		case errors.Is(err, tsdb.ErrQueryPolicyViolation):
+			return response.Error(http.StatusForbidden, err.Error(), err)
+		default:
+			return response.Error(http.StatusInternalServerError, "Failed to explain the queries", err)
+		}
+	}
+
+	return response.JSON(http.StatusOK, explanations)
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/manager"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/grafana/grafana/pkg/tsdb/prometheus"
+)
+
+func TestExplainQueries(t *testing.T) {
+	dataService := tsdb.NewService()
+	dataService.PluginManager = &manager.PluginManager{BackendPluginManager: fakeBackendPM{}}
+	dataService.RegisterQueryHandler("prometheus", prometheus.New(httpclient.NewProvider()))
+	// nolint: staticcheck // plugins.DataPlugin deprecated
+	dataService.RegisterQueryHandler("graphite", func(*models.DataSource) (plugins.DataPlugin, error) {
+		return &fakeChainedDataPlugin{}, nil
+	})
+
+	ds := &models.DataSource{
+		Id: 1, Uid: "prom", OrgId: testOrgID, Type: "prometheus", Url: "http://prometheus:9090",
+		BasicAuth: true, BasicAuthUser: "admin", JsonData: simplejson.New(),
+	}
+	hs := &HTTPServer{
+		Cfg:                    setting.NewCfg(),
+		DatasourceCache:        &fakeDatasourceCache{datasource: ds},
+		DataService:            &dataService,
+		PluginRequestValidator: fakePluginRequestValidator{},
+		log:                    log.New("test"),
+	}
+
+	explain := func(t *testing.T, queries ...map[string]interface{}) (int, []plugins.DataQueryExplanation) {
+		t.Helper()
+
+		cmd := dtos.MetricRequest{From: "1600000000000", To: "1600003600000"}
+		for _, query := range queries {
+			cmd.Queries = append(cmd.Queries, simplejson.NewFromAny(query))
+		}
+
+		var code int
+		var explanations []plugins.DataQueryExplanation
+		loggedInUserScenarioWithRole(t, "When calling POST on", "GET", "/api/ds/explain", "/api/ds/explain",
+			models.ROLE_VIEWER, func(sc *scenarioContext) {
+				addExploreAllowedHandler()
+				sc.handlerFunc = func(c *models.ReqContext) response.Response {
+					return hs.ExplainQueries(c, cmd)
+				}
+				sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+				code = sc.resp.Code
+				if code == 200 {
+					require.NoError(t, json.NewDecoder(sc.resp.Body).Decode(&explanations))
+				}
+			})
+		return code, explanations
+	}
+
+	t.Run("Should explain the queries with the secrets redacted", func(t *testing.T) {
+		code, explanations := explain(t, map[string]interface{}{"refId": "A", "datasourceId": 1, "expr": "up"})
+		require.Equal(t, 200, code)
+		require.Len(t, explanations, 1)
+		assert.Equal(t, "A", explanations[0].RefID)
+		assert.Equal(t, "up", explanations[0].Statement)
+		require.NotNil(t, explanations[0].Request)
+		assert.Equal(t, "http://prometheus:9090/api/v1/query_range", explanations[0].Request.URL)
+		assert.Equal(t, "Basic redacted", explanations[0].Request.Headers["Authorization"])
+	})
+
+	t.Run("Should return 400 for the data sources that don't explain their queries", func(t *testing.T) {
+		ds.Type = "graphite"
+		defer func() { ds.Type = "prometheus" }()
+
+		code, _ := explain(t, map[string]interface{}{"refId": "A", "datasourceId": 1, "target": "a.b.c"})
+		assert.Equal(t, 400, code)
+	})
+
+	t.Run("Should return 400 for the expressions", func(t *testing.T) {
+		code, _ := explain(t,
+			map[string]interface{}{"refId": "A", "datasourceId": 1, "expr": "up"},
+			map[string]interface{}{"refId": "B", "datasource": "__expr__", "type": "math", "expression": "$A * 2"})
+		assert.Equal(t, 400, code)
+	})
+
+	t.Run("Should return 400 for a missing data source", func(t *testing.T) {
+		code, _ := explain(t, map[string]interface{}{"refId": "A", "datasourceId": 2, "expr": "up"})
+		assert.Equal(t, 400, code)
+	})
+
+	t.Run("Should require queries", func(t *testing.T) {
+		code, _ := explain(t)
+		assert.Equal(t, 400, code)
+	})
+}
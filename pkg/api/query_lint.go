@@ -0,0 +1,33 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/components/querylint"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// LintQueries returns the warnings of the static analysis of queries.
+// POST /api/ds/lint
+func (hs *HTTPServer) LintQueries(c *models.ReqContext, reqDTO dtos.LintQueriesRequest) response.Response {
+	result := make([]dtos.QueryLintResult, 0, len(reqDTO.Queries))
+	for _, query := range reqDTO.Queries {
+		datasourceType := ""
+		if datasourceID := query.Get("datasourceId").MustInt64(0); datasourceID != 0 {
+			ds, err := hs.DatasourceCache.GetDatasource(datasourceID, c.SignedInUser, c.SkipCache)
+			if err != nil {
+				return hs.handleGetDataSourceError(err, datasourceID)
+			}
+			datasourceType = ds.Type
+		}
+
+		result = append(result, dtos.QueryLintResult{
+			RefID:    query.Get("refId").MustString("A"),
+			Warnings: querylint.Lint(datasourceType, query),
+		})
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/querylint"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintQueries(t *testing.T) {
+	hs := &HTTPServer{
+		DatasourceCache: &fakeDatasourceCache{datasource: &models.DataSource{Id: 1, Type: "loki"}},
+		log:             log.New("test"),
+	}
+	c := &models.ReqContext{SignedInUser: &models.SignedInUser{OrgId: testOrgID}}
+
+	lint := func(t *testing.T, queries ...map[string]interface{}) (int, []dtos.QueryLintResult) {
+		t.Helper()
+
+		req := dtos.LintQueriesRequest{}
+		for _, query := range queries {
+			req.Queries = append(req.Queries, simplejson.NewFromAny(query))
+		}
+		resp := hs.LintQueries(c, req)
+		var result []dtos.QueryLintResult
+		if resp.Status() == 200 {
+			require.NoError(t, json.Unmarshal(resp.Body(), &result))
+		}
+		return resp.Status(), result
+	}
+
+	t.Run("Should lint the queries", func(t *testing.T) {
+		status, result := lint(t,
+			map[string]interface{}{"refId": "A", "expr": "http_requests_total"},
+			map[string]interface{}{"refId": "B", "rawSql": "SELECT * FROM orders"},
+			map[string]interface{}{"refId": "C", "expr": "rate(http_requests_total[5m])"},
+		)
+		require.Equal(t, 200, status)
+		require.Len(t, result, 3)
+		require.Equal(t, "A", result[0].RefID)
+		require.Equal(t, querylint.CounterWithoutRate, result[0].Warnings[0].Rule)
+		require.Equal(t, querylint.SelectStarWithoutTimeFilter, result[1].Warnings[0].Rule)
+		require.Empty(t, result[2].Warnings)
+	})
+
+	t.Run("Should lint the queries by the type of their data source", func(t *testing.T) {
+		status, result := lint(t, map[string]interface{}{"refId": "A", "datasourceId": 1, "expr": "http_requests_total"})
+		require.Equal(t, 200, status)
+		require.Empty(t, result[0].Warnings)
+	})
+
+	t.Run("Should fail when the data source doesn't exist", func(t *testing.T) {
+		status, _ := lint(t, map[string]interface{}{"refId": "A", "datasourceId": 2, "expr": "up"})
+		require.Equal(t, 400, status)
+	})
+}
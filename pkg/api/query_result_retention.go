@@ -0,0 +1,179 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/oauthtoken"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
+)
+
+// retainedQueryResults are the last successful results of the queries of a panel by ref ID.
+type retainedQueryResults struct {
+	Results map[string]retainedQueryResult
+}
+
+type retainedQueryResult struct {
+	// Frames are the frames of the response encoded to Arrow
+	Frames [][]byte
+	Time   time.Time
+}
+
+func init() {
+	remotecache.Register(&retainedQueryResults{})
+}
+
+// retainedQueryResultsKey returns the key of the results of the queries of a panel, with the UIDs of their data
+// sources and a hash of their models and of the span of their time range, so that the results are only returned to
+// the same queries of the panel. The span is hashed instead of the time range, so that the refreshes of a panel
+// showing the last hours get the results of the previous refreshes.
+func retainedQueryResultsKey(orgID int64, reqDTO dtos.MetricRequest, timeRange plugins.DataTimeRange,
+	datasources []*models.DataSource) (string, error) {
+	uids := make([]string, 0, len(datasources))
+	for _, ds := range datasources {
+		uids = append(uids, ds.Uid)
+	}
+	hash := sha256.New()
+	for _, query := range reqDTO.Queries {
+		encoded, err := query.MarshalJSON()
+		if err != nil {
+			return "", err
+		}
+		_, _ = hash.Write(encoded)
+	}
+	span := (timeRange.GetToAsMsEpoch() - timeRange.GetFromAsMsEpoch()) / 1000
+	_, _ = fmt.Fprintf(hash, "%d", span)
+	return fmt.Sprintf("query-results-%d-%d-%d-%s-%s", orgID, reqDTO.DashboardID, reqDTO.PanelID,
+		strings.Join(uids, ","), hex.EncodeToString(hash.Sum(nil))), nil
+}
+
+// failedQueryResponse returns the response of the queries of a request which failed as a whole.
+func failedQueryResponse(reqDTO dtos.MetricRequest, err error) *backend.QueryDataResponse {
+	qdr := backend.NewQueryDataResponse()
+	for _, query := range reqDTO.Queries {
+		qdr.Responses[query.Get("refId").MustString("A")] = backend.DataResponse{Error: err}
+	}
+	return qdr
+}
+
+// retainQueryResults keeps the successful responses of the queries of a panel, and replaces the failed responses by
+// the last successful ones, with a notice that they're stale. It returns whether a response was replaced. The
+// results are only kept for the dashboards the user can view, and not for the data sources forwarding the identity
+// of the users, or connecting with it, whose results are theirs only.
+func (hs *HTTPServer) retainQueryResults(c *models.ReqContext, reqDTO dtos.MetricRequest, timeRange plugins.DataTimeRange,
+	qdr *backend.QueryDataResponse, datasources ...*models.DataSource) bool {
+	cfg := hs.Cfg.QueryResultRetention
+	if !cfg.Enabled || reqDTO.DashboardID == 0 || reqDTO.PanelID == 0 {
+		return false
+	}
+	for _, ds := range datasources {
+		if oauthtoken.IsOAuthPassThruEnabled(ds) || sqleng.IAMAuth(ds) != "" {
+			return false
+		}
+	}
+	if canView, err := guardian.New(reqDTO.DashboardID, c.OrgId, c.SignedInUser).CanView(); err != nil || !canView {
+		return false
+	}
+
+	key, err := retainedQueryResultsKey(c.OrgId, reqDTO, timeRange, datasources)
+	if err != nil {
+		hs.log.Debug("Failed to get the key of the retained query results", "err", err)
+		return false
+	}
+	retained := &retainedQueryResults{}
+	value, err := hs.RemoteCacheService.Get(key)
+	if err == nil {
+		if results, ok := value.(*retainedQueryResults); ok {
+			retained = results
+		}
+	} else if !errors.Is(err, remotecache.ErrCacheItemNotFound) {
+		hs.log.Warn("Failed to get the retained query results", "key", key, "err", err)
+	}
+	if retained.Results == nil {
+		retained.Results = map[string]retainedQueryResult{}
+	}
+
+	refIDs := make([]string, 0, len(qdr.Responses))
+	for refID := range qdr.Responses {
+		refIDs = append(refIDs, refID)
+	}
+	sort.Strings(refIDs)
+
+	changed, replaced := false, false
+	for _, refID := range refIDs {
+		res := qdr.Responses[refID]
+		if res.Error == nil {
+			frames, err := res.Frames.MarshalArrow()
+			if err != nil {
+				hs.log.Debug("Failed to encode the query result to retain", "refId", refID, "err", err)
+				continue
+			}
+			retained.Results[refID] = retainedQueryResult{Frames: frames, Time: time.Now()}
+			changed = true
+			continue
+		}
+
+		result, ok := retained.Results[refID]
+		if !ok {
+			continue
+		}
+		frames, err := data.UnmarshalArrowFrames(result.Frames)
+		if err != nil {
+			hs.log.Warn("Failed to decode the retained query result", "key", key, "refId", refID, "err", err)
+			continue
+		}
+		notice := data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text: fmt.Sprintf("The query failed, showing the last successful result from %s: %s",
+				result.Time.UTC().Format(time.RFC3339), res.Error),
+		}
+		for _, frame := range frames {
+			frame.AppendNotices(notice)
+		}
+		qdr.Responses[refID] = backend.DataResponse{Frames: frames}
+		replaced = true
+	}
+
+	if !changed {
+		return replaced
+	}
+
+	// the ref IDs are sorted for the size cap to keep the same results every time
+	retainedRefIDs := make([]string, 0, len(retained.Results))
+	for refID := range retained.Results {
+		retainedRefIDs = append(retainedRefIDs, refID)
+	}
+	sort.Strings(retainedRefIDs)
+
+	size := 0
+	for _, refID := range retainedRefIDs {
+		resultSize := 0
+		for _, frame := range retained.Results[refID].Frames {
+			resultSize += len(frame)
+		}
+		if size+resultSize > cfg.MaxSize {
+			hs.log.Debug("Query result too large to retain", "key", key, "refId", refID)
+			delete(retained.Results, refID)
+			continue
+		}
+		size += resultSize
+	}
+
+	if err := hs.RemoteCacheService.Set(key, retained, cfg.TTL); err != nil {
+		hs.log.Warn("Failed to retain the query results", "key", key, "err", err)
+	}
+	return replaced
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestRetainQueryResults(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.QueryResultRetention = setting.QueryResultRetentionSettings{Enabled: true, MaxSize: 1048576, TTL: 24 * time.Hour}
+	hs := &HTTPServer{Cfg: cfg, RemoteCacheService: remotecache.NewFakeStore(t), log: log.New("test")}
+	c := &models.ReqContext{SignedInUser: &models.SignedInUser{OrgId: testOrgID}}
+	ds := &models.DataSource{Id: 1, Uid: "prom", OrgId: testOrgID, Type: "prometheus", JsonData: simplejson.New()}
+	timeRange := plugins.NewDataTimeRange("now-1h", "now")
+
+	origNewGuardian := guardian.New
+	t.Cleanup(func() {
+		guardian.New = origNewGuardian
+	})
+	guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanViewValue: true})
+
+	reqDTO := dtos.MetricRequest{
+		Queries: []*simplejson.Json{
+			simplejson.NewFromAny(map[string]interface{}{"refId": "A"}),
+			simplejson.NewFromAny(map[string]interface{}{"refId": "B"}),
+		},
+		DashboardID: 1,
+		PanelID:     2,
+	}
+	frame := func(value float64) data.Frames {
+		return data.Frames{data.NewFrame("requests", data.NewField("value", nil, []float64{value}))}
+	}
+
+	success := backend.NewQueryDataResponse()
+	success.Responses["A"] = backend.DataResponse{Frames: frame(1)}
+	success.Responses["B"] = backend.DataResponse{Error: errors.New("bad query")}
+	require.False(t, hs.retainQueryResults(c, reqDTO, timeRange, success, ds))
+	require.Nil(t, success.Responses["A"].Frames[0].Meta)
+
+	t.Run("failed responses are replaced by the retained results", func(t *testing.T) {
+		qdr := failedQueryResponse(reqDTO, errors.New("connection refused"))
+		require.True(t, hs.retainQueryResults(c, reqDTO, timeRange, qdr, ds))
+
+		res := qdr.Responses["A"]
+		require.NoError(t, res.Error)
+		require.Len(t, res.Frames, 1)
+		require.Equal(t, 1.0, res.Frames[0].Fields[0].At(0))
+		require.Len(t, res.Frames[0].Meta.Notices, 1)
+		require.Equal(t, data.NoticeSeverityWarning, res.Frames[0].Meta.Notices[0].Severity)
+		require.Contains(t, res.Frames[0].Meta.Notices[0].Text, "connection refused")
+
+		// no result was ever retained for B
+		require.Error(t, qdr.Responses["B"].Error)
+	})
+
+	t.Run("results are retained by panel", func(t *testing.T) {
+		other := reqDTO
+		other.PanelID = 3
+		qdr := failedQueryResponse(other, errors.New("connection refused"))
+		require.False(t, hs.retainQueryResults(c, other, timeRange, qdr, ds))
+		require.Error(t, qdr.Responses["A"].Error)
+	})
+
+	t.Run("results are retained by data source, queries and span of the time range", func(t *testing.T) {
+		failed := func(reqDTO dtos.MetricRequest, timeRange plugins.DataTimeRange, ds *models.DataSource) bool {
+			return hs.retainQueryResults(c, reqDTO, timeRange, failedQueryResponse(reqDTO, errors.New("connection refused")), ds)
+		}
+
+		otherQueries := reqDTO
+		otherQueries.Queries = []*simplejson.Json{
+			simplejson.NewFromAny(map[string]interface{}{"refId": "A", "expr": "up{job=\"other\"}"}),
+			simplejson.NewFromAny(map[string]interface{}{"refId": "B"}),
+		}
+		require.False(t, failed(otherQueries, timeRange, ds))
+		require.False(t, failed(reqDTO, timeRange, &models.DataSource{Id: 2, Uid: "other", OrgId: testOrgID}))
+		require.False(t, failed(reqDTO, plugins.NewDataTimeRange("now-6h", "now"), ds))
+		// the next refresh of the panel
+		require.True(t, failed(reqDTO, plugins.NewDataTimeRange("now-61m", "now-1m"), ds))
+	})
+
+	t.Run("results are not retained for the dashboards the user can't view", func(t *testing.T) {
+		guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanViewValue: false})
+		t.Cleanup(func() {
+			guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanViewValue: true})
+		})
+		qdr := failedQueryResponse(reqDTO, errors.New("connection refused"))
+		require.False(t, hs.retainQueryResults(c, reqDTO, timeRange, qdr, ds))
+		require.Error(t, qdr.Responses["A"].Error)
+	})
+
+	t.Run("results are not retained for the data sources forwarding the identity of the users", func(t *testing.T) {
+		oauth := &models.DataSource{Id: 3, Uid: "oauth", OrgId: testOrgID,
+			JsonData: simplejson.NewFromAny(map[string]interface{}{"oauthPassThru": true})}
+		success := backend.NewQueryDataResponse()
+		success.Responses["A"] = backend.DataResponse{Frames: frame(1)}
+		hs.retainQueryResults(c, reqDTO, timeRange, success, oauth)
+
+		qdr := failedQueryResponse(reqDTO, errors.New("connection refused"))
+		require.False(t, hs.retainQueryResults(c, reqDTO, timeRange, qdr, oauth))
+	})
+
+	t.Run("results are not retained without a panel or when disabled", func(t *testing.T) {
+		other := reqDTO
+		other.PanelID = 0
+		require.False(t, hs.retainQueryResults(c, other, timeRange, failedQueryResponse(other, errors.New("connection refused")), ds))
+
+		disabled := &HTTPServer{Cfg: setting.NewCfg()}
+		require.False(t, disabled.retainQueryResults(c, reqDTO, timeRange, failedQueryResponse(reqDTO, errors.New("connection refused")), ds))
+	})
+
+	t.Run("results larger than the max size are not retained", func(t *testing.T) {
+		small := *hs
+		small.Cfg = setting.NewCfg()
+		small.Cfg.QueryResultRetention = setting.QueryResultRetentionSettings{Enabled: true, MaxSize: 1, TTL: 24 * time.Hour}
+
+		qdr := backend.NewQueryDataResponse()
+		qdr.Responses["A"] = backend.DataResponse{Frames: frame(2)}
+		small.retainQueryResults(c, reqDTO, timeRange, qdr, ds)
+
+		qdr = failedQueryResponse(reqDTO, errors.New("connection refused"))
+		require.False(t, small.retainQueryResults(c, reqDTO, timeRange, qdr, ds))
+	})
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+// GET /api/org/read-only-folders
+func GetReadOnlyFolders(c *models.ReqContext) response.Response {
+	query := models.GetReadOnlyFoldersQuery{OrgId: c.OrgId}
+	if err := bus.Dispatch(&query); err != nil {
+		return response.Error(500, "Failed to get read-only folders", err)
+	}
+	return response.JSON(200, query.Result)
+}
+
+// PUT /api/org/read-only-folders
+func SetReadOnlyFolders(c *models.ReqContext, form dtos.SetReadOnlyFoldersForm) response.Response {
+	cmd := models.SetReadOnlyFoldersCommand{OrgId: c.OrgId, FolderIds: []int64{}}
+	for _, uid := range form.FolderUids {
+		query := models.GetDashboardQuery{Uid: uid, OrgId: c.OrgId}
+		if err := bus.Dispatch(&query); err != nil {
+			if errors.Is(err, models.ErrDashboardNotFound) {
+				return response.Error(400, fmt.Sprintf("Folder %q not found", uid), err)
+			}
+			return response.Error(500, "Failed to get folder", err)
+		}
+		if !query.Result.IsFolder {
+			return response.Error(400, fmt.Sprintf("%q is not the UID of a folder", uid), nil)
+		}
+		cmd.FolderIds = append(cmd.FolderIds, query.Result.Id)
+	}
+
+	if err := bus.Dispatch(&cmd); err != nil {
+		return response.Error(500, "Failed to set read-only folders", err)
+	}
+	return response.Success("Read-only folders updated")
+}
+
+// checkReadOnlyFolders returns a 403 response when one of the folders is read-only for the user, or else nil. The
+// General folder, of ID 0, can't be read-only.
+func (hs *HTTPServer) checkReadOnlyFolders(c *models.ReqContext, folderIDs ...int64) response.Response {
+	for _, folderID := range folderIDs {
+		if folderID == 0 {
+			continue
+		}
+
+		query := models.IsReadOnlyFolderQuery{OrgId: c.OrgId, FolderId: folderID}
+		if err := bus.Dispatch(&query); err != nil {
+			return response.Error(500, "Failed to check read-only folders", err)
+		}
+		if query.Result && !hs.canWriteReadOnlyFolder(c, folderID) {
+			return response.Error(403, "Access denied, the dashboards of the folder are read-only", models.ErrReadOnlyFolder)
+		}
+	}
+	return nil
+}
+
+// canWriteReadOnlyFolder returns whether the user may change the dashboards of a read-only folder. Without access
+// control, only org admins may.
+func (hs *HTTPServer) canWriteReadOnlyFolder(c *models.ReqContext, folderID int64) bool {
+	if hs.AccessControl == nil {
+		return accesscontrol.ReqOrgAdmin(c)
+	}
+	scope := fmt.Sprintf("folders:id:%d", folderID)
+	return accesscontrol.HasAccess(hs.AccessControl, c)(accesscontrol.ReqOrgAdmin, accesscontrol.ActionDashboardsReadOnlyWrite, scope)
+}
+
+// savedDashboardFolderIDs returns the folders a save of a dashboard changes: the folder the dashboard is saved in
+// and, when it exists, the folder it's moved out of.
+func savedDashboardFolderIDs(orgID int64, dash *models.Dashboard) ([]int64, error) {
+	folderIDs := []int64{dash.FolderId}
+	if dash.Id == 0 && dash.Uid == "" {
+		return folderIDs, nil
+	}
+
+	query := models.GetDashboardQuery{Id: dash.Id, Uid: dash.Uid, OrgId: orgID}
+	if err := bus.Dispatch(&query); err != nil {
+		if errors.Is(err, models.ErrDashboardNotFound) {
+			return folderIDs, nil
+		}
+		return nil, err
+	}
+	if query.Result.IsFolder {
+		folderIDs = append(folderIDs, query.Result.Id)
+	}
+	return append(folderIDs, query.Result.FolderId), nil
+}
@@ -0,0 +1,184 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	macaron "gopkg.in/macaron.v1"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const readOnlyFolderID int64 = 1
+
+func addReadOnlyFolderHandlers() {
+	bus.AddHandler("test", func(query *models.IsReadOnlyFolderQuery) error {
+		query.Result = query.FolderId == readOnlyFolderID
+		return nil
+	})
+}
+
+func TestSetReadOnlyFolders(t *testing.T) {
+	t.Cleanup(bus.ClearBusHandlers)
+
+	var saved *models.SetReadOnlyFoldersCommand
+	bus.AddHandler("test", func(cmd *models.SetReadOnlyFoldersCommand) error {
+		saved = cmd
+		return nil
+	})
+	bus.AddHandler("test", func(query *models.GetDashboardQuery) error {
+		switch query.Uid {
+		case "finance":
+			query.Result = &models.Dashboard{Id: 1, Uid: "finance", IsFolder: true}
+		case "audit":
+			query.Result = &models.Dashboard{Id: 3, Uid: "audit", IsFolder: true}
+		case "revenue":
+			query.Result = &models.Dashboard{Id: 2, Uid: "revenue", FolderId: 1}
+		default:
+			return models.ErrDashboardNotFound
+		}
+		return nil
+	})
+	c := &models.ReqContext{SignedInUser: &models.SignedInUser{OrgId: testOrgID}}
+
+	t.Run("Should set the read-only folders of the UIDs", func(t *testing.T) {
+		resp := SetReadOnlyFolders(c, dtos.SetReadOnlyFoldersForm{FolderUids: []string{"finance", "audit"}})
+		require.Equal(t, 200, resp.Status())
+		require.Equal(t, &models.SetReadOnlyFoldersCommand{OrgId: testOrgID, FolderIds: []int64{1, 3}}, saved)
+	})
+
+	t.Run("Should clear the read-only folders", func(t *testing.T) {
+		resp := SetReadOnlyFolders(c, dtos.SetReadOnlyFoldersForm{})
+		require.Equal(t, 200, resp.Status())
+		require.Empty(t, saved.FolderIds)
+	})
+
+	t.Run("Should fail for the UIDs of dashboards and unknown folders", func(t *testing.T) {
+		saved = nil
+		for _, uid := range []string{"revenue", "unknown"} {
+			resp := SetReadOnlyFolders(c, dtos.SetReadOnlyFoldersForm{FolderUids: []string{"finance", uid}})
+			assert.Equal(t, 400, resp.Status(), uid)
+		}
+		require.Nil(t, saved)
+	})
+}
+
+func TestCheckReadOnlyFolders(t *testing.T) {
+	t.Cleanup(bus.ClearBusHandlers)
+	addReadOnlyFolderHandlers()
+
+	req, err := http.NewRequest("PUT", "/api/dashboards/db", nil)
+	require.NoError(t, err)
+	reqContext := func(role models.RoleType) *models.ReqContext {
+		c := &models.ReqContext{
+			Context:      &macaron.Context{Req: macaron.Request{Request: req}},
+			SignedInUser: &models.SignedInUser{OrgId: testOrgID},
+		}
+		c.OrgRole = role
+		return c
+	}
+	hs := &HTTPServer{Cfg: setting.NewCfg()}
+
+	t.Run("Should deny editors the read-only folders", func(t *testing.T) {
+		resp := hs.checkReadOnlyFolders(reqContext(models.ROLE_EDITOR), 0, 2, readOnlyFolderID)
+		require.NotNil(t, resp)
+		require.Equal(t, 403, resp.Status())
+
+		assert.Nil(t, hs.checkReadOnlyFolders(reqContext(models.ROLE_EDITOR), 0, 2))
+	})
+
+	t.Run("Should allow admins the read-only folders", func(t *testing.T) {
+		assert.Nil(t, hs.checkReadOnlyFolders(reqContext(models.ROLE_ADMIN), readOnlyFolderID))
+	})
+
+	t.Run("Should allow the read-only folders with the permission", func(t *testing.T) {
+		hs := &HTTPServer{Cfg: setting.NewCfg(), AccessControl: &fakeAccessControl{}}
+		assert.Nil(t, hs.checkReadOnlyFolders(reqContext(models.ROLE_EDITOR), readOnlyFolderID))
+	})
+}
+
+func TestReadOnlyFolderDashboards(t *testing.T) {
+	setUp := func() {
+		addReadOnlyFolderHandlers()
+
+		fakeDash := models.NewDashboard("Child dash")
+		fakeDash.Id = 2
+		fakeDash.FolderId = readOnlyFolderID
+		bus.AddHandler("test", func(query *models.GetDashboardsBySlugQuery) error {
+			query.Result = []*models.Dashboard{fakeDash}
+			return nil
+		})
+		bus.AddHandler("test", func(query *models.GetDashboardQuery) error {
+			query.Result = fakeDash
+			return nil
+		})
+
+		editorRole := models.ROLE_EDITOR
+		bus.AddHandler("test", func(query *models.GetDashboardAclInfoListQuery) error {
+			query.Result = []*models.DashboardAclInfoDTO{{Role: &editorRole, Permission: models.PERMISSION_EDIT}}
+			return nil
+		})
+		bus.AddHandler("test", func(query *models.GetTeamsByUserQuery) error {
+			query.Result = []*models.TeamDTO{}
+			return nil
+		})
+	}
+
+	loggedInUserScenarioWithRole(t, "When calling GET on", "GET", "/api/dashboards/db/child-dash",
+		"/api/dashboards/db/:slug", models.ROLE_EDITOR, func(sc *scenarioContext) {
+			setUp()
+
+			dash := getDashboardShouldReturn200(sc)
+			assert.True(t, dash.Meta.CanEdit)
+			assert.False(t, dash.Meta.CanSave)
+		})
+
+	loggedInUserScenarioWithRole(t, "When calling DELETE on", "DELETE", "/api/dashboards/db/child-dash",
+		"/api/dashboards/db/:slug", models.ROLE_EDITOR, func(sc *scenarioContext) {
+			setUp()
+
+			callDeleteDashboardBySlug(sc, &HTTPServer{
+				Cfg:                   setting.NewCfg(),
+				LibraryPanelService:   &mockLibraryPanelService{},
+				LibraryElementService: &mockLibraryElementService{},
+			})
+			assert.Equal(t, 403, sc.resp.Code)
+		})
+
+	cmd := models.SaveDashboardCommand{
+		OrgId:  testOrgID,
+		UserId: testUserID,
+		Dashboard: simplejson.NewFromAny(map[string]interface{}{
+			"title": "Dash",
+		}),
+		FolderId: readOnlyFolderID,
+	}
+	mock := &dashboards.FakeDashboardService{}
+	postDashboardScenario(t, "When saving in a read-only folder, calling POST on", "/api/dashboards", "/api/dashboards", mock, cmd, func(sc *scenarioContext) {
+		addReadOnlyFolderHandlers()
+
+		callPostDashboard(sc)
+		assert.Equal(t, 403, sc.resp.Code)
+		assert.Empty(t, mock.SavedDashboards)
+	})
+
+	cmd.FolderId = 0
+	cmd.Dashboard = simplejson.NewFromAny(map[string]interface{}{
+		"uid":   "child",
+		"title": "Child dash",
+	})
+	postDashboardScenario(t, "When moving out of a read-only folder, calling POST on", "/api/dashboards", "/api/dashboards", mock, cmd, func(sc *scenarioContext) {
+		setUp()
+
+		callPostDashboard(sc)
+		assert.Equal(t, 403, sc.resp.Code)
+		assert.Empty(t, mock.SavedDashboards)
+	})
+}
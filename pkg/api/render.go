@@ -1,17 +1,36 @@
 package api
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/guardian"
 	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/variables"
 	"github.com/grafana/grafana/pkg/util"
 )
 
+const (
+	// gridCellHeight and gridCellMargin are the height and the vertical margin in pixels of the units of the grid of
+	// the dashboards, like the frontend lays them out.
+	gridCellHeight = 30
+	gridCellMargin = 8
+	// renderDashboardChromeHeight is the height in pixels of the top of the dashboards, above their panels: the
+	// navigation bar and the variables.
+	renderDashboardChromeHeight = 120
+	// maxRenderDashboardHeight bounds the height of the renders of the dashboards, the larger ones are cut.
+	maxRenderDashboardHeight = 32000
+)
+
 func (hs *HTTPServer) RenderToPng(c *models.ReqContext) {
 	queryReader, err := util.NewURLQueryReader(c.Req.URL)
 	if err != nil {
@@ -19,7 +38,18 @@ func (hs *HTTPServer) RenderToPng(c *models.ReqContext) {
 		return
 	}
 
-	queryParams := fmt.Sprintf("?%s", c.Req.URL.RawQuery)
+	rawQuery, status, err := hs.resolveRenderState(c, c.Params("*"), c.Req.URL.RawQuery)
+	if err != nil {
+		c.Handle(hs.Cfg, status, "Render parameters error", err)
+		return
+	}
+	queryParams := fmt.Sprintf("?%s", rawQuery)
+
+	timezone, err := renderTimezone(queryReader)
+	if err != nil {
+		c.Handle(hs.Cfg, 400, "Render parameters error", err)
+		return
+	}
 
 	width, err := strconv.Atoi(queryReader.Get("width", "800"))
 	if err != nil {
@@ -27,8 +57,14 @@ func (hs *HTTPServer) RenderToPng(c *models.ReqContext) {
 		return
 	}
 
-	height, err := strconv.Atoi(queryReader.Get("height", "400"))
-	if err != nil {
+	var height int
+	if queryReader.Get("height", "400") == "auto" {
+		height, status, err = hs.renderDashboardHeight(c, c.Params("*"), rawQuery)
+		if err != nil {
+			c.Handle(hs.Cfg, status, "Render parameters error", err)
+			return
+		}
+	} else if height, err = strconv.Atoi(queryReader.Get("height", "400")); err != nil {
 		c.Handle(hs.Cfg, 400, "Render parameters error", fmt.Errorf("cannot parse height as int: %s", err))
 		return
 	}
@@ -59,7 +95,7 @@ func (hs *HTTPServer) RenderToPng(c *models.ReqContext) {
 		UserID:            c.UserId,
 		OrgRole:           c.OrgRole,
 		Path:              c.Params("*") + queryParams,
-		Timezone:          queryReader.Get("tz", ""),
+		Timezone:          timezone,
 		Encoding:          queryReader.Get("encoding", ""),
 		ConcurrentLimit:   hs.Cfg.RendererConcurrentRequestLimit,
 		DeviceScaleFactor: scale,
@@ -78,3 +114,138 @@ func (hs *HTTPServer) RenderToPng(c *models.ReqContext) {
 	c.Resp.Header().Set("Content-Type", "image/png")
 	http.ServeFile(c.Resp, c.Req.Request, result.FilePath)
 }
+
+// resolveRenderState replaces the variables and annotations parameters of a
+// panel render, the JSON serialized state of the dashboard the user saw, by
+// the URL parameters restoring it, so that the image of a shared panel
+// matches what the user saw. It returns the query as is without them.
+func (hs *HTTPServer) resolveRenderState(c *models.ReqContext, path string, rawQuery string) (string, int, error) {
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "", 400, err
+	}
+	if _, ok := query["variables"]; !ok {
+		if _, ok := query["annotations"]; !ok {
+			return rawQuery, 0, nil
+		}
+	}
+
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 || parts[0] != "d-solo" {
+		return "", 400, errors.New("variables and annotations are only supported when rendering a panel")
+	}
+
+	dash, status, err := getRenderDashboard(c, parts[1])
+	if err != nil {
+		return "", status, err
+	}
+
+	var state variables.DashboardState
+	if value := query.Get("variables"); value != "" {
+		if err := json.Unmarshal([]byte(value), &state.Variables); err != nil {
+			return "", 400, fmt.Errorf("cannot parse variables: %w", err)
+		}
+	}
+	if value := query.Get("annotations"); value != "" {
+		if err := json.Unmarshal([]byte(value), &state.Annotations); err != nil {
+			return "", 400, fmt.Errorf("cannot parse annotations: %w", err)
+		}
+	}
+
+	params, err := hs.VariablesService.ResolveURLParams(dash.Data, state)
+	if err != nil {
+		return "", 400, err
+	}
+
+	query.Del("variables")
+	query.Del("annotations")
+	for key, values := range params {
+		query[key] = values
+	}
+	return query.Encode(), 0, nil
+}
+
+// getRenderDashboard returns the dashboard of a render, if the user can view it.
+func getRenderDashboard(c *models.ReqContext, uid string) (*models.Dashboard, int, error) {
+	dashQuery := models.GetDashboardQuery{Uid: uid, OrgId: c.OrgId}
+	if err := bus.Dispatch(&dashQuery); err != nil {
+		return nil, 404, err
+	}
+
+	g := guardian.New(dashQuery.Result.Id, c.OrgId, c.SignedInUser)
+	if canView, err := g.CanView(); err != nil {
+		return nil, 500, err
+	} else if !canView {
+		return nil, 403, errors.New("access denied to this dashboard")
+	}
+	return dashQuery.Result, 0, nil
+}
+
+// renderDashboardHeight returns the height in pixels of the render of a dashboard showing all its panels, with its
+// repeated rows and panels expanded for the var-<name> URL parameters of the render, or else the current values of
+// the variables.
+func (hs *HTTPServer) renderDashboardHeight(c *models.ReqContext, path string, rawQuery string) (int, int, error) {
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 || parts[0] != "d" {
+		return 0, 400, errors.New("the auto height is only supported when rendering a dashboard")
+	}
+
+	dash, status, err := getRenderDashboard(c, parts[1])
+	if err != nil {
+		return 0, status, err
+	}
+	// the library panels may be repeated
+	if err := hs.LibraryPanelService.LoadLibraryPanelsForDashboard(c, dash); err != nil {
+		return 0, 500, err
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return 0, 400, err
+	}
+	state := variables.DashboardState{Variables: map[string]interface{}{}}
+	for key, values := range query {
+		name := strings.TrimPrefix(key, "var-")
+		if name == key || len(values) == 0 {
+			continue
+		}
+		if len(values) == 1 {
+			state.Variables[name] = values[0]
+			continue
+		}
+		multi := make([]interface{}, len(values))
+		for i, value := range values {
+			multi[i] = value
+		}
+		state.Variables[name] = multi
+	}
+
+	values, err := hs.VariablesService.ResolveValues(dash.Data, state)
+	if err != nil {
+		return 0, 400, err
+	}
+
+	panels := dashboards.ExpandRepeats(dash.Data.Get("panels").MustArray(), values)
+	height := dashboards.GridHeight(panels)*(gridCellHeight+gridCellMargin) + renderDashboardChromeHeight
+	if height > maxRenderDashboardHeight {
+		height = maxRenderDashboardHeight
+	}
+	return height, 0, nil
+}
+
+// renderTimezone returns the timezone of the browser rendering the page, the
+// timezone parameter of the dashboard if set or the tz parameter.
+func renderTimezone(queryReader *util.URLQueryReader) (string, error) {
+	timezone := queryReader.Get("timezone", "")
+	switch timezone {
+	case "", "browser":
+		return queryReader.Get("tz", ""), nil
+	case "utc":
+		return "UTC", nil
+	}
+
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return "", fmt.Errorf("invalid timezone %q", timezone)
+	}
+	return timezone, nil
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/variables"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderTimezone(t *testing.T) {
+	for query, expected := range map[string]string{
+		"":                                 "",
+		"tz=Europe/Paris":                  "Europe/Paris",
+		"timezone=browser&tz=Europe/Paris": "Europe/Paris",
+		"timezone=utc&tz=Europe/Paris":     "UTC",
+		"timezone=America/New_York":        "America/New_York",
+	} {
+		queryReader, err := util.NewURLQueryReader(&url.URL{RawQuery: query})
+		require.NoError(t, err)
+		timezone, err := renderTimezone(queryReader)
+		require.NoError(t, err)
+		assert.Equal(t, expected, timezone, query)
+	}
+
+	queryReader, err := util.NewURLQueryReader(&url.URL{RawQuery: "timezone=Mars/Olympus"})
+	require.NoError(t, err)
+	_, err = renderTimezone(queryReader)
+	require.Error(t, err)
+}
+
+func TestRenderDashboardHeight(t *testing.T) {
+	hs := &HTTPServer{
+		LibraryPanelService: &mockLibraryPanelService{},
+		VariablesService:    &variables.Service{},
+	}
+
+	height := func(t *testing.T, path string, rawQuery string) (int, int) {
+		t.Helper()
+
+		var h, status int
+		loggedInUserScenarioWithRole(t, "When rendering", "GET", "/render/"+path, "/render/*", models.ROLE_VIEWER,
+			func(sc *scenarioContext) {
+				dashboard, err := simplejson.NewJson([]byte(`{
+					"templating": {"list": [{"name": "host", "type": "query", "multi": true, "current": {"value": ["a"]}}]},
+					"panels": [
+						{"id": 1, "repeat": "host", "gridPos": {"x": 0, "y": 0, "w": 24, "h": 8}},
+						{"id": 2, "gridPos": {"x": 0, "y": 8, "w": 24, "h": 2}}
+					]
+				}`))
+				require.NoError(t, err)
+				bus.AddHandler("test", func(query *models.GetDashboardQuery) error {
+					query.Result = models.NewDashboardFromJson(dashboard)
+					return nil
+				})
+				bus.AddHandler("test", func(query *models.GetDashboardAclInfoListQuery) error {
+					viewerRole := models.ROLE_VIEWER
+					query.Result = []*models.DashboardAclInfoDTO{{Role: &viewerRole, Permission: models.PERMISSION_VIEW}}
+					return nil
+				})
+				bus.AddHandler("test", func(query *models.GetTeamsByUserQuery) error {
+					query.Result = []*models.TeamDTO{}
+					return nil
+				})
+
+				sc.handlerFunc = func(c *models.ReqContext) response.Response {
+					var err error
+					h, status, err = hs.renderDashboardHeight(c, c.Params("*"), rawQuery)
+					if err != nil {
+						require.NotZero(t, status)
+					}
+					return response.Empty(200)
+				}
+				sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+			})
+		return h, status
+	}
+
+	h, status := height(t, "d/abcdefghi/hosts", "")
+	require.Zero(t, status)
+	assert.Equal(t, 10*(gridCellHeight+gridCellMargin)+renderDashboardChromeHeight, h)
+
+	// the repeated panel is expanded for the values of the URL parameters
+	h, status = height(t, "d/abcdefghi/hosts", "var-host=a&var-host=b&var-host=c")
+	require.Zero(t, status)
+	assert.Equal(t, 26*(gridCellHeight+gridCellMargin)+renderDashboardChromeHeight, h)
+
+	_, status = height(t, "d-solo/abcdefghi/hosts", "panelId=1")
+	assert.Equal(t, 400, status)
+}
@@ -0,0 +1,35 @@
+package routing
+
+import (
+	"reflect"
+
+	"github.com/go-macaron/binding"
+	"gopkg.in/macaron.v1"
+)
+
+type bodyBinding struct {
+	typ     reflect.Type
+	handler macaron.Handler
+}
+
+type responseDoc struct {
+	status int
+	typ    reflect.Type
+}
+
+// Bind returns the handler binding the request to a new value of the type of
+// obj like binding.Bind, and documents the type as the request of the route.
+// It can only be added to a RouteRegister, not to a macaron router.
+func Bind(obj interface{}, ifacePtr ...interface{}) macaron.Handler {
+	return bodyBinding{
+		typ:     reflect.TypeOf(obj),
+		handler: binding.Bind(obj, ifacePtr...),
+	}
+}
+
+// Returns documents the type of obj as the response body of the route for the
+// status code. It adds no handler to the route, and can only be added to a
+// RouteRegister.
+func Returns(status int, obj interface{}) macaron.Handler {
+	return responseDoc{status: status, typ: reflect.TypeOf(obj)}
+}
@@ -2,6 +2,7 @@ package routing
 
 import (
 	"net/http"
+	"reflect"
 	"strings"
 
 	"gopkg.in/macaron.v1"
@@ -46,6 +47,21 @@ type RouteRegister interface {
 
 	// Reset resets the route register.
 	Reset()
+
+	// Routes returns the routes added to the RouteRegister and its groups,
+	// in the order they are registered.
+	Routes() []Route
+}
+
+// Route describes a route added to a RouteRegister, used to document the API.
+type Route struct {
+	Method  string
+	Pattern string
+	// Body is the type of the request bound with Bind, or nil.
+	Body reflect.Type
+	// Responses are the types of the response bodies set with Returns, by
+	// status code.
+	Responses map[int]reflect.Type
 }
 
 type RegisterNamedMiddleware func(name string) macaron.Handler
@@ -61,9 +77,11 @@ func NewRouteRegister(namedMiddleware ...RegisterNamedMiddleware) RouteRegister
 }
 
 type route struct {
-	method   string
-	pattern  string
-	handlers []macaron.Handler
+	method    string
+	pattern   string
+	handlers  []macaron.Handler
+	body      reflect.Type
+	responses map[int]reflect.Type
 }
 
 type routeRegister struct {
@@ -129,6 +147,23 @@ func (rr *routeRegister) Register(router Router) {
 	}
 }
 
+func (rr *routeRegister) Routes() []Route {
+	var routes []Route
+	for _, r := range rr.routes {
+		routes = append(routes, Route{
+			Method:    r.method,
+			Pattern:   r.pattern,
+			Body:      r.body,
+			Responses: r.responses,
+		})
+	}
+
+	for _, g := range rr.groups {
+		routes = append(routes, g.Routes()...)
+	}
+	return routes
+}
+
 func (rr *routeRegister) route(pattern, method string, handlers ...macaron.Handler) {
 	h := make([]macaron.Handler, 0)
 	fullPattern := rr.prefix + pattern
@@ -137,8 +172,24 @@ func (rr *routeRegister) route(pattern, method string, handlers ...macaron.Handl
 		h = append(h, fn(fullPattern))
 	}
 
-	h = append(h, rr.subfixHandlers...)
-	h = append(h, handlers...)
+	var body reflect.Type
+	var responses map[int]reflect.Type
+
+	// the bindings and response docs are replaced by their handlers, if any
+	for _, handler := range append(append([]macaron.Handler{}, rr.subfixHandlers...), handlers...) {
+		switch doc := handler.(type) {
+		case bodyBinding:
+			body = doc.typ
+			h = append(h, doc.handler)
+		case responseDoc:
+			if responses == nil {
+				responses = map[int]reflect.Type{}
+			}
+			responses[doc.status] = doc.typ
+		default:
+			h = append(h, handler)
+		}
+	}
 
 	for _, r := range rr.routes {
 		if r.pattern == fullPattern && r.method == method {
@@ -147,9 +198,11 @@ func (rr *routeRegister) route(pattern, method string, handlers ...macaron.Handl
 	}
 
 	rr.routes = append(rr.routes, route{
-		method:   method,
-		pattern:  fullPattern,
-		handlers: h,
+		method:    method,
+		pattern:   fullPattern,
+		handlers:  h,
+		body:      body,
+		responses: responses,
 	})
 }
 
@@ -2,6 +2,7 @@ package routing
 
 import (
 	"net/http"
+	"reflect"
 	"strconv"
 	"testing"
 
@@ -265,3 +266,46 @@ func TestNamedMiddlewareRouteRegister(t *testing.T) {
 		}
 	}
 }
+
+func TestRouteRegisterRoutes(t *testing.T) {
+	type command struct{ Name string }
+	type result struct{ ID int64 }
+
+	rr := NewRouteRegister()
+	rr.Get("/api/teams/:id", Returns(http.StatusOK, result{}), emptyHandler("1"))
+	rr.Group("/api/teams", func(teams RouteRegister) {
+		teams.Post("/", Bind(command{}), Returns(http.StatusOK, result{}), Returns(http.StatusConflict, nil), emptyHandler("1"))
+	})
+
+	routes := rr.Routes()
+	if len(routes) != 2 {
+		t.Fatalf("want 2 routes, got %v", len(routes))
+	}
+
+	if routes[0].Pattern != "/api/teams/:id" || routes[0].Body != nil {
+		t.Errorf("want route without body, got %v", routes[0])
+	}
+	if routes[0].Responses[http.StatusOK] != reflect.TypeOf(result{}) {
+		t.Errorf("want response type %v, got %v", reflect.TypeOf(result{}), routes[0].Responses)
+	}
+
+	if routes[1].Method != http.MethodPost || routes[1].Pattern != "/api/teams/" {
+		t.Errorf("want route POST /api/teams/, got %v", routes[1])
+	}
+	if routes[1].Body != reflect.TypeOf(command{}) {
+		t.Errorf("want body type %v, got %v", reflect.TypeOf(command{}), routes[1].Body)
+	}
+	if typ, ok := routes[1].Responses[http.StatusConflict]; !ok || typ != nil {
+		t.Errorf("want response without body for 409, got %v", routes[1].Responses)
+	}
+
+	// the documentation is not added to the handlers of the route
+	fr := &fakeRouter{}
+	rr.Register(fr)
+	if len(fr.route[0].handlers) != 1 || len(fr.route[1].handlers) != 2 {
+		t.Errorf("want 1 and 2 handlers, got %d and %d", len(fr.route[0].handlers), len(fr.route[1].handlers))
+	}
+	if _, ok := fr.route[1].handlers[0].(bodyBinding); ok {
+		t.Errorf("want the binding handler, got %v", fr.route[1].handlers[0])
+	}
+}
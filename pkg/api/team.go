@@ -1,11 +1,13 @@
 package api
 
 import (
+	"encoding/json"
 	"errors"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/fieldmask"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/teamguardian"
@@ -26,12 +28,23 @@ func (hs *HTTPServer) CreateTeam(c *models.ReqContext, cmd models.CreateTeamComm
 		return response.Error(500, "Failed to create Team", err)
 	}
 
+	hs.addTeamCreator(c, team.Id)
+
+	return response.JSON(200, &util.DynMap{
+		"teamId":  team.Id,
+		"message": "Team created",
+	})
+}
+
+// addTeamCreator makes the editor creating a team its admin, if the editors
+// can admin.
+func (hs *HTTPServer) addTeamCreator(c *models.ReqContext, teamID int64) {
 	if c.OrgRole == models.ROLE_EDITOR && hs.Cfg.EditorsCanAdmin {
 		// if the request is authenticated using API tokens
 		// the SignedInUser is an empty struct therefore
 		// an additional check whether it is an actual user is required
 		if c.SignedInUser.IsRealUser() {
-			if err := addTeamMember(hs.SQLStore, c.SignedInUser.UserId, c.OrgId, team.Id, false,
+			if err := addTeamMember(hs.SQLStore, c.SignedInUser.UserId, c.OrgId, teamID, false,
 				models.PERMISSION_ADMIN); err != nil {
 				c.Logger.Error("Could not add creator to team", "error", err)
 			}
@@ -39,11 +52,6 @@ func (hs *HTTPServer) CreateTeam(c *models.ReqContext, cmd models.CreateTeamComm
 			c.Logger.Warn("Could not add creator to team because is not a real user")
 		}
 	}
-
-	return response.JSON(200, &util.DynMap{
-		"teamId":  team.Id,
-		"message": "Team created",
-	})
 }
 
 // PUT /api/teams/:teamId
@@ -65,6 +73,98 @@ func (hs *HTTPServer) UpdateTeam(c *models.ReqContext, cmd models.UpdateTeamComm
 	return response.Success("Team updated")
 }
 
+// teamMaskFields are the fields of a team that a field mask can update.
+var teamMaskFields = []string{"name", "email"}
+
+// PUT /api/teams/uid/:uid
+//
+// UpsertTeamByUID creates the team of the uid if it does not exist, or else
+// updates it, or only the fields of the fieldMask query parameter.
+func (hs *HTTPServer) UpsertTeamByUID(c *models.ReqContext) response.Response {
+	uid := c.Params(":uid")
+	if !util.IsValidShortUID(uid) || len(uid) > 40 {
+		return response.Error(400, "Invalid team uid", nil)
+	}
+
+	mask := fieldmask.Parse(c.Query(fieldmask.QueryParam))
+	if err := mask.Validate(teamMaskFields...); err != nil {
+		return response.Error(400, err.Error(), err)
+	}
+
+	body, err := c.Req.Body().Bytes()
+	if err != nil {
+		return response.Error(400, "Failed to read the request body", err)
+	}
+
+	for attempt := 0; ; attempt++ {
+		query := models.GetTeamByUidQuery{OrgId: c.OrgId, Uid: uid}
+		err := hs.Bus.Dispatch(&query)
+		if errors.Is(err, models.ErrTeamNotFound) {
+			if c.OrgRole == models.ROLE_VIEWER {
+				return response.Error(403, "Not allowed to create team.", nil)
+			}
+
+			var cmd models.CreateTeamCommand
+			if err := json.Unmarshal(body, &cmd); err != nil {
+				return response.Error(400, "Invalid team", err)
+			}
+			if cmd.Name == "" {
+				return response.Error(400, "Team name is required", nil)
+			}
+
+			team, err := createTeamWithUID(hs.SQLStore, uid, cmd.Name, cmd.Email, c.OrgId)
+			if errors.Is(err, models.ErrTeamUidTaken) && attempt < upsertRetries {
+				// created by someone else in the meantime
+				continue
+			}
+			if err != nil {
+				if errors.Is(err, models.ErrTeamNameTaken) {
+					return response.Error(409, "Team name taken", err)
+				}
+				return response.Error(500, "Failed to create Team", err)
+			}
+
+			hs.addTeamCreator(c, team.Id)
+
+			return response.JSON(200, &util.DynMap{
+				"teamId":  team.Id,
+				"uid":     team.Uid,
+				"message": "Team created",
+			})
+		}
+		if err != nil {
+			return response.Error(500, "Failed to get Team", err)
+		}
+
+		if err := teamguardian.CanAdmin(hs.Bus, c.OrgId, query.Result.Id, c.SignedInUser); err != nil {
+			return response.Error(403, "Not allowed to update team", err)
+		}
+
+		var update models.CreateTeamCommand
+		existing := models.CreateTeamCommand{Name: query.Result.Name, Email: query.Result.Email}
+		if err := mask.Merge(existing, json.RawMessage(body), &update); err != nil {
+			return response.Error(400, "Invalid team", err)
+		}
+		if update.Name == "" {
+			return response.Error(400, "Team name is required", nil)
+		}
+
+		cmd := models.UpdateTeamCommand{Id: query.Result.Id, Name: update.Name, Email: update.Email, OrgId: c.OrgId}
+		if err := hs.Bus.Dispatch(&cmd); err != nil {
+			if errors.Is(err, models.ErrTeamNameTaken) {
+				return response.Error(409, "Team name taken", err)
+			}
+			return response.Error(500, "Failed to update Team", err)
+		}
+
+		return response.JSON(200, &util.DynMap{
+			"teamId":  query.Result.Id,
+			"uid":     uid,
+			"message": "Team updated",
+		})
+	}
+}
+
 // DELETE /api/teams/:teamId
 func (hs *HTTPServer) DeleteTeamByID(c *models.ReqContext) response.Response {
 	orgId := c.OrgId
@@ -176,3 +276,10 @@ func (hs *HTTPServer) UpdateTeamPreferences(c *models.ReqContext, dtoCmd dtos.Up
 var createTeam = func(sqlStore *sqlstore.SQLStore, name, email string, orgID int64) (models.Team, error) {
 	return sqlStore.CreateTeam(name, email, orgID)
 }
+
+// createTeamWithUID creates a team with a uid.
+//
+// Stubbable by tests.
+var createTeamWithUID = func(sqlStore *sqlstore.SQLStore, uid, name, email string, orgID int64) (models.Team, error) {
+	return sqlStore.CreateTeamWithUid(uid, name, email, orgID)
+}
@@ -1,8 +1,12 @@
 package api
 
 import (
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/setting"
 	macaron "gopkg.in/macaron.v1"
@@ -160,3 +164,85 @@ func TestTeamAPIEndpoint(t *testing.T) {
 		})
 	})
 }
+
+func TestUpsertTeamByUID(t *testing.T) {
+	hs := &HTTPServer{
+		Cfg: setting.NewCfg(),
+	}
+
+	upsert := func(t *testing.T, role models.RoleType, body string, queryParams string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		// the handlers of each test are on a new bus
+		hs.Bus = bus.GetBus()
+
+		sc := setupScenarioContext(t, "/api/teams/uid/platform")
+		sc.m.Put("/api/teams/uid/:uid", routing.Wrap(func(c *models.ReqContext) response.Response {
+			c.SignedInUser = &models.SignedInUser{OrgId: testOrgID, UserId: testUserID, OrgRole: role}
+			return hs.UpsertTeamByUID(c)
+		}))
+		sc.resp = httptest.NewRecorder()
+		sc.req = httptest.NewRequest("PUT", sc.url+queryParams, strings.NewReader(body))
+		sc.exec()
+		return sc.resp
+	}
+
+	origCreateTeamWithUID := createTeamWithUID
+	t.Cleanup(func() {
+		createTeamWithUID = origCreateTeamWithUID
+	})
+
+	t.Run("creates the team if it does not exist", func(t *testing.T) {
+		t.Cleanup(bus.ClearBusHandlers)
+
+		bus.AddHandler("test", func(query *models.GetTeamByUidQuery) error {
+			return models.ErrTeamNotFound
+		})
+		var created []string
+		createTeamWithUID = func(sqlStore *sqlstore.SQLStore, uid, name, email string, orgID int64) (models.Team, error) {
+			created = append(created, uid+":"+name+":"+email)
+			return models.Team{Id: 42, Uid: uid, Name: name, Email: email, OrgId: orgID}, nil
+		}
+
+		resp := upsert(t, models.ROLE_ADMIN, `{"name":"Platform","email":"platform@example.com"}`, "")
+		require.Equal(t, 200, resp.Code)
+		assert.Equal(t, []string{"platform:Platform:platform@example.com"}, created)
+	})
+
+	t.Run("viewers cannot create the team", func(t *testing.T) {
+		t.Cleanup(bus.ClearBusHandlers)
+
+		bus.AddHandler("test", func(query *models.GetTeamByUidQuery) error {
+			return models.ErrTeamNotFound
+		})
+
+		resp := upsert(t, models.ROLE_VIEWER, `{"name":"Platform"}`, "")
+		assert.Equal(t, 403, resp.Code)
+	})
+
+	t.Run("updates the fields of the field mask", func(t *testing.T) {
+		t.Cleanup(bus.ClearBusHandlers)
+
+		bus.AddHandler("test", func(query *models.GetTeamByUidQuery) error {
+			query.Result = &models.TeamDTO{Id: 42, Uid: query.Uid, Name: "Platform", Email: "platform@example.com"}
+			return nil
+		})
+		var updated *models.UpdateTeamCommand
+		bus.AddHandler("test", func(cmd *models.UpdateTeamCommand) error {
+			updated = cmd
+			return nil
+		})
+
+		resp := upsert(t, models.ROLE_ADMIN, `{"name":"Platform team","email":"ignored@example.com"}`, "?fieldMask=name")
+		require.Equal(t, 200, resp.Code)
+		require.NotNil(t, updated)
+		assert.Equal(t, int64(42), updated.Id)
+		assert.Equal(t, "Platform team", updated.Name)
+		assert.Equal(t, "platform@example.com", updated.Email)
+	})
+
+	t.Run("rejects the fields that cannot be updated", func(t *testing.T) {
+		resp := upsert(t, models.ROLE_ADMIN, `{"id":1}`, "?fieldMask=id")
+		assert.Equal(t, 400, resp.Code)
+	})
+}
@@ -22,15 +22,24 @@ func GetUserByID(c *models.ReqContext) response.Response {
 }
 
 func getUserUserProfile(userID int64) response.Response {
-	query := models.GetUserProfileQuery{UserId: userID}
-
-	if err := bus.Dispatch(&query); err != nil {
+	profile, err := getUserProfile(userID)
+	if err != nil {
 		if errors.Is(err, models.ErrUserNotFound) {
 			return response.Error(404, models.ErrUserNotFound.Error(), nil)
 		}
 		return response.Error(500, "Failed to get user", err)
 	}
 
+	return response.JSON(200, profile)
+}
+
+func getUserProfile(userID int64) (*models.UserProfileDTO, error) {
+	query := models.GetUserProfileQuery{UserId: userID}
+
+	if err := bus.Dispatch(&query); err != nil {
+		return nil, err
+	}
+
 	getAuthQuery := models.GetAuthInfoQuery{UserId: userID}
 	query.Result.AuthLabels = []string{}
 	if err := bus.Dispatch(&getAuthQuery); err == nil {
@@ -41,7 +50,7 @@ func getUserUserProfile(userID int64) response.Response {
 
 	query.Result.AvatarUrl = dtos.GetGravatarUrl(query.Result.Email)
 
-	return response.JSON(200, query.Result)
+	return &query.Result, nil
 }
 
 // GET /api/users/lookup
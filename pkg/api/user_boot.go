@@ -0,0 +1,112 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// GetSignedInUserBootData returns what the frontend loads for the signed in
+// user on every page load in a single payload: the profile, the teams, the
+// preferences, the fine-grained permissions and the starred dashboards.
+// The payload has an ETag, and the response is not modified when the
+// If-None-Match header matches it.
+// GET /api/user/boot
+func (hs *HTTPServer) GetSignedInUserBootData(c *models.ReqContext) response.Response {
+	profile, err := getUserProfile(c.UserId)
+	if err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			return response.Error(404, models.ErrUserNotFound.Error(), nil)
+		}
+		return response.Error(500, "Failed to get user", err)
+	}
+
+	teamsQuery := models.GetTeamsByUserQuery{OrgId: c.OrgId, UserId: c.UserId}
+	if err := bus.Dispatch(&teamsQuery); err != nil {
+		return response.Error(500, "Failed to get user teams", err)
+	}
+	for _, team := range teamsQuery.Result {
+		team.AvatarUrl = dtos.GetGravatarUrlWithDefault(team.Email, team.Name)
+	}
+
+	prefsQuery := models.GetPreferencesQuery{UserId: c.UserId, OrgId: c.OrgId}
+	if err := bus.Dispatch(&prefsQuery); err != nil {
+		return response.Error(500, "Failed to get preferences", err)
+	}
+
+	starsQuery := models.GetUserStarsQuery{UserId: c.UserId}
+	if err := bus.Dispatch(&starsQuery); err != nil {
+		return response.Error(500, "Failed to get user stars", err)
+	}
+	starred := make([]int64, 0, len(starsQuery.Result))
+	for dashboardID := range starsQuery.Result {
+		starred = append(starred, dashboardID)
+	}
+	sort.Slice(starred, func(i, j int) bool { return starred[i] < starred[j] })
+
+	permissions := map[string][]string{}
+	if hs.Cfg.FeatureToggles["accesscontrol"] {
+		userPermissions, err := hs.AccessControl.GetUserPermissions(c.Req.Context(), c.SignedInUser)
+		if err != nil {
+			return response.Error(500, "Failed to get user permissions", err)
+		}
+		for _, p := range userPermissions {
+			permissions[p.Action] = append(permissions[p.Action], p.Scope)
+		}
+		for action := range permissions {
+			sort.Strings(permissions[action])
+		}
+	}
+
+	teams := teamsQuery.Result
+	if teams == nil {
+		teams = []*models.TeamDTO{}
+	}
+
+	body, err := json.Marshal(dtos.UserBootData{
+		User:  profile,
+		Teams: teams,
+		Preferences: dtos.Prefs{
+			Theme:                prefsQuery.Result.Theme,
+			HomeDashboardID:      prefsQuery.Result.HomeDashboardId,
+			Timezone:             prefsQuery.Result.Timezone,
+			WeekStart:            prefsQuery.Result.WeekStart,
+			FiscalYearStartMonth: prefsQuery.Result.FiscalYearStartMonth,
+		},
+		Permissions:       permissions,
+		StarredDashboards: starred,
+	})
+	if err != nil {
+		return response.Error(500, "Failed to encode user boot data", err)
+	}
+
+	etag := fmt.Sprintf(`"%x"`, sha256.Sum256(body))
+	if etagMatches(c.Req.Header.Get("If-None-Match"), etag) {
+		return response.Respond(304, []byte{}).SetHeader("ETag", etag).SetHeader("Cache-Control", "private, no-cache")
+	}
+
+	return response.Respond(200, body).
+		SetHeader("Content-Type", "application/json").
+		SetHeader("ETag", etag).
+		SetHeader("Cache-Control", "private, no-cache")
+}
+
+// etagMatches returns whether the If-None-Match header matches the ETag, it
+// can list several ETags and mark them as weak.
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
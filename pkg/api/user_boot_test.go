@@ -0,0 +1,120 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAccessControl struct {
+	permissions []*accesscontrol.Permission
+}
+
+func (f *fakeAccessControl) Evaluate(ctx context.Context, user *models.SignedInUser, permission string, scope ...string) (bool, error) {
+	return true, nil
+}
+
+func (f *fakeAccessControl) GetUserPermissions(ctx context.Context, user *models.SignedInUser) ([]*accesscontrol.Permission, error) {
+	return f.permissions, nil
+}
+
+func (f *fakeAccessControl) IsDisabled() bool {
+	return false
+}
+
+func TestGetSignedInUserBootData(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.FeatureToggles = map[string]bool{"accesscontrol": true}
+	hs := &HTTPServer{
+		Cfg: cfg,
+		AccessControl: &fakeAccessControl{permissions: []*accesscontrol.Permission{
+			{Action: "users:read", Scope: "users:*"},
+			{Action: "datasources:read", Scope: "datasources:id:2"},
+			{Action: "datasources:read", Scope: "datasources:id:1"},
+		}},
+	}
+
+	addHandlers := func() {
+		bus.AddHandler("test", func(query *models.GetUserProfileQuery) error {
+			query.Result = models.UserProfileDTO{Id: query.UserId, Login: "testUser"}
+			return nil
+		})
+		bus.AddHandler("test", func(query *models.GetAuthInfoQuery) error {
+			return models.ErrUserNotFound
+		})
+		bus.AddHandler("test", func(query *models.GetTeamsByUserQuery) error {
+			query.Result = []*models.TeamDTO{{Id: 3, Name: "SRE"}}
+			return nil
+		})
+		bus.AddHandler("test", func(query *models.GetPreferencesQuery) error {
+			query.Result = &models.Preferences{Theme: "dark", HomeDashboardId: 4}
+			return nil
+		})
+		bus.AddHandler("test", func(query *models.GetUserStarsQuery) error {
+			query.Result = map[int64]bool{7: true, 5: true}
+			return nil
+		})
+	}
+
+	var etag string
+	loggedInUserScenario(t, "When calling GET on", "/api/user/boot", func(sc *scenarioContext) {
+		addHandlers()
+		sc.handlerFunc = hs.GetSignedInUserBootData
+		sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+		require.Equal(t, 200, sc.resp.Code)
+
+		etag = sc.resp.Header().Get("ETag")
+		require.NotEmpty(t, etag)
+
+		var result struct {
+			User struct {
+				Login string `json:"login"`
+			} `json:"user"`
+			Teams []struct {
+				Name string `json:"name"`
+			} `json:"teams"`
+			Preferences struct {
+				Theme string `json:"theme"`
+			} `json:"preferences"`
+			Permissions       map[string][]string `json:"permissions"`
+			StarredDashboards []int64             `json:"starredDashboards"`
+		}
+		require.NoError(t, json.NewDecoder(sc.resp.Body).Decode(&result))
+		assert.Equal(t, "testUser", result.User.Login)
+		require.Len(t, result.Teams, 1)
+		assert.Equal(t, "SRE", result.Teams[0].Name)
+		assert.Equal(t, "dark", result.Preferences.Theme)
+		assert.Equal(t, map[string][]string{
+			"users:read":       {"users:*"},
+			"datasources:read": {"datasources:id:1", "datasources:id:2"},
+		}, result.Permissions)
+		assert.Equal(t, []int64{5, 7}, result.StarredDashboards)
+	})
+
+	loggedInUserScenario(t, "When calling GET with a matching ETag on", "/api/user/boot", func(sc *scenarioContext) {
+		addHandlers()
+		sc.handlerFunc = hs.GetSignedInUserBootData
+		sc.fakeReqWithParams("GET", sc.url, map[string]string{})
+		sc.req.Header.Set("If-None-Match", `"stale", W/`+etag)
+		sc.exec()
+		require.Equal(t, 304, sc.resp.Code)
+		assert.Equal(t, etag, sc.resp.Header().Get("ETag"))
+		assert.Empty(t, sc.resp.Body.Bytes())
+	})
+
+	loggedInUserScenario(t, "When calling GET with a stale ETag on", "/api/user/boot", func(sc *scenarioContext) {
+		addHandlers()
+		sc.handlerFunc = hs.GetSignedInUserBootData
+		sc.fakeReqWithParams("GET", sc.url, map[string]string{})
+		sc.req.Header.Set("If-None-Match", `"stale"`)
+		sc.exec()
+		require.Equal(t, 200, sc.resp.Code)
+	})
+}
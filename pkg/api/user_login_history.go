@@ -0,0 +1,71 @@
+package api
+
+import (
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/ua-parser/uap-go/uaparser"
+)
+
+const (
+	defaultLoginHistoryLimit = 50
+	maxLoginHistoryLimit     = 500
+)
+
+// GET /api/user/login-history
+func GetUserLoginHistory(c *models.ReqContext) response.Response {
+	return getUserLoginHistoryInternal(c, c.UserId)
+}
+
+// GET /api/admin/users/:id/login-history
+func AdminGetUserLoginHistory(c *models.ReqContext) response.Response {
+	return getUserLoginHistoryInternal(c, c.ParamsInt64(":id"))
+}
+
+func getUserLoginHistoryInternal(c *models.ReqContext, userID int64) response.Response {
+	userQuery := models.GetUserByIdQuery{Id: userID}
+	if err := bus.Dispatch(&userQuery); err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			return response.Error(404, "User not found", err)
+		}
+		return response.Error(500, "Failed to get user", err)
+	}
+
+	limit := c.QueryInt("limit")
+	if limit <= 0 {
+		limit = defaultLoginHistoryLimit
+	}
+	if limit > maxLoginHistoryLimit {
+		limit = maxLoginHistoryLimit
+	}
+
+	query := models.GetUserLoginHistoryQuery{UserId: userID, Limit: limit}
+	if err := bus.Dispatch(&query); err != nil {
+		return response.Error(500, "Failed to get user login history", err)
+	}
+
+	parser := uaparser.NewFromSaved()
+	result := make([]*dtos.LoginHistoryEntry, 0, len(query.Result))
+	for _, entry := range query.Result {
+		client := parseUserAgent(parser, entry.UserAgent)
+		result = append(result, &dtos.LoginHistoryEntry{
+			Id:                     entry.Id,
+			Success:                entry.Success,
+			AuthModule:             entry.AuthModule,
+			ClientIp:               entry.IpAddress,
+			Location:               entry.Location,
+			Device:                 client.device,
+			OperatingSystem:        client.os,
+			OperatingSystemVersion: client.osVersion,
+			Browser:                client.browser,
+			BrowserVersion:         client.browserVersion,
+			CreatedAt:              time.Unix(entry.Created, 0),
+		})
+	}
+
+	return response.JSON(200, result)
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func TestUserLoginHistory(t *testing.T) {
+	loggedInUserScenario(t, "When getting the login history of the signed in user", "/api/user/login-history", func(sc *scenarioContext) {
+		bus.AddHandler("test", func(query *models.GetUserByIdQuery) error {
+			query.Result = &models.User{Id: query.Id}
+			return nil
+		})
+		var historyQuery *models.GetUserLoginHistoryQuery
+		bus.AddHandler("test", func(query *models.GetUserLoginHistoryQuery) error {
+			historyQuery = query
+			query.Result = []*models.LoginHistoryEntry{{
+				Id:        1,
+				UserId:    query.UserId,
+				Success:   true,
+				IpAddress: "192.168.1.10",
+				UserAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.77 Safari/537.36",
+				Location:  "192.168.1.0/24",
+				Created:   time.Date(2021, 6, 1, 8, 0, 0, 0, time.UTC).Unix(),
+			}}
+			return nil
+		})
+
+		sc.handlerFunc = GetUserLoginHistory
+		sc.fakeReqWithParams("GET", sc.url, map[string]string{"limit": "5000"}).exec()
+		require.Equal(t, 200, sc.resp.Code)
+		assert.Equal(t, testUserID, historyQuery.UserId)
+		assert.Equal(t, maxLoginHistoryLimit, historyQuery.Limit)
+
+		var result []dtos.LoginHistoryEntry
+		require.NoError(t, json.Unmarshal(sc.resp.Body.Bytes(), &result))
+		require.Len(t, result, 1)
+		assert.True(t, result[0].Success)
+		assert.Equal(t, "192.168.1.0/24", result[0].Location)
+		assert.Equal(t, "Chrome", result[0].Browser)
+		assert.Equal(t, "Mac OS X", result[0].OperatingSystem)
+	})
+}
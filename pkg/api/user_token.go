@@ -65,26 +65,7 @@ func (hs *HTTPServer) getUserAuthTokensInternal(c *models.ReqContext, userID int
 			isActive = true
 		}
 
-		parser := uaparser.NewFromSaved()
-		client := parser.Parse(token.UserAgent)
-
-		osVersion := ""
-		if client.Os.Major != "" {
-			osVersion = client.Os.Major
-
-			if client.Os.Minor != "" {
-				osVersion = osVersion + "." + client.Os.Minor
-			}
-		}
-
-		browserVersion := ""
-		if client.UserAgent.Major != "" {
-			browserVersion = client.UserAgent.Major
-
-			if client.UserAgent.Minor != "" {
-				browserVersion = browserVersion + "." + client.UserAgent.Minor
-			}
-		}
+		client := parseUserAgent(uaparser.NewFromSaved(), token.UserAgent)
 
 		createdAt := time.Unix(token.CreatedAt, 0)
 		seenAt := time.Unix(token.SeenAt, 0)
@@ -97,11 +78,11 @@ func (hs *HTTPServer) getUserAuthTokensInternal(c *models.ReqContext, userID int
 			Id:                     token.Id,
 			IsActive:               isActive,
 			ClientIp:               token.ClientIp,
-			Device:                 client.Device.ToString(),
-			OperatingSystem:        client.Os.Family,
-			OperatingSystemVersion: osVersion,
-			Browser:                client.UserAgent.Family,
-			BrowserVersion:         browserVersion,
+			Device:                 client.device,
+			OperatingSystem:        client.os,
+			OperatingSystemVersion: client.osVersion,
+			Browser:                client.browser,
+			BrowserVersion:         client.browserVersion,
 			CreatedAt:              createdAt,
 			SeenAt:                 seenAt,
 		})
@@ -110,6 +91,44 @@ func (hs *HTTPServer) getUserAuthTokensInternal(c *models.ReqContext, userID int
 	return response.JSON(200, result)
 }
 
+type userAgentInfo struct {
+	device         string
+	os             string
+	osVersion      string
+	browser        string
+	browserVersion string
+}
+
+func parseUserAgent(parser *uaparser.Parser, userAgent string) userAgentInfo {
+	client := parser.Parse(userAgent)
+
+	osVersion := ""
+	if client.Os.Major != "" {
+		osVersion = client.Os.Major
+
+		if client.Os.Minor != "" {
+			osVersion = osVersion + "." + client.Os.Minor
+		}
+	}
+
+	browserVersion := ""
+	if client.UserAgent.Major != "" {
+		browserVersion = client.UserAgent.Major
+
+		if client.UserAgent.Minor != "" {
+			browserVersion = browserVersion + "." + client.UserAgent.Minor
+		}
+	}
+
+	return userAgentInfo{
+		device:         client.Device.ToString(),
+		os:             client.Os.Family,
+		osVersion:      osVersion,
+		browser:        client.UserAgent.Family,
+		browserVersion: browserVersion,
+	}
+}
+
 func (hs *HTTPServer) revokeUserAuthTokenInternal(c *models.ReqContext, userID int64, cmd models.RevokeAuthTokenCmd) response.Response {
 	userQuery := models.GetUserByIdQuery{Id: userID}
 
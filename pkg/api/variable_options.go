@@ -0,0 +1,159 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/oauthtoken"
+	"github.com/grafana/grafana/pkg/services/querycost"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
+)
+
+// QueryVariableOptions handles POST /api/variables/options. It runs the query of a query variable and returns its
+// options, cached by data source, query and time range when the cache of the variable options is enabled.
+func (hs *HTTPServer) QueryVariableOptions(c *models.ReqContext, cmd dtos.VariableOptionsRequest) response.Response {
+	if cmd.DatasourceUID == "" || cmd.Query == nil {
+		return response.Error(http.StatusBadRequest, "Data source UID and query are required", nil)
+	}
+
+	ds, err := hs.DatasourceCache.GetDatasourceByUID(cmd.DatasourceUID, c.SignedInUser, c.SkipCache)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrDataSourceNotFound):
+			return response.Error(http.StatusNotFound, "Data source not found", nil)
+		case errors.Is(err, models.ErrDataSourceAccessDenied):
+			return response.Error(http.StatusForbidden, "Access denied to data source", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to query data source", err)
+	}
+	if err := hs.PluginRequestValidator.Validate(ds.Url, nil); err != nil {
+		return response.Error(http.StatusForbidden, "Access denied", err)
+	}
+	if resp := hs.checkExplorePermissions(c, cmd.DashboardID, 0, ds); resp != nil {
+		return resp
+	}
+
+	timeRange := hs.newTimeRange(c, cmd.From, cmd.To)
+	resolve := func() ([]dtos.VariableOption, error) {
+		return hs.resolveVariableOptions(c, ds, cmd, timeRange)
+	}
+
+	var options []dtos.VariableOption
+	cached := false
+	// the options of the data sources forwarding the identity of the users, or connecting with it, are theirs only
+	if hs.variableOptionsCache == nil || oauthtoken.IsOAuthPassThruEnabled(ds) || sqleng.IAMAuth(ds) != "" {
+		options, err = resolve()
+	} else {
+		key, keyErr := hs.variableOptionsCache.key(ds, cmd.Query, timeRange)
+		if keyErr != nil {
+			return response.Error(http.StatusBadRequest, "Invalid query", keyErr)
+		}
+		options, cached, err = hs.variableOptionsCache.get(c.OrgId, key, c.SkipCache, resolve)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, tsdb.ErrMaxDataAgeExceeded):
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		case errors.Is(err, tsdb.ErrQueryPolicyViolation):
+			return response.Error(http.StatusForbidden, err.Error(), err)
+		case errors.Is(err, tsdb.ErrQueryBudgetExceeded):
+			return response.Error(http.StatusServiceUnavailable, err.Error(), err)
+		case errors.Is(err, tsdb.ErrRecordingNotFound):
+			return response.Error(http.StatusNotFound, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Variable query failed", err)
+	}
+
+	return response.JSON(http.StatusOK, dtos.VariableOptionsResponse{Options: options, Cached: cached})
+}
+
+// resolveVariableOptions runs the query of a variable and returns the options of its results.
+func (hs *HTTPServer) resolveVariableOptions(c *models.ReqContext, ds *models.DataSource, cmd dtos.VariableOptionsRequest,
+	timeRange plugins.DataTimeRange) ([]dtos.VariableOption, error) {
+	reqDTO := dtos.MetricRequest{From: cmd.From, To: cmd.To, Queries: []*simplejson.Json{cmd.Query}}
+	request := newDataQuery(c, reqDTO, timeRange)
+	request.Queries = append(request.Queries, newDataSubQuery(cmd.Query, ds))
+
+	start := time.Now()
+	resp, err := hs.DataService.HandleRequest(auditQueries(c, reqDTO, ds, &request), ds, request)
+	duration := time.Since(start)
+	if err != nil {
+		hs.QueryCosts.Record(ds, 0, querycost.Cost{Queries: 1, Errors: 1, Duration: duration})
+		return nil, err
+	}
+	qdr, err := resp.ToBackendDataResponse()
+	if err != nil {
+		return nil, err
+	}
+	hs.QueryCosts.Record(ds, 0, querycost.ResponseCost(qdr, duration))
+
+	var frames data.Frames
+	for _, res := range qdr.Responses {
+		if res.Error != nil {
+			return nil, res.Error
+		}
+		frames = append(frames, res.Frames...)
+	}
+	return framesToVariableOptions(frames), nil
+}
+
+// framesToVariableOptions returns the options of the rows of the frames. The texts and values of the options are the
+// fields named text or __text and value or __value, or else the first field of the frames. The duplicate options are
+// removed.
+func framesToVariableOptions(frames data.Frames) []dtos.VariableOption {
+	options := []dtos.VariableOption{}
+	seen := map[dtos.VariableOption]bool{}
+	for _, frame := range frames {
+		if len(frame.Fields) == 0 {
+			continue
+		}
+
+		var text, value *data.Field
+		for _, field := range frame.Fields {
+			switch field.Name {
+			case "text", "__text":
+				text = field
+			case "value", "__value":
+				value = field
+			}
+		}
+		if text == nil && value == nil {
+			text, value = frame.Fields[0], frame.Fields[0]
+		} else if text == nil {
+			text = value
+		} else if value == nil {
+			value = text
+		}
+
+		for i := 0; i < text.Len() && i < value.Len(); i++ {
+			option := dtos.VariableOption{Text: fieldValueString(text, i), Value: fieldValueString(value, i)}
+			if seen[option] {
+				continue
+			}
+			seen[option] = true
+			options = append(options, option)
+		}
+	}
+	return options
+}
+
+func fieldValueString(field *data.Field, i int) string {
+	v, ok := field.ConcreteAt(i)
+	if !ok {
+		return ""
+	}
+	if t, ok := v.(time.Time); ok {
+		return t.UTC().Format(time.RFC3339)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// variableOptionsKey identifies the options of a variable query. The time range is rounded down to the time buckets,
+// so that the requests of relative time ranges share the options.
+type variableOptionsKey struct {
+	datasourceID int64
+	// query is the hash of the canonical JSON of the query
+	query    string
+	from, to int64
+}
+
+type variableOptionsEntry struct {
+	orgID        int64
+	datasourceID int64
+	// options are shared by the requests once cached, so they must not be modified
+	options []dtos.VariableOption
+	expires time.Time
+}
+
+// variableOptionsCache caches the options of the variable queries until they expire, their data source changes, or
+// they're invalidated with the caches API.
+type variableOptionsCache struct {
+	settings setting.VariableOptionsCacheSettings
+
+	mu sync.Mutex
+	// generation is incremented by invalidations, so that the options resolved before them aren't cached
+	generation int64
+	entries    map[variableOptionsKey]*variableOptionsEntry
+	now        func() time.Time
+}
+
+// newVariableOptionsCache returns the cache of the variable options, nil when it's disabled.
+func newVariableOptionsCache(settings setting.VariableOptionsCacheSettings) *variableOptionsCache {
+	if !settings.Enabled {
+		return nil
+	}
+	return &variableOptionsCache{
+		settings: settings,
+		entries:  map[variableOptionsKey]*variableOptionsEntry{},
+		now:      time.Now,
+	}
+}
+
+// key returns the key of the options of the query of the data source in the time range.
+func (c *variableOptionsCache) key(ds *models.DataSource, query *simplejson.Json, timeRange plugins.DataTimeRange) (variableOptionsKey, error) {
+	encoded, err := query.ToDB()
+	if err != nil {
+		return variableOptionsKey{}, err
+	}
+	hash := sha256.Sum256(encoded)
+	bucket := c.settings.TimeBucket.Milliseconds()
+	return variableOptionsKey{
+		datasourceID: ds.Id,
+		query:        hex.EncodeToString(hash[:]),
+		from:         timeRange.GetFromAsMsEpoch() / bucket,
+		to:           timeRange.GetToAsMsEpoch() / bucket,
+	}, nil
+}
+
+// get returns the cached options of the key, or resolves and caches them, and whether they were cached. The cached
+// options are resolved again when refresh is set, and the options are resolved every time without a cache.
+func (c *variableOptionsCache) get(orgID int64, key variableOptionsKey, refresh bool,
+	resolve func() ([]dtos.VariableOption, error)) ([]dtos.VariableOption, bool, error) {
+	if c == nil {
+		options, err := resolve()
+		return options, false, err
+	}
+
+	c.mu.Lock()
+	if entry, ok := c.entries[key]; ok && !refresh && c.now().Before(entry.expires) {
+		c.mu.Unlock()
+		return entry.options, true, nil
+	}
+	generation := c.generation
+	c.mu.Unlock()
+
+	options, err := resolve()
+	if err != nil {
+		return nil, false, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		return options, false, nil
+	}
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.settings.MaxEntries {
+		c.removeExpired()
+		if len(c.entries) >= c.settings.MaxEntries {
+			return options, false, nil
+		}
+	}
+	c.entries[key] = &variableOptionsEntry{
+		orgID:        orgID,
+		datasourceID: key.datasourceID,
+		options:      options,
+		expires:      c.now().Add(c.settings.TTL),
+	}
+	return options, false, nil
+}
+
+func (c *variableOptionsCache) removeExpired() {
+	now := c.now()
+	for key, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// invalidateDataSource removes the cached options of the data source.
+func (c *variableOptionsCache) invalidateDataSource(datasourceID int64) {
+	c.invalidate(func(entry *variableOptionsEntry) bool { return entry.datasourceID == datasourceID })
+}
+
+// invalidateOrg removes the cached options of the data sources of the org.
+func (c *variableOptionsCache) invalidateOrg(orgID int64) {
+	c.invalidate(func(entry *variableOptionsEntry) bool { return entry.orgID == orgID })
+}
+
+func (c *variableOptionsCache) invalidate(match func(entry *variableOptionsEntry) bool) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	for key, entry := range c.entries {
+		if match(entry) {
+			delete(c.entries, key)
+		}
+	}
+}
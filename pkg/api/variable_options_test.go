@@ -0,0 +1,181 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/manager"
+	"github.com/grafana/grafana/pkg/services/querycost"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb"
+)
+
+func TestQueryVariableOptions(t *testing.T) {
+	prometheus := &fakeChainedDataPlugin{frames: func(q plugins.DataSubQuery) data.Frames {
+		return data.Frames{data.NewFrame("", data.NewField("host", nil, []string{"web-1", "web-2", "web-1"}))}
+	}}
+
+	dataService := tsdb.NewService()
+	dataService.PluginManager = &manager.PluginManager{BackendPluginManager: fakeBackendPM{}}
+	// nolint: staticcheck // plugins.DataPlugin deprecated
+	dataService.RegisterQueryHandler("prometheus", func(*models.DataSource) (plugins.DataPlugin, error) {
+		return prometheus, nil
+	})
+
+	cfg := setting.NewCfg()
+	cfg.VariableOptionsCache = setting.VariableOptionsCacheSettings{
+		Enabled: true, TTL: time.Minute, TimeBucket: time.Minute, MaxEntries: 10,
+	}
+	ds := &models.DataSource{Id: 1, Uid: "prom", OrgId: testOrgID, Type: "prometheus"}
+	hs := &HTTPServer{
+		Cfg:                    cfg,
+		DatasourceCache:        &fakeDatasourceCache{datasource: ds},
+		DataService:            &dataService,
+		PluginRequestValidator: fakePluginRequestValidator{},
+		QueryCosts:             &querycost.QueryCostService{Cfg: cfg},
+		frontendSettingsCache:  newFrontendSettingsCache(),
+		variableOptionsCache:   newVariableOptionsCache(cfg.VariableOptionsCache),
+		log:                    log.New("test"),
+	}
+
+	query := func(t *testing.T, cmd dtos.VariableOptionsRequest) (int, dtos.VariableOptionsResponse) {
+		t.Helper()
+
+		var code int
+		var resp dtos.VariableOptionsResponse
+		loggedInUserScenarioWithRole(t, "When calling POST on", "GET", "/api/variables/options", "/api/variables/options",
+			models.ROLE_VIEWER, func(sc *scenarioContext) {
+				addExploreAllowedHandler()
+				sc.handlerFunc = func(c *models.ReqContext) response.Response {
+					return hs.QueryVariableOptions(c, cmd)
+				}
+				sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+				code = sc.resp.Code
+				if code == 200 {
+					require.NoError(t, json.NewDecoder(sc.resp.Body).Decode(&resp))
+				}
+			})
+		return code, resp
+	}
+
+	hosts := dtos.VariableOptionsRequest{
+		DatasourceUID: "prom",
+		Query:         simplejson.NewFromAny(map[string]interface{}{"refId": "A", "expr": "label_values(up, host)"}),
+		From:          "now-1h",
+		To:            "now",
+	}
+
+	t.Run("Should cache the options of the queries", func(t *testing.T) {
+		prometheus.queries = nil
+		code, resp := query(t, hosts)
+		require.Equal(t, 200, code)
+		assert.False(t, resp.Cached)
+		assert.Equal(t, []dtos.VariableOption{{Text: "web-1", Value: "web-1"}, {Text: "web-2", Value: "web-2"}}, resp.Options)
+
+		code, resp = query(t, hosts)
+		require.Equal(t, 200, code)
+		assert.True(t, resp.Cached)
+		assert.Len(t, resp.Options, 2)
+		assert.Len(t, prometheus.queries, 1)
+	})
+
+	t.Run("Should run the queries again once their data source changed", func(t *testing.T) {
+		prometheus.queries = nil
+		require.NoError(t, hs.handleDataSourceChanged(&models.DataSourceChangedEvent{OrgId: testOrgID, Id: 1}))
+
+		code, resp := query(t, hosts)
+		require.Equal(t, 200, code)
+		assert.False(t, resp.Cached)
+		assert.Len(t, prometheus.queries, 1)
+	})
+
+	t.Run("Should run the queries again once the options are invalidated", func(t *testing.T) {
+		prometheus.queries = nil
+		c := &models.ReqContext{SignedInUser: &models.SignedInUser{OrgId: testOrgID, OrgRole: models.ROLE_ADMIN}}
+		require.Equal(t, 200, hs.InvalidateCaches(c, dtos.InvalidateCachesCommand{VariableOptions: true}).Status())
+
+		code, resp := query(t, hosts)
+		require.Equal(t, 200, code)
+		assert.False(t, resp.Cached)
+		assert.Len(t, prometheus.queries, 1)
+	})
+
+	t.Run("Should return 404 for a missing data source", func(t *testing.T) {
+		code, _ := query(t, dtos.VariableOptionsRequest{DatasourceUID: "missing", Query: hosts.Query})
+		assert.Equal(t, 404, code)
+	})
+
+	t.Run("Should require a query", func(t *testing.T) {
+		code, _ := query(t, dtos.VariableOptionsRequest{DatasourceUID: "prom"})
+		assert.Equal(t, 400, code)
+	})
+}
+
+func TestVariableOptionsCache(t *testing.T) {
+	now := time.Now()
+	cache := newVariableOptionsCache(setting.VariableOptionsCacheSettings{
+		Enabled: true, TTL: time.Minute, TimeBucket: time.Minute, MaxEntries: 1,
+	})
+	cache.now = func() time.Time { return now }
+
+	resolves := 0
+	resolve := func() ([]dtos.VariableOption, error) {
+		resolves++
+		return []dtos.VariableOption{{Text: "a", Value: "a"}}, nil
+	}
+	first := variableOptionsKey{datasourceID: 1, query: "a"}
+	second := variableOptionsKey{datasourceID: 1, query: "b"}
+
+	_, cached, err := cache.get(testOrgID, first, false, resolve)
+	require.NoError(t, err)
+	assert.False(t, cached)
+	_, cached, err = cache.get(testOrgID, first, false, resolve)
+	require.NoError(t, err)
+	assert.True(t, cached)
+
+	// the cache is full until the cached options expire
+	_, _, err = cache.get(testOrgID, second, false, resolve)
+	require.NoError(t, err)
+	_, cached, err = cache.get(testOrgID, second, false, resolve)
+	require.NoError(t, err)
+	assert.False(t, cached)
+
+	now = now.Add(time.Minute)
+	_, _, err = cache.get(testOrgID, second, false, resolve)
+	require.NoError(t, err)
+	_, cached, err = cache.get(testOrgID, second, false, resolve)
+	require.NoError(t, err)
+	assert.True(t, cached)
+
+	_, cached, err = cache.get(testOrgID, second, true, resolve)
+	require.NoError(t, err)
+	assert.False(t, cached)
+	assert.Equal(t, 5, resolves)
+}
+
+func TestFramesToVariableOptions(t *testing.T) {
+	options := framesToVariableOptions(data.Frames{
+		data.NewFrame("",
+			data.NewField("__text", nil, []string{"Web 1", "Web 2"}),
+			data.NewField("__value", nil, []int64{1, 2}),
+		),
+		data.NewFrame("", data.NewField("value", nil, []string{"3"})),
+		data.NewFrame(""),
+	})
+	assert.Equal(t, []dtos.VariableOption{
+		{Text: "Web 1", Value: "1"},
+		{Text: "Web 2", Value: "2"},
+		{Text: "3", Value: "3"},
+	}, options)
+}
@@ -64,6 +64,13 @@ func runPluginCommand(command func(commandLine utils.CommandLine) error) func(co
 	}
 }
 
+func runCommand(command func(commandLine utils.CommandLine) error) func(context *cli.Context) error {
+	return func(context *cli.Context) error {
+		cmd := &utils.ContextCommandLine{Context: context}
+		return command(cmd)
+	}
+}
+
 // Command contains command state.
 type Command struct {
 	Client utils.ApiClient
@@ -134,6 +141,14 @@ var adminCommands = []*cli.Command{
 	},
 }
 
+var dashboardCommands = []*cli.Command{
+	{
+		Name:   "lint",
+		Usage:  "lint <dashboard JSON files>, lint the queries of the panels of dashboards",
+		Action: runCommand(lintDashboardsCommand),
+	},
+}
+
 var cueCommands = []*cli.Command{
 	{
 		Name:   "validate-schema",
@@ -164,6 +179,11 @@ var Commands = []*cli.Command{
 		Usage:       "Grafana admin commands",
 		Subcommands: adminCommands,
 	},
+	{
+		Name:        "dashboards",
+		Usage:       "Dashboard commands",
+		Subcommands: dashboardCommands,
+	},
 	{
 		Name:        "cue",
 		Usage:       "Cue validation commands",
@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/components/querylint"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+var errMissingDashboardFiles = errors.New("missing dashboard JSON files, usage: lint <dashboard JSON files>")
+
+// queryWarning is a warning about a query of a panel of a dashboard.
+type queryWarning struct {
+	panel string
+	refID string
+	querylint.Warning
+}
+
+func (w queryWarning) String() string {
+	return fmt.Sprintf("panel %q query %s: [%s] %s", w.panel, w.refID, w.Rule, w.Message)
+}
+
+// lintDashboardsCommand lints the queries of the panels of dashboard JSON files, failing when any query has warnings,
+// e.g. for the dashboards provisioned from a repository.
+func lintDashboardsCommand(c utils.CommandLine) error {
+	return lintDashboardFiles(c.Args().Slice())
+}
+
+func lintDashboardFiles(files []string) error {
+	if len(files) == 0 {
+		return errMissingDashboardFiles
+	}
+
+	count := 0
+	for _, file := range files {
+		b, err := os.ReadFile(filepath.Clean(file))
+		if err != nil {
+			return err
+		}
+		dashboard, err := simplejson.NewJson(b)
+		if err != nil {
+			return fmt.Errorf("failed to parse dashboard %q: %w", file, err)
+		}
+
+		for _, warning := range lintDashboard(dashboard) {
+			logger.Infof("%s: %s\n", file, warning)
+			count++
+		}
+	}
+
+	if count > 0 {
+		return fmt.Errorf("%d query warnings", count)
+	}
+	logger.Info("No query warnings\n")
+	return nil
+}
+
+// lintDashboard returns the warnings about the queries of the panels of a dashboard, including the panels of the
+// collapsed rows and of the rows of the old dashboards.
+func lintDashboard(dashboard *simplejson.Json) []queryWarning {
+	warnings := lintPanels(dashboard.Get("panels"))
+	for i := range dashboard.Get("rows").MustArray() {
+		warnings = append(warnings, lintPanels(dashboard.Get("rows").GetIndex(i).Get("panels"))...)
+	}
+	return warnings
+}
+
+func lintPanels(panels *simplejson.Json) []queryWarning {
+	warnings := []queryWarning{}
+	for i := range panels.MustArray() {
+		panel := panels.GetIndex(i)
+		title := panel.Get("title").MustString()
+
+		targets := panel.Get("targets")
+		for j := range targets.MustArray() {
+			target := targets.GetIndex(j)
+			for _, warning := range querylint.Lint("", target) {
+				warnings = append(warnings, queryWarning{panel: title, refID: target.Get("refId").MustString(), Warning: warning})
+			}
+		}
+
+		warnings = append(warnings, lintPanels(panel.Get("panels"))...)
+	}
+	return warnings
+}
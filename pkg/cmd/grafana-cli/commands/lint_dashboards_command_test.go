@@ -0,0 +1,37 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/querylint"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestLintDashboard(t *testing.T) {
+	t.Run("Should lint the queries of the panels", func(t *testing.T) {
+		require.EqualError(t, lintDashboardFiles([]string{"testdata/dashboards/lint.json"}), "2 query warnings")
+	})
+
+	t.Run("Should lint the queries of the panels of the rows of old dashboards", func(t *testing.T) {
+		dashboard := simplejson.NewFromAny(map[string]interface{}{
+			"rows": []interface{}{
+				map[string]interface{}{"panels": []interface{}{
+					map[string]interface{}{"title": "Errors", "targets": []interface{}{
+						map[string]interface{}{"refId": "A", "expr": `up{job=~".*"}`},
+					}},
+				}},
+			},
+		})
+
+		warnings := lintDashboard(dashboard)
+		require.Len(t, warnings, 1)
+		require.Equal(t, "Errors", warnings[0].panel)
+		require.Equal(t, querylint.UnboundedSelector, warnings[0].Rule)
+	})
+
+	t.Run("Should fail without dashboard files", func(t *testing.T) {
+		require.Equal(t, errMissingDashboardFiles, lintDashboardFiles(nil))
+	})
+}
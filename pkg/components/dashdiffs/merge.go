@@ -0,0 +1,173 @@
+package dashdiffs
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	deltaFormatter "github.com/yudai/gojsondiff/formatter"
+)
+
+// ThreeWayResult is the result of merging two concurrent edits of the same
+// dashboard version.
+type ThreeWayResult struct {
+	// Mine is the delta from base to the edit being saved.
+	Mine json.RawMessage `json:"mine"`
+	// Theirs is the delta from base to the edit that was saved in between.
+	Theirs json.RawMessage `json:"theirs"`
+	// Conflicts lists JSON pointers to values changed differently by both
+	// edits.
+	Conflicts []string `json:"conflicts"`
+	// Merged holds both edits combined, only set when there are no conflicts.
+	Merged *simplejson.Json `json:"merged"`
+}
+
+// absent marks a key that doesn't exist in one of the documents being merged.
+type absent struct{}
+
+// CalculateThreeWayDiff diffs both mine and theirs against their common base
+// and tries to merge them, recording conflicting changes.
+func CalculateThreeWayDiff(base, mine, theirs *simplejson.Json) (*ThreeWayResult, error) {
+	mineDelta, err := getDelta(base, mine)
+	if err != nil {
+		return nil, err
+	}
+
+	theirsDelta, err := getDelta(base, theirs)
+	if err != nil {
+		return nil, err
+	}
+
+	baseDoc, err := toDocument(base)
+	if err != nil {
+		return nil, err
+	}
+	mineDoc, err := toDocument(mine)
+	if err != nil {
+		return nil, err
+	}
+	theirsDoc, err := toDocument(theirs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ThreeWayResult{
+		Mine:      mineDelta,
+		Theirs:    theirsDelta,
+		Conflicts: []string{},
+	}
+
+	merged := mergeValues("", baseDoc, mineDoc, theirsDoc, &result.Conflicts)
+	if len(result.Conflicts) == 0 {
+		result.Merged = simplejson.NewFromAny(merged)
+	}
+
+	return result, nil
+}
+
+func getDelta(base, target *simplejson.Json) (json.RawMessage, error) {
+	_, jsonDiff, err := getDiff(base, target)
+	if err == ErrNilDiff {
+		return json.RawMessage("{}"), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	delta, err := deltaFormatter.NewDeltaFormatter().Format(jsonDiff)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.RawMessage(delta), nil
+}
+
+// toDocument normalizes a dashboard into plain JSON values so that values
+// from different sources can be compared.
+func toDocument(data *simplejson.Json) (interface{}, error) {
+	b, err := data.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+func mergeValues(path string, base, mine, theirs interface{}, conflicts *[]string) interface{} {
+	switch {
+	case reflect.DeepEqual(mine, theirs):
+		return mine
+	case reflect.DeepEqual(base, mine):
+		return theirs
+	case reflect.DeepEqual(base, theirs):
+		return mine
+	}
+
+	baseMap, baseIsMap := base.(map[string]interface{})
+	mineMap, mineIsMap := mine.(map[string]interface{})
+	theirsMap, theirsIsMap := theirs.(map[string]interface{})
+	if baseIsMap && mineIsMap && theirsIsMap {
+		return mergeObjects(path, baseMap, mineMap, theirsMap, conflicts)
+	}
+
+	baseArr, baseIsArr := base.([]interface{})
+	mineArr, mineIsArr := mine.([]interface{})
+	theirsArr, theirsIsArr := theirs.([]interface{})
+	if baseIsArr && mineIsArr && theirsIsArr && len(baseArr) == len(mineArr) && len(baseArr) == len(theirsArr) {
+		merged := make([]interface{}, len(baseArr))
+		for i := range baseArr {
+			merged[i] = mergeValues(fmt.Sprintf("%s/%d", path, i), baseArr[i], mineArr[i], theirsArr[i], conflicts)
+		}
+		return merged
+	}
+
+	if path == "" {
+		path = "/"
+	}
+	*conflicts = append(*conflicts, path)
+	return mine
+}
+
+func mergeObjects(path string, base, mine, theirs map[string]interface{}, conflicts *[]string) interface{} {
+	keys := make(map[string]struct{})
+	for _, m := range []map[string]interface{}{base, mine, theirs} {
+		for key := range m {
+			keys[key] = struct{}{}
+		}
+	}
+
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	merged := make(map[string]interface{})
+	for _, key := range sorted {
+		value := mergeValues(path+"/"+escapePointerToken(key), lookup(base, key), lookup(mine, key), lookup(theirs, key), conflicts)
+		if _, removed := value.(absent); !removed {
+			merged[key] = value
+		}
+	}
+
+	return merged
+}
+
+func lookup(m map[string]interface{}, key string) interface{} {
+	if value, ok := m[key]; ok {
+		return value
+	}
+	return absent{}
+}
+
+func escapePointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
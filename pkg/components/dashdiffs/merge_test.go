@@ -0,0 +1,79 @@
+package dashdiffs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestCalculateThreeWayDiff(t *testing.T) {
+	parse := func(t *testing.T, s string) *simplejson.Json {
+		t.Helper()
+		j, err := simplejson.NewJson([]byte(s))
+		require.NoError(t, err)
+		return j
+	}
+
+	base := `{
+		"title": "Dash",
+		"refresh": "5s",
+		"panels": [{"id": 1, "title": "A"}, {"id": 2, "title": "B"}]
+	}`
+
+	t.Run("Non-overlapping edits are merged", func(t *testing.T) {
+		mine := `{
+			"title": "Dash",
+			"refresh": "5s",
+			"panels": [{"id": 1, "title": "A changed"}, {"id": 2, "title": "B"}],
+			"tags": ["new"]
+		}`
+		theirs := `{
+			"title": "Dash renamed",
+			"panels": [{"id": 1, "title": "A"}, {"id": 2, "title": "B changed"}]
+		}`
+
+		result, err := CalculateThreeWayDiff(parse(t, base), parse(t, mine), parse(t, theirs))
+		require.NoError(t, err)
+		assert.Empty(t, result.Conflicts)
+		require.NotNil(t, result.Merged)
+
+		assert.Equal(t, "Dash renamed", result.Merged.Get("title").MustString())
+		assert.Equal(t, "A changed", result.Merged.Get("panels").GetIndex(0).Get("title").MustString())
+		assert.Equal(t, "B changed", result.Merged.Get("panels").GetIndex(1).Get("title").MustString())
+		assert.Equal(t, []interface{}{"new"}, result.Merged.Get("tags").MustArray())
+		_, hasRefresh := result.Merged.CheckGet("refresh")
+		assert.False(t, hasRefresh)
+		assert.NotEqual(t, "{}", string(result.Mine))
+		assert.NotEqual(t, "{}", string(result.Theirs))
+	})
+
+	t.Run("Overlapping edits are reported as conflicts", func(t *testing.T) {
+		mine := `{
+			"title": "Mine",
+			"refresh": "5s",
+			"panels": [{"id": 1, "title": "A"}]
+		}`
+		theirs := `{
+			"title": "Theirs",
+			"refresh": "5s",
+			"panels": [{"id": 1, "title": "A"}, {"id": 2, "title": "B"}, {"id": 3, "title": "C"}]
+		}`
+
+		result, err := CalculateThreeWayDiff(parse(t, base), parse(t, mine), parse(t, theirs))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"/panels", "/title"}, result.Conflicts)
+		assert.Nil(t, result.Merged)
+	})
+
+	t.Run("Identical edits don't conflict", func(t *testing.T) {
+		edit := `{"title": "Same", "refresh": "5s", "panels": []}`
+
+		result, err := CalculateThreeWayDiff(parse(t, base), parse(t, edit), parse(t, edit))
+		require.NoError(t, err)
+		assert.Empty(t, result.Conflicts)
+		assert.Equal(t, "Same", result.Merged.Get("title").MustString())
+	})
+}
@@ -0,0 +1,193 @@
+// Package datalinks resolves data link URL templates on the server, so that
+// links in rendered images, reports and alert screenshots work without the
+// frontend interpolating them.
+package datalinks
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// Options configures how data links are resolved.
+type Options struct {
+	// Links are added to every non-time field, e.g. the data links of a panel.
+	Links []data.DataLink
+	// Variables maps variable names to a value or a list of values.
+	Variables map[string]interface{}
+	From      time.Time
+	To        time.Time
+}
+
+// built-in variables that are safe to use in a URL without encoding
+var urlSafeVariables = map[string]bool{
+	"__from":           true,
+	"__to":             true,
+	"__url_time_range": true,
+}
+
+// matches ${var}, ${var:format}, [[var]], [[var:format]] and $var
+var variablePattern = regexp.MustCompile(`\$\{([^}:]+)(?::([^}]+))?\}|\[\[([^\]:]+)(?::([^\]]+))?\]\]|\$([a-zA-Z0-9_]+)`)
+
+// Resolve adds the links of the options to the frames and interpolates the
+// URL and title of all links of their fields, in place. Value placeholders,
+// e.g. ${__value.raw}, are only resolved for fields with a single value, in
+// all other cases and for unknown variables the placeholder is kept.
+func Resolve(frames data.Frames, opts Options) {
+	for _, frame := range frames {
+		for _, field := range frame.Fields {
+			if len(opts.Links) > 0 && field.Type() != data.FieldTypeTime && field.Type() != data.FieldTypeNullableTime {
+				if field.Config == nil {
+					field.Config = &data.FieldConfig{}
+				}
+				field.Config.Links = append(field.Config.Links, opts.Links...)
+			}
+
+			if field.Config == nil || len(field.Config.Links) == 0 {
+				continue
+			}
+
+			scope := newFieldScope(frame, field, opts)
+			links := make([]data.DataLink, 0, len(field.Config.Links))
+			for _, link := range field.Config.Links {
+				link.URL = scope.interpolate(link.URL, true)
+				link.Title = scope.interpolate(link.Title, false)
+				links = append(links, link)
+			}
+			field.Config.Links = links
+		}
+	}
+}
+
+type fieldScope struct {
+	values map[string][]string
+}
+
+func newFieldScope(frame *data.Frame, field *data.Field, opts Options) *fieldScope {
+	s := &fieldScope{values: map[string][]string{}}
+
+	for name, value := range opts.Variables {
+		s.values[name] = variableValues(value)
+	}
+
+	if !opts.From.IsZero() && !opts.To.IsZero() {
+		from := strconv.FormatInt(opts.From.UnixNano()/int64(time.Millisecond), 10)
+		to := strconv.FormatInt(opts.To.UnixNano()/int64(time.Millisecond), 10)
+		s.values["__from"] = []string{from}
+		s.values["__to"] = []string{to}
+		s.values["__url_time_range"] = []string{"from=" + from + "&to=" + to}
+	}
+
+	s.values["__series.name"] = []string{frame.Name}
+	s.values["__field.name"] = []string{field.Name}
+	if field.Config != nil && field.Config.DisplayNameFromDS != "" {
+		s.values["__field.name"] = []string{field.Config.DisplayNameFromDS}
+	}
+	for name, value := range field.Labels {
+		s.values["__field.labels."+name] = []string{value}
+	}
+
+	if field.Len() == 1 {
+		s.values["__value.raw"] = []string{formatValue(field, 0)}
+		s.values["__value.text"] = s.values["__value.raw"]
+		s.values["__value.numeric"] = s.values["__value.raw"]
+		for _, f := range frame.Fields {
+			if f.Len() != 1 {
+				continue
+			}
+			s.values["__data.fields."+f.Name] = []string{formatValue(f, 0)}
+			if f.Type() == data.FieldTypeTime || f.Type() == data.FieldTypeNullableTime {
+				if _, ok := s.values["__value.time"]; !ok {
+					s.values["__value.time"] = []string{formatValue(f, 0)}
+				}
+			}
+		}
+	}
+
+	return s
+}
+
+func (s *fieldScope) interpolate(text string, inURL bool) string {
+	return variablePattern.ReplaceAllStringFunc(text, func(match string) string {
+		groups := variablePattern.FindStringSubmatch(match)
+		name, format := groups[1]+groups[3]+groups[5], groups[2]+groups[4]
+
+		values, ok := s.values[name]
+		if !ok {
+			return match
+		}
+
+		if format == "" && (!inURL || urlSafeVariables[name]) {
+			format = "raw"
+		}
+		return formatValues(name, values, format)
+	})
+}
+
+func formatValues(name string, values []string, format string) string {
+	switch format {
+	case "raw", "csv":
+		return strings.Join(values, ",")
+	case "pipe":
+		return strings.Join(values, "|")
+	case "json":
+		b, err := json.Marshal(values)
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	case "queryparam":
+		params := make([]string, 0, len(values))
+		for _, value := range values {
+			params = append(params, "var-"+url.QueryEscape(name)+"="+url.QueryEscape(value))
+		}
+		return strings.Join(params, "&")
+	default:
+		encoded := make([]string, 0, len(values))
+		for _, value := range values {
+			encoded = append(encoded, url.QueryEscape(value))
+		}
+		return strings.Join(encoded, ",")
+	}
+}
+
+func variableValues(value interface{}) []string {
+	switch v := value.(type) {
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			values = append(values, fmt.Sprint(item))
+		}
+		return values
+	case []string:
+		return v
+	case nil:
+		return []string{}
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+func formatValue(field *data.Field, idx int) string {
+	value, ok := field.ConcreteAt(idx)
+	if !ok {
+		return ""
+	}
+
+	switch v := value.(type) {
+	case time.Time:
+		return strconv.FormatInt(v.UnixNano()/int64(time.Millisecond), 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	default:
+		return fmt.Sprint(v)
+	}
+}
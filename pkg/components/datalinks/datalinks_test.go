@@ -0,0 +1,50 @@
+package datalinks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	from := time.Unix(1600000000, 0)
+	to := time.Unix(1600003600, 0)
+
+	t.Run("Panel links are added and interpolated", func(t *testing.T) {
+		frame := data.NewFrame("cpu",
+			data.NewField("time", nil, []time.Time{to}),
+			data.NewField("value", data.Labels{"host": "a b"}, []float64{1.5}),
+		)
+
+		Resolve(data.Frames{frame}, Options{
+			Links: []data.DataLink{{
+				Title: "Details of ${__field.labels.host} in $env",
+				URL:   "/d/abc?${__url_time_range}&var-host=${__field.labels.host}&${servers:queryparam}&value=${__value.raw}&t=${__value.time}",
+			}},
+			Variables: map[string]interface{}{
+				"env":     "prod",
+				"servers": []interface{}{"s1", "s2"},
+			},
+			From: from,
+			To:   to,
+		})
+
+		assert.Nil(t, frame.Fields[0].Config)
+		require.Len(t, frame.Fields[1].Config.Links, 1)
+		link := frame.Fields[1].Config.Links[0]
+		assert.Equal(t, "Details of a b in prod", link.Title)
+		assert.Equal(t, "/d/abc?from=1600000000000&to=1600003600000&var-host=a+b&var-servers=s1&var-servers=s2&value=1.5&t=1600003600000", link.URL)
+	})
+
+	t.Run("Placeholders that can't be resolved are kept", func(t *testing.T) {
+		field := data.NewField("value", nil, []float64{1, 2})
+		field.Config = &data.FieldConfig{Links: []data.DataLink{{URL: "/explore?value=${__value.raw}&x=$unknown&n=${__field.name}"}}}
+
+		Resolve(data.Frames{data.NewFrame("", field)}, Options{})
+
+		assert.Equal(t, "/explore?value=${__value.raw}&x=$unknown&n=value", field.Config.Links[0].URL)
+	})
+}
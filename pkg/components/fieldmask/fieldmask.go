@@ -0,0 +1,161 @@
+// Package fieldmask implements the partial updates of the HTTP API, where a
+// mask of JSON field paths selects the fields of a stored resource to replace
+// by the fields of the request body.
+package fieldmask
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// QueryParam is the query parameter of the field mask of a request, a comma
+// separated list of field paths like "name,jsonData.httpMethod".
+const QueryParam = "fieldMask"
+
+// FieldMask is a list of the dotted paths of the JSON fields to update.
+type FieldMask []string
+
+// Parse returns the field mask of a query parameter value.
+func Parse(s string) FieldMask {
+	var mask FieldMask
+	for _, path := range strings.Split(s, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			mask = append(mask, path)
+		}
+	}
+	return mask
+}
+
+// IsEmpty returns true if the mask has no paths, which means that the whole
+// resource is replaced.
+func (m FieldMask) IsEmpty() bool {
+	return len(m) == 0
+}
+
+// Has returns true if the mask updates the top level field.
+func (m FieldMask) Has(field string) bool {
+	for _, path := range m {
+		if strings.SplitN(path, ".", 2)[0] == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate returns an error if a path of the mask is malformed or if its top
+// level field is not one of the fields that can be updated.
+func (m FieldMask) Validate(fields ...string) error {
+	for _, path := range m {
+		segments := strings.Split(path, ".")
+		for _, s := range segments {
+			if s == "" {
+				return fmt.Errorf("invalid field mask path %q", path)
+			}
+		}
+		if !contains(fields, segments[0]) {
+			return fmt.Errorf("field %q cannot be updated", segments[0])
+		}
+	}
+	return nil
+}
+
+// Merge sets out to the existing resource with the fields of the mask replaced
+// by the fields of the update, both marshaled to JSON. The fields of the mask
+// missing from the update are removed. If the mask is empty out is set to the
+// update. out should point to a zero value.
+func (m FieldMask) Merge(existing interface{}, update interface{}, out interface{}) error {
+	src, err := toMap(update)
+	if err != nil {
+		return err
+	}
+	if m.IsEmpty() {
+		return fromMap(src, out)
+	}
+
+	dst, err := toMap(existing)
+	if err != nil {
+		return err
+	}
+	for _, path := range m {
+		segments := strings.Split(path, ".")
+		if value, ok := get(src, segments); ok {
+			set(dst, segments, value)
+		} else {
+			del(dst, segments)
+		}
+	}
+	return fromMap(dst, out)
+}
+
+func toMap(v interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	// decode the numbers as json.Number to not lose the precision of the IDs
+	m := map[string]interface{}{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&m); err != nil {
+		return nil, err
+	}
+	if m == nil {
+		m = map[string]interface{}{}
+	}
+	return m, nil
+}
+
+func fromMap(m map[string]interface{}, out interface{}) error {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, out)
+}
+
+func get(m map[string]interface{}, path []string) (interface{}, bool) {
+	value, ok := m[path[0]]
+	if !ok || len(path) == 1 {
+		return value, ok
+	}
+	child, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	return get(child, path[1:])
+}
+
+func set(m map[string]interface{}, path []string, value interface{}) {
+	if len(path) == 1 {
+		m[path[0]] = value
+		return
+	}
+	child, ok := m[path[0]].(map[string]interface{})
+	if !ok {
+		child = map[string]interface{}{}
+		m[path[0]] = child
+	}
+	set(child, path[1:], value)
+}
+
+func del(m map[string]interface{}, path []string) {
+	if len(path) == 1 {
+		delete(m, path[0])
+		return
+	}
+	if child, ok := m[path[0]].(map[string]interface{}); ok {
+		del(child, path[1:])
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package fieldmask
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type resource struct {
+	ID       int64                  `json:"id"`
+	Name     string                 `json:"name"`
+	URL      string                 `json:"url"`
+	JSONData map[string]interface{} `json:"jsonData"`
+}
+
+func TestParse(t *testing.T) {
+	require.Nil(t, Parse(""))
+	require.Equal(t, FieldMask{"name", "jsonData.httpMethod"}, Parse(" name, ,jsonData.httpMethod"))
+}
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, FieldMask{"name", "jsonData.a.b"}.Validate("name", "jsonData"))
+	require.EqualError(t, FieldMask{"uid"}.Validate("name", "jsonData"), `field "uid" cannot be updated`)
+	require.EqualError(t, FieldMask{"jsonData."}.Validate("name", "jsonData"), `invalid field mask path "jsonData."`)
+}
+
+func TestHas(t *testing.T) {
+	mask := FieldMask{"name", "jsonData.httpMethod"}
+	require.True(t, mask.Has("name"))
+	require.True(t, mask.Has("jsonData"))
+	require.False(t, mask.Has("url"))
+}
+
+func TestMerge(t *testing.T) {
+	existing := resource{
+		ID:       9007199254740993,
+		Name:     "prometheus",
+		URL:      "http://localhost:9090",
+		JSONData: map[string]interface{}{"httpMethod": "GET", "timeInterval": "15s"},
+	}
+
+	t.Run("empty mask replaces the resource", func(t *testing.T) {
+		var out resource
+		err := FieldMask(nil).Merge(existing, json.RawMessage(`{"name":"new"}`), &out)
+		require.NoError(t, err)
+		require.Equal(t, resource{Name: "new"}, out)
+	})
+
+	t.Run("mask replaces the fields of its paths", func(t *testing.T) {
+		var out resource
+		update := json.RawMessage(`{"name":"new","url":"ignored","jsonData":{"httpMethod":"POST","timeInterval":"ignored"}}`)
+		err := FieldMask{"name", "jsonData.httpMethod"}.Merge(existing, update, &out)
+		require.NoError(t, err)
+		require.Equal(t, resource{
+			ID:       9007199254740993,
+			Name:     "new",
+			URL:      "http://localhost:9090",
+			JSONData: map[string]interface{}{"httpMethod": "POST", "timeInterval": "15s"},
+		}, out)
+	})
+
+	t.Run("mask removes the fields missing from the update", func(t *testing.T) {
+		var out resource
+		err := FieldMask{"url", "jsonData.timeInterval"}.Merge(existing, json.RawMessage(`{}`), &out)
+		require.NoError(t, err)
+		require.Equal(t, resource{
+			ID:       9007199254740993,
+			Name:     "prometheus",
+			JSONData: map[string]interface{}{"httpMethod": "GET"},
+		}, out)
+	})
+
+	t.Run("mask creates the missing parents", func(t *testing.T) {
+		var out resource
+		err := FieldMask{"jsonData.httpMethod"}.Merge(resource{Name: "a"}, json.RawMessage(`{"jsonData":{"httpMethod":"POST"}}`), &out)
+		require.NoError(t, err)
+		require.Equal(t, resource{Name: "a", JSONData: map[string]interface{}{"httpMethod": "POST"}}, out)
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"regexp"
 	"time"
 
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/grafana/grafana/pkg/components/imguploader/gcs"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/setting"
@@ -47,12 +48,20 @@ func NewImageUploader() (ImageUploader, error) {
 		bucketUrl := s3sec.Key("bucket_url").MustString("")
 		accessKey := s3sec.Key("access_key").MustString("")
 		secretKey := s3sec.Key("secret_key").MustString("")
+		partSizeMB := s3sec.Key("multipart_part_size_mb").MustInt64(5)
+		concurrency := s3sec.Key("multipart_concurrency").MustInt(5)
 
 		if path != "" && path[len(path)-1:] != "/" {
 			path += "/"
 		}
 
-		if bucket == "" || region == "" {
+		switch {
+		case endpoint != "" && bucket == "":
+			// the bucket URLs are of AWS S3
+			return nil, fmt.Errorf("the bucket of external_image_storage.s3 is required with a custom endpoint")
+		case endpoint != "" && region == "":
+			region = defaultS3Region
+		case bucket == "" || region == "":
 			info, err := getRegionAndBucketFromUrl(bucketUrl)
 			if err != nil {
 				return nil, err
@@ -60,8 +69,18 @@ func NewImageUploader() (ImageUploader, error) {
 			bucket = info.bucket
 			region = info.region
 		}
+		if partSizeMB*1024*1024 < s3manager.MinUploadPartSize {
+			return nil, fmt.Errorf("the multipart_part_size_mb of external_image_storage.s3 must be at least 5")
+		}
+		if concurrency < 1 {
+			return nil, fmt.Errorf("the multipart_concurrency of external_image_storage.s3 must be at least 1")
+		}
 
-		return NewS3Uploader(endpoint, region, bucket, path, "public-read", accessKey, secretKey, pathStyleAccess), nil
+		uploader := NewS3Uploader(endpoint, region, bucket, path, "public-read", accessKey, secretKey, pathStyleAccess)
+		uploader.partSize = partSizeMB * 1024 * 1024
+		uploader.concurrency = concurrency
+		uploader.validateOnStartup = s3sec.Key("validate_on_startup").MustBool(false)
+		return uploader, nil
 	case "webdav":
 		webdavSec, err := setting.Raw.GetSection("external_image_storage.webdav")
 		if err != nil {
@@ -122,6 +141,21 @@ func NewImageUploader() (ImageUploader, error) {
 	return NopImageUploader{}, nil
 }
 
+// ValidateImageUploader validates the storage of the configured image uploader when its validation on startup is
+// enabled, so that a misconfigured storage fails the startup with an error telling which setting to fix, rather than
+// the uploads of the alert notifications. Only the S3 image uploader supports the validation.
+func ValidateImageUploader(ctx context.Context) error {
+	uploader, err := NewImageUploader()
+	if err != nil {
+		return err
+	}
+	s3Uploader, ok := uploader.(*S3Uploader)
+	if !ok || !s3Uploader.validateOnStartup {
+		return nil
+	}
+	return s3Uploader.Validate(ctx)
+}
+
 type s3Info struct {
 	region string
 	bucket string
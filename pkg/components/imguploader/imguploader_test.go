@@ -82,6 +82,41 @@ func TestImageUploaderFactory(t *testing.T) {
 				So(original.accessKey, ShouldEqual, "access_key")
 				So(original.secretKey, ShouldEqual, "secret_key")
 			})
+
+			Convey("with a custom endpoint", func() {
+				s3sec, err := setting.Raw.GetSection("external_image_storage.s3")
+				So(err, ShouldBeNil)
+				_, err = s3sec.NewKey("endpoint", "http://minio:9000")
+				So(err, ShouldBeNil)
+				_, err = s3sec.NewKey("path_style_access", "true")
+				So(err, ShouldBeNil)
+				_, err = s3sec.NewKey("multipart_part_size_mb", "16")
+				So(err, ShouldBeNil)
+				_, err = s3sec.NewKey("multipart_concurrency", "2")
+				So(err, ShouldBeNil)
+
+				Convey("the bucket is required", func() {
+					_, err := NewImageUploader()
+					So(err, ShouldNotBeNil)
+				})
+
+				Convey("the region defaults to us-east-1", func() {
+					_, err = s3sec.NewKey("bucket", "grafana")
+					So(err, ShouldBeNil)
+
+					uploader, err := NewImageUploader()
+					So(err, ShouldBeNil)
+
+					original, ok := uploader.(*S3Uploader)
+					So(ok, ShouldBeTrue)
+					So(original.endpoint, ShouldEqual, "http://minio:9000")
+					So(original.region, ShouldEqual, "us-east-1")
+					So(original.bucket, ShouldEqual, "grafana")
+					So(original.pathStyleAccess, ShouldBeTrue)
+					So(original.partSize, ShouldEqual, 16*1024*1024)
+					So(original.concurrency, ShouldEqual, 2)
+				})
+			})
 		})
 
 		Convey("Webdav uploader", func() {
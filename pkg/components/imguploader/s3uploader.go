@@ -2,11 +2,15 @@ package imguploader
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"mime"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/client"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/credentials/ec2rolecreds"
@@ -14,13 +18,19 @@ import (
 	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/defaults"
 	"github.com/aws/aws-sdk-go/aws/ec2metadata"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/util"
 )
 
+// defaultS3Region is the region of the S3-compatible storages with a custom endpoint and no region, which most of
+// them ignore.
+const defaultS3Region = "us-east-1"
+
 type S3Uploader struct {
 	endpoint        string
 	region          string
@@ -30,7 +40,11 @@ type S3Uploader struct {
 	secretKey       string
 	accessKey       string
 	pathStyleAccess bool
-	log             log.Logger
+	// the files larger than the part size are uploaded in parts, concurrently
+	partSize          int64
+	concurrency       int
+	validateOnStartup bool
+	log               log.Logger
 }
 
 func NewS3Uploader(endpoint, region, bucket, path, acl, accessKey, secretKey string, pathStyleAccess bool) *S3Uploader {
@@ -43,14 +57,16 @@ func NewS3Uploader(endpoint, region, bucket, path, acl, accessKey, secretKey str
 		accessKey:       accessKey,
 		secretKey:       secretKey,
 		pathStyleAccess: pathStyleAccess,
+		partSize:        s3manager.DefaultUploadPartSize,
+		concurrency:     s3manager.DefaultUploadConcurrency,
 		log:             log.New("s3uploader"),
 	}
 }
 
-func (u *S3Uploader) Upload(ctx context.Context, imageDiskPath string) (string, error) {
+func (u *S3Uploader) newSession() (*session.Session, error) {
 	sess, err := session.NewSession()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	creds := credentials.NewChainCredentials(
 		[]credentials.Provider{
@@ -68,12 +84,26 @@ func (u *S3Uploader) Upload(ctx context.Context, imageDiskPath string) (string,
 		S3ForcePathStyle: aws.Bool(u.pathStyleAccess),
 		Credentials:      creds,
 	}
+	return session.NewSession(cfg)
+}
+
+// Upload uploads a file, in parts when it's larger than the part size. The key of the file has its extension, and
+// its content type is the one of the extension, for other files than images like PDF reports.
+func (u *S3Uploader) Upload(ctx context.Context, imageDiskPath string) (string, error) {
+	ext := filepath.Ext(imageDiskPath)
+	if ext == "" {
+		ext = pngExt
+	}
+	contentType := mime.TypeByExtension(ext)
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
 
 	rand, err := util.GetRandomString(20)
 	if err != nil {
 		return "", err
 	}
-	key := u.path + rand + pngExt
+	key := u.path + rand + ext
 	log.Debugf("Uploading image to s3. bucket = %s, path = %s", u.bucket, key)
 
 	// We can ignore the gosec G304 warning on this one because `imageDiskPath` comes
@@ -89,18 +119,21 @@ func (u *S3Uploader) Upload(ctx context.Context, imageDiskPath string) (string,
 		}
 	}()
 
-	sess, err = session.NewSession(cfg)
+	sess, err := u.newSession()
 	if err != nil {
 		return "", err
 	}
 
-	uploader := s3manager.NewUploader(sess)
+	uploader := s3manager.NewUploader(sess, func(uploader *s3manager.Uploader) {
+		uploader.PartSize = u.partSize
+		uploader.Concurrency = u.concurrency
+	})
 	result, err := uploader.UploadWithContext(ctx, &s3manager.UploadInput{
 		Bucket:      aws.String(u.bucket),
 		Key:         aws.String(key),
 		ACL:         aws.String(u.acl),
 		Body:        file,
-		ContentType: aws.String("image/png"),
+		ContentType: aws.String(contentType),
 	})
 	if err != nil {
 		return "", err
@@ -108,6 +141,76 @@ func (u *S3Uploader) Upload(ctx context.Context, imageDiskPath string) (string,
 	return result.Location, nil
 }
 
+// Validate checks that the bucket exists and that the credentials can list the objects of the path, and returns an
+// error telling which setting to fix otherwise. The path is created by the first upload, so an empty path is only
+// logged.
+func (u *S3Uploader) Validate(ctx context.Context) error {
+	sess, err := u.newSession()
+	if err != nil {
+		return err
+	}
+	svc := s3.New(sess)
+
+	if _, err := svc.HeadBucketWithContext(ctx, &s3.HeadBucketInput{Bucket: aws.String(u.bucket)}); err != nil {
+		return u.validationError(err, "")
+	}
+
+	if u.path == "" {
+		return nil
+	}
+	objects, err := svc.ListObjectsV2WithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket:  aws.String(u.bucket),
+		Prefix:  aws.String(u.path),
+		MaxKeys: aws.Int64(1),
+	})
+	if err != nil {
+		return u.validationError(err, u.path)
+	}
+	if aws.Int64Value(objects.KeyCount) == 0 {
+		u.log.Warn("The path of the external image storage has no objects yet", "bucket", u.bucket, "path", u.path)
+	}
+	return nil
+}
+
+// validationError returns the error of a failed validation request, with the settings to check.
+func (u *S3Uploader) validationError(err error, path string) error {
+	endpoint := u.endpoint
+	if endpoint == "" {
+		endpoint = "AWS S3"
+	}
+	target := fmt.Sprintf("bucket %q", u.bucket)
+	if path != "" {
+		target = fmt.Sprintf("path %q of bucket %q", path, u.bucket)
+	}
+
+	var reqErr awserr.RequestFailure
+	if errors.As(err, &reqErr) {
+		switch {
+		case reqErr.Code() == "BucketRegionError" || reqErr.StatusCode() == 301:
+			return fmt.Errorf("external image storage: %s isn't in region %q, set the region of "+
+				"external_image_storage.s3 to the region of the bucket: %w", target, u.region, err)
+		case reqErr.StatusCode() == 403:
+			return fmt.Errorf("external image storage: access denied to %s of %s, check the access_key and secret_key "+
+				"of external_image_storage.s3 and that they're allowed the s3:ListBucket and s3:PutObject actions: %w",
+				target, endpoint, err)
+		case reqErr.StatusCode() == 404:
+			return fmt.Errorf("external image storage: %s doesn't exist at %s, create it or fix the bucket of "+
+				"external_image_storage.s3: %w", target, endpoint, err)
+		}
+	}
+
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) && awsErr.Code() == request.ErrCodeRequestError {
+		if u.endpoint != "" && !u.pathStyleAccess {
+			return fmt.Errorf("external image storage: failed to reach %s at %s, S3-compatible storages like MinIO and "+
+				"Ceph usually require path_style_access = true in external_image_storage.s3: %w", target, endpoint, err)
+		}
+		return fmt.Errorf("external image storage: failed to reach %s at %s, check the endpoint of "+
+			"external_image_storage.s3: %w", target, endpoint, err)
+	}
+	return fmt.Errorf("external image storage: failed to validate %s at %s: %w", target, endpoint, err)
+}
+
 func webIdentityProvider(sess client.ConfigProvider) credentials.Provider {
 	svc := sts.New(sess)
 
@@ -2,10 +2,18 @@ package imguploader
 
 import (
 	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
 	"testing"
 
 	"github.com/grafana/grafana/pkg/setting"
 	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestUploadToS3(t *testing.T) {
@@ -24,3 +32,89 @@ func TestUploadToS3(t *testing.T) {
 		So(path, ShouldNotEqual, "")
 	})
 }
+
+func newTestS3Uploader(t *testing.T, handler http.HandlerFunc) *S3Uploader {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return NewS3Uploader(server.URL, "us-east-1", "grafana", "images/", "public-read", "access", "secret", true)
+}
+
+func TestS3UploaderUpload(t *testing.T) {
+	t.Run("Should upload a file with the content type of its extension", func(t *testing.T) {
+		var key, contentType string
+		uploader := newTestS3Uploader(t, func(w http.ResponseWriter, r *http.Request) {
+			key, contentType = r.URL.Path, r.Header.Get("Content-Type")
+		})
+
+		path := filepath.Join(t.TempDir(), "report.pdf")
+		require.NoError(t, ioutil.WriteFile(path, []byte("%PDF-1.4"), 0600))
+
+		_, err := uploader.Upload(context.Background(), path)
+		require.NoError(t, err)
+		assert.Regexp(t, `^/grafana/images/\w{20}\.pdf$`, key)
+		assert.Equal(t, "application/pdf", contentType)
+	})
+
+	t.Run("Should upload a file larger than the part size in parts", func(t *testing.T) {
+		var mtx sync.Mutex
+		var parts []string
+		uploader := newTestS3Uploader(t, func(w http.ResponseWriter, r *http.Request) {
+			query := r.URL.Query()
+			_, initiate := query["uploads"]
+			switch {
+			case r.Method == http.MethodPost && initiate:
+				_, _ = w.Write([]byte(`<InitiateMultipartUploadResult><UploadId>upload</UploadId></InitiateMultipartUploadResult>`))
+			case r.Method == http.MethodPut && query.Get("uploadId") == "upload":
+				mtx.Lock()
+				parts = append(parts, query.Get("partNumber"))
+				mtx.Unlock()
+				w.Header().Set("ETag", `"etag"`)
+			case r.Method == http.MethodPost && query.Get("uploadId") == "upload":
+				_, _ = w.Write([]byte(`<CompleteMultipartUploadResult></CompleteMultipartUploadResult>`))
+			default:
+				w.WriteHeader(http.StatusBadRequest)
+			}
+		})
+
+		path := filepath.Join(t.TempDir(), "report.pdf")
+		require.NoError(t, ioutil.WriteFile(path, make([]byte, 2*uploader.partSize+1), 0600))
+
+		location, err := uploader.Upload(context.Background(), path)
+		require.NoError(t, err)
+		assert.Regexp(t, `/grafana/images/\w{20}\.pdf$`, location)
+		assert.ElementsMatch(t, []string{"1", "2", "3"}, parts)
+	})
+}
+
+func TestS3UploaderValidate(t *testing.T) {
+	t.Run("Should succeed when the bucket exists", func(t *testing.T) {
+		uploader := newTestS3Uploader(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				_, _ = w.Write([]byte(`<ListBucketResult><KeyCount>1</KeyCount></ListBucketResult>`))
+			}
+		})
+		require.NoError(t, uploader.Validate(context.Background()))
+	})
+
+	for status, message := range map[int]string{
+		http.StatusNotFound:  "create it or fix the bucket",
+		http.StatusForbidden: "check the access_key and secret_key",
+	} {
+		t.Run(fmt.Sprintf("Should tell the settings to fix for status %d", status), func(t *testing.T) {
+			uploader := newTestS3Uploader(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(status)
+			})
+			err := uploader.Validate(context.Background())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), message)
+		})
+	}
+
+	t.Run("Should suggest the path-style access for an unreachable custom endpoint", func(t *testing.T) {
+		uploader := NewS3Uploader("http://127.0.0.1:1", "us-east-1", "grafana", "", "public-read", "access", "secret", false)
+		err := uploader.Validate(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "path_style_access = true")
+	})
+}
@@ -0,0 +1,172 @@
+package querylint
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// counterSuffixes are the suffixes of the names of the counters, following
+// the naming conventions of Prometheus.
+var counterSuffixes = []string{"_total", "_count", "_sum", "_bucket"}
+
+// counterFunctions are the functions which counters are used with as is,
+// since they compute the rate of the counters or don't use their values.
+var counterFunctions = map[string]bool{
+	"rate":             true,
+	"irate":            true,
+	"increase":         true,
+	"resets":           true,
+	"absent":           true,
+	"absent_over_time": true,
+	"timestamp":        true,
+}
+
+var (
+	// quotedString matches the strings of the expressions, e.g. the values of
+	// labels, in which the variables are valid.
+	quotedString = regexp.MustCompile(`"(\\.|[^"\\])*"|'(\\.|[^'\\])*'|` + "`[^`]*`")
+	// legacyVariable matches the [[variable]] syntax, before the ranges are
+	// replaced.
+	legacyVariable = regexp.MustCompile(`\[\[\w+(:\w+)?\]\]`)
+	// variable matches the $variable and ${variable} syntaxes.
+	variable = regexp.MustCompile(`\$(\w+|\{\w+(:\w+)?\})`)
+	// durationPosition matches the ranges, subqueries and offsets, in which
+	// the variables are durations.
+	durationPosition = regexp.MustCompile(`\[[^\]]*\]|offset\s+\$(\w+|\{\w+\})`)
+)
+
+// LintPromQL returns the warnings about a PromQL expression. Expressions which
+// don't parse have no warnings, the errors are returned when they are run.
+func LintPromQL(expr string) []Warning {
+	warnings := []Warning{}
+
+	parsed, err := parser.ParseExpr(ReplaceVariables(expr))
+	if err != nil {
+		return warnings
+	}
+
+	seen := map[Warning]bool{}
+	add := func(rule, format string, args ...interface{}) {
+		warning := Warning{Rule: rule, Message: fmt.Sprintf(format, args...)}
+		if !seen[warning] {
+			seen[warning] = true
+			warnings = append(warnings, warning)
+		}
+	}
+
+	parser.Inspect(parsed, func(node parser.Node, path []parser.Node) error {
+		selector, ok := node.(*parser.VectorSelector)
+		if !ok {
+			return nil
+		}
+
+		name := metricName(selector)
+		if isCounter(name) && !inCounterFunction(path) {
+			add(CounterWithoutRate, "%s is a counter, which only ever increases, use rate() or increase() to get how "+
+				"much it increased", name)
+		}
+
+		if !hasMetricMatcher(selector) {
+			add(UnboundedSelector, "%s selects the series of any metric, add the name of the metric", selector)
+		}
+		for _, matcher := range selector.LabelMatchers {
+			if matchesAnyValue(matcher) {
+				add(UnboundedSelector, "%s selects the series of every value of the label %q, which can be a lot "+
+					"of series, filter it by the values of the label or aggregate it", selector, matcher.Name)
+			}
+		}
+		return nil
+	})
+
+	return warnings
+}
+
+// ReplaceVariables replaces the template variables out of the strings of a
+// PromQL expression, so that it parses: the variables in ranges and offsets
+// with a duration, the others with the name of a metric, which is valid where
+// metrics and numbers are.
+func ReplaceVariables(expr string) string {
+	var b strings.Builder
+	replace := func(part string) {
+		part = legacyVariable.ReplaceAllString(part, "grafana_variable")
+		part = durationPosition.ReplaceAllStringFunc(part, func(position string) string {
+			return variable.ReplaceAllString(position, "1m")
+		})
+		b.WriteString(variable.ReplaceAllString(part, "grafana_variable"))
+	}
+
+	last := 0
+	for _, loc := range quotedString.FindAllStringIndex(expr, -1) {
+		replace(expr[last:loc[0]])
+		b.WriteString(expr[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	replace(expr[last:])
+	return b.String()
+}
+
+// metricName returns the name of the metric of a selector, empty if it selects
+// any metric.
+func metricName(selector *parser.VectorSelector) string {
+	if selector.Name != "" {
+		return selector.Name
+	}
+	for _, matcher := range selector.LabelMatchers {
+		if matcher.Name == labels.MetricName && matcher.Type == labels.MatchEqual {
+			return matcher.Value
+		}
+	}
+	return ""
+}
+
+// hasMetricMatcher returns whether a selector selects metrics by their name.
+func hasMetricMatcher(selector *parser.VectorSelector) bool {
+	for _, matcher := range selector.LabelMatchers {
+		if matcher.Name == labels.MetricName {
+			return true
+		}
+	}
+	return false
+}
+
+func isCounter(name string) bool {
+	for _, suffix := range counterSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// inCounterFunction returns whether a selector is in a function or an
+// aggregation which counters are used with as is.
+func inCounterFunction(path []parser.Node) bool {
+	for _, node := range path {
+		switch n := node.(type) {
+		case *parser.Call:
+			if counterFunctions[n.Func.Name] {
+				return true
+			}
+		case *parser.AggregateExpr:
+			if n.Op == parser.COUNT || n.Op == parser.GROUP {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchesAnyValue(matcher *labels.Matcher) bool {
+	switch matcher.Type {
+	case labels.MatchRegexp:
+		return matcher.Value == ".*" || matcher.Value == ".+"
+	case labels.MatchNotEqual:
+		return matcher.Value == ""
+	default:
+		return false
+	}
+}
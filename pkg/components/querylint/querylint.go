@@ -0,0 +1,53 @@
+// Package querylint statically analyzes the queries of data sources for
+// patterns which are known to be slow or wrong, e.g. counters graphed without
+// rate(), and returns warnings about them. The queries are analyzed before
+// the template variables are interpolated, without running them.
+package querylint
+
+import (
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// Rules of the warnings.
+const (
+	// CounterWithoutRate warns about counters, which only ever increase, used
+	// without rate() or increase().
+	CounterWithoutRate = "counter-without-rate"
+	// UnboundedSelector warns about selectors matching any value of a label or
+	// any metric, which select an unbounded number of series.
+	UnboundedSelector = "unbounded-selector"
+	// SelectStarWithoutTimeFilter warns about SELECT * queries which aren't
+	// filtered by the time range of the dashboard.
+	SelectStarWithoutTimeFilter = "select-star-without-time-filter"
+)
+
+// Warning is a warning about a query.
+type Warning struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// sqlDatasourceTypes are the types of the data sources with SQL queries.
+var sqlDatasourceTypes = map[string]bool{
+	"mysql":    true,
+	"postgres": true,
+	"mssql":    true,
+}
+
+// Lint returns the warnings about a query of a data source of the type. The
+// query is analyzed by its properties when the type is empty, e.g. for the
+// queries of the panels of dashboard files, which only name their data
+// sources.
+func Lint(datasourceType string, query *simplejson.Json) []Warning {
+	expr := query.Get("expr").MustString()
+	rawSQL := query.Get("rawSql").MustString()
+
+	switch {
+	case datasourceType == "prometheus" || datasourceType == "" && expr != "":
+		return LintPromQL(expr)
+	case sqlDatasourceTypes[datasourceType] || datasourceType == "" && rawSQL != "":
+		return LintSQL(rawSQL)
+	default:
+		return []Warning{}
+	}
+}
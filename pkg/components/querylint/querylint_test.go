@@ -0,0 +1,109 @@
+package querylint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func rules(warnings []Warning) []string {
+	result := []string{}
+	for _, warning := range warnings {
+		result = append(result, warning.Rule)
+	}
+	return result
+}
+
+func TestLintPromQL(t *testing.T) {
+	tcs := []struct {
+		name  string
+		expr  string
+		rules []string
+	}{
+		{name: "rate of counter", expr: `sum(rate(http_requests_total{job="api"}[5m])) by (code)`, rules: []string{}},
+		{name: "counter without rate", expr: `sum(http_requests_total{job="api"})`, rules: []string{CounterWithoutRate}},
+		{name: "counted counter", expr: `count(http_requests_total{job="api"})`, rules: []string{}},
+		{
+			name:  "histogram without rate",
+			expr:  `histogram_quantile(0.99, sum(request_duration_seconds_bucket{job="api"}) by (le))`,
+			rules: []string{CounterWithoutRate},
+		},
+		{name: "gauge", expr: `node_memory_MemAvailable_bytes{instance="host:9100"}`, rules: []string{}},
+		{name: "any metric", expr: `{job="api"}`, rules: []string{UnboundedSelector}},
+		{name: "any value of label", expr: `up{job=~".*"}`, rules: []string{UnboundedSelector}},
+		{name: "any non empty value of label", expr: `up{instance!=""}`, rules: []string{UnboundedSelector}},
+		{
+			name:  "variables",
+			expr:  `rate(http_requests_total{job=~"$job", instance=~"[[instance]]"}[$__rate_interval]) > $threshold`,
+			rules: []string{},
+		},
+		{
+			name:  "variables in subqueries and offsets",
+			expr:  `max_over_time(rate(http_requests_total{job="api"}[${interval}])[$__range:$__interval] offset $shift)`,
+			rules: []string{},
+		},
+		{
+			// the variables are replaced so that the expressions parse
+			name:  "counter with variables",
+			expr:  `max_over_time(http_requests_total{job="$job"}[$__range:$__interval] offset $shift) * [[factor]]`,
+			rules: []string{CounterWithoutRate},
+		},
+		{name: "invalid", expr: `sum(rate(`, rules: []string{}},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.rules, rules(LintPromQL(tc.expr)))
+		})
+	}
+
+	t.Run("warnings explain how to fix the query", func(t *testing.T) {
+		require.Equal(t, []Warning{{
+			Rule: UnboundedSelector,
+			Message: `up{job=~".+"} selects the series of every value of the label "job", which can be a lot of ` +
+				`series, filter it by the values of the label or aggregate it`,
+		}}, LintPromQL(`up{job=~".+"} + up{job=~".+"}`))
+	})
+}
+
+func TestLintSQL(t *testing.T) {
+	tcs := []struct {
+		name  string
+		sql   string
+		rules []string
+	}{
+		{name: "select star", sql: "SELECT * FROM orders", rules: []string{SelectStarWithoutTimeFilter}},
+		{name: "select star of table", sql: "select o.* from orders o", rules: []string{SelectStarWithoutTimeFilter}},
+		{name: "select top star", sql: "SELECT TOP 10 * FROM orders", rules: []string{SelectStarWithoutTimeFilter}},
+		{name: "select star with time filter", sql: "SELECT * FROM orders WHERE $__timeFilter(created)", rules: []string{}},
+		{name: "select star with epoch filter", sql: "SELECT * FROM orders WHERE $__unixEpochFilter(created)", rules: []string{}},
+		{name: "select columns", sql: "SELECT created AS time, total FROM orders", rules: []string{}},
+		{name: "count star", sql: "SELECT count(*) FROM orders", rules: []string{}},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.rules, rules(LintSQL(tc.sql)))
+		})
+	}
+}
+
+func TestLint(t *testing.T) {
+	promQuery := simplejson.NewFromAny(map[string]interface{}{"expr": "http_requests_total"})
+	sqlQuery := simplejson.NewFromAny(map[string]interface{}{"rawSql": "SELECT * FROM orders"})
+
+	require.Equal(t, []string{CounterWithoutRate}, rules(Lint("prometheus", promQuery)))
+	require.Equal(t, []string{SelectStarWithoutTimeFilter}, rules(Lint("postgres", sqlQuery)))
+
+	t.Run("queries are linted by their properties without data source type", func(t *testing.T) {
+		require.Equal(t, []string{CounterWithoutRate}, rules(Lint("", promQuery)))
+		require.Equal(t, []string{SelectStarWithoutTimeFilter}, rules(Lint("", sqlQuery)))
+	})
+
+	t.Run("queries of other data sources aren't linted", func(t *testing.T) {
+		require.Empty(t, Lint("loki", promQuery))
+		require.Empty(t, Lint("", simplejson.New()))
+	})
+}
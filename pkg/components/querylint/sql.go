@@ -0,0 +1,36 @@
+package querylint
+
+import (
+	"regexp"
+)
+
+var (
+	// selectStar matches SELECT *, SELECT DISTINCT * and SELECT TOP n *, of
+	// any table.
+	selectStar = regexp.MustCompile(`(?i)\bselect\s+(distinct\s+)?(top\s+\d+\s+)?(\w+\.)?\*`)
+	// timeFilterMacro matches the macros of the SQL data sources filtering by
+	// the time range of the dashboard.
+	timeFilterMacro = regexp.MustCompile(`\$__(timeFilter|timeFrom|timeTo|unixEpochFilter|unixEpochFrom|unixEpochTo|` +
+		`unixEpochNanoFilter|unixEpochNanoFrom|unixEpochNanoTo)\b`)
+)
+
+// LintSQL returns the warnings about a query of a SQL data source.
+func LintSQL(rawSQL string) []Warning {
+	warnings := []Warning{}
+
+	if selectStar.MatchString(rawSQL) && !HasTimeFilter(rawSQL) {
+		warnings = append(warnings, Warning{
+			Rule: SelectStarWithoutTimeFilter,
+			Message: "the query selects all the columns of all the rows of the table, select the columns used and " +
+				"filter the rows by the time range of the dashboard with $__timeFilter()",
+		})
+	}
+
+	return warnings
+}
+
+// HasTimeFilter returns whether a query of a SQL data source is filtered by
+// the time range of the dashboard with the macros of the data sources.
+func HasTimeFilter(rawSQL string) bool {
+	return timeFilterMacro.MatchString(rawSQL)
+}
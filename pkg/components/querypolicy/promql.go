@@ -0,0 +1,81 @@
+package querypolicy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+
+	"github.com/grafana/grafana/pkg/components/querylint"
+)
+
+// checkPromQL returns whether a PromQL expression complies with a rule of a
+// type of the PromQL queries, with the message of the violation otherwise.
+func checkPromQL(rule *Rule, expr string) (string, bool) {
+	if rule.Type != DenySelector {
+		return "", true
+	}
+
+	parsed, err := parser.ParseExpr(querylint.ReplaceVariables(expr))
+	if err != nil {
+		// the selectors of the expressions which don't parse can't be checked
+		return fmt.Sprintf("the selectors of the query can't be checked: %v", err), false
+	}
+
+	label := rule.Label
+	if label == "" {
+		label = labels.MetricName
+	}
+
+	message := ""
+	parser.Inspect(parsed, func(node parser.Node, path []parser.Node) error {
+		selector, ok := node.(*parser.VectorSelector)
+		if !ok || message != "" {
+			return nil
+		}
+
+		values, restricted := selectorValues(selector, label)
+		if !restricted {
+			message = fmt.Sprintf("%s has to select the series by the values of the label %q", selector, label)
+			return nil
+		}
+		for _, value := range values {
+			if rule.pattern.MatchString(value) {
+				message = fmt.Sprintf("%s selects the denied value %q of the label %q", selector, value, label)
+				return nil
+			}
+		}
+		return nil
+	})
+	return message, message == ""
+}
+
+// selectorValues returns the values of a label a selector selects the series
+// of, when it restricts the label to a list of values with an equality or a
+// regular expression matcher.
+func selectorValues(selector *parser.VectorSelector, label string) ([]string, bool) {
+	for _, matcher := range selector.LabelMatchers {
+		if matcher.Name != label {
+			continue
+		}
+
+		switch matcher.Type {
+		case labels.MatchEqual:
+			return []string{matcher.Value}, true
+		case labels.MatchRegexp:
+			// only the regular expressions listing values, e.g. a|b, are
+			// restricted to values which the pattern can be checked against
+			values := strings.Split(matcher.Value, "|")
+			restricted := true
+			for _, value := range values {
+				restricted = restricted && value == regexp.QuoteMeta(value)
+			}
+			if restricted {
+				return values, true
+			}
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,206 @@
+// Package querypolicy checks the queries of data sources against the rules of
+// their query policies, e.g. to deny the statements modifying the tables of a
+// shared SQL data source, before running them. The policies are the
+// queryPolicy of the JSON data of the data sources.
+package querypolicy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// Types of the rules.
+const (
+	// Deny denies the queries matching the pattern.
+	Deny = "deny"
+	// Allow allows the queries matching the pattern, the queries have to
+	// match one of the allow rules of a policy when it has some.
+	Allow = "allow"
+	// DenyStatements denies the SQL queries with the keywords of the
+	// statements, e.g. DROP, outside of strings, quoted identifiers and
+	// comments.
+	DenyStatements = "denyStatements"
+	// RequireTimeFilter denies the SQL queries which aren't filtered by the
+	// time range of the dashboard.
+	RequireTimeFilter = "requireTimeFilter"
+	// DenySelector denies the PromQL selectors which don't restrict the label,
+	// the name of the metric by default, to values not matching the pattern.
+	DenySelector = "denySelector"
+)
+
+// sqlDatasourceTypes are the types of the data sources with SQL queries.
+var sqlDatasourceTypes = map[string]bool{
+	"mysql":    true,
+	"postgres": true,
+	"mssql":    true,
+}
+
+// Rule is a rule of a query policy.
+type Rule struct {
+	Type string
+	// Pattern is the regular expression of the queries of the deny and allow
+	// rules, and of the values of the label of the denySelector rules.
+	Pattern string
+	// Statements are the statements of the denyStatements rules.
+	Statements []string
+	// Label is the label of the denySelector rules, the name of the metric
+	// when empty.
+	Label string
+	// Message replaces the message of the violations of the rule.
+	Message string
+
+	pattern    *regexp.Regexp
+	statements map[string]bool
+}
+
+// Violation is a violation of a rule by a query.
+type Violation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Policy is the query policy of a data source.
+type Policy struct {
+	Rules []*Rule
+}
+
+// New returns the query policy of the JSON data of a data source, nil when it
+// has no rules.
+func New(jsonData *simplejson.Json) (*Policy, error) {
+	if jsonData == nil {
+		return nil, nil
+	}
+	rules := jsonData.GetPath("queryPolicy", "rules").MustArray()
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	policy := &Policy{}
+	for i := range rules {
+		rule, err := newRule(jsonData.GetPath("queryPolicy", "rules").GetIndex(i))
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		policy.Rules = append(policy.Rules, rule)
+	}
+	return policy, nil
+}
+
+func newRule(json *simplejson.Json) (*Rule, error) {
+	rule := &Rule{
+		Type:    json.Get("type").MustString(),
+		Pattern: json.Get("pattern").MustString(),
+		Label:   json.Get("label").MustString(),
+		Message: json.Get("message").MustString(),
+	}
+
+	switch rule.Type {
+	case Deny, Allow, DenySelector:
+		if rule.Pattern == "" {
+			return nil, fmt.Errorf("%s rules need a pattern", rule.Type)
+		}
+		pattern, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", rule.Pattern, err)
+		}
+		rule.pattern = pattern
+	case DenyStatements:
+		rule.statements = map[string]bool{}
+		for _, statement := range json.Get("statements").MustStringArray() {
+			statement = strings.ToUpper(strings.TrimSpace(statement))
+			if statement != "" {
+				rule.Statements = append(rule.Statements, statement)
+				rule.statements[statement] = true
+			}
+		}
+		if len(rule.Statements) == 0 {
+			return nil, fmt.Errorf("%s rules need statements", rule.Type)
+		}
+	case RequireTimeFilter:
+	default:
+		return nil, fmt.Errorf("unknown type %q", rule.Type)
+	}
+	return rule, nil
+}
+
+// Check returns the violations of the rules of a policy by a query of a data
+// source of the type. The rules which don't apply to the queries of the type
+// are ignored, e.g. the SQL rules for the PromQL queries.
+func (p *Policy) Check(datasourceType string, query *simplejson.Json) []Violation {
+	switch {
+	case sqlDatasourceTypes[datasourceType]:
+		rawSQL := query.Get("rawSql").MustString()
+		return p.check(rawSQL, func(rule *Rule) (string, bool) {
+			return checkSQL(rule, rawSQL)
+		})
+	case datasourceType == "prometheus":
+		return p.CheckPromQL(query.Get("expr").MustString())
+	default:
+		return p.check(queryText(query), nil)
+	}
+}
+
+// CheckPromQL returns the violations of the rules of a policy by a PromQL
+// expression.
+func (p *Policy) CheckPromQL(expr string) []Violation {
+	return p.check(expr, func(rule *Rule) (string, bool) {
+		return checkPromQL(rule, expr)
+	})
+}
+
+// check returns the violations of the deny and allow rules by the text of a
+// query, and of the rules of the type of the query checked by checkRule.
+func (p *Policy) check(text string, checkRule func(rule *Rule) (string, bool)) []Violation {
+	violations := []Violation{}
+	add := func(rule *Rule, message string) {
+		if rule.Message != "" {
+			message = rule.Message
+		}
+		violations = append(violations, Violation{Rule: rule.Type, Message: message})
+	}
+
+	var allowRules []*Rule
+	allowed := false
+	for _, rule := range p.Rules {
+		switch rule.Type {
+		case Deny:
+			if rule.pattern.MatchString(text) {
+				add(rule, fmt.Sprintf("the query matches the denied pattern %q", rule.Pattern))
+			}
+		case Allow:
+			allowRules = append(allowRules, rule)
+			allowed = allowed || rule.pattern.MatchString(text)
+		default:
+			if checkRule == nil {
+				continue
+			}
+			if message, ok := checkRule(rule); !ok {
+				add(rule, message)
+			}
+		}
+	}
+
+	if len(allowRules) > 0 && !allowed {
+		patterns := make([]string, 0, len(allowRules))
+		for _, rule := range allowRules {
+			patterns = append(patterns, fmt.Sprintf("%q", rule.Pattern))
+		}
+		add(&Rule{Type: Allow, Message: allowRules[0].Message},
+			"the query doesn't match any of the allowed patterns "+strings.Join(patterns, ", "))
+	}
+	return violations
+}
+
+// queryText returns the text of a query of a data source of another type, by
+// the properties of the queries of the core data sources.
+func queryText(query *simplejson.Json) string {
+	for _, key := range []string{"expr", "rawSql", "query", "target"} {
+		if text := query.Get(key).MustString(); text != "" {
+			return text
+		}
+	}
+	return ""
+}
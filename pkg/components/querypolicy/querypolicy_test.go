@@ -0,0 +1,202 @@
+package querypolicy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func newPolicy(t *testing.T, rules ...map[string]interface{}) *Policy {
+	t.Helper()
+
+	list := []interface{}{}
+	for _, rule := range rules {
+		list = append(list, rule)
+	}
+	policy, err := New(simplejson.NewFromAny(map[string]interface{}{
+		"queryPolicy": map[string]interface{}{"rules": list},
+	}))
+	require.NoError(t, err)
+	return policy
+}
+
+func violatedRules(violations []Violation) []string {
+	result := []string{}
+	for _, violation := range violations {
+		result = append(result, violation.Rule)
+	}
+	return result
+}
+
+func TestNew(t *testing.T) {
+	t.Run("data sources without rules have no policy", func(t *testing.T) {
+		policy, err := New(simplejson.NewFromAny(map[string]interface{}{"maxDataAge": "30d"}))
+		require.NoError(t, err)
+		require.Nil(t, policy)
+	})
+
+	tcs := []struct {
+		rule map[string]interface{}
+		err  string
+	}{
+		{rule: map[string]interface{}{"type": "block"}, err: `rule 1: unknown type "block"`},
+		{rule: map[string]interface{}{"type": Deny}, err: "rule 1: deny rules need a pattern"},
+		{
+			rule: map[string]interface{}{"type": Allow, "pattern": "("},
+			err:  "rule 1: invalid pattern \"(\": error parsing regexp: missing closing ): `(`",
+		},
+		{
+			rule: map[string]interface{}{"type": DenyStatements, "statements": []interface{}{" "}},
+			err:  "rule 1: denyStatements rules need statements",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.err, func(t *testing.T) {
+			_, err := New(simplejson.NewFromAny(map[string]interface{}{
+				"queryPolicy": map[string]interface{}{"rules": []interface{}{tc.rule}},
+			}))
+			require.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestCheckSQL(t *testing.T) {
+	policy := newPolicy(t,
+		map[string]interface{}{"type": DenyStatements, "statements": []interface{}{"drop", "DELETE"}},
+		map[string]interface{}{"type": RequireTimeFilter},
+	)
+
+	tcs := []struct {
+		name  string
+		sql   string
+		rules []string
+	}{
+		{name: "filtered select", sql: "SELECT time, total FROM orders WHERE $__timeFilter(time)", rules: []string{}},
+		{name: "unfiltered select", sql: "SELECT time, total FROM orders", rules: []string{RequireTimeFilter}},
+		{
+			name:  "delete",
+			sql:   "delete FROM orders WHERE $__timeFilter(time)",
+			rules: []string{DenyStatements},
+		},
+		{
+			name:  "drop in another statement",
+			sql:   "SELECT 1 WHERE $__timeFilter(time); DROP TABLE orders",
+			rules: []string{DenyStatements},
+		},
+		{
+			name:  "keywords in strings, identifiers and comments",
+			sql:   `SELECT "delete", [drop], ` + "`drop`" + ` FROM orders WHERE status = 'deleted; drop' AND $__timeFilter(time) -- drop`,
+			rules: []string{},
+		},
+		{name: "keywords in names", sql: "SELECT deleted_at FROM orders WHERE $__timeFilter(deleted_at)", rules: []string{}},
+		{
+			// the backslashes don't escape the quotes, so the DROP isn't hidden in dialects where they don't either
+			name:  "backslash in string",
+			sql:   `SELECT 'a\' DROP TABLE orders WHERE $__timeFilter(time); -- '`,
+			rules: []string{DenyStatements},
+		},
+		{
+			name:  "time filter in a comment",
+			sql:   "SELECT time, total FROM orders /* WHERE $__timeFilter(time) */",
+			rules: []string{RequireTimeFilter},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			query := simplejson.NewFromAny(map[string]interface{}{"rawSql": tc.sql})
+			require.Equal(t, tc.rules, violatedRules(policy.Check("postgres", query)))
+		})
+	}
+
+	t.Run("violations explain the rule", func(t *testing.T) {
+		query := simplejson.NewFromAny(map[string]interface{}{"rawSql": "DROP TABLE orders"})
+		require.Equal(t, []Violation{
+			{Rule: DenyStatements, Message: "DROP statements are denied"},
+			{Rule: RequireTimeFilter, Message: "the query has to be filtered by the time range of the dashboard with $__timeFilter()"},
+		}, policy.Check("mysql", query))
+	})
+}
+
+func TestCheckPromQL(t *testing.T) {
+	policy := newPolicy(t,
+		map[string]interface{}{"type": DenySelector, "pattern": "^secret_"},
+		map[string]interface{}{"type": DenySelector, "label": "tenant", "pattern": "^(internal|billing)$"},
+	)
+
+	tcs := []struct {
+		name  string
+		expr  string
+		rules []string
+	}{
+		{name: "allowed", expr: `sum(rate(http_requests_total{tenant="acme"}[5m]))`, rules: []string{}},
+		{name: "denied metric", expr: `secret_tokens{tenant="acme"}`, rules: []string{DenySelector}},
+		{name: "denied metric matcher", expr: `{__name__="secret_tokens", tenant="acme"}`, rules: []string{DenySelector}},
+		{name: "denied label value", expr: `up{tenant=~"acme|billing"}`, rules: []string{DenySelector}},
+		{name: "allowed label values", expr: `up{tenant=~"acme|globex"}`, rules: []string{}},
+		{name: "any metric", expr: `{tenant="acme"}`, rules: []string{DenySelector}},
+		{name: "any label value", expr: `up{tenant=~"acme.*"}`, rules: []string{DenySelector}},
+		{name: "no label", expr: `up + on() secret_tokens{tenant="acme"}`, rules: []string{DenySelector, DenySelector}},
+		{
+			name:  "variables",
+			expr:  `rate(http_requests_total{tenant="acme"}[$__rate_interval])`,
+			rules: []string{},
+		},
+		{name: "invalid", expr: `sum(rate(`, rules: []string{DenySelector, DenySelector}},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			query := simplejson.NewFromAny(map[string]interface{}{"expr": tc.expr})
+			require.Equal(t, tc.rules, violatedRules(policy.Check("prometheus", query)))
+		})
+	}
+
+	t.Run("violations explain the rule", func(t *testing.T) {
+		require.Equal(t, []Violation{
+			{Rule: DenySelector, Message: `up has to select the series by the values of the label "tenant"`},
+		}, policy.CheckPromQL(`up`))
+		require.Equal(t, []Violation{
+			{Rule: DenySelector, Message: `secret_tokens{tenant="acme"} selects the denied value "secret_tokens" of the label "__name__"`},
+		}, policy.CheckPromQL(`secret_tokens{tenant="acme"}`))
+	})
+}
+
+func TestCheckPatterns(t *testing.T) {
+	policy := newPolicy(t,
+		map[string]interface{}{"type": Deny, "pattern": `(?i)\bpassword\b`, "message": "passwords are off limits"},
+		map[string]interface{}{"type": Allow, "pattern": `^SELECT `},
+		map[string]interface{}{"type": Allow, "pattern": `^SHOW `},
+		// the rules of the other types of queries are ignored
+		map[string]interface{}{"type": RequireTimeFilter},
+	)
+
+	tcs := []struct {
+		datasourceType string
+		query          map[string]interface{}
+		violations     []Violation
+	}{
+		{datasourceType: "influxdb", query: map[string]interface{}{"query": "SELECT mean(value) FROM cpu"}, violations: []Violation{}},
+		{datasourceType: "influxdb", query: map[string]interface{}{"query": "SHOW MEASUREMENTS"}, violations: []Violation{}},
+		{
+			datasourceType: "influxdb",
+			query:          map[string]interface{}{"query": "DROP MEASUREMENT cpu"},
+			violations: []Violation{
+				{Rule: Allow, Message: `the query doesn't match any of the allowed patterns "^SELECT ", "^SHOW "`},
+			},
+		},
+		{
+			datasourceType: "mysql",
+			query:          map[string]interface{}{"rawSql": "SELECT password FROM users WHERE $__timeFilter(created)"},
+			violations:     []Violation{{Rule: Deny, Message: "passwords are off limits"}},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.datasourceType, func(t *testing.T) {
+			require.Equal(t, tc.violations, policy.Check(tc.datasourceType, simplejson.NewFromAny(tc.query)))
+		})
+	}
+}
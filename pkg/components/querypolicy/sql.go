@@ -0,0 +1,45 @@
+package querypolicy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/querylint"
+)
+
+var (
+	// sqlNonCode matches the comments, strings and quoted identifiers of the
+	// SQL dialects of the data sources. Backslashes aren't escapes, so that a
+	// string ending with one in dialects where they are never hides code.
+	sqlNonCode = regexp.MustCompile(`--[^\n]*|/\*(?s:.*?)(\*/|$)|'[^']*('|$)|"[^"]*("|$)|` + "`[^`]*(`|$)" +
+		`|\[[^\]]*(\]|$)`)
+	sqlKeyword = regexp.MustCompile(`[A-Za-z_]\w*`)
+)
+
+// sqlCode returns a query of a SQL data source with its comments, strings and
+// quoted identifiers replaced by spaces.
+func sqlCode(rawSQL string) string {
+	return sqlNonCode.ReplaceAllString(rawSQL, " ")
+}
+
+// checkSQL returns whether a query of a SQL data source complies with a rule
+// of a type of the SQL queries, with the message of the violation otherwise.
+func checkSQL(rule *Rule, rawSQL string) (string, bool) {
+	code := sqlCode(rawSQL)
+
+	switch rule.Type {
+	case DenyStatements:
+		for _, keyword := range sqlKeyword.FindAllString(code, -1) {
+			// the macros, e.g. $__timeFilter(), aren't keywords
+			if rule.statements[strings.ToUpper(keyword)] && !strings.HasPrefix(keyword, "__") {
+				return fmt.Sprintf("%s statements are denied", strings.ToUpper(keyword)), false
+			}
+		}
+	case RequireTimeFilter:
+		if !querylint.HasTimeFilter(code) {
+			return "the query has to be filtered by the time range of the dashboard with $__timeFilter()", false
+		}
+	}
+	return "", true
+}
@@ -29,12 +29,20 @@ func (j *Json) FromDB(data []byte) error {
 	return dec.Decode(&j.data)
 }
 
+// ToDB returns the minified canonical form of the JSON stored in the database: compact, with the keys of the maps
+// sorted, and without the escaping of the HTML characters, which would grow the large dashboards.
 func (j *Json) ToDB() ([]byte, error) {
 	if j == nil || j.data == nil {
 		return nil, nil
 	}
 
-	return j.Encode()
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(&j.data); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
 }
 
 // NewJson returns a pointer to a new `Json` object
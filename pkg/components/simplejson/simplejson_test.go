@@ -246,3 +246,15 @@ func TestPathWillOverwriteExisting(t *testing.T) {
 	assert.Equal(t, nil, err)
 	assert.Equal(t, "bar", s)
 }
+
+func TestToDB(t *testing.T) {
+	js, err := NewJson([]byte(`{
+		"title": "<b>A & B</b>",
+		"panels": [{"id": 1}]
+	}`))
+	assert.NoError(t, err)
+
+	data, err := js.ToDB()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"panels":[{"id":1}],"title":"<b>A & B</b>"}`, string(data))
+}
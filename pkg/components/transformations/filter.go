@@ -0,0 +1,191 @@
+package transformations
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const (
+	filterInclude = "include"
+	filterExclude = "exclude"
+	matchAny      = "any"
+	matchAll      = "all"
+)
+
+// filterByValueOptions includes or excludes the rows matching any or all of
+// the filters.
+type filterByValueOptions struct {
+	Filters []valueFilter `json:"filters"`
+	Type    string        `json:"type"`
+	Match   string        `json:"match"`
+}
+
+// valueFilter matches the values of the field of a display name with a value
+// matcher of the frontend.
+type valueFilter struct {
+	FieldName string `json:"fieldName"`
+	Config    struct {
+		ID      string `json:"id"`
+		Options struct {
+			Value interface{} `json:"value"`
+			From  interface{} `json:"from"`
+			To    interface{} `json:"to"`
+		} `json:"options"`
+	} `json:"config"`
+}
+
+type valueMatcher func(value interface{}, null bool) bool
+
+func (o filterByValueOptions) transformer() (transformer, error) {
+	if o.Type != filterInclude && o.Type != filterExclude {
+		return nil, fmt.Errorf("invalid filter type %q, must be %s or %s", o.Type, filterInclude, filterExclude)
+	}
+	if o.Match != matchAny && o.Match != matchAll {
+		return nil, fmt.Errorf("invalid filter match %q, must be %s or %s", o.Match, matchAny, matchAll)
+	}
+
+	matchers := make([]valueMatcher, 0, len(o.Filters))
+	for _, filter := range o.Filters {
+		matcher, err := filter.matcher()
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, matcher)
+	}
+
+	return func(frames data.Frames) (data.Frames, error) {
+		if len(o.Filters) == 0 {
+			return frames, nil
+		}
+
+		result := make(data.Frames, 0, len(frames))
+		for _, frame := range frames {
+			result = append(result, o.filter(frame, matchers))
+		}
+		return result, nil
+	}, nil
+}
+
+func (o filterByValueOptions) filter(frame *data.Frame, matchers []valueMatcher) *data.Frame {
+	fieldsByName := make(map[string]*data.Field, len(frame.Fields))
+	for _, field := range frame.Fields {
+		fieldsByName[displayName(field)] = field
+	}
+
+	fields := make([]*data.Field, 0, len(frame.Fields))
+	for _, field := range frame.Fields {
+		fields = append(fields, emptyCopy(field, false))
+	}
+
+	rowLen, _ := frame.RowLen()
+	for row := 0; row < rowLen; row++ {
+		matching := o.Match == matchAll
+		for i, filter := range o.Filters {
+			match := false
+			// filters of fields missing in the frame never match, like in panels
+			if field, ok := fieldsByName[filter.FieldName]; ok {
+				value, ok := field.ConcreteAt(row)
+				match = matchers[i](value, !ok)
+			}
+
+			if o.Match == matchAll && !match {
+				matching = false
+				break
+			}
+			if o.Match == matchAny && match {
+				matching = true
+				break
+			}
+		}
+
+		if matching == (o.Type == filterInclude) {
+			for i, field := range frame.Fields {
+				fields[i].Append(field.CopyAt(row))
+			}
+		}
+	}
+
+	return copyFrame(frame, fields)
+}
+
+func (f valueFilter) matcher() (valueMatcher, error) {
+	opts := f.Config.Options
+	compare := func(test func(value, option float64) bool) (valueMatcher, error) {
+		option, ok := optionFloat(opts.Value)
+		if !ok {
+			return nil, fmt.Errorf("filter of field %q: %s needs a numeric value", f.FieldName, f.Config.ID)
+		}
+		return func(value interface{}, null bool) bool {
+			v, ok := toFloat(value)
+			return !null && ok && test(v, option)
+		}, nil
+	}
+
+	switch f.Config.ID {
+	case "isNull":
+		return func(_ interface{}, null bool) bool { return null }, nil
+	case "isNotNull":
+		return func(_ interface{}, null bool) bool { return !null }, nil
+	case "greater":
+		return compare(func(v, option float64) bool { return v > option })
+	case "greaterOrEqual":
+		return compare(func(v, option float64) bool { return v >= option })
+	case "lower":
+		return compare(func(v, option float64) bool { return v < option })
+	case "lowerOrEqual":
+		return compare(func(v, option float64) bool { return v <= option })
+	case "equal":
+		return func(value interface{}, null bool) bool { return !null && equal(value, opts.Value) }, nil
+	case "notEqual":
+		return func(value interface{}, null bool) bool { return null || !equal(value, opts.Value) }, nil
+	case "between":
+		from, fromOK := optionFloat(opts.From)
+		to, toOK := optionFloat(opts.To)
+		if !fromOK || !toOK {
+			return nil, fmt.Errorf("filter of field %q: between needs numeric from and to values", f.FieldName)
+		}
+		return func(value interface{}, null bool) bool {
+			v, ok := toFloat(value)
+			return !null && ok && v > from && v < to
+		}, nil
+	case "regex":
+		pattern, _ := opts.Value.(string)
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("filter of field %q: invalid regex: %w", f.FieldName, err)
+		}
+		return func(value interface{}, null bool) bool {
+			return !null && re.MatchString(fmt.Sprint(value))
+		}, nil
+	default:
+		return nil, fmt.Errorf("filter of field %q: unsupported matcher %q", f.FieldName, f.Config.ID)
+	}
+}
+
+// optionFloat returns the number of an option, which the editor may save as
+// a string.
+func optionFloat(option interface{}) (float64, bool) {
+	switch v := option.(type) {
+	case float64:
+		return v, true
+	case string:
+		value, err := strconv.ParseFloat(v, 64)
+		return value, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// equal compares the value of a field with the one of an option, numerically
+// when both are numbers.
+func equal(value interface{}, option interface{}) bool {
+	if v, ok := toFloat(value); ok {
+		if o, ok := optionFloat(option); ok {
+			return v == o
+		}
+	}
+	return fmt.Sprint(value) == fmt.Sprint(option)
+}
@@ -0,0 +1,330 @@
+package transformations
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const (
+	operationGroupBy   = "groupby"
+	operationAggregate = "aggregate"
+)
+
+// groupByOptions groups the rows of every frame by the values of the fields
+// with the groupby operation, and calculates the aggregations of the fields
+// with the aggregate operation for every group, by display name.
+type groupByOptions struct {
+	Fields map[string]groupByFieldOptions `json:"fields"`
+}
+
+type groupByFieldOptions struct {
+	Aggregations []string `json:"aggregations"`
+	Operation    string   `json:"operation"`
+}
+
+// reducer calculates an aggregation of the values of the rows of a field.
+// The reducers are the calculations of the frontend, by ID.
+type reducer struct {
+	// name is the name of the calculation in the frontend
+	name string
+	// sameType is whether the aggregation is a value of the field, its type is
+	// a nullable float otherwise
+	sameType bool
+	reduce   func(field *data.Field, rows []int) interface{}
+}
+
+var reducers = map[string]reducer{
+	"sum":           {name: "Total", reduce: numericReducer(sum)},
+	"mean":          {name: "Mean", reduce: numericReducer(mean)},
+	"min":           {name: "Min", reduce: numericReducer(minimum)},
+	"max":           {name: "Max", reduce: numericReducer(maximum)},
+	"range":         {name: "Range", reduce: numericReducer(valuesRange)},
+	"delta":         {name: "Delta", reduce: numericReducer(delta)},
+	"step":          {name: "Step", reduce: numericReducer(step)},
+	"diff":          {name: "Difference", reduce: numericReducer(difference)},
+	"diffperc":      {name: "Difference percent", reduce: numericReducer(differencePercent)},
+	"count":         {name: "Count", reduce: count},
+	"distinctCount": {name: "Distinct count", reduce: distinctCount},
+	"changeCount":   {name: "Change count", reduce: changeCount},
+	"first":         {name: "First", sameType: true, reduce: valueAt(false, false)},
+	"firstNotNull":  {name: "First *", sameType: true, reduce: valueAt(false, true)},
+	"last":          {name: "Last", sameType: true, reduce: valueAt(true, false)},
+	"lastNotNull":   {name: "Last *", sameType: true, reduce: valueAt(true, true)},
+}
+
+func (o groupByOptions) transformer() (transformer, error) {
+	grouped := false
+	for name, field := range o.Fields {
+		switch field.Operation {
+		case operationGroupBy:
+			grouped = true
+		case operationAggregate:
+			for _, aggregation := range field.Aggregations {
+				if _, ok := reducers[aggregation]; !ok {
+					return nil, fmt.Errorf("field %q: unsupported aggregation %q", name, aggregation)
+				}
+			}
+		}
+	}
+
+	return func(frames data.Frames) (data.Frames, error) {
+		if !grouped {
+			return frames, nil
+		}
+
+		result := make(data.Frames, 0, len(frames))
+		for _, frame := range frames {
+			// frames without the fields to group by are left out, like in panels
+			if grouped := o.group(frame); grouped != nil {
+				result = append(result, grouped)
+			}
+		}
+		return result, nil
+	}, nil
+}
+
+func (o groupByOptions) group(frame *data.Frame) *data.Frame {
+	var groupFields []*data.Field
+	for _, field := range frame.Fields {
+		if o.Fields[displayName(field)].Operation == operationGroupBy {
+			groupFields = append(groupFields, field)
+		}
+	}
+	if len(groupFields) == 0 {
+		return nil
+	}
+
+	// the rows of the groups, in the order the groups first appear
+	var keys []string
+	groups := map[string][]int{}
+	rowLen, _ := frame.RowLen()
+	for row := 0; row < rowLen; row++ {
+		values := make([]string, 0, len(groupFields))
+		for _, field := range groupFields {
+			value, _ := field.ConcreteAt(row)
+			values = append(values, fmt.Sprint(value))
+		}
+
+		key := strings.Join(values, "\x00")
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], row)
+	}
+
+	fields := make([]*data.Field, 0, len(frame.Fields))
+	for _, field := range groupFields {
+		result := emptyCopy(field, false)
+		for _, key := range keys {
+			result.Append(field.CopyAt(groups[key][0]))
+		}
+		fields = append(fields, result)
+	}
+
+	for _, field := range frame.Fields {
+		name := displayName(field)
+		opts := o.Fields[name]
+		if opts.Operation != operationAggregate {
+			continue
+		}
+
+		for _, aggregation := range opts.Aggregations {
+			r := reducers[aggregation]
+
+			var result *data.Field
+			if r.sameType {
+				result = data.NewFieldFromFieldType(field.Type().NullableType(), len(keys))
+			} else {
+				result = data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, len(keys))
+			}
+			result.Name = fmt.Sprintf("%s (%s)", name, aggregation)
+
+			for i, key := range keys {
+				if value := r.reduce(field, groups[key]); value != nil {
+					result.SetConcrete(i, value)
+				}
+			}
+			fields = append(fields, result)
+		}
+	}
+
+	return copyFrame(frame, fields)
+}
+
+func numericReducer(reduce func(values []float64) *float64) func(field *data.Field, rows []int) interface{} {
+	return func(field *data.Field, rows []int) interface{} {
+		values := make([]float64, 0, len(rows))
+		for _, row := range rows {
+			value, ok := field.ConcreteAt(row)
+			if !ok {
+				continue
+			}
+			if v, ok := toFloat(value); ok {
+				values = append(values, v)
+			}
+		}
+
+		if result := reduce(values); result != nil {
+			return *result
+		}
+		return nil
+	}
+}
+
+func sum(values []float64) *float64 {
+	var result float64
+	for _, v := range values {
+		result += v
+	}
+	return &result
+}
+
+func mean(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	result := *sum(values) / float64(len(values))
+	return &result
+}
+
+func minimum(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	result := values[0]
+	for _, v := range values[1:] {
+		if v < result {
+			result = v
+		}
+	}
+	return &result
+}
+
+func maximum(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	result := values[0]
+	for _, v := range values[1:] {
+		if v > result {
+			result = v
+		}
+	}
+	return &result
+}
+
+func valuesRange(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	result := *maximum(values) - *minimum(values)
+	return &result
+}
+
+// delta returns the sum of the increases of the values, like the increase of
+// a counter. A decrease is a reset of the counter, after which the value is
+// the increase.
+func delta(values []float64) *float64 {
+	var result float64
+	up := true
+	for i := 1; i < len(values); i++ {
+		previous, current := values[i-1], values[i]
+		if current < previous {
+			up = false
+			if i == len(values)-1 {
+				result += current
+			}
+			continue
+		}
+
+		if up {
+			result += current - previous
+		} else {
+			result += current
+		}
+		up = true
+	}
+	return &result
+}
+
+// step returns the smallest difference between consecutive values.
+func step(values []float64) *float64 {
+	if len(values) < 2 {
+		return nil
+	}
+	result := values[1] - values[0]
+	for i := 2; i < len(values); i++ {
+		if s := values[i] - values[i-1]; s < result {
+			result = s
+		}
+	}
+	return &result
+}
+
+func difference(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	result := values[len(values)-1] - values[0]
+	return &result
+}
+
+// differencePercent returns the difference relative to the first value, as
+// a ratio like the percentunit unit.
+func differencePercent(values []float64) *float64 {
+	if len(values) == 0 || values[0] == 0 {
+		return nil
+	}
+	result := *difference(values) / values[0]
+	return &result
+}
+
+func count(_ *data.Field, rows []int) interface{} {
+	return float64(len(rows))
+}
+
+func distinctCount(field *data.Field, rows []int) interface{} {
+	distinct := map[interface{}]bool{}
+	for _, row := range rows {
+		if value, ok := field.ConcreteAt(row); ok {
+			distinct[joinKey(value)] = true
+		}
+	}
+	return float64(len(distinct))
+}
+
+// changeCount returns how many times the value changes, nulls included.
+func changeCount(field *data.Field, rows []int) interface{} {
+	var result float64
+	for i := 1; i < len(rows); i++ {
+		previous, previousOK := field.ConcreteAt(rows[i-1])
+		current, currentOK := field.ConcreteAt(rows[i])
+		if previousOK != currentOK || (currentOK && joinKey(previous) != joinKey(current)) {
+			result++
+		}
+	}
+	return result
+}
+
+// valueAt returns the reducer of the first or last value, or non-null value.
+func valueAt(last, notNull bool) func(field *data.Field, rows []int) interface{} {
+	return func(field *data.Field, rows []int) interface{} {
+		for i := range rows {
+			row := rows[i]
+			if last {
+				row = rows[len(rows)-1-i]
+			}
+
+			value, ok := field.ConcreteAt(row)
+			if ok {
+				return value
+			}
+			if !notNull {
+				return nil
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,134 @@
+package transformations
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// joinOptions outer joins the frames by the field of a display name, or by
+// the first time field of each frame when it's empty.
+type joinOptions struct {
+	ByField string `json:"byField"`
+}
+
+func (o joinOptions) transform(frames data.Frames) (data.Frames, error) {
+	if len(frames) < 2 {
+		return frames, nil
+	}
+
+	type joined struct {
+		frame *data.Frame
+		key   int
+	}
+	var toJoin []joined
+	for _, frame := range frames {
+		// frames without the field are left out of the join, like in panels
+		if key := o.keyField(frame); key >= 0 {
+			toJoin = append(toJoin, joined{frame: frame, key: key})
+		}
+	}
+	if len(toJoin) == 0 {
+		return frames, nil
+	}
+
+	keyType := toJoin[0].frame.Fields[toJoin[0].key].Type().NonNullableType()
+	keys := map[interface{}]interface{}{}
+	for _, j := range toJoin {
+		field := j.frame.Fields[j.key]
+		if field.Type().NonNullableType() != keyType {
+			return nil, fmt.Errorf("the fields to join by have different types, %s and %s", keyType,
+				field.Type().NonNullableType())
+		}
+
+		for row := 0; row < field.Len(); row++ {
+			if value, ok := field.ConcreteAt(row); ok {
+				keys[joinKey(value)] = value
+			}
+		}
+	}
+
+	values := make([]interface{}, 0, len(keys))
+	for _, value := range keys {
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return less(values[i], values[j])
+	})
+
+	keyField := emptyCopy(toJoin[0].frame.Fields[toJoin[0].key], false)
+	keyField.Extend(len(values))
+	rows := make(map[interface{}]int, len(values))
+	for row, value := range values {
+		keyField.SetConcrete(row, value)
+		rows[joinKey(value)] = row
+	}
+
+	fields := []*data.Field{keyField}
+	for _, j := range toJoin {
+		key := j.frame.Fields[j.key]
+		for i, field := range j.frame.Fields {
+			if i == j.key {
+				continue
+			}
+
+			result := emptyCopy(field, true)
+			result.Extend(len(values))
+			for row := 0; row < field.Len() && row < key.Len(); row++ {
+				keyValue, ok := key.ConcreteAt(row)
+				if !ok {
+					continue
+				}
+				if value, ok := field.ConcreteAt(row); ok {
+					result.SetConcrete(rows[joinKey(keyValue)], value)
+				}
+			}
+			fields = append(fields, result)
+		}
+	}
+
+	frame := data.NewFrame("", fields...)
+	frame.RefID = toJoin[0].frame.RefID
+	return data.Frames{frame}, nil
+}
+
+// keyField returns the index of the field to join the frame by, -1 when the
+// frame has none.
+func (o joinOptions) keyField(frame *data.Frame) int {
+	for i, field := range frame.Fields {
+		if o.ByField != "" && displayName(field) == o.ByField {
+			return i
+		}
+		if o.ByField == "" && field.Type().Time() {
+			return i
+		}
+	}
+	return -1
+}
+
+// joinKey returns the value identifying the rows of a key, since times with
+// the same instant may not be equal.
+func joinKey(value interface{}) interface{} {
+	if t, ok := value.(time.Time); ok {
+		return t.UnixNano()
+	}
+	return value
+}
+
+func less(a, b interface{}) bool {
+	if x, ok := toFloat(a); ok {
+		if y, ok := toFloat(b); ok {
+			if x != y {
+				return x < y
+			}
+		}
+	}
+	if ta, ok := a.(time.Time); ok {
+		if tb, ok := b.(time.Time); ok {
+			return ta.Before(tb)
+		}
+	}
+	return fmt.Sprint(a) < fmt.Sprint(b)
+}
@@ -0,0 +1,58 @@
+package transformations
+
+import (
+	"sort"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// organizeOptions excludes, orders and renames fields by their display name.
+type organizeOptions struct {
+	ExcludeByName map[string]bool   `json:"excludeByName"`
+	IndexByName   map[string]int    `json:"indexByName"`
+	RenameByName  map[string]string `json:"renameByName"`
+}
+
+func (o organizeOptions) transform(frames data.Frames) (data.Frames, error) {
+	result := make(data.Frames, 0, len(frames))
+	for _, frame := range frames {
+		fields := make([]*data.Field, 0, len(frame.Fields))
+		for _, field := range frame.Fields {
+			if !o.ExcludeByName[displayName(field)] {
+				fields = append(fields, field)
+			}
+		}
+
+		// fields without an index are kept in their order after the others
+		index := func(field *data.Field) int {
+			if i, ok := o.IndexByName[displayName(field)]; ok {
+				return i
+			}
+			return len(o.IndexByName) + len(frame.Fields)
+		}
+		sort.SliceStable(fields, func(i, j int) bool {
+			return index(fields[i]) < index(fields[j])
+		})
+
+		for i, field := range fields {
+			name, ok := o.RenameByName[displayName(field)]
+			if !ok || name == "" {
+				continue
+			}
+
+			// the field is renamed with its display name, like in panels
+			renamed := *field
+			config := data.FieldConfig{}
+			if field.Config != nil {
+				config = *field.Config
+			}
+			config.DisplayName = name
+			renamed.Config = &config
+			fields[i] = &renamed
+		}
+
+		result = append(result, copyFrame(frame, fields))
+	}
+
+	return result, nil
+}
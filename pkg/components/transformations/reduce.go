@@ -0,0 +1,243 @@
+package transformations
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const (
+	reduceSeriesToRows = "seriesToRows"
+	reduceFields       = "reduceFields"
+
+	// defaultReduceCalc and defaultReduceLimit are the defaults of the reduce
+	// options of the stat and gauge panels.
+	defaultReduceCalc  = "lastNotNull"
+	defaultReduceLimit = 25
+)
+
+// reduceOptions reduces the numeric fields of the frames to the calculations
+// of the reducers. In the seriesToRows mode, the frames are reduced to a table
+// with a row per field and a column per reducer. In the reduceFields mode,
+// every frame is reduced to a single row.
+type reduceOptions struct {
+	Reducers         []string `json:"reducers"`
+	Mode             string   `json:"mode"`
+	IncludeTimeField bool     `json:"includeTimeField"`
+}
+
+func (o reduceOptions) transformer() (transformer, error) {
+	if err := checkReducers(o.Reducers); err != nil {
+		return nil, err
+	}
+
+	switch o.Mode {
+	case reduceSeriesToRows:
+		return o.seriesToRows, nil
+	case reduceFields:
+		return o.reduceFields, nil
+	default:
+		return nil, fmt.Errorf("invalid reduce mode %q, must be %s or %s", o.Mode, reduceSeriesToRows, reduceFields)
+	}
+}
+
+func (o reduceOptions) reduced(field *data.Field) bool {
+	return field.Type().Numeric() || (o.IncludeTimeField && field.Type().Time())
+}
+
+func (o reduceOptions) seriesToRows(frames data.Frames) (data.Frames, error) {
+	if len(frames) == 0 || len(o.Reducers) == 0 {
+		return frames, nil
+	}
+
+	names := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	names.Name = "Field"
+	calcs := make([]*data.Field, 0, len(o.Reducers))
+	for _, id := range o.Reducers {
+		calc := data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, 0)
+		calc.Name = reducers[id].name
+		calcs = append(calcs, calc)
+	}
+
+	for _, frame := range frames {
+		rows := allRows(frame)
+		for _, field := range frame.Fields {
+			if !o.reduced(field) {
+				continue
+			}
+
+			names.Append(displayName(field))
+			for i, id := range o.Reducers {
+				calcs[i].Append(floatPointer(reducers[id].reduce(field, rows)))
+			}
+		}
+	}
+
+	// the table is returned in the results of the first query, like joins
+	return data.Frames{copyFrame(frames[0], append([]*data.Field{names}, calcs...))}, nil
+}
+
+func (o reduceOptions) reduceFields(frames data.Frames) (data.Frames, error) {
+	if len(o.Reducers) == 0 {
+		return frames, nil
+	}
+
+	result := make(data.Frames, 0, len(frames))
+	for _, frame := range frames {
+		rows := allRows(frame)
+		fields := make([]*data.Field, 0, len(frame.Fields)*len(o.Reducers))
+		for _, field := range frame.Fields {
+			if !o.reduced(field) {
+				continue
+			}
+
+			for _, id := range o.Reducers {
+				calc := emptyCopy(field, true)
+				if !reducers[id].sameType {
+					copied := calc
+					calc = data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, 0)
+					calc.Name, calc.Labels, calc.Config = copied.Name, copied.Labels, copied.Config
+				}
+				if len(o.Reducers) > 1 {
+					calc.Name = fmt.Sprintf("%s (%s)", field.Name, id)
+				}
+
+				calc.Extend(1)
+				if value := reducers[id].reduce(field, rows); value != nil {
+					calc.SetConcrete(0, value)
+				}
+				fields = append(fields, calc)
+			}
+		}
+		result = append(result, copyFrame(frame, fields))
+	}
+	return result, nil
+}
+
+// ReduceOptions are the reduce options of the stat, gauge and bar gauge
+// panels, the options.reduceOptions of their JSON.
+type ReduceOptions struct {
+	// Values is whether to show every value of the fields instead of
+	// calculations
+	Values bool `json:"values"`
+	// Calcs are the IDs of the calculations, lastNotNull by default
+	Calcs []string `json:"calcs"`
+	// Fields is the display name of the fields or a regular expression like
+	// /.*/, the numeric fields when empty
+	Fields string `json:"fields"`
+	// Limit is the max number of values, 25 by default
+	Limit int `json:"limit"`
+}
+
+// FieldValue is a single value of a panel, a calculation of a field or one
+// of its values.
+type FieldValue struct {
+	// Field is the display name of the field
+	Field string `json:"field"`
+	// Calc is the ID of the calculation, empty for the values of the field
+	Calc  string      `json:"calc,omitempty"`
+	Value interface{} `json:"value"`
+}
+
+// ReduceValues returns the single values of the frames shown by a stat or gauge
+// panel of the reduce options, so that reports can show them without
+// rendering the panel.
+func ReduceValues(frames data.Frames, opts ReduceOptions) ([]FieldValue, error) {
+	calcs := opts.Calcs
+	if len(calcs) == 0 {
+		calcs = []string{defaultReduceCalc}
+	}
+	if err := checkReducers(calcs); err != nil {
+		return nil, err
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = defaultReduceLimit
+	}
+	match, err := fieldMatcher(opts.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []FieldValue
+	for _, frame := range frames {
+		rows := allRows(frame)
+		for _, field := range frame.Fields {
+			if !match(field) {
+				continue
+			}
+			name := displayName(field)
+
+			if !opts.Values {
+				for _, id := range calcs {
+					values = append(values, FieldValue{Field: name, Calc: id, Value: reducers[id].reduce(field, rows)})
+				}
+				continue
+			}
+
+			for _, row := range rows {
+				if len(values) >= limit {
+					return values, nil
+				}
+				value, _ := field.ConcreteAt(row)
+				values = append(values, FieldValue{Field: name, Value: value})
+			}
+		}
+	}
+	return values, nil
+}
+
+func checkReducers(ids []string) error {
+	for _, id := range ids {
+		if _, ok := reducers[id]; !ok {
+			return fmt.Errorf("unsupported calculation %q", id)
+		}
+	}
+	return nil
+}
+
+// fieldMatcher returns the matcher of the fields option of the reduce
+// options, the numeric fields when empty, a regular expression between
+// slashes or a display name.
+func fieldMatcher(fields string) (func(field *data.Field) bool, error) {
+	switch {
+	case fields == "":
+		return func(field *data.Field) bool {
+			return field.Type().Numeric()
+		}, nil
+	case len(fields) > 1 && strings.HasPrefix(fields, "/") && strings.HasSuffix(fields, "/"):
+		re, err := regexp.Compile(fields[1 : len(fields)-1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid fields %q: %w", fields, err)
+		}
+		return func(field *data.Field) bool {
+			return re.MatchString(displayName(field))
+		}, nil
+	default:
+		return func(field *data.Field) bool {
+			return displayName(field) == fields
+		}, nil
+	}
+}
+
+func allRows(frame *data.Frame) []int {
+	rowLen, _ := frame.RowLen()
+	rows := make([]int, rowLen)
+	for i := range rows {
+		rows[i] = i
+	}
+	return rows
+}
+
+// floatPointer returns a calculation as a nullable float.
+func floatPointer(value interface{}) *float64 {
+	if value == nil {
+		return nil
+	}
+	if f, ok := toFloat(value); ok {
+		return &f
+	}
+	return nil
+}
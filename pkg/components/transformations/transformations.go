@@ -0,0 +1,187 @@
+// Package transformations applies the transformations of panels to query
+// results on the server, so that alerting, reporting and API consumers get the
+// same shaped data that panels show. The IDs and options of the
+// transformations are the ones of the frontend.
+package transformations
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// IDs of the supported transformations.
+const (
+	Organize        = "organize"
+	FilterByValue   = "filterByValue"
+	SeriesToColumns = "seriesToColumns"
+	// JoinByField is the name of SeriesToColumns in the transformations editor.
+	JoinByField = "joinByField"
+	GroupBy     = "groupBy"
+	Reduce      = "reduce"
+)
+
+// Transformation is a transformation of frames, as saved in the
+// transformations of a panel.
+type Transformation struct {
+	ID       string          `json:"id"`
+	Options  json.RawMessage `json:"options"`
+	Disabled bool            `json:"disabled,omitempty"`
+}
+
+type transformer func(frames data.Frames) (data.Frames, error)
+
+// Apply applies the transformations to the frames in order, skipping the
+// disabled ones. The frames are transformed together, like the frames of all
+// queries of a panel, and aren't modified.
+func Apply(frames data.Frames, transformations []Transformation) (data.Frames, error) {
+	for i, t := range transformations {
+		if t.Disabled {
+			continue
+		}
+
+		transform, err := newTransformer(t)
+		if err != nil {
+			return nil, fmt.Errorf("transformation %d: %w", i, err)
+		}
+
+		if frames, err = transform(frames); err != nil {
+			return nil, fmt.Errorf("transformation %d (%s): %w", i, t.ID, err)
+		}
+	}
+
+	return frames, nil
+}
+
+func newTransformer(t Transformation) (transformer, error) {
+	switch t.ID {
+	case Organize:
+		var opts organizeOptions
+		if err := unmarshalOptions(t.Options, &opts); err != nil {
+			return nil, err
+		}
+		return opts.transform, nil
+	case FilterByValue:
+		opts := filterByValueOptions{Type: filterInclude, Match: matchAny}
+		if err := unmarshalOptions(t.Options, &opts); err != nil {
+			return nil, err
+		}
+		return opts.transformer()
+	case SeriesToColumns, JoinByField:
+		var opts joinOptions
+		if err := unmarshalOptions(t.Options, &opts); err != nil {
+			return nil, err
+		}
+		return opts.transform, nil
+	case GroupBy:
+		var opts groupByOptions
+		if err := unmarshalOptions(t.Options, &opts); err != nil {
+			return nil, err
+		}
+		return opts.transformer()
+	case Reduce:
+		opts := reduceOptions{Mode: reduceSeriesToRows}
+		if err := unmarshalOptions(t.Options, &opts); err != nil {
+			return nil, err
+		}
+		return opts.transformer()
+	default:
+		return nil, fmt.Errorf("unsupported transformation %q", t.ID)
+	}
+}
+
+func unmarshalOptions(raw json.RawMessage, opts interface{}) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, opts); err != nil {
+		return fmt.Errorf("invalid options: %w", err)
+	}
+	return nil
+}
+
+// displayName returns the name fields are referred to by in the options of
+// the transformations, the display name of their config or their name and
+// labels.
+func displayName(field *data.Field) string {
+	if field.Config != nil {
+		if field.Config.DisplayName != "" {
+			return field.Config.DisplayName
+		}
+		if field.Config.DisplayNameFromDS != "" {
+			return field.Config.DisplayNameFromDS
+		}
+	}
+
+	if len(field.Labels) > 0 {
+		return fmt.Sprintf("%s {%s}", field.Name, field.Labels)
+	}
+	return field.Name
+}
+
+// emptyCopy returns a field of the same name, labels, config and type
+// without values, or of the nullable type when nullable is true.
+func emptyCopy(field *data.Field, nullable bool) *data.Field {
+	fieldType := field.Type()
+	if nullable {
+		fieldType = fieldType.NullableType()
+	}
+
+	result := data.NewFieldFromFieldType(fieldType, 0)
+	result.Name = field.Name
+	if field.Labels != nil {
+		result.Labels = field.Labels.Copy()
+	}
+	if field.Config != nil {
+		config := *field.Config
+		result.Config = &config
+	}
+	return result
+}
+
+// copyFrame returns a frame of the same name, ref ID and meta with the fields.
+func copyFrame(frame *data.Frame, fields []*data.Field) *data.Frame {
+	result := data.NewFrame(frame.Name, fields...)
+	result.RefID = frame.RefID
+	result.Meta = frame.Meta
+	return result
+}
+
+// toFloat returns the value as a float, for numbers, times (as Unix
+// milliseconds) and booleans.
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, !math.IsNaN(v)
+	case time.Time:
+		return float64(v.UnixNano() / int64(time.Millisecond)), true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	default:
+		return 0, false
+	}
+}
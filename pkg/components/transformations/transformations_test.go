@@ -0,0 +1,273 @@
+package transformations
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func transformation(id string, options string) Transformation {
+	return Transformation{ID: id, Options: json.RawMessage(options)}
+}
+
+func TestApply(t *testing.T) {
+	t.Run("Disabled transformations are skipped", func(t *testing.T) {
+		frames := data.Frames{data.NewFrame("", data.NewField("value", nil, []float64{1}))}
+		result, err := Apply(frames, []Transformation{{ID: "unknown", Disabled: true}})
+		require.NoError(t, err)
+		assert.Equal(t, frames, result)
+	})
+
+	t.Run("Unsupported transformations fail", func(t *testing.T) {
+		_, err := Apply(nil, []Transformation{{ID: "calculateField"}})
+		require.EqualError(t, err, `transformation 0: unsupported transformation "calculateField"`)
+	})
+
+	t.Run("Invalid options fail", func(t *testing.T) {
+		_, err := Apply(nil, []Transformation{transformation(GroupBy, `{"fields": []}`)})
+		require.Error(t, err)
+	})
+}
+
+func TestOrganize(t *testing.T) {
+	frame := data.NewFrame("cpu",
+		data.NewField("time", nil, []time.Time{time.Unix(0, 0)}),
+		data.NewField("value", data.Labels{"host": "a"}, []float64{1}),
+		data.NewField("internal", nil, []string{"x"}),
+	)
+
+	result, err := Apply(data.Frames{frame}, []Transformation{transformation(Organize, `{
+		"excludeByName": {"internal": true},
+		"indexByName": {"value {host=a}": 0, "time": 1},
+		"renameByName": {"value {host=a}": "CPU"}
+	}`)})
+	require.NoError(t, err)
+
+	require.Len(t, result, 1)
+	require.Len(t, result[0].Fields, 2)
+	assert.Equal(t, "value", result[0].Fields[0].Name)
+	assert.Equal(t, "CPU", result[0].Fields[0].Config.DisplayName)
+	assert.Equal(t, "time", result[0].Fields[1].Name)
+	assert.Nil(t, frame.Fields[1].Config, "the frames of the input are not modified")
+}
+
+func TestFilterByValue(t *testing.T) {
+	frame := data.NewFrame("",
+		data.NewField("host", nil, []string{"a", "b", "c"}),
+		data.NewField("value", nil, []*float64{pointer(1), nil, pointer(10)}),
+	)
+
+	t.Run("Rows matching any filter are included", func(t *testing.T) {
+		result, err := Apply(data.Frames{frame}, []Transformation{transformation(FilterByValue, `{
+			"type": "include",
+			"match": "any",
+			"filters": [
+				{"fieldName": "value", "config": {"id": "greater", "options": {"value": 5}}},
+				{"fieldName": "host", "config": {"id": "regex", "options": {"value": "^a$"}}}
+			]
+		}`)})
+		require.NoError(t, err)
+
+		assert.Equal(t, data.NewFrame("",
+			data.NewField("host", nil, []string{"a", "c"}),
+			data.NewField("value", nil, []*float64{pointer(1), pointer(10)}),
+		), result[0])
+	})
+
+	t.Run("Rows matching all filters are excluded", func(t *testing.T) {
+		result, err := Apply(data.Frames{frame}, []Transformation{transformation(FilterByValue, `{
+			"type": "exclude",
+			"match": "all",
+			"filters": [
+				{"fieldName": "value", "config": {"id": "isNotNull", "options": {}}},
+				{"fieldName": "value", "config": {"id": "between", "options": {"from": "0", "to": "5"}}}
+			]
+		}`)})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"b", "c"}, []string{
+			result[0].Fields[0].At(0).(string),
+			result[0].Fields[0].At(1).(string),
+		})
+	})
+
+	t.Run("Invalid filters fail", func(t *testing.T) {
+		_, err := Apply(data.Frames{frame}, []Transformation{transformation(FilterByValue, `{
+			"filters": [{"fieldName": "value", "config": {"id": "greater", "options": {"value": "high"}}}]
+		}`)})
+		require.EqualError(t, err, `transformation 0: filter of field "value": greater needs a numeric value`)
+	})
+}
+
+func TestJoinByField(t *testing.T) {
+	first := data.NewFrame("A",
+		data.NewField("time", nil, []time.Time{time.Unix(10, 0), time.Unix(20, 0)}),
+		data.NewField("cpu", nil, []float64{1, 2}),
+	)
+	first.RefID = "A"
+	second := data.NewFrame("B",
+		data.NewField("time", nil, []time.Time{time.Unix(30, 0), time.Unix(20, 0)}),
+		data.NewField("memory", nil, []int64{3, 4}),
+	)
+	second.RefID = "B"
+	withoutTime := data.NewFrame("C", data.NewField("name", nil, []string{"x"}))
+
+	result, err := Apply(data.Frames{first, second, withoutTime}, []Transformation{
+		transformation(SeriesToColumns, `{}`),
+	})
+	require.NoError(t, err)
+
+	expected := data.NewFrame("",
+		data.NewField("time", nil, []time.Time{time.Unix(10, 0), time.Unix(20, 0), time.Unix(30, 0)}),
+		data.NewField("cpu", nil, []*float64{pointer(1), pointer(2), nil}),
+		data.NewField("memory", nil, []*int64{nil, int64Pointer(4), int64Pointer(3)}),
+	)
+	expected.RefID = "A"
+	assert.Equal(t, data.Frames{expected}, result)
+
+	t.Run("The fields to join by must have the same type", func(t *testing.T) {
+		other := data.NewFrame("", data.NewField("time", nil, []string{"now"}))
+		_, err := Apply(data.Frames{first, other}, []Transformation{transformation(JoinByField, `{"byField": "time"}`)})
+		require.Error(t, err)
+	})
+}
+
+func TestGroupBy(t *testing.T) {
+	frame := data.NewFrame("",
+		data.NewField("host", nil, []string{"a", "b", "a", "a"}),
+		data.NewField("value", nil, []*float64{pointer(1), pointer(2), nil, pointer(5)}),
+		data.NewField("ignored", nil, []string{"w", "x", "y", "z"}),
+	)
+	other := data.NewFrame("", data.NewField("name", nil, []string{"x"}))
+
+	result, err := Apply(data.Frames{frame, other}, []Transformation{transformation(GroupBy, `{
+		"fields": {
+			"host": {"operation": "groupby", "aggregations": []},
+			"value": {"operation": "aggregate", "aggregations": ["sum", "mean", "count", "last", "lastNotNull"]}
+		}
+	}`)})
+	require.NoError(t, err)
+
+	assert.Equal(t, data.Frames{data.NewFrame("",
+		data.NewField("host", nil, []string{"a", "b"}),
+		data.NewField("value (sum)", nil, []*float64{pointer(6), pointer(2)}),
+		data.NewField("value (mean)", nil, []*float64{pointer(3), pointer(2)}),
+		data.NewField("value (count)", nil, []*float64{pointer(3), pointer(1)}),
+		data.NewField("value (last)", nil, []*float64{pointer(5), pointer(2)}),
+		data.NewField("value (lastNotNull)", nil, []*float64{pointer(5), pointer(2)}),
+	)}, result)
+
+	t.Run("Unsupported aggregations fail", func(t *testing.T) {
+		_, err := Apply(data.Frames{frame}, []Transformation{transformation(GroupBy, `{
+			"fields": {"value": {"operation": "aggregate", "aggregations": ["p99"]}}
+		}`)})
+		require.EqualError(t, err, `transformation 0: field "value": unsupported aggregation "p99"`)
+	})
+}
+
+func TestReduce(t *testing.T) {
+	cpu := data.NewFrame("cpu",
+		data.NewField("time", nil, []time.Time{time.Unix(0, 0), time.Unix(60, 0), time.Unix(120, 0), time.Unix(180, 0)}),
+		data.NewField("requests", data.Labels{"host": "a"}, []*float64{pointer(10), pointer(15), pointer(4), nil}),
+	)
+	cpu.RefID = "A"
+	memory := data.NewFrame("memory",
+		data.NewField("time", nil, []time.Time{time.Unix(0, 0), time.Unix(60, 0)}),
+		data.NewField("used", nil, []int64{2, 8}),
+	)
+	memory.RefID = "B"
+
+	t.Run("Series are reduced to rows", func(t *testing.T) {
+		result, err := Apply(data.Frames{cpu, memory}, []Transformation{transformation(Reduce, `{
+			"reducers": ["lastNotNull", "mean", "max", "delta", "diff"]
+		}`)})
+		require.NoError(t, err)
+
+		expected := data.NewFrame("cpu",
+			data.NewField("Field", nil, []string{"requests {host=a}", "used"}),
+			data.NewField("Last *", nil, []*float64{pointer(4), pointer(8)}),
+			data.NewField("Mean", nil, []*float64{pointer(29.0 / 3), pointer(5)}),
+			data.NewField("Max", nil, []*float64{pointer(15), pointer(8)}),
+			data.NewField("Delta", nil, []*float64{pointer(9), pointer(6)}),
+			data.NewField("Difference", nil, []*float64{pointer(-6), pointer(6)}),
+		)
+		expected.RefID = "A"
+		assert.Equal(t, data.Frames{expected}, result)
+	})
+
+	t.Run("Fields are reduced to a row", func(t *testing.T) {
+		result, err := Apply(data.Frames{memory}, []Transformation{transformation(Reduce, `{
+			"mode": "reduceFields",
+			"reducers": ["last", "step"]
+		}`)})
+		require.NoError(t, err)
+
+		expected := data.NewFrame("memory",
+			data.NewField("used (last)", nil, []*int64{int64Pointer(8)}),
+			data.NewField("used (step)", nil, []*float64{pointer(6)}),
+		)
+		expected.RefID = "B"
+		assert.Equal(t, data.Frames{expected}, result)
+	})
+
+	t.Run("Unsupported reducers fail", func(t *testing.T) {
+		_, err := Apply(data.Frames{cpu}, []Transformation{transformation(Reduce, `{"reducers": ["p99"]}`)})
+		require.EqualError(t, err, `transformation 0: unsupported calculation "p99"`)
+	})
+}
+
+func TestDelta(t *testing.T) {
+	assert.Equal(t, 0.0, *delta(nil))
+	assert.Equal(t, 5.0, *delta([]float64{1, 3, 6}))
+	// the counter is reset after 6, and increases by 2 and 3
+	assert.Equal(t, 10.0, *delta([]float64{1, 3, 6, 2, 5}))
+	assert.Equal(t, 7.0, *delta([]float64{1, 3, 6, 2}))
+}
+
+func TestReduceValues(t *testing.T) {
+	frame := data.NewFrame("",
+		data.NewField("time", nil, []time.Time{time.Unix(0, 0), time.Unix(60, 0)}),
+		data.NewField("host", nil, []string{"a", "b"}),
+		data.NewField("cpu", nil, []float64{0.5, 0.25}),
+	)
+
+	t.Run("Numeric fields are reduced to the last non-null value by default", func(t *testing.T) {
+		values, err := ReduceValues(data.Frames{frame}, ReduceOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, []FieldValue{{Field: "cpu", Calc: "lastNotNull", Value: 0.25}}, values)
+	})
+
+	t.Run("Fields matching a regular expression are calculated", func(t *testing.T) {
+		values, err := ReduceValues(data.Frames{frame}, ReduceOptions{Fields: "/^(host|cpu)$/", Calcs: []string{"first", "diffperc"}})
+		require.NoError(t, err)
+		assert.Equal(t, []FieldValue{
+			{Field: "host", Calc: "first", Value: "a"},
+			{Field: "host", Calc: "diffperc", Value: nil},
+			{Field: "cpu", Calc: "first", Value: 0.5},
+			{Field: "cpu", Calc: "diffperc", Value: -0.5},
+		}, values)
+	})
+
+	t.Run("Values are limited", func(t *testing.T) {
+		values, err := ReduceValues(data.Frames{frame}, ReduceOptions{Values: true, Fields: "host", Limit: 1})
+		require.NoError(t, err)
+		assert.Equal(t, []FieldValue{{Field: "host", Value: "a"}}, values)
+	})
+
+	t.Run("Unsupported calculations fail", func(t *testing.T) {
+		_, err := ReduceValues(data.Frames{frame}, ReduceOptions{Calcs: []string{"p99"}})
+		require.EqualError(t, err, `unsupported calculation "p99"`)
+	})
+}
+
+func pointer(f float64) *float64 {
+	return &f
+}
+
+func int64Pointer(i int64) *int64 {
+	return &i
+}
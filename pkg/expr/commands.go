@@ -3,9 +3,11 @@ package expr
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/gtime"
 	"github.com/grafana/grafana/pkg/expr/mathexp"
 )
@@ -226,6 +228,234 @@ func (gr *ResampleCommand) Execute(ctx context.Context, vars mathexp.Vars) (math
 	return newRes, nil
 }
 
+// Join modes of the JoinCommand.
+const (
+	joinModeInner = "inner"
+	joinModeOuter = "outer"
+)
+
+// JoinCommand is an expression command that joins the items (series or numbers)
+// of two variables, typically queries of different data sources, and evaluates
+// a math expression such as "$A / $B" on every joined pair.
+type JoinCommand struct {
+	Left          string
+	Right         string
+	On            string // the label key to join on, or "time" to join every item of Left with every item of Right
+	Mode          string
+	RawExpression string
+	Expression    *mathexp.Expr
+	refID         string
+}
+
+// NewJoinCommand creates a new JoinCommand. It will return an error
+// if the mode is not supported or if there is an error parsing expr.
+func NewJoinCommand(refID, left, right, on, mode, expr string) (*JoinCommand, error) {
+	if on == "" {
+		on = "time"
+	}
+	if mode == "" {
+		mode = joinModeInner
+	}
+	if mode != joinModeInner && mode != joinModeOuter {
+		return nil, fmt.Errorf("unsupported join mode %q, must be %s or %s", mode, joinModeInner, joinModeOuter)
+	}
+	parsedExpr, err := mathexp.New(expr)
+	if err != nil {
+		return nil, err
+	}
+	return &JoinCommand{
+		Left:          left,
+		Right:         right,
+		On:            on,
+		Mode:          mode,
+		RawExpression: expr,
+		Expression:    parsedExpr,
+		refID:         refID,
+	}, nil
+}
+
+// UnmarshalJoinCommand creates a JoinCommand from Grafana's frontend query.
+func UnmarshalJoinCommand(rn *rawNode) (*JoinCommand, error) {
+	getString := func(key string, required bool) (string, error) {
+		raw, ok := rn.Query[key]
+		if !ok {
+			if required {
+				return "", fmt.Errorf("no %v specified in join command for refId %v", key, rn.RefID)
+			}
+			return "", nil
+		}
+		s, ok := raw.(string)
+		if !ok {
+			return "", fmt.Errorf("expected join %v to be a string, got %T for refId %v", key, raw, rn.RefID)
+		}
+		return s, nil
+	}
+
+	var values [5]string
+	for i, key := range []string{"left", "right", "on", "mode", "expression"} {
+		v, err := getString(key, key != "on" && key != "mode")
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	left := strings.TrimPrefix(values[0], "$")
+	right := strings.TrimPrefix(values[1], "$")
+
+	jc, err := NewJoinCommand(rn.RefID, left, right, values[2], values[3], values[4])
+	if err != nil {
+		return nil, fmt.Errorf("invalid join command in '%v': %v", rn.RefID, err)
+	}
+	return jc, nil
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (jc *JoinCommand) NeedsVars() []string {
+	needed := []string{jc.Left, jc.Right}
+	for _, name := range jc.Expression.VarNames {
+		if name != jc.Left && name != jc.Right {
+			needed = append(needed, name)
+		}
+	}
+	return needed
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (jc *JoinCommand) Execute(ctx context.Context, vars mathexp.Vars) (mathexp.Results, error) {
+	newRes := mathexp.Results{Values: mathexp.Values{}}
+	for _, pair := range jc.pairs(vars[jc.Left].Values, vars[jc.Right].Values) {
+		labels := data.Labels{}
+		for _, val := range pair {
+			if val == nil {
+				continue
+			}
+			for k, v := range val.GetLabels() {
+				labels[k] = v
+			}
+		}
+
+		left, right, err := jc.align(pair[0], pair[1])
+		if err != nil {
+			return newRes, err
+		}
+
+		pairVars := make(mathexp.Vars, len(vars))
+		for name, res := range vars {
+			pairVars[name] = res
+		}
+		pairVars[jc.Left] = mathexp.Results{Values: mathexp.Values{left}}
+		pairVars[jc.Right] = mathexp.Results{Values: mathexp.Values{right}}
+
+		res, err := jc.Expression.Execute(jc.refID, pairVars)
+		if err != nil {
+			return newRes, err
+		}
+		for _, val := range res.Values {
+			val.SetLabels(labels)
+			newRes.Values = append(newRes.Values, val)
+		}
+	}
+	return newRes, nil
+}
+
+// pairs returns the pairs of items of the left and right variables to join.
+// In outer mode, items without a match are kept, paired with nil.
+func (jc *JoinCommand) pairs(left, right mathexp.Values) [][2]mathexp.Value {
+	var pairs [][2]mathexp.Value
+	if jc.On == "time" {
+		for _, l := range left {
+			for _, r := range right {
+				pairs = append(pairs, [2]mathexp.Value{l, r})
+			}
+		}
+		if jc.Mode == joinModeOuter && (len(left) == 0 || len(right) == 0) {
+			for _, l := range left {
+				pairs = append(pairs, [2]mathexp.Value{l, nil})
+			}
+			for _, r := range right {
+				pairs = append(pairs, [2]mathexp.Value{nil, r})
+			}
+		}
+		return pairs
+	}
+
+	matched := make(map[int]bool)
+	for _, l := range left {
+		key, ok := l.GetLabels()[jc.On]
+		found := false
+		for i, r := range right {
+			if rKey, rOK := r.GetLabels()[jc.On]; ok && rOK && key == rKey {
+				pairs = append(pairs, [2]mathexp.Value{l, r})
+				matched[i] = true
+				found = true
+			}
+		}
+		if !found && jc.Mode == joinModeOuter {
+			pairs = append(pairs, [2]mathexp.Value{l, nil})
+		}
+	}
+	if jc.Mode == joinModeOuter {
+		for i, r := range right {
+			if !matched[i] {
+				pairs = append(pairs, [2]mathexp.Value{nil, r})
+			}
+		}
+	}
+	return pairs
+}
+
+// align returns the items of a pair ready for the expression. Missing items of
+// outer joins become a null number, and in outer mode two series get the union
+// of their time stamps, with null values where a series has no point.
+func (jc *JoinCommand) align(left, right mathexp.Value) (mathexp.Value, mathexp.Value, error) {
+	if left == nil {
+		left = mathexp.NewNumber(jc.refID, nil)
+	}
+	if right == nil {
+		right = mathexp.NewNumber(jc.refID, nil)
+	}
+
+	leftSeries, leftOK := left.(mathexp.Series)
+	rightSeries, rightOK := right.(mathexp.Series)
+	if jc.Mode != joinModeOuter || !leftOK || !rightOK {
+		return left, right, nil
+	}
+
+	seen := make(map[int64]bool)
+	var times []time.Time
+	for _, s := range []mathexp.Series{leftSeries, rightSeries} {
+		for i := 0; i < s.Len(); i++ {
+			t := s.GetTime(i)
+			if t != nil && !seen[t.UnixNano()] {
+				seen[t.UnixNano()] = true
+				times = append(times, *t)
+			}
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+	aligned := make([]mathexp.Series, 2)
+	for i, s := range []mathexp.Series{leftSeries, rightSeries} {
+		values := make(map[int64]*float64, s.Len())
+		for p := 0; p < s.Len(); p++ {
+			if t, f := s.GetPoint(p); t != nil {
+				values[t.UnixNano()] = f
+			}
+		}
+
+		aligned[i] = mathexp.NewSeries(s.GetName(), s.GetLabels(), s.TimeIdx, s.TimeIsNullable, s.ValueIdx, true, len(times))
+		for p := range times {
+			t := times[p]
+			if err := aligned[i].SetPoint(p, &t, values[t.UnixNano()]); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	return aligned[0], aligned[1], nil
+}
+
 // CommandType is the type of the expression command.
 type CommandType int
 
@@ -240,6 +470,8 @@ const (
 	TypeResample
 	// TypeClassicConditions is the CMDType for the classic condition operation.
 	TypeClassicConditions
+	// TypeJoin is the CMDType for joining two variables.
+	TypeJoin
 )
 
 func (gt CommandType) String() string {
@@ -252,6 +484,8 @@ func (gt CommandType) String() string {
 		return "resample"
 	case TypeClassicConditions:
 		return "classic_conditions"
+	case TypeJoin:
+		return "join"
 	default:
 		return "unknown"
 	}
@@ -268,6 +502,8 @@ func ParseCommandType(s string) (CommandType, error) {
 		return TypeResample, nil
 	case "classic_conditions":
 		return TypeClassicConditions, nil
+	case "join":
+		return TypeJoin, nil
 	default:
 		return TypeUnknown, fmt.Errorf("'%v' is not a recognized expression type", s)
 	}
@@ -0,0 +1,131 @@
+package expr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJoinCommand(t *testing.T) {
+	newSeries := func(labels data.Labels, points map[int64]float64) mathexp.Series {
+		s := mathexp.NewSeries("", labels, 0, true, 1, true, 0)
+		for _, sec := range []int64{1, 2, 3} {
+			if f, ok := points[sec]; ok {
+				require.NoError(t, s.AppendPoint(0, utp(sec), fp(f)))
+			}
+		}
+		return s
+	}
+	newNumber := func(labels data.Labels, f float64) mathexp.Number {
+		n := mathexp.NewNumber("", labels)
+		n.SetValue(fp(f))
+		return n
+	}
+	points := func(s mathexp.Series) map[time.Time]*float64 {
+		result := map[time.Time]*float64{}
+		for i := 0; i < s.Len(); i++ {
+			tm, f := s.GetPoint(i)
+			result[*tm] = f
+		}
+		return result
+	}
+
+	t.Run("Series are joined on time", func(t *testing.T) {
+		vars := mathexp.Vars{
+			"A": mathexp.Results{Values: mathexp.Values{newSeries(data.Labels{"table": "orders"}, map[int64]float64{1: 10, 2: 20})}},
+			"B": mathexp.Results{Values: mathexp.Values{newSeries(data.Labels{"job": "api"}, map[int64]float64{2: 4, 3: 5})}},
+		}
+
+		t.Run("inner", func(t *testing.T) {
+			jc, err := NewJoinCommand("C", "A", "B", "", "", "$B / $A")
+			require.NoError(t, err)
+			require.Equal(t, []string{"A", "B"}, jc.NeedsVars())
+
+			res, err := jc.Execute(context.Background(), vars)
+			require.NoError(t, err)
+			require.Len(t, res.Values, 1)
+
+			s := res.Values[0].(mathexp.Series)
+			require.Equal(t, data.Labels{"table": "orders", "job": "api"}, s.GetLabels())
+			require.Equal(t, map[time.Time]*float64{*utp(2): fp(0.2)}, points(s))
+		})
+
+		t.Run("outer", func(t *testing.T) {
+			jc, err := NewJoinCommand("C", "A", "B", "time", "outer", "$A + $B")
+			require.NoError(t, err)
+
+			res, err := jc.Execute(context.Background(), vars)
+			require.NoError(t, err)
+			require.Len(t, res.Values, 1)
+
+			require.Equal(t, map[time.Time]*float64{
+				*utp(1): nil,
+				*utp(2): fp(24),
+				*utp(3): nil,
+			}, points(res.Values[0].(mathexp.Series)))
+		})
+	})
+
+	t.Run("Numbers are joined on a label", func(t *testing.T) {
+		vars := mathexp.Vars{
+			"A": mathexp.Results{Values: mathexp.Values{
+				newNumber(data.Labels{"service": "checkout", "db": "mysql"}, 100),
+				newNumber(data.Labels{"service": "search", "db": "mysql"}, 50),
+			}},
+			"B": mathexp.Results{Values: mathexp.Values{
+				newNumber(data.Labels{"service": "checkout", "instance": "a"}, 5),
+				newNumber(data.Labels{"service": "cart", "instance": "b"}, 1),
+			}},
+		}
+
+		t.Run("inner", func(t *testing.T) {
+			jc, err := NewJoinCommand("C", "A", "B", "service", "inner", "$B / $A")
+			require.NoError(t, err)
+
+			res, err := jc.Execute(context.Background(), vars)
+			require.NoError(t, err)
+			require.Len(t, res.Values, 1)
+
+			n := res.Values[0].(mathexp.Number)
+			require.Equal(t, data.Labels{"service": "checkout", "db": "mysql", "instance": "a"}, n.GetLabels())
+			require.Equal(t, fp(0.05), n.GetFloat64Value())
+		})
+
+		t.Run("outer", func(t *testing.T) {
+			jc, err := NewJoinCommand("C", "A", "B", "service", "outer", "$B / $A")
+			require.NoError(t, err)
+
+			res, err := jc.Execute(context.Background(), vars)
+			require.NoError(t, err)
+			require.Len(t, res.Values, 3)
+
+			values := map[string]*float64{}
+			for _, val := range res.Values {
+				values[val.GetLabels()["service"]] = val.(mathexp.Number).GetFloat64Value()
+			}
+			require.Equal(t, map[string]*float64{"checkout": fp(0.05), "search": nil, "cart": nil}, values)
+		})
+	})
+
+	t.Run("Unsupported modes fail", func(t *testing.T) {
+		_, err := NewJoinCommand("C", "A", "B", "time", "left", "$A / $B")
+		require.EqualError(t, err, `unsupported join mode "left", must be inner or outer`)
+	})
+
+	t.Run("Unmarshal requires the variables and the expression", func(t *testing.T) {
+		_, err := UnmarshalJoinCommand(&rawNode{RefID: "C", Query: map[string]interface{}{"left": "$A", "right": "B"}})
+		require.EqualError(t, err, "no expression specified in join command for refId C")
+
+		jc, err := UnmarshalJoinCommand(&rawNode{RefID: "C", Query: map[string]interface{}{
+			"left": "$A", "right": "B", "expression": "$A / $B / $D",
+		}})
+		require.NoError(t, err)
+		require.Equal(t, "time", jc.On)
+		require.Equal(t, joinModeInner, jc.Mode)
+		require.Equal(t, []string{"A", "B", "D"}, jc.NeedsVars())
+	})
+}
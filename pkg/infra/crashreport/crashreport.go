@@ -0,0 +1,69 @@
+// Package crashreport keeps the reports of the latest panics recovered while
+// serving requests, to include them in the diagnostics bundles.
+package crashreport
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// maxReports is the number of reports kept, the oldest reports are dropped
+// past it
+const maxReports = 20
+
+// Report is a panic recovered while serving a request.
+type Report struct {
+	Time   time.Time `json:"time"`
+	Error  string    `json:"error"`
+	Method string    `json:"method,omitempty"`
+	// Path is the path of the request, without its query which might
+	// contain credentials
+	Path  string `json:"path,omitempty"`
+	Stack string `json:"stack"`
+}
+
+var (
+	mu      sync.Mutex
+	reports []Report
+	// next is the index of reports the next report is written at once
+	// maxReports are kept
+	next int
+)
+
+// Capture records a panic recovered while serving a request.
+func Capture(recovered interface{}, method, path string, stack []byte) {
+	report := Report{
+		Time:   time.Now(),
+		Error:  fmt.Sprintf("%v", recovered),
+		Method: method,
+		Path:   path,
+		Stack:  string(stack),
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reports) < maxReports {
+		reports = append(reports, report)
+		return
+	}
+	reports[next] = report
+	next = (next + 1) % maxReports
+}
+
+// Recent returns the reports of the latest panics, the oldest first.
+func Recent() []Report {
+	mu.Lock()
+	defer mu.Unlock()
+	result := make([]Report, 0, len(reports))
+	result = append(result, reports[next:]...)
+	return append(result, reports[:next]...)
+}
+
+// Reset drops the reports.
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	reports = nil
+	next = 0
+}
@@ -0,0 +1,40 @@
+package crashreport
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapture(t *testing.T) {
+	t.Cleanup(Reset)
+
+	t.Run("Keeps the reports in order", func(t *testing.T) {
+		Reset()
+		Capture(errors.New("first"), "GET", "/api/first", []byte("stack"))
+		Capture("second", "POST", "/api/second", nil)
+
+		recent := Recent()
+		require.Len(t, recent, 2)
+		assert.Equal(t, "first", recent[0].Error)
+		assert.Equal(t, "stack", recent[0].Stack)
+		assert.Equal(t, "second", recent[1].Error)
+		assert.Equal(t, "POST", recent[1].Method)
+		assert.Equal(t, "/api/second", recent[1].Path)
+	})
+
+	t.Run("Drops the oldest reports", func(t *testing.T) {
+		Reset()
+		for i := 0; i < maxReports+5; i++ {
+			Capture(fmt.Sprintf("panic %d", i), "GET", "/", nil)
+		}
+
+		recent := Recent()
+		require.Len(t, recent, maxReports)
+		assert.Equal(t, "panic 5", recent[0].Error)
+		assert.Equal(t, fmt.Sprintf("panic %d", maxReports+4), recent[maxReports-1].Error)
+	})
+}
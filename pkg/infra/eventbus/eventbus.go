@@ -0,0 +1,229 @@
+// Package eventbus is the distributed event bus of the Grafana servers of a
+// database. The events of the types registered with Distribute, published to
+// the bus of a server, are published to the bus of the other servers too, so
+// that they e.g. invalidate their caches right away instead of once they
+// expire.
+//
+// The events are stored in the database, and read by the other servers every
+// poll interval. An event is published to the bus of the other servers at
+// most once, it's lost if a server is down.
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/leaderelection"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	// batchSize is the max number of the events read at once
+	batchSize = 1000
+	// cleanupInterval is how often the expired events are deleted
+	cleanupInterval = 10 * time.Minute
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// eventTypes are the distributed types of events by name, the name of the
+// events on the bus.
+var eventTypes = map[string]reflect.Type{}
+
+// Distribute registers the type of `event`, a pointer to a struct encoded in
+// JSON, as distributed. It's called from the init functions of the packages.
+func Distribute(event bus.Msg) {
+	eventType := reflect.TypeOf(event).Elem()
+	eventTypes[eventType.Name()] = eventType
+}
+
+func init() {
+	registry.RegisterService(&Service{})
+}
+
+// Service stores the distributed events published to the bus of the server,
+// and publishes the events of the other servers to it.
+type Service struct {
+	Cfg            *setting.Cfg            `inject:""`
+	SQLStore       *sqlstore.SQLStore      `inject:""`
+	Bus            bus.Bus                 `inject:""`
+	LeaderElection *leaderelection.Service `inject:""`
+	log            log.Logger
+
+	// instanceID identifies the events stored by the server
+	instanceID string
+	// lastID is the ID of the last event read, -1 before the first read
+	lastID int64
+
+	mtx sync.Mutex
+	// received are the events of the other servers being published to the
+	// bus, which aren't stored again
+	received map[bus.Msg]struct{}
+}
+
+func (s *Service) Init() error {
+	s.log = log.New("eventbus")
+	s.instanceID = util.GenerateShortUID()
+	s.lastID = -1
+	s.received = map[bus.Msg]struct{}{}
+
+	if !s.Cfg.EventBus.Enabled {
+		return nil
+	}
+	for _, eventType := range eventTypes {
+		s.Bus.AddEventListener(s.listener(eventType))
+	}
+	return nil
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.Cfg.EventBus.Enabled
+}
+
+func (s *Service) Run(ctx context.Context) error {
+	// the events stored before the server started are skipped
+	s.receive(ctx)
+
+	ticker := time.NewTicker(s.Cfg.EventBus.PollInterval)
+	defer ticker.Stop()
+	cleanupTicker := time.NewTicker(cleanupInterval)
+	defer cleanupTicker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.receive(ctx)
+		case <-cleanupTicker.C:
+			if s.LeaderElection.IsLeader() {
+				s.deleteExpired(ctx)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// listener returns a listener of the events of a type, a func(*T) error,
+// storing them for the other servers.
+func (s *Service) listener(eventType reflect.Type) bus.HandlerFunc {
+	funcType := reflect.FuncOf([]reflect.Type{reflect.PtrTo(eventType)}, []reflect.Type{errorType}, false)
+	return reflect.MakeFunc(funcType, func(args []reflect.Value) []reflect.Value {
+		s.store(eventType.Name(), args[0].Interface())
+		return []reflect.Value{reflect.Zero(errorType)}
+	}).Interface()
+}
+
+// store stores an event published to the bus of the server, unless it's an
+// event of another server. The event is already published to the server, so
+// failing to store it is only logged.
+func (s *Service) store(name string, event bus.Msg) {
+	s.mtx.Lock()
+	_, received := s.received[event]
+	s.mtx.Unlock()
+	if received {
+		return
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		s.log.Error("Failed to encode event", "type", name, "error", err)
+		return
+	}
+
+	err = s.SQLStore.WithDbSession(context.Background(), func(dbSession *sqlstore.DBSession) error {
+		_, err := dbSession.Insert(&clusterEvent{
+			Type:       name,
+			Payload:    string(payload),
+			InstanceID: s.instanceID,
+			Created:    time.Now().UnixNano() / int64(time.Millisecond),
+		})
+		return err
+	})
+	if err != nil {
+		s.log.Error("Failed to store event", "type", name, "error", err)
+	}
+}
+
+// receive publishes the events stored by the other servers since the last
+// read to the bus. The events committed out of the order of their IDs can be
+// skipped, it's up to the caches to expire.
+func (s *Service) receive(ctx context.Context) {
+	if s.lastID < 0 {
+		var lastID int64
+		err := s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+			_, err := dbSession.Table("cluster_event").Select("COALESCE(MAX(id), 0)").Get(&lastID)
+			return err
+		})
+		if err != nil {
+			s.log.Error("Failed to read the last event", "error", err)
+			return
+		}
+		s.lastID = lastID
+		return
+	}
+
+	var events []*clusterEvent
+	err := s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		return dbSession.Where("id > ?", s.lastID).OrderBy("id").Limit(batchSize).Find(&events)
+	})
+	if err != nil {
+		s.log.Error("Failed to read the events", "error", err)
+		return
+	}
+
+	for _, e := range events {
+		s.lastID = e.Id
+		if e.InstanceID == s.instanceID {
+			continue
+		}
+
+		eventType, ok := eventTypes[e.Type]
+		if !ok {
+			// e.g. an event of a newer version of Grafana
+			s.log.Debug("Skipping event of unknown type", "type", e.Type)
+			continue
+		}
+		event := reflect.New(eventType).Interface()
+		if err := json.Unmarshal([]byte(e.Payload), event); err != nil {
+			s.log.Error("Failed to decode event", "type", e.Type, "error", err)
+			continue
+		}
+		s.publish(e.Type, event)
+	}
+}
+
+func (s *Service) publish(name string, event bus.Msg) {
+	s.mtx.Lock()
+	s.received[event] = struct{}{}
+	s.mtx.Unlock()
+
+	defer func() {
+		s.mtx.Lock()
+		delete(s.received, event)
+		s.mtx.Unlock()
+	}()
+
+	if err := s.Bus.Publish(event); err != nil {
+		s.log.Error("Failed to publish event", "type", name, "error", err)
+	}
+}
+
+// deleteExpired deletes the events older than the retention.
+func (s *Service) deleteExpired(ctx context.Context) {
+	expired := time.Now().Add(-s.Cfg.EventBus.Retention).UnixNano() / int64(time.Millisecond)
+	err := s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		_, err := dbSession.Exec("DELETE FROM cluster_event WHERE created < ?", expired)
+		return err
+	})
+	if err != nil {
+		s.log.Error("Failed to delete the expired events", "error", err)
+	}
+}
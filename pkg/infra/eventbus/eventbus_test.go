@@ -0,0 +1,100 @@
+package eventbus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type testEvent struct {
+	Name string `json:"name"`
+}
+
+func init() {
+	Distribute(&testEvent{})
+}
+
+func createTestService(t *testing.T, sqlStore *sqlstore.SQLStore) *Service {
+	t.Helper()
+
+	cfg := setting.NewCfg()
+	cfg.EventBus = setting.EventBusSettings{Enabled: true, PollInterval: time.Second, Retention: time.Hour}
+	s := &Service{Cfg: cfg, SQLStore: sqlStore, Bus: bus.New()}
+	require.NoError(t, s.Init())
+	// the events stored before are skipped
+	s.receive(context.Background())
+	return s
+}
+
+func countEvents(t *testing.T, sqlStore *sqlstore.SQLStore) int64 {
+	t.Helper()
+
+	var count int64
+	err := sqlStore.WithDbSession(context.Background(), func(dbSession *sqlstore.DBSession) error {
+		var err error
+		count, err = dbSession.Count(&clusterEvent{})
+		return err
+	})
+	require.NoError(t, err)
+	return count
+}
+
+func TestEventBus(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	first := createTestService(t, sqlStore)
+	second := createTestService(t, sqlStore)
+	ctx := context.Background()
+
+	var firstEvents, secondEvents []*testEvent
+	first.Bus.AddEventListener(func(event *testEvent) error {
+		firstEvents = append(firstEvents, event)
+		return nil
+	})
+	second.Bus.AddEventListener(func(event *testEvent) error {
+		secondEvents = append(secondEvents, event)
+		return nil
+	})
+
+	t.Run("The events are published to the other servers", func(t *testing.T) {
+		require.NoError(t, first.Bus.Publish(&testEvent{Name: "changed"}))
+		assert.Equal(t, int64(1), countEvents(t, sqlStore))
+
+		first.receive(ctx)
+		second.receive(ctx)
+
+		assert.Equal(t, []*testEvent{{Name: "changed"}}, firstEvents)
+		assert.Equal(t, []*testEvent{{Name: "changed"}}, secondEvents)
+	})
+
+	t.Run("The events of the other servers aren't stored again", func(t *testing.T) {
+		assert.Equal(t, int64(1), countEvents(t, sqlStore))
+		assert.Empty(t, second.received)
+	})
+
+	t.Run("The expired events are deleted", func(t *testing.T) {
+		err := sqlStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+			_, err := dbSession.Exec("UPDATE cluster_event SET created = 1")
+			return err
+		})
+		require.NoError(t, err)
+
+		first.deleteExpired(ctx)
+		assert.Equal(t, int64(0), countEvents(t, sqlStore))
+	})
+}
+
+func TestEventBusDisabled(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	s := &Service{Cfg: setting.NewCfg(), SQLStore: sqlStore, Bus: bus.New()}
+	require.NoError(t, s.Init())
+
+	require.NoError(t, s.Bus.Publish(&testEvent{Name: "changed"}))
+	assert.Equal(t, int64(0), countEvents(t, sqlStore))
+}
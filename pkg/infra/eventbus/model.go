@@ -0,0 +1,13 @@
+package eventbus
+
+type clusterEvent struct {
+	// nolint:stylecheck
+	Id int64
+	// Type is the name of the type of the event
+	Type string
+	// Payload is the event encoded in JSON
+	Payload    string
+	InstanceID string `xorm:"instance_id"`
+	// Created is a unix time in milliseconds
+	Created int64
+}
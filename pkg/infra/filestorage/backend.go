@@ -0,0 +1,40 @@
+package filestorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var (
+	// ErrObjectNotFound is returned by a backend if the object doesn't exist
+	ErrObjectNotFound = errors.New("object not found")
+
+	// ErrInvalidBackend is returned if the configured backend is unknown
+	ErrInvalidBackend = errors.New("invalid file storage backend")
+)
+
+// Backend stores the content of files. Keys are slash separated paths that
+// have already been validated by the FileStorage service.
+type Backend interface {
+	Put(ctx context.Context, key string, contentType string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
+
+func createBackend(ctx context.Context, cfg setting.FileStorageSettings) (Backend, error) {
+	switch cfg.Backend {
+	case setting.FileStorageLocal:
+		return newLocalBackend(cfg.LocalPath), nil
+	case setting.FileStorageS3:
+		return newS3Backend(cfg)
+	case setting.FileStorageGCS:
+		return newGCSBackend(ctx, cfg)
+	case setting.FileStorageAzureBlob:
+		return newAzureBlobBackend(cfg), nil
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrInvalidBackend, cfg.Backend)
+}
@@ -0,0 +1,77 @@
+package filestorage
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"path"
+	"strings"
+)
+
+var contentTypesByExtension = map[string]string{
+	".png":     "image/png",
+	".jpg":     "image/jpeg",
+	".jpeg":    "image/jpeg",
+	".gif":     "image/gif",
+	".webp":    "image/webp",
+	".svg":     "image/svg+xml",
+	".json":    "application/json",
+	".geojson": "application/geo+json",
+}
+
+var geoJSONTypes = map[string]bool{
+	"FeatureCollection":  true,
+	"Feature":            true,
+	"Point":              true,
+	"MultiPoint":         true,
+	"LineString":         true,
+	"MultiLineString":    true,
+	"Polygon":            true,
+	"MultiPolygon":       true,
+	"GeometryCollection": true,
+}
+
+// detectContentType derives the content type from the file extension and
+// verifies that the content actually is of that type, so that a file can't be
+// served with a content type it doesn't have.
+func detectContentType(filePath string, data []byte, allowed []string) (string, error) {
+	contentType, ok := contentTypesByExtension[strings.ToLower(path.Ext(filePath))]
+	if !ok || !isAllowed(contentType, allowed) {
+		return "", ErrContentTypeNotAllowed
+	}
+
+	switch contentType {
+	case "application/json":
+		if !json.Valid(data) {
+			return "", ErrContentTypeMismatch
+		}
+	case "application/geo+json":
+		var geo struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(data, &geo); err != nil || !geoJSONTypes[geo.Type] {
+			return "", ErrContentTypeMismatch
+		}
+	case "image/svg+xml":
+		// SVGs can embed scripts, only accept documents that look like plain SVG markup
+		lower := bytes.ToLower(data)
+		if !bytes.Contains(lower, []byte("<svg")) || bytes.Contains(lower, []byte("<script")) {
+			return "", ErrContentTypeMismatch
+		}
+	default:
+		if http.DetectContentType(data) != contentType {
+			return "", ErrContentTypeMismatch
+		}
+	}
+
+	return contentType, nil
+}
+
+func isAllowed(contentType string, allowed []string) bool {
+	for _, a := range allowed {
+		if a == contentType {
+			return true
+		}
+	}
+	return false
+}
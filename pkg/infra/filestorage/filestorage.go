@@ -0,0 +1,327 @@
+// Package filestorage stores files uploaded by users, such as images and
+// GeoJSON layers used by panels, in a configurable local or cloud backend.
+package filestorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var (
+	ErrFileNotFound           = errors.New("file not found")
+	ErrInvalidPath            = errors.New("invalid file path")
+	ErrFileTooLarge           = errors.New("file is too large")
+	ErrQuotaReached           = errors.New("file storage quota reached")
+	ErrContentTypeNotAllowed  = errors.New("content type not allowed")
+	ErrContentTypeMismatch    = errors.New("file content doesn't match its content type")
+	ErrFileStorageUnavailable = errors.New("file storage is not available")
+)
+
+var logger = log.New("filestorage")
+
+const maxPathLength = 190
+
+// systemDir is the directory of the files of the services of an organization,
+// such as the dashboard thumbnails. Its paths are invalid paths for the users,
+// so the files can't be read, listed, replaced or deleted by their path.
+const systemDir = ".system"
+
+var validPath = regexp.MustCompile(`^[a-zA-Z0-9_\-][a-zA-Z0-9_\-./]*$`)
+
+func init() {
+	registry.RegisterService(&FileStorage{})
+}
+
+// File is the metadata of a stored file.
+type File struct {
+	Id          int64     `json:"-"`
+	OrgId       int64     `json:"-"`
+	Path        string    `json:"path"`
+	ContentType string    `json:"contentType"`
+	Size        int64     `json:"size"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+	CreatedBy   int64     `json:"createdBy"`
+	UpdatedBy   int64     `json:"updatedBy"`
+}
+
+// FileStorage stores files per organization. Metadata is kept in the
+// database, which makes quota checks and listings independent of the backend.
+type FileStorage struct {
+	Cfg      *setting.Cfg       `inject:""`
+	SQLStore *sqlstore.SQLStore `inject:""`
+
+	backend Backend
+}
+
+func (fs *FileStorage) Init() error {
+	backend, err := createBackend(context.Background(), fs.Cfg.FileStorage)
+	if err != nil {
+		// don't prevent Grafana from starting because of a broken storage config
+		logger.Error("Failed to initialize file storage backend", "backend", fs.Cfg.FileStorage.Backend, "error", err)
+		return nil
+	}
+
+	fs.backend = backend
+	return nil
+}
+
+// Upload stores a file, replacing an existing file with the same path.
+func (fs *FileStorage) Upload(ctx context.Context, user *models.SignedInUser, filePath string, data []byte) (*File, error) {
+	filePath, err := cleanPath(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return fs.upload(ctx, user, filePath, data)
+}
+
+// UploadSystemFile stores a file of a service of an organization, such as a
+// dashboard thumbnail, replacing an existing file with the same path. The
+// files of the services are only available with GetSystemFile.
+func (fs *FileStorage) UploadSystemFile(ctx context.Context, orgID int64, filePath string, data []byte) (*File, error) {
+	filePath, err := cleanPath(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return fs.upload(ctx, &models.SignedInUser{OrgId: orgID}, systemDir+"/"+filePath, data)
+}
+
+func (fs *FileStorage) upload(ctx context.Context, user *models.SignedInUser, filePath string, data []byte) (*File, error) {
+	if fs.backend == nil {
+		return nil, ErrFileStorageUnavailable
+	}
+
+	settings := fs.Cfg.FileStorage
+	size := int64(len(data))
+	if settings.MaxFileSize > 0 && size > settings.MaxFileSize {
+		return nil, ErrFileTooLarge
+	}
+
+	contentType, err := detectContentType(filePath, data, settings.AllowedContentTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &File{
+		OrgId:       user.OrgId,
+		Path:        filePath,
+		ContentType: contentType,
+		Size:        size,
+		Updated:     time.Now(),
+		UpdatedBy:   user.UserId,
+	}
+
+	err = fs.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var existing File
+		exists, err := sess.Where("org_id=? AND path=?", user.OrgId, filePath).Get(&existing)
+		if err != nil {
+			return err
+		}
+
+		if settings.OrgQuota >= 0 {
+			used, err := sess.Where("org_id=?", user.OrgId).SumInt(&File{}, "size")
+			if err != nil {
+				return err
+			}
+			if used-existing.Size+size > settings.OrgQuota {
+				return ErrQuotaReached
+			}
+		}
+
+		// write the content first, so metadata never points at a missing object
+		if err := fs.backend.Put(ctx, storageKey(user.OrgId, filePath), contentType, data); err != nil {
+			return err
+		}
+
+		if exists {
+			file.Id = existing.Id
+			file.Created = existing.Created
+			file.CreatedBy = existing.CreatedBy
+			_, err = sess.ID(existing.Id).AllCols().Update(file)
+			return err
+		}
+
+		file.Created = file.Updated
+		file.CreatedBy = user.UserId
+		_, err = sess.Insert(file)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// UploadInstanceFile stores a file of the instance, such as a usage export,
+// which doesn't belong to an org. The files of the instance are stored under
+// the instance directory of the backend, without metadata or quota.
+func (fs *FileStorage) UploadInstanceFile(ctx context.Context, filePath string, contentType string, data []byte) error {
+	if fs.backend == nil {
+		return ErrFileStorageUnavailable
+	}
+
+	filePath, err := cleanPath(filePath)
+	if err != nil {
+		return err
+	}
+
+	return fs.backend.Put(ctx, "instance/"+filePath, contentType, data)
+}
+
+// Get returns the metadata and the content of a file.
+func (fs *FileStorage) Get(ctx context.Context, orgID int64, filePath string) (*File, []byte, error) {
+	filePath, err := cleanPath(filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return fs.get(ctx, orgID, filePath)
+}
+
+// GetSystemFile returns the metadata and the content of a file of a service
+// of an organization, stored with UploadSystemFile.
+func (fs *FileStorage) GetSystemFile(ctx context.Context, orgID int64, filePath string) (*File, []byte, error) {
+	filePath, err := cleanPath(filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return fs.get(ctx, orgID, systemDir+"/"+filePath)
+}
+
+func (fs *FileStorage) get(ctx context.Context, orgID int64, filePath string) (*File, []byte, error) {
+	if fs.backend == nil {
+		return nil, nil, ErrFileStorageUnavailable
+	}
+
+	file, err := fs.getMeta(ctx, orgID, filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := fs.backend.Get(ctx, storageKey(orgID, file.Path))
+	if errors.Is(err, ErrObjectNotFound) {
+		return nil, nil, ErrFileNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return file, data, nil
+}
+
+// List returns the metadata of all files of an organization with paths
+// starting with the prefix, besides the files of the services.
+func (fs *FileStorage) List(ctx context.Context, orgID int64, prefix string) ([]*File, error) {
+	var all []*File
+	err := fs.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Where("org_id=?", orgID).OrderBy("path").Find(&all)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// filter in code rather than with LIKE, which escapes differently in each database
+	files := make([]*File, 0, len(all))
+	for _, file := range all {
+		if strings.HasPrefix(file.Path, prefix) && !strings.HasPrefix(file.Path, systemDir+"/") {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// Usage returns the total size in bytes of all files of an organization.
+func (fs *FileStorage) Usage(ctx context.Context, orgID int64) (int64, error) {
+	var used int64
+	err := fs.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var err error
+		used, err = sess.Where("org_id=?", orgID).SumInt(&File{}, "size")
+		return err
+	})
+	return used, err
+}
+
+// Delete removes a file.
+func (fs *FileStorage) Delete(ctx context.Context, orgID int64, filePath string) error {
+	if fs.backend == nil {
+		return ErrFileStorageUnavailable
+	}
+
+	filePath, err := cleanPath(filePath)
+	if err != nil {
+		return err
+	}
+	file, err := fs.getMeta(ctx, orgID, filePath)
+	if err != nil {
+		return err
+	}
+
+	return fs.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		if _, err := sess.ID(file.Id).Delete(&File{}); err != nil {
+			return err
+		}
+
+		err := fs.backend.Delete(ctx, storageKey(orgID, file.Path))
+		if errors.Is(err, ErrObjectNotFound) {
+			return nil
+		}
+		return err
+	})
+}
+
+// getMeta returns the metadata of a file of a cleaned path.
+func (fs *FileStorage) getMeta(ctx context.Context, orgID int64, filePath string) (*File, error) {
+	var file File
+	err := fs.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		exists, err := sess.Where("org_id=? AND path=?", orgID, filePath).Get(&file)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrFileNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &file, nil
+}
+
+func storageKey(orgID int64, filePath string) string {
+	return fmt.Sprintf("orgs/%d/%s", orgID, filePath)
+}
+
+// cleanPath normalizes a file path and makes sure it can't escape the
+// organization's directory, nor be in the directory of the services.
+func cleanPath(filePath string) (string, error) {
+	filePath = strings.TrimPrefix(strings.TrimSpace(filePath), "/")
+	if filePath == "" {
+		return "", ErrInvalidPath
+	}
+
+	cleaned := path.Clean(filePath)
+	if cleaned != filePath || len(cleaned) > maxPathLength || !validPath.MatchString(cleaned) {
+		return "", ErrInvalidPath
+	}
+
+	for _, segment := range strings.Split(cleaned, "/") {
+		if segment == ".." || segment == "." {
+			return "", ErrInvalidPath
+		}
+	}
+
+	return cleaned, nil
+}
@@ -0,0 +1,150 @@
+package filestorage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var pngHeader = []byte("\x89PNG\x0D\x0A\x1A\x0A\x00\x00\x00\x0DIHDR")
+
+func setupFileStorage(t *testing.T, orgQuota int64) *FileStorage {
+	t.Helper()
+
+	cfg := setting.NewCfg()
+	cfg.FileStorage = setting.FileStorageSettings{
+		Backend:             setting.FileStorageLocal,
+		LocalPath:           t.TempDir(),
+		MaxFileSize:         1024,
+		OrgQuota:            orgQuota,
+		AllowedContentTypes: []string{"image/png", "application/json", "application/geo+json"},
+	}
+
+	fs := &FileStorage{Cfg: cfg, SQLStore: sqlstore.InitTestDB(t)}
+	require.NoError(t, fs.Init())
+	return fs
+}
+
+func TestFileStorage(t *testing.T) {
+	ctx := context.Background()
+	user := &models.SignedInUser{OrgId: 1, UserId: 2}
+
+	t.Run("Files can be uploaded, listed, read and deleted", func(t *testing.T) {
+		fs := setupFileStorage(t, -1)
+
+		geoJSON := []byte(`{"type": "FeatureCollection", "features": []}`)
+		file, err := fs.Upload(ctx, user, "layers/countries.geojson", geoJSON)
+		require.NoError(t, err)
+		assert.Equal(t, "application/geo+json", file.ContentType)
+		assert.Equal(t, int64(len(geoJSON)), file.Size)
+
+		_, err = fs.Upload(ctx, user, "images/logo.png", pngHeader)
+		require.NoError(t, err)
+
+		files, err := fs.List(ctx, 1, "layers/")
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+		assert.Equal(t, "layers/countries.geojson", files[0].Path)
+
+		meta, data, err := fs.Get(ctx, 1, "layers/countries.geojson")
+		require.NoError(t, err)
+		assert.Equal(t, geoJSON, data)
+		assert.Equal(t, int64(2), meta.CreatedBy)
+
+		_, _, err = fs.Get(ctx, 2, "layers/countries.geojson")
+		assert.ErrorIs(t, err, ErrFileNotFound)
+
+		require.NoError(t, fs.Delete(ctx, 1, "layers/countries.geojson"))
+		_, _, err = fs.Get(ctx, 1, "layers/countries.geojson")
+		assert.ErrorIs(t, err, ErrFileNotFound)
+	})
+
+	t.Run("Uploads are validated", func(t *testing.T) {
+		fs := setupFileStorage(t, -1)
+
+		_, err := fs.Upload(ctx, user, "../escape.json", []byte(`{}`))
+		assert.ErrorIs(t, err, ErrInvalidPath)
+
+		_, err = fs.Upload(ctx, user, "a/./b.json", []byte(`{}`))
+		assert.ErrorIs(t, err, ErrInvalidPath)
+
+		_, err = fs.Upload(ctx, user, "script.js", []byte(`alert(1)`))
+		assert.ErrorIs(t, err, ErrContentTypeNotAllowed)
+
+		_, err = fs.Upload(ctx, user, "fake.png", []byte(`<html></html>`))
+		assert.ErrorIs(t, err, ErrContentTypeMismatch)
+
+		_, err = fs.Upload(ctx, user, "broken.json", []byte(`{"a":`))
+		assert.ErrorIs(t, err, ErrContentTypeMismatch)
+
+		_, err = fs.Upload(ctx, user, "layer.geojson", []byte(`{"type": "Unknown"}`))
+		assert.ErrorIs(t, err, ErrContentTypeMismatch)
+
+		_, err = fs.Upload(ctx, user, "large.json", []byte(`"`+string(make([]byte, 2048))+`"`))
+		assert.ErrorIs(t, err, ErrFileTooLarge)
+	})
+
+	t.Run("Instance files are stored outside of the organizations", func(t *testing.T) {
+		fs := setupFileStorage(t, 0)
+
+		require.NoError(t, fs.UploadInstanceFile(ctx, "usage/usage.csv", "text/csv", []byte("org_id\n1\n")))
+		data, err := fs.backend.Get(ctx, "instance/usage/usage.csv")
+		require.NoError(t, err)
+		assert.Equal(t, "org_id\n1\n", string(data))
+
+		files, err := fs.List(ctx, 1, "")
+		require.NoError(t, err)
+		assert.Empty(t, files)
+
+		assert.ErrorIs(t, fs.UploadInstanceFile(ctx, "../usage.csv", "text/csv", nil), ErrInvalidPath)
+	})
+
+	t.Run("System files are only available to the services", func(t *testing.T) {
+		fs := setupFileStorage(t, -1)
+
+		_, err := fs.UploadSystemFile(ctx, 1, "thumbnails/abc.png", pngHeader)
+		require.NoError(t, err)
+
+		file, data, err := fs.GetSystemFile(ctx, 1, "thumbnails/abc.png")
+		require.NoError(t, err)
+		assert.Equal(t, pngHeader, data)
+		assert.Equal(t, ".system/thumbnails/abc.png", file.Path)
+
+		_, _, err = fs.Get(ctx, 1, "thumbnails/abc.png")
+		assert.ErrorIs(t, err, ErrFileNotFound)
+		_, _, err = fs.Get(ctx, 1, ".system/thumbnails/abc.png")
+		assert.ErrorIs(t, err, ErrInvalidPath)
+		_, err = fs.Upload(ctx, user, ".system/thumbnails/abc.png", pngHeader)
+		assert.ErrorIs(t, err, ErrInvalidPath)
+		assert.ErrorIs(t, fs.Delete(ctx, 1, ".system/thumbnails/abc.png"), ErrInvalidPath)
+		files, err := fs.List(ctx, 1, ".system")
+		require.NoError(t, err)
+		assert.Empty(t, files)
+
+		_, _, err = fs.GetSystemFile(ctx, 2, "thumbnails/abc.png")
+		assert.ErrorIs(t, err, ErrFileNotFound)
+	})
+
+	t.Run("Uploads are limited by the organization quota", func(t *testing.T) {
+		fs := setupFileStorage(t, 30)
+
+		_, err := fs.Upload(ctx, user, "a.json", []byte(`{"value": "0123456789"}`))
+		require.NoError(t, err)
+
+		_, err = fs.Upload(ctx, user, "b.json", []byte(`{"value": "0123456789"}`))
+		assert.ErrorIs(t, err, ErrQuotaReached)
+
+		// replacing a file only counts the difference
+		_, err = fs.Upload(ctx, user, "a.json", []byte(`{"value": "012345678901"}`))
+		require.NoError(t, err)
+
+		used, err := fs.Usage(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, int64(25), used)
+	})
+}
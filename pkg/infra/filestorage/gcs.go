@@ -0,0 +1,72 @@
+package filestorage
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"path"
+
+	"cloud.google.com/go/storage"
+	"github.com/grafana/grafana/pkg/setting"
+	"google.golang.org/api/option"
+)
+
+// gcsBackend stores files in a Google Cloud Storage bucket.
+type gcsBackend struct {
+	bucket *storage.BucketHandle
+	path   string
+}
+
+func newGCSBackend(ctx context.Context, cfg setting.FileStorageSettings) (*gcsBackend, error) {
+	var opts []option.ClientOption
+	// without a key file the application default credentials are used
+	if cfg.GCSKeyFile != "" {
+		opts = append(opts, option.WithCredentialsFile(cfg.GCSKeyFile))
+	}
+
+	client, err := storage.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gcsBackend{bucket: client.Bucket(cfg.GCSBucket), path: cfg.GCSPath}, nil
+}
+
+func (b *gcsBackend) object(key string) *storage.ObjectHandle {
+	return b.bucket.Object(path.Join(b.path, key))
+}
+
+func (b *gcsBackend) Put(ctx context.Context, key string, contentType string, data []byte) error {
+	w := b.object(key).NewWriter(ctx)
+	w.ContentType = contentType
+	if _, err := w.Write(data); err != nil {
+		_ = w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+func (b *gcsBackend) Get(ctx context.Context, key string) ([]byte, error) {
+	r, err := b.object(key).NewReader(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil, ErrObjectNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := r.Close(); err != nil {
+			logger.Warn("Failed to close GCS object reader", "key", key, "err", err)
+		}
+	}()
+
+	return ioutil.ReadAll(r)
+}
+
+func (b *gcsBackend) Delete(ctx context.Context, key string) error {
+	err := b.object(key).Delete(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return ErrObjectNotFound
+	}
+	return err
+}
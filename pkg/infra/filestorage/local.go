@@ -0,0 +1,48 @@
+package filestorage
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// localBackend stores files in a directory on disk.
+type localBackend struct {
+	root string
+}
+
+func newLocalBackend(root string) *localBackend {
+	return &localBackend{root: root}
+}
+
+func (b *localBackend) filePath(key string) string {
+	return filepath.Join(b.root, filepath.FromSlash(key))
+}
+
+func (b *localBackend) Put(_ context.Context, key string, _ string, data []byte) error {
+	p := b.filePath(key)
+	if err := os.MkdirAll(filepath.Dir(p), 0750); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(p, data, 0640)
+}
+
+func (b *localBackend) Get(_ context.Context, key string) ([]byte, error) {
+	// It's safe to ignore gosec warning G304 since keys are validated before they reach the backend
+	// nolint:gosec
+	data, err := ioutil.ReadFile(b.filePath(key))
+	if os.IsNotExist(err) {
+		return nil, ErrObjectNotFound
+	}
+	return data, err
+}
+
+func (b *localBackend) Delete(_ context.Context, key string) error {
+	err := os.Remove(b.filePath(key))
+	if os.IsNotExist(err) {
+		return ErrObjectNotFound
+	}
+	return err
+}
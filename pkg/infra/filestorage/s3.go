@@ -0,0 +1,87 @@
+package filestorage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"path"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// s3Backend stores files in an S3 compatible bucket.
+type s3Backend struct {
+	client *s3.S3
+	bucket string
+	path   string
+}
+
+func newS3Backend(cfg setting.FileStorageSettings) (*s3Backend, error) {
+	awsCfg := &aws.Config{
+		Region:           aws.String(cfg.S3Region),
+		S3ForcePathStyle: aws.Bool(cfg.S3PathStyleAccess),
+	}
+	if cfg.S3Endpoint != "" {
+		awsCfg.Endpoint = aws.String(cfg.S3Endpoint)
+	}
+	// without static keys the default credential chain (environment, shared config, instance role) is used
+	if cfg.S3AccessKey != "" {
+		awsCfg.Credentials = credentials.NewStaticCredentials(cfg.S3AccessKey, cfg.S3SecretKey, "")
+	}
+
+	sess, err := session.NewSession(awsCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s3Backend{client: s3.New(sess), bucket: cfg.S3Bucket, path: cfg.S3Path}, nil
+}
+
+func (b *s3Backend) objectKey(key string) *string {
+	return aws.String(path.Join(b.path, key))
+}
+
+func (b *s3Backend) Put(ctx context.Context, key string, contentType string, data []byte) error {
+	_, err := b.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(b.bucket),
+		Key:         b.objectKey(key),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String(contentType),
+	})
+	return err
+}
+
+func (b *s3Backend) Get(ctx context.Context, key string) ([]byte, error) {
+	out, err := b.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    b.objectKey(key),
+	})
+	if err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) && awsErr.Code() == s3.ErrCodeNoSuchKey {
+			return nil, ErrObjectNotFound
+		}
+		return nil, err
+	}
+	defer func() {
+		if err := out.Body.Close(); err != nil {
+			logger.Warn("Failed to close S3 object body", "key", key, "err", err)
+		}
+	}()
+
+	return ioutil.ReadAll(out.Body)
+}
+
+func (b *s3Backend) Delete(ctx context.Context, key string) error {
+	_, err := b.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    b.objectKey(key),
+	})
+	return err
+}
@@ -19,6 +19,7 @@ func New(cfg *setting.Cfg) httpclient.Provider {
 		TracingMiddleware(logger),
 		DataSourceMetricsMiddleware(),
 		SetUserAgentMiddleware(userAgent),
+		QueryAuditMiddleware(),
 		sdkhttpclient.BasicAuthenticationMiddleware(),
 		sdkhttpclient.CustomHeadersMiddleware(),
 	}
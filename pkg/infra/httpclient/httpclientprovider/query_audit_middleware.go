@@ -0,0 +1,26 @@
+package httpclientprovider
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+	"github.com/grafana/grafana/pkg/services/queryaudit"
+)
+
+// QueryAuditMiddlewareName is the middleware name used by QueryAuditMiddleware.
+const QueryAuditMiddlewareName = "query-audit"
+
+// QueryAuditMiddleware is middleware that sets the X-Grafana headers of the audited queries of the context of the
+// outgoing request, see queryaudit.WithInfo.
+func QueryAuditMiddleware() httpclient.Middleware {
+	return httpclient.NamedMiddlewareFunc(QueryAuditMiddlewareName, func(opts httpclient.Options, next http.RoundTripper) http.RoundTripper {
+		return httpclient.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if info, ok := queryaudit.FromContext(req.Context()); ok {
+				for name, value := range info.Headers() {
+					req.Header.Set(name, value)
+				}
+			}
+			return next.RoundTrip(req)
+		})
+	})
+}
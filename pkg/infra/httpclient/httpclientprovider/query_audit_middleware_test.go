@@ -0,0 +1,50 @@
+package httpclientprovider
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+	"github.com/grafana/grafana/pkg/services/queryaudit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryAuditMiddleware(t *testing.T) {
+	roundTrip := func(t *testing.T, req *http.Request) {
+		t.Helper()
+		ctx := &testContext{}
+		mw := QueryAuditMiddleware()
+		rt := mw.CreateMiddleware(httpclient.Options{}, ctx.createRoundTripper("final"))
+		require.NotNil(t, rt)
+		middlewareName, ok := mw.(httpclient.MiddlewareName)
+		require.True(t, ok)
+		require.Equal(t, QueryAuditMiddlewareName, middlewareName.MiddlewareName())
+
+		res, err := rt.RoundTrip(req)
+		require.NoError(t, err)
+		require.NotNil(t, res)
+		if res.Body != nil {
+			require.NoError(t, res.Body.Close())
+		}
+		require.Equal(t, []string{"final"}, ctx.callChain)
+	}
+
+	t.Run("Without audited queries should not apply HTTP headers to the request", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "http://", nil)
+		require.NoError(t, err)
+		roundTrip(t, req)
+		require.Empty(t, req.Header)
+	})
+
+	t.Run("With audited queries should apply HTTP headers to the request", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "http://", nil)
+		require.NoError(t, err)
+		info := queryaudit.Info{DashboardUID: "abc", PanelID: 2, UserLogin: "admin", OrgID: 1}
+		req = req.WithContext(queryaudit.WithInfo(req.Context(), info))
+		roundTrip(t, req)
+		require.Equal(t, "abc", req.Header.Get("X-Grafana-Dashboard-Uid"))
+		require.Equal(t, "2", req.Header.Get("X-Grafana-Panel-Id"))
+		require.Equal(t, "admin", req.Header.Get("X-Grafana-User"))
+		require.Equal(t, "1", req.Header.Get("X-Grafana-Org-Id"))
+	})
+}
@@ -0,0 +1,236 @@
+// Package leaderelection elects one of the Grafana servers of a database as
+// the leader running the singleton services, like the alert schedulers and
+// the cleanup jobs, so that running several servers doesn't duplicate their
+// work or send the notifications several times.
+//
+// The leader holds a lease in the database, renewed every renew interval.
+// The other servers acquire the lease once it has expired, so the clocks of
+// the servers have to be synchronized.
+package leaderelection
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// leaseName is the name of the lease of the singleton services.
+const leaseName = "singleton_services"
+
+func init() {
+	registry.RegisterService(&Service{})
+}
+
+// Service elects the leader of the servers. Every server is the leader when
+// the leader election is disabled.
+type Service struct {
+	Cfg      *setting.Cfg       `inject:""`
+	SQLStore *sqlstore.SQLStore `inject:""`
+	log      log.Logger
+
+	mtx      sync.RWMutex
+	leaderID string
+	// leaseExpiry is when the leadership of the server expires unless it's
+	// renewed, zero when it isn't the leader
+	leaseExpiry time.Time
+}
+
+// Status is the leadership status of the server.
+type Status struct {
+	Enabled    bool   `json:"enabled"`
+	Leader     bool   `json:"leader"`
+	InstanceID string `json:"instanceId"`
+	// LeaderID is the instance ID of the leader, empty when it's unknown
+	LeaderID string `json:"leaderId,omitempty"`
+}
+
+func (s *Service) Init() error {
+	s.log = log.New("leaderelection")
+	return nil
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.Cfg.LeaderElection.Enabled
+}
+
+// IsLeader returns whether the server is the leader, true when the leader
+// election is disabled. The leadership is lost once the lease has expired
+// locally, even if the server failed to reach the database.
+func (s *Service) IsLeader() bool {
+	if s == nil || s.Cfg == nil || !s.Cfg.LeaderElection.Enabled {
+		return true
+	}
+
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return time.Now().Before(s.leaseExpiry)
+}
+
+// Status returns the leadership status of the server.
+func (s *Service) Status() Status {
+	if s == nil || s.Cfg == nil || !s.Cfg.LeaderElection.Enabled {
+		return Status{Leader: true}
+	}
+
+	leader := s.IsLeader()
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	status := Status{
+		Enabled:    true,
+		Leader:     leader,
+		InstanceID: s.Cfg.LeaderElection.InstanceID,
+		LeaderID:   s.leaderID,
+	}
+	if leader {
+		status.LeaderID = status.InstanceID
+	}
+	return status
+}
+
+// Run acquires or renews the lease every renew interval, and releases it
+// when the server shuts down so that another server takes over right away.
+func (s *Service) Run(ctx context.Context) error {
+	s.log.Info("Leader election started", "instanceId", s.Cfg.LeaderElection.InstanceID)
+
+	ticker := time.NewTicker(s.Cfg.LeaderElection.RenewInterval)
+	defer ticker.Stop()
+
+	for {
+		s.elect(ctx)
+
+		select {
+		case <-ctx.Done():
+			s.release()
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) elect(ctx context.Context) {
+	wasLeader := s.IsLeader()
+	// the lease expires locally from the time before it's acquired, so that
+	// it doesn't expire later than in the database
+	now := time.Now()
+	acquired, leaderID, err := s.acquireLease(ctx, now)
+	if err != nil {
+		s.log.Error("Failed to acquire the leader lease", "error", err)
+		return
+	}
+
+	s.mtx.Lock()
+	s.leaderID = leaderID
+	if acquired {
+		s.leaseExpiry = now.Add(s.Cfg.LeaderElection.LeaseDuration)
+	} else {
+		s.leaseExpiry = time.Time{}
+	}
+	s.mtx.Unlock()
+
+	switch {
+	case acquired && !wasLeader:
+		s.log.Info("Became the leader", "instanceId", s.Cfg.LeaderElection.InstanceID)
+	case !acquired && wasLeader:
+		s.log.Warn("Lost the leadership", "instanceId", s.Cfg.LeaderElection.InstanceID, "leaderId", leaderID)
+	}
+}
+
+// acquireLease acquires the lease when it's held by the server or has
+// expired, and returns whether it was acquired and the ID of its holder.
+func (s *Service) acquireLease(ctx context.Context, now time.Time) (bool, string, error) {
+	instanceID := s.Cfg.LeaderElection.InstanceID
+
+	lease, err := s.getOrCreate(ctx)
+	if err != nil {
+		return false, "", err
+	}
+	if lease.Holder != instanceID && lease.ExpiresAt > now.UnixNano()/int64(time.Millisecond) {
+		return false, lease.Holder, nil
+	}
+
+	acquiredAt := lease.AcquiredAt
+	if lease.Holder != instanceID {
+		acquiredAt = now.UnixNano() / int64(time.Millisecond)
+	}
+	expiresAt := now.Add(s.Cfg.LeaderElection.LeaseDuration).UnixNano() / int64(time.Millisecond)
+
+	var acquired bool
+	err = s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		sql := `UPDATE leader_lease SET
+			holder = ?,
+			version = ?,
+			acquired_at = ?,
+			expires_at = ?
+		WHERE
+			id = ? AND version = ?`
+
+		res, err := dbSession.Exec(sql, instanceID, lease.Version+1, acquiredAt, expiresAt, lease.Id, lease.Version)
+		if err != nil {
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		acquired = affected == 1
+		return err
+	})
+	if err != nil || !acquired {
+		// another server updated the lease first, its holder is read again
+		// at the next renewal
+		return false, "", err
+	}
+	return true, instanceID, nil
+}
+
+func (s *Service) getOrCreate(ctx context.Context) (*leaderLease, error) {
+	var result *leaderLease
+
+	err := s.SQLStore.WithTransactionalDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		leases := []*leaderLease{}
+		if err := dbSession.Where("name = ?", leaseName).Find(&leases); err != nil {
+			return err
+		}
+
+		if len(leases) > 0 {
+			result = leases[0]
+			return nil
+		}
+
+		lease := &leaderLease{Name: leaseName}
+		if _, err := dbSession.Insert(lease); err != nil {
+			return err
+		}
+
+		result = lease
+		return nil
+	})
+
+	return result, err
+}
+
+// release expires the lease when it's held by the server.
+func (s *Service) release() {
+	if !s.IsLeader() {
+		return
+	}
+
+	s.mtx.Lock()
+	s.leaseExpiry = time.Time{}
+	s.mtx.Unlock()
+
+	// the context of the service is already canceled
+	err := s.SQLStore.WithDbSession(context.Background(), func(dbSession *sqlstore.DBSession) error {
+		_, err := dbSession.Exec("UPDATE leader_lease SET version = version + 1, expires_at = 0 WHERE name = ? AND holder = ?",
+			leaseName, s.Cfg.LeaderElection.InstanceID)
+		return err
+	})
+	if err != nil {
+		s.log.Error("Failed to release the leader lease", "error", err)
+		return
+	}
+	s.log.Info("Released the leadership", "instanceId", s.Cfg.LeaderElection.InstanceID)
+}
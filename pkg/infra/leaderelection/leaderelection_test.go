@@ -0,0 +1,92 @@
+package leaderelection
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func createTestService(t *testing.T, sqlStore *sqlstore.SQLStore, instanceID string) *Service {
+	t.Helper()
+
+	cfg := setting.NewCfg()
+	cfg.LeaderElection = setting.LeaderElectionSettings{
+		Enabled:       true,
+		InstanceID:    instanceID,
+		LeaseDuration: time.Minute,
+		RenewInterval: 20 * time.Second,
+	}
+	s := &Service{Cfg: cfg, SQLStore: sqlStore}
+	require.NoError(t, s.Init())
+	return s
+}
+
+func TestLeaderElection(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	first := createTestService(t, sqlStore, "first")
+	second := createTestService(t, sqlStore, "second")
+	ctx := context.Background()
+
+	t.Run("The first server acquiring the lease is the leader", func(t *testing.T) {
+		first.elect(ctx)
+		second.elect(ctx)
+
+		assert.True(t, first.IsLeader())
+		assert.False(t, second.IsLeader())
+		assert.Equal(t, Status{Enabled: true, Leader: false, InstanceID: "second", LeaderID: "first"}, second.Status())
+	})
+
+	t.Run("The leader renews its lease", func(t *testing.T) {
+		first.elect(ctx)
+		second.elect(ctx)
+
+		assert.True(t, first.IsLeader())
+		assert.False(t, second.IsLeader())
+	})
+
+	t.Run("Another server acquires the lease once it has expired", func(t *testing.T) {
+		err := sqlStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+			_, err := dbSession.Exec("UPDATE leader_lease SET expires_at = 1")
+			return err
+		})
+		require.NoError(t, err)
+
+		second.elect(ctx)
+		first.elect(ctx)
+
+		assert.True(t, second.IsLeader())
+		assert.False(t, first.IsLeader())
+		assert.Equal(t, "second", first.Status().LeaderID)
+	})
+
+	t.Run("Another server acquires the lease released by the leader", func(t *testing.T) {
+		second.release()
+		assert.False(t, second.IsLeader())
+
+		first.elect(ctx)
+		assert.True(t, first.IsLeader())
+	})
+
+	t.Run("The leadership expires locally", func(t *testing.T) {
+		first.mtx.Lock()
+		first.leaseExpiry = time.Now().Add(-time.Second)
+		first.mtx.Unlock()
+
+		assert.False(t, first.IsLeader())
+	})
+}
+
+func TestLeaderElectionDisabled(t *testing.T) {
+	s := &Service{Cfg: setting.NewCfg()}
+	assert.True(t, s.IsLeader())
+	assert.Equal(t, Status{Leader: true}, s.Status())
+
+	var nilService *Service
+	assert.True(t, nilService.IsLeader())
+}
@@ -0,0 +1,14 @@
+package leaderelection
+
+type leaderLease struct {
+	// nolint:stylecheck
+	Id     int64
+	Name   string
+	Holder string
+	// Version is incremented by every update, the updates of a lease are
+	// conditional to the version read
+	Version int64
+	// AcquiredAt and ExpiresAt are unix times in milliseconds
+	AcquiredAt int64
+	ExpiresAt  int64
+}
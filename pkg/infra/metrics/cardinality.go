@@ -0,0 +1,250 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gobwas/glob"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Modes of the high cardinality labels.
+const (
+	// HighCardinalityLabelsKeep exposes the high cardinality labels.
+	HighCardinalityLabelsKeep = "keep"
+	// HighCardinalityLabelsAggregate removes the high cardinality labels and merges the series which only differed
+	// by them.
+	HighCardinalityLabelsAggregate = "aggregate"
+	// HighCardinalityLabelsDrop removes the series with high cardinality labels.
+	HighCardinalityLabelsDrop = "drop"
+)
+
+// FilterOptions are the options of the filtering of the gathered metrics.
+type FilterOptions struct {
+	// HighCardinalityLabels are the names of the labels of the internal metrics with a value per dashboard or data
+	// source.
+	HighCardinalityLabels []string
+	// HighCardinalityLabelsMode is how the high cardinality labels are handled, it defaults to keeping them.
+	HighCardinalityLabelsMode string
+	// Allowlist are the glob patterns of the names of the exposed metrics, all of them are exposed without patterns.
+	Allowlist []string
+}
+
+type filteringGatherer struct {
+	gatherer  prometheus.Gatherer
+	labels    map[string]bool
+	mode      string
+	allowlist []glob.Glob
+}
+
+// NewFilteringGatherer returns a gatherer of the metrics of the gatherer, without the metrics outside of the
+// allowlist and with the high cardinality labels handled according to their mode. The gatherer is returned as is
+// without filtering.
+func NewFilteringGatherer(gatherer prometheus.Gatherer, opts FilterOptions) (prometheus.Gatherer, error) {
+	g := &filteringGatherer{
+		gatherer: gatherer,
+		labels:   map[string]bool{},
+		mode:     opts.HighCardinalityLabelsMode,
+	}
+
+	switch g.mode {
+	case "":
+		g.mode = HighCardinalityLabelsKeep
+	case HighCardinalityLabelsKeep, HighCardinalityLabelsAggregate, HighCardinalityLabelsDrop:
+	default:
+		return nil, fmt.Errorf("invalid high cardinality labels mode %q", g.mode)
+	}
+
+	for _, label := range opts.HighCardinalityLabels {
+		g.labels[label] = true
+	}
+
+	for _, pattern := range opts.Allowlist {
+		compiled, err := glob.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid metrics allowlist pattern %q: %w", pattern, err)
+		}
+		g.allowlist = append(g.allowlist, compiled)
+	}
+
+	if len(g.allowlist) == 0 && (g.mode == HighCardinalityLabelsKeep || len(g.labels) == 0) {
+		return gatherer, nil
+	}
+	return g, nil
+}
+
+// Gather implements prometheus.Gatherer. The metrics gathered despite an error are filtered and returned with it,
+// like the registry does.
+func (g *filteringGatherer) Gather() ([]*dto.MetricFamily, error) {
+	families, err := g.gatherer.Gather()
+
+	filtered := make([]*dto.MetricFamily, 0, len(families))
+	for _, family := range families {
+		if !g.allowed(family.GetName()) {
+			continue
+		}
+
+		switch g.mode {
+		case HighCardinalityLabelsDrop:
+			family = g.drop(family)
+		case HighCardinalityLabelsAggregate:
+			family = g.aggregate(family)
+		}
+		if len(family.Metric) > 0 {
+			filtered = append(filtered, family)
+		}
+	}
+
+	return filtered, err
+}
+
+func (g *filteringGatherer) allowed(name string) bool {
+	if len(g.allowlist) == 0 {
+		return true
+	}
+	for _, pattern := range g.allowlist {
+		if pattern.Match(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *filteringGatherer) hasHighCardinalityLabel(metric *dto.Metric) bool {
+	for _, label := range metric.Label {
+		if g.labels[label.GetName()] {
+			return true
+		}
+	}
+	return false
+}
+
+// drop returns the family without the series with high cardinality labels.
+func (g *filteringGatherer) drop(family *dto.MetricFamily) *dto.MetricFamily {
+	metrics := make([]*dto.Metric, 0, len(family.Metric))
+	for _, metric := range family.Metric {
+		if !g.hasHighCardinalityLabel(metric) {
+			metrics = append(metrics, metric)
+		}
+	}
+
+	return &dto.MetricFamily{Name: family.Name, Help: family.Help, Type: family.Type, Metric: metrics}
+}
+
+// aggregate returns the family without the high cardinality labels, the series with the same remaining labels are
+// merged into one. Since quantiles can't be merged, the merged summaries only have their count and sum.
+func (g *filteringGatherer) aggregate(family *dto.MetricFamily) *dto.MetricFamily {
+	metrics := make([]*dto.Metric, 0, len(family.Metric))
+	byLabels := map[string]*dto.Metric{}
+
+	for _, metric := range family.Metric {
+		if !g.hasHighCardinalityLabel(metric) {
+			metrics = append(metrics, metric)
+			continue
+		}
+
+		labels := make([]*dto.LabelPair, 0, len(metric.Label))
+		keyParts := make([]string, 0, len(metric.Label))
+		for _, label := range metric.Label {
+			if g.labels[label.GetName()] {
+				continue
+			}
+			labels = append(labels, label)
+			keyParts = append(keyParts, label.GetName()+"="+label.GetValue())
+		}
+		key := strings.Join(keyParts, "\xff")
+
+		merged, ok := byLabels[key]
+		if !ok {
+			merged = copyMetric(metric, labels)
+			byLabels[key] = merged
+			metrics = append(metrics, merged)
+			continue
+		}
+		mergeMetric(merged, metric)
+	}
+
+	return &dto.MetricFamily{Name: family.Name, Help: family.Help, Type: family.Type, Metric: metrics}
+}
+
+func copyMetric(metric *dto.Metric, labels []*dto.LabelPair) *dto.Metric {
+	c := &dto.Metric{Label: labels}
+	if metric.Counter != nil {
+		c.Counter = &dto.Counter{Value: float64Ptr(metric.Counter.GetValue())}
+	}
+	if metric.Gauge != nil {
+		c.Gauge = &dto.Gauge{Value: float64Ptr(metric.Gauge.GetValue())}
+	}
+	if metric.Untyped != nil {
+		c.Untyped = &dto.Untyped{Value: float64Ptr(metric.Untyped.GetValue())}
+	}
+	if metric.Summary != nil {
+		c.Summary = &dto.Summary{
+			SampleCount: uint64Ptr(metric.Summary.GetSampleCount()),
+			SampleSum:   float64Ptr(metric.Summary.GetSampleSum()),
+			Quantile:    metric.Summary.Quantile,
+		}
+	}
+	if metric.Histogram != nil {
+		c.Histogram = &dto.Histogram{
+			SampleCount: uint64Ptr(metric.Histogram.GetSampleCount()),
+			SampleSum:   float64Ptr(metric.Histogram.GetSampleSum()),
+		}
+		for _, bucket := range metric.Histogram.Bucket {
+			c.Histogram.Bucket = append(c.Histogram.Bucket, &dto.Bucket{
+				UpperBound:      float64Ptr(bucket.GetUpperBound()),
+				CumulativeCount: uint64Ptr(bucket.GetCumulativeCount()),
+			})
+		}
+	}
+	return c
+}
+
+func mergeMetric(merged *dto.Metric, metric *dto.Metric) {
+	if merged.Counter != nil && metric.Counter != nil {
+		*merged.Counter.Value += metric.Counter.GetValue()
+	}
+	if merged.Gauge != nil && metric.Gauge != nil {
+		*merged.Gauge.Value += metric.Gauge.GetValue()
+	}
+	if merged.Untyped != nil && metric.Untyped != nil {
+		*merged.Untyped.Value += metric.Untyped.GetValue()
+	}
+	if merged.Summary != nil && metric.Summary != nil {
+		*merged.Summary.SampleCount += metric.Summary.GetSampleCount()
+		*merged.Summary.SampleSum += metric.Summary.GetSampleSum()
+		merged.Summary.Quantile = nil
+	}
+	if merged.Histogram != nil && metric.Histogram != nil {
+		*merged.Histogram.SampleCount += metric.Histogram.GetSampleCount()
+		*merged.Histogram.SampleSum += metric.Histogram.GetSampleSum()
+		merged.Histogram.Bucket = mergeBuckets(merged.Histogram.Bucket, metric.Histogram.Bucket)
+	}
+}
+
+// mergeBuckets returns the sum of the cumulative buckets, by upper bound.
+func mergeBuckets(a, b []*dto.Bucket) []*dto.Bucket {
+	counts := map[float64]uint64{}
+	for _, buckets := range [][]*dto.Bucket{a, b} {
+		for _, bucket := range buckets {
+			counts[bucket.GetUpperBound()] += bucket.GetCumulativeCount()
+		}
+	}
+
+	merged := make([]*dto.Bucket, 0, len(counts))
+	for upperBound, count := range counts {
+		merged = append(merged, &dto.Bucket{UpperBound: float64Ptr(upperBound), CumulativeCount: uint64Ptr(count)})
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].GetUpperBound() < merged[j].GetUpperBound() })
+	return merged
+}
+
+func float64Ptr(v float64) *float64 {
+	return &v
+}
+
+func uint64Ptr(v uint64) *uint64 {
+	return &v
+}
@@ -0,0 +1,124 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+)
+
+func newCardinalityTestRegistry(t *testing.T) *prometheus.Registry {
+	t.Helper()
+
+	registry := prometheus.NewRegistry()
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_requests_total", Help: "requests"},
+		[]string{"datasource", "code"})
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "test_duration_seconds", Help: "duration",
+		Buckets: []float64{1, 10}}, []string{"datasource"})
+	latency := prometheus.NewSummaryVec(prometheus.SummaryOpts{Name: "test_latency_seconds", Help: "latency",
+		Objectives: map[float64]float64{0.5: 0.05}}, []string{"datasource"})
+	up := prometheus.NewGauge(prometheus.GaugeOpts{Name: "test_up", Help: "up"})
+	registry.MustRegister(requests, duration, latency, up)
+
+	requests.WithLabelValues("a", "200").Add(1)
+	requests.WithLabelValues("b", "200").Add(2)
+	requests.WithLabelValues("b", "500").Add(4)
+	duration.WithLabelValues("a").Observe(0.5)
+	duration.WithLabelValues("b").Observe(5)
+	latency.WithLabelValues("a").Observe(1)
+	latency.WithLabelValues("b").Observe(2)
+	up.Set(1)
+
+	return registry
+}
+
+func gatherFamilies(t *testing.T, gatherer prometheus.Gatherer) map[string]*dto.MetricFamily {
+	t.Helper()
+
+	families, err := gatherer.Gather()
+	require.NoError(t, err)
+	byName := map[string]*dto.MetricFamily{}
+	for _, family := range families {
+		byName[family.GetName()] = family
+	}
+	return byName
+}
+
+func TestFilteringGatherer(t *testing.T) {
+	t.Run("Keeps the labels by default", func(t *testing.T) {
+		registry := newCardinalityTestRegistry(t)
+		gatherer, err := NewFilteringGatherer(registry, FilterOptions{HighCardinalityLabels: []string{"datasource"}})
+		require.NoError(t, err)
+		require.Same(t, registry, gatherer)
+	})
+
+	t.Run("Aggregates the high cardinality labels", func(t *testing.T) {
+		gatherer, err := NewFilteringGatherer(newCardinalityTestRegistry(t), FilterOptions{
+			HighCardinalityLabels:     []string{"datasource"},
+			HighCardinalityLabelsMode: HighCardinalityLabelsAggregate,
+		})
+		require.NoError(t, err)
+		families := gatherFamilies(t, gatherer)
+		require.Len(t, families, 4)
+
+		requests := families["test_requests_total"].Metric
+		require.Len(t, requests, 2)
+		for _, metric := range requests {
+			require.Len(t, metric.Label, 1)
+			require.Equal(t, "code", metric.Label[0].GetName())
+			switch metric.Label[0].GetValue() {
+			case "200":
+				require.Equal(t, float64(3), metric.Counter.GetValue())
+			case "500":
+				require.Equal(t, float64(4), metric.Counter.GetValue())
+			}
+		}
+
+		duration := families["test_duration_seconds"].Metric
+		require.Len(t, duration, 1)
+		require.Empty(t, duration[0].Label)
+		require.Equal(t, uint64(2), duration[0].Histogram.GetSampleCount())
+		require.Equal(t, 5.5, duration[0].Histogram.GetSampleSum())
+		require.Len(t, duration[0].Histogram.Bucket, 2)
+		require.Equal(t, uint64(1), duration[0].Histogram.Bucket[0].GetCumulativeCount())
+		require.Equal(t, uint64(2), duration[0].Histogram.Bucket[1].GetCumulativeCount())
+
+		latency := families["test_latency_seconds"].Metric
+		require.Len(t, latency, 1)
+		require.Equal(t, uint64(2), latency[0].Summary.GetSampleCount())
+		require.Empty(t, latency[0].Summary.Quantile)
+
+		require.Equal(t, float64(1), families["test_up"].Metric[0].Gauge.GetValue())
+	})
+
+	t.Run("Drops the series with high cardinality labels", func(t *testing.T) {
+		gatherer, err := NewFilteringGatherer(newCardinalityTestRegistry(t), FilterOptions{
+			HighCardinalityLabels:     []string{"datasource"},
+			HighCardinalityLabelsMode: HighCardinalityLabelsDrop,
+		})
+		require.NoError(t, err)
+		families := gatherFamilies(t, gatherer)
+		require.Len(t, families, 1)
+		require.Contains(t, families, "test_up")
+	})
+
+	t.Run("Only exposes the metrics of the allowlist", func(t *testing.T) {
+		gatherer, err := NewFilteringGatherer(newCardinalityTestRegistry(t), FilterOptions{
+			Allowlist: []string{"test_up", "test_*_seconds"},
+		})
+		require.NoError(t, err)
+		families := gatherFamilies(t, gatherer)
+		require.Len(t, families, 3)
+		require.NotContains(t, families, "test_requests_total")
+		require.Len(t, families["test_duration_seconds"].Metric, 2)
+	})
+
+	t.Run("Invalid options", func(t *testing.T) {
+		_, err := NewFilteringGatherer(prometheus.NewRegistry(), FilterOptions{HighCardinalityLabelsMode: "sum"})
+		require.Error(t, err)
+
+		_, err = NewFilteringGatherer(prometheus.NewRegistry(), FilterOptions{Allowlist: []string{"test_["}})
+		require.Error(t, err)
+	})
+}
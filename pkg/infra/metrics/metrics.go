@@ -108,6 +108,9 @@ var (
 
 	// MAccessEvaluationCount is a metric gauge for total number of evaluation requests
 	MAccessEvaluationCount prometheus.Counter
+
+	// MDataSourceQueryTotal is a metric counter for data source queries
+	MDataSourceQueryTotal prometheus.Counter
 )
 
 // Timers
@@ -133,6 +136,9 @@ var (
 	// MAlertingActiveAlerts is a metric amount of active alerts
 	MAlertingActiveAlerts prometheus.Gauge
 
+	// MAlertingEvaluationLag is a metric of the lag of the last alert evaluation
+	MAlertingEvaluationLag prometheus.Gauge
+
 	// MStatTotalDashboards is a metric total amount of dashboards
 	MStatTotalDashboards prometheus.Gauge
 
@@ -428,6 +434,12 @@ func init() {
 		Namespace: ExporterName,
 	})
 
+	MAlertingEvaluationLag = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:      "alerting_evaluation_lag_seconds",
+		Help:      "delay between the scheduled time of the last alert evaluation and its start",
+		Namespace: ExporterName,
+	})
+
 	MStatTotalDashboards = prometheus.NewGauge(prometheus.GaugeOpts{
 		Name:      "stat_totals_dashboard",
 		Help:      "total amount of dashboards",
@@ -554,6 +566,12 @@ func init() {
 		Namespace: ExporterName,
 	})
 
+	MDataSourceQueryTotal = newCounterStartingAtZero(prometheus.CounterOpts{
+		Name:      "datasource_queries_total",
+		Help:      "total number of data source queries",
+		Namespace: ExporterName,
+	})
+
 	StatsTotalLibraryPanels = prometheus.NewGauge(prometheus.GaugeOpts{
 		Name:      "stat_totals_library_panels",
 		Help:      "total amount of library panels in the database",
@@ -640,6 +658,7 @@ func initMetricVars() {
 		MAccessPermissionsSummary,
 		MAccessEvaluationsSummary,
 		MAlertingActiveAlerts,
+		MAlertingEvaluationLag,
 		MStatTotalDashboards,
 		MStatTotalFolders,
 		MStatTotalUsers,
@@ -658,6 +677,7 @@ func initMetricVars() {
 		StatsTotalDashboardVersions,
 		StatsTotalAnnotations,
 		MAccessEvaluationCount,
+		MDataSourceQueryTotal,
 		StatsTotalLibraryPanels,
 		StatsTotalLibraryVariables,
 	)
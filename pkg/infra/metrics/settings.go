@@ -36,11 +36,19 @@ func (im *InternalMetricsService) parseGraphiteSettings() error {
 		return nil
 	}
 
+	gatherer, err := NewFilteringGatherer(prometheus.DefaultGatherer, FilterOptions{
+		HighCardinalityLabels:     im.Cfg.MetricsHighCardinalityLabels,
+		HighCardinalityLabelsMode: im.Cfg.MetricsHighCardinalityLabelsMode,
+	})
+	if err != nil {
+		return err
+	}
+
 	bridgeCfg := &graphitebridge.Config{
 		URL:             address,
 		Prefix:          graphiteSection.Key("prefix").MustString("prod.grafana.%(instance_name)s"),
 		CountersAsDelta: true,
-		Gatherer:        prometheus.DefaultGatherer,
+		Gatherer:        gatherer,
 		Interval:        time.Duration(im.intervalSeconds) * time.Second,
 		Timeout:         10 * time.Second,
 		Logger:          &logWrapper{logger: metricsLogger},
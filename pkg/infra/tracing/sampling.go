@@ -0,0 +1,235 @@
+package tracing
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/uber/jaeger-client-go"
+)
+
+const (
+	// requestOperationPrefix is the prefix of the operation names of the
+	// spans of the HTTP requests.
+	requestOperationPrefix = "HTTP "
+	// maxPendingSpans is the maximum number of spans of a request kept until
+	// its sampling decision, the next ones are dropped.
+	maxPendingSpans = 1000
+	// maxRandomNumber is the mask of the trace IDs compared to the sampling
+	// rates, the same as the probabilistic sampler of Jaeger.
+	maxRandomNumber = ^(uint64(1) << 63)
+
+	samplingReasonTagKey = "sampling.reason"
+)
+
+// samplingPolicy decides whether the traces of the HTTP requests are
+// reported, at the end of the requests. The errors and slow requests are
+// always reported, the other ones at the rate of their route.
+type samplingPolicy struct {
+	defaultRate float64
+	// routeRates are the rates of the path prefixes, the longest first
+	routeRates []routeRate
+	// errorStatus is the lowest status of the errors, 0 if they aren't
+	// always reported
+	errorStatus int
+	// slowThreshold is the duration of the slow requests, 0 if they aren't
+	// always reported
+	slowThreshold time.Duration
+	appSubURL     string
+}
+
+type routeRate struct {
+	prefix string
+	rate   float64
+}
+
+// parseRouteRates parses the rates of the path prefixes, from a comma
+// separated list of <path prefix>:<rate>.
+func parseRouteRates(input string) ([]routeRate, error) {
+	var rates []routeRate
+	for _, v := range strings.Split(input, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		i := strings.LastIndex(v, ":")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid route sampling rate %q, expected <path prefix>:<rate>", v)
+		}
+		rate, err := strconv.ParseFloat(v[i+1:], 64)
+		if err != nil || rate < 0 || rate > 1 {
+			return nil, fmt.Errorf("invalid route sampling rate %q, the rate must be between 0 and 1", v)
+		}
+		rates = append(rates, routeRate{prefix: v[:i], rate: rate})
+	}
+	sort.SliceStable(rates, func(i, j int) bool {
+		return len(rates[i].prefix) > len(rates[j].prefix)
+	})
+	return rates, nil
+}
+
+// rate returns the sampling rate of the requests to a path.
+func (p *samplingPolicy) rate(path string) float64 {
+	for _, r := range p.routeRates {
+		if path == r.prefix || strings.HasPrefix(path, strings.TrimSuffix(r.prefix, "/")+"/") {
+			return r.rate
+		}
+	}
+	return p.defaultRate
+}
+
+// sample returns whether the trace of a request is reported, and the reason.
+// The decision of the rate is based on the trace ID, like the probabilistic
+// sampler of Jaeger.
+func (p *samplingPolicy) sample(traceID jaeger.TraceID, path string, status int, duration time.Duration) (bool, string) {
+	if p.errorStatus > 0 && status >= p.errorStatus {
+		return true, "error"
+	}
+	if p.slowThreshold > 0 && duration >= p.slowThreshold {
+		return true, "slow"
+	}
+
+	rate := p.rate(strings.TrimPrefix(path, p.appSubURL))
+	boundary := uint64(float64(maxRandomNumber) * rate)
+	if traceID.Low&maxRandomNumber < boundary {
+		return true, "rate"
+	}
+	return false, ""
+}
+
+// requestSampler samples all the traces of the HTTP requests, so that they
+// are recorded until their sampling decision at the end of the requests,
+// and delegates the sampling of the other traces.
+type requestSampler struct {
+	jaeger.Sampler
+}
+
+func (s *requestSampler) IsSampled(id jaeger.TraceID, operation string) (bool, []jaeger.Tag) {
+	if strings.HasPrefix(operation, requestOperationPrefix) {
+		return true, []jaeger.Tag{jaeger.NewTag(jaeger.SamplerTypeTagKey, "request")}
+	}
+	return s.Sampler.IsSampled(id, operation)
+}
+
+func (s *requestSampler) Equal(other jaeger.Sampler) bool {
+	if o, ok := other.(*requestSampler); ok {
+		return s.Sampler.Equal(o.Sampler)
+	}
+	return false
+}
+
+// samplingReporter keeps the spans of the traces of the HTTP requests until
+// their sampling decision, and reports the spans of the other traces.
+type samplingReporter struct {
+	jaeger.Reporter
+
+	mu      sync.Mutex
+	pending map[jaeger.TraceID][]*jaeger.Span
+}
+
+func newSamplingReporter(reporter jaeger.Reporter) *samplingReporter {
+	return &samplingReporter{
+		Reporter: reporter,
+		pending:  map[jaeger.TraceID][]*jaeger.Span{},
+	}
+}
+
+func (r *samplingReporter) Report(span *jaeger.Span) {
+	traceID := span.SpanContext().TraceID()
+
+	r.mu.Lock()
+	if spans, ok := r.pending[traceID]; ok {
+		if len(spans) < maxPendingSpans {
+			r.pending[traceID] = append(spans, span.Retain())
+		}
+		r.mu.Unlock()
+		return
+	}
+	r.mu.Unlock()
+
+	r.Reporter.Report(span)
+}
+
+// start keeps the spans of a trace until finish is called.
+func (r *samplingReporter) start(traceID jaeger.TraceID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pending[traceID] = []*jaeger.Span{}
+}
+
+// finish reports or drops the kept spans of a trace, the spans finished
+// afterwards are reported.
+func (r *samplingReporter) finish(traceID jaeger.TraceID, report bool) {
+	r.mu.Lock()
+	spans := r.pending[traceID]
+	delete(r.pending, traceID)
+	r.mu.Unlock()
+
+	for _, span := range spans {
+		if report {
+			r.Reporter.Report(span)
+		}
+		span.Release()
+	}
+}
+
+// RequestSampling is the sampling of the trace of an HTTP request, decided
+// at the end of the request. The methods of a nil RequestSampling do
+// nothing, it's nil when the sampling policies are disabled or the trace
+// was sampled by the caller.
+type RequestSampling struct {
+	policy   *samplingPolicy
+	reporter *samplingReporter
+	traceID  jaeger.TraceID
+	report   bool
+}
+
+// StartRequestSampling starts the sampling of the trace of the span of an
+// HTTP request, the spans of the trace are kept until Finish is called.
+func (ts *TracingService) StartRequestSampling(span opentracing.Span) *RequestSampling {
+	if ts == nil || ts.samplingReporter == nil {
+		return nil
+	}
+	jaegerSpan, ok := span.(*jaeger.Span)
+	if !ok {
+		return nil
+	}
+	spanContext := jaegerSpan.SpanContext()
+	if spanContext.ParentID() != 0 || !spanContext.IsSampled() {
+		return nil
+	}
+
+	ts.samplingReporter.start(spanContext.TraceID())
+	return &RequestSampling{
+		policy:   ts.samplingPolicy,
+		reporter: ts.samplingReporter,
+		traceID:  spanContext.TraceID(),
+	}
+}
+
+// Decide decides whether the trace is reported from the path, status and
+// duration of the request, and tags the span of the request with the reason.
+// It must be called before the span is finished.
+func (s *RequestSampling) Decide(span opentracing.Span, path string, status int, duration time.Duration) {
+	if s == nil {
+		return
+	}
+	var reason string
+	s.report, reason = s.policy.sample(s.traceID, path, status, duration)
+	if s.report {
+		span.SetTag(samplingReasonTagKey, reason)
+	}
+}
+
+// Finish reports the kept spans of the trace when it was decided so, or
+// drops them.
+func (s *RequestSampling) Finish() {
+	if s == nil {
+		return
+	}
+	s.reporter.finish(s.traceID, s.report)
+}
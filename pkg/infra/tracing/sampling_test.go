@@ -0,0 +1,112 @@
+package tracing
+
+import (
+	"testing"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber/jaeger-client-go"
+)
+
+func TestParseRouteRates(t *testing.T) {
+	rates, err := parseRouteRates("/api:0.1, /api/ds/query:0.5,/login:1")
+	require.NoError(t, err)
+	assert.Equal(t, []routeRate{
+		{prefix: "/api/ds/query", rate: 0.5},
+		{prefix: "/login", rate: 1},
+		{prefix: "/api", rate: 0.1},
+	}, rates)
+
+	rates, err = parseRouteRates("")
+	require.NoError(t, err)
+	assert.Empty(t, rates)
+
+	_, err = parseRouteRates("/api")
+	require.Error(t, err)
+	_, err = parseRouteRates("/api:2")
+	require.Error(t, err)
+}
+
+func TestSamplingPolicy(t *testing.T) {
+	policy := &samplingPolicy{
+		defaultRate:   0,
+		routeRates:    []routeRate{{prefix: "/api/ds/query", rate: 1}, {prefix: "/api", rate: 0}},
+		errorStatus:   500,
+		slowThreshold: time.Second,
+		appSubURL:     "/grafana",
+	}
+	traceID := jaeger.TraceID{Low: 42}
+
+	tests := []struct {
+		desc     string
+		path     string
+		status   int
+		duration time.Duration
+		sampled  bool
+		reason   string
+	}{
+		{desc: "errors are sampled", path: "/api/search", status: 502, sampled: true, reason: "error"},
+		{desc: "slow requests are sampled", path: "/api/search", status: 200, duration: 2 * time.Second, sampled: true, reason: "slow"},
+		{desc: "the rate of the longest prefix is used", path: "/grafana/api/ds/query", status: 200, sampled: true, reason: "rate"},
+		{desc: "the prefixes match whole path segments", path: "/grafana/api/ds/querying", status: 200},
+		{desc: "other requests use the default rate", path: "/grafana/d/uid", status: 404},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			sampled, reason := policy.sample(traceID, tc.path, tc.status, tc.duration)
+			assert.Equal(t, tc.sampled, sampled)
+			assert.Equal(t, tc.reason, reason)
+		})
+	}
+}
+
+func TestRequestSampling(t *testing.T) {
+	inMemory := jaeger.NewInMemoryReporter()
+	ts := &TracingService{
+		samplingPolicy:   &samplingPolicy{errorStatus: 500},
+		samplingReporter: newSamplingReporter(inMemory),
+	}
+	tracer, closer := jaeger.NewTracer("grafana", &requestSampler{Sampler: jaeger.NewConstSampler(false)}, ts.samplingReporter)
+	t.Cleanup(func() { _ = closer.Close() })
+
+	request := func(status int) {
+		span := tracer.StartSpan("HTTP GET /api/search")
+		sampling := ts.StartRequestSampling(span)
+		require.NotNil(t, sampling)
+		tracer.StartSpan("bus - SearchQuery", opentracing.ChildOf(span.Context())).Finish()
+		sampling.Decide(span, "/api/search", status, time.Millisecond)
+		span.Finish()
+		sampling.Finish()
+	}
+
+	t.Run("Drops the spans of the requests which aren't sampled", func(t *testing.T) {
+		request(200)
+		assert.Empty(t, inMemory.GetSpans())
+	})
+
+	t.Run("Reports the spans of the sampled requests", func(t *testing.T) {
+		request(500)
+		spans := inMemory.GetSpans()
+		require.Len(t, spans, 2)
+		assert.Equal(t, "error", spans[1].(*jaeger.Span).Tags()[samplingReasonTagKey])
+	})
+
+	t.Run("Delegates the sampling of the other traces", func(t *testing.T) {
+		inMemory.Reset()
+		span := tracer.StartSpan("alert execution")
+		assert.Nil(t, ts.StartRequestSampling(span))
+		span.Finish()
+		assert.Empty(t, inMemory.GetSpans())
+	})
+
+	t.Run("Is disabled without sampling policies", func(t *testing.T) {
+		var disabled *TracingService
+		sampling := disabled.StartRequestSampling(tracer.StartSpan("HTTP GET /api/search"))
+		assert.Nil(t, sampling)
+		// the methods of a nil sampling do nothing
+		sampling.Decide(nil, "/api/search", 500, 0)
+		sampling.Finish()
+	})
+}
@@ -6,12 +6,14 @@ import (
 	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/registry"
 	"github.com/grafana/grafana/pkg/setting"
 
 	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/uber/jaeger-client-go"
 	jaegercfg "github.com/uber/jaeger-client-go/config"
 	"github.com/uber/jaeger-client-go/zipkin"
 )
@@ -36,6 +38,10 @@ type TracingService struct {
 	closer                   io.Closer
 	zipkinPropagation        bool
 	disableSharedZipkinSpans bool
+	// samplingPolicy is the policy of the sampling of the HTTP requests, nil
+	// when their traces are sampled like the other ones
+	samplingPolicy   *samplingPolicy
+	samplingReporter *samplingReporter
 
 	Cfg *setting.Cfg `inject:""`
 }
@@ -77,6 +83,20 @@ func (ts *TracingService) parseSettings() error {
 	ts.zipkinPropagation = section.Key("zipkin_propagation").MustBool(false)
 	ts.disableSharedZipkinSpans = section.Key("disable_shared_zipkin_spans").MustBool(false)
 	ts.samplingServerURL = section.Key("sampling_server_url").MustString("")
+
+	if section.Key("request_sampling_enabled").MustBool(false) {
+		routeRates, err := parseRouteRates(section.Key("request_sampling_route_rates").MustString(""))
+		if err != nil {
+			return err
+		}
+		ts.samplingPolicy = &samplingPolicy{
+			defaultRate:   section.Key("request_sampling_rate").MustFloat64(0.1),
+			routeRates:    routeRates,
+			errorStatus:   section.Key("request_sampling_error_status").MustInt(500),
+			slowThreshold: section.Key("request_sampling_slow_threshold").MustDuration(time.Second),
+			appSubURL:     ts.Cfg.AppSubURL,
+		}
+	}
 	return nil
 }
 
@@ -117,6 +137,24 @@ func (ts *TracingService) initGlobalTracer() error {
 		options = append(options, jaegercfg.Tag(tag, value))
 	}
 
+	if ts.samplingPolicy != nil {
+		// the traces of the HTTP requests are recorded, and reported by the
+		// reporter once their sampling is decided
+		sampler, err := cfg.Sampler.NewSampler(cfg.ServiceName, jaeger.NewNullMetrics())
+		if err != nil {
+			return err
+		}
+		reporter, err := cfg.Reporter.NewReporter(cfg.ServiceName, jaeger.NewNullMetrics(), jLogger)
+		if err != nil {
+			return err
+		}
+		ts.samplingReporter = newSamplingReporter(reporter)
+		options = append(options,
+			jaegercfg.Sampler(&requestSampler{Sampler: sampler}),
+			jaegercfg.Reporter(ts.samplingReporter),
+		)
+	}
+
 	if ts.zipkinPropagation {
 		zipkinPropagator := zipkin.NewZipkinB3HTTPHeaderPropagator()
 		options = append(options,
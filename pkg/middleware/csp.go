@@ -6,16 +6,19 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
 	"strings"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/setting"
 	macaron "gopkg.in/macaron.v1"
 )
 
-// AddCSPHeader adds the Content Security Policy header.
-func AddCSPHeader(cfg *setting.Cfg, logger log.Logger) macaron.Handler {
+// AddCSPHeader adds the Content Security Policy header, generated from the template with the sources the plugins add
+// to its directives. The plugin manager can be nil, for the policy to only be the template.
+func AddCSPHeader(cfg *setting.Cfg, pluginManager plugins.Manager, logger log.Logger) macaron.Handler {
 	return func(w http.ResponseWriter, req *http.Request, c *macaron.Context) {
 		if !cfg.CSPEnabled {
 			return
@@ -38,12 +41,71 @@ func AddCSPHeader(cfg *setting.Cfg, logger log.Logger) macaron.Handler {
 		if _, err := io.ReadFull(rand.Reader, buf[:]); err != nil {
 			logger.Error("Failed to generate CSP nonce", "err", err)
 			ctx.JsonApiErr(500, "Failed to generate CSP nonce", err)
+			return
 		}
 
 		nonce := base64.RawStdEncoding.EncodeToString(buf[:])
-		val := strings.ReplaceAll(cfg.CSPTemplate, "$NONCE", fmt.Sprintf("'nonce-%s'", nonce))
-		w.Header().Set("Content-Security-Policy", val)
+		var pluginSources map[string][]string
+		if pluginManager != nil {
+			pluginSources = plugins.CSPSources(pluginManager.Plugins())
+		}
+
+		header := "Content-Security-Policy"
+		if cfg.CSPReportOnly {
+			header = "Content-Security-Policy-Report-Only"
+		}
+		w.Header().Set(header, cspPolicy(cfg, nonce, pluginSources))
 		ctx.RequestNonce = nonce
 		logger.Debug("Successfully generated CSP nonce", "nonce", nonce)
 	}
 }
+
+// cspPolicy returns the policy of the template, with its variables replaced and the sources added to their directives.
+// The directives the template doesn't have are added with only the sources.
+func cspPolicy(cfg *setting.Cfg, nonce string, sources map[string][]string) string {
+	policy := strings.ReplaceAll(cfg.CSPTemplate, "$NONCE", fmt.Sprintf("'nonce-%s'", nonce))
+	policy = strings.ReplaceAll(policy, "$ROOT_PATH", strings.TrimSuffix(cfg.AppURL, "/"))
+	if len(sources) == 0 {
+		return policy
+	}
+
+	added := map[string]bool{}
+	directives := strings.Split(strings.TrimSuffix(strings.TrimSpace(policy), ";"), ";")
+	for i, directive := range directives {
+		fields := strings.Fields(directive)
+		if len(fields) == 0 {
+			continue
+		}
+
+		name := strings.ToLower(fields[0])
+		for _, source := range sources[name] {
+			if !containsString(fields[1:], source) {
+				fields = append(fields, source)
+			}
+		}
+		directives[i] = strings.Join(fields, " ")
+		added[name] = true
+	}
+
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		if !added[name] && len(sources[name]) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		directives = append(directives, name+" "+strings.Join(sources[name], " "))
+	}
+
+	return strings.Join(directives, ";") + ";"
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
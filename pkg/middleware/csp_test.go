@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestCSPPolicy(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.AppURL = "https://grafana.example.com/"
+	cfg.CSPTemplate = "script-src 'self' $NONCE;img-src * data:;connect-src 'self' $ROOT_PATH;"
+
+	t.Run("replaces the variables of the template", func(t *testing.T) {
+		assert.Equal(t, "script-src 'self' 'nonce-abc';img-src * data:;connect-src 'self' https://grafana.example.com;",
+			cspPolicy(cfg, "abc", nil))
+	})
+
+	t.Run("adds the plugin sources to their directives", func(t *testing.T) {
+		policy := cspPolicy(cfg, "abc", map[string][]string{
+			"script-src":  {"https://cdn.example.com"},
+			"connect-src": {"'self'", "https://api.example.com"},
+			"font-src":    {"https://fonts.example.com"},
+			"worker-src":  {},
+		})
+		assert.Equal(t, "script-src 'self' 'nonce-abc' https://cdn.example.com;img-src * data:;"+
+			"connect-src 'self' https://grafana.example.com https://api.example.com;font-src https://fonts.example.com;", policy)
+	})
+}
@@ -0,0 +1,148 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"gopkg.in/macaron.v1"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var ipFilterAuditLogger = log.New("audit.ip_filter")
+
+const ipFilterDeniedMessage = "Access denied from your IP address"
+
+// ipFilterPathPrefixes are the path prefixes of the route groups of the IP filter.
+var ipFilterPathPrefixes = map[string]string{
+	setting.IPFilterGroupLivePush: "/api/live/push",
+	setting.IPFilterGroupAPI:      "/api",
+	setting.IPFilterGroupLogin:    "/login",
+	setting.IPFilterGroupRender:   "/render",
+}
+
+// IPFilter rejects the requests to the route groups from the IPs which neither the instance rules nor the rules of any
+// organization allow. It runs before the context handler, so that the rejected requests aren't authenticated, and
+// OrgIPFilter applies the rules of the organization of the signed in user afterwards. The render calls are let
+// through when their render key is valid. The rejected requests are recorded in the audit log.
+func IPFilter(cfg *setting.Cfg, renderService rendering.Service) macaron.Handler {
+	return func(c *macaron.Context) {
+		group := ipFilterGroup(strings.TrimPrefix(c.Req.URL.Path, cfg.AppSubURL))
+		if group == "" {
+			return
+		}
+		if key := c.GetCookie("renderKey"); key != "" {
+			if _, exists := renderService.GetRenderUser(key); exists {
+				return
+			}
+		}
+
+		ip := net.ParseIP(clientIP(c.Req.Request, cfg.IPFilter))
+		if rules, ok := cfg.IPFilter.Rules[group]; !ok || ipAllowed(ip, rules) {
+			return
+		}
+		for _, orgRules := range cfg.IPFilter.OrgRules {
+			if rules, ok := orgRules[group]; ok && ipAllowed(ip, rules) {
+				return
+			}
+		}
+
+		ipFilterAuditLogger.Warn("Request rejected by the IP filter", "remote_addr", ip, "group", group,
+			"method", c.Req.Method, "path", c.Req.URL.Path)
+		c.JSON(403, map[string]interface{}{"message": ipFilterDeniedMessage})
+	}
+}
+
+// OrgIPFilter rejects the requests to the route groups from the networks they deny, or from outside of the networks
+// they allow. The rules of the organization of the signed in user replace the instance rules, so it needs to be after
+// the context handler. The rejected requests are recorded in the audit log.
+func OrgIPFilter(cfg *setting.Cfg) macaron.Handler {
+	return func(c *models.ReqContext) {
+		// the render calls are authenticated by their render key, verified by IPFilter and the context handler
+		if c.IsRenderCall {
+			return
+		}
+
+		group := ipFilterGroup(strings.TrimPrefix(c.Req.URL.Path, cfg.AppSubURL))
+		if group == "" {
+			return
+		}
+
+		rules, ok := cfg.IPFilter.Rules[group]
+		if c.IsSignedIn {
+			if orgRules, exists := cfg.IPFilter.OrgRules[c.OrgId][group]; exists {
+				rules, ok = orgRules, true
+			}
+		}
+		if !ok {
+			return
+		}
+
+		addr := clientIP(c.Req.Request, cfg.IPFilter)
+		if ipAllowed(net.ParseIP(addr), rules) {
+			return
+		}
+
+		ipFilterAuditLogger.Warn("Request rejected by the IP filter", "remote_addr", addr, "group", group,
+			"method", c.Req.Method, "path", c.Req.URL.Path, "orgId", c.OrgId, "userId", c.UserId)
+		c.JsonApiErr(403, ipFilterDeniedMessage, nil)
+	}
+}
+
+// ipFilterGroup returns the route group of the path, or an empty string when the path isn't filtered.
+func ipFilterGroup(path string) string {
+	for _, group := range setting.IPFilterGroups {
+		prefix := ipFilterPathPrefixes[group]
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return group
+		}
+	}
+	return ""
+}
+
+// clientIP returns the IP of the client, the connection's one unless the forwarded headers are trusted. Only the
+// address added by the reverse proxy is used: the value of the configured client IP header, or else the last address
+// of X-Forwarded-For, since the clients can set the other ones.
+func clientIP(req *http.Request, settings setting.IPFilterSettings) string {
+	if settings.UseForwardedHeaders {
+		if settings.ClientIPHeader != "" {
+			if addr := strings.TrimSpace(req.Header.Get(settings.ClientIPHeader)); addr != "" {
+				return addr
+			}
+		} else if forwarded := req.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+			// the proxy appends to the last header
+			addrs := strings.Split(forwarded[len(forwarded)-1], ",")
+			return strings.TrimSpace(addrs[len(addrs)-1])
+		}
+	}
+
+	addr := req.RemoteAddr
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+func ipAllowed(ip net.IP, rules setting.IPFilterRules) bool {
+	if ip == nil {
+		return false
+	}
+	for _, network := range rules.Deny {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	if len(rules.Allow) == 0 {
+		return true
+	}
+	for _, network := range rules.Allow {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
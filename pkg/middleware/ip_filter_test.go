@@ -0,0 +1,187 @@
+package middleware
+
+import (
+	"net"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/macaron.v1"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestIPFilterMiddleware(t *testing.T) {
+	networks := func(cidrs ...string) []*net.IPNet {
+		var result []*net.IPNet
+		for _, cidr := range cidrs {
+			_, network, err := net.ParseCIDR(cidr)
+			require.NoError(t, err)
+			result = append(result, network)
+		}
+		return result
+	}
+
+	configure := func(cfg *setting.Cfg) {
+		cfg.IPFilter = setting.IPFilterSettings{
+			Enabled: true,
+			Rules: map[string]setting.IPFilterRules{
+				setting.IPFilterGroupAPI:      {Allow: networks("10.0.0.0/8"), Deny: networks("10.1.0.0/16")},
+				setting.IPFilterGroupLivePush: {Allow: networks("192.168.0.0/16")},
+				setting.IPFilterGroupLogin:    {Deny: networks("2001:db8::/32")},
+			},
+			OrgRules: map[int64]map[string]setting.IPFilterRules{
+				12: {setting.IPFilterGroupAPI: {Allow: networks("172.16.0.0/12")}},
+			},
+		}
+	}
+
+	tests := []struct {
+		desc       string
+		url        string
+		remoteAddr string
+		code       int
+	}{
+		{desc: "allows the API requests from the allowed networks", url: "/api/search", remoteAddr: "10.2.3.4:1234", code: 200},
+		{desc: "rejects the API requests from outside of the allowed networks", url: "/api/search", remoteAddr: "8.8.8.8:1234", code: 403},
+		{desc: "rejects the API requests from the denied networks", url: "/api/search", remoteAddr: "10.1.3.4:1234", code: 403},
+		{desc: "applies the rules of the live push group to its requests", url: "/api/live/push/stream", remoteAddr: "192.168.1.1:1234", code: 200},
+		{desc: "rejects the login requests from the denied networks", url: "/login", remoteAddr: "[2001:db8::1]:1234", code: 403},
+		{desc: "allows the login requests from the other networks", url: "/login", remoteAddr: "[2001:db9::1]:1234", code: 200},
+		{desc: "doesn't filter the route groups without rules", url: "/render/d/uid", remoteAddr: "8.8.8.8:1234", code: 200},
+		{desc: "doesn't filter the other paths", url: "/apis", remoteAddr: "8.8.8.8:1234", code: 200},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		middlewareScenario(t, tc.desc, func(t *testing.T, sc *scenarioContext) {
+			sc.m.Get(tc.url, OrgIPFilter(sc.cfg), sc.defaultHandler)
+			sc.fakeReq("GET", tc.url)
+			sc.req.RemoteAddr = tc.remoteAddr
+			sc.exec()
+
+			assert.Equal(t, tc.code, sc.resp.Code)
+		}, configure)
+	}
+
+	middlewareScenario(t, "applies the rules of the organization of the signed in user", func(t *testing.T, sc *scenarioContext) {
+		keyhash, err := util.EncodePassword("v5nAwpMafFP6znaS4urhdWDLS5511M42", "asd")
+		require.NoError(t, err)
+		bus.AddHandler("test", func(query *models.GetApiKeyByNameQuery) error {
+			query.Result = &models.ApiKey{OrgId: 12, Role: models.ROLE_EDITOR, Key: keyhash}
+			return nil
+		})
+
+		sc.m.Get("/api/search", OrgIPFilter(sc.cfg), sc.defaultHandler)
+		sc.fakeReq("GET", "/api/search").withValidApiKey()
+		sc.req.RemoteAddr = "10.2.3.4:1234"
+		sc.exec()
+
+		assert.Equal(t, 403, sc.resp.Code)
+		assert.Equal(t, "Access denied from your IP address", sc.respJson["message"])
+	}, configure)
+
+	middlewareScenario(t, "reads the client IP from the forwarded headers when they're trusted", func(t *testing.T, sc *scenarioContext) {
+		sc.m.Get("/api/search", OrgIPFilter(sc.cfg), sc.defaultHandler)
+		sc.fakeReq("GET", "/api/search")
+		sc.req.RemoteAddr = "127.0.0.1:1234"
+		sc.req.Header.Set("X-Forwarded-For", "10.2.3.4, 8.8.8.8")
+		sc.exec()
+
+		assert.Equal(t, 403, sc.resp.Code)
+	}, func(cfg *setting.Cfg) {
+		configure(cfg)
+		cfg.IPFilter.UseForwardedHeaders = true
+	})
+
+	middlewareScenario(t, "ignores a X-Real-IP set by the client", func(t *testing.T, sc *scenarioContext) {
+		sc.m.Get("/api/search", OrgIPFilter(sc.cfg), sc.defaultHandler)
+		sc.fakeReq("GET", "/api/search")
+		sc.req.RemoteAddr = "127.0.0.1:1234"
+		sc.req.Header.Set("X-Real-IP", "10.2.3.4")
+		sc.req.Header.Add("X-Forwarded-For", "10.2.3.4")
+		sc.req.Header.Add("X-Forwarded-For", "8.8.8.8")
+		sc.exec()
+
+		assert.Equal(t, 403, sc.resp.Code)
+	}, func(cfg *setting.Cfg) {
+		configure(cfg)
+		cfg.IPFilter.UseForwardedHeaders = true
+	})
+
+	middlewareScenario(t, "reads the client IP from the configured header only", func(t *testing.T, sc *scenarioContext) {
+		sc.m.Get("/api/search", OrgIPFilter(sc.cfg), sc.defaultHandler)
+		sc.fakeReq("GET", "/api/search")
+		sc.req.RemoteAddr = "127.0.0.1:1234"
+		sc.req.Header.Set("X-Client-IP", "8.8.8.8")
+		sc.req.Header.Set("X-Forwarded-For", "10.2.3.4")
+		sc.exec()
+
+		assert.Equal(t, 403, sc.resp.Code)
+	}, func(cfg *setting.Cfg) {
+		configure(cfg)
+		cfg.IPFilter.UseForwardedHeaders = true
+		cfg.IPFilter.ClientIPHeader = "X-Client-IP"
+	})
+
+	t.Run("rejects the requests before their authentication", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		configure(cfg)
+		cfg.IPFilter.UseForwardedHeaders = true
+
+		tests := []struct {
+			desc        string
+			remoteAddr  string
+			headers     map[string]string
+			cookie      string
+			code        int
+			contextUsed bool
+		}{
+			{desc: "allowed by the instance rules", remoteAddr: "10.2.3.4:1234", code: 200, contextUsed: true},
+			{desc: "allowed by the rules of an organization", remoteAddr: "172.16.1.1:1234", code: 200, contextUsed: true},
+			{desc: "allowed by no rules", remoteAddr: "8.8.8.8:1234", code: 403},
+			{desc: "allowed by no rules with a spoofed X-Real-IP", remoteAddr: "127.0.0.1:1234",
+				headers: map[string]string{"X-Real-IP": "10.2.3.4", "X-Forwarded-For": "8.8.8.8"}, code: 403},
+			{desc: "with a render key", remoteAddr: "8.8.8.8:1234", cookie: "renderKey=key", code: 200, contextUsed: true},
+			{desc: "with a bogus render key", remoteAddr: "8.8.8.8:1234", cookie: "renderKey=bogus", code: 403},
+		}
+		renderService := &fakeRenderService{renderUsers: map[string]*rendering.RenderUser{
+			"key": {OrgID: 1, UserID: 2, OrgRole: "Viewer"},
+		}}
+
+		for _, tc := range tests {
+			t.Run(tc.desc, func(t *testing.T) {
+				contextUsed := false
+				m := macaron.New()
+				m.Use(macaron.Renderer())
+				m.Use(IPFilter(cfg, renderService))
+				// stands for the context handler
+				m.Use(func(c *macaron.Context) {
+					contextUsed = true
+				})
+				m.Get("/api/search", func(c *macaron.Context) {
+					c.Resp.WriteHeader(200)
+				})
+
+				req := httptest.NewRequest("GET", "/api/search", nil)
+				req.RemoteAddr = tc.remoteAddr
+				for name, value := range tc.headers {
+					req.Header.Set(name, value)
+				}
+				if tc.cookie != "" {
+					req.Header.Set("Cookie", tc.cookie)
+				}
+				resp := httptest.NewRecorder()
+				m.ServeHTTP(resp, req)
+
+				assert.Equal(t, tc.code, resp.Code)
+				assert.Equal(t, tc.contextUsed, contextUsed)
+			})
+		}
+	})
+}
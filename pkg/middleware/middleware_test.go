@@ -197,6 +197,81 @@ func TestMiddlewareContext(t *testing.T) {
 		assert.Equal(t, "Expired API key", sc.respJson["message"])
 	})
 
+	middlewareScenario(t, "Valid API key, but older than the maximum lifetime", func(t *testing.T, sc *scenarioContext) {
+		now := time.Date(2021, 5, 1, 10, 0, 0, 0, time.UTC)
+		sc.contextHandler.GetTime = func() time.Time { return now }
+
+		keyhash, err := util.EncodePassword("v5nAwpMafFP6znaS4urhdWDLS5511M42", "asd")
+		require.NoError(t, err)
+
+		bus.AddHandler("test", func(query *models.GetApiKeyByNameQuery) error {
+			query.Result = &models.ApiKey{OrgId: 12, Role: models.ROLE_EDITOR, Key: keyhash,
+				Created: now.Add(-30 * 24 * time.Hour)}
+			return nil
+		})
+
+		sc.fakeReq("GET", "/").withValidApiKey().exec()
+
+		assert.Equal(t, 401, sc.resp.Code)
+		assert.Equal(t, "Expired API key", sc.respJson["message"])
+	}, func(cfg *setting.Cfg) {
+		cfg.ApiKeyMaxLifetime = 30 * 24 * time.Hour
+	})
+
+	middlewareScenario(t, "Valid API key, but inactive for longer than the maximum inactive lifetime", func(t *testing.T, sc *scenarioContext) {
+		now := time.Date(2021, 5, 1, 10, 0, 0, 0, time.UTC)
+		sc.contextHandler.GetTime = func() time.Time { return now }
+
+		keyhash, err := util.EncodePassword("v5nAwpMafFP6znaS4urhdWDLS5511M42", "asd")
+		require.NoError(t, err)
+
+		bus.AddHandler("test", func(query *models.GetApiKeyByNameQuery) error {
+			lastUsedAt := now.Add(-7 * 24 * time.Hour).Unix()
+			query.Result = &models.ApiKey{OrgId: 12, Role: models.ROLE_EDITOR, Key: keyhash,
+				Created: now.Add(-10 * 24 * time.Hour), LastUsedAt: &lastUsedAt}
+			return nil
+		})
+
+		sc.fakeReq("GET", "/").withValidApiKey().exec()
+
+		assert.Equal(t, 401, sc.resp.Code)
+		assert.Equal(t, "Expired API key", sc.respJson["message"])
+	}, func(cfg *setting.Cfg) {
+		cfg.ApiKeyMaxInactiveLifetime = 7 * 24 * time.Hour
+		cfg.ApiKeyExtendOnActivity = true
+	})
+
+	middlewareScenario(t, "Valid API key used recently records its use", func(t *testing.T, sc *scenarioContext) {
+		now := time.Date(2021, 5, 1, 10, 0, 0, 0, time.UTC)
+		sc.contextHandler.GetTime = func() time.Time { return now }
+
+		keyhash, err := util.EncodePassword("v5nAwpMafFP6znaS4urhdWDLS5511M42", "asd")
+		require.NoError(t, err)
+
+		bus.AddHandler("test", func(query *models.GetApiKeyByNameQuery) error {
+			lastUsedAt := now.Add(-6 * 24 * time.Hour).Unix()
+			query.Result = &models.ApiKey{Id: 3, OrgId: 12, Role: models.ROLE_EDITOR, Key: keyhash,
+				Created: now.Add(-10 * 24 * time.Hour), LastUsedAt: &lastUsedAt}
+			return nil
+		})
+
+		var updated *models.UpdateApiKeyLastUsedCommand
+		bus.AddHandler("test", func(cmd *models.UpdateApiKeyLastUsedCommand) error {
+			updated = cmd
+			return nil
+		})
+
+		sc.fakeReq("GET", "/").withValidApiKey().exec()
+
+		assert.Equal(t, 200, sc.resp.Code)
+		require.NotNil(t, updated)
+		assert.Equal(t, int64(3), updated.Id)
+		assert.Equal(t, now.Unix(), updated.LastUsedAt)
+	}, func(cfg *setting.Cfg) {
+		cfg.ApiKeyMaxInactiveLifetime = 7 * 24 * time.Hour
+		cfg.ApiKeyExtendOnActivity = true
+	})
+
 	middlewareScenario(t, "Non-expired auth token in cookie which is not being rotated", func(
 		t *testing.T, sc *scenarioContext) {
 		const userID int64 = 12
@@ -564,7 +639,7 @@ func middlewareScenario(t *testing.T, desc string, fn scenarioFunc, cbs ...func(
 
 		sc.m = macaron.New()
 		sc.m.Use(AddDefaultResponseHeaders(cfg))
-		sc.m.Use(AddCSPHeader(cfg, logger))
+		sc.m.Use(AddCSPHeader(cfg, nil, logger))
 		sc.m.Use(macaron.Renderer(macaron.RenderOptions{
 			Directory: viewsPath,
 			Delims:    macaron.Delims{Left: "[[", Right: "]]"},
@@ -649,8 +724,71 @@ func getContextHandler(t *testing.T, cfg *setting.Cfg) *contexthandler.ContextHa
 
 type fakeRenderService struct {
 	rendering.Service
+	renderUsers map[string]*rendering.RenderUser
 }
 
 func (s *fakeRenderService) Init() error {
 	return nil
 }
+
+func (s *fakeRenderService) GetRenderUser(key string) (*rendering.RenderUser, bool) {
+	renderUser, exists := s.renderUsers[key]
+	return renderUser, exists
+}
+
+func TestMiddlewareImpersonation(t *testing.T) {
+	const adminID int64 = 12
+	const userID int64 = 13
+
+	setup := func(sc *scenarioContext, session *models.ImpersonationSession) {
+		sc.withTokenSessionCookie("token")
+		sc.userAuthTokenService.LookupTokenProvider = func(ctx context.Context, unhashedToken string) (*models.UserToken, error) {
+			return &models.UserToken{UserId: adminID, UnhashedToken: unhashedToken}, nil
+		}
+		bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetSignedInUserQuery) error {
+			query.Result = &models.SignedInUser{OrgId: 2, UserId: query.UserId, IsGrafanaAdmin: query.UserId == adminID}
+			return nil
+		})
+		bus.AddHandler("test", func(query *models.GetImpersonationSessionQuery) error {
+			if session == nil || query.KeyHash != models.HashImpersonationKey("key") {
+				return models.ErrImpersonationSessionNotFound
+			}
+			query.Result = session
+			return nil
+		})
+
+		sc.fakeReq("GET", "/")
+		sc.req.AddCookie(&http.Cookie{Name: models.ImpersonationCookieName, Value: "key"})
+		sc.exec()
+	}
+
+	middlewareScenario(t, "Active impersonation session of the admin", func(t *testing.T, sc *scenarioContext) {
+		session := &models.ImpersonationSession{Id: 1, AdminUserId: adminID, UserId: userID, Expires: time.Now().Add(time.Hour)}
+		setup(sc, session)
+
+		require.NotNil(t, sc.context)
+		assert.True(t, sc.context.IsSignedIn)
+		assert.Equal(t, userID, sc.context.UserId)
+		assert.Equal(t, adminID, sc.context.ImpersonatorId)
+		assert.Equal(t, adminID, sc.context.UserToken.UserId)
+		assert.Equal(t, session, sc.context.Impersonation)
+	})
+
+	for desc, session := range map[string]*models.ImpersonationSession{
+		"Unknown impersonation session":          nil,
+		"Expired impersonation session":          {Id: 1, AdminUserId: adminID, UserId: userID, Expires: time.Now().Add(-time.Minute)},
+		"Stopped impersonation session":          {Id: 1, AdminUserId: adminID, UserId: userID, Expires: time.Now().Add(time.Hour), Stopped: true},
+		"Impersonation session of another admin": {Id: 1, AdminUserId: 1, UserId: userID, Expires: time.Now().Add(time.Hour)},
+	} {
+		session := session
+		middlewareScenario(t, desc, func(t *testing.T, sc *scenarioContext) {
+			setup(sc, session)
+
+			require.NotNil(t, sc.context)
+			assert.Equal(t, adminID, sc.context.UserId)
+			assert.Zero(t, sc.context.ImpersonatorId)
+			assert.Nil(t, sc.context.Impersonation)
+			assert.Contains(t, sc.resp.Header().Get("Set-Cookie"), models.ImpersonationCookieName+"=;")
+		})
+	}
+}
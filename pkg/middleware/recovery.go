@@ -25,6 +25,7 @@ import (
 
 	"gopkg.in/macaron.v1"
 
+	"github.com/grafana/grafana/pkg/infra/crashreport"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/setting"
@@ -126,6 +127,7 @@ func Recovery(cfg *setting.Cfg) macaron.Handler {
 
 				stack := stack(3)
 				panicLogger.Error("Request error", "error", r, "stack", string(stack))
+				crashreport.Capture(r, c.Req.Method, c.Req.URL.Path, stack)
 
 				// if response has already been written, skip.
 				if c.Written() {
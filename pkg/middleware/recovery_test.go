@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/crashreport"
 	"github.com/grafana/grafana/pkg/infra/remotecache"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/auth"
@@ -19,13 +20,21 @@ func TestRecoveryMiddleware(t *testing.T) {
 	t.Run("Given an API route that panics", func(t *testing.T) {
 		apiURL := "/api/whatever"
 		recoveryScenario(t, "recovery middleware should return JSON", apiURL, func(t *testing.T, sc *scenarioContext) {
+			crashreport.Reset()
 			sc.handlerFunc = panicHandler
-			sc.fakeReq("GET", apiURL).exec()
+			sc.fakeReq("GET", apiURL+"?token=secret").exec()
 			sc.req.Header.Set("content-type", "application/json")
 
 			assert.Equal(t, 500, sc.resp.Code)
 			assert.Equal(t, "Internal Server Error - Check the Grafana server logs for the detailed error message.", sc.respJson["message"])
 			assert.True(t, strings.HasPrefix(sc.respJson["error"].(string), "Server Error"))
+
+			reports := crashreport.Recent()
+			require.Len(t, reports, 1)
+			assert.Equal(t, "Handler has panicked", reports[0].Error)
+			assert.Equal(t, "GET", reports[0].Method)
+			assert.Equal(t, apiURL, reports[0].Path)
+			assert.NotEmpty(t, reports[0].Stack)
 		})
 	})
 
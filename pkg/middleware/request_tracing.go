@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
 
 	"gopkg.in/macaron.v1"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
 )
 
 type contextKey struct{}
@@ -36,7 +39,9 @@ func RouteOperationNameFromContext(ctx context.Context) (string, bool) {
 	return "", false
 }
 
-func RequestTracing() macaron.Handler {
+// RequestTracing traces the requests, the sampling policies of the tracing service decide at the end of the requests
+// whether their traces are reported.
+func RequestTracing(ts *tracing.TracingService) macaron.Handler {
 	return func(res http.ResponseWriter, req *http.Request, c *macaron.Context) {
 		if strings.HasPrefix(c.Req.URL.Path, "/public/") ||
 			c.Req.URL.Path == "robots.txt" {
@@ -45,11 +50,16 @@ func RequestTracing() macaron.Handler {
 		}
 
 		rw := res.(macaron.ResponseWriter)
+		start := time.Now()
 
 		tracer := opentracing.GlobalTracer()
 		wireContext, _ := tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(req.Header))
 		span := tracer.StartSpan(fmt.Sprintf("HTTP %s %s", req.Method, req.URL.Path), ext.RPCServerOption(wireContext))
 
+		// the kept spans are reported or dropped after the span is finished
+		sampling := ts.StartRequestSampling(span)
+		defer sampling.Finish()
+
 		ctx := opentracing.ContextWithSpan(req.Context(), span)
 		c.Req.Request = req.WithContext(ctx)
 
@@ -70,5 +80,7 @@ func RequestTracing() macaron.Handler {
 		if status >= 400 {
 			ext.Error.Set(span, true)
 		}
+
+		sampling.Decide(span, req.URL.Path, status, time.Since(start))
 	}
 }
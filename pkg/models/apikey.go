@@ -21,6 +21,9 @@ type ApiKey struct {
 	Created time.Time
 	Updated time.Time
 	Expires *int64
+	// LastUsedAt is when the key was last used, it's only recorded when the activity extends the inactive lifetime
+	// of the keys.
+	LastUsedAt *int64
 }
 
 // ---------------------
@@ -35,6 +38,11 @@ type AddApiKeyCommand struct {
 	Result *ApiKey `json:"-"`
 }
 
+type UpdateApiKeyLastUsedCommand struct {
+	Id         int64
+	LastUsedAt int64
+}
+
 type DeleteApiKeyCommand struct {
 	Id    int64 `json:"id"`
 	OrgId int64 `json:"-"`
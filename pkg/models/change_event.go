@@ -0,0 +1,123 @@
+package models
+
+import (
+	"time"
+)
+
+// ChangeEventResourceType is the type of the resources changed by the change events.
+type ChangeEventResourceType string
+
+const (
+	ChangeEventDashboard   ChangeEventResourceType = "dashboard"
+	ChangeEventFolder      ChangeEventResourceType = "folder"
+	ChangeEventDataSource  ChangeEventResourceType = "datasource"
+	ChangeEventAlertRule   ChangeEventResourceType = "alert_rule"
+	ChangeEventPermissions ChangeEventResourceType = "permissions"
+)
+
+// IsValid returns true if the resource type is known.
+func (t ChangeEventResourceType) IsValid() bool {
+	switch t {
+	case ChangeEventDashboard, ChangeEventFolder, ChangeEventDataSource, ChangeEventAlertRule, ChangeEventPermissions:
+		return true
+	}
+	return false
+}
+
+// ChangeEventAction is the action of a change event.
+type ChangeEventAction string
+
+const (
+	ChangeEventCreated  ChangeEventAction = "created"
+	ChangeEventUpdated  ChangeEventAction = "updated"
+	ChangeEventRestored ChangeEventAction = "restored"
+	ChangeEventDeleted  ChangeEventAction = "deleted"
+)
+
+// IsValid returns true if the action is known.
+func (a ChangeEventAction) IsValid() bool {
+	switch a {
+	case ChangeEventCreated, ChangeEventUpdated, ChangeEventRestored, ChangeEventDeleted:
+		return true
+	}
+	return false
+}
+
+// ChangeEvent records who changed a resource of an org, when, and the versions of the resource before and after the
+// change, if it's versioned. The old version is 0 for the created resources, and the new version is 0 for the deleted ones.
+// The permissions events are the changes of the permissions of the dashboard or folder of their UID. The impersonator
+// is the Grafana admin who made the change impersonating the user, if any.
+type ChangeEvent struct {
+	Id             int64
+	OrgId          int64
+	ResourceType   ChangeEventResourceType
+	ResourceId     int64
+	ResourceUid    string
+	ResourceName   string
+	Action         ChangeEventAction
+	OldVersion     int64
+	NewVersion     int64
+	UserId         int64
+	ImpersonatorId int64
+	Created        time.Time
+}
+
+// ChangeEventDTO represents a change event with the login of its user.
+type ChangeEventDTO struct {
+	Id                int64                   `json:"id"`
+	ResourceType      ChangeEventResourceType `json:"resourceType"`
+	ResourceId        int64                   `json:"resourceId"`
+	ResourceUid       string                  `json:"resourceUid"`
+	ResourceName      string                  `json:"resourceName"`
+	Action            ChangeEventAction       `json:"action"`
+	OldVersion        int64                   `json:"oldVersion"`
+	NewVersion        int64                   `json:"newVersion"`
+	UserId            int64                   `json:"userId"`
+	UserLogin         string                  `json:"userLogin"`
+	ImpersonatorId    int64                   `json:"impersonatorId,omitempty"`
+	ImpersonatorLogin string                  `json:"impersonatorLogin,omitempty"`
+	Created           time.Time               `json:"created"`
+}
+
+// ---------------------
+// COMMANDS
+
+type AddChangeEventCommand struct {
+	OrgId          int64
+	ResourceType   ChangeEventResourceType
+	ResourceId     int64
+	ResourceUid    string
+	ResourceName   string
+	Action         ChangeEventAction
+	OldVersion     int64
+	NewVersion     int64
+	UserId         int64
+	ImpersonatorId int64
+
+	Result *ChangeEvent
+}
+
+// ---------------------
+// QUERIES
+
+// SearchChangeEventsQuery returns the change events of an org matching the filters set, the newest first.
+type SearchChangeEventsQuery struct {
+	OrgId         int64
+	ResourceTypes []ChangeEventResourceType
+	ResourceUid   string
+	Actions       []ChangeEventAction
+	UserId        int64
+	From          time.Time
+	To            time.Time
+	Page          int
+	PerPage       int
+
+	Result SearchChangeEventsQueryResult
+}
+
+type SearchChangeEventsQueryResult struct {
+	TotalCount int64             `json:"totalCount"`
+	Events     []*ChangeEventDTO `json:"events"`
+	Page       int               `json:"page"`
+	PerPage    int               `json:"perPage"`
+}
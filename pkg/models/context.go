@@ -13,6 +13,8 @@ type ReqContext struct {
 	*macaron.Context
 	*SignedInUser
 	UserToken *UserToken
+	// Impersonation is the impersonation session of the request, nil when the user isn't impersonated
+	Impersonation *ImpersonationSession
 
 	IsSignedIn     bool
 	IsRenderCall   bool
@@ -22,13 +22,19 @@ func (p PermissionType) String() string {
 	return names[int(p)]
 }
 
+// MaxExternalGroupLength is the maximum length of the external groups the
+// permissions can reference.
+const MaxExternalGroupLength = 190
+
 // Typed errors
 var (
 	ErrDashboardAclInfoMissing           = errors.New("user id and team id cannot both be empty for a dashboard permission")
 	ErrDashboardPermissionDashboardEmpty = errors.New("dashboard id must be greater than zero for a dashboard permission")
 	ErrFolderAclInfoMissing              = errors.New("user id and team id cannot both be empty for a folder permission")
 	ErrFolderPermissionFolderEmpty       = errors.New("folder id must be greater than zero for a folder permission")
-	ErrPermissionsWithRoleNotAllowed     = errors.New("team and user permissions cannot have an associated role")
+	ErrPermissionsWithRoleNotAllowed     = errors.New("team, user and external group permissions cannot have an associated role")
+	ErrPermissionsWithExternalGroup      = errors.New("external group permissions cannot have an associated user or team")
+	ErrPermissionsExternalGroupTooLong   = errors.New("external group of a permission cannot be longer than 190 characters")
 )
 
 // Dashboard ACL model
@@ -38,10 +44,11 @@ type DashboardAcl struct {
 	OrgID       int64 `xorm:"org_id"`
 	DashboardID int64 `xorm:"dashboard_id"`
 
-	UserID     int64     `xorm:"user_id"`
-	TeamID     int64     `xorm:"team_id"`
-	Role       *RoleType // pointer to be nullable
-	Permission PermissionType
+	UserID        int64     `xorm:"user_id"`
+	TeamID        int64     `xorm:"team_id"`
+	ExternalGroup string    `xorm:"external_group"` // LDAP or OAuth group of the users, synced at login
+	Role          *RoleType // pointer to be nullable
+	Permission    PermissionType
 
 	Created time.Time
 	Updated time.Time
@@ -63,6 +70,7 @@ type DashboardAclInfoDTO struct {
 	TeamEmail      string         `json:"teamEmail"`
 	TeamAvatarUrl  string         `json:"teamAvatarUrl"`
 	Team           string         `json:"team"`
+	ExternalGroup  string         `json:"externalGroup,omitempty"`
 	Role           *RoleType      `json:"role,omitempty"`
 	Permission     PermissionType `json:"permission"`
 	PermissionName string         `json:"permissionName"`
@@ -90,9 +98,13 @@ func (dto *DashboardAclInfoDTO) hasSameTeamAs(other *DashboardAclInfoDTO) bool {
 	return dto.TeamId > 0 && dto.TeamId == other.TeamId
 }
 
-// IsDuplicateOf returns true if other item has same role, same user or same team
+func (dto *DashboardAclInfoDTO) hasSameExternalGroupAs(other *DashboardAclInfoDTO) bool {
+	return dto.ExternalGroup != "" && dto.ExternalGroup == other.ExternalGroup
+}
+
+// IsDuplicateOf returns true if other item has same role, same user, same team or same external group
 func (dto *DashboardAclInfoDTO) IsDuplicateOf(other *DashboardAclInfoDTO) bool {
-	return dto.hasSameRoleAs(other) || dto.hasSameUserAs(other) || dto.hasSameTeamAs(other)
+	return dto.hasSameRoleAs(other) || dto.hasSameUserAs(other) || dto.hasSameTeamAs(other) || dto.hasSameExternalGroupAs(other)
 }
 
 //
@@ -0,0 +1,142 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+var (
+	ErrDashboardRevisionNotFound   = errors.New("dashboard revision not found")
+	ErrDashboardRevisionNotPending = errors.New("the dashboard revision has been reviewed already")
+)
+
+type DashboardRevisionStatus string
+
+const (
+	DashboardRevisionPending  DashboardRevisionStatus = "pending"
+	DashboardRevisionApproved DashboardRevisionStatus = "approved"
+	DashboardRevisionRejected DashboardRevisionStatus = "rejected"
+)
+
+// FolderReviewer is a user who reviews the changes to the dashboards of a folder. The folders with reviewers are
+// protected: the saves of the other users create pending revisions, which are published once a reviewer approves
+// them.
+type FolderReviewer struct {
+	Id       int64
+	OrgId    int64
+	FolderId int64
+	UserId   int64
+	Created  time.Time
+}
+
+type FolderReviewerDTO struct {
+	UserId int64  `json:"userId"`
+	Login  string `json:"login"`
+	Email  string `json:"email"`
+	Name   string `json:"name"`
+}
+
+// DashboardRevision is a save of a dashboard of a protected folder waiting for, or having had, the review of one of
+// the reviewers of the folder.
+type DashboardRevision struct {
+	Id           int64
+	OrgId        int64
+	DashboardId  int64
+	DashboardUid string
+	// FolderId is the folder the dashboard is saved in once the revision is approved
+	FolderId int64
+	// ReviewFolderId is the protected folder whose reviewers review the revision
+	ReviewFolderId int64
+	Title          string
+	Data           *simplejson.Json
+	Message        string
+	Status         DashboardRevisionStatus
+
+	CreatedBy     int64
+	Created       time.Time
+	ReviewedBy    int64
+	Reviewed      time.Time
+	ReviewComment string
+}
+
+// DashboardRevisionDTO represents a dashboard revision, with the logins of its author and reviewer. The dashboard
+// map is only set for the single revisions.
+type DashboardRevisionDTO struct {
+	Id             int64                   `json:"id"`
+	DashboardId    int64                   `json:"dashboardId"`
+	DashboardUid   string                  `json:"dashboardUid"`
+	FolderId       int64                   `json:"folderId"`
+	ReviewFolderId int64                   `json:"reviewFolderId"`
+	Title          string                  `json:"title"`
+	Message        string                  `json:"message"`
+	Status         DashboardRevisionStatus `json:"status"`
+	CreatedBy      string                  `json:"createdBy"`
+	Created        time.Time               `json:"created"`
+	ReviewedBy     string                  `json:"reviewedBy,omitempty"`
+	Reviewed       *time.Time              `json:"reviewed,omitempty"`
+	ReviewComment  string                  `json:"reviewComment,omitempty"`
+	Data           *simplejson.Json        `json:"data,omitempty" xorm:"-"`
+}
+
+// ---------------------
+// QUERIES
+
+type GetFolderReviewersQuery struct {
+	OrgId    int64
+	FolderId int64
+
+	Result []*FolderReviewerDTO
+}
+
+type GetDashboardRevisionQuery struct {
+	OrgId int64
+	Id    int64
+
+	Result *DashboardRevision
+}
+
+// GetDashboardRevisionsQuery returns the revisions of an org with the status, the newest first. When UserId is set,
+// only the revisions the user created or reviews are returned.
+type GetDashboardRevisionsQuery struct {
+	OrgId  int64
+	Status DashboardRevisionStatus
+	UserId int64
+
+	Result []*DashboardRevisionDTO
+}
+
+// ---------------------
+// COMMANDS
+
+// SetFolderReviewersCommand replaces the reviewers of a folder, the folder isn't protected anymore without reviewers.
+type SetFolderReviewersCommand struct {
+	OrgId    int64
+	FolderId int64
+	UserIds  []int64
+}
+
+type CreateDashboardRevisionCommand struct {
+	OrgId          int64
+	DashboardId    int64
+	DashboardUid   string
+	FolderId       int64
+	ReviewFolderId int64
+	Title          string
+	Data           *simplejson.Json
+	Message        string
+	CreatedBy      int64
+
+	Result *DashboardRevision
+}
+
+// ReviewDashboardRevisionCommand approves or rejects a pending revision, failing with ErrDashboardRevisionNotPending
+// when it has been reviewed already.
+type ReviewDashboardRevisionCommand struct {
+	OrgId      int64
+	Id         int64
+	Status     DashboardRevisionStatus
+	ReviewedBy int64
+	Comment    string
+}
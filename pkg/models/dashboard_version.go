@@ -26,6 +26,12 @@ type DashboardVersion struct {
 
 	Message string           `json:"message"`
 	Data    *simplejson.Json `json:"data"`
+
+	// DataEncoding, BaseVersion and DataCompressed describe how Data is
+	// persisted. They're resolved by the store and never exposed.
+	DataEncoding   string `json:"-"`
+	BaseVersion    int    `json:"-"`
+	DataCompressed []byte `json:"-"`
 }
 
 // DashboardVersionMeta extends the dashboard version model with the names
@@ -84,3 +90,11 @@ type GetDashboardVersionsQuery struct {
 type DeleteExpiredVersionsCommand struct {
 	DeletedRows int64
 }
+
+// CompressDashboardVersionsCommand converts uncompressed legacy dashboard
+// versions into compressed snapshots, a batch at a time.
+type CompressDashboardVersionsCommand struct {
+	BatchSize int
+
+	CompressedRows int64
+}
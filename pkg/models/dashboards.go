@@ -359,6 +359,9 @@ type DashboardProvisioning struct {
 	ExternalId  string
 	CheckSum    string
 	Updated     int64
+	// Overlay is set when changes from the UI are kept instead of the
+	// provisioned dashboard.
+	Overlay bool
 }
 
 type DeleteDashboardCommand struct {
@@ -370,6 +373,13 @@ type DeleteOrphanedProvisionedDashboardsCommand struct {
 	ReaderNames []string
 }
 
+type UpdateDashboardProvisioningCommand struct {
+	DashboardId int64
+	CheckSum    string
+	Updated     int64
+	Overlay     bool
+}
+
 //
 // QUERIES
 //
@@ -443,3 +453,8 @@ type GetDashboardRefByIdQuery struct {
 type UnprovisionDashboardCommand struct {
 	Id int64
 }
+
+type GetProvisionedDashboardOverlaysQuery struct {
+	OrgId  int64
+	Result []*DashboardProvisioning
+}
@@ -1,9 +1,12 @@
 package models
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
 
+	"github.com/grafana/grafana/pkg/components/gtime"
 	"github.com/grafana/grafana/pkg/components/securejsondata"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 )
@@ -29,6 +32,8 @@ var (
 	ErrDataSourceAccessDenied            = errors.New("data source access denied")
 	ErrDataSourceFailedGenerateUniqueUid = errors.New("failed to generate unique datasource ID")
 	ErrDataSourceIdentifierNotSet        = errors.New("unique identifier and org id are needed to be able to get or delete a datasource")
+	ErrDataSourceQueryExamplesInvalid    = errors.New("invalid data source query examples")
+	ErrDataSourceWarmUpQueriesInvalid    = errors.New("invalid data source warm-up queries")
 )
 
 type DsAccess string
@@ -79,6 +84,158 @@ func (ds *DataSource) decryptedValue(field string, fallback string) string {
 	return fallback
 }
 
+// DataSourceQueryExample is a query of the queryExamples of the jsonData of a data source, offered as a starter by
+// query editors. Query is the model of a query of the data source.
+type DataSourceQueryExample struct {
+	Title       string           `json:"title"`
+	Description string           `json:"description,omitempty"`
+	Query       *simplejson.Json `json:"query"`
+	Default     bool             `json:"default,omitempty"`
+}
+
+// QueryExamples returns the query examples of the data source.
+func (ds *DataSource) QueryExamples() ([]DataSourceQueryExample, error) {
+	return QueryExamplesFromJSONData(ds.JsonData)
+}
+
+// QueryExamplesFromJSONData returns the query examples of the jsonData of a data source. Examples need a unique
+// title and a query, and only one of them can be the default query.
+func QueryExamplesFromJSONData(jsonData *simplejson.Json) ([]DataSourceQueryExample, error) {
+	examples := []DataSourceQueryExample{}
+	if jsonData == nil {
+		return examples, nil
+	}
+	raw, ok := jsonData.CheckGet("queryExamples")
+	if !ok {
+		return examples, nil
+	}
+
+	bytes, err := raw.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(bytes, &examples); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrDataSourceQueryExamplesInvalid, err)
+	}
+	if examples == nil {
+		examples = []DataSourceQueryExample{}
+	}
+
+	titles := map[string]bool{}
+	hasDefault := false
+	for i, example := range examples {
+		if example.Title == "" {
+			return nil, fmt.Errorf("%w: example %d has no title", ErrDataSourceQueryExamplesInvalid, i)
+		}
+		if titles[example.Title] {
+			return nil, fmt.Errorf("%w: duplicate title %q", ErrDataSourceQueryExamplesInvalid, example.Title)
+		}
+		titles[example.Title] = true
+		if example.Query == nil {
+			return nil, fmt.Errorf("%w: %q has no query", ErrDataSourceQueryExamplesInvalid, example.Title)
+		}
+		if _, err := example.Query.Map(); err != nil {
+			return nil, fmt.Errorf("%w: query of %q is not an object", ErrDataSourceQueryExamplesInvalid, example.Title)
+		}
+		if example.Default {
+			if hasDefault {
+				return nil, fmt.Errorf("%w: more than one default query", ErrDataSourceQueryExamplesInvalid)
+			}
+			hasDefault = true
+		}
+	}
+
+	return examples, nil
+}
+
+// MinWarmUpQueryInterval is the shortest interval a warm-up query can be run at.
+const MinWarmUpQueryInterval = time.Minute
+
+// DataSourceWarmUpQuery is a query of the warmUpQueries of the jsonData of a data source, run on a schedule to keep
+// the data source warm, e.g. to prime its caches. Query is the model of a query of the data source, run for the time
+// range from From to To, which default to the last five minutes.
+type DataSourceWarmUpQuery struct {
+	Name     string           `json:"name"`
+	Query    *simplejson.Json `json:"query"`
+	Interval string           `json:"interval"`
+	From     string           `json:"from,omitempty"`
+	To       string           `json:"to,omitempty"`
+}
+
+// IntervalDuration returns the interval of the warm-up query, which is valid for warm-up queries returned by
+// WarmUpQueriesFromJSONData.
+func (q DataSourceWarmUpQuery) IntervalDuration() time.Duration {
+	interval, err := gtime.ParseDuration(q.Interval)
+	if err != nil {
+		return 0
+	}
+	return interval
+}
+
+// WarmUpQueries returns the warm-up queries of the data source.
+func (ds *DataSource) WarmUpQueries() ([]DataSourceWarmUpQuery, error) {
+	return WarmUpQueriesFromJSONData(ds.JsonData)
+}
+
+// WarmUpQueriesFromJSONData returns the warm-up queries of the jsonData of a data source. Queries need a unique
+// name, a query and an interval of at least MinWarmUpQueryInterval.
+func WarmUpQueriesFromJSONData(jsonData *simplejson.Json) ([]DataSourceWarmUpQuery, error) {
+	queries := []DataSourceWarmUpQuery{}
+	if jsonData == nil {
+		return queries, nil
+	}
+	raw, ok := jsonData.CheckGet("warmUpQueries")
+	if !ok {
+		return queries, nil
+	}
+
+	bytes, err := raw.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(bytes, &queries); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrDataSourceWarmUpQueriesInvalid, err)
+	}
+	if queries == nil {
+		queries = []DataSourceWarmUpQuery{}
+	}
+
+	names := map[string]bool{}
+	for i, query := range queries {
+		if query.Name == "" {
+			return nil, fmt.Errorf("%w: query %d has no name", ErrDataSourceWarmUpQueriesInvalid, i)
+		}
+		if names[query.Name] {
+			return nil, fmt.Errorf("%w: duplicate name %q", ErrDataSourceWarmUpQueriesInvalid, query.Name)
+		}
+		names[query.Name] = true
+		if query.Query == nil {
+			return nil, fmt.Errorf("%w: %q has no query", ErrDataSourceWarmUpQueriesInvalid, query.Name)
+		}
+		if _, err := query.Query.Map(); err != nil {
+			return nil, fmt.Errorf("%w: query of %q is not an object", ErrDataSourceWarmUpQueriesInvalid, query.Name)
+		}
+		interval, err := gtime.ParseDuration(query.Interval)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid interval of %q: %s", ErrDataSourceWarmUpQueriesInvalid, query.Name, err)
+		}
+		if interval < MinWarmUpQueryInterval {
+			return nil, fmt.Errorf("%w: interval of %q is shorter than %s", ErrDataSourceWarmUpQueriesInvalid,
+				query.Name, MinWarmUpQueryInterval)
+		}
+	}
+
+	return queries, nil
+}
+
+// DataSourceChangedEvent is published when a data source is added, updated or deleted. It's published to the other
+// Grafana servers too, via the distributed event bus.
+type DataSourceChangedEvent struct {
+	OrgId int64  `json:"orgId"`
+	Id    int64  `json:"id"`
+	Uid   string `json:"uid"`
+}
+
 // ----------------------
 // COMMANDS
 
@@ -102,6 +259,8 @@ type AddDataSourceCommand struct {
 
 	OrgId    int64 `json:"-"`
 	ReadOnly bool  `json:"-"`
+	// UserId is the author of the first version of the data source, zero when provisioned
+	UserId int64 `json:"-"`
 
 	Result *DataSource
 }
@@ -128,6 +287,10 @@ type UpdateDataSourceCommand struct {
 	OrgId    int64 `json:"-"`
 	Id       int64 `json:"-"`
 	ReadOnly bool  `json:"-"`
+	// UserId is the author of the version of the update, zero when provisioned
+	UserId int64 `json:"-"`
+	// RestoredFrom is the version the update restores, if any
+	RestoredFrom int `json:"-"`
 
 	Result *DataSource
 }
@@ -159,6 +322,11 @@ type GetDataSourcesByTypeQuery struct {
 	Result []*DataSource
 }
 
+// GetAllDataSourcesQuery returns the data sources of all orgs.
+type GetAllDataSourcesQuery struct {
+	Result []*DataSource
+}
+
 type GetDefaultDataSourceQuery struct {
 	OrgId  int64
 	User   *SignedInUser
@@ -244,6 +244,18 @@ func ClearDSDecryptionCache() {
 	dsDecryptionCache.cache = make(map[int64]cachedDecryptedJSON)
 }
 
+// ClearDSCache removes the cached HTTP transport and decrypted secureJsonData of the data source, so that they're
+// created again when next used.
+func ClearDSCache(id int64) {
+	ptc.Lock()
+	delete(ptc.cache, id)
+	ptc.Unlock()
+
+	dsDecryptionCache.Lock()
+	delete(dsDecryptionCache.cache, id)
+	dsDecryptionCache.Unlock()
+}
+
 func awsServiceNamespace(dsType string) string {
 	switch dsType {
 	case DS_ES, DS_ES_OPEN_DISTRO:
@@ -367,6 +367,54 @@ func TestDataSource_DecryptedValue(t *testing.T) {
 	})
 }
 
+func TestClearDSCache(t *testing.T) {
+	ClearDSDecryptionCache()
+	clearDSProxyCache(t)
+
+	var configuredTransport *http.Transport
+	provider := httpclient.NewProvider(sdkhttpclient.ProviderOptions{
+		ConfigureTransport: func(opts sdkhttpclient.Options, transport *http.Transport) {
+			configuredTransport = transport
+		},
+	})
+
+	ds := DataSource{
+		Id:   1,
+		Type: DS_INFLUXDB_08,
+		SecureJsonData: securejsondata.GetEncryptedJsonData(map[string]string{
+			"password": "password",
+		}),
+	}
+	other := DataSource{Id: 2, Type: DS_INFLUXDB_08}
+
+	// Populate caches
+	_, err := ds.GetHTTPTransport(provider)
+	require.NoError(t, err)
+	tr1 := configuredTransport
+	_, err = other.GetHTTPTransport(provider)
+	require.NoError(t, err)
+	ds.DecryptedValues()
+
+	ClearDSCache(1)
+
+	ds.SecureJsonData = securejsondata.GetEncryptedJsonData(map[string]string{
+		"password": "",
+	})
+	password, ok := ds.DecryptedValue("password")
+	require.True(t, ok)
+	require.Empty(t, password)
+
+	_, err = ds.GetHTTPTransport(provider)
+	require.NoError(t, err)
+	require.NotSame(t, tr1, configuredTransport)
+
+	// the caches of the other data sources are kept
+	configuredTransport = nil
+	_, err = other.GetHTTPTransport(provider)
+	require.NoError(t, err)
+	require.Nil(t, configuredTransport)
+}
+
 func clearDSProxyCache(t *testing.T) {
 	t.Helper()
 
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrDataSourceExploreDenied is returned when a user explores a data source restricted to other users.
+	ErrDataSourceExploreDenied = errors.New("access denied to explore the data source")
+	// ErrDataSourceExplorePermissionInvalid is returned for the explore permissions granted to none or several of a
+	// user, a team and a role.
+	ErrDataSourceExplorePermissionInvalid = errors.New("an explore permission is granted to one of a user, a team or a role")
+)
+
+// DataSourceExplorePermission grants a user, a team or the users of a role, and the roles including it, the
+// permission to query a data source in Explore. The data sources without explore permissions can be explored by any
+// user who can use Explore. The data sources with explore permissions can only be explored by the users they are
+// granted to and the org admins, and still be queried by the panels of the dashboards, so that the sensitive data
+// sources can power curated dashboards without being freely explorable.
+type DataSourceExplorePermission struct {
+	Id           int64
+	OrgId        int64
+	DatasourceId int64
+	UserId       int64
+	TeamId       int64
+	Role         *RoleType
+	Created      time.Time
+}
+
+// Matches returns whether the permission is granted to the user, a member of the teams.
+func (p *DataSourceExplorePermission) Matches(user *SignedInUser, teamIDs []int64) bool {
+	switch {
+	case p.UserId > 0:
+		return p.UserId == user.UserId
+	case p.TeamId > 0:
+		for _, teamID := range teamIDs {
+			if teamID == p.TeamId {
+				return true
+			}
+		}
+		return false
+	case p.Role != nil:
+		return user.OrgRole.Includes(*p.Role)
+	default:
+		return false
+	}
+}
+
+type DataSourceExplorePermissionDTO struct {
+	UserId    int64     `json:"userId,omitempty"`
+	UserLogin string    `json:"userLogin,omitempty"`
+	TeamId    int64     `json:"teamId,omitempty"`
+	Team      string    `json:"team,omitempty"`
+	Role      *RoleType `json:"role,omitempty"`
+	Created   time.Time `json:"created"`
+}
+
+// ---------------------
+// QUERIES
+
+type GetDataSourceExplorePermissionsQuery struct {
+	OrgId        int64
+	DatasourceId int64
+
+	Result []*DataSourceExplorePermissionDTO
+}
+
+// CanExploreDataSourceQuery returns whether a user may explore a data source, by the explore permissions of the data
+// source.
+type CanExploreDataSourceQuery struct {
+	OrgId        int64
+	DatasourceId int64
+	User         *SignedInUser
+
+	Result bool
+}
+
+// ---------------------
+// COMMANDS
+
+// UpdateDataSourceExplorePermissionsCommand replaces the explore permissions of a data source.
+type UpdateDataSourceExplorePermissionsCommand struct {
+	OrgId        int64
+	DatasourceId int64
+	Items        []*DataSourceExplorePermission
+}
@@ -0,0 +1,157 @@
+package models
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/securejsondata"
+)
+
+var (
+	ErrDataSourceSecretsNotStaged     = errors.New("no secrets are staged for the data source")
+	ErrDataSourceSecretsNotValidated  = errors.New("the staged secrets of the data source haven't passed validation")
+	ErrDataSourceSecretsStagedChanged = errors.New("the staged secrets of the data source were changed")
+	ErrDataSourceSecretsNoPrevious    = errors.New("the data source has no previous secrets to roll back to")
+	ErrDataSourceSecretsRequired      = errors.New("secrets to stage are required")
+)
+
+// DataSourceSecretRotation is the rotation of the secrets of a data source: the staged secrets, to be validated with
+// the rest of the configuration of the data source before they're promoted, and the previous secrets, replaced by the
+// latest promotion and kept until their grace period expires so that the promotion can be rolled back, e.g. when the
+// upstream revoked the old credential before the dashboards were checked against the new one.
+//
+// The secrets are the keys of the secure JSON data, like password and basicAuthPassword, encrypted like it. The
+// previous secrets hold an empty value for the keys that weren't set before the promotion.
+type DataSourceSecretRotation struct {
+	Id              int64
+	OrgId           int64
+	DatasourceId    int64
+	StagedSecrets   securejsondata.SecureJsonData
+	Revision        int64
+	Staged          time.Time
+	StagedBy        int64
+	Validated       bool
+	PreviousSecrets securejsondata.SecureJsonData
+	Promoted        time.Time
+	PromotedBy      int64
+	GraceExpires    time.Time
+}
+
+// HasPrevious returns whether the previous secrets can still be rolled back to.
+func (r *DataSourceSecretRotation) HasPrevious(now time.Time) bool {
+	return len(r.PreviousSecrets) > 0 && now.Before(r.GraceExpires)
+}
+
+// DataSourceSecretRotationDTO is the status of the rotation of the secrets of a data source, with the keys of the
+// secrets only.
+type DataSourceSecretRotationDTO struct {
+	DatasourceId int64      `json:"datasourceId"`
+	StagedKeys   []string   `json:"stagedKeys"`
+	Revision     int64      `json:"revision"`
+	Staged       *time.Time `json:"staged,omitempty"`
+	StagedBy     int64      `json:"stagedBy,omitempty"`
+	Validated    bool       `json:"validated"`
+	PreviousKeys []string   `json:"previousKeys"`
+	Promoted     *time.Time `json:"promoted,omitempty"`
+	PromotedBy   int64      `json:"promotedBy,omitempty"`
+	GraceExpires *time.Time `json:"graceExpires,omitempty"`
+}
+
+// ToDTO returns the status of the rotation at a time.
+func (r *DataSourceSecretRotation) ToDTO(now time.Time) *DataSourceSecretRotationDTO {
+	dto := &DataSourceSecretRotationDTO{
+		DatasourceId: r.DatasourceId,
+		StagedKeys:   secretKeys(r.StagedSecrets),
+		Revision:     r.Revision,
+		Validated:    r.Validated,
+		PreviousKeys: []string{},
+	}
+	if len(r.StagedSecrets) > 0 {
+		staged := r.Staged
+		dto.Staged = &staged
+		dto.StagedBy = r.StagedBy
+	}
+	if r.HasPrevious(now) {
+		promoted, graceExpires := r.Promoted, r.GraceExpires
+		dto.PreviousKeys = secretKeys(r.PreviousSecrets)
+		dto.Promoted = &promoted
+		dto.PromotedBy = r.PromotedBy
+		dto.GraceExpires = &graceExpires
+	}
+	return dto
+}
+
+func secretKeys(secrets securejsondata.SecureJsonData) []string {
+	keys := make([]string, 0, len(secrets))
+	for key := range secrets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ---------------------
+// QUERIES
+
+// GetDataSourceSecretRotationQuery returns the rotation of the secrets of a data source, or an empty rotation when
+// none was staged.
+type GetDataSourceSecretRotationQuery struct {
+	OrgId        int64
+	DatasourceId int64
+
+	Result *DataSourceSecretRotation
+}
+
+// ---------------------
+// COMMANDS
+
+// StageDataSourceSecretsCommand stages secrets of a data source, replacing the staged ones.
+type StageDataSourceSecretsCommand struct {
+	OrgId        int64
+	DatasourceId int64
+	UserId       int64
+	Secrets      map[string]string
+
+	Result *DataSourceSecretRotation
+}
+
+// DiscardDataSourceSecretsCommand discards the staged secrets of a data source.
+type DiscardDataSourceSecretsCommand struct {
+	OrgId        int64
+	DatasourceId int64
+}
+
+// SetDataSourceSecretsValidatedCommand records the validation of the staged secrets of a data source, unless they
+// were staged again since the revision validated.
+type SetDataSourceSecretsValidatedCommand struct {
+	OrgId        int64
+	DatasourceId int64
+	Revision     int64
+	Validated    bool
+}
+
+// PromoteDataSourceSecretsCommand atomically replaces the secrets of a data source by its validated staged secrets,
+// keeping the replaced ones for the grace period.
+type PromoteDataSourceSecretsCommand struct {
+	OrgId        int64
+	DatasourceId int64
+	UserId       int64
+	GracePeriod  time.Duration
+
+	Result *DataSource
+}
+
+// RollbackDataSourceSecretsCommand restores the previous secrets of a data source during their grace period.
+type RollbackDataSourceSecretsCommand struct {
+	OrgId        int64
+	DatasourceId int64
+	UserId       int64
+
+	Result *DataSource
+}
+
+// DeleteExpiredDataSourceSecretsCommand deletes the previous secrets of the data sources whose grace period expired.
+type DeleteExpiredDataSourceSecretsCommand struct {
+	DeletedRows int64
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryExamplesFromJSONData(t *testing.T) {
+	t.Run("Without query examples", func(t *testing.T) {
+		examples, err := QueryExamplesFromJSONData(simplejson.New())
+		require.NoError(t, err)
+		require.Empty(t, examples)
+
+		examples, err = QueryExamplesFromJSONData(nil)
+		require.NoError(t, err)
+		require.Empty(t, examples)
+	})
+
+	t.Run("With query examples", func(t *testing.T) {
+		examples, err := QueryExamplesFromJSONData(simplejson.NewFromAny(map[string]interface{}{
+			"queryExamples": []interface{}{
+				map[string]interface{}{"title": "Errors", "query": map[string]interface{}{"expr": "errors"}, "default": true},
+				map[string]interface{}{"title": "Latency", "description": "p99", "query": map[string]interface{}{}},
+			},
+		}))
+		require.NoError(t, err)
+		require.Len(t, examples, 2)
+		require.True(t, examples[0].Default)
+		require.Equal(t, "errors", examples[0].Query.Get("expr").MustString())
+		require.Equal(t, "p99", examples[1].Description)
+	})
+
+	for name, queryExamples := range map[string]interface{}{
+		"not a list":         "errors",
+		"without title":      []interface{}{map[string]interface{}{"query": map[string]interface{}{}}},
+		"without query":      []interface{}{map[string]interface{}{"title": "Errors"}},
+		"with a query value": []interface{}{map[string]interface{}{"title": "Errors", "query": "errors"}},
+		"with duplicate titles": []interface{}{
+			map[string]interface{}{"title": "Errors", "query": map[string]interface{}{}},
+			map[string]interface{}{"title": "Errors", "query": map[string]interface{}{}},
+		},
+		"with two default queries": []interface{}{
+			map[string]interface{}{"title": "Errors", "query": map[string]interface{}{}, "default": true},
+			map[string]interface{}{"title": "Latency", "query": map[string]interface{}{}, "default": true},
+		},
+	} {
+		t.Run("Invalid query examples "+name, func(t *testing.T) {
+			_, err := QueryExamplesFromJSONData(simplejson.NewFromAny(map[string]interface{}{"queryExamples": queryExamples}))
+			require.True(t, errors.Is(err, ErrDataSourceQueryExamplesInvalid), err)
+		})
+	}
+}
+
+func TestWarmUpQueriesFromJSONData(t *testing.T) {
+	t.Run("Without warm-up queries", func(t *testing.T) {
+		queries, err := WarmUpQueriesFromJSONData(simplejson.New())
+		require.NoError(t, err)
+		require.Empty(t, queries)
+	})
+
+	t.Run("With warm-up queries", func(t *testing.T) {
+		queries, err := WarmUpQueriesFromJSONData(simplejson.NewFromAny(map[string]interface{}{
+			"warmUpQueries": []interface{}{
+				map[string]interface{}{"name": "Prime", "query": map[string]interface{}{"rawSql": "SELECT 1"}, "interval": "5m"},
+				map[string]interface{}{"name": "Keep warm", "query": map[string]interface{}{}, "interval": "1h", "from": "now-1h"},
+			},
+		}))
+		require.NoError(t, err)
+		require.Len(t, queries, 2)
+		require.Equal(t, "SELECT 1", queries[0].Query.Get("rawSql").MustString())
+		require.Equal(t, 5*time.Minute, queries[0].IntervalDuration())
+		require.Equal(t, "now-1h", queries[1].From)
+	})
+
+	for name, warmUpQueries := range map[string]interface{}{
+		"not a list":         "SELECT 1",
+		"without name":       []interface{}{map[string]interface{}{"query": map[string]interface{}{}, "interval": "5m"}},
+		"without query":      []interface{}{map[string]interface{}{"name": "Prime", "interval": "5m"}},
+		"with a query value": []interface{}{map[string]interface{}{"name": "Prime", "query": "SELECT 1", "interval": "5m"}},
+		"without interval":   []interface{}{map[string]interface{}{"name": "Prime", "query": map[string]interface{}{}}},
+		"with a short interval": []interface{}{
+			map[string]interface{}{"name": "Prime", "query": map[string]interface{}{}, "interval": "10s"},
+		},
+		"with duplicate names": []interface{}{
+			map[string]interface{}{"name": "Prime", "query": map[string]interface{}{}, "interval": "5m"},
+			map[string]interface{}{"name": "Prime", "query": map[string]interface{}{}, "interval": "5m"},
+		},
+	} {
+		t.Run("Invalid warm-up queries "+name, func(t *testing.T) {
+			_, err := WarmUpQueriesFromJSONData(simplejson.NewFromAny(map[string]interface{}{"warmUpQueries": warmUpQueries}))
+			require.True(t, errors.Is(err, ErrDataSourceWarmUpQueriesInvalid), err)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+var ErrDataSourceVersionNotFound = errors.New("data source version not found")
+
+// DataSourceVersion is a version of the configuration of a data source, stored by the additions and updates of the
+// data source which change it. The versions of a data source are numbered from 1, and don't store the passwords and
+// the values of the secure JSON data, only the names of the secure JSON fields.
+type DataSourceVersion struct {
+	Id           int64            `json:"id"`
+	OrgId        int64            `json:"-"`
+	DataSourceId int64            `json:"dataSourceId"`
+	Version      int              `json:"version"`
+	RestoredFrom int              `json:"restoredFrom"`
+	Data         *simplejson.Json `json:"data"`
+	Created      time.Time        `json:"created"`
+	CreatedBy    int64            `json:"createdBy"`
+}
+
+// DataSourceVersionDTO represents a data source version with the login of its author, without the configuration.
+type DataSourceVersionDTO struct {
+	Id           int64     `json:"id"`
+	DataSourceId int64     `json:"dataSourceId"`
+	Version      int       `json:"version"`
+	RestoredFrom int       `json:"restoredFrom"`
+	Created      time.Time `json:"created"`
+	CreatedBy    string    `json:"createdBy"`
+}
+
+// ---------------------
+// QUERIES
+
+type GetDataSourceVersionQuery struct {
+	OrgId        int64
+	DataSourceId int64
+	Version      int
+
+	Result *DataSourceVersion
+}
+
+// GetDataSourceVersionsQuery returns the versions of a data source, the newest first.
+type GetDataSourceVersionsQuery struct {
+	OrgId        int64
+	DataSourceId int64
+	Limit        int
+	Start        int
+
+	Result []*DataSourceVersionDTO
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// ImpersonationCookieName is the name of the cookie of the impersonation sessions of the Grafana admins.
+const ImpersonationCookieName = "grafana_impersonation"
+
+var (
+	ErrImpersonationSessionNotFound = errors.New("impersonation session not found")
+)
+
+// ImpersonationSession is a time-limited session of a Grafana admin impersonating another user, to see Grafana like
+// the user does. The requests of the admin with the key of the session are the requests of the user, and are
+// audit-logged.
+type ImpersonationSession struct {
+	Id          int64
+	AdminUserId int64
+	UserId      int64
+	Reason      string
+	KeyHash     string
+	Created     time.Time
+	Expires     time.Time
+	// Stopped is true when the admin stopped the session before it expired
+	Stopped bool
+}
+
+// IsActive returns true if the session isn't stopped or expired.
+func (s *ImpersonationSession) IsActive(now time.Time) bool {
+	return !s.Stopped && now.Before(s.Expires)
+}
+
+// HashImpersonationKey returns the hash of the key of an impersonation session, which is stored instead of the key.
+func HashImpersonationKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// ImpersonationSessionDTO represents an impersonation session with the logins of the admin and user.
+type ImpersonationSessionDTO struct {
+	Id          int64     `json:"id"`
+	AdminUserId int64     `json:"adminUserId"`
+	AdminLogin  string    `json:"adminLogin"`
+	UserId      int64     `json:"userId"`
+	UserLogin   string    `json:"userLogin"`
+	Reason      string    `json:"reason"`
+	Created     time.Time `json:"created"`
+	Expires     time.Time `json:"expires"`
+	Stopped     bool      `json:"stopped"`
+	Active      bool      `json:"active"`
+}
+
+// ---------------------
+// COMMANDS
+
+type StartImpersonationCommand struct {
+	AdminUserId int64
+	UserId      int64
+	Reason      string
+	KeyHash     string
+	Expires     time.Time
+
+	Result *ImpersonationSession
+}
+
+type StopImpersonationCommand struct {
+	Id int64
+}
+
+// ---------------------
+// QUERIES
+
+// GetImpersonationSessionQuery returns the impersonation session of the hash of its key.
+type GetImpersonationSessionQuery struct {
+	KeyHash string
+
+	Result *ImpersonationSession
+}
+
+// SearchImpersonationSessionsQuery returns a page of the impersonation sessions, the newest first, of a user when
+// the user ID is set.
+type SearchImpersonationSessionsQuery struct {
+	UserId  int64
+	Page    int
+	PerPage int
+
+	Result SearchImpersonationSessionsQueryResult
+}
+
+type SearchImpersonationSessionsQueryResult struct {
+	TotalCount int64                      `json:"totalCount"`
+	Sessions   []*ImpersonationSessionDTO `json:"sessions"`
+	Page       int                        `json:"page"`
+	PerPage    int                        `json:"perPage"`
+}
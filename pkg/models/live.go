@@ -78,6 +78,35 @@ type DashboardActivityChannel interface {
 	// Experimental! Indicate is GitOps is active.  This really means
 	// someone is subscribed to the `grafana/dashboards/gitops` channel
 	HasGitOpsObserver(orgID int64) bool
+
+	// DashboardEditors returns the users editing a dashboard and who holds
+	// its edit lock
+	DashboardEditors(orgID int64, uid string) DashboardEditors
+
+	// TakeOverDashboardEditLock makes the user the holder of the edit lock of
+	// a dashboard, and tells the other editors
+	TakeOverDashboardEditLock(orgID int64, user *UserDisplayDTO, uid string) error
+}
+
+// DashboardEditor is a user editing a dashboard
+type DashboardEditor struct {
+	User  *UserDisplayDTO `json:"user"`
+	Since time.Time       `json:"since"`
+}
+
+// DashboardEditors are the users editing a dashboard, in the order they
+// started editing it
+type DashboardEditors struct {
+	Editors []DashboardEditor `json:"editors"`
+	// LockedBy is the editor holding the edit lock, the first editor unless
+	// another one took it over
+	LockedBy *UserDisplayDTO `json:"lockedBy,omitempty"`
+}
+
+// IsLockedByAnother returns whether another user than the one with the ID
+// holds the edit lock
+func (e DashboardEditors) IsLockedByAnother(userID int64) bool {
+	return e.LockedBy != nil && e.LockedBy.Id != userID
 }
 
 type LiveMessage struct {
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+)
+
+// LoginHistoryEntry is a login attempt of a user, successful or not. The user ID is 0 when no user has the login of
+// the attempt.
+type LoginHistoryEntry struct {
+	Id         int64
+	UserId     int64
+	Login      string
+	AuthModule string
+	Success    bool
+	IpAddress  string
+	UserAgent  string
+	Location   string
+	Created    int64
+}
+
+// ---------------------
+// COMMANDS
+
+type CreateLoginHistoryEntryCommand struct {
+	UserId     int64
+	Login      string
+	AuthModule string
+	Success    bool
+	IpAddress  string
+	UserAgent  string
+	Location   string
+
+	Result *LoginHistoryEntry
+}
+
+type DeleteOldLoginHistoryCommand struct {
+	OlderThan   time.Time
+	DeletedRows int64
+}
+
+// ---------------------
+// QUERIES
+
+// GetUserLoginHistoryQuery returns the last login attempts of a user, most recent first
+type GetUserLoginHistoryQuery struct {
+	UserId int64
+	Limit  int
+
+	Result []*LoginHistoryEntry
+}
+
+// IsKnownLoginLocationQuery returns whether a user has logged in successfully from a location before, and whether
+// they have logged in successfully at all
+type IsKnownLoginLocationQuery struct {
+	UserId   int64
+	Location string
+
+	Result      bool
+	HasLoggedIn bool
+}
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	ErrOrgRoleRuleNotFound = errors.New("org role rule not found")
+)
+
+// OrgRoleRule adds the users whose email matches its domain or regex to an org with its role, when they log in for
+// the first time with an auth provider which doesn't set their org roles.
+type OrgRoleRule struct {
+	Id    int64
+	OrgId int64
+	// Domain is the lowercase domain of the emails of the rule, without @
+	Domain string
+	// Regex is the regular expression matching the emails of the rule, when Domain is empty
+	Regex   string
+	Role    RoleType
+	Created time.Time
+	Updated time.Time
+}
+
+// Matches returns true if the email matches the domain or regex of the rule.
+func (r *OrgRoleRule) Matches(email string) bool {
+	if r.Domain != "" {
+		at := strings.LastIndex(email, "@")
+		return at >= 0 && strings.ToLower(email[at+1:]) == r.Domain
+	}
+	if r.Regex != "" {
+		// the regex was validated when the rule was saved
+		re, err := regexp.Compile(r.Regex)
+		return err == nil && re.MatchString(email)
+	}
+	return false
+}
+
+// OrgRoleRuleDTO represents an org role rule with the name of its org.
+type OrgRoleRuleDTO struct {
+	Id      int64     `json:"id"`
+	OrgId   int64     `json:"orgId"`
+	OrgName string    `json:"orgName"`
+	Domain  string    `json:"domain"`
+	Regex   string    `json:"regex"`
+	Role    RoleType  `json:"role"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+// NormalizeOrgRoleRuleDomain returns the trimmed lowercase domain, without @.
+func NormalizeOrgRoleRuleDomain(domain string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(domain), "@"))
+}
+
+// ValidateOrgRoleRule returns an error if a rule doesn't have either a domain or a valid regex, or has an invalid
+// role.
+func ValidateOrgRoleRule(domain string, regex string, role RoleType) error {
+	if (domain == "") == (regex == "") {
+		return errors.New("an org role rule must have either a domain or a regex")
+	}
+	if strings.ContainsAny(domain, "@ ") {
+		return fmt.Errorf("invalid domain: %q", domain)
+	}
+	if regex != "" {
+		if _, err := regexp.Compile(regex); err != nil {
+			return fmt.Errorf("invalid regex: %v", err)
+		}
+	}
+	if !role.IsValid() {
+		return fmt.Errorf("invalid role: %q", role)
+	}
+	return nil
+}
+
+// MatchOrgRoleRules returns the rules matching the email, in the order of the rules and with the first matching rule
+// of each org.
+func MatchOrgRoleRules(rules []*OrgRoleRuleDTO, email string) []*OrgRoleRuleDTO {
+	matches := []*OrgRoleRuleDTO{}
+	orgs := map[int64]bool{}
+	for _, r := range rules {
+		rule := OrgRoleRule{Domain: r.Domain, Regex: r.Regex}
+		if orgs[r.OrgId] || !rule.Matches(email) {
+			continue
+		}
+		orgs[r.OrgId] = true
+		matches = append(matches, r)
+	}
+	return matches
+}
+
+// ---------------------
+// COMMANDS
+
+type CreateOrgRoleRuleCommand struct {
+	OrgId  int64    `json:"orgId" binding:"Required"`
+	Domain string   `json:"domain"`
+	Regex  string   `json:"regex"`
+	Role   RoleType `json:"role" binding:"Required"`
+
+	Result *OrgRoleRule `json:"-"`
+}
+
+type UpdateOrgRoleRuleCommand struct {
+	Id     int64    `json:"-"`
+	OrgId  int64    `json:"orgId" binding:"Required"`
+	Domain string   `json:"domain"`
+	Regex  string   `json:"regex"`
+	Role   RoleType `json:"role" binding:"Required"`
+}
+
+type DeleteOrgRoleRuleCommand struct {
+	Id int64
+}
+
+// ---------------------
+// QUERIES
+
+// GetOrgRoleRulesQuery returns the org role rules of the existing orgs, in the order they were created.
+type GetOrgRoleRulesQuery struct {
+	Result []*OrgRoleRuleDTO
+}
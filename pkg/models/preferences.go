@@ -13,8 +13,12 @@ type Preferences struct {
 	HomeDashboardId int64
 	Timezone        string
 	Theme           string
-	Created         time.Time
-	Updated         time.Time
+	// WeekStart is the first day of the week, "monday", "saturday" or "sunday", and FiscalYearStartMonth the
+	// first month of the fiscal year, from 1 for January to 12. They're not set when empty and 0.
+	WeekStart            string
+	FiscalYearStartMonth int
+	Created              time.Time
+	Updated              time.Time
 }
 
 // ---------------------
@@ -42,7 +46,9 @@ type SavePreferencesCommand struct {
 	OrgId  int64
 	TeamId int64
 
-	HomeDashboardId int64  `json:"homeDashboardId"`
-	Timezone        string `json:"timezone"`
-	Theme           string `json:"theme"`
+	HomeDashboardId      int64  `json:"homeDashboardId"`
+	Timezone             string `json:"timezone"`
+	Theme                string `json:"theme"`
+	WeekStart            string `json:"weekStart"`
+	FiscalYearStartMonth int    `json:"fiscalYearStartMonth"`
 }
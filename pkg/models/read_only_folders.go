@@ -0,0 +1,50 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrReadOnlyFolder is returned when a user without the permission to write read-only folders changes a dashboard
+// in one.
+var ErrReadOnlyFolder = errors.New("the dashboards of the folder are read-only")
+
+// ReadOnlyFolder is a folder of an org whose dashboards are read-only to the users without the permission to write
+// them, e.g. the editors in regulated environments.
+type ReadOnlyFolder struct {
+	Id       int64
+	OrgId    int64
+	FolderId int64
+	Created  time.Time
+}
+
+type ReadOnlyFolderDTO struct {
+	FolderId    int64  `json:"folderId"`
+	FolderUid   string `json:"folderUid"`
+	FolderTitle string `json:"folderTitle"`
+}
+
+// ---------------------
+// QUERIES
+
+type GetReadOnlyFoldersQuery struct {
+	OrgId int64
+
+	Result []*ReadOnlyFolderDTO
+}
+
+type IsReadOnlyFolderQuery struct {
+	OrgId    int64
+	FolderId int64
+
+	Result bool
+}
+
+// ---------------------
+// COMMANDS
+
+// SetReadOnlyFoldersCommand replaces the read-only folders of an org.
+type SetReadOnlyFoldersCommand struct {
+	OrgId     int64
+	FolderIds []int64
+}
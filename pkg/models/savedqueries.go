@@ -0,0 +1,37 @@
+package models
+
+import (
+	"errors"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+var (
+	ErrSavedQueryNotFound         = errors.New("saved query could not be found")
+	ErrSavedQueryInvalidVariables = errors.New("invalid saved query variables")
+)
+
+// SavedQueryUIDKey is the property of queries of panels and alert rules
+// referencing a saved query, and SavedQueryVariablesKey the one of the
+// values of its variables.
+const (
+	SavedQueryUIDKey       = "savedQueryUid"
+	SavedQueryVariablesKey = "savedQueryVariables"
+)
+
+// IsSavedQueryReference returns whether the query references a saved query.
+func IsSavedQueryReference(query *simplejson.Json) bool {
+	return query.Get(SavedQueryUIDKey).MustString() != ""
+}
+
+// ResolveSavedQueryQuery resolves a query referencing a saved query to the
+// query of the saved query with its variables replaced.
+type ResolveSavedQueryQuery struct {
+	OrgId int64
+	// SignedInUser is the user the query is run for, whose permissions
+	// on the folder of the saved query are checked. It's nil for alert rules.
+	SignedInUser *SignedInUser
+	Query        *simplejson.Json
+
+	Result *simplejson.Json
+}
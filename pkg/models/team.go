@@ -9,6 +9,7 @@ import (
 var (
 	ErrTeamNotFound                         = errors.New("team not found")
 	ErrTeamNameTaken                        = errors.New("team name is taken")
+	ErrTeamUidTaken                         = errors.New("team uid is taken")
 	ErrTeamMemberNotFound                   = errors.New("team member not found")
 	ErrLastTeamAdmin                        = errors.New("not allowed to remove last admin")
 	ErrNotAllowedToUpdateTeam               = errors.New("user not allowed to update team")
@@ -18,6 +19,7 @@ var (
 // Team model
 type Team struct {
 	Id    int64  `json:"id"`
+	Uid   string `json:"uid"`
 	OrgId int64  `json:"orgId"`
 	Name  string `json:"name"`
 	Email string `json:"email"`
@@ -57,6 +59,12 @@ type GetTeamByIdQuery struct {
 	Result       *TeamDTO
 }
 
+type GetTeamByUidQuery struct {
+	OrgId  int64
+	Uid    string
+	Result *TeamDTO
+}
+
 type GetTeamsByUserQuery struct {
 	OrgId  int64
 	UserId int64      `json:"userId"`
@@ -78,6 +86,7 @@ type SearchTeamsQuery struct {
 
 type TeamDTO struct {
 	Id          int64          `json:"id"`
+	Uid         string         `json:"uid"`
 	OrgId       int64          `json:"orgId"`
 	Name        string         `json:"name"`
 	Email       string         `json:"email"`
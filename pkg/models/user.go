@@ -179,6 +179,8 @@ type SignedInUser struct {
 	HelpFlags1     HelpFlags1
 	LastSeenAt     time.Time
 	Teams          []int64
+	// ImpersonatorId is the ID of the Grafana admin impersonating the user, 0 when the user isn't impersonated
+	ImpersonatorId int64
 }
 
 func (u *SignedInUser) ShouldUpdateLastSeenAt() bool {
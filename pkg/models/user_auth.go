@@ -116,6 +116,14 @@ type GetAuthInfoQuery struct {
 	Result *UserAuth
 }
 
+// GetUserExternalGroupsQuery returns the external groups of a user, synced
+// from the LDAP or OAuth provider at login.
+type GetUserExternalGroupsQuery struct {
+	UserId int64
+
+	Result []string
+}
+
 type TeamOrgGroupDTO struct {
 	TeamName string `json:"teamName"`
 	OrgName  string `json:"orgName"`
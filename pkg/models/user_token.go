@@ -54,7 +54,9 @@ type UserToken struct {
 	CreatedAt     int64
 	UpdatedAt     int64
 	RevokedAt     int64
-	UnhashedToken string
+	// LastActivityAt is when the token was last used, at the resolution of a minute
+	LastActivityAt int64
+	UnhashedToken  string
 }
 
 type RevokeAuthTokenCmd struct {
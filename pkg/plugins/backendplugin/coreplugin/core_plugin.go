@@ -52,6 +52,12 @@ func (cp *corePlugin) DataQuery(ctx context.Context, dsInfo *models.DataSource,
 	return adapter.DataQuery(ctx, dsInfo, tsdbQuery)
 }
 
+// CanHandleDataQueries returns whether the plugin handles data queries, since core plugins may only handle
+// resources.
+func (cp *corePlugin) CanHandleDataQueries() bool {
+	return cp.QueryDataHandler != nil
+}
+
 func (cp *corePlugin) Start(ctx context.Context) error {
 	return nil
 }
@@ -30,6 +30,8 @@ func TestCorePlugin(t *testing.T) {
 
 		err = p.CallResource(context.Background(), nil, nil)
 		require.Equal(t, backendplugin.ErrMethodNotImplemented, err)
+
+		require.False(t, p.(queryHandler).CanHandleDataQueries())
 	})
 
 	t.Run("New core plugin with handlers set in opts should return expected values", func(t *testing.T) {
@@ -66,4 +68,20 @@ func TestCorePlugin(t *testing.T) {
 		require.NoError(t, err)
 		require.True(t, callResourceCalled)
 	})
+
+	t.Run("New core plugin with query data handler should handle data queries", func(t *testing.T) {
+		factory := coreplugin.New(backend.ServeOpts{
+			QueryDataHandler: backend.QueryDataHandlerFunc(func(ctx context.Context,
+				req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+				return nil, nil
+			}),
+		})
+		p, err := factory("plugin", log.New("test"), nil)
+		require.NoError(t, err)
+		require.True(t, p.(queryHandler).CanHandleDataQueries())
+	})
+}
+
+type queryHandler interface {
+	CanHandleDataQueries() bool
 }
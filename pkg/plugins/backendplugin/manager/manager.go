@@ -189,6 +189,10 @@ func (m *manager) GetDataPlugin(pluginID string) interface{} {
 		return nil
 	}
 
+	if queryHandler, ok := p.(interface{ CanHandleDataQueries() bool }); ok && !queryHandler.CanHandleDataQueries() {
+		return nil
+	}
+
 	if dataPlugin, ok := p.(plugins.DataPlugin); ok {
 		return dataPlugin
 	}
@@ -0,0 +1,53 @@
+package plugins
+
+import (
+	"sort"
+	"strings"
+)
+
+// CSPDirectives are the Content Security Policy directives the plugins can add sources to.
+var CSPDirectives = map[string]bool{
+	"connect-src": true,
+	"font-src":    true,
+	"frame-src":   true,
+	"img-src":     true,
+	"media-src":   true,
+	"script-src":  true,
+	"style-src":   true,
+	"worker-src":  true,
+}
+
+// IsValidCSPSource returns whether a plugin can add the source to the Content Security Policy. Only host and scheme
+// sources are valid, the keywords, nonces and hashes would weaken the policy of every page and the separators would
+// inject directives.
+func IsValidCSPSource(source string) bool {
+	if source == "" || source == "*" {
+		return false
+	}
+	return !strings.ContainsAny(source, " \t\r\n;,'\"")
+}
+
+// CSPSources returns the sources the plugins add to the Content Security Policy, by directive. The sources are
+// deduplicated and sorted, for the policy to be the same for every request.
+func CSPSources(plugins []*PluginBase) map[string][]string {
+	seen := map[string]map[string]bool{}
+	for _, p := range plugins {
+		for directive, sources := range p.ContentSecurityPolicy {
+			if seen[directive] == nil {
+				seen[directive] = map[string]bool{}
+			}
+			for _, source := range sources {
+				seen[directive][source] = true
+			}
+		}
+	}
+
+	result := make(map[string][]string, len(seen))
+	for directive, sources := range seen {
+		for source := range sources {
+			result[directive] = append(result[directive], source)
+		}
+		sort.Strings(result[directive])
+	}
+	return result
+}
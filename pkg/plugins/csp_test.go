@@ -0,0 +1,29 @@
+package plugins
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsValidCSPSource(t *testing.T) {
+	for _, source := range []string{"https://cdn.example.com", "*.example.com", "data:", "wss://live.example.com:8443"} {
+		assert.True(t, IsValidCSPSource(source), source)
+	}
+	for _, source := range []string{"", "*", "'unsafe-eval'", "'nonce-abc'", "https://a.com; script-src *", "a.com b.com"} {
+		assert.False(t, IsValidCSPSource(source), source)
+	}
+}
+
+func TestCSPSources(t *testing.T) {
+	sources := CSPSources([]*PluginBase{
+		{Id: "a", ContentSecurityPolicy: map[string][]string{"script-src": {"https://b.example.com", "https://a.example.com"}}},
+		{Id: "b", ContentSecurityPolicy: map[string][]string{"script-src": {"https://a.example.com"}, "img-src": {"data:"}}},
+		{Id: "c"},
+	})
+
+	assert.Equal(t, map[string][]string{
+		"script-src": {"https://a.example.com", "https://b.example.com"},
+		"img-src":    {"data:"},
+	}, sources)
+}
@@ -49,6 +49,21 @@ func NewEncodedDataFrames(encodedFrames [][]byte) DataFrames {
 	}
 }
 
+// EncodedDataFramesSize returns the size in bytes of DataFrames instantiated from encoded frames, without decoding
+// them. It returns false for the other DataFrames.
+func EncodedDataFramesSize(frames DataFrames) (int64, bool) {
+	df, ok := frames.(*dataFrames)
+	if !ok || df.encoded == nil {
+		return 0, false
+	}
+
+	var size int64
+	for _, frame := range df.encoded {
+		size += int64(len(frame))
+	}
+	return size, true
+}
+
 func (df *dataFrames) Encoded() ([][]byte, error) {
 	if df.encoded == nil {
 		encoded, err := df.decoded.MarshalArrow()
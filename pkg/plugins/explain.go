@@ -0,0 +1,95 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// RedactedValue replaces the secrets in the explanations of the queries.
+const RedactedValue = "redacted"
+
+// sensitiveParams are the query parameters of the data source requests redacted in the explanations, lowercased.
+var sensitiveParams = map[string]bool{
+	"p":            true,
+	"password":     true,
+	"token":        true,
+	"access_token": true,
+	"api_key":      true,
+	"apikey":       true,
+}
+
+// DataQueryExplanation explains what a query will do, without running it.
+type DataQueryExplanation struct {
+	RefID string `json:"refId"`
+	// Macros are the values of the macros of the query, by macro as written in the query
+	Macros map[string]string `json:"macros,omitempty"`
+	// Statement is the final statement of the query, e.g. its SQL statement with the macros interpolated
+	Statement string `json:"statement,omitempty"`
+	// Request is the HTTP request of the query to the data source, with the secrets redacted
+	Request *ExplainedHTTPRequest `json:"request,omitempty"`
+	// Frames describes the frames of the response of the query
+	Frames string `json:"frames,omitempty"`
+	// Error is why the query can't run, e.g. an invalid macro
+	Error string `json:"error,omitempty"`
+}
+
+// ExplainedHTTPRequest is an HTTP request of a query to a data source.
+type ExplainedHTTPRequest struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+// DataQueryExplainer is implemented by the data plugins explaining their queries.
+type DataQueryExplainer interface {
+	ExplainDataQuery(ctx context.Context, ds *models.DataSource, query DataQuery) ([]DataQueryExplanation, error)
+}
+
+// ExplainHTTPRequest returns the explanation of a request to the URL of a data source, with the secrets of the data
+// source redacted: the password of the URL and the sensitive query parameters, the authorization header of the basic
+// and SigV4 authentication, and the values of the custom headers.
+func ExplainHTTPRequest(ds *models.DataSource, method, rawURL string, headers map[string]string,
+	body string) (*ExplainedHTTPRequest, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL of the data source: %w", err)
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), RedactedValue)
+	}
+	if u.RawQuery != "" {
+		params := u.Query()
+		for name := range params {
+			if sensitiveParams[strings.ToLower(name)] {
+				params[name] = []string{RedactedValue}
+			}
+		}
+		u.RawQuery = params.Encode()
+	}
+
+	req := &ExplainedHTTPRequest{Method: method, URL: u.String(), Headers: map[string]string{}, Body: body}
+	for name, value := range headers {
+		req.Headers[name] = value
+	}
+	switch {
+	case ds.BasicAuth || ds.User != "":
+		req.Headers["Authorization"] = "Basic " + RedactedValue
+	case ds.JsonData != nil && ds.JsonData.Get("sigV4Auth").MustBool(false):
+		req.Headers["Authorization"] = "AWS4-HMAC-SHA256 " + RedactedValue
+	}
+	if ds.JsonData != nil {
+		for i := 1; ; i++ {
+			name := ds.JsonData.Get(fmt.Sprintf("httpHeaderName%d", i)).MustString()
+			if name == "" {
+				break
+			}
+			req.Headers[name] = RedactedValue
+		}
+	}
+	return req, nil
+}
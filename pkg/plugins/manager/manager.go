@@ -511,6 +511,25 @@ func (pm *PluginManager) loadPlugin(jsonParser *json.Decoder, pluginBase *plugin
 		}
 	}
 
+	for directive, sources := range pb.ContentSecurityPolicy {
+		if !plugins.CSPDirectives[directive] {
+			pm.log.Warn("Ignoring unsupported Content Security Policy directive of plugin", "id", pb.Id, "directive", directive)
+			delete(pb.ContentSecurityPolicy, directive)
+			continue
+		}
+
+		valid := make([]string, 0, len(sources))
+		for _, source := range sources {
+			if !plugins.IsValidCSPSource(source) {
+				pm.log.Warn("Ignoring invalid Content Security Policy source of plugin", "id", pb.Id, "directive", directive,
+					"source", source)
+				continue
+			}
+			valid = append(valid, source)
+		}
+		pb.ContentSecurityPolicy[directive] = valid
+	}
+
 	// Copy relevant fields from the base
 	pb.PluginDir = pluginBase.PluginDir
 	pb.Signature = pluginBase.Signature
@@ -66,6 +66,9 @@ type PluginBase struct {
 	State        PluginState           `json:"state,omitempty"`
 	Signature    PluginSignatureStatus `json:"signature"`
 	Backend      bool                  `json:"backend"`
+	// ContentSecurityPolicy are the sources the plugin adds to the Content Security Policy, by directive, for the
+	// external assets it loads.
+	ContentSecurityPolicy map[string][]string `json:"contentSecurityPolicy,omitempty"`
 
 	IncludedInAppId string              `json:"-"`
 	PluginDir       string              `json:"-"`
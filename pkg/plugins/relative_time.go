@@ -0,0 +1,156 @@
+package plugins
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+var (
+	// relativeTimeExpr matches the relative times of Grafana, e.g. "now-1d/d" or "now/fy"
+	relativeTimeExpr = regexp.MustCompile(`^now((?:(?:[+-]\d+|/)(?:fy|fQ|[yQMwdhms]))*)$`)
+	relativeTimeOp   = regexp.MustCompile(`([+-]\d+|/)(fy|fQ|[yQMwdhms])`)
+)
+
+var weekdays = map[string]time.Weekday{
+	"monday":   time.Monday,
+	"saturday": time.Saturday,
+	"sunday":   time.Sunday,
+}
+
+// NewDataTimeRangeForUser returns a time range with the preferences of a user, or of the organization for a user
+// without an ID, applied. The time range is returned without the preferences when they fail to load.
+func NewDataTimeRangeForUser(ctx context.Context, from, to string, user *models.SignedInUser) (DataTimeRange, error) {
+	timeRange := NewDataTimeRange(from, to)
+
+	prefsQuery := models.GetPreferencesWithDefaultsQuery{User: user}
+	if err := bus.DispatchCtx(ctx, &prefsQuery); err != nil {
+		return timeRange, err
+	}
+	timeRange.ApplyPreferences(prefsQuery.Result)
+
+	return timeRange, nil
+}
+
+// ApplyPreferences sets the time zone, the first day of the week and the first month of the fiscal year of the
+// relative times of the time range, e.g. "now/d", "now/w" and "now/fy", to the ones of the preferences. The time zone
+// is UTC, the week starts on Monday and the fiscal year in January when they're not set, or set to the browser's.
+func (tr *DataTimeRange) ApplyPreferences(prefs *models.Preferences) {
+	if prefs == nil {
+		return
+	}
+
+	if prefs.Timezone != "" && prefs.Timezone != "browser" {
+		if location, err := time.LoadLocation(prefs.Timezone); err == nil {
+			tr.Location = location
+		}
+	}
+	if weekday, ok := weekdays[prefs.WeekStart]; ok {
+		tr.WeekStart = &weekday
+	}
+	if prefs.FiscalYearStartMonth >= 1 && prefs.FiscalYearStartMonth <= 12 {
+		tr.FiscalYearStartMonth = time.Month(prefs.FiscalYearStartMonth)
+	}
+}
+
+// parseRelativeTime evaluates a relative time, rounding it to the start of the units, or to their end when
+// withRoundUp, like the relative times of the time range picker. It returns false when the time isn't relative.
+func (tr DataTimeRange) parseRelativeTime(s string, withRoundUp bool, location *time.Location) (time.Time, bool) {
+	match := relativeTimeExpr.FindStringSubmatch(strings.TrimSpace(s))
+	if match == nil {
+		return time.Time{}, false
+	}
+
+	if location == nil {
+		location = time.UTC
+	}
+	t := tr.Now.In(location)
+	for _, op := range relativeTimeOp.FindAllStringSubmatch(match[1], -1) {
+		if op[1] == "/" {
+			t = tr.roundTime(t, op[2], withRoundUp)
+			continue
+		}
+
+		n, err := strconv.Atoi(op[1])
+		if err != nil {
+			return time.Time{}, false
+		}
+		t = addTimeUnits(t, n, op[2])
+	}
+
+	return t, true
+}
+
+func addTimeUnits(t time.Time, n int, unit string) time.Time {
+	switch unit {
+	case "y", "fy":
+		return t.AddDate(n, 0, 0)
+	case "Q", "fQ":
+		return t.AddDate(0, 3*n, 0)
+	case "M":
+		return t.AddDate(0, n, 0)
+	case "w":
+		return t.AddDate(0, 0, 7*n)
+	case "d":
+		return t.AddDate(0, 0, n)
+	case "h":
+		return t.Add(time.Duration(n) * time.Hour)
+	case "m":
+		return t.Add(time.Duration(n) * time.Minute)
+	default:
+		return t.Add(time.Duration(n) * time.Second)
+	}
+}
+
+// roundTime rounds a time down to the start of the unit, or up to the last millisecond of the unit.
+func (tr DataTimeRange) roundTime(t time.Time, unit string, withRoundUp bool) time.Time {
+	var start time.Time
+	switch unit {
+	case "y":
+		start = time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, t.Location())
+	case "fy":
+		start = time.Date(t.Year(), tr.fiscalYearStartMonth(), 1, 0, 0, 0, 0, t.Location())
+		if t.Before(start) {
+			start = start.AddDate(-1, 0, 0)
+		}
+	case "Q":
+		start = time.Date(t.Year(), t.Month()-(t.Month()-1)%3, 1, 0, 0, 0, 0, t.Location())
+	case "fQ":
+		monthsInQuarter := (int(t.Month()) - int(tr.fiscalYearStartMonth()) + 12) % 3
+		start = time.Date(t.Year(), t.Month()-time.Month(monthsInQuarter), 1, 0, 0, 0, 0, t.Location())
+	case "M":
+		start = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	case "w":
+		weekStart := time.Monday
+		if tr.WeekStart != nil {
+			weekStart = *tr.WeekStart
+		}
+		days := (int(t.Weekday()) - int(weekStart) + 7) % 7
+		start = time.Date(t.Year(), t.Month(), t.Day()-days, 0, 0, 0, 0, t.Location())
+	case "d":
+		start = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	case "h":
+		start = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	case "m":
+		start = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, t.Location())
+	default:
+		start = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, t.Location())
+	}
+
+	if withRoundUp {
+		return addTimeUnits(start, 1, unit).Add(-time.Millisecond)
+	}
+	return start
+}
+
+func (tr DataTimeRange) fiscalYearStartMonth() time.Month {
+	if tr.FiscalYearStartMonth == 0 {
+		return time.January
+	}
+	return tr.FiscalYearStartMonth
+}
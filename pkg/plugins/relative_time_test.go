@@ -0,0 +1,84 @@
+package plugins
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataTimeRangePreferences(t *testing.T) {
+	// Sunday 2021-01-03 01:30 UTC is Saturday 2021-01-02 20:30 in New York
+	now := time.Date(2021, 1, 3, 1, 30, 0, 0, time.UTC)
+
+	parse := func(t *testing.T, tr DataTimeRange, from, to string) (time.Time, time.Time) {
+		t.Helper()
+
+		tr.From, tr.To, tr.Now = from, to, now
+		fromTime, err := tr.ParseFrom()
+		require.NoError(t, err)
+		toTime, err := tr.ParseTo()
+		require.NoError(t, err)
+		return fromTime.UTC(), toTime.UTC()
+	}
+
+	t.Run("Should evaluate the relative times in UTC with weeks starting on Monday by default", func(t *testing.T) {
+		from, to := parse(t, DataTimeRange{}, "now/w", "now/w")
+		assert.Equal(t, time.Date(2020, 12, 28, 0, 0, 0, 0, time.UTC), from)
+		assert.Equal(t, time.Date(2021, 1, 3, 23, 59, 59, 999000000, time.UTC), to)
+
+		from, to = parse(t, DataTimeRange{}, "now-1d/d", "now")
+		assert.Equal(t, time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC), from)
+		assert.Equal(t, now, to)
+
+		from, _ = parse(t, DataTimeRange{}, "now/Q", "now")
+		assert.Equal(t, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), from)
+	})
+
+	t.Run("Should evaluate the relative times with the preferences", func(t *testing.T) {
+		tr := DataTimeRange{}
+		tr.ApplyPreferences(&models.Preferences{Timezone: "America/New_York", WeekStart: "sunday", FiscalYearStartMonth: 11})
+
+		from, to := parse(t, tr, "now/d", "now/d")
+		assert.Equal(t, time.Date(2021, 1, 2, 5, 0, 0, 0, time.UTC), from)
+		assert.Equal(t, time.Date(2021, 1, 3, 4, 59, 59, 999000000, time.UTC), to)
+
+		from, _ = parse(t, tr, "now/w", "now")
+		assert.Equal(t, time.Date(2020, 12, 27, 5, 0, 0, 0, time.UTC), from)
+
+		from, to = parse(t, tr, "now/fy", "now/fy")
+		assert.Equal(t, time.Date(2020, 11, 1, 4, 0, 0, 0, time.UTC), from)
+		assert.Equal(t, time.Date(2021, 11, 1, 3, 59, 59, 999000000, time.UTC), to)
+
+		from, to = parse(t, tr, "now-1fQ/fQ", "now-1fQ/fQ")
+		assert.Equal(t, time.Date(2020, 8, 1, 4, 0, 0, 0, time.UTC), from)
+		assert.Equal(t, time.Date(2020, 11, 1, 3, 59, 59, 999000000, time.UTC), to)
+	})
+
+	t.Run("Should start the weeks on Saturday", func(t *testing.T) {
+		tr := DataTimeRange{}
+		tr.ApplyPreferences(&models.Preferences{WeekStart: "saturday"})
+
+		from, _ := parse(t, tr, "now/w", "now")
+		assert.Equal(t, time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC), from)
+	})
+
+	t.Run("Should ignore the browser time zone and week start", func(t *testing.T) {
+		tr := DataTimeRange{}
+		tr.ApplyPreferences(&models.Preferences{Timezone: "browser", WeekStart: "browser"})
+
+		assert.Nil(t, tr.Location)
+		assert.Nil(t, tr.WeekStart)
+	})
+
+	t.Run("Should parse the other times as before", func(t *testing.T) {
+		from, to := parse(t, DataTimeRange{}, "5m", "1609637400000")
+		assert.Equal(t, now.Add(-5*time.Minute), from)
+		assert.Equal(t, now, to)
+
+		from, _ = parse(t, DataTimeRange{}, "2021-01-01", "now")
+		assert.Equal(t, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), from)
+	})
+}
@@ -39,6 +39,11 @@ type DataTimeRange struct {
 	From string
 	To   string
 	Now  time.Time
+	// Location, WeekStart and FiscalYearStartMonth are the time zone, the first day of the week and the first
+	// month of the fiscal year of the relative times, see ApplyPreferences.
+	Location             *time.Location
+	WeekStart            *time.Weekday
+	FiscalYearStartMonth time.Month
 }
 
 type DataTable struct {
@@ -285,22 +290,22 @@ func (tr *DataTimeRange) MustGetTo() time.Time {
 }
 
 func (tr DataTimeRange) ParseFrom() (time.Time, error) {
-	return parseTimeRange(tr.From, tr.Now, false, nil)
+	return tr.parseTimeRange(tr.From, false, tr.Location)
 }
 
 func (tr DataTimeRange) ParseTo() (time.Time, error) {
-	return parseTimeRange(tr.To, tr.Now, true, nil)
+	return tr.parseTimeRange(tr.To, true, tr.Location)
 }
 
 func (tr DataTimeRange) ParseFromWithLocation(location *time.Location) (time.Time, error) {
-	return parseTimeRange(tr.From, tr.Now, false, location)
+	return tr.parseTimeRange(tr.From, false, location)
 }
 
 func (tr DataTimeRange) ParseToWithLocation(location *time.Location) (time.Time, error) {
-	return parseTimeRange(tr.To, tr.Now, true, location)
+	return tr.parseTimeRange(tr.To, true, location)
 }
 
-func parseTimeRange(s string, now time.Time, withRoundUp bool, location *time.Location) (time.Time, error) {
+func (tr DataTimeRange) parseTimeRange(s string, withRoundUp bool, location *time.Location) (time.Time, error) {
 	if val, err := strconv.ParseInt(s, 10, 64); err == nil {
 		seconds := val / 1000
 		nano := (val - seconds*1000) * 1000000
@@ -309,8 +314,12 @@ func parseTimeRange(s string, now time.Time, withRoundUp bool, location *time.Lo
 
 	diff, err := time.ParseDuration("-" + s)
 	if err != nil {
+		if t, ok := tr.parseRelativeTime(s, withRoundUp, location); ok {
+			return t, nil
+		}
+
 		options := []func(*datemath.Options){
-			datemath.WithNow(now),
+			datemath.WithNow(tr.Now),
 			datemath.WithRoundUp(withRoundUp),
 		}
 		if location != nil {
@@ -320,7 +329,7 @@ func parseTimeRange(s string, now time.Time, withRoundUp bool, location *time.Lo
 		return datemath.ParseAndEvaluate(s, options...)
 	}
 
-	return now.Add(diff), nil
+	return tr.Now.Add(diff), nil
 }
 
 // SeriesToFrame converts a DataTimeSeries to an SDK frame.
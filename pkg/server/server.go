@@ -19,7 +19,9 @@ import (
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/bus"
 	_ "github.com/grafana/grafana/pkg/extensions"
+	_ "github.com/grafana/grafana/pkg/infra/eventbus"
 	"github.com/grafana/grafana/pkg/infra/httpclient/httpclientprovider"
+	_ "github.com/grafana/grafana/pkg/infra/leaderelection"
 	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/metrics"
@@ -32,18 +34,28 @@ import (
 	"github.com/grafana/grafana/pkg/middleware"
 	_ "github.com/grafana/grafana/pkg/plugins/manager"
 	"github.com/grafana/grafana/pkg/registry"
+	_ "github.com/grafana/grafana/pkg/services/adminapi"
 	_ "github.com/grafana/grafana/pkg/services/alerting"
 	_ "github.com/grafana/grafana/pkg/services/auth"
 	_ "github.com/grafana/grafana/pkg/services/auth/jwt"
 	_ "github.com/grafana/grafana/pkg/services/cleanup"
+	_ "github.com/grafana/grafana/pkg/services/comments"
+	_ "github.com/grafana/grafana/pkg/services/flight"
+	_ "github.com/grafana/grafana/pkg/services/graphql"
 	_ "github.com/grafana/grafana/pkg/services/librarypanels"
 	_ "github.com/grafana/grafana/pkg/services/login/loginservice"
+	_ "github.com/grafana/grafana/pkg/services/loginhistory"
 	_ "github.com/grafana/grafana/pkg/services/ngalert"
+	_ "github.com/grafana/grafana/pkg/services/ngalert/remoteeval"
 	_ "github.com/grafana/grafana/pkg/services/notifications"
 	_ "github.com/grafana/grafana/pkg/services/provisioning"
+	_ "github.com/grafana/grafana/pkg/services/querycost"
 	_ "github.com/grafana/grafana/pkg/services/rendering"
+	_ "github.com/grafana/grafana/pkg/services/savedqueries"
 	_ "github.com/grafana/grafana/pkg/services/search"
 	_ "github.com/grafana/grafana/pkg/services/sqlstore"
+	_ "github.com/grafana/grafana/pkg/services/usageexport"
+	_ "github.com/grafana/grafana/pkg/services/warmup"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -74,11 +74,25 @@ const (
 	ActionOrgUsersRemove     = "org.users:remove"
 	ActionOrgUsersRoleUpdate = "org.users.role:update"
 
+	// Read-only folders actions
+	ActionOrgReadOnlyFoldersRead  = "org.read_only_folders:read"
+	ActionOrgReadOnlyFoldersWrite = "org.read_only_folders:write"
+	// ActionDashboardsReadOnlyWrite allows to change the dashboards of read-only folders
+	ActionDashboardsReadOnlyWrite = "dashboards.read_only:write"
+
+	// Folder reviewers actions
+	ActionFoldersReviewersRead  = "folders.reviewers:read"
+	ActionFoldersReviewersWrite = "folders.reviewers:write"
+
 	// LDAP actions
 	ActionLDAPUsersRead  = "ldap.user:read"
 	ActionLDAPUsersSync  = "ldap.user:sync"
 	ActionLDAPStatusRead = "ldap.status:read"
 
+	// Alerting actions
+	ActionAlertingRuleExternalRead  = "alert.rules.external:read"
+	ActionAlertingRuleExternalWrite = "alert.rules.external:write"
+
 	// Global Scopes
 	ScopeGlobalUsersAll = "global:users:*"
 
@@ -87,6 +101,12 @@ const (
 
 	// Services Scopes
 	ScopeServicesAll = "service:*"
+
+	// Data sources Scopes
+	ScopeDatasourcesAll = "datasources:*"
+
+	// Folders Scopes
+	ScopeFoldersAll = "folders:*"
 )
 
 const RoleGrafanaAdmin = "Grafana Admin"
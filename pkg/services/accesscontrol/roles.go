@@ -135,6 +135,74 @@ var provisioningAdminRole = RoleDTO{
 	},
 }
 
+var alertingRulesExternalReadRole = RoleDTO{
+	Name:    alertingRulesExternalRead,
+	Version: 1,
+	Permissions: []Permission{
+		{
+			Action: ActionAlertingRuleExternalRead,
+			Scope:  ScopeDatasourcesAll,
+		},
+	},
+}
+
+var alertingRulesExternalEditRole = RoleDTO{
+	Name:    alertingRulesExternalEdit,
+	Version: 1,
+	Permissions: ConcatPermissions(alertingRulesExternalReadRole.Permissions, []Permission{
+		{
+			Action: ActionAlertingRuleExternalWrite,
+			Scope:  ScopeDatasourcesAll,
+		},
+	}),
+}
+
+var readOnlyFoldersReadRole = RoleDTO{
+	Name:    readOnlyFoldersRead,
+	Version: 1,
+	Permissions: []Permission{
+		{
+			Action: ActionOrgReadOnlyFoldersRead,
+		},
+	},
+}
+
+var readOnlyFoldersEditRole = RoleDTO{
+	Name:    readOnlyFoldersEdit,
+	Version: 1,
+	Permissions: ConcatPermissions(readOnlyFoldersReadRole.Permissions, []Permission{
+		{
+			Action: ActionOrgReadOnlyFoldersWrite,
+		},
+		{
+			Action: ActionDashboardsReadOnlyWrite,
+			Scope:  ScopeFoldersAll,
+		},
+	}),
+}
+
+var folderReviewersReadRole = RoleDTO{
+	Name:    folderReviewersRead,
+	Version: 1,
+	Permissions: []Permission{
+		{
+			Action: ActionFoldersReviewersRead,
+			Scope:  ScopeFoldersAll,
+		},
+	},
+}
+
+var folderReviewersEditRole = RoleDTO{
+	Name:    folderReviewersEdit,
+	Version: 1,
+	Permissions: ConcatPermissions(folderReviewersReadRole.Permissions, []Permission{
+		{
+			Action: ActionFoldersReviewersWrite,
+			Scope:  ScopeFoldersAll,
+		},
+	}),
+}
+
 // FixedRoles provides a map of permission sets/roles which can be
 // assigned to a set of users. When adding a new resource protected by
 // Grafana access control the default permissions should be added to a
@@ -152,6 +220,15 @@ var FixedRoles = map[string]RoleDTO{
 	ldapAdminEdit: ldapAdminEditRole,
 
 	provisioningAdmin: provisioningAdminRole,
+
+	alertingRulesExternalRead: alertingRulesExternalReadRole,
+	alertingRulesExternalEdit: alertingRulesExternalEditRole,
+
+	readOnlyFoldersRead: readOnlyFoldersReadRole,
+	readOnlyFoldersEdit: readOnlyFoldersEditRole,
+
+	folderReviewersRead: folderReviewersReadRole,
+	folderReviewersEdit: folderReviewersEditRole,
 }
 
 const (
@@ -165,6 +242,15 @@ const (
 	ldapAdminRead = "fixed:ldap:admin:read"
 
 	provisioningAdmin = "fixed:provisioning:admin"
+
+	alertingRulesExternalEdit = "fixed:alerting:rules:external:edit"
+	alertingRulesExternalRead = "fixed:alerting:rules:external:read"
+
+	readOnlyFoldersEdit = "fixed:org:read_only_folders:edit"
+	readOnlyFoldersRead = "fixed:org:read_only_folders:read"
+
+	folderReviewersEdit = "fixed:folders:reviewers:edit"
+	folderReviewersRead = "fixed:folders:reviewers:read"
 )
 
 // FixedRoleGrants specifies which built-in roles are assigned
@@ -180,9 +266,19 @@ var FixedRoleGrants = map[string][]string{
 		usersOrgRead,
 	},
 	string(models.ROLE_ADMIN): {
+		folderReviewersEdit,
+		folderReviewersRead,
+		readOnlyFoldersEdit,
+		readOnlyFoldersRead,
 		usersOrgEdit,
 		usersOrgRead,
 	},
+	string(models.ROLE_EDITOR): {
+		alertingRulesExternalEdit,
+	},
+	string(models.ROLE_VIEWER): {
+		alertingRulesExternalRead,
+	},
 }
 
 func ConcatPermissions(permissions ...[]Permission) []Permission {
@@ -0,0 +1,283 @@
+// Package adminapi serves a versioned gRPC API for the administration of the
+// users, organizations, data sources and dashboards, for the operators
+// automating many Grafana instances. It goes through the same bus commands
+// and services as the HTTP API.
+package adminapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/adminapi/adminv1"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/services/libraryelements"
+	"github.com/grafana/grafana/pkg/services/librarypanels"
+	"github.com/grafana/grafana/pkg/services/live"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/provisioning"
+	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/services/sanitizer"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+var logger = log.New("grpc_admin_api")
+
+func init() {
+	registry.RegisterService(&Service{})
+}
+
+const (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+)
+
+type userCtxKey struct{}
+
+// Service is the gRPC admin API server. The calls are authenticated by the
+// basic auth of a user, in the organization of the x-grafana-org-id metadata
+// or else the current organization of the user, or by an API key sent as a
+// bearer token.
+type Service struct {
+	Cfg                   *setting.Cfg                     `inject:""`
+	ContextHandler        *contexthandler.ContextHandler   `inject:""`
+	SQLStore              *sqlstore.SQLStore               `inject:""`
+	Login                 login.Service                    `inject:""`
+	Live                  *live.GrafanaLive                `inject:""`
+	QuotaService          *quota.QuotaService              `inject:""`
+	ProvisioningService   provisioning.ProvisioningService `inject:""`
+	LibraryPanelService   librarypanels.Service            `inject:""`
+	LibraryElementService libraryelements.Service          `inject:""`
+	HTMLSanitizer         *sanitizer.Service               `inject:""`
+}
+
+func (s *Service) Init() error {
+	return nil
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.Cfg.GRPCAdminAPI.Enabled
+}
+
+func (s *Service) Run(ctx context.Context) error {
+	server, err := s.newServer()
+	if err != nil {
+		return err
+	}
+
+	lis, err := net.Listen("tcp", s.Cfg.GRPCAdminAPI.Address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.Cfg.GRPCAdminAPI.Address, err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		server.GracefulStop()
+	}()
+
+	logger.Info("gRPC admin API server started", "address", lis.Addr())
+	if err := server.Serve(lis); err != nil {
+		return fmt.Errorf("gRPC admin API server failed: %w", err)
+	}
+	return ctx.Err()
+}
+
+func (s *Service) newServer() (*grpc.Server, error) {
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(s.authenticateUnary),
+		grpc.ChainStreamInterceptor(s.authenticateStream),
+	}
+	if s.Cfg.GRPCAdminAPI.CertFile != "" {
+		creds, err := credentials.NewServerTLSFromFile(s.Cfg.GRPCAdminAPI.CertFile, s.Cfg.GRPCAdminAPI.CertKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the gRPC admin API TLS certificate: %w", err)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	server := grpc.NewServer(opts...)
+	adminv1.RegisterUserServiceServer(server, s)
+	adminv1.RegisterOrgServiceServer(server, s)
+	adminv1.RegisterDataSourceServiceServer(server, s)
+	adminv1.RegisterDashboardServiceServer(server, s)
+	return server, nil
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *Service) authenticateUnary(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (s *Service) authenticateStream(srv interface{}, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authenticate(stream.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+}
+
+// authenticate returns the context with the signed in user of the
+// authorization metadata of the call.
+func (s *Service) authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	header := firstValue(md, "authorization")
+	parts := strings.SplitN(header, " ", 2)
+	if len(parts) != 2 {
+		return nil, status.Error(codes.Unauthenticated, "missing credentials")
+	}
+
+	var user *models.SignedInUser
+	var err error
+	switch {
+	case strings.EqualFold(parts[0], "bearer"):
+		user, err = s.authenticateAPIKey(strings.TrimSpace(parts[1]))
+	case strings.EqualFold(parts[0], "basic") && s.Cfg.BasicAuthEnabled:
+		user, err = s.authenticateBasicAuth(ctx, header, firstValue(md, "x-grafana-org-id"))
+	default:
+		return nil, status.Error(codes.Unauthenticated, "unsupported authorization scheme")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return context.WithValue(ctx, userCtxKey{}, user), nil
+}
+
+func (s *Service) authenticateAPIKey(keyString string) (*models.SignedInUser, error) {
+	apikey, err := s.ContextHandler.ValidateAPIKey(keyString)
+	if err != nil {
+		switch {
+		case errors.Is(err, contexthandler.ErrInvalidAPIKey):
+			return nil, status.Error(codes.Unauthenticated, contexthandler.InvalidAPIKey)
+		case errors.Is(err, contexthandler.ErrExpiredAPIKey):
+			return nil, status.Error(codes.Unauthenticated, "expired API key")
+		}
+		logger.Error("Validating API key failed", "error", err)
+		return nil, status.Error(codes.Internal, "validating API key failed")
+	}
+
+	return &models.SignedInUser{OrgId: apikey.OrgId, OrgRole: apikey.Role, ApiKeyId: apikey.Id}, nil
+}
+
+func (s *Service) authenticateBasicAuth(ctx context.Context, header string, orgHeader string) (*models.SignedInUser, error) {
+	username, password, err := util.DecodeBasicAuthHeader(header)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid basic auth header")
+	}
+
+	var orgID int64
+	if orgHeader != "" {
+		if orgID, err = strconv.ParseInt(orgHeader, 10, 64); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid x-grafana-org-id")
+		}
+	}
+
+	authQuery := models.LoginUserQuery{Username: username, Password: password, Cfg: s.Cfg}
+	if err := bus.Dispatch(&authQuery); err != nil {
+		logger.Debug("Failed to authorize the user", "username", username, "err", err)
+		return nil, status.Error(codes.Unauthenticated, contexthandler.InvalidUsernamePassword)
+	}
+
+	query := models.GetSignedInUserQuery{UserId: authQuery.User.Id, OrgId: orgID}
+	if err := bus.DispatchCtx(ctx, &query); err != nil {
+		logger.Error("Failed at user signed in", "id", authQuery.User.Id, "org", orgID, "error", err)
+		return nil, status.Error(codes.Unauthenticated, contexthandler.InvalidUsernamePassword)
+	}
+	return query.Result, nil
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func signedInUser(ctx context.Context) (*models.SignedInUser, error) {
+	user, ok := ctx.Value(userCtxKey{}).(*models.SignedInUser)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing credentials")
+	}
+	return user, nil
+}
+
+// requireGrafanaAdmin returns the signed in user of the users and
+// organizations calls, which are for the Grafana server admins like
+// /api/admin.
+func requireGrafanaAdmin(ctx context.Context) (*models.SignedInUser, error) {
+	user, err := signedInUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsGrafanaAdmin {
+		return nil, status.Error(codes.PermissionDenied, "permission denied")
+	}
+	return user, nil
+}
+
+// requireOrgAdmin returns the signed in user of the data sources calls, which
+// are for the organization admins like /api/datasources.
+func requireOrgAdmin(ctx context.Context) (*models.SignedInUser, error) {
+	user, err := signedInUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if user.OrgRole != models.ROLE_ADMIN {
+		return nil, status.Error(codes.PermissionDenied, "permission denied")
+	}
+	return user, nil
+}
+
+func pageSize(size int32) int {
+	switch {
+	case size <= 0:
+		return defaultPageSize
+	case size > maxPageSize:
+		return maxPageSize
+	}
+	return int(size)
+}
+
+// httpStatusCode returns the gRPC code of the status code of an HTTP error,
+// for the errors of the services like models.DashboardErr.
+func httpStatusCode(statusCode int) codes.Code {
+	switch statusCode {
+	case 400, 422:
+		return codes.InvalidArgument
+	case 403:
+		return codes.PermissionDenied
+	case 404:
+		return codes.NotFound
+	case 409:
+		return codes.AlreadyExists
+	case 412:
+		return codes.FailedPrecondition
+	}
+	return codes.Internal
+}
@@ -0,0 +1,344 @@
+package adminapi
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/apikeygen"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/adminapi/adminv1"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/services/libraryelements"
+	"github.com/grafana/grafana/pkg/services/librarypanels"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/provisioning"
+	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/services/sanitizer"
+	"github.com/grafana/grafana/pkg/services/search"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakeLoginService struct {
+	login.Service
+	sqlStore *sqlstore.SQLStore
+}
+
+func (s fakeLoginService) CreateUser(cmd models.CreateUserCommand) (*models.User, error) {
+	return s.sqlStore.CreateUser(context.Background(), cmd)
+}
+
+type fakeLibraryPanelService struct {
+	librarypanels.Service
+}
+
+func (fakeLibraryPanelService) CleanLibraryPanelsForDashboard(*models.Dashboard) error {
+	return nil
+}
+
+func (fakeLibraryPanelService) ConnectLibraryPanelsForDashboard(*models.ReqContext, *models.Dashboard) error {
+	return nil
+}
+
+type fakeLibraryElementService struct {
+	libraryelements.Service
+}
+
+func (fakeLibraryElementService) DisconnectElementsFromDashboard(*models.ReqContext, int64) error {
+	return nil
+}
+
+type scenarioContext struct {
+	conn *grpc.ClientConn
+	// admin is the context of the calls of the Grafana admin, editor and
+	// apiKey of an editor and an admin API key of its organization
+	admin  context.Context
+	editor context.Context
+	apiKey context.Context
+}
+
+func setupScenario(t *testing.T) scenarioContext {
+	t.Helper()
+
+	sqlStore := sqlstore.InitTestDB(t)
+	cfg := setting.NewCfg()
+	cfg.BasicAuthEnabled = true
+
+	searchService := &search.SearchService{Bus: bus.GetBus(), Cfg: cfg}
+	require.NoError(t, searchService.Init())
+
+	ctx := context.Background()
+	_, err := sqlStore.CreateUser(ctx, models.CreateUserCommand{Login: "admin", Email: "admin@example.com", IsAdmin: true})
+	require.NoError(t, err)
+	// the test store registers its handlers of the signed in user on its own bus
+	bus.AddHandlerCtx("test", sqlStore.GetSignedInUserWithCacheCtx)
+	bus.AddHandler("test", func(query *models.LoginUserQuery) error {
+		if query.Password != "password" {
+			return models.ErrUserNotFound
+		}
+		userQuery := models.GetUserByLoginQuery{LoginOrEmail: query.Username}
+		if err := bus.Dispatch(&userQuery); err != nil {
+			return err
+		}
+		query.User = userQuery.Result
+		return nil
+	})
+
+	keys := map[models.RoleType]string{}
+	for _, role := range []models.RoleType{models.ROLE_ADMIN, models.ROLE_EDITOR} {
+		key, err := apikeygen.New(1, string(role))
+		require.NoError(t, err)
+		require.NoError(t, bus.Dispatch(&models.AddApiKeyCommand{Name: string(role), Role: role, OrgId: 1, Key: key.HashedKey}))
+		keys[role] = key.ClientSecret
+	}
+
+	s := &Service{
+		Cfg:                   cfg,
+		ContextHandler:        &contexthandler.ContextHandler{Cfg: cfg},
+		SQLStore:              sqlStore,
+		Login:                 fakeLoginService{sqlStore: sqlStore},
+		QuotaService:          &quota.QuotaService{Cfg: cfg},
+		ProvisioningService:   provisioning.NewProvisioningServiceMock(),
+		LibraryPanelService:   fakeLibraryPanelService{},
+		LibraryElementService: fakeLibraryElementService{},
+		HTMLSanitizer:         &sanitizer.Service{Cfg: cfg},
+	}
+	server, err := s.newServer()
+	require.NoError(t, err)
+
+	lis := bufconn.Listen(1024 * 1024)
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return lis.Dial()
+	}))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	basic := "Basic " + base64.StdEncoding.EncodeToString([]byte("admin:password"))
+	return scenarioContext{
+		conn:   conn,
+		admin:  metadata.AppendToOutgoingContext(ctx, "authorization", basic),
+		editor: metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+keys[models.ROLE_EDITOR]),
+		apiKey: metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+keys[models.ROLE_ADMIN]),
+	}
+}
+
+// receiveAll returns the messages of a list stream.
+func receiveAll(t *testing.T, recv func() (interface{}, error)) ([]interface{}, error) {
+	t.Helper()
+
+	var msgs []interface{}
+	for {
+		msg, err := recv()
+		if errors.Is(err, io.EOF) {
+			return msgs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, msg)
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	sc := setupScenario(t)
+	users := adminv1.NewUserServiceClient(sc.conn)
+
+	_, err := users.GetUser(context.Background(), &adminv1.GetUserRequest{Id: 1})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	wrongPassword := "Basic " + base64.StdEncoding.EncodeToString([]byte("admin:wrong"))
+	_, err = users.GetUser(metadata.AppendToOutgoingContext(context.Background(), "authorization", wrongPassword), &adminv1.GetUserRequest{Id: 1})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = users.GetUser(metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer invalid"), &adminv1.GetUserRequest{Id: 1})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// the API keys aren't Grafana admins
+	_, err = users.GetUser(sc.apiKey, &adminv1.GetUserRequest{Id: 1})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	user, err := users.GetUser(sc.admin, &adminv1.GetUserRequest{Id: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "admin", user.Login)
+	assert.True(t, user.IsGrafanaAdmin)
+}
+
+func TestUserService(t *testing.T) {
+	sc := setupScenario(t)
+	users := adminv1.NewUserServiceClient(sc.conn)
+
+	_, err := users.CreateUser(sc.admin, &adminv1.CreateUserRequest{Email: "viewer@example.com", Password: "p"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	created, err := users.CreateUser(sc.admin, &adminv1.CreateUserRequest{Email: "viewer@example.com", Name: "Viewer", Password: "password"})
+	require.NoError(t, err)
+	assert.Equal(t, "viewer@example.com", created.Login)
+
+	_, err = users.CreateUser(sc.admin, &adminv1.CreateUserRequest{Login: "admin", Email: "other@example.com", Password: "password"})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	updated, err := users.UpdateUser(sc.admin, &adminv1.UpdateUserRequest{Id: created.Id, Login: "viewer", Email: "viewer@example.com", Name: "Viewer"})
+	require.NoError(t, err)
+	assert.Equal(t, "viewer", updated.Login)
+
+	// the page size of one streams the users over several pages
+	stream, err := users.ListUsers(sc.admin, &adminv1.ListUsersRequest{PageSize: 1})
+	require.NoError(t, err)
+	msgs, err := receiveAll(t, func() (interface{}, error) { return stream.Recv() })
+	require.NoError(t, err)
+	var logins []string
+	for _, msg := range msgs {
+		logins = append(logins, msg.(*adminv1.User).Login)
+	}
+	assert.Equal(t, []string{"admin", "viewer"}, logins)
+
+	_, err = users.DeleteUser(sc.admin, &adminv1.DeleteUserRequest{Id: created.Id})
+	require.NoError(t, err)
+	_, err = users.GetUser(sc.admin, &adminv1.GetUserRequest{Id: created.Id})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestOrgService(t *testing.T) {
+	sc := setupScenario(t)
+	orgs := adminv1.NewOrgServiceClient(sc.conn)
+
+	created, err := orgs.CreateOrg(sc.admin, &adminv1.CreateOrgRequest{Name: "Team A"})
+	require.NoError(t, err)
+	_, err = orgs.CreateOrg(sc.admin, &adminv1.CreateOrgRequest{Name: "Team A"})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	updated, err := orgs.UpdateOrg(sc.admin, &adminv1.UpdateOrgRequest{Id: created.Id, Name: "Team B"})
+	require.NoError(t, err)
+	assert.Equal(t, "Team B", updated.Name)
+
+	stream, err := orgs.ListOrgs(sc.admin, &adminv1.ListOrgsRequest{Query: "Team"})
+	require.NoError(t, err)
+	msgs, err := receiveAll(t, func() (interface{}, error) { return stream.Recv() })
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, created.Id, msgs[0].(*adminv1.Org).Id)
+
+	_, err = orgs.DeleteOrg(sc.admin, &adminv1.DeleteOrgRequest{Id: created.Id})
+	require.NoError(t, err)
+	_, err = orgs.GetOrg(sc.admin, &adminv1.GetOrgRequest{Id: created.Id})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestDataSourceService(t *testing.T) {
+	sc := setupScenario(t)
+	datasources := adminv1.NewDataSourceServiceClient(sc.conn)
+
+	_, err := datasources.CreateDataSource(sc.editor, &adminv1.CreateDataSourceRequest{Name: "Prometheus", Type: "prometheus", Access: "proxy"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = datasources.CreateDataSource(sc.apiKey, &adminv1.CreateDataSourceRequest{Name: "Prometheus", Type: "prometheus", Access: "proxy", Url: "://invalid"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	created, err := datasources.CreateDataSource(sc.apiKey, &adminv1.CreateDataSourceRequest{
+		Uid:      "prom",
+		Name:     "Prometheus",
+		Type:     "prometheus",
+		Access:   "proxy",
+		Url:      "http://prometheus:9090",
+		JsonData: `{"httpMethod":"POST"}`,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), created.OrgId)
+	assert.JSONEq(t, `{"httpMethod":"POST"}`, created.JsonData)
+
+	updated, err := datasources.UpdateDataSource(sc.apiKey, &adminv1.UpdateDataSourceRequest{
+		Uid:     "prom",
+		Name:    "Prometheus",
+		Type:    "prometheus",
+		Access:  "proxy",
+		Url:     "http://prometheus:9091",
+		Version: created.Version,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "http://prometheus:9091", updated.Url)
+
+	_, err = datasources.UpdateDataSource(sc.apiKey, &adminv1.UpdateDataSourceRequest{
+		Uid:     "prom",
+		Name:    "Prometheus",
+		Type:    "prometheus",
+		Access:  "proxy",
+		Version: created.Version,
+	})
+	assert.Equal(t, codes.Aborted, status.Code(err))
+
+	// the basic auth of the Grafana admin is in its organization
+	stream, err := datasources.ListDataSources(sc.admin, &adminv1.ListDataSourcesRequest{})
+	require.NoError(t, err)
+	msgs, err := receiveAll(t, func() (interface{}, error) { return stream.Recv() })
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, "prom", msgs[0].(*adminv1.DataSource).Uid)
+
+	_, err = datasources.DeleteDataSource(sc.apiKey, &adminv1.DeleteDataSourceRequest{Uid: "prom"})
+	require.NoError(t, err)
+	_, err = datasources.GetDataSource(sc.apiKey, &adminv1.GetDataSourceRequest{Uid: "prom"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestDashboardService(t *testing.T) {
+	sc := setupScenario(t)
+	dashboards := adminv1.NewDashboardServiceClient(sc.conn)
+
+	_, err := dashboards.CreateDashboard(sc.admin, &adminv1.CreateDashboardRequest{Json: "{"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	created, err := dashboards.CreateDashboard(sc.admin, &adminv1.CreateDashboardRequest{
+		Json: `{"uid": "latency", "title": "Latency", "tags": ["prod"]}`,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "latency", created.Uid)
+	assert.Equal(t, []string{"prod"}, created.Tags)
+	assert.Equal(t, int32(1), created.Version)
+
+	_, err = dashboards.CreateDashboard(sc.admin, &adminv1.CreateDashboardRequest{Json: `{"uid": "latency", "title": "Other"}`})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = dashboards.UpdateDashboard(sc.admin, &adminv1.UpdateDashboardRequest{Uid: "latency", Json: `{"title": "Latency", "version": 0}`})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	updated, err := dashboards.UpdateDashboard(sc.editor, &adminv1.UpdateDashboardRequest{Uid: "latency", Json: `{"title": "Latency (p99)", "version": 1}`})
+	require.NoError(t, err)
+	assert.Equal(t, "Latency (p99)", updated.Title)
+	assert.Equal(t, created.Id, updated.Id)
+
+	stream, err := dashboards.ListDashboards(sc.editor, &adminv1.ListDashboardsRequest{Query: "latency"})
+	require.NoError(t, err)
+	msgs, err := receiveAll(t, func() (interface{}, error) { return stream.Recv() })
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, "Latency (p99)", msgs[0].(*adminv1.Dashboard).Title)
+
+	dash, err := dashboards.GetDashboard(sc.editor, &adminv1.GetDashboardRequest{Uid: "latency"})
+	require.NoError(t, err)
+	assert.Contains(t, dash.Json, `"title":"Latency (p99)"`)
+
+	_, err = dashboards.DeleteDashboard(sc.editor, &adminv1.DeleteDashboardRequest{Uid: "latency"})
+	require.NoError(t, err)
+	_, err = dashboards.GetDashboard(sc.editor, &adminv1.GetDashboardRequest{Uid: "latency"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
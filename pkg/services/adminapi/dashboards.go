@@ -0,0 +1,332 @@
+package adminapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gopkg.in/macaron.v1"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/adminapi/adminv1"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/search"
+)
+
+func (s *Service) ListDashboards(req *adminv1.ListDashboardsRequest, stream adminv1.DashboardService_ListDashboardsServer) error {
+	user, err := signedInUser(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	limit := pageSize(req.PageSize)
+	for page := int64(1); ; page++ {
+		if err := stream.Context().Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+
+		query := search.Query{
+			Title:        req.Query,
+			Tags:         req.Tags,
+			FolderIds:    req.FolderIds,
+			OrgId:        user.OrgId,
+			SignedInUser: user,
+			Limit:        int64(limit),
+			Page:         page,
+			Type:         string(search.DashHitDB),
+			Permission:   models.PERMISSION_VIEW,
+		}
+		if err := bus.Dispatch(&query); err != nil {
+			logger.Error("Search failed", "error", err)
+			return status.Error(codes.Internal, "search failed")
+		}
+
+		for _, hit := range query.Result {
+			if err := stream.Send(&adminv1.Dashboard{
+				Id:       hit.ID,
+				Uid:      hit.UID,
+				Title:    hit.Title,
+				Url:      hit.URL,
+				FolderId: hit.FolderID,
+				Tags:     hit.Tags,
+			}); err != nil {
+				return err
+			}
+		}
+		if len(query.Result) < limit {
+			return nil
+		}
+	}
+}
+
+func (s *Service) GetDashboard(ctx context.Context, req *adminv1.GetDashboardRequest) (*adminv1.Dashboard, error) {
+	user, err := signedInUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	dash, err := getDashboard(req.Uid, user)
+	if err != nil {
+		return nil, err
+	}
+	return dashboardToProto(dash)
+}
+
+func (s *Service) CreateDashboard(ctx context.Context, req *adminv1.CreateDashboardRequest) (*adminv1.Dashboard, error) {
+	user, err := signedInUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := simplejson.NewJson([]byte(req.Json))
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid dashboard JSON: %s", err)
+	}
+	data.Del("id")
+
+	c := reqContext(ctx, user)
+	limitReached, err := s.QuotaService.QuotaReached(c, "dashboard")
+	if err != nil {
+		logger.Error("Failed to get quota", "error", err)
+		return nil, status.Error(codes.Internal, "failed to get quota")
+	}
+	if limitReached {
+		return nil, status.Error(codes.ResourceExhausted, "quota reached")
+	}
+
+	return s.saveDashboard(c, models.SaveDashboardCommand{
+		Dashboard: data,
+		FolderId:  req.FolderId,
+		Message:   req.Message,
+	})
+}
+
+func (s *Service) UpdateDashboard(ctx context.Context, req *adminv1.UpdateDashboardRequest) (*adminv1.Dashboard, error) {
+	user, err := signedInUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := getDashboard(req.Uid, user)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := simplejson.NewJson([]byte(req.Json))
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid dashboard JSON: %s", err)
+	}
+	data.Set("id", existing.Id)
+	data.Set("uid", existing.Uid)
+
+	return s.saveDashboard(reqContext(ctx, user), models.SaveDashboardCommand{
+		Dashboard: data,
+		FolderId:  req.FolderId,
+		Message:   req.Message,
+		Overwrite: req.Overwrite,
+	})
+}
+
+// saveDashboard saves the dashboard like the POST /api/dashboards/db
+// endpoint, with the dashboard service checking the permissions.
+func (s *Service) saveDashboard(c *models.ReqContext, cmd models.SaveDashboardCommand) (*adminv1.Dashboard, error) {
+	cmd.OrgId = c.OrgId
+	cmd.UserId = c.UserId
+	s.HTMLSanitizer.SanitizeDashboard(c.OrgId, cmd.Dashboard)
+	dash := cmd.GetDashboardModel()
+
+	svc := dashboards.NewProvisioningService(s.SQLStore)
+	provisioningData, err := svc.GetProvisionedDashboardDataByDashboardID(dash.Id)
+	if err != nil {
+		logger.Error("Error while checking if dashboard is provisioned", "error", err)
+		return nil, status.Error(codes.Internal, "error while checking if dashboard is provisioned")
+	}
+
+	allowUiUpdate := true
+	if provisioningData != nil {
+		allowUiUpdate = s.ProvisioningService.GetAllowUIUpdatesFromConfig(provisioningData.Name)
+	}
+
+	// clean up all unnecessary library panels JSON properties so we store a minimum JSON
+	if err := s.LibraryPanelService.CleanLibraryPanelsForDashboard(dash); err != nil {
+		logger.Error("Error while cleaning library panels", "error", err)
+		return nil, status.Error(codes.Internal, "error while cleaning library panels")
+	}
+
+	dashSvc := dashboards.NewService(s.SQLStore)
+	dashboard, err := dashSvc.SaveDashboard(&dashboards.SaveDashboardDTO{
+		Dashboard: dash,
+		Message:   cmd.Message,
+		OrgId:     c.OrgId,
+		User:      c.SignedInUser,
+		Overwrite: cmd.Overwrite,
+	}, allowUiUpdate)
+
+	if s.Live != nil {
+		saved := dashboard
+		if saved == nil {
+			saved = dash
+		}
+		channel := s.Live.GrafanaScope.Dashboards
+		if liveerr := channel.DashboardSaved(c.OrgId, c.SignedInUser.ToUserDisplayDTO(), cmd.Message, saved, err); liveerr != nil {
+			logger.Warn("Unable to broadcast save event", "uid", saved.Uid, "error", liveerr)
+		}
+	}
+
+	if err != nil {
+		return nil, dashboardSaveError(err)
+	}
+
+	if provisioningData != nil {
+		if err := s.ProvisioningService.SyncDashboardUIUpdate(provisioningData, dashboard); err != nil {
+			logger.Error("Dashboard saved but failed to sync it with its provisioning file", "error", err)
+			return nil, status.Error(codes.Internal, "dashboard saved but failed to sync it with its provisioning file")
+		}
+	}
+
+	if s.Cfg.EditorsCanAdmin && cmd.Dashboard.Get("id").MustInt64() == 0 {
+		inFolder := cmd.FolderId > 0
+		if err := dashSvc.MakeUserAdmin(cmd.OrgId, cmd.UserId, dashboard.Id, !inFolder); err != nil {
+			logger.Error("Could not make user admin", "dashboard", dashboard.Title, "user", cmd.UserId, "error", err)
+		}
+	}
+
+	// connect library panels for this dashboard after the dashboard is stored and has an ID
+	if err := s.LibraryPanelService.ConnectLibraryPanelsForDashboard(c, dashboard); err != nil {
+		logger.Error("Error while connecting library panels", "error", err)
+		return nil, status.Error(codes.Internal, "error while connecting library panels")
+	}
+
+	return dashboardToProto(dashboard)
+}
+
+func (s *Service) DeleteDashboard(ctx context.Context, req *adminv1.DeleteDashboardRequest) (*adminv1.DeleteResponse, error) {
+	user, err := signedInUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	dash, err := getDashboard(req.Uid, user)
+	if err != nil {
+		return nil, err
+	}
+
+	guardian := guardian.New(dash.Id, user.OrgId, user)
+	if canSave, err := guardian.CanSave(); err != nil || !canSave {
+		return nil, dashboardGuardianError(err)
+	}
+
+	c := reqContext(ctx, user)
+	// disconnect all library elements for this dashboard
+	if err := s.LibraryElementService.DisconnectElementsFromDashboard(c, dash.Id); err != nil {
+		logger.Error("Failed to disconnect library elements", "dashboard", dash.Id, "error", err)
+	}
+
+	svc := dashboards.NewService(s.SQLStore)
+	if err := svc.DeleteDashboard(dash.Id, user.OrgId); err != nil {
+		if errors.Is(err, models.ErrDashboardCannotDeleteProvisionedDashboard) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		logger.Error("Failed to delete dashboard", "error", err)
+		return nil, status.Error(codes.Internal, "failed to delete dashboard")
+	}
+
+	if s.Live != nil {
+		if err := s.Live.GrafanaScope.Dashboards.DashboardDeleted(user.OrgId, user.ToUserDisplayDTO(), dash.Uid); err != nil {
+			logger.Error("Failed to broadcast delete info", "dashboard", dash.Uid, "error", err)
+		}
+	}
+
+	return &adminv1.DeleteResponse{Message: "Dashboard " + dash.Title + " deleted"}, nil
+}
+
+// getDashboard returns the dashboard of the UID, if the user can view it.
+func getDashboard(uid string, user *models.SignedInUser) (*models.Dashboard, error) {
+	if uid == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing dashboard uid")
+	}
+
+	query := models.GetDashboardQuery{Uid: uid, OrgId: user.OrgId}
+	if err := bus.Dispatch(&query); err != nil {
+		if errors.Is(err, models.ErrDashboardNotFound) {
+			return nil, status.Error(codes.NotFound, "dashboard not found")
+		}
+		logger.Error("Failed to get dashboard", "error", err)
+		return nil, status.Error(codes.Internal, "failed to get dashboard")
+	}
+
+	guardian := guardian.New(query.Result.Id, user.OrgId, user)
+	if canView, err := guardian.CanView(); err != nil || !canView {
+		return nil, dashboardGuardianError(err)
+	}
+	return query.Result, nil
+}
+
+func dashboardGuardianError(err error) error {
+	if err != nil {
+		logger.Error("Error while checking dashboard permissions", "error", err)
+		return status.Error(codes.Internal, "error while checking dashboard permissions")
+	}
+	return status.Error(codes.PermissionDenied, "access denied to this dashboard")
+}
+
+// dashboardSaveError returns the status of the errors of the dashboard
+// service, like the HTTP API responses.
+func dashboardSaveError(err error) error {
+	var dashboardErr models.DashboardErr
+	if ok := errors.As(err, &dashboardErr); ok {
+		return status.Error(httpStatusCode(dashboardErr.StatusCode), dashboardErr.Error())
+	}
+
+	if errors.Is(err, models.ErrFolderNotFound) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	var validationErr alerting.ValidationError
+	if ok := errors.As(err, &validationErr); ok {
+		return status.Error(codes.InvalidArgument, validationErr.Error())
+	}
+
+	var pluginErr models.UpdatePluginDashboardError
+	if ok := errors.As(err, &pluginErr); ok {
+		return status.Errorf(codes.FailedPrecondition, "the dashboard belongs to plugin %s", pluginErr.PluginId)
+	}
+
+	logger.Error("Failed to save dashboard", "error", err)
+	return status.Error(codes.Internal, "failed to save dashboard")
+}
+
+func dashboardToProto(dash *models.Dashboard) (*adminv1.Dashboard, error) {
+	b, err := dash.Data.Encode()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "invalid dashboard JSON: %s", err)
+	}
+
+	return &adminv1.Dashboard{
+		Id:       dash.Id,
+		Uid:      dash.Uid,
+		Title:    dash.Title,
+		Url:      dash.GetUrl(),
+		FolderId: dash.FolderId,
+		Tags:     dash.GetTags(),
+		Version:  int32(dash.Version),
+		Json:     string(b),
+	}, nil
+}
+
+// reqContext returns a request context of the signed in user, for the
+// services taking the context of an HTTP request.
+func reqContext(ctx context.Context, user *models.SignedInUser) *models.ReqContext {
+	return &models.ReqContext{
+		Context:      &macaron.Context{Req: macaron.Request{Request: (&http.Request{}).WithContext(ctx)}},
+		SignedInUser: user,
+		IsSignedIn:   true,
+		Logger:       logger,
+	}
+}
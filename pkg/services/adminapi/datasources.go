@@ -0,0 +1,253 @@
+package adminapi
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/api/datasource"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/adminapi/adminv1"
+)
+
+func (s *Service) ListDataSources(req *adminv1.ListDataSourcesRequest, stream adminv1.DataSourceService_ListDataSourcesServer) error {
+	user, err := requireOrgAdmin(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	query := models.GetDataSourcesQuery{OrgId: user.OrgId, DataSourceLimit: s.Cfg.DataSourceLimit}
+	if err := bus.Dispatch(&query); err != nil {
+		logger.Error("Failed to query datasources", "error", err)
+		return status.Error(codes.Internal, "failed to query datasources")
+	}
+
+	for _, ds := range query.Result {
+		msg, err := dataSourceToProto(ds)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) GetDataSource(ctx context.Context, req *adminv1.GetDataSourceRequest) (*adminv1.DataSource, error) {
+	user, err := requireOrgAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ds, err := getDataSource(req.Uid, user.OrgId)
+	if err != nil {
+		return nil, err
+	}
+	return dataSourceToProto(ds)
+}
+
+func (s *Service) CreateDataSource(ctx context.Context, req *adminv1.CreateDataSourceRequest) (*adminv1.DataSource, error) {
+	user, err := requireOrgAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name == "" || req.Type == "" || req.Access == "" {
+		return nil, status.Error(codes.InvalidArgument, "name, type and access are required")
+	}
+
+	jsonData, err := validateDataSource(req.Type, req.Url, req.JsonData)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := models.AddDataSourceCommand{
+		Uid:             req.Uid,
+		Name:            req.Name,
+		Type:            req.Type,
+		Access:          models.DsAccess(req.Access),
+		Url:             req.Url,
+		Database:        req.Database,
+		User:            req.User,
+		BasicAuth:       req.BasicAuth,
+		BasicAuthUser:   req.BasicAuthUser,
+		WithCredentials: req.WithCredentials,
+		IsDefault:       req.IsDefault,
+		JsonData:        jsonData,
+		SecureJsonData:  req.SecureJsonData,
+		OrgId:           user.OrgId,
+		UserId:          user.UserId,
+	}
+	if err := bus.Dispatch(&cmd); err != nil {
+		if errors.Is(err, models.ErrDataSourceNameExists) || errors.Is(err, models.ErrDataSourceUidExists) {
+			return nil, status.Error(codes.AlreadyExists, err.Error())
+		}
+		logger.Error("Failed to add datasource", "error", err)
+		return nil, status.Error(codes.Internal, "failed to add datasource")
+	}
+	return dataSourceToProto(cmd.Result)
+}
+
+func (s *Service) UpdateDataSource(ctx context.Context, req *adminv1.UpdateDataSourceRequest) (*adminv1.DataSource, error) {
+	user, err := requireOrgAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name == "" || req.Type == "" || req.Access == "" {
+		return nil, status.Error(codes.InvalidArgument, "name, type and access are required")
+	}
+
+	ds, err := getDataSource(req.Uid, user.OrgId)
+	if err != nil {
+		return nil, err
+	}
+	if ds.ReadOnly {
+		return nil, status.Error(codes.PermissionDenied, models.ErrDatasourceIsReadOnly.Error())
+	}
+
+	jsonData, err := validateDataSource(req.Type, req.Url, req.JsonData)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := models.UpdateDataSourceCommand{
+		Id:              ds.Id,
+		Uid:             ds.Uid,
+		Name:            req.Name,
+		Type:            req.Type,
+		Access:          models.DsAccess(req.Access),
+		Url:             req.Url,
+		Database:        req.Database,
+		User:            req.User,
+		BasicAuth:       req.BasicAuth,
+		BasicAuthUser:   req.BasicAuthUser,
+		WithCredentials: req.WithCredentials,
+		IsDefault:       req.IsDefault,
+		JsonData:        jsonData,
+		SecureJsonData:  req.SecureJsonData,
+		Version:         int(req.Version),
+		OrgId:           user.OrgId,
+		UserId:          user.UserId,
+	}
+	// the secure JSON data not in the request is kept, like in the HTTP API
+	if len(cmd.SecureJsonData) > 0 {
+		for k, v := range ds.SecureJsonData.Decrypt() {
+			if _, ok := cmd.SecureJsonData[k]; !ok {
+				cmd.SecureJsonData[k] = v
+			}
+		}
+	}
+
+	if err := bus.Dispatch(&cmd); err != nil {
+		if errors.Is(err, models.ErrDataSourceUpdatingOldVersion) {
+			return nil, status.Error(codes.Aborted, "datasource has already been updated by someone else")
+		}
+		logger.Error("Failed to update datasource", "error", err)
+		return nil, status.Error(codes.Internal, "failed to update datasource")
+	}
+
+	if s.Live != nil {
+		s.Live.HandleDatasourceUpdate(user.OrgId, cmd.Result.Uid)
+	}
+
+	return dataSourceToProto(cmd.Result)
+}
+
+func (s *Service) DeleteDataSource(ctx context.Context, req *adminv1.DeleteDataSourceRequest) (*adminv1.DeleteResponse, error) {
+	user, err := requireOrgAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ds, err := getDataSource(req.Uid, user.OrgId)
+	if err != nil {
+		return nil, err
+	}
+	if ds.ReadOnly {
+		return nil, status.Error(codes.PermissionDenied, "cannot delete read-only data source")
+	}
+
+	if err := bus.Dispatch(&models.DeleteDataSourceCommand{UID: ds.Uid, OrgID: user.OrgId}); err != nil {
+		logger.Error("Failed to delete datasource", "error", err)
+		return nil, status.Error(codes.Internal, "failed to delete datasource")
+	}
+
+	if s.Live != nil {
+		s.Live.HandleDatasourceDelete(user.OrgId, ds.Uid)
+	}
+
+	return &adminv1.DeleteResponse{Message: "Data source deleted"}, nil
+}
+
+func getDataSource(uid string, orgID int64) (*models.DataSource, error) {
+	if uid == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing datasource uid")
+	}
+
+	query := models.GetDataSourceQuery{Uid: uid, OrgId: orgID}
+	if err := bus.Dispatch(&query); err != nil {
+		if errors.Is(err, models.ErrDataSourceNotFound) {
+			return nil, status.Error(codes.NotFound, "data source not found")
+		}
+		logger.Error("Failed to query datasource", "error", err)
+		return nil, status.Error(codes.Internal, "failed to query datasource")
+	}
+	return query.Result, nil
+}
+
+// validateDataSource validates the URL and query examples of a data source
+// like the HTTP API, and returns its JSON data.
+func validateDataSource(tp string, url string, rawJSONData string) (*simplejson.Json, error) {
+	if url != "" {
+		if _, err := datasource.ValidateURL(tp, url); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid URL: %q", url)
+		}
+	}
+
+	if rawJSONData == "" {
+		return nil, nil
+	}
+	jsonData, err := simplejson.NewJson([]byte(rawJSONData))
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid JSON data: %s", err)
+	}
+	if _, err := models.QueryExamplesFromJSONData(jsonData); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if _, err := models.WarmUpQueriesFromJSONData(jsonData); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return jsonData, nil
+}
+
+func dataSourceToProto(ds *models.DataSource) (*adminv1.DataSource, error) {
+	msg := &adminv1.DataSource{
+		Id:              ds.Id,
+		Uid:             ds.Uid,
+		OrgId:           ds.OrgId,
+		Name:            ds.Name,
+		Type:            ds.Type,
+		Access:          string(ds.Access),
+		Url:             ds.Url,
+		Database:        ds.Database,
+		User:            ds.User,
+		BasicAuth:       ds.BasicAuth,
+		BasicAuthUser:   ds.BasicAuthUser,
+		WithCredentials: ds.WithCredentials,
+		IsDefault:       ds.IsDefault,
+		ReadOnly:        ds.ReadOnly,
+		Version:         int32(ds.Version),
+	}
+	if ds.JsonData != nil {
+		b, err := ds.JsonData.Encode()
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "invalid JSON data: %s", err)
+		}
+		msg.JsonData = string(b)
+	}
+	return msg, nil
+}
@@ -0,0 +1,121 @@
+package adminapi
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/adminapi/adminv1"
+)
+
+func (s *Service) ListOrgs(req *adminv1.ListOrgsRequest, stream adminv1.OrgService_ListOrgsServer) error {
+	if _, err := requireGrafanaAdmin(stream.Context()); err != nil {
+		return err
+	}
+
+	// the pages of the organizations search start at zero
+	limit := pageSize(req.PageSize)
+	for page := 0; ; page++ {
+		if err := stream.Context().Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+
+		query := models.SearchOrgsQuery{Query: req.Query, Page: page, Limit: limit}
+		if err := bus.Dispatch(&query); err != nil {
+			logger.Error("Failed to search orgs", "error", err)
+			return status.Error(codes.Internal, "failed to search orgs")
+		}
+
+		for _, org := range query.Result {
+			if err := stream.Send(&adminv1.Org{Id: org.Id, Name: org.Name}); err != nil {
+				return err
+			}
+		}
+		if len(query.Result) < limit {
+			return nil
+		}
+	}
+}
+
+func (s *Service) GetOrg(ctx context.Context, req *adminv1.GetOrgRequest) (*adminv1.Org, error) {
+	if _, err := requireGrafanaAdmin(ctx); err != nil {
+		return nil, err
+	}
+	return getOrg(req.Id)
+}
+
+func (s *Service) CreateOrg(ctx context.Context, req *adminv1.CreateOrgRequest) (*adminv1.Org, error) {
+	user, err := requireGrafanaAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+
+	cmd := models.CreateOrgCommand{Name: req.Name, UserId: user.UserId}
+	if err := bus.Dispatch(&cmd); err != nil {
+		if errors.Is(err, models.ErrOrgNameTaken) {
+			return nil, status.Error(codes.AlreadyExists, "organization name taken")
+		}
+		logger.Error("Failed to create organization", "error", err)
+		return nil, status.Error(codes.Internal, "failed to create organization")
+	}
+
+	metrics.MApiOrgCreate.Inc()
+
+	return &adminv1.Org{Id: cmd.Result.Id, Name: cmd.Result.Name}, nil
+}
+
+func (s *Service) UpdateOrg(ctx context.Context, req *adminv1.UpdateOrgRequest) (*adminv1.Org, error) {
+	if _, err := requireGrafanaAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+	if _, err := getOrg(req.Id); err != nil {
+		return nil, err
+	}
+
+	if err := bus.Dispatch(&models.UpdateOrgCommand{Name: req.Name, OrgId: req.Id}); err != nil {
+		if errors.Is(err, models.ErrOrgNameTaken) {
+			return nil, status.Error(codes.AlreadyExists, "organization name taken")
+		}
+		logger.Error("Failed to update organization", "error", err)
+		return nil, status.Error(codes.Internal, "failed to update organization")
+	}
+	return getOrg(req.Id)
+}
+
+func (s *Service) DeleteOrg(ctx context.Context, req *adminv1.DeleteOrgRequest) (*adminv1.DeleteResponse, error) {
+	if _, err := requireGrafanaAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := bus.Dispatch(&models.DeleteOrgCommand{Id: req.Id}); err != nil {
+		if errors.Is(err, models.ErrOrgNotFound) {
+			return nil, status.Error(codes.NotFound, models.ErrOrgNotFound.Error())
+		}
+		logger.Error("Failed to delete organization", "error", err)
+		return nil, status.Error(codes.Internal, "failed to delete organization")
+	}
+	return &adminv1.DeleteResponse{Message: "Organization deleted"}, nil
+}
+
+func getOrg(id int64) (*adminv1.Org, error) {
+	query := models.GetOrgByIdQuery{Id: id}
+	if err := bus.Dispatch(&query); err != nil {
+		if errors.Is(err, models.ErrOrgNotFound) {
+			return nil, status.Error(codes.NotFound, models.ErrOrgNotFound.Error())
+		}
+		logger.Error("Failed to get organization", "error", err)
+		return nil, status.Error(codes.Internal, "failed to get organization")
+	}
+	return &adminv1.Org{Id: query.Result.Id, Name: query.Result.Name}, nil
+}
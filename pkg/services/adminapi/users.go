@@ -0,0 +1,158 @@
+package adminapi
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/adminapi/adminv1"
+)
+
+func (s *Service) ListUsers(req *adminv1.ListUsersRequest, stream adminv1.UserService_ListUsersServer) error {
+	if _, err := requireGrafanaAdmin(stream.Context()); err != nil {
+		return err
+	}
+
+	limit := pageSize(req.PageSize)
+	for page := 1; ; page++ {
+		if err := stream.Context().Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+
+		query := models.SearchUsersQuery{Query: req.Query, Page: page, Limit: limit}
+		if err := bus.Dispatch(&query); err != nil {
+			logger.Error("Failed to search users", "error", err)
+			return status.Error(codes.Internal, "failed to search users")
+		}
+
+		for _, hit := range query.Result.Users {
+			if err := stream.Send(&adminv1.User{
+				Id:             hit.Id,
+				Login:          hit.Login,
+				Email:          hit.Email,
+				Name:           hit.Name,
+				IsGrafanaAdmin: hit.IsAdmin,
+				IsDisabled:     hit.IsDisabled,
+				LastSeenAt:     hit.LastSeenAt.Unix(),
+			}); err != nil {
+				return err
+			}
+		}
+		if len(query.Result.Users) < limit {
+			return nil
+		}
+	}
+}
+
+func (s *Service) GetUser(ctx context.Context, req *adminv1.GetUserRequest) (*adminv1.User, error) {
+	if _, err := requireGrafanaAdmin(ctx); err != nil {
+		return nil, err
+	}
+	return getUser(req.Id)
+}
+
+func (s *Service) CreateUser(ctx context.Context, req *adminv1.CreateUserRequest) (*adminv1.User, error) {
+	if _, err := requireGrafanaAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	cmd := models.CreateUserCommand{
+		Login:    req.Login,
+		Email:    req.Email,
+		Password: req.Password,
+		Name:     req.Name,
+		OrgId:    req.OrgId,
+	}
+	if len(cmd.Login) == 0 {
+		cmd.Login = cmd.Email
+		if len(cmd.Login) == 0 {
+			return nil, status.Error(codes.InvalidArgument, "need to specify either username or email")
+		}
+	}
+	if len(cmd.Password) < 4 {
+		return nil, status.Error(codes.InvalidArgument, "password is missing or too short")
+	}
+
+	user, err := s.Login.CreateUser(cmd)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrOrgNotFound):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, models.ErrUserAlreadyExists):
+			return nil, status.Errorf(codes.AlreadyExists, "user with email '%s' or username '%s' already exists", cmd.Email, cmd.Login)
+		}
+		logger.Error("Failed to create user", "error", err)
+		return nil, status.Error(codes.Internal, "failed to create user")
+	}
+
+	metrics.MApiAdminUserCreate.Inc()
+
+	return userToProto(user), nil
+}
+
+func (s *Service) UpdateUser(ctx context.Context, req *adminv1.UpdateUserRequest) (*adminv1.User, error) {
+	if _, err := requireGrafanaAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := getUser(req.Id); err != nil {
+		return nil, err
+	}
+
+	cmd := models.UpdateUserCommand{UserId: req.Id, Login: req.Login, Email: req.Email, Name: req.Name}
+	if len(cmd.Login) == 0 {
+		cmd.Login = cmd.Email
+		if len(cmd.Login) == 0 {
+			return nil, status.Error(codes.InvalidArgument, "need to specify either username or email")
+		}
+	}
+
+	if err := bus.Dispatch(&cmd); err != nil {
+		logger.Error("Failed to update user", "error", err)
+		return nil, status.Error(codes.Internal, "failed to update user")
+	}
+	return getUser(req.Id)
+}
+
+func (s *Service) DeleteUser(ctx context.Context, req *adminv1.DeleteUserRequest) (*adminv1.DeleteResponse, error) {
+	if _, err := requireGrafanaAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := bus.Dispatch(&models.DeleteUserCommand{UserId: req.Id}); err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			return nil, status.Error(codes.NotFound, models.ErrUserNotFound.Error())
+		}
+		logger.Error("Failed to delete user", "error", err)
+		return nil, status.Error(codes.Internal, "failed to delete user")
+	}
+	return &adminv1.DeleteResponse{Message: "User deleted"}, nil
+}
+
+func getUser(id int64) (*adminv1.User, error) {
+	query := models.GetUserByIdQuery{Id: id}
+	if err := bus.Dispatch(&query); err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			return nil, status.Error(codes.NotFound, models.ErrUserNotFound.Error())
+		}
+		logger.Error("Failed to get user", "error", err)
+		return nil, status.Error(codes.Internal, "failed to get user")
+	}
+	return userToProto(query.Result), nil
+}
+
+func userToProto(user *models.User) *adminv1.User {
+	return &adminv1.User{
+		Id:             user.Id,
+		Login:          user.Login,
+		Email:          user.Email,
+		Name:           user.Name,
+		IsGrafanaAdmin: user.IsAdmin,
+		IsDisabled:     user.IsDisabled,
+		LastSeenAt:     user.LastSeenAt.Unix(),
+	}
+}
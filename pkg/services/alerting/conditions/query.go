@@ -47,7 +47,14 @@ type AlertQuery struct {
 
 // Eval evaluates the `QueryCondition`.
 func (c *QueryCondition) Eval(context *alerting.EvalContext, requestHandler plugins.DataRequestHandler) (*alerting.ConditionResult, error) {
-	timeRange := plugins.NewDataTimeRange(c.Query.From, c.Query.To)
+	// The relative times are evaluated like for the users of the organization of the alert
+	timeRange, err := plugins.NewDataTimeRangeForUser(context.Ctx, c.Query.From, c.Query.To,
+		&models.SignedInUser{OrgId: context.Rule.OrgID})
+	if err != nil {
+		context.Logs = append(context.Logs, &alerting.ResultLogEntry{
+			Message: fmt.Sprintf("Condition[%d]: Failed to get the preferences of the organization: %v", c.Index, err),
+		})
+	}
 
 	seriesList, err := c.executeQuery(context, timeRange, requestHandler)
 	if err != nil {
@@ -8,11 +8,15 @@ import (
 
 	"github.com/benbjohnson/clock"
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/imguploader"
+	"github.com/grafana/grafana/pkg/infra/leaderelection"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/registry"
 	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/usageexport"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
@@ -20,6 +24,8 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
+const imageStorageValidationTimeout = 30 * time.Second
+
 // AlertEngine is the background process that
 // schedules alert evaluations and makes sure notifications
 // are sent.
@@ -29,6 +35,8 @@ type AlertEngine struct {
 	RequestValidator models.PluginRequestValidator `inject:""`
 	DataService      plugins.DataRequestHandler    `inject:""`
 	Cfg              *setting.Cfg                  `inject:""`
+	UsageExport      *usageexport.Service          `inject:""`
+	LeaderElection   *leaderelection.Service       `inject:""`
 
 	execQueue     chan *Job
 	ticker        *Ticker
@@ -57,7 +65,11 @@ func (e *AlertEngine) Init() error {
 	e.ruleReader = newRuleReader()
 	e.log = log.New("alerting.engine")
 	e.resultHandler = newResultHandler(e.RenderService)
-	return nil
+
+	// the images of the notifications are uploaded to the external image storage
+	ctx, cancel := context.WithTimeout(context.Background(), imageStorageValidationTimeout)
+	defer cancel()
+	return imguploader.ValidateImageUploader(ctx)
 }
 
 // Run starts the alerting service background process.
@@ -89,7 +101,11 @@ func (e *AlertEngine) alertingTicker(grafanaCtx context.Context) error {
 				e.scheduler.Update(e.ruleReader.fetch())
 			}
 
-			e.scheduler.Tick(tick, e.execQueue)
+			// the rules are updated on every server, so that a new leader
+			// runs the current rules right away
+			if e.LeaderElection.IsLeader() {
+				e.scheduler.Tick(tick, e.execQueue)
+			}
 			tickIndex++
 		}
 	}
@@ -103,6 +119,7 @@ func (e *AlertEngine) runJobDispatcher(grafanaCtx context.Context) error {
 		case <-grafanaCtx.Done():
 			return dispatcherGroup.Wait()
 		case job := <-e.execQueue:
+			metrics.MAlertingEvaluationLag.Set(time.Since(job.Scheduled).Seconds())
 			dispatcherGroup.Go(func() error { return e.processJobWithRetry(alertCtx, job) })
 		}
 	}
@@ -186,6 +203,7 @@ func (e *AlertEngine) processJob(attemptID int, attemptChan chan int, cancelChan
 		}()
 
 		e.evalHandler.Eval(evalContext)
+		e.UsageExport.RecordAlertEvaluation(evalContext.Rule.OrgID)
 
 		span.SetTag("alertId", evalContext.Rule.ID)
 		span.SetTag("dashboardId", evalContext.Rule.DashboardID)
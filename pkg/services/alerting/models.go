@@ -2,15 +2,18 @@ package alerting
 
 import (
 	"sync"
+	"time"
 
 	"github.com/grafana/grafana/pkg/components/null"
 )
 
 // Job holds state about when the alert rule should be evaluated.
 type Job struct {
-	Offset      int64
-	OffsetWait  bool
-	Delay       bool
+	Offset     int64
+	OffsetWait bool
+	Delay      bool
+	// Scheduled is the time of the tick the job was put on the exec queue
+	Scheduled   time.Time
 	running     bool
 	Rule        *Rule
 	runningLock sync.Mutex // Lock for running property which is used in the Scheduler and AlertEngine execution
@@ -58,7 +58,7 @@ func (s *schedulerImpl) Tick(tickTime time.Time, execQueue chan *Job) {
 
 		if job.OffsetWait && now%job.Offset == 0 {
 			job.OffsetWait = false
-			s.enqueue(job, execQueue)
+			s.enqueue(job, tickTime, execQueue)
 			continue
 		}
 
@@ -72,13 +72,14 @@ func (s *schedulerImpl) Tick(tickTime time.Time, execQueue chan *Job) {
 			if job.Offset > 0 {
 				job.OffsetWait = true
 			} else {
-				s.enqueue(job, execQueue)
+				s.enqueue(job, tickTime, execQueue)
 			}
 		}
 	}
 }
 
-func (s *schedulerImpl) enqueue(job *Job, execQueue chan *Job) {
+func (s *schedulerImpl) enqueue(job *Job, tickTime time.Time, execQueue chan *Job) {
 	s.log.Debug("Scheduler: Putting job on to exec queue", "name", job.Rule.Name, "id", job.Rule.ID)
+	job.Scheduled = tickTime
 	execQueue <- job
 }
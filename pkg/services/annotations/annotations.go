@@ -17,6 +17,8 @@ type Repository interface {
 	Update(item *Item) error
 	Find(query *ItemQuery) ([]*ItemDTO, error)
 	Delete(params *DeleteParams) error
+	FindTags(query *TagsQuery) (FindTagsResult, error)
+	FindAggregated(query *AggregateQuery) ([]*AggregatedItemDTO, error)
 }
 
 // AnnotationCleaner is responsible for cleaning up old annotations
@@ -40,6 +42,50 @@ type ItemQuery struct {
 	Limit int64 `json:"limit"`
 }
 
+// AggregateQuery is the query of the annotations counted by buckets of Interval milliseconds, the range of the
+// ItemQuery being required.
+type AggregateQuery struct {
+	ItemQuery
+	Interval int64
+}
+
+// AggregatedItemDTO is a bucket of annotations, with the tag which is on the most of them.
+type AggregatedItemDTO struct {
+	Time             int64  `json:"time"`
+	TimeEnd          int64  `json:"timeEnd"`
+	Count            int64  `json:"count"`
+	DominantTag      string `json:"dominantTag"`
+	DominantTagCount int64  `json:"dominantTagCount"`
+}
+
+// TagsQuery is the query of the tags of the annotations of an org. Tag is matched against the tags, tolerating
+// typos.
+type TagsQuery struct {
+	OrgID int64
+	Tag   string
+	Limit int64
+}
+
+// Tag is a tag of annotations, with the number of annotations it's on.
+type Tag struct {
+	Key   string
+	Value string
+	Count int64
+}
+
+type TagsDTO struct {
+	Tag   string `json:"tag"`
+	Count int64  `json:"count"`
+}
+
+type FindTagsResult struct {
+	Tags []*TagsDTO `json:"tags"`
+}
+
+type GetAnnotationTagsResponse struct {
+	Result FindTagsResult `json:"result"`
+}
+
 type DeleteParams struct {
 	OrgId       int64
 	Id          int64
@@ -0,0 +1,23 @@
+// Package audit records the changes of the resources of the orgs, in the audit log and the change events feed.
+package audit
+
+import (
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+var changesLogger = log.New("audit.changes")
+
+// RecordChange writes a change of a resource to the audit log and stores it as a change event. The resource is
+// already changed, so failing to store the event is only logged.
+func RecordChange(cmd *models.AddChangeEventCommand) {
+	changesLogger.Info("Resource changed", "org", cmd.OrgId, "resourceType", cmd.ResourceType, "resourceUid",
+		cmd.ResourceUid, "resourceName", cmd.ResourceName, "action", cmd.Action, "oldVersion", cmd.OldVersion,
+		"newVersion", cmd.NewVersion, "user", cmd.UserId, "impersonator", cmd.ImpersonatorId)
+
+	if err := bus.Dispatch(cmd); err != nil {
+		changesLogger.Error("Failed to store change event", "org", cmd.OrgId, "resourceType", cmd.ResourceType,
+			"resourceUid", cmd.ResourceUid, "error", err)
+	}
+}
@@ -32,6 +32,10 @@ var getTime = time.Now
 
 const urgentRotateTime = 1 * time.Minute
 
+// activityUpdateInterval is how often the activity of the tokens is recorded, and so the resolution of their inactive
+// lifetime.
+const activityUpdateInterval = 1 * time.Minute
+
 type UserAuthTokenService struct {
 	SQLStore          *sqlstore.SQLStore            `inject:""`
 	ServerLockService *serverlock.ServerLockService `inject:""`
@@ -49,7 +53,7 @@ func (s *UserAuthTokenService) ActiveTokenCount(ctx context.Context) (int64, err
 	var err error
 	err = s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
 		var model userAuthToken
-		count, err = dbSession.Where(`created_at > ? AND rotated_at > ? AND revoked_at = 0`,
+		count, err = dbSession.Where(`created_at > ? AND `+s.lastActivitySQL()+` > ? AND revoked_at = 0`,
 			s.createdAfterParam(),
 			s.rotatedAfterParam()).
 			Count(&model)
@@ -135,7 +139,7 @@ func (s *UserAuthTokenService) LookupToken(ctx context.Context, unhashedToken st
 		}
 	}
 
-	if model.CreatedAt <= s.createdAfterParam() || model.RotatedAt <= s.rotatedAfterParam() {
+	if model.CreatedAt <= s.createdAfterParam() || s.lastActivity(&model) <= s.rotatedAfterParam() {
 		return nil, &models.TokenExpiredError{
 			UserID:  model.UserId,
 			TokenID: model.Id,
@@ -199,6 +203,10 @@ func (s *UserAuthTokenService) LookupToken(ctx context.Context, unhashedToken st
 		}
 	}
 
+	if err := s.recordActivity(ctx, &model); err != nil {
+		return nil, err
+	}
+
 	model.UnhashedToken = unhashedToken
 
 	var userToken models.UserToken
@@ -207,6 +215,47 @@ func (s *UserAuthTokenService) LookupToken(ctx context.Context, unhashedToken st
 	return &userToken, err
 }
 
+// recordActivity records the use of the token, for its inactive lifetime to be extended. The activity is recorded
+// at most once by activityUpdateInterval, and the rotations of the token are activity as well.
+func (s *UserAuthTokenService) recordActivity(ctx context.Context, model *userAuthToken) error {
+	if !s.Cfg.LoginExtendSessionOnActivity {
+		return nil
+	}
+
+	now := getTime()
+	if time.Unix(s.lastActivity(model), 0).After(now.Add(-activityUpdateInterval)) {
+		return nil
+	}
+
+	return s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		if _, err := dbSession.Exec("UPDATE user_auth_token SET last_activity_at = ? WHERE id = ?", now.Unix(), model.Id); err != nil {
+			return err
+		}
+		model.LastActivityAt = now.Unix()
+		return nil
+	})
+}
+
+// lastActivity returns when the inactive lifetime of the token started: its last use or rotation, or its creation
+// when the activity doesn't extend the sessions.
+func (s *UserAuthTokenService) lastActivity(model *userAuthToken) int64 {
+	if !s.Cfg.LoginExtendSessionOnActivity {
+		return model.CreatedAt
+	}
+	if model.LastActivityAt > model.RotatedAt {
+		return model.LastActivityAt
+	}
+	return model.RotatedAt
+}
+
+// lastActivitySQL is the SQL expression of lastActivity.
+func (s *UserAuthTokenService) lastActivitySQL() string {
+	if !s.Cfg.LoginExtendSessionOnActivity {
+		return "created_at"
+	}
+	return "(CASE WHEN COALESCE(last_activity_at, 0) > rotated_at THEN last_activity_at ELSE rotated_at END)"
+}
+
 func (s *UserAuthTokenService) TryRotateToken(ctx context.Context, token *models.UserToken,
 	clientIP net.IP, userAgent string) (bool, error) {
 	if token == nil {
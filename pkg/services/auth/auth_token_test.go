@@ -237,6 +237,99 @@ func TestUserAuthToken(t *testing.T) {
 			})
 		})
 
+		Convey("activity extends the session", func() {
+			userToken, err := userAuthTokenService.CreateToken(context.Background(), user,
+				net.ParseIP("192.168.10.11"), "some user agent")
+			So(err, ShouldBeNil)
+
+			getTime = func() time.Time {
+				return t.Add(24 * 6 * time.Hour)
+			}
+
+			stillGood, err := userAuthTokenService.LookupToken(context.Background(), userToken.UnhashedToken)
+			So(err, ShouldBeNil)
+			So(stillGood, ShouldNotBeNil)
+
+			model, err := ctx.getAuthTokenByID(userToken.Id)
+			So(err, ShouldBeNil)
+			So(model.LastActivityAt, ShouldEqual, t.Add(24*6*time.Hour).Unix())
+
+			Convey("when last activity is 6:23:59:59 ago should find token", func() {
+				getTime = func() time.Time {
+					return time.Unix(model.LastActivityAt, 0).Add(24 * 7 * time.Hour).Add(-time.Second)
+				}
+
+				stillGood, err = userAuthTokenService.LookupToken(context.Background(), userToken.UnhashedToken)
+				So(err, ShouldBeNil)
+				So(stillGood, ShouldNotBeNil)
+
+				count, err := userAuthTokenService.ActiveTokenCount(context.Background())
+				So(err, ShouldBeNil)
+				So(count, ShouldEqual, 1)
+			})
+
+			Convey("when last activity is 7:00:00 ago should return token expired error", func() {
+				getTime = func() time.Time {
+					return time.Unix(model.LastActivityAt, 0).Add(24 * 7 * time.Hour)
+				}
+
+				notGood, err := userAuthTokenService.LookupToken(context.Background(), userToken.UnhashedToken)
+				So(err, ShouldHaveSameTypeAs, &models.TokenExpiredError{})
+				So(notGood, ShouldBeNil)
+			})
+
+			Convey("should not record activity more than once a minute", func() {
+				getTime = func() time.Time {
+					return t.Add(24 * 6 * time.Hour).Add(30 * time.Second)
+				}
+
+				_, err = userAuthTokenService.LookupToken(context.Background(), userToken.UnhashedToken)
+				So(err, ShouldBeNil)
+
+				model, err := ctx.getAuthTokenByID(userToken.Id)
+				So(err, ShouldBeNil)
+				So(model.LastActivityAt, ShouldEqual, t.Add(24*6*time.Hour).Unix())
+			})
+
+			Convey("when created_at is 30 days ago should return token expired error", func() {
+				getTime = func() time.Time {
+					return t.Add(24 * 30 * time.Hour)
+				}
+
+				notGood, err := userAuthTokenService.LookupToken(context.Background(), userToken.UnhashedToken)
+				So(err, ShouldHaveSameTypeAs, &models.TokenExpiredError{})
+				So(notGood, ShouldBeNil)
+			})
+		})
+
+		Convey("activity doesn't extend the session when disabled", func() {
+			userAuthTokenService.Cfg.LoginExtendSessionOnActivity = false
+
+			userToken, err := userAuthTokenService.CreateToken(context.Background(), user,
+				net.ParseIP("192.168.10.11"), "some user agent")
+			So(err, ShouldBeNil)
+
+			getTime = func() time.Time {
+				return t.Add(24 * 6 * time.Hour)
+			}
+
+			stillGood, err := userAuthTokenService.LookupToken(context.Background(), userToken.UnhashedToken)
+			So(err, ShouldBeNil)
+			So(stillGood, ShouldNotBeNil)
+
+			model, err := ctx.getAuthTokenByID(userToken.Id)
+			So(err, ShouldBeNil)
+			So(model.LastActivityAt, ShouldEqual, 0)
+
+			getTime = func() time.Time {
+				return t.Add(24 * 7 * time.Hour)
+			}
+
+			notGood, err := userAuthTokenService.LookupToken(context.Background(), userToken.UnhashedToken)
+			So(err, ShouldHaveSameTypeAs, &models.TokenExpiredError{})
+			So(notGood, ShouldBeNil)
+		})
+
 		Convey("can properly rotate tokens", func() {
 			userToken, err := userAuthTokenService.CreateToken(context.Background(), user,
 				net.ParseIP("192.168.10.11"), "some user agent")
@@ -531,6 +624,7 @@ func createTestContext(t *testing.T) *testContext {
 			LoginMaxInactiveLifetime:     maxInactiveDurationVal,
 			LoginMaxLifetime:             maxLifetimeDurationVal,
 			TokenRotationIntervalMinutes: 10,
+			LoginExtendSessionOnActivity: true,
 		},
 		log: log.New("test-logger"),
 	}
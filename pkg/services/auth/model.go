@@ -19,7 +19,9 @@ type userAuthToken struct {
 	CreatedAt     int64
 	UpdatedAt     int64
 	RevokedAt     int64
-	UnhashedToken string `xorm:"-"`
+	// LastActivityAt is when the token was last used, it's zero until then
+	LastActivityAt int64
+	UnhashedToken  string `xorm:"-"`
 }
 
 func userAuthTokenFromUserToken(ut *models.UserToken) (*userAuthToken, error) {
@@ -45,6 +47,7 @@ func (uat *userAuthToken) fromUserToken(ut *models.UserToken) error {
 	uat.CreatedAt = ut.CreatedAt
 	uat.UpdatedAt = ut.UpdatedAt
 	uat.RevokedAt = ut.RevokedAt
+	uat.LastActivityAt = ut.LastActivityAt
 	uat.UnhashedToken = ut.UnhashedToken
 
 	return nil
@@ -67,6 +70,7 @@ func (uat *userAuthToken) toUserToken(ut *models.UserToken) error {
 	ut.CreatedAt = uat.CreatedAt
 	ut.UpdatedAt = uat.UpdatedAt
 	ut.RevokedAt = uat.RevokedAt
+	ut.LastActivityAt = uat.LastActivityAt
 	ut.UnhashedToken = uat.UnhashedToken
 
 	return nil
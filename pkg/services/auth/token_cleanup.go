@@ -41,14 +41,14 @@ func (s *UserAuthTokenService) Run(ctx context.Context) error {
 
 func (s *UserAuthTokenService) deleteExpiredTokens(ctx context.Context, maxInactiveLifetime, maxLifetime time.Duration) (int64, error) {
 	createdBefore := getTime().Add(-maxLifetime)
-	rotatedBefore := getTime().Add(-maxInactiveLifetime)
+	inactiveBefore := getTime().Add(-maxInactiveLifetime)
 
-	s.log.Debug("starting cleanup of expired auth tokens", "createdBefore", createdBefore, "rotatedBefore", rotatedBefore)
+	s.log.Debug("starting cleanup of expired auth tokens", "createdBefore", createdBefore, "inactiveBefore", inactiveBefore)
 
 	var affected int64
 	err := s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
-		sql := `DELETE from user_auth_token WHERE created_at <= ? OR rotated_at <= ?`
-		res, err := dbSession.Exec(sql, createdBefore.Unix(), rotatedBefore.Unix())
+		sql := `DELETE from user_auth_token WHERE created_at <= ? OR ` + s.lastActivitySQL() + ` <= ?`
+		res, err := dbSession.Exec(sql, createdBefore.Unix(), inactiveBefore.Unix())
 		if err != nil {
 			return err
 		}
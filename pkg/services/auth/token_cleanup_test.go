@@ -66,5 +66,26 @@ func TestUserAuthTokenCleanup(t *testing.T) {
 			So(err, ShouldBeNil)
 			So(affected, ShouldEqual, 3)
 		})
+
+		Convey("should not delete tokens with activity in the last 7 days", func() {
+			from := t.Add(-168 * time.Hour)
+
+			// insert three old tokens that should be deleted
+			for i := 0; i < 3; i++ {
+				insertToken(fmt.Sprintf("oldA%d", i), fmt.Sprintf("oldB%d", i), from.Unix(), from.Unix())
+			}
+
+			// insert three tokens with recent activity that should not be deleted
+			for i := 0; i < 3; i++ {
+				insertToken(fmt.Sprintf("newA%d", i), fmt.Sprintf("newB%d", i), from.Unix(), from.Unix())
+				_, err := ctx.sqlstore.NewSession().Exec("UPDATE user_auth_token SET last_activity_at = ? WHERE auth_token = ?",
+					t.Add(-time.Hour).Unix(), fmt.Sprintf("newA%d", i))
+				So(err, ShouldBeNil)
+			}
+
+			affected, err := ctx.tokenService.deleteExpiredTokens(context.Background(), 7*24*time.Hour, 30*24*time.Hour)
+			So(err, ShouldBeNil)
+			So(affected, ShouldEqual, 3)
+		})
 	})
 }
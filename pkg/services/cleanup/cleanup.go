@@ -10,6 +10,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/shorturls"
 
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/leaderelection"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/serverlock"
 	"github.com/grafana/grafana/pkg/models"
@@ -23,6 +24,7 @@ type CleanUpService struct {
 	Cfg               *setting.Cfg                  `inject:""`
 	ServerLockService *serverlock.ServerLockService `inject:""`
 	ShortURLService   *shorturls.ShortURLService    `inject:""`
+	LeaderElection    *leaderelection.Service       `inject:""`
 }
 
 func init() {
@@ -45,11 +47,19 @@ func (srv *CleanUpService) Run(ctx context.Context) error {
 			defer cancelFn()
 
 			srv.cleanUpTmpFiles()
+			// the temp files are local to every server, the database is
+			// cleaned up by the leader
+			if !srv.LeaderElection.IsLeader() {
+				continue
+			}
 			srv.deleteExpiredSnapshots()
 			srv.deleteExpiredDashboardVersions()
+			srv.compressDashboardVersions()
 			srv.cleanUpOldAnnotations(ctxWithTimeout)
 			srv.expireOldUserInvites()
 			srv.deleteStaleShortURLs()
+			srv.deleteExpiredDataSourceSecrets()
+			srv.deleteOldLoginHistory()
 			err := srv.ServerLockService.LockAndExecute(ctx, "delete old login attempts",
 				time.Minute*10, func() {
 					srv.deleteOldLoginAttempts()
@@ -141,6 +151,15 @@ func (srv *CleanUpService) deleteExpiredDashboardVersions() {
 	}
 }
 
+func (srv *CleanUpService) compressDashboardVersions() {
+	cmd := models.CompressDashboardVersionsCommand{}
+	if err := bus.Dispatch(&cmd); err != nil {
+		srv.log.Error("Failed to compress dashboard versions", "error", err.Error())
+	} else {
+		srv.log.Debug("Compressed dashboard versions", "rows affected", cmd.CompressedRows)
+	}
+}
+
 func (srv *CleanUpService) deleteOldLoginAttempts() {
 	if srv.Cfg.DisableBruteForceLoginProtection {
 		return
@@ -156,6 +175,17 @@ func (srv *CleanUpService) deleteOldLoginAttempts() {
 	}
 }
 
+func (srv *CleanUpService) deleteOldLoginHistory() {
+	cmd := models.DeleteOldLoginHistoryCommand{
+		OlderThan: time.Now().Add(-srv.Cfg.LoginHistory.MaxAge),
+	}
+	if err := bus.Dispatch(&cmd); err != nil {
+		srv.log.Error("Problem deleting old login history", "error", err.Error())
+	} else {
+		srv.log.Debug("Deleted old login history", "rows affected", cmd.DeletedRows)
+	}
+}
+
 func (srv *CleanUpService) expireOldUserInvites() {
 	maxInviteLifetime := srv.Cfg.UserInviteMaxLifetime
 
@@ -179,3 +209,12 @@ func (srv *CleanUpService) deleteStaleShortURLs() {
 		srv.log.Debug("Deleted short urls", "rows affected", cmd.NumDeleted)
 	}
 }
+
+func (srv *CleanUpService) deleteExpiredDataSourceSecrets() {
+	cmd := models.DeleteExpiredDataSourceSecretsCommand{}
+	if err := bus.Dispatch(&cmd); err != nil {
+		srv.log.Error("Failed to delete expired data source secrets", "error", err.Error())
+	} else {
+		srv.log.Debug("Deleted expired data source secrets", "rows affected", cmd.DeletedRows)
+	}
+}
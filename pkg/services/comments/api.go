@@ -0,0 +1,87 @@
+package comments
+
+import (
+	"errors"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func (s *CommentService) registerAPIEndpoints() {
+	s.RouteRegister.Group("/api/comments", func(comments routing.RouteRegister) {
+		comments.Post("/", middleware.ReqSignedIn, routing.Bind(CreateCommentCommand{}), routing.Wrap(s.createHandler))
+		comments.Get("/", middleware.ReqSignedIn, routing.Wrap(s.getAllHandler))
+		comments.Patch("/:uid", middleware.ReqSignedIn, routing.Bind(updateCommentCommand{}), routing.Wrap(s.updateHandler))
+		comments.Delete("/:uid", middleware.ReqSignedIn, routing.Wrap(s.deleteHandler))
+	})
+}
+
+// createHandler handles POST /api/comments.
+func (s *CommentService) createHandler(c *models.ReqContext, cmd CreateCommentCommand) response.Response {
+	comment, err := s.createComment(c.Req.Context(), c.SignedInUser, cmd)
+	if err != nil {
+		return toCommentError(err, "Failed to create comment")
+	}
+
+	return response.JSON(200, util.DynMap{"result": comment})
+}
+
+// getAllHandler handles GET /api/comments.
+func (s *CommentService) getAllHandler(c *models.ReqContext) response.Response {
+	if c.Query("dashboardUid") == "" {
+		return response.Error(400, "dashboardUid is required", nil)
+	}
+
+	query := searchCommentsQuery{
+		dashboardUID: c.Query("dashboardUid"),
+		panelID:      c.QueryInt64("panelId"),
+		perPage:      c.QueryInt("perPage"),
+		page:         c.QueryInt("page"),
+	}
+
+	result, err := s.getAllComments(c.Req.Context(), c.SignedInUser, query)
+	if err != nil {
+		return toCommentError(err, "Failed to get comments")
+	}
+
+	return response.JSON(200, util.DynMap{"result": result})
+}
+
+// updateHandler handles PATCH /api/comments/:uid.
+func (s *CommentService) updateHandler(c *models.ReqContext, cmd updateCommentCommand) response.Response {
+	comment, err := s.updateComment(c.Req.Context(), c.SignedInUser, c.Params(":uid"), cmd)
+	if err != nil {
+		return toCommentError(err, "Failed to update comment")
+	}
+
+	return response.JSON(200, util.DynMap{"result": comment})
+}
+
+// deleteHandler handles DELETE /api/comments/:uid.
+func (s *CommentService) deleteHandler(c *models.ReqContext) response.Response {
+	if err := s.deleteComment(c.Req.Context(), c.SignedInUser, c.Params(":uid")); err != nil {
+		return toCommentError(err, "Failed to delete comment")
+	}
+
+	return response.Success("Comment deleted")
+}
+
+func toCommentError(err error, message string) response.Response {
+	switch {
+	case errors.Is(err, errCommentPanelNotFound), errors.Is(err, errCommentInvalidTimeRange),
+		errors.Is(err, errCommentEmpty), errors.Is(err, errCommentTooLong):
+		return response.Error(400, err.Error(), err)
+	case errors.Is(err, errCommentNotFound):
+		return response.Error(404, errCommentNotFound.Error(), err)
+	case errors.Is(err, models.ErrDashboardNotFound):
+		return response.Error(404, models.ErrDashboardNotFound.Error(), err)
+	case errors.Is(err, errCommentAccessDenied):
+		return response.Error(403, errCommentAccessDenied.Error(), err)
+	case errors.Is(err, errCommentDashboardAccessDenied):
+		return response.Error(403, errCommentDashboardAccessDenied.Error(), err)
+	}
+	return response.Error(500, message, err)
+}
@@ -0,0 +1,37 @@
+package comments
+
+import (
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/live"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// CommentService is the service for the comments feature, discussion threads
+// on dashboards and panels for collaborating on incidents. The users
+// mentioned in comments are notified by email, and the new comments are
+// broadcast to the dashboard with Grafana Live.
+type CommentService struct {
+	Cfg           *setting.Cfg          `inject:""`
+	SQLStore      *sqlstore.SQLStore    `inject:""`
+	RouteRegister routing.RouteRegister `inject:""`
+	Bus           bus.Bus               `inject:""`
+	Live          *live.GrafanaLive     `inject:""`
+	log           log.Logger
+}
+
+func init() {
+	registry.RegisterService(&CommentService{})
+}
+
+// Init initializes the Comment service
+func (s *CommentService) Init() error {
+	s.log = log.New("comments")
+
+	s.registerAPIEndpoints()
+
+	return nil
+}
@@ -0,0 +1,225 @@
+package comments
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/macaron.v1"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type commentResult struct {
+	Result CommentDTO `json:"result"`
+}
+
+type scenarioContext struct {
+	service    *CommentService
+	reqContext *models.ReqContext
+	user       models.SignedInUser
+	sqlStore   *sqlstore.SQLStore
+	dashboard  *models.Dashboard
+	// emails are the emails sent, by recipient
+	emails map[string]models.SendEmailCommand
+}
+
+func TestComments(t *testing.T) {
+	testScenario(t, "Comments can be created, replied to, updated and deleted", func(t *testing.T, sc scenarioContext) {
+		resp := sc.service.createHandler(sc.reqContext, CreateCommentCommand{
+			DashboardUID: sc.dashboard.Uid,
+			PanelID:      2,
+			TimeFrom:     1000,
+			TimeTo:       2000,
+			Content:      "  Latency spike  ",
+		})
+		created := unmarshalResponse(t, resp)
+		require.Equal(t, "Latency spike", created.Content)
+		require.Equal(t, sc.user.Login, created.User.Login)
+
+		reply, err := sc.service.createComment(context.Background(), &sc.user, CreateCommentCommand{
+			DashboardUID: sc.dashboard.Uid,
+			ParentUID:    created.UID,
+			Content:      "Deploy rolled back",
+		})
+		require.NoError(t, err)
+		require.Equal(t, created.UID, reply.ParentUID)
+		require.Equal(t, int64(2), reply.PanelID)
+		require.Equal(t, int64(1000), reply.TimeFrom)
+
+		// the replies to replies are in the same thread
+		nested, err := sc.service.createComment(context.Background(), &sc.user, CreateCommentCommand{
+			DashboardUID: sc.dashboard.Uid,
+			ParentUID:    reply.UID,
+			Content:      "Thanks",
+		})
+		require.NoError(t, err)
+		require.Equal(t, created.UID, nested.ParentUID)
+
+		_, err = sc.service.createComment(context.Background(), &sc.user, CreateCommentCommand{
+			DashboardUID: sc.dashboard.Uid,
+			Content:      "Dashboard comment",
+		})
+		require.NoError(t, err)
+
+		result, err := sc.service.getAllComments(context.Background(), &sc.user, searchCommentsQuery{
+			dashboardUID: sc.dashboard.Uid,
+			panelID:      2,
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(3), result.TotalCount)
+		require.Equal(t, created.UID, result.Comments[0].UID)
+		require.Equal(t, created.UID, result.Comments[1].ParentUID)
+
+		updated, err := sc.service.updateComment(context.Background(), &sc.user, created.UID, updateCommentCommand{Content: "Latency spike on the API"})
+		require.NoError(t, err)
+		require.Equal(t, "Latency spike on the API", updated.Content)
+
+		require.NoError(t, sc.service.deleteComment(context.Background(), &sc.user, created.UID))
+		result, err = sc.service.getAllComments(context.Background(), &sc.user, searchCommentsQuery{dashboardUID: sc.dashboard.Uid})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), result.TotalCount)
+		require.Equal(t, "Dashboard comment", result.Comments[0].Content)
+	})
+
+	testScenario(t, "Comments are validated", func(t *testing.T, sc scenarioContext) {
+		for name, cmd := range map[string]CreateCommentCommand{
+			"Empty":      {DashboardUID: sc.dashboard.Uid, Content: "   "},
+			"Panel":      {DashboardUID: sc.dashboard.Uid, PanelID: 42, Content: "Missing panel"},
+			"Time range": {DashboardUID: sc.dashboard.Uid, TimeFrom: 2000, TimeTo: 1000, Content: "Backwards"},
+		} {
+			resp := sc.service.createHandler(sc.reqContext, cmd)
+			require.Equal(t, 400, resp.Status(), name)
+		}
+
+		resp := sc.service.createHandler(sc.reqContext, CreateCommentCommand{DashboardUID: "missing", Content: "Hello"})
+		require.Equal(t, 404, resp.Status())
+	})
+
+	testScenario(t, "Only the author of a comment can update it", func(t *testing.T, sc scenarioContext) {
+		created, err := sc.service.createComment(context.Background(), &sc.user, CreateCommentCommand{
+			DashboardUID: sc.dashboard.Uid,
+			Content:      "Mine",
+		})
+		require.NoError(t, err)
+
+		other := sc.user
+		other.UserId = 2
+		_, err = sc.service.updateComment(context.Background(), &other, created.UID, updateCommentCommand{Content: "Yours"})
+		require.ErrorIs(t, err, errCommentAccessDenied)
+
+		// the dashboard admins can delete it
+		require.NoError(t, sc.service.deleteComment(context.Background(), &other, created.UID))
+	})
+
+	testScenario(t, "Mentioned users are notified", func(t *testing.T, sc scenarioContext) {
+		_, err := sc.sqlStore.CreateUser(context.Background(), models.CreateUserCommand{
+			Login: "oncall",
+			Email: "oncall@example.com",
+			OrgId: sc.user.OrgId,
+		})
+		require.NoError(t, err)
+
+		created, err := sc.service.createComment(context.Background(), &sc.user, CreateCommentCommand{
+			DashboardUID: sc.dashboard.Uid,
+			PanelID:      2,
+			Content:      "@oncall can you check this? cc @signed_in_user @nobody",
+		})
+		require.NoError(t, err)
+
+		require.Len(t, sc.emails, 1)
+		email := sc.emails["oncall@example.com"]
+		require.Equal(t, "comment_mention.html", email.Template)
+		require.Equal(t, "Dashboard", email.Data["DashboardTitle"])
+		require.Contains(t, email.Data["LinkUrl"], "viewPanel=2")
+
+		// the users are only notified once
+		delete(sc.emails, "oncall@example.com")
+		_, err = sc.service.updateComment(context.Background(), &sc.user, created.UID, updateCommentCommand{Content: "@oncall any news?"})
+		require.NoError(t, err)
+		require.Empty(t, sc.emails)
+	})
+}
+
+func TestMentions(t *testing.T) {
+	require.Equal(t, []string{"alice", "bob.smith", "carol@example.com"},
+		mentions("@alice and @bob.smith. Also @carol@example.com, @alice again but not email@example.com"))
+	require.Empty(t, mentions("no mentions"))
+}
+
+func unmarshalResponse(t *testing.T, resp interface {
+	Status() int
+	Body() []byte
+}) CommentDTO {
+	t.Helper()
+
+	require.Equal(t, 200, resp.Status(), string(resp.Body()))
+	var result commentResult
+	require.NoError(t, json.Unmarshal(resp.Body(), &result))
+	return result.Result
+}
+
+// testScenario is a wrapper around t.Run performing common setup for comment tests.
+func testScenario(t *testing.T, desc string, fn func(t *testing.T, sc scenarioContext)) {
+	t.Helper()
+
+	t.Run(desc, func(t *testing.T) {
+		ctx := macaron.Context{
+			Req: macaron.Request{Request: &http.Request{}},
+		}
+		sqlStore := sqlstore.InitTestDB(t)
+		service := CommentService{
+			Cfg:      setting.NewCfg(),
+			SQLStore: sqlStore,
+			Bus:      bus.GetBus(),
+			log:      log.New("comments"),
+		}
+
+		emails := map[string]models.SendEmailCommand{}
+		bus.AddHandler("test", func(cmd *models.SendEmailCommand) error {
+			emails[cmd.To[0]] = *cmd
+			return nil
+		})
+
+		user := models.SignedInUser{
+			UserId:     1,
+			Login:      "signed_in_user",
+			OrgId:      1,
+			OrgRole:    models.ROLE_ADMIN,
+			LastSeenAt: time.Now(),
+		}
+
+		dashboard, err := sqlStore.SaveDashboard(models.SaveDashboardCommand{
+			OrgId: user.OrgId,
+			Dashboard: simplejson.NewFromAny(map[string]interface{}{
+				"title": "Dashboard",
+				"panels": []interface{}{
+					map[string]interface{}{"id": 1, "type": "row", "panels": []interface{}{
+						map[string]interface{}{"id": 2, "type": "graph"},
+					}},
+				},
+			}),
+		})
+		require.NoError(t, err)
+
+		fn(t, scenarioContext{
+			service:  &service,
+			user:     user,
+			sqlStore: sqlStore,
+			reqContext: &models.ReqContext{
+				Context:      &ctx,
+				SignedInUser: &user,
+			},
+			dashboard: dashboard,
+			emails:    emails,
+		})
+	})
+}
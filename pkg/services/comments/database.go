@@ -0,0 +1,327 @@
+package comments
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	defaultPerPage = 100
+	maxPerPage     = 1000
+)
+
+// commentWithAuthor is a comment with the author and the UID of the comment
+// it replies to.
+type commentWithAuthor struct {
+	Comment   `xorm:"extends"`
+	ParentUID string `xorm:"parent_uid"`
+	Login     string
+	Name      string
+	Email     string
+}
+
+func toCommentDTO(comment commentWithAuthor, dashboardUID string) CommentDTO {
+	return CommentDTO{
+		UID:          comment.UID,
+		DashboardUID: dashboardUID,
+		PanelID:      comment.PanelID,
+		ParentUID:    comment.ParentUID,
+		TimeFrom:     comment.TimeFrom,
+		TimeTo:       comment.TimeTo,
+		Content:      comment.Content,
+		User: &models.UserDisplayDTO{
+			Id:        comment.UserID,
+			Login:     comment.Login,
+			Name:      comment.Name,
+			AvatarUrl: dtos.GetGravatarUrl(comment.Email),
+		},
+		Created: comment.Created,
+		Updated: comment.Updated,
+	}
+}
+
+func (s *CommentService) selectComments() string {
+	return `SELECT c.*, p.uid AS parent_uid, u.login, u.name, u.email
+		FROM comment AS c
+		LEFT JOIN comment AS p ON p.id = c.parent_id
+		LEFT JOIN ` + s.SQLStore.Dialect.Quote("user") + ` AS u ON u.id = c.user_id `
+}
+
+func (s *CommentService) getComment(session *sqlstore.DBSession, orgID int64, uid string) (commentWithAuthor, error) {
+	var comments []commentWithAuthor
+	err := session.SQL(s.selectComments()+"WHERE c.org_id = ? AND c.uid = ?", orgID, uid).Find(&comments)
+	if err != nil {
+		return commentWithAuthor{}, err
+	}
+	if len(comments) == 0 {
+		return commentWithAuthor{}, errCommentNotFound
+	}
+	return comments[0], nil
+}
+
+// getDashboard returns the dashboard with the UID, or the ID when the UID is
+// empty, after checking that the user can view it.
+func (s *CommentService) getDashboard(user *models.SignedInUser, uid string, id int64) (*models.Dashboard, guardian.DashboardGuardian, error) {
+	query := models.GetDashboardQuery{Uid: uid, Id: id, OrgId: user.OrgId}
+	if err := s.Bus.Dispatch(&query); err != nil {
+		return nil, nil, err
+	}
+
+	g := guardian.New(query.Result.Id, user.OrgId, user)
+	canView, err := g.CanView()
+	if err != nil {
+		return nil, nil, err
+	}
+	if !canView {
+		return nil, nil, errCommentDashboardAccessDenied
+	}
+	return query.Result, g, nil
+}
+
+func validateContent(content string) (string, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return "", errCommentEmpty
+	}
+	if len(content) > maxContentLength {
+		return "", errCommentTooLong
+	}
+	return content, nil
+}
+
+// hasPanel returns whether the dashboard has the panel, including the panels
+// of the collapsed rows and of the legacy rows.
+func hasPanel(dashboard *simplejson.Json, panelID int64) bool {
+	var containsPanel func(panels *simplejson.Json) bool
+	containsPanel = func(panels *simplejson.Json) bool {
+		for _, p := range panels.MustArray() {
+			panel := simplejson.NewFromAny(p)
+			if panel.Get("id").MustInt64() == panelID || containsPanel(panel.Get("panels")) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if containsPanel(dashboard.Get("panels")) {
+		return true
+	}
+	for _, row := range dashboard.Get("rows").MustArray() {
+		if containsPanel(simplejson.NewFromAny(row).Get("panels")) {
+			return true
+		}
+	}
+	return false
+}
+
+// createComment adds a comment.
+func (s *CommentService) createComment(ctx context.Context, user *models.SignedInUser, cmd CreateCommentCommand) (CommentDTO, error) {
+	content, err := validateContent(cmd.Content)
+	if err != nil {
+		return CommentDTO{}, err
+	}
+
+	dash, _, err := s.getDashboard(user, cmd.DashboardUID, 0)
+	if err != nil {
+		return CommentDTO{}, err
+	}
+
+	comment := commentWithAuthor{
+		Comment: Comment{
+			OrgID:       user.OrgId,
+			UID:         util.GenerateShortUID(),
+			DashboardID: dash.Id,
+			PanelID:     cmd.PanelID,
+			TimeFrom:    cmd.TimeFrom,
+			TimeTo:      cmd.TimeTo,
+			Content:     content,
+			UserID:      user.UserId,
+			Created:     time.Now(),
+			Updated:     time.Now(),
+		},
+		Login: user.Login,
+		Name:  user.Name,
+		Email: user.Email,
+	}
+
+	err = s.SQLStore.WithTransactionalDbSession(ctx, func(session *sqlstore.DBSession) error {
+		if cmd.ParentUID != "" {
+			parent, err := s.getComment(session, user.OrgId, cmd.ParentUID)
+			if err != nil {
+				return err
+			}
+			if parent.DashboardID != dash.Id {
+				return errCommentNotFound
+			}
+
+			// the replies to replies are in the thread of the first comment
+			comment.ParentID, comment.ParentUID = parent.ID, parent.UID
+			if parent.ParentID != 0 {
+				comment.ParentID, comment.ParentUID = parent.ParentID, parent.ParentUID
+			}
+			comment.PanelID, comment.TimeFrom, comment.TimeTo = parent.PanelID, parent.TimeFrom, parent.TimeTo
+		} else {
+			if comment.PanelID != 0 && !hasPanel(dash.Data, comment.PanelID) {
+				return errCommentPanelNotFound
+			}
+			if comment.TimeFrom < 0 || comment.TimeTo < comment.TimeFrom || (comment.TimeFrom == 0) != (comment.TimeTo == 0) {
+				return errCommentInvalidTimeRange
+			}
+		}
+
+		_, err := session.Insert(&comment.Comment)
+		return err
+	})
+	if err != nil {
+		return CommentDTO{}, err
+	}
+
+	dto := toCommentDTO(comment, dash.Uid)
+	s.publish(user.OrgId, commentEvent{Action: commentCreated, UID: dto.UID, Comment: &dto}, dash.Uid)
+	s.notifyMentions(ctx, user, dash, dto, mentions(content))
+	return dto, nil
+}
+
+// getAllComments returns the comments of a dashboard, in the order they were
+// added.
+func (s *CommentService) getAllComments(ctx context.Context, user *models.SignedInUser, query searchCommentsQuery) (CommentSearchResult, error) {
+	dash, _, err := s.getDashboard(user, query.dashboardUID, 0)
+	if err != nil {
+		return CommentSearchResult{}, err
+	}
+
+	if query.perPage <= 0 {
+		query.perPage = defaultPerPage
+	} else if query.perPage > maxPerPage {
+		query.perPage = maxPerPage
+	}
+	if query.page <= 0 {
+		query.page = 1
+	}
+
+	result := CommentSearchResult{Comments: []CommentDTO{}, Page: query.page, PerPage: query.perPage}
+	err = s.SQLStore.WithDbSession(ctx, func(session *sqlstore.DBSession) error {
+		where := "c.org_id = ? AND c.dashboard_id = ?"
+		params := []interface{}{user.OrgId, dash.Id}
+		if query.panelID != 0 {
+			where += " AND c.panel_id = ?"
+			params = append(params, query.panelID)
+		}
+
+		count, err := session.Table("comment").Alias("c").Where(where, params...).Count()
+		if err != nil {
+			return err
+		}
+		result.TotalCount = count
+
+		var comments []commentWithAuthor
+		sql := s.selectComments() + "WHERE " + where + " ORDER BY c.created ASC, c.id ASC " +
+			s.SQLStore.Dialect.LimitOffset(int64(query.perPage), int64((query.page-1)*query.perPage))
+		if err := session.SQL(sql, params...).Find(&comments); err != nil {
+			return err
+		}
+		for _, comment := range comments {
+			result.Comments = append(result.Comments, toCommentDTO(comment, dash.Uid))
+		}
+		return nil
+	})
+	if err != nil {
+		return CommentSearchResult{}, err
+	}
+
+	return result, nil
+}
+
+// updateComment edits the content of a comment, only its author can.
+func (s *CommentService) updateComment(ctx context.Context, user *models.SignedInUser, uid string, cmd updateCommentCommand) (CommentDTO, error) {
+	content, err := validateContent(cmd.Content)
+	if err != nil {
+		return CommentDTO{}, err
+	}
+
+	var comment commentWithAuthor
+	var previousContent string
+	var dash *models.Dashboard
+	err = s.SQLStore.WithTransactionalDbSession(ctx, func(session *sqlstore.DBSession) error {
+		var err error
+		comment, err = s.getComment(session, user.OrgId, uid)
+		if err != nil {
+			return err
+		}
+		if comment.UserID != user.UserId {
+			return errCommentAccessDenied
+		}
+		if dash, _, err = s.getDashboard(user, "", comment.DashboardID); err != nil {
+			return err
+		}
+
+		previousContent = comment.Content
+		comment.Content = content
+		comment.Updated = time.Now()
+		_, err = session.ID(comment.ID).Cols("content", "updated").Update(&comment.Comment)
+		return err
+	})
+	if err != nil {
+		return CommentDTO{}, err
+	}
+
+	dto := toCommentDTO(comment, dash.Uid)
+	s.publish(user.OrgId, commentEvent{Action: commentUpdated, UID: dto.UID, Comment: &dto}, dash.Uid)
+
+	// only notify the users who weren't mentioned already
+	previous := map[string]bool{}
+	for _, login := range mentions(previousContent) {
+		previous[login] = true
+	}
+	var added []string
+	for _, login := range mentions(content) {
+		if !previous[login] {
+			added = append(added, login)
+		}
+	}
+	s.notifyMentions(ctx, user, dash, dto, added)
+	return dto, nil
+}
+
+// deleteComment deletes a comment and its replies, its author and the admins
+// of its dashboard can.
+func (s *CommentService) deleteComment(ctx context.Context, user *models.SignedInUser, uid string) error {
+	var dash *models.Dashboard
+	err := s.SQLStore.WithTransactionalDbSession(ctx, func(session *sqlstore.DBSession) error {
+		comment, err := s.getComment(session, user.OrgId, uid)
+		if err != nil {
+			return err
+		}
+
+		var g guardian.DashboardGuardian
+		if dash, g, err = s.getDashboard(user, "", comment.DashboardID); err != nil {
+			return err
+		}
+		if comment.UserID != user.UserId {
+			canAdmin, err := g.CanAdmin()
+			if err != nil {
+				return err
+			}
+			if !canAdmin {
+				return errCommentAccessDenied
+			}
+		}
+
+		_, err = session.Exec("DELETE FROM comment WHERE org_id = ? AND (id = ? OR parent_id = ?)", user.OrgId, comment.ID, comment.ID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.publish(user.OrgId, commentEvent{Action: commentDeleted, UID: uid}, dash.Uid)
+	return nil
+}
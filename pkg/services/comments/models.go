@@ -0,0 +1,98 @@
+package comments
+
+import (
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// Comment is the model for the comments of dashboards. A comment is attached
+// to the dashboard, or to one of its panels and optionally a time range, and
+// starts a thread unless it replies to another comment.
+type Comment struct {
+	ID          int64  `xorm:"pk autoincr 'id'"`
+	OrgID       int64  `xorm:"org_id"`
+	UID         string `xorm:"uid"`
+	DashboardID int64  `xorm:"dashboard_id"`
+	PanelID     int64  `xorm:"panel_id"`
+	ParentID    int64  `xorm:"parent_id"`
+	// TimeFrom and TimeTo are the time range of the comment in epoch
+	// milliseconds, zero when the comment isn't about a time range
+	TimeFrom int64
+	TimeTo   int64
+	Content  string
+	UserID   int64 `xorm:"user_id"`
+
+	Created time.Time
+	Updated time.Time
+}
+
+// CommentDTO is the frontend DTO for comments.
+type CommentDTO struct {
+	UID          string                 `json:"uid"`
+	DashboardUID string                 `json:"dashboardUid"`
+	PanelID      int64                  `json:"panelId"`
+	ParentUID    string                 `json:"parentUid,omitempty"`
+	TimeFrom     int64                  `json:"timeFrom,omitempty"`
+	TimeTo       int64                  `json:"timeTo,omitempty"`
+	Content      string                 `json:"content"`
+	User         *models.UserDisplayDTO `json:"user"`
+	Created      time.Time              `json:"created"`
+	Updated      time.Time              `json:"updated"`
+}
+
+// CommentSearchResult is the search result for comments.
+type CommentSearchResult struct {
+	TotalCount int64        `json:"totalCount"`
+	Comments   []CommentDTO `json:"comments"`
+	Page       int          `json:"page"`
+	PerPage    int          `json:"perPage"`
+}
+
+var (
+	// errCommentNotFound is an error for when a comment can't be found.
+	errCommentNotFound = errors.New("comment could not be found")
+	// errCommentAccessDenied is an error for when the user can't change a comment.
+	errCommentAccessDenied = errors.New("only the author of a comment can change it")
+	// errCommentDashboardAccessDenied is an error for when the user can't view the dashboard of a comment.
+	errCommentDashboardAccessDenied = errors.New("access denied to the dashboard of the comment")
+	// errCommentPanelNotFound is an error for when the panel of a comment isn't in its dashboard.
+	errCommentPanelNotFound = errors.New("the panel of the comment could not be found in the dashboard")
+	// errCommentInvalidTimeRange is an error for when the time range of a comment is invalid.
+	errCommentInvalidTimeRange = errors.New("the time range of the comment is invalid")
+	// errCommentEmpty is an error for when the content of a comment is empty.
+	errCommentEmpty = errors.New("the comment is empty")
+	// errCommentTooLong is an error for when the content of a comment is too long.
+	errCommentTooLong = errors.New("the comment is too long")
+)
+
+// maxContentLength is the maximum length of the content of a comment in bytes.
+const maxContentLength = 10000
+
+// Commands
+
+// CreateCommentCommand is the command for adding a Comment. The replies are
+// attached to the panel and time range of the comment they reply to.
+type CreateCommentCommand struct {
+	DashboardUID string `json:"dashboardUid" binding:"Required"`
+	PanelID      int64  `json:"panelId"`
+	ParentUID    string `json:"parentUid"`
+	TimeFrom     int64  `json:"timeFrom"`
+	TimeTo       int64  `json:"timeTo"`
+	Content      string `json:"content" binding:"Required"`
+}
+
+// updateCommentCommand is the command for editing the content of a Comment.
+type updateCommentCommand struct {
+	Content string `json:"content" binding:"Required"`
+}
+
+// searchCommentsQuery is the query used for getting the comments of a
+// dashboard, of all its panels unless panelID is set.
+type searchCommentsQuery struct {
+	dashboardUID string
+	panelID      int64
+	perPage      int
+	page         int
+}
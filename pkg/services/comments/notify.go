@@ -0,0 +1,131 @@
+package comments
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// maxMentions is the maximum number of users notified for a comment.
+const maxMentions = 10
+
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([\w.@+-]+)`)
+
+type commentAction string
+
+const (
+	commentCreated commentAction = "created"
+	commentUpdated commentAction = "updated"
+	commentDeleted commentAction = "deleted"
+)
+
+// commentEvent is the event broadcast to the grafana/comment/uid/<dashboard
+// uid> channel when the comments of a dashboard change.
+type commentEvent struct {
+	Action  commentAction `json:"action"`
+	UID     string        `json:"uid"`
+	Comment *CommentDTO   `json:"comment,omitempty"`
+}
+
+// publish broadcasts the event to the users viewing the dashboard.
+func (s *CommentService) publish(orgID int64, event commentEvent, dashboardUID string) {
+	if s.Live == nil {
+		return
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		s.log.Error("Failed to marshal comment event", "error", err)
+		return
+	}
+	if err := s.Live.Publish(orgID, "grafana/comment/uid/"+dashboardUID, data); err != nil {
+		s.log.Warn("Failed to publish comment event", "uid", event.UID, "error", err)
+	}
+}
+
+// mentions returns the logins mentioned with @login in the content, without
+// duplicates.
+func mentions(content string) []string {
+	var logins []string
+	seen := map[string]bool{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(content, -1) {
+		// the punctuation ending a sentence isn't part of the login
+		login := strings.TrimRight(match[1], ".-")
+		if login == "" || seen[login] {
+			continue
+		}
+		seen[login] = true
+		logins = append(logins, login)
+		if len(logins) == maxMentions {
+			break
+		}
+	}
+	return logins
+}
+
+// notifyMentions emails the mentioned users who can view the dashboard. The
+// comment is saved already, so the failures are only logged.
+func (s *CommentService) notifyMentions(ctx context.Context, author *models.SignedInUser, dash *models.Dashboard, comment CommentDTO, logins []string) {
+	for _, login := range logins {
+		if login == author.Login {
+			continue
+		}
+
+		userQuery := models.GetUserByLoginQuery{LoginOrEmail: login}
+		if err := s.Bus.Dispatch(&userQuery); err != nil {
+			if !errors.Is(err, models.ErrUserNotFound) {
+				s.log.Warn("Failed to get mentioned user", "login", login, "error", err)
+			}
+			continue
+		}
+		if userQuery.Result.Login != login || userQuery.Result.Email == "" {
+			continue
+		}
+
+		query := models.GetSignedInUserQuery{UserId: userQuery.Result.Id, OrgId: author.OrgId}
+		if err := s.SQLStore.GetSignedInUserWithCacheCtx(ctx, &query); err != nil {
+			s.log.Warn("Failed to get mentioned user", "login", login, "error", err)
+			continue
+		}
+
+		// the users who aren't members of the organization have no organization
+		user := query.Result
+		if user.OrgId != author.OrgId {
+			continue
+		}
+		canView, err := guardian.New(dash.Id, author.OrgId, user).CanView()
+		if err != nil || !canView {
+			continue
+		}
+
+		link := fmt.Sprintf("%s?orgId=%d", dash.GetUrl(), author.OrgId)
+		if comment.PanelID != 0 {
+			link += fmt.Sprintf("&viewPanel=%d", comment.PanelID)
+		}
+		if comment.TimeFrom != 0 {
+			link += fmt.Sprintf("&from=%d&to=%d", comment.TimeFrom, comment.TimeTo)
+		}
+
+		cmd := models.SendEmailCommand{
+			To:       []string{user.Email},
+			Template: "comment_mention.html",
+			Data: map[string]interface{}{
+				"Name":           user.NameOrFallback(),
+				"MentionedBy":    author.NameOrFallback(),
+				"DashboardTitle": dash.Title,
+				"Content":        comment.Content,
+				"LinkUrl":        setting.ToAbsUrl(strings.TrimPrefix(link, setting.AppSubUrl+"/")),
+			},
+		}
+		if err := s.Bus.Dispatch(&cmd); err != nil && !errors.Is(err, models.ErrSmtpNotEnabled) {
+			s.log.Warn("Failed to send comment mention email", "login", login, "error", err)
+		}
+	}
+}
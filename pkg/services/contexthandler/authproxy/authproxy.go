@@ -45,7 +45,7 @@ var isLDAPEnabled = func(cfg *setting.Cfg) bool {
 var newLDAP = multildap.New
 
 // supportedHeaders states the supported headers configuration fields
-var supportedHeaderFields = []string{"Name", "Email", "Login", "Groups"}
+var supportedHeaderFields = []string{"Name", "Email", "Login", "Groups", "Role", "Teams"}
 
 // AuthProxy struct
 type AuthProxy struct {
@@ -54,6 +54,7 @@ type AuthProxy struct {
 	ctx         *models.ReqContext
 	orgID       int64
 	header      string
+	log         log.Logger
 }
 
 // Error auth proxy specific error
@@ -91,6 +92,7 @@ func New(cfg *setting.Cfg, options *Options) *AuthProxy {
 		ctx:         options.Ctx,
 		orgID:       options.OrgID,
 		header:      header,
+		log:         log.New("auth.proxy"),
 	}
 }
 
@@ -113,27 +115,17 @@ func (auth *AuthProxy) IsAllowedIP() error {
 		return nil
 	}
 
-	proxies := strings.Split(auth.cfg.AuthProxyWhitelist, ",")
-	var proxyObjs []*net.IPNet
-	for _, proxy := range proxies {
-		result, err := coerceProxyAddress(proxy)
-		if err != nil {
-			return newError("could not get the network", err)
-		}
-
-		proxyObjs = append(proxyObjs, result)
-	}
-
 	sourceIP, _, err := net.SplitHostPort(ip)
 	if err != nil {
 		return newError("could not parse address", err)
 	}
-	sourceObj := net.ParseIP(sourceIP)
 
-	for _, proxyObj := range proxyObjs {
-		if proxyObj.Contains(sourceObj) {
-			return nil
-		}
+	allowed, err := isInNetworks(net.ParseIP(sourceIP), strings.Split(auth.cfg.AuthProxyWhitelist, ","))
+	if err != nil {
+		return newError("could not get the network", err)
+	}
+	if allowed {
+		return nil
 	}
 
 	return newError("proxy authentication required", fmt.Errorf(
@@ -142,6 +134,43 @@ func (auth *AuthProxy) IsAllowedIP() error {
 	))
 }
 
+// isAllowedHeader returns whether the additional header of a field is allowed from the address of the request, when
+// the field has a whitelist of its own
+func (auth *AuthProxy) isAllowedHeader(field string) bool {
+	networks := auth.cfg.AuthProxyHeaderWhitelists[field]
+	if len(networks) == 0 {
+		return true
+	}
+
+	sourceIP, _, err := net.SplitHostPort(auth.ctx.Req.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	allowed, err := isInNetworks(net.ParseIP(sourceIP), networks)
+	if err != nil {
+		auth.log.Warn("Could not get the network of the header whitelist", "field", field, "error", err)
+		return false
+	}
+	if !allowed {
+		auth.log.Warn("Ignoring auth proxy header not allowed from this address", "field", field, "address", sourceIP)
+	}
+	return allowed
+}
+
+// isInNetworks returns whether an IP is in any of the networks, in CIDR notation
+func isInNetworks(ip net.IP, networks []string) (bool, error) {
+	for _, network := range networks {
+		result, err := coerceProxyAddress(network)
+		if err != nil {
+			return false, err
+		}
+		if result.Contains(ip) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func HashCacheKey(key string) (string, error) {
 	hasher := fnv.New128a()
 	if _, err := hasher.Write([]byte(key)); err != nil {
@@ -277,10 +306,24 @@ func (auth *AuthProxy) LoginViaHeader() (int64, error) {
 		return 0, fmt.Errorf("auth proxy header property invalid")
 	}
 
+	var teams []string
+	syncTeams := false
 	auth.headersIterator(func(field string, header string) {
-		if field == "Groups" {
+		switch field {
+		case "Groups":
 			extUser.Groups = util.SplitString(header)
-		} else {
+		case "Teams":
+			teams = util.SplitString(header)
+			syncTeams = true
+		case "Role":
+			// the role is the role of the user in the org the users are assigned to
+			role, ok := parseRole(header)
+			if !ok {
+				auth.log.Warn("Ignoring invalid role of auth proxy header", "role", header)
+				return
+			}
+			extUser.OrgRoles = map[int64]models.RoleType{auth.syncOrgID(): role}
+		default:
 			reflect.ValueOf(extUser).Elem().FieldByName(field).SetString(header)
 		}
 	})
@@ -296,9 +339,33 @@ func (auth *AuthProxy) LoginViaHeader() (int64, error) {
 		return 0, err
 	}
 
+	if syncTeams {
+		if err := auth.syncTeamMemberships(upsert.Result.Id, auth.syncOrgID(), teams); err != nil {
+			return 0, err
+		}
+	}
+
 	return upsert.Result.Id, nil
 }
 
+// syncOrgID returns the org of the role and the teams of the headers, the org the users are assigned to
+func (auth *AuthProxy) syncOrgID() int64 {
+	if auth.cfg.AutoAssignOrg && auth.cfg.AutoAssignOrgId > 0 {
+		return int64(auth.cfg.AutoAssignOrgId)
+	}
+	return 1
+}
+
+// parseRole returns the org role of the value of a header, case insensitive
+func parseRole(value string) (models.RoleType, bool) {
+	for _, role := range []models.RoleType{models.ROLE_VIEWER, models.ROLE_EDITOR, models.ROLE_ADMIN} {
+		if strings.EqualFold(value, string(role)) {
+			return role, true
+		}
+	}
+	return "", false
+}
+
 // headersIterator iterates over all non-empty supported additional headers
 func (auth *AuthProxy) headersIterator(fn func(field string, header string)) {
 	for _, field := range supportedHeaderFields {
@@ -307,7 +374,7 @@ func (auth *AuthProxy) headersIterator(fn func(field string, header string)) {
 			continue
 		}
 
-		if value := auth.ctx.Req.Header.Get(h); value != "" {
+		if value := auth.ctx.Req.Header.Get(h); value != "" && auth.isAllowedHeader(field) {
 			fn(field, strings.TrimSpace(value))
 		}
 	}
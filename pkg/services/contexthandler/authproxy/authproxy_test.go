@@ -221,3 +221,106 @@ func TestMiddlewareContext_ldap(t *testing.T) {
 		assert.False(t, stub.loginCalled)
 	})
 }
+
+func TestMiddlewareContext_headers(t *testing.T) {
+	const id int64 = 42
+
+	var upserted *models.ExternalUserInfo
+	bus.AddHandler("test", func(cmd *models.UpsertUserCommand) error {
+		upserted = cmd.ExternalUser
+		cmd.Result = &models.User{Id: id}
+		return nil
+	})
+
+	teams := map[string]*models.TeamDTO{
+		"editors": {Id: 1, OrgId: 1, Name: "editors"},
+		"admins":  {Id: 2, OrgId: 1, Name: "admins"},
+		"viewers": {Id: 3, OrgId: 1, Name: "viewers"},
+	}
+	var members []*models.TeamMemberDTO
+	bus.AddHandler("test", func(query *models.GetTeamMembersQuery) error {
+		query.Result = nil
+		for _, m := range members {
+			if !query.External || m.External {
+				query.Result = append(query.Result, m)
+			}
+		}
+		return nil
+	})
+	bus.AddHandler("test", func(query *models.GetTeamsByUserQuery) error {
+		query.Result = nil
+		for _, m := range members {
+			for _, team := range teams {
+				if team.Id == m.TeamId {
+					query.Result = append(query.Result, team)
+				}
+			}
+		}
+		return nil
+	})
+	bus.AddHandler("test", func(query *models.SearchTeamsQuery) error {
+		query.Result = models.SearchTeamQueryResult{}
+		if team, ok := teams[query.Name]; ok {
+			query.Result.Teams = []*models.TeamDTO{team}
+		}
+		return nil
+	})
+	bus.AddHandler("test", func(cmd *models.AddTeamMemberCommand) error {
+		members = append(members, &models.TeamMemberDTO{OrgId: cmd.OrgId, TeamId: cmd.TeamId, UserId: cmd.UserId, External: cmd.External})
+		return nil
+	})
+	bus.AddHandler("test", func(cmd *models.RemoveTeamMemberCommand) error {
+		for i, m := range members {
+			if m.TeamId == cmd.TeamId {
+				members = append(members[:i], members[i+1:]...)
+				return nil
+			}
+		}
+		return models.ErrTeamMemberNotFound
+	})
+
+	cache := remotecache.NewFakeStore(t)
+
+	t.Run("Syncs the role and the teams of the headers", func(t *testing.T) {
+		members = []*models.TeamMemberDTO{
+			{OrgId: 1, TeamId: 2, UserId: id, External: true},
+			{OrgId: 1, TeamId: 3, UserId: id},
+		}
+
+		auth := prepareMiddleware(t, cache, func(req *http.Request, cfg *setting.Cfg) {
+			cfg.AuthProxyHeaderProperty = "username"
+			req.Header.Set("X-WEBAUTH-ROLE", "editor")
+			req.Header.Set("X-WEBAUTH-TEAMS", "editors, unknown")
+			cfg.AuthProxyHeaders = map[string]string{"Role": "X-WEBAUTH-ROLE", "Teams": "X-WEBAUTH-TEAMS"}
+		})
+
+		gotID, err := auth.LoginViaHeader()
+		require.NoError(t, err)
+		assert.Equal(t, id, gotID)
+		assert.Equal(t, map[int64]models.RoleType{1: models.ROLE_EDITOR}, upserted.OrgRoles)
+
+		var teamIDs []int64
+		for _, m := range members {
+			teamIDs = append(teamIDs, m.TeamId)
+		}
+		assert.ElementsMatch(t, []int64{1, 3}, teamIDs)
+	})
+
+	t.Run("Ignores the headers not allowed from the address of the request", func(t *testing.T) {
+		members = nil
+
+		auth := prepareMiddleware(t, cache, func(req *http.Request, cfg *setting.Cfg) {
+			cfg.AuthProxyHeaderProperty = "username"
+			req.RemoteAddr = "192.168.1.10:4567"
+			req.Header.Set("X-WEBAUTH-ROLE", "Admin")
+			req.Header.Set("X-WEBAUTH-NAME", "Markelog")
+			cfg.AuthProxyHeaders = map[string]string{"Role": "X-WEBAUTH-ROLE", "Name": "X-WEBAUTH-NAME"}
+			cfg.AuthProxyHeaderWhitelists = map[string][]string{"Role": {"10.0.0.0/8"}}
+		})
+
+		_, err := auth.LoginViaHeader()
+		require.NoError(t, err)
+		assert.Empty(t, upserted.OrgRoles)
+		assert.Equal(t, "Markelog", upserted.Name)
+	})
+}
@@ -0,0 +1,211 @@
+package authproxy
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+// The modes of the signature of the headers of the auth proxy
+const (
+	// SignatureModeHMAC expects the signature header "t=<unix time>,v1=<hex HMAC-SHA256>", the HMAC of the time and
+	// the signed headers with the shared secret, see signaturePayload
+	SignatureModeHMAC = "hmac"
+	// SignatureModeJWT expects a JWT in the signature header, signed with the shared secret (HS256) or the private
+	// key of the public key file, with an iat claim and the values of the signed headers in a headers claim, by
+	// lower case name
+	SignatureModeJWT = "jwt"
+)
+
+// signatureLeeway is the clock skew allowed between the auth proxy and Grafana
+const signatureLeeway = 30 * time.Second
+
+// now returns the current time, it can be stubbed by tests
+var now = time.Now
+
+// signatureKeys holds the public keys of the JWT signatures, by file
+var signatureKeys sync.Map
+
+// jwtClaims are the claims of the JWT signature of the headers
+type jwtClaims struct {
+	jwt.Claims
+	Headers map[string]string `json:"headers"`
+}
+
+// VerifySignature verifies the signature of the headers of the request, when the headers are signed. The auth header
+// and all the additional headers are signed, an absent header is signed as an empty value so that it can't be
+// stripped.
+func (auth *AuthProxy) VerifySignature() error {
+	if auth.cfg.AuthProxySignatureMode == "" {
+		return nil
+	}
+
+	signature := auth.ctx.Req.Header.Get(auth.cfg.AuthProxySignatureHeader)
+	if signature == "" {
+		return newError("proxy authentication required", fmt.Errorf(
+			"request for user (%s) has no signature header %s", auth.header, auth.cfg.AuthProxySignatureHeader))
+	}
+
+	var err error
+	switch auth.cfg.AuthProxySignatureMode {
+	case SignatureModeHMAC:
+		err = auth.verifyHMAC(signature)
+	case SignatureModeJWT:
+		err = auth.verifyJWT(signature)
+	default:
+		err = fmt.Errorf("unknown signature mode %q", auth.cfg.AuthProxySignatureMode)
+	}
+	if err != nil {
+		return newError("proxy authentication required", fmt.Errorf(
+			"invalid signature of the headers of the request for user (%s): %w", auth.header, err))
+	}
+	return nil
+}
+
+// signedHeaders returns the lower case names of the signed headers with their value: the auth header, then the
+// additional headers in the order of their field
+func (auth *AuthProxy) signedHeaders() [][2]string {
+	headers := [][2]string{{strings.ToLower(auth.cfg.AuthProxyHeaderName), auth.header}}
+	for _, field := range supportedHeaderFields {
+		if h := auth.cfg.AuthProxyHeaders[field]; h != "" {
+			headers = append(headers, [2]string{strings.ToLower(h), strings.TrimSpace(auth.ctx.Req.Header.Get(h))})
+		}
+	}
+	return headers
+}
+
+// signaturePayload returns the payload of the HMAC signature: the time, then a line "<lower case name>:<value>"
+// per signed header
+func (auth *AuthProxy) signaturePayload(timestamp string) string {
+	lines := []string{timestamp}
+	for _, header := range auth.signedHeaders() {
+		lines = append(lines, header[0]+":"+header[1])
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (auth *AuthProxy) verifyHMAC(signature string) error {
+	var timestamp, mac string
+	for _, part := range strings.Split(signature, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "t":
+			timestamp = kv[1]
+		case "v1":
+			mac = kv[1]
+		}
+	}
+	if timestamp == "" || mac == "" {
+		return errors.New("expected t=<unix time>,v1=<signature>")
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid time %q", timestamp)
+	}
+	if err := auth.checkSignatureAge(time.Unix(unix, 0)); err != nil {
+		return err
+	}
+
+	expected := hmac.New(sha256.New, []byte(auth.cfg.AuthProxySignatureSecret))
+	expected.Write([]byte(auth.signaturePayload(timestamp)))
+	actual, err := hex.DecodeString(mac)
+	if err != nil || !hmac.Equal(actual, expected.Sum(nil)) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
+
+func (auth *AuthProxy) verifyJWT(signature string) error {
+	token, err := jwt.ParseSigned(signature)
+	if err != nil {
+		return err
+	}
+
+	var key interface{} = []byte(auth.cfg.AuthProxySignatureSecret)
+	if keyFile := auth.cfg.AuthProxySignatureKeyFile; keyFile != "" {
+		if key, err = loadSignatureKey(keyFile); err != nil {
+			return err
+		}
+	}
+	var claims jwtClaims
+	if err := token.Claims(key, &claims); err != nil {
+		return err
+	}
+
+	if claims.IssuedAt == nil {
+		return errors.New("missing iat claim")
+	}
+	if err := claims.ValidateWithLeeway(jwt.Expected{Time: now()}, signatureLeeway); err != nil {
+		return err
+	}
+	if err := auth.checkSignatureAge(claims.IssuedAt.Time()); err != nil {
+		return err
+	}
+
+	for _, header := range auth.signedHeaders() {
+		if claims.Headers[header[0]] != header[1] {
+			return fmt.Errorf("header %s mismatch", header[0])
+		}
+	}
+	return nil
+}
+
+// checkSignatureAge checks that a signature isn't older than the max age, so that a signed request can't be replayed
+// later
+func (auth *AuthProxy) checkSignatureAge(signed time.Time) error {
+	age := now().Sub(signed)
+	if age > auth.cfg.AuthProxySignatureMaxAge || age < -signatureLeeway {
+		return fmt.Errorf("signature time %s is outside the max age %s", signed.UTC().Format(time.RFC3339),
+			auth.cfg.AuthProxySignatureMaxAge)
+	}
+	return nil
+}
+
+// loadSignatureKey returns the public key of a PEM file, cached
+func loadSignatureKey(path string) (interface{}, error) {
+	if key, ok := signatureKeys.Load(path); ok {
+		return key, nil
+	}
+
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because `path` comes from grafana configuration file
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("failed to parse pem-encoded file %s", path)
+	}
+
+	var key interface{}
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unknown pem block type %q, expected a public key", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	signatureKeys.Store(path, key)
+	return key, nil
+}
@@ -0,0 +1,149 @@
+package authproxy
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+func TestVerifySignature(t *testing.T) {
+	origNow := now
+	t.Cleanup(func() { now = origNow })
+	current := time.Unix(1622548800, 0)
+	now = func() time.Time { return current }
+
+	cache := remotecache.NewFakeStore(t)
+	const secret = "proxy-secret"
+	newAuth := func(t *testing.T, mode string, cb func(*http.Request, *setting.Cfg)) *AuthProxy {
+		return prepareMiddleware(t, cache, func(req *http.Request, cfg *setting.Cfg) {
+			cfg.AuthProxySignatureMode = mode
+			cfg.AuthProxySignatureHeader = "X-WEBAUTH-SIGNATURE"
+			cfg.AuthProxySignatureSecret = secret
+			cfg.AuthProxySignatureMaxAge = 5 * time.Minute
+			cfg.AuthProxyHeaders = map[string]string{"Email": "X-WEBAUTH-EMAIL", "Role": "X-WEBAUTH-ROLE"}
+			req.Header.Set("X-WEBAUTH-EMAIL", "markelog@example.com")
+			if cb != nil {
+				cb(req, cfg)
+			}
+		})
+	}
+	hmacSignature := func(timestamp time.Time, payload string) string {
+		t := fmt.Sprint(timestamp.Unix())
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(t + "\n" + payload))
+		return fmt.Sprintf("t=%s,v1=%s", t, hex.EncodeToString(mac.Sum(nil)))
+	}
+	const payload = "x-killa:markelog\nx-webauth-email:markelog@example.com\nx-webauth-role:"
+
+	t.Run("Should not verify the headers unless signed", func(t *testing.T) {
+		assert.NoError(t, newAuth(t, "", nil).VerifySignature())
+	})
+
+	t.Run("Should require the signature header", func(t *testing.T) {
+		assert.Error(t, newAuth(t, SignatureModeHMAC, nil).VerifySignature())
+	})
+
+	t.Run("Should verify the HMAC signature of the headers", func(t *testing.T) {
+		auth := newAuth(t, SignatureModeHMAC, func(req *http.Request, cfg *setting.Cfg) {
+			req.Header.Set("X-WEBAUTH-SIGNATURE", hmacSignature(current.Add(-time.Minute), payload))
+		})
+		assert.NoError(t, auth.VerifySignature())
+	})
+
+	t.Run("Should reject the headers added after the HMAC signature", func(t *testing.T) {
+		auth := newAuth(t, SignatureModeHMAC, func(req *http.Request, cfg *setting.Cfg) {
+			req.Header.Set("X-WEBAUTH-SIGNATURE", hmacSignature(current, payload))
+			req.Header.Set("X-WEBAUTH-ROLE", "Admin")
+		})
+		assert.Error(t, auth.VerifySignature())
+	})
+
+	t.Run("Should reject the HMAC signatures older than the max age", func(t *testing.T) {
+		auth := newAuth(t, SignatureModeHMAC, func(req *http.Request, cfg *setting.Cfg) {
+			req.Header.Set("X-WEBAUTH-SIGNATURE", hmacSignature(current.Add(-10*time.Minute), payload))
+		})
+		assert.Error(t, auth.VerifySignature())
+	})
+
+	signJWT := func(t *testing.T, key interface{}, alg jose.SignatureAlgorithm, claims jwtClaims) string {
+		signer, err := jose.NewSigner(jose.SigningKey{Algorithm: alg, Key: key}, nil)
+		require.NoError(t, err)
+		token, err := jwt.Signed(signer).Claims(claims).CompactSerialize()
+		require.NoError(t, err)
+		return token
+	}
+	headers := map[string]string{"x-killa": "markelog", "x-webauth-email": "markelog@example.com"}
+
+	t.Run("Should verify the JWT signature of the headers", func(t *testing.T) {
+		token := signJWT(t, []byte(secret), jose.HS256, jwtClaims{
+			Claims:  jwt.Claims{IssuedAt: jwt.NewNumericDate(current)},
+			Headers: headers,
+		})
+		auth := newAuth(t, SignatureModeJWT, func(req *http.Request, cfg *setting.Cfg) {
+			req.Header.Set("X-WEBAUTH-SIGNATURE", token)
+		})
+		assert.NoError(t, auth.VerifySignature())
+
+		auth = newAuth(t, SignatureModeJWT, func(req *http.Request, cfg *setting.Cfg) {
+			req.Header.Set("X-WEBAUTH-SIGNATURE", token)
+			req.Header.Set("X-WEBAUTH-EMAIL", "attacker@example.com")
+		})
+		assert.Error(t, auth.VerifySignature())
+	})
+
+	t.Run("Should reject the JWT signatures without time or expired", func(t *testing.T) {
+		token := signJWT(t, []byte(secret), jose.HS256, jwtClaims{Headers: headers})
+		auth := newAuth(t, SignatureModeJWT, func(req *http.Request, cfg *setting.Cfg) {
+			req.Header.Set("X-WEBAUTH-SIGNATURE", token)
+		})
+		assert.Error(t, auth.VerifySignature())
+
+		token = signJWT(t, []byte(secret), jose.HS256, jwtClaims{
+			Claims:  jwt.Claims{IssuedAt: jwt.NewNumericDate(current), Expiry: jwt.NewNumericDate(current.Add(-time.Minute))},
+			Headers: headers,
+		})
+		auth = newAuth(t, SignatureModeJWT, func(req *http.Request, cfg *setting.Cfg) {
+			req.Header.Set("X-WEBAUTH-SIGNATURE", token)
+		})
+		assert.Error(t, auth.VerifySignature())
+	})
+
+	t.Run("Should verify the JWT signature with the public key file", func(t *testing.T) {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		require.NoError(t, err)
+		keyFile := filepath.Join(t.TempDir(), "proxy.pem")
+		require.NoError(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600))
+
+		claims := jwtClaims{Claims: jwt.Claims{IssuedAt: jwt.NewNumericDate(current)}, Headers: headers}
+		auth := newAuth(t, SignatureModeJWT, func(req *http.Request, cfg *setting.Cfg) {
+			cfg.AuthProxySignatureKeyFile = keyFile
+			req.Header.Set("X-WEBAUTH-SIGNATURE", signJWT(t, key, jose.RS256, claims))
+		})
+		assert.NoError(t, auth.VerifySignature())
+
+		auth = newAuth(t, SignatureModeJWT, func(req *http.Request, cfg *setting.Cfg) {
+			cfg.AuthProxySignatureKeyFile = keyFile
+			req.Header.Set("X-WEBAUTH-SIGNATURE", signJWT(t, []byte(secret), jose.HS256, claims))
+		})
+		assert.Error(t, auth.VerifySignature())
+	})
+}
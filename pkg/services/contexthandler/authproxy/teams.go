@@ -0,0 +1,69 @@
+package authproxy
+
+import (
+	"errors"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// syncTeamMemberships syncs the teams of a user in an org with the teams of the auth proxy header, by name. The user
+// is added to the teams as an external member, and removed from the external memberships of the teams which aren't
+// in the header any more. The teams the user was added to in Grafana are kept, the teams which don't exist are
+// ignored.
+func (auth *AuthProxy) syncTeamMemberships(userID, orgID int64, teamNames []string) error {
+	external := &models.GetTeamMembersQuery{OrgId: orgID, UserId: userID, External: true}
+	if err := bus.Dispatch(external); err != nil {
+		return err
+	}
+	current := &models.GetTeamsByUserQuery{OrgId: orgID, UserId: userID}
+	if err := bus.Dispatch(current); err != nil {
+		return err
+	}
+
+	wanted := make(map[string]bool, len(teamNames))
+	for _, name := range teamNames {
+		wanted[name] = true
+	}
+	member := make(map[string]bool, len(current.Result))
+	teamNamesByID := make(map[int64]string, len(current.Result))
+	for _, team := range current.Result {
+		member[team.Name] = true
+		teamNamesByID[team.Id] = team.Name
+	}
+
+	for _, name := range teamNames {
+		if member[name] {
+			continue
+		}
+		search := &models.SearchTeamsQuery{OrgId: orgID, Name: name, Limit: 1, Page: 1}
+		if err := bus.Dispatch(search); err != nil {
+			return err
+		}
+		if len(search.Result.Teams) == 0 {
+			auth.log.Debug("Ignoring team of auth proxy header which doesn't exist", "team", name, "orgId", orgID)
+			continue
+		}
+		cmd := &models.AddTeamMemberCommand{
+			UserId:   userID,
+			OrgId:    orgID,
+			TeamId:   search.Result.Teams[0].Id,
+			External: true,
+		}
+		if err := bus.Dispatch(cmd); err != nil && !errors.Is(err, models.ErrTeamMemberAlreadyAdded) {
+			return err
+		}
+		member[name] = true
+	}
+
+	for _, membership := range external.Result {
+		if wanted[teamNamesByID[membership.TeamId]] {
+			continue
+		}
+		cmd := &models.RemoveTeamMemberCommand{OrgId: orgID, UserId: userID, TeamId: membership.TeamId}
+		if err := bus.Dispatch(cmd); err != nil && !errors.Is(err, models.ErrTeamMemberNotFound) {
+			return err
+		}
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ package contexthandler
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
@@ -33,6 +34,11 @@ const (
 
 const ServiceName = "ContextHandler"
 
+var (
+	ErrInvalidAPIKey = errors.New(InvalidAPIKey)
+	ErrExpiredAPIKey = errors.New("expired API key")
+)
+
 func init() {
 	registry.Register(&registry.Descriptor{
 		Name:         ServiceName,
@@ -104,6 +110,10 @@ func (h *ContextHandler) Middleware(c *macaron.Context) {
 	}
 
 	ctx.Logger = log.New("context", "userId", ctx.UserId, "orgId", ctx.OrgId, "uname", ctx.Login)
+	if ctx.Impersonation != nil {
+		ctx.Logger = ctx.Logger.New("impersonatorId", ctx.ImpersonatorId)
+		logImpersonatedRequest(ctx)
+	}
 	ctx.Data["ctx"] = ctx
 
 	c.Map(ctx)
@@ -154,18 +164,41 @@ func (h *ContextHandler) initContextWithAPIKey(ctx *models.ReqContext) bool {
 		return false
 	}
 
+	apikey, err := h.ValidateAPIKey(keyString)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidAPIKey):
+			ctx.JsonApiErr(401, InvalidAPIKey, err)
+		case errors.Is(err, ErrExpiredAPIKey):
+			ctx.JsonApiErr(401, "Expired API key", err)
+		default:
+			ctx.JsonApiErr(500, "Validating API key failed", err)
+		}
+		return true
+	}
+
+	ctx.IsSignedIn = true
+	ctx.SignedInUser = &models.SignedInUser{}
+	ctx.OrgRole = apikey.Role
+	ctx.ApiKeyId = apikey.Id
+	ctx.OrgId = apikey.OrgId
+	return true
+}
+
+// ValidateAPIKey returns the API key of the key string, after checking that
+// it isn't expired. The errors are ErrInvalidAPIKey and ErrExpiredAPIKey,
+// unless the validation failed.
+func (h *ContextHandler) ValidateAPIKey(keyString string) (*models.ApiKey, error) {
 	// base64 decode key
 	decoded, err := apikeygen.Decode(keyString)
 	if err != nil {
-		ctx.JsonApiErr(401, InvalidAPIKey, err)
-		return true
+		return nil, fmt.Errorf("%w: %s", ErrInvalidAPIKey, err)
 	}
 
 	// fetch key
 	keyQuery := models.GetApiKeyByNameQuery{KeyName: decoded.Name, OrgId: decoded.OrgId}
 	if err := bus.Dispatch(&keyQuery); err != nil {
-		ctx.JsonApiErr(401, InvalidAPIKey, err)
-		return true
+		return nil, fmt.Errorf("%w: %s", ErrInvalidAPIKey, err)
 	}
 
 	apikey := keyQuery.Result
@@ -173,12 +206,10 @@ func (h *ContextHandler) initContextWithAPIKey(ctx *models.ReqContext) bool {
 	// validate api key
 	isValid, err := apikeygen.IsValid(decoded, apikey.Key)
 	if err != nil {
-		ctx.JsonApiErr(500, "Validating API key failed", err)
-		return true
+		return nil, err
 	}
 	if !isValid {
-		ctx.JsonApiErr(401, InvalidAPIKey, err)
-		return true
+		return nil, ErrInvalidAPIKey
 	}
 
 	// check for expiration
@@ -186,17 +217,32 @@ func (h *ContextHandler) initContextWithAPIKey(ctx *models.ReqContext) bool {
 	if getTime == nil {
 		getTime = time.Now
 	}
-	if apikey.Expires != nil && *apikey.Expires <= getTime().Unix() {
-		ctx.JsonApiErr(401, "Expired API key", err)
-		return true
+	now := getTime()
+	if apikey.Expires != nil && *apikey.Expires <= now.Unix() {
+		return nil, ErrExpiredAPIKey
+	}
+	if h.Cfg.ApiKeyMaxLifetime > 0 && !apikey.Created.Add(h.Cfg.ApiKeyMaxLifetime).After(now) {
+		return nil, ErrExpiredAPIKey
 	}
+	if h.Cfg.ApiKeyMaxInactiveLifetime > 0 {
+		lastUsed := apikey.Created.Unix()
+		if h.Cfg.ApiKeyExtendOnActivity && apikey.LastUsedAt != nil && *apikey.LastUsedAt > lastUsed {
+			lastUsed = *apikey.LastUsedAt
+		}
+		if lastUsed <= now.Add(-h.Cfg.ApiKeyMaxInactiveLifetime).Unix() {
+			return nil, ErrExpiredAPIKey
+		}
 
-	ctx.IsSignedIn = true
-	ctx.SignedInUser = &models.SignedInUser{}
-	ctx.OrgRole = apikey.Role
-	ctx.ApiKeyId = apikey.Id
-	ctx.OrgId = apikey.OrgId
-	return true
+		// the use of the key is recorded at most once a minute, which is the resolution of its inactive lifetime
+		if h.Cfg.ApiKeyExtendOnActivity && lastUsed <= now.Add(-time.Minute).Unix() {
+			cmd := models.UpdateApiKeyLastUsedCommand{Id: apikey.Id, LastUsedAt: now.Unix()}
+			if err := bus.Dispatch(&cmd); err != nil {
+				log.New("context").Error("Failed to record the use of the API key", "id", apikey.Id, "error", err)
+			}
+		}
+	}
+
+	return apikey, nil
 }
 
 func (h *ContextHandler) initContextWithBasicAuth(ctx *models.ReqContext, orgID int64) bool {
@@ -278,6 +324,7 @@ func (h *ContextHandler) initContextWithToken(ctx *models.ReqContext, orgID int6
 	ctx.SignedInUser = query.Result
 	ctx.IsSignedIn = true
 	ctx.UserToken = token
+	h.initImpersonation(ctx, orgID)
 
 	// Rotate the token just before we write response headers to ensure there is no delay between
 	// the new token being generated and the client receiving it.
@@ -399,6 +446,14 @@ func (h *ContextHandler) initContextWithAuthProxy(ctx *models.ReqContext, orgID
 		return true
 	}
 
+	// Check the signature of the headers, so that they can't be spoofed by bypassing the proxy
+	if err := auth.VerifySignature(); err != nil {
+		h.handleError(ctx, err, 407, func(details error) {
+			logger.Error("Failed to verify the signature of the auth proxy headers", "message", err.Error(), "error", details)
+		})
+		return true
+	}
+
 	id, err := logUserIn(auth, username, logger, false)
 	if err != nil {
 		h.handleError(ctx, err, 407, nil)
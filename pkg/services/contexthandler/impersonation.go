@@ -0,0 +1,59 @@
+package contexthandler
+
+import (
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/middleware/cookies"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+var impersonationAuditLogger = log.New("audit.impersonation")
+
+// initImpersonation replaces the signed in Grafana admin with the user they impersonate, when the request has the
+// cookie of an active impersonation session of the admin. The cookie of the other sessions is deleted.
+func (h *ContextHandler) initImpersonation(ctx *models.ReqContext, orgID int64) {
+	key := ctx.GetCookie(models.ImpersonationCookieName)
+	if key == "" {
+		return
+	}
+
+	query := models.GetImpersonationSessionQuery{KeyHash: models.HashImpersonationKey(key)}
+	if err := bus.Dispatch(&query); err != nil {
+		if !errors.Is(err, models.ErrImpersonationSessionNotFound) {
+			ctx.Logger.Error("Failed to get impersonation session", "error", err)
+		}
+		cookies.DeleteCookie(ctx.Resp, models.ImpersonationCookieName, nil)
+		return
+	}
+
+	getTime := h.GetTime
+	if getTime == nil {
+		getTime = time.Now
+	}
+	session := query.Result
+	if !ctx.IsGrafanaAdmin || session.AdminUserId != ctx.UserId || !session.IsActive(getTime()) {
+		cookies.DeleteCookie(ctx.Resp, models.ImpersonationCookieName, nil)
+		return
+	}
+
+	userQuery := models.GetSignedInUserQuery{UserId: session.UserId, OrgId: orgID}
+	if err := bus.DispatchCtx(ctx.Req.Context(), &userQuery); err != nil {
+		ctx.Logger.Error("Failed to get impersonated user", "userId", session.UserId, "error", err)
+		cookies.DeleteCookie(ctx.Resp, models.ImpersonationCookieName, nil)
+		return
+	}
+
+	userQuery.Result.ImpersonatorId = session.AdminUserId
+	ctx.SignedInUser = userQuery.Result
+	ctx.Impersonation = session
+}
+
+// logImpersonatedRequest writes a request of a Grafana admin impersonating a user to the audit log.
+func logImpersonatedRequest(ctx *models.ReqContext) {
+	impersonationAuditLogger.Info("Impersonated request", "session", ctx.Impersonation.Id,
+		"admin", ctx.Impersonation.AdminUserId, "user", ctx.UserId, "org", ctx.OrgId, "method", ctx.Req.Method,
+		"path", ctx.Req.URL.Path)
+}
@@ -0,0 +1,266 @@
+package dashboards
+
+import (
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// ExpandRepeats returns the panels of a dashboard with their repeated rows and panels expanded for the values of
+// their variables, like the frontend shows them, e.g. for the renderer to show all the repeated panels. The values
+// of the variables are in the scopedVars of the repeats, and the copies of the rows and panels have new IDs and the
+// ID of the repeated row or panel in their repeatPanelId. The rows and panels below the repeated ones are moved down
+// to make room for the repeats. The panels are modified.
+func ExpandRepeats(panels []interface{}, values map[string][]string) []interface{} {
+	r := &repeater{values: values, nextID: maxPanelID(panels)}
+	return r.expandRepeats(panels)
+}
+
+// GridHeight returns the height of the panels of a dashboard in grid units, a collapsed row being one unit high.
+func GridHeight(panels []interface{}) int {
+	height := 0
+	for _, p := range panels {
+		panel, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if bottom := gridPos(panel, "y") + gridPos(panel, "h"); bottom > height {
+			height = bottom
+		}
+	}
+	return height
+}
+
+// repeater expands the repeated rows and panels of a dashboard.
+type repeater struct {
+	// values are the values of the variables of the dashboard by name
+	values map[string][]string
+	// nextID is the last ID given to a panel, the copies of the repeated panels are given the next ones
+	nextID int64
+}
+
+// dashboardSection is a row of a dashboard and its panels, the panels before the first row have no row.
+type dashboardSection struct {
+	row    map[string]interface{}
+	panels []interface{}
+}
+
+func (r *repeater) expandRepeats(panels []interface{}) []interface{} {
+	var sections []dashboardSection
+	for _, section := range splitSections(panels) {
+		sections = append(sections, r.repeatSection(section)...)
+	}
+
+	result := []interface{}{}
+	y := 0
+	for _, section := range sections {
+		start := 0
+		if section.row != nil {
+			start = gridPos(section.row, "y") + 1
+			setGridPos(section.row, "y", y)
+			y++
+			result = append(result, section.row)
+		}
+
+		panels := r.repeatPanels(section.panels, section.row)
+		if section.row != nil && simplejson.NewFromAny(section.row).Get("collapsed").MustBool() {
+			section.row["panels"] = panels
+			continue
+		}
+
+		height := 0
+		for _, p := range panels {
+			panel := p.(map[string]interface{})
+			offset := gridPos(panel, "y") - start
+			setGridPos(panel, "y", y+offset)
+			if bottom := offset + gridPos(panel, "h"); bottom > height {
+				height = bottom
+			}
+		}
+		y += height
+		result = append(result, panels...)
+	}
+	return result
+}
+
+// splitSections splits the panels of a dashboard by row. The panels of the collapsed rows are within them, those of
+// the expanded rows follow them.
+func splitSections(panels []interface{}) []dashboardSection {
+	sections := []dashboardSection{{}}
+	for _, p := range panels {
+		panel, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if panel["type"] == "row" {
+			section := dashboardSection{row: panel}
+			if simplejson.NewFromAny(panel).Get("collapsed").MustBool() {
+				section.panels = simplejson.NewFromAny(panel).Get("panels").MustArray()
+			}
+			sections = append(sections, section)
+			continue
+		}
+		sections[len(sections)-1].panels = append(sections[len(sections)-1].panels, panel)
+	}
+	return sections
+}
+
+// repeatSection returns the copies of a repeated row and its panels for each value of its variable.
+func (r *repeater) repeatSection(section dashboardSection) []dashboardSection {
+	if section.row == nil {
+		return []dashboardSection{section}
+	}
+	name := simplejson.NewFromAny(section.row).Get("repeat").MustString()
+	values := r.values[name]
+	if name == "" || len(values) == 0 {
+		return []dashboardSection{section}
+	}
+
+	sections := make([]dashboardSection, 0, len(values))
+	for i, value := range values {
+		repeated := section
+		if i > 0 {
+			repeated = dashboardSection{row: r.copyPanel(section.row)}
+			for _, p := range section.panels {
+				repeated.panels = append(repeated.panels, r.copyPanel(p.(map[string]interface{})))
+			}
+		}
+		delete(repeated.row, "repeat")
+		setScopedVar(repeated.row, name, value)
+		sections = append(sections, repeated)
+	}
+	return sections
+}
+
+// repeatPanels returns the panels with their repeated panels expanded, and the scoped variables of their row.
+func (r *repeater) repeatPanels(panels []interface{}, row map[string]interface{}) []interface{} {
+	result := []interface{}{}
+	for i, p := range panels {
+		panel, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if row != nil {
+			for name, scopedVar := range simplejson.NewFromAny(row).Get("scopedVars").MustMap() {
+				setScopedVarValue(panel, name, scopedVar)
+			}
+		}
+
+		name := simplejson.NewFromAny(panel).Get("repeat").MustString()
+		values := r.values[name]
+		if name == "" || len(values) == 0 {
+			result = append(result, panel)
+			continue
+		}
+
+		x, y, w, h := gridPos(panel, "x"), gridPos(panel, "y"), gridPos(panel, "w"), gridPos(panel, "h")
+		horizontal := simplejson.NewFromAny(panel).Get("repeatDirection").MustString() == "h"
+		width := w
+		if horizontal {
+			maxPerRow := simplejson.NewFromAny(panel).Get("maxPerRow").MustInt(4)
+			if maxPerRow <= 0 || maxPerRow > 24 {
+				maxPerRow = 4
+			}
+			width = 24 / len(values)
+			if min := 24 / maxPerRow; width < min {
+				width = min
+			}
+		}
+
+		bottom := y
+		repeated := make([]interface{}, 0, len(values))
+		for j, value := range values {
+			clone := panel
+			if j > 0 {
+				clone = r.copyPanel(panel)
+			}
+			delete(clone, "repeat")
+			setScopedVar(clone, name, value)
+
+			if horizontal {
+				setGridPos(clone, "x", j*width%24)
+				setGridPos(clone, "y", y+j*width/24*h)
+				setGridPos(clone, "w", width)
+			} else {
+				setGridPos(clone, "x", x)
+				setGridPos(clone, "y", y+j*h)
+			}
+			if cloneBottom := gridPos(clone, "y") + h; cloneBottom > bottom {
+				bottom = cloneBottom
+			}
+			repeated = append(repeated, clone)
+		}
+
+		// the panels below the repeated panel are moved down by the height of the repeats
+		if extra := bottom - (y + h); extra > 0 {
+			moveDown := func(others []interface{}) {
+				for _, o := range others {
+					if other, ok := o.(map[string]interface{}); ok && gridPos(other, "y") >= y+h {
+						setGridPos(other, "y", gridPos(other, "y")+extra)
+					}
+				}
+			}
+			moveDown(result)
+			moveDown(panels[i+1:])
+		}
+		result = append(result, repeated...)
+	}
+	return result
+}
+
+// copyPanel returns a copy of a panel with a new ID, referencing the panel it's a repeat of.
+func (r *repeater) copyPanel(panel map[string]interface{}) map[string]interface{} {
+	b, err := simplejson.NewFromAny(panel).Encode()
+	if err != nil {
+		return panel
+	}
+	copied, err := simplejson.NewJson(b)
+	if err != nil {
+		return panel
+	}
+
+	r.nextID++
+	copied.Set("repeatPanelId", panel["id"])
+	copied.Set("id", r.nextID)
+	return copied.MustMap()
+}
+
+// maxPanelID returns the largest ID of the panels, and of the panels of the collapsed rows.
+func maxPanelID(panels []interface{}) int64 {
+	var max int64
+	for _, p := range panels {
+		panel := simplejson.NewFromAny(p)
+		if id := panel.Get("id").MustInt64(); id > max {
+			max = id
+		}
+		if id := maxPanelID(panel.Get("panels").MustArray()); id > max {
+			max = id
+		}
+	}
+	return max
+}
+
+func gridPos(panel map[string]interface{}, key string) int {
+	return simplejson.NewFromAny(panel).GetPath("gridPos", key).MustInt()
+}
+
+func setGridPos(panel map[string]interface{}, key string, value int) {
+	grid, ok := panel["gridPos"].(map[string]interface{})
+	if !ok {
+		grid = map[string]interface{}{}
+		panel["gridPos"] = grid
+	}
+	grid[key] = value
+}
+
+// setScopedVar sets the value of a variable for a repeated panel or row.
+func setScopedVar(panel map[string]interface{}, name string, value string) {
+	setScopedVarValue(panel, name, map[string]interface{}{"text": value, "value": value})
+}
+
+func setScopedVarValue(panel map[string]interface{}, name string, scopedVar interface{}) {
+	scopedVars, ok := panel["scopedVars"].(map[string]interface{})
+	if !ok {
+		scopedVars = map[string]interface{}{}
+		panel["scopedVars"] = scopedVars
+	}
+	scopedVars[name] = scopedVar
+}
@@ -0,0 +1,65 @@
+package dashboards
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestExpandRepeats(t *testing.T) {
+	panels := func(t *testing.T, model string) []interface{} {
+		t.Helper()
+		dashboard, err := simplejson.NewJson([]byte(model))
+		require.NoError(t, err)
+		return dashboard.Get("panels").MustArray()
+	}
+
+	t.Run("expands the horizontal repeats in rows of max per row panels", func(t *testing.T) {
+		expanded := ExpandRepeats(panels(t, `{"panels": [
+			{"id": 1, "repeat": "host", "repeatDirection": "h", "maxPerRow": 2, "gridPos": {"x": 0, "y": 0, "w": 24, "h": 4}},
+			{"id": 2, "gridPos": {"x": 0, "y": 4, "w": 24, "h": 4}}
+		]}`), map[string][]string{"host": {"a", "b", "c"}})
+
+		require.Len(t, expanded, 4)
+		var grids [][]int
+		for _, p := range expanded {
+			panel := simplejson.NewFromAny(p)
+			grids = append(grids, []int{
+				panel.GetPath("gridPos", "x").MustInt(), panel.GetPath("gridPos", "y").MustInt(),
+				panel.GetPath("gridPos", "w").MustInt(),
+			})
+		}
+		assert.Equal(t, [][]int{{0, 0, 12}, {12, 0, 12}, {0, 4, 12}, {0, 8, 24}}, grids)
+		assert.Equal(t, "c", simplejson.NewFromAny(expanded[2]).GetPath("scopedVars", "host", "value").MustString())
+		assert.Equal(t, int64(4), simplejson.NewFromAny(expanded[2]).Get("id").MustInt64())
+		assert.Equal(t, 12, GridHeight(expanded))
+	})
+
+	t.Run("expands the repeats of the collapsed rows within them", func(t *testing.T) {
+		expanded := ExpandRepeats(panels(t, `{"panels": [
+			{"id": 1, "type": "row", "repeat": "region", "collapsed": true, "gridPos": {"x": 0, "y": 0, "w": 24, "h": 1},
+				"panels": [{"id": 2, "gridPos": {"x": 0, "y": 1, "w": 12, "h": 8}}]}
+		]}`), map[string][]string{"region": {"eu", "us"}})
+
+		require.Len(t, expanded, 2)
+		second := simplejson.NewFromAny(expanded[1])
+		assert.Equal(t, 1, second.GetPath("gridPos", "y").MustInt())
+		assert.Equal(t, int64(1), second.Get("repeatPanelId").MustInt64())
+		nested := second.Get("panels").GetIndex(0)
+		assert.Equal(t, "us", nested.GetPath("scopedVars", "region", "value").MustString())
+		assert.Equal(t, int64(2), nested.Get("repeatPanelId").MustInt64())
+		assert.Equal(t, 2, GridHeight(expanded))
+	})
+
+	t.Run("keeps the repeats of variables without values", func(t *testing.T) {
+		expanded := ExpandRepeats(panels(t, `{"panels": [
+			{"id": 1, "repeat": "host", "gridPos": {"x": 0, "y": 0, "w": 24, "h": 4}}
+		]}`), map[string][]string{})
+
+		require.Len(t, expanded, 1)
+		assert.Equal(t, "host", simplejson.NewFromAny(expanded[0]).Get("repeat").MustString())
+	})
+}
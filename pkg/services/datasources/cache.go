@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/eventbus"
 	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/registry"
@@ -18,9 +20,13 @@ type CacheService interface {
 type CacheServiceImpl struct {
 	CacheService *localcache.CacheService `inject:""`
 	SQLStore     *sqlstore.SQLStore       `inject:""`
+	Bus          bus.Bus                  `inject:""`
 }
 
 func init() {
+	// the other servers invalidate their caches of the changed data sources
+	eventbus.Distribute(&models.DataSourceChangedEvent{})
+
 	registry.Register(&registry.Descriptor{
 		Name:         "DatasourceCacheService",
 		Instance:     &CacheServiceImpl{},
@@ -29,6 +35,26 @@ func init() {
 }
 
 func (dc *CacheServiceImpl) Init() error {
+	dc.Bus.AddEventListener(dc.handleDataSourceChanged)
+	return nil
+}
+
+// handleDataSourceChanged removes the cached data source, its HTTP transport and decrypted secureJsonData, of this
+// server or another one, so that they're read and created again when next used.
+func (dc *CacheServiceImpl) handleDataSourceChanged(event *models.DataSourceChangedEvent) error {
+	uid := event.Uid
+	if cached, found := dc.CacheService.Get(idKey(event.Id)); found {
+		// the UID isn't set by the updates leaving it unchanged
+		if uid == "" {
+			uid = cached.(*models.DataSource).Uid
+		}
+		dc.CacheService.Delete(idKey(event.Id))
+	}
+	if uid != "" {
+		dc.CacheService.Delete(uidKey(event.OrgId, uid))
+	}
+
+	models.ClearDSCache(event.Id)
 	return nil
 }
 
@@ -0,0 +1,292 @@
+// Package onboarding validates the setup of data sources step by step, so that
+// the configuration page of a data source shows exactly which part of the
+// setup is wrong: the settings, the connection, the health check of the
+// plugin, a sample query or the permissions of the configured user.
+package onboarding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/adapters"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/tsdb"
+)
+
+// Statuses of the validation steps.
+const (
+	StatusOK      = "ok"
+	StatusWarning = "warning"
+	StatusError   = "error"
+	StatusSkipped = "skipped"
+)
+
+// IDs of the validation steps, in the order they run.
+const (
+	StepSettings    = "settings"
+	StepConnection  = "connection"
+	StepHealth      = "health"
+	StepQuery       = "query"
+	StepPermissions = "permissions"
+)
+
+const stepTimeout = 15 * time.Second
+
+var logger = log.New("datasources.onboarding")
+
+// Step is the result of a validation step.
+type Step struct {
+	ID      string                 `json:"id"`
+	Title   string                 `json:"title"`
+	Status  string                 `json:"status"`
+	Message string                 `json:"message,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// Result is the result of the validation of a data source. Its status is
+// the worst status of the steps.
+type Result struct {
+	Status string `json:"status"`
+	Steps  []Step `json:"steps"`
+}
+
+// Service validates the setup of data sources.
+type Service struct {
+	DataService            *tsdb.Service                 `inject:""`
+	BackendPluginManager   backendplugin.Manager         `inject:""`
+	PluginRequestValidator models.PluginRequestValidator `inject:""`
+	HTTPClientProvider     httpclient.Provider           `inject:""`
+}
+
+func init() {
+	registry.RegisterService(&Service{})
+}
+
+// Init initializes the service.
+func (s *Service) Init() error {
+	return nil
+}
+
+// Validate runs the validation steps of a data source. The steps after a
+// failed settings or connection step are skipped.
+func (s *Service) Validate(ctx context.Context, user *models.SignedInUser, ds *models.DataSource) Result {
+	v := validations[ds.Type]
+	result := Result{Status: StatusOK}
+
+	add := func(step Step) {
+		result.Steps = append(result.Steps, step)
+		switch {
+		case step.Status == StatusError:
+			result.Status = StatusError
+		case step.Status == StatusWarning && result.Status == StatusOK:
+			result.Status = StatusWarning
+		}
+	}
+	skipRest := func(from int, reason string) {
+		for _, step := range steps[from:] {
+			add(Step{ID: step.id, Title: step.title, Status: StatusSkipped, Message: reason})
+		}
+	}
+
+	for i, step := range steps {
+		stepCtx, cancel := context.WithTimeout(ctx, stepTimeout)
+		res := step.run(stepCtx, s, user, ds, v)
+		cancel()
+
+		res.ID = step.id
+		res.Title = step.title
+		add(res)
+
+		if res.Status == StatusError && (step.id == StepSettings || step.id == StepConnection) {
+			skipRest(i+1, fmt.Sprintf("Skipped, the %s step failed", step.id))
+			break
+		}
+	}
+
+	logger.Debug("Validated data source", "datasource", ds.Name, "type", ds.Type, "status", result.Status)
+	return result
+}
+
+type stepRunner func(ctx context.Context, s *Service, user *models.SignedInUser, ds *models.DataSource, v validation) Step
+
+var steps = []struct {
+	id    string
+	title string
+	run   stepRunner
+}{
+	{StepSettings, "Check settings", validateSettings},
+	{StepConnection, "Test connection", testConnection},
+	{StepHealth, "Run health check", runHealthCheck},
+	{StepQuery, "Run sample query", runSampleQuery},
+	{StepPermissions, "Verify permissions", verifyPermissions},
+}
+
+func validateSettings(_ context.Context, _ *Service, _ *models.SignedInUser, ds *models.DataSource, v validation) Step {
+	switch v.connection {
+	case connectionHTTP:
+		u, err := url.Parse(ds.Url)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return Step{Status: StatusError, Message: fmt.Sprintf("The URL %q must be an absolute http or https URL", ds.Url)}
+		}
+	case connectionTCP:
+		if _, _, err := net.SplitHostPort(tcpAddress(ds, v)); err != nil {
+			return Step{Status: StatusError, Message: fmt.Sprintf("The host %q must be a host name and a port", ds.Url)}
+		}
+	}
+
+	if v.settings != nil {
+		if err := v.settings(ds); err != nil {
+			return Step{Status: StatusError, Message: fmt.Sprintf("Invalid settings: %v", err)}
+		}
+	}
+
+	return Step{Status: StatusOK, Message: "The settings are valid"}
+}
+
+func testConnection(ctx context.Context, s *Service, _ *models.SignedInUser, ds *models.DataSource, v validation) Step {
+	switch v.connection {
+	case connectionHTTP:
+		if err := s.PluginRequestValidator.Validate(ds.Url, nil); err != nil {
+			return Step{Status: StatusError, Message: "The URL is not allowed by the data source proxy whitelist"}
+		}
+
+		resp, err := s.get(ctx, ds, ds.Url)
+		if err != nil {
+			return Step{Status: StatusError, Message: fmt.Sprintf("Failed to connect to %s: %v", ds.Url, err)}
+		}
+		if err := resp.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "err", err)
+		}
+
+		details := map[string]interface{}{"statusCode": resp.StatusCode}
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			return Step{Status: StatusError, Message: "Authentication failed, check the credentials", Details: details}
+		}
+		return Step{Status: StatusOK, Message: fmt.Sprintf("Connected to %s", ds.Url), Details: details}
+	case connectionTCP:
+		address := tcpAddress(ds, v)
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", address)
+		if err != nil {
+			return Step{Status: StatusError, Message: fmt.Sprintf("Failed to connect to %s: %v", address, err)}
+		}
+		if err := conn.Close(); err != nil {
+			logger.Warn("Failed to close connection", "err", err)
+		}
+		return Step{Status: StatusOK, Message: fmt.Sprintf("Connected to %s", address)}
+	default:
+		return Step{Status: StatusSkipped, Message: "The connection of this data source type can't be tested"}
+	}
+}
+
+func runHealthCheck(ctx context.Context, s *Service, user *models.SignedInUser, ds *models.DataSource, _ validation) Step {
+	settings, err := adapters.ModelToInstanceSettings(ds)
+	if err != nil {
+		return Step{Status: StatusError, Message: err.Error()}
+	}
+
+	resp, err := s.BackendPluginManager.CheckHealth(ctx, backend.PluginContext{
+		User:                       adapters.BackendUserFromSignedInUser(user),
+		OrgID:                      ds.OrgId,
+		PluginID:                   ds.Type,
+		DataSourceInstanceSettings: settings,
+	})
+	if err != nil {
+		if errors.Is(err, backendplugin.ErrPluginNotRegistered) || errors.Is(err, backendplugin.ErrMethodNotImplemented) {
+			return Step{Status: StatusSkipped, Message: "The data source has no health check"}
+		}
+		return Step{Status: StatusError, Message: fmt.Sprintf("Health check failed: %v", err)}
+	}
+
+	if resp.Status != backend.HealthStatusOk {
+		return Step{Status: StatusError, Message: resp.Message}
+	}
+	return Step{Status: StatusOK, Message: resp.Message}
+}
+
+func runSampleQuery(ctx context.Context, s *Service, user *models.SignedInUser, ds *models.DataSource, v validation) Step {
+	if v.sampleQuery == nil {
+		return Step{Status: StatusSkipped, Message: "There is no sample query for this data source type"}
+	}
+
+	model := v.sampleQuery(ds)
+	if model == nil {
+		return Step{Status: StatusSkipped, Message: "There is no sample query for the settings of this data source"}
+	}
+
+	frames, err := s.query(ctx, user, ds, model)
+	if err != nil {
+		return Step{Status: StatusError, Message: fmt.Sprintf("The sample query failed: %v", err)}
+	}
+	return Step{
+		Status:  StatusOK,
+		Message: "The sample query succeeded",
+		Details: map[string]interface{}{"query": model, "frames": len(frames)},
+	}
+}
+
+func verifyPermissions(ctx context.Context, s *Service, user *models.SignedInUser, ds *models.DataSource, v validation) Step {
+	if v.permissions == nil {
+		return Step{Status: StatusSkipped, Message: "The permissions of this data source type can't be verified"}
+	}
+	return v.permissions(ctx, s, user, ds)
+}
+
+// query runs a query of the data source and returns the frames of the result,
+// with a frame per series or table of the data sources returning those.
+func (s *Service) query(ctx context.Context, user *models.SignedInUser, ds *models.DataSource, model map[string]interface{}) (data.Frames, error) {
+	timeRange := plugins.NewDataTimeRange("now-5m", "now")
+	resp, err := s.DataService.HandleRequest(ctx, ds, plugins.DataQuery{
+		TimeRange: &timeRange,
+		User:      user,
+		Queries: []plugins.DataSubQuery{{
+			RefID:         "A",
+			MaxDataPoints: 100,
+			IntervalMS:    60000,
+			Model:         simplejson.NewFromAny(model),
+			DataSource:    ds,
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := resp.Results["A"]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.ErrorString != "" {
+		return nil, errors.New(result.ErrorString)
+	}
+	if result.Dataframes != nil {
+		return result.Dataframes.Decoded()
+	}
+	return make(data.Frames, len(result.Series)+len(result.Tables)), nil
+}
+
+// get sends a GET request with the HTTP client of the data source.
+func (s *Service) get(ctx context.Context, ds *models.DataSource, rawURL string) (*http.Response, error) {
+	client, err := ds.GetHTTPClient(s.HTTPClientProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	return client.Do(req)
+}
@@ -0,0 +1,164 @@
+package onboarding
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/plugins/manager"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	labelsStatus := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/labels" {
+			w.WriteHeader(labelsStatus)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	queryErr := error(nil)
+	s := newTestService(func(ds *models.DataSource, query plugins.DataQuery) plugins.DataResponse {
+		return plugins.DataResponse{Results: map[string]plugins.DataQueryResult{
+			"A": {RefID: "A", Error: queryErr, Series: plugins.DataTimeSeriesSlice{{Name: "1"}}},
+		}}
+	})
+	ds := &models.DataSource{Type: "prometheus", Url: server.URL, JsonData: simplejson.New()}
+
+	t.Run("Should run all steps", func(t *testing.T) {
+		result := s.Validate(context.Background(), &models.SignedInUser{}, ds)
+
+		require.Equal(t, StatusOK, result.Status)
+		require.Equal(t, []string{StatusOK, StatusOK, StatusSkipped, StatusOK, StatusOK}, stepStatuses(result))
+		require.Equal(t, 1, result.Steps[3].Details["frames"])
+	})
+
+	t.Run("Should report failing queries and denied labels", func(t *testing.T) {
+		queryErr = errors.New("parse error")
+		labelsStatus = http.StatusForbidden
+		t.Cleanup(func() {
+			queryErr = nil
+			labelsStatus = http.StatusOK
+		})
+
+		result := s.Validate(context.Background(), &models.SignedInUser{}, ds)
+
+		require.Equal(t, StatusError, result.Status)
+		require.Equal(t, []string{StatusOK, StatusOK, StatusSkipped, StatusError, StatusError}, stepStatuses(result))
+		require.Equal(t, "The sample query failed: parse error", result.Steps[3].Message)
+	})
+
+	t.Run("Should skip the steps after invalid settings", func(t *testing.T) {
+		result := s.Validate(context.Background(), &models.SignedInUser{}, &models.DataSource{Type: "prometheus", Url: "localhost:9090", JsonData: simplejson.New()})
+
+		require.Equal(t, StatusError, result.Status)
+		require.Equal(t, []string{StatusError, StatusSkipped, StatusSkipped, StatusSkipped, StatusSkipped}, stepStatuses(result))
+	})
+
+	t.Run("Should test the connection of SQL data sources", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		address := listener.Addr().String()
+		require.NoError(t, listener.Close())
+
+		result := s.Validate(context.Background(), &models.SignedInUser{}, &models.DataSource{Type: "mysql", Url: address, Database: "grafana", JsonData: simplejson.New()})
+
+		require.Equal(t, []string{StatusOK, StatusError, StatusSkipped, StatusSkipped, StatusSkipped}, stepStatuses(result))
+	})
+
+	t.Run("Should warn about write privileges of SQL users", func(t *testing.T) {
+		u, err := url.Parse(server.URL)
+		require.NoError(t, err)
+
+		s := newTestService(func(ds *models.DataSource, query plugins.DataQuery) plugins.DataResponse {
+			grants := []string{"GRANT USAGE ON *.* TO 'grafana'@'%'"}
+			if query.Queries[0].Model.Get("rawSql").MustString() == "SHOW GRANTS" {
+				grants = append(grants, "GRANT SELECT, INSERT, UPDATE ON `grafana`.* TO 'grafana'@'%'")
+			}
+			frame := data.NewFrame("", data.NewField("Grants", nil, grants))
+			return plugins.DataResponse{Results: map[string]plugins.DataQueryResult{
+				"A": {RefID: "A", Dataframes: plugins.NewDecodedDataFrames(data.Frames{frame})},
+			}}
+		})
+		result := s.Validate(context.Background(), &models.SignedInUser{}, &models.DataSource{Type: "mysql", Url: u.Host, Database: "grafana", JsonData: simplejson.New()})
+
+		require.Equal(t, StatusWarning, result.Status)
+		require.Equal(t, []string{StatusOK, StatusOK, StatusSkipped, StatusOK, StatusWarning}, stepStatuses(result))
+		require.Equal(t, []string{"INSERT", "UPDATE"}, result.Steps[4].Details["privileges"])
+	})
+}
+
+func TestWritePrivileges(t *testing.T) {
+	frames := []*data.Frame{data.NewFrame("",
+		data.NewField("privilege", nil, []*string{stringPointer("insert"), nil, stringPointer("DELETE")}),
+	)}
+
+	require.Equal(t, []string{"DELETE", "INSERT"}, writePrivileges(frames, nil))
+}
+
+func newTestService(query func(ds *models.DataSource, query plugins.DataQuery) plugins.DataResponse) *Service {
+	dataService := tsdb.NewService()
+	dataService.PluginManager = &manager.PluginManager{BackendPluginManager: fakeBackendPluginManager{}}
+	dataService.RegisterQueryHandler("prometheus", func(*models.DataSource) (plugins.DataPlugin, error) {
+		return fakeDataPlugin(query), nil
+	})
+	dataService.RegisterQueryHandler("mysql", func(*models.DataSource) (plugins.DataPlugin, error) {
+		return fakeDataPlugin(query), nil
+	})
+
+	return &Service{
+		DataService:            &dataService,
+		BackendPluginManager:   fakeBackendPluginManager{},
+		PluginRequestValidator: fakeRequestValidator{},
+		HTTPClientProvider:     httpclient.NewProvider(),
+	}
+}
+
+func stepStatuses(result Result) []string {
+	statuses := make([]string, 0, len(result.Steps))
+	for _, step := range result.Steps {
+		statuses = append(statuses, step.Status)
+	}
+	return statuses
+}
+
+func stringPointer(s string) *string {
+	return &s
+}
+
+type fakeDataPlugin func(ds *models.DataSource, query plugins.DataQuery) plugins.DataResponse
+
+func (f fakeDataPlugin) DataQuery(_ context.Context, ds *models.DataSource, query plugins.DataQuery) (plugins.DataResponse, error) {
+	return f(ds, query), nil
+}
+
+type fakeBackendPluginManager struct {
+	backendplugin.Manager
+}
+
+func (fakeBackendPluginManager) GetDataPlugin(string) interface{} {
+	return nil
+}
+
+func (fakeBackendPluginManager) CheckHealth(context.Context, backend.PluginContext) (*backend.CheckHealthResult, error) {
+	return nil, backendplugin.ErrPluginNotRegistered
+}
+
+type fakeRequestValidator struct{}
+
+func (fakeRequestValidator) Validate(string, *http.Request) error {
+	return nil
+}
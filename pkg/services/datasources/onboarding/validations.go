@@ -0,0 +1,209 @@
+package onboarding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+type connectionKind int
+
+const (
+	connectionNone connectionKind = iota
+	connectionHTTP
+	connectionTCP
+)
+
+// validation is how the setup of a data source type is validated.
+type validation struct {
+	connection connectionKind
+	// defaultPort is the port of TCP connections when the host has none.
+	defaultPort string
+	settings    func(ds *models.DataSource) error
+	// sampleQuery returns the model of a cheap query which the data source
+	// runs with valid settings, or nil when there is none for its settings.
+	sampleQuery func(ds *models.DataSource) map[string]interface{}
+	permissions func(ctx context.Context, s *Service, user *models.SignedInUser, ds *models.DataSource) Step
+}
+
+var validations = map[string]validation{
+	"prometheus": {
+		connection:  connectionHTTP,
+		sampleQuery: staticQuery(map[string]interface{}{"expr": "1"}),
+		permissions: canReadLabels("/api/v1/labels"),
+	},
+	"loki": {
+		connection:  connectionHTTP,
+		sampleQuery: staticQuery(map[string]interface{}{"expr": `count_over_time({job=~".+"}[1m])`}),
+		permissions: canReadLabels("/loki/api/v1/label"),
+	},
+	"graphite": {
+		connection:  connectionHTTP,
+		sampleQuery: staticQuery(map[string]interface{}{"target": "constantLine(1)"}),
+	},
+	"influxdb": {
+		connection: connectionHTTP,
+		sampleQuery: func(ds *models.DataSource) map[string]interface{} {
+			if ds.JsonData != nil && ds.JsonData.Get("version").MustString() == "Flux" {
+				return nil
+			}
+			return map[string]interface{}{"query": "SHOW MEASUREMENTS LIMIT 1", "rawQuery": true, "resultFormat": "table"}
+		},
+	},
+	"elasticsearch": {
+		connection: connectionHTTP,
+		settings: func(ds *models.DataSource) error {
+			if ds.Database == "" {
+				return errors.New("the index name or pattern is required")
+			}
+			if ds.JsonData == nil || ds.JsonData.Get("timeField").MustString() == "" {
+				return errors.New("the time field name is required")
+			}
+			return nil
+		},
+	},
+	"opentsdb": {connection: connectionHTTP},
+	"tempo":    {connection: connectionHTTP},
+	"mysql": {
+		connection:  connectionTCP,
+		defaultPort: "3306",
+		settings:    requireDatabase,
+		sampleQuery: staticQuery(sqlQuery("SELECT 1")),
+		permissions: hasNoWritePrivileges(`SHOW GRANTS`,
+			regexp.MustCompile(`(?i)\b(ALL PRIVILEGES|INSERT|UPDATE|DELETE|DROP|CREATE|ALTER)\b`)),
+	},
+	"postgres": {
+		connection:  connectionTCP,
+		defaultPort: "5432",
+		settings:    requireDatabase,
+		sampleQuery: staticQuery(sqlQuery("SELECT 1")),
+		permissions: hasNoWritePrivileges(`SELECT 'SUPERUSER' AS privilege FROM pg_roles WHERE rolname = current_user AND rolsuper
+UNION ALL
+SELECT DISTINCT privilege_type AS privilege FROM information_schema.table_privileges
+WHERE grantee = current_user AND privilege_type IN ('INSERT', 'UPDATE', 'DELETE', 'TRUNCATE')`, nil),
+	},
+	"mssql": {
+		connection:  connectionTCP,
+		defaultPort: "1433",
+		settings:    requireDatabase,
+		sampleQuery: staticQuery(sqlQuery("SELECT 1")),
+		permissions: hasNoWritePrivileges(`SELECT permission_name AS privilege FROM fn_my_permissions(NULL, 'DATABASE')
+WHERE permission_name IN ('INSERT', 'UPDATE', 'DELETE', 'ALTER', 'CONTROL')`, nil),
+	},
+}
+
+func staticQuery(model map[string]interface{}) func(ds *models.DataSource) map[string]interface{} {
+	return func(*models.DataSource) map[string]interface{} {
+		return model
+	}
+}
+
+func sqlQuery(rawSQL string) map[string]interface{} {
+	return map[string]interface{}{"rawSql": rawSQL, "format": "table"}
+}
+
+func requireDatabase(ds *models.DataSource) error {
+	if ds.Database == "" {
+		return errors.New("the database name is required")
+	}
+	return nil
+}
+
+// tcpAddress returns the address of the host of a SQL data source, with the
+// default port of its type when it has none.
+func tcpAddress(ds *models.DataSource, v validation) string {
+	if _, _, err := net.SplitHostPort(ds.Url); err != nil && v.defaultPort != "" {
+		return net.JoinHostPort(ds.Url, v.defaultPort)
+	}
+	return ds.Url
+}
+
+// canReadLabels verifies that the credentials of the data source can read the
+// labels of an HTTP API, which the query editors need.
+func canReadLabels(path string) func(ctx context.Context, s *Service, user *models.SignedInUser, ds *models.DataSource) Step {
+	return func(ctx context.Context, s *Service, _ *models.SignedInUser, ds *models.DataSource) Step {
+		resp, err := s.get(ctx, ds, strings.TrimSuffix(ds.Url, "/")+path)
+		if err != nil {
+			return Step{Status: StatusError, Message: fmt.Sprintf("Failed to read the labels: %v", err)}
+		}
+		if err := resp.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "err", err)
+		}
+
+		details := map[string]interface{}{"path": path, "statusCode": resp.StatusCode}
+		switch {
+		case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+			return Step{Status: StatusError, Message: "The credentials are not allowed to read the labels", Details: details}
+		case resp.StatusCode >= 400:
+			return Step{Status: StatusWarning, Message: fmt.Sprintf("Reading the labels failed with status %d", resp.StatusCode), Details: details}
+		default:
+			return Step{Status: StatusOK, Message: "The credentials can read the labels", Details: details}
+		}
+	}
+}
+
+// hasNoWritePrivileges verifies that the user of a SQL data source can't
+// modify data, since the queries of dashboards run with its privileges. The
+// query returns the write privileges of the user, the rows matching the
+// pattern when there is one.
+func hasNoWritePrivileges(rawSQL string, pattern *regexp.Regexp) func(ctx context.Context, s *Service, user *models.SignedInUser, ds *models.DataSource) Step {
+	return func(ctx context.Context, s *Service, user *models.SignedInUser, ds *models.DataSource) Step {
+		frames, err := s.query(ctx, user, ds, sqlQuery(rawSQL))
+		if err != nil {
+			return Step{Status: StatusWarning, Message: fmt.Sprintf("Failed to read the privileges of the user: %v", err)}
+		}
+
+		privileges := writePrivileges(frames, pattern)
+		if len(privileges) > 0 {
+			return Step{
+				Status:  StatusWarning,
+				Message: "The user can modify data, use a user with read-only privileges, since the queries run with its privileges",
+				Details: map[string]interface{}{"privileges": privileges},
+			}
+		}
+		return Step{Status: StatusOK, Message: "The user has read-only privileges"}
+	}
+}
+
+func writePrivileges(frames data.Frames, pattern *regexp.Regexp) []string {
+	found := map[string]bool{}
+	for _, frame := range frames {
+		if frame == nil {
+			continue
+		}
+		for _, field := range frame.Fields {
+			for i := 0; i < field.Len(); i++ {
+				value, ok := field.ConcreteAt(i)
+				if !ok {
+					continue
+				}
+				s, ok := value.(string)
+				if !ok {
+					continue
+				}
+				if pattern == nil {
+					found[strings.ToUpper(s)] = true
+					continue
+				}
+				for _, match := range pattern.FindAllString(s, -1) {
+					found[strings.ToUpper(match)] = true
+				}
+			}
+		}
+	}
+
+	privileges := make([]string, 0, len(found))
+	for privilege := range found {
+		privileges = append(privileges, privilege)
+	}
+	sort.Strings(privileges)
+	return privileges
+}
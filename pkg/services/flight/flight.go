@@ -0,0 +1,334 @@
+// Package flight serves the data source query results over Apache Arrow
+// Flight, for data science tools to pull them as Arrow record batches
+// instead of JSON.
+package flight
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/apache/arrow/go/arrow/flight"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb"
+)
+
+var logger = log.New("arrow_flight")
+
+func init() {
+	registry.RegisterService(&Service{})
+}
+
+type userCtxKey struct{}
+
+// Ticket is the ticket of a DoGet call, the queries of a /api/ds/query
+// request. A Flight stream has a single schema, so a ticket selects one
+// frame of the results, by default the first frame of the first query.
+type Ticket struct {
+	From    string             `json:"from"`
+	To      string             `json:"to"`
+	Queries []*simplejson.Json `json:"queries"`
+	RefID   string             `json:"refId"`
+	Frame   int                `json:"frame"`
+}
+
+// Service is the Arrow Flight server, authenticated by the API keys sent as
+// bearer tokens in the authorization metadata of the calls.
+type Service struct {
+	Cfg                    *setting.Cfg                   `inject:""`
+	ContextHandler         *contexthandler.ContextHandler `inject:""`
+	DatasourceCache        datasources.CacheService       `inject:""`
+	DataService            *tsdb.Service                  `inject:""`
+	PluginRequestValidator models.PluginRequestValidator  `inject:""`
+}
+
+func (s *Service) Init() error {
+	return nil
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.Cfg.ArrowFlight.Enabled
+}
+
+func (s *Service) Run(ctx context.Context) error {
+	server, err := s.newServer()
+	if err != nil {
+		return err
+	}
+
+	lis, err := net.Listen("tcp", s.Cfg.ArrowFlight.Address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.Cfg.ArrowFlight.Address, err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		server.GracefulStop()
+	}()
+
+	logger.Info("Arrow Flight server started", "address", lis.Addr())
+	if err := server.Serve(lis); err != nil {
+		return fmt.Errorf("arrow Flight server failed: %w", err)
+	}
+	return ctx.Err()
+}
+
+func (s *Service) newServer() (*grpc.Server, error) {
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(s.authenticateUnary),
+		grpc.ChainStreamInterceptor(s.authenticateStream),
+	}
+	if s.Cfg.ArrowFlight.CertFile != "" {
+		creds, err := credentials.NewServerTLSFromFile(s.Cfg.ArrowFlight.CertFile, s.Cfg.ArrowFlight.CertKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the Arrow Flight TLS certificate: %w", err)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	server := grpc.NewServer(opts...)
+	flight.RegisterFlightServiceService(server, &flight.FlightServiceService{DoGet: s.doGet})
+	return server, nil
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *Service) authenticateUnary(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (s *Service) authenticateStream(srv interface{}, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authenticate(stream.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+}
+
+// authenticate returns the context with the signed in user of the API key of
+// the call, the user of an API key being its organization and role.
+func (s *Service) authenticate(ctx context.Context) (context.Context, error) {
+	var keyString string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			parts := strings.SplitN(values[0], " ", 2)
+			if len(parts) == 2 && strings.EqualFold(parts[0], "bearer") {
+				keyString = strings.TrimSpace(parts[1])
+			}
+		}
+	}
+	if keyString == "" {
+		return nil, status.Error(codes.Unauthenticated, "missing API key")
+	}
+
+	apikey, err := s.ContextHandler.ValidateAPIKey(keyString)
+	if err != nil {
+		switch {
+		case errors.Is(err, contexthandler.ErrInvalidAPIKey):
+			return nil, status.Error(codes.Unauthenticated, contexthandler.InvalidAPIKey)
+		case errors.Is(err, contexthandler.ErrExpiredAPIKey):
+			return nil, status.Error(codes.Unauthenticated, "expired API key")
+		}
+		logger.Error("Validating API key failed", "error", err)
+		return nil, status.Error(codes.Internal, "validating API key failed")
+	}
+
+	user := &models.SignedInUser{OrgId: apikey.OrgId, OrgRole: apikey.Role, ApiKeyId: apikey.Id}
+	return context.WithValue(ctx, userCtxKey{}, user), nil
+}
+
+func (s *Service) doGet(t *flight.Ticket, stream flight.FlightService_DoGetServer) error {
+	ctx := stream.Context()
+	user, ok := ctx.Value(userCtxKey{}).(*models.SignedInUser)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "missing API key")
+	}
+
+	var ticket Ticket
+	if err := json.Unmarshal(t.Ticket, &ticket); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid ticket: %s", err)
+	}
+
+	frame, err := s.query(ctx, user, ticket)
+	if err != nil {
+		return err
+	}
+	if rows, err := frame.RowLen(); err != nil {
+		return status.Errorf(codes.Internal, "invalid frame: %s", err)
+	} else if maxRows := s.Cfg.ArrowFlight.MaxRows; maxRows > 0 && rows > maxRows {
+		return status.Errorf(codes.ResourceExhausted, "the frame has %d rows, more than the limit of %d", rows, maxRows)
+	}
+
+	return writeFrame(stream, frame)
+}
+
+// query runs the queries of the ticket like /api/ds/query, and returns the
+// frame it selects.
+func (s *Service) query(ctx context.Context, user *models.SignedInUser, ticket Ticket) (*data.Frame, error) {
+	if len(ticket.Queries) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no queries found in the ticket")
+	}
+
+	for i, query := range ticket.Queries {
+		if !models.IsSavedQueryReference(query) {
+			continue
+		}
+
+		resolveQuery := &models.ResolveSavedQueryQuery{OrgId: user.OrgId, SignedInUser: user, Query: query}
+		if err := bus.DispatchCtx(ctx, resolveQuery); err != nil {
+			switch {
+			case errors.Is(err, models.ErrSavedQueryNotFound):
+				return nil, status.Error(codes.NotFound, err.Error())
+			case errors.Is(err, models.ErrFolderAccessDenied):
+				return nil, status.Error(codes.PermissionDenied, "access denied to saved query")
+			case errors.Is(err, models.ErrSavedQueryInvalidVariables):
+				return nil, status.Error(codes.InvalidArgument, err.Error())
+			}
+			logger.Error("Failed to resolve saved query", "error", err)
+			return nil, status.Error(codes.Internal, "failed to resolve saved query")
+		}
+		ticket.Queries[i] = resolveQuery.Result
+	}
+
+	timeRange, err := plugins.NewDataTimeRangeForUser(ctx, ticket.From, ticket.To, user)
+	if err != nil {
+		logger.Warn("Failed to get the preferences of the time range", "error", err)
+	}
+	request := plugins.DataQuery{
+		TimeRange: &timeRange,
+		User:      user,
+		Queries:   make([]plugins.DataSubQuery, 0, len(ticket.Queries)),
+	}
+
+	var ds *models.DataSource
+	for i, query := range ticket.Queries {
+		if query.Get("datasource").MustString("") == expr.DatasourceName {
+			return nil, status.Error(codes.InvalidArgument, "expressions aren't supported")
+		}
+
+		datasourceID, err := query.Get("datasourceId").Int64()
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "query missing data source ID")
+		}
+
+		// the queries are of the data source of the first query, like
+		// /api/ds/query
+		if i == 0 {
+			ds, err = s.DatasourceCache.GetDatasource(datasourceID, user, false)
+			if err != nil {
+				if errors.Is(err, models.ErrDataSourceAccessDenied) {
+					return nil, status.Error(codes.PermissionDenied, "access denied to data source")
+				}
+				if errors.Is(err, models.ErrDataSourceNotFound) {
+					return nil, status.Error(codes.NotFound, "data source not found")
+				}
+				logger.Error("Unable to load data source metadata", "error", err)
+				return nil, status.Error(codes.Internal, "unable to load data source metadata")
+			}
+		}
+
+		request.Queries = append(request.Queries, plugins.DataSubQuery{
+			RefID:         query.Get("refId").MustString("A"),
+			MaxDataPoints: query.Get("maxDataPoints").MustInt64(100),
+			IntervalMS:    query.Get("intervalMs").MustInt64(1000),
+			QueryType:     query.Get("queryType").MustString(""),
+			Model:         query,
+			DataSource:    ds,
+		})
+	}
+
+	if err := s.PluginRequestValidator.Validate(ds.Url, nil); err != nil {
+		return nil, status.Error(codes.PermissionDenied, "access denied")
+	}
+
+	resp, err := s.DataService.HandleRequest(ctx, ds, request)
+	if err != nil {
+		logger.Error("Metric request error", "error", err)
+		return nil, status.Error(codes.Internal, "metric request error")
+	}
+	qdr, err := resp.ToBackendDataResponse()
+	if err != nil {
+		logger.Error("Error converting results", "error", err)
+		return nil, status.Error(codes.Internal, "error converting results")
+	}
+
+	refID := ticket.RefID
+	if refID == "" {
+		refID = request.Queries[0].RefID
+	}
+	res, ok := qdr.Responses[refID]
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "no query with ref ID %q", refID)
+	}
+	if res.Error != nil {
+		return nil, status.Errorf(codes.Unknown, "query %s failed: %s", refID, res.Error)
+	}
+	if ticket.Frame < 0 || ticket.Frame >= len(res.Frames) {
+		return nil, status.Errorf(codes.InvalidArgument, "query %s returned %d frames, no frame %d", refID, len(res.Frames), ticket.Frame)
+	}
+	return res.Frames[ticket.Frame], nil
+}
+
+// writeFrame streams the frame as Arrow record batches. The frame is
+// marshaled with the frame metadata in its schema, the way the frontend
+// reads it.
+func writeFrame(stream flight.FlightService_DoGetServer, frame *data.Frame) error {
+	b, err := frame.MarshalArrow()
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to marshal frame: %s", err)
+	}
+
+	reader, err := ipc.NewFileReader(bytes.NewReader(b))
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to read frame: %s", err)
+	}
+	defer func() {
+		if err := reader.Close(); err != nil {
+			logger.Warn("Failed to close frame reader", "error", err)
+		}
+	}()
+
+	writer := flight.NewRecordWriter(stream, ipc.WithSchema(reader.Schema()))
+	for i := 0; i < reader.NumRecords(); i++ {
+		rec, err := reader.Record(i)
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to read frame: %s", err)
+		}
+		if err := writer.Write(rec); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
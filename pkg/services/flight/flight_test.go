@@ -0,0 +1,252 @@
+package flight
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/apache/arrow/go/arrow/flight"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/apikeygen"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/plugins/manager"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb"
+)
+
+type fakeDatasourceCache struct {
+	ds *models.DataSource
+}
+
+func (c *fakeDatasourceCache) GetDatasource(datasourceID int64, user *models.SignedInUser, skipCache bool) (*models.DataSource, error) {
+	if datasourceID != c.ds.Id || user.OrgId != c.ds.OrgId {
+		return nil, models.ErrDataSourceNotFound
+	}
+	return c.ds, nil
+}
+
+func (c *fakeDatasourceCache) GetDatasourceByUID(datasourceUID string, user *models.SignedInUser, skipCache bool) (*models.DataSource, error) {
+	return nil, models.ErrDataSourceNotFound
+}
+
+type fakePluginRequestValidator struct{}
+
+func (fakePluginRequestValidator) Validate(string, *http.Request) error {
+	return nil
+}
+
+type fakeBackendPM struct {
+	backendplugin.Manager
+}
+
+func (pm fakeBackendPM) GetDataPlugin(string) interface{} {
+	return nil
+}
+
+// nolint: staticcheck // plugins.DataPlugin deprecated
+type fakeDataPlugin struct {
+	queries []plugins.DataQuery
+}
+
+// nolint: staticcheck // plugins.DataPlugin deprecated
+func (p *fakeDataPlugin) DataQuery(ctx context.Context, ds *models.DataSource, query plugins.DataQuery) (plugins.DataResponse, error) {
+	p.queries = append(p.queries, query)
+
+	frame := data.NewFrame("cpu",
+		data.NewField("time", nil, []int64{1, 2, 3}),
+		data.NewField("value", data.Labels{"host": "a"}, []float64{0.5, 0.75, 1}),
+	)
+	return plugins.DataResponse{
+		Results: map[string]plugins.DataQueryResult{
+			"A": {RefID: "A", Dataframes: plugins.NewDecodedDataFrames(data.Frames{frame})},
+		},
+	}, nil
+}
+
+func setupClient(t *testing.T, cfg *setting.Cfg) (flight.FlightServiceClient, *fakeDataPlugin, string) {
+	t.Helper()
+
+	key, err := apikeygen.New(1, "flight")
+	require.NoError(t, err)
+	bus.AddHandler("test", func(query *models.GetApiKeyByNameQuery) error {
+		if query.KeyName != "flight" || query.OrgId != 1 {
+			return models.ErrInvalidApiKey
+		}
+		query.Result = &models.ApiKey{Id: 1, OrgId: 1, Name: "flight", Key: key.HashedKey, Role: models.ROLE_VIEWER}
+		return nil
+	})
+	t.Cleanup(bus.ClearBusHandlers)
+
+	plugin := &fakeDataPlugin{}
+	dataService := tsdb.NewService()
+	dataService.PluginManager = &manager.PluginManager{BackendPluginManager: fakeBackendPM{}}
+	//nolint: staticcheck // plugins.DataPlugin deprecated
+	dataService.RegisterQueryHandler("test", func(*models.DataSource) (plugins.DataPlugin, error) {
+		return plugin, nil
+	})
+
+	s := &Service{
+		Cfg:                    cfg,
+		ContextHandler:         &contexthandler.ContextHandler{Cfg: cfg},
+		DatasourceCache:        &fakeDatasourceCache{ds: &models.DataSource{Id: 1, OrgId: 1, Type: "test"}},
+		DataService:            &dataService,
+		PluginRequestValidator: fakePluginRequestValidator{},
+	}
+	server, err := s.newServer()
+	require.NoError(t, err)
+
+	lis := bufconn.Listen(1024 * 1024)
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return lis.Dial()
+	}))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return flight.NewFlightServiceClient(conn), plugin, key.ClientSecret
+}
+
+// streamResult is the schema and number of rows of a DoGet stream.
+type streamResult struct {
+	name   string
+	fields []string
+	rows   int64
+}
+
+func doGet(t *testing.T, client flight.FlightServiceClient, apiKey string, ticket Ticket) (*streamResult, error) {
+	t.Helper()
+
+	b, err := json.Marshal(ticket)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	if apiKey != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+apiKey)
+	}
+	stream, err := client.DoGet(ctx, &flight.Ticket{Ticket: b})
+	require.NoError(t, err)
+
+	reader, err := flight.NewRecordReader(stream)
+	if err != nil {
+		// the reader wraps the status of the call
+		if _, recvErr := stream.Recv(); recvErr != nil {
+			return nil, recvErr
+		}
+		return nil, err
+	}
+	defer reader.Release()
+
+	schema := reader.Schema()
+	result := &streamResult{}
+	if i := schema.Metadata().FindKey("name"); i >= 0 {
+		result.name = schema.Metadata().Values()[i]
+	}
+	for _, field := range schema.Fields() {
+		result.fields = append(result.fields, field.Name)
+	}
+	for reader.Next() {
+		result.rows += reader.Record().NumRows()
+	}
+	return result, reader.Err()
+}
+
+func testTicket() Ticket {
+	return Ticket{
+		From:    "now-1h",
+		To:      "now",
+		Queries: []*simplejson.Json{simplejson.NewFromAny(map[string]interface{}{"refId": "A", "datasourceId": 1})},
+	}
+}
+
+func TestService(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.ArrowFlight = setting.ArrowFlightSettings{Enabled: true, MaxRows: 1000}
+
+	t.Run("Rejects the calls without an API key", func(t *testing.T) {
+		client, _, _ := setupClient(t, cfg)
+
+		_, err := doGet(t, client, "", testTicket())
+		require.Error(t, err)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("Rejects the calls with an invalid API key", func(t *testing.T) {
+		client, _, _ := setupClient(t, cfg)
+
+		invalid, err := apikeygen.New(1, "other")
+		require.NoError(t, err)
+		_, err = doGet(t, client, invalid.ClientSecret, testTicket())
+		require.Error(t, err)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("Streams the frame of the query", func(t *testing.T) {
+		client, plugin, apiKey := setupClient(t, cfg)
+
+		result, err := doGet(t, client, apiKey, testTicket())
+		require.NoError(t, err)
+		assert.Equal(t, &streamResult{name: "cpu", fields: []string{"time", "value"}, rows: 3}, result)
+
+		require.Len(t, plugin.queries, 1)
+		assert.Equal(t, int64(1), plugin.queries[0].User.OrgId)
+		assert.Equal(t, models.ROLE_VIEWER, plugin.queries[0].User.OrgRole)
+		assert.Equal(t, "A", plugin.queries[0].Queries[0].RefID)
+	})
+
+	t.Run("Rejects the tickets selecting a missing frame", func(t *testing.T) {
+		client, _, apiKey := setupClient(t, cfg)
+
+		ticket := testTicket()
+		ticket.RefID = "B"
+		_, err := doGet(t, client, apiKey, ticket)
+		require.Error(t, err)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		ticket = testTicket()
+		ticket.Frame = 1
+		_, err = doGet(t, client, apiKey, ticket)
+		require.Error(t, err)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Rejects the frames over the row limit", func(t *testing.T) {
+		limited := setting.NewCfg()
+		limited.ArrowFlight = setting.ArrowFlightSettings{Enabled: true, MaxRows: 2}
+		client, _, apiKey := setupClient(t, limited)
+
+		_, err := doGet(t, client, apiKey, testTicket())
+		require.Error(t, err)
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	})
+
+	t.Run("Rejects the expressions", func(t *testing.T) {
+		client, _, apiKey := setupClient(t, cfg)
+
+		ticket := testTicket()
+		ticket.Queries = append(ticket.Queries, simplejson.NewFromAny(map[string]interface{}{"refId": "B", "datasource": "__expr__"}))
+		_, err := doGet(t, client, apiKey, ticket)
+		require.Error(t, err)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
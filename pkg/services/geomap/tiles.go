@@ -0,0 +1,192 @@
+// Package geomap proxies the tile servers configured for geomap panels, so
+// that their API keys stay on the server.
+package geomap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var (
+	ErrTileServerNotFound = errors.New("tile server not found")
+	ErrInvalidTile        = errors.New("invalid tile coordinates")
+	ErrTileNotFound       = errors.New("tile not found")
+)
+
+var logger = log.New("geomap")
+
+const (
+	// tiles are small, anything larger is most likely not a tile
+	maxTileSize = 5 << 20
+	// only keep reasonably sized tiles in memory
+	maxCachedTileSize = 512 << 10
+)
+
+func init() {
+	registry.RegisterService(&TileProxy{})
+}
+
+// Tile is the content of a map tile.
+type Tile struct {
+	ContentType string
+	Data        []byte
+}
+
+// TileServer is the configuration of a tile server that is safe to expose to
+// browsers.
+type TileServer struct {
+	Name        string `json:"name"`
+	URL         string `json:"url"`
+	Attribution string `json:"attribution"`
+	MaxZoom     int    `json:"maxZoom"`
+}
+
+// TileProxy fetches tiles from the configured tile servers, adding the API
+// key to the request and caching the response.
+type TileProxy struct {
+	Cfg          *setting.Cfg             `inject:""`
+	CacheService *localcache.CacheService `inject:""`
+
+	client *http.Client
+}
+
+func (p *TileProxy) Init() error {
+	p.client = &http.Client{Timeout: 30 * time.Second}
+	return nil
+}
+
+// TileServers returns the configured tile servers, with URLs pointing at the
+// proxy endpoint.
+func (p *TileProxy) TileServers() []TileServer {
+	servers := make([]TileServer, 0, len(p.Cfg.Geomap.TileServers))
+	for name, server := range p.Cfg.Geomap.TileServers {
+		servers = append(servers, TileServer{
+			Name:        name,
+			URL:         fmt.Sprintf("%s/api/geomap/tiles/%s/{z}/{x}/{y}", p.Cfg.AppSubURL, url.PathEscape(name)),
+			Attribution: server.Attribution,
+			MaxZoom:     server.MaxZoom,
+		})
+	}
+
+	sort.Slice(servers, func(i, j int) bool {
+		return servers[i].Name < servers[j].Name
+	})
+	return servers
+}
+
+// GetTile returns a tile of a configured tile server.
+func (p *TileProxy) GetTile(ctx context.Context, serverName string, z, x, y int) (*Tile, error) {
+	server, ok := p.Cfg.Geomap.TileServers[serverName]
+	if !ok {
+		return nil, ErrTileServerNotFound
+	}
+
+	if z < 0 || z > server.MaxZoom || z > 30 || x < 0 || y < 0 || x >= 1<<z || y >= 1<<z {
+		return nil, ErrInvalidTile
+	}
+
+	cacheKey := fmt.Sprintf("geomap-tile-%s-%d-%d-%d", serverName, z, x, y)
+	if cached, found := p.CacheService.Get(cacheKey); found {
+		return cached.(*Tile), nil
+	}
+
+	tile, err := p.fetchTile(ctx, server, z, x, y)
+	if err != nil {
+		return nil, err
+	}
+
+	if ttl := p.Cfg.Geomap.TileCacheTTL; ttl > 0 && len(tile.Data) <= maxCachedTileSize {
+		p.CacheService.Set(cacheKey, tile, ttl)
+	}
+
+	return tile, nil
+}
+
+func (p *TileProxy) fetchTile(ctx context.Context, server setting.GeomapTileServer, z, x, y int) (*Tile, error) {
+	tileURL := strings.NewReplacer(
+		"{z}", strconv.Itoa(z),
+		"{x}", strconv.Itoa(x),
+		"{y}", strconv.Itoa(y),
+	).Replace(server.URL)
+
+	u, err := url.Parse(tileURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url of tile server %q: %w", server.Name, err)
+	}
+
+	if server.APIKey != "" && server.APIKeyParam != "" {
+		query := u.Query()
+		query.Set(server.APIKeyParam, server.APIKey)
+		u.RawQuery = query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Grafana/"+setting.BuildVersion)
+	if server.APIKey != "" && server.APIKeyHeader != "" {
+		req.Header.Set(server.APIKeyHeader, server.APIKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		// the error contains the url, which may contain the api key
+		return nil, fmt.Errorf("failed to fetch tile from tile server %q", server.Name)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logger.Warn("Failed to close tile response body", "err", err)
+		}
+	}()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrTileNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("tile server %q responded with status %d", server.Name, resp.StatusCode)
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxTileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxTileSize {
+		return nil, fmt.Errorf("tile of tile server %q is too large", server.Name)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	// tiles are served from Grafana's origin, never pass through content that a browser would render as a page
+	if !isTileContentType(contentType) {
+		return nil, fmt.Errorf("tile server %q responded with unexpected content type %q", server.Name, contentType)
+	}
+
+	return &Tile{ContentType: contentType, Data: data}, nil
+}
+
+func isTileContentType(contentType string) bool {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	switch mediaType {
+	case "image/png", "image/jpeg", "image/webp", "image/gif",
+		"application/x-protobuf", "application/vnd.mapbox-vector-tile", "application/octet-stream":
+		return true
+	}
+	return false
+}
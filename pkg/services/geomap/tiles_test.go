@@ -0,0 +1,96 @@
+package geomap
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var pngTile = []byte("\x89PNG\x0D\x0A\x1A\x0A\x00\x00\x00\x0DIHDR")
+
+func setupTileProxy(t *testing.T, servers ...setting.GeomapTileServer) *TileProxy {
+	t.Helper()
+
+	cfg := setting.NewCfg()
+	cfg.Geomap = setting.GeomapSettings{
+		TileCacheTTL: time.Hour,
+		TileServers:  map[string]setting.GeomapTileServer{},
+	}
+	for _, server := range servers {
+		cfg.Geomap.TileServers[server.Name] = server
+	}
+
+	proxy := &TileProxy{Cfg: cfg, CacheService: localcache.New(time.Hour, time.Hour)}
+	require.NoError(t, proxy.Init())
+	return proxy
+}
+
+func TestTileProxy(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Tiles are fetched with the api key and cached", func(t *testing.T) {
+		requests := 0
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			assert.Equal(t, "/tiles/3/2/1.png", r.URL.Path)
+			assert.Equal(t, "secret", r.URL.Query().Get("key"))
+			assert.Equal(t, "secret", r.Header.Get("X-Api-Key"))
+			w.Header().Set("Content-Type", "image/png")
+			_, err := w.Write(pngTile)
+			require.NoError(t, err)
+		}))
+		defer ts.Close()
+
+		proxy := setupTileProxy(t, setting.GeomapTileServer{
+			Name:         "streets",
+			URL:          ts.URL + "/tiles/{z}/{x}/{y}.png",
+			APIKey:       "secret",
+			APIKeyParam:  "key",
+			APIKeyHeader: "X-Api-Key",
+			MaxZoom:      18,
+		})
+
+		for i := 0; i < 2; i++ {
+			tile, err := proxy.GetTile(ctx, "streets", 3, 2, 1)
+			require.NoError(t, err)
+			assert.Equal(t, "image/png", tile.ContentType)
+			assert.Equal(t, pngTile, tile.Data)
+		}
+		assert.Equal(t, 1, requests)
+
+		servers := proxy.TileServers()
+		require.Len(t, servers, 1)
+		assert.Equal(t, "/api/geomap/tiles/streets/{z}/{x}/{y}", servers[0].URL)
+		assert.NotContains(t, servers[0].URL, "secret")
+	})
+
+	t.Run("Invalid tiles are rejected", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html")
+			_, err := w.Write([]byte("<html></html>"))
+			require.NoError(t, err)
+		}))
+		defer ts.Close()
+
+		proxy := setupTileProxy(t, setting.GeomapTileServer{Name: "html", URL: ts.URL + "/{z}/{x}/{y}", MaxZoom: 5})
+
+		_, err := proxy.GetTile(ctx, "unknown", 0, 0, 0)
+		assert.ErrorIs(t, err, ErrTileServerNotFound)
+
+		_, err = proxy.GetTile(ctx, "html", 6, 0, 0)
+		assert.ErrorIs(t, err, ErrInvalidTile)
+
+		_, err = proxy.GetTile(ctx, "html", 1, 2, 0)
+		assert.ErrorIs(t, err, ErrInvalidTile)
+
+		_, err = proxy.GetTile(ctx, "html", 1, 1, 1)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,27 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+type queryCommand struct {
+	Query         string                 `json:"query" binding:"Required"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+func (s *Service) registerAPIEndpoints() {
+	s.RouteRegister.Post("/api/graphql", middleware.ReqSignedIn, routing.Bind(queryCommand{}), routing.Wrap(s.queryHandler))
+}
+
+// queryHandler handles POST /api/graphql. The errors of the query are in the
+// errors of the response, with the data of the fields without errors.
+func (s *Service) queryHandler(c *models.ReqContext, cmd queryCommand) response.Response {
+	ctx := context.WithValue(c.Req.Context(), userCtxKey{}, c.SignedInUser)
+	return response.JSON(200, s.schema.Exec(ctx, cmd.Query, cmd.OperationName, cmd.Variables))
+}
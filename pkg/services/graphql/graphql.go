@@ -0,0 +1,170 @@
+// Package graphql serves an experimental read-only GraphQL API, for
+// integrators to fetch the dashboards, folders, data sources, users, teams
+// and alert rules with their nested resources in one request.
+package graphql
+
+import (
+	"context"
+	"errors"
+
+	"github.com/graph-gophers/graphql-go"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/search"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// the nesting and the concurrent resolvers of a query are limited, each
+	// nested list being another round of queries
+	maxDepth       = 6
+	maxParallelism = 10
+)
+
+func init() {
+	registry.RegisterService(&Service{})
+}
+
+// Service is the GraphQL API, enabled by the graphql feature toggle.
+type Service struct {
+	Cfg           *setting.Cfg          `inject:""`
+	SQLStore      *sqlstore.SQLStore    `inject:""`
+	RouteRegister routing.RouteRegister `inject:""`
+
+	log    log.Logger
+	schema *graphql.Schema
+}
+
+func (s *Service) Init() error {
+	s.log = log.New("graphql")
+
+	if !s.Cfg.IsGraphQLEnabled() {
+		return nil
+	}
+
+	schema, err := parseSchema(s)
+	if err != nil {
+		return errors.New("invalid GraphQL schema: " + err.Error())
+	}
+	s.schema = schema
+
+	s.registerAPIEndpoints()
+	return nil
+}
+
+func parseSchema(s *Service) (*graphql.Schema, error) {
+	return graphql.ParseSchema(schema, &queryResolver{s: s}, graphql.MaxDepth(maxDepth), graphql.MaxParallelism(maxParallelism))
+}
+
+// getFolder returns the folder of the UID, or nil when it doesn't exist.
+func (s *Service) getFolder(user *models.SignedInUser, uid string) (*models.Folder, error) {
+	folder, err := dashboards.NewFolderService(user.OrgId, user, s.SQLStore).GetFolderByUID(uid)
+	switch {
+	case errors.Is(err, models.ErrFolderNotFound):
+		return nil, nil
+	case errors.Is(err, models.ErrFolderAccessDenied):
+		return nil, errAccessDenied
+	}
+	return folder, err
+}
+
+// searchDashboards returns the dashboards of the search, which only returns
+// the dashboards the user can view.
+func (s *Service) searchDashboards(ctx context.Context, query search.Query) ([]*dashboardResolver, error) {
+	if err := bus.Dispatch(&query); err != nil {
+		return nil, err
+	}
+	if len(query.Result) == 0 {
+		return []*dashboardResolver{}, nil
+	}
+
+	ids := make([]int64, 0, len(query.Result))
+	for _, hit := range query.Result {
+		ids = append(ids, hit.ID)
+	}
+	dashQuery := models.GetDashboardsQuery{DashboardIds: ids}
+	if err := bus.Dispatch(&dashQuery); err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int64]*models.Dashboard, len(dashQuery.Result))
+	for _, dash := range dashQuery.Result {
+		byID[dash.Id] = dash
+	}
+	result := make([]*dashboardResolver, 0, len(ids))
+	for _, id := range ids {
+		if dash, ok := byID[id]; ok {
+			result = append(result, &dashboardResolver{s: s, dash: dash})
+		}
+	}
+	return result, nil
+}
+
+// getDataSources returns the data sources of the organization the user can
+// query.
+func (s *Service) getDataSources(ctx context.Context) ([]*datasourceResolver, error) {
+	user := signedInUser(ctx)
+	query := models.GetDataSourcesQuery{OrgId: user.OrgId, DataSourceLimit: s.Cfg.DataSourceLimit}
+	if err := bus.Dispatch(&query); err != nil {
+		return nil, err
+	}
+
+	datasources := query.Result
+	if len(datasources) > 0 {
+		filterQuery := models.DatasourcesPermissionFilterQuery{User: user, Datasources: datasources}
+		if err := bus.Dispatch(&filterQuery); err != nil {
+			if !errors.Is(err, bus.ErrHandlerNotFound) {
+				return nil, err
+			}
+		} else {
+			datasources = filterQuery.Result
+		}
+	}
+
+	result := make([]*datasourceResolver, 0, len(datasources))
+	for _, ds := range datasources {
+		result = append(result, &datasourceResolver{isAdmin: user.OrgRole == models.ROLE_ADMIN, ds: ds})
+	}
+	return result, nil
+}
+
+func (s *Service) getAlertRules(ctx context.Context, query models.GetAlertsQuery) ([]*alertRuleResolver, error) {
+	if err := bus.Dispatch(&query); err != nil {
+		return nil, err
+	}
+
+	result := make([]*alertRuleResolver, 0, len(query.Result))
+	for _, alert := range query.Result {
+		alert.Url = models.GetDashboardUrl(alert.DashboardUid, alert.DashboardSlug)
+		result = append(result, &alertRuleResolver{s: s, alert: alert})
+	}
+	return result, nil
+}
+
+// isOrgAdminFolderAdminOrTeamAdmin returns whether the user can look up the
+// users of the organization, like the org users lookup endpoint.
+func isOrgAdminFolderAdminOrTeamAdmin(ctx context.Context, user *models.SignedInUser) (bool, error) {
+	if user.OrgRole == models.ROLE_ADMIN {
+		return true, nil
+	}
+
+	foldersQuery := models.HasAdminPermissionInFoldersQuery{SignedInUser: user}
+	if err := bus.Dispatch(&foldersQuery); err != nil {
+		return false, err
+	}
+	if foldersQuery.Result {
+		return true, nil
+	}
+
+	teamsQuery := models.IsAdminOfTeamsQuery{SignedInUser: user}
+	if err := bus.Dispatch(&teamsQuery); err != nil {
+		return false, err
+	}
+	return teamsQuery.Result, nil
+}
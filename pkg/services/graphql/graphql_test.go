@@ -0,0 +1,234 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/macaron.v1"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/search"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type queryResult struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []struct {
+		Message string        `json:"message"`
+		Path    []interface{} `json:"path"`
+	} `json:"errors"`
+}
+
+type scenarioContext struct {
+	service *Service
+	admin   models.SignedInUser
+	viewer  models.SignedInUser
+}
+
+func TestGraphQL(t *testing.T) {
+	testScenario(t, "Resolves the nested resources in one query", func(t *testing.T, sc scenarioContext) {
+		result := sc.query(t, &sc.admin, `{
+			dashboards(tags: ["prod"]) {
+				title
+				tags
+				folder { title dashboards { title } }
+				alertRules { name state }
+			}
+			datasources { name type url }
+			users { login email role teams { name } }
+			teams { name memberCount members { login } }
+		}`)
+		require.Empty(t, result.Errors)
+
+		assert.Equal(t, []interface{}{map[string]interface{}{
+			"title": "Latency",
+			"tags":  []interface{}{"prod"},
+			"folder": map[string]interface{}{
+				"title":      "Ops",
+				"dashboards": []interface{}{map[string]interface{}{"title": "Latency"}},
+			},
+			"alertRules": []interface{}{map[string]interface{}{"name": "High latency", "state": "unknown"}},
+		}}, result.Data["dashboards"])
+		assert.Equal(t, []interface{}{map[string]interface{}{
+			"name": "Prometheus", "type": "prometheus", "url": "http://prometheus:9090",
+		}}, result.Data["datasources"])
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"login": "admin", "email": "admin@example.com", "role": "Admin", "teams": []interface{}{}},
+			map[string]interface{}{"login": "viewer", "email": "viewer@example.com", "role": "Viewer", "teams": []interface{}{
+				map[string]interface{}{"name": "SRE"},
+			}},
+		}, result.Data["users"])
+		assert.Equal(t, []interface{}{map[string]interface{}{
+			"name": "SRE", "memberCount": float64(1), "members": []interface{}{map[string]interface{}{"login": "viewer"}},
+		}}, result.Data["teams"])
+	})
+
+	testScenario(t, "Returns access denied errors for the fields the user can't read", func(t *testing.T, sc scenarioContext) {
+		result := sc.query(t, &sc.viewer, `{
+			datasources { name url }
+			users { login }
+			teams { name members { login } }
+		}`)
+
+		assert.Equal(t, []interface{}{map[string]interface{}{"name": "Prometheus", "url": nil}}, result.Data["datasources"])
+		assert.Nil(t, result.Data["users"])
+		assert.Equal(t, []interface{}{map[string]interface{}{"name": "SRE", "members": nil}}, result.Data["teams"])
+
+		paths := map[string]string{}
+		for _, err := range result.Errors {
+			b, jsonErr := json.Marshal(err.Path)
+			require.NoError(t, jsonErr)
+			paths[string(b)] = err.Message
+		}
+		assert.Equal(t, map[string]string{
+			`["datasources",0,"url"]`: "access denied",
+			`["users"]`:               "access denied",
+			`["teams",0,"members"]`:   "access denied",
+		}, paths)
+	})
+
+	testScenario(t, "Returns the personal fields of the user to themselves", func(t *testing.T, sc scenarioContext) {
+		result := sc.query(t, &sc.admin, `{ teams { members { login } } }`)
+		require.Empty(t, result.Errors)
+
+		result = sc.query(t, &sc.viewer, `{ dashboard(uid: "latency") { title folder { title } } }`)
+		require.Empty(t, result.Errors)
+		assert.Equal(t, map[string]interface{}{
+			"title": "Latency", "folder": map[string]interface{}{"title": "Ops"},
+		}, result.Data["dashboard"])
+
+		sc.service.Cfg.EditorsCanAdmin = true
+		result = sc.query(t, &sc.viewer, `{ teams { members { login email } } }`)
+		require.Empty(t, result.Errors)
+		assert.Equal(t, []interface{}{map[string]interface{}{"members": []interface{}{
+			map[string]interface{}{"login": "viewer", "email": "viewer@example.com"},
+		}}}, result.Data["teams"])
+	})
+
+	testScenario(t, "Returns null for the missing resources", func(t *testing.T, sc scenarioContext) {
+		result := sc.query(t, &sc.admin, `{ dashboard(uid: "missing") { title } folder(uid: "missing") { title } }`)
+		require.Empty(t, result.Errors)
+		assert.Nil(t, result.Data["dashboard"])
+		assert.Nil(t, result.Data["folder"])
+	})
+
+	testScenario(t, "Rejects the mutations", func(t *testing.T, sc scenarioContext) {
+		result := sc.query(t, &sc.admin, `mutation { deleteDashboard(uid: "latency") }`)
+		require.NotEmpty(t, result.Errors)
+		assert.Nil(t, result.Data)
+	})
+}
+
+func (sc scenarioContext) query(t *testing.T, user *models.SignedInUser, query string) queryResult {
+	t.Helper()
+
+	c := &models.ReqContext{
+		Context:      &macaron.Context{Req: macaron.Request{Request: &http.Request{}}},
+		SignedInUser: user,
+	}
+	resp := sc.service.queryHandler(c, queryCommand{Query: query})
+	require.Equal(t, 200, resp.Status())
+
+	var result queryResult
+	require.NoError(t, json.Unmarshal(resp.(*response.NormalResponse).Body(), &result))
+	return result
+}
+
+func testScenario(t *testing.T, desc string, fn func(t *testing.T, sc scenarioContext)) {
+	t.Helper()
+
+	t.Run(desc, func(t *testing.T) {
+		sqlStore := sqlstore.InitTestDB(t)
+		cfg := setting.NewCfg()
+		cfg.FeatureToggles = map[string]bool{"graphql": true}
+
+		searchService := &search.SearchService{Bus: bus.GetBus(), Cfg: cfg}
+		require.NoError(t, searchService.Init())
+
+		service := &Service{Cfg: cfg, SQLStore: sqlStore, log: log.New("graphql")}
+		schema, err := parseSchema(service)
+		require.NoError(t, err)
+		service.schema = schema
+
+		ctx := context.Background()
+		admin, err := sqlStore.CreateUser(ctx, models.CreateUserCommand{Login: "admin", Email: "admin@example.com"})
+		require.NoError(t, err)
+		viewer, err := sqlStore.CreateUser(ctx, models.CreateUserCommand{Login: "viewer", Email: "viewer@example.com", SkipOrgSetup: true})
+		require.NoError(t, err)
+		require.NoError(t, bus.Dispatch(&models.AddOrgUserCommand{OrgId: admin.OrgId, UserId: viewer.Id, Role: models.ROLE_VIEWER}))
+
+		team, err := sqlStore.CreateTeam("SRE", "", admin.OrgId)
+		require.NoError(t, err)
+		require.NoError(t, sqlStore.AddTeamMember(viewer.Id, admin.OrgId, team.Id, false, 0))
+
+		folder, err := sqlStore.SaveDashboard(models.SaveDashboardCommand{
+			OrgId:    admin.OrgId,
+			IsFolder: true,
+			Dashboard: simplejson.NewFromAny(map[string]interface{}{
+				"uid":   "ops",
+				"title": "Ops",
+			}),
+		})
+		require.NoError(t, err)
+		dash, err := sqlStore.SaveDashboard(models.SaveDashboardCommand{
+			OrgId:    admin.OrgId,
+			FolderId: folder.Id,
+			Dashboard: simplejson.NewFromAny(map[string]interface{}{
+				"uid":   "latency",
+				"title": "Latency",
+				"tags":  []interface{}{"prod"},
+			}),
+		})
+		require.NoError(t, err)
+		_, err = sqlStore.SaveDashboard(models.SaveDashboardCommand{
+			OrgId:     admin.OrgId,
+			Dashboard: simplejson.NewFromAny(map[string]interface{}{"uid": "home", "title": "Home"}),
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, bus.Dispatch(&models.SaveAlertsCommand{
+			DashboardId: dash.Id,
+			OrgId:       admin.OrgId,
+			Alerts: []*models.Alert{{
+				DashboardId: dash.Id,
+				OrgId:       admin.OrgId,
+				PanelId:     1,
+				Name:        "High latency",
+				Settings:    simplejson.New(),
+			}},
+		}))
+
+		require.NoError(t, bus.Dispatch(&models.AddDataSourceCommand{
+			OrgId:  admin.OrgId,
+			Name:   "Prometheus",
+			Type:   "prometheus",
+			Access: models.DS_ACCESS_PROXY,
+			Url:    "http://prometheus:9090",
+		}))
+
+		fn(t, scenarioContext{
+			service: service,
+			admin: models.SignedInUser{
+				UserId:  admin.Id,
+				Login:   admin.Login,
+				OrgId:   admin.OrgId,
+				OrgRole: models.ROLE_ADMIN,
+			},
+			viewer: models.SignedInUser{
+				UserId:  viewer.Id,
+				Login:   viewer.Login,
+				OrgId:   admin.OrgId,
+				OrgRole: models.ROLE_VIEWER,
+			},
+		})
+	})
+}
@@ -0,0 +1,462 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/search"
+)
+
+const (
+	defaultLimit = 100
+	maxLimit     = 1000
+)
+
+var errAccessDenied = errors.New("access denied")
+
+type userCtxKey struct{}
+
+func signedInUser(ctx context.Context) *models.SignedInUser {
+	user, _ := ctx.Value(userCtxKey{}).(*models.SignedInUser)
+	return user
+}
+
+func toID(id int64) graphql.ID {
+	return graphql.ID(strconv.FormatInt(id, 10))
+}
+
+func toLimit(limit *int32) int {
+	if limit == nil || *limit <= 0 {
+		return defaultLimit
+	}
+	if *limit > maxLimit {
+		return maxLimit
+	}
+	return int(*limit)
+}
+
+// queryResolver resolves the root fields, the resolvers of the nested fields
+// checking the permissions of the signed in user of the context the same way
+// as the REST API.
+type queryResolver struct {
+	s *Service
+}
+
+func (r *queryResolver) Dashboard(ctx context.Context, args struct{ UID string }) (*dashboardResolver, error) {
+	user := signedInUser(ctx)
+	query := models.GetDashboardQuery{Uid: args.UID, OrgId: user.OrgId}
+	if err := bus.Dispatch(&query); err != nil {
+		if errors.Is(err, models.ErrDashboardNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if query.Result.IsFolder {
+		return nil, nil
+	}
+
+	if canView, err := guardian.New(query.Result.Id, user.OrgId, user).CanView(); err != nil || !canView {
+		return nil, errAccessDenied
+	}
+	return &dashboardResolver{s: r.s, dash: query.Result}, nil
+}
+
+func (r *queryResolver) Dashboards(ctx context.Context, args struct {
+	Query     *string
+	Tags      *[]string
+	FolderUID *string
+	Starred   *bool
+	Limit     *int32
+}) ([]*dashboardResolver, error) {
+	user := signedInUser(ctx)
+	query := search.Query{
+		OrgId:        user.OrgId,
+		SignedInUser: user,
+		Limit:        int64(toLimit(args.Limit)),
+		Type:         string(search.DashHitDB),
+		Permission:   models.PERMISSION_VIEW,
+	}
+	if args.Query != nil {
+		query.Title = *args.Query
+	}
+	if args.Tags != nil {
+		query.Tags = *args.Tags
+	}
+	if args.Starred != nil {
+		query.IsStarred = *args.Starred
+	}
+	if args.FolderUID != nil {
+		folder, err := r.s.getFolder(user, *args.FolderUID)
+		if err != nil || folder == nil {
+			return nil, err
+		}
+		query.FolderIds = []int64{folder.Id}
+	}
+
+	return r.s.searchDashboards(ctx, query)
+}
+
+func (r *queryResolver) Folder(ctx context.Context, args struct{ UID string }) (*folderResolver, error) {
+	folder, err := r.s.getFolder(signedInUser(ctx), args.UID)
+	if err != nil || folder == nil {
+		return nil, err
+	}
+	return &folderResolver{s: r.s, folder: folder}, nil
+}
+
+func (r *queryResolver) Folders(ctx context.Context, args struct{ Limit *int32 }) ([]*folderResolver, error) {
+	user := signedInUser(ctx)
+	folders, err := dashboards.NewFolderService(user.OrgId, user, r.s.SQLStore).GetFolders(int64(toLimit(args.Limit)))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*folderResolver, 0, len(folders))
+	for _, folder := range folders {
+		result = append(result, &folderResolver{s: r.s, folder: folder})
+	}
+	return result, nil
+}
+
+func (r *queryResolver) Datasource(ctx context.Context, args struct{ UID string }) (*datasourceResolver, error) {
+	datasources, err := r.s.getDataSources(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, ds := range datasources {
+		if ds.ds.Uid == args.UID {
+			return ds, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *queryResolver) Datasources(ctx context.Context) ([]*datasourceResolver, error) {
+	return r.s.getDataSources(ctx)
+}
+
+func (r *queryResolver) Users(ctx context.Context, args struct {
+	Query *string
+	Limit *int32
+}) (*[]*userResolver, error) {
+	user := signedInUser(ctx)
+	isAdmin, err := isOrgAdminFolderAdminOrTeamAdmin(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin {
+		return nil, errAccessDenied
+	}
+
+	query := models.GetOrgUsersQuery{OrgId: user.OrgId, Limit: toLimit(args.Limit)}
+	if args.Query != nil {
+		query.Query = *args.Query
+	}
+	if err := bus.Dispatch(&query); err != nil {
+		return nil, err
+	}
+
+	result := make([]*userResolver, 0, len(query.Result))
+	for _, u := range query.Result {
+		if dtos.IsHiddenUser(u.Login, user, r.s.Cfg) {
+			continue
+		}
+		role, lastSeenAt := u.Role, u.LastSeenAt
+		result = append(result, &userResolver{
+			s:          r.s,
+			id:         u.UserId,
+			login:      u.Login,
+			name:       u.Name,
+			email:      u.Email,
+			role:       &role,
+			lastSeenAt: &lastSeenAt,
+		})
+	}
+	return &result, nil
+}
+
+func (r *queryResolver) Teams(ctx context.Context, args struct {
+	Query *string
+	Limit *int32
+}) ([]*teamResolver, error) {
+	user := signedInUser(ctx)
+
+	// like the team search, the editors who can administrate teams only see
+	// their teams
+	var userIDFilter int64
+	if r.s.Cfg.EditorsCanAdmin && user.OrgRole != models.ROLE_ADMIN {
+		userIDFilter = user.UserId
+	}
+
+	query := models.SearchTeamsQuery{
+		OrgId:        user.OrgId,
+		UserIdFilter: userIDFilter,
+		Page:         1,
+		Limit:        toLimit(args.Limit),
+		SignedInUser: user,
+		HiddenUsers:  r.s.Cfg.HiddenUsers,
+	}
+	if args.Query != nil {
+		query.Query = *args.Query
+	}
+	if err := bus.Dispatch(&query); err != nil {
+		return nil, err
+	}
+
+	result := make([]*teamResolver, 0, len(query.Result.Teams))
+	for _, team := range query.Result.Teams {
+		result = append(result, &teamResolver{s: r.s, team: team})
+	}
+	return result, nil
+}
+
+func (r *queryResolver) AlertRules(ctx context.Context, args struct {
+	DashboardUID *string
+	State        *[]string
+	Query        *string
+	Limit        *int32
+}) ([]*alertRuleResolver, error) {
+	user := signedInUser(ctx)
+	query := models.GetAlertsQuery{OrgId: user.OrgId, Limit: int64(toLimit(args.Limit)), User: user}
+	if args.DashboardUID != nil {
+		dash, err := r.Dashboard(ctx, struct{ UID string }{UID: *args.DashboardUID})
+		if err != nil || dash == nil {
+			return []*alertRuleResolver{}, err
+		}
+		query.DashboardIDs = []int64{dash.dash.Id}
+	}
+	if args.State != nil {
+		query.State = *args.State
+	}
+	if args.Query != nil {
+		query.Query = *args.Query
+	}
+
+	return r.s.getAlertRules(ctx, query)
+}
+
+type dashboardResolver struct {
+	s    *Service
+	dash *models.Dashboard
+}
+
+func (r *dashboardResolver) ID() graphql.ID        { return toID(r.dash.Id) }
+func (r *dashboardResolver) UID() string           { return r.dash.Uid }
+func (r *dashboardResolver) Title() string         { return r.dash.Title }
+func (r *dashboardResolver) URL() string           { return r.dash.GetUrl() }
+func (r *dashboardResolver) Tags() []string        { return r.dash.GetTags() }
+func (r *dashboardResolver) Version() int32        { return int32(r.dash.Version) }
+func (r *dashboardResolver) Created() graphql.Time { return graphql.Time{Time: r.dash.Created} }
+func (r *dashboardResolver) Updated() graphql.Time { return graphql.Time{Time: r.dash.Updated} }
+
+func (r *dashboardResolver) Folder(ctx context.Context) (*folderResolver, error) {
+	if r.dash.FolderId == 0 {
+		return nil, nil
+	}
+
+	user := signedInUser(ctx)
+	folder, err := dashboards.NewFolderService(user.OrgId, user, r.s.SQLStore).GetFolderByID(r.dash.FolderId)
+	if err != nil {
+		if errors.Is(err, models.ErrFolderAccessDenied) {
+			return nil, errAccessDenied
+		}
+		return nil, err
+	}
+	return &folderResolver{s: r.s, folder: folder}, nil
+}
+
+func (r *dashboardResolver) AlertRules(ctx context.Context) ([]*alertRuleResolver, error) {
+	user := signedInUser(ctx)
+	return r.s.getAlertRules(ctx, models.GetAlertsQuery{OrgId: user.OrgId, DashboardIDs: []int64{r.dash.Id}, User: user})
+}
+
+type folderResolver struct {
+	s      *Service
+	folder *models.Folder
+}
+
+func (r *folderResolver) ID() graphql.ID        { return toID(r.folder.Id) }
+func (r *folderResolver) UID() string           { return r.folder.Uid }
+func (r *folderResolver) Title() string         { return r.folder.Title }
+func (r *folderResolver) URL() string           { return r.folder.Url }
+func (r *folderResolver) Version() int32        { return int32(r.folder.Version) }
+func (r *folderResolver) Created() graphql.Time { return graphql.Time{Time: r.folder.Created} }
+func (r *folderResolver) Updated() graphql.Time { return graphql.Time{Time: r.folder.Updated} }
+
+func (r *folderResolver) Dashboards(ctx context.Context, args struct{ Limit *int32 }) ([]*dashboardResolver, error) {
+	user := signedInUser(ctx)
+	return r.s.searchDashboards(ctx, search.Query{
+		OrgId:        user.OrgId,
+		SignedInUser: user,
+		Limit:        int64(toLimit(args.Limit)),
+		Type:         string(search.DashHitDB),
+		FolderIds:    []int64{r.folder.Id},
+		Permission:   models.PERMISSION_VIEW,
+	})
+}
+
+type datasourceResolver struct {
+	isAdmin bool
+	ds      *models.DataSource
+}
+
+func (r *datasourceResolver) ID() graphql.ID  { return toID(r.ds.Id) }
+func (r *datasourceResolver) UID() string     { return r.ds.Uid }
+func (r *datasourceResolver) Name() string    { return r.ds.Name }
+func (r *datasourceResolver) Type() string    { return r.ds.Type }
+func (r *datasourceResolver) IsDefault() bool { return r.ds.IsDefault }
+func (r *datasourceResolver) ReadOnly() bool  { return r.ds.ReadOnly }
+
+// adminField returns the connection setting for the organization admins,
+// the settings of the data sources being only in the admin part of the REST
+// API.
+func (r *datasourceResolver) adminField(value string) (*string, error) {
+	if !r.isAdmin {
+		return nil, errAccessDenied
+	}
+	return &value, nil
+}
+
+func (r *datasourceResolver) URL() (*string, error)      { return r.adminField(r.ds.Url) }
+func (r *datasourceResolver) Access() (*string, error)   { return r.adminField(string(r.ds.Access)) }
+func (r *datasourceResolver) Database() (*string, error) { return r.adminField(r.ds.Database) }
+func (r *datasourceResolver) User() (*string, error)     { return r.adminField(r.ds.User) }
+
+func (r *datasourceResolver) JSONData() (*string, error) {
+	if r.ds.JsonData == nil {
+		return r.adminField("{}")
+	}
+	b, err := r.ds.JsonData.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return r.adminField(string(b))
+}
+
+// userResolver resolves the users of the organization and the members of the
+// teams, the role and last seen date being only known for the former.
+type userResolver struct {
+	s          *Service
+	id         int64
+	login      string
+	name       string
+	email      string
+	role       *string
+	lastSeenAt *time.Time
+}
+
+func (r *userResolver) ID() graphql.ID    { return toID(r.id) }
+func (r *userResolver) Login() string     { return r.login }
+func (r *userResolver) Name() string      { return r.name }
+func (r *userResolver) AvatarURL() string { return dtos.GetGravatarUrl(r.email) }
+
+func (r *userResolver) canReadPersonalFields(ctx context.Context) bool {
+	user := signedInUser(ctx)
+	return user.OrgRole == models.ROLE_ADMIN || user.IsGrafanaAdmin || user.UserId == r.id
+}
+
+func (r *userResolver) Email(ctx context.Context) (*string, error) {
+	if !r.canReadPersonalFields(ctx) {
+		return nil, errAccessDenied
+	}
+	return &r.email, nil
+}
+
+func (r *userResolver) Role(ctx context.Context) (*string, error) {
+	if signedInUser(ctx).OrgRole != models.ROLE_ADMIN {
+		return nil, errAccessDenied
+	}
+	return r.role, nil
+}
+
+func (r *userResolver) LastSeenAt(ctx context.Context) (*graphql.Time, error) {
+	if signedInUser(ctx).OrgRole != models.ROLE_ADMIN {
+		return nil, errAccessDenied
+	}
+	if r.lastSeenAt == nil {
+		return nil, nil
+	}
+	return &graphql.Time{Time: *r.lastSeenAt}, nil
+}
+
+func (r *userResolver) Teams(ctx context.Context) (*[]*teamResolver, error) {
+	if !r.canReadPersonalFields(ctx) {
+		return nil, errAccessDenied
+	}
+
+	query := models.GetTeamsByUserQuery{OrgId: signedInUser(ctx).OrgId, UserId: r.id}
+	if err := bus.Dispatch(&query); err != nil {
+		return nil, err
+	}
+
+	result := make([]*teamResolver, 0, len(query.Result))
+	for _, team := range query.Result {
+		result = append(result, &teamResolver{s: r.s, team: team})
+	}
+	return &result, nil
+}
+
+type teamResolver struct {
+	s    *Service
+	team *models.TeamDTO
+}
+
+func (r *teamResolver) ID() graphql.ID { return toID(r.team.Id) }
+func (r *teamResolver) Name() string   { return r.team.Name }
+func (r *teamResolver) Email() string  { return r.team.Email }
+func (r *teamResolver) AvatarURL() string {
+	return dtos.GetGravatarUrlWithDefault(r.team.Email, r.team.Name)
+}
+func (r *teamResolver) MemberCount() int32 { return int32(r.team.MemberCount) }
+
+// Members returns the members of the team for the users who can access the
+// team admin pages, like the team members endpoint.
+func (r *teamResolver) Members(ctx context.Context) (*[]*userResolver, error) {
+	user := signedInUser(ctx)
+	if user.OrgRole != models.ROLE_ADMIN && !r.s.Cfg.EditorsCanAdmin {
+		return nil, errAccessDenied
+	}
+
+	query := models.GetTeamMembersQuery{OrgId: user.OrgId, TeamId: r.team.Id}
+	if err := bus.Dispatch(&query); err != nil {
+		return nil, err
+	}
+
+	result := make([]*userResolver, 0, len(query.Result))
+	for _, member := range query.Result {
+		if dtos.IsHiddenUser(member.Login, user, r.s.Cfg) {
+			continue
+		}
+		result = append(result, &userResolver{s: r.s, id: member.UserId, login: member.Login, name: member.Name, email: member.Email})
+	}
+	return &result, nil
+}
+
+type alertRuleResolver struct {
+	s     *Service
+	alert *models.AlertListItemDTO
+}
+
+func (r *alertRuleResolver) ID() graphql.ID         { return toID(r.alert.Id) }
+func (r *alertRuleResolver) Name() string           { return r.alert.Name }
+func (r *alertRuleResolver) State() string          { return string(r.alert.State) }
+func (r *alertRuleResolver) PanelID() int32         { return int32(r.alert.PanelId) }
+func (r *alertRuleResolver) URL() string            { return r.alert.Url }
+func (r *alertRuleResolver) ExecutionError() string { return r.alert.ExecutionError }
+
+func (r *alertRuleResolver) NewStateDate() graphql.Time {
+	return graphql.Time{Time: r.alert.NewStateDate}
+}
+
+func (r *alertRuleResolver) Dashboard(ctx context.Context) (*dashboardResolver, error) {
+	return (&queryResolver{s: r.s}).Dashboard(ctx, struct{ UID string }{UID: r.alert.DashboardUid})
+}
@@ -0,0 +1,106 @@
+package graphql
+
+// schema is the read-only schema of the GraphQL API. The fields some users
+// can't read are nullable, for the queries to return the other fields with
+// an access denied error for them.
+const schema = `
+schema {
+	query: Query
+}
+
+scalar Time
+
+type Query {
+	# The dashboard of the UID, null when it doesn't exist.
+	dashboard(uid: String!): Dashboard
+	# The dashboards matching the search, in the order of the dashboard search.
+	dashboards(query: String, tags: [String!], folderUid: String, starred: Boolean, limit: Int): [Dashboard!]!
+	# The folder of the UID, null when it doesn't exist.
+	folder(uid: String!): Folder
+	folders(limit: Int): [Folder!]!
+	# The data source of the UID, null when it doesn't exist.
+	datasource(uid: String!): DataSource
+	datasources: [DataSource!]!
+	# The users of the organization, for organization, folder and team admins.
+	users(query: String, limit: Int): [User!]
+	teams(query: String, limit: Int): [Team!]!
+	# The legacy alert rules of the dashboards.
+	alertRules(dashboardUid: String, state: [String!], query: String, limit: Int): [AlertRule!]!
+}
+
+type Dashboard {
+	id: ID!
+	uid: String!
+	title: String!
+	url: String!
+	tags: [String!]!
+	version: Int!
+	created: Time!
+	updated: Time!
+	# The folder of the dashboard, null in the General folder.
+	folder: Folder
+	alertRules: [AlertRule!]!
+}
+
+type Folder {
+	id: ID!
+	uid: String!
+	title: String!
+	url: String!
+	version: Int!
+	created: Time!
+	updated: Time!
+	dashboards(limit: Int): [Dashboard!]!
+}
+
+type DataSource {
+	id: ID!
+	uid: String!
+	name: String!
+	type: String!
+	isDefault: Boolean!
+	readOnly: Boolean!
+	# The connection settings, for organization admins.
+	url: String
+	access: String
+	database: String
+	user: String
+	# The JSON data of the data source.
+	jsonData: String
+}
+
+type User {
+	id: ID!
+	login: String!
+	name: String!
+	avatarUrl: String!
+	# The email, for organization admins and the user.
+	email: String
+	# The role in the organization, for organization admins.
+	role: String
+	lastSeenAt: Time
+	# The teams of the user, for organization admins and the user.
+	teams: [Team!]
+}
+
+type Team {
+	id: ID!
+	name: String!
+	email: String!
+	avatarUrl: String!
+	memberCount: Int!
+	# The members of the team, for the users who can administrate teams.
+	members: [User!]
+}
+
+type AlertRule {
+	id: ID!
+	name: String!
+	state: String!
+	panelId: Int!
+	url: String!
+	newStateDate: Time!
+	executionError: String!
+	dashboard: Dashboard
+}
+`
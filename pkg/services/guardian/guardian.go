@@ -34,12 +34,13 @@ type DashboardGuardian interface {
 }
 
 type dashboardGuardianImpl struct {
-	user   *models.SignedInUser
-	dashId int64
-	orgId  int64
-	acl    []*models.DashboardAclInfoDTO
-	teams  []*models.TeamDTO
-	log    log.Logger
+	user           *models.SignedInUser
+	dashId         int64
+	orgId          int64
+	acl            []*models.DashboardAclInfoDTO
+	teams          []*models.TeamDTO
+	externalGroups []string
+	log            log.Logger
 }
 
 // New factory for creating a new dashboard guardian instance
@@ -103,6 +104,7 @@ func (g *dashboardGuardianImpl) logHasPermissionResult(permission models.Permiss
 func (g *dashboardGuardianImpl) checkAcl(permission models.PermissionType, acl []*models.DashboardAclInfoDTO) (bool, error) {
 	orgRole := g.user.OrgRole
 	teamAclItems := []*models.DashboardAclInfoDTO{}
+	externalGroupAclItems := []*models.DashboardAclInfoDTO{}
 
 	for _, p := range acl {
 		// user match
@@ -123,23 +125,44 @@ func (g *dashboardGuardianImpl) checkAcl(permission models.PermissionType, acl [
 		if p.TeamId > 0 {
 			teamAclItems = append(teamAclItems, p)
 		}
+		if p.ExternalGroup != "" && !g.user.IsAnonymous {
+			externalGroupAclItems = append(externalGroupAclItems, p)
+		}
 	}
 
 	// do we have team rules?
-	if len(teamAclItems) == 0 {
+	if len(teamAclItems) > 0 {
+		// load teams
+		teams, err := g.getTeams()
+		if err != nil {
+			return false, err
+		}
+
+		// evaluate team rules
+		for _, p := range acl {
+			for _, ug := range teams {
+				if ug.Id == p.TeamId && p.Permission >= permission {
+					return true, nil
+				}
+			}
+		}
+	}
+
+	// do we have external group rules?
+	if len(externalGroupAclItems) == 0 {
 		return false, nil
 	}
 
-	// load teams
-	teams, err := g.getTeams()
+	// the groups are resolved at access time, so that the rules follow the
+	// group memberships synced at the last login
+	groups, err := g.getExternalGroups()
 	if err != nil {
 		return false, err
 	}
 
-	// evaluate team rules
-	for _, p := range acl {
-		for _, ug := range teams {
-			if ug.Id == p.TeamId && p.Permission >= permission {
+	for _, p := range externalGroupAclItems {
+		for _, group := range groups {
+			if group == p.ExternalGroup && p.Permission >= permission {
 				return true, nil
 			}
 		}
@@ -155,7 +178,7 @@ func (g *dashboardGuardianImpl) CheckPermissionBeforeUpdate(permission models.Pe
 
 	// validate that duplicate permissions don't exists
 	for _, p := range updatePermissions {
-		aclItem := &models.DashboardAclInfoDTO{DashboardId: p.DashboardID, UserId: p.UserID, TeamId: p.TeamID, Role: p.Role, Permission: p.Permission}
+		aclItem := &models.DashboardAclInfoDTO{DashboardId: p.DashboardID, UserId: p.UserID, TeamId: p.TeamID, ExternalGroup: p.ExternalGroup, Role: p.Role, Permission: p.Permission}
 		if aclItem.IsDuplicateOf(everyoneWithAdminRole) {
 			return false, ErrGuardianPermissionExists
 		}
@@ -257,6 +280,18 @@ func (g *dashboardGuardianImpl) getTeams() ([]*models.TeamDTO, error) {
 	return query.Result, err
 }
 
+func (g *dashboardGuardianImpl) getExternalGroups() ([]string, error) {
+	if g.externalGroups != nil {
+		return g.externalGroups, nil
+	}
+
+	query := models.GetUserExternalGroupsQuery{UserId: g.user.UserId}
+	err := bus.Dispatch(&query)
+
+	g.externalGroups = query.Result
+	return query.Result, err
+}
+
 func (g *dashboardGuardianImpl) GetHiddenACL(cfg *setting.Cfg) ([]*models.DashboardAcl, error) {
 	hiddenACL := make([]*models.DashboardAcl, 0)
 	if g.user.IsGrafanaAdmin {
@@ -768,3 +768,47 @@ func TestGuardianGetAclWithoutDuplicates(t *testing.T) {
 		})
 	})
 }
+
+func TestGuardianExternalGroupPermissions(t *testing.T) {
+	t.Cleanup(bus.ClearBusHandlers)
+
+	bus.AddHandler("test", func(query *models.GetDashboardAclInfoListQuery) error {
+		query.Result = []*models.DashboardAclInfoDTO{
+			{ExternalGroup: "cn=sre,ou=groups,dc=grafana,dc=org", Permission: models.PERMISSION_EDIT},
+			{ExternalGroup: "viewers", Permission: models.PERMISSION_VIEW},
+		}
+		return nil
+	})
+	groupQueries := 0
+	bus.AddHandler("test", func(query *models.GetUserExternalGroupsQuery) error {
+		groupQueries++
+		if query.UserId == 1 {
+			query.Result = []string{"cn=sre,ou=groups,dc=grafana,dc=org"}
+		} else {
+			query.Result = []string{"viewers"}
+		}
+		return nil
+	})
+
+	sre := New(dashboardID, orgID, &models.SignedInUser{OrgId: orgID, UserId: 1, OrgRole: models.ROLE_VIEWER})
+	canEdit, err := sre.CanEdit()
+	require.NoError(t, err)
+	require.True(t, canEdit)
+	canAdmin, err := sre.CanAdmin()
+	require.NoError(t, err)
+	require.False(t, canAdmin)
+	require.Equal(t, 1, groupQueries)
+
+	viewer := New(dashboardID, orgID, &models.SignedInUser{OrgId: orgID, UserId: 2, OrgRole: models.ROLE_VIEWER})
+	canView, err := viewer.CanView()
+	require.NoError(t, err)
+	require.True(t, canView)
+	canEdit, err = viewer.CanEdit()
+	require.NoError(t, err)
+	require.False(t, canEdit)
+
+	anonymous := New(dashboardID, orgID, &models.SignedInUser{OrgId: orgID, OrgRole: models.ROLE_VIEWER, IsAnonymous: true})
+	canView, err = anonymous.CanView()
+	require.NoError(t, err)
+	require.False(t, canView)
+}
@@ -0,0 +1,179 @@
+package ldap
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The strategies ordering the hosts of the same priority of a server
+const (
+	// FailoverStrategyPriority tries the hosts in their configured order
+	FailoverStrategyPriority = "priority"
+	// FailoverStrategyRoundRobin starts with the next host for each connection
+	FailoverStrategyRoundRobin = "round_robin"
+	// FailoverStrategyWeighted starts with a random host, chosen by weight
+	FailoverStrategyWeighted = "weighted"
+)
+
+// defaultHealthCheckInterval is the default number of seconds a host which failed to connect is tried after the
+// others
+const defaultHealthCheckInterval = 30
+
+// randIntn returns a random number in [0,n), it can be stubbed by tests
+var randIntn = rand.Intn
+
+// now returns the current time, it can be stubbed by tests
+var now = time.Now
+
+// hostHealth holds the hosts which failed to connect, by address, with the time they're healthy again
+var hostHealth = struct {
+	sync.Mutex
+	unhealthyUntil map[string]time.Time
+}{unhealthyUntil: map[string]time.Time{}}
+
+// setConnectionDefaults validates the hosts and the failover strategy of a server
+func setConnectionDefaults(server *ServerConfig) error {
+	switch server.FailoverStrategy {
+	case "":
+		server.FailoverStrategy = FailoverStrategyPriority
+	case FailoverStrategyPriority, FailoverStrategyRoundRobin, FailoverStrategyWeighted:
+	default:
+		return fmt.Errorf("LDAP config file has an invalid failover_strategy: %q", server.FailoverStrategy)
+	}
+
+	for _, host := range server.Hosts {
+		if err := assertNotEmptyCfg(host.Host, "hosts.host"); err != nil {
+			return err
+		}
+		if host.Weight < 0 {
+			return fmt.Errorf("LDAP config file has a negative weight for host %q", host.Host)
+		}
+	}
+	return nil
+}
+
+// hosts returns the hosts of a server, either its hosts setting or the space separated hosts of its host setting,
+// with the port of the server when they have none
+func (config *ServerConfig) hosts() []HostConfig {
+	var hosts []HostConfig
+	if len(config.Hosts) > 0 {
+		for _, host := range config.Hosts {
+			hosts = append(hosts, *host)
+		}
+	} else {
+		for _, host := range strings.Split(config.Host, " ") {
+			hosts = append(hosts, HostConfig{Host: host})
+		}
+	}
+
+	for i := range hosts {
+		// Remove any square brackets enclosing IPv6 addresses, a format we support for backwards compatibility
+		hosts[i].Host = strings.TrimSuffix(strings.TrimPrefix(hosts[i].Host, "["), "]")
+		if hosts[i].Port == 0 {
+			hosts[i].Port = config.Port
+		}
+	}
+	return hosts
+}
+
+// address returns the address of a host to dial
+func (host HostConfig) address() string {
+	return net.JoinHostPort(host.Host, strconv.Itoa(host.Port))
+}
+
+// orderedHosts returns the hosts of a server in the order they're tried: by priority, then by the failover
+// strategy of the server. The unhealthy hosts are tried last, so that they're only tried if no other host is
+// available until their health check.
+func (config *ServerConfig) orderedHosts() []HostConfig {
+	hosts := config.hosts()
+	sort.SliceStable(hosts, func(i, j int) bool {
+		return hosts[i].Priority < hosts[j].Priority
+	})
+
+	var next uint64
+	if config.FailoverStrategy == FailoverStrategyRoundRobin {
+		next = nextRoundRobin(config)
+	}
+	for start := 0; start < len(hosts); {
+		end := start + 1
+		for end < len(hosts) && hosts[end].Priority == hosts[start].Priority {
+			end++
+		}
+		switch config.FailoverStrategy {
+		case FailoverStrategyRoundRobin:
+			rotate(hosts[start:end], int(next%uint64(end-start)))
+		case FailoverStrategyWeighted:
+			shuffleByWeight(hosts[start:end])
+		}
+		start = end
+	}
+
+	hostHealth.Lock()
+	defer hostHealth.Unlock()
+	current := now()
+	healthy := func(host HostConfig) bool {
+		return !current.Before(hostHealth.unhealthyUntil[host.address()])
+	}
+	sort.SliceStable(hosts, func(i, j int) bool {
+		return healthy(hosts[i]) && !healthy(hosts[j])
+	})
+	return hosts
+}
+
+// rotate rotates the hosts left by n
+func rotate(hosts []HostConfig, n int) {
+	rotated := append(append([]HostConfig{}, hosts[n:]...), hosts[:n]...)
+	copy(hosts, rotated)
+}
+
+// shuffleByWeight orders the hosts by repeatedly choosing a random one by weight, like the SRV records of RFC 2782.
+// The hosts without weight are chosen as if they had a weight of 1.
+func shuffleByWeight(hosts []HostConfig) {
+	for i := range hosts {
+		total := 0
+		for _, host := range hosts[i:] {
+			total += hostWeight(host)
+		}
+		n := randIntn(total)
+		for j := i; j < len(hosts); j++ {
+			n -= hostWeight(hosts[j])
+			if n < 0 {
+				hosts[i], hosts[j] = hosts[j], hosts[i]
+				break
+			}
+		}
+	}
+}
+
+func hostWeight(host HostConfig) int {
+	if host.Weight <= 0 {
+		return 1
+	}
+	return host.Weight
+}
+
+// markHostHealthy records that a host connected
+func markHostHealthy(address string) {
+	hostHealth.Lock()
+	defer hostHealth.Unlock()
+	delete(hostHealth.unhealthyUntil, address)
+}
+
+// markHostUnhealthy records that a host failed to connect, so that it's tried after the other hosts until the
+// health check interval of its server elapsed
+func markHostUnhealthy(config *ServerConfig, address string) {
+	interval := config.HealthCheckInterval
+	if interval <= 0 {
+		interval = defaultHealthCheckInterval
+	}
+
+	hostHealth.Lock()
+	defer hostHealth.Unlock()
+	hostHealth.unhealthyUntil[address] = now().Add(time.Duration(interval) * time.Second)
+}
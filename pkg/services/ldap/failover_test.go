@@ -0,0 +1,117 @@
+package ldap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ldap.v3"
+)
+
+func TestOrderedHosts(t *testing.T) {
+	origNow, origRandIntn := now, randIntn
+	t.Cleanup(func() {
+		now, randIntn = origNow, origRandIntn
+		hostHealth.Lock()
+		hostHealth.unhealthyUntil = map[string]time.Time{}
+		hostHealth.Unlock()
+	})
+	current := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return current }
+
+	addresses := func(hosts []HostConfig) []string {
+		var result []string
+		for _, host := range hosts {
+			result = append(result, host.address())
+		}
+		return result
+	}
+
+	t.Run("Should use the space separated hosts in order", func(t *testing.T) {
+		config := &ServerConfig{Host: "ldap1 [::1] ldap3", Port: 389}
+		assert.Equal(t, []string{"ldap1:389", "[::1]:389", "ldap3:389"}, addresses(config.orderedHosts()))
+	})
+
+	t.Run("Should order the hosts by priority", func(t *testing.T) {
+		config := &ServerConfig{Port: 636, Hosts: []*HostConfig{
+			{Host: "backup", Priority: 10},
+			{Host: "primary", Port: 1636},
+			{Host: "secondary", Priority: 5},
+		}}
+		assert.Equal(t, []string{"primary:1636", "secondary:636", "backup:636"}, addresses(config.orderedHosts()))
+	})
+
+	t.Run("Should rotate the hosts of the same priority with the round-robin strategy", func(t *testing.T) {
+		config := &ServerConfig{Port: 389, FailoverStrategy: FailoverStrategyRoundRobin, Hosts: []*HostConfig{
+			{Host: "a"}, {Host: "b"}, {Host: "backup", Priority: 1},
+		}}
+		assert.Equal(t, []string{"a:389", "b:389", "backup:389"}, addresses(config.orderedHosts()))
+		assert.Equal(t, []string{"b:389", "a:389", "backup:389"}, addresses(config.orderedHosts()))
+		assert.Equal(t, []string{"a:389", "b:389", "backup:389"}, addresses(config.orderedHosts()))
+	})
+
+	t.Run("Should choose the hosts of the same priority by weight with the weighted strategy", func(t *testing.T) {
+		config := &ServerConfig{Port: 389, FailoverStrategy: FailoverStrategyWeighted, Hosts: []*HostConfig{
+			{Host: "small", Weight: 1}, {Host: "large", Weight: 3},
+		}}
+		var totals []int
+		randIntn = func(n int) int {
+			totals = append(totals, n)
+			return 1
+		}
+		assert.Equal(t, []string{"large:389", "small:389"}, addresses(config.orderedHosts()))
+		assert.Equal(t, []int{4, 1}, totals)
+	})
+
+	t.Run("Should try the unhealthy hosts last until their health check", func(t *testing.T) {
+		config := &ServerConfig{Host: "ldap1 ldap2", Port: 389, HealthCheckInterval: 60}
+		markHostUnhealthy(config, "ldap1:389")
+		assert.Equal(t, []string{"ldap2:389", "ldap1:389"}, addresses(config.orderedHosts()))
+
+		current = current.Add(time.Minute)
+		assert.Equal(t, []string{"ldap1:389", "ldap2:389"}, addresses(config.orderedHosts()))
+
+		markHostUnhealthy(config, "ldap1:389")
+		markHostHealthy("ldap1:389")
+		assert.Equal(t, []string{"ldap1:389", "ldap2:389"}, addresses(config.orderedHosts()))
+	})
+}
+
+func TestSetConnectionDefaults(t *testing.T) {
+	config := &ServerConfig{}
+	require.NoError(t, setConnectionDefaults(config))
+	assert.Equal(t, FailoverStrategyPriority, config.FailoverStrategy)
+
+	assert.Error(t, setConnectionDefaults(&ServerConfig{FailoverStrategy: "random"}))
+	assert.Error(t, setConnectionDefaults(&ServerConfig{Hosts: []*HostConfig{{Port: 389}}}))
+	assert.Error(t, setConnectionDefaults(&ServerConfig{Hosts: []*HostConfig{{Host: "ldap", Weight: -1}}}))
+}
+
+func TestFollowReferrals(t *testing.T) {
+	result := &ldap.SearchResult{
+		Entries:   []*ldap.Entry{{DN: "cn=admin,dc=grafana,dc=org"}},
+		Referrals: []string{"ldap://127.0.0.1:1/dc=other,dc=org", "ftp://example.com"},
+	}
+	request := &ldap.SearchRequest{BaseDN: "dc=grafana,dc=org"}
+
+	t.Run("Should not follow the referrals unless enabled", func(t *testing.T) {
+		server := &Server{Config: &ServerConfig{}, Connection: &MockConnection{SearchResult: result}, log: log.New("test")}
+		searchResult, err := server.search(request)
+		require.NoError(t, err)
+		assert.Len(t, searchResult.Referrals, 2)
+	})
+
+	t.Run("Should skip the referrals which fail", func(t *testing.T) {
+		server := &Server{
+			Config:     &ServerConfig{FollowReferrals: true},
+			Connection: &MockConnection{SearchResult: result},
+			log:        log.New("test"),
+		}
+		searchResult, err := server.search(request)
+		require.NoError(t, err)
+		assert.Empty(t, searchResult.Referrals)
+		assert.Len(t, searchResult.Entries, 1)
+	})
+}
@@ -7,8 +7,6 @@ import (
 	"fmt"
 	"io/ioutil"
 	"math"
-	"net"
-	"strconv"
 	"strings"
 
 	"github.com/davecgh/go-spew/spew"
@@ -87,9 +85,34 @@ func New(config *ServerConfig) IServer {
 	}
 }
 
-// Dial dials in the LDAP
-// TODO: decrease cyclomatic complexity
+// Dial dials in the LDAP, reusing an idle connection of the pool of the server if any. Otherwise, the hosts of
+// the server are tried in the order of their priority and the failover strategy of the server.
 func (server *Server) Dial() error {
+	if conn := acquireConnection(server.Config); conn != nil {
+		server.Connection = conn
+		return nil
+	}
+
+	tlsCfg, err := server.tlsConfig()
+	if err != nil {
+		return err
+	}
+	for _, host := range server.Config.orderedHosts() {
+		address := host.address()
+		server.Connection, err = server.dial(host.Host, address, server.Config.UseSSL, server.Config.StartTLS, tlsCfg)
+		if err == nil {
+			markHostHealthy(address)
+			return nil
+		}
+
+		server.log.Debug("Unable to dial LDAP host", "address", address, "error", err)
+		markHostUnhealthy(server.Config, address)
+	}
+	return err
+}
+
+// tlsConfig returns the TLS config of the server, without server name
+func (server *Server) tlsConfig() (*tls.Config, error) {
 	var err error
 	var certPool *x509.CertPool
 	if server.Config.RootCACert != "" {
@@ -99,10 +122,10 @@ func (server *Server) Dial() error {
 			// We can ignore the gosec G304 warning on this one because `caCertFile` comes from ldap config.
 			pem, err := ioutil.ReadFile(caCertFile)
 			if err != nil {
-				return err
+				return nil, err
 			}
 			if !certPool.AppendCertsFromPEM(pem) {
-				return errors.New("Failed to append CA certificate " + caCertFile)
+				return nil, errors.New("Failed to append CA certificate " + caCertFile)
 			}
 		}
 	}
@@ -110,48 +133,56 @@ func (server *Server) Dial() error {
 	if server.Config.ClientCert != "" && server.Config.ClientKey != "" {
 		clientCert, err = tls.LoadX509KeyPair(server.Config.ClientCert, server.Config.ClientKey)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
-	for _, host := range strings.Split(server.Config.Host, " ") {
-		// Remove any square brackets enclosing IPv6 addresses, a format we support for backwards compatibility
-		host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
-		address := net.JoinHostPort(host, strconv.Itoa(server.Config.Port))
-		if server.Config.UseSSL {
-			tlsCfg := &tls.Config{
-				InsecureSkipVerify: server.Config.SkipVerifySSL,
-				ServerName:         host,
-				RootCAs:            certPool,
-			}
-			if len(clientCert.Certificate) > 0 {
-				tlsCfg.Certificates = append(tlsCfg.Certificates, clientCert)
-			}
-			if server.Config.StartTLS {
-				server.Connection, err = ldap.Dial("tcp", address)
-				if err == nil {
-					if err = server.Connection.StartTLS(tlsCfg); err == nil {
-						return nil
-					}
-				}
-			} else {
-				server.Connection, err = ldap.DialTLS("tcp", address, tlsCfg)
-			}
-		} else {
-			server.Connection, err = ldap.Dial("tcp", address)
-		}
 
+	tlsCfg := &tls.Config{
+		InsecureSkipVerify: server.Config.SkipVerifySSL,
+		RootCAs:            certPool,
+	}
+	if len(clientCert.Certificate) > 0 {
+		tlsCfg.Certificates = append(tlsCfg.Certificates, clientCert)
+	}
+	return tlsCfg, nil
+}
+
+// dial dials a host of the server, with LDAPS or STARTTLS if useSSL is set
+func (server *Server) dial(host, address string, useSSL, startTLS bool, tlsCfg *tls.Config) (IConnection, error) {
+	var conn *ldap.Conn
+	var err error
+	switch {
+	case !useSSL:
+		conn, err = ldap.Dial("tcp", address)
+	case startTLS:
+		conn, err = ldap.Dial("tcp", address)
 		if err == nil {
-			return nil
+			if err = conn.StartTLS(serverTLSConfig(tlsCfg, host)); err != nil {
+				conn.Close()
+			}
 		}
+	default:
+		conn, err = ldap.DialTLS("tcp", address, serverTLSConfig(tlsCfg, host))
 	}
-	return err
+	if err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+func serverTLSConfig(tlsCfg *tls.Config, host string) *tls.Config {
+	tlsCfg = tlsCfg.Clone()
+	tlsCfg.ServerName = host
+	return tlsCfg
 }
 
-// Close closes the LDAP connection
+// Close closes the LDAP connection, or puts it back into the pool of the server
 // Dial() sets the connection with the server for this Struct. Therefore, we require a
 // call to Dial() before being able to execute this function.
 func (server *Server) Close() {
-	server.Connection.Close()
+	if !releaseConnection(server.Config, server.Connection) {
+		server.Connection.Close()
+	}
 }
 
 // Login the user.
@@ -316,7 +347,7 @@ func (server *Server) users(logins []string) (
 	var err error
 
 	for _, base := range Config.SearchBaseDNs {
-		result, err = server.Connection.Search(
+		result, err = server.search(
 			server.getSearchRequest(base, logins),
 		)
 		if err != nil {
@@ -536,7 +567,7 @@ func (server *Server) requestMemberOf(entry *ldap.Entry) ([]string, error) {
 			Filter:       filter,
 		}
 
-		groupSearchResult, err := server.Connection.Search(&groupSearchReq)
+		groupSearchResult, err := server.search(&groupSearchReq)
 		if err != nil {
 			return nil, err
 		}
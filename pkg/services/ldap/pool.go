@@ -0,0 +1,120 @@
+package ldap
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultPoolIdleTimeout is the default number of seconds an idle connection is kept in the pool
+const defaultPoolIdleTimeout = 300
+
+// connectionPool holds the idle connections of a server, bound to any user, since every request binds first
+type connectionPool struct {
+	sync.Mutex
+	idle       []pooledConnection
+	roundRobin uint64
+}
+
+type pooledConnection struct {
+	conn     IConnection
+	released time.Time
+}
+
+// pools holds the connection pools and the round-robin counters of the servers, by config
+var pools = struct {
+	sync.Mutex
+	byConfig map[*ServerConfig]*connectionPool
+}{byConfig: map[*ServerConfig]*connectionPool{}}
+
+// closingConnection is implemented by the connections knowing whether they're closed, like *ldap.Conn
+type closingConnection interface {
+	IsClosing() bool
+}
+
+func getPool(config *ServerConfig) *connectionPool {
+	pools.Lock()
+	defer pools.Unlock()
+
+	pool, ok := pools.byConfig[config]
+	if !ok {
+		pool = &connectionPool{}
+		pools.byConfig[config] = pool
+	}
+	return pool
+}
+
+// nextRoundRobin returns the next value of the round-robin counter of a server
+func nextRoundRobin(config *ServerConfig) uint64 {
+	return atomic.AddUint64(&getPool(config).roundRobin, 1) - 1
+}
+
+// acquireConnection returns an idle connection of a server from its pool, or else nil. The connections idle for
+// longer than the idle timeout, or closed by the server, are closed.
+func acquireConnection(config *ServerConfig) IConnection {
+	if config.PoolSize <= 0 {
+		return nil
+	}
+
+	pool := getPool(config)
+	pool.Lock()
+	defer pool.Unlock()
+
+	for len(pool.idle) > 0 {
+		pooled := pool.idle[len(pool.idle)-1]
+		pool.idle = pool.idle[:len(pool.idle)-1]
+		if now().Sub(pooled.released) < poolIdleTimeout(config) && !isClosing(pooled.conn) {
+			return pooled.conn
+		}
+		pooled.conn.Close()
+	}
+	return nil
+}
+
+// releaseConnection puts a connection of a server back into its pool, unless the pool is disabled or full or the
+// connection closed, and returns whether it did
+func releaseConnection(config *ServerConfig, conn IConnection) bool {
+	if config.PoolSize <= 0 || isClosing(conn) {
+		return false
+	}
+
+	pool := getPool(config)
+	pool.Lock()
+	defer pool.Unlock()
+
+	if len(pool.idle) >= config.PoolSize {
+		return false
+	}
+	pool.idle = append(pool.idle, pooledConnection{conn: conn, released: now()})
+	return true
+}
+
+// closePools closes the idle connections of all the servers, when their config is reloaded
+func closePools() {
+	pools.Lock()
+	defer pools.Unlock()
+
+	for config, pool := range pools.byConfig {
+		pool.Lock()
+		for _, pooled := range pool.idle {
+			pooled.conn.Close()
+		}
+		pool.idle = nil
+		pool.Unlock()
+		delete(pools.byConfig, config)
+	}
+}
+
+func poolIdleTimeout(config *ServerConfig) time.Duration {
+	if config.PoolIdleTimeout <= 0 {
+		return defaultPoolIdleTimeout * time.Second
+	}
+	return time.Duration(config.PoolIdleTimeout) * time.Second
+}
+
+func isClosing(conn IConnection) bool {
+	if closing, ok := conn.(closingConnection); ok {
+		return closing.IsClosing()
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package ldap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/stretchr/testify/assert"
+)
+
+type closingMockConnection struct {
+	MockConnection
+	closing bool
+}
+
+func (c *closingMockConnection) IsClosing() bool {
+	return c.closing
+}
+
+func TestConnectionPool(t *testing.T) {
+	origNow := now
+	t.Cleanup(func() {
+		now = origNow
+		closePools()
+	})
+	current := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return current }
+
+	t.Run("Should close the connections when the pool is disabled", func(t *testing.T) {
+		conn := &MockConnection{}
+		server := &Server{Config: &ServerConfig{}, Connection: conn, log: log.New("test")}
+		server.Close()
+		assert.True(t, conn.CloseCalled)
+		assert.Nil(t, acquireConnection(server.Config))
+	})
+
+	t.Run("Should reuse the idle connections up to the pool size", func(t *testing.T) {
+		config := &ServerConfig{PoolSize: 1}
+		first, second := &MockConnection{}, &MockConnection{}
+		(&Server{Config: config, Connection: first}).Close()
+		(&Server{Config: config, Connection: second}).Close()
+		assert.False(t, first.CloseCalled)
+		assert.True(t, second.CloseCalled)
+
+		server := &Server{Config: config, log: log.New("test")}
+		assert.NoError(t, server.Dial())
+		assert.Same(t, first, server.Connection)
+		assert.Nil(t, acquireConnection(config))
+	})
+
+	t.Run("Should close the connections idle for too long", func(t *testing.T) {
+		config := &ServerConfig{PoolSize: 1, PoolIdleTimeout: 60}
+		conn := &MockConnection{}
+		assert.True(t, releaseConnection(config, conn))
+
+		current = current.Add(time.Minute)
+		assert.Nil(t, acquireConnection(config))
+		assert.True(t, conn.CloseCalled)
+	})
+
+	t.Run("Should not pool the closed connections", func(t *testing.T) {
+		config := &ServerConfig{PoolSize: 2}
+		conn := &closingMockConnection{closing: true}
+		(&Server{Config: config, Connection: conn}).Close()
+		assert.True(t, conn.CloseCalled)
+
+		conn = &closingMockConnection{}
+		assert.True(t, releaseConnection(config, conn))
+		conn.closing = true
+		assert.Nil(t, acquireConnection(config))
+		assert.True(t, conn.CloseCalled)
+	})
+
+	t.Run("Should close the idle connections when the config is reloaded", func(t *testing.T) {
+		config := &ServerConfig{PoolSize: 1}
+		conn := &MockConnection{}
+		assert.True(t, releaseConnection(config, conn))
+		closePools()
+		assert.True(t, conn.CloseCalled)
+		assert.Nil(t, acquireConnection(config))
+	})
+}
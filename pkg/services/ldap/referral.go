@@ -0,0 +1,99 @@
+package ldap
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"gopkg.in/ldap.v3"
+)
+
+// defaultMaxReferralHops is the default number of referrals followed from a search
+const defaultMaxReferralHops = 5
+
+// search runs a search on the connection of the server, following the referrals of the result to the other
+// servers of the directory when enabled
+func (server *Server) search(request *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	result, err := server.Connection.Search(request)
+	if err != nil || !server.Config.FollowReferrals {
+		return result, err
+	}
+
+	hops := server.Config.MaxReferralHops
+	if hops <= 0 {
+		hops = defaultMaxReferralHops
+	}
+	server.followReferrals(request, result, hops, map[string]bool{})
+	return result, nil
+}
+
+// followReferrals searches the referrals of a result, up to a number of hops, and adds their entries to the
+// result. The referrals are searched with the bind of the server, the referrals which fail are skipped.
+func (server *Server) followReferrals(request *ldap.SearchRequest, result *ldap.SearchResult, hops int,
+	visited map[string]bool) {
+	referrals := result.Referrals
+	result.Referrals = nil
+	for _, referral := range referrals {
+		if visited[referral] {
+			continue
+		}
+		visited[referral] = true
+		if hops == 0 {
+			server.log.Warn("Not following LDAP referral, too many hops", "referral", referral)
+			continue
+		}
+
+		referralResult, err := server.searchReferral(request, referral)
+		if err != nil {
+			server.log.Warn("Failed to follow LDAP referral", "referral", referral, "error", err)
+			continue
+		}
+		server.followReferrals(request, referralResult, hops-1, visited)
+		result.Entries = append(result.Entries, referralResult.Entries...)
+	}
+}
+
+// searchReferral runs a search on the server of a referral, at the base DN of the referral if any
+func (server *Server) searchReferral(request *ldap.SearchRequest, referral string) (*ldap.SearchResult, error) {
+	referralURL, err := url.Parse(referral)
+	if err != nil {
+		return nil, err
+	}
+
+	var useSSL, startTLS bool
+	port := "389"
+	switch referralURL.Scheme {
+	case "ldaps":
+		useSSL = true
+		port = "636"
+	case "ldap":
+		useSSL, startTLS = server.Config.UseSSL && server.Config.StartTLS, true
+	default:
+		return nil, fmt.Errorf("unsupported referral scheme %q", referralURL.Scheme)
+	}
+	if referralURL.Port() != "" {
+		port = referralURL.Port()
+	}
+
+	tlsCfg, err := server.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := server.dial(referralURL.Hostname(), net.JoinHostPort(referralURL.Hostname(), port), useSSL, startTLS, tlsCfg)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	referralServer := &Server{Config: server.Config, Connection: conn, log: server.log}
+	if err := referralServer.Bind(); err != nil {
+		return nil, err
+	}
+
+	referralRequest := *request
+	if baseDN := strings.TrimPrefix(referralURL.Path, "/"); baseDN != "" {
+		referralRequest.BaseDN = baseDN
+	}
+	return conn.Search(&referralRequest)
+}
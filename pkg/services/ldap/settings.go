@@ -40,6 +40,34 @@ type ServerConfig struct {
 	GroupSearchBaseDNs             []string `toml:"group_search_base_dns"`
 
 	Groups []*GroupToOrgRole `toml:"group_mappings"`
+
+	// Hosts are the hosts of the server with their priority and weight, in place of Host
+	Hosts []*HostConfig `toml:"hosts"`
+	// FailoverStrategy orders the hosts of the same priority, see the FailoverStrategy* constants
+	FailoverStrategy string `toml:"failover_strategy"`
+	// HealthCheckInterval is the number of seconds a host which failed to connect is tried after the others
+	HealthCheckInterval int `toml:"health_check_interval_seconds"`
+
+	// PoolSize is the maximum number of idle connections kept for the next logins, 0 disables the pool
+	PoolSize int `toml:"pool_size"`
+	// PoolIdleTimeout is the number of seconds an idle connection is kept in the pool
+	PoolIdleTimeout int `toml:"pool_idle_timeout_seconds"`
+
+	FollowReferrals bool `toml:"follow_referrals"`
+	MaxReferralHops int  `toml:"max_referral_hops"`
+}
+
+// HostConfig is a struct representation of LDAP
+// config "hosts" setting
+type HostConfig struct {
+	Host string `toml:"host"`
+	// Port defaults to the port of the server
+	Port int `toml:"port"`
+	// Priority orders the hosts, the lowest first
+	Priority int `toml:"priority"`
+	// Weight is the relative share of the connections of the host among the hosts of the same priority, for the
+	// weighted strategy
+	Weight int `toml:"weight"`
 }
 
 // AttributeMap is a struct representation for LDAP "attributes" setting
@@ -85,6 +113,8 @@ func ReloadConfig() error {
 
 	var err error
 	config, err = readConfig(setting.LDAPConfigFile)
+	// the pools are kept by config, so the idle connections of the previous config are closed
+	closePools()
 	return err
 }
 
@@ -157,6 +187,10 @@ func readConfig(configFile string) (*Config, error) {
 				groupMap.OrgId = 1
 			}
 		}
+
+		if err := setConnectionDefaults(server); err != nil {
+			return nil, errutil.Wrap("Failed to validate connection settings", err)
+		}
 	}
 
 	return result, nil
@@ -0,0 +1,50 @@
+package features
+
+import (
+	"context"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/guardian"
+)
+
+// CommentHandler manages all the `grafana/comment/uid/<dashboard uid>`
+// channels, the comments service publishes the changes of the comments of
+// the dashboards to them.
+type CommentHandler struct{}
+
+// GetHandlerForPath called on init
+func (h *CommentHandler) GetHandlerForPath(_ string) (models.ChannelHandler, error) {
+	return h, nil // all dashboards share the same handler
+}
+
+// OnSubscribe lets the users who can view the dashboard subscribe
+func (h *CommentHandler) OnSubscribe(_ context.Context, user *models.SignedInUser, e models.SubscribeEvent) (models.SubscribeReply, backend.SubscribeStreamStatus, error) {
+	parts := strings.Split(e.Path, "/")
+	if len(parts) != 2 || parts[0] != "uid" {
+		logger.Error("Unknown comment channel", "path", e.Path)
+		return models.SubscribeReply{}, backend.SubscribeStreamStatusNotFound, nil
+	}
+
+	query := models.GetDashboardQuery{Uid: parts[1], OrgId: user.OrgId}
+	if err := bus.Dispatch(&query); err != nil {
+		logger.Error("Unknown dashboard", "query", query)
+		return models.SubscribeReply{}, backend.SubscribeStreamStatusNotFound, nil
+	}
+
+	guardian := guardian.New(query.Result.Id, user.OrgId, user)
+	if canView, err := guardian.CanView(); err != nil || !canView {
+		return models.SubscribeReply{}, backend.SubscribeStreamStatusPermissionDenied, nil
+	}
+
+	return models.SubscribeReply{}, backend.SubscribeStreamStatusOK, nil
+}
+
+// OnPublish rejects the messages of the clients, the comments are added with
+// the HTTP API
+func (h *CommentHandler) OnPublish(_ context.Context, _ *models.SignedInUser, _ models.PublishEvent) (models.PublishReply, backend.PublishStreamStatus, error) {
+	return models.PublishReply{}, backend.PublishStreamStatusPermissionDenied, nil
+}
@@ -20,6 +20,9 @@ const (
 	ACTION_DELETED   actionType = "deleted"
 	EDITING_STARTED  actionType = "editing-started"
 	EDITING_FINISHED actionType = "editing-finished"
+	// EDITING_CANCELLED is sent by the frontend when the changes are discarded
+	EDITING_CANCELLED actionType = "editing-cancelled"
+	EDITING_TAKEOVER  actionType = "editing-takeover"
 
 	GITOPS_CHANNEL = "grafana/dashboard/gitops"
 )
@@ -33,12 +36,24 @@ type dashboardEvent struct {
 	Message   string                 `json:"message,omitempty"`
 	Dashboard *models.Dashboard      `json:"dashboard,omitempty"`
 	Error     string                 `json:"error,omitempty"`
+	// Editors are the editors of the dashboard after the editing events
+	Editors *models.DashboardEditors `json:"editors,omitempty"`
 }
 
 // DashboardHandler manages all the `grafana/dashboard/*` channels
 type DashboardHandler struct {
 	Publisher   models.ChannelPublisher
 	ClientCount models.ChannelClientCount
+
+	editors *dashboardEditorRegistry
+}
+
+func NewDashboardHandler(publisher models.ChannelPublisher, clientCount models.ChannelClientCount) *DashboardHandler {
+	return &DashboardHandler{
+		Publisher:   publisher,
+		ClientCount: clientCount,
+		editors:     newDashboardEditorRegistry(),
+	}
 }
 
 // GetHandlerForPath called on init
@@ -104,7 +119,9 @@ func (h *DashboardHandler) OnPublish(ctx context.Context, user *models.SignedInU
 		if err != nil || event.UID != parts[1] {
 			return models.PublishReply{}, backend.PublishStreamStatusNotFound, fmt.Errorf("bad request")
 		}
-		if event.Action != EDITING_STARTED {
+		switch event.Action {
+		case EDITING_STARTED, EDITING_FINISHED, EDITING_CANCELLED, EDITING_TAKEOVER:
+		default:
 			// just ignore the event
 			return models.PublishReply{}, backend.PublishStreamStatusNotFound, fmt.Errorf("ignore???")
 		}
@@ -128,6 +145,17 @@ func (h *DashboardHandler) OnPublish(ctx context.Context, user *models.SignedInU
 		// Tell everyone who is editing
 		event.User = user.ToUserDisplayDTO()
 
+		var editors models.DashboardEditors
+		switch event.Action {
+		case EDITING_STARTED:
+			editors = h.editors.started(user.OrgId, event.UID, event.User)
+		case EDITING_TAKEOVER:
+			editors = h.editors.takeOver(user.OrgId, event.UID, event.User)
+		default:
+			editors = h.editors.finished(user.OrgId, event.UID, user.UserId)
+		}
+		event.Editors = &editors
+
 		msg, err := json.Marshal(event)
 		if err != nil {
 			return models.PublishReply{}, backend.PublishStreamStatusNotFound, fmt.Errorf("internal error")
@@ -180,6 +208,7 @@ func (h *DashboardHandler) DashboardSaved(orgID int64, user *models.UserDisplayD
 
 // DashboardDeleted will broadcast to all connected dashboards
 func (h *DashboardHandler) DashboardDeleted(orgID int64, user *models.UserDisplayDTO, uid string) error {
+	h.editors.remove(orgID, uid)
 	return h.publish(orgID, dashboardEvent{
 		UID:    uid,
 		Action: ACTION_DELETED,
@@ -196,3 +225,19 @@ func (h *DashboardHandler) HasGitOpsObserver(orgID int64) bool {
 	}
 	return count > 0
 }
+
+// DashboardEditors returns the users editing a dashboard and who holds its edit lock
+func (h *DashboardHandler) DashboardEditors(orgID int64, uid string) models.DashboardEditors {
+	return h.editors.get(orgID, uid)
+}
+
+// TakeOverDashboardEditLock makes the user the holder of the edit lock, and broadcasts it to the dashboard
+func (h *DashboardHandler) TakeOverDashboardEditLock(orgID int64, user *models.UserDisplayDTO, uid string) error {
+	editors := h.editors.takeOver(orgID, uid, user)
+	return h.publish(orgID, dashboardEvent{
+		UID:     uid,
+		Action:  EDITING_TAKEOVER,
+		User:    user,
+		Editors: &editors,
+	})
+}
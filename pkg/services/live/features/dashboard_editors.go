@@ -0,0 +1,152 @@
+package features
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// dashboardEditorTimeout is how long a user stays an editor of a dashboard
+// without publishing editing-started again, the frontend publishes it
+// periodically while editing. It covers the editors who closed their browser
+// without finishing.
+const dashboardEditorTimeout = 2 * time.Minute
+
+type dashboardKey struct {
+	orgID int64
+	uid   string
+}
+
+type dashboardEditor struct {
+	user     *models.UserDisplayDTO
+	since    time.Time
+	lastSeen time.Time
+}
+
+type dashboardEditing struct {
+	editors  map[int64]*dashboardEditor
+	lockedBy int64
+}
+
+// dashboardEditorRegistry keeps the editors of the dashboards in memory, the
+// Live connections being to this instance.
+type dashboardEditorRegistry struct {
+	mu         sync.Mutex
+	dashboards map[dashboardKey]*dashboardEditing
+	now        func() time.Time
+}
+
+func newDashboardEditorRegistry() *dashboardEditorRegistry {
+	return &dashboardEditorRegistry{
+		dashboards: map[dashboardKey]*dashboardEditing{},
+		now:        time.Now,
+	}
+}
+
+// started adds the user to the editors of the dashboard, or keeps them when
+// they already are.
+func (r *dashboardEditorRegistry) started(orgID int64, uid string, user *models.UserDisplayDTO) models.DashboardEditors {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := dashboardKey{orgID: orgID, uid: uid}
+	editing, ok := r.dashboards[key]
+	if !ok {
+		editing = &dashboardEditing{editors: map[int64]*dashboardEditor{}}
+		r.dashboards[key] = editing
+	}
+
+	now := r.now()
+	if editor, ok := editing.editors[user.Id]; ok && now.Sub(editor.lastSeen) <= dashboardEditorTimeout {
+		editor.lastSeen = now
+	} else {
+		editing.editors[user.Id] = &dashboardEditor{user: user, since: now, lastSeen: now}
+	}
+	return r.state(key)
+}
+
+// finished removes the user from the editors of the dashboard, releasing the
+// edit lock if they hold it.
+func (r *dashboardEditorRegistry) finished(orgID int64, uid string, userID int64) models.DashboardEditors {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := dashboardKey{orgID: orgID, uid: uid}
+	if editing, ok := r.dashboards[key]; ok {
+		delete(editing.editors, userID)
+	}
+	return r.state(key)
+}
+
+// takeOver makes the user the holder of the edit lock of the dashboard,
+// adding them to its editors.
+func (r *dashboardEditorRegistry) takeOver(orgID int64, uid string, user *models.UserDisplayDTO) models.DashboardEditors {
+	r.started(orgID, uid, user)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := dashboardKey{orgID: orgID, uid: uid}
+	r.dashboards[key].lockedBy = user.Id
+	return r.state(key)
+}
+
+func (r *dashboardEditorRegistry) get(orgID int64, uid string) models.DashboardEditors {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.state(dashboardKey{orgID: orgID, uid: uid})
+}
+
+func (r *dashboardEditorRegistry) remove(orgID int64, uid string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.dashboards, dashboardKey{orgID: orgID, uid: uid})
+}
+
+// state returns the editors of the dashboard after removing the ones who
+// timed out. The edit lock passes to the first editor when its holder isn't
+// editing anymore. The caller must hold the lock of the registry.
+func (r *dashboardEditorRegistry) state(key dashboardKey) models.DashboardEditors {
+	result := models.DashboardEditors{Editors: []models.DashboardEditor{}}
+	editing, ok := r.dashboards[key]
+	if !ok {
+		return result
+	}
+
+	now := r.now()
+	editors := make([]*dashboardEditor, 0, len(editing.editors))
+	for userID, editor := range editing.editors {
+		if now.Sub(editor.lastSeen) > dashboardEditorTimeout {
+			delete(editing.editors, userID)
+			continue
+		}
+		editors = append(editors, editor)
+	}
+	if len(editors) == 0 {
+		delete(r.dashboards, key)
+		return result
+	}
+
+	sort.Slice(editors, func(i, j int) bool {
+		if editors[i].since.Equal(editors[j].since) {
+			return editors[i].user.Id < editors[j].user.Id
+		}
+		return editors[i].since.Before(editors[j].since)
+	})
+
+	if _, ok := editing.editors[editing.lockedBy]; !ok {
+		editing.lockedBy = editors[0].user.Id
+	}
+
+	for _, editor := range editors {
+		result.Editors = append(result.Editors, models.DashboardEditor{User: editor.user, Since: editor.since})
+		if editor.user.Id == editing.lockedBy {
+			result.LockedBy = editor.user
+		}
+	}
+	return result
+}
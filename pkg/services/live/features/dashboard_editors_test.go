@@ -0,0 +1,84 @@
+package features
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDashboardEditorRegistry(t *testing.T) {
+	alice := &models.UserDisplayDTO{Id: 1, Login: "alice"}
+	bob := &models.UserDisplayDTO{Id: 2, Login: "bob"}
+
+	setup := func() (*dashboardEditorRegistry, *time.Time) {
+		r := newDashboardEditorRegistry()
+		now := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+		r.now = func() time.Time { return now }
+		return r, &now
+	}
+
+	t.Run("the first editor holds the lock", func(t *testing.T) {
+		r, now := setup()
+		r.started(1, "abc", alice)
+		*now = now.Add(time.Second)
+		editors := r.started(1, "abc", bob)
+
+		require.Len(t, editors.Editors, 2)
+		require.Equal(t, alice, editors.Editors[0].User)
+		require.Equal(t, bob, editors.Editors[1].User)
+		require.Equal(t, alice, editors.LockedBy)
+		require.True(t, editors.IsLockedByAnother(bob.Id))
+		require.False(t, editors.IsLockedByAnother(alice.Id))
+
+		require.Empty(t, r.get(2, "abc").Editors)
+		require.Nil(t, r.get(1, "other").LockedBy)
+	})
+
+	t.Run("the lock passes to the next editor when its holder finishes", func(t *testing.T) {
+		r, now := setup()
+		r.started(1, "abc", alice)
+		*now = now.Add(time.Second)
+		r.started(1, "abc", bob)
+
+		editors := r.finished(1, "abc", alice.Id)
+		require.Len(t, editors.Editors, 1)
+		require.Equal(t, bob, editors.LockedBy)
+
+		editors = r.finished(1, "abc", bob.Id)
+		require.Empty(t, editors.Editors)
+		require.Nil(t, editors.LockedBy)
+	})
+
+	t.Run("the lock can be taken over", func(t *testing.T) {
+		r, now := setup()
+		r.started(1, "abc", alice)
+		*now = now.Add(time.Second)
+		r.started(1, "abc", bob)
+
+		editors := r.takeOver(1, "abc", bob)
+		require.Equal(t, bob, editors.LockedBy)
+
+		// starting again doesn't take the lock back
+		editors = r.started(1, "abc", alice)
+		require.Equal(t, bob, editors.LockedBy)
+	})
+
+	t.Run("the editors time out without publishing again", func(t *testing.T) {
+		r, now := setup()
+		r.started(1, "abc", alice)
+		*now = now.Add(time.Minute)
+		r.started(1, "abc", bob)
+
+		*now = now.Add(90 * time.Second)
+		r.started(1, "abc", bob)
+		editors := r.get(1, "abc")
+		require.Len(t, editors.Editors, 1)
+		require.Equal(t, bob, editors.LockedBy)
+
+		*now = now.Add(3 * time.Minute)
+		require.Empty(t, r.get(1, "abc").Editors)
+		require.Empty(t, r.dashboards)
+	})
+}
@@ -0,0 +1,166 @@
+package features
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+const (
+	// InternalMetricsChannel is the channel of the internal metrics.
+	InternalMetricsChannel = "grafana/internal/metrics"
+
+	// internalMetricsInterval is how often the internal metrics are published.
+	internalMetricsInterval = 5 * time.Second
+)
+
+// InternalMetric is a metric of the internals of Grafana published to the
+// internal metrics channel.
+type InternalMetric struct {
+	Name string
+	// Value returns the current value of the metric
+	Value func(ctx context.Context) (float64, error)
+}
+
+// InternalMetricsRunner manages the `grafana/internal/metrics` channel, it
+// publishes a frame with the internal metrics to the orgs of its subscribers
+// at every interval, so that a dashboard of the server admins shows them in
+// real time without scraping the Prometheus metrics.
+type InternalMetricsRunner struct {
+	Publisher   models.ChannelPublisher
+	ClientCount models.ChannelClientCount
+
+	metrics []InternalMetric
+
+	mu sync.Mutex
+	// orgs are the orgs with subscribers
+	orgs map[int64]struct{}
+	// last is the last published frame, sent to the new subscribers
+	last []byte
+}
+
+func NewInternalMetricsRunner(publisher models.ChannelPublisher, clientCount models.ChannelClientCount, metrics []InternalMetric) *InternalMetricsRunner {
+	return &InternalMetricsRunner{
+		Publisher:   publisher,
+		ClientCount: clientCount,
+		metrics:     metrics,
+		orgs:        map[int64]struct{}{},
+	}
+}
+
+// GetHandlerForPath called on init
+func (r *InternalMetricsRunner) GetHandlerForPath(_ string) (models.ChannelHandler, error) {
+	return r, nil
+}
+
+// OnSubscribe lets the server admins subscribe to the metrics
+func (r *InternalMetricsRunner) OnSubscribe(_ context.Context, user *models.SignedInUser, e models.SubscribeEvent) (models.SubscribeReply, backend.SubscribeStreamStatus, error) {
+	if e.Path != "metrics" {
+		return models.SubscribeReply{}, backend.SubscribeStreamStatusNotFound, nil
+	}
+	if !user.IsGrafanaAdmin {
+		return models.SubscribeReply{}, backend.SubscribeStreamStatusPermissionDenied, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.orgs[user.OrgId] = struct{}{}
+	return models.SubscribeReply{
+		Presence: true,
+		Data:     r.last,
+	}, backend.SubscribeStreamStatusOK, nil
+}
+
+// OnPublish rejects the messages of the clients, the metrics are only
+// published by the server
+func (r *InternalMetricsRunner) OnPublish(_ context.Context, _ *models.SignedInUser, _ models.PublishEvent) (models.PublishReply, backend.PublishStreamStatus, error) {
+	return models.PublishReply{}, backend.PublishStreamStatusPermissionDenied, nil
+}
+
+// Run publishes the metrics at every interval until the context is done.
+func (r *InternalMetricsRunner) Run(ctx context.Context) error {
+	ticker := time.NewTicker(internalMetricsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.publish(ctx, time.Now())
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// publish publishes the metrics to the orgs which still have subscribers,
+// the metrics aren't collected when there are none.
+func (r *InternalMetricsRunner) publish(ctx context.Context, now time.Time) {
+	orgs := r.subscribedOrgs()
+	if len(orgs) == 0 {
+		return
+	}
+
+	frame := r.frame(ctx, now)
+	frameJSON, err := data.FrameToJSON(frame)
+	if err != nil {
+		logger.Error("Error marshaling the internal metrics", "error", err)
+		return
+	}
+	last := frameJSON.Bytes(data.IncludeAll)
+
+	r.mu.Lock()
+	r.last = last
+	r.mu.Unlock()
+
+	for _, orgID := range orgs {
+		if err := r.Publisher(orgID, InternalMetricsChannel, last); err != nil {
+			logger.Error("Error publishing the internal metrics", "orgId", orgID, "error", err)
+		}
+	}
+}
+
+// subscribedOrgs returns the orgs with subscribers, forgetting the orgs
+// whose subscribers are gone.
+func (r *InternalMetricsRunner) subscribedOrgs() []int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	orgs := make([]int64, 0, len(r.orgs))
+	for orgID := range r.orgs {
+		count, err := r.ClientCount(orgID, InternalMetricsChannel)
+		if err != nil {
+			logger.Error("Error getting the subscribers of the internal metrics", "orgId", orgID, "error", err)
+			continue
+		}
+		if count == 0 {
+			delete(r.orgs, orgID)
+			continue
+		}
+		orgs = append(orgs, orgID)
+	}
+	if len(r.orgs) == 0 {
+		r.last = nil
+	}
+	return orgs
+}
+
+// frame returns a frame with the time and a field per metric, the metrics
+// which fail to be collected have a null value.
+func (r *InternalMetricsRunner) frame(ctx context.Context, now time.Time) *data.Frame {
+	fields := []*data.Field{data.NewField("time", nil, []time.Time{now})}
+	for _, metric := range r.metrics {
+		var value *float64
+		if v, err := metric.Value(ctx); err != nil {
+			logger.Error("Error collecting an internal metric", "metric", metric.Name, "error", err)
+		} else {
+			value = &v
+		}
+		fields = append(fields, data.NewField(metric.Name, nil, []*float64{value}))
+	}
+	return data.NewFrame("metrics", fields...)
+}
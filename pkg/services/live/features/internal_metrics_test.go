@@ -0,0 +1,88 @@
+package features
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func TestInternalMetricsRunner(t *testing.T) {
+	published := map[int64][]byte{}
+	clients := map[int64]int{}
+	publisher := func(orgID int64, channel string, data []byte) error {
+		require.Equal(t, InternalMetricsChannel, channel)
+		published[orgID] = data
+		return nil
+	}
+	clientCount := func(orgID int64, channel string) (int, error) {
+		return clients[orgID], nil
+	}
+	r := NewInternalMetricsRunner(publisher, clientCount, []InternalMetric{
+		{Name: "active_users", Value: func(context.Context) (float64, error) { return 3, nil }},
+		{Name: "broken", Value: func(context.Context) (float64, error) { return 0, errors.New("boom") }},
+	})
+	admin := &models.SignedInUser{OrgId: 1, IsGrafanaAdmin: true}
+	event := models.SubscribeEvent{Channel: InternalMetricsChannel, Path: "metrics"}
+
+	t.Run("Only server admins can subscribe", func(t *testing.T) {
+		_, status, err := r.OnSubscribe(context.Background(), &models.SignedInUser{OrgId: 1, OrgRole: models.ROLE_ADMIN}, event)
+		require.NoError(t, err)
+		require.Equal(t, backend.SubscribeStreamStatusPermissionDenied, status)
+
+		_, status, err = r.OnSubscribe(context.Background(), admin, models.SubscribeEvent{Path: "unknown"})
+		require.NoError(t, err)
+		require.Equal(t, backend.SubscribeStreamStatusNotFound, status)
+
+		reply, status, err := r.OnSubscribe(context.Background(), admin, event)
+		require.NoError(t, err)
+		require.Equal(t, backend.SubscribeStreamStatusOK, status)
+		require.True(t, reply.Presence)
+		require.Nil(t, reply.Data)
+	})
+
+	t.Run("Publishes the metrics to the orgs with subscribers", func(t *testing.T) {
+		clients[1] = 1
+		now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+		r.publish(context.Background(), now)
+
+		require.Contains(t, published, int64(1))
+		frame := &data.Frame{}
+		require.NoError(t, json.Unmarshal(published[1], frame))
+		require.Len(t, frame.Fields, 3)
+		require.Equal(t, now, frame.Fields[0].At(0))
+		require.Equal(t, "active_users", frame.Fields[1].Name)
+		value, ok := frame.Fields[1].ConcreteAt(0)
+		require.True(t, ok)
+		require.Equal(t, 3.0, value)
+		_, ok = frame.Fields[2].ConcreteAt(0)
+		require.False(t, ok)
+
+		// new subscribers get the last frame
+		reply, _, err := r.OnSubscribe(context.Background(), admin, event)
+		require.NoError(t, err)
+		require.JSONEq(t, string(published[1]), string(reply.Data))
+	})
+
+	t.Run("Stops publishing to the orgs without subscribers", func(t *testing.T) {
+		published = map[int64][]byte{}
+		clients[1] = 0
+		r.publish(context.Background(), time.Now())
+
+		require.Empty(t, published)
+		require.Empty(t, r.orgs)
+	})
+
+	t.Run("Clients can't publish", func(t *testing.T) {
+		_, status, err := r.OnPublish(context.Background(), admin, models.PublishEvent{Channel: InternalMetricsChannel, Path: "metrics"})
+		require.NoError(t, err)
+		require.Equal(t, backend.PublishStreamStatusPermissionDenied, status)
+	})
+}
@@ -0,0 +1,97 @@
+package live
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/live/features"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+const (
+	// activeUsersWindow is how recently the active users were seen, the last
+	// seen time of the users is only updated every 5 minutes.
+	activeUsersWindow = 10 * time.Minute
+	// activeUsersCacheTTL is how long the count of the active users is kept,
+	// so that the database isn't queried at every publish.
+	activeUsersCacheTTL = time.Minute
+)
+
+// internalMetrics returns the metrics published to the internal metrics
+// channel, the active users, the data source queries per second and the lag
+// of the alert evaluations.
+func (g *GrafanaLive) internalMetrics() []features.InternalMetric {
+	return []features.InternalMetric{
+		{Name: "active_users", Value: activeUsersMetric(g.SQLStore)},
+		{Name: "queries_per_second", Value: counterRateMetric(metrics.MDataSourceQueryTotal, time.Now)},
+		{Name: "alert_evaluation_lag_seconds", Value: gaugeMetric(metrics.MAlertingEvaluationLag)},
+	}
+}
+
+// activeUsersMetric returns the number of users seen in the active users
+// window.
+func activeUsersMetric(sqlStore *sqlstore.SQLStore) func(ctx context.Context) (float64, error) {
+	var count int64
+	var countedAt time.Time
+	return func(ctx context.Context) (float64, error) {
+		if time.Since(countedAt) < activeUsersCacheTTL {
+			return float64(count), nil
+		}
+
+		seenAfter := time.Now().Add(-activeUsersWindow)
+		err := sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+			var err error
+			count, err = sess.Where("last_seen_at > ?", seenAfter).Count(&models.User{})
+			return err
+		})
+		if err != nil {
+			return 0, err
+		}
+		countedAt = time.Now()
+		return float64(count), nil
+	}
+}
+
+// counterRateMetric returns the per-second rate of a counter since the
+// previous collection.
+func counterRateMetric(counter prometheus.Counter, now func() time.Time) func(ctx context.Context) (float64, error) {
+	lastValue, _ := metricValue(counter)
+	lastTime := now()
+	return func(_ context.Context) (float64, error) {
+		value, err := metricValue(counter)
+		if err != nil {
+			return 0, err
+		}
+		t := now()
+		elapsed := t.Sub(lastTime).Seconds()
+		rate := 0.0
+		if elapsed > 0 {
+			rate = (value - lastValue) / elapsed
+		}
+		lastValue, lastTime = value, t
+		return rate, nil
+	}
+}
+
+// gaugeMetric returns the value of a gauge.
+func gaugeMetric(gauge prometheus.Gauge) func(ctx context.Context) (float64, error) {
+	return func(_ context.Context) (float64, error) {
+		return metricValue(gauge)
+	}
+}
+
+func metricValue(metric prometheus.Metric) (float64, error) {
+	var m dto.Metric
+	if err := metric.Write(&m); err != nil {
+		return 0, err
+	}
+	if m.Counter != nil {
+		return m.Counter.GetValue(), nil
+	}
+	return m.Gauge.GetValue(), nil
+}
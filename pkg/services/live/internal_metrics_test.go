@@ -0,0 +1,36 @@
+package live
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCounterRateMetric(t *testing.T) {
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_total"})
+	counter.Add(10)
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	rate := counterRateMetric(counter, func() time.Time { return now })
+
+	counter.Add(50)
+	now = now.Add(5 * time.Second)
+	value, err := rate(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 10.0, value)
+
+	now = now.Add(5 * time.Second)
+	value, err = rate(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 0.0, value)
+}
+
+func TestGaugeMetric(t *testing.T) {
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "test_seconds"})
+	gauge.Set(1.5)
+	value, err := gaugeMetric(gauge)(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1.5, value)
+}
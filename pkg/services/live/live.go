@@ -37,6 +37,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana-plugin-sdk-go/live"
+	"golang.org/x/sync/errgroup"
 )
 
 var (
@@ -96,9 +97,10 @@ type GrafanaLive struct {
 
 	ManagedStreamRunner *managedstream.Runner
 
-	contextGetter    *pluginContextGetter
-	runStreamManager *runstream.Manager
-	storage          *database.Storage
+	contextGetter         *pluginContextGetter
+	runStreamManager      *runstream.Manager
+	storage               *database.Storage
+	internalMetricsRunner *features.InternalMetricsRunner
 }
 
 func (g *GrafanaLive) getStreamPlugin(pluginID string) (backend.StreamHandler, error) {
@@ -124,8 +126,11 @@ func (g *GrafanaLive) AddMigration(mg *migrator.Migrator) {
 
 func (g *GrafanaLive) Run(ctx context.Context) error {
 	if g.runStreamManager != nil {
-		// Only run stream manager if GrafanaLive properly initialized.
-		return g.runStreamManager.Run(ctx)
+		// Only run stream manager and internal metrics if GrafanaLive properly initialized.
+		runGroup, ctx := errgroup.WithContext(ctx)
+		runGroup.Go(func() error { return g.runStreamManager.Run(ctx) })
+		runGroup.Go(func() error { return g.internalMetricsRunner.Run(ctx) })
+		return runGroup.Wait()
 	}
 	return nil
 }
@@ -160,14 +165,14 @@ func (g *GrafanaLive) Init() error {
 	g.runStreamManager = runstream.NewManager(channelSender, presenceGetter, g.contextGetter)
 
 	// Initialize the main features
-	dash := &features.DashboardHandler{
-		Publisher:   g.Publish,
-		ClientCount: g.ClientCount,
-	}
+	dash := features.NewDashboardHandler(g.Publish, g.ClientCount)
 	g.storage = database.NewStorage(g.SQLStore, g.CacheService)
 	g.GrafanaScope.Dashboards = dash
 	g.GrafanaScope.Features["dashboard"] = dash
 	g.GrafanaScope.Features["broadcast"] = features.NewBroadcastRunner(g.storage)
+	g.GrafanaScope.Features["comment"] = &features.CommentHandler{}
+	g.internalMetricsRunner = features.NewInternalMetricsRunner(g.Publish, g.ClientCount, g.internalMetrics())
+	g.GrafanaScope.Features["internal"] = g.internalMetricsRunner
 
 	g.ManagedStreamRunner = managedstream.NewRunner(g.Publish)
 
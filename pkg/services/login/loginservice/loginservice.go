@@ -68,11 +68,25 @@ func (ls *Implementation) UpsertUser(cmd *models.UpsertUserCommand) error {
 			return login.ErrUsersQuotaReached
 		}
 
-		cmd.Result, err = ls.createUser(extUser)
+		// the org role rules only apply to the users whose auth provider doesn't set their org roles
+		var rules []*models.OrgRoleRuleDTO
+		if len(extUser.OrgRoles) == 0 && extUser.Email != "" {
+			rulesQuery := &models.GetOrgRoleRulesQuery{}
+			if err := bus.Dispatch(rulesQuery); err != nil {
+				return err
+			}
+			rules = models.MatchOrgRoleRules(rulesQuery.Result, extUser.Email)
+		}
+
+		cmd.Result, err = ls.createUser(extUser, len(rules) > 0)
 		if err != nil {
 			return err
 		}
 
+		if err := addOrgRoleRuleMemberships(cmd.Result, rules); err != nil {
+			return err
+		}
+
 		if extUser.AuthModule != "" {
 			cmd2 := &models.SetAuthInfoCommand{
 				UserId:     cmd.Result.Id,
@@ -112,6 +126,14 @@ func (ls *Implementation) UpsertUser(cmd *models.UpsertUserCommand) error {
 		return err
 	}
 
+	// Sync the external groups the dashboard permissions reference, the
+	// groups the user isn't a member of any more are removed
+	if extUser.AuthModule != "" {
+		if err := ls.SQLStore.SyncUserExternalGroups(context.Background(), cmd.Result.Id, extUser.AuthModule, extUser.Groups); err != nil {
+			return err
+		}
+	}
+
 	// Sync isGrafanaAdmin permission
 	if extUser.IsGrafanaAdmin != nil && *extUser.IsGrafanaAdmin != cmd.Result.IsAdmin {
 		if err := ls.SQLStore.UpdateUserPermissions(cmd.Result.Id, *extUser.IsGrafanaAdmin); err != nil {
@@ -134,17 +156,43 @@ func (ls *Implementation) SetTeamSyncFunc(teamSyncFunc login.TeamSyncFunc) {
 	ls.TeamSync = teamSyncFunc
 }
 
-func (ls *Implementation) createUser(extUser *models.ExternalUserInfo) (*models.User, error) {
+func (ls *Implementation) createUser(extUser *models.ExternalUserInfo, skipOrgSetup bool) (*models.User, error) {
 	cmd := models.CreateUserCommand{
 		Login:        extUser.Login,
 		Email:        extUser.Email,
 		Name:         extUser.Name,
-		SkipOrgSetup: len(extUser.OrgRoles) > 0,
+		SkipOrgSetup: skipOrgSetup || len(extUser.OrgRoles) > 0,
 	}
 
 	return ls.CreateUser(cmd)
 }
 
+// addOrgRoleRuleMemberships adds a new user to the orgs of the org role rules matching its email, with their roles.
+// The org of the first rule becomes the current org of the user.
+func addOrgRoleRuleMemberships(user *models.User, rules []*models.OrgRoleRuleDTO) error {
+	hasOrg := false
+	for _, rule := range rules {
+		logger.Debug("Adding user to organization of org role rule", "userId", user.Id, "orgId", rule.OrgId,
+			"role", rule.Role, "ruleId", rule.Id)
+		cmd := &models.AddOrgUserCommand{UserId: user.Id, Role: rule.Role, OrgId: rule.OrgId}
+		if err := bus.Dispatch(cmd); err != nil {
+			if errors.Is(err, models.ErrOrgNotFound) {
+				continue
+			}
+			return err
+		}
+
+		if !hasOrg {
+			hasOrg = true
+			user.OrgId = rule.OrgId
+			if err := bus.Dispatch(&models.SetUsingOrgCommand{UserId: user.Id, OrgId: user.OrgId}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func updateUser(user *models.User, extUser *models.ExternalUserInfo) error {
 	// sync user info
 	updateCmd := &models.UpdateUserCommand{
@@ -7,6 +7,8 @@ import (
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
 	log "github.com/inconshreveable/log15"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -130,6 +132,65 @@ func Test_teamSync(t *testing.T) {
 	})
 }
 
+func Test_UpsertUser_addsNewUsersToOrgsOfOrgRoleRules(t *testing.T) {
+	login := Implementation{
+		SQLStore:     sqlstore.InitTestDB(t),
+		Bus:          bus.New(),
+		QuotaService: &quota.QuotaService{Cfg: setting.NewCfg()},
+	}
+
+	bus.ClearBusHandlers()
+	t.Cleanup(func() { bus.ClearBusHandlers() })
+	bus.AddHandler("test", func(query *models.GetUserByAuthInfoQuery) error {
+		return models.ErrUserNotFound
+	})
+	bus.AddHandler("test", func(query *models.GetOrgRoleRulesQuery) error {
+		query.Result = []*models.OrgRoleRuleDTO{
+			{Id: 1, OrgId: 2, Domain: "support.example.com", Role: models.ROLE_EDITOR},
+			{Id: 2, OrgId: 3, Regex: `@(sales|support)\.example\.com$`, Role: models.ROLE_VIEWER},
+			{Id: 3, OrgId: 2, Regex: `@support\.example\.com$`, Role: models.ROLE_ADMIN},
+			{Id: 4, OrgId: 4, Domain: "sales.example.com", Role: models.ROLE_ADMIN},
+		}
+		return nil
+	})
+	added := map[int64]models.RoleType{}
+	bus.AddHandler("test", func(cmd *models.AddOrgUserCommand) error {
+		added[cmd.OrgId] = cmd.Role
+		return nil
+	})
+	var usingOrgID int64
+	bus.AddHandler("test", func(cmd *models.SetUsingOrgCommand) error {
+		usingOrgID = cmd.OrgId
+		return nil
+	})
+
+	t.Run("Adds the user to the orgs of the matching rules", func(t *testing.T) {
+		cmd := &models.UpsertUserCommand{
+			SignupAllowed: true,
+			ExternalUser:  &models.ExternalUserInfo{Login: "jane", Email: "Jane@Support.example.com"},
+		}
+		require.NoError(t, login.UpsertUser(cmd))
+		assert.Equal(t, map[int64]models.RoleType{2: models.ROLE_EDITOR}, added)
+		assert.Equal(t, int64(2), usingOrgID)
+		assert.Equal(t, int64(2), cmd.Result.OrgId)
+	})
+
+	t.Run("Doesn't apply the rules when the auth provider sets the org roles", func(t *testing.T) {
+		added = map[int64]models.RoleType{}
+		bus.AddHandler("test", func(q *models.GetUserOrgListQuery) error {
+			return nil
+		})
+		cmd := &models.UpsertUserCommand{
+			SignupAllowed: true,
+			ExternalUser: &models.ExternalUserInfo{
+				Login: "john", Email: "john@sales.example.com", OrgRoles: map[int64]models.RoleType{5: models.ROLE_VIEWER},
+			},
+		}
+		require.NoError(t, login.UpsertUser(cmd))
+		assert.Equal(t, map[int64]models.RoleType{5: models.ROLE_VIEWER}, added)
+	})
+}
+
 func createSimpleUser() models.User {
 	user := models.User{
 		Id: 1,
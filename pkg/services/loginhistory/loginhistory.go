@@ -0,0 +1,153 @@
+// Package loginhistory records the login attempts of the users, with the
+// address, the user agent and the location of the client, and emails the
+// users when they log in from a location they never logged in from.
+package loginhistory
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/network"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/hooks"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// maxUserAgentLength is the length of the user_agent column
+const maxUserAgentLength = 255
+
+func init() {
+	registry.RegisterService(&Service{})
+}
+
+// Service records the login attempts run through the login hooks.
+type Service struct {
+	Cfg          *setting.Cfg        `inject:""`
+	Bus          bus.Bus             `inject:""`
+	HooksService *hooks.HooksService `inject:""`
+
+	log log.Logger
+}
+
+func (s *Service) Init() error {
+	s.log = log.New("login.history")
+	s.HooksService.AddLoginHook(s.recordLogin)
+	return nil
+}
+
+// recordLogin records a login attempt, and notifies the user when it's a successful login from a new location.
+func (s *Service) recordLogin(loginInfo *models.LoginInfo, c *models.ReqContext) {
+	if !s.Cfg.LoginHistory.Enabled || c == nil {
+		return
+	}
+
+	user := loginInfo.User
+	login := loginInfo.LoginUsername
+	if login == "" {
+		login = loginInfo.ExternalUser.Login
+	}
+	if user == nil && login != "" {
+		// record the failed attempts to log in as an existing user in their history
+		query := &models.GetUserByLoginQuery{LoginOrEmail: login}
+		if err := s.Bus.Dispatch(query); err == nil {
+			user = query.Result
+		} else if !errors.Is(err, models.ErrUserNotFound) {
+			s.log.Warn("Failed to get the user of a login attempt", "login", login, "error", err)
+		}
+	}
+
+	cmd := &models.CreateLoginHistoryEntryCommand{
+		Login:      login,
+		AuthModule: loginInfo.AuthModule,
+		Success:    loginInfo.Error == nil && loginInfo.HTTPStatus < 400 && user != nil,
+		IpAddress:  clientIP(c),
+		UserAgent:  c.Req.UserAgent(),
+		Location:   s.location(c),
+	}
+	if user != nil {
+		cmd.UserId = user.Id
+		cmd.Login = user.Login
+	}
+	if len(cmd.UserAgent) > maxUserAgentLength {
+		cmd.UserAgent = cmd.UserAgent[:maxUserAgentLength]
+	}
+
+	newLocation := false
+	if cmd.Success && s.Cfg.LoginHistory.NotifyNewLocation {
+		query := &models.IsKnownLoginLocationQuery{UserId: user.Id, Location: cmd.Location}
+		if err := s.Bus.Dispatch(query); err != nil {
+			s.log.Warn("Failed to check the location of a login", "userId", user.Id, "error", err)
+		} else {
+			newLocation = query.HasLoggedIn && !query.Result
+		}
+	}
+
+	if err := s.Bus.Dispatch(cmd); err != nil {
+		s.log.Warn("Failed to record a login attempt", "login", cmd.Login, "error", err)
+		return
+	}
+
+	if newLocation {
+		s.notifyNewLocation(user, cmd.Result)
+	}
+}
+
+// location returns the location of the client: the value of the location header when set, or else the network of
+// the address of the client
+func (s *Service) location(c *models.ReqContext) string {
+	if header := s.Cfg.LoginHistory.LocationHeader; header != "" {
+		if location := c.Req.Header.Get(header); location != "" {
+			return location
+		}
+	}
+
+	ip := net.ParseIP(clientIP(c))
+	if ip == nil {
+		return ""
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return (&net.IPNet{IP: ip4.Mask(net.CIDRMask(24, 32)), Mask: net.CIDRMask(24, 32)}).String()
+	}
+	return (&net.IPNet{IP: ip.Mask(net.CIDRMask(48, 128)), Mask: net.CIDRMask(48, 128)}).String()
+}
+
+// clientIP returns the IP address of the client, or the address as is when it's not an IP address
+func clientIP(c *models.ReqContext) string {
+	addr := c.RemoteAddr()
+	ip, err := network.GetIPFromAddress(addr)
+	if err != nil {
+		return addr
+	}
+	return ip.String()
+}
+
+// notifyNewLocation emails a user that they logged in from a new location.
+func (s *Service) notifyNewLocation(user *models.User, entry *models.LoginHistoryEntry) {
+	if user.Email == "" {
+		return
+	}
+	name := user.Name
+	if name == "" {
+		name = user.Login
+	}
+
+	cmd := models.SendEmailCommand{
+		To:       []string{user.Email},
+		Template: "new_login_location.html",
+		Data: map[string]interface{}{
+			"Name":      name,
+			"Location":  entry.Location,
+			"IpAddress": entry.IpAddress,
+			"UserAgent": entry.UserAgent,
+			"Time":      time.Unix(entry.Created, 0).UTC().Format(time.RFC1123),
+			"LinkUrl":   setting.ToAbsUrl("profile"),
+		},
+	}
+	if err := s.Bus.Dispatch(&cmd); err != nil && !errors.Is(err, models.ErrSmtpNotEnabled) {
+		s.log.Warn("Failed to send new login location email", "login", user.Login, "error", err)
+	}
+}
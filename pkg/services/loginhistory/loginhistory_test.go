@@ -0,0 +1,103 @@
+package loginhistory
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/macaron.v1"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/hooks"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestService(t *testing.T) {
+	user := &models.User{Id: 3, Login: "leonard", Email: "leonard@example.com"}
+
+	var entries []*models.CreateLoginHistoryEntryCommand
+	var emails []*models.SendEmailCommand
+	b := bus.New()
+	b.AddHandler(func(query *models.GetUserByLoginQuery) error {
+		if query.LoginOrEmail != user.Login {
+			return models.ErrUserNotFound
+		}
+		query.Result = user
+		return nil
+	})
+	b.AddHandler(func(cmd *models.CreateLoginHistoryEntryCommand) error {
+		entries = append(entries, cmd)
+		cmd.Result = &models.LoginHistoryEntry{UserId: cmd.UserId, IpAddress: cmd.IpAddress, UserAgent: cmd.UserAgent, Location: cmd.Location}
+		return nil
+	})
+	b.AddHandler(func(query *models.IsKnownLoginLocationQuery) error {
+		for _, entry := range entries {
+			if entry.UserId == query.UserId && entry.Success {
+				query.HasLoggedIn = true
+				query.Result = query.Result || entry.Location == query.Location
+			}
+		}
+		return nil
+	})
+	b.AddHandler(func(cmd *models.SendEmailCommand) error {
+		emails = append(emails, cmd)
+		return nil
+	})
+
+	cfg := setting.NewCfg()
+	cfg.LoginHistory = setting.LoginHistorySettings{Enabled: true, LocationHeader: "CF-IPCountry", NotifyNewLocation: true}
+	hooksService := &hooks.HooksService{}
+	s := &Service{Cfg: cfg, Bus: b, HooksService: hooksService}
+	require.NoError(t, s.Init())
+
+	login := func(t *testing.T, remoteAddr string, country string, info *models.LoginInfo) {
+		t.Helper()
+		req, err := http.NewRequest("POST", "http://localhost/login", nil)
+		require.NoError(t, err)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("User-Agent", "Mozilla/5.0")
+		if country != "" {
+			req.Header.Set("CF-IPCountry", country)
+		}
+		hooksService.RunLoginHook(info, &models.ReqContext{Context: &macaron.Context{Req: macaron.Request{Request: req}}})
+	}
+
+	t.Run("Should record the failed attempts to log in as a user", func(t *testing.T) {
+		login(t, "192.168.1.10:4567", "", &models.LoginInfo{
+			AuthModule: "", LoginUsername: "leonard", HTTPStatus: 401, Error: errors.New("invalid username or password"),
+		})
+		require.Len(t, entries, 1)
+		assert.Equal(t, user.Id, entries[0].UserId)
+		assert.False(t, entries[0].Success)
+		assert.Equal(t, "192.168.1.10", entries[0].IpAddress)
+		assert.Equal(t, "192.168.1.0/24", entries[0].Location)
+		assert.Equal(t, "Mozilla/5.0", entries[0].UserAgent)
+
+		login(t, "192.168.1.10:4567", "", &models.LoginInfo{LoginUsername: "unknown", HTTPStatus: 401, Error: errors.New("user not found")})
+		require.Len(t, entries, 2)
+		assert.Equal(t, int64(0), entries[1].UserId)
+		assert.Equal(t, "unknown", entries[1].Login)
+	})
+
+	t.Run("Should not notify the first login of a user", func(t *testing.T) {
+		login(t, "192.168.1.10:4567", "CH", &models.LoginInfo{User: user, LoginUsername: "leonard", HTTPStatus: 200})
+		require.Len(t, entries, 3)
+		assert.True(t, entries[2].Success)
+		assert.Equal(t, "CH", entries[2].Location)
+		assert.Empty(t, emails)
+	})
+
+	t.Run("Should notify the logins from new locations", func(t *testing.T) {
+		login(t, "10.0.0.1:4567", "CH", &models.LoginInfo{User: user, AuthModule: "oauth_github", HTTPStatus: 200})
+		assert.Empty(t, emails)
+
+		login(t, "10.0.0.1:4567", "FR", &models.LoginInfo{User: user, AuthModule: "oauth_github", HTTPStatus: 200})
+		require.Len(t, emails, 1)
+		assert.Equal(t, []string{user.Email}, emails[0].To)
+		assert.Equal(t, "new_login_location.html", emails[0].Template)
+		assert.Equal(t, "FR", emails[0].Data["Location"])
+	})
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/datasourceproxy"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
@@ -17,6 +18,8 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/common/model"
 )
 
 // timeNow makes it possible to test usage of time
@@ -35,6 +38,9 @@ type Alertmanager interface {
 	// Alerts
 	GetAlerts(active, silenced, inhibited bool, filter []string, receiver string) (apimodels.GettableAlerts, error)
 	GetAlertGroups(active, silenced, inhibited bool, filter []string, receiver string) (apimodels.AlertGroups, error)
+
+	// Routing
+	TestRoutes(route *config.Route, alerts []model.LabelSet) ([]apimodels.TestRoutesGroup, error)
 }
 
 // API handlers.
@@ -51,6 +57,9 @@ type API struct {
 	DataProxy       *datasourceproxy.DatasourceProxyService
 	Alertmanager    Alertmanager
 	StateManager    *state.Manager
+	AccessControl   accesscontrol.AccessControl
+	// RulerCapabilities detects the ruler API of Prometheus data sources.
+	RulerCapabilities RulerCapabilities
 }
 
 // RegisterAPIEndpoints registers API handlers
@@ -75,7 +84,7 @@ func (api *API) RegisterAPIEndpoints(m *metrics.Metrics) {
 	// Register endpoints for proxing to Cortex Ruler-compatible backends.
 	api.RegisterRulerApiEndpoints(NewForkedRuler(
 		api.DatasourceCache,
-		NewLotexRuler(proxy, logger),
+		NewLotexRuler(proxy, logger, api.AccessControl, api.RulerCapabilities),
 		RulerSrv{DatasourceCache: api.DatasourceCache, QuotaService: api.QuotaService, manager: api.StateManager, store: api.RuleStore, log: logger},
 	), m)
 	api.RegisterTestingApiEndpoints(TestingApiSrv{
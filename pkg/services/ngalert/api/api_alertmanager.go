@@ -4,8 +4,12 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"sync"
+
+	"github.com/prometheus/alertmanager/config"
 
 	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/components/fieldmask"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
@@ -243,7 +247,145 @@ func (srv AlertmanagerSrv) RoutePostAlertingConfig(c *models.ReqContext, body ap
 	return response.JSON(http.StatusAccepted, util.DynMap{"message": "configuration created"})
 }
 
+// contactPointMaskFields are the fields of a contact point that a field mask
+// can update.
+var contactPointMaskFields = []string{"name", "type", "disableResolveMessage", "settings", "secureSettings"}
+
+// contactPointsMtx serializes the updates of the contact points, which read,
+// modify and save the whole configuration.
+var contactPointsMtx sync.Mutex
+
+// RoutePutContactPoint creates the Grafana managed contact point of the UID
+// in the receiver of its name if it does not exist, or else replaces it, or
+// only the fields of the fieldMask query parameter. The secure settings that
+// are not sent are kept.
+func (srv AlertmanagerSrv) RoutePutContactPoint(c *models.ReqContext, body apimodels.PostableGrafanaReceiver) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return response.Error(http.StatusForbidden, "Permission denied", nil)
+	}
+
+	uid := c.Params(":UID")
+	if !util.IsValidShortUID(uid) || len(uid) > 40 {
+		return response.Error(http.StatusBadRequest, "invalid contact point UID", nil)
+	}
+	if body.UID != "" && body.UID != uid {
+		return response.Error(http.StatusBadRequest, "the contact point UID does not match the URL", nil)
+	}
+
+	mask := fieldmask.Parse(c.Query(fieldmask.QueryParam))
+	if err := mask.Validate(contactPointMaskFields...); err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+
+	contactPointsMtx.Lock()
+	defer contactPointsMtx.Unlock()
+
+	query := ngmodels.GetLatestAlertmanagerConfigurationQuery{}
+	if err := srv.store.GetLatestAlertmanagerConfiguration(&query); err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to get latest configuration", err)
+	}
+
+	cfg, err := notifier.Load([]byte(query.Result.AlertmanagerConfiguration))
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to load lastest configuration", err)
+	}
+
+	// the stored secure settings are encrypted again when the configuration is saved
+	var existing *apimodels.PostableGrafanaReceiver
+	for _, r := range cfg.AlertmanagerConfig.Receivers {
+		integrations := r.PostableGrafanaReceivers.GrafanaManagedReceivers[:0]
+		for _, gr := range r.PostableGrafanaReceivers.GrafanaManagedReceivers {
+			if gr.UID == uid {
+				existing = gr
+				continue
+			}
+			if err := decryptSecureSettings(gr); err != nil {
+				return response.Error(http.StatusInternalServerError, "failed to decrypt stored secure settings", err)
+			}
+			integrations = append(integrations, gr)
+		}
+		r.PostableGrafanaReceivers.GrafanaManagedReceivers = integrations
+	}
+
+	receiver := body
+	message := "contact point created"
+	if existing != nil {
+		current := *existing
+		current.SecureSettings = nil
+		receiver = apimodels.PostableGrafanaReceiver{}
+		if err := mask.Merge(current, body, &receiver); err != nil {
+			return response.Error(http.StatusBadRequest, "invalid contact point", err)
+		}
+
+		if err := decryptSecureSettings(existing); err != nil {
+			return response.Error(http.StatusInternalServerError, "failed to decrypt stored secure settings", err)
+		}
+		for key, value := range existing.SecureSettings {
+			if _, ok := receiver.SecureSettings[key]; !ok {
+				if receiver.SecureSettings == nil {
+					receiver.SecureSettings = make(map[string]string, len(existing.SecureSettings))
+				}
+				receiver.SecureSettings[key] = value
+			}
+		}
+		message = "contact point updated"
+	}
+	receiver.UID = uid
+	if receiver.Name == "" || receiver.Type == "" {
+		return response.Error(http.StatusBadRequest, "the contact point name and type are required", nil)
+	}
+
+	var owner *apimodels.PostableApiReceiver
+	for _, r := range cfg.AlertmanagerConfig.Receivers {
+		if r.Name == receiver.Name {
+			owner = r
+			break
+		}
+	}
+	if owner == nil {
+		owner = &apimodels.PostableApiReceiver{Receiver: config.Receiver{Name: receiver.Name}}
+		cfg.AlertmanagerConfig.Receivers = append(cfg.AlertmanagerConfig.Receivers, owner)
+	} else if owner.Type() == apimodels.AlertmanagerReceiverType {
+		return response.Error(http.StatusBadRequest, fmt.Sprintf("receiver %s is not managed by Grafana", receiver.Name), nil)
+	}
+	owner.PostableGrafanaReceivers.GrafanaManagedReceivers = append(owner.PostableGrafanaReceivers.GrafanaManagedReceivers, &receiver)
+
+	if err := cfg.ProcessConfig(); err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to post process Alertmanager configuration", err)
+	}
+
+	if err := srv.am.SaveAndApplyConfig(cfg); err != nil {
+		srv.log.Error("unable to save and apply alertmanager configuration", "err", err)
+		return response.Error(http.StatusBadRequest, "failed to save and apply Alertmanager configuration", err)
+	}
+
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": message, "uid": uid})
+}
+
+func decryptSecureSettings(gr *apimodels.PostableGrafanaReceiver) error {
+	for key := range gr.SecureSettings {
+		decryptedValue, err := gr.GetDecryptedSecret(key)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt stored secure setting %s: %w", key, err)
+		}
+		gr.SecureSettings[key] = decryptedValue
+	}
+	return nil
+}
+
 func (srv AlertmanagerSrv) RoutePostAMAlerts(c *models.ReqContext, body apimodels.PostableAlerts) response.Response {
 	// not implemented
 	return response.Error(http.StatusNotImplemented, "", nil)
 }
+
+func (srv AlertmanagerSrv) RouteTestRoutes(c *models.ReqContext, body apimodels.TestRoutesPayload) response.Response {
+	groups, err := srv.am.TestRoutes(body.Route, body.Alerts)
+	if err != nil {
+		if errors.Is(err, notifier.ErrNoRoute) {
+			return response.Error(http.StatusBadRequest, err.Error(), nil)
+		}
+		return response.Error(http.StatusInternalServerError, "failed to test routes", err)
+	}
+
+	return response.JSON(http.StatusOK, apimodels.TestRoutesResponse{Groups: groups})
+}
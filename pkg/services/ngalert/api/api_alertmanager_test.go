@@ -0,0 +1,173 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/macaron.v1"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+type fakeAlertingStore struct {
+	store.AlertingStore
+	config string
+}
+
+func (s *fakeAlertingStore) GetLatestAlertmanagerConfiguration(query *ngmodels.GetLatestAlertmanagerConfigurationQuery) error {
+	query.Result = &ngmodels.AlertConfiguration{AlertmanagerConfiguration: s.config}
+	return nil
+}
+
+type fakeAlertmanager struct {
+	Alertmanager
+	saved *apimodels.PostableUserConfig
+}
+
+func (am *fakeAlertmanager) SaveAndApplyConfig(cfg *apimodels.PostableUserConfig) error {
+	am.saved = cfg
+	return nil
+}
+
+func TestRoutePutContactPoint(t *testing.T) {
+	stored := func(t *testing.T) string {
+		t.Helper()
+
+		cfg := apimodels.PostableUserConfig{
+			AlertmanagerConfig: apimodels.PostableApiAlertingConfig{
+				Config: apimodels.Config{Route: &config.Route{Receiver: "ops"}},
+				Receivers: []*apimodels.PostableApiReceiver{
+					{
+						Receiver: config.Receiver{Name: "ops"},
+						PostableGrafanaReceivers: apimodels.PostableGrafanaReceivers{
+							GrafanaManagedReceivers: []*apimodels.PostableGrafanaReceiver{
+								{
+									UID:            "slack",
+									Name:           "ops",
+									Type:           "slack",
+									Settings:       simplejson.NewFromAny(map[string]interface{}{"recipient": "#ops", "username": "grafana"}),
+									SecureSettings: map[string]string{"url": "https://hooks.slack.com/secret"},
+								},
+								{
+									UID:            "pagerduty",
+									Name:           "ops",
+									Type:           "pagerduty",
+									Settings:       simplejson.New(),
+									SecureSettings: map[string]string{"integrationKey": "key"},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+		require.NoError(t, cfg.ProcessConfig())
+		b, err := json.Marshal(cfg)
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	put := func(t *testing.T, uid string, url string, body apimodels.PostableGrafanaReceiver) (*fakeAlertmanager, int) {
+		t.Helper()
+
+		am := &fakeAlertmanager{}
+		srv := AlertmanagerSrv{am: am, store: &fakeAlertingStore{config: stored(t)}, log: log.New("test")}
+
+		req, err := http.NewRequest(http.MethodPut, url, nil)
+		require.NoError(t, err)
+		c := &models.ReqContext{
+			Context:      &macaron.Context{Req: macaron.Request{Request: req}},
+			SignedInUser: &models.SignedInUser{OrgId: 1, OrgRole: models.ROLE_EDITOR},
+		}
+		c.ReplaceAllParams(map[string]string{":UID": uid})
+
+		resp := srv.RoutePutContactPoint(c, body)
+		return am, resp.Status()
+	}
+
+	receivers := func(cfg *apimodels.PostableUserConfig) map[string][]*apimodels.PostableGrafanaReceiver {
+		result := map[string][]*apimodels.PostableGrafanaReceiver{}
+		for _, r := range cfg.AlertmanagerConfig.Receivers {
+			result[r.Name] = r.PostableGrafanaReceivers.GrafanaManagedReceivers
+		}
+		return result
+	}
+
+	t.Run("creates the contact point if it does not exist", func(t *testing.T) {
+		am, status := put(t, "email", "/contact-points/email", apimodels.PostableGrafanaReceiver{
+			Name:     "team",
+			Type:     "email",
+			Settings: simplejson.NewFromAny(map[string]interface{}{"addresses": "team@example.com"}),
+		})
+		require.Equal(t, http.StatusAccepted, status)
+		require.NotNil(t, am.saved)
+
+		result := receivers(am.saved)
+		require.Len(t, result["ops"], 2)
+		require.Len(t, result["team"], 1)
+		assert.Equal(t, "email", result["team"][0].UID)
+
+		// the existing secure settings are not encrypted twice
+		secret, err := am.saved.GetGrafanaReceiverMap()["slack"].GetDecryptedSecret("url")
+		require.NoError(t, err)
+		assert.Equal(t, "https://hooks.slack.com/secret", secret)
+	})
+
+	t.Run("updates the fields of the field mask", func(t *testing.T) {
+		am, status := put(t, "slack", "/contact-points/slack?fieldMask=settings.recipient", apimodels.PostableGrafanaReceiver{
+			Type:     "ignored",
+			Settings: simplejson.NewFromAny(map[string]interface{}{"recipient": "#alerts", "username": "ignored"}),
+		})
+		require.Equal(t, http.StatusAccepted, status)
+		require.NotNil(t, am.saved)
+
+		result := receivers(am.saved)
+		require.Len(t, result["ops"], 2)
+		slack := am.saved.GetGrafanaReceiverMap()["slack"]
+		assert.Equal(t, "ops", slack.Name)
+		assert.Equal(t, "slack", slack.Type)
+		assert.Equal(t, "#alerts", slack.Settings.Get("recipient").MustString())
+		assert.Equal(t, "grafana", slack.Settings.Get("username").MustString())
+
+		secret, err := slack.GetDecryptedSecret("url")
+		require.NoError(t, err)
+		assert.Equal(t, "https://hooks.slack.com/secret", secret)
+	})
+
+	t.Run("moves the contact point to the receiver of its name", func(t *testing.T) {
+		am, status := put(t, "pagerduty", "/contact-points/pagerduty?fieldMask=name", apimodels.PostableGrafanaReceiver{
+			Name: "on-call",
+		})
+		require.Equal(t, http.StatusAccepted, status)
+
+		result := receivers(am.saved)
+		require.Len(t, result["ops"], 1)
+		require.Len(t, result["on-call"], 1)
+		assert.Equal(t, "pagerduty", result["on-call"][0].UID)
+	})
+
+	t.Run("requires the name and type of a new contact point", func(t *testing.T) {
+		am, status := put(t, "email", "/contact-points/email", apimodels.PostableGrafanaReceiver{Name: "team"})
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Nil(t, am.saved)
+	})
+
+	t.Run("rejects the fields that cannot be updated", func(t *testing.T) {
+		_, status := put(t, "slack", "/contact-points/slack?fieldMask=uid", apimodels.PostableGrafanaReceiver{})
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("rejects a UID not matching the URL", func(t *testing.T) {
+		_, status := put(t, "slack", "/contact-points/slack", apimodels.PostableGrafanaReceiver{UID: "other"})
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+}
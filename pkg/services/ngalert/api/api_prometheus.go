@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
 	"time"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
@@ -61,50 +62,29 @@ func (srv PrometheusSrv) RouteGetRuleStatuses(c *models.ReqContext) response.Res
 		},
 	}
 
-	ruleGroupQuery := ngmodels.ListOrgRuleGroupsQuery{
-		OrgID: c.SignedInUser.OrgId,
-	}
-	if err := srv.store.GetOrgRuleGroups(&ruleGroupQuery); err != nil {
+	groups, err := srv.orgRuleGroups(c.SignedInUser.OrgId)
+	if err != nil {
 		ruleResponse.DiscoveryBase.Status = "error"
-		ruleResponse.DiscoveryBase.Error = fmt.Sprintf("failure getting rule groups: %s", err.Error())
+		ruleResponse.DiscoveryBase.Error = err.Error()
 		ruleResponse.DiscoveryBase.ErrorType = apiv1.ErrServer
 		return response.JSON(http.StatusInternalServerError, ruleResponse)
 	}
 
-	for _, r := range ruleGroupQuery.Result {
-		if len(r) < 3 {
-			continue
-		}
-		groupId, namespaceUID, namespace := r[0], r[1], r[2]
-		alertRuleQuery := ngmodels.ListRuleGroupAlertRulesQuery{OrgID: c.SignedInUser.OrgId, NamespaceUID: namespaceUID, RuleGroup: groupId}
-		if err := srv.store.GetRuleGroupAlertRules(&alertRuleQuery); err != nil {
-			ruleResponse.DiscoveryBase.Status = "error"
-			ruleResponse.DiscoveryBase.Error = fmt.Sprintf("failure getting rules for group %s: %s", groupId, err.Error())
-			ruleResponse.DiscoveryBase.ErrorType = apiv1.ErrServer
-			return response.JSON(http.StatusInternalServerError, ruleResponse)
-		}
-
+	for _, group := range groups {
 		newGroup := &apimodels.RuleGroup{
-			Name: groupId,
+			Name: group.name,
 			// This doesn't make sense in our architecture
 			// so we use this field for passing to the frontend the namespace
-			File:           namespace,
+			File:           group.namespace,
 			LastEvaluation: time.Time{},
 			EvaluationTime: 0, // TODO: see if we are able to pass this along with evaluation results
 		}
 
-		for _, rule := range alertRuleQuery.Result {
-			var queryStr string
-			encodedQuery, err := json.Marshal(rule.Data)
-			if err != nil {
-				queryStr = err.Error()
-			} else {
-				queryStr = string(encodedQuery)
-			}
+		for _, rule := range group.rules {
 			alertingRule := apimodels.AlertingRule{
 				State:       "inactive",
 				Name:        rule.Title,
-				Query:       queryStr,
+				Query:       ruleQuery(rule),
 				Duration:    rule.For.Seconds(),
 				Annotations: rule.Annotations,
 			}
@@ -168,3 +148,191 @@ func (srv PrometheusSrv) RouteGetRuleStatuses(c *models.ReqContext) response.Res
 	}
 	return response.JSON(http.StatusOK, ruleResponse)
 }
+
+// RouteGetCompatAlertStatuses returns the pending and firing alerts of the Grafana managed rules
+// like the alerts API of Prometheus, so that the tooling made for it can consume them.
+func (srv PrometheusSrv) RouteGetCompatAlertStatuses(c *models.ReqContext) response.Response {
+	alertResponse := apimodels.AlertResponse{
+		DiscoveryBase: apimodels.DiscoveryBase{
+			Status: "success",
+		},
+		Data: apimodels.AlertDiscovery{
+			Alerts: []*apimodels.Alert{},
+		},
+	}
+	for _, alertState := range sortStates(srv.manager.GetAll(c.OrgId)) {
+		if alert := compatAlert(alertState); alert != nil {
+			alertResponse.Data.Alerts = append(alertResponse.Data.Alerts, alert)
+		}
+	}
+	return response.JSON(http.StatusOK, alertResponse)
+}
+
+// RouteGetCompatRuleStatuses returns the Grafana managed rules and their state like the rules API
+// of Prometheus: the states are inactive, pending or firing, the health is ok, err or unknown and
+// only the active alerts are listed.
+func (srv PrometheusSrv) RouteGetCompatRuleStatuses(c *models.ReqContext) response.Response {
+	ruleResponse := apimodels.RuleResponse{
+		DiscoveryBase: apimodels.DiscoveryBase{
+			Status: "success",
+		},
+		Data: apimodels.RuleDiscovery{
+			RuleGroups: []*apimodels.RuleGroup{},
+		},
+	}
+
+	switch ruleType := c.Query("type"); ruleType {
+	case "", "alert":
+	case "record":
+		// Grafana managed rules are all alerting rules
+		return response.JSON(http.StatusOK, ruleResponse)
+	default:
+		ruleResponse.DiscoveryBase.Status = "error"
+		ruleResponse.DiscoveryBase.Error = fmt.Sprintf("unsupported rule type %q, expecting alert or record", ruleType)
+		ruleResponse.DiscoveryBase.ErrorType = apiv1.ErrBadData
+		return response.JSON(http.StatusBadRequest, ruleResponse)
+	}
+
+	groups, err := srv.orgRuleGroups(c.SignedInUser.OrgId)
+	if err != nil {
+		ruleResponse.DiscoveryBase.Status = "error"
+		ruleResponse.DiscoveryBase.Error = err.Error()
+		ruleResponse.DiscoveryBase.ErrorType = apiv1.ErrServer
+		return response.JSON(http.StatusInternalServerError, ruleResponse)
+	}
+
+	for _, group := range groups {
+		newGroup := &apimodels.RuleGroup{
+			Name:  group.name,
+			File:  group.namespace,
+			Rules: []apimodels.AlertingRule{},
+		}
+
+		for _, rule := range group.rules {
+			query := ruleQuery(rule)
+			alertingRule := apimodels.AlertingRule{
+				State:       "inactive",
+				Name:        rule.Title,
+				Query:       query,
+				Duration:    rule.For.Seconds(),
+				Annotations: rule.Annotations,
+				Alerts:      []*apimodels.Alert{},
+				Rule: apimodels.Rule{
+					Name:   rule.Title,
+					Query:  query,
+					Labels: rule.Labels,
+					// the rule is unknown until it's evaluated
+					Health: "unknown",
+					Type:   apiv1.RuleTypeAlerting,
+				},
+			}
+
+			for _, alertState := range sortStates(srv.manager.GetStatesForRuleUID(c.OrgId, rule.UID)) {
+				if alertingRule.Health == "unknown" {
+					alertingRule.Health = "ok"
+				}
+				if alertState.LastEvaluationTime.After(alertingRule.LastEvaluation) {
+					alertingRule.LastEvaluation = alertState.LastEvaluationTime
+					alertingRule.EvaluationTime = alertState.EvaluationDuration.Seconds()
+				}
+				if alertState.State == eval.Error || alertState.Error != nil {
+					alertingRule.Health = "err"
+					if alertState.Error != nil {
+						alertingRule.LastError = alertState.Error.Error()
+					}
+				}
+
+				alert := compatAlert(alertState)
+				if alert == nil {
+					continue
+				}
+				if alert.State == "firing" || alertingRule.State == "inactive" {
+					alertingRule.State = alert.State
+				}
+				alertingRule.Alerts = append(alertingRule.Alerts, alert)
+			}
+
+			if alertingRule.LastEvaluation.After(newGroup.LastEvaluation) {
+				newGroup.LastEvaluation = alertingRule.LastEvaluation
+			}
+			newGroup.EvaluationTime += alertingRule.EvaluationTime
+			newGroup.Interval = float64(rule.IntervalSeconds)
+			newGroup.Rules = append(newGroup.Rules, alertingRule)
+		}
+		ruleResponse.Data.RuleGroups = append(ruleResponse.Data.RuleGroups, newGroup)
+	}
+	return response.JSON(http.StatusOK, ruleResponse)
+}
+
+type ruleGroup struct {
+	name, namespace string
+	rules           []*ngmodels.AlertRule
+}
+
+// orgRuleGroups returns the rule groups of the org with their rules.
+func (srv PrometheusSrv) orgRuleGroups(orgID int64) ([]ruleGroup, error) {
+	ruleGroupQuery := ngmodels.ListOrgRuleGroupsQuery{
+		OrgID: orgID,
+	}
+	if err := srv.store.GetOrgRuleGroups(&ruleGroupQuery); err != nil {
+		return nil, fmt.Errorf("failure getting rule groups: %w", err)
+	}
+
+	groups := make([]ruleGroup, 0, len(ruleGroupQuery.Result))
+	for _, r := range ruleGroupQuery.Result {
+		if len(r) < 3 {
+			continue
+		}
+		groupId, namespaceUID, namespace := r[0], r[1], r[2]
+		alertRuleQuery := ngmodels.ListRuleGroupAlertRulesQuery{OrgID: orgID, NamespaceUID: namespaceUID, RuleGroup: groupId}
+		if err := srv.store.GetRuleGroupAlertRules(&alertRuleQuery); err != nil {
+			return nil, fmt.Errorf("failure getting rules for group %s: %w", groupId, err)
+		}
+		groups = append(groups, ruleGroup{name: groupId, namespace: namespace, rules: alertRuleQuery.Result})
+	}
+	return groups, nil
+}
+
+// ruleQuery returns the queries and expressions of the rule, encoded in JSON.
+func ruleQuery(rule *ngmodels.AlertRule) string {
+	encodedQuery, err := json.Marshal(rule.Data)
+	if err != nil {
+		return err.Error()
+	}
+	return string(encodedQuery)
+}
+
+// compatAlert returns the alert of the state as Prometheus reports it, or nil when it isn't
+// active: Prometheus only lists the pending and firing alerts.
+func compatAlert(alertState *state.State) *apimodels.Alert {
+	var promState string
+	switch alertState.State {
+	case eval.Pending:
+		promState = "pending"
+	case eval.Alerting:
+		promState = "firing"
+	default:
+		return nil
+	}
+
+	valString := ""
+	if len(alertState.Results) > 0 {
+		valString = alertState.Results[0].EvaluationString
+	}
+	activeAt := alertState.StartsAt
+	return &apimodels.Alert{
+		Labels:      map[string]string(alertState.Labels),
+		Annotations: alertState.Annotations,
+		State:       promState,
+		ActiveAt:    &activeAt,
+		Value:       valString,
+	}
+}
+
+// sortStates sorts the states by their labels, for the responses to be stable.
+func sortStates(states []*state.State) []*state.State {
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].CacheId < states[j].CacheId
+	})
+	return states
+}
@@ -0,0 +1,190 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/macaron.v1"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+type fakeRuleStore struct {
+	store.RuleStore
+	// groups are the names of the rule groups, and rules their rules by group name
+	groups []string
+	rules  map[string][]*ngmodels.AlertRule
+	err    error
+}
+
+func (s fakeRuleStore) GetOrgRuleGroups(query *ngmodels.ListOrgRuleGroupsQuery) error {
+	if s.err != nil {
+		return s.err
+	}
+	for _, group := range s.groups {
+		query.Result = append(query.Result, []string{group, "folder-uid", "Folder"})
+	}
+	return nil
+}
+
+func (s fakeRuleStore) GetRuleGroupAlertRules(query *ngmodels.ListRuleGroupAlertRulesQuery) error {
+	query.Result = s.rules[query.RuleGroup]
+	return nil
+}
+
+func TestRouteGetCompatStatuses(t *testing.T) {
+	now := time.Date(2021, 5, 1, 10, 0, 0, 0, time.UTC)
+	rules := map[string][]*ngmodels.AlertRule{
+		"group": {
+			{UID: "firing", Title: "Firing", For: time.Minute, IntervalSeconds: 10, Labels: map[string]string{"team": "a"}},
+			{UID: "failing", Title: "Failing", IntervalSeconds: 10},
+			{UID: "new", Title: "New", IntervalSeconds: 10},
+		},
+	}
+
+	manager := state.NewManager(log.New("test"), metrics.NewMetrics(prometheus.NewRegistry()))
+	t.Cleanup(manager.Close)
+	manager.Put([]*state.State{
+		{
+			AlertRuleUID:       "firing",
+			OrgID:              1,
+			CacheId:            "firing-1",
+			State:              eval.Alerting,
+			Results:            []state.Evaluation{{EvaluationTime: now, EvaluationState: eval.Alerting, EvaluationString: "value=3"}},
+			StartsAt:           now.Add(-time.Minute),
+			LastEvaluationTime: now,
+			EvaluationDuration: time.Second,
+			Labels:             map[string]string{"alertname": "Firing", "instance": "1"},
+			Annotations:        map[string]string{"summary": "it fires"},
+		},
+		{
+			AlertRuleUID:       "firing",
+			OrgID:              1,
+			CacheId:            "firing-2",
+			State:              eval.Pending,
+			StartsAt:           now,
+			LastEvaluationTime: now,
+			Labels:             map[string]string{"alertname": "Firing", "instance": "2"},
+		},
+		{
+			AlertRuleUID:       "firing",
+			OrgID:              1,
+			CacheId:            "firing-3",
+			State:              eval.Normal,
+			LastEvaluationTime: now,
+			Labels:             map[string]string{"alertname": "Firing", "instance": "3"},
+		},
+		{
+			AlertRuleUID:       "failing",
+			OrgID:              1,
+			CacheId:            "failing-1",
+			State:              eval.Error,
+			Error:              errors.New("query failed"),
+			LastEvaluationTime: now,
+			Labels:             map[string]string{"alertname": "Failing"},
+		},
+	})
+
+	srv := PrometheusSrv{log: log.New("test"), manager: manager, store: fakeRuleStore{groups: []string{"group"}, rules: rules}}
+
+	newContext := func(url string) *models.ReqContext {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		require.NoError(t, err)
+		return &models.ReqContext{
+			Context:      &macaron.Context{Req: macaron.Request{Request: req}},
+			SignedInUser: &models.SignedInUser{OrgId: 1},
+		}
+	}
+
+	t.Run("alerts only lists the active alerts", func(t *testing.T) {
+		resp := srv.RouteGetCompatAlertStatuses(newContext("/api/v1/alerts"))
+		require.Equal(t, http.StatusOK, resp.Status())
+
+		var result apimodels.AlertResponse
+		require.NoError(t, json.Unmarshal(resp.Body(), &result))
+		require.Equal(t, "success", result.Status)
+		require.Len(t, result.Data.Alerts, 2)
+
+		assert.Equal(t, "firing", result.Data.Alerts[0].State)
+		assert.Equal(t, "value=3", result.Data.Alerts[0].Value)
+		assert.Equal(t, "1", result.Data.Alerts[0].Labels["instance"])
+		assert.Equal(t, "it fires", result.Data.Alerts[0].Annotations["summary"])
+		assert.True(t, now.Add(-time.Minute).Equal(*result.Data.Alerts[0].ActiveAt))
+		assert.Equal(t, "pending", result.Data.Alerts[1].State)
+	})
+
+	t.Run("rules have the Prometheus states and health", func(t *testing.T) {
+		resp := srv.RouteGetCompatRuleStatuses(newContext("/api/v1/rules"))
+		require.Equal(t, http.StatusOK, resp.Status())
+
+		var result apimodels.RuleResponse
+		require.NoError(t, json.Unmarshal(resp.Body(), &result))
+		require.Len(t, result.Data.RuleGroups, 1)
+		group := result.Data.RuleGroups[0]
+		assert.Equal(t, "group", group.Name)
+		assert.Equal(t, "Folder", group.File)
+		assert.Equal(t, float64(10), group.Interval)
+		assert.True(t, now.Equal(group.LastEvaluation))
+		require.Len(t, group.Rules, 3)
+
+		firing := group.Rules[0]
+		assert.Equal(t, "firing", firing.State)
+		assert.Equal(t, "ok", firing.Health)
+		assert.Equal(t, float64(60), firing.Duration)
+		assert.Equal(t, float64(1), firing.EvaluationTime)
+		assert.Equal(t, "a", firing.Labels["team"])
+		require.Len(t, firing.Alerts, 2)
+		assert.Equal(t, "firing", firing.Alerts[0].State)
+		assert.Equal(t, "pending", firing.Alerts[1].State)
+
+		failing := group.Rules[1]
+		assert.Equal(t, "inactive", failing.State)
+		assert.Equal(t, "err", failing.Health)
+		assert.Equal(t, "query failed", failing.LastError)
+		assert.Empty(t, failing.Alerts)
+
+		assert.Equal(t, "unknown", group.Rules[2].Health)
+	})
+
+	t.Run("recording rules are empty", func(t *testing.T) {
+		resp := srv.RouteGetCompatRuleStatuses(newContext("/api/v1/rules?type=record"))
+		require.Equal(t, http.StatusOK, resp.Status())
+
+		var result apimodels.RuleResponse
+		require.NoError(t, json.Unmarshal(resp.Body(), &result))
+		assert.Empty(t, result.Data.RuleGroups)
+	})
+
+	t.Run("unsupported rule type is rejected", func(t *testing.T) {
+		resp := srv.RouteGetCompatRuleStatuses(newContext("/api/v1/rules?type=unknown"))
+		require.Equal(t, http.StatusBadRequest, resp.Status())
+
+		var result apimodels.RuleResponse
+		require.NoError(t, json.Unmarshal(resp.Body(), &result))
+		assert.Equal(t, "error", result.Status)
+		assert.Equal(t, "bad_data", string(result.ErrorType))
+	})
+
+	t.Run("store errors are returned", func(t *testing.T) {
+		srv := PrometheusSrv{log: log.New("test"), manager: manager, store: fakeRuleStore{err: errors.New("db down")}}
+		resp := srv.RouteGetCompatRuleStatuses(newContext("/api/v1/rules"))
+		require.Equal(t, http.StatusInternalServerError, resp.Status())
+
+		var result apimodels.RuleResponse
+		require.NoError(t, json.Unmarshal(resp.Body(), &result))
+		assert.Equal(t, "failure getting rule groups: db down", result.Error)
+	})
+}
@@ -1,11 +1,13 @@
 package api
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
+	"github.com/grafana/grafana/pkg/services/audit"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
@@ -13,6 +15,8 @@ import (
 
 	coreapi "github.com/grafana/grafana/pkg/api"
 	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
@@ -36,6 +40,14 @@ func (srv RulerSrv) RouteDeleteNamespaceRulesConfig(c *models.ReqContext) respon
 		return toNamespaceErrorResponse(err)
 	}
 
+	q := ngmodels.ListNamespaceAlertRulesQuery{
+		OrgID:        c.SignedInUser.OrgId,
+		NamespaceUID: namespace.Uid,
+	}
+	if err := srv.store.GetNamespaceAlertRules(&q); err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to get namespace alert rules", err)
+	}
+
 	uids, err := srv.store.DeleteNamespaceAlertRules(c.SignedInUser.OrgId, namespace.Uid)
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, "failed to delete namespace alert rules", err)
@@ -44,6 +56,7 @@ func (srv RulerSrv) RouteDeleteNamespaceRulesConfig(c *models.ReqContext) respon
 	for _, uid := range uids {
 		srv.manager.RemoveByRuleUID(c.SignedInUser.OrgId, uid)
 	}
+	recordRuleChanges(c, q.Result, nil)
 
 	return response.JSON(http.StatusAccepted, util.DynMap{"message": "namespace rules deleted"})
 }
@@ -55,6 +68,15 @@ func (srv RulerSrv) RouteDeleteRuleGroupConfig(c *models.ReqContext) response.Re
 		return toNamespaceErrorResponse(err)
 	}
 	ruleGroup := c.Params(":Groupname")
+	q := ngmodels.ListRuleGroupAlertRulesQuery{
+		OrgID:        c.SignedInUser.OrgId,
+		NamespaceUID: namespace.Uid,
+		RuleGroup:    ruleGroup,
+	}
+	if err := srv.store.GetRuleGroupAlertRules(&q); err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to get group alert rules", err)
+	}
+
 	uids, err := srv.store.DeleteRuleGroupAlertRules(c.SignedInUser.OrgId, namespace.Uid, ruleGroup)
 
 	if err != nil {
@@ -67,6 +89,7 @@ func (srv RulerSrv) RouteDeleteRuleGroupConfig(c *models.ReqContext) response.Re
 	for _, uid := range uids {
 		srv.manager.RemoveByRuleUID(c.SignedInUser.OrgId, uid)
 	}
+	recordRuleChanges(c, q.Result, nil)
 
 	return response.JSON(http.StatusAccepted, util.DynMap{"message": "rule group deleted"})
 }
@@ -243,6 +266,16 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *models.ReqContext, ruleGroupConf
 		alertRuleUIDs = append(alertRuleUIDs, r.GrafanaManagedAlert.UID)
 	}
 
+	groupQuery := ngmodels.ListRuleGroupAlertRulesQuery{
+		OrgID:        c.SignedInUser.OrgId,
+		NamespaceUID: namespace.Uid,
+		RuleGroup:    ruleGroupConfig.Name,
+	}
+	if err := srv.store.GetRuleGroupAlertRules(&groupQuery); err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to get group alert rules", err)
+	}
+	existing := groupQuery.Result
+
 	if err := srv.store.UpdateRuleGroup(store.UpdateRuleGroupCmd{
 		OrgID:           c.SignedInUser.OrgId,
 		NamespaceUID:    namespace.Uid,
@@ -260,9 +293,270 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *models.ReqContext, ruleGroupConf
 		srv.manager.RemoveByRuleUID(c.OrgId, uid)
 	}
 
+	groupQuery.Result = nil
+	if err := srv.store.GetRuleGroupAlertRules(&groupQuery); err != nil {
+		srv.log.Error("failed to get the updated rule group to record its changes", "group", ruleGroupConfig.Name, "err", err)
+	} else {
+		recordRuleChanges(c, existing, groupQuery.Result)
+	}
+
 	return response.JSON(http.StatusAccepted, util.DynMap{"message": "rule group updated successfully"})
 }
 
+func (srv RulerSrv) RouteGetRuleVersion(c *models.ReqContext) response.Response {
+	rule, namespace, errResp := srv.getRuleForVersions(c, false)
+	if errResp != nil {
+		return errResp
+	}
+	v, errResp := srv.getRuleVersion(c, rule.UID, c.ParamsInt64(":Version"))
+	if errResp != nil {
+		return errResp
+	}
+
+	result := toGettableRuleVersion(*v)
+	node := toGettableExtendedRuleNode(versionToAlertRule(*rule, *v), namespace.Id)
+	result.Rule = &node
+	return response.JSON(http.StatusOK, result)
+}
+
+// RouteGetRuleVersionDiff returns the diff of a version of a rule with the base query parameter version, or else with
+// the previous version, in the format of the diffType query parameter like the diffs of the dashboards.
+func (srv RulerSrv) RouteGetRuleVersionDiff(c *models.ReqContext) response.Response {
+	rule, namespace, errResp := srv.getRuleForVersions(c, false)
+	if errResp != nil {
+		return errResp
+	}
+
+	newVersion := c.ParamsInt64(":Version")
+	baseVersion := c.QueryInt64("base")
+	if baseVersion == 0 {
+		baseVersion = newVersion - 1
+	}
+	if baseVersion < 1 {
+		return response.Error(http.StatusBadRequest, fmt.Sprintf("version %d has no previous version to compare with", newVersion), nil)
+	}
+
+	baseVer, errResp := srv.getRuleVersion(c, rule.UID, baseVersion)
+	if errResp != nil {
+		return errResp
+	}
+	newVer, errResp := srv.getRuleVersion(c, rule.UID, newVersion)
+	if errResp != nil {
+		return errResp
+	}
+
+	baseData, err := ruleVersionDiffData(versionToAlertRule(*rule, *baseVer), namespace.Id)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to encode rule version", err)
+	}
+	newData, err := ruleVersionDiffData(versionToAlertRule(*rule, *newVer), namespace.Id)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to encode rule version", err)
+	}
+
+	diffType := dashdiffs.ParseDiffType(c.Query("diffType"))
+	result, err := dashdiffs.CalculateJSONDiff(baseData, newData, diffType)
+	if err != nil {
+		if errors.Is(err, dashdiffs.ErrNilDiff) {
+			return response.Error(http.StatusBadRequest, "the versions have no differences", err)
+		}
+		return response.Error(http.StatusInternalServerError, "unable to compute diff", err)
+	}
+
+	if diffType == dashdiffs.DiffDelta {
+		return response.Respond(http.StatusOK, result.Delta).SetHeader("Content-Type", "application/json")
+	}
+	return response.Respond(http.StatusOK, result.Delta).SetHeader("Content-Type", "text/html")
+}
+
+func (srv RulerSrv) RouteGetRuleVersions(c *models.ReqContext) response.Response {
+	rule, _, errResp := srv.getRuleForVersions(c, false)
+	if errResp != nil {
+		return errResp
+	}
+
+	q := ngmodels.ListAlertRuleVersionsQuery{
+		OrgID:   c.SignedInUser.OrgId,
+		RuleUID: rule.UID,
+	}
+	if err := srv.store.GetAlertRuleVersions(&q); err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to get rule versions", err)
+	}
+
+	result := make(apimodels.RuleVersionsResponse, 0, len(q.Result))
+	for _, v := range q.Result {
+		result = append(result, toGettableRuleVersion(*v))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// RoutePostRestoreRuleVersion updates a rule with the definition of one of its versions, in its current namespace and
+// rule group.
+func (srv RulerSrv) RoutePostRestoreRuleVersion(c *models.ReqContext, body apimodels.RestoreRuleVersion) response.Response {
+	rule, _, errResp := srv.getRuleForVersions(c, true)
+	if errResp != nil {
+		return errResp
+	}
+	v, errResp := srv.getRuleVersion(c, rule.UID, body.Version)
+	if errResp != nil {
+		return errResp
+	}
+
+	restored := versionToAlertRule(*rule, *v)
+	cond := ngmodels.Condition{
+		Condition: restored.Condition,
+		OrgID:     c.SignedInUser.OrgId,
+		Data:      restored.Data,
+	}
+	if err := validateCondition(cond, c.SignedInUser, c.SkipCache, srv.DatasourceCache); err != nil {
+		return response.Error(http.StatusBadRequest, fmt.Sprintf("failed to validate alert rule %s", restored.Title), err)
+	}
+
+	if err := srv.store.UpsertAlertRules([]store.UpsertRule{{
+		Existing:     rule,
+		New:          restored,
+		RestoredFrom: v.Version,
+	}}); err != nil {
+		if errors.Is(err, ngmodels.ErrAlertRuleFailedValidation) {
+			return response.Error(http.StatusBadRequest, "failed to restore rule version", err)
+		}
+		return response.Error(http.StatusInternalServerError, "failed to restore rule version", err)
+	}
+
+	srv.manager.RemoveByRuleUID(c.SignedInUser.OrgId, rule.UID)
+	restored.Version = rule.Version + 1
+	recordRuleChange(c, &restored, models.ChangeEventRestored, rule.Version)
+
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": fmt.Sprintf("rule restored from version %d", v.Version)})
+}
+
+// getRuleForVersions returns the rule of the request and its namespace, after checking the user may read, or save when
+// write is true, the namespace. The error response is returned when it can't be used.
+func (srv RulerSrv) getRuleForVersions(c *models.ReqContext, write bool) (*ngmodels.AlertRule, *models.Folder, response.Response) {
+	q := ngmodels.GetAlertRuleByUIDQuery{
+		UID:   c.Params(":RuleUID"),
+		OrgID: c.SignedInUser.OrgId,
+	}
+	if err := srv.store.GetAlertRuleByUID(&q); err != nil {
+		if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+			return nil, nil, response.Error(http.StatusNotFound, "rule not found", err)
+		}
+		return nil, nil, response.Error(http.StatusInternalServerError, "failed to get rule", err)
+	}
+
+	namespace, err := srv.store.GetNamespaceByUID(q.Result.NamespaceUID, c.SignedInUser.OrgId, c.SignedInUser)
+	if err != nil {
+		return nil, nil, toNamespaceErrorResponse(err)
+	}
+	if write {
+		// checks the user may save the rules of the namespace
+		namespace, err = srv.store.GetNamespaceByTitle(namespace.Title, c.SignedInUser.OrgId, c.SignedInUser, true)
+		if err != nil {
+			return nil, nil, toNamespaceErrorResponse(err)
+		}
+	}
+	return q.Result, namespace, nil
+}
+
+func (srv RulerSrv) getRuleVersion(c *models.ReqContext, ruleUID string, version int64) (*ngmodels.AlertRuleVersion, response.Response) {
+	q := ngmodels.GetAlertRuleVersionQuery{
+		OrgID:   c.SignedInUser.OrgId,
+		RuleUID: ruleUID,
+		Version: version,
+	}
+	if err := srv.store.GetAlertRuleVersion(&q); err != nil {
+		if errors.Is(err, ngmodels.ErrAlertRuleVersionNotFound) {
+			return nil, response.Error(http.StatusNotFound, fmt.Sprintf("rule version %d not found", version), err)
+		}
+		return nil, response.Error(http.StatusInternalServerError, "failed to get rule version", err)
+	}
+	return q.Result, nil
+}
+
+// versionToAlertRule returns the rule with the definition of one of its versions.
+func versionToAlertRule(rule ngmodels.AlertRule, v ngmodels.AlertRuleVersion) ngmodels.AlertRule {
+	rule.Version = v.Version
+	rule.Updated = v.Created
+	rule.Title = v.Title
+	rule.Condition = v.Condition
+	rule.Data = v.Data
+	rule.IntervalSeconds = v.IntervalSeconds
+	rule.NoDataState = v.NoDataState
+	rule.ExecErrState = v.ExecErrState
+	rule.For = v.For
+	rule.Annotations = v.Annotations
+	rule.Labels = v.Labels
+	return rule
+}
+
+// ruleVersionDiffData returns the JSON representation of a rule compared by the diffs, without the fields changed by
+// every version.
+func ruleVersionDiffData(rule ngmodels.AlertRule, namespaceID int64) (*simplejson.Json, error) {
+	rule.Version = 0
+	rule.Updated = time.Time{}
+	b, err := json.Marshal(toGettableExtendedRuleNode(rule, namespaceID))
+	if err != nil {
+		return nil, err
+	}
+	return simplejson.NewJson(b)
+}
+
+func toGettableRuleVersion(v ngmodels.AlertRuleVersion) apimodels.GettableRuleVersion {
+	return apimodels.GettableRuleVersion{
+		Version:       v.Version,
+		ParentVersion: v.ParentVersion,
+		RestoredFrom:  v.RestoredFrom,
+		Created:       v.Created,
+		Title:         v.Title,
+	}
+}
+
+// recordRuleChanges records the changes of the alert rules from the rules before a change to the rules after it, the
+// rules missing after the change being deleted.
+func recordRuleChanges(c *models.ReqContext, before, after []*ngmodels.AlertRule) {
+	previous := make(map[string]*ngmodels.AlertRule, len(before))
+	for _, r := range before {
+		previous[r.UID] = r
+	}
+	for _, r := range after {
+		p, ok := previous[r.UID]
+		delete(previous, r.UID)
+		switch {
+		case !ok && r.Version == 1:
+			recordRuleChange(c, r, models.ChangeEventCreated, 0)
+		case !ok:
+			// moved from another group
+			recordRuleChange(c, r, models.ChangeEventUpdated, r.Version-1)
+		case p.Version != r.Version:
+			recordRuleChange(c, r, models.ChangeEventUpdated, p.Version)
+		}
+	}
+	for _, r := range before {
+		if _, ok := previous[r.UID]; ok {
+			recordRuleChange(c, r, models.ChangeEventDeleted, r.Version)
+		}
+	}
+}
+
+// recordRuleChange records the change of an alert rule from the old version to its current version, or its deletion.
+func recordRuleChange(c *models.ReqContext, r *ngmodels.AlertRule, action models.ChangeEventAction, oldVersion int64) {
+	cmd := &models.AddChangeEventCommand{
+		OrgId:          c.SignedInUser.OrgId,
+		ResourceType:   models.ChangeEventAlertRule,
+		ResourceId:     r.ID,
+		ResourceUid:    r.UID,
+		ResourceName:   r.Title,
+		Action:         action,
+		OldVersion:     oldVersion,
+		UserId:         c.SignedInUser.UserId,
+		ImpersonatorId: c.SignedInUser.ImpersonatorId,
+	}
+	if action != models.ChangeEventDeleted {
+		cmd.NewVersion = r.Version
+	}
+	audit.RecordChange(cmd)
+}
+
 func toGettableExtendedRuleNode(r ngmodels.AlertRule, namespaceID int64) apimodels.GettableExtendedRuleNode {
 	gettableExtendedRuleNode := apimodels.GettableExtendedRuleNode{
 		GrafanaManagedAlert: &apimodels.GettableGrafanaRule{
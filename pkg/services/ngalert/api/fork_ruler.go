@@ -127,3 +127,45 @@ func (r *ForkedRuler) RoutePostNameRulesConfig(ctx *models.ReqContext, conf apim
 		return response.Error(400, fmt.Sprintf("unexpected backend type (%v)", backendType), nil)
 	}
 }
+
+// RouteGetRuleVersion is only served by Grafana, the versions are only stored for the Grafana managed rules.
+func (r *ForkedRuler) RouteGetRuleVersion(ctx *models.ReqContext) response.Response {
+	return r.forkGrafanaOnly(ctx, r.GrafanaRuler.RouteGetRuleVersion, r.LotexRuler.RouteGetRuleVersion)
+}
+
+// RouteGetRuleVersionDiff is only served by Grafana, the versions are only stored for the Grafana managed rules.
+func (r *ForkedRuler) RouteGetRuleVersionDiff(ctx *models.ReqContext) response.Response {
+	return r.forkGrafanaOnly(ctx, r.GrafanaRuler.RouteGetRuleVersionDiff, r.LotexRuler.RouteGetRuleVersionDiff)
+}
+
+// RouteGetRuleVersions is only served by Grafana, the versions are only stored for the Grafana managed rules.
+func (r *ForkedRuler) RouteGetRuleVersions(ctx *models.ReqContext) response.Response {
+	return r.forkGrafanaOnly(ctx, r.GrafanaRuler.RouteGetRuleVersions, r.LotexRuler.RouteGetRuleVersions)
+}
+
+// RoutePostRestoreRuleVersion is only served by Grafana, the versions are only stored for the Grafana managed rules.
+func (r *ForkedRuler) RoutePostRestoreRuleVersion(ctx *models.ReqContext, body apimodels.RestoreRuleVersion) response.Response {
+	return r.forkGrafanaOnly(ctx,
+		func(ctx *models.ReqContext) response.Response {
+			return r.GrafanaRuler.RoutePostRestoreRuleVersion(ctx, body)
+		},
+		func(ctx *models.ReqContext) response.Response {
+			return r.LotexRuler.RoutePostRestoreRuleVersion(ctx, body)
+		},
+	)
+}
+
+func (r *ForkedRuler) forkGrafanaOnly(ctx *models.ReqContext, grafana, lotex func(*models.ReqContext) response.Response) response.Response {
+	t, err := backendType(ctx, r.DatasourceCache)
+	if err != nil {
+		return response.Error(400, err.Error(), nil)
+	}
+	switch t {
+	case apimodels.GrafanaBackend:
+		return grafana(ctx)
+	case apimodels.LoTexRulerBackend:
+		return lotex(ctx)
+	default:
+		return response.Error(400, fmt.Sprintf("unexpected backend type (%v)", t), nil)
+	}
+}
@@ -141,3 +141,21 @@ func (am *ForkedAMSvc) RoutePostAMAlerts(ctx *models.ReqContext, body apimodels.
 
 	return s.RoutePostAMAlerts(ctx, body)
 }
+
+func (am *ForkedAMSvc) RouteTestRoutes(ctx *models.ReqContext, body apimodels.TestRoutesPayload) response.Response {
+	s, err := am.getService(ctx)
+	if err != nil {
+		return response.Error(400, err.Error(), nil)
+	}
+
+	return s.RouteTestRoutes(ctx, body)
+}
+
+func (am *ForkedAMSvc) RoutePutContactPoint(ctx *models.ReqContext, body apimodels.PostableGrafanaReceiver) response.Response {
+	s, err := am.getService(ctx)
+	if err != nil {
+		return response.Error(400, err.Error(), nil)
+	}
+
+	return s.RoutePutContactPoint(ctx, body)
+}
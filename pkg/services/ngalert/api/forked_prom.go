@@ -54,3 +54,15 @@ func (p *ForkedPromSvc) RouteGetRuleStatuses(ctx *models.ReqContext) response.Re
 		return response.Error(400, fmt.Sprintf("unexpected backend type (%v)", t), nil)
 	}
 }
+
+// RouteGetCompatAlertStatuses is only served by Grafana, the alerts of the data sources are
+// available from their own Prometheus API.
+func (p *ForkedPromSvc) RouteGetCompatAlertStatuses(ctx *models.ReqContext) response.Response {
+	return p.GrafanaSvc.RouteGetCompatAlertStatuses(ctx)
+}
+
+// RouteGetCompatRuleStatuses is only served by Grafana, the rules of the data sources are
+// available from their own Prometheus API.
+func (p *ForkedPromSvc) RouteGetCompatRuleStatuses(ctx *models.ReqContext) response.Response {
+	return p.GrafanaSvc.RouteGetCompatRuleStatuses(ctx)
+}
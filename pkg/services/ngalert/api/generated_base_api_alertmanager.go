@@ -10,8 +10,6 @@ package api
 import (
 	"net/http"
 
-	"github.com/go-macaron/binding"
-
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/middleware"
@@ -31,13 +29,15 @@ type AlertmanagerApiService interface {
 	RouteGetSilences(*models.ReqContext) response.Response
 	RoutePostAMAlerts(*models.ReqContext, apimodels.PostableAlerts) response.Response
 	RoutePostAlertingConfig(*models.ReqContext, apimodels.PostableUserConfig) response.Response
+	RoutePutContactPoint(*models.ReqContext, apimodels.PostableGrafanaReceiver) response.Response
+	RouteTestRoutes(*models.ReqContext, apimodels.TestRoutesPayload) response.Response
 }
 
 func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApiService, m *metrics.Metrics) {
 	api.RouteRegister.Group("", func(group routing.RouteRegister) {
 		group.Post(
 			toMacaronPath("/api/alertmanager/{Recipient}/api/v2/silences"),
-			binding.Bind(apimodels.PostableSilence{}),
+			routing.Bind(apimodels.PostableSilence{}),
 			metrics.Instrument(
 				http.MethodPost,
 				"/api/alertmanager/{Recipient}/api/v2/silences",
@@ -110,7 +110,7 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApiService, m *
 		)
 		group.Post(
 			toMacaronPath("/api/alertmanager/{Recipient}/api/v2/alerts"),
-			binding.Bind(apimodels.PostableAlerts{}),
+			routing.Bind(apimodels.PostableAlerts{}),
 			metrics.Instrument(
 				http.MethodPost,
 				"/api/alertmanager/{Recipient}/api/v2/alerts",
@@ -120,7 +120,7 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApiService, m *
 		)
 		group.Post(
 			toMacaronPath("/api/alertmanager/{Recipient}/config/api/v1/alerts"),
-			binding.Bind(apimodels.PostableUserConfig{}),
+			routing.Bind(apimodels.PostableUserConfig{}),
 			metrics.Instrument(
 				http.MethodPost,
 				"/api/alertmanager/{Recipient}/config/api/v1/alerts",
@@ -128,5 +128,25 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApiService, m *
 				m,
 			),
 		)
+		group.Put(
+			toMacaronPath("/api/alertmanager/{Recipient}/config/api/v1/contact-points/{UID}"),
+			routing.Bind(apimodels.PostableGrafanaReceiver{}),
+			metrics.Instrument(
+				http.MethodPut,
+				"/api/alertmanager/{Recipient}/config/api/v1/contact-points/{UID}",
+				srv.RoutePutContactPoint,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/alertmanager/{Recipient}/config/api/v1/routes/test"),
+			routing.Bind(apimodels.TestRoutesPayload{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/alertmanager/{Recipient}/config/api/v1/routes/test",
+				srv.RouteTestRoutes,
+				m,
+			),
+		)
 	}, middleware.ReqSignedIn)
 }
@@ -19,6 +19,8 @@ import (
 
 type PrometheusApiService interface {
 	RouteGetAlertStatuses(*models.ReqContext) response.Response
+	RouteGetCompatAlertStatuses(*models.ReqContext) response.Response
+	RouteGetCompatRuleStatuses(*models.ReqContext) response.Response
 	RouteGetRuleStatuses(*models.ReqContext) response.Response
 }
 
@@ -33,6 +35,24 @@ func (api *API) RegisterPrometheusApiEndpoints(srv PrometheusApiService, m *metr
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/v1/alerts"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/alerts",
+				srv.RouteGetCompatAlertStatuses,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/rules"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/rules",
+				srv.RouteGetCompatRuleStatuses,
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/prometheus/{Recipient}/api/v1/rules"),
 			metrics.Instrument(
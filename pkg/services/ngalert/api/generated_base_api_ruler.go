@@ -10,8 +10,6 @@ package api
 import (
 	"net/http"
 
-	"github.com/go-macaron/binding"
-
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/middleware"
@@ -24,9 +22,13 @@ type RulerApiService interface {
 	RouteDeleteNamespaceRulesConfig(*models.ReqContext) response.Response
 	RouteDeleteRuleGroupConfig(*models.ReqContext) response.Response
 	RouteGetNamespaceRulesConfig(*models.ReqContext) response.Response
+	RouteGetRuleVersion(*models.ReqContext) response.Response
+	RouteGetRuleVersionDiff(*models.ReqContext) response.Response
+	RouteGetRuleVersions(*models.ReqContext) response.Response
 	RouteGetRulegGroupConfig(*models.ReqContext) response.Response
 	RouteGetRulesConfig(*models.ReqContext) response.Response
 	RoutePostNameRulesConfig(*models.ReqContext, apimodels.PostableRuleGroupConfig) response.Response
+	RoutePostRestoreRuleVersion(*models.ReqContext, apimodels.RestoreRuleVersion) response.Response
 }
 
 func (api *API) RegisterRulerApiEndpoints(srv RulerApiService, m *metrics.Metrics) {
@@ -58,6 +60,33 @@ func (api *API) RegisterRulerApiEndpoints(srv RulerApiService, m *metrics.Metric
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/ruler/{Recipient}/api/v1/rule/{RuleUID}/versions/{Version}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/ruler/{Recipient}/api/v1/rule/{RuleUID}/versions/{Version}",
+				srv.RouteGetRuleVersion,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/ruler/{Recipient}/api/v1/rule/{RuleUID}/versions/{Version}/diff"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/ruler/{Recipient}/api/v1/rule/{RuleUID}/versions/{Version}/diff",
+				srv.RouteGetRuleVersionDiff,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/ruler/{Recipient}/api/v1/rule/{RuleUID}/versions"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/ruler/{Recipient}/api/v1/rule/{RuleUID}/versions",
+				srv.RouteGetRuleVersions,
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/ruler/{Recipient}/api/v1/rules/{Namespace}/{Groupname}"),
 			metrics.Instrument(
@@ -78,7 +107,7 @@ func (api *API) RegisterRulerApiEndpoints(srv RulerApiService, m *metrics.Metric
 		)
 		group.Post(
 			toMacaronPath("/api/ruler/{Recipient}/api/v1/rules/{Namespace}"),
-			binding.Bind(apimodels.PostableRuleGroupConfig{}),
+			routing.Bind(apimodels.PostableRuleGroupConfig{}),
 			metrics.Instrument(
 				http.MethodPost,
 				"/api/ruler/{Recipient}/api/v1/rules/{Namespace}",
@@ -86,5 +115,15 @@ func (api *API) RegisterRulerApiEndpoints(srv RulerApiService, m *metrics.Metric
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/ruler/{Recipient}/api/v1/rule/{RuleUID}/restore"),
+			routing.Bind(apimodels.RestoreRuleVersion{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/ruler/{Recipient}/api/v1/rule/{RuleUID}/restore",
+				srv.RoutePostRestoreRuleVersion,
+				m,
+			),
+		)
 	}, middleware.ReqSignedIn)
 }
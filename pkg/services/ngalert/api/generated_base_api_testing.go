@@ -10,8 +10,6 @@ package api
 import (
 	"net/http"
 
-	"github.com/go-macaron/binding"
-
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/middleware"
@@ -30,7 +28,7 @@ func (api *API) RegisterTestingApiEndpoints(srv TestingApiService, m *metrics.Me
 	api.RouteRegister.Group("", func(group routing.RouteRegister) {
 		group.Post(
 			toMacaronPath("/api/v1/eval"),
-			binding.Bind(apimodels.EvalQueriesPayload{}),
+			routing.Bind(apimodels.EvalQueriesPayload{}),
 			metrics.Instrument(
 				http.MethodPost,
 				"/api/v1/eval",
@@ -40,7 +38,7 @@ func (api *API) RegisterTestingApiEndpoints(srv TestingApiService, m *metrics.Me
 		)
 		group.Post(
 			toMacaronPath("/api/v1/receiver/test/{Recipient}"),
-			binding.Bind(apimodels.ExtendedReceiver{}),
+			routing.Bind(apimodels.ExtendedReceiver{}),
 			metrics.Instrument(
 				http.MethodPost,
 				"/api/v1/receiver/test/{Recipient}",
@@ -50,7 +48,7 @@ func (api *API) RegisterTestingApiEndpoints(srv TestingApiService, m *metrics.Me
 		)
 		group.Post(
 			toMacaronPath("/api/v1/rule/test/{Recipient}"),
-			binding.Bind(apimodels.TestRulePayload{}),
+			routing.Bind(apimodels.TestRulePayload{}),
 			metrics.Instrument(
 				http.MethodPost,
 				"/api/v1/rule/test/{Recipient}",
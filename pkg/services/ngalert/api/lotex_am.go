@@ -177,3 +177,13 @@ func (am *LotexAM) RoutePostAMAlerts(ctx *models.ReqContext, alerts apimodels.Po
 		nil,
 	)
 }
+
+func (am *LotexAM) RouteTestRoutes(ctx *models.ReqContext, body apimodels.TestRoutesPayload) response.Response {
+	// Alertmanager has no API to preview routing
+	return response.Error(http.StatusNotImplemented, "testing routes is only supported by the Grafana Alertmanager", nil)
+}
+
+func (am *LotexAM) RoutePutContactPoint(ctx *models.ReqContext, body apimodels.PostableGrafanaReceiver) response.Response {
+	// the contact points with UIDs are Grafana receivers
+	return response.Error(http.StatusNotImplemented, "updating contact points is only supported by the Grafana Alertmanager", nil)
+}
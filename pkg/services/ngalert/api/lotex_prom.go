@@ -75,6 +75,16 @@ func (p *LotexProm) RouteGetRuleStatuses(ctx *models.ReqContext) response.Respon
 	)
 }
 
+func (p *LotexProm) RouteGetCompatAlertStatuses(ctx *models.ReqContext) response.Response {
+	// the data sources already expose the Prometheus API
+	return response.Error(http.StatusNotImplemented, "the Prometheus compatible alerts are only served for Grafana managed rules", nil)
+}
+
+func (p *LotexProm) RouteGetCompatRuleStatuses(ctx *models.ReqContext) response.Response {
+	// the data sources already expose the Prometheus API
+	return response.Error(http.StatusNotImplemented, "the Prometheus compatible rules are only served for Grafana managed rules", nil)
+}
+
 func (p *LotexProm) getEndpoints(ctx *models.ReqContext) (*promEndpoints, error) {
 	ds, err := p.DataProxy.DatasourceCache.GetDatasource(ctx.ParamsInt64("Recipient"), ctx.SignedInUser, ctx.SkipCache)
 	if err != nil {
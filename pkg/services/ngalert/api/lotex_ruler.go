@@ -2,9 +2,12 @@ package api
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"gopkg.in/yaml.v3"
@@ -12,6 +15,8 @@ import (
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/tsdb/prometheus"
 )
 
 var dsTypeToRulerPrefix = map[string]string{
@@ -19,29 +24,38 @@ var dsTypeToRulerPrefix = map[string]string{
 	"loki":       "/api/prom/rules",
 }
 
+// RulerCapabilities detects whether the servers of Prometheus data sources have a ruler config API, and its path.
+type RulerCapabilities interface {
+	Capabilities(ctx context.Context, ds *models.DataSource) (prometheus.Capabilities, error)
+}
+
 type LotexRuler struct {
-	log log.Logger
+	log           log.Logger
+	accessControl accesscontrol.AccessControl
+	capabilities  RulerCapabilities
 	*AlertingProxy
 }
 
-func NewLotexRuler(proxy *AlertingProxy, log log.Logger) *LotexRuler {
+func NewLotexRuler(proxy *AlertingProxy, log log.Logger, ac accesscontrol.AccessControl, capabilities RulerCapabilities) *LotexRuler {
 	return &LotexRuler{
 		log:           log,
+		accessControl: ac,
+		capabilities:  capabilities,
 		AlertingProxy: proxy,
 	}
 }
 
 func (r *LotexRuler) RouteDeleteNamespaceRulesConfig(ctx *models.ReqContext) response.Response {
-	legacyRulerPrefix, err := r.getPrefix(ctx)
-	if err != nil {
-		return response.Error(500, err.Error(), nil)
+	legacyRulerPrefix, errResp := r.getPrefix(ctx, true)
+	if errResp != nil {
+		return errResp
 	}
 	return r.withReq(
 		ctx,
 		http.MethodDelete,
-		withPath(
+		withEscapedPath(
 			*ctx.Req.URL,
-			fmt.Sprintf("%s/%s", legacyRulerPrefix, ctx.Params("Namespace")),
+			rulerPath(legacyRulerPrefix, ctx.Params("Namespace")),
 		),
 		nil,
 		messageExtractor,
@@ -50,21 +64,16 @@ func (r *LotexRuler) RouteDeleteNamespaceRulesConfig(ctx *models.ReqContext) res
 }
 
 func (r *LotexRuler) RouteDeleteRuleGroupConfig(ctx *models.ReqContext) response.Response {
-	legacyRulerPrefix, err := r.getPrefix(ctx)
-	if err != nil {
-		return response.Error(500, err.Error(), nil)
+	legacyRulerPrefix, errResp := r.getPrefix(ctx, true)
+	if errResp != nil {
+		return errResp
 	}
 	return r.withReq(
 		ctx,
 		http.MethodDelete,
-		withPath(
+		withEscapedPath(
 			*ctx.Req.URL,
-			fmt.Sprintf(
-				"%s/%s/%s",
-				legacyRulerPrefix,
-				ctx.Params("Namespace"),
-				ctx.Params("Groupname"),
-			),
+			rulerPath(legacyRulerPrefix, ctx.Params("Namespace"), ctx.Params("Groupname")),
 		),
 		nil,
 		messageExtractor,
@@ -73,20 +82,16 @@ func (r *LotexRuler) RouteDeleteRuleGroupConfig(ctx *models.ReqContext) response
 }
 
 func (r *LotexRuler) RouteGetNamespaceRulesConfig(ctx *models.ReqContext) response.Response {
-	legacyRulerPrefix, err := r.getPrefix(ctx)
-	if err != nil {
-		return response.Error(500, err.Error(), nil)
+	legacyRulerPrefix, errResp := r.getPrefix(ctx, false)
+	if errResp != nil {
+		return errResp
 	}
 	return r.withReq(
 		ctx,
 		http.MethodGet,
-		withPath(
+		withEscapedPath(
 			*ctx.Req.URL,
-			fmt.Sprintf(
-				"%s/%s",
-				legacyRulerPrefix,
-				ctx.Params("Namespace"),
-			),
+			rulerPath(legacyRulerPrefix, ctx.Params("Namespace")),
 		),
 		nil,
 		yamlExtractor(apimodels.NamespaceConfigResponse{}),
@@ -95,21 +100,16 @@ func (r *LotexRuler) RouteGetNamespaceRulesConfig(ctx *models.ReqContext) respon
 }
 
 func (r *LotexRuler) RouteGetRulegGroupConfig(ctx *models.ReqContext) response.Response {
-	legacyRulerPrefix, err := r.getPrefix(ctx)
-	if err != nil {
-		return response.Error(500, err.Error(), nil)
+	legacyRulerPrefix, errResp := r.getPrefix(ctx, false)
+	if errResp != nil {
+		return errResp
 	}
 	return r.withReq(
 		ctx,
 		http.MethodGet,
-		withPath(
+		withEscapedPath(
 			*ctx.Req.URL,
-			fmt.Sprintf(
-				"%s/%s/%s",
-				legacyRulerPrefix,
-				ctx.Params("Namespace"),
-				ctx.Params("Groupname"),
-			),
+			rulerPath(legacyRulerPrefix, ctx.Params("Namespace"), ctx.Params("Groupname")),
 		),
 		nil,
 		yamlExtractor(&apimodels.GettableRuleGroupConfig{}),
@@ -118,14 +118,14 @@ func (r *LotexRuler) RouteGetRulegGroupConfig(ctx *models.ReqContext) response.R
 }
 
 func (r *LotexRuler) RouteGetRulesConfig(ctx *models.ReqContext) response.Response {
-	legacyRulerPrefix, err := r.getPrefix(ctx)
-	if err != nil {
-		return response.Error(500, err.Error(), nil)
+	legacyRulerPrefix, errResp := r.getPrefix(ctx, false)
+	if errResp != nil {
+		return errResp
 	}
 	return r.withReq(
 		ctx,
 		http.MethodGet,
-		withPath(
+		withEscapedPath(
 			*ctx.Req.URL,
 			legacyRulerPrefix,
 		),
@@ -136,33 +136,112 @@ func (r *LotexRuler) RouteGetRulesConfig(ctx *models.ReqContext) response.Respon
 }
 
 func (r *LotexRuler) RoutePostNameRulesConfig(ctx *models.ReqContext, conf apimodels.PostableRuleGroupConfig) response.Response {
-	legacyRulerPrefix, err := r.getPrefix(ctx)
-	if err != nil {
-		return response.Error(500, err.Error(), nil)
+	legacyRulerPrefix, errResp := r.getPrefix(ctx, true)
+	if errResp != nil {
+		return errResp
 	}
 	yml, err := yaml.Marshal(conf)
 	if err != nil {
 		return response.Error(500, "Failed marshal rule group", err)
 	}
 	ns := ctx.Params("Namespace")
-	u := withPath(*ctx.Req.URL, fmt.Sprintf("%s/%s", legacyRulerPrefix, ns))
+	u := withEscapedPath(*ctx.Req.URL, rulerPath(legacyRulerPrefix, ns))
 	return r.withReq(ctx, http.MethodPost, u, bytes.NewBuffer(yml), jsonExtractor(nil), nil)
 }
 
-func (r *LotexRuler) getPrefix(ctx *models.ReqContext) (string, error) {
-	ds, err := r.DataProxy.DatasourceCache.GetDatasource(ctx.ParamsInt64("Recipient"), ctx.SignedInUser, ctx.SkipCache)
+func (r *LotexRuler) RouteGetRuleVersion(ctx *models.ReqContext) response.Response {
+	return response.Error(http.StatusNotImplemented, "the rule versions are only stored for Grafana managed rules", nil)
+}
+
+func (r *LotexRuler) RouteGetRuleVersionDiff(ctx *models.ReqContext) response.Response {
+	return response.Error(http.StatusNotImplemented, "the rule versions are only stored for Grafana managed rules", nil)
+}
+
+func (r *LotexRuler) RouteGetRuleVersions(ctx *models.ReqContext) response.Response {
+	return response.Error(http.StatusNotImplemented, "the rule versions are only stored for Grafana managed rules", nil)
+}
+
+func (r *LotexRuler) RoutePostRestoreRuleVersion(ctx *models.ReqContext, body apimodels.RestoreRuleVersion) response.Response {
+	return response.Error(http.StatusNotImplemented, "the rule versions are only stored for Grafana managed rules", nil)
+}
+
+// getPrefix returns the path of the ruler config API of the data source, after checking the user may read, or write
+// when write is true, its rules. The error response is returned when it can't be used.
+func (r *LotexRuler) getPrefix(ctx *models.ReqContext, write bool) (string, response.Response) {
+	datasourceID := ctx.ParamsInt64("Recipient")
+	if !r.hasAccess(ctx, datasourceID, write) {
+		return "", response.Error(http.StatusForbidden, "Permission denied", nil)
+	}
+
+	ds, err := r.DataProxy.DatasourceCache.GetDatasource(datasourceID, ctx.SignedInUser, ctx.SkipCache)
 	if err != nil {
-		return "", err
+		if errors.Is(err, models.ErrDataSourceAccessDenied) {
+			return "", response.Error(http.StatusForbidden, "Access denied to datasource", err)
+		}
+		return "", response.Error(http.StatusInternalServerError, err.Error(), nil)
+	}
+
+	// the ruler config API of Mimir isn't under the legacy prefix of Cortex
+	if ds.Type == "prometheus" && r.capabilities != nil {
+		capabilities, err := r.capabilities.Capabilities(ctx.Req.Context(), ds)
+		switch {
+		case err != nil:
+			r.log.Warn("Failed to detect the ruler API of the data source, using the legacy prefix", "datasource", ds.Name, "error", err)
+		case !capabilities.Ruler:
+			return "", response.Error(http.StatusNotFound, "The data source doesn't have a ruler API", nil)
+		default:
+			return capabilities.RulerConfigPath, nil
+		}
 	}
+
 	prefix, ok := dsTypeToRulerPrefix[ds.Type]
 	if !ok {
-		return "", fmt.Errorf("unexpected datasource type. expecting loki or prometheus")
+		return "", response.Error(http.StatusInternalServerError, "unexpected datasource type. expecting loki or prometheus", nil)
 	}
 	return prefix, nil
 }
 
+// hasAccess returns whether the user may read, or write when write is true, the rules of the data source. Without
+// access control, viewers may read them and editors write them.
+func (r *LotexRuler) hasAccess(ctx *models.ReqContext, datasourceID int64, write bool) bool {
+	action := accesscontrol.ActionAlertingRuleExternalRead
+	fallback := func(*models.ReqContext) bool { return true }
+	if write {
+		action = accesscontrol.ActionAlertingRuleExternalWrite
+		fallback = func(c *models.ReqContext) bool { return c.SignedInUser.HasRole(models.ROLE_EDITOR) }
+	}
+
+	if r.accessControl == nil {
+		return fallback(ctx)
+	}
+	scope := fmt.Sprintf("datasources:id:%d", datasourceID)
+	return accesscontrol.HasAccess(r.accessControl, ctx)(fallback, action, scope)
+}
+
+// rulerPath returns the path of the namespace, and group, under the prefix. They're escaped, since the names of
+// namespaces and groups may contain e.g. slashes.
+func rulerPath(prefix string, names ...string) string {
+	segments := make([]string, 0, len(names)+1)
+	segments = append(segments, prefix)
+	for _, name := range names {
+		segments = append(segments, url.PathEscape(name))
+	}
+	return strings.Join(segments, "/")
+}
+
 func withPath(u url.URL, newPath string) *url.URL {
 	// TODO: handle path escaping
 	u.Path = newPath
 	return &u
 }
+
+// withEscapedPath returns the URL with the path, as escaped by rulerPath.
+func withEscapedPath(u url.URL, escapedPath string) *url.URL {
+	path, err := url.PathUnescape(escapedPath)
+	if err != nil {
+		path = escapedPath
+	}
+	u.Path = path
+	u.RawPath = escapedPath
+	return &u
+}
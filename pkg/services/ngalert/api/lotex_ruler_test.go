@@ -0,0 +1,108 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/datasourceproxy"
+	"github.com/grafana/grafana/pkg/tsdb/prometheus"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/macaron.v1"
+)
+
+type fakeDatasourceCache struct {
+	datasources map[int64]*models.DataSource
+}
+
+func (c fakeDatasourceCache) GetDatasource(id int64, _ *models.SignedInUser, _ bool) (*models.DataSource, error) {
+	if ds, ok := c.datasources[id]; ok {
+		return ds, nil
+	}
+	return nil, models.ErrDataSourceNotFound
+}
+
+func (c fakeDatasourceCache) GetDatasourceByUID(string, *models.SignedInUser, bool) (*models.DataSource, error) {
+	return nil, models.ErrDataSourceNotFound
+}
+
+type fakeRulerCapabilities struct {
+	capabilities prometheus.Capabilities
+	err          error
+}
+
+func (c fakeRulerCapabilities) Capabilities(context.Context, *models.DataSource) (prometheus.Capabilities, error) {
+	return c.capabilities, c.err
+}
+
+func TestLotexRulerPrefix(t *testing.T) {
+	cache := fakeDatasourceCache{datasources: map[int64]*models.DataSource{
+		1: {Id: 1, Type: "loki"},
+		2: {Id: 2, Type: "prometheus"},
+	}}
+	newRuler := func(capabilities RulerCapabilities) *LotexRuler {
+		proxy := &AlertingProxy{DataProxy: &datasourceproxy.DatasourceProxyService{DatasourceCache: cache}}
+		return NewLotexRuler(proxy, log.New("test"), nil, capabilities)
+	}
+	newContext := func(recipient string, role models.RoleType) *models.ReqContext {
+		req, err := http.NewRequest(http.MethodGet, "/", nil)
+		require.NoError(t, err)
+		ctx := &models.ReqContext{
+			Context:      &macaron.Context{Req: macaron.Request{Request: req}},
+			SignedInUser: &models.SignedInUser{OrgRole: role},
+		}
+		ctx.ReplaceAllParams(map[string]string{":Recipient": recipient})
+		return ctx
+	}
+
+	t.Run("Should use the legacy prefix of Loki", func(t *testing.T) {
+		prefix, resp := newRuler(nil).getPrefix(newContext("1", models.ROLE_VIEWER), false)
+		require.Nil(t, resp)
+		require.Equal(t, "/api/prom/rules", prefix)
+	})
+
+	t.Run("Should use the detected ruler API of Prometheus", func(t *testing.T) {
+		ruler := newRuler(fakeRulerCapabilities{capabilities: prometheus.Capabilities{
+			Ruler:           true,
+			RulerConfigPath: "/config/v1/rules",
+		}})
+		prefix, resp := ruler.getPrefix(newContext("2", models.ROLE_VIEWER), false)
+		require.Nil(t, resp)
+		require.Equal(t, "/config/v1/rules", prefix)
+	})
+
+	t.Run("Should fail when Prometheus has no ruler API", func(t *testing.T) {
+		_, resp := newRuler(fakeRulerCapabilities{}).getPrefix(newContext("2", models.ROLE_VIEWER), false)
+		require.Equal(t, http.StatusNotFound, resp.Status())
+	})
+
+	t.Run("Should use the legacy prefix when the ruler API can't be detected", func(t *testing.T) {
+		ruler := newRuler(fakeRulerCapabilities{err: errors.New("unreachable")})
+		prefix, resp := ruler.getPrefix(newContext("2", models.ROLE_VIEWER), false)
+		require.Nil(t, resp)
+		require.Equal(t, "/rules", prefix)
+	})
+
+	t.Run("Should only let editors write rules", func(t *testing.T) {
+		_, resp := newRuler(nil).getPrefix(newContext("1", models.ROLE_VIEWER), true)
+		require.Equal(t, http.StatusForbidden, resp.Status())
+
+		_, resp = newRuler(nil).getPrefix(newContext("1", models.ROLE_EDITOR), true)
+		require.Nil(t, resp)
+	})
+}
+
+func TestRulerPath(t *testing.T) {
+	path := rulerPath("/api/prom/rules", "team/a", "cpu usage")
+	require.Equal(t, "/api/prom/rules/team%2Fa/cpu%20usage", path)
+
+	base, err := url.Parse("http://localhost:3000/api/ruler/1/api/v1/rules?x=y")
+	require.NoError(t, err)
+	u := withEscapedPath(*base, path)
+	require.Equal(t, "/api/prom/rules/team/a/cpu usage", u.Path)
+	require.Equal(t, "/api/prom/rules/team%2Fa/cpu%20usage?x=y", u.RequestURI())
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/pkg/errors"
 	amv2 "github.com/prometheus/alertmanager/api/v2/models"
 	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/common/model"
 	"gopkg.in/yaml.v3"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
@@ -96,6 +97,22 @@ import (
 //       200: Ack
 //       400: ValidationError
 
+// swagger:route POST /api/alertmanager/{Recipient}/config/api/v1/routes/test alertmanager RouteTestRoutes
+//
+// previews the notification groups alerts are routed to, without sending notifications
+//
+//     Responses:
+//       200: TestRoutesResponse
+//       400: ValidationError
+
+// swagger:route PUT /api/alertmanager/{Recipient}/config/api/v1/contact-points/{UID} alertmanager RoutePutContactPoint
+//
+// creates the Grafana managed contact point of the UID if it does not exist, or else updates it
+//
+//     Responses:
+//       202: Ack
+//       400: ValidationError
+
 // swagger:parameters RouteCreateSilence
 type CreateSilenceParams struct {
 	// in:body
@@ -108,6 +125,17 @@ type GetDeleteSilenceParams struct {
 	SilenceId string
 }
 
+// swagger:parameters RoutePutContactPoint
+type PutContactPointParams struct {
+	// in:path
+	UID string
+	// FieldMask is the comma separated fields of the contact point to update, all of them if not set.
+	// in:query
+	FieldMask string `json:"fieldMask"`
+	// in:body
+	Body PostableGrafanaReceiver
+}
+
 // swagger:parameters RouteGetSilences
 type GetSilencesParams struct {
 	// in:query
@@ -170,6 +198,75 @@ type AlertsParams struct {
 	Receivers string `json:"receiver"`
 }
 
+// swagger:parameters RouteTestRoutes
+type TestRoutesParams struct {
+	// in:body
+	Body TestRoutesPayload
+}
+
+// swagger:model
+type TestRoutesPayload struct {
+	// Route is the notification policy tree to test, the current one is used if not set.
+	Route *config.Route `yaml:"route,omitempty" json:"route,omitempty"`
+	// Alerts are the labels of the alerts to route, the current firing alerts are used if not set.
+	Alerts []model.LabelSet `yaml:"alerts,omitempty" json:"alerts,omitempty"`
+}
+
+func (p *TestRoutesPayload) UnmarshalJSON(b []byte) error {
+	type plain TestRoutesPayload
+	if err := json.Unmarshal(b, (*plain)(p)); err != nil {
+		return err
+	}
+
+	if p.Route == nil {
+		return nil
+	}
+
+	// the route is validated by its yaml unmarshaler
+	y, err := yaml.Marshal(p.Route)
+	if err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(y, p.Route); err != nil {
+		return err
+	}
+
+	if p.Route.Receiver == "" {
+		return fmt.Errorf("root route must specify a default receiver")
+	}
+	if len(p.Route.Match) > 0 || len(p.Route.MatchRE) > 0 || len(p.Route.Matchers) > 0 {
+		return fmt.Errorf("root route must not have any matchers")
+	}
+	return nil
+}
+
+// swagger:model
+type TestRoutesResponse struct {
+	Groups []TestRoutesGroup `json:"groups"`
+}
+
+// TestRoutesGroup is a notification group as the Alertmanager would dispatch it.
+// swagger:model
+type TestRoutesGroup struct {
+	Receiver    string           `json:"receiver"`
+	GroupLabels model.LabelSet   `json:"groupLabels"`
+	Route       TestRoutesRoute  `json:"route"`
+	Alerts      []model.LabelSet `json:"alerts"`
+}
+
+// TestRoutesRoute describes the route that matched the alerts of a group.
+// swagger:model
+type TestRoutesRoute struct {
+	// Key identifies the route by the matchers of the routes from the root to it.
+	Key            string         `json:"key"`
+	Receiver       string         `json:"receiver"`
+	GroupBy        []string       `json:"groupBy"`
+	GroupByAll     bool           `json:"groupByAll"`
+	GroupWait      model.Duration `json:"groupWait"`
+	GroupInterval  model.Duration `json:"groupInterval"`
+	RepeatInterval model.Duration `json:"repeatInterval"`
+}
+
 // swagger:parameters RoutePostAMAlerts
 type PostableAlerts struct {
 	// in:body
@@ -183,7 +280,7 @@ type BodyAlertingConfig struct {
 }
 
 // alertmanager routes
-// swagger:parameters RoutePostAlertingConfig RouteGetAlertingConfig RouteDeleteAlertingConfig RouteGetAMAlerts RoutePostAMAlerts RouteGetAMAlertGroups RouteGetSilences RouteCreateSilence RouteGetSilence RouteDeleteSilence RoutePostAlertingConfig
+// swagger:parameters RoutePostAlertingConfig RouteGetAlertingConfig RouteDeleteAlertingConfig RouteGetAMAlerts RoutePostAMAlerts RouteGetAMAlertGroups RouteGetSilences RouteCreateSilence RouteGetSilence RouteDeleteSilence RoutePostAlertingConfig RouteTestRoutes RoutePutContactPoint
 // ruler routes
 // swagger:parameters RouteGetRulesConfig RoutePostNameRulesConfig RouteGetNamespaceRulesConfig RouteDeleteNamespaceRulesConfig RouteGetRulegGroupConfig RouteDeleteRuleGroupConfig
 // prom routes
@@ -64,6 +64,47 @@ import (
 //     Responses:
 //       202: Ack
 
+// swagger:route Get /api/ruler/{Recipient}/api/v1/rule/{RuleUID}/versions ruler RouteGetRuleVersions
+//
+// List the versions of a Grafana managed rule, the newest first
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: RuleVersionsResponse
+
+// swagger:route Get /api/ruler/{Recipient}/api/v1/rule/{RuleUID}/versions/{Version} ruler RouteGetRuleVersion
+//
+// Get a version of a Grafana managed rule
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GettableRuleVersion
+
+// swagger:route Get /api/ruler/{Recipient}/api/v1/rule/{RuleUID}/versions/{Version}/diff ruler RouteGetRuleVersionDiff
+//
+// Get the diff of a version of a Grafana managed rule with another version
+//
+//     Produces:
+//     - application/json
+//     - text/html
+//
+//     Responses:
+//       200: Ack
+
+// swagger:route POST /api/ruler/{Recipient}/api/v1/rule/{RuleUID}/restore ruler RoutePostRestoreRuleVersion
+//
+// Restore a version of a Grafana managed rule
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       202: Ack
+
 // swagger:parameters RoutePostNameRulesConfig
 type NamespaceConfig struct {
 	// in:path
@@ -86,6 +127,63 @@ type PathRouleGroupConfig struct {
 	Groupname string
 }
 
+// swagger:parameters RouteGetRuleVersions
+type PathRuleUID struct {
+	// in: path
+	RuleUID string
+}
+
+// swagger:parameters RouteGetRuleVersion
+type PathRuleVersion struct {
+	// in: path
+	RuleUID string
+	// in: path
+	Version int64
+}
+
+// swagger:parameters RouteGetRuleVersionDiff
+type RuleVersionDiffParams struct {
+	// in: path
+	RuleUID string
+	// in: path
+	Version int64
+	// Base is the version to compare with, the previous version by default.
+	// in: query
+	// required: false
+	Base int64 `json:"base"`
+	// DiffType is the format of the diff: "basic" (default), "json" or "delta".
+	// in: query
+	// required: false
+	DiffType string `json:"diffType"`
+}
+
+// swagger:parameters RoutePostRestoreRuleVersion
+type RestoreRuleVersionParams struct {
+	// in: path
+	RuleUID string
+	// in: body
+	Body RestoreRuleVersion
+}
+
+// swagger:model
+type RestoreRuleVersion struct {
+	Version int64 `json:"version"`
+}
+
+// GettableRuleVersion is a version of a Grafana managed rule, the rule is only set for the single versions.
+// swagger:model
+type GettableRuleVersion struct {
+	Version       int64                     `json:"version"`
+	ParentVersion int64                     `json:"parentVersion"`
+	RestoredFrom  int64                     `json:"restoredFrom"`
+	Created       time.Time                 `json:"created"`
+	Title         string                    `json:"title"`
+	Rule          *GettableExtendedRuleNode `json:"rule,omitempty"`
+}
+
+// swagger:model
+type RuleVersionsResponse []GettableRuleVersion
+
 // swagger:model
 type RuleGroupConfigResponse struct {
 	GettableRuleGroupConfig
@@ -20,6 +20,28 @@ import (
 //     Responses:
 //       200: AlertResponse
 
+// swagger:route GET /api/v1/rules prometheus RouteGetCompatRuleStatuses
+//
+// gets the evaluation statuses of the Grafana managed rules in the format of the Prometheus rules API
+//
+//     Responses:
+//       200: RuleResponse
+
+// swagger:route GET /api/v1/alerts prometheus RouteGetCompatAlertStatuses
+//
+// gets the active alerts of the Grafana managed rules in the format of the Prometheus alerts API
+//
+//     Responses:
+//       200: AlertResponse
+
+// swagger:parameters RouteGetCompatRuleStatuses
+type CompatRuleStatusesParams struct {
+	// Type only returns the alerting rules with "alert" and the recording rules with "record".
+	// in: query
+	// required: false
+	Type string `json:"type"`
+}
+
 // swagger:model
 type RuleResponse struct {
 	// in: body
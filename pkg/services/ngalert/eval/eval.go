@@ -8,6 +8,9 @@ import (
 	"sort"
 	"time"
 
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	grafanamodels "github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 
 	"github.com/grafana/grafana/pkg/setting"
@@ -120,6 +123,10 @@ func GetExprRequest(ctx AlertExecCtx, data []models.AlertQuery, now time.Time) (
 		if err != nil {
 			return nil, fmt.Errorf("failed to get query model: %w", err)
 		}
+		datasourceUID := q.DatasourceUID
+		if model, datasourceUID, err = resolveSavedQuery(ctx, model, datasourceUID); err != nil {
+			return nil, fmt.Errorf("failed to resolve saved query: %w", err)
+		}
 		interval, err := q.GetIntervalDuration()
 		if err != nil {
 			return nil, fmt.Errorf("failed to retrieve intervalMs from the model: %w", err)
@@ -135,7 +142,7 @@ func GetExprRequest(ctx AlertExecCtx, data []models.AlertQuery, now time.Time) (
 				From: q.RelativeTimeRange.ToTimeRange(now).From,
 				To:   q.RelativeTimeRange.ToTimeRange(now).To,
 			},
-			DatasourceUID: q.DatasourceUID,
+			DatasourceUID: datasourceUID,
 			JSON:          model,
 			Interval:      interval,
 			RefID:         q.RefID,
@@ -146,6 +153,26 @@ func GetExprRequest(ctx AlertExecCtx, data []models.AlertQuery, now time.Time) (
 	return req, nil
 }
 
+// resolveSavedQuery returns the model and data source of the saved query the
+// model of an alert query references, or the ones of the alert query.
+func resolveSavedQuery(ctx AlertExecCtx, model []byte, datasourceUID string) ([]byte, string, error) {
+	query, err := simplejson.NewJson(model)
+	if err != nil || !grafanamodels.IsSavedQueryReference(query) {
+		return model, datasourceUID, nil
+	}
+
+	resolveQuery := &grafanamodels.ResolveSavedQueryQuery{OrgId: ctx.OrgID, Query: query}
+	if err := bus.DispatchCtx(ctx.Ctx, resolveQuery); err != nil {
+		return nil, "", err
+	}
+
+	resolved, err := resolveQuery.Result.MarshalJSON()
+	if err != nil {
+		return nil, "", err
+	}
+	return resolved, resolveQuery.Result.Get("datasourceUid").MustString(), nil
+}
+
 func executeCondition(ctx AlertExecCtx, c *models.Condition, now time.Time, dataService *tsdb.Service) ExecutionResults {
 	result := ExecutionResults{}
 
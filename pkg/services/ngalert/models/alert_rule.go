@@ -17,6 +17,8 @@ var (
 	ErrRuleGroupNamespaceNotFound = errors.New("rule group not found under this namespace")
 	// ErrAlertRuleFailedValidation
 	ErrAlertRuleFailedValidation = errors.New("invalid alert rule")
+	// ErrAlertRuleVersionNotFound is an error for an unknown version of an alert rule.
+	ErrAlertRuleVersionNotFound = errors.New("could not find alert rule version")
 )
 
 type NoDataState string
@@ -129,6 +131,23 @@ type GetAlertRuleByUIDQuery struct {
 	Result *AlertRule
 }
 
+// GetAlertRuleVersionQuery is the query for retrieving a version of an alert rule by the UID of the rule.
+type GetAlertRuleVersionQuery struct {
+	OrgID   int64
+	RuleUID string
+	Version int64
+
+	Result *AlertRuleVersion
+}
+
+// ListAlertRuleVersionsQuery is the query for listing the versions of an alert rule, the newest first.
+type ListAlertRuleVersionsQuery struct {
+	OrgID   int64
+	RuleUID string
+
+	Result []*AlertRuleVersion
+}
+
 // ListAlertRulesQuery is the query for listing alert rules
 type ListAlertRulesQuery struct {
 	OrgID int64
@@ -13,18 +13,23 @@ import (
 	"github.com/benbjohnson/clock"
 
 	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/leaderelection"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/datasourceproxy"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/ngalert/api"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/remoteeval"
 	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/usageexport"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/grafana/grafana/pkg/tsdb/prometheus"
 )
 
 const (
@@ -41,18 +46,23 @@ const (
 
 // AlertNG is the service for evaluating the condition of an alert definition.
 type AlertNG struct {
-	Cfg             *setting.Cfg                            `inject:""`
-	DatasourceCache datasources.CacheService                `inject:""`
-	RouteRegister   routing.RouteRegister                   `inject:""`
-	SQLStore        *sqlstore.SQLStore                      `inject:""`
-	DataService     *tsdb.Service                           `inject:""`
-	DataProxy       *datasourceproxy.DatasourceProxyService `inject:""`
-	QuotaService    *quota.QuotaService                     `inject:""`
-	Metrics         *metrics.Metrics                        `inject:""`
-	Alertmanager    *notifier.Alertmanager
-	Log             log.Logger
-	schedule        schedule.ScheduleService
-	stateManager    *state.Manager
+	Cfg               *setting.Cfg                            `inject:""`
+	DatasourceCache   datasources.CacheService                `inject:""`
+	RouteRegister     routing.RouteRegister                   `inject:""`
+	SQLStore          *sqlstore.SQLStore                      `inject:""`
+	DataService       *tsdb.Service                           `inject:""`
+	DataProxy         *datasourceproxy.DatasourceProxyService `inject:""`
+	QuotaService      *quota.QuotaService                     `inject:""`
+	Metrics           *metrics.Metrics                        `inject:""`
+	AccessControl     accesscontrol.AccessControl             `inject:""`
+	PrometheusService *prometheus.PrometheusService           `inject:""`
+	UsageExport       *usageexport.Service                    `inject:""`
+	LeaderElection    *leaderelection.Service                 `inject:""`
+	Alertmanager      *notifier.Alertmanager
+	Log               log.Logger
+	schedule          schedule.ScheduleService
+	stateManager      *state.Manager
+	remoteEvaluator   *remoteeval.Client
 }
 
 func init() {
@@ -79,15 +89,25 @@ func (ng *AlertNG) Init() error {
 	}
 
 	schedCfg := schedule.SchedulerCfg{
-		C:             clock.New(),
-		BaseInterval:  baseInterval,
-		Logger:        ng.Log,
-		MaxAttempts:   maxAttempts,
-		Evaluator:     eval.Evaluator{Cfg: ng.Cfg},
-		InstanceStore: store,
-		RuleStore:     store,
-		Notifier:      ng.Alertmanager,
-		Metrics:       ng.Metrics,
+		C:              clock.New(),
+		BaseInterval:   baseInterval,
+		Logger:         ng.Log,
+		MaxAttempts:    maxAttempts,
+		Evaluator:      eval.Evaluator{Cfg: ng.Cfg},
+		InstanceStore:  store,
+		RuleStore:      store,
+		Notifier:       ng.Alertmanager,
+		Metrics:        ng.Metrics,
+		EvalRecordFunc: ng.UsageExport.RecordAlertEvaluation,
+		IsLeaderFunc:   ng.LeaderElection.IsLeader,
+	}
+	if len(ng.Cfg.RemoteEvaluation.Evaluators) > 0 {
+		ng.remoteEvaluator, err = remoteeval.NewClient(ng.Cfg.RemoteEvaluation)
+		if err != nil {
+			return err
+		}
+		schedCfg.RemoteEvaluator = ng.remoteEvaluator
+		ng.Log.Info("Alert rules are evaluated on the remote evaluator servers", "evaluators", ng.Cfg.RemoteEvaluation.Evaluators)
 	}
 	ng.schedule = schedule.NewScheduler(schedCfg, ng.DataService)
 
@@ -104,6 +124,9 @@ func (ng *AlertNG) Init() error {
 		AlertingStore:   store,
 		Alertmanager:    ng.Alertmanager,
 		StateManager:    ng.stateManager,
+		AccessControl:   ng.AccessControl,
+		// the ruler API of Prometheus data sources depends on the server
+		RulerCapabilities: ng.PrometheusService,
 	}
 	api.RegisterAPIEndpoints(ng.Metrics)
 
@@ -122,7 +145,13 @@ func (ng *AlertNG) Run(ctx context.Context) error {
 	children.Go(func() error {
 		return ng.Alertmanager.Run(subCtx)
 	})
-	return children.Wait()
+	err := children.Wait()
+	if ng.remoteEvaluator != nil {
+		if closeErr := ng.remoteEvaluator.Close(); closeErr != nil {
+			ng.Log.Warn("Failed to close the connections to the evaluator servers", "error", closeErr)
+		}
+	}
+	return err
 }
 
 // IsDisabled returns true if the alerting service is disable for this instance.
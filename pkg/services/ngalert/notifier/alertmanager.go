@@ -444,6 +444,10 @@ func (am *Alertmanager) buildReceiverIntegrations(receiver *apimodels.PostableAp
 			n, err = channels.NewOpsgenieNotifier(cfg, tmpl)
 		case "prometheus-alertmanager":
 			n, err = channels.NewAlertmanagerNotifier(cfg, tmpl)
+		case "snmp":
+			n, err = channels.NewSNMPNotifier(cfg, tmpl)
+		case "syslog":
+			n, err = channels.NewSyslogNotifier(cfg, tmpl)
 		default:
 			return nil, fmt.Errorf("notifier %s is not supported", r.Type)
 		}
@@ -635,6 +635,34 @@ func GetAvailableNotifiers() []*alerting.NotifierPlugin {
 					InputType:    alerting.InputTypeText,
 					PropertyName: "maxAlerts",
 				},
+				{
+					Label:        "HTTP Headers",
+					Description:  "Custom headers added to the request, one \"Name: value\" header per line.",
+					Element:      alerting.ElementTypeTextArea,
+					PropertyName: "httpHeaders",
+				},
+				{
+					Label:        "HMAC Secret",
+					Description:  "If set, the request is signed with HMAC-SHA256 of the timestamp header and the body joined by a dot.",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypePassword,
+					PropertyName: "hmacSecret",
+					Secure:       true,
+				},
+				{
+					Label:        "Signature Header",
+					Description:  "Header the signature is sent in, prefixed with sha256=. The timestamp is sent in X-Grafana-Signature-Timestamp.",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "X-Grafana-Signature",
+					PropertyName: "signatureHeader",
+				},
+				{
+					Label:        "Payload Template",
+					Description:  "Optional template of the JSON payload, executed with the default webhook message. Use the json function to encode values, e.g. {{ json .Title }}.",
+					Element:      alerting.ElementTypeTextArea,
+					PropertyName: "payloadTemplate",
+				},
 			},
 		},
 		{
@@ -817,5 +845,109 @@ func GetAvailableNotifiers() []*alerting.NotifierPlugin {
 				},
 			},
 		},
+		{
+			Type:        "snmp",
+			Name:        "SNMP",
+			Description: "Sends SNMPv2c traps to an SNMP manager",
+			Heading:     "SNMP settings",
+			Options: []alerting.NotifierOption{
+				{
+					Label:        "Address",
+					Description:  "Host and port of the SNMP manager, the port defaults to 162",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "snmp.example.com:162",
+					PropertyName: "address",
+					Required:     true,
+				},
+				{
+					Label:        "Community",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypePassword,
+					Placeholder:  "public",
+					PropertyName: "community",
+					Secure:       true,
+				},
+				{
+					Label:        "Trap OID",
+					Description:  "OID of the trap, the state, title, message, link, group key and number of alerts are sent as variables .1 to .6 below it",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "1.3.6.1.4.1.8072.9999.9999.1",
+					PropertyName: "trapOid",
+				},
+			},
+		},
+		{
+			Type:        "syslog",
+			Name:        "Syslog",
+			Description: "Sends RFC5424 syslog messages",
+			Heading:     "Syslog settings",
+			Options: []alerting.NotifierOption{
+				{
+					Label:        "Address",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "syslog.example.com:514",
+					PropertyName: "address",
+					Required:     true,
+				},
+				{
+					Label:   "Protocol",
+					Element: alerting.ElementTypeSelect,
+					SelectOptions: []alerting.SelectOption{
+						{
+							Value: "udp",
+							Label: "UDP",
+						},
+						{
+							Value: "tcp",
+							Label: "TCP",
+						},
+						{
+							Value: "tls",
+							Label: "TLS",
+						},
+					},
+					PropertyName: "protocol",
+				},
+				{
+					Label:        "Facility",
+					Description:  "Syslog facility, e.g. local0 or daemon",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "local0",
+					PropertyName: "facility",
+				},
+				{
+					Label:        "Severity",
+					Description:  "Severity of firing alerts, e.g. warning or crit. Resolved alerts are sent with the notice severity.",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "warning",
+					PropertyName: "severity",
+				},
+				{
+					Label:        "App Name",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "grafana",
+					PropertyName: "appName",
+				},
+				{
+					Label:        "Enterprise ID",
+					Description:  "Private enterprise number used to add the group key and number of alerts as structured data",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					PropertyName: "enterpriseId",
+				},
+				{
+					Label:        "Message",
+					Element:      alerting.ElementTypeTextArea,
+					Placeholder:  `{{ template "default.title" . }}`,
+					PropertyName: "message",
+				},
+			},
+		},
 	}
 }
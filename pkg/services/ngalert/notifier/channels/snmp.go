@@ -0,0 +1,151 @@
+package channels
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	gokit_log "github.com/go-kit/kit/log"
+	"github.com/gosnmp/gosnmp"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	old_notifiers "github.com/grafana/grafana/pkg/services/alerting/notifiers"
+	"github.com/grafana/grafana/pkg/services/ngalert/logging"
+)
+
+const (
+	defaultSNMPPort = 162
+	// netSnmpPlaypen is the experimental enterprise OID of Net-SNMP, it should
+	// be replaced by an OID of the organization receiving the traps.
+	defaultSNMPTrapOID = "1.3.6.1.4.1.8072.9999.9999.1"
+	snmpTrapOID        = "1.3.6.1.6.3.1.1.4.1.0"
+)
+
+// SNMPNotifier is responsible for sending alert notifications as SNMPv2c
+// traps.
+type SNMPNotifier struct {
+	old_notifiers.NotifierBase
+	Address   string
+	Community string
+	TrapOID   string
+	log       log.Logger
+	tmpl      *template.Template
+}
+
+// NewSNMPNotifier is the constructor for the SNMP trap notifier.
+func NewSNMPNotifier(model *NotificationChannelConfig, t *template.Template) (*SNMPNotifier, error) {
+	address := model.Settings.Get("address").MustString()
+	if address == "" {
+		return nil, alerting.ValidationError{Reason: "Could not find address property in settings"}
+	}
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, strconv.Itoa(defaultSNMPPort))
+	}
+
+	trapOID := model.Settings.Get("trapOid").MustString()
+	if trapOID == "" {
+		trapOID = defaultSNMPTrapOID
+	}
+
+	return &SNMPNotifier{
+		NotifierBase: old_notifiers.NewNotifierBase(&models.AlertNotification{
+			Uid:                   model.UID,
+			Name:                  model.Name,
+			Type:                  model.Type,
+			DisableResolveMessage: model.DisableResolveMessage,
+			Settings:              model.Settings,
+		}),
+		Address:   address,
+		Community: model.DecryptedValue("community", model.Settings.Get("community").MustString("public")),
+		TrapOID:   trapOID,
+		log:       log.New("alerting.notifier.snmp"),
+		tmpl:      t,
+	}, nil
+}
+
+// Notify sends one trap for the notification, with the state, title,
+// message, link and number of alerts as variables below the trap OID.
+func (sn *SNMPNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	groupKey, err := notify.ExtractGroupKey(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	data := notify.GetTemplateData(ctx, sn.tmpl, as, gokit_log.NewLogfmtLogger(logging.NewWrapper(sn.log)))
+	var tmplErr error
+	tmpl := notify.TmplText(sn.tmpl, data, &tmplErr)
+
+	title := tmpl(`{{ template "default.title" . }}`)
+	message := tmpl(`{{ template "default.message" . }}`)
+	if tmplErr != nil {
+		return false, fmt.Errorf("failed to template SNMP trap: %w", tmplErr)
+	}
+
+	ruleURL, err := joinUrlPath(sn.tmpl.ExternalURL.String(), "/alerting/list")
+	if err != nil {
+		return false, err
+	}
+
+	state := "firing"
+	if types.Alerts(as...).Status() == model.AlertResolved {
+		state = "resolved"
+	}
+
+	host, portString, err := net.SplitHostPort(sn.Address)
+	if err != nil {
+		return false, err
+	}
+	port, err := strconv.ParseUint(portString, 10, 16)
+	if err != nil {
+		return false, fmt.Errorf("invalid SNMP port %q: %w", portString, err)
+	}
+
+	client := &gosnmp.GoSNMP{
+		Target:    host,
+		Port:      uint16(port),
+		Transport: "udp",
+		Community: sn.Community,
+		Version:   gosnmp.Version2c,
+		Timeout:   10 * time.Second,
+		Context:   ctx,
+	}
+	if err := client.Connect(); err != nil {
+		return false, fmt.Errorf("failed to connect to SNMP manager: %w", err)
+	}
+	defer func() {
+		if err := client.Conn.Close(); err != nil {
+			sn.log.Warn("Failed to close SNMP connection", "err", err)
+		}
+	}()
+
+	sn.log.Debug("Sending SNMP trap", "address", sn.Address, "state", state)
+
+	trap := gosnmp.SnmpTrap{
+		Variables: []gosnmp.SnmpPDU{
+			{Name: snmpTrapOID, Type: gosnmp.ObjectIdentifier, Value: sn.TrapOID},
+			{Name: sn.TrapOID + ".1", Type: gosnmp.OctetString, Value: state},
+			{Name: sn.TrapOID + ".2", Type: gosnmp.OctetString, Value: title},
+			{Name: sn.TrapOID + ".3", Type: gosnmp.OctetString, Value: message},
+			{Name: sn.TrapOID + ".4", Type: gosnmp.OctetString, Value: ruleURL},
+			{Name: sn.TrapOID + ".5", Type: gosnmp.OctetString, Value: groupKey.String()},
+			{Name: sn.TrapOID + ".6", Type: gosnmp.Integer, Value: len(as)},
+		},
+	}
+	if _, err := client.SendTrap(trap); err != nil {
+		return false, fmt.Errorf("failed to send SNMP trap: %w", err)
+	}
+
+	return true, nil
+}
+
+func (sn *SNMPNotifier) SendResolved() bool {
+	return !sn.GetDisableResolveMessage()
+}
@@ -0,0 +1,96 @@
+package channels
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/alerting"
+)
+
+func TestSNMPNotifier(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	t.Run("Error in initing", func(t *testing.T) {
+		settingsJSON, err := simplejson.NewJson([]byte(`{}`))
+		require.NoError(t, err)
+
+		_, err = NewSNMPNotifier(&NotificationChannelConfig{Name: "snmp_testing", Type: "snmp", Settings: settingsJSON}, tmpl)
+		require.Equal(t, alerting.ValidationError{Reason: "Could not find address property in settings"}, err)
+	})
+
+	t.Run("Default port is added to the address", func(t *testing.T) {
+		settingsJSON, err := simplejson.NewJson([]byte(`{"address": "snmp.example.com"}`))
+		require.NoError(t, err)
+
+		sn, err := NewSNMPNotifier(&NotificationChannelConfig{Name: "snmp_testing", Type: "snmp", Settings: settingsJSON}, tmpl)
+		require.NoError(t, err)
+		require.Equal(t, "snmp.example.com:162", sn.Address)
+		require.Equal(t, "public", sn.Community)
+		require.Equal(t, defaultSNMPTrapOID, sn.TrapOID)
+	})
+
+	t.Run("Traps are sent with the alert variables", func(t *testing.T) {
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer func() { require.NoError(t, conn.Close()) }()
+
+		settingsJSON, err := simplejson.NewJson([]byte(`{"address": "` + conn.LocalAddr().String() + `", "trapOid": "1.3.6.1.4.1.99999.1"}`))
+		require.NoError(t, err)
+
+		sn, err := NewSNMPNotifier(&NotificationChannelConfig{
+			Name:     "snmp_testing",
+			Type:     "snmp",
+			Settings: settingsJSON,
+		}, tmpl)
+		require.NoError(t, err)
+
+		ctx := notify.WithGroupKey(context.Background(), "alertname")
+		ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+		ok, err := sn.Notify(ctx, &types.Alert{
+			Alert: model.Alert{
+				Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+				Annotations: model.LabelSet{"ann1": "annv1"},
+			},
+		})
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		buf := make([]byte, 65535)
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		n, _, err := conn.ReadFrom(buf)
+		require.NoError(t, err)
+
+		packet, err := gosnmp.Default.SnmpDecodePacket(buf[:n])
+		require.NoError(t, err)
+		require.Equal(t, "public", packet.Community)
+
+		values := map[string]interface{}{}
+		for _, v := range packet.Variables {
+			if b, ok := v.Value.([]byte); ok {
+				values[v.Name] = string(b)
+			} else {
+				values[v.Name] = v.Value
+			}
+		}
+		require.Equal(t, ".1.3.6.1.4.1.99999.1", values["."+snmpTrapOID])
+		require.Equal(t, "firing", values[".1.3.6.1.4.1.99999.1.1"])
+		require.Equal(t, "[FIRING:1]  (val1)", values[".1.3.6.1.4.1.99999.1.2"])
+		require.Equal(t, "http://localhost/alerting/list", values[".1.3.6.1.4.1.99999.1.4"])
+		require.Equal(t, "alertname", values[".1.3.6.1.4.1.99999.1.5"])
+		require.Equal(t, 1, values[".1.3.6.1.4.1.99999.1.6"])
+	})
+}
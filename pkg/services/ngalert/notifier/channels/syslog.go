@@ -0,0 +1,233 @@
+package channels
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	gokit_log "github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	old_notifiers "github.com/grafana/grafana/pkg/services/alerting/notifiers"
+	"github.com/grafana/grafana/pkg/services/ngalert/logging"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+	"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19, "local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+var syslogEnterpriseID = regexp.MustCompile(`^[0-9]+(\.[0-9]+)*$`)
+
+var syslogSeverities = map[string]int{
+	"emerg": 0, "alert": 1, "crit": 2, "err": 3, "warning": 4, "notice": 5, "info": 6, "debug": 7,
+}
+
+// SyslogNotifier is responsible for sending alert notifications as RFC5424
+// syslog messages.
+type SyslogNotifier struct {
+	old_notifiers.NotifierBase
+	Address  string
+	Protocol string
+	Facility int
+	Severity int
+	AppName  string
+	Message  string
+	// EnterpriseID is the private enterprise number used for the structured
+	// data element with the alert fields, it's omitted if not set
+	EnterpriseID string
+	log          log.Logger
+	tmpl         *template.Template
+}
+
+// NewSyslogNotifier is the constructor for the syslog notifier.
+func NewSyslogNotifier(model *NotificationChannelConfig, t *template.Template) (*SyslogNotifier, error) {
+	address := model.Settings.Get("address").MustString()
+	if address == "" {
+		return nil, alerting.ValidationError{Reason: "Could not find address property in settings"}
+	}
+
+	protocol := model.Settings.Get("protocol").MustString("udp")
+	if protocol != "udp" && protocol != "tcp" && protocol != "tls" {
+		return nil, alerting.ValidationError{Reason: fmt.Sprintf("Invalid protocol %q, must be udp, tcp or tls", protocol)}
+	}
+
+	facility, ok := syslogFacilities[model.Settings.Get("facility").MustString("local0")]
+	if !ok {
+		return nil, alerting.ValidationError{Reason: "Invalid syslog facility"}
+	}
+
+	severity, ok := syslogSeverities[model.Settings.Get("severity").MustString("warning")]
+	if !ok {
+		return nil, alerting.ValidationError{Reason: "Invalid syslog severity"}
+	}
+
+	enterpriseID := model.Settings.Get("enterpriseId").MustString()
+	if enterpriseID != "" && !syslogEnterpriseID.MatchString(enterpriseID) {
+		return nil, alerting.ValidationError{Reason: "Invalid enterprise id, must be a private enterprise number, e.g. 32473"}
+	}
+
+	return &SyslogNotifier{
+		NotifierBase: old_notifiers.NewNotifierBase(&models.AlertNotification{
+			Uid:                   model.UID,
+			Name:                  model.Name,
+			Type:                  model.Type,
+			DisableResolveMessage: model.DisableResolveMessage,
+			Settings:              model.Settings,
+		}),
+		Address:      address,
+		Protocol:     protocol,
+		Facility:     facility,
+		Severity:     severity,
+		AppName:      model.Settings.Get("appName").MustString("grafana"),
+		Message:      model.Settings.Get("message").MustString(`{{ template "default.title" . }}`),
+		EnterpriseID: enterpriseID,
+		log:          log.New("alerting.notifier.syslog"),
+		tmpl:         t,
+	}, nil
+}
+
+// Notify sends one syslog message for the notification. Resolved
+// notifications are sent with the notice severity.
+func (sn *SyslogNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	groupKey, err := notify.ExtractGroupKey(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	data := notify.GetTemplateData(ctx, sn.tmpl, as, gokit_log.NewLogfmtLogger(logging.NewWrapper(sn.log)))
+	var tmplErr error
+	tmpl := notify.TmplText(sn.tmpl, data, &tmplErr)
+
+	text := tmpl(sn.Message)
+	if tmplErr != nil {
+		return false, fmt.Errorf("failed to template syslog message: %w", tmplErr)
+	}
+
+	status := "firing"
+	severity := sn.Severity
+	if types.Alerts(as...).Status() == model.AlertResolved {
+		status = "resolved"
+		severity = syslogSeverities["notice"]
+	}
+
+	msg := formatSyslogMessage(time.Now(), sn.Facility, severity, sn.AppName, status, sn.EnterpriseID, map[string]string{
+		"groupKey": groupKey.String(),
+		"alerts":   strconv.Itoa(len(as)),
+	}, text)
+
+	sn.log.Debug("Sending syslog message", "address", sn.Address, "protocol", sn.Protocol, "status", status)
+
+	if err := sn.send(ctx, msg); err != nil {
+		return false, fmt.Errorf("failed to send syslog message: %w", err)
+	}
+
+	return true, nil
+}
+
+func (sn *SyslogNotifier) send(ctx context.Context, msg string) error {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+
+	var conn net.Conn
+	var err error
+	switch sn.Protocol {
+	case "tls":
+		tlsDialer := &tls.Dialer{NetDialer: dialer}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", sn.Address)
+	default:
+		conn, err = dialer.DialContext(ctx, sn.Protocol, sn.Address)
+	}
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			sn.log.Warn("Failed to close syslog connection", "err", err)
+		}
+	}()
+
+	if err := conn.SetWriteDeadline(time.Now().Add(10 * time.Second)); err != nil {
+		return err
+	}
+
+	// stream transports frame the messages by octet counting, RFC6587
+	if sn.Protocol != "udp" {
+		msg = strconv.Itoa(len(msg)) + " " + msg
+	}
+
+	_, err = conn.Write([]byte(msg))
+	return err
+}
+
+func (sn *SyslogNotifier) SendResolved() bool {
+	return !sn.GetDisableResolveMessage()
+}
+
+// formatSyslogMessage formats an RFC5424 message with the alert status as
+// message id. The alert fields are added as structured data element of the
+// enterprise if one is configured, since element names without an
+// enterprise number are reserved for IANA registered elements.
+func formatSyslogMessage(ts time.Time, facility, severity int, appName, msgID, enterpriseID string, params map[string]string, text string) string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+
+	var sd strings.Builder
+	sd.WriteString(`[origin software="Grafana" swVersion="`)
+	sd.WriteString(escapeSyslogParam(setting.BuildVersion))
+	sd.WriteString(`"]`)
+	if enterpriseID != "" {
+		sd.WriteString("[alert@" + enterpriseID)
+		for _, name := range []string{"groupKey", "alerts"} {
+			sd.WriteString(" " + name + `="` + escapeSyslogParam(params[name]) + `"`)
+		}
+		sd.WriteString("]")
+	}
+
+	return fmt.Sprintf("<%d>1 %s %s %s %d %s %s %s",
+		facility*8+severity,
+		ts.UTC().Format(time.RFC3339Nano),
+		syslogHeaderField(hostname, 255),
+		syslogHeaderField(appName, 48),
+		os.Getpid(),
+		msgID,
+		sd.String(),
+		text,
+	)
+}
+
+// header fields are printable US-ASCII without spaces
+func syslogHeaderField(value string, maxLength int) string {
+	field := strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return -1
+		}
+		return r
+	}, value)
+	if field == "" {
+		return "-"
+	}
+	if len(field) > maxLength {
+		field = field[:maxLength]
+	}
+	return field
+}
+
+func escapeSyslogParam(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(value)
+}
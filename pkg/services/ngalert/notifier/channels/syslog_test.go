@@ -0,0 +1,80 @@
+package channels
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/alerting"
+)
+
+func TestSyslogNotifier(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	t.Run("Invalid settings are rejected", func(t *testing.T) {
+		cases := map[string]string{
+			`{}`: "Could not find address property in settings",
+			`{"address": "localhost:514", "protocol": "http"}`:     `Invalid protocol "http", must be udp, tcp or tls`,
+			`{"address": "localhost:514", "facility": "unknown"}`:  "Invalid syslog facility",
+			`{"address": "localhost:514", "severity": "unknown"}`:  "Invalid syslog severity",
+			`{"address": "localhost:514", "enterpriseId": "acme"}`: "Invalid enterprise id, must be a private enterprise number, e.g. 32473",
+		}
+		for settings, reason := range cases {
+			settingsJSON, err := simplejson.NewJson([]byte(settings))
+			require.NoError(t, err)
+
+			_, err = NewSyslogNotifier(&NotificationChannelConfig{Name: "syslog_testing", Type: "syslog", Settings: settingsJSON}, tmpl)
+			require.Equal(t, alerting.ValidationError{Reason: reason}, err)
+		}
+	})
+
+	t.Run("Messages are sent in the RFC5424 format", func(t *testing.T) {
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer func() { require.NoError(t, conn.Close()) }()
+
+		settingsJSON, err := simplejson.NewJson([]byte(`{
+			"address": "` + conn.LocalAddr().String() + `",
+			"facility": "daemon",
+			"severity": "crit",
+			"enterpriseId": "32473"
+		}`))
+		require.NoError(t, err)
+
+		sn, err := NewSyslogNotifier(&NotificationChannelConfig{Name: "syslog_testing", Type: "syslog", Settings: settingsJSON}, tmpl)
+		require.NoError(t, err)
+
+		ctx := notify.WithGroupKey(context.Background(), "alertname")
+		ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+		ok, err := sn.Notify(ctx, &types.Alert{
+			Alert: model.Alert{
+				Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+				Annotations: model.LabelSet{"ann1": "annv1"},
+			},
+		})
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		buf := make([]byte, 65535)
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		n, _, err := conn.ReadFrom(buf)
+		require.NoError(t, err)
+
+		// daemon (3) * 8 + crit (2) = 26
+		expected := regexp.MustCompile(`^<26>1 \S+ \S+ grafana \d+ firing \[origin software="Grafana" swVersion="[^"]*"\]\[alert@32473 groupKey="alertname" alerts="1"\] \[FIRING:1\]  \(val1\)$`)
+		require.Regexp(t, expected, string(buf[:n]))
+	})
+}
@@ -1,9 +1,17 @@
 package channels
 
 import (
+	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
+	tmpltext "text/template"
+	"time"
 
 	gokit_log "github.com/go-kit/kit/log"
 	"github.com/prometheus/alertmanager/notify"
@@ -19,17 +27,26 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/logging"
 )
 
+const (
+	defaultWebhookSignatureHeader = "X-Grafana-Signature"
+	webhookTimestampHeader        = "X-Grafana-Signature-Timestamp"
+)
+
 // WebhookNotifier is responsible for sending
 // alert notifications as webhooks.
 type WebhookNotifier struct {
 	old_notifiers.NotifierBase
-	URL        string
-	User       string
-	Password   string
-	HTTPMethod string
-	MaxAlerts  int
-	log        log.Logger
-	tmpl       *template.Template
+	URL             string
+	User            string
+	Password        string
+	HTTPMethod      string
+	MaxAlerts       int
+	HTTPHeaders     map[string]string
+	HMACSecret      string
+	SignatureHeader string
+	PayloadTemplate *tmpltext.Template
+	log             log.Logger
+	tmpl            *template.Template
 }
 
 // NewWebHookNotifier is the constructor for
@@ -39,6 +56,25 @@ func NewWebHookNotifier(model *NotificationChannelConfig, t *template.Template)
 	if url == "" {
 		return nil, alerting.ValidationError{Reason: "Could not find url property in settings"}
 	}
+
+	headers, err := parseWebhookHeaders(model.Settings.Get("httpHeaders").Interface())
+	if err != nil {
+		return nil, alerting.ValidationError{Reason: err.Error()}
+	}
+
+	signatureHeader := model.Settings.Get("signatureHeader").MustString()
+	if signatureHeader == "" {
+		signatureHeader = defaultWebhookSignatureHeader
+	}
+
+	var payloadTemplate *tmpltext.Template
+	if text := model.Settings.Get("payloadTemplate").MustString(); strings.TrimSpace(text) != "" {
+		payloadTemplate, err = tmpltext.New("payload").Funcs(webhookTemplateFuncs).Parse(text)
+		if err != nil {
+			return nil, alerting.ValidationError{Reason: fmt.Sprintf("Invalid payload template: %v", err)}
+		}
+	}
+
 	return &WebhookNotifier{
 		NotifierBase: old_notifiers.NewNotifierBase(&models.AlertNotification{
 			Uid:                   model.UID,
@@ -47,16 +83,62 @@ func NewWebHookNotifier(model *NotificationChannelConfig, t *template.Template)
 			DisableResolveMessage: model.DisableResolveMessage,
 			Settings:              model.Settings,
 		}),
-		URL:        url,
-		User:       model.Settings.Get("username").MustString(),
-		Password:   model.DecryptedValue("password", model.Settings.Get("password").MustString()),
-		HTTPMethod: model.Settings.Get("httpMethod").MustString("POST"),
-		MaxAlerts:  model.Settings.Get("maxAlerts").MustInt(0),
-		log:        log.New("alerting.notifier.webhook"),
-		tmpl:       t,
+		URL:             url,
+		User:            model.Settings.Get("username").MustString(),
+		Password:        model.DecryptedValue("password", model.Settings.Get("password").MustString()),
+		HTTPMethod:      model.Settings.Get("httpMethod").MustString("POST"),
+		MaxAlerts:       model.Settings.Get("maxAlerts").MustInt(0),
+		HTTPHeaders:     headers,
+		HMACSecret:      model.DecryptedValue("hmacSecret", model.Settings.Get("hmacSecret").MustString()),
+		SignatureHeader: signatureHeader,
+		PayloadTemplate: payloadTemplate,
+		log:             log.New("alerting.notifier.webhook"),
+		tmpl:            t,
 	}, nil
 }
 
+// webhookTemplateFuncs are available in payload templates in addition to the
+// functions of notification templates. The json function encodes a value, so
+// that label values can't break the payload.
+var webhookTemplateFuncs = func() tmpltext.FuncMap {
+	funcs := tmpltext.FuncMap{}
+	for name, fn := range template.DefaultFuncs {
+		funcs[name] = fn
+	}
+	funcs["json"] = func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	}
+	return funcs
+}()
+
+// parseWebhookHeaders accepts the headers either as an object or as a text
+// with one "Name: value" header per line.
+func parseWebhookHeaders(value interface{}) (map[string]string, error) {
+	headers := map[string]string{}
+	switch v := value.(type) {
+	case nil:
+	case map[string]interface{}:
+		for name, headerValue := range v {
+			headers[name] = fmt.Sprint(headerValue)
+		}
+	case string:
+		for _, line := range strings.Split(v, "\n") {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			parts := strings.SplitN(line, ":", 2)
+			if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+				return nil, fmt.Errorf("invalid http header %q, expected the format name: value", line)
+			}
+			headers[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	default:
+		return nil, fmt.Errorf("invalid http headers, expected an object or a text with one header per line")
+	}
+	return headers, nil
+}
+
 // webhookMessage defines the JSON object send to webhook endpoints.
 type webhookMessage struct {
 	*template.Data
@@ -104,17 +186,28 @@ func (wn *WebhookNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool
 		return false, fmt.Errorf("failed to template webhook message: %w", tmplErr)
 	}
 
-	body, err := json.Marshal(msg)
+	body, err := wn.buildBody(msg)
 	if err != nil {
 		return false, err
 	}
 
+	headers := make(map[string]string, len(wn.HTTPHeaders)+2)
+	for name, value := range wn.HTTPHeaders {
+		headers[name] = value
+	}
+	if wn.HMACSecret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		headers[webhookTimestampHeader] = timestamp
+		headers[wn.SignatureHeader] = "sha256=" + signWebhookBody(wn.HMACSecret, timestamp, body)
+	}
+
 	cmd := &models.SendWebhookSync{
 		Url:        wn.URL,
 		User:       wn.User,
 		Password:   wn.Password,
 		Body:       string(body),
 		HttpMethod: wn.HTTPMethod,
+		HttpHeader: headers,
 	}
 
 	if err := bus.DispatchCtx(ctx, cmd); err != nil {
@@ -124,6 +217,34 @@ func (wn *WebhookNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool
 	return true, nil
 }
 
+// buildBody renders the payload template if one is configured, otherwise the
+// message is sent as is.
+func (wn *WebhookNotifier) buildBody(msg *webhookMessage) ([]byte, error) {
+	if wn.PayloadTemplate == nil {
+		return json.Marshal(msg)
+	}
+
+	var buf bytes.Buffer
+	if err := wn.PayloadTemplate.Execute(&buf, msg); err != nil {
+		return nil, fmt.Errorf("failed to render webhook payload template: %w", err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, fmt.Errorf("webhook payload template didn't render valid JSON")
+	}
+	return buf.Bytes(), nil
+}
+
+// signWebhookBody returns the hex encoded HMAC-SHA256 of the timestamp and
+// the body, joined by a dot. Including the timestamp lets receivers reject
+// replayed requests.
+func signWebhookBody(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	// writing to a hash never fails
+	_, _ = mac.Write([]byte(timestamp + "."))
+	_, _ = mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
 func truncateAlerts(maxAlerts int, alerts []*types.Alert) ([]*types.Alert, int) {
 	if maxAlerts > 0 && len(alerts) > maxAlerts {
 		return alerts[:maxAlerts], len(alerts) - maxAlerts
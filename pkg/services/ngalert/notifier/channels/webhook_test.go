@@ -2,6 +2,9 @@ package channels
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"net/url"
 	"testing"
@@ -219,3 +222,80 @@ func TestWebhookNotifier(t *testing.T) {
 		})
 	}
 }
+
+func TestWebhookNotifierHeadersSigningAndPayloadTemplate(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	alerts := []*types.Alert{
+		{
+			Alert: model.Alert{
+				Labels:      model.LabelSet{"alertname": "alert1", "lbl1": `val"1`},
+				Annotations: model.LabelSet{"ann1": "annv1"},
+			},
+		},
+	}
+
+	settingsJSON, err := simplejson.NewJson([]byte(`{
+		"url": "http://localhost/test",
+		"httpHeaders": "X-Tenant: team-a\nX-Source: grafana",
+		"hmacSecret": "s3cr3t",
+		"payloadTemplate": "{\"status\": {{ json .Status }}, \"summary\": {{ json .Title }}, \"labels\": {{ json .CommonLabels }}}"
+	}`))
+	require.NoError(t, err)
+
+	pn, err := NewWebHookNotifier(&NotificationChannelConfig{Name: "webhook_testing", Type: "webhook", Settings: settingsJSON}, tmpl)
+	require.NoError(t, err)
+
+	var payload *models.SendWebhookSync
+	bus.AddHandlerCtx("test", func(ctx context.Context, webhook *models.SendWebhookSync) error {
+		payload = webhook
+		return nil
+	})
+
+	ctx := notify.WithGroupKey(context.Background(), "alertname")
+	ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+	ctx = notify.WithReceiverName(ctx, "my_receiver")
+	ok, err := pn.Notify(ctx, alerts...)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	require.JSONEq(t, `{"status": "firing", "summary": "[FIRING:1]  (val\"1)", "labels": {"alertname": "alert1", "lbl1": "val\"1"}}`, payload.Body)
+	require.Equal(t, "team-a", payload.HttpHeader["X-Tenant"])
+	require.Equal(t, "grafana", payload.HttpHeader["X-Source"])
+
+	timestamp := payload.HttpHeader["X-Grafana-Signature-Timestamp"]
+	require.NotEmpty(t, timestamp)
+	mac := hmac.New(sha256.New, []byte("s3cr3t"))
+	_, err = mac.Write([]byte(timestamp + "." + payload.Body))
+	require.NoError(t, err)
+	require.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), payload.HttpHeader["X-Grafana-Signature"])
+
+	t.Run("Payload templates must render JSON", func(t *testing.T) {
+		settingsJSON, err := simplejson.NewJson([]byte(`{"url": "http://localhost/test", "payloadTemplate": "status: {{ .Status }}"}`))
+		require.NoError(t, err)
+
+		pn, err := NewWebHookNotifier(&NotificationChannelConfig{Name: "webhook_testing", Type: "webhook", Settings: settingsJSON}, tmpl)
+		require.NoError(t, err)
+
+		ok, err := pn.Notify(ctx, alerts...)
+		require.False(t, ok)
+		require.EqualError(t, err, "webhook payload template didn't render valid JSON")
+	})
+
+	t.Run("Invalid settings are rejected", func(t *testing.T) {
+		for _, settings := range []string{
+			`{"url": "http://localhost/test", "payloadTemplate": "{{ .Status"}`,
+			`{"url": "http://localhost/test", "httpHeaders": "no separator"}`,
+		} {
+			settingsJSON, err := simplejson.NewJson([]byte(settings))
+			require.NoError(t, err)
+
+			_, err = NewWebHookNotifier(&NotificationChannelConfig{Name: "webhook_testing", Type: "webhook", Settings: settingsJSON}, tmpl)
+			require.Error(t, err)
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package notifier
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+var ErrNoRoute = errors.New("no notification policy is configured")
+
+// TestRoutes returns the notification groups the alerts would be dispatched
+// to, without sending any notification. The current notification policy tree
+// is used if route is nil, the current firing alerts if there are no alerts.
+func (am *Alertmanager) TestRoutes(route *config.Route, alerts []model.LabelSet) ([]apimodels.TestRoutesGroup, error) {
+	am.reloadConfigMtx.RLock()
+	defer am.reloadConfigMtx.RUnlock()
+
+	root := am.route
+	if route != nil {
+		root = dispatch.NewRoute(route, nil)
+	}
+	if root == nil {
+		return nil, ErrNoRoute
+	}
+
+	if len(alerts) == 0 {
+		var err error
+		if alerts, err = am.firingAlertLabels(); err != nil {
+			return nil, err
+		}
+	}
+
+	return groupAlerts(root, alerts), nil
+}
+
+func (am *Alertmanager) firingAlertLabels() ([]model.LabelSet, error) {
+	it := am.alerts.GetPending()
+	defer it.Close()
+
+	var res []model.LabelSet
+	for a := range it.Next() {
+		if err := it.Err(); err != nil {
+			return nil, err
+		}
+		if a.Resolved() || am.marker.Status(a.Fingerprint()).State == types.AlertStateSuppressed {
+			continue
+		}
+		res = append(res, a.Labels)
+	}
+	return res, nil
+}
+
+// groupAlerts mirrors how the dispatcher groups alerts: every route an alert
+// matches has one group per distinct value of the labels it groups by.
+func groupAlerts(root *dispatch.Route, alerts []model.LabelSet) []apimodels.TestRoutesGroup {
+	groups := map[string]*apimodels.TestRoutesGroup{}
+	var keys []string
+
+	for _, alert := range alerts {
+		for _, r := range root.Match(alert) {
+			groupLabels := model.LabelSet{}
+			for name, value := range alert {
+				if _, ok := r.RouteOpts.GroupBy[name]; ok || r.RouteOpts.GroupByAll {
+					groupLabels[name] = value
+				}
+			}
+
+			key := r.Key() + ":" + groupLabels.String()
+			group, ok := groups[key]
+			if !ok {
+				group = &apimodels.TestRoutesGroup{
+					Receiver:    r.RouteOpts.Receiver,
+					GroupLabels: groupLabels,
+					Route:       routeSummary(r),
+					Alerts:      []model.LabelSet{},
+				}
+				groups[key] = group
+				keys = append(keys, key)
+			}
+			group.Alerts = append(group.Alerts, alert)
+		}
+	}
+
+	sort.Strings(keys)
+	res := make([]apimodels.TestRoutesGroup, 0, len(keys))
+	for _, key := range keys {
+		res = append(res, *groups[key])
+	}
+	return res
+}
+
+func routeSummary(r *dispatch.Route) apimodels.TestRoutesRoute {
+	groupBy := make([]string, 0, len(r.RouteOpts.GroupBy))
+	for name := range r.RouteOpts.GroupBy {
+		groupBy = append(groupBy, string(name))
+	}
+	sort.Strings(groupBy)
+
+	return apimodels.TestRoutesRoute{
+		Key:            r.Key(),
+		Receiver:       r.RouteOpts.Receiver,
+		GroupBy:        groupBy,
+		GroupByAll:     r.RouteOpts.GroupByAll,
+		GroupWait:      model.Duration(r.RouteOpts.GroupWait),
+		GroupInterval:  model.Duration(r.RouteOpts.GroupInterval),
+		RepeatInterval: model.Duration(r.RouteOpts.RepeatInterval),
+	}
+}
@@ -0,0 +1,74 @@
+package notifier
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+func TestAlertmanager_TestRoutes(t *testing.T) {
+	var payload apimodels.TestRoutesPayload
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"route": {
+			"receiver": "default",
+			"group_by": ["alertname"],
+			"routes": [
+				{"receiver": "team-a", "matchers": ["team=\"a\""], "group_by": ["alertname", "cluster"], "continue": true},
+				{"receiver": "critical", "match": {"severity": "critical"}, "group_wait": "10s"}
+			]
+		},
+		"alerts": [
+			{"alertname": "HighCPU", "team": "a", "cluster": "eu", "severity": "critical"},
+			{"alertname": "HighCPU", "team": "a", "cluster": "us", "severity": "warning"},
+			{"alertname": "DiskFull", "severity": "critical"},
+			{"alertname": "DiskFull", "severity": "critical", "instance": "b"},
+			{"alertname": "Unrouted"}
+		]
+	}`), &payload))
+
+	am := &Alertmanager{}
+	groups, err := am.TestRoutes(payload.Route, payload.Alerts)
+	require.NoError(t, err)
+
+	type group struct {
+		receiver    string
+		groupLabels model.LabelSet
+		alerts      int
+	}
+	var actual []group
+	for _, g := range groups {
+		actual = append(actual, group{g.Receiver, g.GroupLabels, len(g.Alerts)})
+	}
+
+	require.ElementsMatch(t, []group{
+		{"team-a", model.LabelSet{"alertname": "HighCPU", "cluster": "eu"}, 1},
+		{"team-a", model.LabelSet{"alertname": "HighCPU", "cluster": "us"}, 1},
+		// the team-a route continues, so the critical alert is also routed to the critical receiver
+		{"critical", model.LabelSet{"alertname": "HighCPU"}, 1},
+		{"critical", model.LabelSet{"alertname": "DiskFull"}, 2},
+		{"default", model.LabelSet{"alertname": "Unrouted"}, 1},
+	}, actual)
+
+	for _, g := range groups {
+		if g.Receiver == "critical" {
+			require.Equal(t, model.Duration(10*time.Second), g.Route.GroupWait)
+			require.Equal(t, []string{"alertname"}, g.Route.GroupBy)
+		}
+	}
+
+	t.Run("without a route and current configuration", func(t *testing.T) {
+		_, err := am.TestRoutes(nil, payload.Alerts)
+		require.ErrorIs(t, err, ErrNoRoute)
+	})
+
+	t.Run("invalid routes are rejected", func(t *testing.T) {
+		var payload apimodels.TestRoutesPayload
+		require.Error(t, json.Unmarshal([]byte(`{"route": {"receiver": "default", "match": {"a": "b"}}}`), &payload))
+		require.Error(t, json.Unmarshal([]byte(`{"route": {"group_by": ["a"]}}`), &payload))
+	})
+}
@@ -0,0 +1,163 @@
+package remoteeval
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/remoteeval/evaluatorv1"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// evaluationTimeout is the timeout of the remote evaluations, a bit longer
+// than the timeout of the evaluations on the evaluator servers.
+const evaluationTimeout = 35 * time.Second
+
+// Client dispatches the evaluations of the alert rules to the evaluator
+// servers in turn. An evaluation fails over to the next servers when a server
+// is unavailable.
+type Client struct {
+	conns   []*grpc.ClientConn
+	clients []evaluatorv1.EvaluatorServiceClient
+	next    uint64
+}
+
+// NewClient returns a client of the evaluator servers of the settings.
+func NewClient(settings setting.RemoteEvaluationSettings) (*Client, error) {
+	if len(settings.Evaluators) == 0 {
+		return nil, errors.New("no evaluator servers")
+	}
+
+	opts := []grpc.DialOption{grpc.WithPerRPCCredentials(tokenCredentials(settings.Token))}
+	switch {
+	case settings.ClientTLS && settings.ClientCACert != "":
+		creds, err := credentials.NewClientTLSFromFile(settings.ClientCACert, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the remote evaluation CA certificate: %w", err)
+		}
+		opts = append(opts, grpc.WithTransportCredentials(creds))
+	case settings.ClientTLS:
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{})))
+	default:
+		opts = append(opts, grpc.WithInsecure())
+	}
+
+	c := &Client{}
+	for _, address := range settings.Evaluators {
+		// the connections are established in the background
+		conn, err := grpc.Dial(address, opts...)
+		if err != nil {
+			_ = c.Close()
+			return nil, fmt.Errorf("failed to connect to the evaluator server %s: %w", address, err)
+		}
+		c.conns = append(c.conns, conn)
+		c.clients = append(c.clients, evaluatorv1.NewEvaluatorServiceClient(conn))
+	}
+	return c, nil
+}
+
+// ConditionEval evaluates a condition on the evaluator servers.
+func (c *Client) ConditionEval(condition *models.Condition, now time.Time) (eval.Results, error) {
+	data, err := json.Marshal(condition.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the data of the condition: %w", err)
+	}
+	req := &evaluatorv1.EvaluateRequest{
+		OrgId:     condition.OrgID,
+		Condition: condition.Condition,
+		Data:      data,
+		Now:       now.UnixNano(),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), evaluationTimeout)
+	defer cancel()
+
+	first := int(atomic.AddUint64(&c.next, 1) % uint64(len(c.clients)))
+	for i := range c.clients {
+		index := (first + i) % len(c.clients)
+		var resp *evaluatorv1.EvaluateResponse
+		resp, err = c.clients[index].Evaluate(ctx, req)
+		if status.Code(err) == codes.Unavailable {
+			logger.Warn("Evaluator server unavailable", "address", c.conns[index].Target(), "error", err)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("remote evaluation failed: %w", err)
+		}
+		return fromProtoResults(resp.Results), nil
+	}
+	return nil, fmt.Errorf("no evaluator server is available: %w", err)
+}
+
+// Close closes the connections to the evaluator servers.
+func (c *Client) Close() error {
+	var err error
+	for _, conn := range c.conns {
+		if closeErr := conn.Close(); closeErr != nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// tokenCredentials sends the token of the remote evaluation as a bearer
+// token. It's also sent without TLS, like the plaintext server.
+type tokenCredentials string
+
+func (t tokenCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+func (t tokenCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+// The values of the states of the evaluation results are the same in the
+// eval package and the protocol.
+
+func toProtoResults(results eval.Results) []*evaluatorv1.Result {
+	protoResults := make([]*evaluatorv1.Result, 0, len(results))
+	for _, result := range results {
+		protoResult := &evaluatorv1.Result{
+			Labels:             result.Instance,
+			State:              evaluatorv1.State(result.State),
+			EvaluatedAt:        result.EvaluatedAt.UnixNano(),
+			EvaluationDuration: int64(result.EvaluationDuration),
+			EvaluationString:   result.EvaluationString,
+		}
+		if result.Error != nil {
+			protoResult.Error = result.Error.Error()
+		}
+		protoResults = append(protoResults, protoResult)
+	}
+	return protoResults
+}
+
+func fromProtoResults(protoResults []*evaluatorv1.Result) eval.Results {
+	results := make(eval.Results, 0, len(protoResults))
+	for _, protoResult := range protoResults {
+		result := eval.Result{
+			Instance:           protoResult.Labels,
+			State:              eval.State(protoResult.State),
+			EvaluatedAt:        time.Unix(0, protoResult.EvaluatedAt),
+			EvaluationDuration: time.Duration(protoResult.EvaluationDuration),
+			EvaluationString:   protoResult.EvaluationString,
+		}
+		if protoResult.Error != "" {
+			result.Error = errors.New(protoResult.Error)
+		}
+		results = append(results, result)
+	}
+	return results
+}
@@ -0,0 +1,128 @@
+package remoteeval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	grafanamodels "github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func startServer(t *testing.T, token string) string {
+	t.Helper()
+
+	cfg := setting.NewCfg()
+	cfg.ExpressionsEnabled = true
+	cfg.RemoteEvaluation = setting.RemoteEvaluationSettings{ServerEnabled: true, Token: token}
+	s := &Service{Cfg: cfg}
+	server, err := s.newServer()
+	require.NoError(t, err)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	t.Cleanup(server.Stop)
+	return lis.Addr().String()
+}
+
+func newTestClient(t *testing.T, token string, evaluators ...string) *Client {
+	t.Helper()
+
+	client, err := NewClient(setting.RemoteEvaluationSettings{Evaluators: evaluators, Token: token})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+	return client
+}
+
+func TestRemoteEvaluation(t *testing.T) {
+	t.Cleanup(bus.ClearBusHandlers)
+	bus.AddHandlerCtx("test", func(ctx context.Context, query *grafanamodels.ResolveSavedQueryQuery) error {
+		return grafanamodels.ErrSavedQueryNotFound
+	})
+
+	address := startServer(t, "secret")
+	// the condition fails before its expressions are executed, the results
+	// of the evaluations are tested with the local evaluations
+	condition := &models.Condition{
+		Condition: "A",
+		OrgID:     1,
+		Data: []models.AlertQuery{{
+			RefID:             "A",
+			DatasourceUID:     "-100",
+			RelativeTimeRange: models.RelativeTimeRange{From: models.Duration(10 * time.Minute)},
+			Model:             json.RawMessage(`{"savedQueryUid": "deleted"}`),
+		}},
+	}
+	now := time.Now()
+
+	t.Run("Evaluates the conditions on the evaluator servers", func(t *testing.T) {
+		client := newTestClient(t, "secret", address)
+		results, err := client.ConditionEval(condition, now)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, eval.Error, results[0].State)
+		require.Error(t, results[0].Error)
+		assert.Contains(t, results[0].Error.Error(), grafanamodels.ErrSavedQueryNotFound.Error())
+		assert.Equal(t, now.UnixNano(), results[0].EvaluatedAt.UnixNano())
+	})
+
+	t.Run("Fails over to the next evaluator servers", func(t *testing.T) {
+		// nothing listens on the address of a closed listener
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		require.NoError(t, lis.Close())
+
+		client := newTestClient(t, "secret", lis.Addr().String(), address)
+		for i := 0; i < 2; i++ {
+			results, err := client.ConditionEval(condition, now)
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+		}
+	})
+
+	t.Run("Rejects the invalid tokens", func(t *testing.T) {
+		client := newTestClient(t, "wrong", address)
+		_, err := client.ConditionEval(condition, now)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid token")
+	})
+}
+
+func TestProtoResults(t *testing.T) {
+	results := eval.Results{
+		{
+			Instance:           data.Labels{"instance": "a"},
+			State:              eval.Alerting,
+			EvaluatedAt:        time.Unix(0, 1000),
+			EvaluationDuration: time.Second,
+			EvaluationString:   "[ var='A' value=5 ]",
+		},
+		{
+			Instance:    data.Labels{},
+			State:       eval.Error,
+			Error:       errors.New("query failed"),
+			EvaluatedAt: time.Unix(0, 1000),
+		},
+	}
+
+	converted := fromProtoResults(toProtoResults(results))
+	require.Len(t, converted, 2)
+	assert.Equal(t, results[0], converted[0])
+	assert.Equal(t, eval.Error, converted[1].State)
+	assert.EqualError(t, converted[1].Error, "query failed")
+}
@@ -0,0 +1,121 @@
+// Package remoteeval evaluates the alert rules of the scheduler on remote
+// evaluator servers, so that the evaluation of the alert rules is scaled
+// independently of the Grafana servers serving the users. The evaluator
+// servers are Grafana servers of the same database, evaluating the conditions
+// of the alert rules the schedulers send them over gRPC.
+package remoteeval
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/remoteeval/evaluatorv1"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb"
+)
+
+var logger = log.New("ngalert.remoteeval")
+
+func init() {
+	registry.RegisterService(&Service{})
+}
+
+// Service is the gRPC evaluator server. The calls are authenticated by the
+// shared token of the remote evaluation, sent as a bearer token.
+type Service struct {
+	Cfg         *setting.Cfg  `inject:""`
+	DataService *tsdb.Service `inject:""`
+}
+
+func (s *Service) Init() error {
+	return nil
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.Cfg.RemoteEvaluation.ServerEnabled
+}
+
+func (s *Service) Run(ctx context.Context) error {
+	server, err := s.newServer()
+	if err != nil {
+		return err
+	}
+
+	lis, err := net.Listen("tcp", s.Cfg.RemoteEvaluation.ServerAddress)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.Cfg.RemoteEvaluation.ServerAddress, err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		server.GracefulStop()
+	}()
+
+	logger.Info("Remote evaluation server started", "address", lis.Addr())
+	if err := server.Serve(lis); err != nil {
+		return fmt.Errorf("remote evaluation server failed: %w", err)
+	}
+	return ctx.Err()
+}
+
+func (s *Service) newServer() (*grpc.Server, error) {
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(s.authenticate),
+	}
+	if s.Cfg.RemoteEvaluation.CertFile != "" {
+		creds, err := credentials.NewServerTLSFromFile(s.Cfg.RemoteEvaluation.CertFile, s.Cfg.RemoteEvaluation.CertKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the remote evaluation server TLS certificate: %w", err)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	server := grpc.NewServer(opts...)
+	evaluatorv1.RegisterEvaluatorServiceServer(server, s)
+	return server, nil
+}
+
+func (s *Service) authenticate(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var header string
+	if values := md.Get("authorization"); len(values) > 0 {
+		header = values[0]
+	}
+	expected := "Bearer " + s.Cfg.RemoteEvaluation.Token
+	if subtle.ConstantTimeCompare([]byte(header), []byte(expected)) != 1 {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	return handler(ctx, req)
+}
+
+// Evaluate evaluates the condition of an alert rule, like the local
+// evaluations of the scheduler.
+func (s *Service) Evaluate(ctx context.Context, req *evaluatorv1.EvaluateRequest) (*evaluatorv1.EvaluateResponse, error) {
+	var data []models.AlertQuery
+	if err := json.Unmarshal(req.Data, &data); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid data: %v", err)
+	}
+
+	condition := models.Condition{Condition: req.Condition, OrgID: req.OrgId, Data: data}
+	evaluator := eval.Evaluator{Cfg: s.Cfg}
+	results, err := evaluator.ConditionEval(&condition, time.Unix(0, req.Now), s.DataService)
+	if err != nil {
+		logger.Error("Failed to evaluate the condition", "orgId", req.OrgId, "error", err)
+		return nil, status.Error(codes.Internal, "failed to evaluate the condition")
+	}
+	return &evaluatorv1.EvaluateResponse{Results: toProtoResults(results)}, nil
+}
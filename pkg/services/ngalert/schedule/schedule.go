@@ -12,6 +12,7 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	inframetrics "github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/services/alerting"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -69,7 +70,13 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 					OrgID:     alertRule.OrgID,
 					Data:      alertRule.Data,
 				}
-				results, err := sch.evaluator.ConditionEval(&condition, ctx.now, sch.dataService)
+				var results eval.Results
+				var err error
+				if sch.remoteEvaluator != nil {
+					results, err = sch.remoteEvaluator.ConditionEval(&condition, ctx.now)
+				} else {
+					results, err = sch.evaluator.ConditionEval(&condition, ctx.now, sch.dataService)
+				}
 				var (
 					end    = timeNow()
 					tenant = fmt.Sprint(alertRule.OrgID)
@@ -77,6 +84,9 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 				)
 
 				sch.metrics.EvalTotal.WithLabelValues(tenant).Inc()
+				if sch.evalRecordFunc != nil {
+					sch.evalRecordFunc(alertRule.OrgID)
+				}
 				sch.metrics.EvalDuration.WithLabelValues(tenant).Observe(dur)
 				if err != nil {
 					sch.metrics.EvalFailures.WithLabelValues(tenant).Inc()
@@ -99,6 +109,7 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 
 			func() {
 				evalRunning = true
+				inframetrics.MAlertingEvaluationLag.Set(timeNow().Sub(ctx.now).Seconds())
 				defer func() {
 					evalRunning = false
 					sch.evalApplied(key, ctx.now)
@@ -122,6 +133,12 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 	}
 }
 
+// RemoteEvaluator evaluates the conditions of the alert rules on remote
+// evaluator servers.
+type RemoteEvaluator interface {
+	ConditionEval(condition *models.Condition, now time.Time) (eval.Results, error)
+}
+
 // Notifier handles the delivery of alert notifications to the end user
 type Notifier interface {
 	PutAlerts(alerts apimodels.PostableAlerts) error
@@ -154,6 +171,10 @@ type schedule struct {
 
 	evaluator eval.Evaluator
 
+	// remoteEvaluator evaluates the alert rules instead of the evaluator,
+	// if not nil.
+	remoteEvaluator RemoteEvaluator
+
 	ruleStore store.RuleStore
 
 	instanceStore store.InstanceStore
@@ -162,6 +183,13 @@ type schedule struct {
 
 	notifier Notifier
 	metrics  *metrics.Metrics
+
+	// evalRecordFunc is called with the org of every evaluation, if not nil.
+	evalRecordFunc func(orgID int64)
+
+	// isLeaderFunc returns whether the server is the leader evaluating the
+	// alert rules, every server evaluates them if nil.
+	isLeaderFunc func() bool
 }
 
 // SchedulerCfg is the scheduler configuration.
@@ -173,10 +201,20 @@ type SchedulerCfg struct {
 	MaxAttempts     int64
 	StopAppliedFunc func(models.AlertRuleKey)
 	Evaluator       eval.Evaluator
+	// RemoteEvaluator evaluates the alert rules instead of the Evaluator,
+	// if not nil.
+	RemoteEvaluator RemoteEvaluator
 	RuleStore       store.RuleStore
 	InstanceStore   store.InstanceStore
 	Notifier        Notifier
 	Metrics         *metrics.Metrics
+	// EvalRecordFunc is called with the org of every evaluation, e.g. to
+	// record the usage of the orgs.
+	EvalRecordFunc func(orgID int64)
+	// IsLeaderFunc returns whether the server is the leader evaluating the
+	// alert rules, so that several servers don't send the notifications
+	// several times. Every server evaluates them if nil.
+	IsLeaderFunc func() bool
 }
 
 // NewScheduler returns a new schedule.
@@ -192,11 +230,14 @@ func NewScheduler(cfg SchedulerCfg, dataService *tsdb.Service) *schedule {
 		evalAppliedFunc: cfg.EvalAppliedFunc,
 		stopAppliedFunc: cfg.StopAppliedFunc,
 		evaluator:       cfg.Evaluator,
+		remoteEvaluator: cfg.RemoteEvaluator,
 		ruleStore:       cfg.RuleStore,
 		instanceStore:   cfg.InstanceStore,
 		dataService:     dataService,
 		notifier:        cfg.Notifier,
 		metrics:         cfg.Metrics,
+		evalRecordFunc:  cfg.EvalRecordFunc,
+		isLeaderFunc:    cfg.IsLeaderFunc,
 	}
 	return &sch
 }
@@ -217,6 +258,10 @@ func (sch *schedule) evalApplied(alertDefKey models.AlertRuleKey, now time.Time)
 	sch.evalAppliedFunc(alertDefKey, now)
 }
 
+func (sch *schedule) isLeader() bool {
+	return sch.isLeaderFunc == nil || sch.isLeaderFunc()
+}
+
 func (sch *schedule) stopApplied(alertDefKey models.AlertRuleKey) {
 	if sch.stopAppliedFunc == nil {
 		return
@@ -245,9 +290,25 @@ func (sch *schedule) Unpause() error {
 
 func (sch *schedule) Ticker(grafanaCtx context.Context, stateManager *state.Manager) error {
 	dispatcherGroup, ctx := errgroup.WithContext(grafanaCtx)
+	// the state cache is warmed at startup
+	wasLeader := true
 	for {
 		select {
 		case tick := <-sch.heartbeat.C:
+			if !sch.isLeader() {
+				if wasLeader {
+					sch.log.Info("not the leader, the alert rules aren't evaluated")
+				}
+				wasLeader = false
+				continue
+			}
+			if !wasLeader {
+				// the states were saved by the previous leader
+				sch.log.Info("became the leader, the alert rules are evaluated")
+				sch.WarmStateCache(stateManager)
+				wasLeader = true
+			}
+
 			tickNum := tick.Unix() / int64(sch.baseInterval.Seconds())
 			alertRules := sch.fetchAllDetails()
 			sch.log.Debug("alert rules fetched", "count", len(alertRules))
@@ -318,13 +379,17 @@ func (sch *schedule) Ticker(grafanaCtx context.Context, stateManager *state.Mana
 		case <-grafanaCtx.Done():
 			waitErr := dispatcherGroup.Wait()
 
-			orgIds, err := sch.instanceStore.FetchOrgIds()
-			if err != nil {
-				sch.log.Error("unable to fetch orgIds", "msg", err.Error())
-			}
+			// the states of the other servers would overwrite the states of
+			// the leader
+			if wasLeader {
+				orgIds, err := sch.instanceStore.FetchOrgIds()
+				if err != nil {
+					sch.log.Error("unable to fetch orgIds", "msg", err.Error())
+				}
 
-			for _, v := range orgIds {
-				sch.saveAlertStates(stateManager.GetAll(v))
+				for _, v := range orgIds {
+					sch.saveAlertStates(stateManager.GetAll(v))
+				}
 			}
 
 			stateManager.Close()
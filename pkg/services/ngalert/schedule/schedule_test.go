@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"runtime"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -223,6 +224,51 @@ func TestAlertingTicker(t *testing.T) {
 	})
 }
 
+func TestAlertingTickerLeader(t *testing.T) {
+	dbstore := tests.SetupTestEnv(t, 1)
+	t.Cleanup(registry.ClearOverrides)
+
+	alert := tests.CreateTestAlertRule(t, dbstore, 1)
+
+	evalAppliedCh := make(chan evalAppliedInfo, 1)
+	mockedClock := clock.NewMock()
+	var isLeader int32
+
+	schedCfg := schedule.SchedulerCfg{
+		C:            mockedClock,
+		BaseInterval: time.Second,
+		EvalAppliedFunc: func(alertDefKey models.AlertRuleKey, now time.Time) {
+			evalAppliedCh <- evalAppliedInfo{alertDefKey: alertDefKey, now: now}
+		},
+		RuleStore:     dbstore,
+		InstanceStore: dbstore,
+		Logger:        log.New("ngalert schedule test"),
+		Metrics:       metrics.NewMetrics(prometheus.NewRegistry()),
+		IsLeaderFunc: func() bool {
+			return atomic.LoadInt32(&isLeader) == 1
+		},
+	}
+	sched := schedule.NewScheduler(schedCfg, nil)
+
+	st := state.NewManager(schedCfg.Logger, nilMetrics)
+	go func() {
+		err := sched.Ticker(context.Background(), st)
+		require.NoError(t, err)
+	}()
+	runtime.Gosched()
+
+	t.Run("alert rules should not be evaluated when not the leader", func(t *testing.T) {
+		tick := advanceClock(t, mockedClock)
+		assertEvalRun(t, evalAppliedCh, tick)
+	})
+
+	t.Run("alert rules should be evaluated when the leader", func(t *testing.T) {
+		atomic.StoreInt32(&isLeader, 1)
+		tick := advanceClock(t, mockedClock)
+		assertEvalRun(t, evalAppliedCh, tick, alert.GetKey())
+	})
+}
+
 func assertEvalRun(t *testing.T, ch <-chan evalAppliedInfo, tick time.Time, keys ...models.AlertRuleKey) {
 	timeout := time.After(time.Second)
 
@@ -33,6 +33,8 @@ type UpdateRuleGroupCmd struct {
 type UpsertRule struct {
 	Existing *ngmodels.AlertRule
 	New      ngmodels.AlertRule
+	// RestoredFrom is the version of the existing rule the new one restores, if any
+	RestoredFrom int64
 }
 
 // Store is the interface for persisting alert rules and instances
@@ -42,6 +44,8 @@ type RuleStore interface {
 	DeleteRuleGroupAlertRules(orgID int64, namespaceUID string, ruleGroup string) ([]string, error)
 	DeleteAlertInstancesByRuleUID(orgID int64, ruleUID string) error
 	GetAlertRuleByUID(*ngmodels.GetAlertRuleByUIDQuery) error
+	GetAlertRuleVersion(*ngmodels.GetAlertRuleVersionQuery) error
+	GetAlertRuleVersions(*ngmodels.ListAlertRuleVersionsQuery) error
 	GetAlertRulesForScheduling(query *ngmodels.ListAlertRulesQuery) error
 	GetOrgAlertRules(query *ngmodels.ListAlertRulesQuery) error
 	GetNamespaceAlertRules(query *ngmodels.ListNamespaceAlertRulesQuery) error
@@ -182,6 +186,34 @@ func (st DBstore) GetAlertRuleByUID(query *ngmodels.GetAlertRuleByUIDQuery) erro
 	})
 }
 
+// GetAlertRuleVersion is a handler for retrieving a version of an alert rule.
+func (st DBstore) GetAlertRuleVersion(query *ngmodels.GetAlertRuleVersionQuery) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		version := ngmodels.AlertRuleVersion{}
+		has, err := sess.Where("rule_org_id = ? AND rule_uid = ? AND version = ?", query.OrgID, query.RuleUID, query.Version).Get(&version)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return ngmodels.ErrAlertRuleVersionNotFound
+		}
+		query.Result = &version
+		return nil
+	})
+}
+
+// GetAlertRuleVersions is a handler for retrieving the versions of an alert rule, the newest first.
+func (st DBstore) GetAlertRuleVersions(query *ngmodels.ListAlertRuleVersionsQuery) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		versions := make([]*ngmodels.AlertRuleVersion, 0)
+		if err := sess.Where("rule_org_id = ? AND rule_uid = ?", query.OrgID, query.RuleUID).Desc("version").Find(&versions); err != nil {
+			return err
+		}
+		query.Result = versions
+		return nil
+	})
+}
+
 // UpsertAlertRules is a handler for creating/updating alert rules.
 func (st DBstore) UpsertAlertRules(rules []UpsertRule) error {
 	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
@@ -293,6 +325,7 @@ func (st DBstore) UpsertAlertRules(rules []UpsertRule) error {
 				RuleNamespaceUID: r.New.NamespaceUID,
 				RuleGroup:        r.New.RuleGroup,
 				ParentVersion:    parentVersion,
+				RestoredFrom:     r.RestoredFrom,
 				Version:          r.New.Version,
 				Created:          r.New.Updated,
 				Condition:        r.New.Condition,
@@ -96,12 +96,30 @@ func (cr *configReader) readConfig() ([]*config, error) {
 			return nil, fmt.Errorf("failed to provision dashboards with %q reader: %w", dashboard.Name, err)
 		}
 
+		if dashboard.Name == KubernetesProvisionerName {
+			return nil, fmt.Errorf("failed to provision dashboards with %q reader: the name is reserved for dashboards provisioned from Kubernetes", dashboard.Name)
+		}
+
 		if dashboard.Type == "" {
 			dashboard.Type = "file"
 		}
 
 		if dashboard.UpdateIntervalSeconds == 0 {
 			dashboard.UpdateIntervalSeconds = 10
+			if dashboard.Type == "git" {
+				dashboard.UpdateIntervalSeconds = 60
+			}
+		}
+
+		switch dashboard.SyncUIUpdates {
+		case "", SyncUIUpdatesOverlay:
+		case SyncUIUpdatesFile:
+			// a git checkout is reset with every fetch
+			if dashboard.Type != "file" {
+				return nil, fmt.Errorf("failed to provision dashboards with %q reader: syncUiUpdates %q is only supported by file providers", dashboard.Name, dashboard.SyncUIUpdates)
+			}
+		default:
+			return nil, fmt.Errorf("failed to provision dashboards with %q reader: invalid syncUiUpdates %q, must be %q or %q", dashboard.Name, dashboard.SyncUIUpdates, SyncUIUpdatesFile, SyncUIUpdatesOverlay)
 		}
 		if len(dashboard.FolderUID) > 0 {
 			uidUsage[dashboard.FolderUID]++
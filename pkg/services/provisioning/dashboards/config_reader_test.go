@@ -105,6 +105,7 @@ func validateDashboardAsConfig(t *testing.T, cfg []*config) {
 	require.Equal(t, ds.Options["path"], "/var/lib/grafana/dashboards")
 	require.True(t, ds.DisableDeletion)
 	require.Equal(t, ds.UpdateIntervalSeconds, int64(15))
+	require.Equal(t, ds.SyncUIUpdates, SyncUIUpdatesFile)
 
 	ds2 := cfg[1]
 	require.Equal(t, ds2.Name, "default")
@@ -117,4 +118,5 @@ func validateDashboardAsConfig(t *testing.T, cfg []*config) {
 	require.Equal(t, ds2.Options["path"], "/var/lib/grafana/dashboards")
 	require.False(t, ds2.DisableDeletion)
 	require.Equal(t, ds2.UpdateIntervalSeconds, int64(10))
+	require.Equal(t, ds2.SyncUIUpdates, "")
 }
@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/dashboards"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
@@ -16,9 +18,12 @@ import (
 // Grafana's database.
 type DashboardProvisioner interface {
 	Provision() error
-	PollChanges(ctx context.Context)
+	PollChanges(ctx context.Context, isLeader func() bool)
 	GetProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
+	SyncUIUpdate(provisioning *models.DashboardProvisioning, dash *models.Dashboard) error
+	GetProvisionedDashboard(provisioning *models.DashboardProvisioning) (*simplejson.Json, error)
+	GetGitSyncStatus() []GitSyncStatus
 	CleanUpOrphanedDashboards()
 }
 
@@ -75,11 +80,13 @@ func (provider *Provisioner) Provision() error {
 
 // CleanUpOrphanedDashboards deletes provisioned dashboards missing a linked reader.
 func (provider *Provisioner) CleanUpOrphanedDashboards() {
-	currentReaders := make([]string, len(provider.fileReaders))
+	currentReaders := make([]string, len(provider.fileReaders), len(provider.fileReaders)+1)
 
 	for index, reader := range provider.fileReaders {
 		currentReaders[index] = reader.Cfg.Name
 	}
+	// dashboards provisioned from Kubernetes are cleaned up by the Kubernetes watcher
+	currentReaders = append(currentReaders, KubernetesProvisionerName)
 
 	if err := bus.Dispatch(&models.DeleteOrphanedProvisionedDashboardsCommand{ReaderNames: currentReaders}); err != nil {
 		provider.log.Warn("Failed to delete orphaned provisioned dashboards", "err", err)
@@ -87,10 +94,11 @@ func (provider *Provisioner) CleanUpOrphanedDashboards() {
 }
 
 // PollChanges starts polling for changes in dashboard definition files. It creates a goroutine for each provider
-// defined in the config.
-func (provider *Provisioner) PollChanges(ctx context.Context) {
+// defined in the config. The changes are only applied while `isLeader` returns true, so that several servers don't
+// save them at the same time.
+func (provider *Provisioner) PollChanges(ctx context.Context, isLeader func() bool) {
 	for _, reader := range provider.fileReaders {
-		go reader.pollChanges(ctx)
+		go reader.pollChanges(ctx, isLeader)
 	}
 }
 
@@ -109,12 +117,68 @@ func (provider *Provisioner) GetProvisionerResolvedPath(name string) string {
 func (provider *Provisioner) GetAllowUIUpdatesFromConfig(name string) bool {
 	for _, config := range provider.configs {
 		if config.Name == name {
-			return config.AllowUIUpdates
+			return config.AllowUIUpdates || config.SyncUIUpdates != ""
 		}
 	}
 	return false
 }
 
+// SyncUIUpdate stores a provisioned dashboard saved from the UI as configured by the syncUiUpdates option of its
+// provisioner, either by writing it back to its provisioning file or by keeping it as overlay.
+func (provider *Provisioner) SyncUIUpdate(provisioning *models.DashboardProvisioning, dash *models.Dashboard) error {
+	reader := provider.getFileReader(provisioning.Name)
+	if reader == nil {
+		return nil
+	}
+
+	switch reader.Cfg.SyncUIUpdates {
+	case SyncUIUpdatesFile:
+		return reader.writeDashboardToFile(provisioning, dash)
+	case SyncUIUpdatesOverlay:
+		return bus.Dispatch(&models.UpdateDashboardProvisioningCommand{
+			DashboardId: provisioning.DashboardId,
+			CheckSum:    provisioning.CheckSum,
+			Updated:     provisioning.Updated,
+			Overlay:     true,
+		})
+	}
+	return nil
+}
+
+// GetProvisionedDashboard returns the dashboard as it's defined in its provisioning file.
+func (provider *Provisioner) GetProvisionedDashboard(provisioning *models.DashboardProvisioning) (*simplejson.Json, error) {
+	reader := provider.getFileReader(provisioning.Name)
+	if reader == nil {
+		return nil, fmt.Errorf("dashboard provisioner %q not found", provisioning.Name)
+	}
+
+	jsonFile, err := reader.readDashboardFromFile(provisioning.ExternalId, time.Time{}, 0)
+	if err != nil {
+		return nil, err
+	}
+	return jsonFile.dashboard.Dashboard.Data, nil
+}
+
+// GetGitSyncStatus returns the status of the repositories of the git provisioners.
+func (provider *Provisioner) GetGitSyncStatus() []GitSyncStatus {
+	result := []GitSyncStatus{}
+	for _, reader := range provider.fileReaders {
+		if reader.git != nil {
+			result = append(result, reader.git.getStatus())
+		}
+	}
+	return result
+}
+
+func (provider *Provisioner) getFileReader(name string) *FileReader {
+	for _, reader := range provider.fileReaders {
+		if reader.Cfg.Name == name {
+			return reader
+		}
+	}
+	return nil
+}
+
 func getFileReaders(configs []*config, logger log.Logger, store dashboards.Store) ([]*FileReader, error) {
 	var readers []*FileReader
 
@@ -127,6 +191,13 @@ func getFileReaders(configs []*config, logger log.Logger, store dashboards.Store
 				return nil, errutil.Wrapf(err, "Failed to create file reader for config %v", config.Name)
 			}
 			readers = append(readers, fileReader)
+		case "git":
+			gitReader, err := NewDashboardGitReader(config, logger.New("type", config.Type, "name", config.Name),
+				store)
+			if err != nil {
+				return nil, errutil.Wrapf(err, "Failed to create git reader for config %v", config.Name)
+			}
+			readers = append(readers, gitReader)
 		default:
 			return nil, fmt.Errorf("type %s is not supported", config.Type)
 		}
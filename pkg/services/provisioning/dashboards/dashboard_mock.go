@@ -1,6 +1,11 @@
 package dashboards
 
-import "context"
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+)
 
 // Calls is a mock implementation of the provisioner interface
 type calls struct {
@@ -8,6 +13,9 @@ type calls struct {
 	PollChanges                 []interface{}
 	GetProvisionerResolvedPath  []interface{}
 	GetAllowUIUpdatesFromConfig []interface{}
+	SyncUIUpdate                []interface{}
+	GetProvisionedDashboard     []interface{}
+	GetGitSyncStatus            []interface{}
 }
 
 // ProvisionerMock is a mock implementation of `Provisioner`
@@ -17,6 +25,9 @@ type ProvisionerMock struct {
 	PollChangesFunc                 func(ctx context.Context)
 	GetProvisionerResolvedPathFunc  func(name string) string
 	GetAllowUIUpdatesFromConfigFunc func(name string) bool
+	SyncUIUpdateFunc                func(provisioning *models.DashboardProvisioning, dash *models.Dashboard) error
+	GetProvisionedDashboardFunc     func(provisioning *models.DashboardProvisioning) (*simplejson.Json, error)
+	GetGitSyncStatusFunc            func() []GitSyncStatus
 }
 
 // NewDashboardProvisionerMock returns a new dashboardprovisionermock
@@ -36,7 +47,7 @@ func (dpm *ProvisionerMock) Provision() error {
 }
 
 // PollChanges is a mock implementation of `Provisioner.PollChanges`
-func (dpm *ProvisionerMock) PollChanges(ctx context.Context) {
+func (dpm *ProvisionerMock) PollChanges(ctx context.Context, isLeader func() bool) {
 	dpm.Calls.PollChanges = append(dpm.Calls.PollChanges, ctx)
 	if dpm.PollChangesFunc != nil {
 		dpm.PollChangesFunc(ctx)
@@ -61,5 +72,32 @@ func (dpm *ProvisionerMock) GetAllowUIUpdatesFromConfig(name string) bool {
 	return false
 }
 
+// SyncUIUpdate is a mock implementation of `Provisioner.SyncUIUpdate`
+func (dpm *ProvisionerMock) SyncUIUpdate(provisioning *models.DashboardProvisioning, dash *models.Dashboard) error {
+	dpm.Calls.SyncUIUpdate = append(dpm.Calls.SyncUIUpdate, provisioning)
+	if dpm.SyncUIUpdateFunc != nil {
+		return dpm.SyncUIUpdateFunc(provisioning, dash)
+	}
+	return nil
+}
+
+// GetProvisionedDashboard is a mock implementation of `Provisioner.GetProvisionedDashboard`
+func (dpm *ProvisionerMock) GetProvisionedDashboard(provisioning *models.DashboardProvisioning) (*simplejson.Json, error) {
+	dpm.Calls.GetProvisionedDashboard = append(dpm.Calls.GetProvisionedDashboard, provisioning)
+	if dpm.GetProvisionedDashboardFunc != nil {
+		return dpm.GetProvisionedDashboardFunc(provisioning)
+	}
+	return nil, nil
+}
+
+// GetGitSyncStatus is a mock implementation of `Provisioner.GetGitSyncStatus`
+func (dpm *ProvisionerMock) GetGitSyncStatus() []GitSyncStatus {
+	dpm.Calls.GetGitSyncStatus = append(dpm.Calls.GetGitSyncStatus, nil)
+	if dpm.GetGitSyncStatusFunc != nil {
+		return dpm.GetGitSyncStatusFunc()
+	}
+	return nil
+}
+
 // CleanUpOrphanedDashboards not implemented for mocks
 func (dpm *ProvisionerMock) CleanUpOrphanedDashboards() {}
@@ -0,0 +1,134 @@
+package dashboards
+
+import (
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	dboards "github.com/grafana/grafana/pkg/dashboards"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// KubernetesProvisionerName is the provisioner name of the dashboards provisioned from Kubernetes objects. It's
+// reserved so the dashboards aren't cleaned up as orphans of the dashboard providers.
+const KubernetesProvisionerName = "kubernetes"
+
+// ExternalDashboard is a dashboard provisioned from a source other than the dashboard providers, like a Kubernetes
+// object.
+type ExternalDashboard struct {
+	// ExternalID identifies the dashboard in its source
+	ExternalID string
+	Folder     string
+	JSON       []byte
+}
+
+// ExternalDashboardsProvisioner saves the dashboards of a source other than the dashboard providers to the main
+// organization.
+type ExternalDashboardsProvisioner struct {
+	cfg                          *config
+	log                          log.Logger
+	dashboardProvisioningService dashboards.DashboardProvisioningService
+}
+
+// NewExternalDashboardsProvisioner returns a new ExternalDashboardsProvisioner saving dashboards provisioned by `name`.
+func NewExternalDashboardsProvisioner(name string, log log.Logger, store dboards.Store) *ExternalDashboardsProvisioner {
+	return &ExternalDashboardsProvisioner{
+		cfg:                          &config{Name: name, OrgID: 1},
+		log:                          log,
+		dashboardProvisioningService: dashboards.NewProvisioningService(store),
+	}
+}
+
+// Provision saves the dashboards which changed since they were last provisioned, and deletes the dashboards of the
+// provisioner missing from `externalDashboards`.
+func (p *ExternalDashboardsProvisioner) Provision(externalDashboards []ExternalDashboard) error {
+	provisionedDashboardRefs, err := getProvisionedDashboardsByPath(p.dashboardProvisioningService, p.cfg.Name)
+	if err != nil {
+		return err
+	}
+
+	found := make(map[string]bool, len(externalDashboards))
+	sanityChecker := newProvisioningSanityChecker(p.cfg.Name)
+	for _, external := range externalDashboards {
+		found[external.ExternalID] = true
+
+		provisioningMetadata, err := p.saveDashboard(external, provisionedDashboardRefs[external.ExternalID])
+		if err != nil {
+			p.log.Error("failed to save dashboard", "externalId", external.ExternalID, "error", err)
+			continue
+		}
+
+		sanityChecker.track(provisioningMetadata)
+	}
+	sanityChecker.logWarnings(p.log)
+
+	for externalID, provisioningData := range provisionedDashboardRefs {
+		if found[externalID] {
+			continue
+		}
+
+		p.log.Debug("deleting provisioned dashboard, missing in source", "externalId", externalID)
+		if err := p.dashboardProvisioningService.DeleteProvisionedDashboard(provisioningData.DashboardId, p.cfg.OrgID); err != nil {
+			p.log.Error("failed to delete dashboard", "id", provisioningData.DashboardId, "error", err)
+		}
+	}
+
+	return nil
+}
+
+func (p *ExternalDashboardsProvisioner) saveDashboard(external ExternalDashboard,
+	provisionedData *models.DashboardProvisioning) (provisioningMetadata, error) {
+	provisioningMetadata := provisioningMetadata{}
+
+	// the folder is part of the check sum so moving the dashboard to another folder saves it again
+	checkSum, err := util.Md5SumString(external.Folder + "\n" + string(external.JSON))
+	if err != nil {
+		return provisioningMetadata, err
+	}
+
+	data, err := simplejson.NewJson(external.JSON)
+	if err != nil {
+		return provisioningMetadata, err
+	}
+
+	folderID, err := getOrCreateFolderID(p.cfg, p.dashboardProvisioningService, external.Folder)
+	if err != nil && !errors.Is(err, ErrFolderNameMissing) {
+		return provisioningMetadata, err
+	}
+
+	now := time.Now()
+	dash, err := createDashboardJSON(data, now, p.cfg, folderID)
+	if err != nil {
+		return provisioningMetadata, err
+	}
+
+	provisioningMetadata.uid = dash.Dashboard.Uid
+	provisioningMetadata.identity = dashboardIdentity{title: dash.Dashboard.Title, folderID: dash.Dashboard.FolderId}
+
+	if provisionedData != nil && provisionedData.CheckSum == checkSum {
+		return provisioningMetadata, nil
+	}
+
+	if dash.Dashboard.Id != 0 {
+		dash.Dashboard.Data.Set("id", nil)
+		dash.Dashboard.Id = 0
+	}
+
+	if provisionedData != nil {
+		dash.Dashboard.SetId(provisionedData.DashboardId)
+	}
+
+	p.log.Debug("saving new dashboard", "provisioner", p.cfg.Name, "externalId", external.ExternalID, "folderId", folderID)
+	dp := &models.DashboardProvisioning{
+		ExternalId: external.ExternalID,
+		Name:       p.cfg.Name,
+		Updated:    now.Unix(),
+		CheckSum:   checkSum,
+	}
+
+	_, err = p.dashboardProvisioningService.SaveProvisionedDashboard(dash, dp)
+	return provisioningMetadata, err
+}
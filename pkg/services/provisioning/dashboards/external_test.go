@@ -0,0 +1,76 @@
+package dashboards
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+)
+
+func TestExternalDashboardsProvisioner(t *testing.T) {
+	bus.ClearBusHandlers()
+	origNewDashboardProvisioningService := dashboards.NewProvisioningService
+	t.Cleanup(func() {
+		dashboards.NewProvisioningService = origNewDashboardProvisioningService
+	})
+	fakeService = mockDashboardProvisioningService()
+	bus.AddHandler("test", mockGetDashboardQuery)
+
+	provisioner := NewExternalDashboardsProvisioner(KubernetesProvisionerName, log.New("test-logger"), nil)
+
+	t.Run("Should save new dashboards and their folders", func(t *testing.T) {
+		err := provisioner.Provision([]ExternalDashboard{
+			{ExternalID: "configmap/default/one/one.json", JSON: []byte(`{"title": "One"}`)},
+			{ExternalID: "configmap/default/two/two.json", Folder: "Team", JSON: []byte(`{"title": "Two"}`)},
+		})
+		require.NoError(t, err)
+
+		require.Len(t, fakeService.inserted, 3)
+		require.Len(t, fakeService.provisioned[KubernetesProvisionerName], 2)
+		for _, dash := range fakeService.inserted {
+			require.Equal(t, int64(1), dash.OrgId)
+		}
+	})
+
+	t.Run("Should not save unchanged dashboards", func(t *testing.T) {
+		fakeService.inserted = nil
+
+		err := provisioner.Provision([]ExternalDashboard{
+			{ExternalID: "configmap/default/one/one.json", JSON: []byte(`{"title": "One"}`)},
+			{ExternalID: "configmap/default/two/two.json", Folder: "Team", JSON: []byte(`{"title": "Two changed"}`)},
+		})
+		require.NoError(t, err)
+
+		var titles []string
+		for _, dash := range fakeService.inserted {
+			if !dash.Dashboard.IsFolder {
+				titles = append(titles, dash.Dashboard.Title)
+			}
+		}
+		require.Equal(t, []string{"Two changed"}, titles)
+		require.Len(t, fakeService.provisioned[KubernetesProvisionerName], 2)
+	})
+
+	t.Run("Should delete dashboards missing from the source", func(t *testing.T) {
+		err := provisioner.Provision([]ExternalDashboard{
+			{ExternalID: "configmap/default/one/one.json", JSON: []byte(`{"title": "One"}`)},
+		})
+		require.NoError(t, err)
+
+		require.Len(t, fakeService.provisioned[KubernetesProvisionerName], 1)
+		require.Equal(t, "configmap/default/one/one.json", fakeService.provisioned[KubernetesProvisionerName][0].ExternalId)
+	})
+
+	t.Run("Should skip invalid dashboards", func(t *testing.T) {
+		err := provisioner.Provision([]ExternalDashboard{
+			{ExternalID: "configmap/default/one/one.json", JSON: []byte(`{"title": "One"}`)},
+			{ExternalID: "configmap/default/invalid/invalid.json", JSON: []byte(`{`)},
+		})
+		require.NoError(t, err)
+
+		require.Len(t, fakeService.provisioned[KubernetesProvisionerName], 1)
+	})
+}
@@ -33,6 +33,7 @@ type FileReader struct {
 	log                          log.Logger
 	dashboardProvisioningService dashboards.DashboardProvisioningService
 	FoldersFromFilesStructure    bool
+	git                          *gitRepository
 }
 
 // NewDashboardFileReader returns a new filereader based on `config`
@@ -62,12 +63,17 @@ func NewDashboardFileReader(cfg *config, log log.Logger, store dboards.Store) (*
 	}, nil
 }
 
-// pollChanges periodically runs walkDisk based on interval specified in the config.
-func (fr *FileReader) pollChanges(ctx context.Context) {
+// pollChanges periodically runs walkDisk based on interval specified in the config, on the leader only. The other
+// servers keep their git checkout up to date, the provisioned dashboards being read from it.
+func (fr *FileReader) pollChanges(ctx context.Context, isLeader func() bool) {
 	ticker := time.NewTicker(time.Duration(int64(time.Second) * fr.Cfg.UpdateIntervalSeconds))
 	for {
 		select {
 		case <-ticker.C:
+			if !isLeader() {
+				fr.syncGit()
+				continue
+			}
 			if err := fr.walkDisk(); err != nil {
 				fr.log.Error("failed to search for dashboards", "error", err)
 			}
@@ -77,9 +83,21 @@ func (fr *FileReader) pollChanges(ctx context.Context) {
 	}
 }
 
+// syncGit pulls the git repository of the dashboards, if any.
+func (fr *FileReader) syncGit() {
+	if fr.git == nil {
+		return
+	}
+	if err := fr.git.sync(); err != nil {
+		fr.log.Error("Failed to sync git repository, using the previous checkout", "url", fr.git.url, "error", err)
+	}
+}
+
 // walkDisk traverses the file system for the defined path, reading dashboard definition files,
 // and applies any change to the database.
 func (fr *FileReader) walkDisk() error {
+	fr.syncGit()
+
 	fr.log.Debug("Start walking disk", "path", fr.Path)
 	resolvedPath := fr.resolvedPath()
 	if _, err := os.Stat(resolvedPath); err != nil {
@@ -227,6 +245,11 @@ func (fr *FileReader) saveDashboard(path string, folderID int64, fileInfo os.Fil
 		return provisioningMetadata, nil
 	}
 
+	if alreadyProvisioned && provisionedData.Overlay && fr.Cfg.SyncUIUpdates == SyncUIUpdatesOverlay {
+		fr.log.Debug("keeping changes from the UI of provisioned dashboard", "provisioner", fr.Cfg.Name, "file", path)
+		return provisioningMetadata, nil
+	}
+
 	if dash.Dashboard.Id != 0 {
 		dash.Dashboard.Data.Set("id", nil)
 		dash.Dashboard.Id = 0
@@ -248,6 +271,55 @@ func (fr *FileReader) saveDashboard(path string, folderID int64, fileInfo os.Fil
 	return provisioningMetadata, err
 }
 
+// writeDashboardToFile writes a provisioned dashboard saved from the UI back to its provisioning file, and updates
+// the provisioning metadata so the dashboard isn't provisioned again.
+func (fr *FileReader) writeDashboardToFile(provisioning *models.DashboardProvisioning, dash *models.Dashboard) error {
+	raw, err := dash.Data.Encode()
+	if err != nil {
+		return err
+	}
+
+	// copy the dashboard to not change the saved model
+	data, err := simplejson.NewJson(raw)
+	if err != nil {
+		return err
+	}
+	data.Del("id")
+	data.Del("version")
+
+	content, err := data.EncodePretty()
+	if err != nil {
+		return err
+	}
+
+	path := provisioning.ExternalId
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(path, content, fileInfo.Mode()); err != nil {
+		return err
+	}
+
+	checkSum, err := util.Md5SumString(string(content))
+	if err != nil {
+		return err
+	}
+
+	fileInfo, err = os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	fr.log.Debug("wrote dashboard saved from the UI to file", "provisioner", fr.Cfg.Name, "file", path)
+	return bus.Dispatch(&models.UpdateDashboardProvisioningCommand{
+		DashboardId: provisioning.DashboardId,
+		CheckSum:    checkSum,
+		Updated:     fileInfo.ModTime().Unix(),
+	})
+}
+
 func getProvisionedDashboardsByPath(service dashboards.DashboardProvisioningService, name string) (
 	map[string]*models.DashboardProvisioning, error) {
 	arr, err := service.GetProvisionedDashboardData(name)
@@ -2,6 +2,7 @@ package dashboards
 
 import (
 	"fmt"
+	"io/ioutil"
 	"math/rand"
 	"os"
 	"path/filepath"
@@ -10,6 +11,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	dboards "github.com/grafana/grafana/pkg/dashboards"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/dashboards"
@@ -212,6 +214,74 @@ func TestDashboardFileReader(t *testing.T) {
 				So(len(fakeService.inserted), ShouldEqual, 1)
 			})
 
+			Convey("Dashboard with changes from the UI kept as overlay will not be replaced", func() {
+				cfg.Options["path"] = oneDashboard
+				cfg.SyncUIUpdates = SyncUIUpdatesOverlay
+				absPath, err := filepath.Abs(oneDashboard + "/dashboard1.json")
+				So(err, ShouldBeNil)
+
+				fakeService.provisioned = map[string][]*models.DashboardProvisioning{
+					"Default": {
+						{
+							Name:       "Default",
+							ExternalId: absPath,
+							CheckSum:   "fakechecksum",
+							Overlay:    true,
+						},
+					},
+				}
+
+				reader, err := NewDashboardFileReader(cfg, logger, nil)
+				So(err, ShouldBeNil)
+
+				err = reader.walkDisk()
+				So(err, ShouldBeNil)
+				So(len(fakeService.inserted), ShouldEqual, 0)
+			})
+
+			Convey("Dashboard saved from the UI is written back to its file", func() {
+				dir := t.TempDir()
+				path := filepath.Join(dir, "dashboard.json")
+				err := ioutil.WriteFile(path, []byte(`{"title": "Old title"}`), 0600)
+				So(err, ShouldBeNil)
+
+				var updateCmd *models.UpdateDashboardProvisioningCommand
+				bus.AddHandler("test", func(cmd *models.UpdateDashboardProvisioningCommand) error {
+					updateCmd = cmd
+					return nil
+				})
+
+				cfg.Options["path"] = dir
+				cfg.SyncUIUpdates = SyncUIUpdatesFile
+				reader, err := NewDashboardFileReader(cfg, logger, nil)
+				So(err, ShouldBeNil)
+
+				dash := models.NewDashboardFromJson(simplejson.NewFromAny(map[string]interface{}{
+					"id":      3,
+					"uid":     "abc",
+					"title":   "New title",
+					"version": 2,
+				}))
+				err = reader.writeDashboardToFile(&models.DashboardProvisioning{DashboardId: 3, Name: "Default", ExternalId: path}, dash)
+				So(err, ShouldBeNil)
+
+				content, err := ioutil.ReadFile(filepath.Clean(path))
+				So(err, ShouldBeNil)
+				data, err := simplejson.NewJson(content)
+				So(err, ShouldBeNil)
+				So(data.Get("title").MustString(), ShouldEqual, "New title")
+				So(data.Get("uid").MustString(), ShouldEqual, "abc")
+				So(data.Get("id").Interface(), ShouldBeNil)
+				So(dash.Data.Get("id").MustInt64(), ShouldEqual, 3)
+
+				checkSum, err := util.Md5SumString(string(content))
+				So(err, ShouldBeNil)
+				So(updateCmd, ShouldNotBeNil)
+				So(updateCmd.DashboardId, ShouldEqual, 3)
+				So(updateCmd.CheckSum, ShouldEqual, checkSum)
+				So(updateCmd.Overlay, ShouldBeFalse)
+			})
+
 			Convey("Dashboard with newer timestamp and the same checksum will not replace imported dashboard", func() {
 				cfg.Options["path"] = oneDashboard
 				absPath, err := filepath.Abs(oneDashboard + "/dashboard1.json")
@@ -0,0 +1,210 @@
+package dashboards
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	dboards "github.com/grafana/grafana/pkg/dashboards"
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+const gitCommandTimeout = 2 * time.Minute
+
+// GitSyncStatus is the status of the repository of a git dashboard provisioner.
+type GitSyncStatus struct {
+	Name               string    `json:"name"`
+	URL                string    `json:"url"`
+	Branch             string    `json:"branch"`
+	Commit             string    `json:"commit"`
+	LastSync           time.Time `json:"lastSync"`
+	LastSuccessfulSync time.Time `json:"lastSuccessfulSync"`
+	Error              string    `json:"error,omitempty"`
+}
+
+// gitRepository keeps a shallow checkout of a branch of a git repository in sync with the remote.
+type gitRepository struct {
+	url        string
+	branch     string
+	dir        string
+	username   string
+	token      string
+	tokenFile  string
+	sshKey     string
+	sshKeyFile string
+	log        log.Logger
+
+	mutex  sync.Mutex
+	status GitSyncStatus
+}
+
+// NewDashboardGitReader returns a new filereader reading the dashboards of the git repository
+// configured by `cfg`, the repository is checked out to the path option.
+func NewDashboardGitReader(cfg *config, log log.Logger, store dboards.Store) (*FileReader, error) {
+	url, _ := cfg.Options["url"].(string)
+	if url == "" {
+		return nil, fmt.Errorf("failed to load dashboards, url param is missing")
+	}
+
+	reader, err := NewDashboardFileReader(cfg, log, store)
+	if err != nil {
+		return nil, err
+	}
+
+	repo := &gitRepository{
+		url: url,
+		dir: reader.Path,
+		log: log,
+	}
+	repo.branch, _ = cfg.Options["branch"].(string)
+	repo.username, _ = cfg.Options["username"].(string)
+	repo.token, _ = cfg.Options["token"].(string)
+	repo.tokenFile, _ = cfg.Options["tokenFile"].(string)
+	repo.sshKey, _ = cfg.Options["sshKey"].(string)
+	repo.sshKeyFile, _ = cfg.Options["sshKeyFile"].(string)
+	if repo.username == "" {
+		repo.username = "git"
+	}
+	repo.status = GitSyncStatus{Name: cfg.Name, URL: url, Branch: repo.branch}
+
+	if subPath, ok := cfg.Options["subPath"].(string); ok {
+		reader.Path = filepath.Join(reader.Path, filepath.Clean("/"+subPath))
+	}
+	reader.git = repo
+
+	return reader, nil
+}
+
+// sync fetches the branch from the remote and checks it out, the previous checkout is kept if it fails.
+func (r *gitRepository) sync() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.status.LastSync = time.Now()
+	commit, err := r.pull()
+	if err != nil {
+		r.status.Error = err.Error()
+		return err
+	}
+
+	if commit != r.status.Commit {
+		r.log.Info("Checked out git repository", "url", r.url, "branch", r.branch, "commit", commit)
+	}
+	r.status.Error = ""
+	r.status.Commit = commit
+	r.status.LastSuccessfulSync = r.status.LastSync
+	return nil
+}
+
+func (r *gitRepository) getStatus() GitSyncStatus {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.status
+}
+
+func (r *gitRepository) pull() (string, error) {
+	if _, err := os.Stat(filepath.Join(r.dir, ".git")); os.IsNotExist(err) {
+		if err := os.MkdirAll(r.dir, 0750); err != nil {
+			return "", err
+		}
+		if _, err := r.git(nil, "init", "--quiet"); err != nil {
+			return "", err
+		}
+	}
+
+	env, err := r.authEnv()
+	if err != nil {
+		return "", err
+	}
+
+	ref := r.branch
+	if ref == "" {
+		ref = "HEAD"
+	}
+	// the url is fetched directly instead of configuring a remote so changes of the url apply without migrating
+	// the checkout
+	if _, err := r.git(env, "fetch", "--quiet", "--depth", "1", r.url, ref); err != nil {
+		return "", err
+	}
+	if _, err := r.git(nil, "reset", "--quiet", "--hard", "FETCH_HEAD"); err != nil {
+		return "", err
+	}
+
+	commit, err := r.git(nil, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(commit), nil
+}
+
+// authEnv returns the environment to authenticate with the remote. The secrets are passed by environment, instead
+// of arguments, so they aren't visible in the process list, and files are read again with each sync in case
+// they're rotated.
+func (r *gitRepository) authEnv() ([]string, error) {
+	var env []string
+
+	token := r.token
+	if r.tokenFile != "" {
+		content, err := ioutil.ReadFile(filepath.Clean(r.tokenFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read git token file: %w", err)
+		}
+		token = strings.TrimSpace(string(content))
+	}
+	if token != "" {
+		auth := base64.StdEncoding.EncodeToString([]byte(r.username + ":" + token))
+		env = append(env,
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=http.extraHeader",
+			"GIT_CONFIG_VALUE_0=Authorization: Basic "+auth,
+		)
+	}
+
+	keyFile := r.sshKeyFile
+	if r.sshKey != "" {
+		keyFile = filepath.Join(r.dir, ".git", "grafana_ssh_key")
+		if err := ioutil.WriteFile(keyFile, []byte(strings.TrimSpace(r.sshKey)+"\n"), 0600); err != nil {
+			return nil, fmt.Errorf("failed to write git ssh key: %w", err)
+		}
+	}
+	if keyFile != "" {
+		knownHosts := filepath.Join(r.dir, ".git", "known_hosts")
+		env = append(env, fmt.Sprintf(
+			"GIT_SSH_COMMAND=ssh -i %s -o IdentitiesOnly=yes -o BatchMode=yes -o StrictHostKeyChecking=accept-new -o UserKnownHostsFile=%s",
+			shellQuote(keyFile), shellQuote(knownHosts)))
+	}
+
+	return env, nil
+}
+
+func (r *gitRepository) git(env []string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), gitCommandTimeout)
+	defer cancel()
+
+	// nolint:gosec
+	// We can ignore the gosec G204 warning since the arguments come from the provisioning configuration file.
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = r.dir
+	cmd.Env = append(append(os.Environ(), "GIT_TERMINAL_PROMPT=0"), env...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.String(), nil
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,116 @@
+package dashboards
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+)
+
+func TestDashboardGitReader(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	bus.ClearBusHandlers()
+	origNewDashboardProvisioningService := dashboards.NewProvisioningService
+	t.Cleanup(func() {
+		dashboards.NewProvisioningService = origNewDashboardProvisioningService
+	})
+	fakeService = mockDashboardProvisioningService()
+	bus.AddHandler("test", mockGetDashboardQuery)
+
+	remote := t.TempDir()
+	runGit(t, remote, "init", "--quiet")
+	writeDashboard(t, remote, "dashboards/one.json", "One")
+	writeDashboard(t, remote, "other/two.json", "Two")
+	firstCommit := commitAll(t, remote)
+
+	cfg := &config{
+		Name:  "Default",
+		Type:  "git",
+		OrgID: 1,
+		Options: map[string]interface{}{
+			"url":     "file://" + remote,
+			"path":    filepath.Join(t.TempDir(), "checkout"),
+			"subPath": "dashboards",
+		},
+	}
+
+	reader, err := NewDashboardGitReader(cfg, log.New("test-logger"), nil)
+	require.NoError(t, err)
+
+	t.Run("Should check out the repository and provision its dashboards", func(t *testing.T) {
+		require.NoError(t, reader.walkDisk())
+
+		require.Len(t, fakeService.inserted, 1)
+		require.Equal(t, "One", fakeService.inserted[0].Dashboard.Title)
+
+		status := reader.git.getStatus()
+		require.Equal(t, firstCommit, status.Commit)
+		require.Empty(t, status.Error)
+		require.False(t, status.LastSuccessfulSync.IsZero())
+	})
+
+	t.Run("Should provision dashboards of new commits", func(t *testing.T) {
+		writeDashboard(t, remote, "dashboards/three.json", "Three")
+		secondCommit := commitAll(t, remote)
+
+		require.NoError(t, reader.walkDisk())
+
+		require.Len(t, fakeService.inserted, 2)
+		require.Equal(t, "Three", fakeService.inserted[1].Dashboard.Title)
+		require.Equal(t, secondCommit, reader.git.getStatus().Commit)
+	})
+
+	t.Run("Should keep the previous checkout if the fetch fails", func(t *testing.T) {
+		reader.git.url = "file://" + filepath.Join(remote, "missing")
+
+		require.NoError(t, reader.walkDisk())
+
+		status := reader.git.getStatus()
+		require.Contains(t, status.Error, "git fetch failed")
+		require.Len(t, fakeService.provisioned["Default"], 2)
+	})
+
+	t.Run("Should require the url", func(t *testing.T) {
+		_, err := NewDashboardGitReader(&config{Name: "Default", Type: "git", Options: map[string]interface{}{
+			"path": t.TempDir(),
+		}}, log.New("test-logger"), nil)
+		require.Error(t, err)
+	})
+}
+
+func writeDashboard(t *testing.T, dir, name, title string) {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"title": "`+title+`"}`), 0600))
+}
+
+func commitAll(t *testing.T, dir string) string {
+	t.Helper()
+
+	runGit(t, dir, "add", "--all")
+	runGit(t, dir, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "update")
+	return strings.TrimSpace(runGit(t, dir, "rev-parse", "HEAD"))
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	return string(out)
+}
@@ -10,6 +10,14 @@ import (
 	"github.com/grafana/grafana/pkg/services/provisioning/values"
 )
 
+const (
+	// SyncUIUpdatesFile writes dashboards saved from the UI back to their provisioning file.
+	SyncUIUpdatesFile = "file"
+	// SyncUIUpdatesOverlay keeps dashboards saved from the UI, even if their provisioning file changes,
+	// until the changes are discarded with the drift API.
+	SyncUIUpdatesOverlay = "overlay"
+)
+
 type config struct {
 	Name                  string
 	Type                  string
@@ -21,6 +29,7 @@ type config struct {
 	DisableDeletion       bool
 	UpdateIntervalSeconds int64
 	AllowUIUpdates        bool
+	SyncUIUpdates         string
 }
 
 type configV0 struct {
@@ -34,6 +43,7 @@ type configV0 struct {
 	DisableDeletion       bool                   `json:"disableDeletion" yaml:"disableDeletion"`
 	UpdateIntervalSeconds int64                  `json:"updateIntervalSeconds" yaml:"updateIntervalSeconds"`
 	AllowUIUpdates        bool                   `json:"allowUiUpdates" yaml:"allowUiUpdates"`
+	SyncUIUpdates         string                 `json:"syncUiUpdates" yaml:"syncUiUpdates"`
 }
 
 type configVersion struct {
@@ -55,6 +65,7 @@ type configs struct {
 	DisableDeletion       values.BoolValue   `json:"disableDeletion" yaml:"disableDeletion"`
 	UpdateIntervalSeconds values.Int64Value  `json:"updateIntervalSeconds" yaml:"updateIntervalSeconds"`
 	AllowUIUpdates        values.BoolValue   `json:"allowUiUpdates" yaml:"allowUiUpdates"`
+	SyncUIUpdates         values.StringValue `json:"syncUiUpdates" yaml:"syncUiUpdates"`
 }
 
 func createDashboardJSON(data *simplejson.Json, lastModified time.Time, cfg *config, folderID int64) (*dashboards.SaveDashboardDTO, error) {
@@ -94,6 +105,7 @@ func mapV0ToDashboardsAsConfig(v0 []*configV0) ([]*config, error) {
 			DisableDeletion:       v.DisableDeletion,
 			UpdateIntervalSeconds: v.UpdateIntervalSeconds,
 			AllowUIUpdates:        v.AllowUIUpdates,
+			SyncUIUpdates:         v.SyncUIUpdates,
 		})
 	}
 
@@ -121,6 +133,7 @@ func (dc *configV1) mapToDashboardsAsConfig() ([]*config, error) {
 			DisableDeletion:       v.DisableDeletion.Value(),
 			UpdateIntervalSeconds: v.UpdateIntervalSeconds.Value(),
 			AllowUIUpdates:        v.AllowUIUpdates.Value(),
+			SyncUIUpdates:         v.SyncUIUpdates.Value(),
 		})
 	}
 
@@ -5,8 +5,10 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/provisioning/utils"
@@ -57,8 +59,39 @@ func (cr *configReader) parseDatasourceConfig(path string, file os.FileInfo) (*c
 		return nil, err
 	}
 
+	return cr.parseDatasourceConfigContent(filename, yamlFile)
+}
+
+// readConfigContents parses the provisioning config files given by content, in the order of their names.
+func (cr *configReader) readConfigContents(files map[string][]byte) ([]*configs, error) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var datasources []*configs
+	for _, name := range names {
+		datasource, err := cr.parseDatasourceConfigContent(name, files[name])
+		if err != nil {
+			return nil, fmt.Errorf("could not parse provisioning config file: %s error: %w", name, err)
+		}
+
+		if datasource != nil {
+			datasources = append(datasources, datasource)
+		}
+	}
+
+	if err := cr.validateDefaultUniqueness(datasources); err != nil {
+		return nil, err
+	}
+
+	return datasources, nil
+}
+
+func (cr *configReader) parseDatasourceConfigContent(filename string, yamlFile []byte) (*configs, error) {
 	var apiVersion *configVersion
-	err = yaml.Unmarshal(yamlFile, &apiVersion)
+	err := yaml.Unmarshal(yamlFile, &apiVersion)
 	if err != nil {
 		return nil, err
 	}
@@ -104,6 +137,14 @@ func (cr *configReader) validateDefaultUniqueness(datasources []*configs) error
 				return fmt.Errorf("failed to provision %q data source: %w", ds.Name, err)
 			}
 
+			jsonData := simplejson.NewFromAny(ds.JSONData)
+			if _, err := models.QueryExamplesFromJSONData(jsonData); err != nil {
+				return fmt.Errorf("failed to provision %q data source: %w", ds.Name, err)
+			}
+			if _, err := models.WarmUpQueriesFromJSONData(jsonData); err != nil {
+				return fmt.Errorf("failed to provision %q data source: %w", ds.Name, err)
+			}
+
 			if ds.IsDefault {
 				defaultCount[ds.OrgID]++
 				if defaultCount[ds.OrgID] > 1 {
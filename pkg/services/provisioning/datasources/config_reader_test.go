@@ -144,6 +144,35 @@ func TestDatasourceAsConfig(t *testing.T) {
 			})
 		})
 
+		Convey("Config files given by content", func() {
+			Convey("should provision datasources and return them", func() {
+				provisioned, err := ProvisionConfigs(map[string][]byte{
+					"b.yaml": []byte("apiVersion: 1\ndatasources:\n  - name: Prometheus\n    type: prometheus\n"),
+					"a.yaml": []byte("apiVersion: 1\ndatasources:\n  - name: Graphite\n    type: graphite\n    orgId: 2\n"),
+				})
+				So(err, ShouldBeNil)
+				So(len(fakeRepo.inserted), ShouldEqual, 2)
+				So(provisioned, ShouldResemble, []ProvisionedDatasource{
+					{OrgID: 2, Name: "Graphite"},
+					{OrgID: 1, Name: "Prometheus"},
+				})
+			})
+
+			Convey("should return error for more than one default datasource", func() {
+				_, err := ProvisionConfigs(map[string][]byte{
+					"a.yaml": []byte("apiVersion: 1\ndatasources:\n  - name: Graphite\n    isDefault: true\n"),
+					"b.yaml": []byte("apiVersion: 1\ndatasources:\n  - name: Prometheus\n    isDefault: true\n"),
+				})
+				So(err, ShouldEqual, ErrInvalidConfigToManyDefault)
+				So(len(fakeRepo.inserted), ShouldEqual, 0)
+			})
+
+			Convey("should return error for broken yaml", func() {
+				_, err := ProvisionConfigs(map[string][]byte{"a.yaml": []byte("datasources: [")})
+				So(err, ShouldNotBeNil)
+			})
+		})
+
 		Convey("broken yaml should return error", func() {
 			reader := &configReader{}
 			_, err := reader.readConfig(brokenYaml)
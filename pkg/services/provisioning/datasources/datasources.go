@@ -23,6 +23,35 @@ func Provision(configDirectory string) error {
 	return dc.applyChanges(configDirectory)
 }
 
+// ProvisionedDatasource identifies a datasource provisioned by ProvisionConfigs.
+type ProvisionedDatasource struct {
+	OrgID int64
+	Name  string
+}
+
+// ProvisionConfigs provisions the datasources of the provisioning config files given by content, like the files of
+// a Kubernetes ConfigMap, and returns the provisioned datasources.
+func ProvisionConfigs(files map[string][]byte) ([]ProvisionedDatasource, error) {
+	dc := newDatasourceProvisioner(log.New("provisioning.datasources"))
+	configs, err := dc.cfgProvider.readConfigContents(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var provisioned []ProvisionedDatasource
+	for _, cfg := range configs {
+		if err := dc.apply(cfg); err != nil {
+			return nil, err
+		}
+
+		for _, ds := range cfg.Datasources {
+			provisioned = append(provisioned, ProvisionedDatasource{OrgID: ds.OrgID, Name: ds.Name})
+		}
+	}
+
+	return provisioned, nil
+}
+
 // DatasourceProvisioner is responsible for provisioning datasources based on
 // configuration read by the `configReader`
 type DatasourceProvisioner struct {
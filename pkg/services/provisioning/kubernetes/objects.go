@@ -0,0 +1,121 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
+)
+
+const (
+	// folderAnnotation is the annotation of the folder of the dashboards of an object
+	folderAnnotation = "grafana_folder"
+
+	dashboardResource  = "grafanadashboards"
+	datasourceResource = "grafanadatasources"
+)
+
+// dashboardsFromObjects returns the dashboards of the keys ending with .json of ConfigMaps and Secrets, and of the
+// spec.json field of GrafanaDashboard custom resources, ordered by external ID.
+func dashboardsFromObjects(objects []interface{}, logger log.Logger) []dashboards.ExternalDashboard {
+	var result []dashboards.ExternalDashboard
+	add := func(externalID, folder string, content []byte) {
+		result = append(result, dashboards.ExternalDashboard{ExternalID: externalID, Folder: folder, JSON: content})
+	}
+
+	for _, obj := range objects {
+		switch o := obj.(type) {
+		case *corev1.ConfigMap:
+			folder := o.Annotations[folderAnnotation]
+			for key, content := range configMapFiles(o) {
+				if strings.HasSuffix(key, ".json") {
+					add(objectID("configmap", o.Namespace, o.Name)+"/"+key, folder, content)
+				}
+			}
+		case *corev1.Secret:
+			folder := o.Annotations[folderAnnotation]
+			for key, content := range o.Data {
+				if strings.HasSuffix(key, ".json") {
+					add(objectID("secret", o.Namespace, o.Name)+"/"+key, folder, content)
+				}
+			}
+		case *unstructured.Unstructured:
+			content, found, err := unstructured.NestedString(o.Object, "spec", "json")
+			if err != nil || !found {
+				logger.Warn("Skipping GrafanaDashboard without spec.json", "namespace", o.GetNamespace(), "name", o.GetName())
+				continue
+			}
+
+			folder, _, _ := unstructured.NestedString(o.Object, "spec", "customFolderName")
+			if folder == "" {
+				folder = o.GetAnnotations()[folderAnnotation]
+			}
+			add(objectID("grafanadashboard", o.GetNamespace(), o.GetName()), folder, []byte(content))
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ExternalID < result[j].ExternalID
+	})
+	return result
+}
+
+// datasourceFilesFromObjects returns the data source provisioning files of the keys of ConfigMaps and Secrets, and of
+// the spec.datasources field of GrafanaDataSource custom resources, by external ID.
+func datasourceFilesFromObjects(objects []interface{}, logger log.Logger) map[string][]byte {
+	result := map[string][]byte{}
+
+	for _, obj := range objects {
+		switch o := obj.(type) {
+		case *corev1.ConfigMap:
+			for key, content := range configMapFiles(o) {
+				result[objectID("configmap", o.Namespace, o.Name)+"/"+key] = content
+			}
+		case *corev1.Secret:
+			for key, content := range o.Data {
+				result[objectID("secret", o.Namespace, o.Name)+"/"+key] = content
+			}
+		case *unstructured.Unstructured:
+			datasources, found, err := unstructured.NestedSlice(o.Object, "spec", "datasources")
+			if err != nil || !found {
+				logger.Warn("Skipping GrafanaDataSource without spec.datasources", "namespace", o.GetNamespace(), "name", o.GetName())
+				continue
+			}
+
+			// the provisioning files are YAML, which JSON is a subset of
+			content, err := json.Marshal(map[string]interface{}{
+				"apiVersion":  1,
+				"datasources": datasources,
+			})
+			if err != nil {
+				logger.Warn("Skipping invalid GrafanaDataSource", "namespace", o.GetNamespace(), "name", o.GetName(), "error", err)
+				continue
+			}
+			result[objectID("grafanadatasource", o.GetNamespace(), o.GetName())] = content
+		}
+	}
+
+	return result
+}
+
+func configMapFiles(cm *corev1.ConfigMap) map[string][]byte {
+	files := make(map[string][]byte, len(cm.Data)+len(cm.BinaryData))
+	for key, content := range cm.Data {
+		files[key] = []byte(content)
+	}
+	for key, content := range cm.BinaryData {
+		files[key] = content
+	}
+	return files
+}
+
+// objectID identifies an object in the external IDs of its dashboards and data source files.
+func objectID(kind, namespace, name string) string {
+	return fmt.Sprintf("%s/%s/%s", kind, namespace, name)
+}
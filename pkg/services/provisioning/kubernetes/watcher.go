@@ -0,0 +1,263 @@
+// Package kubernetes provisions dashboards and data sources from Kubernetes objects, ConfigMaps, Secrets and
+// custom resources, watching them so changes are applied when they happen.
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/grafana/grafana/pkg/bus"
+	dboards "github.com/grafana/grafana/pkg/dashboards"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
+	"github.com/grafana/grafana/pkg/services/provisioning/datasources"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	allNamespaces = "ALL"
+	// syncDelay is how long the watcher waits for more changes before applying them, so that updating many objects
+	// at once, e.g. with kubectl apply, applies them together.
+	syncDelay = time.Second
+)
+
+// Watcher watches Kubernetes objects for dashboards and data sources and provisions them.
+type Watcher struct {
+	log                  log.Logger
+	dashboardInformers   []cache.SharedIndexInformer
+	datasourceInformers  []cache.SharedIndexInformer
+	provisionDashboards  func([]dashboards.ExternalDashboard) error
+	provisionDatasources func(map[string][]byte) ([]datasources.ProvisionedDatasource, error)
+	start                []func(stopCh <-chan struct{})
+	changes              chan struct{}
+
+	// provisionedDatasources are the data sources of the last sync, to delete them once their objects are deleted
+	provisionedDatasources []datasources.ProvisionedDatasource
+}
+
+// NewWatcher returns a new Watcher connecting to the Kubernetes API server from the kubeconfig of the settings, or
+// the in-cluster config.
+func NewWatcher(cfg setting.KubernetesProvisioningSettings, store dboards.Store) (*Watcher, error) {
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: cfg.Kubeconfig},
+		&clientcmd.ConfigOverrides{},
+	)
+
+	restConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Kubernetes client config: %w", err)
+	}
+
+	namespace := cfg.Namespace
+	switch namespace {
+	case allNamespaces:
+		namespace = ""
+	case "":
+		// the namespace of the kubeconfig context, or the namespace Grafana runs in for the in-cluster config
+		namespace, _, err = clientConfig.Namespace()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Kubernetes namespace: %w", err)
+		}
+	}
+
+	client, err := clientset.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var dynamicClient dynamic.Interface
+	if cfg.CRDGroupVersion != "" {
+		dynamicClient, err = dynamic.NewForConfig(restConfig)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return newWatcher(cfg, namespace, client, dynamicClient, store)
+}
+
+func newWatcher(cfg setting.KubernetesProvisioningSettings, namespace string, client clientset.Interface,
+	dynamicClient dynamic.Interface, store dboards.Store) (*Watcher, error) {
+	logger := log.New("provisioning.kubernetes")
+	w := &Watcher{
+		log:                  logger,
+		provisionDashboards:  dashboards.NewExternalDashboardsProvisioner(dashboards.KubernetesProvisionerName, logger, store).Provision,
+		provisionDatasources: datasources.ProvisionConfigs,
+		changes:              make(chan struct{}, 1),
+	}
+
+	dashboardInformers, err := w.newInformers(cfg, namespace, cfg.DashboardsLabelSelector, client)
+	if err != nil {
+		return nil, err
+	}
+	datasourceInformers, err := w.newInformers(cfg, namespace, cfg.DatasourcesLabelSelector, client)
+	if err != nil {
+		return nil, err
+	}
+	w.dashboardInformers = dashboardInformers
+	w.datasourceInformers = datasourceInformers
+
+	if dynamicClient != nil {
+		gv, err := schema.ParseGroupVersion(cfg.CRDGroupVersion)
+		if err != nil {
+			return nil, fmt.Errorf("invalid custom resource group version %q: %w", cfg.CRDGroupVersion, err)
+		}
+
+		factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(dynamicClient, cfg.ResyncInterval, namespace, nil)
+		w.dashboardInformers = append(w.dashboardInformers,
+			w.watch(factory.ForResource(gv.WithResource(dashboardResource)).Informer()))
+		w.datasourceInformers = append(w.datasourceInformers,
+			w.watch(factory.ForResource(gv.WithResource(datasourceResource)).Informer()))
+		w.start = append(w.start, factory.Start)
+	}
+
+	return w, nil
+}
+
+// newInformers returns the informers of the resources of the settings, selecting objects with `labelSelector`.
+func (w *Watcher) newInformers(cfg setting.KubernetesProvisioningSettings, namespace, labelSelector string,
+	client clientset.Interface) ([]cache.SharedIndexInformer, error) {
+	factory := informers.NewSharedInformerFactoryWithOptions(client, cfg.ResyncInterval,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.LabelSelector = labelSelector
+		}),
+	)
+
+	var result []cache.SharedIndexInformer
+	for _, resource := range cfg.Resources {
+		switch resource {
+		case "configmaps":
+			result = append(result, w.watch(factory.Core().V1().ConfigMaps().Informer()))
+		case "secrets":
+			result = append(result, w.watch(factory.Core().V1().Secrets().Informer()))
+		default:
+			return nil, fmt.Errorf("unsupported Kubernetes resource %q, must be configmaps or secrets", resource)
+		}
+	}
+	w.start = append(w.start, factory.Start)
+
+	return result, nil
+}
+
+// watch registers the handler notifying the watcher of changes of the objects of `informer`.
+func (w *Watcher) watch(informer cache.SharedIndexInformer) cache.SharedIndexInformer {
+	notify := func() {
+		select {
+		case w.changes <- struct{}{}:
+		default:
+			// a sync is pending already
+		}
+	}
+
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { notify() },
+		UpdateFunc: func(interface{}, interface{}) { notify() },
+		DeleteFunc: func(interface{}) { notify() },
+	})
+	return informer
+}
+
+// Run watches the objects until `ctx` is cancelled, the dashboards and data sources are provisioned on start and
+// whenever their objects change.
+func (w *Watcher) Run(ctx context.Context) error {
+	synced := make([]cache.InformerSynced, 0, len(w.dashboardInformers)+len(w.datasourceInformers))
+	for _, informer := range append(w.dashboardInformers, w.datasourceInformers...) {
+		synced = append(synced, informer.HasSynced)
+	}
+	for _, start := range w.start {
+		start(ctx.Done())
+	}
+
+	w.log.Info("Watching Kubernetes objects for dashboards and data sources")
+	if !cache.WaitForCacheSync(ctx.Done(), synced...) {
+		return ctx.Err()
+	}
+
+	for {
+		// the objects of the caches are all applied again with every change since the dashboards and data sources
+		// of an object can't be told apart from the change alone
+		w.sync()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-w.changes:
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(syncDelay):
+		}
+	}
+}
+
+func (w *Watcher) sync() {
+	if err := w.syncDashboards(); err != nil {
+		w.log.Error("Failed to provision dashboards from Kubernetes", "error", err)
+	}
+
+	if err := w.syncDatasources(); err != nil {
+		w.log.Error("Failed to provision data sources from Kubernetes", "error", err)
+	}
+}
+
+func (w *Watcher) syncDashboards() error {
+	var objects []interface{}
+	for _, informer := range w.dashboardInformers {
+		objects = append(objects, informer.GetStore().List()...)
+	}
+
+	return w.provisionDashboards(dashboardsFromObjects(objects, w.log))
+}
+
+func (w *Watcher) syncDatasources() error {
+	var objects []interface{}
+	for _, informer := range w.datasourceInformers {
+		objects = append(objects, informer.GetStore().List()...)
+	}
+
+	provisioned, err := w.provisionDatasources(datasourceFilesFromObjects(objects, w.log))
+	if err != nil {
+		// the data sources of the last sync are kept, since they can't be told apart from the ones of the invalid files
+		return err
+	}
+
+	current := make(map[datasources.ProvisionedDatasource]bool, len(provisioned))
+	for _, ds := range provisioned {
+		current[ds] = true
+	}
+
+	for _, ds := range w.provisionedDatasources {
+		if current[ds] {
+			continue
+		}
+
+		cmd := &models.DeleteDataSourceCommand{OrgID: ds.OrgID, Name: ds.Name}
+		if err := bus.Dispatch(cmd); err != nil {
+			w.log.Error("Failed to delete data source", "name", ds.Name, "orgId", ds.OrgID, "error", err)
+			// deleting it is retried with the next sync
+			provisioned = append(provisioned, ds)
+			continue
+		}
+
+		if cmd.DeletedDatasourcesCount > 0 {
+			w.log.Info("Deleted data source, missing in Kubernetes", "name", ds.Name, "orgId", ds.OrgID)
+		}
+	}
+	w.provisionedDatasources = provisioned
+
+	return nil
+}
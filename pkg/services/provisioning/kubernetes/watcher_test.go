@@ -0,0 +1,184 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
+	"github.com/grafana/grafana/pkg/services/provisioning/datasources"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestDashboardsFromObjects(t *testing.T) {
+	objects := []interface{}{
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "monitoring",
+				Name:        "dashboards",
+				Annotations: map[string]string{folderAnnotation: "Team"},
+			},
+			Data: map[string]string{
+				"one.json":   `{"title": "One"}`,
+				"README.txt": "not a dashboard",
+			},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "monitoring", Name: "secret-dashboards"},
+			Data:       map[string][]byte{"two.json": []byte(`{"title": "Two"}`)},
+		},
+		&unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"namespace": "monitoring", "name": "three"},
+			"spec":     map[string]interface{}{"json": `{"title": "Three"}`, "customFolderName": "Custom"},
+		}},
+		&unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"namespace": "monitoring", "name": "from-url"},
+			"spec":     map[string]interface{}{"url": "https://grafana.com/api/dashboards/1/revisions/1/download"},
+		}},
+	}
+
+	require.Equal(t, []dashboards.ExternalDashboard{
+		{ExternalID: "configmap/monitoring/dashboards/one.json", Folder: "Team", JSON: []byte(`{"title": "One"}`)},
+		{ExternalID: "grafanadashboard/monitoring/three", Folder: "Custom", JSON: []byte(`{"title": "Three"}`)},
+		{ExternalID: "secret/monitoring/secret-dashboards/two.json", JSON: []byte(`{"title": "Two"}`)},
+	}, dashboardsFromObjects(objects, log.New("test-logger")))
+}
+
+func TestDatasourceFilesFromObjects(t *testing.T) {
+	objects := []interface{}{
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "monitoring", Name: "datasources"},
+			Data:       map[string]string{"datasources.yaml": "datasources: []"},
+		},
+		&unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"namespace": "monitoring", "name": "prometheus"},
+			"spec": map[string]interface{}{"datasources": []interface{}{
+				map[string]interface{}{"name": "Prometheus", "type": "prometheus"},
+			}},
+		}},
+	}
+
+	require.Equal(t, map[string][]byte{
+		"configmap/monitoring/datasources/datasources.yaml": []byte("datasources: []"),
+		"grafanadatasource/monitoring/prometheus":          []byte(`{"apiVersion":1,"datasources":[{"name":"Prometheus","type":"prometheus"}]}`),
+	}, datasourceFilesFromObjects(objects, log.New("test-logger")))
+}
+
+func TestWatcher(t *testing.T) {
+	bus.ClearBusHandlers()
+	deleted := make(chan models.DeleteDataSourceCommand, 10)
+	bus.AddHandler("test", func(cmd *models.DeleteDataSourceCommand) error {
+		cmd.DeletedDatasourcesCount = 1
+		deleted <- *cmd
+		return nil
+	})
+
+	cfg := setting.KubernetesProvisioningSettings{
+		Enabled:                  true,
+		Resources:                []string{"configmaps", "secrets"},
+		DashboardsLabelSelector:  "grafana_dashboard=1",
+		DatasourcesLabelSelector: "grafana_datasource=1",
+		CRDGroupVersion:          "integreatly.org/v1alpha1",
+		ResyncInterval:           time.Hour,
+	}
+
+	client := fake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "monitoring",
+			Name:      "dashboards",
+			Labels:    map[string]string{"grafana_dashboard": "1"},
+		},
+		Data: map[string]string{"one.json": `{"title": "One"}`},
+	}, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "monitoring",
+			Name:      "datasources",
+			Labels:    map[string]string{"grafana_datasource": "1"},
+		},
+		Data: map[string]string{"datasources.yaml": "datasources: []"},
+	})
+
+	gv := schema.GroupVersion{Group: "integreatly.org", Version: "v1alpha1"}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		gv.WithResource(dashboardResource):  "GrafanaDashboardList",
+		gv.WithResource(datasourceResource): "GrafanaDataSourceList",
+	})
+
+	watcher, err := newWatcher(cfg, "monitoring", client, dynamicClient, nil)
+	require.NoError(t, err)
+
+	dashboardSyncs := make(chan []dashboards.ExternalDashboard, 10)
+	watcher.provisionDashboards = func(dashboards []dashboards.ExternalDashboard) error {
+		dashboardSyncs <- dashboards
+		return nil
+	}
+	datasourceSyncs := make(chan map[string][]byte, 10)
+	provisioned := []datasources.ProvisionedDatasource{{OrgID: 1, Name: "Prometheus"}}
+	watcher.provisionDatasources = func(files map[string][]byte) ([]datasources.ProvisionedDatasource, error) {
+		datasourceSyncs <- files
+		if len(files) == 0 {
+			return nil, nil
+		}
+		return provisioned, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- watcher.Run(ctx)
+	}()
+
+	t.Run("Should provision the objects on start", func(t *testing.T) {
+		dashboards := <-dashboardSyncs
+		require.Len(t, dashboards, 1)
+		require.Equal(t, "configmap/monitoring/dashboards/one.json", dashboards[0].ExternalID)
+
+		files := <-datasourceSyncs
+		require.Contains(t, files, "configmap/monitoring/datasources/datasources.yaml")
+	})
+
+	t.Run("Should provision the objects again when they change", func(t *testing.T) {
+		err := client.CoreV1().ConfigMaps("monitoring").Delete(ctx, "datasources", metav1.DeleteOptions{})
+		require.NoError(t, err)
+		_, err = dynamicClient.Resource(gv.WithResource(dashboardResource)).Namespace("monitoring").Create(ctx,
+			&unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": gv.String(),
+				"kind":       "GrafanaDashboard",
+				"metadata":   map[string]interface{}{"namespace": "monitoring", "name": "two"},
+				"spec":       map[string]interface{}{"json": `{"title": "Two"}`},
+			}}, metav1.CreateOptions{})
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			select {
+			case dashboards := <-dashboardSyncs:
+				return len(dashboards) == 2 && len(<-datasourceSyncs) == 0
+			default:
+				return false
+			}
+		}, 10*time.Second, 10*time.Millisecond)
+
+		require.Equal(t, models.DeleteDataSourceCommand{OrgID: 1, Name: "Prometheus", DeletedDatasourcesCount: 1}, <-deleted)
+	})
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestNewWatcherRejectsUnsupportedResources(t *testing.T) {
+	_, err := newWatcher(setting.KubernetesProvisioningSettings{Resources: []string{"pods"}}, "",
+		fake.NewSimpleClientset(), nil, nil)
+	require.Error(t, err)
+}
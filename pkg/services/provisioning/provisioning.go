@@ -2,14 +2,19 @@ package provisioning
 
 import (
 	"context"
+	"errors"
 	"path/filepath"
 	"sync"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/leaderelection"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
 	plugifaces "github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/registry"
 	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
 	"github.com/grafana/grafana/pkg/services/provisioning/datasources"
+	"github.com/grafana/grafana/pkg/services/provisioning/kubernetes"
 	"github.com/grafana/grafana/pkg/services/provisioning/notifiers"
 	"github.com/grafana/grafana/pkg/services/provisioning/plugins"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
@@ -26,6 +31,9 @@ type ProvisioningService interface {
 	ProvisionDashboards() error
 	GetDashboardProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
+	SyncDashboardUIUpdate(provisioning *models.DashboardProvisioning, dash *models.Dashboard) error
+	GetProvisionedDashboard(provisioning *models.DashboardProvisioning) (*simplejson.Json, error)
+	GetDashboardGitSyncStatus() []dashboards.GitSyncStatus
 }
 
 func init() {
@@ -64,9 +72,10 @@ func newProvisioningServiceImpl(
 }
 
 type provisioningServiceImpl struct {
-	Cfg                     *setting.Cfg       `inject:""`
-	SQLStore                *sqlstore.SQLStore `inject:""`
-	PluginManager           plugifaces.Manager `inject:""`
+	Cfg                     *setting.Cfg            `inject:""`
+	SQLStore                *sqlstore.SQLStore      `inject:""`
+	PluginManager           plugifaces.Manager      `inject:""`
+	LeaderElection          *leaderelection.Service `inject:""`
 	log                     log.Logger
 	pollingCtxCancel        context.CancelFunc
 	newDashboardProvisioner dashboards.DashboardProvisionerFactory
@@ -107,6 +116,20 @@ func (ps *provisioningServiceImpl) Run(ctx context.Context) error {
 		return err
 	}
 
+	if ps.Cfg.KubernetesProvisioning.Enabled {
+		watcher, err := kubernetes.NewWatcher(ps.Cfg.KubernetesProvisioning, ps.SQLStore)
+		if err != nil {
+			ps.log.Error("Failed to watch Kubernetes objects", "error", err)
+			return err
+		}
+
+		go func() {
+			if err := watcher.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				ps.log.Error("Failed to watch Kubernetes objects", "error", err)
+			}
+		}()
+	}
+
 	for {
 		// Wait for unlock. This is tied to new dashboardProvisioner to be instantiated before we start polling.
 		ps.mutex.Lock()
@@ -114,7 +137,7 @@ func (ps *provisioningServiceImpl) Run(ctx context.Context) error {
 		// non-deterministically take one of the route possibly going into one polling loop before exiting.
 		pollingContext, cancelFun := context.WithCancel(context.Background())
 		ps.pollingCtxCancel = cancelFun
-		ps.dashboardProvisioner.PollChanges(pollingContext)
+		ps.dashboardProvisioner.PollChanges(pollingContext, ps.LeaderElection.IsLeader)
 		ps.mutex.Unlock()
 
 		select {
@@ -178,6 +201,18 @@ func (ps *provisioningServiceImpl) GetAllowUIUpdatesFromConfig(name string) bool
 	return ps.dashboardProvisioner.GetAllowUIUpdatesFromConfig(name)
 }
 
+func (ps *provisioningServiceImpl) SyncDashboardUIUpdate(provisioning *models.DashboardProvisioning, dash *models.Dashboard) error {
+	return ps.dashboardProvisioner.SyncUIUpdate(provisioning, dash)
+}
+
+func (ps *provisioningServiceImpl) GetProvisionedDashboard(provisioning *models.DashboardProvisioning) (*simplejson.Json, error) {
+	return ps.dashboardProvisioner.GetProvisionedDashboard(provisioning)
+}
+
+func (ps *provisioningServiceImpl) GetDashboardGitSyncStatus() []dashboards.GitSyncStatus {
+	return ps.dashboardProvisioner.GetGitSyncStatus()
+}
+
 func (ps *provisioningServiceImpl) cancelPolling() {
 	if ps.pollingCtxCancel != nil {
 		ps.log.Debug("Stop polling for dashboard changes")
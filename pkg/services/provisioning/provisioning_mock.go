@@ -1,6 +1,12 @@
 package provisioning
 
-import "context"
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
+)
 
 type Calls struct {
 	RunInitProvisioners                 []interface{}
@@ -10,6 +16,9 @@ type Calls struct {
 	ProvisionDashboards                 []interface{}
 	GetDashboardProvisionerResolvedPath []interface{}
 	GetAllowUIUpdatesFromConfig         []interface{}
+	SyncDashboardUIUpdate               []interface{}
+	GetProvisionedDashboard             []interface{}
+	GetDashboardGitSyncStatus           []interface{}
 	Run                                 []interface{}
 }
 
@@ -22,6 +31,9 @@ type ProvisioningServiceMock struct {
 	ProvisionDashboardsFunc                 func() error
 	GetDashboardProvisionerResolvedPathFunc func(name string) string
 	GetAllowUIUpdatesFromConfigFunc         func(name string) bool
+	SyncDashboardUIUpdateFunc               func(provisioning *models.DashboardProvisioning, dash *models.Dashboard) error
+	GetProvisionedDashboardFunc             func(provisioning *models.DashboardProvisioning) (*simplejson.Json, error)
+	GetDashboardGitSyncStatusFunc           func() []dashboards.GitSyncStatus
 	RunFunc                                 func(ctx context.Context) error
 }
 
@@ -87,6 +99,30 @@ func (mock *ProvisioningServiceMock) GetAllowUIUpdatesFromConfig(name string) bo
 	return false
 }
 
+func (mock *ProvisioningServiceMock) SyncDashboardUIUpdate(provisioning *models.DashboardProvisioning, dash *models.Dashboard) error {
+	mock.Calls.SyncDashboardUIUpdate = append(mock.Calls.SyncDashboardUIUpdate, provisioning)
+	if mock.SyncDashboardUIUpdateFunc != nil {
+		return mock.SyncDashboardUIUpdateFunc(provisioning, dash)
+	}
+	return nil
+}
+
+func (mock *ProvisioningServiceMock) GetProvisionedDashboard(provisioning *models.DashboardProvisioning) (*simplejson.Json, error) {
+	mock.Calls.GetProvisionedDashboard = append(mock.Calls.GetProvisionedDashboard, provisioning)
+	if mock.GetProvisionedDashboardFunc != nil {
+		return mock.GetProvisionedDashboardFunc(provisioning)
+	}
+	return nil, nil
+}
+
+func (mock *ProvisioningServiceMock) GetDashboardGitSyncStatus() []dashboards.GitSyncStatus {
+	mock.Calls.GetDashboardGitSyncStatus = append(mock.Calls.GetDashboardGitSyncStatus, nil)
+	if mock.GetDashboardGitSyncStatusFunc != nil {
+		return mock.GetDashboardGitSyncStatusFunc()
+	}
+	return nil
+}
+
 func (mock *ProvisioningServiceMock) Run(ctx context.Context) error {
 	mock.Calls.Run = append(mock.Calls.Run, nil)
 	if mock.RunFunc != nil {
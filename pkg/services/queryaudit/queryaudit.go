@@ -0,0 +1,129 @@
+// Package queryaudit propagates the dashboard, panel, user and org of the data source queries to the data sources,
+// as X-Grafana headers of the HTTP requests and in the application name or comments of the SQL queries, so that DBAs
+// and operators can trace their load back to the dashboards. It's enabled by the queryAuditHeaders setting of the
+// data sources.
+package queryaudit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// The headers of the audited requests to the data sources.
+const (
+	HeaderDashboardUID = "X-Grafana-Dashboard-Uid"
+	HeaderPanelID      = "X-Grafana-Panel-Id"
+	HeaderUser         = "X-Grafana-User"
+	HeaderOrgID        = "X-Grafana-Org-Id"
+)
+
+// Info is the origin of the queries of a request to a data source.
+type Info struct {
+	DashboardUID string
+	PanelID      int64
+	UserLogin    string
+	OrgID        int64
+}
+
+// Enabled returns whether the queries of the data source are audited.
+func Enabled(ds *models.DataSource) bool {
+	return ds != nil && ds.JsonData != nil && ds.JsonData.Get("queryAuditHeaders").MustBool(false)
+}
+
+// NewInfo returns the Info of the queries of the user from the panel of the dashboard, which are 0 for the queries
+// of Explore.
+func NewInfo(user *models.SignedInUser, dashboardID, panelID int64) Info {
+	info := Info{PanelID: panelID, OrgID: user.OrgId}
+	if !user.IsAnonymous {
+		info.UserLogin = user.Login
+	}
+	if dashboardID != 0 {
+		query := models.GetDashboardRefByIdQuery{Id: dashboardID}
+		if err := bus.Dispatch(&query); err == nil {
+			info.DashboardUID = query.Result.Uid
+		}
+	}
+	return info
+}
+
+// Headers returns the headers of the info, without the missing values.
+func (i Info) Headers() map[string]string {
+	headers := map[string]string{}
+	if i.DashboardUID != "" {
+		headers[HeaderDashboardUID] = i.DashboardUID
+	}
+	if i.PanelID != 0 {
+		headers[HeaderPanelID] = strconv.FormatInt(i.PanelID, 10)
+	}
+	if i.UserLogin != "" {
+		headers[HeaderUser] = i.UserLogin
+	}
+	if i.OrgID != 0 {
+		headers[HeaderOrgID] = strconv.FormatInt(i.OrgID, 10)
+	}
+	return headers
+}
+
+// FromHeaders returns the Info of the headers of a request, and false when the request isn't audited.
+func FromHeaders(headers map[string]string) (Info, bool) {
+	orgID, err := strconv.ParseInt(headers[HeaderOrgID], 10, 64)
+	if err != nil {
+		return Info{}, false
+	}
+	panelID, _ := strconv.ParseInt(headers[HeaderPanelID], 10, 64)
+	return Info{
+		DashboardUID: headers[HeaderDashboardUID],
+		PanelID:      panelID,
+		UserLogin:    headers[HeaderUser],
+		OrgID:        orgID,
+	}, true
+}
+
+// Name returns the name of the info for the SQL application names and comments, e.g.
+// grafana dashboard=nErXDvCkzz panel=2 user=admin org=1, without the missing values. The name only contains printable
+// ASCII characters.
+func (i Info) Name() string {
+	parts := []string{"grafana"}
+	if i.DashboardUID != "" {
+		parts = append(parts, "dashboard="+i.DashboardUID)
+	}
+	if i.PanelID != 0 {
+		parts = append(parts, fmt.Sprintf("panel=%d", i.PanelID))
+	}
+	if i.UserLogin != "" {
+		parts = append(parts, "user="+i.UserLogin)
+	}
+	if i.OrgID != 0 {
+		parts = append(parts, fmt.Sprintf("org=%d", i.OrgID))
+	}
+	return strings.Map(func(r rune) rune {
+		if r < ' ' || r > '~' {
+			return '?'
+		}
+		return r
+	}, strings.Join(parts, " "))
+}
+
+// Comment returns a SQL comment of the name of the info, whose * are replaced so that the values can't end the comment
+// or nest another.
+func (i Info) Comment() string {
+	return "/* " + strings.ReplaceAll(i.Name(), "*", "?") + " */"
+}
+
+type contextKey struct{}
+
+// WithInfo returns a context of the audited queries of the info, whose requests to the data sources have its headers.
+func WithInfo(ctx context.Context, info Info) context.Context {
+	return context.WithValue(ctx, contextKey{}, info)
+}
+
+// FromContext returns the Info of the audited queries of the context, and false when they aren't audited.
+func FromContext(ctx context.Context) (Info, bool) {
+	info, ok := ctx.Value(contextKey{}).(Info)
+	return info, ok
+}
@@ -0,0 +1,80 @@
+package queryaudit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnabled(t *testing.T) {
+	assert.False(t, Enabled(nil))
+	assert.False(t, Enabled(&models.DataSource{}))
+	assert.False(t, Enabled(&models.DataSource{JsonData: simplejson.New()}))
+	assert.True(t, Enabled(&models.DataSource{JsonData: simplejson.NewFromAny(map[string]interface{}{"queryAuditHeaders": true})}))
+}
+
+func TestInfo(t *testing.T) {
+	bus.AddHandler("test", func(query *models.GetDashboardRefByIdQuery) error {
+		if query.Id != 3 {
+			return models.ErrDashboardNotFound
+		}
+		query.Result = &models.DashboardRef{Uid: "nErXDvCkzz", Slug: "home"}
+		return nil
+	})
+	t.Cleanup(bus.ClearBusHandlers)
+
+	t.Run("Should return the info of the queries of a panel", func(t *testing.T) {
+		info := NewInfo(&models.SignedInUser{Login: "admin", OrgId: 1}, 3, 2)
+		require.Equal(t, Info{DashboardUID: "nErXDvCkzz", PanelID: 2, UserLogin: "admin", OrgID: 1}, info)
+		headers := info.Headers()
+		require.Equal(t, map[string]string{
+			HeaderDashboardUID: "nErXDvCkzz",
+			HeaderPanelID:      "2",
+			HeaderUser:         "admin",
+			HeaderOrgID:        "1",
+		}, headers)
+
+		fromHeaders, ok := FromHeaders(headers)
+		require.True(t, ok)
+		require.Equal(t, info, fromHeaders)
+		require.Equal(t, "grafana dashboard=nErXDvCkzz panel=2 user=admin org=1", info.Name())
+	})
+
+	t.Run("Should return the info of the queries of Explore without the missing values", func(t *testing.T) {
+		info := NewInfo(&models.SignedInUser{Login: "anonymous", OrgId: 1, IsAnonymous: true}, 0, 0)
+		require.Equal(t, Info{OrgID: 1}, info)
+		require.Equal(t, map[string]string{HeaderOrgID: "1"}, info.Headers())
+		require.Equal(t, "grafana org=1", info.Name())
+
+		info = NewInfo(&models.SignedInUser{Login: "admin", OrgId: 1}, 4, 1)
+		require.Equal(t, Info{PanelID: 1, UserLogin: "admin", OrgID: 1}, info)
+	})
+
+	t.Run("Should not return the info of the requests without the org header", func(t *testing.T) {
+		_, ok := FromHeaders(map[string]string{HeaderDashboardUID: "nErXDvCkzz"})
+		require.False(t, ok)
+		_, ok = FromHeaders(nil)
+		require.False(t, ok)
+	})
+
+	t.Run("Should sanitize the names and comments", func(t *testing.T) {
+		info := Info{UserLogin: "*/ DROP TABLE users; /*\nrenée", OrgID: 1}
+		require.Equal(t, "grafana user=*/ DROP TABLE users; /*?ren?e org=1", info.Name())
+		require.Equal(t, "/* grafana user=?/ DROP TABLE users; /??ren?e org=1 */", info.Comment())
+	})
+}
+
+func TestContext(t *testing.T) {
+	_, ok := FromContext(context.Background())
+	require.False(t, ok)
+
+	info := Info{DashboardUID: "nErXDvCkzz", OrgID: 1}
+	fromContext, ok := FromContext(WithInfo(context.Background(), info))
+	require.True(t, ok)
+	require.Equal(t, info, fromContext)
+}
@@ -0,0 +1,47 @@
+package querycost
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// defaultTimeRange is the time range of the costs returned when the request
+// has none.
+const defaultTimeRange = 30 * 24 * time.Hour
+
+func (s *QueryCostService) registerAPIEndpoints() {
+	s.RouteRegister.Get("/api/query-costs", middleware.ReqOrgAdmin, routing.Wrap(s.searchHandler))
+}
+
+// searchHandler handles GET /api/query-costs.
+func (s *QueryCostService) searchHandler(c *models.ReqContext) response.Response {
+	to := time.Now()
+	if ms := c.QueryInt64("to"); ms > 0 {
+		to = time.Unix(0, ms*int64(time.Millisecond))
+	}
+	from := to.Add(-defaultTimeRange)
+	if ms := c.QueryInt64("from"); ms > 0 {
+		from = time.Unix(0, ms*int64(time.Millisecond))
+	}
+	if !from.Before(to) {
+		return response.Error(400, "from must be before to", nil)
+	}
+
+	// the costs are aggregated by hour, so the hour of from is included
+	result, err := s.searchQueryCosts(c.Req.Context(), searchQueryCostsQuery{
+		orgID: c.OrgId,
+		from:  from.Truncate(time.Hour).Unix(),
+		to:    to.Unix(),
+		limit: c.QueryInt("limit"),
+	})
+	if err != nil {
+		return response.Error(500, "Failed to get query costs", err)
+	}
+
+	return response.JSON(200, util.DynMap{"result": result})
+}
@@ -0,0 +1,83 @@
+package querycost
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+const (
+	defaultLimit = 100
+	maxLimit     = 1000
+)
+
+// addQueryCosts adds the costs to the costs of their hours in the database.
+func (s *QueryCostService) addQueryCosts(ctx context.Context, costs map[costKey]*QueryCost) error {
+	return s.SQLStore.WithTransactionalDbSession(ctx, func(session *sqlstore.DBSession) error {
+		for _, cost := range costs {
+			result, err := session.Exec("UPDATE query_cost SET queries = queries + ?, errors = errors + ?, "+
+				"scanned_bytes = scanned_bytes + ?, duration_ms = duration_ms + ? "+
+				"WHERE org_id = ? AND datasource_id = ? AND dashboard_id = ? AND period = ?",
+				cost.Queries, cost.Errors, cost.ScannedBytes, cost.DurationMs,
+				cost.OrgID, cost.DatasourceID, cost.DashboardID, cost.Period)
+			if err != nil {
+				return err
+			}
+			if affected, err := result.RowsAffected(); err != nil {
+				return err
+			} else if affected > 0 {
+				continue
+			}
+
+			if _, err := session.Insert(cost); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// searchQueryCosts returns the costs of the queries of an org in a time range
+// by data source and dashboard, the most expensive first.
+func (s *QueryCostService) searchQueryCosts(ctx context.Context, query searchQueryCostsQuery) ([]QueryCostDTO, error) {
+	limit := query.limit
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+
+	result := make([]QueryCostDTO, 0)
+	err := s.SQLStore.WithDbSession(ctx, func(session *sqlstore.DBSession) error {
+		return session.SQL(`SELECT
+			query_cost.datasource_id,
+			COALESCE(data_source.uid, '') AS datasource_uid,
+			COALESCE(data_source.name, '') AS datasource_name,
+			COALESCE(data_source.type, '') AS datasource_type,
+			query_cost.dashboard_id,
+			COALESCE(dashboard.uid, '') AS dashboard_uid,
+			COALESCE(dashboard.title, '') AS dashboard_title,
+			SUM(query_cost.queries) AS queries,
+			SUM(query_cost.errors) AS errors,
+			SUM(query_cost.scanned_bytes) AS scanned_bytes,
+			SUM(query_cost.duration_ms) AS duration_ms
+		FROM query_cost
+		LEFT JOIN data_source ON data_source.id = query_cost.datasource_id
+		LEFT JOIN dashboard ON dashboard.id = query_cost.dashboard_id
+		WHERE query_cost.org_id = ? AND query_cost.period >= ? AND query_cost.period < ?
+		GROUP BY query_cost.datasource_id, data_source.uid, data_source.name, data_source.type,
+			query_cost.dashboard_id, dashboard.uid, dashboard.title
+		ORDER BY duration_ms DESC, queries DESC
+		LIMIT ?`, query.orgID, query.from, query.to, limit).Find(&result)
+	})
+	return result, err
+}
+
+// deleteQueryCosts deletes the costs of the hours before a unix time.
+func (s *QueryCostService) deleteQueryCosts(ctx context.Context, before int64) error {
+	return s.SQLStore.WithDbSession(ctx, func(session *sqlstore.DBSession) error {
+		_, err := session.Exec("DELETE FROM query_cost WHERE period < ?", before)
+		return err
+	})
+}
@@ -0,0 +1,101 @@
+package querycost
+
+import (
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+// QueryCost is the model for the costs of the queries of a data source from a
+// dashboard in an hour. DashboardID is zero for the queries which aren't from
+// a dashboard, e.g. from Explore.
+type QueryCost struct {
+	ID           int64 `xorm:"pk autoincr 'id'"`
+	OrgID        int64 `xorm:"org_id"`
+	DatasourceID int64 `xorm:"datasource_id"`
+	DashboardID  int64 `xorm:"dashboard_id"`
+	// Period is the unix time in seconds of the start of the hour
+	Period       int64
+	Queries      int64
+	Errors       int64
+	ScannedBytes int64
+	DurationMs   int64
+}
+
+// QueryCostDTO is the DTO of the costs of the queries of a data source from a
+// dashboard in a time range.
+type QueryCostDTO struct {
+	DatasourceID   int64  `json:"datasourceId" xorm:"datasource_id"`
+	DatasourceUID  string `json:"datasourceUid" xorm:"datasource_uid"`
+	DatasourceName string `json:"datasourceName"`
+	DatasourceType string `json:"datasourceType"`
+	DashboardID    int64  `json:"dashboardId" xorm:"dashboard_id"`
+	DashboardUID   string `json:"dashboardUid" xorm:"dashboard_uid"`
+	DashboardTitle string `json:"dashboardTitle"`
+	Queries        int64  `json:"queries"`
+	Errors         int64  `json:"errors"`
+	ScannedBytes   int64  `json:"scannedBytes"`
+	DurationMs     int64  `json:"durationMs"`
+}
+
+// Cost is the cost of the queries of a request to a data source.
+type Cost struct {
+	Queries int64
+	Errors  int64
+	// ScannedBytes are the bytes the data source reports it scanned, zero
+	// when it doesn't report them
+	ScannedBytes int64
+	Duration     time.Duration
+}
+
+// scannedBytesStats are the display names of the query stats of the frames
+// with the bytes scanned by the query, lower case.
+var scannedBytesStats = []string{"bytes scanned", "bytes processed"}
+
+// ResponseCost returns the cost of the queries of a response which took the
+// duration, with the bytes scanned reported in the stats of its frames.
+func ResponseCost(qdr *backend.QueryDataResponse, duration time.Duration) Cost {
+	cost := Cost{Queries: int64(len(qdr.Responses)), Duration: duration}
+	for _, res := range qdr.Responses {
+		if res.Error != nil {
+			cost.Errors++
+		}
+		for _, frame := range res.Frames {
+			if frame.Meta == nil {
+				continue
+			}
+			for _, stat := range frame.Meta.Stats {
+				if isScannedBytesStat(stat.DisplayName) {
+					cost.ScannedBytes += int64(stat.Value)
+				}
+			}
+		}
+	}
+	return cost
+}
+
+func isScannedBytesStat(displayName string) bool {
+	displayName = strings.ToLower(displayName)
+	for _, name := range scannedBytesStats {
+		if displayName == name {
+			return true
+		}
+	}
+	return false
+}
+
+type costKey struct {
+	orgID        int64
+	datasourceID int64
+	dashboardID  int64
+	period       int64
+}
+
+type searchQueryCostsQuery struct {
+	orgID int64
+	// from and to are the time range of the costs in unix seconds
+	from  int64
+	to    int64
+	limit int
+}
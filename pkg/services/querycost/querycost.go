@@ -0,0 +1,184 @@
+// Package querycost records the costs of the data source queries, the query
+// counts, scanned bytes and execution time by org, data source and dashboard,
+// for platform teams to charge back the expensive dashboards. The costs are
+// aggregated by hour in the database and exposed as Prometheus metrics.
+package querycost
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// flushInterval is how often the recorded costs are written to the
+	// database.
+	flushInterval = time.Minute
+	// cleanUpInterval is how often the costs older than the retention are
+	// deleted.
+	cleanUpInterval = time.Hour
+)
+
+var costLabels = []string{"org_id", "datasource_id", "datasource", "datasource_type"}
+
+var (
+	queriesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.ExporterName,
+		Subsystem: "query_cost",
+		Name:      "queries_total",
+		Help:      "Number of data source queries",
+	}, costLabels)
+	errorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.ExporterName,
+		Subsystem: "query_cost",
+		Name:      "errors_total",
+		Help:      "Number of failed data source queries",
+	}, costLabels)
+	scannedBytesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.ExporterName,
+		Subsystem: "query_cost",
+		Name:      "scanned_bytes_total",
+		Help:      "Bytes scanned by the data source queries, as reported by the data sources",
+	}, costLabels)
+	durationSecondsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.ExporterName,
+		Subsystem: "query_cost",
+		Name:      "duration_seconds_total",
+		Help:      "Execution time of the data source queries",
+	}, costLabels)
+)
+
+func init() {
+	registry.RegisterService(&QueryCostService{})
+	prometheus.MustRegister(queriesTotal, errorsTotal, scannedBytesTotal, durationSecondsTotal)
+}
+
+// QueryCostService records the costs of the data source queries.
+type QueryCostService struct {
+	Cfg           *setting.Cfg          `inject:""`
+	SQLStore      *sqlstore.SQLStore    `inject:""`
+	RouteRegister routing.RouteRegister `inject:""`
+	log           log.Logger
+
+	mu sync.Mutex
+	// pending are the costs recorded since the last flush
+	pending map[costKey]*QueryCost
+}
+
+// Init initializes the QueryCost service
+func (s *QueryCostService) Init() error {
+	s.log = log.New("query-cost")
+	s.pending = map[costKey]*QueryCost{}
+
+	s.registerAPIEndpoints()
+
+	return nil
+}
+
+func (s *QueryCostService) IsDisabled() bool {
+	return !s.Cfg.QueryCost.Enabled
+}
+
+func (s *QueryCostService) Run(ctx context.Context) error {
+	flushTicker := time.NewTicker(flushInterval)
+	defer flushTicker.Stop()
+	cleanUpTicker := time.NewTicker(cleanUpInterval)
+	defer cleanUpTicker.Stop()
+
+	for {
+		select {
+		case <-flushTicker.C:
+			s.flush(ctx)
+		case <-cleanUpTicker.C:
+			before := time.Now().AddDate(0, 0, -s.Cfg.QueryCost.RetentionDays)
+			if err := s.deleteQueryCosts(ctx, before.Unix()); err != nil {
+				s.log.Error("Failed to delete old query costs", "error", err)
+			}
+		case <-ctx.Done():
+			// the costs recorded since the last flush are written before exiting
+			s.flush(context.Background())
+			return ctx.Err()
+		}
+	}
+}
+
+// Record records the cost of the queries of a request to a data source from a
+// dashboard, zero if they aren't from a dashboard.
+func (s *QueryCostService) Record(ds *models.DataSource, dashboardID int64, cost Cost) {
+	if s.IsDisabled() {
+		return
+	}
+
+	labels := prometheus.Labels{
+		"org_id":          strconv.FormatInt(ds.OrgId, 10),
+		"datasource_id":   strconv.FormatInt(ds.Id, 10),
+		"datasource":      ds.Name,
+		"datasource_type": ds.Type,
+	}
+	queriesTotal.With(labels).Add(float64(cost.Queries))
+	errorsTotal.With(labels).Add(float64(cost.Errors))
+	scannedBytesTotal.With(labels).Add(float64(cost.ScannedBytes))
+	durationSecondsTotal.With(labels).Add(cost.Duration.Seconds())
+
+	key := costKey{
+		orgID:        ds.OrgId,
+		datasourceID: ds.Id,
+		dashboardID:  dashboardID,
+		period:       time.Now().Truncate(time.Hour).Unix(),
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.add(key, cost)
+}
+
+// add adds a cost to the pending costs, the caller must hold the lock.
+func (s *QueryCostService) add(key costKey, cost Cost) {
+	pending, ok := s.pending[key]
+	if !ok {
+		pending = &QueryCost{OrgID: key.orgID, DatasourceID: key.datasourceID, DashboardID: key.dashboardID, Period: key.period}
+		s.pending[key] = pending
+	}
+	pending.Queries += cost.Queries
+	pending.Errors += cost.Errors
+	pending.ScannedBytes += cost.ScannedBytes
+	pending.DurationMs += cost.Duration.Milliseconds()
+}
+
+// flush writes the pending costs to the database. The costs which failed to
+// be written are kept for the next flush.
+func (s *QueryCostService) flush(ctx context.Context) {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = map[costKey]*QueryCost{}
+	s.mu.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+
+	if err := s.addQueryCosts(ctx, pending); err != nil {
+		s.log.Error("Failed to write the query costs", "error", err)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for key, cost := range pending {
+			s.add(key, Cost{
+				Queries:      cost.Queries,
+				Errors:       cost.Errors,
+				ScannedBytes: cost.ScannedBytes,
+				Duration:     time.Duration(cost.DurationMs) * time.Millisecond,
+			})
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package querycost
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/macaron.v1"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestResponseCost(t *testing.T) {
+	frame := data.NewFrame("logs").SetMeta(&data.FrameMeta{Stats: []data.QueryStat{
+		{FieldConfig: data.FieldConfig{DisplayName: "Bytes scanned"}, Value: 2048},
+		{FieldConfig: data.FieldConfig{DisplayName: "Records scanned"}, Value: 10},
+	}})
+	qdr := backend.NewQueryDataResponse()
+	qdr.Responses["A"] = backend.DataResponse{Frames: data.Frames{frame}}
+	qdr.Responses["B"] = backend.DataResponse{Error: errors.New("bad query")}
+
+	require.Equal(t, Cost{Queries: 2, Errors: 1, ScannedBytes: 2048, Duration: time.Second}, ResponseCost(qdr, time.Second))
+}
+
+func TestQueryCosts(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	cfg := setting.NewCfg()
+	cfg.QueryCost = setting.QueryCostSettings{Enabled: true, RetentionDays: 90}
+	s := &QueryCostService{Cfg: cfg, SQLStore: sqlStore, log: log.New("query-cost"), pending: map[costKey]*QueryCost{}}
+
+	cmd := &models.AddDataSourceCommand{OrgId: 1, Name: "Warehouse", Type: "test", Access: models.DS_ACCESS_PROXY}
+	require.NoError(t, sqlstore.AddDataSource(cmd))
+	ds := cmd.Result
+	dashboard, err := sqlStore.SaveDashboard(models.SaveDashboardCommand{
+		OrgId:     ds.OrgId,
+		Dashboard: simplejson.NewFromAny(map[string]interface{}{"title": "Expensive"}),
+	})
+	require.NoError(t, err)
+
+	search := func(t *testing.T, from, to string) []QueryCostDTO {
+		t.Helper()
+
+		req, err := http.NewRequest(http.MethodGet, "/api/query-costs?from="+from+"&to="+to, nil)
+		require.NoError(t, err)
+		c := &models.ReqContext{
+			Context:      &macaron.Context{Req: macaron.Request{Request: req}},
+			SignedInUser: &models.SignedInUser{OrgId: ds.OrgId, OrgRole: models.ROLE_ADMIN},
+		}
+		resp := s.searchHandler(c)
+		require.Equal(t, 200, resp.Status())
+
+		result := struct {
+			Result []QueryCostDTO `json:"result"`
+		}{}
+		require.NoError(t, json.Unmarshal(resp.Body(), &result))
+		return result.Result
+	}
+
+	before := time.Now().Add(-time.Hour)
+	s.Record(ds, dashboard.Id, Cost{Queries: 2, Errors: 1, ScannedBytes: 1024, Duration: 3 * time.Second})
+	s.Record(ds, dashboard.Id, Cost{Queries: 1, Duration: time.Second})
+	s.Record(ds, 0, Cost{Queries: 1, Duration: 100 * time.Millisecond})
+	s.flush(context.Background())
+	// the costs are added to the costs of the hour in the database
+	s.Record(ds, dashboard.Id, Cost{Queries: 1, ScannedBytes: 1024, Duration: time.Second})
+	s.flush(context.Background())
+
+	t.Run("costs are aggregated by data source and dashboard", func(t *testing.T) {
+		costs := search(t, "", "")
+		require.Equal(t, []QueryCostDTO{
+			{
+				DatasourceID: ds.Id, DatasourceUID: ds.Uid, DatasourceName: "Warehouse", DatasourceType: "test",
+				DashboardID: dashboard.Id, DashboardUID: dashboard.Uid, DashboardTitle: "Expensive",
+				Queries: 4, Errors: 1, ScannedBytes: 2048, DurationMs: 5000,
+			},
+			{
+				DatasourceID: ds.Id, DatasourceUID: ds.Uid, DatasourceName: "Warehouse", DatasourceType: "test",
+				Queries: 1, DurationMs: 100,
+			},
+		}, costs)
+	})
+
+	t.Run("costs are searched by time range", func(t *testing.T) {
+		to := before.Add(-time.Hour)
+		require.Empty(t, search(t, "1", formatMs(to)))
+		require.Len(t, search(t, formatMs(before), ""), 2)
+	})
+
+	t.Run("costs are exposed as metrics", func(t *testing.T) {
+		labels := []string{"1", strconv.FormatInt(ds.Id, 10), "Warehouse", "test"}
+		require.Equal(t, 5.0, testutil.ToFloat64(queriesTotal.WithLabelValues(labels...)))
+		require.Equal(t, 2048.0, testutil.ToFloat64(scannedBytesTotal.WithLabelValues(labels...)))
+	})
+
+	t.Run("old costs are deleted", func(t *testing.T) {
+		require.NoError(t, s.deleteQueryCosts(context.Background(), time.Now().Add(time.Hour).Unix()))
+		require.Empty(t, search(t, "", ""))
+	})
+
+	t.Run("costs aren't recorded when disabled", func(t *testing.T) {
+		disabled := &QueryCostService{Cfg: setting.NewCfg()}
+		disabled.Record(ds, dashboard.Id, Cost{Queries: 1})
+		require.Empty(t, disabled.pending)
+	})
+}
+
+func formatMs(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10)
+}
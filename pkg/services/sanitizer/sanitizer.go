@@ -0,0 +1,108 @@
+// Package sanitizer sanitizes the HTML saved through the API, as defense in
+// depth against stored XSS since the frontend sanitizes it as well.
+package sanitizer
+
+import (
+	"regexp"
+
+	"github.com/microcosm-cc/bluemonday"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var iframeSrcPattern = regexp.MustCompile(`^https?://`)
+
+func init() {
+	registry.RegisterService(&Service{})
+}
+
+// Service sanitizes the text panel content and the annotation text with the
+// policy of their organization.
+type Service struct {
+	Cfg *setting.Cfg `inject:""`
+
+	policy      *bluemonday.Policy
+	orgPolicies map[int64]*bluemonday.Policy
+}
+
+func (s *Service) Init() error {
+	s.policy = newPolicy(s.Cfg.HTMLSanitizer.Policy)
+	s.orgPolicies = make(map[int64]*bluemonday.Policy, len(s.Cfg.HTMLSanitizer.OrgPolicies))
+	for orgID, policy := range s.Cfg.HTMLSanitizer.OrgPolicies {
+		s.orgPolicies[orgID] = newPolicy(policy)
+	}
+	return nil
+}
+
+func newPolicy(settings setting.HTMLSanitizerPolicySettings) *bluemonday.Policy {
+	if settings.Policy == setting.HTMLSanitizerPolicyStrict {
+		return bluemonday.StrictPolicy()
+	}
+
+	policy := bluemonday.UGCPolicy()
+	if settings.AllowClassAttributes {
+		policy.AllowAttrs("class").Globally()
+	}
+	if settings.AllowIframes {
+		policy.AllowAttrs("src").Matching(iframeSrcPattern).OnElements("iframe")
+		policy.AllowAttrs("width", "height", "frameborder", "allowfullscreen").OnElements("iframe")
+	}
+	return policy
+}
+
+// IsEnabled returns whether the HTML is sanitized.
+func (s *Service) IsEnabled() bool {
+	return s.Cfg.HTMLSanitizer.Enabled
+}
+
+// SanitizeHTML returns the HTML sanitized with the policy of the
+// organization, or as is when the sanitizer is disabled.
+func (s *Service) SanitizeHTML(orgID int64, html string) string {
+	if !s.IsEnabled() || html == "" {
+		return html
+	}
+
+	policy, ok := s.orgPolicies[orgID]
+	if !ok {
+		policy = s.policy
+	}
+	return policy.Sanitize(html)
+}
+
+// SanitizeDashboard sanitizes the content of the text panels of the
+// dashboard in HTML mode, including the panels of the collapsed rows and of
+// the legacy rows. The markdown content is left to the frontend, since
+// sanitizing it would escape its syntax.
+func (s *Service) SanitizeDashboard(orgID int64, dashboard *simplejson.Json) {
+	if !s.IsEnabled() || dashboard == nil {
+		return
+	}
+
+	s.sanitizePanels(orgID, dashboard.Get("panels"))
+	for _, row := range dashboard.Get("rows").MustArray() {
+		s.sanitizePanels(orgID, simplejson.NewFromAny(row).Get("panels"))
+	}
+}
+
+func (s *Service) sanitizePanels(orgID int64, panels *simplejson.Json) {
+	for _, p := range panels.MustArray() {
+		panel := simplejson.NewFromAny(p)
+		s.sanitizePanels(orgID, panel.Get("panels"))
+
+		if panel.Get("type").MustString() != "text" {
+			continue
+		}
+
+		// the content is in the options since 7.1, and at the root before
+		for _, container := range []*simplejson.Json{panel.Get("options"), panel} {
+			if container.Get("mode").MustString() != "html" {
+				continue
+			}
+			if content, err := container.Get("content").String(); err == nil {
+				container.Set("content", s.SanitizeHTML(orgID, content))
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package sanitizer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func newTestService(t *testing.T, settings setting.HTMLSanitizerSettings) *Service {
+	t.Helper()
+	cfg := setting.NewCfg()
+	cfg.HTMLSanitizer = settings
+	s := &Service{Cfg: cfg}
+	require.NoError(t, s.Init())
+	return s
+}
+
+func TestSanitizeHTML(t *testing.T) {
+	s := newTestService(t, setting.HTMLSanitizerSettings{
+		Enabled: true,
+		Policy:  setting.HTMLSanitizerPolicySettings{Policy: setting.HTMLSanitizerPolicyUGC},
+		OrgPolicies: map[int64]setting.HTMLSanitizerPolicySettings{
+			2: {Policy: setting.HTMLSanitizerPolicyStrict},
+			3: {Policy: setting.HTMLSanitizerPolicyUGC, AllowClassAttributes: true, AllowIframes: true},
+		},
+	})
+
+	t.Run("removes the scripts and keeps the formatting", func(t *testing.T) {
+		assert.Equal(t, `<b>deploy</b> <a href="https://example.com" rel="nofollow">link</a>`,
+			s.SanitizeHTML(1, `<b>deploy</b><script>alert(1)</script> <a href="https://example.com" onclick="alert(1)">link</a>`))
+		assert.Equal(t, "link", s.SanitizeHTML(1, `<a href="javascript:alert(1)">link</a>`))
+	})
+
+	t.Run("uses the policy of the organization", func(t *testing.T) {
+		assert.Equal(t, "deploy", s.SanitizeHTML(2, "<b>deploy</b>"))
+		assert.Equal(t, `<p class="big">deploy</p>`, s.SanitizeHTML(3, `<p class="big">deploy</p>`))
+		assert.Equal(t, `<iframe src="https://example.com/embed"></iframe>`,
+			s.SanitizeHTML(3, `<iframe src="https://example.com/embed"></iframe>`))
+		assert.Equal(t, ``, s.SanitizeHTML(3, `<iframe src="javascript:alert(1)"></iframe>`))
+		assert.Equal(t, ``, s.SanitizeHTML(1, `<iframe src="https://example.com/embed"></iframe>`))
+	})
+
+	t.Run("keeps the HTML when disabled", func(t *testing.T) {
+		s := newTestService(t, setting.HTMLSanitizerSettings{})
+		assert.Equal(t, "<script>alert(1)</script>", s.SanitizeHTML(1, "<script>alert(1)</script>"))
+	})
+}
+
+func TestSanitizeDashboard(t *testing.T) {
+	s := newTestService(t, setting.HTMLSanitizerSettings{
+		Enabled: true,
+		Policy:  setting.HTMLSanitizerPolicySettings{Policy: setting.HTMLSanitizerPolicyUGC},
+	})
+
+	dashboard, err := simplejson.NewJson([]byte(`{
+		"panels": [
+			{"type": "text", "options": {"mode": "html", "content": "<b>a</b><script>alert(1)</script>"}},
+			{"type": "text", "options": {"mode": "markdown", "content": "> quote <script>alert(1)</script>"}},
+			{"type": "graph", "options": {"mode": "html", "content": "<script>alert(1)</script>"}},
+			{"type": "row", "panels": [
+				{"type": "text", "options": {"mode": "html", "content": "<img src=x onerror=alert(1)>"}}
+			]}
+		],
+		"rows": [
+			{"panels": [{"type": "text", "mode": "html", "content": "<i>b</i><script>alert(1)</script>"}]}
+		]
+	}`))
+	require.NoError(t, err)
+
+	s.SanitizeDashboard(1, dashboard)
+
+	panels := dashboard.Get("panels")
+	assert.Equal(t, "<b>a</b>", panels.GetIndex(0).GetPath("options", "content").MustString())
+	assert.Equal(t, "> quote <script>alert(1)</script>", panels.GetIndex(1).GetPath("options", "content").MustString())
+	assert.Equal(t, "<script>alert(1)</script>", panels.GetIndex(2).GetPath("options", "content").MustString())
+	assert.Equal(t, `<img src="x">`, panels.GetIndex(3).Get("panels").GetIndex(0).GetPath("options", "content").MustString())
+	assert.Equal(t, "<i>b</i>", dashboard.Get("rows").GetIndex(0).Get("panels").GetIndex(0).Get("content").MustString())
+}
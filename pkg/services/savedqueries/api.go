@@ -0,0 +1,115 @@
+package savedqueries
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func (s *SavedQueryService) registerAPIEndpoints() {
+	s.RouteRegister.Group("/api/saved-queries", func(queries routing.RouteRegister) {
+		queries.Post("/", middleware.ReqSignedIn, routing.Bind(CreateSavedQueryCommand{}), routing.Wrap(s.createHandler))
+		queries.Delete("/:uid", middleware.ReqSignedIn, routing.Wrap(s.deleteHandler))
+		queries.Get("/", middleware.ReqSignedIn, routing.Wrap(s.getAllHandler))
+		queries.Get("/:uid", middleware.ReqSignedIn, routing.Wrap(s.getHandler))
+		queries.Patch("/:uid", middleware.ReqSignedIn, routing.Bind(patchSavedQueryCommand{}), routing.Wrap(s.patchHandler))
+	})
+}
+
+// createHandler handles POST /api/saved-queries.
+func (s *SavedQueryService) createHandler(c *models.ReqContext, cmd CreateSavedQueryCommand) response.Response {
+	query, err := s.createSavedQuery(c.Req.Context(), c.SignedInUser, cmd)
+	if err != nil {
+		return toSavedQueryError(err, "Failed to create saved query")
+	}
+
+	return response.JSON(200, util.DynMap{"result": query})
+}
+
+// deleteHandler handles DELETE /api/saved-queries/:uid.
+func (s *SavedQueryService) deleteHandler(c *models.ReqContext) response.Response {
+	err := s.deleteSavedQuery(c.Req.Context(), c.SignedInUser, c.Params(":uid"))
+	if err != nil {
+		return toSavedQueryError(err, "Failed to delete saved query")
+	}
+
+	return response.Success("Saved query deleted")
+}
+
+// getHandler handles GET /api/saved-queries/:uid.
+func (s *SavedQueryService) getHandler(c *models.ReqContext) response.Response {
+	query, err := s.getSavedQueryByUID(c.Req.Context(), c.SignedInUser, c.Params(":uid"))
+	if err != nil {
+		return toSavedQueryError(err, "Failed to get saved query")
+	}
+
+	return response.JSON(200, util.DynMap{"result": query})
+}
+
+// getAllHandler handles GET /api/saved-queries/.
+func (s *SavedQueryService) getAllHandler(c *models.ReqContext) response.Response {
+	query := searchSavedQueriesQuery{
+		perPage:       c.QueryInt("perPage"),
+		page:          c.QueryInt("page"),
+		searchString:  c.Query("searchString"),
+		folderID:      -1,
+		datasourceUID: c.Query("datasourceUid"),
+	}
+	if folderID := c.Query("folderId"); folderID != "" {
+		id, err := strconv.ParseInt(folderID, 10, 64)
+		if err != nil {
+			return response.Error(400, "Invalid folderId", err)
+		}
+		query.folderID = id
+	}
+
+	result, err := s.getAllSavedQueries(c.Req.Context(), c.SignedInUser, query)
+	if err != nil {
+		return toSavedQueryError(err, "Failed to get saved queries")
+	}
+
+	return response.JSON(200, util.DynMap{"result": result})
+}
+
+// patchHandler handles PATCH /api/saved-queries/:uid.
+func (s *SavedQueryService) patchHandler(c *models.ReqContext, cmd patchSavedQueryCommand) response.Response {
+	query, err := s.patchSavedQuery(c.Req.Context(), c.SignedInUser, cmd, c.Params(":uid"))
+	if err != nil {
+		return toSavedQueryError(err, "Failed to update saved query")
+	}
+
+	return response.JSON(200, util.DynMap{"result": query})
+}
+
+func toSavedQueryError(err error, message string) response.Response {
+	if errors.Is(err, errSavedQueryAlreadyExists) {
+		return response.Error(400, errSavedQueryAlreadyExists.Error(), err)
+	}
+	if errors.Is(err, errSavedQueryReference) {
+		return response.Error(400, errSavedQueryReference.Error(), err)
+	}
+	if errors.Is(err, errSavedQueryDatasourceNotFound) {
+		return response.Error(400, errSavedQueryDatasourceNotFound.Error(), err)
+	}
+	if errors.Is(err, models.ErrSavedQueryInvalidVariables) {
+		return response.Error(400, err.Error(), err)
+	}
+	if errors.Is(err, models.ErrSavedQueryNotFound) {
+		return response.Error(404, models.ErrSavedQueryNotFound.Error(), err)
+	}
+	if errors.Is(err, errSavedQueryVersionMismatch) {
+		return response.Error(412, errSavedQueryVersionMismatch.Error(), err)
+	}
+	if errors.Is(err, models.ErrFolderNotFound) {
+		return response.Error(404, models.ErrFolderNotFound.Error(), err)
+	}
+	if errors.Is(err, models.ErrFolderAccessDenied) {
+		return response.Error(403, models.ErrFolderAccessDenied.Error(), err)
+	}
+	return response.Error(500, message, err)
+}
@@ -0,0 +1,376 @@
+package savedqueries
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func getSavedQuery(session *sqlstore.DBSession, uid string, orgID int64) (SavedQuery, error) {
+	query := SavedQuery{}
+	exists, err := session.Where("uid=? AND org_id=?", uid, orgID).Get(&query)
+	if err != nil {
+		return SavedQuery{}, err
+	}
+	if !exists {
+		return SavedQuery{}, models.ErrSavedQueryNotFound
+	}
+
+	return query, nil
+}
+
+func toSavedQueryDTO(query SavedQuery) (SavedQueryDTO, error) {
+	variables := make([]SavedQueryVariable, 0)
+	if len(query.Variables) > 0 {
+		if err := json.Unmarshal(query.Variables, &variables); err != nil {
+			return SavedQueryDTO{}, err
+		}
+	}
+
+	return SavedQueryDTO{
+		ID:            query.ID,
+		OrgID:         query.OrgID,
+		FolderID:      query.FolderID,
+		UID:           query.UID,
+		Name:          query.Name,
+		Description:   query.Description,
+		DatasourceUID: query.DatasourceUID,
+		Model:         query.Model,
+		Variables:     variables,
+		Version:       query.Version,
+		Created:       query.Created,
+		Updated:       query.Updated,
+		CreatedBy:     query.CreatedBy,
+		UpdatedBy:     query.UpdatedBy,
+	}, nil
+}
+
+// validateSavedQuery validates the model, variables and data source of a
+// saved query and returns the model and variables to store.
+func (s *SavedQueryService) validateSavedQuery(ctx context.Context, orgID int64, datasourceUID string, model json.RawMessage,
+	variables []SavedQueryVariable) (json.RawMessage, json.RawMessage, error) {
+	if len(model) == 0 {
+		model = json.RawMessage("{}")
+	}
+	modelJSON, err := simplejson.NewJson(model)
+	if err != nil {
+		return nil, nil, err
+	}
+	if models.IsSavedQueryReference(modelJSON) {
+		return nil, nil, errSavedQueryReference
+	}
+
+	if variables == nil {
+		variables = []SavedQueryVariable{}
+	}
+	if err := validateVariables(variables); err != nil {
+		return nil, nil, err
+	}
+	variablesJSON, err := json.Marshal(variables)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dsQuery := &models.GetDataSourceQuery{Uid: datasourceUID, OrgId: orgID}
+	if err := s.Bus.Dispatch(dsQuery); err != nil {
+		if errors.Is(err, models.ErrDataSourceNotFound) {
+			return nil, nil, errSavedQueryDatasourceNotFound
+		}
+		return nil, nil, err
+	}
+
+	return model, variablesJSON, nil
+}
+
+// createSavedQuery adds a saved query.
+func (s *SavedQueryService) createSavedQuery(ctx context.Context, user *models.SignedInUser, cmd CreateSavedQueryCommand) (SavedQueryDTO, error) {
+	model, variables, err := s.validateSavedQuery(ctx, user.OrgId, cmd.DatasourceUID, cmd.Model, cmd.Variables)
+	if err != nil {
+		return SavedQueryDTO{}, err
+	}
+
+	query := SavedQuery{
+		OrgID:         user.OrgId,
+		FolderID:      cmd.FolderID,
+		UID:           util.GenerateShortUID(),
+		Name:          cmd.Name,
+		Description:   cmd.Description,
+		DatasourceUID: cmd.DatasourceUID,
+		Model:         model,
+		Variables:     variables,
+		Version:       1,
+
+		Created: time.Now(),
+		Updated: time.Now(),
+
+		CreatedBy: user.UserId,
+		UpdatedBy: user.UserId,
+	}
+
+	err = s.SQLStore.WithTransactionalDbSession(ctx, func(session *sqlstore.DBSession) error {
+		if err := s.requirePermissionsOnFolder(user, cmd.FolderID); err != nil {
+			return err
+		}
+		if _, err := session.Insert(&query); err != nil {
+			if s.SQLStore.Dialect.IsUniqueConstraintViolation(err) {
+				return errSavedQueryAlreadyExists
+			}
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return SavedQueryDTO{}, err
+	}
+
+	return toSavedQueryDTO(query)
+}
+
+// deleteSavedQuery deletes a saved query.
+func (s *SavedQueryService) deleteSavedQuery(ctx context.Context, user *models.SignedInUser, uid string) error {
+	return s.SQLStore.WithTransactionalDbSession(ctx, func(session *sqlstore.DBSession) error {
+		query, err := getSavedQuery(session, uid, user.OrgId)
+		if err != nil {
+			return err
+		}
+		if err := s.requirePermissionsOnFolder(user, query.FolderID); err != nil {
+			return err
+		}
+
+		if _, err := session.Exec("DELETE FROM saved_query WHERE id=?", query.ID); err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+// getSavedQueryByUID gets a saved query the user can view by uid.
+func (s *SavedQueryService) getSavedQueryByUID(ctx context.Context, user *models.SignedInUser, uid string) (SavedQueryDTO, error) {
+	var query SavedQuery
+	err := s.SQLStore.WithDbSession(ctx, func(session *sqlstore.DBSession) error {
+		var err error
+		query, err = getSavedQuery(session, uid, user.OrgId)
+		return err
+	})
+	if err != nil {
+		return SavedQueryDTO{}, err
+	}
+
+	if canView, err := s.canViewFolder(user, query.FolderID); err != nil {
+		return SavedQueryDTO{}, err
+	} else if !canView {
+		return SavedQueryDTO{}, models.ErrFolderAccessDenied
+	}
+
+	return toSavedQueryDTO(query)
+}
+
+// getAllSavedQueries gets the saved queries the user can view.
+func (s *SavedQueryService) getAllSavedQueries(ctx context.Context, user *models.SignedInUser, query searchSavedQueriesQuery) (SavedQuerySearchResult, error) {
+	if query.perPage <= 0 {
+		query.perPage = 100
+	}
+	if query.page <= 0 {
+		query.page = 1
+	}
+
+	queries := make([]SavedQuery, 0)
+	err := s.SQLStore.WithDbSession(ctx, func(session *sqlstore.DBSession) error {
+		builder := sqlstore.SQLBuilder{}
+		builder.Write("SELECT * FROM saved_query WHERE org_id=?", user.OrgId)
+		if len(strings.TrimSpace(query.searchString)) > 0 {
+			builder.Write(" AND name "+s.SQLStore.Dialect.LikeStr()+" ?", "%"+query.searchString+"%")
+		}
+		if query.folderID >= 0 {
+			builder.Write(" AND folder_id=?", query.folderID)
+		}
+		if query.datasourceUID != "" {
+			builder.Write(" AND datasource_uid=?", query.datasourceUID)
+		}
+		return session.SQL(builder.GetSQLString(), builder.GetParams()...).Find(&queries)
+	})
+	if err != nil {
+		return SavedQuerySearchResult{}, err
+	}
+	sort.Slice(queries, func(i, j int) bool {
+		return strings.ToLower(queries[i].Name) < strings.ToLower(queries[j].Name)
+	})
+
+	// the permissions are the ones of the folders, checked once per folder
+	canView := map[int64]bool{}
+	dtos := make([]SavedQueryDTO, 0, len(queries))
+	for _, q := range queries {
+		allowed, ok := canView[q.FolderID]
+		if !ok {
+			if allowed, err = s.canViewFolder(user, q.FolderID); err != nil && !errors.Is(err, models.ErrFolderNotFound) {
+				return SavedQuerySearchResult{}, err
+			}
+			canView[q.FolderID] = allowed
+		}
+		if !allowed {
+			continue
+		}
+
+		dto, err := toSavedQueryDTO(q)
+		if err != nil {
+			return SavedQuerySearchResult{}, err
+		}
+		dtos = append(dtos, dto)
+	}
+
+	result := SavedQuerySearchResult{
+		TotalCount: len(dtos),
+		Queries:    []SavedQueryDTO{},
+		Page:       query.page,
+		PerPage:    query.perPage,
+	}
+	if start := (query.page - 1) * query.perPage; start < len(dtos) {
+		end := start + query.perPage
+		if end > len(dtos) {
+			end = len(dtos)
+		}
+		result.Queries = dtos[start:end]
+	}
+
+	return result, nil
+}
+
+// patchSavedQuery updates a saved query, checking its version to detect
+// concurrent changes.
+func (s *SavedQueryService) patchSavedQuery(ctx context.Context, user *models.SignedInUser, cmd patchSavedQueryCommand, uid string) (SavedQueryDTO, error) {
+	var query SavedQuery
+	err := s.SQLStore.WithTransactionalDbSession(ctx, func(session *sqlstore.DBSession) error {
+		existing, err := getSavedQuery(session, uid, user.OrgId)
+		if err != nil {
+			return err
+		}
+		if existing.Version != cmd.Version {
+			return errSavedQueryVersionMismatch
+		}
+		if err := s.requirePermissionsOnFolder(user, existing.FolderID); err != nil {
+			return err
+		}
+
+		dto, err := toSavedQueryDTO(existing)
+		if err != nil {
+			return err
+		}
+
+		query = existing
+		if cmd.FolderID != -1 && cmd.FolderID != existing.FolderID {
+			if err := s.requirePermissionsOnFolder(user, cmd.FolderID); err != nil {
+				return err
+			}
+			query.FolderID = cmd.FolderID
+		}
+		if cmd.Name != "" {
+			query.Name = cmd.Name
+		}
+		if cmd.Description != nil {
+			query.Description = *cmd.Description
+		}
+		if cmd.DatasourceUID != "" {
+			query.DatasourceUID = cmd.DatasourceUID
+		}
+		if len(cmd.Model) > 0 {
+			query.Model = cmd.Model
+		}
+		variables := dto.Variables
+		if cmd.Variables != nil {
+			variables = *cmd.Variables
+		}
+		if query.Model, query.Variables, err = s.validateSavedQuery(ctx, user.OrgId, query.DatasourceUID, query.Model, variables); err != nil {
+			return err
+		}
+
+		query.Version++
+		query.Updated = time.Now()
+		query.UpdatedBy = user.UserId
+
+		rowsAffected, err := session.ID(existing.ID).Where("version=?", existing.Version).AllCols().Update(&query)
+		if err != nil {
+			if s.SQLStore.Dialect.IsUniqueConstraintViolation(err) {
+				return errSavedQueryAlreadyExists
+			}
+			return err
+		}
+		if rowsAffected != 1 {
+			return errSavedQueryVersionMismatch
+		}
+		return nil
+	})
+	if err != nil {
+		return SavedQueryDTO{}, err
+	}
+
+	return toSavedQueryDTO(query)
+}
+
+// resolveSavedQuery handles the ResolveSavedQueryQuery of the bus. The
+// resolved query is the model of the saved query with its variables replaced,
+// its data source, and the ref ID, interval and max data points of the query
+// referencing it.
+func (s *SavedQueryService) resolveSavedQuery(ctx context.Context, cmd *models.ResolveSavedQueryQuery) error {
+	uid := cmd.Query.Get(models.SavedQueryUIDKey).MustString()
+
+	var query SavedQuery
+	err := s.SQLStore.WithDbSession(ctx, func(session *sqlstore.DBSession) error {
+		var err error
+		query, err = getSavedQuery(session, uid, cmd.OrgId)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if cmd.SignedInUser != nil {
+		if canView, err := s.canViewFolder(cmd.SignedInUser, query.FolderID); err != nil {
+			return err
+		} else if !canView {
+			return models.ErrFolderAccessDenied
+		}
+	}
+
+	dto, err := toSavedQueryDTO(query)
+	if err != nil {
+		return err
+	}
+	values, err := variableValues(dto.Variables, cmd.Query.Get(models.SavedQueryVariablesKey).MustMap())
+	if err != nil {
+		return err
+	}
+
+	var model map[string]interface{}
+	if err := json.Unmarshal(query.Model, &model); err != nil {
+		return err
+	}
+	result := simplejson.NewFromAny(interpolate(model, values))
+
+	dsQuery := &models.GetDataSourceQuery{Uid: query.DatasourceUID, OrgId: cmd.OrgId}
+	if err := s.Bus.Dispatch(dsQuery); err != nil {
+		if errors.Is(err, models.ErrDataSourceNotFound) {
+			return errSavedQueryDatasourceNotFound
+		}
+		return err
+	}
+	result.Set("datasource", dsQuery.Result.Name)
+	result.Set("datasourceId", dsQuery.Result.Id)
+	result.Set("datasourceUid", dsQuery.Result.Uid)
+
+	for _, key := range []string{"refId", "intervalMs", "maxDataPoints", "hide"} {
+		if value, ok := cmd.Query.CheckGet(key); ok {
+			result.Set(key, value.Interface())
+		}
+	}
+
+	cmd.Result = result
+	return nil
+}
@@ -0,0 +1,61 @@
+package savedqueries
+
+import (
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/guardian"
+)
+
+func isGeneralFolder(folderID int64) bool {
+	return folderID == 0
+}
+
+// requirePermissionsOnFolder checks that the user can edit the saved queries
+// of a folder.
+func (s *SavedQueryService) requirePermissionsOnFolder(user *models.SignedInUser, folderID int64) error {
+	if isGeneralFolder(folderID) && user.HasRole(models.ROLE_EDITOR) {
+		return nil
+	}
+
+	if isGeneralFolder(folderID) && user.HasRole(models.ROLE_VIEWER) {
+		return models.ErrFolderAccessDenied
+	}
+
+	g, err := s.folderGuardian(user, folderID)
+	if err != nil {
+		return err
+	}
+
+	canEdit, err := g.CanEdit()
+	if err != nil {
+		return err
+	}
+	if !canEdit {
+		return models.ErrFolderAccessDenied
+	}
+
+	return nil
+}
+
+// canViewFolder returns whether the user can use the saved queries of a folder.
+func (s *SavedQueryService) canViewFolder(user *models.SignedInUser, folderID int64) (bool, error) {
+	if isGeneralFolder(folderID) {
+		return true, nil
+	}
+
+	g, err := s.folderGuardian(user, folderID)
+	if err != nil {
+		return false, err
+	}
+
+	return g.CanView()
+}
+
+func (s *SavedQueryService) folderGuardian(user *models.SignedInUser, folderID int64) (guardian.DashboardGuardian, error) {
+	folder, err := dashboards.NewFolderService(user.OrgId, user, s.SQLStore).GetFolderByID(folderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return guardian.New(folder.Id, user.OrgId, user), nil
+}
@@ -0,0 +1,107 @@
+package savedqueries
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// SavedQuery is the model for saved query definitions.
+type SavedQuery struct {
+	ID            int64  `xorm:"pk autoincr 'id'"`
+	OrgID         int64  `xorm:"org_id"`
+	FolderID      int64  `xorm:"folder_id"`
+	UID           string `xorm:"uid"`
+	Name          string
+	Description   string
+	DatasourceUID string `xorm:"datasource_uid"`
+	Model         json.RawMessage
+	Variables     json.RawMessage
+	Version       int64
+
+	Created time.Time
+	Updated time.Time
+
+	CreatedBy int64
+	UpdatedBy int64
+}
+
+// SavedQueryVariable is a variable of a saved query, referenced in its model
+// as $name, ${name} or [[name]].
+type SavedQueryVariable struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Default is the value of the variable when the query referencing the
+	// saved query doesn't set it.
+	Default string `json:"default"`
+}
+
+// SavedQueryDTO is the frontend DTO for saved queries.
+type SavedQueryDTO struct {
+	ID            int64                `json:"id"`
+	OrgID         int64                `json:"orgId"`
+	FolderID      int64                `json:"folderId"`
+	UID           string               `json:"uid"`
+	Name          string               `json:"name"`
+	Description   string               `json:"description"`
+	DatasourceUID string               `json:"datasourceUid"`
+	Model         json.RawMessage      `json:"model"`
+	Variables     []SavedQueryVariable `json:"variables"`
+	Version       int64                `json:"version"`
+	Created       time.Time            `json:"created"`
+	Updated       time.Time            `json:"updated"`
+	CreatedBy     int64                `json:"createdBy"`
+	UpdatedBy     int64                `json:"updatedBy"`
+}
+
+// SavedQuerySearchResult is the search result for saved queries.
+type SavedQuerySearchResult struct {
+	TotalCount int             `json:"totalCount"`
+	Queries    []SavedQueryDTO `json:"queries"`
+	Page       int             `json:"page"`
+	PerPage    int             `json:"perPage"`
+}
+
+var (
+	// errSavedQueryAlreadyExists is an error for when the user tries to add a saved query that already exists.
+	errSavedQueryAlreadyExists = errors.New("saved query with that name already exists")
+	// errSavedQueryVersionMismatch is an error for when a saved query has been changed by someone else.
+	errSavedQueryVersionMismatch = errors.New("the saved query has been changed by someone else")
+	// errSavedQueryReference is an error for when the model of a saved query references another saved query.
+	errSavedQueryReference = errors.New("a saved query can't reference another saved query")
+	// errSavedQueryDatasourceNotFound is an error for when the data source of a saved query can't be found.
+	errSavedQueryDatasourceNotFound = errors.New("the data source of the saved query could not be found")
+)
+
+// Commands
+
+// CreateSavedQueryCommand is the command for adding a SavedQuery.
+type CreateSavedQueryCommand struct {
+	FolderID      int64                `json:"folderId"`
+	Name          string               `json:"name" binding:"Required"`
+	Description   string               `json:"description"`
+	DatasourceUID string               `json:"datasourceUid" binding:"Required"`
+	Model         json.RawMessage      `json:"model"`
+	Variables     []SavedQueryVariable `json:"variables"`
+}
+
+// patchSavedQueryCommand is the command for patching a SavedQuery, the
+// fields which aren't set are left unchanged.
+type patchSavedQueryCommand struct {
+	FolderID      int64                 `json:"folderId" binding:"Default(-1)"`
+	Name          string                `json:"name"`
+	Description   *string               `json:"description"`
+	DatasourceUID string                `json:"datasourceUid"`
+	Model         json.RawMessage       `json:"model"`
+	Variables     *[]SavedQueryVariable `json:"variables"`
+	Version       int64                 `json:"version" binding:"Required"`
+}
+
+// searchSavedQueriesQuery is the query used for searching for saved queries.
+type searchSavedQueriesQuery struct {
+	perPage       int
+	page          int
+	searchString  string
+	folderID      int64
+	datasourceUID string
+}
@@ -0,0 +1,35 @@
+package savedqueries
+
+import (
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// SavedQueryService is the service for the saved queries feature, a library
+// of queries that panels and alert rules reference by UID so that they are
+// maintained in one place.
+type SavedQueryService struct {
+	Cfg           *setting.Cfg          `inject:""`
+	SQLStore      *sqlstore.SQLStore    `inject:""`
+	RouteRegister routing.RouteRegister `inject:""`
+	Bus           bus.Bus               `inject:""`
+	log           log.Logger
+}
+
+func init() {
+	registry.RegisterService(&SavedQueryService{})
+}
+
+// Init initializes the SavedQuery service
+func (s *SavedQueryService) Init() error {
+	s.log = log.New("saved-queries")
+
+	s.registerAPIEndpoints()
+	s.Bus.AddHandlerCtx(s.resolveSavedQuery)
+
+	return nil
+}
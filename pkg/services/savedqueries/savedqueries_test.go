@@ -0,0 +1,192 @@
+package savedqueries
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/macaron.v1"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type savedQueryResult struct {
+	Result SavedQueryDTO `json:"result"`
+}
+
+type scenarioContext struct {
+	service    *SavedQueryService
+	reqContext *models.ReqContext
+	user       models.SignedInUser
+	sqlStore   *sqlstore.SQLStore
+	datasource *models.DataSource
+}
+
+func TestSavedQueries(t *testing.T) {
+	testScenario(t, "Saved queries can be created, updated and deleted", func(t *testing.T, sc scenarioContext) {
+		resp := sc.service.createHandler(sc.reqContext, CreateSavedQueryCommand{
+			Name:          "Errors",
+			DatasourceUID: sc.datasource.Uid,
+			Model:         json.RawMessage(`{"expr": "sum(rate(errors{job=\"$job\"}[$__interval]))"}`),
+			Variables:     []SavedQueryVariable{{Name: "job", Default: "api"}},
+		})
+		created := unmarshalResponse(t, resp)
+		require.Equal(t, int64(1), created.Version)
+		require.Equal(t, []SavedQueryVariable{{Name: "job", Default: "api"}}, created.Variables)
+
+		query, err := sc.service.getSavedQueryByUID(context.Background(), &sc.user, created.UID)
+		require.NoError(t, err)
+		require.Equal(t, "Errors", query.Name)
+
+		name := "API errors"
+		patched, err := sc.service.patchSavedQuery(context.Background(), &sc.user, patchSavedQueryCommand{
+			FolderID: -1,
+			Name:     name,
+			Version:  1,
+		}, created.UID)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), patched.Version)
+		require.Equal(t, name, patched.Name)
+		require.JSONEq(t, string(created.Model), string(patched.Model))
+
+		_, err = sc.service.patchSavedQuery(context.Background(), &sc.user, patchSavedQueryCommand{
+			FolderID: -1,
+			Version:  1,
+		}, created.UID)
+		require.ErrorIs(t, err, errSavedQueryVersionMismatch)
+
+		result, err := sc.service.getAllSavedQueries(context.Background(), &sc.user, searchSavedQueriesQuery{
+			searchString: "errors",
+			folderID:     -1,
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, result.TotalCount)
+
+		require.NoError(t, sc.service.deleteSavedQuery(context.Background(), &sc.user, created.UID))
+		_, err = sc.service.getSavedQueryByUID(context.Background(), &sc.user, created.UID)
+		require.ErrorIs(t, err, models.ErrSavedQueryNotFound)
+	})
+
+	testScenario(t, "Saved queries are validated", func(t *testing.T, sc scenarioContext) {
+		for _, cmd := range []CreateSavedQueryCommand{
+			{Name: "Reference", DatasourceUID: sc.datasource.Uid, Model: json.RawMessage(`{"savedQueryUid": "other"}`)},
+			{Name: "Variable", DatasourceUID: sc.datasource.Uid, Variables: []SavedQueryVariable{{Name: "a b"}}},
+			{Name: "Data source", DatasourceUID: "missing"},
+		} {
+			resp := sc.service.createHandler(sc.reqContext, cmd)
+			require.Equal(t, 400, resp.Status(), cmd.Name)
+		}
+	})
+
+	testScenario(t, "Viewers can't create saved queries in the General folder", func(t *testing.T, sc scenarioContext) {
+		sc.user.OrgRole = models.ROLE_VIEWER
+		_, err := sc.service.createSavedQuery(context.Background(), &sc.user, CreateSavedQueryCommand{
+			Name:          "Errors",
+			DatasourceUID: sc.datasource.Uid,
+		})
+		require.ErrorIs(t, err, models.ErrFolderAccessDenied)
+	})
+
+	testScenario(t, "Queries referencing a saved query are resolved", func(t *testing.T, sc scenarioContext) {
+		created, err := sc.service.createSavedQuery(context.Background(), &sc.user, CreateSavedQueryCommand{
+			Name:          "Errors",
+			DatasourceUID: sc.datasource.Uid,
+			Model:         json.RawMessage(`{"refId": "Z", "expr": "sum(rate(errors{job=\"$job\", env=\"${env}\"}[$__interval]))"}`),
+			Variables:     []SavedQueryVariable{{Name: "job", Default: "api"}, {Name: "env"}},
+		})
+		require.NoError(t, err)
+
+		query := &models.ResolveSavedQueryQuery{
+			OrgId:        sc.user.OrgId,
+			SignedInUser: &sc.user,
+			Query: simplejson.NewFromAny(map[string]interface{}{
+				"refId":                 "A",
+				"maxDataPoints":         500,
+				models.SavedQueryUIDKey: created.UID,
+				models.SavedQueryVariablesKey: map[string]interface{}{
+					"env": "prod",
+				},
+			}),
+		}
+		require.NoError(t, sc.service.resolveSavedQuery(context.Background(), query))
+
+		require.Equal(t, map[string]interface{}{
+			"refId":         "A",
+			"maxDataPoints": 500,
+			"expr":          `sum(rate(errors{job="api", env="prod"}[$__interval]))`,
+			"datasource":    sc.datasource.Name,
+			"datasourceId":  sc.datasource.Id,
+			"datasourceUid": sc.datasource.Uid,
+		}, query.Result.MustMap())
+
+		query.Query.Set(models.SavedQueryVariablesKey, map[string]interface{}{"unknown": "x"})
+		err = sc.service.resolveSavedQuery(context.Background(), query)
+		require.ErrorIs(t, err, models.ErrSavedQueryInvalidVariables)
+
+		query.Query.Set(models.SavedQueryUIDKey, "missing")
+		err = sc.service.resolveSavedQuery(context.Background(), query)
+		require.ErrorIs(t, err, models.ErrSavedQueryNotFound)
+	})
+}
+
+func unmarshalResponse(t *testing.T, resp interface {
+	Status() int
+	Body() []byte
+}) SavedQueryDTO {
+	t.Helper()
+
+	require.Equal(t, 200, resp.Status(), string(resp.Body()))
+	var result savedQueryResult
+	require.NoError(t, json.Unmarshal(resp.Body(), &result))
+	return result.Result
+}
+
+// testScenario is a wrapper around t.Run performing common setup for saved query tests.
+func testScenario(t *testing.T, desc string, fn func(t *testing.T, sc scenarioContext)) {
+	t.Helper()
+
+	t.Run(desc, func(t *testing.T) {
+		ctx := macaron.Context{
+			Req: macaron.Request{Request: &http.Request{}},
+		}
+		sqlStore := sqlstore.InitTestDB(t)
+		service := SavedQueryService{
+			Cfg:      setting.NewCfg(),
+			SQLStore: sqlStore,
+			Bus:      bus.GetBus(),
+		}
+
+		user := models.SignedInUser{
+			UserId:     1,
+			Login:      "signed_in_user",
+			OrgId:      1,
+			OrgRole:    models.ROLE_ADMIN,
+			LastSeenAt: time.Now(),
+		}
+
+		addDS := &models.AddDataSourceCommand{OrgId: user.OrgId, Name: "Prometheus", Type: "prometheus", Access: models.DS_ACCESS_PROXY, Url: "http://localhost:9090"}
+		require.NoError(t, bus.Dispatch(addDS))
+
+		_, err := dashboards.NewFolderService(user.OrgId, &user, sqlStore).CreateFolder("ScenarioFolder", "ScenarioFolder")
+		require.NoError(t, err)
+
+		fn(t, scenarioContext{
+			service:  &service,
+			user:     user,
+			sqlStore: sqlStore,
+			reqContext: &models.ReqContext{
+				Context:      &ctx,
+				SignedInUser: &user,
+			},
+			datasource: addDS.Result,
+		})
+	})
+}
@@ -0,0 +1,83 @@
+package savedqueries
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+var (
+	variableNameRegexp = regexp.MustCompile(`^\w+$`)
+	// variableRegexp matches the $name, ${name} and [[name]] syntaxes of the
+	// variables of dashboards.
+	variableRegexp = regexp.MustCompile(`\$(\w+)|\$\{(\w+)\}|\[\[(\w+)\]\]`)
+)
+
+func validateVariables(variables []SavedQueryVariable) error {
+	names := make(map[string]bool, len(variables))
+	for _, v := range variables {
+		if !variableNameRegexp.MatchString(v.Name) {
+			return fmt.Errorf("%w: invalid variable name %q", models.ErrSavedQueryInvalidVariables, v.Name)
+		}
+		if names[v.Name] {
+			return fmt.Errorf("%w: duplicate variable %q", models.ErrSavedQueryInvalidVariables, v.Name)
+		}
+		names[v.Name] = true
+	}
+	return nil
+}
+
+// variableValues returns the values of the variables of a saved query, the
+// values set by a query referencing it or the defaults.
+func variableValues(variables []SavedQueryVariable, values map[string]interface{}) (map[string]string, error) {
+	result := make(map[string]string, len(variables))
+	for _, v := range variables {
+		result[v.Name] = v.Default
+	}
+
+	for name, value := range values {
+		if _, ok := result[name]; !ok {
+			return nil, fmt.Errorf("%w: the saved query has no variable %q", models.ErrSavedQueryInvalidVariables, name)
+		}
+		switch v := value.(type) {
+		case string:
+			result[name] = v
+		case json.Number, float64, bool:
+			result[name] = fmt.Sprint(v)
+		default:
+			return nil, fmt.Errorf("%w: the value of variable %q must be a string, got %T", models.ErrSavedQueryInvalidVariables, name, value)
+		}
+	}
+	return result, nil
+}
+
+// interpolate replaces the variables in the strings of a query model, leaving
+// the other variables, like the global ones of the data sources, as they are.
+func interpolate(model interface{}, values map[string]string) interface{} {
+	switch m := model.(type) {
+	case string:
+		return variableRegexp.ReplaceAllStringFunc(m, func(match string) string {
+			groups := variableRegexp.FindStringSubmatch(match)
+			for _, name := range groups[1:] {
+				if value, ok := values[name]; ok {
+					return value
+				}
+			}
+			return match
+		})
+	case map[string]interface{}:
+		for k, v := range m {
+			m[k] = interpolate(v, values)
+		}
+		return m
+	case []interface{}:
+		for i, v := range m {
+			m[i] = interpolate(v, values)
+		}
+		return m
+	default:
+		return model
+	}
+}
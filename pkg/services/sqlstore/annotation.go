@@ -146,6 +146,32 @@ func (r *SQLAnnotationRepo) Find(query *annotations.ItemQuery) ([]*annotations.I
 			SELECT a.id from annotation a
 		`)
 
+	filterSQL, filterParams := annotationFilterSQL(query)
+	sql.WriteString(filterSQL)
+	params = append(params, filterParams...)
+
+	if query.Limit == 0 {
+		query.Limit = 100
+	}
+
+	// order of ORDER BY arguments match the order of a sql index for performance
+	sql.WriteString(" ORDER BY a.org_id, a.epoch_end DESC, a.epoch DESC" + dialect.Limit(query.Limit) + " ) dt on dt.id = annotation.id")
+
+	items := make([]*annotations.ItemDTO, 0)
+
+	if err := x.SQL(sql.String(), params...).Find(&items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// annotationFilterSQL returns the WHERE clause of the annotations matching the query, the annotation table being
+// aliased as a.
+func annotationFilterSQL(query *annotations.ItemQuery) (string, []interface{}) {
+	var sql bytes.Buffer
+	params := make([]interface{}, 0)
+
 	sql.WriteString(`WHERE a.org_id = ?`)
 	params = append(params, query.OrgId)
 
@@ -218,22 +244,116 @@ func (r *SQLAnnotationRepo) Find(query *annotations.ItemQuery) ([]*annotations.I
 		}
 	}
 
-	if query.Limit == 0 {
-		query.Limit = 100
+	return sql.String(), params
+}
+
+// FindAggregated returns the number of annotations matching the query by bucket of the interval, from the start of
+// the annotations, with the tag most of the annotations of each bucket have. The buckets without annotations are
+// left out.
+func (r *SQLAnnotationRepo) FindAggregated(query *annotations.AggregateQuery) ([]*annotations.AggregatedItemDTO, error) {
+	if query.Interval <= 0 {
+		return nil, errors.New("aggregation interval must be positive")
 	}
 
-	// order of ORDER BY arguments match the order of a sql index for performance
-	sql.WriteString(" ORDER BY a.org_id, a.epoch_end DESC, a.epoch DESC" + dialect.Limit(query.Limit) + " ) dt on dt.id = annotation.id")
+	filterSQL, params := annotationFilterSQL(&query.ItemQuery)
+	// the interval is an integer, it's inlined for the dialects to use an integer modulo
+	bucket := fmt.Sprintf("(a.epoch - a.epoch %% %d)", query.Interval)
 
-	items := make([]*annotations.ItemDTO, 0)
+	type bucketCount struct {
+		Bucket int64
+		Count  int64
+	}
+	counts := make([]*bucketCount, 0)
+	countSQL := `SELECT ` + bucket + ` AS bucket, COUNT(*) AS count FROM annotation a ` + filterSQL +
+		` GROUP BY ` + bucket + ` ORDER BY bucket`
+	if err := x.SQL(countSQL, params...).Find(&counts); err != nil {
+		return nil, err
+	}
 
-	if err := x.SQL(sql.String(), params...).Find(&items); err != nil {
+	type bucketTagCount struct {
+		Bucket int64
+		Key    string
+		Value  string
+		Count  int64
+	}
+	tagCounts := make([]*bucketTagCount, 0)
+	tagKey := "agg_tag." + dialect.Quote("key")
+	tagValue := "agg_tag." + dialect.Quote("value")
+	tagCountSQL := `SELECT ` + bucket + ` AS bucket, ` + tagKey + `, ` + tagValue + `, COUNT(*) AS count
+		FROM annotation a
+		INNER JOIN annotation_tag agg_at ON agg_at.annotation_id = a.id
+		INNER JOIN tag agg_tag ON agg_tag.id = agg_at.tag_id ` + filterSQL +
+		` GROUP BY ` + bucket + `, ` + tagKey + `, ` + tagValue
+	if err := x.SQL(tagCountSQL, params...).Find(&tagCounts); err != nil {
 		return nil, err
 	}
 
+	items := make([]*annotations.AggregatedItemDTO, 0, len(counts))
+	byBucket := make(map[int64]*annotations.AggregatedItemDTO, len(counts))
+	for _, count := range counts {
+		item := &annotations.AggregatedItemDTO{
+			Time:    count.Bucket,
+			TimeEnd: count.Bucket + query.Interval,
+			Count:   count.Count,
+		}
+		items = append(items, item)
+		byBucket[count.Bucket] = item
+	}
+
+	for _, tagCount := range tagCounts {
+		item, ok := byBucket[tagCount.Bucket]
+		if !ok {
+			continue
+		}
+		tag := tagCount.Key
+		if tagCount.Value != "" {
+			tag = tagCount.Key + ":" + tagCount.Value
+		}
+		// ties go to the first tag alphabetically, for the buckets to be stable
+		if tagCount.Count > item.DominantTagCount || (tagCount.Count == item.DominantTagCount && tag < item.DominantTag) {
+			item.DominantTag = tag
+			item.DominantTagCount = tagCount.Count
+		}
+	}
+
 	return items, nil
 }
 
+// FindTags returns the tags of the annotations of the org with the number of annotations they're on. With a term,
+// the tags starting with it come first, then the ones containing it and the ones a few typos away from it, the most
+// used first.
+func (r *SQLAnnotationRepo) FindTags(query *annotations.TagsQuery) (annotations.FindTagsResult, error) {
+	if query.Limit == 0 {
+		query.Limit = 100
+	}
+
+	// the annotations are counted by tag ID before the join, using the tag_id index, and the tags are matched
+	// afterwards since there are a lot less tags than annotations
+	sql := `
+		SELECT
+			tag.` + dialect.Quote("key") + `,
+			tag.` + dialect.Quote("value") + `,
+			tag_count.count
+		FROM (
+			SELECT annotation_tag.tag_id, COUNT(*) AS count FROM annotation_tag
+			INNER JOIN annotation ON annotation.id = annotation_tag.annotation_id
+			WHERE annotation.org_id = ?
+			GROUP BY annotation_tag.tag_id
+		) tag_count
+		INNER JOIN tag ON tag.id = tag_count.tag_id`
+
+	tags := make([]*annotations.Tag, 0)
+	if err := x.SQL(sql, query.OrgID).Find(&tags); err != nil {
+		return annotations.FindTagsResult{}, err
+	}
+
+	result := annotations.FindTagsResult{Tags: make([]*annotations.TagsDTO, 0)}
+	for _, tag := range matchTags(tags, query.Tag, query.Limit) {
+		result.Tags = append(result.Tags, &annotations.TagsDTO{Tag: tag.name, Count: tag.Count})
+	}
+	return result, nil
+}
+
 func (r *SQLAnnotationRepo) Delete(params *annotations.DeleteParams) error {
 	return inTransaction(func(sess *DBSession) error {
 		var (
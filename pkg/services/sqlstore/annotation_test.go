@@ -178,6 +178,60 @@ func TestAnnotations(t *testing.T) {
 			assert.Len(t, items, 1)
 		})
 
+		t.Run("Can aggregate annotations by interval", func(t *testing.T) {
+			items, err := repo.FindAggregated(&annotations.AggregateQuery{
+				ItemQuery: annotations.ItemQuery{OrgId: 1, From: 1, To: 30},
+				Interval:  10,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, []*annotations.AggregatedItemDTO{
+				{Time: 10, TimeEnd: 20, Count: 3, DominantTag: "deploy", DominantTagCount: 1},
+				{Time: 20, TimeEnd: 30, Count: 1, DominantTag: "error", DominantTagCount: 1},
+			}, items)
+
+			items, err = repo.FindAggregated(&annotations.AggregateQuery{
+				ItemQuery: annotations.ItemQuery{OrgId: 1, From: 1, To: 30, Tags: []string{"outage"}},
+				Interval:  30,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, []*annotations.AggregatedItemDTO{
+				{Time: 0, TimeEnd: 30, Count: 2, DominantTag: "error", DominantTagCount: 2},
+			}, items)
+
+			_, err = repo.FindAggregated(&annotations.AggregateQuery{ItemQuery: annotations.ItemQuery{OrgId: 1}})
+			require.Error(t, err)
+		})
+
+		t.Run("Can find the tags with their counts", func(t *testing.T) {
+			result, err := repo.FindTags(&annotations.TagsQuery{OrgID: 1})
+			require.NoError(t, err)
+			assert.Equal(t, []*annotations.TagsDTO{
+				{Tag: "error", Count: 2},
+				{Tag: "outage", Count: 2},
+				{Tag: "server:server-1", Count: 2},
+				{Tag: "type:outage", Count: 2},
+				{Tag: "deploy", Count: 1},
+				{Tag: "rollback", Count: 1},
+			}, result.Tags)
+
+			result, err = repo.FindTags(&annotations.TagsQuery{OrgID: 2})
+			require.NoError(t, err)
+			assert.Empty(t, result.Tags)
+		})
+
+		t.Run("Can find the tags matching a term", func(t *testing.T) {
+			result, err := repo.FindTags(&annotations.TagsQuery{OrgID: 1, Tag: "out", Limit: 10})
+			require.NoError(t, err)
+			assert.Equal(t, []*annotations.TagsDTO{
+				{Tag: "outage", Count: 2},
+				{Tag: "type:outage", Count: 2},
+			}, result.Tags)
+
+			result, err = repo.FindTags(&annotations.TagsQuery{OrgID: 1, Tag: "outgae", Limit: 1})
+			require.NoError(t, err)
+			assert.Equal(t, []*annotations.TagsDTO{{Tag: "outage", Count: 2}}, result.Tags)
+		})
+
 		t.Run("Can update annotation and remove all tags", func(t *testing.T) {
 			query := &annotations.ItemQuery{
 				OrgId:       1,
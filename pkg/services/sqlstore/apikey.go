@@ -14,6 +14,7 @@ func init() {
 	bus.AddHandler("sql", GetApiKeyByName)
 	bus.AddHandlerCtx("sql", DeleteApiKeyCtx)
 	bus.AddHandler("sql", AddApiKey)
+	bus.AddHandler("sql", UpdateApiKeyLastUsed)
 }
 
 func GetApiKeys(query *models.GetApiKeysQuery) error {
@@ -82,6 +83,13 @@ func AddApiKey(cmd *models.AddApiKeyCommand) error {
 	})
 }
 
+func UpdateApiKeyLastUsed(cmd *models.UpdateApiKeyLastUsedCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		_, err := sess.Exec("UPDATE api_key SET last_used_at = ? WHERE id = ?", cmd.LastUsedAt, cmd.Id)
+		return err
+	})
+}
+
 func GetApiKeyById(query *models.GetApiKeyByIdQuery) error {
 	var apikey models.ApiKey
 	has, err := x.Id(query.ApiKeyId).Get(&apikey)
@@ -32,6 +32,21 @@ func TestApiKeyDataAccess(t *testing.T) {
 			})
 		})
 
+		t.Run("Should record the last use of the key", func(t *testing.T) {
+			cmd := models.AddApiKeyCommand{OrgId: 1, Name: "used", Key: "asd0"}
+			err := AddApiKey(&cmd)
+			assert.Nil(t, err)
+			assert.Nil(t, cmd.Result.LastUsedAt)
+
+			err = UpdateApiKeyLastUsed(&models.UpdateApiKeyLastUsedCommand{Id: cmd.Result.Id, LastUsedAt: 1000})
+			assert.Nil(t, err)
+
+			query := models.GetApiKeyByNameQuery{KeyName: "used", OrgId: 1}
+			err = GetApiKeyByName(&query)
+			assert.Nil(t, err)
+			assert.Equal(t, int64(1000), *query.Result.LastUsedAt)
+		})
+
 		t.Run("Add non expiring key", func(t *testing.T) {
 			cmd := models.AddApiKeyCommand{OrgId: 1, Name: "non-expiring", Key: "asd1", SecondsToLive: 0}
 			err := AddApiKey(&cmd)
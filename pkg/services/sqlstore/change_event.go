@@ -0,0 +1,102 @@
+package sqlstore
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"xorm.io/xorm"
+)
+
+func init() {
+	bus.AddHandler("sql", AddChangeEvent)
+	bus.AddHandler("sql", SearchChangeEvents)
+}
+
+func AddChangeEvent(cmd *models.AddChangeEventCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		event := &models.ChangeEvent{
+			OrgId:          cmd.OrgId,
+			ResourceType:   cmd.ResourceType,
+			ResourceId:     cmd.ResourceId,
+			ResourceUid:    cmd.ResourceUid,
+			ResourceName:   cmd.ResourceName,
+			Action:         cmd.Action,
+			OldVersion:     cmd.OldVersion,
+			NewVersion:     cmd.NewVersion,
+			UserId:         cmd.UserId,
+			ImpersonatorId: cmd.ImpersonatorId,
+			Created:        time.Now(),
+		}
+		if _, err := sess.Insert(event); err != nil {
+			return err
+		}
+		cmd.Result = event
+		return nil
+	})
+}
+
+// SearchChangeEvents returns a page of the change events of an org matching the filters of the query, the newest
+// first.
+func SearchChangeEvents(query *models.SearchChangeEventsQuery) error {
+	if query.PerPage <= 0 {
+		query.PerPage = 100
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	query.Result = models.SearchChangeEventsQueryResult{
+		Events:  make([]*models.ChangeEventDTO, 0),
+		Page:    query.Page,
+		PerPage: query.PerPage,
+	}
+
+	filter := func(sess *xorm.Session) *xorm.Session {
+		sess = sess.Where("change_event.org_id = ?", query.OrgId)
+		if len(query.ResourceTypes) > 0 {
+			sess = sess.In("change_event.resource_type", query.ResourceTypes)
+		}
+		if query.ResourceUid != "" {
+			sess = sess.And("change_event.resource_uid = ?", query.ResourceUid)
+		}
+		if len(query.Actions) > 0 {
+			sess = sess.In("change_event.action", query.Actions)
+		}
+		if query.UserId != 0 {
+			sess = sess.And("change_event.user_id = ?", query.UserId)
+		}
+		if !query.From.IsZero() {
+			sess = sess.And("change_event.created >= ?", query.From)
+		}
+		if !query.To.IsZero() {
+			sess = sess.And("change_event.created <= ?", query.To)
+		}
+		return sess
+	}
+
+	count, err := filter(x.Table("change_event")).Count(&models.ChangeEvent{})
+	if err != nil {
+		return err
+	}
+	query.Result.TotalCount = count
+
+	return filter(x.Table("change_event")).
+		Select(`change_event.id,
+				change_event.resource_type,
+				change_event.resource_id,
+				change_event.resource_uid,
+				change_event.resource_name,
+				change_event.action,
+				change_event.old_version,
+				change_event.new_version,
+				change_event.user_id,
+				change_event.impersonator_id,
+				change_event.created,
+				u.login AS user_login,
+				i.login AS impersonator_login`).
+		Join("LEFT", dialect.Quote("user")+" AS u", "u.id = change_event.user_id").
+		Join("LEFT", dialect.Quote("user")+" AS i", "i.id = change_event.impersonator_id").
+		OrderBy("change_event.created DESC, change_event.id DESC").
+		Limit(query.PerPage, (query.Page-1)*query.PerPage).
+		Find(&query.Result.Events)
+}
@@ -0,0 +1,85 @@
+// +build integration
+
+package sqlstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangeEvents(t *testing.T) {
+	sqlStore := InitTestDB(t)
+
+	add := func(orgID int64, resourceType models.ChangeEventResourceType, uid string, action models.ChangeEventAction, userID int64) {
+		require.NoError(t, AddChangeEvent(&models.AddChangeEventCommand{
+			OrgId:        orgID,
+			ResourceType: resourceType,
+			ResourceUid:  uid,
+			ResourceName: uid,
+			Action:       action,
+			UserId:       userID,
+		}))
+	}
+	add(1, models.ChangeEventDashboard, "dash", models.ChangeEventCreated, 1)
+	add(1, models.ChangeEventDashboard, "dash", models.ChangeEventUpdated, 2)
+	add(1, models.ChangeEventDataSource, "ds", models.ChangeEventCreated, 1)
+	add(1, models.ChangeEventAlertRule, "rule", models.ChangeEventDeleted, 2)
+	add(2, models.ChangeEventDashboard, "dash", models.ChangeEventCreated, 1)
+
+	search := func(query models.SearchChangeEventsQuery) models.SearchChangeEventsQueryResult {
+		query.OrgId = 1
+		require.NoError(t, SearchChangeEvents(&query))
+		return query.Result
+	}
+	uids := func(result models.SearchChangeEventsQueryResult) []string {
+		uids := []string{}
+		for _, e := range result.Events {
+			uids = append(uids, e.ResourceUid+":"+string(e.Action))
+		}
+		return uids
+	}
+
+	t.Run("Returns the events of the org, the newest first", func(t *testing.T) {
+		result := search(models.SearchChangeEventsQuery{})
+		assert.Equal(t, int64(4), result.TotalCount)
+		assert.Equal(t, []string{"rule:deleted", "ds:created", "dash:updated", "dash:created"}, uids(result))
+	})
+
+	t.Run("Filters the events", func(t *testing.T) {
+		result := search(models.SearchChangeEventsQuery{ResourceTypes: []models.ChangeEventResourceType{models.ChangeEventDashboard}})
+		assert.Equal(t, []string{"dash:updated", "dash:created"}, uids(result))
+
+		result = search(models.SearchChangeEventsQuery{Actions: []models.ChangeEventAction{models.ChangeEventCreated}, UserId: 1})
+		assert.Equal(t, []string{"ds:created", "dash:created"}, uids(result))
+		assert.Equal(t, int64(2), result.TotalCount)
+
+		result = search(models.SearchChangeEventsQuery{ResourceUid: "rule"})
+		assert.Equal(t, []string{"rule:deleted"}, uids(result))
+
+		result = search(models.SearchChangeEventsQuery{From: time.Now().Add(time.Hour)})
+		assert.Empty(t, result.Events)
+		result = search(models.SearchChangeEventsQuery{To: time.Now().Add(-time.Hour)})
+		assert.Empty(t, result.Events)
+	})
+
+	t.Run("Pages the events", func(t *testing.T) {
+		result := search(models.SearchChangeEventsQuery{Page: 2, PerPage: 3})
+		assert.Equal(t, int64(4), result.TotalCount)
+		assert.Equal(t, 2, result.Page)
+		assert.Equal(t, 3, result.PerPage)
+		assert.Equal(t, []string{"dash:created"}, uids(result))
+	})
+
+	t.Run("Returns the login of the users", func(t *testing.T) {
+		user := createUser(t, sqlStore, "events", "Admin", false)
+		add(1, models.ChangeEventPermissions, "folder", models.ChangeEventUpdated, user.Id)
+
+		result := search(models.SearchChangeEventsQuery{ResourceUid: "folder"})
+		require.Len(t, result.Events, 1)
+		assert.Equal(t, "events", result.Events[0].UserLogin)
+	})
+}
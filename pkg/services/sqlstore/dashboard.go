@@ -138,6 +138,10 @@ func saveDashboard(sess *DBSession, cmd *models.SaveDashboardCommand) error {
 		Data:          dash.Data,
 	}
 
+	if err := encodeDashboardVersion(sess, dashVersion); err != nil {
+		return err
+	}
+
 	// insert version entry
 	if affectedRows, err = sess.Insert(dashVersion); err != nil {
 		return err
@@ -430,6 +434,12 @@ func deleteDashboard(cmd *models.DeleteDashboardCommand, sess *DBSession) error
 		"DELETE FROM annotation WHERE dashboard_id = ?",
 		"DELETE FROM dashboard_provisioning WHERE dashboard_id = ?",
 		"DELETE FROM dashboard_acl WHERE dashboard_id = ?",
+		"DELETE FROM comment WHERE dashboard_id = ?",
+		"DELETE FROM read_only_folder WHERE folder_id = ?",
+		"DELETE FROM dashboard_revision WHERE dashboard_id = ?",
+		"DELETE FROM dashboard_revision WHERE folder_id = ?",
+		"DELETE FROM dashboard_revision WHERE review_folder_id = ?",
+		"DELETE FROM folder_reviewer WHERE folder_id = ?",
 	}
 
 	if dashboard.IsFolder {
@@ -457,6 +467,8 @@ func deleteDashboard(cmd *models.DeleteDashboardCommand, sess *DBSession) error
 				"DELETE FROM annotation WHERE dashboard_id IN (SELECT id FROM dashboard WHERE org_id = ? AND folder_id = ?)",
 				"DELETE FROM dashboard_provisioning WHERE dashboard_id IN (SELECT id FROM dashboard WHERE org_id = ? AND folder_id = ?)",
 				"DELETE FROM dashboard_acl WHERE dashboard_id IN (SELECT id FROM dashboard WHERE org_id = ? AND folder_id = ?)",
+				"DELETE FROM comment WHERE dashboard_id IN (SELECT id FROM dashboard WHERE org_id = ? AND folder_id = ?)",
+				"DELETE FROM dashboard_revision WHERE dashboard_id IN (SELECT id FROM dashboard WHERE org_id = ? AND folder_id = ?)",
 			}
 			for _, sql := range childrenDeletes {
 				_, err := sess.Exec(sql, dashboard.OrgId, dashboard.Id)
@@ -556,7 +568,8 @@ func GetDashboardPermissionsForUser(query *models.GetDashboardPermissionsForUser
 	sql += ` AND
 	d.org_id = ? AND
 	  (
-		(d.has_acl = ?  AND (da.user_id = ? OR ugm.user_id = ? OR ou.id IS NOT NULL))
+		(d.has_acl = ?  AND (da.user_id = ? OR ugm.user_id = ? OR
+			da.external_group IN (SELECT group_id FROM user_external_group WHERE user_id = ?) OR ou.id IS NOT NULL))
 		OR (d.has_acl = ? AND ouRole.id IS NOT NULL)
 	)
 	group by d.id
@@ -565,6 +578,7 @@ func GetDashboardPermissionsForUser(query *models.GetDashboardPermissionsForUser
 	params = append(params, dialect.BooleanStr(true))
 	params = append(params, query.UserId)
 	params = append(params, query.UserId)
+	params = append(params, query.UserId)
 	params = append(params, dialect.BooleanStr(false))
 
 	err := x.SQL(sql, params...).Find(&query.Result)
@@ -21,7 +21,7 @@ func (ss *SQLStore) UpdateDashboardACL(dashboardID int64, items []*models.Dashbo
 		}
 
 		for _, item := range items {
-			if item.UserID == 0 && item.TeamID == 0 && (item.Role == nil || !item.Role.IsValid()) {
+			if item.UserID == 0 && item.TeamID == 0 && item.ExternalGroup == "" && (item.Role == nil || !item.Role.IsValid()) {
 				return models.ErrDashboardAclInfoMissing
 			}
 
@@ -29,7 +29,7 @@ func (ss *SQLStore) UpdateDashboardACL(dashboardID int64, items []*models.Dashbo
 				return models.ErrDashboardPermissionDashboardEmpty
 			}
 
-			sess.Nullable("user_id", "team_id")
+			sess.Nullable("user_id", "team_id", "external_group")
 			if _, err := sess.Insert(item); err != nil {
 				return err
 			}
@@ -59,6 +59,7 @@ func GetDashboardAclInfoList(query *models.GetDashboardAclInfoListQuery) error {
 		da.dashboard_id,
 		da.user_id,
 		da.team_id,
+		da.external_group,
 		da.permission,
 		da.role,
 		da.created,
@@ -84,6 +85,7 @@ func GetDashboardAclInfoList(query *models.GetDashboardAclInfoListQuery) error {
 				da.dashboard_id,
 				da.user_id,
 				da.team_id,
+				da.external_group,
 				da.permission,
 				da.role,
 				da.created,
@@ -11,6 +11,8 @@ import (
 func init() {
 	bus.AddHandler("sql", UnprovisionDashboard)
 	bus.AddHandler("sql", DeleteOrphanedProvisionedDashboards)
+	bus.AddHandler("sql", UpdateDashboardProvisioning)
+	bus.AddHandler("sql", GetProvisionedDashboardOverlays)
 }
 
 type DashboardExtras struct {
@@ -61,7 +63,7 @@ func saveProvisionedData(sess *DBSession, provisioning *models.DashboardProvisio
 	provisioning.DashboardId = dashboard.Id
 
 	if exist {
-		_, err = sess.ID(result.Id).Update(provisioning)
+		_, err = sess.ID(result.Id).MustCols("overlay").Update(provisioning)
 	} else {
 		_, err = sess.Insert(provisioning)
 	}
@@ -87,6 +89,26 @@ func UnprovisionDashboard(cmd *models.UnprovisionDashboardCommand) error {
 	return nil
 }
 
+// UpdateDashboardProvisioning updates the state of the provisioned dashboard after it was saved from the UI.
+func UpdateDashboardProvisioning(cmd *models.UpdateDashboardProvisioningCommand) error {
+	_, err := x.Where("dashboard_id = ?", cmd.DashboardId).Cols("check_sum", "updated", "overlay").Update(&models.DashboardProvisioning{
+		CheckSum: cmd.CheckSum,
+		Updated:  cmd.Updated,
+		Overlay:  cmd.Overlay,
+	})
+	return err
+}
+
+// GetProvisionedDashboardOverlays returns the provisioned dashboards of the organization with changes from the UI.
+func GetProvisionedDashboardOverlays(query *models.GetProvisionedDashboardOverlaysQuery) error {
+	query.Result = make([]*models.DashboardProvisioning, 0)
+	return x.Table("dashboard_provisioning").
+		Join("INNER", "dashboard", "dashboard.id = dashboard_provisioning.dashboard_id").
+		Where("dashboard.org_id = ? AND dashboard_provisioning.overlay = ?", query.OrgId, dialect.BooleanStr(true)).
+		Select("dashboard_provisioning.*").
+		Find(&query.Result)
+}
+
 func DeleteOrphanedProvisionedDashboards(cmd *models.DeleteOrphanedProvisionedDashboardsCommand) error {
 	var result []*models.DashboardProvisioning
 
@@ -134,6 +134,29 @@ func TestDashboardProvisioningTest(t *testing.T) {
 				So(err, ShouldBeNil)
 				So(data, ShouldBeNil)
 			})
+
+			Convey("Can mark provisioned dashboard as overlay", func() {
+				updateCmd := &models.UpdateDashboardProvisioningCommand{
+					DashboardId: dashId,
+					CheckSum:    "checksum",
+					Updated:     now.Unix(),
+					Overlay:     true,
+				}
+				So(UpdateDashboardProvisioning(updateCmd), ShouldBeNil)
+
+				query := &models.GetProvisionedDashboardOverlaysQuery{OrgId: 1}
+				So(GetProvisionedDashboardOverlays(query), ShouldBeNil)
+				So(len(query.Result), ShouldEqual, 1)
+				So(query.Result[0].DashboardId, ShouldEqual, dashId)
+				So(query.Result[0].CheckSum, ShouldEqual, "checksum")
+
+				updateCmd.Overlay = false
+				So(UpdateDashboardProvisioning(updateCmd), ShouldBeNil)
+
+				query = &models.GetProvisionedDashboardOverlaysQuery{OrgId: 1}
+				So(GetProvisionedDashboardOverlays(query), ShouldBeNil)
+				So(len(query.Result), ShouldEqual, 0)
+			})
 		})
 	})
 }
@@ -0,0 +1,149 @@
+package sqlstore
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func init() {
+	bus.AddHandler("sql", GetFolderReviewers)
+	bus.AddHandler("sql", SetFolderReviewers)
+	bus.AddHandler("sql", GetDashboardRevision)
+	bus.AddHandler("sql", GetDashboardRevisions)
+	bus.AddHandler("sql", CreateDashboardRevision)
+	bus.AddHandler("sql", ReviewDashboardRevision)
+}
+
+// GetFolderReviewers returns the reviewers of a folder, ordered by login.
+func GetFolderReviewers(query *models.GetFolderReviewersQuery) error {
+	query.Result = make([]*models.FolderReviewerDTO, 0)
+	return x.SQL(`SELECT folder_reviewer.user_id, u.login, u.email, u.name
+		FROM folder_reviewer
+		INNER JOIN `+dialect.Quote("user")+` AS u ON u.id = folder_reviewer.user_id
+		WHERE folder_reviewer.org_id = ? AND folder_reviewer.folder_id = ?
+		ORDER BY u.login ASC`, query.OrgId, query.FolderId).Find(&query.Result)
+}
+
+// SetFolderReviewers replaces the reviewers of a folder.
+func SetFolderReviewers(cmd *models.SetFolderReviewersCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		if _, err := sess.Exec("DELETE FROM folder_reviewer WHERE org_id = ? AND folder_id = ?", cmd.OrgId, cmd.FolderId); err != nil {
+			return err
+		}
+
+		seen := map[int64]bool{}
+		for _, userID := range cmd.UserIds {
+			if seen[userID] {
+				continue
+			}
+			seen[userID] = true
+
+			if _, err := sess.Insert(&models.FolderReviewer{
+				OrgId:    cmd.OrgId,
+				FolderId: cmd.FolderId,
+				UserId:   userID,
+				Created:  time.Now(),
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetDashboardRevision returns a revision of an org.
+func GetDashboardRevision(query *models.GetDashboardRevisionQuery) error {
+	revision := models.DashboardRevision{}
+	has, err := x.Where("org_id = ? AND id = ?", query.OrgId, query.Id).Get(&revision)
+	if err != nil {
+		return err
+	}
+	if !has {
+		return models.ErrDashboardRevisionNotFound
+	}
+	query.Result = &revision
+	return nil
+}
+
+// GetDashboardRevisions returns the revisions of an org with the status, the newest first.
+func GetDashboardRevisions(query *models.GetDashboardRevisionsQuery) error {
+	sql := `SELECT r.id, r.dashboard_id, r.dashboard_uid, r.folder_id, r.review_folder_id, r.title, r.message, r.status,
+			r.created, r.reviewed, r.review_comment, author.login AS created_by, reviewer.login AS reviewed_by
+		FROM dashboard_revision AS r
+		LEFT JOIN ` + dialect.Quote("user") + ` AS author ON author.id = r.created_by
+		LEFT JOIN ` + dialect.Quote("user") + ` AS reviewer ON reviewer.id = r.reviewed_by
+		WHERE r.org_id = ? AND r.status = ?`
+	params := []interface{}{query.OrgId, query.Status}
+	if query.UserId != 0 {
+		sql += ` AND (r.created_by = ? OR r.review_folder_id IN (
+			SELECT folder_id FROM folder_reviewer WHERE org_id = ? AND user_id = ?))`
+		params = append(params, query.UserId, query.OrgId, query.UserId)
+	}
+	sql += " ORDER BY r.created DESC, r.id DESC"
+
+	var revisions []struct {
+		models.DashboardRevisionDTO `xorm:"extends"`
+	}
+	if err := x.SQL(sql, params...).Find(&revisions); err != nil {
+		return err
+	}
+
+	query.Result = make([]*models.DashboardRevisionDTO, 0, len(revisions))
+	for i := range revisions {
+		query.Result = append(query.Result, &revisions[i].DashboardRevisionDTO)
+	}
+	return nil
+}
+
+// CreateDashboardRevision adds a pending revision.
+func CreateDashboardRevision(cmd *models.CreateDashboardRevisionCommand) error {
+	revision := &models.DashboardRevision{
+		OrgId:          cmd.OrgId,
+		DashboardId:    cmd.DashboardId,
+		DashboardUid:   cmd.DashboardUid,
+		FolderId:       cmd.FolderId,
+		ReviewFolderId: cmd.ReviewFolderId,
+		Title:          cmd.Title,
+		Data:           cmd.Data,
+		Message:        cmd.Message,
+		Status:         models.DashboardRevisionPending,
+		CreatedBy:      cmd.CreatedBy,
+		Created:        time.Now(),
+	}
+	if _, err := x.Insert(revision); err != nil {
+		return err
+	}
+	cmd.Result = revision
+	return nil
+}
+
+// ReviewDashboardRevision approves or rejects a pending revision.
+func ReviewDashboardRevision(cmd *models.ReviewDashboardRevisionCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		affected, err := sess.Where("org_id = ? AND id = ? AND status = ?", cmd.OrgId, cmd.Id, models.DashboardRevisionPending).
+			Cols("status", "reviewed_by", "reviewed", "review_comment").
+			Update(&models.DashboardRevision{
+				Status:        cmd.Status,
+				ReviewedBy:    cmd.ReviewedBy,
+				Reviewed:      time.Now(),
+				ReviewComment: cmd.Comment,
+			})
+		if err != nil {
+			return err
+		}
+		if affected > 0 {
+			return nil
+		}
+
+		exists, err := sess.Where("org_id = ? AND id = ?", cmd.OrgId, cmd.Id).Exist(&models.DashboardRevision{})
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return models.ErrDashboardRevisionNotFound
+		}
+		return models.ErrDashboardRevisionNotPending
+	})
+}
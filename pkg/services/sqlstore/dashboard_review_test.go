@@ -0,0 +1,114 @@
+// +build integration
+
+package sqlstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func TestDashboardReviews(t *testing.T) {
+	sqlStore := InitTestDB(t)
+	finance := insertTestDashboard(t, sqlStore, "finance", 1, 0, true)
+	revenue := insertTestDashboard(t, sqlStore, "revenue", 1, finance.Id, false)
+	alice := createUser(t, sqlStore, "alice", "Editor", false)
+	bob := createUser(t, sqlStore, "bob", "Editor", false)
+	carol := createUser(t, sqlStore, "carol", "Editor", false)
+
+	createRevision := func(t *testing.T, userID int64) *models.DashboardRevision {
+		t.Helper()
+		cmd := &models.CreateDashboardRevisionCommand{
+			OrgId:          1,
+			DashboardId:    revenue.Id,
+			DashboardUid:   revenue.Uid,
+			FolderId:       finance.Id,
+			ReviewFolderId: finance.Id,
+			Title:          "revenue",
+			Data:           simplejson.NewFromAny(map[string]interface{}{"title": "revenue", "uid": revenue.Uid}),
+			Message:        "add the forecast",
+			CreatedBy:      userID,
+		}
+		require.NoError(t, CreateDashboardRevision(cmd))
+		return cmd.Result
+	}
+
+	getRevisions := func(t *testing.T, status models.DashboardRevisionStatus, userID int64) []*models.DashboardRevisionDTO {
+		t.Helper()
+		query := &models.GetDashboardRevisionsQuery{OrgId: 1, Status: status, UserId: userID}
+		require.NoError(t, GetDashboardRevisions(query))
+		return query.Result
+	}
+
+	t.Run("Setting replaces the reviewers of the folder", func(t *testing.T) {
+		err := SetFolderReviewers(&models.SetFolderReviewersCommand{OrgId: 1, FolderId: finance.Id, UserIds: []int64{carol.Id}})
+		require.NoError(t, err)
+		err = SetFolderReviewers(&models.SetFolderReviewersCommand{OrgId: 1, FolderId: finance.Id, UserIds: []int64{bob.Id, bob.Id}})
+		require.NoError(t, err)
+
+		query := &models.GetFolderReviewersQuery{OrgId: 1, FolderId: finance.Id}
+		require.NoError(t, GetFolderReviewers(query))
+		require.Equal(t, []*models.FolderReviewerDTO{
+			{UserId: bob.Id, Login: "bob", Email: "bob@test.com", Name: "a bob"},
+		}, query.Result)
+	})
+
+	t.Run("Revisions are listed for their authors and reviewers", func(t *testing.T) {
+		revision := createRevision(t, alice.Id)
+
+		query := &models.GetDashboardRevisionQuery{OrgId: 1, Id: revision.Id}
+		require.NoError(t, GetDashboardRevision(query))
+		require.Equal(t, models.DashboardRevisionPending, query.Result.Status)
+		require.Equal(t, "revenue", query.Result.Data.Get("title").MustString())
+
+		for _, userID := range []int64{0, alice.Id, bob.Id} {
+			revisions := getRevisions(t, models.DashboardRevisionPending, userID)
+			require.Len(t, revisions, 1)
+			require.Equal(t, revision.Id, revisions[0].Id)
+			require.Equal(t, "alice", revisions[0].CreatedBy)
+			require.Nil(t, revisions[0].Reviewed)
+		}
+		require.Empty(t, getRevisions(t, models.DashboardRevisionPending, carol.Id))
+		require.Empty(t, getRevisions(t, models.DashboardRevisionApproved, 0))
+
+		err := GetDashboardRevision(&models.GetDashboardRevisionQuery{OrgId: 2, Id: revision.Id})
+		require.Equal(t, models.ErrDashboardRevisionNotFound, err)
+	})
+
+	t.Run("Revisions are reviewed once", func(t *testing.T) {
+		revision := createRevision(t, alice.Id)
+
+		cmd := &models.ReviewDashboardRevisionCommand{
+			OrgId:      1,
+			Id:         revision.Id,
+			Status:     models.DashboardRevisionRejected,
+			ReviewedBy: bob.Id,
+			Comment:    "the forecast is wrong",
+		}
+		require.NoError(t, ReviewDashboardRevision(cmd))
+
+		revisions := getRevisions(t, models.DashboardRevisionRejected, alice.Id)
+		require.Len(t, revisions, 1)
+		require.Equal(t, "bob", revisions[0].ReviewedBy)
+		require.Equal(t, "the forecast is wrong", revisions[0].ReviewComment)
+		require.NotNil(t, revisions[0].Reviewed)
+
+		cmd.Status = models.DashboardRevisionApproved
+		require.Equal(t, models.ErrDashboardRevisionNotPending, ReviewDashboardRevision(cmd))
+		cmd.Id = 1000
+		require.Equal(t, models.ErrDashboardRevisionNotFound, ReviewDashboardRevision(cmd))
+	})
+
+	t.Run("Deleting a folder removes its reviewers and revisions", func(t *testing.T) {
+		err := DeleteDashboard(&models.DeleteDashboardCommand{Id: finance.Id, OrgId: 1})
+		require.NoError(t, err)
+
+		query := &models.GetFolderReviewersQuery{OrgId: 1, FolderId: finance.Id}
+		require.NoError(t, GetFolderReviewers(query))
+		require.Empty(t, query.Result)
+		require.Empty(t, getRevisions(t, models.DashboardRevisionPending, 0))
+	})
+}
@@ -1,6 +1,7 @@
 package sqlstore
 
 import (
+	"context"
 	"strings"
 
 	"github.com/grafana/grafana/pkg/bus"
@@ -12,26 +13,33 @@ func init() {
 	bus.AddHandler("sql", GetDashboardVersion)
 	bus.AddHandler("sql", GetDashboardVersions)
 	bus.AddHandler("sql", DeleteExpiredVersions)
+	bus.AddHandler("sql", CompressDashboardVersions)
 }
 
 // GetDashboardVersion gets the dashboard version for the given dashboard ID and version number.
 func GetDashboardVersion(query *models.GetDashboardVersionQuery) error {
-	version := models.DashboardVersion{}
-	has, err := x.Where("dashboard_version.dashboard_id=? AND dashboard_version.version=? AND dashboard.org_id=?", query.DashboardId, query.Version, query.OrgId).
-		Join("LEFT", "dashboard", `dashboard.id = dashboard_version.dashboard_id`).
-		Get(&version)
+	return withDbSession(context.Background(), x, func(sess *DBSession) error {
+		version := models.DashboardVersion{}
+		has, err := sess.Where("dashboard_version.dashboard_id=? AND dashboard_version.version=? AND dashboard.org_id=?", query.DashboardId, query.Version, query.OrgId).
+			Join("LEFT", "dashboard", `dashboard.id = dashboard_version.dashboard_id`).
+			Get(&version)
 
-	if err != nil {
-		return err
-	}
+		if err != nil {
+			return err
+		}
 
-	if !has {
-		return models.ErrDashboardVersionNotFound
-	}
+		if !has {
+			return models.ErrDashboardVersionNotFound
+		}
 
-	version.Data.Set("id", version.DashboardId)
-	query.Result = &version
-	return nil
+		if err := decodeDashboardVersion(sess, &version); err != nil {
+			return err
+		}
+
+		version.Data.Set("id", version.DashboardId)
+		query.Result = &version
+		return nil
+	})
 }
 
 // GetDashboardVersions gets all dashboard versions for the given dashboard ID.
@@ -48,8 +56,7 @@ func GetDashboardVersions(query *models.GetDashboardVersionsQuery) error {
 				dashboard_version.version,
 				dashboard_version.created,
 				dashboard_version.created_by as created_by_id,
-				dashboard_version.message,
-				dashboard_version.data,`+
+				dashboard_version.message,`+
 			dialect.Quote("user")+`.login as created_by`).
 		Join("LEFT", dialect.Quote("user"), `dashboard_version.created_by = `+dialect.Quote("user")+`.id`).
 		Join("LEFT", "dashboard", `dashboard.id = dashboard_version.dashboard_id`).
@@ -88,6 +95,7 @@ func deleteExpiredVersions(cmd *models.DeleteExpiredVersionsCommand, perBatch in
 			// min_version_to_keep = min_version + (versions_count - versions_to_keep)
 			// where version stats is processed for each dashboard. This guarantees that we keep at least versions_to_keep
 			// versions, but in some cases (when versions are sparse) this number may be more.
+			// Snapshots that compressed deltas are still based on are kept until their deltas are gone.
 			versionIdsToDeleteQuery := `SELECT id
 				FROM dashboard_version, (
 					SELECT dashboard_id, count(version) as count, min(version) as min
@@ -96,6 +104,11 @@ func deleteExpiredVersions(cmd *models.DeleteExpiredVersionsCommand, perBatch in
 				) AS vtd
 				WHERE dashboard_version.dashboard_id=vtd.dashboard_id
 				AND version < vtd.min + vtd.count - ?
+				AND NOT EXISTS (
+					SELECT 1 FROM dashboard_version AS dv
+					WHERE dv.dashboard_id=dashboard_version.dashboard_id
+					AND dv.base_version=dashboard_version.version
+				)
 				LIMIT ?`
 
 			var versionIdsToDelete []interface{}
@@ -132,3 +145,51 @@ func deleteExpiredVersions(cmd *models.DeleteExpiredVersionsCommand, perBatch in
 
 	return nil
 }
+
+const COMPRESS_VERSIONS_BATCH_SIZE = 100
+
+// CompressDashboardVersions converts a batch of uncompressed dashboard versions, written before
+// compression was enabled, into compressed snapshots. Each batch is committed on its own so the
+// conversion can be interrupted and resumed at any time.
+func CompressDashboardVersions(cmd *models.CompressDashboardVersionsCommand) error {
+	if !setting.DashboardVersionsCompression {
+		return nil
+	}
+
+	batchSize := cmd.BatchSize
+	if batchSize < 1 {
+		batchSize = COMPRESS_VERSIONS_BATCH_SIZE
+	}
+
+	return inTransaction(func(sess *DBSession) error {
+		var versions []*models.DashboardVersion
+		err := sess.Where("data_encoding IS NULL OR data_encoding = ''").
+			OrderBy("id").
+			Limit(batchSize).
+			Find(&versions)
+		if err != nil {
+			return err
+		}
+
+		for _, version := range versions {
+			full, err := version.Data.Encode()
+			if err != nil {
+				return err
+			}
+
+			compressed, err := gzipBytes(full)
+			if err != nil {
+				return err
+			}
+
+			_, err = sess.Exec("UPDATE dashboard_version SET data = ?, data_encoding = ?, data_compressed = ? WHERE id = ?",
+				"{}", dashboardVersionEncodingGzip, compressed, version.Id)
+			if err != nil {
+				return err
+			}
+			cmd.CompressedRows++
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,275 @@
+package sqlstore
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// dashboardVersionEncodingGzip marks a version stored as a gzipped snapshot of the full dashboard JSON.
+	dashboardVersionEncodingGzip = "gzip"
+	// dashboardVersionEncodingGzipDelta marks a version stored as a gzipped JSON merge patch (RFC 7386)
+	// against the snapshot referenced by base_version.
+	dashboardVersionEncodingGzipDelta = "gzip-delta"
+)
+
+// encodeDashboardVersion prepares a dashboard version for storage. When compression is enabled the data
+// is either stored as a delta against the snapshot the parent version is based on, or, when the delta
+// wouldn't save enough space, as a new full snapshot.
+func encodeDashboardVersion(sess *DBSession, version *models.DashboardVersion) error {
+	if !setting.DashboardVersionsCompression {
+		return nil
+	}
+
+	full, err := version.Data.Encode()
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := gzipBytes(full)
+	if err != nil {
+		return err
+	}
+
+	version.DataEncoding = dashboardVersionEncodingGzip
+	version.BaseVersion = 0
+	version.DataCompressed = snapshot
+
+	baseVersion, base, err := getDashboardVersionBase(sess, version.DashboardId, version.ParentVersion)
+	if err != nil {
+		return err
+	}
+
+	if base != nil {
+		delta, err := createDashboardVersionDelta(base, full)
+		if err != nil {
+			return err
+		}
+
+		// only keep the delta if it actually pays off, otherwise start a new snapshot
+		if delta != nil && len(delta) < len(snapshot)/2 {
+			version.DataEncoding = dashboardVersionEncodingGzipDelta
+			version.BaseVersion = baseVersion
+			version.DataCompressed = delta
+		}
+	}
+
+	// the data column is kept non-null, the actual content lives in data_compressed
+	version.Data = simplejson.New()
+	return nil
+}
+
+// decodeDashboardVersion restores the dashboard JSON of a version read from the database.
+func decodeDashboardVersion(sess *DBSession, version *models.DashboardVersion) error {
+	switch version.DataEncoding {
+	case "":
+		return nil
+	case dashboardVersionEncodingGzip:
+		full, err := gunzipBytes(version.DataCompressed)
+		if err != nil {
+			return err
+		}
+		data, err := simplejson.NewJson(full)
+		if err != nil {
+			return err
+		}
+		version.Data = data
+	case dashboardVersionEncodingGzipDelta:
+		base, err := getDashboardVersionSnapshot(sess, version.DashboardId, version.BaseVersion)
+		if err != nil {
+			return err
+		}
+		if base == nil {
+			return fmt.Errorf("base version %d of dashboard version %d is missing", version.BaseVersion, version.Version)
+		}
+
+		patchJSON, err := gunzipBytes(version.DataCompressed)
+		if err != nil {
+			return err
+		}
+		patch, err := unmarshalDashboardVersionJSON(patchJSON)
+		if err != nil {
+			return err
+		}
+		version.Data = simplejson.NewFromAny(applyMergePatch(base, patch))
+	default:
+		return fmt.Errorf("unknown dashboard version encoding %q", version.DataEncoding)
+	}
+
+	version.DataEncoding = ""
+	version.BaseVersion = 0
+	version.DataCompressed = nil
+	return nil
+}
+
+// getDashboardVersionBase returns the snapshot a new delta can be based on, which is the parent version
+// itself unless the parent is a delta, in which case it's the parent's base.
+func getDashboardVersionBase(sess *DBSession, dashboardID int64, parentVersion int) (int, interface{}, error) {
+	if parentVersion < 1 {
+		return 0, nil, nil
+	}
+
+	parent := models.DashboardVersion{}
+	has, err := sess.Where("dashboard_id=? AND version=?", dashboardID, parentVersion).Get(&parent)
+	if err != nil || !has {
+		return 0, nil, err
+	}
+
+	if parent.DataEncoding == dashboardVersionEncodingGzipDelta {
+		base, err := getDashboardVersionSnapshot(sess, dashboardID, parent.BaseVersion)
+		return parent.BaseVersion, base, err
+	}
+
+	base, err := dashboardVersionSnapshotData(&parent)
+	return parentVersion, base, err
+}
+
+// getDashboardVersionSnapshot loads a version that isn't a delta, returning nil if it doesn't exist.
+func getDashboardVersionSnapshot(sess *DBSession, dashboardID int64, version int) (interface{}, error) {
+	snapshot := models.DashboardVersion{}
+	has, err := sess.Where("dashboard_id=? AND version=?", dashboardID, version).Get(&snapshot)
+	if err != nil || !has {
+		return nil, err
+	}
+
+	return dashboardVersionSnapshotData(&snapshot)
+}
+
+func dashboardVersionSnapshotData(snapshot *models.DashboardVersion) (interface{}, error) {
+	switch snapshot.DataEncoding {
+	case "":
+		full, err := snapshot.Data.Encode()
+		if err != nil {
+			return nil, err
+		}
+		return unmarshalDashboardVersionJSON(full)
+	case dashboardVersionEncodingGzip:
+		full, err := gunzipBytes(snapshot.DataCompressed)
+		if err != nil {
+			return nil, err
+		}
+		return unmarshalDashboardVersionJSON(full)
+	default:
+		return nil, fmt.Errorf("dashboard version %d is not a snapshot", snapshot.Version)
+	}
+}
+
+// createDashboardVersionDelta returns the gzipped merge patch turning base into full, or nil if the
+// patch can't represent the change exactly (merge patches can't express explicit null values).
+func createDashboardVersionDelta(base interface{}, full []byte) ([]byte, error) {
+	modified, err := unmarshalDashboardVersionJSON(full)
+	if err != nil {
+		return nil, err
+	}
+
+	patch := createMergePatch(base, modified)
+	if !reflect.DeepEqual(applyMergePatch(base, patch), modified) {
+		return nil, nil
+	}
+
+	patchJSON, err := json.Marshal(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	return gzipBytes(patchJSON)
+}
+
+func createMergePatch(original, modified interface{}) interface{} {
+	originalMap, ok := original.(map[string]interface{})
+	if !ok {
+		return modified
+	}
+	modifiedMap, ok := modified.(map[string]interface{})
+	if !ok {
+		return modified
+	}
+
+	patch := make(map[string]interface{})
+	for key := range originalMap {
+		if _, exists := modifiedMap[key]; !exists {
+			patch[key] = nil
+		}
+	}
+
+	for key, value := range modifiedMap {
+		originalValue, exists := originalMap[key]
+		if exists && reflect.DeepEqual(originalValue, value) {
+			continue
+		}
+		if exists {
+			patch[key] = createMergePatch(originalValue, value)
+		} else {
+			patch[key] = value
+		}
+	}
+
+	return patch
+}
+
+func applyMergePatch(target, patch interface{}) interface{} {
+	patchMap, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	result := make(map[string]interface{})
+	if targetMap, ok := target.(map[string]interface{}); ok {
+		for key, value := range targetMap {
+			result[key] = value
+		}
+	}
+
+	for key, value := range patchMap {
+		if value == nil {
+			delete(result, key)
+			continue
+		}
+		result[key] = applyMergePatch(result[key], value)
+	}
+
+	return result
+}
+
+func unmarshalDashboardVersionJSON(data []byte) (interface{}, error) {
+	var result interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func gunzipBytes(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := r.Close(); err != nil {
+			sqlog.Warn("Failed to close gzip reader", "error", err)
+		}
+	}()
+	return ioutil.ReadAll(r)
+}
@@ -172,3 +172,93 @@ func TestDeleteExpiredVersions(t *testing.T) {
 		})
 	})
 }
+
+func TestCompressedDashboardVersions(t *testing.T) {
+	Convey("Testing compressed dashboard versions", t, func() {
+		sqlStore := InitTestDB(t)
+		setting.DashboardVersionsCompression = true
+		Reset(func() {
+			setting.DashboardVersionsCompression = false
+			setting.DashboardVersionsToKeep = 20
+		})
+
+		panels := make([]interface{}, 0)
+		for i := 0; i < 20; i++ {
+			panels = append(panels, map[string]interface{}{
+				"id":    i,
+				"title": "panel title that repeats itself",
+				"type":  "timeseries",
+			})
+		}
+
+		savedDash := insertTestDashboard(t, sqlStore, "test dash compressed", 1, 0, false, "compressed")
+		expected := map[int]map[string]interface{}{}
+		for i := 0; i < 5; i++ {
+			data := map[string]interface{}{
+				"title":  "test dash compressed",
+				"panels": panels,
+				"step":   i,
+			}
+			if i == 3 {
+				data["nested"] = map[string]interface{}{"value": nil}
+			}
+			updateTestDashboard(t, sqlStore, savedDash, data)
+			expected[savedDash.Version+i+1] = data
+		}
+
+		Convey("Versions are stored compressed", func() {
+			var stored []*models.DashboardVersion
+			err := x.Where("dashboard_id=?", savedDash.Id).Find(&stored)
+			So(err, ShouldBeNil)
+
+			deltas := 0
+			for _, version := range stored {
+				So(version.DataEncoding, ShouldNotBeEmpty)
+				if version.DataEncoding == dashboardVersionEncodingGzipDelta {
+					deltas++
+				}
+			}
+			So(deltas, ShouldBeGreaterThan, 0)
+		})
+
+		Convey("Versions are decompressed on read", func() {
+			for version, data := range expected {
+				query := models.GetDashboardVersionQuery{DashboardId: savedDash.Id, Version: version, OrgId: 1}
+				err := GetDashboardVersion(&query)
+				So(err, ShouldBeNil)
+				So(query.Result.Data.Get("step").MustInt(-1), ShouldEqual, data["step"])
+				So(len(query.Result.Data.Get("panels").MustArray()), ShouldEqual, len(panels))
+				_, hasNested := query.Result.Data.CheckGet("nested")
+				So(hasNested, ShouldEqual, data["nested"] != nil)
+			}
+		})
+
+		Convey("Snapshots still referenced by deltas are not deleted", func() {
+			setting.DashboardVersionsToKeep = 1
+			err := DeleteExpiredVersions(&models.DeleteExpiredVersionsCommand{})
+			So(err, ShouldBeNil)
+
+			latest := savedDash.Version + len(expected)
+			query := models.GetDashboardVersionQuery{DashboardId: savedDash.Id, Version: latest, OrgId: 1}
+			err = GetDashboardVersion(&query)
+			So(err, ShouldBeNil)
+			So(query.Result.Data.Get("step").MustInt(-1), ShouldEqual, expected[latest]["step"])
+		})
+
+		Convey("Legacy versions are compressed in batches", func() {
+			setting.DashboardVersionsCompression = false
+			legacyDash := insertTestDashboard(t, sqlStore, "test dash legacy", 1, 0, false, "legacy")
+			setting.DashboardVersionsCompression = true
+
+			cmd := models.CompressDashboardVersionsCommand{BatchSize: 10}
+			err := CompressDashboardVersions(&cmd)
+			So(err, ShouldBeNil)
+			So(cmd.CompressedRows, ShouldEqual, 1)
+
+			query := models.GetDashboardVersionQuery{DashboardId: legacyDash.Id, Version: legacyDash.Version, OrgId: 1}
+			err = GetDashboardVersion(&query)
+			So(err, ShouldBeNil)
+			So(query.Result.Data.Get("title").MustString(), ShouldEqual, "test dash legacy")
+		})
+	})
+}
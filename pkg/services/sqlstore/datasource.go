@@ -20,6 +20,7 @@ import (
 func init() {
 	bus.AddHandler("sql", GetDataSources)
 	bus.AddHandler("sql", GetDataSourcesByType)
+	bus.AddHandler("sql", GetAllDataSources)
 	bus.AddHandler("sql", GetDataSource)
 	bus.AddHandler("sql", AddDataSource)
 	bus.AddHandler("sql", DeleteDataSource)
@@ -78,6 +79,12 @@ func GetDataSources(query *models.GetDataSourcesQuery) error {
 	return sess.Find(&query.Result)
 }
 
+// GetAllDataSources returns the datasources of all orgs
+func GetAllDataSources(query *models.GetAllDataSourcesQuery) error {
+	query.Result = make([]*models.DataSource, 0)
+	return x.Asc("id").Find(&query.Result)
+}
+
 // GetDataSourcesByType returns all datasources for a given type or an error if the specified type is an empty string
 func GetDataSourcesByType(query *models.GetDataSourcesByTypeQuery) error {
 	if query.Type == "" {
@@ -122,30 +129,50 @@ func (ss *SQLStore) DeleteDataSource(uid string, id int64, name string, orgID in
 // DeleteDataSource removes a datasource by org_id as well as either uid (preferred), id, or name
 // and is added to the bus.
 func DeleteDataSource(cmd *models.DeleteDataSourceCommand) error {
-	params := make([]interface{}, 0)
-
-	makeQuery := func(sql string, p ...interface{}) {
-		params = append(params, sql)
-		params = append(params, p...)
-	}
+	var where string
+	var args []interface{}
 
 	switch {
 	case cmd.OrgID == 0:
 		return models.ErrDataSourceIdentifierNotSet
 	case cmd.UID != "":
-		makeQuery("DELETE FROM data_source WHERE uid=? and org_id=?", cmd.UID, cmd.OrgID)
+		where, args = "uid=? and org_id=?", []interface{}{cmd.UID, cmd.OrgID}
 	case cmd.ID != 0:
-		makeQuery("DELETE FROM data_source WHERE id=? and org_id=?", cmd.ID, cmd.OrgID)
+		where, args = "id=? and org_id=?", []interface{}{cmd.ID, cmd.OrgID}
 	case cmd.Name != "":
-		makeQuery("DELETE FROM data_source WHERE name=? and org_id=?", cmd.Name, cmd.OrgID)
+		where, args = "name=? and org_id=?", []interface{}{cmd.Name, cmd.OrgID}
 	default:
 		return models.ErrDataSourceIdentifierNotSet
 	}
 
 	return inTransaction(func(sess *DBSession) error {
-		result, err := sess.Exec(params...)
+		// the deleted data sources are read for their change events
+		var deleted []*models.DataSource
+		if err := sess.Where(where, args...).Find(&deleted); err != nil {
+			return err
+		}
+
+		result, err := sess.Exec(append([]interface{}{"DELETE FROM data_source WHERE " + where}, args...)...)
+		if err != nil {
+			return err
+		}
 		cmd.DeletedDatasourcesCount, _ = result.RowsAffected()
-		return err
+
+		if cmd.DeletedDatasourcesCount > 0 {
+			if _, err := sess.Exec("DELETE FROM data_source_version WHERE org_id = ? AND data_source_id NOT IN (SELECT id FROM data_source WHERE org_id = ?)", cmd.OrgID, cmd.OrgID); err != nil {
+				return err
+			}
+			if _, err := sess.Exec("DELETE FROM data_source_explore_permission WHERE org_id = ? AND datasource_id NOT IN (SELECT id FROM data_source WHERE org_id = ?)", cmd.OrgID, cmd.OrgID); err != nil {
+				return err
+			}
+			if _, err := sess.Exec("DELETE FROM data_source_secret_rotation WHERE org_id = ? AND datasource_id NOT IN (SELECT id FROM data_source WHERE org_id = ?)", cmd.OrgID, cmd.OrgID); err != nil {
+				return err
+			}
+			for _, ds := range deleted {
+				sess.publishAfterCommit(&models.DataSourceChangedEvent{OrgId: ds.OrgId, Id: ds.Id, Uid: ds.Uid})
+			}
+		}
+		return nil
 	})
 }
 
@@ -202,6 +229,11 @@ func AddDataSource(cmd *models.AddDataSourceCommand) error {
 		if err := updateIsDefaultFlag(ds, sess); err != nil {
 			return err
 		}
+		if err := insertDataSourceVersion(sess, ds.OrgId, ds.Id, cmd.UserId, 0); err != nil {
+			return err
+		}
+
+		sess.publishAfterCommit(&models.DataSourceChangedEvent{OrgId: ds.OrgId, Id: ds.Id, Uid: ds.Uid})
 
 		cmd.Result = ds
 		return nil
@@ -277,10 +309,17 @@ func UpdateDataSource(cmd *models.UpdateDataSourceCommand) error {
 			return models.ErrDataSourceUpdatingOldVersion
 		}
 
-		err = updateIsDefaultFlag(ds, sess)
+		if err := updateIsDefaultFlag(ds, sess); err != nil {
+			return err
+		}
+		if err := insertDataSourceVersion(sess, ds.OrgId, ds.Id, cmd.UserId, cmd.RestoredFrom); err != nil {
+			return err
+		}
+
+		sess.publishAfterCommit(&models.DataSourceChangedEvent{OrgId: ds.OrgId, Id: ds.Id, Uid: ds.Uid})
 
 		cmd.Result = ds
-		return err
+		return nil
 	})
 }
 
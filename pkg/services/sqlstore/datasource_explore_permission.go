@@ -0,0 +1,82 @@
+package sqlstore
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func init() {
+	bus.AddHandler("sql", GetDataSourceExplorePermissions)
+	bus.AddHandler("sql", CanExploreDataSource)
+	bus.AddHandler("sql", UpdateDataSourceExplorePermissions)
+}
+
+// GetDataSourceExplorePermissions returns the explore permissions of a data source, the permissions of the users
+// first, then of the teams and the roles.
+func GetDataSourceExplorePermissions(query *models.GetDataSourceExplorePermissionsQuery) error {
+	query.Result = make([]*models.DataSourceExplorePermissionDTO, 0)
+	return x.SQL(`SELECT
+			p.user_id, u.login AS user_login, p.team_id, team.name AS team, p.role, p.created
+		FROM data_source_explore_permission AS p
+		LEFT JOIN `+dialect.Quote("user")+` AS u ON u.id = p.user_id
+		LEFT JOIN team ON team.id = p.team_id
+		WHERE p.org_id = ? AND p.datasource_id = ?
+		ORDER BY p.user_id DESC, p.team_id DESC, p.role ASC`, query.OrgId, query.DatasourceId).Find(&query.Result)
+}
+
+// CanExploreDataSource returns whether a user may explore a data source: the org admins and, when the data source
+// has explore permissions, the users they are granted to, or else any user.
+func CanExploreDataSource(query *models.CanExploreDataSourceQuery) error {
+	if query.User.OrgRole == models.ROLE_ADMIN {
+		query.Result = true
+		return nil
+	}
+
+	var permissions []*models.DataSourceExplorePermission
+	if err := x.Where("org_id = ? AND datasource_id = ?", query.OrgId, query.DatasourceId).Find(&permissions); err != nil {
+		return err
+	}
+	if len(permissions) == 0 {
+		query.Result = true
+		return nil
+	}
+
+	var teamIDs []int64
+	if query.User.UserId > 0 {
+		if err := x.Table("team_member").Where("org_id = ? AND user_id = ?", query.OrgId, query.User.UserId).
+			Cols("team_id").Find(&teamIDs); err != nil {
+			return err
+		}
+	}
+	query.Result = false
+	for _, permission := range permissions {
+		if permission.Matches(query.User, teamIDs) {
+			query.Result = true
+			break
+		}
+	}
+	return nil
+}
+
+// UpdateDataSourceExplorePermissions replaces the explore permissions of a data source.
+func UpdateDataSourceExplorePermissions(cmd *models.UpdateDataSourceExplorePermissionsCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		if _, err := sess.Exec("DELETE FROM data_source_explore_permission WHERE org_id = ? AND datasource_id = ?",
+			cmd.OrgId, cmd.DatasourceId); err != nil {
+			return err
+		}
+
+		for _, item := range cmd.Items {
+			item.Id = 0
+			item.OrgId = cmd.OrgId
+			item.DatasourceId = cmd.DatasourceId
+			item.Created = time.Now()
+			if _, err := sess.Insert(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
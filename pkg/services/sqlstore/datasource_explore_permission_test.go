@@ -0,0 +1,88 @@
+// +build integration
+
+package sqlstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func TestDataSourceExplorePermissions(t *testing.T) {
+	sqlStore := InitTestDB(t)
+
+	addDS := models.AddDataSourceCommand{OrgId: 1, Name: "finance", Type: models.DS_MYSQL, Access: models.DS_ACCESS_PROXY}
+	require.NoError(t, AddDataSource(&addDS))
+	ds := addDS.Result
+
+	analyst, err := sqlStore.CreateUser(context.Background(), models.CreateUserCommand{Login: "analyst", OrgId: 1})
+	require.NoError(t, err)
+	member, err := sqlStore.CreateUser(context.Background(), models.CreateUserCommand{Login: "member", OrgId: 1})
+	require.NoError(t, err)
+	other, err := sqlStore.CreateUser(context.Background(), models.CreateUserCommand{Login: "other", OrgId: 1})
+	require.NoError(t, err)
+	team, err := sqlStore.CreateTeam("finance", "", 1)
+	require.NoError(t, err)
+	require.NoError(t, sqlStore.AddTeamMember(member.Id, 1, team.Id, false, 0))
+
+	canExplore := func(t *testing.T, user *models.User, role models.RoleType) bool {
+		t.Helper()
+		query := &models.CanExploreDataSourceQuery{
+			OrgId:        1,
+			DatasourceId: ds.Id,
+			User:         &models.SignedInUser{OrgId: 1, UserId: user.Id, OrgRole: role},
+		}
+		require.NoError(t, CanExploreDataSource(query))
+		return query.Result
+	}
+
+	t.Run("Any user can explore the data sources without explore permissions", func(t *testing.T) {
+		assert.True(t, canExplore(t, other, models.ROLE_VIEWER))
+	})
+
+	t.Run("Only the granted users can explore the data sources with explore permissions", func(t *testing.T) {
+		editor := models.ROLE_EDITOR
+		err := UpdateDataSourceExplorePermissions(&models.UpdateDataSourceExplorePermissionsCommand{
+			OrgId:        1,
+			DatasourceId: ds.Id,
+			Items: []*models.DataSourceExplorePermission{
+				{UserId: analyst.Id}, {TeamId: team.Id}, {Role: &editor},
+			},
+		})
+		require.NoError(t, err)
+
+		query := &models.GetDataSourceExplorePermissionsQuery{OrgId: 1, DatasourceId: ds.Id}
+		require.NoError(t, GetDataSourceExplorePermissions(query))
+		require.Len(t, query.Result, 3)
+		assert.Equal(t, "analyst", query.Result[0].UserLogin)
+		assert.Equal(t, "finance", query.Result[1].Team)
+		assert.Equal(t, &editor, query.Result[2].Role)
+
+		assert.True(t, canExplore(t, analyst, models.ROLE_VIEWER))
+		assert.True(t, canExplore(t, member, models.ROLE_VIEWER))
+		assert.True(t, canExplore(t, other, models.ROLE_EDITOR))
+		assert.True(t, canExplore(t, other, models.ROLE_ADMIN))
+		assert.False(t, canExplore(t, other, models.ROLE_VIEWER))
+	})
+
+	t.Run("Deleting a team removes its explore permissions", func(t *testing.T) {
+		require.NoError(t, DeleteTeam(&models.DeleteTeamCommand{OrgId: 1, Id: team.Id}))
+
+		query := &models.GetDataSourceExplorePermissionsQuery{OrgId: 1, DatasourceId: ds.Id}
+		require.NoError(t, GetDataSourceExplorePermissions(query))
+		assert.Len(t, query.Result, 2)
+		assert.False(t, canExplore(t, member, models.ROLE_VIEWER))
+	})
+
+	t.Run("Deleting the data source removes its explore permissions", func(t *testing.T) {
+		require.NoError(t, DeleteDataSource(&models.DeleteDataSourceCommand{ID: ds.Id, OrgID: 1}))
+
+		query := &models.GetDataSourceExplorePermissionsQuery{OrgId: 1, DatasourceId: ds.Id}
+		require.NoError(t, GetDataSourceExplorePermissions(query))
+		assert.Empty(t, query.Result)
+	})
+}
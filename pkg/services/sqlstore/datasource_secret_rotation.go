@@ -0,0 +1,268 @@
+package sqlstore
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/securejsondata"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func init() {
+	bus.AddHandler("sql", GetDataSourceSecretRotation)
+	bus.AddHandler("sql", StageDataSourceSecrets)
+	bus.AddHandler("sql", DiscardDataSourceSecrets)
+	bus.AddHandler("sql", SetDataSourceSecretsValidated)
+	bus.AddHandler("sql", PromoteDataSourceSecrets)
+	bus.AddHandler("sql", RollbackDataSourceSecrets)
+	bus.AddHandler("sql", DeleteExpiredDataSourceSecrets)
+}
+
+// GetDataSourceSecretRotation returns the rotation of the secrets of a data source.
+func GetDataSourceSecretRotation(query *models.GetDataSourceSecretRotationQuery) error {
+	rotation, _, err := getDataSourceSecretRotation(newSession(), query.OrgId, query.DatasourceId)
+	query.Result = rotation
+	return err
+}
+
+// StageDataSourceSecrets stages secrets of a data source, replacing the staged ones and their validation.
+func StageDataSourceSecrets(cmd *models.StageDataSourceSecretsCommand) error {
+	if len(cmd.Secrets) == 0 {
+		return models.ErrDataSourceSecretsRequired
+	}
+
+	return inTransaction(func(sess *DBSession) error {
+		rotation, has, err := getDataSourceSecretRotation(sess, cmd.OrgId, cmd.DatasourceId)
+		if err != nil {
+			return err
+		}
+
+		rotation.StagedSecrets = securejsondata.GetEncryptedJsonData(cmd.Secrets)
+		rotation.Revision++
+		rotation.Staged = time.Now()
+		rotation.StagedBy = cmd.UserId
+		rotation.Validated = false
+		if has {
+			_, err = sess.ID(rotation.Id).Cols("staged_secrets", "revision", "staged", "staged_by", "validated").
+				Update(rotation)
+		} else {
+			_, err = sess.Insert(rotation)
+		}
+		if err != nil {
+			return err
+		}
+
+		cmd.Result = rotation
+		return nil
+	})
+}
+
+// DiscardDataSourceSecrets discards the staged secrets of a data source.
+func DiscardDataSourceSecrets(cmd *models.DiscardDataSourceSecretsCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		rotation, _, err := getDataSourceSecretRotation(sess, cmd.OrgId, cmd.DatasourceId)
+		if err != nil {
+			return err
+		}
+		if len(rotation.StagedSecrets) == 0 {
+			return models.ErrDataSourceSecretsNotStaged
+		}
+
+		_, err = sess.Exec("UPDATE data_source_secret_rotation SET staged_secrets = NULL, validated = ? WHERE id = ?",
+			false, rotation.Id)
+		return err
+	})
+}
+
+// SetDataSourceSecretsValidated records the validation of a revision of the staged secrets of a data source.
+func SetDataSourceSecretsValidated(cmd *models.SetDataSourceSecretsValidatedCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		result, err := sess.Exec(`UPDATE data_source_secret_rotation SET validated = ?
+			WHERE org_id = ? AND datasource_id = ? AND revision = ? AND staged_secrets IS NOT NULL`,
+			cmd.Validated, cmd.OrgId, cmd.DatasourceId, cmd.Revision)
+		if err != nil {
+			return err
+		}
+		if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+			return models.ErrDataSourceSecretsStagedChanged
+		}
+		return nil
+	})
+}
+
+// PromoteDataSourceSecrets replaces the secrets of a data source by its validated staged secrets in a transaction, so
+// that the data source is never left with a part of them, and keeps the replaced secrets until the grace period
+// expires. The deprecated password fields are cleared when their secret is promoted.
+func PromoteDataSourceSecrets(cmd *models.PromoteDataSourceSecretsCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		rotation, _, err := getDataSourceSecretRotation(sess, cmd.OrgId, cmd.DatasourceId)
+		if err != nil {
+			return err
+		}
+		if len(rotation.StagedSecrets) == 0 {
+			return models.ErrDataSourceSecretsNotStaged
+		}
+		if !rotation.Validated {
+			return models.ErrDataSourceSecretsNotValidated
+		}
+
+		ds := &models.DataSource{}
+		has, err := sess.Where("org_id = ? AND id = ?", cmd.OrgId, cmd.DatasourceId).Get(ds)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return models.ErrDataSourceNotFound
+		}
+
+		secrets := make(securejsondata.SecureJsonData, len(ds.SecureJsonData)+len(rotation.StagedSecrets))
+		for key, value := range ds.SecureJsonData {
+			secrets[key] = value
+		}
+		previous := make(map[string]string, len(rotation.StagedSecrets))
+		for key, value := range rotation.StagedSecrets {
+			if current, ok := ds.SecureJsonData.DecryptedValue(key); ok {
+				previous[key] = current
+			} else {
+				previous[key] = legacyDataSourceSecret(ds, key)
+			}
+			secrets[key] = value
+		}
+		if _, ok := rotation.StagedSecrets["password"]; ok {
+			ds.Password = ""
+		}
+		if _, ok := rotation.StagedSecrets["basicAuthPassword"]; ok {
+			ds.BasicAuthPassword = ""
+		}
+		if err := updateDataSourceSecrets(sess, ds, secrets, cmd.UserId); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		rotation.PreviousSecrets = securejsondata.GetEncryptedJsonData(previous)
+		rotation.Validated = false
+		rotation.Promoted = now
+		rotation.PromotedBy = cmd.UserId
+		rotation.GraceExpires = now.Add(cmd.GracePeriod)
+		if _, err := sess.ID(rotation.Id).Cols("previous_secrets", "validated", "promoted", "promoted_by", "grace_expires").
+			Update(rotation); err != nil {
+			return err
+		}
+		if _, err := sess.Exec("UPDATE data_source_secret_rotation SET staged_secrets = NULL WHERE id = ?", rotation.Id); err != nil {
+			return err
+		}
+
+		cmd.Result = ds
+		return nil
+	})
+}
+
+// RollbackDataSourceSecrets restores the secrets of a data source replaced by the latest promotion, during their
+// grace period. The secrets which weren't set before the promotion are removed.
+func RollbackDataSourceSecrets(cmd *models.RollbackDataSourceSecretsCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		rotation, _, err := getDataSourceSecretRotation(sess, cmd.OrgId, cmd.DatasourceId)
+		if err != nil {
+			return err
+		}
+		if !rotation.HasPrevious(time.Now()) {
+			return models.ErrDataSourceSecretsNoPrevious
+		}
+
+		ds := &models.DataSource{}
+		has, err := sess.Where("org_id = ? AND id = ?", cmd.OrgId, cmd.DatasourceId).Get(ds)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return models.ErrDataSourceNotFound
+		}
+
+		secrets := make(securejsondata.SecureJsonData, len(ds.SecureJsonData))
+		for key, value := range ds.SecureJsonData {
+			secrets[key] = value
+		}
+		for key, value := range rotation.PreviousSecrets.Decrypt() {
+			if value == "" {
+				delete(secrets, key)
+				continue
+			}
+			secrets[key] = rotation.PreviousSecrets[key]
+		}
+		if err := updateDataSourceSecrets(sess, ds, secrets, cmd.UserId); err != nil {
+			return err
+		}
+
+		if _, err := sess.Exec("UPDATE data_source_secret_rotation SET previous_secrets = NULL, grace_expires = NULL WHERE id = ?",
+			rotation.Id); err != nil {
+			return err
+		}
+
+		cmd.Result = ds
+		return nil
+	})
+}
+
+// DeleteExpiredDataSourceSecrets deletes the previous secrets of the data sources whose grace period expired.
+func DeleteExpiredDataSourceSecrets(cmd *models.DeleteExpiredDataSourceSecretsCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		result, err := sess.Exec(`UPDATE data_source_secret_rotation SET previous_secrets = NULL, grace_expires = NULL
+			WHERE grace_expires < ?`, time.Now())
+		if err != nil {
+			return err
+		}
+		cmd.DeletedRows, _ = result.RowsAffected()
+		return nil
+	})
+}
+
+// getDataSourceSecretRotation returns the rotation of the secrets of a data source and whether it's stored, or else
+// a new rotation.
+func getDataSourceSecretRotation(sess *DBSession, orgID, datasourceID int64) (*models.DataSourceSecretRotation, bool, error) {
+	rotation := &models.DataSourceSecretRotation{}
+	has, err := sess.Where("org_id = ? AND datasource_id = ?", orgID, datasourceID).Get(rotation)
+	if err != nil {
+		return nil, false, err
+	}
+	if !has {
+		rotation = &models.DataSourceSecretRotation{OrgId: orgID, DatasourceId: datasourceID}
+	}
+	return rotation, has, nil
+}
+
+// legacyDataSourceSecret returns the value of the deprecated password field of a secret, or else an empty value.
+func legacyDataSourceSecret(ds *models.DataSource, key string) string {
+	switch key {
+	case "password":
+		return ds.Password
+	case "basicAuthPassword":
+		return ds.BasicAuthPassword
+	default:
+		return ""
+	}
+}
+
+// updateDataSourceSecrets updates the secrets and the password fields of a data source as its next version, unless
+// it was updated meanwhile.
+func updateDataSourceSecrets(sess *DBSession, ds *models.DataSource, secrets securejsondata.SecureJsonData, userID int64) error {
+	version := ds.Version
+	ds.SecureJsonData = secrets
+	ds.Version++
+	ds.Updated = time.Now()
+
+	affected, err := sess.Where("id = ? AND org_id = ? AND version = ?", ds.Id, ds.OrgId, version).
+		Cols("secure_json_data", "password", "basic_auth_password", "version", "updated").
+		Update(ds)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return models.ErrDataSourceUpdatingOldVersion
+	}
+	if err := insertDataSourceVersion(sess, ds.OrgId, ds.Id, userID, 0); err != nil {
+		return err
+	}
+
+	sess.publishAfterCommit(&models.DataSourceChangedEvent{OrgId: ds.OrgId, Id: ds.Id, Uid: ds.Uid})
+	return nil
+}
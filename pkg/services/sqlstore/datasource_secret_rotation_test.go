@@ -0,0 +1,129 @@
+// +build integration
+
+package sqlstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func TestDataSourceSecretRotation(t *testing.T) {
+	InitTestDB(t)
+
+	addDS := models.AddDataSourceCommand{
+		OrgId:          1,
+		Name:           "billing",
+		Type:           models.DS_MYSQL,
+		Access:         models.DS_ACCESS_PROXY,
+		SecureJsonData: map[string]string{"password": "old", "tlsClientKey": "key"},
+	}
+	require.NoError(t, AddDataSource(&addDS))
+	ds := addDS.Result
+
+	getDS := func(t *testing.T) *models.DataSource {
+		t.Helper()
+		query := models.GetDataSourceQuery{OrgId: 1, Id: ds.Id}
+		require.NoError(t, GetDataSource(&query))
+		return query.Result
+	}
+	getRotation := func(t *testing.T) *models.DataSourceSecretRotation {
+		t.Helper()
+		query := models.GetDataSourceSecretRotationQuery{OrgId: 1, DatasourceId: ds.Id}
+		require.NoError(t, GetDataSourceSecretRotation(&query))
+		return query.Result
+	}
+	stage := func(t *testing.T, secrets map[string]string) *models.DataSourceSecretRotation {
+		t.Helper()
+		cmd := models.StageDataSourceSecretsCommand{OrgId: 1, DatasourceId: ds.Id, UserId: 2, Secrets: secrets}
+		require.NoError(t, StageDataSourceSecrets(&cmd))
+		return cmd.Result
+	}
+	promote := func(gracePeriod time.Duration) error {
+		return PromoteDataSourceSecrets(&models.PromoteDataSourceSecretsCommand{
+			OrgId:        1,
+			DatasourceId: ds.Id,
+			UserId:       2,
+			GracePeriod:  gracePeriod,
+		})
+	}
+
+	t.Run("A data source has no rotation until secrets are staged", func(t *testing.T) {
+		rotation := getRotation(t)
+		assert.Empty(t, rotation.StagedSecrets)
+		assert.False(t, rotation.HasPrevious(time.Now()))
+		assert.Equal(t, models.ErrDataSourceSecretsNotStaged, promote(time.Hour))
+	})
+
+	t.Run("Staged secrets are promoted once validated", func(t *testing.T) {
+		rotation := stage(t, map[string]string{"password": "new", "apiKey": "secret"})
+		assert.Equal(t, int64(1), rotation.Revision)
+		assert.Equal(t, models.ErrDataSourceSecretsNotValidated, promote(time.Hour))
+
+		err := SetDataSourceSecretsValidated(&models.SetDataSourceSecretsValidatedCommand{
+			OrgId: 1, DatasourceId: ds.Id, Revision: rotation.Revision + 1, Validated: true,
+		})
+		assert.Equal(t, models.ErrDataSourceSecretsStagedChanged, err)
+		require.NoError(t, SetDataSourceSecretsValidated(&models.SetDataSourceSecretsValidatedCommand{
+			OrgId: 1, DatasourceId: ds.Id, Revision: rotation.Revision, Validated: true,
+		}))
+
+		require.NoError(t, promote(time.Hour))
+		promoted := getDS(t)
+		assert.Equal(t, ds.Version+1, promoted.Version)
+		assert.Equal(t, map[string]string{"password": "new", "apiKey": "secret", "tlsClientKey": "key"},
+			promoted.SecureJsonData.Decrypt())
+
+		rotation = getRotation(t)
+		assert.Empty(t, rotation.StagedSecrets)
+		assert.False(t, rotation.Validated)
+		require.True(t, rotation.HasPrevious(time.Now()))
+		assert.Equal(t, map[string]string{"password": "old", "apiKey": ""}, rotation.PreviousSecrets.Decrypt())
+	})
+
+	t.Run("The previous secrets are restored by a rollback", func(t *testing.T) {
+		require.NoError(t, RollbackDataSourceSecrets(&models.RollbackDataSourceSecretsCommand{
+			OrgId: 1, DatasourceId: ds.Id, UserId: 2,
+		}))
+		assert.Equal(t, map[string]string{"password": "old", "tlsClientKey": "key"}, getDS(t).SecureJsonData.Decrypt())
+		assert.False(t, getRotation(t).HasPrevious(time.Now()))
+
+		err := RollbackDataSourceSecrets(&models.RollbackDataSourceSecretsCommand{OrgId: 1, DatasourceId: ds.Id})
+		assert.Equal(t, models.ErrDataSourceSecretsNoPrevious, err)
+	})
+
+	t.Run("Staged secrets can be discarded", func(t *testing.T) {
+		rotation := stage(t, map[string]string{"password": "discarded"})
+		assert.Equal(t, int64(2), rotation.Revision)
+		require.NoError(t, DiscardDataSourceSecrets(&models.DiscardDataSourceSecretsCommand{OrgId: 1, DatasourceId: ds.Id}))
+		assert.Empty(t, getRotation(t).StagedSecrets)
+
+		err := DiscardDataSourceSecrets(&models.DiscardDataSourceSecretsCommand{OrgId: 1, DatasourceId: ds.Id})
+		assert.Equal(t, models.ErrDataSourceSecretsNotStaged, err)
+	})
+
+	t.Run("The previous secrets are deleted when their grace period expired", func(t *testing.T) {
+		rotation := stage(t, map[string]string{"password": "newer"})
+		require.NoError(t, SetDataSourceSecretsValidated(&models.SetDataSourceSecretsValidatedCommand{
+			OrgId: 1, DatasourceId: ds.Id, Revision: rotation.Revision, Validated: true,
+		}))
+		require.NoError(t, promote(-time.Minute))
+
+		cmd := models.DeleteExpiredDataSourceSecretsCommand{}
+		require.NoError(t, DeleteExpiredDataSourceSecrets(&cmd))
+		assert.Equal(t, int64(1), cmd.DeletedRows)
+		assert.Empty(t, getRotation(t).PreviousSecrets)
+		assert.Equal(t, "newer", getDS(t).DecryptedPassword())
+	})
+
+	t.Run("The rotation is deleted with the data source", func(t *testing.T) {
+		require.NoError(t, DeleteDataSource(&models.DeleteDataSourceCommand{OrgID: 1, ID: ds.Id}))
+		count, err := x.Table("data_source_secret_rotation").Where("datasource_id = ?", ds.Id).Count()
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	})
+}
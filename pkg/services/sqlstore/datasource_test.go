@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"testing"
 
+	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -190,6 +191,22 @@ func TestDataAccess(t *testing.T) {
 			require.Equal(t, 0, len(query.Result))
 		})
 
+		t.Run("publishes the change of the deleted datasource", func(t *testing.T) {
+			InitTestDB(t)
+			ds := initDatasource()
+
+			var events []*models.DataSourceChangedEvent
+			bus.AddEventListener(func(event *models.DataSourceChangedEvent) error {
+				events = append(events, event)
+				return nil
+			})
+
+			err := DeleteDataSource(&models.DeleteDataSourceCommand{ID: ds.Id, OrgID: ds.OrgId})
+			require.NoError(t, err)
+
+			require.Equal(t, []*models.DataSourceChangedEvent{{OrgId: ds.OrgId, Id: ds.Id, Uid: ds.Uid}}, events)
+		})
+
 		t.Run("Can not delete datasource with wrong orgId", func(t *testing.T) {
 			InitTestDB(t)
 			ds := initDatasource()
@@ -331,6 +348,31 @@ func TestDataAccess(t *testing.T) {
 			require.Error(t, err)
 		})
 	})
+
+	t.Run("GetAllDataSources", func(t *testing.T) {
+		t.Run("Returns the datasources of all orgs", func(t *testing.T) {
+			InitTestDB(t)
+
+			for _, orgID := range []int64{10, 20} {
+				err := AddDataSource(&models.AddDataSourceCommand{
+					OrgId:  orgID,
+					Name:   "Graphite",
+					Type:   models.DS_GRAPHITE,
+					Access: models.DS_ACCESS_DIRECT,
+					Url:    "http://test",
+				})
+				require.NoError(t, err)
+			}
+
+			query := models.GetAllDataSourcesQuery{}
+			err := GetAllDataSources(&query)
+
+			require.NoError(t, err)
+			require.Equal(t, 2, len(query.Result))
+			require.Equal(t, int64(10), query.Result[0].OrgId)
+			require.Equal(t, int64(20), query.Result[1].OrgId)
+		})
+	})
 }
 
 func TestGetDefaultDataSource(t *testing.T) {
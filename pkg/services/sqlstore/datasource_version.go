@@ -0,0 +1,126 @@
+package sqlstore
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func init() {
+	bus.AddHandler("sql", GetDataSourceVersion)
+	bus.AddHandler("sql", GetDataSourceVersions)
+}
+
+// GetDataSourceVersion returns a version of a data source of an org.
+func GetDataSourceVersion(query *models.GetDataSourceVersionQuery) error {
+	version := models.DataSourceVersion{}
+	has, err := x.Where("org_id = ? AND data_source_id = ? AND version = ?", query.OrgId, query.DataSourceId, query.Version).
+		Get(&version)
+	if err != nil {
+		return err
+	}
+	if !has {
+		return models.ErrDataSourceVersionNotFound
+	}
+	query.Result = &version
+	return nil
+}
+
+// GetDataSourceVersions returns the versions of a data source of an org, the newest first.
+func GetDataSourceVersions(query *models.GetDataSourceVersionsQuery) error {
+	if query.Limit == 0 {
+		query.Limit = 1000
+	}
+
+	query.Result = make([]*models.DataSourceVersionDTO, 0)
+	return x.Table("data_source_version").
+		Select(`data_source_version.id,
+				data_source_version.data_source_id,
+				data_source_version.version,
+				data_source_version.restored_from,
+				data_source_version.created,
+				u.login AS created_by`).
+		Join("LEFT", dialect.Quote("user")+" AS u", "u.id = data_source_version.created_by").
+		Where("data_source_version.org_id = ? AND data_source_version.data_source_id = ?", query.OrgId, query.DataSourceId).
+		OrderBy("data_source_version.version DESC").
+		Limit(query.Limit, query.Start).
+		Find(&query.Result)
+}
+
+// dataSourceVersionData returns the configuration of a data source stored by its versions, without the passwords and
+// the values of the secure JSON data.
+func dataSourceVersionData(ds *models.DataSource) *simplejson.Json {
+	jsonData := ds.JsonData
+	if jsonData == nil {
+		jsonData = simplejson.New()
+	}
+	secureJsonFields := map[string]bool{}
+	for k := range ds.SecureJsonData {
+		secureJsonFields[k] = true
+	}
+
+	return simplejson.NewFromAny(map[string]interface{}{
+		"uid":              ds.Uid,
+		"name":             ds.Name,
+		"type":             ds.Type,
+		"access":           ds.Access,
+		"url":              ds.Url,
+		"user":             ds.User,
+		"database":         ds.Database,
+		"basicAuth":        ds.BasicAuth,
+		"basicAuthUser":    ds.BasicAuthUser,
+		"withCredentials":  ds.WithCredentials,
+		"isDefault":        ds.IsDefault,
+		"jsonData":         jsonData.Interface(),
+		"secureJsonFields": secureJsonFields,
+		"readOnly":         ds.ReadOnly,
+	})
+}
+
+// insertDataSourceVersion stores the configuration of a data source as its next version, unless it's the one of the
+// latest version already, e.g. when provisioned again.
+func insertDataSourceVersion(sess *DBSession, orgID, dataSourceID, userID int64, restoredFrom int) error {
+	ds := models.DataSource{}
+	has, err := sess.Where("org_id = ? AND id = ?", orgID, dataSourceID).Get(&ds)
+	if err != nil {
+		return err
+	}
+	if !has {
+		return models.ErrDataSourceNotFound
+	}
+
+	data := dataSourceVersionData(&ds)
+	encoded, err := data.Encode()
+	if err != nil {
+		return err
+	}
+
+	latest := models.DataSourceVersion{}
+	has, err = sess.Where("org_id = ? AND data_source_id = ?", orgID, dataSourceID).Desc("version").Get(&latest)
+	if err != nil {
+		return err
+	}
+	if has {
+		latestEncoded, err := latest.Data.Encode()
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(encoded, latestEncoded) {
+			return nil
+		}
+	}
+
+	_, err = sess.Insert(&models.DataSourceVersion{
+		OrgId:        orgID,
+		DataSourceId: dataSourceID,
+		Version:      latest.Version + 1,
+		RestoredFrom: restoredFrom,
+		Data:         data,
+		Created:      time.Now(),
+		CreatedBy:    userID,
+	})
+	return err
+}
@@ -0,0 +1,122 @@
+// +build integration
+
+package sqlstore
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataSourceVersions(t *testing.T) {
+	addDataSource := func(t *testing.T) *models.DataSource {
+		cmd := models.AddDataSourceCommand{
+			OrgId:          10,
+			Name:           "graphite",
+			Type:           models.DS_GRAPHITE,
+			Access:         models.DS_ACCESS_PROXY,
+			Url:            "http://test",
+			SecureJsonData: map[string]string{"token": "secret"},
+			UserId:         1,
+		}
+		require.NoError(t, AddDataSource(&cmd))
+		return cmd.Result
+	}
+
+	updateDataSource := func(t *testing.T, ds *models.DataSource, url string) {
+		cmd := models.UpdateDataSourceCommand{
+			Id:     ds.Id,
+			OrgId:  ds.OrgId,
+			Name:   ds.Name,
+			Type:   ds.Type,
+			Access: ds.Access,
+			Url:    url,
+			UserId: 2,
+		}
+		require.NoError(t, UpdateDataSource(&cmd))
+	}
+
+	getVersions := func(t *testing.T, ds *models.DataSource) []*models.DataSourceVersionDTO {
+		query := models.GetDataSourceVersionsQuery{OrgId: ds.OrgId, DataSourceId: ds.Id}
+		require.NoError(t, GetDataSourceVersions(&query))
+		return query.Result
+	}
+
+	t.Run("Adding a data source stores its first version", func(t *testing.T) {
+		InitTestDB(t)
+		ds := addDataSource(t)
+
+		query := models.GetDataSourceVersionQuery{OrgId: ds.OrgId, DataSourceId: ds.Id, Version: 1}
+		require.NoError(t, GetDataSourceVersion(&query))
+		assert.Equal(t, int64(1), query.Result.CreatedBy)
+		assert.Equal(t, "http://test", query.Result.Data.Get("url").MustString())
+		assert.Equal(t, map[string]interface{}{"token": true}, query.Result.Data.Get("secureJsonFields").MustMap())
+		assert.NotContains(t, string(mustEncode(t, query.Result.Data)), "secret")
+	})
+
+	t.Run("Updating a data source stores a version, unless it's unchanged", func(t *testing.T) {
+		InitTestDB(t)
+		ds := addDataSource(t)
+		updateDataSource(t, ds, "http://test2")
+		updateDataSource(t, ds, "http://test2")
+
+		versions := getVersions(t, ds)
+		require.Len(t, versions, 2)
+		assert.Equal(t, 2, versions[0].Version)
+		assert.Equal(t, 1, versions[1].Version)
+
+		query := models.GetDataSourceVersionQuery{OrgId: ds.OrgId, DataSourceId: ds.Id, Version: 2}
+		require.NoError(t, GetDataSourceVersion(&query))
+		assert.Equal(t, "http://test2", query.Result.Data.Get("url").MustString())
+		assert.Equal(t, int64(2), query.Result.CreatedBy)
+	})
+
+	t.Run("Restoring a version stores the version it's restored from", func(t *testing.T) {
+		InitTestDB(t)
+		ds := addDataSource(t)
+		updateDataSource(t, ds, "http://test2")
+
+		cmd := models.UpdateDataSourceCommand{
+			Id:           ds.Id,
+			OrgId:        ds.OrgId,
+			Name:         ds.Name,
+			Type:         ds.Type,
+			Access:       ds.Access,
+			Url:          "http://test",
+			RestoredFrom: 1,
+		}
+		require.NoError(t, UpdateDataSource(&cmd))
+
+		versions := getVersions(t, ds)
+		require.Len(t, versions, 3)
+		assert.Equal(t, 3, versions[0].Version)
+		assert.Equal(t, 1, versions[0].RestoredFrom)
+	})
+
+	t.Run("Getting a missing version returns an error", func(t *testing.T) {
+		InitTestDB(t)
+		ds := addDataSource(t)
+
+		query := models.GetDataSourceVersionQuery{OrgId: ds.OrgId, DataSourceId: ds.Id, Version: 2}
+		require.ErrorIs(t, GetDataSourceVersion(&query), models.ErrDataSourceVersionNotFound)
+	})
+
+	t.Run("Deleting a data source deletes its versions", func(t *testing.T) {
+		InitTestDB(t)
+		ds := addDataSource(t)
+		updateDataSource(t, ds, "http://test2")
+
+		require.NoError(t, DeleteDataSource(&models.DeleteDataSourceCommand{ID: ds.Id, OrgID: ds.OrgId}))
+		assert.Empty(t, getVersions(t, ds))
+	})
+}
+
+func mustEncode(t *testing.T, data *simplejson.Json) []byte {
+	t.Helper()
+	b, err := data.Encode()
+	require.NoError(t, err)
+	return b
+}
@@ -0,0 +1,116 @@
+package sqlstore
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"xorm.io/xorm"
+)
+
+func init() {
+	bus.AddHandler("sql", StartImpersonation)
+	bus.AddHandler("sql", GetImpersonationSession)
+	bus.AddHandler("sql", StopImpersonation)
+	bus.AddHandler("sql", SearchImpersonationSessions)
+}
+
+func StartImpersonation(cmd *models.StartImpersonationCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		session := &models.ImpersonationSession{
+			AdminUserId: cmd.AdminUserId,
+			UserId:      cmd.UserId,
+			Reason:      cmd.Reason,
+			KeyHash:     cmd.KeyHash,
+			Created:     time.Now(),
+			Expires:     cmd.Expires,
+		}
+		if _, err := sess.Insert(session); err != nil {
+			return err
+		}
+		cmd.Result = session
+		return nil
+	})
+}
+
+func GetImpersonationSession(query *models.GetImpersonationSessionQuery) error {
+	var session models.ImpersonationSession
+	exists, err := x.Where("key_hash = ?", query.KeyHash).Get(&session)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return models.ErrImpersonationSessionNotFound
+	}
+	query.Result = &session
+	return nil
+}
+
+// StopImpersonation stops an impersonation session, which expires now.
+func StopImpersonation(cmd *models.StopImpersonationCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		affected, err := sess.ID(cmd.Id).Cols("stopped", "expires").
+			Update(&models.ImpersonationSession{Stopped: true, Expires: time.Now()})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return models.ErrImpersonationSessionNotFound
+		}
+		return nil
+	})
+}
+
+// SearchImpersonationSessions returns a page of the impersonation sessions, the newest first, with the logins of
+// their admins and users.
+func SearchImpersonationSessions(query *models.SearchImpersonationSessionsQuery) error {
+	if query.PerPage <= 0 {
+		query.PerPage = 100
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	query.Result = models.SearchImpersonationSessionsQueryResult{
+		Sessions: make([]*models.ImpersonationSessionDTO, 0),
+		Page:     query.Page,
+		PerPage:  query.PerPage,
+	}
+
+	filter := func(sess *xorm.Session) *xorm.Session {
+		if query.UserId != 0 {
+			sess = sess.Where("impersonation_session.user_id = ?", query.UserId)
+		}
+		return sess
+	}
+
+	count, err := filter(x.Table("impersonation_session")).Count(&models.ImpersonationSession{})
+	if err != nil {
+		return err
+	}
+	query.Result.TotalCount = count
+
+	err = filter(x.Table("impersonation_session")).
+		Select(`impersonation_session.id,
+				impersonation_session.admin_user_id,
+				impersonation_session.user_id,
+				impersonation_session.reason,
+				impersonation_session.created,
+				impersonation_session.expires,
+				impersonation_session.stopped,
+				a.login AS admin_login,
+				u.login AS user_login`).
+		Join("LEFT", dialect.Quote("user")+" AS a", "a.id = impersonation_session.admin_user_id").
+		Join("LEFT", dialect.Quote("user")+" AS u", "u.id = impersonation_session.user_id").
+		OrderBy("impersonation_session.created DESC, impersonation_session.id DESC").
+		Limit(query.PerPage, (query.Page-1)*query.PerPage).
+		Find(&query.Result.Sessions)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, s := range query.Result.Sessions {
+		s.Active = !s.Stopped && now.Before(s.Expires)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+// +build integration
+
+package sqlstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImpersonationSessions(t *testing.T) {
+	sqlStore := InitTestDB(t)
+	admin := createUser(t, sqlStore, "admin", "Admin", true)
+	viewer := createUser(t, sqlStore, "viewer", "Viewer", false)
+
+	start := func(userID int64, keyHash string, expires time.Time) *models.ImpersonationSession {
+		cmd := models.StartImpersonationCommand{
+			AdminUserId: admin.Id,
+			UserId:      userID,
+			Reason:      "support ticket",
+			KeyHash:     keyHash,
+			Expires:     expires,
+		}
+		require.NoError(t, StartImpersonation(&cmd))
+		return cmd.Result
+	}
+
+	t.Run("Gets the sessions by the hash of their key", func(t *testing.T) {
+		started := start(viewer.Id, "hash-1", time.Now().Add(time.Hour))
+
+		query := models.GetImpersonationSessionQuery{KeyHash: "hash-1"}
+		require.NoError(t, GetImpersonationSession(&query))
+		assert.Equal(t, started.Id, query.Result.Id)
+		assert.Equal(t, viewer.Id, query.Result.UserId)
+		assert.True(t, query.Result.IsActive(time.Now()))
+
+		err := GetImpersonationSession(&models.GetImpersonationSessionQuery{KeyHash: "missing"})
+		require.Equal(t, models.ErrImpersonationSessionNotFound, err)
+	})
+
+	t.Run("Stops the sessions", func(t *testing.T) {
+		started := start(viewer.Id, "hash-2", time.Now().Add(time.Hour))
+		require.NoError(t, StopImpersonation(&models.StopImpersonationCommand{Id: started.Id}))
+
+		query := models.GetImpersonationSessionQuery{KeyHash: "hash-2"}
+		require.NoError(t, GetImpersonationSession(&query))
+		assert.True(t, query.Result.Stopped)
+		assert.False(t, query.Result.IsActive(time.Now()))
+
+		err := StopImpersonation(&models.StopImpersonationCommand{Id: -1})
+		require.Equal(t, models.ErrImpersonationSessionNotFound, err)
+	})
+
+	t.Run("Searches the sessions with the logins of the admins and users", func(t *testing.T) {
+		start(admin.Id, "hash-3", time.Now().Add(-time.Minute))
+
+		query := models.SearchImpersonationSessionsQuery{}
+		require.NoError(t, SearchImpersonationSessions(&query))
+		require.Equal(t, int64(3), query.Result.TotalCount)
+		first := query.Result.Sessions[0]
+		assert.Equal(t, "admin", first.AdminLogin)
+		assert.Equal(t, "admin", first.UserLogin)
+		assert.False(t, first.Active)
+		assert.True(t, query.Result.Sessions[2].Active)
+
+		query = models.SearchImpersonationSessionsQuery{UserId: viewer.Id, Page: 2, PerPage: 1}
+		require.NoError(t, SearchImpersonationSessions(&query))
+		assert.Equal(t, int64(2), query.Result.TotalCount)
+		require.Len(t, query.Result.Sessions, 1)
+		assert.Equal(t, "viewer", query.Result.Sessions[0].UserLogin)
+		assert.Equal(t, "support ticket", query.Result.Sessions[0].Reason)
+	})
+}
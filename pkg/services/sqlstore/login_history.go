@@ -0,0 +1,76 @@
+package sqlstore
+
+import (
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func init() {
+	bus.AddHandler("sql", CreateLoginHistoryEntry)
+	bus.AddHandler("sql", DeleteOldLoginHistory)
+	bus.AddHandler("sql", GetUserLoginHistory)
+	bus.AddHandler("sql", IsKnownLoginLocation)
+}
+
+func CreateLoginHistoryEntry(cmd *models.CreateLoginHistoryEntryCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		entry := &models.LoginHistoryEntry{
+			UserId:     cmd.UserId,
+			Login:      cmd.Login,
+			AuthModule: cmd.AuthModule,
+			Success:    cmd.Success,
+			IpAddress:  cmd.IpAddress,
+			UserAgent:  cmd.UserAgent,
+			Location:   cmd.Location,
+			Created:    getTimeNow().Unix(),
+		}
+
+		if _, err := sess.Insert(entry); err != nil {
+			return err
+		}
+
+		cmd.Result = entry
+		return nil
+	})
+}
+
+func DeleteOldLoginHistory(cmd *models.DeleteOldLoginHistoryCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		result, err := sess.Exec("DELETE FROM login_history_entry WHERE created < ?", cmd.OlderThan.Unix())
+		if err != nil {
+			return err
+		}
+		cmd.DeletedRows, err = result.RowsAffected()
+		return err
+	})
+}
+
+func GetUserLoginHistory(query *models.GetUserLoginHistoryQuery) error {
+	query.Result = make([]*models.LoginHistoryEntry, 0)
+	sess := x.Where("user_id = ?", query.UserId).Desc("id")
+	if query.Limit > 0 {
+		sess.Limit(query.Limit)
+	}
+	return sess.Find(&query.Result)
+}
+
+func IsKnownLoginLocation(query *models.IsKnownLoginLocationQuery) error {
+	logins, err := x.Where("user_id = ? AND success = ?", query.UserId, dialect.BooleanStr(true)).
+		Count(&models.LoginHistoryEntry{})
+	if err != nil {
+		return err
+	}
+	query.HasLoggedIn = logins > 0
+	if !query.HasLoggedIn {
+		query.Result = false
+		return nil
+	}
+
+	known, err := x.Where("user_id = ? AND success = ? AND location = ?", query.UserId, dialect.BooleanStr(true),
+		query.Location).Count(&models.LoginHistoryEntry{})
+	if err != nil {
+		return err
+	}
+	query.Result = known > 0
+	return nil
+}
@@ -0,0 +1,75 @@
+// +build integration
+
+package sqlstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func TestLoginHistory(t *testing.T) {
+	sqlStore := InitTestDB(t)
+	t.Cleanup(func() { getTimeNow = time.Now })
+
+	user, err := sqlStore.CreateUser(context.Background(), models.CreateUserCommand{Login: "leonard", OrgId: 1})
+	require.NoError(t, err)
+
+	record := func(t *testing.T, created time.Time, success bool, location string) {
+		t.Helper()
+		mockTime(created)
+		require.NoError(t, CreateLoginHistoryEntry(&models.CreateLoginHistoryEntryCommand{
+			UserId:    user.Id,
+			Login:     user.Login,
+			Success:   success,
+			IpAddress: "192.168.0.1",
+			Location:  location,
+		}))
+	}
+	isKnown := func(t *testing.T, location string) *models.IsKnownLoginLocationQuery {
+		t.Helper()
+		query := &models.IsKnownLoginLocationQuery{UserId: user.Id, Location: location}
+		require.NoError(t, IsKnownLoginLocation(query))
+		return query
+	}
+
+	start := time.Date(2021, 6, 1, 8, 0, 0, 0, time.UTC)
+
+	query := isKnown(t, "192.168.0.0/24")
+	assert.False(t, query.HasLoggedIn)
+	assert.False(t, query.Result)
+
+	record(t, start, false, "10.0.0.0/24")
+	record(t, start.Add(time.Minute), true, "192.168.0.0/24")
+	record(t, start.Add(2*time.Minute), true, "192.168.0.0/24")
+
+	t.Run("Should return the login history, most recent first", func(t *testing.T) {
+		query := &models.GetUserLoginHistoryQuery{UserId: user.Id, Limit: 2}
+		require.NoError(t, GetUserLoginHistory(query))
+		require.Len(t, query.Result, 2)
+		assert.Equal(t, start.Add(2*time.Minute).Unix(), query.Result[0].Created)
+		assert.True(t, query.Result[1].Success)
+	})
+
+	t.Run("Should know the locations of the successful logins only", func(t *testing.T) {
+		query := isKnown(t, "192.168.0.0/24")
+		assert.True(t, query.HasLoggedIn)
+		assert.True(t, query.Result)
+		assert.False(t, isKnown(t, "10.0.0.0/24").Result)
+	})
+
+	t.Run("Should delete the old login history", func(t *testing.T) {
+		cmd := &models.DeleteOldLoginHistoryCommand{OlderThan: start.Add(90 * time.Second)}
+		require.NoError(t, DeleteOldLoginHistory(cmd))
+		assert.Equal(t, int64(2), cmd.DeletedRows)
+
+		query := &models.GetUserLoginHistoryQuery{UserId: user.Id}
+		require.NoError(t, GetUserLoginHistory(query))
+		assert.Len(t, query.Result, 1)
+	})
+}
@@ -0,0 +1,76 @@
+package sqlstore
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// latestMigrationsCount is the number of runs of migrations in the migration state
+const latestMigrationsCount = 20
+
+// MigrationState is the state of the migrations of the database.
+type MigrationState struct {
+	Total   int `json:"total"`
+	Applied int `json:"applied"`
+	// Pending are the IDs of the migrations that didn't run successfully yet
+	Pending []string `json:"pending"`
+	// Failed are the runs of migrations that failed
+	Failed []MigrationRun `json:"failed"`
+	// Latest are the latest runs of migrations, the latest first
+	Latest []MigrationRun `json:"latest"`
+}
+
+// MigrationRun is a run of a migration, as logged in the migration log.
+type MigrationRun struct {
+	MigrationID string    `json:"migrationId"`
+	Success     bool      `json:"success"`
+	Error       string    `json:"error,omitempty"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// GetMigrationState returns the state of the migrations of the database.
+func (ss *SQLStore) GetMigrationState() (*MigrationState, error) {
+	mg := ss.newMigrator()
+	applied, err := mg.GetMigrationLog()
+	if err != nil {
+		return nil, err
+	}
+
+	state := &MigrationState{Pending: []string{}, Failed: []MigrationRun{}, Latest: []MigrationRun{}}
+	for _, id := range mg.MigrationIDs() {
+		state.Total++
+		if _, ok := applied[id]; ok {
+			state.Applied++
+		} else {
+			state.Pending = append(state.Pending, id)
+		}
+	}
+
+	var failed []migrator.MigrationLog
+	if err := ss.engine.Where("success = ?", false).Asc("id").Find(&failed); err != nil {
+		return nil, err
+	}
+	for _, entry := range failed {
+		state.Failed = append(state.Failed, newMigrationRun(entry))
+	}
+
+	var latest []migrator.MigrationLog
+	if err := ss.engine.Desc("id").Limit(latestMigrationsCount).Find(&latest); err != nil {
+		return nil, err
+	}
+	for _, entry := range latest {
+		state.Latest = append(state.Latest, newMigrationRun(entry))
+	}
+
+	return state, nil
+}
+
+func newMigrationRun(entry migrator.MigrationLog) MigrationRun {
+	return MigrationRun{
+		MigrationID: entry.MigrationID,
+		Success:     entry.Success,
+		Error:       entry.Error,
+		Timestamp:   entry.Timestamp,
+	}
+}
@@ -0,0 +1,41 @@
+// +build integration
+
+package sqlstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func TestGetMigrationState(t *testing.T) {
+	ss := InitTestDB(t)
+
+	state, err := ss.GetMigrationState()
+	require.NoError(t, err)
+	assert.Greater(t, state.Total, 0)
+	assert.Equal(t, state.Total, state.Applied)
+	assert.Empty(t, state.Pending)
+	assert.Empty(t, state.Failed)
+	assert.Len(t, state.Latest, latestMigrationsCount)
+
+	_, err = ss.engine.Insert(&migrator.MigrationLog{
+		MigrationID: "create user table",
+		Success:     false,
+		Error:       "table already exists",
+		Timestamp:   time.Now(),
+	})
+	require.NoError(t, err)
+
+	state, err = ss.GetMigrationState()
+	require.NoError(t, err)
+	require.Len(t, state.Failed, 1)
+	assert.Equal(t, "create user table", state.Failed[0].MigrationID)
+	assert.Equal(t, "table already exists", state.Failed[0].Error)
+	assert.Equal(t, "create user table", state.Latest[0].MigrationID)
+	assert.Equal(t, state.Total, state.Applied)
+}
@@ -178,6 +178,11 @@ func addAnnotationMig(mg *Migrator) {
 	mg.AddMigration("Add index for alert_id on annotation table", NewAddIndexMigration(table, &Index{
 		Cols: []string{"alert_id"}, Type: IndexType,
 	}))
+
+	// counting the annotations by tag
+	mg.AddMigration("Add index for tag_id on annotation_tag table", NewAddIndexMigration(annotationTagTableV3, &Index{
+		Cols: []string{"tag_id"}, Type: IndexType,
+	}))
 }
 
 type AddMakeRegionSingleRowMigration struct {
@@ -0,0 +1,29 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addChangeEventMigrations(mg *Migrator) {
+	changeEventV1 := Table{
+		Name: "change_event",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "resource_type", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "resource_id", Type: DB_BigInt, Nullable: false},
+			{Name: "resource_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "resource_name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "action", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "old_version", Type: DB_BigInt, Nullable: false},
+			{Name: "new_version", Type: DB_BigInt, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "created"}},
+			{Cols: []string{"org_id", "resource_type", "resource_uid"}},
+		},
+	}
+
+	mg.AddMigration("create change_event table", NewAddTableMigration(changeEventV1))
+	addTableIndicesMigrations(mg, "v1", changeEventV1)
+}
@@ -0,0 +1,23 @@
+package migrations
+
+import "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addClusterEventMigrations(mg *migrator.Migrator) {
+	clusterEvent := migrator.Table{
+		Name: "cluster_event",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "type", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "payload", Type: migrator.DB_Text, Nullable: false},
+			{Name: "instance_id", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "created", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"created"}},
+		},
+	}
+
+	mg.AddMigration("create cluster_event table", migrator.NewAddTableMigration(clusterEvent))
+
+	mg.AddMigration("add index cluster_event.created", migrator.NewAddIndexMigration(clusterEvent, clusterEvent.Indices[0]))
+}
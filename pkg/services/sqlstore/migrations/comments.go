@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// addCommentMigrations defines database migrations for comments.
+func addCommentMigrations(mg *migrator.Migrator) {
+	commentV1 := migrator.Table{
+		Name: "comment",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "dashboard_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "panel_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "parent_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "time_from", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "time_to", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "content", Type: migrator.DB_Text, Nullable: false},
+			{Name: "user_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "uid"}, Type: migrator.UniqueIndex},
+			{Cols: []string{"org_id", "dashboard_id"}},
+		},
+	}
+
+	mg.AddMigration("create comment table v1", migrator.NewAddTableMigration(commentV1))
+	mg.AddMigration("add index comment org_id-uid", migrator.NewAddIndexMigration(commentV1, commentV1.Indices[0]))
+	mg.AddMigration("add index comment org_id-dashboard_id", migrator.NewAddIndexMigration(commentV1, commentV1.Indices[1]))
+}
@@ -49,4 +49,11 @@ INSERT INTO dashboard_acl
 
 	mg.AddMigration("delete acl rules for deleted dashboards and folders", NewRawSQLMigration(
 		"DELETE FROM dashboard_acl WHERE dashboard_id NOT IN (SELECT id FROM dashboard) AND dashboard_id != -1"))
+
+	mg.AddMigration("Add column external_group in dashboard_acl", NewAddColumnMigration(dashboardAclV1, &Column{
+		Name: "external_group", Type: DB_NVarchar, Length: 190, Nullable: true,
+	}))
+	mg.AddMigration("add unique index dashboard_acl_dashboard_id_external_group", NewAddIndexMigration(dashboardAclV1, &Index{
+		Cols: []string{"dashboard_id", "external_group"}, Type: UniqueIndex,
+	}))
 }
@@ -225,4 +225,8 @@ func addDashboardMigration(mg *Migrator) {
 
 	mg.AddMigration("delete stars for deleted dashboards", NewRawSQLMigration(
 		"DELETE FROM star WHERE dashboard_id NOT IN (SELECT id FROM dashboard)"))
+
+	mg.AddMigration("Add overlay column to dashboard_provisioning", NewAddColumnMigration(dashboardExtrasTableV2, &Column{
+		Name: "overlay", Type: DB_Bool, Nullable: false, Default: "0",
+	}))
 }
@@ -0,0 +1,52 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addDashboardReviewMigrations(mg *Migrator) {
+	folderReviewerV1 := Table{
+		Name: "folder_reviewer",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "folder_id", Type: DB_BigInt, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "folder_id", "user_id"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "user_id"}},
+		},
+	}
+
+	mg.AddMigration("create folder_reviewer table", NewAddTableMigration(folderReviewerV1))
+	addTableIndicesMigrations(mg, "v1", folderReviewerV1)
+
+	dashboardRevisionV1 := Table{
+		Name: "dashboard_revision",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "folder_id", Type: DB_BigInt, Nullable: false},
+			{Name: "review_folder_id", Type: DB_BigInt, Nullable: false},
+			{Name: "title", Type: DB_NVarchar, Length: 189, Nullable: false},
+			{Name: "data", Type: DB_MediumText, Nullable: false},
+			{Name: "message", Type: DB_Text, Nullable: false},
+			{Name: "status", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "created_by", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "reviewed_by", Type: DB_BigInt, Nullable: false},
+			{Name: "reviewed", Type: DB_DateTime, Nullable: true},
+			{Name: "review_comment", Type: DB_Text, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "status"}},
+			{Cols: []string{"org_id", "dashboard_id"}},
+			{Cols: []string{"org_id", "review_folder_id"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard_revision table", NewAddTableMigration(dashboardRevisionV1))
+	addTableIndicesMigrations(mg, "v1", dashboardRevisionV1)
+}
@@ -56,4 +56,20 @@ FROM dashboard;`
 	// change column type of dashboard_version.data
 	mg.AddMigration("alter dashboard_version.data to mediumtext v1", NewRawSQLMigration("").
 		Mysql("ALTER TABLE dashboard_version MODIFY data MEDIUMTEXT;"))
+
+	// compressed storage: rows are either a gzipped snapshot or a gzipped merge patch against base_version
+	mg.AddMigration("Add column data_encoding to dashboard_version", NewAddColumnMigration(dashboardVersionV1, &Column{
+		Name: "data_encoding", Type: DB_NVarchar, Length: 20, Nullable: true,
+	}))
+
+	mg.AddMigration("Add column base_version to dashboard_version", NewAddColumnMigration(dashboardVersionV1, &Column{
+		Name: "base_version", Type: DB_Int, Nullable: false, Default: "0",
+	}))
+
+	mg.AddMigration("Add column data_compressed to dashboard_version", NewAddColumnMigration(dashboardVersionV1, &Column{
+		Name: "data_compressed", Type: DB_Blob, Nullable: true,
+	}))
+
+	mg.AddMigration("Change dashboard_version.data_compressed column to MEDIUMBLOB", NewRawSQLMigration("").
+		Mysql("ALTER TABLE dashboard_version MODIFY data_compressed MEDIUMBLOB;"))
 }
@@ -0,0 +1,24 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addDataSourceExplorePermissionMigrations(mg *Migrator) {
+	dataSourceExplorePermissionV1 := Table{
+		Name: "data_source_explore_permission",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "datasource_id", Type: DB_BigInt, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "team_id", Type: DB_BigInt, Nullable: false},
+			{Name: "role", Type: DB_Varchar, Length: 20, Nullable: true},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "datasource_id"}},
+		},
+	}
+
+	mg.AddMigration("create data_source_explore_permission table", NewAddTableMigration(dataSourceExplorePermissionV1))
+	mg.AddMigration("add index data_source_explore_permission.org_id_datasource_id", NewAddIndexMigration(dataSourceExplorePermissionV1, dataSourceExplorePermissionV1.Indices[0]))
+}
@@ -0,0 +1,31 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addDataSourceSecretRotationMigrations(mg *Migrator) {
+	dataSourceSecretRotationV1 := Table{
+		Name: "data_source_secret_rotation",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "datasource_id", Type: DB_BigInt, Nullable: false},
+			{Name: "staged_secrets", Type: DB_Text, Nullable: true},
+			{Name: "revision", Type: DB_BigInt, Nullable: false},
+			{Name: "staged", Type: DB_DateTime, Nullable: true},
+			{Name: "staged_by", Type: DB_BigInt, Nullable: false},
+			{Name: "validated", Type: DB_Bool, Nullable: false},
+			{Name: "previous_secrets", Type: DB_Text, Nullable: true},
+			{Name: "promoted", Type: DB_DateTime, Nullable: true},
+			{Name: "promoted_by", Type: DB_BigInt, Nullable: false},
+			{Name: "grace_expires", Type: DB_DateTime, Nullable: true},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "datasource_id"}, Type: UniqueIndex},
+			{Cols: []string{"grace_expires"}},
+		},
+	}
+
+	mg.AddMigration("create data_source_secret_rotation table", NewAddTableMigration(dataSourceSecretRotationV1))
+	mg.AddMigration("add unique index data_source_secret_rotation.org_id_datasource_id", NewAddIndexMigration(dataSourceSecretRotationV1, dataSourceSecretRotationV1.Indices[0]))
+	mg.AddMigration("add index data_source_secret_rotation.grace_expires", NewAddIndexMigration(dataSourceSecretRotationV1, dataSourceSecretRotationV1.Indices[1]))
+}
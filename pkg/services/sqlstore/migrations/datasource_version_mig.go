@@ -0,0 +1,25 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addDataSourceVersionMigrations(mg *Migrator) {
+	dataSourceVersionV1 := Table{
+		Name: "data_source_version",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "data_source_id", Type: DB_BigInt, Nullable: false},
+			{Name: "version", Type: DB_Int, Nullable: false},
+			{Name: "restored_from", Type: DB_Int, Nullable: false},
+			{Name: "data", Type: DB_MediumText, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "created_by", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "data_source_id", "version"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create data_source_version table", NewAddTableMigration(dataSourceVersionV1))
+	addTableIndicesMigrations(mg, "v1", dataSourceVersionV1)
+}
@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addFileMigrations(mg *Migrator) {
+	fileV1 := Table{
+		Name: "file",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "path", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "content_type", Type: DB_NVarchar, Length: 100, Nullable: false},
+			{Name: "size", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+			{Name: "created_by", Type: DB_BigInt, Nullable: false},
+			{Name: "updated_by", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "path"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create file table v1", NewAddTableMigration(fileV1))
+	mg.AddMigration("add unique index file.org_id-path", NewAddIndexMigration(fileV1, fileV1.Indices[0]))
+}
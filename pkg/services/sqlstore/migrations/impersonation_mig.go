@@ -0,0 +1,30 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addImpersonationMigrations(mg *Migrator) {
+	impersonationSessionV1 := Table{
+		Name: "impersonation_session",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "admin_user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "reason", Type: DB_Text, Nullable: false},
+			{Name: "key_hash", Type: DB_NVarchar, Length: 100, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "expires", Type: DB_DateTime, Nullable: false},
+			{Name: "stopped", Type: DB_Bool, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"key_hash"}, Type: UniqueIndex},
+			{Cols: []string{"user_id"}},
+		},
+	}
+
+	mg.AddMigration("create impersonation_session table", NewAddTableMigration(impersonationSessionV1))
+	addTableIndicesMigrations(mg, "v1", impersonationSessionV1)
+
+	mg.AddMigration("add impersonator_id column to change_event", NewAddColumnMigration(Table{Name: "change_event"}, &Column{
+		Name: "impersonator_id", Type: DB_BigInt, Nullable: false, Default: "0",
+	}))
+}
@@ -0,0 +1,24 @@
+package migrations
+
+import "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addLeaderElectionMigrations(mg *migrator.Migrator) {
+	leaderLease := migrator.Table{
+		Name: "leader_lease",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "name", Type: migrator.DB_NVarchar, Length: 100, Nullable: false},
+			{Name: "holder", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "version", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "acquired_at", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "expires_at", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"name"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create leader_lease table", migrator.NewAddTableMigration(leaderLease))
+
+	mg.AddMigration("add unique index leader_lease.name", migrator.NewAddIndexMigration(leaderLease, leaderLease.Indices[0]))
+}
@@ -0,0 +1,28 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addLoginHistoryMigrations(mg *Migrator) {
+	loginHistoryEntryV1 := Table{
+		Name: "login_history_entry",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "login", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "auth_module", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "success", Type: DB_Bool, Nullable: false},
+			{Name: "ip_address", Type: DB_NVarchar, Length: 50, Nullable: false},
+			{Name: "user_agent", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "location", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "created", Type: DB_Int, Default: "0", Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"user_id", "location"}},
+			{Cols: []string{"created"}},
+		},
+	}
+
+	mg.AddMigration("create login_history_entry table", NewAddTableMigration(loginHistoryEntryV1))
+	mg.AddMigration("add index login_history_entry.user_id_location", NewAddIndexMigration(loginHistoryEntryV1, loginHistoryEntryV1.Indices[0]))
+	mg.AddMigration("add index login_history_entry.created", NewAddIndexMigration(loginHistoryEntryV1, loginHistoryEntryV1.Indices[1]))
+}
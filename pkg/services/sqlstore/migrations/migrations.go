@@ -41,6 +41,23 @@ func AddMigrations(mg *Migrator) {
 	ualert.AddTablesMigrations(mg)
 	ualert.AddDashAlertMigration(mg)
 	addLibraryElementsMigrations(mg)
+	addFileMigrations(mg)
+	addSavedQueriesMigrations(mg)
+	addCommentMigrations(mg)
+	addUserExternalGroupMigrations(mg)
+	addQueryCostMigrations(mg)
+	addReadOnlyFolderMigrations(mg)
+	addDashboardReviewMigrations(mg)
+	addDataSourceVersionMigrations(mg)
+	addChangeEventMigrations(mg)
+	addImpersonationMigrations(mg)
+	addOrgRoleRuleMigrations(mg)
+	addUsageExportMigrations(mg)
+	addLeaderElectionMigrations(mg)
+	addClusterEventMigrations(mg)
+	addDataSourceExplorePermissionMigrations(mg)
+	addDataSourceSecretRotationMigrations(mg)
+	addLoginHistoryMigrations(mg)
 }
 
 func addMigrationLogMigrations(mg *Migrator) {
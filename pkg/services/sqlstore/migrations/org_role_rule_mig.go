@@ -0,0 +1,24 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addOrgRoleRuleMigrations(mg *Migrator) {
+	orgRoleRuleV1 := Table{
+		Name: "org_role_rule",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "domain", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "regex", Type: DB_Text, Nullable: false},
+			{Name: "role", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id"}},
+		},
+	}
+
+	mg.AddMigration("create org_role_rule table", NewAddTableMigration(orgRoleRuleV1))
+	addTableIndicesMigrations(mg, "v1", orgRoleRuleV1)
+}
@@ -42,4 +42,12 @@ func addPreferencesMigrations(mg *Migrator) {
 		SQLite("UPDATE preferences SET team_id=0 WHERE team_id IS NULL;").
 		Postgres("UPDATE preferences SET team_id=0 WHERE team_id IS NULL;").
 		Mysql("UPDATE preferences SET team_id=0 WHERE team_id IS NULL;"))
+
+	mg.AddMigration("Add column week_start in preferences", NewAddColumnMigration(preferencesV2, &Column{
+		Name: "week_start", Type: DB_NVarchar, Length: 10, Nullable: false, Default: "''",
+	}))
+
+	mg.AddMigration("Add column fiscal_year_start_month in preferences", NewAddColumnMigration(preferencesV2, &Column{
+		Name: "fiscal_year_start_month", Type: DB_Int, Nullable: false, Default: "0",
+	}))
 }
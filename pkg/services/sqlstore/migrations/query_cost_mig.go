@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// addQueryCostMigrations defines database migrations for the query costs,
+// aggregated by org, data source, dashboard and hour. The period is the unix
+// time in seconds of the start of the hour.
+func addQueryCostMigrations(mg *migrator.Migrator) {
+	queryCostV1 := migrator.Table{
+		Name: "query_cost",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "datasource_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "dashboard_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "period", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "queries", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "errors", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "scanned_bytes", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "duration_ms", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "datasource_id", "dashboard_id", "period"}, Type: migrator.UniqueIndex},
+			{Cols: []string{"period"}},
+		},
+	}
+
+	mg.AddMigration("create query_cost table v1", migrator.NewAddTableMigration(queryCostV1))
+	mg.AddMigration("add index query_cost org_id-datasource_id-dashboard_id-period",
+		migrator.NewAddIndexMigration(queryCostV1, queryCostV1.Indices[0]))
+	mg.AddMigration("add index query_cost period", migrator.NewAddIndexMigration(queryCostV1, queryCostV1.Indices[1]))
+}
@@ -0,0 +1,21 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addReadOnlyFolderMigrations(mg *Migrator) {
+	readOnlyFolderV1 := Table{
+		Name: "read_only_folder",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "folder_id", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "folder_id"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create read_only_folder table", NewAddTableMigration(readOnlyFolderV1))
+	mg.AddMigration("add unique index read_only_folder.org_id_folder_id", NewAddIndexMigration(readOnlyFolderV1, readOnlyFolderV1.Indices[0]))
+}
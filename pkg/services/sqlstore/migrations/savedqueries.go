@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// addSavedQueriesMigrations defines database migrations for saved queries.
+func addSavedQueriesMigrations(mg *migrator.Migrator) {
+	savedQueryV1 := migrator.Table{
+		Name: "saved_query",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "folder_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "name", Type: migrator.DB_NVarchar, Length: 150, Nullable: false},
+			{Name: "description", Type: migrator.DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "datasource_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "model", Type: migrator.DB_Text, Nullable: false},
+			{Name: "variables", Type: migrator.DB_Text, Nullable: false},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "created_by", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "updated_by", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "version", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "uid"}, Type: migrator.UniqueIndex},
+			{Cols: []string{"org_id", "folder_id", "name"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create saved_query table v1", migrator.NewAddTableMigration(savedQueryV1))
+	mg.AddMigration("add index saved_query org_id-uid", migrator.NewAddIndexMigration(savedQueryV1, savedQueryV1.Indices[0]))
+	mg.AddMigration("add index saved_query org_id-folder_id-name", migrator.NewAddIndexMigration(savedQueryV1, savedQueryV1.Indices[1]))
+}
@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// addUsageExportMigrations defines database migrations for the alert
+// evaluations of the usage exports, aggregated by org and hour. The period is
+// the unix time in seconds of the start of the hour.
+func addUsageExportMigrations(mg *migrator.Migrator) {
+	alertEvaluationUsageV1 := migrator.Table{
+		Name: "alert_evaluation_usage",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "period", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "evaluations", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "period"}, Type: migrator.UniqueIndex},
+			{Cols: []string{"period"}},
+		},
+	}
+
+	mg.AddMigration("create alert_evaluation_usage table v1", migrator.NewAddTableMigration(alertEvaluationUsageV1))
+	mg.AddMigration("add index alert_evaluation_usage org_id-period",
+		migrator.NewAddIndexMigration(alertEvaluationUsageV1, alertEvaluationUsageV1.Indices[0]))
+	mg.AddMigration("add index alert_evaluation_usage period",
+		migrator.NewAddIndexMigration(alertEvaluationUsageV1, alertEvaluationUsageV1.Indices[1]))
+}
@@ -0,0 +1,22 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addUserExternalGroupMigrations(mg *Migrator) {
+	userExternalGroupV1 := Table{
+		Name: "user_external_group",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "auth_module", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "group_id", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"user_id", "auth_module", "group_id"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create user_external_group table", NewAddTableMigration(userExternalGroupV1))
+	mg.AddMigration("add unique index user_external_group.user_id_auth_module_group_id", NewAddIndexMigration(userExternalGroupV1, userExternalGroupV1.Indices[0]))
+}
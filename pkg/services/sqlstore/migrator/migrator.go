@@ -44,6 +44,15 @@ func (mg *Migrator) MigrationsCount() int {
 	return len(mg.migrations)
 }
 
+// MigrationIDs returns the IDs of the migrations, in the order they run.
+func (mg *Migrator) MigrationIDs() []string {
+	ids := make([]string, 0, len(mg.migrations))
+	for _, m := range mg.migrations {
+		ids = append(ids, m.Id())
+	}
+	return ids
+}
+
 func (mg *Migrator) AddMigration(id string, m Migration) {
 	m.SetId(id)
 	mg.migrations = append(mg.migrations, m)
@@ -241,6 +241,14 @@ func DeleteOrg(cmd *models.DeleteOrgCommand) error {
 			"DELETE FROM org_user WHERE org_id = ?",
 			"DELETE FROM org WHERE id = ?",
 			"DELETE FROM temp_user WHERE org_id = ?",
+			"DELETE FROM read_only_folder WHERE org_id = ?",
+			"DELETE FROM folder_reviewer WHERE org_id = ?",
+			"DELETE FROM dashboard_revision WHERE org_id = ?",
+			"DELETE FROM data_source_version WHERE org_id = ?",
+			"DELETE FROM change_event WHERE org_id = ?",
+			"DELETE FROM org_role_rule WHERE org_id = ?",
+			"DELETE FROM data_source_explore_permission WHERE org_id = ?",
+			"DELETE FROM data_source_secret_rotation WHERE org_id = ?",
 		}
 
 		for _, sql := range deletes {
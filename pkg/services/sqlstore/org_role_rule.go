@@ -0,0 +1,96 @@
+package sqlstore
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func init() {
+	bus.AddHandler("sql", GetOrgRoleRules)
+	bus.AddHandler("sql", CreateOrgRoleRule)
+	bus.AddHandler("sql", UpdateOrgRoleRule)
+	bus.AddHandler("sql", DeleteOrgRoleRule)
+}
+
+// GetOrgRoleRules returns the org role rules of the existing orgs with the names of their orgs, in the order they
+// were created.
+func GetOrgRoleRules(query *models.GetOrgRoleRulesQuery) error {
+	query.Result = make([]*models.OrgRoleRuleDTO, 0)
+	return x.Table("org_role_rule").
+		Select(`org_role_rule.id,
+				org_role_rule.org_id,
+				org_role_rule.domain,
+				org_role_rule.regex,
+				org_role_rule.role,
+				org_role_rule.created,
+				org_role_rule.updated,
+				org.name AS org_name`).
+		Join("INNER", "org", "org.id = org_role_rule.org_id").
+		OrderBy("org_role_rule.id").
+		Find(&query.Result)
+}
+
+func CreateOrgRoleRule(cmd *models.CreateOrgRoleRuleCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		if has, err := sess.ID(cmd.OrgId).Exist(&models.Org{}); err != nil {
+			return err
+		} else if !has {
+			return models.ErrOrgNotFound
+		}
+
+		rule := &models.OrgRoleRule{
+			OrgId:   cmd.OrgId,
+			Domain:  cmd.Domain,
+			Regex:   cmd.Regex,
+			Role:    cmd.Role,
+			Created: time.Now(),
+			Updated: time.Now(),
+		}
+		if _, err := sess.Insert(rule); err != nil {
+			return err
+		}
+		cmd.Result = rule
+		return nil
+	})
+}
+
+func UpdateOrgRoleRule(cmd *models.UpdateOrgRoleRuleCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		if has, err := sess.ID(cmd.OrgId).Exist(&models.Org{}); err != nil {
+			return err
+		} else if !has {
+			return models.ErrOrgNotFound
+		}
+
+		affected, err := sess.ID(cmd.Id).Cols("org_id", "domain", "regex", "role", "updated").
+			Update(&models.OrgRoleRule{
+				OrgId:   cmd.OrgId,
+				Domain:  cmd.Domain,
+				Regex:   cmd.Regex,
+				Role:    cmd.Role,
+				Updated: time.Now(),
+			})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return models.ErrOrgRoleRuleNotFound
+		}
+		return nil
+	})
+}
+
+func DeleteOrgRoleRule(cmd *models.DeleteOrgRoleRuleCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		affected, err := sess.ID(cmd.Id).Delete(&models.OrgRoleRule{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return models.ErrOrgRoleRuleNotFound
+		}
+		return nil
+	})
+}
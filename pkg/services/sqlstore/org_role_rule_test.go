@@ -0,0 +1,74 @@
+// +build integration
+
+package sqlstore
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrgRoleRules(t *testing.T) {
+	sqlStore := InitTestDB(t)
+	admin := createUser(t, sqlStore, "admin", "Admin", true)
+	sales, err := sqlStore.CreateOrgWithMember("Sales", admin.Id)
+	require.NoError(t, err)
+	support, err := sqlStore.CreateOrgWithMember("Support", admin.Id)
+	require.NoError(t, err)
+
+	create := func(orgID int64, domain string, regex string, role models.RoleType) *models.OrgRoleRule {
+		cmd := models.CreateOrgRoleRuleCommand{OrgId: orgID, Domain: domain, Regex: regex, Role: role}
+		require.NoError(t, CreateOrgRoleRule(&cmd))
+		return cmd.Result
+	}
+	rules := func() []*models.OrgRoleRuleDTO {
+		query := models.GetOrgRoleRulesQuery{}
+		require.NoError(t, GetOrgRoleRules(&query))
+		return query.Result
+	}
+
+	first := create(sales.Id, "sales.example.com", "", models.ROLE_EDITOR)
+	second := create(support.Id, "", `^.*@support\.example\.com$`, models.ROLE_VIEWER)
+
+	t.Run("Gets the rules with the names of their orgs", func(t *testing.T) {
+		result := rules()
+		require.Len(t, result, 2)
+		assert.Equal(t, first.Id, result[0].Id)
+		assert.Equal(t, "Sales", result[0].OrgName)
+		assert.Equal(t, "sales.example.com", result[0].Domain)
+		assert.Equal(t, models.ROLE_EDITOR, result[0].Role)
+		assert.Equal(t, "Support", result[1].OrgName)
+		assert.Equal(t, `^.*@support\.example\.com$`, result[1].Regex)
+	})
+
+	t.Run("Updates the rules", func(t *testing.T) {
+		err := UpdateOrgRoleRule(&models.UpdateOrgRoleRuleCommand{
+			Id: first.Id, OrgId: sales.Id, Domain: "example.com", Role: models.ROLE_ADMIN,
+		})
+		require.NoError(t, err)
+		result := rules()
+		assert.Equal(t, "example.com", result[0].Domain)
+		assert.Equal(t, models.ROLE_ADMIN, result[0].Role)
+
+		err = UpdateOrgRoleRule(&models.UpdateOrgRoleRuleCommand{Id: 999, OrgId: sales.Id, Domain: "example.com", Role: models.ROLE_ADMIN})
+		require.Equal(t, models.ErrOrgRoleRuleNotFound, err)
+	})
+
+	t.Run("Returns an error for the missing orgs", func(t *testing.T) {
+		err := CreateOrgRoleRule(&models.CreateOrgRoleRuleCommand{OrgId: 999, Domain: "example.com", Role: models.ROLE_VIEWER})
+		require.Equal(t, models.ErrOrgNotFound, err)
+	})
+
+	t.Run("Deletes the rules", func(t *testing.T) {
+		require.NoError(t, DeleteOrgRoleRule(&models.DeleteOrgRoleRuleCommand{Id: second.Id}))
+		assert.Len(t, rules(), 1)
+		require.Equal(t, models.ErrOrgRoleRuleNotFound, DeleteOrgRoleRule(&models.DeleteOrgRoleRuleCommand{Id: second.Id}))
+	})
+
+	t.Run("Deletes the rules of the deleted orgs", func(t *testing.T) {
+		require.NoError(t, DeleteOrg(&models.DeleteOrgCommand{Id: sales.Id}))
+		assert.Empty(t, rules())
+	})
+}
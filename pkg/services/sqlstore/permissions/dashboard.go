@@ -43,6 +43,7 @@ func (d DashboardPermissionFilter) Where() (string, []interface{}) {
 						(
 							da.user_id = ? OR
 							ugm.user_id = ? OR
+							da.external_group IN (SELECT group_id FROM user_external_group WHERE user_id = ?) OR
 							da.role IN (?` + strings.Repeat(",?", len(okRoles)-1) + `)
 						)
 				UNION
@@ -69,7 +70,7 @@ func (d DashboardPermissionFilter) Where() (string, []interface{}) {
 	)
 	`
 
-	params := []interface{}{d.OrgId, d.PermissionLevel, d.UserId, d.UserId}
+	params := []interface{}{d.OrgId, d.PermissionLevel, d.UserId, d.UserId, d.UserId}
 	params = append(params, okRoles...)
 	params = append(params, d.OrgId, d.PermissionLevel, d.UserId)
 	params = append(params, okRoles...)
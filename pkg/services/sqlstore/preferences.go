@@ -42,6 +42,7 @@ func (ss *SQLStore) GetPreferencesWithDefaults(query *models.GetPreferencesWithD
 	res := &models.Preferences{
 		Theme:           ss.Cfg.DefaultTheme,
 		Timezone:        ss.Cfg.DateFormats.DefaultTimezone,
+		WeekStart:       ss.Cfg.DateFormats.DefaultWeekStart,
 		HomeDashboardId: 0,
 	}
 
@@ -55,6 +56,12 @@ func (ss *SQLStore) GetPreferencesWithDefaults(query *models.GetPreferencesWithD
 		if p.HomeDashboardId != 0 {
 			res.HomeDashboardId = p.HomeDashboardId
 		}
+		if p.WeekStart != "" {
+			res.WeekStart = p.WeekStart
+		}
+		if p.FiscalYearStartMonth != 0 {
+			res.FiscalYearStartMonth = p.FiscalYearStartMonth
+		}
 	}
 
 	query.Result = res
@@ -88,14 +95,16 @@ func SavePreferences(cmd *models.SavePreferencesCommand) error {
 
 		if !exists {
 			prefs = models.Preferences{
-				UserId:          cmd.UserId,
-				OrgId:           cmd.OrgId,
-				TeamId:          cmd.TeamId,
-				HomeDashboardId: cmd.HomeDashboardId,
-				Timezone:        cmd.Timezone,
-				Theme:           cmd.Theme,
-				Created:         time.Now(),
-				Updated:         time.Now(),
+				UserId:               cmd.UserId,
+				OrgId:                cmd.OrgId,
+				TeamId:               cmd.TeamId,
+				HomeDashboardId:      cmd.HomeDashboardId,
+				Timezone:             cmd.Timezone,
+				Theme:                cmd.Theme,
+				WeekStart:            cmd.WeekStart,
+				FiscalYearStartMonth: cmd.FiscalYearStartMonth,
+				Created:              time.Now(),
+				Updated:              time.Now(),
 			}
 			_, err = sess.Insert(&prefs)
 			return err
@@ -103,6 +112,8 @@ func SavePreferences(cmd *models.SavePreferencesCommand) error {
 		prefs.HomeDashboardId = cmd.HomeDashboardId
 		prefs.Timezone = cmd.Timezone
 		prefs.Theme = cmd.Theme
+		prefs.WeekStart = cmd.WeekStart
+		prefs.FiscalYearStartMonth = cmd.FiscalYearStartMonth
 		prefs.Updated = time.Now()
 		prefs.Version += 1
 		_, err = sess.ID(prefs.Id).AllCols().Update(&prefs)
@@ -113,4 +113,30 @@ func TestPreferencesDataAccess(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, int64(1), query.Result.HomeDashboardId)
 	})
+
+	t.Run("GetPreferencesWithDefaults with saved org week start and fiscal year should return org and user ones", func(t *testing.T) {
+		ss.Cfg.DateFormats.DefaultWeekStart = "browser"
+
+		err := SavePreferences(&models.SavePreferencesCommand{OrgId: 2, WeekStart: "sunday", FiscalYearStartMonth: 4})
+		require.NoError(t, err)
+		err = SavePreferences(&models.SavePreferencesCommand{OrgId: 2, UserId: 1, WeekStart: "saturday"})
+		require.NoError(t, err)
+
+		query := &models.GetPreferencesWithDefaultsQuery{User: &models.SignedInUser{OrgId: 2, UserId: 1}}
+		err = ss.GetPreferencesWithDefaults(query)
+		require.NoError(t, err)
+		require.Equal(t, "saturday", query.Result.WeekStart)
+		require.Equal(t, 4, query.Result.FiscalYearStartMonth)
+
+		query = &models.GetPreferencesWithDefaultsQuery{User: &models.SignedInUser{OrgId: 2}}
+		err = ss.GetPreferencesWithDefaults(query)
+		require.NoError(t, err)
+		require.Equal(t, "sunday", query.Result.WeekStart)
+
+		query = &models.GetPreferencesWithDefaultsQuery{User: &models.SignedInUser{OrgId: 3}}
+		err = ss.GetPreferencesWithDefaults(query)
+		require.NoError(t, err)
+		require.Equal(t, "browser", query.Result.WeekStart)
+		require.Equal(t, 0, query.Result.FiscalYearStartMonth)
+	})
 }
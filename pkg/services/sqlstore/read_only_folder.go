@@ -0,0 +1,57 @@
+package sqlstore
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func init() {
+	bus.AddHandler("sql", GetReadOnlyFolders)
+	bus.AddHandler("sql", IsReadOnlyFolder)
+	bus.AddHandler("sql", SetReadOnlyFolders)
+}
+
+// GetReadOnlyFolders returns the read-only folders of an org, ordered by title.
+func GetReadOnlyFolders(query *models.GetReadOnlyFoldersQuery) error {
+	query.Result = make([]*models.ReadOnlyFolderDTO, 0)
+	return x.SQL(`SELECT read_only_folder.folder_id, dashboard.uid AS folder_uid, dashboard.title AS folder_title
+		FROM read_only_folder
+		INNER JOIN dashboard ON dashboard.id = read_only_folder.folder_id
+		WHERE read_only_folder.org_id = ?
+		ORDER BY dashboard.title ASC`, query.OrgId).Find(&query.Result)
+}
+
+// IsReadOnlyFolder returns whether a folder of an org is read-only.
+func IsReadOnlyFolder(query *models.IsReadOnlyFolderQuery) error {
+	has, err := x.Where("org_id = ? AND folder_id = ?", query.OrgId, query.FolderId).Exist(&models.ReadOnlyFolder{})
+	query.Result = has
+	return err
+}
+
+// SetReadOnlyFolders replaces the read-only folders of an org.
+func SetReadOnlyFolders(cmd *models.SetReadOnlyFoldersCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		if _, err := sess.Exec("DELETE FROM read_only_folder WHERE org_id = ?", cmd.OrgId); err != nil {
+			return err
+		}
+
+		seen := map[int64]bool{}
+		for _, folderID := range cmd.FolderIds {
+			if seen[folderID] {
+				continue
+			}
+			seen[folderID] = true
+
+			if _, err := sess.Insert(&models.ReadOnlyFolder{
+				OrgId:    cmd.OrgId,
+				FolderId: folderID,
+				Created:  time.Now(),
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,54 @@
+// +build integration
+
+package sqlstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func TestReadOnlyFolders(t *testing.T) {
+	sqlStore := InitTestDB(t)
+	audit := insertTestDashboard(t, sqlStore, "audit", 1, 0, true)
+	finance := insertTestDashboard(t, sqlStore, "finance", 1, 0, true)
+	other := insertTestDashboard(t, sqlStore, "other", 1, 0, true)
+
+	isReadOnly := func(t *testing.T, folderID int64) bool {
+		t.Helper()
+		query := &models.IsReadOnlyFolderQuery{OrgId: 1, FolderId: folderID}
+		require.NoError(t, IsReadOnlyFolder(query))
+		return query.Result
+	}
+
+	t.Run("Setting replaces the read-only folders of the org", func(t *testing.T) {
+		err := SetReadOnlyFolders(&models.SetReadOnlyFoldersCommand{OrgId: 1, FolderIds: []int64{other.Id, finance.Id}})
+		require.NoError(t, err)
+		err = SetReadOnlyFolders(&models.SetReadOnlyFoldersCommand{OrgId: 1, FolderIds: []int64{finance.Id, audit.Id, audit.Id}})
+		require.NoError(t, err)
+
+		query := &models.GetReadOnlyFoldersQuery{OrgId: 1}
+		require.NoError(t, GetReadOnlyFolders(query))
+		require.Equal(t, []*models.ReadOnlyFolderDTO{
+			{FolderId: audit.Id, FolderUid: audit.Uid, FolderTitle: "audit"},
+			{FolderId: finance.Id, FolderUid: finance.Uid, FolderTitle: "finance"},
+		}, query.Result)
+
+		require.True(t, isReadOnly(t, audit.Id))
+		require.False(t, isReadOnly(t, other.Id))
+
+		query = &models.GetReadOnlyFoldersQuery{OrgId: 2}
+		require.NoError(t, GetReadOnlyFolders(query))
+		require.Empty(t, query.Result)
+	})
+
+	t.Run("Deleting a folder removes it from the read-only folders", func(t *testing.T) {
+		err := DeleteDashboard(&models.DeleteDashboardCommand{Id: finance.Id, OrgId: 1})
+		require.NoError(t, err)
+
+		require.False(t, isReadOnly(t, finance.Id))
+		require.True(t, isReadOnly(t, audit.Id))
+	})
+}
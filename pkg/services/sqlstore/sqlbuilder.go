@@ -62,6 +62,7 @@ func (sb *SQLBuilder) WriteDashboardPermissionFilter(user *models.SignedInUser,
 						(
 							da.user_id = ? OR
 							ugm.user_id = ? OR
+							da.external_group IN (SELECT group_id FROM user_external_group WHERE user_id = ?) OR
 							da.role IN (?` + strings.Repeat(",?", len(okRoles)-1) + `)
 						)
 				UNION
@@ -87,7 +88,7 @@ func (sb *SQLBuilder) WriteDashboardPermissionFilter(user *models.SignedInUser,
 		)
 	)`)
 
-	sb.params = append(sb.params, user.OrgId, permission, user.UserId, user.UserId)
+	sb.params = append(sb.params, user.OrgId, permission, user.UserId, user.UserId, user.UserId)
 	sb.params = append(sb.params, okRoles...)
 
 	sb.params = append(sb.params, user.OrgId, permission, user.UserId)
@@ -85,17 +85,7 @@ func (ss *SQLStore) Init() error {
 	dialect = ss.Dialect
 
 	if !ss.dbCfg.SkipMigrations {
-		migrator := migrator.NewMigrator(ss.engine, ss.Cfg)
-		migrations.AddMigrations(migrator)
-
-		for _, descriptor := range registry.GetServices() {
-			sc, ok := descriptor.Instance.(registry.DatabaseMigrator)
-			if ok {
-				sc.AddMigration(migrator)
-			}
-		}
-
-		if err := migrator.Start(); err != nil {
+		if err := ss.newMigrator().Start(); err != nil {
 			return err
 		}
 	}
@@ -123,6 +113,20 @@ func (ss *SQLStore) Init() error {
 	return nil
 }
 
+// newMigrator returns a migrator with the migrations of the database and of the services.
+func (ss *SQLStore) newMigrator() *migrator.Migrator {
+	mg := migrator.NewMigrator(ss.engine, ss.Cfg)
+	migrations.AddMigrations(mg)
+
+	for _, descriptor := range registry.GetServices() {
+		sc, ok := descriptor.Instance.(registry.DatabaseMigrator)
+		if ok {
+			sc.AddMigration(mg)
+		}
+	}
+	return mg
+}
+
 // Sync syncs changes to the database.
 func (ss *SQLStore) Sync() error {
 	return ss.engine.Sync2()
@@ -1,6 +1,12 @@
 package sqlstore
 
-import "github.com/grafana/grafana/pkg/models"
+import (
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/annotations"
+)
 
 // Will insert if needed any new key/value pars and return ids
 func EnsureTagsExist(sess *DBSession, tags []*models.Tag) ([]*models.Tag, error) {
@@ -24,3 +30,131 @@ func EnsureTagsExist(sess *DBSession, tags []*models.Tag) ([]*models.Tag, error)
 
 	return tags, nil
 }
+
+// The classes of the matches of tags, from the best to the worst.
+const (
+	tagMatchPrefix = iota
+	tagMatchContains
+	tagMatchTypo
+	tagNoMatch
+)
+
+type matchedTag struct {
+	*annotations.Tag
+	// name is the tag as it's written, key:value
+	name  string
+	match int
+}
+
+// matchTags returns the tags matching the term, at most limit of them. They're sorted by the class of their match,
+// then the most used first. All the tags match an empty term.
+func matchTags(tags []*annotations.Tag, term string, limit int64) []matchedTag {
+	term = strings.ToLower(strings.TrimSpace(term))
+
+	matched := make([]matchedTag, 0, len(tags))
+	for _, tag := range tags {
+		name := tag.Key
+		if tag.Value != "" {
+			name = tag.Key + ":" + tag.Value
+		}
+		match := matchTag(strings.ToLower(name), strings.ToLower(tag.Value), term)
+		if match != tagNoMatch {
+			matched = append(matched, matchedTag{Tag: tag, name: name, match: match})
+		}
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].match != matched[j].match {
+			return matched[i].match < matched[j].match
+		}
+		if matched[i].Count != matched[j].Count {
+			return matched[i].Count > matched[j].Count
+		}
+		return matched[i].name < matched[j].name
+	})
+
+	if limit > 0 && int64(len(matched)) > limit {
+		matched = matched[:limit]
+	}
+	return matched
+}
+
+// matchTag returns how the lower cased tag matches the term. The typos are counted against the beginning of the tag
+// or of its value, as long as the term, since the term is usually what has been typed so far.
+func matchTag(name, value, term string) int {
+	if strings.HasPrefix(name, term) || (value != "" && strings.HasPrefix(value, term)) {
+		return tagMatchPrefix
+	}
+	if strings.Contains(name, term) {
+		return tagMatchContains
+	}
+
+	typos := maxTagTypos(term)
+	if typos == 0 {
+		return tagNoMatch
+	}
+	termRunes := []rune(term)
+	for _, candidate := range []string{name, value} {
+		if candidate == "" {
+			continue
+		}
+		candidateRunes := []rune(candidate)
+		if len(candidateRunes) > len(termRunes) {
+			candidateRunes = candidateRunes[:len(termRunes)]
+		}
+		if editDistance(termRunes, candidateRunes) <= typos {
+			return tagMatchTypo
+		}
+	}
+	return tagNoMatch
+}
+
+// maxTagTypos returns the number of typos tolerated in the term, the short terms have to match exactly.
+func maxTagTypos(term string) int {
+	switch length := len([]rune(term)); {
+	case length < 3:
+		return 0
+	case length < 6:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance returns the number of insertions, deletions, substitutions and transpositions of adjacent characters
+// needed to change a into b.
+func editDistance(a, b []rune) int {
+	// rows of the distances between the prefixes of a and b, the two previous ones are kept for the transpositions
+	prevPrev := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				curr[j] = minInt(curr[j], prevPrev[j-2]+1)
+			}
+		}
+		prevPrev, prev, curr = prev, curr, prevPrev
+	}
+	return prev[len(b)]
+}
+
+func minInt(values ...int) int {
+	min := values[0]
+	for _, v := range values[1:] {
+		if v < min {
+			min = v
+		}
+	}
+	return min
+}
@@ -8,6 +8,7 @@ import (
 	. "github.com/smartystreets/goconvey/convey"
 
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/annotations"
 )
 
 func TestSavingTags(t *testing.T) {
@@ -26,3 +27,47 @@ func TestSavingTags(t *testing.T) {
 		So(len(tags), ShouldEqual, 4)
 	})
 }
+
+func TestMatchTags(t *testing.T) {
+	tags := []*annotations.Tag{
+		{Key: "production", Count: 1},
+		{Key: "env", Value: "production", Count: 5},
+		{Key: "deploy", Value: "productions-api", Count: 3},
+		{Key: "preprod", Count: 10},
+		{Key: "staging", Count: 20},
+	}
+
+	names := func(matched []matchedTag) []string {
+		result := []string{}
+		for _, tag := range matched {
+			result = append(result, tag.name)
+		}
+		return result
+	}
+
+	Convey("Testing tags matching", t, func() {
+		Convey("without a term, the most used tags come first", func() {
+			So(names(matchTags(tags, "", 3)), ShouldResemble, []string{"staging", "preprod", "env:production"})
+		})
+
+		Convey("the prefixes come before the other matches", func() {
+			So(names(matchTags(tags, "Prod", 0)), ShouldResemble, []string{"env:production", "deploy:productions-api", "production", "preprod"})
+		})
+
+		Convey("typos are tolerated", func() {
+			So(names(matchTags(tags, "porduction", 0)), ShouldResemble, []string{"env:production", "deploy:productions-api", "production"})
+			So(names(matchTags(tags, "stagnig", 0)), ShouldResemble, []string{"staging"})
+		})
+
+		Convey("short terms have to match", func() {
+			So(names(matchTags(tags, "sx", 0)), ShouldBeEmpty)
+		})
+	})
+
+	Convey("Testing edit distance", t, func() {
+		So(editDistance([]rune("outage"), []rune("outage")), ShouldEqual, 0)
+		So(editDistance([]rune("outgae"), []rune("outage")), ShouldEqual, 1)
+		So(editDistance([]rune("outag"), []rune("outage")), ShouldEqual, 1)
+		So(editDistance([]rune("kitten"), []rune("sitting")), ShouldEqual, 3)
+	})
+}
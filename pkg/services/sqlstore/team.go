@@ -9,6 +9,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/util"
 )
 
 func init() {
@@ -16,6 +17,7 @@ func init() {
 	bus.AddHandler("sql", DeleteTeam)
 	bus.AddHandler("sql", SearchTeams)
 	bus.AddHandler("sql", GetTeamById)
+	bus.AddHandler("sql", GetTeamByUid)
 	bus.AddHandler("sql", GetTeamsByUser)
 
 	bus.AddHandler("sql", UpdateTeamMember)
@@ -55,6 +57,7 @@ func getTeamMemberCount(filteredUsers []string) string {
 func getTeamSearchSQLBase(filteredUsers []string) string {
 	return `SELECT
 		team.id AS id,
+		team.uid AS uid,
 		team.org_id,
 		team.name AS name,
 		team.email AS email,
@@ -67,6 +70,7 @@ func getTeamSearchSQLBase(filteredUsers []string) string {
 func getTeamSelectSQLBase(filteredUsers []string) string {
 	return `SELECT
 		team.id as id,
+		team.uid as uid,
 		team.org_id,
 		team.name as name,
 		team.email as email, ` +
@@ -75,7 +79,14 @@ func getTeamSelectSQLBase(filteredUsers []string) string {
 }
 
 func (ss *SQLStore) CreateTeam(name, email string, orgID int64) (models.Team, error) {
+	return ss.CreateTeamWithUid(util.GenerateShortUID(), name, email, orgID)
+}
+
+// CreateTeamWithUid creates a team with the given uid, for the clients that
+// identify the teams by their own uids.
+func (ss *SQLStore) CreateTeamWithUid(uid, name, email string, orgID int64) (models.Team, error) {
 	team := models.Team{
+		Uid:     uid,
 		Name:    name,
 		Email:   email,
 		OrgId:   orgID,
@@ -89,6 +100,12 @@ func (ss *SQLStore) CreateTeam(name, email string, orgID int64) (models.Team, er
 			return models.ErrTeamNameTaken
 		}
 
+		if exists, err := sess.Where("org_id=? and uid=?", orgID, uid).Exist(&models.Team{}); err != nil {
+			return err
+		} else if exists {
+			return models.ErrTeamUidTaken
+		}
+
 		_, err := sess.Insert(&team)
 		return err
 	})
@@ -136,6 +153,7 @@ func DeleteTeam(cmd *models.DeleteTeamCommand) error {
 			"DELETE FROM team_member WHERE org_id=? and team_id = ?",
 			"DELETE FROM team WHERE org_id=? and id = ?",
 			"DELETE FROM dashboard_acl WHERE org_id=? and team_id = ?",
+			"DELETE FROM data_source_explore_permission WHERE org_id=? and team_id = ?",
 		}
 
 		for _, sql := range deletes {
@@ -263,6 +281,27 @@ func GetTeamById(query *models.GetTeamByIdQuery) error {
 	return nil
 }
 
+// GetTeamByUid returns the team of a uid, for checking the existence of a
+// team rather than viewing it, so without the filtering of the hidden users.
+func GetTeamByUid(query *models.GetTeamByUidQuery) error {
+	var sql bytes.Buffer
+	sql.WriteString(getTeamSelectSQLBase([]string{}))
+	sql.WriteString(` WHERE team.org_id = ? and team.uid = ?`)
+
+	var team models.TeamDTO
+	exists, err := x.SQL(sql.String(), query.OrgId, query.Uid).Get(&team)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return models.ErrTeamNotFound
+	}
+
+	query.Result = &team
+	return nil
+}
+
 // GetTeamsByUser is used by the Guardian when checking a users' permissions
 func GetTeamsByUser(query *models.GetTeamsByUserQuery) error {
 	query.Result = make([]*models.TeamDTO, 0)
@@ -88,6 +88,35 @@ func TestTeamCommandsAndQueries(t *testing.T) {
 				So(team1.MemberCount, ShouldEqual, 2)
 			})
 
+			Convey("Should be able to get teams by uid", func() {
+				So(team1.Uid, ShouldNotBeEmpty)
+				So(team1.Uid, ShouldNotEqual, team2.Uid)
+
+				team3, err := sqlStore.CreateTeamWithUid("team3", "group3 name", "", testOrgID)
+				So(err, ShouldBeNil)
+				So(team3.Uid, ShouldEqual, "team3")
+
+				_, err = sqlStore.CreateTeamWithUid("team3", "group4 name", "", testOrgID)
+				So(err, ShouldEqual, models.ErrTeamUidTaken)
+
+				query := &models.GetTeamByUidQuery{OrgId: testOrgID, Uid: "team3"}
+				err = GetTeamByUid(query)
+				So(err, ShouldBeNil)
+				So(query.Result.Id, ShouldEqual, team3.Id)
+				So(query.Result.Name, ShouldEqual, "group3 name")
+
+				err = GetTeamByUid(&models.GetTeamByUidQuery{OrgId: 2, Uid: "team3"})
+				So(err, ShouldEqual, models.ErrTeamNotFound)
+
+				// the teams created before the uids were added have none
+				_, err = x.Exec("UPDATE team SET uid = NULL WHERE id = ?", team2.Id)
+				So(err, ShouldBeNil)
+				getTeamQuery := &models.GetTeamByIdQuery{OrgId: testOrgID, Id: team2.Id}
+				err = GetTeamById(getTeamQuery)
+				So(err, ShouldBeNil)
+				So(getTeamQuery.Result.Uid, ShouldBeEmpty)
+			})
+
 			Convey("Should return latest auth module for users when getting team members", func() {
 				userId := userIds[1]
 				err := SetAuthInfo(&models.SetAuthInfoCommand{UserId: userId, AuthModule: "oauth_github", AuthId: "1234567"})
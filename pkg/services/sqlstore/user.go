@@ -712,7 +712,11 @@ func deleteUserInTransaction(sess *DBSession, cmd *models.DeleteUserCommand) err
 		"DELETE FROM team_member WHERE user_id = ?",
 		"DELETE FROM user_auth WHERE user_id = ?",
 		"DELETE FROM user_auth_token WHERE user_id = ?",
+		"DELETE FROM user_external_group WHERE user_id = ?",
 		"DELETE FROM quota WHERE user_id = ?",
+		"DELETE FROM folder_reviewer WHERE user_id = ?",
+		"DELETE FROM data_source_explore_permission WHERE user_id = ?",
+		"DELETE FROM login_history_entry WHERE user_id = ?",
 	}
 
 	for _, sql := range deletes {
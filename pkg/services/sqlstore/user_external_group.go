@@ -0,0 +1,59 @@
+package sqlstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// UserExternalGroup is a group of a user in an LDAP or OAuth provider.
+type UserExternalGroup struct {
+	Id         int64
+	UserId     int64
+	AuthModule string
+	GroupId    string
+	Created    time.Time
+}
+
+func init() {
+	bus.AddHandler("sql", GetUserExternalGroups)
+}
+
+// GetUserExternalGroups returns the external groups of a user, of all the
+// auth modules the user logged in with.
+func GetUserExternalGroups(query *models.GetUserExternalGroupsQuery) error {
+	query.Result = make([]string, 0)
+	return x.Table("user_external_group").Distinct("group_id").Where("user_id = ?", query.UserId).
+		OrderBy("group_id").Find(&query.Result)
+}
+
+// SyncUserExternalGroups replaces the external groups of a user from an auth
+// module with the groups the user has at login.
+func (ss *SQLStore) SyncUserExternalGroups(ctx context.Context, userID int64, authModule string, groups []string) error {
+	return ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		if _, err := sess.Exec("DELETE FROM user_external_group WHERE user_id = ? AND auth_module = ?", userID, authModule); err != nil {
+			return err
+		}
+
+		// the longer groups can't be referenced by the permissions
+		seen := map[string]bool{}
+		for _, group := range groups {
+			if group == "" || len(group) > models.MaxExternalGroupLength || seen[group] {
+				continue
+			}
+			seen[group] = true
+
+			if _, err := sess.Insert(&UserExternalGroup{
+				UserId:     userID,
+				AuthModule: authModule,
+				GroupId:    group,
+				Created:    time.Now(),
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,80 @@
+// +build integration
+
+package sqlstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/search"
+)
+
+func TestUserExternalGroups(t *testing.T) {
+	sqlStore := InitTestDB(t)
+	user, err := sqlStore.CreateUser(context.Background(), models.CreateUserCommand{Login: "viewer", Email: "viewer@test.com"})
+	require.NoError(t, err)
+
+	t.Run("Syncing replaces the groups of the auth module", func(t *testing.T) {
+		err := sqlStore.SyncUserExternalGroups(context.Background(), user.Id, models.AuthModuleLDAP, []string{"cn=sre", "cn=dev", "cn=sre", ""})
+		require.NoError(t, err)
+		err = sqlStore.SyncUserExternalGroups(context.Background(), user.Id, "oauth_github", []string{"@grafana/sre"})
+		require.NoError(t, err)
+
+		query := &models.GetUserExternalGroupsQuery{UserId: user.Id}
+		require.NoError(t, GetUserExternalGroups(query))
+		require.Equal(t, []string{"@grafana/sre", "cn=dev", "cn=sre"}, query.Result)
+
+		err = sqlStore.SyncUserExternalGroups(context.Background(), user.Id, models.AuthModuleLDAP, []string{"cn=dev"})
+		require.NoError(t, err)
+
+		require.NoError(t, GetUserExternalGroups(query))
+		require.Equal(t, []string{"@grafana/sre", "cn=dev"}, query.Result)
+	})
+
+	t.Run("Dashboard permissions can reference external groups", func(t *testing.T) {
+		folder := insertTestDashboard(t, sqlStore, "folder", 1, 0, true)
+		dash := insertTestDashboard(t, sqlStore, "dashboard", 1, folder.Id, false)
+		err := testHelperUpdateDashboardAcl(t, sqlStore, folder.Id, models.DashboardAcl{
+			DashboardID:   folder.Id,
+			OrgID:         1,
+			ExternalGroup: "cn=dev",
+			Permission:    models.PERMISSION_EDIT,
+		})
+		require.NoError(t, err)
+
+		aclQuery := models.GetDashboardAclInfoListQuery{DashboardID: dash.Id, OrgID: 1}
+		require.NoError(t, GetDashboardAclInfoList(&aclQuery))
+		require.Len(t, aclQuery.Result, 1)
+		require.Equal(t, "cn=dev", aclQuery.Result[0].ExternalGroup)
+		require.True(t, aclQuery.Result[0].Inherited)
+
+		signedInUser := &models.SignedInUser{UserId: user.Id, OrgId: 1, OrgRole: models.ROLE_VIEWER}
+		query := &search.FindPersistedDashboardsQuery{
+			SignedInUser: signedInUser,
+			OrgId:        1,
+			Permission:   models.PERMISSION_EDIT,
+			DashboardIds: []int64{dash.Id},
+		}
+		require.NoError(t, SearchDashboards(query))
+		require.Len(t, query.Result, 1)
+
+		permissionsQuery := &models.GetDashboardPermissionsForUserQuery{
+			DashboardIds: []int64{dash.Id},
+			OrgId:        1,
+			UserId:       user.Id,
+			OrgRole:      models.ROLE_VIEWER,
+		}
+		require.NoError(t, GetDashboardPermissionsForUser(permissionsQuery))
+		require.Len(t, permissionsQuery.Result, 1)
+		require.Equal(t, models.PERMISSION_EDIT, permissionsQuery.Result[0].Permission)
+
+		// the user leaves the group
+		err = sqlStore.SyncUserExternalGroups(context.Background(), user.Id, models.AuthModuleLDAP, nil)
+		require.NoError(t, err)
+		require.NoError(t, SearchDashboards(query))
+		require.Empty(t, query.Result)
+	})
+}
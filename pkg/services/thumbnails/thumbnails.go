@@ -0,0 +1,228 @@
+// Package thumbnails renders the thumbnails of the dashboards shown in the
+// dashboard browser, and keeps them with the files of the services of the
+// file storage.
+package thumbnails
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/filestorage"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var ErrThumbnailNotFound = errors.New("thumbnail not rendered yet")
+
+var logger = log.New("thumbnails")
+
+const (
+	thumbnailsDir = "thumbnails"
+	// renders are slow, requests beyond the queue size are retried on the
+	// next request of the thumbnail
+	queueSize = 100
+)
+
+func init() {
+	registry.RegisterService(&Service{})
+}
+
+type renderRequest struct {
+	OrgID int64
+	UID   string
+	Slug  string
+}
+
+// Service renders the thumbnails of the dashboards in the background, one at
+// a time, when they are missing or stale.
+type Service struct {
+	Cfg           *setting.Cfg             `inject:""`
+	RenderService rendering.Service        `inject:""`
+	FileStorage   *filestorage.FileStorage `inject:""`
+
+	queue   chan renderRequest
+	mu      sync.Mutex
+	pending map[renderRequest]bool
+}
+
+func (s *Service) Init() error {
+	s.queue = make(chan renderRequest, queueSize)
+	s.pending = map[renderRequest]bool{}
+	return nil
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.Cfg.DashboardThumbnails.Enabled
+}
+
+// IsAvailable returns whether the thumbnails are enabled and can be rendered.
+func (s *Service) IsAvailable() bool {
+	return !s.IsDisabled() && s.RenderService.IsAvailable()
+}
+
+func (s *Service) Run(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case req := <-s.queue:
+			if err := s.render(ctx, req); err != nil {
+				logger.Error("Failed to render dashboard thumbnail", "orgId", req.OrgID, "uid", req.UID, "error", err)
+			}
+
+			s.mu.Lock()
+			delete(s.pending, req)
+			s.mu.Unlock()
+		}
+	}
+}
+
+// GetThumbnail returns the thumbnail of the dashboard, and queues a new
+// render when it's stale. It returns ErrThumbnailNotFound, and queues the
+// first render, when the dashboard doesn't have a thumbnail yet.
+func (s *Service) GetThumbnail(ctx context.Context, dash *models.Dashboard) (*filestorage.File, []byte, error) {
+	file, data, err := s.FileStorage.GetSystemFile(ctx, dash.OrgId, thumbnailPath(dash.Uid))
+	if errors.Is(err, filestorage.ErrFileNotFound) {
+		s.queueRender(dash)
+		return nil, nil, ErrThumbnailNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if s.isStale(file, dash) {
+		s.queueRender(dash)
+	}
+	return file, data, nil
+}
+
+// isStale returns whether the dashboard was updated since its thumbnail was
+// rendered, or the thumbnail is older than the max age.
+func (s *Service) isStale(file *filestorage.File, dash *models.Dashboard) bool {
+	if file.Updated.Before(dash.Updated) {
+		return true
+	}
+	maxAge := s.Cfg.DashboardThumbnails.MaxAge
+	return maxAge > 0 && time.Since(file.Updated) > maxAge
+}
+
+func (s *Service) queueRender(dash *models.Dashboard) {
+	req := renderRequest{OrgID: dash.OrgId, UID: dash.Uid, Slug: dash.Slug}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pending[req] {
+		return
+	}
+
+	select {
+	case s.queue <- req:
+		s.pending[req] = true
+	default:
+		logger.Debug("Dashboard thumbnail render queue is full", "orgId", req.OrgID, "uid", req.UID)
+	}
+}
+
+func (s *Service) render(ctx context.Context, req renderRequest) error {
+	settings := s.Cfg.DashboardThumbnails
+	result, err := s.RenderService.Render(ctx, rendering.Opts{
+		Width:   settings.RenderWidth,
+		Height:  settings.RenderHeight,
+		Timeout: settings.RenderTimeout,
+		OrgID:   req.OrgID,
+		// the thumbnail is shared by everyone who can view the dashboard, so
+		// it shouldn't depend on the permissions of whoever requested it
+		OrgRole:           models.ROLE_ADMIN,
+		Path:              fmt.Sprintf("d/%s/%s?orgId=%d&kiosk", req.UID, req.Slug, req.OrgID),
+		ConcurrentLimit:   s.Cfg.RendererConcurrentRequestLimit,
+		DeviceScaleFactor: 1,
+	})
+	if err != nil {
+		return err
+	}
+
+	// the placeholder images returned when the renderer is unavailable or
+	// busy aren't in the images directory, and mustn't be kept
+	if !strings.HasPrefix(result.FilePath, filepath.Clean(s.Cfg.ImagesDir)+string(filepath.Separator)) {
+		return errors.New("renderer didn't render the dashboard")
+	}
+	defer func() {
+		if err := os.Remove(result.FilePath); err != nil {
+			logger.Warn("Failed to remove dashboard render", "path", result.FilePath, "error", err)
+		}
+	}()
+
+	// nolint:gosec
+	// the path is the one of the render, in the images directory
+	rendered, err := ioutil.ReadFile(result.FilePath)
+	if err != nil {
+		return err
+	}
+	src, err := png.Decode(bytes.NewReader(rendered))
+	if err != nil {
+		return fmt.Errorf("failed to decode dashboard render: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, scaleDown(src, settings.Width)); err != nil {
+		return err
+	}
+
+	// the thumbnails are rendered with the permissions of an admin, they're
+	// kept out of the files of the org and only served to the users who can
+	// view the dashboard
+	_, err = s.FileStorage.UploadSystemFile(ctx, req.OrgID, thumbnailPath(req.UID), buf.Bytes())
+	return err
+}
+
+func thumbnailPath(uid string) string {
+	return thumbnailsDir + "/" + uid + ".png"
+}
+
+// scaleDown returns the image scaled down to the width, keeping its aspect
+// ratio. Each pixel is the average of the pixels of the source it covers.
+func scaleDown(src image.Image, width int) image.Image {
+	bounds := src.Bounds()
+	if width <= 0 || width >= bounds.Dx() {
+		return src
+	}
+	height := bounds.Dy() * width / bounds.Dx()
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*bounds.Dy()/height
+		y1 := bounds.Min.Y + (y+1)*bounds.Dy()/height
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*bounds.Dx()/width
+			x1 := bounds.Min.X + (x+1)*bounds.Dx()/width
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa)
+					n++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(b / n), A: uint16(a / n)})
+		}
+	}
+	return dst
+}
@@ -0,0 +1,170 @@
+package thumbnails
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/filestorage"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRenderService struct {
+	rendering.Service
+	dir  string
+	opts []rendering.Opts
+}
+
+func (rs *fakeRenderService) IsAvailable() bool {
+	return true
+}
+
+func (rs *fakeRenderService) Render(ctx context.Context, opts rendering.Opts) (*rendering.RenderResult, error) {
+	rs.opts = append(rs.opts, opts)
+
+	img := image.NewRGBA(image.Rect(0, 0, opts.Width, opts.Height))
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	filePath := filepath.Join(rs.dir, "render.png")
+	if err := ioutil.WriteFile(filePath, buf.Bytes(), 0600); err != nil {
+		return nil, err
+	}
+	return &rendering.RenderResult{FilePath: filePath}, nil
+}
+
+func setupService(t *testing.T) (*Service, *fakeRenderService) {
+	t.Helper()
+
+	cfg := setting.NewCfg()
+	cfg.ImagesDir = t.TempDir()
+	cfg.RendererConcurrentRequestLimit = 30
+	cfg.DashboardThumbnails = setting.DashboardThumbnailsSettings{
+		Enabled:       true,
+		Width:         320,
+		RenderWidth:   1280,
+		RenderHeight:  960,
+		RenderTimeout: time.Minute,
+		MaxAge:        24 * time.Hour,
+	}
+	cfg.FileStorage = setting.FileStorageSettings{
+		Backend:             setting.FileStorageLocal,
+		LocalPath:           t.TempDir(),
+		OrgQuota:            -1,
+		AllowedContentTypes: []string{"image/png"},
+	}
+
+	fs := &filestorage.FileStorage{Cfg: cfg, SQLStore: sqlstore.InitTestDB(t)}
+	require.NoError(t, fs.Init())
+
+	rs := &fakeRenderService{dir: cfg.ImagesDir}
+	s := &Service{Cfg: cfg, RenderService: rs, FileStorage: fs}
+	require.NoError(t, s.Init())
+	return s, rs
+}
+
+// renderQueued renders the queued thumbnails, like the background process.
+func renderQueued(t *testing.T, s *Service) {
+	t.Helper()
+
+	for {
+		select {
+		case req := <-s.queue:
+			require.NoError(t, s.render(context.Background(), req))
+			delete(s.pending, req)
+		default:
+			return
+		}
+	}
+}
+
+func TestThumbnails(t *testing.T) {
+	ctx := context.Background()
+	dash := &models.Dashboard{OrgId: 1, Uid: "abc", Slug: "my-dashboard", Updated: time.Now().Add(-time.Hour)}
+
+	t.Run("The first request queues the render of the thumbnail", func(t *testing.T) {
+		s, rs := setupService(t)
+
+		_, _, err := s.GetThumbnail(ctx, dash)
+		require.ErrorIs(t, err, ErrThumbnailNotFound)
+		_, _, err = s.GetThumbnail(ctx, dash)
+		require.ErrorIs(t, err, ErrThumbnailNotFound)
+		require.Len(t, s.queue, 1)
+
+		renderQueued(t, s)
+		require.Len(t, rs.opts, 1)
+		assert.Equal(t, "d/abc/my-dashboard?orgId=1&kiosk", rs.opts[0].Path)
+		assert.Equal(t, int64(1), rs.opts[0].OrgID)
+		assert.Equal(t, models.ROLE_ADMIN, rs.opts[0].OrgRole)
+		assert.NoFileExists(t, filepath.Join(rs.dir, "render.png"))
+
+		file, data, err := s.GetThumbnail(ctx, dash)
+		require.NoError(t, err)
+		assert.Equal(t, ".system/thumbnails/abc.png", file.Path)
+		img, err := png.Decode(bytes.NewReader(data))
+		require.NoError(t, err)
+		assert.Equal(t, image.Rect(0, 0, 320, 240), img.Bounds())
+		require.Len(t, s.queue, 0)
+
+		// the thumbnails aren't files of the org, readable or replaceable by the users
+		_, _, err = s.FileStorage.Get(ctx, 1, "thumbnails/abc.png")
+		assert.ErrorIs(t, err, filestorage.ErrFileNotFound)
+		_, _, err = s.FileStorage.Get(ctx, 1, file.Path)
+		assert.ErrorIs(t, err, filestorage.ErrInvalidPath)
+		files, err := s.FileStorage.List(ctx, 1, "")
+		require.NoError(t, err)
+		assert.Empty(t, files)
+	})
+
+	t.Run("The thumbnail is rendered again when the dashboard is updated", func(t *testing.T) {
+		s, _ := setupService(t)
+
+		_, _, err := s.GetThumbnail(ctx, dash)
+		require.ErrorIs(t, err, ErrThumbnailNotFound)
+		renderQueued(t, s)
+
+		updated := *dash
+		updated.Updated = time.Now().Add(time.Minute)
+		_, data, err := s.GetThumbnail(ctx, &updated)
+		require.NoError(t, err)
+		require.NotEmpty(t, data)
+		require.Len(t, s.queue, 1)
+	})
+
+	t.Run("The thumbnail is rendered again when it's older than the max age", func(t *testing.T) {
+		s, _ := setupService(t)
+
+		file := &filestorage.File{Updated: time.Now().Add(-25 * time.Hour)}
+		assert.True(t, s.isStale(file, dash))
+
+		s.Cfg.DashboardThumbnails.MaxAge = 0
+		assert.False(t, s.isStale(file, &models.Dashboard{Updated: file.Updated.Add(-time.Minute)}))
+	})
+}
+
+func TestScaleDown(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for x := 0; x < 4; x++ {
+		src.Set(x, 0, color.White)
+		src.Set(x, 1, color.Black)
+	}
+
+	dst := scaleDown(src, 2)
+	require.Equal(t, image.Rect(0, 0, 2, 1), dst.Bounds())
+	r, g, b, a := dst.At(0, 0).RGBA()
+	assert.Equal(t, []uint32{0x7f7f, 0x7f7f, 0x7f7f, 0xffff}, []uint32{r, g, b, a})
+
+	assert.Equal(t, src, scaleDown(src, 8))
+}
@@ -0,0 +1,52 @@
+package usageexport
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func (s *Service) registerAPIEndpoints() {
+	s.RouteRegister.Get("/api/admin/usage", middleware.ReqGrafanaAdmin, routing.Wrap(s.usageHandler))
+}
+
+// usageHandler handles GET /api/admin/usage, returning the usage of the orgs
+// in a time range, the last interval by default.
+func (s *Service) usageHandler(c *models.ReqContext) response.Response {
+	to := time.Now()
+	if ms := c.QueryInt64("to"); ms > 0 {
+		to = time.Unix(0, ms*int64(time.Millisecond))
+	}
+	from := to.Add(-s.Cfg.UsageExport.Interval)
+	if ms := c.QueryInt64("from"); ms > 0 {
+		from = time.Unix(0, ms*int64(time.Millisecond))
+	}
+	if !from.Before(to) {
+		return response.Error(400, "from must be before to", nil)
+	}
+	format := c.Query("format")
+	if format == "" {
+		format = setting.UsageExportFormatJSON
+	}
+	if format != setting.UsageExportFormatJSON && format != setting.UsageExportFormatCSV {
+		return response.Error(400, "format must be json or csv", nil)
+	}
+
+	report, err := s.report(c.Req.Context(), from, to)
+	if err != nil {
+		return response.Error(500, "Failed to get the usage", err)
+	}
+	if format == setting.UsageExportFormatJSON {
+		return response.JSON(200, report)
+	}
+
+	content, contentType, err := report.encode(format)
+	if err != nil {
+		return response.Error(500, "Failed to encode the usage", err)
+	}
+	return response.Respond(200, content).SetHeader("Content-Type", contentType)
+}
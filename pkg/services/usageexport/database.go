@@ -0,0 +1,72 @@
+package usageexport
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// addAlertEvaluations adds the evaluations to the evaluations of their hours
+// in the database.
+func (s *Service) addAlertEvaluations(ctx context.Context, evaluations map[evaluationKey]int64) error {
+	return s.SQLStore.WithTransactionalDbSession(ctx, func(session *sqlstore.DBSession) error {
+		for key, count := range evaluations {
+			result, err := session.Exec("UPDATE alert_evaluation_usage SET evaluations = evaluations + ? "+
+				"WHERE org_id = ? AND period = ?", count, key.orgID, key.period)
+			if err != nil {
+				return err
+			}
+			if affected, err := result.RowsAffected(); err != nil {
+				return err
+			} else if affected > 0 {
+				continue
+			}
+
+			usage := &AlertEvaluationUsage{OrgID: key.orgID, Period: key.period, Evaluations: count}
+			if _, err := session.Insert(usage); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// searchOrgUsage returns the usage of the orgs in the period between from and
+// to, by org ID.
+func (s *Service) searchOrgUsage(ctx context.Context, from, to time.Time) ([]OrgUsage, error) {
+	dialect := s.SQLStore.Dialect
+	// the queries and evaluations are aggregated by hour, so the hour of from
+	// is included
+	periodFrom, periodTo := from.Truncate(time.Hour).Unix(), to.Unix()
+
+	result := make([]OrgUsage, 0)
+	err := s.SQLStore.WithDbSession(ctx, func(session *sqlstore.DBSession) error {
+		return session.SQL(`SELECT
+			org.id AS org_id,
+			org.name AS org_name,
+			(SELECT COUNT(*) FROM org_user WHERE org_user.org_id = org.id) AS users,
+			(SELECT COUNT(*) FROM org_user
+				INNER JOIN `+dialect.Quote("user")+` AS u ON u.id = org_user.user_id
+				WHERE org_user.org_id = org.id AND u.last_seen_at >= ?) AS active_users,
+			(SELECT COUNT(*) FROM dashboard WHERE dashboard.org_id = org.id AND dashboard.is_folder = ?) AS dashboards,
+			(SELECT COALESCE(SUM(query_cost.queries), 0) FROM query_cost
+				WHERE query_cost.org_id = org.id AND query_cost.period >= ? AND query_cost.period < ?) AS queries,
+			(SELECT COALESCE(SUM(alert_evaluation_usage.evaluations), 0) FROM alert_evaluation_usage
+				WHERE alert_evaluation_usage.org_id = org.id AND alert_evaluation_usage.period >= ?
+				AND alert_evaluation_usage.period < ?) AS alert_evaluations
+		FROM org
+		ORDER BY org.id`,
+			from, dialect.BooleanStr(false), periodFrom, periodTo, periodFrom, periodTo).Find(&result)
+	})
+	return result, err
+}
+
+// deleteAlertEvaluations deletes the alert evaluations of the hours before a
+// unix time.
+func (s *Service) deleteAlertEvaluations(ctx context.Context, before int64) error {
+	return s.SQLStore.WithDbSession(ctx, func(session *sqlstore.DBSession) error {
+		_, err := session.Exec("DELETE FROM alert_evaluation_usage WHERE period < ?", before)
+		return err
+	})
+}
@@ -0,0 +1,86 @@
+package usageexport
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// AlertEvaluationUsage is the model for the evaluations of the alert rules of
+// an org in an hour.
+type AlertEvaluationUsage struct {
+	ID    int64 `xorm:"pk autoincr 'id'"`
+	OrgID int64 `xorm:"org_id"`
+	// Period is the unix time in seconds of the start of the hour
+	Period      int64
+	Evaluations int64
+}
+
+// OrgUsage is the usage of an org in a period. The users and dashboards are
+// counted at the time of the export.
+type OrgUsage struct {
+	OrgID   int64  `json:"orgId" xorm:"org_id"`
+	OrgName string `json:"orgName"`
+	Users   int64  `json:"users"`
+	// ActiveUsers are the users of the org seen since the start of the period
+	ActiveUsers int64 `json:"activeUsers"`
+	Dashboards  int64 `json:"dashboards"`
+	// Queries are the data source queries recorded by the query costs
+	Queries          int64 `json:"queries"`
+	AlertEvaluations int64 `json:"alertEvaluations"`
+}
+
+// Report is the usage of the orgs in a period.
+type Report struct {
+	From time.Time  `json:"from"`
+	To   time.Time  `json:"to"`
+	Orgs []OrgUsage `json:"orgs"`
+}
+
+var csvHeader = []string{"from", "to", "org_id", "org_name", "users", "active_users", "dashboards", "queries", "alert_evaluations"}
+
+// encode returns the content of the report in the format, csv or json, and
+// its content type.
+func (r *Report) encode(format string) ([]byte, string, error) {
+	switch format {
+	case setting.UsageExportFormatJSON:
+		content, err := json.Marshal(r)
+		return content, "application/json", err
+	case setting.UsageExportFormatCSV:
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		if err := w.Write(csvHeader); err != nil {
+			return nil, "", err
+		}
+		from, to := r.From.Format(time.RFC3339), r.To.Format(time.RFC3339)
+		for _, org := range r.Orgs {
+			err := w.Write([]string{
+				from,
+				to,
+				strconv.FormatInt(org.OrgID, 10),
+				org.OrgName,
+				strconv.FormatInt(org.Users, 10),
+				strconv.FormatInt(org.ActiveUsers, 10),
+				strconv.FormatInt(org.Dashboards, 10),
+				strconv.FormatInt(org.Queries, 10),
+				strconv.FormatInt(org.AlertEvaluations, 10),
+			})
+			if err != nil {
+				return nil, "", err
+			}
+		}
+		w.Flush()
+		return buf.Bytes(), "text/csv", w.Error()
+	}
+	return nil, "", fmt.Errorf("invalid usage export format: %q", format)
+}
+
+type evaluationKey struct {
+	orgID  int64
+	period int64
+}
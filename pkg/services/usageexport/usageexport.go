@@ -0,0 +1,199 @@
+// Package usageexport exports the usage of the orgs, their users, active
+// users, dashboards, queries and alert evaluations, as CSV or JSON files
+// stored with the file storage or posted to a webhook at every interval, e.g.
+// for the hosts of many tenant orgs to bill them. The alert evaluations are
+// recorded by org and hour in the database, and the queries are the query
+// costs of the querycost package.
+package usageexport
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/filestorage"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// tickInterval is how often the recorded alert evaluations are written to
+	// the database and the last period is exported when it wasn't yet.
+	tickInterval = time.Minute
+	// exportDelay is the delay of the export after the end of a period, so
+	// that the alert evaluations of all the servers are written.
+	exportDelay = 5 * time.Minute
+	// cleanUpInterval is how often the alert evaluations older than the
+	// retention are deleted.
+	cleanUpInterval = time.Hour
+)
+
+func init() {
+	registry.RegisterService(&Service{})
+}
+
+// Service records the alert evaluations of the orgs and exports their usage.
+type Service struct {
+	Cfg           *setting.Cfg                  `inject:""`
+	SQLStore      *sqlstore.SQLStore            `inject:""`
+	ServerLock    *serverlock.ServerLockService `inject:""`
+	FileStorage   *filestorage.FileStorage      `inject:""`
+	RouteRegister routing.RouteRegister         `inject:""`
+	log           log.Logger
+
+	mu sync.Mutex
+	// pending are the alert evaluations recorded since the last flush
+	pending map[evaluationKey]int64
+}
+
+// Init initializes the usage export service.
+func (s *Service) Init() error {
+	s.log = log.New("usage-export")
+	s.pending = map[evaluationKey]int64{}
+
+	s.registerAPIEndpoints()
+
+	return nil
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.Cfg.UsageExport.Enabled
+}
+
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+	cleanUpTicker := time.NewTicker(cleanUpInterval)
+	defer cleanUpTicker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.flush(ctx)
+			s.exportLastPeriod(ctx, time.Now())
+		case <-cleanUpTicker.C:
+			before := time.Now().AddDate(0, 0, -s.Cfg.UsageExport.RetentionDays)
+			if err := s.deleteAlertEvaluations(ctx, before.Unix()); err != nil {
+				s.log.Error("Failed to delete old alert evaluations", "error", err)
+			}
+		case <-ctx.Done():
+			// the evaluations recorded since the last flush are written before exiting
+			s.flush(context.Background())
+			return ctx.Err()
+		}
+	}
+}
+
+// RecordAlertEvaluation records an evaluation of an alert rule of an org.
+func (s *Service) RecordAlertEvaluation(orgID int64) {
+	if s == nil || s.IsDisabled() {
+		return
+	}
+
+	key := evaluationKey{orgID: orgID, period: time.Now().Truncate(time.Hour).Unix()}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending[key]++
+}
+
+// flush writes the pending alert evaluations to the database. The evaluations
+// which failed to be written are kept for the next flush.
+func (s *Service) flush(ctx context.Context) {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = map[evaluationKey]int64{}
+	s.mu.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+
+	if err := s.addAlertEvaluations(ctx, pending); err != nil {
+		s.log.Error("Failed to write the alert evaluations", "error", err)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for key, count := range pending {
+			s.pending[key] += count
+		}
+	}
+}
+
+// exportLastPeriod exports the last period ended before now, unless it was
+// already exported by this server or another one.
+func (s *Service) exportLastPeriod(ctx context.Context, now time.Time) {
+	interval := s.Cfg.UsageExport.Interval
+	to := now.Add(-exportDelay).Truncate(interval)
+	from := to.Add(-interval)
+
+	// the lock of each period is only executed once
+	actionName := "usage export " + to.UTC().Format(time.RFC3339)
+	err := s.ServerLock.LockAndExecute(ctx, actionName, interval, func() {
+		if err := s.export(ctx, from, to); err != nil {
+			s.log.Error("Failed to export the usage", "from", from, "to", to, "error", err)
+			return
+		}
+		s.log.Info("Exported the usage", "from", from, "to", to)
+	})
+	if err != nil {
+		s.log.Error("Failed to lock the usage export", "from", from, "to", to, "error", err)
+	}
+}
+
+// export exports the usage of the period between from and to, to the file
+// storage and the webhook.
+func (s *Service) export(ctx context.Context, from, to time.Time) error {
+	report, err := s.report(ctx, from, to)
+	if err != nil {
+		return err
+	}
+
+	settings := s.Cfg.UsageExport
+	content, contentType, err := report.encode(settings.Format)
+	if err != nil {
+		return err
+	}
+
+	if settings.Storage {
+		name := fmt.Sprintf("usage/usage-%s.%s", from.UTC().Format("20060102T150405Z"), settings.Format)
+		if err := s.FileStorage.UploadInstanceFile(ctx, name, contentType, content); err != nil {
+			return fmt.Errorf("failed to store the usage export: %w", err)
+		}
+	}
+
+	if settings.WebhookURL != "" {
+		cmd := &models.SendWebhookSync{
+			Url:         settings.WebhookURL,
+			User:        settings.WebhookUsername,
+			Password:    settings.WebhookPassword,
+			Body:        string(content),
+			HttpMethod:  "POST",
+			ContentType: contentType,
+			HttpHeader: map[string]string{
+				"X-Grafana-Usage-From": from.UTC().Format(time.RFC3339),
+				"X-Grafana-Usage-To":   to.UTC().Format(time.RFC3339),
+			},
+		}
+		if err := bus.DispatchCtx(ctx, cmd); err != nil {
+			return fmt.Errorf("failed to post the usage export: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// report returns the usage of the orgs in the period between from and to.
+func (s *Service) report(ctx context.Context, from, to time.Time) (*Report, error) {
+	orgs, err := s.searchOrgUsage(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return &Report{From: from.UTC(), To: to.UTC(), Orgs: orgs}, nil
+}
@@ -0,0 +1,145 @@
+package usageexport
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/macaron.v1"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/filestorage"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestUsageExport(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	storagePath := t.TempDir()
+	cfg := setting.NewCfg()
+	cfg.UsageExport = setting.UsageExportSettings{
+		Enabled:       true,
+		Interval:      24 * time.Hour,
+		Format:        setting.UsageExportFormatCSV,
+		Storage:       true,
+		WebhookURL:    "http://billing.example.com/usage",
+		RetentionDays: 90,
+	}
+	cfg.FileStorage = setting.FileStorageSettings{Backend: setting.FileStorageLocal, LocalPath: storagePath}
+	fs := &filestorage.FileStorage{Cfg: cfg, SQLStore: sqlStore}
+	require.NoError(t, fs.Init())
+	serverLock := &serverlock.ServerLockService{SQLStore: sqlStore}
+	require.NoError(t, serverLock.Init())
+	s := &Service{
+		Cfg:         cfg,
+		SQLStore:    sqlStore,
+		ServerLock:  serverLock,
+		FileStorage: fs,
+		log:         log.New("usage-export"),
+		pending:     map[evaluationKey]int64{},
+	}
+
+	ctx := context.Background()
+	active, err := sqlStore.CreateUser(ctx, models.CreateUserCommand{Login: "tenant-a"})
+	require.NoError(t, err)
+	require.NoError(t, sqlstore.UpdateUserLastSeenAt(&models.UpdateUserLastSeenAtCommand{UserId: active.Id}))
+	inactive, err := sqlStore.CreateUser(ctx, models.CreateUserCommand{Login: "tenant-b"})
+	require.NoError(t, err)
+	_, err = sqlStore.SaveDashboard(models.SaveDashboardCommand{
+		OrgId:     active.OrgId,
+		Dashboard: simplejson.NewFromAny(map[string]interface{}{"title": "Billing"}),
+	})
+	require.NoError(t, err)
+
+	now := time.Now()
+	err = sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.Exec("INSERT INTO query_cost (org_id, datasource_id, dashboard_id, period, queries, errors, scanned_bytes, duration_ms) "+
+			"VALUES (?, 1, 0, ?, 5, 0, 0, 0)", active.OrgId, now.Truncate(time.Hour).Unix())
+		return err
+	})
+	require.NoError(t, err)
+	s.RecordAlertEvaluation(active.OrgId)
+	s.RecordAlertEvaluation(active.OrgId)
+	s.flush(ctx)
+	// the evaluations are added to the evaluations of the hour in the database
+	s.RecordAlertEvaluation(active.OrgId)
+	s.RecordAlertEvaluation(inactive.OrgId)
+	s.flush(ctx)
+
+	t.Run("Reports the usage of the orgs", func(t *testing.T) {
+		report, err := s.report(ctx, now.Add(-time.Hour), now.Add(time.Hour))
+		require.NoError(t, err)
+		require.Len(t, report.Orgs, 2)
+		assert.Equal(t, OrgUsage{
+			OrgID:            active.OrgId,
+			OrgName:          "tenant-a",
+			Users:            1,
+			ActiveUsers:      1,
+			Dashboards:       1,
+			Queries:          5,
+			AlertEvaluations: 3,
+		}, report.Orgs[0])
+		assert.Equal(t, OrgUsage{OrgID: inactive.OrgId, OrgName: "tenant-b", Users: 1, AlertEvaluations: 1}, report.Orgs[1])
+
+		report, err = s.report(ctx, now.Add(-48*time.Hour), now.Add(-24*time.Hour))
+		require.NoError(t, err)
+		assert.Zero(t, report.Orgs[0].Queries)
+		assert.Zero(t, report.Orgs[0].AlertEvaluations)
+	})
+
+	t.Run("Exports the last period once to the file storage and the webhook", func(t *testing.T) {
+		var webhooks []*models.SendWebhookSync
+		bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.SendWebhookSync) error {
+			webhooks = append(webhooks, cmd)
+			return nil
+		})
+		t.Cleanup(bus.ClearBusHandlers)
+
+		exportTime := now.Truncate(24 * time.Hour).Add(24*time.Hour + 10*time.Minute)
+		s.exportLastPeriod(ctx, exportTime)
+		s.exportLastPeriod(ctx, exportTime.Add(time.Minute))
+
+		from := now.Truncate(24 * time.Hour).UTC()
+		content, err := ioutil.ReadFile(filepath.Join(storagePath, "instance", "usage",
+			"usage-"+from.Format("20060102T150405Z")+".csv"))
+		require.NoError(t, err)
+		period := from.Format(time.RFC3339) + "," + from.Add(24*time.Hour).Format(time.RFC3339)
+		assert.Equal(t, "from,to,org_id,org_name,users,active_users,dashboards,queries,alert_evaluations\n"+
+			period+","+strconv.FormatInt(active.OrgId, 10)+",tenant-a,1,1,1,5,3\n"+
+			period+","+strconv.FormatInt(inactive.OrgId, 10)+",tenant-b,1,0,0,0,1\n", string(content))
+
+		require.Len(t, webhooks, 1)
+		assert.Equal(t, "http://billing.example.com/usage", webhooks[0].Url)
+		assert.Equal(t, "text/csv", webhooks[0].ContentType)
+		assert.Equal(t, string(content), webhooks[0].Body)
+		assert.Equal(t, from.Format(time.RFC3339), webhooks[0].HttpHeader["X-Grafana-Usage-From"])
+	})
+
+	t.Run("Returns the usage with the API", func(t *testing.T) {
+		from := now.Add(-time.Hour).UnixNano() / int64(time.Millisecond)
+		req, err := http.NewRequest(http.MethodGet, "/api/admin/usage?from="+strconv.FormatInt(from, 10), nil)
+		require.NoError(t, err)
+		c := &models.ReqContext{
+			Context:      &macaron.Context{Req: macaron.Request{Request: req}},
+			SignedInUser: &models.SignedInUser{IsGrafanaAdmin: true},
+		}
+		resp := s.usageHandler(c)
+		require.Equal(t, 200, resp.Status())
+
+		var report Report
+		require.NoError(t, json.Unmarshal(resp.Body(), &report))
+		require.Len(t, report.Orgs, 2)
+		assert.Equal(t, int64(3), report.Orgs[0].AlertEvaluations)
+	})
+}
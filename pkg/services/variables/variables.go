@@ -0,0 +1,228 @@
+// Package variables resolves the state of a dashboard the user saw, its
+// variable values and annotation toggles, to the URL parameters restoring it
+// in the frontend, e.g. for the renderer to match what the user shared, or to
+// the values of the variables of the dashboards resolved by the server.
+package variables
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/registry"
+)
+
+var (
+	ErrUnknownVariable      = errors.New("unknown variable")
+	ErrInvalidVariableValue = errors.New("invalid variable value")
+	ErrUnknownAnnotation    = errors.New("unknown annotation")
+)
+
+// allValue is the value of the All option of the variables.
+const allValue = "$__all"
+
+func init() {
+	registry.RegisterService(&Service{})
+}
+
+// DashboardState is the state of a dashboard that differs from its saved
+// model.
+type DashboardState struct {
+	// Variables are the values of the variables by name, a string, number
+	// or boolean, or a list of them for the multi-value variables
+	Variables map[string]interface{}
+	// Annotations are whether the annotations are enabled by name
+	Annotations map[string]bool
+}
+
+// Service resolves the state of dashboards against their model.
+type Service struct{}
+
+func (s *Service) Init() error {
+	return nil
+}
+
+// ResolveURLParams validates the state against the variables and the
+// annotations of the dashboard, and returns the var-<name> and
+// annotation-<name> URL parameters restoring it.
+func (s *Service) ResolveURLParams(dashboard *simplejson.Json, state DashboardState) (url.Values, error) {
+	params := url.Values{}
+
+	variables := map[string]*simplejson.Json{}
+	for _, v := range dashboard.GetPath("templating", "list").MustArray() {
+		variable := simplejson.NewFromAny(v)
+		variables[variable.Get("name").MustString()] = variable
+	}
+
+	for name, value := range state.Variables {
+		variable, ok := variables[name]
+		if !ok {
+			return nil, fmt.Errorf("%w %q", ErrUnknownVariable, name)
+		}
+
+		values, err := resolveValues(variable, value)
+		if err != nil {
+			return nil, fmt.Errorf("%w of variable %q: %s", ErrInvalidVariableValue, name, err)
+		}
+		params["var-"+name] = values
+	}
+
+	annotations := map[string]bool{}
+	for _, a := range dashboard.GetPath("annotations", "list").MustArray() {
+		annotations[simplejson.NewFromAny(a).Get("name").MustString()] = true
+	}
+
+	for name, enabled := range state.Annotations {
+		if !annotations[name] {
+			return nil, fmt.Errorf("%w %q", ErrUnknownAnnotation, name)
+		}
+		if enabled {
+			params.Set("annotation-"+name, "on")
+		} else {
+			params.Set("annotation-"+name, "off")
+		}
+	}
+
+	return params, nil
+}
+
+// ResolveValues validates the values of the variables of the state, and
+// returns the values of all the variables of the dashboard by name: those of
+// the state, or else the current values of the variables. The All value is
+// expanded to the custom all value of the variable, or else to the values of
+// its saved options.
+func (s *Service) ResolveValues(dashboard *simplejson.Json, state DashboardState) (map[string][]string, error) {
+	stateValues := map[string][]string{}
+	variables := map[string]*simplejson.Json{}
+	for _, v := range dashboard.GetPath("templating", "list").MustArray() {
+		variable := simplejson.NewFromAny(v)
+		variables[variable.Get("name").MustString()] = variable
+	}
+	for name, value := range state.Variables {
+		variable, ok := variables[name]
+		if !ok {
+			return nil, fmt.Errorf("%w %q", ErrUnknownVariable, name)
+		}
+		values, err := resolveValues(variable, value)
+		if err != nil {
+			return nil, fmt.Errorf("%w of variable %q: %s", ErrInvalidVariableValue, name, err)
+		}
+		stateValues[name] = values
+	}
+
+	result := map[string][]string{}
+	for name, variable := range variables {
+		values, ok := stateValues[name]
+		if !ok {
+			values = currentValues(variable)
+		}
+		result[name] = expandAll(variable, values)
+	}
+	return result, nil
+}
+
+// currentValues returns the current values of a variable, the query of the
+// constant variables.
+func currentValues(variable *simplejson.Json) []string {
+	if variable.Get("type").MustString() == "constant" {
+		return []string{variable.Get("query").MustString()}
+	}
+
+	var values []string
+	switch v := variable.GetPath("current", "value").Interface().(type) {
+	case []interface{}:
+		for _, item := range v {
+			if s, err := valueString(item); err == nil {
+				values = append(values, s)
+			}
+		}
+	default:
+		if s, err := valueString(v); err == nil {
+			values = []string{s}
+		}
+	}
+	return values
+}
+
+// expandAll returns the values of the variable the All value stands for.
+func expandAll(variable *simplejson.Json, values []string) []string {
+	if len(values) != 1 || values[0] != allValue {
+		return values
+	}
+	if custom := variable.Get("allValue").MustString(); custom != "" {
+		return []string{custom}
+	}
+
+	expanded := []string{}
+	for _, o := range variable.Get("options").MustArray() {
+		if value := simplejson.NewFromAny(o).Get("value").MustString(); value != allValue && value != "" {
+			expanded = append(expanded, value)
+		}
+	}
+	return expanded
+}
+
+// resolveValues returns the values of the variable. The values of the
+// variables with static options must be among them, the options of the query
+// variables are refreshed by the frontend so their values are kept as is.
+func resolveValues(variable *simplejson.Json, value interface{}) ([]string, error) {
+	var values []string
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			s, err := valueString(item)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, s)
+		}
+	default:
+		s, err := valueString(v)
+		if err != nil {
+			return nil, err
+		}
+		values = []string{s}
+	}
+
+	if len(values) == 0 {
+		return nil, errors.New("no value")
+	}
+	if len(values) > 1 && !variable.Get("multi").MustBool() {
+		return nil, errors.New("the variable doesn't allow multiple values")
+	}
+
+	switch variable.Get("type").MustString() {
+	case "constant":
+		return nil, errors.New("constant variables can't be changed")
+	case "custom", "interval":
+		options := map[string]bool{}
+		for _, o := range variable.Get("options").MustArray() {
+			options[simplejson.NewFromAny(o).Get("value").MustString()] = true
+		}
+		for _, s := range values {
+			if !options[s] && !(s == allValue && variable.Get("includeAll").MustBool()) {
+				return nil, fmt.Errorf("%q isn't an option of the variable", s)
+			}
+		}
+	}
+
+	return values, nil
+}
+
+func valueString(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		return "", fmt.Errorf("values must be strings, numbers or booleans, got %T", value)
+	}
+}
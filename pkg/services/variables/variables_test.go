@@ -0,0 +1,97 @@
+package variables
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/stretchr/testify/require"
+)
+
+func testDashboard(t *testing.T) *simplejson.Json {
+	t.Helper()
+
+	dashboard, err := simplejson.NewJson([]byte(`{
+		"templating": {"list": [
+			{"name": "host", "type": "query", "multi": true},
+			{"name": "env", "type": "custom", "includeAll": true, "options": [{"text": "prod", "value": "prod"}, {"text": "dev", "value": "dev"}]},
+			{"name": "region", "type": "custom", "options": [{"text": "eu", "value": "eu"}]},
+			{"name": "version", "type": "constant", "query": "1"}
+		]},
+		"annotations": {"list": [
+			{"name": "Annotations & Alerts", "builtIn": 1, "enable": true},
+			{"name": "Deployments", "enable": false}
+		]}
+	}`))
+	require.NoError(t, err)
+	return dashboard
+}
+
+func TestResolveURLParams(t *testing.T) {
+	s := &Service{}
+
+	t.Run("resolves the variables and the annotations", func(t *testing.T) {
+		params, err := s.ResolveURLParams(testDashboard(t), DashboardState{
+			Variables: map[string]interface{}{
+				"host": []interface{}{"a", "b"},
+				"env":  "$__all",
+			},
+			Annotations: map[string]bool{"Annotations & Alerts": false, "Deployments": true},
+		})
+		require.NoError(t, err)
+		require.Equal(t, url.Values{
+			"var-host":                        {"a", "b"},
+			"var-env":                         {"$__all"},
+			"annotation-Annotations & Alerts": {"off"},
+			"annotation-Deployments":          {"on"},
+		}, params)
+	})
+
+	t.Run("rejects invalid states", func(t *testing.T) {
+		for name, state := range map[string]DashboardState{
+			"unknown variable":   {Variables: map[string]interface{}{"cluster": "a"}},
+			"unknown option":     {Variables: map[string]interface{}{"env": "staging"}},
+			"all not included":   {Variables: map[string]interface{}{"region": "$__all"}},
+			"multiple values":    {Variables: map[string]interface{}{"env": []interface{}{"prod", "dev"}}},
+			"no value":           {Variables: map[string]interface{}{"host": []interface{}{}}},
+			"object value":       {Variables: map[string]interface{}{"host": map[string]interface{}{}}},
+			"constant":           {Variables: map[string]interface{}{"version": "2"}},
+			"unknown annotation": {Annotations: map[string]bool{"Incidents": true}},
+		} {
+			_, err := s.ResolveURLParams(testDashboard(t), state)
+			require.Error(t, err, name)
+		}
+	})
+}
+
+func TestResolveValues(t *testing.T) {
+	s := &Service{}
+
+	dashboard, err := simplejson.NewJson([]byte(`{
+		"templating": {"list": [
+			{"name": "host", "type": "query", "multi": true, "current": {"value": ["a", "b"]}},
+			{"name": "env", "type": "custom", "includeAll": true, "current": {"value": "$__all"},
+				"options": [{"text": "All", "value": "$__all"}, {"text": "prod", "value": "prod"}, {"text": "dev", "value": "dev"}]},
+			{"name": "region", "type": "custom", "includeAll": true, "allValue": ".*", "current": {"value": "eu"},
+				"options": [{"text": "eu", "value": "eu"}, {"text": "us", "value": "us"}]},
+			{"name": "version", "type": "constant", "query": "1"},
+			{"name": "step", "type": "textbox", "current": {"value": 30}}
+		]}
+	}`))
+	require.NoError(t, err)
+
+	values, err := s.ResolveValues(dashboard, DashboardState{
+		Variables: map[string]interface{}{"region": "$__all"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string][]string{
+		"host":    {"a", "b"},
+		"env":     {"prod", "dev"},
+		"region":  {".*"},
+		"version": {"1"},
+		"step":    {"30"},
+	}, values)
+
+	_, err = s.ResolveValues(dashboard, DashboardState{Variables: map[string]interface{}{"env": "staging"}})
+	require.ErrorIs(t, err, ErrInvalidVariableValue)
+}
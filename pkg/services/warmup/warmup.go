@@ -0,0 +1,290 @@
+// Package warmup runs the warm-up queries of the data sources on their
+// schedule, e.g. to prime the caches of a columnstore or to keep a serverless
+// warehouse from suspending. The runs of each query are kept in memory, and
+// the org admins are emailed when a query starts to fail. The queries are run
+// by the leader of the servers only, so their runs are kept by the leader.
+package warmup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/leaderelection"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb"
+)
+
+// tickInterval is how often the data sources are checked for warm-up queries
+// to run.
+const tickInterval = 10 * time.Second
+
+func init() {
+	registry.RegisterService(&Service{})
+}
+
+// Run is a run of a warm-up query.
+type Run struct {
+	Started  time.Time
+	Duration time.Duration
+	// Error is the error of the query, empty when it succeeded
+	Error string
+}
+
+type queryKey struct {
+	dataSourceID int64
+	name         string
+}
+
+type queryState struct {
+	next    time.Time
+	running bool
+	// runs are the last runs of the query, oldest first
+	runs []Run
+}
+
+// Service runs the warm-up queries of the jsonData of the data sources.
+type Service struct {
+	Cfg                    *setting.Cfg                  `inject:""`
+	Bus                    bus.Bus                       `inject:""`
+	DataService            *tsdb.Service                 `inject:""`
+	PluginRequestValidator models.PluginRequestValidator `inject:""`
+	LeaderElection         *leaderelection.Service       `inject:""`
+
+	log     log.Logger
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	queries map[queryKey]*queryState
+}
+
+func (s *Service) Init() error {
+	s.log = log.New("datasource.warmup")
+	s.queries = map[queryKey]*queryState{}
+	return nil
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.Cfg.DataSourceWarmUp.Enabled
+}
+
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+
+	s.tick(ctx, time.Now())
+	for {
+		select {
+		case now := <-ticker.C:
+			s.tick(ctx, now)
+		case <-ctx.Done():
+			s.wg.Wait()
+			return ctx.Err()
+		}
+	}
+}
+
+// Runs returns the last runs of the warm-up queries of a data source by query
+// name, newest first.
+func (s *Service) Runs(dataSourceID int64) map[string][]Run {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := map[string][]Run{}
+	for key, state := range s.queries {
+		if key.dataSourceID != dataSourceID {
+			continue
+		}
+		runs := make([]Run, 0, len(state.runs))
+		for i := len(state.runs) - 1; i >= 0; i-- {
+			runs = append(runs, state.runs[i])
+		}
+		result[key.name] = runs
+	}
+	return result
+}
+
+// tick starts the warm-up queries which are due and not running, and forgets
+// the queries which were removed. It does nothing unless the server is the
+// leader.
+func (s *Service) tick(ctx context.Context, now time.Time) {
+	if !s.LeaderElection.IsLeader() {
+		return
+	}
+
+	query := &models.GetAllDataSourcesQuery{}
+	if err := s.Bus.Dispatch(query); err != nil {
+		s.log.Error("Failed to get the data sources", "error", err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen := map[queryKey]bool{}
+	for _, ds := range query.Result {
+		warmUpQueries, err := ds.WarmUpQueries()
+		if err != nil {
+			s.log.Warn("Invalid warm-up queries of data source", "datasource", ds.Name, "orgId", ds.OrgId, "error", err)
+			continue
+		}
+
+		for _, warmUpQuery := range warmUpQueries {
+			key := queryKey{dataSourceID: ds.Id, name: warmUpQuery.Name}
+			seen[key] = true
+
+			state, ok := s.queries[key]
+			if !ok {
+				state = &queryState{next: now}
+				s.queries[key] = state
+			}
+			if state.running || now.Before(state.next) {
+				continue
+			}
+
+			state.running = true
+			state.next = now.Add(warmUpQuery.IntervalDuration())
+			s.wg.Add(1)
+			go func(ds *models.DataSource, warmUpQuery models.DataSourceWarmUpQuery) {
+				defer s.wg.Done()
+				s.run(ctx, ds, warmUpQuery)
+			}(ds, warmUpQuery)
+		}
+	}
+
+	for key, state := range s.queries {
+		if !seen[key] && !state.running {
+			delete(s.queries, key)
+		}
+	}
+}
+
+// run runs a warm-up query as an admin of the org of its data source, and
+// records the run.
+func (s *Service) run(ctx context.Context, ds *models.DataSource, warmUpQuery models.DataSourceWarmUpQuery) {
+	queryCtx, cancel := context.WithTimeout(ctx, warmUpQuery.IntervalDuration())
+	defer cancel()
+
+	started := time.Now()
+	err := s.query(queryCtx, ds, warmUpQuery)
+	run := Run{Started: started, Duration: time.Since(started)}
+	if err != nil {
+		run.Error = err.Error()
+		s.log.Warn("Warm-up query failed", "datasource", ds.Name, "orgId", ds.OrgId, "query", warmUpQuery.Name, "error", err)
+	} else {
+		s.log.Debug("Warm-up query succeeded", "datasource", ds.Name, "orgId", ds.OrgId, "query", warmUpQuery.Name,
+			"duration", run.Duration)
+	}
+
+	s.mu.Lock()
+	key := queryKey{dataSourceID: ds.Id, name: warmUpQuery.Name}
+	state, ok := s.queries[key]
+	if !ok {
+		state = &queryState{}
+		s.queries[key] = state
+	}
+	// the admins are notified when the query starts to fail, not on every
+	// failed run
+	startedToFail := err != nil && (len(state.runs) == 0 || state.runs[len(state.runs)-1].Error == "")
+	state.running = false
+	state.runs = append(state.runs, run)
+	if size := s.Cfg.DataSourceWarmUp.HistorySize; len(state.runs) > size {
+		state.runs = state.runs[len(state.runs)-size:]
+	}
+	s.mu.Unlock()
+
+	if startedToFail && s.Cfg.DataSourceWarmUp.NotifyFailures {
+		s.notifyFailure(ds, warmUpQuery, err)
+	}
+}
+
+func (s *Service) query(ctx context.Context, ds *models.DataSource, warmUpQuery models.DataSourceWarmUpQuery) error {
+	if err := s.PluginRequestValidator.Validate(ds.Url, nil); err != nil {
+		return fmt.Errorf("access denied: %w", err)
+	}
+
+	from, to := warmUpQuery.From, warmUpQuery.To
+	if from == "" {
+		from = "now-5m"
+	}
+	if to == "" {
+		to = "now"
+	}
+	// The relative times are evaluated like for the users of the organization of the data source
+	timeRange, err := plugins.NewDataTimeRangeForUser(ctx, from, to, &models.SignedInUser{OrgId: ds.OrgId})
+	if err != nil {
+		s.log.Warn("Failed to get the preferences of the organization", "err", err, "orgId", ds.OrgId)
+	}
+
+	model := warmUpQuery.Query
+	model.Set("refId", "A")
+	request := plugins.DataQuery{
+		TimeRange: &timeRange,
+		User:      &models.SignedInUser{OrgId: ds.OrgId, OrgRole: models.ROLE_ADMIN, Login: "datasource-warm-up"},
+		Queries: []plugins.DataSubQuery{
+			{
+				RefID:         "A",
+				MaxDataPoints: model.Get("maxDataPoints").MustInt64(100),
+				IntervalMS:    model.Get("intervalMs").MustInt64(1000),
+				QueryType:     model.Get("queryType").MustString(""),
+				Model:         model,
+				DataSource:    ds,
+			},
+		},
+	}
+
+	resp, err := s.DataService.HandleRequest(ctx, ds, request)
+	if err != nil {
+		return err
+	}
+	for _, res := range resp.Results {
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.ErrorString != "" {
+			return errors.New(res.ErrorString)
+		}
+	}
+	return nil
+}
+
+// notifyFailure emails the admins of the org of the data source that its
+// warm-up query failed.
+func (s *Service) notifyFailure(ds *models.DataSource, warmUpQuery models.DataSourceWarmUpQuery, queryErr error) {
+	query := &models.GetOrgUsersQuery{OrgId: ds.OrgId}
+	if err := s.Bus.Dispatch(query); err != nil {
+		s.log.Warn("Failed to get the org users to notify of a failed warm-up query", "orgId", ds.OrgId, "error", err)
+		return
+	}
+
+	for _, user := range query.Result {
+		if user.Role != string(models.ROLE_ADMIN) || user.Email == "" {
+			continue
+		}
+		name := user.Name
+		if name == "" {
+			name = user.Login
+		}
+
+		cmd := models.SendEmailCommand{
+			To:       []string{user.Email},
+			Template: "warm_up_failed.html",
+			Data: map[string]interface{}{
+				"Name":           name,
+				"DataSourceName": ds.Name,
+				"QueryName":      warmUpQuery.Name,
+				"Error":          queryErr.Error(),
+				"LinkUrl":        setting.ToAbsUrl("datasources/edit/" + ds.Uid),
+			},
+		}
+		if err := s.Bus.Dispatch(&cmd); err != nil && !errors.Is(err, models.ErrSmtpNotEnabled) {
+			s.log.Warn("Failed to send warm-up query failure email", "login", user.Login, "error", err)
+		}
+	}
+}
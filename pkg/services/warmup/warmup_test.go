@@ -0,0 +1,146 @@
+package warmup
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/plugins/manager"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb"
+)
+
+type fakePluginRequestValidator struct{}
+
+func (fakePluginRequestValidator) Validate(string, *http.Request) error {
+	return nil
+}
+
+type fakeBackendPM struct {
+	backendplugin.Manager
+}
+
+func (pm fakeBackendPM) GetDataPlugin(string) interface{} {
+	return nil
+}
+
+// nolint: staticcheck // plugins.DataPlugin deprecated
+type fakeDataPlugin struct {
+	queries []plugins.DataQuery
+	err     error
+}
+
+// nolint: staticcheck // plugins.DataPlugin deprecated
+func (p *fakeDataPlugin) DataQuery(ctx context.Context, ds *models.DataSource, query plugins.DataQuery) (plugins.DataResponse, error) {
+	p.queries = append(p.queries, query)
+	return plugins.DataResponse{
+		Results: map[string]plugins.DataQueryResult{"A": {RefID: "A", Error: p.err}},
+	}, nil
+}
+
+func TestService(t *testing.T) {
+	ds := &models.DataSource{
+		Id:    1,
+		OrgId: 2,
+		Uid:   "warehouse",
+		Name:  "Warehouse",
+		Type:  "test",
+		JsonData: simplejson.NewFromAny(map[string]interface{}{
+			"warmUpQueries": []interface{}{
+				map[string]interface{}{"name": "Prime", "query": map[string]interface{}{"rawSql": "SELECT 1"}, "interval": "5m"},
+			},
+		}),
+	}
+	bus.AddHandler("test", func(query *models.GetAllDataSourcesQuery) error {
+		query.Result = []*models.DataSource{ds}
+		return nil
+	})
+	bus.AddHandler("test", func(query *models.GetOrgUsersQuery) error {
+		query.Result = []*models.OrgUserDTO{
+			{OrgId: query.OrgId, Login: "admin", Email: "admin@example.com", Role: string(models.ROLE_ADMIN)},
+			{OrgId: query.OrgId, Login: "viewer", Email: "viewer@example.com", Role: string(models.ROLE_VIEWER)},
+		}
+		return nil
+	})
+	var emails []models.SendEmailCommand
+	bus.AddHandler("test", func(cmd *models.SendEmailCommand) error {
+		emails = append(emails, *cmd)
+		return nil
+	})
+	t.Cleanup(bus.ClearBusHandlers)
+
+	plugin := &fakeDataPlugin{}
+	dataService := tsdb.NewService()
+	dataService.PluginManager = &manager.PluginManager{BackendPluginManager: fakeBackendPM{}}
+	//nolint: staticcheck // plugins.DataPlugin deprecated
+	dataService.RegisterQueryHandler("test", func(*models.DataSource) (plugins.DataPlugin, error) {
+		return plugin, nil
+	})
+
+	cfg := setting.NewCfg()
+	cfg.DataSourceWarmUp = setting.DataSourceWarmUpSettings{Enabled: true, HistorySize: 2, NotifyFailures: true}
+	s := &Service{
+		Cfg:                    cfg,
+		Bus:                    bus.GetBus(),
+		DataService:            &dataService,
+		PluginRequestValidator: fakePluginRequestValidator{},
+	}
+	require.NoError(t, s.Init())
+
+	now := time.Now()
+	tick := func(at time.Time) {
+		s.tick(context.Background(), at)
+		s.wg.Wait()
+	}
+
+	t.Run("runs the queries on their schedule", func(t *testing.T) {
+		tick(now)
+		require.Len(t, plugin.queries, 1)
+		query := plugin.queries[0]
+		require.Equal(t, "now-5m", query.TimeRange.From)
+		require.Equal(t, int64(2), query.User.OrgId)
+		require.Equal(t, "SELECT 1", query.Queries[0].Model.Get("rawSql").MustString())
+
+		tick(now.Add(time.Minute))
+		require.Len(t, plugin.queries, 1)
+
+		tick(now.Add(5 * time.Minute))
+		require.Len(t, plugin.queries, 2)
+
+		runs := s.Runs(ds.Id)["Prime"]
+		require.Len(t, runs, 2)
+		require.Empty(t, runs[0].Error)
+		require.Empty(t, emails)
+	})
+
+	t.Run("notifies the org admins when a query starts to fail", func(t *testing.T) {
+		plugin.err = errors.New("warehouse suspended")
+		tick(now.Add(10 * time.Minute))
+		tick(now.Add(15 * time.Minute))
+
+		runs := s.Runs(ds.Id)["Prime"]
+		require.Len(t, runs, 2)
+		require.Equal(t, "warehouse suspended", runs[0].Error)
+		require.Equal(t, "warehouse suspended", runs[1].Error)
+
+		require.Len(t, emails, 1)
+		require.Equal(t, []string{"admin@example.com"}, emails[0].To)
+		require.Equal(t, "warm_up_failed.html", emails[0].Template)
+		require.Equal(t, "Prime", emails[0].Data["QueryName"])
+	})
+
+	t.Run("forgets the removed queries", func(t *testing.T) {
+		ds.JsonData = simplejson.New()
+		tick(now.Add(20 * time.Minute))
+		require.Empty(t, s.Runs(ds.Id))
+	})
+}
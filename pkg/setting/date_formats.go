@@ -1,6 +1,8 @@
 package setting
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"gopkg.in/ini.v1"
@@ -11,6 +13,7 @@ type DateFormats struct {
 	UseBrowserLocale bool                `json:"useBrowserLocale"`
 	Interval         DateFormatIntervals `json:"interval"`
 	DefaultTimezone  string              `json:"defaultTimezone"`
+	DefaultWeekStart string              `json:"defaultWeekStart"`
 }
 
 type DateFormatIntervals struct {
@@ -38,6 +41,19 @@ func valueAsTimezone(section *ini.Section, keyName string) (string, error) {
 	return location.String(), nil
 }
 
+var weekStarts = map[string]bool{localBrowserWeekStart: true, "monday": true, "saturday": true, "sunday": true}
+
+const localBrowserWeekStart = "browser"
+
+func valueAsWeekStart(section *ini.Section, keyName string) (string, error) {
+	weekStart := strings.ToLower(section.Key(keyName).MustString(localBrowserWeekStart))
+	if !weekStarts[weekStart] {
+		return localBrowserWeekStart, fmt.Errorf("unknown week start %q", weekStart)
+	}
+
+	return weekStart, nil
+}
+
 func (cfg *Cfg) readDateFormats() {
 	dateFormats := cfg.Raw.Section("date_formats")
 	cfg.DateFormats.FullDate = valueAsString(dateFormats, "full_date", "YYYY-MM-DD HH:mm:ss")
@@ -54,4 +70,10 @@ func (cfg *Cfg) readDateFormats() {
 		cfg.Logger.Warn("Unknown timezone as default_timezone", "err", err)
 	}
 	cfg.DateFormats.DefaultTimezone = timezone
+
+	weekStart, err := valueAsWeekStart(dateFormats, "default_week_start")
+	if err != nil {
+		cfg.Logger.Warn("Unknown week start as default_week_start", "err", err)
+	}
+	cfg.DateFormats.DefaultWeekStart = weekStart
 }
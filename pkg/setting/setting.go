@@ -103,8 +103,9 @@ var (
 	SnapShotRemoveExpired bool
 
 	// Dashboard history
-	DashboardVersionsToKeep int
-	MinRefreshInterval      string
+	DashboardVersionsToKeep      int
+	DashboardVersionsCompression bool
+	MinRefreshInterval           string
 
 	// User settings
 	AllowUserSignUp         bool
@@ -252,6 +253,9 @@ type Cfg struct {
 	CSPEnabled bool
 	// CSPTemplate contains the Content Security Policy template.
 	CSPTemplate string
+	// CSPReportOnly sends the Content Security Policy in the report only header, for the violations to be reported
+	// by the browsers rather than blocked.
+	CSPReportOnly bool
 
 	TempDataLifetime         time.Duration
 	PluginsEnableAlpha       bool
@@ -268,16 +272,25 @@ type Cfg struct {
 	MetricsEndpointBasicAuthUsername string
 	MetricsEndpointBasicAuthPassword string
 	MetricsEndpointDisableTotalStats bool
+	MetricsEndpointAllowlist         []string
+	MetricsHighCardinalityLabels     []string
+	MetricsHighCardinalityLabelsMode string
 	MetricsGrafanaEnvironmentInfo    map[string]string
 
 	// Dashboards
 	DefaultHomeDashboardPath string
+	// DashboardEditLock is whether saving a dashboard is rejected while
+	// another user holds its edit lock
+	DashboardEditLock bool
 
 	// Auth
 	LoginCookieName              string
 	LoginMaxInactiveLifetime     time.Duration
 	LoginMaxLifetime             time.Duration
 	TokenRotationIntervalMinutes int
+	// LoginExtendSessionOnActivity makes the activity of the users reset the inactive lifetime of their sessions,
+	// otherwise the sessions expire after the maximum inactive lifetime since the login.
+	LoginExtendSessionOnActivity bool
 	SigV4AuthEnabled             bool
 	BasicAuthEnabled             bool
 	AdminUser                    string
@@ -300,6 +313,13 @@ type Cfg struct {
 	AuthProxyWhitelist        string
 	AuthProxyHeaders          map[string]string
 	AuthProxySyncTTL          int
+	// AuthProxyHeaderWhitelists are the networks allowed to send the additional headers, by field
+	AuthProxyHeaderWhitelists map[string][]string
+	AuthProxySignatureMode    string
+	AuthProxySignatureHeader  string
+	AuthProxySignatureSecret  string
+	AuthProxySignatureKeyFile string
+	AuthProxySignatureMaxAge  time.Duration
 
 	// OAuth
 	OAuthCookieMaxAge int
@@ -324,6 +344,14 @@ type Cfg struct {
 	EditorsCanAdmin bool
 
 	ApiKeyMaxSecondsToLive int64
+	// ApiKeyMaxInactiveLifetime is how long API keys can be unused before expiring, they don't expire when unused
+	// if zero.
+	ApiKeyMaxInactiveLifetime time.Duration
+	// ApiKeyMaxLifetime is how long API keys can be used since their creation, whatever their expiration, if not
+	// zero.
+	ApiKeyMaxLifetime time.Duration
+	// ApiKeyExtendOnActivity makes the use of API keys reset their inactive lifetime.
+	ApiKeyExtendOnActivity bool
 
 	// Use to enable new features which may still be in alpha/beta stage.
 	FeatureToggles       map[string]bool
@@ -347,8 +375,72 @@ type Cfg struct {
 	// Sentry config
 	Sentry Sentry
 
+	// File storage
+	FileStorage FileStorageSettings
+
+	// Geomap
+	Geomap GeomapSettings
+
+	// IP filter
+	IPFilter IPFilterSettings
+
+	// HTML sanitizer
+	HTMLSanitizer HTMLSanitizerSettings
+
+	// Dashboard thumbnails
+	DashboardThumbnails DashboardThumbnailsSettings
+
+	// Arrow Flight
+	ArrowFlight ArrowFlightSettings
+
+	// gRPC admin API
+	GRPCAdminAPI GRPCAdminAPISettings
+
+	// Last successful query results of the panels
+	QueryResultRetention QueryResultRetentionSettings
+
+	// Warm-up queries of the data sources
+	DataSourceWarmUp DataSourceWarmUpSettings
+
+	// Cost attribution of the queries
+	QueryCost QueryCostSettings
+
+	// Export of the usage of the orgs
+	UsageExport UsageExportSettings
+
+	// Profiles and runtime stats of the admin API
+	Diagnostics DiagnosticsSettings
+
+	// Budget of the data source queries running at the same time
+	QueryBudget QueryBudgetSettings
+
+	// Evaluation of the alert rules on remote evaluator servers
+	RemoteEvaluation RemoteEvaluationSettings
+
+	// Election of the server running the singleton services
+	LeaderElection LeaderElectionSettings
+
+	// Distribution of the events to the other servers
+	EventBus EventBusSettings
+
+	// Cache of the options of the query variables
+	VariableOptionsCache VariableOptionsCacheSettings
+
+	// Recording and replay of the responses of the data sources
+	DataSourceRecording DataSourceRecordingSettings
+
+	// History of the login attempts of the users
+	LoginHistory LoginHistorySettings
+
+	// TestData DB plugin
+	TestData TestDataSettings
+
+	// Kubernetes provisioning
+	KubernetesProvisioning KubernetesProvisioningSettings
+
 	// Data sources
-	DataSourceLimit int
+	DataSourceLimit                     int
+	DataSourceSecretRotationGracePeriod time.Duration
 
 	// Snapshots
 	SnapshotPublicMode bool
@@ -391,6 +483,11 @@ func (cfg Cfg) IsNgAlertEnabled() bool {
 	return cfg.FeatureToggles["ngalert"]
 }
 
+// IsGraphQLEnabled returns whether the experimental GraphQL API is enabled.
+func (cfg Cfg) IsGraphQLEnabled() bool {
+	return cfg.FeatureToggles["graphql"]
+}
+
 // IsTrimDefaultsEnabled returns whether the standalone trim dashboard default feature is enabled.
 func (cfg Cfg) IsTrimDefaultsEnabled() bool {
 	return cfg.FeatureToggles["trimDefaults"]
@@ -845,9 +942,11 @@ func (cfg *Cfg) Load(args *CommandLineArgs) error {
 	// read dashboard settings
 	dashboards := iniFile.Section("dashboards")
 	DashboardVersionsToKeep = dashboards.Key("versions_to_keep").MustInt(20)
+	DashboardVersionsCompression = dashboards.Key("versions_compression").MustBool(true)
 	MinRefreshInterval = valueAsString(dashboards, "min_refresh_interval", "5s")
 
 	cfg.DefaultHomeDashboardPath = dashboards.Key("default_home_dashboard_path").MustString("")
+	cfg.DashboardEditLock = dashboards.Key("edit_lock").MustBool(false)
 
 	if err := readUserSettings(iniFile, cfg); err != nil {
 		return err
@@ -864,6 +963,10 @@ func (cfg *Cfg) Load(args *CommandLineArgs) error {
 	cfg.MetricsEndpointBasicAuthUsername = valueAsString(iniFile.Section("metrics"), "basic_auth_username", "")
 	cfg.MetricsEndpointBasicAuthPassword = valueAsString(iniFile.Section("metrics"), "basic_auth_password", "")
 	cfg.MetricsEndpointDisableTotalStats = iniFile.Section("metrics").Key("disable_total_stats").MustBool(false)
+	cfg.MetricsEndpointAllowlist = util.SplitString(valueAsString(iniFile.Section("metrics"), "allowlist", ""))
+	cfg.MetricsHighCardinalityLabels = util.SplitString(valueAsString(iniFile.Section("metrics"), "high_cardinality_labels",
+		"datasource datasource_id datasource_uid dashboard_uid"))
+	cfg.MetricsHighCardinalityLabelsMode = valueAsString(iniFile.Section("metrics"), "high_cardinality_labels_mode", "keep")
 
 	analytics := iniFile.Section("analytics")
 	cfg.CheckForUpdates = analytics.Key("check_for_updates").MustBool(true)
@@ -951,7 +1054,34 @@ func (cfg *Cfg) Load(args *CommandLineArgs) error {
 	}
 
 	cfg.readDateFormats()
-	cfg.readSentryConfig()
+	if err := cfg.readSentryConfig(); err != nil {
+		return err
+	}
+	cfg.readFileStorageSettings()
+	cfg.readGeomapSettings()
+	cfg.readKubernetesProvisioningSettings()
+	cfg.readDashboardThumbnailsSettings()
+	cfg.readArrowFlightSettings()
+	cfg.readGRPCAdminAPISettings()
+	cfg.readQueryResultRetentionSettings()
+	cfg.readDataSourceWarmUpSettings()
+	cfg.readQueryCostSettings()
+	cfg.readUsageExportSettings()
+	cfg.readDiagnosticsSettings()
+	cfg.readQueryBudgetSettings()
+	cfg.readRemoteEvaluationSettings()
+	cfg.readLeaderElectionSettings()
+	cfg.readEventBusSettings()
+	cfg.readVariableOptionsCacheSettings()
+	cfg.readDataSourceRecordingSettings()
+	cfg.readLoginHistorySettings()
+	cfg.readTestDataSettings()
+	if err := cfg.readIPFilterSettings(); err != nil {
+		return err
+	}
+	if err := cfg.readHTMLSanitizerSettings(); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -1117,6 +1247,7 @@ func readSecuritySettings(iniFile *ini.File, cfg *Cfg) error {
 	cfg.StrictTransportSecuritySubDomains = security.Key("strict_transport_security_subdomains").MustBool(false)
 	cfg.CSPEnabled = security.Key("content_security_policy").MustBool(false)
 	cfg.CSPTemplate = security.Key("content_security_policy_template").MustString("")
+	cfg.CSPReportOnly = security.Key("content_security_policy_report_only").MustBool(false)
 
 	// read data source proxy whitelist
 	DataProxyWhiteList = make(map[string]bool)
@@ -1165,11 +1296,23 @@ func readAuthSettings(iniFile *ini.File, cfg *Cfg) (err error) {
 	}
 
 	cfg.ApiKeyMaxSecondsToLive = auth.Key("api_key_max_seconds_to_live").MustInt64(-1)
+	if val := valueAsString(auth, "api_key_maximum_inactive_lifetime_duration", ""); val != "" {
+		if cfg.ApiKeyMaxInactiveLifetime, err = gtime.ParseDuration(val); err != nil {
+			return err
+		}
+	}
+	if val := valueAsString(auth, "api_key_maximum_lifetime_duration", ""); val != "" {
+		if cfg.ApiKeyMaxLifetime, err = gtime.ParseDuration(val); err != nil {
+			return err
+		}
+	}
+	cfg.ApiKeyExtendOnActivity = auth.Key("api_key_extend_on_activity").MustBool(true)
 
 	cfg.TokenRotationIntervalMinutes = auth.Key("token_rotation_interval_minutes").MustInt(10)
 	if cfg.TokenRotationIntervalMinutes < 2 {
 		cfg.TokenRotationIntervalMinutes = 2
 	}
+	cfg.LoginExtendSessionOnActivity = auth.Key("login_extend_session_on_activity").MustBool(true)
 
 	DisableLoginForm = auth.Key("disable_login_form").MustBool(false)
 	DisableSignoutMenu = auth.Key("disable_signout_menu").MustBool(false)
@@ -1237,6 +1380,31 @@ func readAuthSettings(iniFile *ini.File, cfg *Cfg) (err error) {
 		}
 	}
 
+	cfg.AuthProxyHeaderWhitelists = make(map[string][]string)
+	for _, propertyAndNetwork := range util.SplitString(valueAsString(authProxy, "header_whitelists", "")) {
+		split := strings.SplitN(propertyAndNetwork, ":", 2)
+		if len(split) == 2 {
+			cfg.AuthProxyHeaderWhitelists[split[0]] = append(cfg.AuthProxyHeaderWhitelists[split[0]], split[1])
+		}
+	}
+
+	cfg.AuthProxySignatureMode = valueAsString(authProxy, "signature_mode", "")
+	switch cfg.AuthProxySignatureMode {
+	case "", "hmac", "jwt":
+	default:
+		return fmt.Errorf("invalid auth proxy signature_mode %q, expected hmac or jwt", cfg.AuthProxySignatureMode)
+	}
+	cfg.AuthProxySignatureHeader = valueAsString(authProxy, "signature_header", "X-WEBAUTH-SIGNATURE")
+	cfg.AuthProxySignatureSecret = valueAsString(authProxy, "signature_secret", "")
+	cfg.AuthProxySignatureKeyFile = valueAsString(authProxy, "signature_key_file", "")
+	cfg.AuthProxySignatureMaxAge = authProxy.Key("signature_max_age").MustDuration(5 * time.Minute)
+	if cfg.AuthProxySignatureMode == "hmac" && cfg.AuthProxySignatureSecret == "" {
+		return errors.New("auth proxy signature_mode hmac requires a signature_secret")
+	}
+	if cfg.AuthProxySignatureMode == "jwt" && cfg.AuthProxySignatureSecret == "" && cfg.AuthProxySignatureKeyFile == "" {
+		return errors.New("auth proxy signature_mode jwt requires a signature_secret or a signature_key_file")
+	}
+
 	return nil
 }
 
@@ -1422,4 +1590,5 @@ func (cfg *Cfg) GetContentDeliveryURL(prefix string) string {
 func (cfg *Cfg) readDataSourcesSettings() {
 	datasources := cfg.Raw.Section("datasources")
 	cfg.DataSourceLimit = datasources.Key("datasource_limit").MustInt(5000)
+	cfg.DataSourceSecretRotationGracePeriod = datasources.Key("secret_rotation_grace_period").MustDuration(24 * time.Hour)
 }
@@ -0,0 +1,38 @@
+package setting
+
+type ArrowFlightSettings struct {
+	// Enabled is whether the Arrow Flight server is started
+	Enabled bool
+	// Address is the host:port the server listens on
+	Address string
+	// CertFile and CertKey are the TLS certificate and key of the server,
+	// it's plaintext without them
+	CertFile string
+	CertKey  string
+	// MaxRows is the maximum number of rows of a query result, zero for no
+	// limit
+	MaxRows int
+}
+
+func (cfg *Cfg) readArrowFlightSettings() {
+	sec := cfg.Raw.Section("arrow_flight")
+
+	flight := ArrowFlightSettings{
+		Enabled:  sec.Key("enabled").MustBool(false),
+		Address:  valueAsString(sec, "address", "127.0.0.1:10000"),
+		CertFile: valueAsString(sec, "cert_file", ""),
+		CertKey:  valueAsString(sec, "cert_key", ""),
+		MaxRows:  sec.Key("max_rows").MustInt(1000000),
+	}
+
+	if (flight.CertFile == "") != (flight.CertKey == "") {
+		cfg.Logger.Warn("Arrow Flight TLS requires both cert_file and cert_key, using plaintext")
+		flight.CertFile, flight.CertKey = "", ""
+	}
+	if flight.MaxRows < 0 {
+		cfg.Logger.Warn("Invalid Arrow Flight max rows, using no limit", "max_rows", flight.MaxRows)
+		flight.MaxRows = 0
+	}
+
+	cfg.ArrowFlight = flight
+}
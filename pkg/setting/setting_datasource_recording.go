@@ -0,0 +1,38 @@
+package setting
+
+import (
+	"path/filepath"
+)
+
+// The modes of the recording of the data source responses.
+const (
+	DataSourceRecordingOff    = "off"
+	DataSourceRecordingRecord = "record"
+	DataSourceRecordingReplay = "replay"
+)
+
+type DataSourceRecordingSettings struct {
+	// Mode is whether the responses of the data source queries are recorded,
+	// replayed instead of querying the data sources, or neither
+	Mode string
+	// Path is the directory of the recorded responses
+	Path string
+}
+
+func (cfg *Cfg) readDataSourceRecordingSettings() {
+	sec := cfg.Raw.Section("datasource_recording")
+
+	recording := DataSourceRecordingSettings{
+		Mode: valueAsString(sec, "mode", DataSourceRecordingOff),
+		Path: makeAbsolute(valueAsString(sec, "path", filepath.Join(cfg.DataPath, "recordings")), HomePath),
+	}
+
+	switch recording.Mode {
+	case DataSourceRecordingOff, DataSourceRecordingRecord, DataSourceRecordingReplay:
+	default:
+		cfg.Logger.Warn("Invalid data source recording mode, the recording is off", "mode", recording.Mode)
+		recording.Mode = DataSourceRecordingOff
+	}
+
+	cfg.DataSourceRecording = recording
+}
@@ -0,0 +1,27 @@
+package setting
+
+type DataSourceWarmUpSettings struct {
+	// Enabled is whether the warm-up queries of the data sources are run
+	Enabled bool
+	// HistorySize is the number of runs kept for each warm-up query
+	HistorySize int
+	// NotifyFailures is whether the org admins are emailed when a warm-up query starts to fail
+	NotifyFailures bool
+}
+
+func (cfg *Cfg) readDataSourceWarmUpSettings() {
+	sec := cfg.Raw.Section("datasource_warm_up")
+
+	warmUp := DataSourceWarmUpSettings{
+		Enabled:        sec.Key("enabled").MustBool(true),
+		HistorySize:    sec.Key("history_size").MustInt(20),
+		NotifyFailures: sec.Key("notify_failures").MustBool(true),
+	}
+
+	if warmUp.HistorySize <= 0 {
+		cfg.Logger.Warn("Invalid data source warm-up history size, using the default", "history_size", warmUp.HistorySize)
+		warmUp.HistorySize = 20
+	}
+
+	cfg.DataSourceWarmUp = warmUp
+}
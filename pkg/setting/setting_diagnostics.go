@@ -0,0 +1,40 @@
+package setting
+
+import (
+	"time"
+)
+
+type DiagnosticsSettings struct {
+	// APIEnabled is whether the server admins can capture profiles and the
+	// runtime stats with the admin API
+	APIEnabled bool
+	// MaxCPUProfileDuration is the maximum duration of the CPU profiles
+	MaxCPUProfileDuration time.Duration
+	// BundleLogLines is the number of the latest lines of the log file in
+	// the diagnostics bundles
+	BundleLogLines int
+}
+
+func (cfg *Cfg) readDiagnosticsSettings() {
+	sec := cfg.Raw.Section("diagnostics")
+
+	diagnostics := DiagnosticsSettings{
+		APIEnabled:            sec.Key("api_enabled").MustBool(true),
+		MaxCPUProfileDuration: sec.Key("max_cpu_profile_duration").MustDuration(5 * time.Minute),
+		BundleLogLines:        sec.Key("bundle_log_lines").MustInt(1000),
+	}
+
+	if diagnostics.MaxCPUProfileDuration < time.Second {
+		cfg.Logger.Warn("Invalid max CPU profile duration, it must be at least a second, using the default",
+			"max_cpu_profile_duration", diagnostics.MaxCPUProfileDuration)
+		diagnostics.MaxCPUProfileDuration = 5 * time.Minute
+	}
+
+	if diagnostics.BundleLogLines < 0 {
+		cfg.Logger.Warn("Invalid number of log lines of the diagnostics bundles, using the default",
+			"bundle_log_lines", diagnostics.BundleLogLines)
+		diagnostics.BundleLogLines = 1000
+	}
+
+	cfg.Diagnostics = diagnostics
+}
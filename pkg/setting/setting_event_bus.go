@@ -0,0 +1,35 @@
+package setting
+
+import (
+	"time"
+)
+
+type EventBusSettings struct {
+	// Enabled is whether the events, e.g. of the changes of the data sources,
+	// are published to the other servers of the database
+	Enabled bool
+	// PollInterval is how often the events of the other servers are read
+	PollInterval time.Duration
+	// Retention is how long the events are kept in the database
+	Retention time.Duration
+}
+
+func (cfg *Cfg) readEventBusSettings() {
+	sec := cfg.Raw.Section("event_bus")
+
+	eventBus := EventBusSettings{
+		Enabled:      sec.Key("enabled").MustBool(false),
+		PollInterval: sec.Key("poll_interval").MustDuration(time.Second),
+		Retention:    sec.Key("retention").MustDuration(time.Hour),
+	}
+
+	if eventBus.PollInterval <= 0 {
+		eventBus.PollInterval = time.Second
+	}
+	if eventBus.Retention < time.Minute {
+		cfg.Logger.Warn("Event bus retention must be at least 1m, using 1m")
+		eventBus.Retention = time.Minute
+	}
+
+	cfg.EventBus = eventBus
+}
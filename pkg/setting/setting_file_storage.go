@@ -0,0 +1,85 @@
+package setting
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+const (
+	FileStorageLocal     = "local"
+	FileStorageS3        = "s3"
+	FileStorageGCS       = "gcs"
+	FileStorageAzureBlob = "azure_blob"
+)
+
+type FileStorageSettings struct {
+	Backend string
+	// MaxFileSize is the maximum size of a single file in bytes
+	MaxFileSize int64
+	// OrgQuota is the maximum size of all files of an organization in bytes, negative for unlimited
+	OrgQuota            int64
+	AllowedContentTypes []string
+
+	LocalPath string
+
+	S3Bucket          string
+	S3Region          string
+	S3Path            string
+	S3Endpoint        string
+	S3AccessKey       string
+	S3SecretKey       string
+	S3PathStyleAccess bool
+
+	GCSKeyFile string
+	GCSBucket  string
+	GCSPath    string
+
+	AzureBlobAccountName   string
+	AzureBlobAccountKey    string
+	AzureBlobContainerName string
+}
+
+func (cfg *Cfg) readFileStorageSettings() {
+	sec := cfg.Raw.Section("file_storage")
+
+	fs := FileStorageSettings{
+		Backend:     valueAsString(sec, "backend", FileStorageLocal),
+		MaxFileSize: sec.Key("max_file_size_mb").MustInt64(10) * 1024 * 1024,
+		LocalPath:   makeAbsolute(valueAsString(sec, "local_path", filepath.Join(cfg.DataPath, "files")), HomePath),
+	}
+
+	if orgQuota := sec.Key("org_quota_mb").MustInt64(100); orgQuota < 0 {
+		fs.OrgQuota = -1
+	} else {
+		fs.OrgQuota = orgQuota * 1024 * 1024
+	}
+
+	contentTypes := valueAsString(sec, "allowed_content_types",
+		"image/png,image/jpeg,image/gif,image/webp,image/svg+xml,application/json,application/geo+json")
+	for _, contentType := range strings.Split(contentTypes, ",") {
+		if contentType = strings.TrimSpace(contentType); contentType != "" {
+			fs.AllowedContentTypes = append(fs.AllowedContentTypes, contentType)
+		}
+	}
+
+	s3 := cfg.Raw.Section("file_storage.s3")
+	fs.S3Bucket = s3.Key("bucket").String()
+	fs.S3Region = s3.Key("region").String()
+	fs.S3Path = s3.Key("path").String()
+	fs.S3Endpoint = s3.Key("endpoint").String()
+	fs.S3AccessKey = s3.Key("access_key").String()
+	fs.S3SecretKey = s3.Key("secret_key").String()
+	fs.S3PathStyleAccess = s3.Key("path_style_access").MustBool(false)
+
+	gcs := cfg.Raw.Section("file_storage.gcs")
+	fs.GCSKeyFile = gcs.Key("key_file").String()
+	fs.GCSBucket = gcs.Key("bucket").String()
+	fs.GCSPath = gcs.Key("path").String()
+
+	azureBlob := cfg.Raw.Section("file_storage.azure_blob")
+	fs.AzureBlobAccountName = azureBlob.Key("account_name").String()
+	fs.AzureBlobAccountKey = azureBlob.Key("account_key").String()
+	fs.AzureBlobContainerName = azureBlob.Key("container_name").String()
+
+	cfg.FileStorage = fs
+}
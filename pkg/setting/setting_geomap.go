@@ -0,0 +1,70 @@
+package setting
+
+import (
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/gtime"
+)
+
+const geomapTileServerSectionPrefix = "geomap.tile_server."
+
+type GeomapSettings struct {
+	// TileCacheTTL is how long proxied tiles are cached, zero disables caching
+	TileCacheTTL time.Duration
+	// TileServers maps the name of a configured tile server to its settings
+	TileServers map[string]GeomapTileServer
+}
+
+// GeomapTileServer is a tile server proxied by Grafana. The API key is only
+// added by the server and is never sent to browsers.
+type GeomapTileServer struct {
+	Name string
+	// URL is a template with {z}, {x} and {y} placeholders
+	URL          string
+	APIKey       string
+	APIKeyParam  string
+	APIKeyHeader string
+	Attribution  string
+	MaxZoom      int
+}
+
+func (cfg *Cfg) readGeomapSettings() {
+	sec := cfg.Raw.Section("geomap")
+
+	geomap := GeomapSettings{
+		TileCacheTTL: time.Hour,
+		TileServers:  map[string]GeomapTileServer{},
+	}
+
+	if ttl, err := gtime.ParseDuration(valueAsString(sec, "tile_cache_ttl", "1h")); err == nil {
+		geomap.TileCacheTTL = ttl
+	} else {
+		cfg.Logger.Warn("Invalid geomap tile cache ttl, using default", "err", err)
+	}
+
+	for _, section := range cfg.Raw.Sections() {
+		if !strings.HasPrefix(section.Name(), geomapTileServerSectionPrefix) {
+			continue
+		}
+
+		name := strings.TrimPrefix(section.Name(), geomapTileServerSectionPrefix)
+		server := GeomapTileServer{
+			Name:         name,
+			URL:          section.Key("url").String(),
+			APIKey:       section.Key("api_key").String(),
+			APIKeyParam:  section.Key("api_key_param").String(),
+			APIKeyHeader: section.Key("api_key_header").String(),
+			Attribution:  section.Key("attribution").String(),
+			MaxZoom:      section.Key("max_zoom").MustInt(22),
+		}
+		if server.URL == "" {
+			cfg.Logger.Warn("Ignoring geomap tile server without url", "name", name)
+			continue
+		}
+
+		geomap.TileServers[name] = server
+	}
+
+	cfg.Geomap = geomap
+}
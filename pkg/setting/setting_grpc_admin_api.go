@@ -0,0 +1,30 @@
+package setting
+
+type GRPCAdminAPISettings struct {
+	// Enabled is whether the gRPC admin API server is started
+	Enabled bool
+	// Address is the host:port the server listens on
+	Address string
+	// CertFile and CertKey are the TLS certificate and key of the server,
+	// it's plaintext without them
+	CertFile string
+	CertKey  string
+}
+
+func (cfg *Cfg) readGRPCAdminAPISettings() {
+	sec := cfg.Raw.Section("grpc_admin_api")
+
+	api := GRPCAdminAPISettings{
+		Enabled:  sec.Key("enabled").MustBool(false),
+		Address:  valueAsString(sec, "address", "127.0.0.1:10001"),
+		CertFile: valueAsString(sec, "cert_file", ""),
+		CertKey:  valueAsString(sec, "cert_key", ""),
+	}
+
+	if (api.CertFile == "") != (api.CertKey == "") {
+		cfg.Logger.Warn("gRPC admin API TLS requires both cert_file and cert_key, using plaintext")
+		api.CertFile, api.CertKey = "", ""
+	}
+
+	cfg.GRPCAdminAPI = api
+}
@@ -0,0 +1,142 @@
+package setting
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const ipFilterOrgSectionPrefix = "ip_filter.org_"
+
+// Route groups of the IP filter.
+const (
+	IPFilterGroupAPI      = "api"
+	IPFilterGroupLogin    = "login"
+	IPFilterGroupLivePush = "live_push"
+	IPFilterGroupRender   = "render"
+)
+
+// IPFilterGroups are the route groups of the IP filter, the path prefixes of
+// the groups are matched in this order.
+var IPFilterGroups = []string{IPFilterGroupLivePush, IPFilterGroupAPI, IPFilterGroupLogin, IPFilterGroupRender}
+
+type IPFilterSettings struct {
+	Enabled bool
+	// UseForwardedHeaders is whether the client IP is read from the headers
+	// of the reverse proxy rather than from the connection, it should only be
+	// enabled behind a reverse proxy setting them.
+	UseForwardedHeaders bool
+	// ClientIPHeader is the header the reverse proxy sets to the client IP,
+	// the last address of X-Forwarded-For is used when empty
+	ClientIPHeader string
+	// Rules are the rules of the route groups, by group
+	Rules map[string]IPFilterRules
+	// OrgRules are the rules replacing the instance rules of the route groups
+	// for the requests signed in to an organization, by organization ID and
+	// group
+	OrgRules map[int64]map[string]IPFilterRules
+}
+
+// IPFilterRules are the networks of a route group. The denied networks take
+// precedence, and all the networks which aren't denied are allowed when no
+// network is allowed.
+type IPFilterRules struct {
+	Allow []*net.IPNet
+	Deny  []*net.IPNet
+}
+
+func (cfg *Cfg) readIPFilterSettings() error {
+	sec := cfg.Raw.Section("ip_filter")
+
+	ipFilter := IPFilterSettings{
+		Enabled:             sec.Key("enabled").MustBool(false),
+		UseForwardedHeaders: sec.Key("use_forwarded_headers").MustBool(false),
+		ClientIPHeader:      strings.TrimSpace(sec.Key("client_ip_header").String()),
+		OrgRules:            map[int64]map[string]IPFilterRules{},
+	}
+
+	rules, err := readIPFilterRules(sec)
+	if err != nil {
+		return err
+	}
+	ipFilter.Rules = rules
+
+	for _, section := range cfg.Raw.Sections() {
+		if !strings.HasPrefix(section.Name(), ipFilterOrgSectionPrefix) {
+			continue
+		}
+
+		orgID, err := strconv.ParseInt(strings.TrimPrefix(section.Name(), ipFilterOrgSectionPrefix), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid organization ID in section %q: %w", section.Name(), err)
+		}
+
+		rules, err := readIPFilterRules(section)
+		if err != nil {
+			return err
+		}
+		ipFilter.OrgRules[orgID] = rules
+	}
+
+	cfg.IPFilter = ipFilter
+	return nil
+}
+
+// readIPFilterRules returns the rules of the route groups set in the
+// section, from their <group>_allow and <group>_deny keys.
+func readIPFilterRules(section *ini.Section) (map[string]IPFilterRules, error) {
+	rules := map[string]IPFilterRules{}
+	for _, group := range IPFilterGroups {
+		allowKey, denyKey := group+"_allow", group+"_deny"
+		if !section.HasKey(allowKey) && !section.HasKey(denyKey) {
+			continue
+		}
+
+		allow, err := parseNetworks(section.Key(allowKey).String())
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s in section %q: %w", allowKey, section.Name(), err)
+		}
+		deny, err := parseNetworks(section.Key(denyKey).String())
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s in section %q: %w", denyKey, section.Name(), err)
+		}
+		if len(allow) > 0 || len(deny) > 0 {
+			rules[group] = IPFilterRules{Allow: allow, Deny: deny}
+		}
+	}
+	return rules, nil
+}
+
+// parseNetworks parses a list of CIDRs, the IPs without prefix length are
+// single address networks.
+func parseNetworks(value string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, cidr := range util.SplitString(strings.TrimSpace(value)) {
+		if cidr == "" {
+			continue
+		}
+		if !strings.Contains(cidr, "/") {
+			ip := net.ParseIP(cidr)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address %q", cidr)
+			}
+			if ip.To4() != nil {
+				cidr += "/32"
+			} else {
+				cidr += "/128"
+			}
+		}
+
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
@@ -0,0 +1,67 @@
+package setting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIPFilterSettings(t *testing.T) {
+	t.Run("reads the rules of the instance and of the organizations", func(t *testing.T) {
+		cfg := NewCfg()
+		sec, err := cfg.Raw.NewSection("ip_filter")
+		require.NoError(t, err)
+		_, err = sec.NewKey("enabled", "true")
+		require.NoError(t, err)
+		_, err = sec.NewKey("client_ip_header", " X-Real-IP ")
+		require.NoError(t, err)
+		_, err = sec.NewKey("api_allow", "10.0.0.0/8, 192.168.1.10")
+		require.NoError(t, err)
+		_, err = sec.NewKey("api_deny", "10.1.0.0/16")
+		require.NoError(t, err)
+		_, err = sec.NewKey("login_deny", "2001:db8::/32")
+		require.NoError(t, err)
+		_, err = sec.NewKey("render_allow", "")
+		require.NoError(t, err)
+
+		sec, err = cfg.Raw.NewSection("ip_filter.org_2")
+		require.NoError(t, err)
+		_, err = sec.NewKey("api_allow", "172.16.0.0/12")
+		require.NoError(t, err)
+
+		require.NoError(t, cfg.readIPFilterSettings())
+		require.True(t, cfg.IPFilter.Enabled)
+		require.False(t, cfg.IPFilter.UseForwardedHeaders)
+		require.Equal(t, "X-Real-IP", cfg.IPFilter.ClientIPHeader)
+
+		require.Len(t, cfg.IPFilter.Rules, 2)
+		api := cfg.IPFilter.Rules[IPFilterGroupAPI]
+		require.Len(t, api.Allow, 2)
+		require.Equal(t, "10.0.0.0/8", api.Allow[0].String())
+		require.Equal(t, "192.168.1.10/32", api.Allow[1].String())
+		require.Len(t, api.Deny, 1)
+		require.Equal(t, "10.1.0.0/16", api.Deny[0].String())
+		require.Equal(t, "2001:db8::/32", cfg.IPFilter.Rules[IPFilterGroupLogin].Deny[0].String())
+
+		require.Len(t, cfg.IPFilter.OrgRules, 1)
+		require.Equal(t, "172.16.0.0/12", cfg.IPFilter.OrgRules[2][IPFilterGroupAPI].Allow[0].String())
+	})
+
+	t.Run("rejects invalid networks", func(t *testing.T) {
+		cfg := NewCfg()
+		sec, err := cfg.Raw.NewSection("ip_filter")
+		require.NoError(t, err)
+		_, err = sec.NewKey("api_allow", "10.0.0.0/33")
+		require.NoError(t, err)
+
+		require.Error(t, cfg.readIPFilterSettings())
+	})
+
+	t.Run("rejects invalid organization sections", func(t *testing.T) {
+		cfg := NewCfg()
+		_, err := cfg.Raw.NewSection("ip_filter.org_main")
+		require.NoError(t, err)
+
+		require.Error(t, cfg.readIPFilterSettings())
+	})
+}
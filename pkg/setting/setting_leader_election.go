@@ -0,0 +1,45 @@
+package setting
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type LeaderElectionSettings struct {
+	// Enabled is whether the servers elect a leader running the singleton
+	// services, like the alert schedulers, every server runs them otherwise
+	Enabled bool
+	// InstanceID identifies the server in the election, unique per server
+	InstanceID string
+	// LeaseDuration is how long the leadership lasts without being renewed
+	LeaseDuration time.Duration
+	// RenewInterval is how often the leader renews its lease, and the other
+	// servers try to acquire it
+	RenewInterval time.Duration
+}
+
+func (cfg *Cfg) readLeaderElectionSettings() {
+	sec := cfg.Raw.Section("leader_election")
+
+	leaderElection := LeaderElectionSettings{
+		Enabled:       sec.Key("enabled").MustBool(false),
+		InstanceID:    valueAsString(sec, "instance_id", ""),
+		LeaseDuration: sec.Key("lease_duration").MustDuration(15 * time.Second),
+		RenewInterval: sec.Key("renew_interval").MustDuration(5 * time.Second),
+	}
+
+	if leaderElection.InstanceID == "" {
+		// the instance names are often the same host name in containers
+		leaderElection.InstanceID = InstanceName + "-" + util.GenerateShortUID()
+	}
+	if leaderElection.LeaseDuration <= 0 {
+		leaderElection.LeaseDuration = 15 * time.Second
+	}
+	if leaderElection.RenewInterval <= 0 || leaderElection.RenewInterval >= leaderElection.LeaseDuration {
+		cfg.Logger.Warn("Leader election renew_interval must be shorter than lease_duration, using a third of it")
+		leaderElection.RenewInterval = leaderElection.LeaseDuration / 3
+	}
+
+	cfg.LeaderElection = leaderElection
+}
@@ -0,0 +1,33 @@
+package setting
+
+import "time"
+
+type LoginHistorySettings struct {
+	// Enabled is whether the login attempts of the users are recorded
+	Enabled bool
+	// MaxAge is how long the login attempts are kept
+	MaxAge time.Duration
+	// LocationHeader is the header with the location of the client, e.g. set by a CDN; the network of the address of
+	// the client is used when it's empty or absent
+	LocationHeader string
+	// NotifyNewLocation is whether the users are emailed when they log in from a new location
+	NotifyNewLocation bool
+}
+
+func (cfg *Cfg) readLoginHistorySettings() {
+	sec := cfg.Raw.Section("login_history")
+
+	loginHistory := LoginHistorySettings{
+		Enabled:           sec.Key("enabled").MustBool(true),
+		MaxAge:            sec.Key("max_age").MustDuration(30 * 24 * time.Hour),
+		LocationHeader:    sec.Key("location_header").MustString(""),
+		NotifyNewLocation: sec.Key("notify_new_location").MustBool(false),
+	}
+
+	if loginHistory.MaxAge <= 0 {
+		cfg.Logger.Warn("Invalid login history max age, using the default", "max_age", loginHistory.MaxAge)
+		loginHistory.MaxAge = 30 * 24 * time.Hour
+	}
+
+	cfg.LoginHistory = loginHistory
+}
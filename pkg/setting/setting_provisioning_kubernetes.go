@@ -0,0 +1,41 @@
+package setting
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// KubernetesProvisioningSettings configures the provisioning of dashboards and data sources from Kubernetes
+// objects.
+type KubernetesProvisioningSettings struct {
+	Enabled bool
+	// Kubeconfig is the path to a kubeconfig file, the in-cluster config is used if empty
+	Kubeconfig string
+	// Namespace is the namespace of the watched objects, the namespace Grafana runs in if empty and all
+	// namespaces if ALL
+	Namespace string
+	// Resources are the watched kinds of resources, configmaps and secrets
+	Resources                []string
+	DashboardsLabelSelector  string
+	DatasourcesLabelSelector string
+	// CRDGroupVersion is the group and version of the GrafanaDashboard and GrafanaDataSource custom resources,
+	// custom resources are not watched if empty
+	CRDGroupVersion string
+	ResyncInterval  time.Duration
+}
+
+func (cfg *Cfg) readKubernetesProvisioningSettings() {
+	sec := cfg.Raw.Section("provisioning.kubernetes")
+
+	cfg.KubernetesProvisioning = KubernetesProvisioningSettings{
+		Enabled:                  sec.Key("enabled").MustBool(false),
+		Kubeconfig:               valueAsString(sec, "kubeconfig", ""),
+		Namespace:                valueAsString(sec, "namespace", ""),
+		Resources:                util.SplitString(valueAsString(sec, "resources", "configmaps secrets")),
+		DashboardsLabelSelector:  valueAsString(sec, "dashboards_label_selector", "grafana_dashboard=1"),
+		DatasourcesLabelSelector: valueAsString(sec, "datasources_label_selector", "grafana_datasource=1"),
+		CRDGroupVersion:          valueAsString(sec, "crd_group_version", ""),
+		ResyncInterval:           sec.Key("resync_interval").MustDuration(10 * time.Minute),
+	}
+}
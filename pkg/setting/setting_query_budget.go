@@ -0,0 +1,62 @@
+package setting
+
+import (
+	"time"
+)
+
+type QueryBudgetSettings struct {
+	// MaxConcurrentQueries is the maximum number of data source queries
+	// running at the same time, 0 for no limit
+	MaxConcurrentQueries int64
+	// MaxInflightMemory is the maximum estimated memory in bytes of the data
+	// source queries running at the same time, 0 for no limit
+	MaxInflightMemory int64
+	// EstimatedSeriesPerQuery is the number of series of a query in the
+	// estimate of its memory, with its max data points
+	EstimatedSeriesPerQuery int64
+	// MaxQueuedRequests is the maximum number of requests waiting for the
+	// budget, the next ones are rejected
+	MaxQueuedRequests int64
+	// QueueTimeout is how long a request waits for the budget before being
+	// rejected
+	QueueTimeout time.Duration
+	// MaxQueryResponseSize is the maximum size in bytes of the response of a
+	// query, the larger responses are truncated, 0 for no limit
+	MaxQueryResponseSize int64
+	// MaxQueryRows is the maximum number of rows or data points of the
+	// response of a query, 0 for no limit
+	MaxQueryRows int64
+	// MaxQuerySeries is the maximum number of series or frames of the
+	// response of a query, 0 for no limit
+	MaxQuerySeries int64
+}
+
+// Enabled returns whether the queries are limited by the budget.
+func (s QueryBudgetSettings) Enabled() bool {
+	return s.MaxConcurrentQueries > 0 || s.MaxInflightMemory > 0
+}
+
+func (cfg *Cfg) readQueryBudgetSettings() {
+	sec := cfg.Raw.Section("query_budget")
+
+	queryBudget := QueryBudgetSettings{
+		MaxConcurrentQueries:    sec.Key("max_concurrent_queries").MustInt64(0),
+		MaxInflightMemory:       sec.Key("max_inflight_memory_mb").MustInt64(0) * 1024 * 1024,
+		EstimatedSeriesPerQuery: sec.Key("estimated_series_per_query").MustInt64(100),
+		MaxQueuedRequests:       sec.Key("max_queued_requests").MustInt64(100),
+		QueueTimeout:            sec.Key("queue_timeout").MustDuration(10 * time.Second),
+		MaxQueryResponseSize:    sec.Key("max_query_response_size_mb").MustInt64(0) * 1024 * 1024,
+		MaxQueryRows:            sec.Key("max_query_rows").MustInt64(0),
+		MaxQuerySeries:          sec.Key("max_query_series").MustInt64(0),
+	}
+
+	if queryBudget.EstimatedSeriesPerQuery <= 0 {
+		cfg.Logger.Warn("Invalid estimated series per query, using the default", "estimated_series_per_query", queryBudget.EstimatedSeriesPerQuery)
+		queryBudget.EstimatedSeriesPerQuery = 100
+	}
+	if queryBudget.MaxQueuedRequests < 0 {
+		queryBudget.MaxQueuedRequests = 0
+	}
+
+	cfg.QueryBudget = queryBudget
+}
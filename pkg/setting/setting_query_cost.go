@@ -0,0 +1,24 @@
+package setting
+
+type QueryCostSettings struct {
+	// Enabled is whether the costs of the queries are recorded
+	Enabled bool
+	// RetentionDays is how many days the recorded costs are kept
+	RetentionDays int
+}
+
+func (cfg *Cfg) readQueryCostSettings() {
+	sec := cfg.Raw.Section("query_cost")
+
+	queryCost := QueryCostSettings{
+		Enabled:       sec.Key("enabled").MustBool(false),
+		RetentionDays: sec.Key("retention_days").MustInt(90),
+	}
+
+	if queryCost.RetentionDays <= 0 {
+		cfg.Logger.Warn("Invalid query cost retention days, using the default", "retention_days", queryCost.RetentionDays)
+		queryCost.RetentionDays = 90
+	}
+
+	cfg.QueryCost = queryCost
+}
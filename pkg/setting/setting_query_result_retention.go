@@ -0,0 +1,34 @@
+package setting
+
+import "time"
+
+type QueryResultRetentionSettings struct {
+	// Enabled is whether the last successful query results of the panels are
+	// kept, and returned when their queries fail
+	Enabled bool
+	// MaxSize is the maximum size in bytes of the results kept for a panel
+	MaxSize int
+	// TTL is how long the results are kept
+	TTL time.Duration
+}
+
+func (cfg *Cfg) readQueryResultRetentionSettings() {
+	sec := cfg.Raw.Section("query_result_retention")
+
+	retention := QueryResultRetentionSettings{
+		Enabled: sec.Key("enabled").MustBool(false),
+		MaxSize: sec.Key("max_size").MustInt(1048576),
+		TTL:     sec.Key("ttl").MustDuration(24 * time.Hour),
+	}
+
+	if retention.MaxSize <= 0 {
+		cfg.Logger.Warn("Invalid query result retention max size, using the default", "max_size", retention.MaxSize)
+		retention.MaxSize = 1048576
+	}
+	if retention.TTL <= 0 {
+		cfg.Logger.Warn("Invalid query result retention TTL, using the default", "ttl", retention.TTL)
+		retention.TTL = 24 * time.Hour
+	}
+
+	cfg.QueryResultRetention = retention
+}
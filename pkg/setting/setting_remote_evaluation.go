@@ -0,0 +1,55 @@
+package setting
+
+import (
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type RemoteEvaluationSettings struct {
+	// ServerEnabled is whether the gRPC server evaluating the alert rules
+	// of the remote schedulers is started
+	ServerEnabled bool
+	// ServerAddress is the host:port the server listens on
+	ServerAddress string
+	// CertFile and CertKey are the TLS certificate and key of the server,
+	// it's plaintext without them
+	CertFile string
+	CertKey  string
+	// Evaluators are the host:port of the evaluator servers the scheduler
+	// dispatches the evaluations of the alert rules to, they're evaluated
+	// locally when empty
+	Evaluators []string
+	// ClientTLS is whether the scheduler connects to the evaluator servers
+	// with TLS, verified with the CA certificate of ClientCACert if set
+	ClientTLS    bool
+	ClientCACert string
+	// Token is the shared secret authenticating the schedulers to the
+	// evaluator servers
+	Token string
+}
+
+func (cfg *Cfg) readRemoteEvaluationSettings() {
+	sec := cfg.Raw.Section("remote_evaluation")
+
+	remoteEvaluation := RemoteEvaluationSettings{
+		ServerEnabled: sec.Key("server_enabled").MustBool(false),
+		ServerAddress: valueAsString(sec, "server_address", "127.0.0.1:10002"),
+		CertFile:      valueAsString(sec, "cert_file", ""),
+		CertKey:       valueAsString(sec, "cert_key", ""),
+		Evaluators:    util.SplitString(valueAsString(sec, "evaluators", "")),
+		ClientTLS:     sec.Key("client_tls").MustBool(false),
+		ClientCACert:  valueAsString(sec, "client_ca_cert", ""),
+		Token:         valueAsString(sec, "token", ""),
+	}
+
+	if (remoteEvaluation.CertFile == "") != (remoteEvaluation.CertKey == "") {
+		cfg.Logger.Warn("Remote evaluation server TLS requires both cert_file and cert_key, using plaintext")
+		remoteEvaluation.CertFile, remoteEvaluation.CertKey = "", ""
+	}
+	if remoteEvaluation.Token == "" && (remoteEvaluation.ServerEnabled || len(remoteEvaluation.Evaluators) > 0) {
+		cfg.Logger.Warn("Remote evaluation requires a token, it's disabled")
+		remoteEvaluation.ServerEnabled = false
+		remoteEvaluation.Evaluators = nil
+	}
+
+	cfg.RemoteEvaluation = remoteEvaluation
+}
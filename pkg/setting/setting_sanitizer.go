@@ -0,0 +1,87 @@
+package setting
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+const htmlSanitizerOrgSectionPrefix = "html_sanitizer.org_"
+
+// Policies of the HTML sanitizer.
+const (
+	// HTMLSanitizerPolicyUGC keeps the formatting, links, images and tables.
+	HTMLSanitizerPolicyUGC = "ugc"
+	// HTMLSanitizerPolicyStrict only keeps the text.
+	HTMLSanitizerPolicyStrict = "strict"
+)
+
+type HTMLSanitizerSettings struct {
+	// Enabled is whether the text panel content and the annotation text are
+	// sanitized when saved through the API
+	Enabled bool
+	// Policy is the instance policy
+	Policy HTMLSanitizerPolicySettings
+	// OrgPolicies are the policies replacing the instance policy for the
+	// organizations, by organization ID
+	OrgPolicies map[int64]HTMLSanitizerPolicySettings
+}
+
+type HTMLSanitizerPolicySettings struct {
+	Policy               string
+	AllowClassAttributes bool
+	AllowIframes         bool
+}
+
+func (cfg *Cfg) readHTMLSanitizerSettings() error {
+	sec := cfg.Raw.Section("html_sanitizer")
+
+	policy, err := readHTMLSanitizerPolicy(sec, HTMLSanitizerPolicySettings{Policy: HTMLSanitizerPolicyUGC})
+	if err != nil {
+		return err
+	}
+
+	sanitizer := HTMLSanitizerSettings{
+		Enabled:     sec.Key("enabled").MustBool(false),
+		Policy:      policy,
+		OrgPolicies: map[int64]HTMLSanitizerPolicySettings{},
+	}
+
+	for _, section := range cfg.Raw.Sections() {
+		if !strings.HasPrefix(section.Name(), htmlSanitizerOrgSectionPrefix) {
+			continue
+		}
+
+		orgID, err := strconv.ParseInt(strings.TrimPrefix(section.Name(), htmlSanitizerOrgSectionPrefix), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid organization ID in section %q: %w", section.Name(), err)
+		}
+
+		// the keys the organization doesn't set are the instance ones
+		orgPolicy, err := readHTMLSanitizerPolicy(section, policy)
+		if err != nil {
+			return err
+		}
+		sanitizer.OrgPolicies[orgID] = orgPolicy
+	}
+
+	cfg.HTMLSanitizer = sanitizer
+	return nil
+}
+
+func readHTMLSanitizerPolicy(section *ini.Section, defaults HTMLSanitizerPolicySettings) (HTMLSanitizerPolicySettings, error) {
+	policy := HTMLSanitizerPolicySettings{
+		Policy:               valueAsString(section, "policy", defaults.Policy),
+		AllowClassAttributes: section.Key("allow_class_attributes").MustBool(defaults.AllowClassAttributes),
+		AllowIframes:         section.Key("allow_iframes").MustBool(defaults.AllowIframes),
+	}
+
+	switch policy.Policy {
+	case HTMLSanitizerPolicyUGC, HTMLSanitizerPolicyStrict:
+	default:
+		return policy, fmt.Errorf("invalid HTML sanitizer policy %q in section %q", policy.Policy, section.Name())
+	}
+	return policy, nil
+}
@@ -0,0 +1,41 @@
+package setting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTMLSanitizerSettings(t *testing.T) {
+	t.Run("organizations inherit the keys they don't set", func(t *testing.T) {
+		cfg := NewCfg()
+		sec, err := cfg.Raw.NewSection("html_sanitizer")
+		require.NoError(t, err)
+		_, err = sec.NewKey("enabled", "true")
+		require.NoError(t, err)
+		_, err = sec.NewKey("allow_class_attributes", "true")
+		require.NoError(t, err)
+
+		sec, err = cfg.Raw.NewSection("html_sanitizer.org_2")
+		require.NoError(t, err)
+		_, err = sec.NewKey("policy", "strict")
+		require.NoError(t, err)
+
+		require.NoError(t, cfg.readHTMLSanitizerSettings())
+		require.True(t, cfg.HTMLSanitizer.Enabled)
+		require.Equal(t, HTMLSanitizerPolicySettings{Policy: HTMLSanitizerPolicyUGC, AllowClassAttributes: true}, cfg.HTMLSanitizer.Policy)
+		require.Equal(t, map[int64]HTMLSanitizerPolicySettings{
+			2: {Policy: HTMLSanitizerPolicyStrict, AllowClassAttributes: true},
+		}, cfg.HTMLSanitizer.OrgPolicies)
+	})
+
+	t.Run("rejects unknown policies", func(t *testing.T) {
+		cfg := NewCfg()
+		sec, err := cfg.Raw.NewSection("html_sanitizer")
+		require.NoError(t, err)
+		_, err = sec.NewKey("policy", "lenient")
+		require.NoError(t, err)
+
+		require.Error(t, cfg.readHTMLSanitizerSettings())
+	})
+}
@@ -1,5 +1,13 @@
 package setting
 
+import (
+	"fmt"
+
+	"github.com/getsentry/sentry-go"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
 type Sentry struct {
 	Enabled        bool    `json:"enabled"`
 	DSN            string  `json:"dsn"`
@@ -7,16 +15,36 @@ type Sentry struct {
 	SampleRate     float64 `json:"sampleRate"`
 	EndpointRPS    int     `json:"-"`
 	EndpointBurst  int     `json:"-"`
+	// BackendSampleRate is the rate of the events received by the log endpoint which are kept
+	BackendSampleRate float64 `json:"-"`
+	// ScrubPII is whether the emails, IP addresses and credentials are removed from the events of the log endpoint
+	ScrubPII bool `json:"-"`
+	// ForwardDSNs are the DSNs of the Sentry compatible servers the events of the log endpoint are forwarded to
+	ForwardDSNs []string `json:"-"`
 }
 
-func (cfg *Cfg) readSentryConfig() {
+func (cfg *Cfg) readSentryConfig() error {
 	raw := cfg.Raw.Section("log.frontend")
 	cfg.Sentry = Sentry{
-		Enabled:        raw.Key("enabled").MustBool(true),
-		DSN:            raw.Key("sentry_dsn").String(),
-		CustomEndpoint: raw.Key("custom_endpoint").String(),
-		SampleRate:     raw.Key("sample_rate").MustFloat64(),
-		EndpointRPS:    raw.Key("log_endpoint_requests_per_second_limit").MustInt(),
-		EndpointBurst:  raw.Key("log_endpoint_burst_limit").MustInt(),
+		Enabled:           raw.Key("enabled").MustBool(true),
+		DSN:               raw.Key("sentry_dsn").String(),
+		CustomEndpoint:    raw.Key("custom_endpoint").String(),
+		SampleRate:        raw.Key("sample_rate").MustFloat64(),
+		EndpointRPS:       raw.Key("log_endpoint_requests_per_second_limit").MustInt(),
+		EndpointBurst:     raw.Key("log_endpoint_burst_limit").MustInt(),
+		BackendSampleRate: raw.Key("log_endpoint_sample_rate").MustFloat64(1),
+		ScrubPII:          raw.Key("log_endpoint_scrub_pii").MustBool(true),
+		ForwardDSNs:       util.SplitString(raw.Key("log_endpoint_forward_dsn").String()),
+	}
+
+	if cfg.Sentry.BackendSampleRate < 0 || cfg.Sentry.BackendSampleRate > 1 {
+		return fmt.Errorf("invalid log_endpoint_sample_rate %v in [log.frontend], expected a rate between 0 and 1",
+			cfg.Sentry.BackendSampleRate)
+	}
+	for _, dsn := range cfg.Sentry.ForwardDSNs {
+		if _, err := sentry.NewDsn(dsn); err != nil {
+			return fmt.Errorf("invalid log_endpoint_forward_dsn in [log.frontend]: %w", err)
+		}
 	}
+	return nil
 }
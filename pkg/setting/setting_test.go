@@ -379,6 +379,28 @@ func TestAuthDurationSettings(t *testing.T) {
 	err = readAuthSettings(f, cfg)
 	require.NoError(t, err)
 	require.Equal(t, maxLifetimeDurationTest, cfg.LoginMaxLifetime)
+	require.True(t, cfg.LoginExtendSessionOnActivity)
+	require.Zero(t, cfg.ApiKeyMaxInactiveLifetime)
+	require.Zero(t, cfg.ApiKeyMaxLifetime)
+	require.True(t, cfg.ApiKeyExtendOnActivity)
+
+	f = ini.Empty()
+	sec, err = f.NewSection("auth")
+	require.NoError(t, err)
+	_, err = sec.NewKey("login_extend_session_on_activity", "false")
+	require.NoError(t, err)
+	_, err = sec.NewKey("api_key_maximum_inactive_lifetime_duration", "30d")
+	require.NoError(t, err)
+	_, err = sec.NewKey("api_key_maximum_lifetime_duration", "52w")
+	require.NoError(t, err)
+	_, err = sec.NewKey("api_key_extend_on_activity", "false")
+	require.NoError(t, err)
+	err = readAuthSettings(f, cfg)
+	require.NoError(t, err)
+	require.False(t, cfg.LoginExtendSessionOnActivity)
+	require.Equal(t, 30*24*time.Hour, cfg.ApiKeyMaxInactiveLifetime)
+	require.Equal(t, 52*7*24*time.Hour, cfg.ApiKeyMaxLifetime)
+	require.False(t, cfg.ApiKeyExtendOnActivity)
 }
 
 func TestGetCDNPath(t *testing.T) {
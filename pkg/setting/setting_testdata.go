@@ -0,0 +1,59 @@
+package setting
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// testDataCSVDirName matches the names of the CSV directories of the testdata plugin, which are part of the paths
+// of the Grafana Live channels of the CSV streams.
+var testDataCSVDirName = regexp.MustCompile(`^[\w-]+$`)
+
+type TestDataSettings struct {
+	// CSVDirs maps the name of a directory of CSV files, its base name, to its absolute path
+	CSVDirs map[string]string
+	// CSVURLHosts are the hosts of the remote CSV files the scenarios can read, lower case. A *. prefix matches the
+	// subdomains of a host. The remote CSV files can't be read when empty.
+	CSVURLHosts []string
+	// CSVURLCacheTTL is the duration for which the remote CSV files are read from the cache without requests
+	CSVURLCacheTTL time.Duration
+}
+
+func (cfg *Cfg) readTestDataSettings() {
+	sec := cfg.Raw.Section("plugin.testdata")
+
+	testData := TestDataSettings{
+		CSVDirs:        map[string]string{},
+		CSVURLCacheTTL: sec.Key("csv_url_cache_ttl").MustDuration(5 * time.Minute),
+	}
+
+	for _, dir := range strings.Split(valueAsString(sec, "csv_dirs", ""), ",") {
+		if dir = strings.TrimSpace(dir); dir == "" {
+			continue
+		}
+
+		path := makeAbsolute(dir, HomePath)
+		name := filepath.Base(path)
+		if !testDataCSVDirName.MatchString(name) {
+			cfg.Logger.Warn("Ignoring testdata csv directory with an invalid name", "path", path)
+			continue
+		}
+		if existing, ok := testData.CSVDirs[name]; ok {
+			cfg.Logger.Warn("Ignoring testdata csv directory with the name of another one", "path", path,
+				"other", existing)
+			continue
+		}
+
+		testData.CSVDirs[name] = path
+	}
+
+	for _, host := range strings.Split(valueAsString(sec, "csv_url_hosts", ""), ",") {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			testData.CSVURLHosts = append(testData.CSVURLHosts, host)
+		}
+	}
+
+	cfg.TestData = testData
+}
@@ -0,0 +1,27 @@
+package setting
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTestDataSettings(t *testing.T) {
+	cfg := NewCfg()
+	sec, err := cfg.Raw.NewSection("plugin.testdata")
+	require.NoError(t, err)
+	_, err = sec.NewKey("csv_dirs", "/data/samples, customer-data ,/other/samples,/data/with space,")
+	require.NoError(t, err)
+	_, err = sec.NewKey("csv_url_hosts", "raw.githubusercontent.com, *.S3.amazonaws.com,")
+	require.NoError(t, err)
+
+	cfg.readTestDataSettings()
+	require.Equal(t, map[string]string{
+		"samples":       "/data/samples",
+		"customer-data": filepath.Join(HomePath, "customer-data"),
+	}, cfg.TestData.CSVDirs)
+	require.Equal(t, []string{"raw.githubusercontent.com", "*.s3.amazonaws.com"}, cfg.TestData.CSVURLHosts)
+	require.Equal(t, 5*time.Minute, cfg.TestData.CSVURLCacheTTL)
+}
@@ -0,0 +1,61 @@
+package setting
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/gtime"
+)
+
+type DashboardThumbnailsSettings struct {
+	// Enabled is whether the thumbnails of the dashboards are rendered, it
+	// requires the image renderer
+	Enabled bool
+	// Width is the width of the thumbnails in pixels, their height keeps the
+	// aspect ratio of the render viewport
+	Width int
+	// RenderWidth and RenderHeight are the size of the viewport the
+	// dashboards are rendered in before being scaled down
+	RenderWidth  int
+	RenderHeight int
+	// RenderTimeout is the timeout of a dashboard render
+	RenderTimeout time.Duration
+	// MaxAge is how long a thumbnail is used before being rendered again
+	// when its dashboard isn't updated, zero for never
+	MaxAge time.Duration
+}
+
+func (cfg *Cfg) readDashboardThumbnailsSettings() {
+	sec := cfg.Raw.Section("dashboard_thumbnails")
+
+	thumbnails := DashboardThumbnailsSettings{
+		Enabled:       sec.Key("enabled").MustBool(false),
+		Width:         sec.Key("width").MustInt(320),
+		RenderWidth:   sec.Key("render_width").MustInt(1280),
+		RenderHeight:  sec.Key("render_height").MustInt(960),
+		RenderTimeout: time.Minute,
+		MaxAge:        24 * time.Hour,
+	}
+
+	if thumbnails.RenderWidth <= 0 || thumbnails.RenderHeight <= 0 {
+		cfg.Logger.Warn("Invalid dashboard thumbnails render size, using default")
+		thumbnails.RenderWidth, thumbnails.RenderHeight = 1280, 960
+	}
+	if thumbnails.Width <= 0 || thumbnails.Width > thumbnails.RenderWidth {
+		cfg.Logger.Warn("Invalid dashboard thumbnails width, using the render width", "width", thumbnails.Width)
+		thumbnails.Width = thumbnails.RenderWidth
+	}
+
+	if timeout, err := gtime.ParseDuration(valueAsString(sec, "render_timeout", "1m")); err == nil && timeout > 0 {
+		thumbnails.RenderTimeout = timeout
+	} else {
+		cfg.Logger.Warn("Invalid dashboard thumbnails render timeout, using default", "err", err)
+	}
+
+	if maxAge, err := gtime.ParseDuration(valueAsString(sec, "max_age", "24h")); err == nil {
+		thumbnails.MaxAge = maxAge
+	} else {
+		cfg.Logger.Warn("Invalid dashboard thumbnails max age, using default", "err", err)
+	}
+
+	cfg.DashboardThumbnails = thumbnails
+}
@@ -0,0 +1,36 @@
+package setting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDashboardThumbnailsSettings(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		cfg := NewCfg()
+		cfg.readDashboardThumbnailsSettings()
+		require.Equal(t, DashboardThumbnailsSettings{
+			Width:         320,
+			RenderWidth:   1280,
+			RenderHeight:  960,
+			RenderTimeout: time.Minute,
+			MaxAge:        24 * time.Hour,
+		}, cfg.DashboardThumbnails)
+	})
+
+	t.Run("thumbnails aren't wider than the render", func(t *testing.T) {
+		cfg := NewCfg()
+		sec, err := cfg.Raw.NewSection("dashboard_thumbnails")
+		require.NoError(t, err)
+		_, err = sec.NewKey("width", "2000")
+		require.NoError(t, err)
+		_, err = sec.NewKey("max_age", "0")
+		require.NoError(t, err)
+
+		cfg.readDashboardThumbnailsSettings()
+		require.Equal(t, 1280, cfg.DashboardThumbnails.Width)
+		require.Zero(t, cfg.DashboardThumbnails.MaxAge)
+	})
+}
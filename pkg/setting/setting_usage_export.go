@@ -0,0 +1,57 @@
+package setting
+
+import (
+	"time"
+)
+
+const (
+	UsageExportFormatCSV  = "csv"
+	UsageExportFormatJSON = "json"
+)
+
+type UsageExportSettings struct {
+	// Enabled is whether the usage of the orgs is exported
+	Enabled bool
+	// Interval is the period of the usage of an export, a multiple of an hour
+	Interval time.Duration
+	// Format is the format of the exports, csv or json
+	Format string
+	// Storage is whether the exports are stored with the file storage
+	Storage bool
+	// WebhookURL is the URL the exports are posted to, if any
+	WebhookURL      string
+	WebhookUsername string
+	WebhookPassword string
+	// RetentionDays is how many days the recorded alert evaluations are kept
+	RetentionDays int
+}
+
+func (cfg *Cfg) readUsageExportSettings() {
+	sec := cfg.Raw.Section("usage_export")
+
+	usageExport := UsageExportSettings{
+		Enabled:         sec.Key("enabled").MustBool(false),
+		Interval:        sec.Key("interval").MustDuration(24 * time.Hour),
+		Format:          valueAsString(sec, "format", UsageExportFormatCSV),
+		Storage:         sec.Key("storage").MustBool(false),
+		WebhookURL:      valueAsString(sec, "webhook_url", ""),
+		WebhookUsername: valueAsString(sec, "webhook_username", ""),
+		WebhookPassword: valueAsString(sec, "webhook_password", ""),
+		RetentionDays:   sec.Key("retention_days").MustInt(90),
+	}
+
+	if usageExport.Interval < time.Hour || usageExport.Interval%time.Hour != 0 {
+		cfg.Logger.Warn("Invalid usage export interval, it must be a multiple of an hour, using the default", "interval", usageExport.Interval)
+		usageExport.Interval = 24 * time.Hour
+	}
+	if usageExport.Format != UsageExportFormatCSV && usageExport.Format != UsageExportFormatJSON {
+		cfg.Logger.Warn("Invalid usage export format, using the default", "format", usageExport.Format)
+		usageExport.Format = UsageExportFormatCSV
+	}
+	if usageExport.RetentionDays <= 0 {
+		cfg.Logger.Warn("Invalid usage export retention days, using the default", "retention_days", usageExport.RetentionDays)
+		usageExport.RetentionDays = 90
+	}
+
+	cfg.UsageExport = usageExport
+}
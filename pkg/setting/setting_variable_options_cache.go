@@ -0,0 +1,43 @@
+package setting
+
+import (
+	"time"
+)
+
+type VariableOptionsCacheSettings struct {
+	// Enabled is whether the options of the query variables resolved by the
+	// server are cached
+	Enabled bool
+	// TTL is how long the options are cached
+	TTL time.Duration
+	// TimeBucket is the precision of the time ranges of the cached options,
+	// the requests of the time ranges within the same buckets share them
+	TimeBucket time.Duration
+	// MaxEntries is the maximum number of cached options
+	MaxEntries int
+}
+
+func (cfg *Cfg) readVariableOptionsCacheSettings() {
+	sec := cfg.Raw.Section("variable_options_cache")
+
+	cache := VariableOptionsCacheSettings{
+		Enabled:    sec.Key("enabled").MustBool(false),
+		TTL:        sec.Key("ttl").MustDuration(5 * time.Minute),
+		TimeBucket: sec.Key("time_bucket").MustDuration(time.Minute),
+		MaxEntries: sec.Key("max_entries").MustInt(10000),
+	}
+
+	if cache.TTL <= 0 {
+		cfg.Logger.Warn("Invalid variable options cache TTL, using the default", "ttl", cache.TTL)
+		cache.TTL = 5 * time.Minute
+	}
+	if cache.TimeBucket <= 0 {
+		cache.TimeBucket = time.Minute
+	}
+	if cache.MaxEntries <= 0 {
+		cfg.Logger.Warn("Invalid variable options cache max entries, using the default", "max_entries", cache.MaxEntries)
+		cache.MaxEntries = 10000
+	}
+
+	cfg.VariableOptionsCache = cache
+}
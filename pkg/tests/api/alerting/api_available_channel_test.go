@@ -1287,6 +1287,70 @@ var expAvailableChannelJsonOutput = `
         "required": false,
         "validationRule": "",
         "secure": false
+      },
+      {
+        "element": "textarea",
+        "inputType": "",
+        "label": "HTTP Headers",
+        "description": "Custom headers added to the request, one \"Name: value\" header per line.",
+        "placeholder": "",
+        "propertyName": "httpHeaders",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "password",
+        "label": "HMAC Secret",
+        "description": "If set, the request is signed with HMAC-SHA256 of the timestamp header and the body joined by a dot.",
+        "placeholder": "",
+        "propertyName": "hmacSecret",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": true
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Signature Header",
+        "description": "Header the signature is sent in, prefixed with sha256=. The timestamp is sent in X-Grafana-Signature-Timestamp.",
+        "placeholder": "X-Grafana-Signature",
+        "propertyName": "signatureHeader",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "textarea",
+        "inputType": "",
+        "label": "Payload Template",
+        "description": "Optional template of the JSON payload, executed with the default webhook message. Use the json function to encode values, e.g. {{ json .Title }}.",
+        "placeholder": "",
+        "propertyName": "payloadTemplate",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
       }
     ]
   },
@@ -1596,6 +1660,197 @@ var expAvailableChannelJsonOutput = `
         "secure": false
       }
     ]
+  },
+  {
+    "type": "snmp",
+    "name": "SNMP",
+    "heading": "SNMP settings",
+    "description": "Sends SNMPv2c traps to an SNMP manager",
+    "info": "",
+    "options": [
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Address",
+        "description": "Host and port of the SNMP manager, the port defaults to 162",
+        "placeholder": "snmp.example.com:162",
+        "propertyName": "address",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": true,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "password",
+        "label": "Community",
+        "description": "",
+        "placeholder": "public",
+        "propertyName": "community",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": true
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Trap OID",
+        "description": "OID of the trap, the state, title, message, link, group key and number of alerts are sent as variables .1 to .6 below it",
+        "placeholder": "1.3.6.1.4.1.8072.9999.9999.1",
+        "propertyName": "trapOid",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      }
+    ]
+  },
+  {
+    "type": "syslog",
+    "name": "Syslog",
+    "heading": "Syslog settings",
+    "description": "Sends RFC5424 syslog messages",
+    "info": "",
+    "options": [
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Address",
+        "description": "",
+        "placeholder": "syslog.example.com:514",
+        "propertyName": "address",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": true,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "select",
+        "inputType": "",
+        "label": "Protocol",
+        "description": "",
+        "placeholder": "",
+        "propertyName": "protocol",
+        "selectOptions": [
+          {
+            "value": "udp",
+            "label": "UDP"
+          },
+          {
+            "value": "tcp",
+            "label": "TCP"
+          },
+          {
+            "value": "tls",
+            "label": "TLS"
+          }
+        ],
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Facility",
+        "description": "Syslog facility, e.g. local0 or daemon",
+        "placeholder": "local0",
+        "propertyName": "facility",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Severity",
+        "description": "Severity of firing alerts, e.g. warning or crit. Resolved alerts are sent with the notice severity.",
+        "placeholder": "warning",
+        "propertyName": "severity",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "App Name",
+        "description": "",
+        "placeholder": "grafana",
+        "propertyName": "appName",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Enterprise ID",
+        "description": "Private enterprise number used to add the group key and number of alerts as structured data",
+        "placeholder": "",
+        "propertyName": "enterpriseId",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "textarea",
+        "inputType": "",
+        "label": "Message",
+        "description": "",
+        "placeholder": "{{ template \"default.title\" . }}",
+        "propertyName": "message",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      }
+    ]
   }
 ]
 `
@@ -298,3 +298,96 @@ func createRule(t *testing.T, grafanaListedAddr string, folder string, user, pas
 	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
 	require.JSONEq(t, `{"message":"rule group updated successfully"}`, string(b))
 }
+
+func TestAlertRuleVersions(t *testing.T) {
+	// Setup Grafana and its Database
+	dir, path := testinfra.CreateGrafDir(t, testinfra.GrafanaOpts{
+		EnableFeatureToggles: []string{"ngalert"},
+		DisableAnonymous:     true,
+	})
+	store := testinfra.SetUpDatabase(t, dir)
+	// override bus to get the GetSignedInUserQuery handler
+	store.Bus = bus.GetBus()
+	grafanaListedAddr := testinfra.StartGrafana(t, dir, path, store)
+
+	require.NoError(t, createUser(t, store, models.ROLE_EDITOR, "grafana", "password"))
+	_, err := createFolder(t, store, 0, "folder1")
+	require.NoError(t, err)
+
+	createRule(t, grafanaListedAddr, "folder1", "grafana", "password")
+
+	var group apimodels.RuleGroupConfigResponse
+	resp := getRequest(t, fmt.Sprintf("http://grafana:password@%s/api/ruler/grafana/api/v1/rules/folder1/arulegroup", grafanaListedAddr), http.StatusAccepted)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&group))
+	require.Len(t, group.Rules, 1)
+	rule := group.Rules[0]
+	ruleUID := rule.GrafanaManagedAlert.UID
+
+	// Update the title of the rule to store a second version.
+	{
+		rules := apimodels.PostableRuleGroupConfig{
+			Name:     "arulegroup",
+			Interval: group.Interval,
+			Rules: []apimodels.PostableExtendedRuleNode{
+				{
+					ApiRuleNode: rule.ApiRuleNode,
+					GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+						UID:       ruleUID,
+						Title:     "updated rule",
+						Condition: rule.GrafanaManagedAlert.Condition,
+						Data:      rule.GrafanaManagedAlert.Data,
+					},
+				},
+			},
+		}
+		b, err := json.Marshal(&rules)
+		require.NoError(t, err)
+		postRequest(t, fmt.Sprintf("http://grafana:password@%s/api/ruler/grafana/api/v1/rules/folder1", grafanaListedAddr), string(b), http.StatusAccepted)
+	}
+
+	versionsURL := fmt.Sprintf("http://grafana:password@%s/api/ruler/grafana/api/v1/rule/%s/versions", grafanaListedAddr, ruleUID)
+	getVersions := func() apimodels.RuleVersionsResponse {
+		var versions apimodels.RuleVersionsResponse
+		resp := getRequest(t, versionsURL, http.StatusOK)
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&versions))
+		return versions
+	}
+
+	versions := getVersions()
+	require.Len(t, versions, 2)
+	assert.Equal(t, int64(2), versions[0].Version)
+	assert.Equal(t, "updated rule", versions[0].Title)
+	assert.Equal(t, int64(1), versions[1].Version)
+	assert.Equal(t, "rule under folder folder1", versions[1].Title)
+
+	// Get the first version with its rule.
+	{
+		var v apimodels.GettableRuleVersion
+		resp := getRequest(t, versionsURL+"/1", http.StatusOK)
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&v))
+		require.NotNil(t, v.Rule)
+		assert.Equal(t, "rule under folder folder1", v.Rule.GrafanaManagedAlert.Title)
+		assert.Equal(t, int64(1), v.Rule.GrafanaManagedAlert.Version)
+	}
+
+	getRequest(t, versionsURL+"/3", http.StatusNotFound)
+
+	// The diff with the previous version only has the title change.
+	{
+		resp := getRequest(t, versionsURL+"/2/diff?diffType=delta", http.StatusOK)
+		assert.Contains(t, getBody(t, resp.Body), "updated rule")
+	}
+
+	// Restore the first version.
+	restoreURL := fmt.Sprintf("http://grafana:password@%s/api/ruler/grafana/api/v1/rule/%s/restore", grafanaListedAddr, ruleUID)
+	resp = postRequest(t, restoreURL, `{"version": 1}`, http.StatusAccepted)
+	require.JSONEq(t, `{"message":"rule restored from version 1"}`, getBody(t, resp.Body))
+
+	versions = getVersions()
+	require.Len(t, versions, 3)
+	assert.Equal(t, int64(3), versions[0].Version)
+	assert.Equal(t, int64(1), versions[0].RestoredFrom)
+	assert.Equal(t, "rule under folder folder1", versions[0].Title)
+
+	postRequest(t, restoreURL, `{"version": 4}`, http.StatusNotFound)
+}
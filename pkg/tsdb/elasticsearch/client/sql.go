@@ -0,0 +1,137 @@
+package es
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Query languages of the SQL plugins.
+const (
+	SQLLanguageSQL = "sql"
+	SQLLanguagePPL = "ppl"
+)
+
+// SQL plugins of the data source setting sqlPlugin, which decide the endpoints SQL and PPL queries are posted to.
+const (
+	SQLPluginOpenSearch    = "opensearch"
+	SQLPluginOpenDistro    = "opendistro"
+	SQLPluginElasticsearch = "elasticsearch"
+)
+
+// sqlEndpoints are the endpoints of the query languages by SQL plugin. The SQL of Elasticsearch is returned in the
+// JSON format, it has no PPL endpoint.
+var sqlEndpoints = map[string]map[string]struct{ path, query string }{
+	SQLPluginOpenSearch: {
+		SQLLanguageSQL: {path: "_plugins/_sql"},
+		SQLLanguagePPL: {path: "_plugins/_ppl"},
+	},
+	SQLPluginOpenDistro: {
+		SQLLanguageSQL: {path: "_opendistro/_sql"},
+		SQLLanguagePPL: {path: "_opendistro/_ppl"},
+	},
+	SQLPluginElasticsearch: {
+		SQLLanguageSQL: {path: "_sql", query: "format=json"},
+	},
+}
+
+// SQLRequest represents a SQL or PPL query request
+type SQLRequest struct {
+	Language string
+	Query    string
+}
+
+// SQLColumn represents a column of a SQL response
+type SQLColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// SQLResponse represents the tabular result of a SQL or PPL query, of either the OpenSearch plugins or Elasticsearch
+type SQLResponse struct {
+	Status  int
+	Columns []SQLColumn
+	Rows    [][]interface{}
+	// Error is the reason of the error of a failed query
+	Error string
+}
+
+type sqlResponseBody struct {
+	// columns and rows of the OpenSearch plugins
+	Schema   []SQLColumn     `json:"schema"`
+	Datarows [][]interface{} `json:"datarows"`
+	// columns and rows of Elasticsearch
+	Columns []SQLColumn     `json:"columns"`
+	Rows    [][]interface{} `json:"rows"`
+	Error   *struct {
+		Reason  string `json:"reason"`
+		Details string `json:"details"`
+	} `json:"error"`
+}
+
+func (c *baseClientImpl) ExecuteSQL(r *SQLRequest) (*SQLResponse, error) {
+	plugin := c.getSettings().Get("sqlPlugin").MustString(SQLPluginOpenSearch)
+	endpoints, ok := sqlEndpoints[plugin]
+	if !ok {
+		return nil, fmt.Errorf("unsupported SQL plugin %q", plugin)
+	}
+	endpoint, ok := endpoints[r.Language]
+	if !ok {
+		return nil, fmt.Errorf("%s queries aren't supported by the %s SQL plugin", strings.ToUpper(r.Language), plugin)
+	}
+
+	body, err := json.Marshal(map[string]string{"query": r.Query})
+	if err != nil {
+		return nil, err
+	}
+
+	clientLog.Debug("Executing SQL query", "language", r.Language, "plugin", plugin)
+	clientRes, err := c.executeRequest(http.MethodPost, endpoint.path, endpoint.query, body, "application/json")
+	if err != nil {
+		return nil, err
+	}
+	res := clientRes.httpResponse
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			clientLog.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	start := time.Now()
+	bodyBytes, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var resBody sqlResponseBody
+	if err := json.Unmarshal(bodyBytes, &resBody); err != nil {
+		if res.StatusCode/100 != 2 {
+			return &SQLResponse{Status: res.StatusCode, Error: strings.TrimSpace(string(bodyBytes))}, nil
+		}
+		return nil, err
+	}
+	clientLog.Debug("Decoded SQL json response", "took", time.Since(start))
+
+	sr := &SQLResponse{
+		Status:  res.StatusCode,
+		Columns: resBody.Schema,
+		Rows:    resBody.Datarows,
+	}
+	if resBody.Columns != nil {
+		sr.Columns = resBody.Columns
+		sr.Rows = resBody.Rows
+	}
+	if resBody.Error != nil {
+		sr.Error = resBody.Error.Reason
+		if resBody.Error.Details != "" {
+			sr.Error = fmt.Sprintf("%s: %s", sr.Error, resBody.Error.Details)
+		}
+	} else if res.StatusCode/100 != 2 {
+		sr.Error = res.Status
+	}
+
+	return sr, nil
+}
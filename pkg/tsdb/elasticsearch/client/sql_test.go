@@ -0,0 +1,91 @@
+package es
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_ExecuteSQL(t *testing.T) {
+	httpClientScenario(t, "Given a fake http client and an OpenSearch client with a PPL response", &models.DataSource{
+		Database: "logs",
+		JsonData: simplejson.NewFromAny(map[string]interface{}{
+			"esVersion": 70,
+			"timeField": "@timestamp",
+		}),
+	}, func(sc *scenarioContext) {
+		sc.responseBody = `{
+			"schema": [{ "name": "host", "type": "string" }, { "name": "count()", "type": "integer" }],
+			"datarows": [["a", 2], ["b", 1]],
+			"total": 2,
+			"size": 2
+		}`
+
+		res, err := sc.client.ExecuteSQL(&SQLRequest{Language: SQLLanguagePPL, Query: "source=logs | stats count() by host"})
+		require.NoError(t, err)
+
+		require.NotNil(t, sc.request)
+		assert.Equal(t, http.MethodPost, sc.request.Method)
+		assert.Equal(t, "/_plugins/_ppl", sc.request.URL.Path)
+		assert.Equal(t, "application/json", sc.request.Header.Get("Content-Type"))
+
+		jBody, err := simplejson.NewJson(sc.requestBody.Bytes())
+		require.NoError(t, err)
+		assert.Equal(t, "source=logs | stats count() by host", jBody.Get("query").MustString())
+
+		assert.Equal(t, 200, res.Status)
+		assert.Empty(t, res.Error)
+		assert.Equal(t, []SQLColumn{{Name: "host", Type: "string"}, {Name: "count()", Type: "integer"}}, res.Columns)
+		assert.Equal(t, [][]interface{}{{"a", float64(2)}, {"b", float64(1)}}, res.Rows)
+	})
+
+	httpClientScenario(t, "Given a fake http client and an Elasticsearch client with a SQL response", &models.DataSource{
+		Database: "logs",
+		JsonData: simplejson.NewFromAny(map[string]interface{}{
+			"esVersion": 70,
+			"timeField": "@timestamp",
+			"sqlPlugin": SQLPluginElasticsearch,
+		}),
+	}, func(sc *scenarioContext) {
+		sc.responseBody = `{
+			"columns": [{ "name": "host", "type": "keyword" }],
+			"rows": [["a"]]
+		}`
+
+		res, err := sc.client.ExecuteSQL(&SQLRequest{Language: SQLLanguageSQL, Query: "SELECT host FROM logs"})
+		require.NoError(t, err)
+
+		require.NotNil(t, sc.request)
+		assert.Equal(t, "/_sql", sc.request.URL.Path)
+		assert.Equal(t, "format=json", sc.request.URL.RawQuery)
+		assert.Equal(t, []SQLColumn{{Name: "host", Type: "keyword"}}, res.Columns)
+		assert.Equal(t, [][]interface{}{{"a"}}, res.Rows)
+
+		_, err = sc.client.ExecuteSQL(&SQLRequest{Language: SQLLanguagePPL, Query: "source=logs"})
+		require.Error(t, err)
+	})
+
+	httpClientScenario(t, "Given a fake http client and an OpenDistro client with an error response", &models.DataSource{
+		Database: "logs",
+		JsonData: simplejson.NewFromAny(map[string]interface{}{
+			"esVersion": 70,
+			"timeField": "@timestamp",
+			"sqlPlugin": SQLPluginOpenDistro,
+		}),
+	}, func(sc *scenarioContext) {
+		sc.responseBody = `{
+			"error": { "reason": "Invalid SQL query", "details": "unknown field [hots]", "type": "SemanticCheckException" },
+			"status": 400
+		}`
+
+		res, err := sc.client.ExecuteSQL(&SQLRequest{Language: SQLLanguageSQL, Query: "SELECT hots FROM logs"})
+		require.NoError(t, err)
+
+		assert.Equal(t, "/_opendistro/_sql", sc.request.URL.Path)
+		assert.Equal(t, "Invalid SQL query: unknown field [hots]", res.Error)
+	})
+}
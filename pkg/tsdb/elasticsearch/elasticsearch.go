@@ -46,6 +46,39 @@ func (e *Executor) DataQuery(ctx context.Context, dsInfo *models.DataSource,
 		client.EnableDebug()
 	}
 
-	query := newTimeSeriesQuery(client, tsdbQuery, e.intervalCalculator)
-	return query.execute()
+	// SQL and PPL queries are executed apart from the queries of the aggregation DSL, which are sent in a single
+	// multisearch request
+	sqlTsdbQuery, timeSeriesTsdbQuery := tsdbQuery, tsdbQuery
+	sqlTsdbQuery.Queries, timeSeriesTsdbQuery.Queries = nil, nil
+	for _, q := range tsdbQuery.Queries {
+		if sqlLanguage(q.Model) != "" {
+			sqlTsdbQuery.Queries = append(sqlTsdbQuery.Queries, q)
+		} else {
+			timeSeriesTsdbQuery.Queries = append(timeSeriesTsdbQuery.Queries, q)
+		}
+	}
+
+	if len(sqlTsdbQuery.Queries) == 0 {
+		query := newTimeSeriesQuery(client, tsdbQuery, e.intervalCalculator)
+		return query.execute()
+	}
+
+	sqlQuery := &sqlQuery{client: client, tsdbQuery: sqlTsdbQuery}
+	result, err := sqlQuery.execute()
+	if err != nil {
+		return plugins.DataResponse{}, err
+	}
+	if len(timeSeriesTsdbQuery.Queries) == 0 {
+		return result, nil
+	}
+
+	query := newTimeSeriesQuery(client, timeSeriesTsdbQuery, e.intervalCalculator)
+	timeSeriesResult, err := query.execute()
+	if err != nil {
+		return plugins.DataResponse{}, err
+	}
+	for refID, res := range timeSeriesResult.Results {
+		result.Results[refID] = res
+	}
+	return result, nil
 }
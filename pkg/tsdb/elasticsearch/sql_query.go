@@ -0,0 +1,189 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/plugins"
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+// sqlTimeFormat is the format of the times of the $__timeFilter macro, which both SQL plugins and Elasticsearch
+// compare with dates.
+const sqlTimeFormat = "2006-01-02 15:04:05"
+
+// sqlTimeLayouts are the layouts of the dates and times of SQL responses.
+var sqlTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999",
+	time.RFC3339Nano,
+	"2006-01-02",
+}
+
+type sqlQuery struct {
+	client    es.Client
+	tsdbQuery plugins.DataQuery
+}
+
+// sqlLanguage returns the SQL or PPL language of the query type of a query, empty for the queries of the aggregation
+// DSL.
+func sqlLanguage(model *simplejson.Json) string {
+	switch language := strings.ToLower(model.Get("queryType").MustString()); language {
+	case es.SQLLanguageSQL, es.SQLLanguagePPL:
+		return language
+	default:
+		return ""
+	}
+}
+
+// nolint:staticcheck // plugins.DataQueryResult deprecated
+func (e *sqlQuery) execute() (plugins.DataResponse, error) {
+	result := plugins.DataResponse{
+		Results: make(map[string]plugins.DataQueryResult),
+	}
+
+	for _, q := range e.tsdbQuery.Queries {
+		language := sqlLanguage(q.Model)
+		raw := q.Model.Get("query").MustString()
+		queryResult := plugins.DataQueryResult{RefID: q.RefID}
+		if strings.TrimSpace(raw) == "" {
+			queryResult.Error = fmt.Errorf("%s query is empty", strings.ToUpper(language))
+			queryResult.ErrorString = queryResult.Error.Error()
+			result.Results[q.RefID] = queryResult
+			continue
+		}
+
+		query := e.interpolate(raw)
+		res, err := e.client.ExecuteSQL(&es.SQLRequest{Language: language, Query: query})
+		if err != nil {
+			return plugins.DataResponse{}, err
+		}
+		if res.Error != "" {
+			queryResult.Error = errors.New(res.Error)
+			queryResult.ErrorString = res.Error
+			result.Results[q.RefID] = queryResult
+			continue
+		}
+
+		frame := sqlResponseToFrame(res)
+		frame.RefID = q.RefID
+		frame.Meta = &data.FrameMeta{ExecutedQueryString: query}
+		queryResult.Dataframes = plugins.NewDecodedDataFrames(data.Frames{frame})
+		result.Results[q.RefID] = queryResult
+	}
+
+	return result, nil
+}
+
+// interpolate replaces the $__timeFilter macro with the condition of the time range on the time field of the data
+// source, since SQL and PPL queries aren't filtered by it otherwise.
+func (e *sqlQuery) interpolate(query string) string {
+	from := e.tsdbQuery.TimeRange.GetFromAsTimeUTC().Format(sqlTimeFormat)
+	to := e.tsdbQuery.TimeRange.GetToAsTimeUTC().Format(sqlTimeFormat)
+	timeField := e.client.GetTimeField()
+	filter := fmt.Sprintf("`%s` >= '%s' AND `%s` <= '%s'", timeField, from, timeField, to)
+	return strings.ReplaceAll(query, "$__timeFilter", filter)
+}
+
+// sqlResponseToFrame returns the frame of the columns of the response, numbers as floats, dates as times, booleans
+// as booleans and the other values as strings.
+func sqlResponseToFrame(res *es.SQLResponse) *data.Frame {
+	fields := make([]*data.Field, 0, len(res.Columns))
+	for i, column := range res.Columns {
+		var field *data.Field
+		switch sqlColumnType(column.Type) {
+		case data.FieldTypeNullableFloat64:
+			values := make([]*float64, len(res.Rows))
+			for j, row := range res.Rows {
+				if v, ok := rowValue(row, i).(float64); ok {
+					values[j] = &v
+				}
+			}
+			field = data.NewField(column.Name, nil, values)
+		case data.FieldTypeNullableTime:
+			values := make([]*time.Time, len(res.Rows))
+			for j, row := range res.Rows {
+				values[j] = parseSQLTime(rowValue(row, i))
+			}
+			field = data.NewField(column.Name, nil, values)
+		case data.FieldTypeNullableBool:
+			values := make([]*bool, len(res.Rows))
+			for j, row := range res.Rows {
+				if v, ok := rowValue(row, i).(bool); ok {
+					values[j] = &v
+				}
+			}
+			field = data.NewField(column.Name, nil, values)
+		default:
+			values := make([]*string, len(res.Rows))
+			for j, row := range res.Rows {
+				values[j] = sqlString(rowValue(row, i))
+			}
+			field = data.NewField(column.Name, nil, values)
+		}
+		fields = append(fields, field)
+	}
+
+	return data.NewFrame("", fields...)
+}
+
+func sqlColumnType(columnType string) data.FieldType {
+	switch strings.ToLower(columnType) {
+	case "long", "integer", "short", "byte", "double", "float", "half_float", "scaled_float", "unsigned_long":
+		return data.FieldTypeNullableFloat64
+	case "date", "datetime", "timestamp", "date_nanos":
+		return data.FieldTypeNullableTime
+	case "boolean":
+		return data.FieldTypeNullableBool
+	default:
+		return data.FieldTypeNullableString
+	}
+}
+
+func rowValue(row []interface{}, i int) interface{} {
+	if i >= len(row) {
+		return nil
+	}
+	return row[i]
+}
+
+// parseSQLTime returns the time of a date, either formatted or in epoch milliseconds.
+func parseSQLTime(value interface{}) *time.Time {
+	switch v := value.(type) {
+	case float64:
+		t := time.Unix(0, int64(v)*int64(time.Millisecond)).UTC()
+		return &t
+	case string:
+		for _, layout := range sqlTimeLayouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				t = t.UTC()
+				return &t
+			}
+		}
+	}
+	return nil
+}
+
+// sqlString returns the string of a value, objects and arrays as JSON.
+func sqlString(value interface{}) *string {
+	var s string
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		s = v
+	case map[string]interface{}, []interface{}:
+		bytes, err := json.Marshal(v)
+		if err != nil {
+			return nil
+		}
+		s = string(bytes)
+	default:
+		s = fmt.Sprint(v)
+	}
+	return &s
+}
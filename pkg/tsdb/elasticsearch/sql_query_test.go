@@ -0,0 +1,100 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/plugins"
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+	"github.com/stretchr/testify/require"
+)
+
+// nolint:staticcheck // plugins.DataQueryResult deprecated
+func executeSQLQuery(t *testing.T, c es.Client, body string, from, to time.Time) plugins.DataResponse {
+	t.Helper()
+
+	model, err := simplejson.NewJson([]byte(body))
+	require.NoError(t, err)
+	timeRange := plugins.NewDataTimeRange(fmt.Sprintf("%d", from.UnixNano()/int64(time.Millisecond)),
+		fmt.Sprintf("%d", to.UnixNano()/int64(time.Millisecond)))
+	query := &sqlQuery{client: c, tsdbQuery: plugins.DataQuery{
+		Queries:   []plugins.DataSubQuery{{RefID: "A", Model: model}},
+		TimeRange: &timeRange,
+	}}
+
+	res, err := query.execute()
+	require.NoError(t, err)
+	return res
+}
+
+func TestExecuteSQLQuery(t *testing.T) {
+	from := time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC)
+	to := time.Date(2018, 5, 15, 17, 55, 0, 0, time.UTC)
+
+	t.Run("Should interpolate the time filter and convert the columns", func(t *testing.T) {
+		c := newFakeClient("7.0.0")
+		c.sqlResponse = &es.SQLResponse{
+			Status: 200,
+			Columns: []es.SQLColumn{
+				{Name: "@timestamp", Type: "timestamp"},
+				{Name: "host", Type: "keyword"},
+				{Name: "bytes", Type: "long"},
+				{Name: "error", Type: "boolean"},
+			},
+			Rows: [][]interface{}{
+				{"2018-05-15 17:51:00.123", "a", float64(10), true},
+				{float64(1526406720000), nil, nil, false},
+			},
+		}
+
+		res := executeSQLQuery(t, c, `{
+			"queryType": "sql",
+			"query": "SELECT * FROM logs WHERE $__timeFilter"
+		}`, from, to)
+
+		require.Len(t, c.sqlRequests, 1)
+		require.Equal(t, es.SQLLanguageSQL, c.sqlRequests[0].Language)
+		expected := "SELECT * FROM logs WHERE `@timestamp` >= '2018-05-15 17:50:00' AND `@timestamp` <= '2018-05-15 17:55:00'"
+		require.Equal(t, expected, c.sqlRequests[0].Query)
+
+		result := res.Results["A"]
+		require.NoError(t, result.Error)
+		frames, err := result.Dataframes.Decoded()
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		frame := frames[0]
+		require.Equal(t, "A", frame.RefID)
+		require.Equal(t, expected, frame.Meta.ExecutedQueryString)
+		require.Len(t, frame.Fields, 4)
+
+		require.Equal(t, time.Date(2018, 5, 15, 17, 51, 0, 123000000, time.UTC), *frame.Fields[0].At(0).(*time.Time))
+		require.Equal(t, time.Date(2018, 5, 15, 17, 52, 0, 0, time.UTC), *frame.Fields[0].At(1).(*time.Time))
+		require.Equal(t, "a", *frame.Fields[1].At(0).(*string))
+		require.Nil(t, frame.Fields[1].At(1))
+		require.Equal(t, float64(10), *frame.Fields[2].At(0).(*float64))
+		require.Nil(t, frame.Fields[2].At(1))
+		require.True(t, *frame.Fields[3].At(0).(*bool))
+		require.False(t, *frame.Fields[3].At(1).(*bool))
+	})
+
+	t.Run("Should return the error of the response", func(t *testing.T) {
+		c := newFakeClient("7.0.0")
+		c.sqlResponse = &es.SQLResponse{Status: 400, Error: "Invalid SQL query"}
+
+		res := executeSQLQuery(t, c, `{ "queryType": "ppl", "query": "source=logs | where" }`, from, to)
+
+		require.Equal(t, es.SQLLanguagePPL, c.sqlRequests[0].Language)
+		require.EqualError(t, res.Results["A"].Error, "Invalid SQL query")
+	})
+
+	t.Run("Should return an error for empty queries", func(t *testing.T) {
+		c := newFakeClient("7.0.0")
+
+		res := executeSQLQuery(t, c, `{ "queryType": "sql", "query": " " }`, from, to)
+
+		require.Empty(t, c.sqlRequests)
+		require.EqualError(t, res.Results["A"].Error, "SQL query is empty")
+	})
+}
@@ -1018,6 +1018,8 @@ type fakeClient struct {
 	multiSearchError    error
 	builder             *es.MultiSearchRequestBuilder
 	multisearchRequests []*es.MultiSearchRequest
+	sqlResponse         *es.SQLResponse
+	sqlRequests         []*es.SQLRequest
 }
 
 func newFakeClient(versionString string) *fakeClient {
@@ -1027,6 +1029,7 @@ func newFakeClient(versionString string) *fakeClient {
 		timeField:           "@timestamp",
 		multisearchRequests: make([]*es.MultiSearchRequest, 0),
 		multiSearchResponse: &es.MultiSearchResponse{},
+		sqlResponse:         &es.SQLResponse{},
 	}
 }
 
@@ -1049,6 +1052,11 @@ func (c *fakeClient) ExecuteMultisearch(r *es.MultiSearchRequest) (*es.MultiSear
 	return c.multiSearchResponse, c.multiSearchError
 }
 
+func (c *fakeClient) ExecuteSQL(r *es.SQLRequest) (*es.SQLResponse, error) {
+	c.sqlRequests = append(c.sqlRequests, r)
+	return c.sqlResponse, nil
+}
+
 func (c *fakeClient) MultiSearch() *es.MultiSearchRequestBuilder {
 	c.builder = es.NewMultiSearchRequestBuilder(c.version)
 	return c.builder
@@ -13,11 +13,11 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
-	"github.com/grafana/grafana-plugin-sdk-go/experimental"
 	"github.com/grafana/grafana/pkg/components/securejsondata"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/xorcare/pointer"
@@ -71,9 +71,7 @@ func executeMockedQuery(t *testing.T, name string, query queryModel) *backend.Da
 func verifyGoldenResponse(t *testing.T, name string) *backend.DataResponse {
 	dr := executeMockedQuery(t, name, queryModel{MaxDataPoints: 100})
 
-	err := experimental.CheckGoldenDataResponse(filepath.Join("testdata", fmt.Sprintf("%s.golden.txt", name)),
-		dr, true)
-	require.NoError(t, err)
+	testutil.CheckGoldenDataResponse(t, filepath.Join("testdata", fmt.Sprintf("%s.golden.txt", name)), dr)
 	require.NoError(t, dr.Error)
 
 	return dr
@@ -238,8 +236,7 @@ func TestRealQuery(t *testing.T) {
 			MaxDataPoints: 100,
 			RawQuery:      "buckets()",
 		}, runner, 50)
-		err = experimental.CheckGoldenDataResponse(filepath.Join("testdata", "buckets-real.golden.txt"), &dr, true)
-		require.NoError(t, err)
+		testutil.CheckGoldenDataResponse(t, filepath.Join("testdata", "buckets-real.golden.txt"), &dr)
 	})
 }
 
@@ -0,0 +1,131 @@
+package loki
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// valueRawMacro is interpolated with the value of the field by the consumers of the links.
+const valueRawMacro = "${__value.raw}"
+
+// derivedFieldConfig is a derived field of the settings of the data source, the regex extracts the value of the field
+// from the log lines.
+type derivedFieldConfig struct {
+	Name          string `json:"name"`
+	MatcherRegex  string `json:"matcherRegex"`
+	URL           string `json:"url"`
+	DatasourceUID string `json:"datasourceUid"`
+}
+
+// derivedField is a field added to the frames of log queries, with the links of the derived fields of its name. The
+// regex of the first derived field of the name is used, like in the frontend.
+type derivedField struct {
+	name  string
+	regex *regexp.Regexp
+	links []data.DataLink
+}
+
+// parseDerivedFields returns the derived fields of the settings of the data source, with the links for the time
+// range of the query. Derived fields with invalid regexes are skipped.
+func parseDerivedFields(dsInfo *models.DataSource, query *lokiQuery) []*derivedField {
+	if dsInfo.JsonData == nil {
+		return nil
+	}
+	raw, err := dsInfo.JsonData.Get("derivedFields").MarshalJSON()
+	if err != nil {
+		return nil
+	}
+	var configs []derivedFieldConfig
+	if err := json.Unmarshal(raw, &configs); err != nil {
+		plog.Warn("Invalid derived fields", "datasource", dsInfo.Name, "error", err)
+		return nil
+	}
+
+	var fields []*derivedField
+	byName := map[string]*derivedField{}
+	for _, config := range configs {
+		field, ok := byName[config.Name]
+		if !ok {
+			regex, err := regexp.Compile(config.MatcherRegex)
+			if err != nil {
+				plog.Warn("Invalid regex of derived field", "datasource", dsInfo.Name, "field", config.Name,
+					"error", err)
+				continue
+			}
+			field = &derivedField{name: config.Name, regex: regex}
+			byName[config.Name] = field
+			fields = append(fields, field)
+		}
+
+		if link, ok := derivedFieldLink(dsInfo, config, query); ok {
+			field.links = append(field.links, link)
+		}
+	}
+
+	return fields
+}
+
+// derivedFieldLink returns the link of the derived field, to Explore for internal links. The links keep the macro of
+// the value, since they're shared by the values of the field.
+func derivedFieldLink(dsInfo *models.DataSource, config derivedFieldConfig, query *lokiQuery) (data.DataLink, bool) {
+	if config.DatasourceUID == "" {
+		return data.DataLink{URL: config.URL}, config.URL != ""
+	}
+
+	dsQuery := &models.GetDataSourceQuery{Uid: config.DatasourceUID, OrgId: dsInfo.OrgId}
+	if err := bus.Dispatch(dsQuery); err != nil {
+		plog.Warn("Failed to get the data source of derived field", "datasource", dsInfo.Name, "field", config.Name,
+			"uid", config.DatasourceUID, "error", err)
+		return data.DataLink{}, false
+	}
+
+	// the query is hardcoded for Jaeger and Zipkin, like in the frontend
+	state, err := json.Marshal([]interface{}{
+		strconv.FormatInt(query.Start.UnixNano()/1e6, 10),
+		strconv.FormatInt(query.End.UnixNano()/1e6, 10),
+		dsQuery.Result.Name,
+		map[string]string{"query": config.URL},
+	})
+	if err != nil {
+		return data.DataLink{}, false
+	}
+
+	left := strings.ReplaceAll(url.QueryEscape(string(state)), url.QueryEscape(valueRawMacro), valueRawMacro)
+	return data.DataLink{
+		Title: dsQuery.Result.Name,
+		URL:   fmt.Sprintf("%s/explore?orgId=%d&left=%s", setting.AppSubUrl, dsInfo.OrgId, left),
+	}, true
+}
+
+// addDerivedFields adds the derived fields to the frame of a stream, with the first capture group of the regex, or
+// null when the line doesn't match.
+func addDerivedFields(frame *data.Frame, fields []*derivedField) {
+	if len(fields) == 0 || len(frame.Fields) < 2 {
+		return
+	}
+	lines := frame.Fields[1]
+
+	for _, field := range fields {
+		values := make([]*string, lines.Len())
+		for i := 0; i < lines.Len(); i++ {
+			line, _ := lines.At(i).(string)
+			if match := field.regex.FindStringSubmatch(line); len(match) > 1 {
+				value := match[1]
+				values[i] = &value
+			}
+		}
+
+		frame.Fields = append(frame.Fields, data.NewField(field.name, nil, values).SetConfig(&data.FieldConfig{
+			Links: field.links,
+		}))
+	}
+}
@@ -0,0 +1,68 @@
+package loki
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/loki/pkg/loghttp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDerivedFields(t *testing.T) {
+	bus.ClearBusHandlers()
+	t.Cleanup(bus.ClearBusHandlers)
+	bus.AddHandler("test", func(query *models.GetDataSourceQuery) error {
+		if query.Uid != "jaeger" {
+			return models.ErrDataSourceNotFound
+		}
+		query.Result = &models.DataSource{Uid: "jaeger", Name: "Jaeger"}
+		return nil
+	})
+
+	dsInfo := &models.DataSource{OrgId: 1, JsonData: simplejson.NewFromAny(map[string]interface{}{
+		"derivedFields": []interface{}{
+			map[string]interface{}{"name": "traceID", "matcherRegex": "traceID=(\\w+)", "datasourceUid": "jaeger",
+				"url": "${__value.raw}"},
+			map[string]interface{}{"name": "traceID", "matcherRegex": "ignored", "url": "http://tracing/${__value.raw}"},
+			map[string]interface{}{"name": "invalid", "matcherRegex": "("},
+			map[string]interface{}{"name": "missing", "matcherRegex": "x", "datasourceUid": "missing"},
+		},
+	})}
+	query := &lokiQuery{Start: time.Unix(10, 0), End: time.Unix(20, 0)}
+	query.DerivedFields = parseDerivedFields(dsInfo, query)
+
+	require.Len(t, query.DerivedFields, 2)
+	require.Equal(t, []data.DataLink{
+		{Title: "Jaeger", URL: `/explore?orgId=1&left=%5B%2210000%22%2C%2220000%22%2C%22Jaeger%22%2C%7B%22query%22%3A%22${__value.raw}%22%7D%5D`},
+		{URL: "http://tracing/${__value.raw}"},
+	}, query.DerivedFields[0].links)
+	require.Empty(t, query.DerivedFields[1].links)
+
+	value := loghttp.QueryResponse{
+		Data: loghttp.QueryResponseData{
+			Result: loghttp.Streams{{
+				Labels: loghttp.LabelSet{"app": "backend"},
+				Entries: []loghttp.Entry{
+					{Timestamp: time.Unix(12, 0), Line: "msg=done traceID=abc"},
+					{Timestamp: time.Unix(11, 0), Line: "msg=start"},
+				},
+			}},
+		},
+	}
+	res, err := parseResponse(&value, query)
+	require.NoError(t, err)
+
+	decoded, err := res.Dataframes.Decoded()
+	require.NoError(t, err)
+	require.Len(t, decoded[0].Fields, 4)
+
+	traceID := decoded[0].Fields[2]
+	require.Equal(t, "traceID", traceID.Name)
+	require.Equal(t, "abc", *traceID.At(0).(*string))
+	require.Nil(t, traceID.At(1))
+	require.Len(t, traceID.Config.Links, 2)
+}
@@ -38,6 +38,10 @@ func New(httpClientProvider httpclient.Provider) func(dsInfo *models.DataSource)
 	}
 }
 
+// defaultMaxLines is the number of lines log queries return without a limit, the default of the data source in the
+// frontend.
+const defaultMaxLines = 1000
+
 var (
 	plog         = log.New("tsdb.loki")
 	legendFormat = regexp.MustCompile(`\{\{\s*(.+?)\s*\}\}`)
@@ -86,12 +90,10 @@ func (e *LokiExecutor) DataQuery(ctx context.Context, dsInfo *models.DataSource,
 		span.SetTag("stop_unixnano", query.End.UnixNano())
 		defer span.Finish()
 
-		//Currently hard coded as not used - applies to log queries
-		limit := 1000
 		//Currently hard coded as not used - applies to queries which produce a stream response
 		interval := time.Second * 1
 
-		value, err := client.QueryRange(query.Expr, limit, query.Start, query.End, logproto.BACKWARD, query.Step, interval, false)
+		value, err := client.QueryRange(query.Expr, query.MaxLines, query.Start, query.End, logproto.BACKWARD, query.Step, interval, false)
 		if err != nil {
 			return plugins.DataResponse{}, err
 		}
@@ -100,6 +102,16 @@ func (e *LokiExecutor) DataQuery(ctx context.Context, dsInfo *models.DataSource,
 		if err != nil {
 			return plugins.DataResponse{}, err
 		}
+
+		if _, logs := value.Data.Result.(loghttp.Streams); logs && query.LogsVolume {
+			// the logs are returned without their volume when it fails, like in Explore
+			frames, err := logsVolume(client, query)
+			if err != nil {
+				plog.Warn("Failed to query logs volume", "query", query.Expr, "error", err)
+			} else {
+				queryResult.Dataframes = appendFrames(queryResult.Dataframes, frames)
+			}
+		}
 		result.Results[query.RefID] = queryResult
 	}
 
@@ -134,6 +146,10 @@ func (e *LokiExecutor) parseQuery(dsInfo *models.DataSource, queryContext plugin
 		}
 
 		format := queryModel.Model.Get("legendFormat").MustString("")
+		maxLines := queryModel.Model.Get("maxLines").MustInt(defaultMaxLines)
+		if maxLines <= 0 {
+			maxLines = defaultMaxLines
+		}
 
 		start, err := queryContext.TimeRange.ParseFrom()
 		if err != nil {
@@ -153,14 +169,18 @@ func (e *LokiExecutor) parseQuery(dsInfo *models.DataSource, queryContext plugin
 		interval := e.intervalCalculator.Calculate(*queryContext.TimeRange, dsInterval)
 		step := time.Duration(int64(interval.Value))
 
-		qs = append(qs, &lokiQuery{
+		query := &lokiQuery{
 			Expr:         expr,
 			Step:         step,
 			LegendFormat: format,
 			Start:        start,
 			End:          end,
 			RefID:        queryModel.RefID,
-		})
+			MaxLines:     maxLines,
+			LogsVolume:   queryModel.Model.Get("logsVolume").MustBool(false),
+		}
+		query.DerivedFields = parseDerivedFields(dsInfo, query)
+		qs = append(qs, query)
 	}
 
 	return qs, nil
@@ -171,9 +191,15 @@ func parseResponse(value *loghttp.QueryResponse, query *lokiQuery) (plugins.Data
 	var queryRes plugins.DataQueryResult
 	frames := data.Frames{}
 
-	//We are currently processing only matrix results (for alerting)
-	matrix, ok := value.Data.Result.(loghttp.Matrix)
-	if !ok {
+	//We are currently processing only matrix results (for alerting) and streams of log queries
+	var matrix loghttp.Matrix
+	switch result := value.Data.Result.(type) {
+	case loghttp.Matrix:
+		matrix = result
+	case loghttp.Streams:
+		queryRes.Dataframes = plugins.NewDecodedDataFrames(parseStreams(result, query.DerivedFields))
+		return queryRes, nil
+	default:
 		return queryRes, fmt.Errorf("unsupported result format: %q", value.Data.ResultType)
 	}
 
@@ -200,3 +226,73 @@ func parseResponse(value *loghttp.QueryResponse, query *lokiQuery) (plugins.Data
 
 	return queryRes, nil
 }
+
+// parseStreams returns a frame of the time and line of the entries of every stream, with the labels of the stream on
+// the line field and the derived fields, like the frames of log queries in the frontend.
+func parseStreams(streams loghttp.Streams, derivedFields []*derivedField) data.Frames {
+	frames := make(data.Frames, 0, len(streams))
+	for _, stream := range streams {
+		labels := data.Labels(stream.Labels.Map())
+		timeVector := make([]time.Time, 0, len(stream.Entries))
+		lines := make([]string, 0, len(stream.Entries))
+		for _, entry := range stream.Entries {
+			timeVector = append(timeVector, entry.Timestamp.UTC())
+			lines = append(lines, entry.Line)
+		}
+
+		frame := data.NewFrame(labels.String(),
+			data.NewField("ts", nil, timeVector),
+			data.NewField("line", labels, lines))
+		frame.Meta = &data.FrameMeta{PreferredVisualization: data.VisTypeLogs}
+		addDerivedFields(frame, derivedFields)
+		frames = append(frames, frame)
+	}
+	return frames
+}
+
+// logsVolumeExpr returns the metric query of the number of lines of the log query by level, at the step of the query.
+func logsVolumeExpr(query *lokiQuery) string {
+	return fmt.Sprintf("sum by (level) (count_over_time(%s[%s]))", query.Expr, model.Duration(query.Step))
+}
+
+// logsVolume returns the frames of the volume of the logs of the query by level. The frames are named by level, or
+// unknown for lines without one, and are marked with the logsVolume custom meta.
+func logsVolume(client *client.DefaultClient, query *lokiQuery) (data.Frames, error) {
+	volumeQuery := *query
+	volumeQuery.Expr = logsVolumeExpr(query)
+	volumeQuery.LegendFormat = "{{level}}"
+
+	value, err := client.QueryRange(volumeQuery.Expr, query.MaxLines, query.Start, query.End, logproto.BACKWARD,
+		query.Step, time.Second, false)
+	if err != nil {
+		return nil, err
+	}
+
+	queryRes, err := parseResponse(value, &volumeQuery)
+	if err != nil {
+		return nil, err
+	}
+	frames, err := queryRes.Dataframes.Decoded()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, frame := range frames {
+		if frame.Name == "" {
+			frame.Name = "unknown"
+			frame.Fields[1].Config.DisplayNameFromDS = frame.Name
+		}
+		frame.Meta = &data.FrameMeta{Custom: map[string]interface{}{"logsVolume": true}}
+	}
+	return frames, nil
+}
+
+// appendFrames returns the data frames with the frames appended.
+func appendFrames(dataFrames plugins.DataFrames, frames data.Frames) plugins.DataFrames {
+	decoded, err := dataFrames.Decoded()
+	if err != nil {
+		plog.Warn("Failed to decode frames", "error", err)
+		return dataFrames
+	}
+	return plugins.NewDecodedDataFrames(append(decoded, frames...))
+}
@@ -1,9 +1,14 @@
 package loki
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/models"
@@ -156,3 +161,95 @@ func TestParseResponse(t *testing.T) {
 		require.Equal(t, "UTC", testValue.(time.Time).Location().String())
 	})
 }
+
+func TestParseStreams(t *testing.T) {
+	value := loghttp.QueryResponse{
+		Data: loghttp.QueryResponseData{
+			Result: loghttp.Streams{
+				{
+					Labels: loghttp.LabelSet{"app": "backend", "level": "error"},
+					Entries: []loghttp.Entry{
+						{Timestamp: time.Unix(2, 0), Line: "second"},
+						{Timestamp: time.Unix(1, 0), Line: "first"},
+					},
+				},
+			},
+		},
+	}
+
+	res, err := parseResponse(&value, &lokiQuery{})
+	require.NoError(t, err)
+
+	decoded, err := res.Dataframes.Decoded()
+	require.NoError(t, err)
+	require.Len(t, decoded, 1)
+	require.Equal(t, "app=backend, level=error", decoded[0].Name)
+	require.Equal(t, data.VisType(data.VisTypeLogs), decoded[0].Meta.PreferredVisualization)
+	require.Equal(t, "ts", decoded[0].Fields[0].Name)
+	require.Equal(t, time.Unix(2, 0).UTC(), decoded[0].Fields[0].At(0))
+	require.Equal(t, "line", decoded[0].Fields[1].Name)
+	require.Equal(t, "second", decoded[0].Fields[1].At(0))
+	require.Equal(t, data.Labels{"app": "backend", "level": "error"}, decoded[0].Fields[1].Labels)
+}
+
+func TestLogsVolume(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query().Get("query")
+		queries = append(queries, query)
+
+		body := `{"status": "success", "data": {"resultType": "streams", "result": [
+			{"stream": {"level": "info"}, "values": [["1000000000", "line"]]}
+		]}}`
+		if strings.HasPrefix(query, "sum by (level)") {
+			body = `{"status": "success", "data": {"resultType": "matrix", "result": [
+				{"metric": {"level": "info"}, "values": [[1, "1"]]},
+				{"metric": {}, "values": [[1, "2"]]}
+			]}}`
+		}
+		_, err := rw.Write([]byte(body))
+		require.NoError(t, err)
+	}))
+	t.Cleanup(server.Close)
+
+	dsInfo := &models.DataSource{Id: 1, Url: server.URL, JsonData: simplejson.New()}
+	timeRange := plugins.NewDataTimeRange("1h", "now")
+	newQuery := func(logsVolume bool) plugins.DataQuery {
+		return plugins.DataQuery{
+			TimeRange: &timeRange,
+			Queries: []plugins.DataSubQuery{{
+				RefID: "A",
+				Model: simplejson.NewFromAny(map[string]interface{}{"expr": `{app="backend"}`, "logsVolume": logsVolume}),
+			}},
+		}
+	}
+
+	exe, err := New(httpclient.NewProvider())(dsInfo)
+	require.NoError(t, err)
+
+	t.Run("Should return the volume of the logs by level", func(t *testing.T) {
+		queries = nil
+		res, err := exe.DataQuery(context.Background(), dsInfo, newQuery(true))
+		require.NoError(t, err)
+		require.Equal(t, []string{`{app="backend"}`, `sum by (level) (count_over_time({app="backend"}[2s]))`}, queries)
+
+		decoded, err := res.Results["A"].Dataframes.Decoded()
+		require.NoError(t, err)
+		require.Len(t, decoded, 3)
+		require.Equal(t, data.VisType(data.VisTypeLogs), decoded[0].Meta.PreferredVisualization)
+		require.Equal(t, "info", decoded[1].Name)
+		require.Equal(t, "unknown", decoded[2].Name)
+		require.Equal(t, map[string]interface{}{"logsVolume": true}, decoded[2].Meta.Custom)
+	})
+
+	t.Run("Should only return the logs without the logsVolume option", func(t *testing.T) {
+		queries = nil
+		res, err := exe.DataQuery(context.Background(), dsInfo, newQuery(false))
+		require.NoError(t, err)
+		require.Len(t, queries, 1)
+
+		decoded, err := res.Results["A"].Dataframes.Decoded()
+		require.NoError(t, err)
+		require.Len(t, decoded, 1)
+	})
+}
@@ -9,4 +9,9 @@ type lokiQuery struct {
 	Start        time.Time
 	End          time.Time
 	RefID        string
+	MaxLines     int
+	// LogsVolume is whether the volume of the logs of a log query is returned too, for the histogram of Explore.
+	LogsVolume bool
+	// DerivedFields are added to the frames of log queries.
+	DerivedFields []*derivedField
 }
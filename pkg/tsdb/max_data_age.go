@@ -0,0 +1,76 @@
+package tsdb
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/gtime"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+)
+
+// ErrMaxDataAgeExceeded is returned for the queries reaching further back than the max data age of their data source.
+var ErrMaxDataAgeExceeded = errors.New("query exceeds the max data age of the data source")
+
+// applyMaxDataAge enforces the max data age of a data source, the maxDataAge of its JSON data, e.g. "90d", on the
+// time range of a query. The time ranges starting before the max data age fail, or are snapped to the max data age
+// when snapToMaxDataAge is set. The time ranges ending before the max data age always fail.
+//nolint: staticcheck // plugins.DataQuery deprecated
+func applyMaxDataAge(ds *models.DataSource, query plugins.DataQuery) (plugins.DataQuery, error) {
+	if ds.JsonData == nil || query.TimeRange == nil {
+		return query, nil
+	}
+	maxDataAge := ds.JsonData.Get("maxDataAge").MustString()
+	if maxDataAge == "" {
+		return query, nil
+	}
+
+	maxAge, err := gtime.ParseDuration(maxDataAge)
+	if err != nil || maxAge <= 0 {
+		return query, fmt.Errorf("invalid max data age %q of data source %q", maxDataAge, ds.Name)
+	}
+
+	from, err := query.TimeRange.ParseFrom()
+	if err != nil {
+		// the data sources fail on the invalid time ranges
+		return query, nil
+	}
+	to, err := query.TimeRange.ParseTo()
+	if err != nil {
+		return query, nil
+	}
+
+	now := query.TimeRange.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	oldest := now.Add(-maxAge)
+	if !from.Before(oldest) {
+		return query, nil
+	}
+
+	if !to.After(oldest) {
+		return query, fmt.Errorf("%w: the time range ends %s ago, before the max data age %s of data source %q",
+			ErrMaxDataAgeExceeded, formatAge(now.Sub(to)), maxDataAge, ds.Name)
+	}
+	if !ds.JsonData.Get("snapToMaxDataAge").MustBool() {
+		return query, fmt.Errorf("%w: the time range starts %s ago, before the max data age %s of data source %q",
+			ErrMaxDataAgeExceeded, formatAge(now.Sub(from)), maxDataAge, ds.Name)
+	}
+
+	timeRange := *query.TimeRange
+	timeRange.From = strconv.FormatInt(oldest.UnixNano()/int64(time.Millisecond), 10)
+	query.TimeRange = &timeRange
+	return query, nil
+}
+
+// formatAge formats an age in days, or in hours, minutes and seconds when it's less than a day.
+func formatAge(age time.Duration) string {
+	const day = 24 * time.Hour
+	if age >= day {
+		return fmt.Sprintf("%dd", age/day)
+	}
+	return age.Round(time.Second).String()
+}
@@ -0,0 +1,73 @@
+package tsdb
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxDataAge(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	//nolint: staticcheck // plugins.DataPlugin deprecated
+	query := func(t *testing.T, jsonData map[string]interface{}, from, to string) (*plugins.DataTimeRange, error) {
+		t.Helper()
+
+		svc, exe := createService()
+		var timeRange *plugins.DataTimeRange
+		exe.HandleQuery("A", func(query plugins.DataQuery) plugins.DataQueryResult {
+			timeRange = query.TimeRange
+			return plugins.DataQueryResult{}
+		})
+
+		ds := &models.DataSource{Id: 1, Name: "warehouse", Type: "test", JsonData: simplejson.NewFromAny(jsonData)}
+		_, err := svc.HandleRequest(context.Background(), ds, plugins.DataQuery{
+			TimeRange: &plugins.DataTimeRange{From: from, To: to, Now: now},
+			Queries:   []plugins.DataSubQuery{{RefID: "A", DataSource: ds}},
+		})
+		return timeRange, err
+	}
+
+	t.Run("Should run the queries without a max data age", func(t *testing.T) {
+		timeRange, err := query(t, map[string]interface{}{}, "now-5y", "now")
+		require.NoError(t, err)
+		require.Equal(t, "now-5y", timeRange.From)
+	})
+
+	t.Run("Should run the queries within the max data age", func(t *testing.T) {
+		timeRange, err := query(t, map[string]interface{}{"maxDataAge": "30d"}, "now-7d", "now")
+		require.NoError(t, err)
+		require.Equal(t, "now-7d", timeRange.From)
+	})
+
+	t.Run("Should fail the queries starting before the max data age", func(t *testing.T) {
+		timeRange, err := query(t, map[string]interface{}{"maxDataAge": "30d"}, "now-1y", "now")
+		require.True(t, errors.Is(err, ErrMaxDataAgeExceeded))
+		require.Contains(t, err.Error(), `the time range starts 365d ago, before the max data age 30d of data source "warehouse"`)
+		require.Nil(t, timeRange)
+	})
+
+	t.Run("Should snap the queries starting before the max data age", func(t *testing.T) {
+		timeRange, err := query(t, map[string]interface{}{"maxDataAge": "30d", "snapToMaxDataAge": true}, "now-1y", "now")
+		require.NoError(t, err)
+		require.Equal(t, now.AddDate(0, 0, -30), timeRange.MustGetFrom().UTC())
+		require.Equal(t, "now", timeRange.To)
+	})
+
+	t.Run("Should fail the queries ending before the max data age", func(t *testing.T) {
+		_, err := query(t, map[string]interface{}{"maxDataAge": "30d", "snapToMaxDataAge": true}, "now-1y", "now-6M")
+		require.True(t, errors.Is(err, ErrMaxDataAgeExceeded))
+		require.Contains(t, err.Error(), "the time range ends")
+	})
+
+	t.Run("Should fail with an invalid max data age", func(t *testing.T) {
+		_, err := query(t, map[string]interface{}{"maxDataAge": "a month"}, "now-1h", "now")
+		require.EqualError(t, err, `invalid max data age "a month" of data source "warehouse"`)
+	})
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/queryaudit"
 	"github.com/grafana/grafana/pkg/tsdb/sqleng"
 )
 
@@ -109,6 +110,10 @@ func generateConnectionString(dataSource *models.DataSource) (string, error) {
 	if encrypt != "false" {
 		connStr += fmt.Sprintf("encrypt=%s;", encrypt)
 	}
+	// the program name of the sessions of the audited data sources, whose queries are commented with their origin
+	if queryaudit.Enabled(dataSource) {
+		connStr += "app name=Grafana;"
+	}
 	return connStr, nil
 }
 
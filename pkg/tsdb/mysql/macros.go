@@ -17,15 +17,22 @@ const sExpr = `\$` + rsIdentifier + `\(([^\)]*)\)`
 
 var restrictedRegExp = regexp.MustCompile(`(?im)([\s]*show[\s]+grants|[\s,]session_user\([^\)]*\)|[\s,]current_user(\([^\)]*\))?|[\s,]system_user\([^\)]*\)|[\s,]user\([^\)]*\))([\s,;]|$)`)
 
+var storedProcedureCallRegExp = regexp.MustCompile(`(?i)(^|;)\s*call\s`)
+
 type mySQLMacroEngine struct {
 	*sqleng.SQLMacroEngineBase
-	timeRange plugins.DataTimeRange
-	query     plugins.DataSubQuery
-	logger    log.Logger
+	timeRange             plugins.DataTimeRange
+	query                 plugins.DataSubQuery
+	logger                log.Logger
+	allowStoredProcedures bool
 }
 
-func newMysqlMacroEngine(logger log.Logger) sqleng.SQLMacroEngine {
-	return &mySQLMacroEngine{SQLMacroEngineBase: sqleng.NewSQLMacroEngineBase(), logger: logger}
+func newMysqlMacroEngine(logger log.Logger, allowStoredProcedures bool) sqleng.SQLMacroEngine {
+	return &mySQLMacroEngine{
+		SQLMacroEngineBase:    sqleng.NewSQLMacroEngineBase(),
+		logger:                logger,
+		allowStoredProcedures: allowStoredProcedures,
+	}
 }
 
 func (m *mySQLMacroEngine) Interpolate(query plugins.DataSubQuery, timeRange plugins.DataTimeRange, sql string) (string, error) {
@@ -38,6 +45,10 @@ func (m *mySQLMacroEngine) Interpolate(query plugins.DataSubQuery, timeRange plu
 		return "", errors.New("invalid query - inspect Grafana server log for details")
 	}
 
+	if !m.allowStoredProcedures && storedProcedureCallRegExp.MatchString(sql) {
+		return "", errors.New("calling stored procedures is not allowed by the data source settings")
+	}
+
 	// TODO: Handle error
 	rExp, _ := regexp.Compile(sExpr)
 	var macroError error
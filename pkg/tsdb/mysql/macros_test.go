@@ -186,5 +186,35 @@ func TestMacroEngine(t *testing.T) {
 				So(err.Error(), ShouldEqual, "invalid query - inspect Grafana server log for details")
 			}
 		})
+
+		Convey("Given queries that call stored procedures", func() {
+			tcs := []string{
+				"CALL report()",
+				" call report(1, 2)",
+				"SET @a = 1; CALL report(@a)",
+			}
+
+			Convey("Should reject them when stored procedures aren't allowed", func() {
+				for _, tc := range tcs {
+					_, err := engine.Interpolate(plugins.DataSubQuery{}, plugins.DataTimeRange{}, tc)
+					So(err.Error(), ShouldEqual, "calling stored procedures is not allowed by the data source settings")
+				}
+			})
+
+			Convey("Should interpolate them when stored procedures are allowed", func() {
+				engine := newMysqlMacroEngine(log.New("test"), true)
+				for _, tc := range tcs {
+					sql, err := engine.Interpolate(plugins.DataSubQuery{}, plugins.DataTimeRange{}, tc)
+					So(err, ShouldBeNil)
+					So(sql, ShouldEqual, tc)
+				}
+			})
+
+			Convey("Should not reject columns named call", func() {
+				sql, err := engine.Interpolate(plugins.DataSubQuery{}, plugins.DataTimeRange{}, "SELECT `call` FROM calls")
+				So(err, ShouldBeNil)
+				So(sql, ShouldEqual, "SELECT `call` FROM calls")
+			})
+		})
 	})
 }
@@ -32,7 +32,7 @@ func characterEscape(s string, escapeChar string) string {
 	return strings.ReplaceAll(s, escapeChar, url.QueryEscape(escapeChar))
 }
 
-//nolint: staticcheck // plugins.DataPlugin deprecated
+// nolint: staticcheck // plugins.DataPlugin deprecated
 func New(httpClientProvider httpclient.Provider) func(datasource *models.DataSource) (plugins.DataPlugin, error) {
 	//nolint: staticcheck // plugins.DataPlugin deprecated
 	return func(datasource *models.DataSource) (plugins.DataPlugin, error) {
@@ -43,9 +43,8 @@ func New(httpClientProvider httpclient.Provider) func(datasource *models.DataSou
 			protocol = "unix"
 		}
 
-		cnnstr := fmt.Sprintf("%s:%s@%s(%s)/%s?collation=utf8mb4_unicode_ci&parseTime=true&loc=UTC&allowNativePasswords=true",
-			characterEscape(datasource.User, ":"),
-			datasource.DecryptedPassword(),
+		// the options of the connection strings, after the credentials
+		options := fmt.Sprintf("%s(%s)/%s?collation=utf8mb4_unicode_ci&parseTime=true&loc=UTC&allowNativePasswords=true",
 			protocol,
 			characterEscape(datasource.Url, ")"),
 			characterEscape(datasource.Database, "?"),
@@ -56,34 +55,68 @@ func New(httpClientProvider httpclient.Provider) func(datasource *models.DataSou
 			return nil, err
 		}
 
+		iamAuth := sqleng.IAMAuth(datasource)
 		if tlsConfig.RootCAs != nil || len(tlsConfig.Certificates) > 0 {
 			tlsConfigString := fmt.Sprintf("ds%d", datasource.Id)
 			if err := mysql.RegisterTLSConfig(tlsConfigString, tlsConfig); err != nil {
 				return nil, err
 			}
-			cnnstr += "&tls=" + tlsConfigString
+			options += "&tls=" + tlsConfigString
+		} else if iamAuth != "" {
+			options += "&tls=true"
 		}
 
+		// the IAM authentication tokens are sent as cleartext passwords, over TLS
+		if iamAuth != "" {
+			options += "&allowCleartextPasswords=true"
+		}
+
+		// stored procedures are often called after setting their arguments to variables, so multiple statements are
+		// allowed together with them
+		allowStoredProcedures := datasource.JsonData.Get("allowStoredProcedures").MustBool(false)
+		if allowStoredProcedures {
+			options += "&multiStatements=true"
+		}
+
+		connectionString := func(user, password string) string {
+			return fmt.Sprintf("%s:%s@%s", characterEscape(user, ":"), password, options)
+		}
+		cnnstr := connectionString(datasource.User, datasource.DecryptedPassword())
+
 		if setting.Env == setting.Dev {
 			logger.Debug("getEngine", "connection", cnnstr)
 		}
 
 		config := sqleng.DataPluginConfiguration{
-			DriverName:        "mysql",
-			ConnectionString:  cnnstr,
-			Datasource:        datasource,
-			TimeColumnNames:   []string{"time", "time_sec"},
-			MetricColumnTypes: []string{"CHAR", "VARCHAR", "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT"},
+			DriverName:         "mysql",
+			ConnectionString:   cnnstr,
+			Datasource:         datasource,
+			TimeColumnNames:    []string{"time", "time_sec"},
+			MetricColumnTypes:  []string{"CHAR", "VARCHAR", "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT"},
+			MultipleResultSets: allowStoredProcedures,
+			ExplainQuery:       explainQuery,
+			Identity: &sqleng.IdentityConfiguration{
+				DefaultPort:  3306,
+				CloudSQLUser: sqleng.TrimEmailDomain,
+				ConnectionString: func(creds sqleng.IdentityCredentials) (string, error) {
+					return connectionString(creds.User, creds.Password), nil
+				},
+			},
 		}
 
 		rowTransformer := mysqlQueryResultTransformer{
 			log: logger,
 		}
 
-		return sqleng.NewDataPlugin(config, &rowTransformer, newMysqlMacroEngine(logger), logger)
+		return sqleng.NewDataPlugin(config, &rowTransformer, newMysqlMacroEngine(logger, allowStoredProcedures), logger)
 	}
 }
 
+// explainQuery returns the statement explaining the plan of a query.
+func explainQuery(rawSQL string) string {
+	return "EXPLAIN " + rawSQL
+}
+
 type mysqlQueryResultTransformer struct {
 	log log.Logger
 }
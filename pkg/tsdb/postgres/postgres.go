@@ -1,13 +1,19 @@
 package postgres
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"reflect"
 	"strconv"
 	"strings"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin/coreplugin"
 	"github.com/grafana/grafana/pkg/registry"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util/errutil"
@@ -27,42 +33,43 @@ func init() {
 }
 
 type PostgresService struct {
-	Cfg        *setting.Cfg `inject:""`
-	logger     log.Logger
-	tlsManager tlsSettingsProvider
+	Cfg                  *setting.Cfg          `inject:""`
+	BackendPluginManager backendplugin.Manager `inject:""`
+	logger               log.Logger
+	tlsManager           tlsSettingsProvider
+	timescaleDB          timescaleDBCache
 }
 
 func (s *PostgresService) Init() error {
 	s.logger = log.New("tsdb.postgres")
 	s.tlsManager = newTLSManager(s.logger, s.Cfg.DataPath)
+
+	// the queries are handled by the data plugin of NewExecutor, the core plugin only handles the resources
+	resourceMux := http.NewServeMux()
+	s.registerRoutes(resourceMux)
+	factory := coreplugin.New(backend.ServeOpts{
+		CallResourceHandler: httpadapter.New(resourceMux),
+	})
+	if err := s.BackendPluginManager.RegisterAndStart(context.Background(), "postgres", factory); err != nil {
+		s.logger.Error("Failed to register plugin", "error", err)
+	}
 	return nil
 }
 
-//nolint: staticcheck // plugins.DataPlugin deprecated
+// nolint: staticcheck // plugins.DataPlugin deprecated
 func (s *PostgresService) NewExecutor(datasource *models.DataSource) (plugins.DataPlugin, error) {
 	s.logger.Debug("Creating Postgres query endpoint")
 
-	cnnstr, err := s.generateConnectionString(datasource)
+	config, err := s.newDataPluginConfiguration(datasource)
 	if err != nil {
 		return nil, err
 	}
 
-	if s.Cfg.Env == setting.Dev {
-		s.logger.Debug("getEngine", "connection", cnnstr)
-	}
-
-	config := sqleng.DataPluginConfiguration{
-		DriverName:        "postgres",
-		ConnectionString:  cnnstr,
-		Datasource:        datasource,
-		MetricColumnTypes: []string{"UNKNOWN", "TEXT", "VARCHAR", "CHAR"},
-	}
-
 	queryResultTransformer := postgresQueryResultTransformer{
 		log: s.logger,
 	}
 
-	timescaledb := datasource.JsonData.Get("timescaledb").MustBool(false)
+	timescaledb := s.timescaleDBEnabled(config)
 
 	plugin, err := sqleng.NewDataPlugin(config, &queryResultTransformer, newPostgresMacroEngine(timescaledb),
 		s.logger)
@@ -75,12 +82,54 @@ func (s *PostgresService) NewExecutor(datasource *models.DataSource) (plugins.Da
 	return plugin, nil
 }
 
+func (s *PostgresService) newDataPluginConfiguration(datasource *models.DataSource) (sqleng.DataPluginConfiguration, error) {
+	cnnstr, err := s.generateConnectionString(datasource)
+	if err != nil {
+		return sqleng.DataPluginConfiguration{}, err
+	}
+
+	if s.Cfg.Env == setting.Dev {
+		s.logger.Debug("getEngine", "connection", cnnstr)
+	}
+
+	return sqleng.DataPluginConfiguration{
+		DriverName:         "postgres",
+		ConnectionString:   cnnstr,
+		Datasource:         datasource,
+		MetricColumnTypes:  []string{"UNKNOWN", "TEXT", "VARCHAR", "CHAR"},
+		ExplainQuery:       explainQuery,
+		SetApplicationName: setApplicationName,
+		Identity: &sqleng.IdentityConfiguration{
+			DefaultPort: 5432,
+			ConnectionString: func(creds sqleng.IdentityCredentials) (string, error) {
+				return s.generateUserConnectionString(datasource, creds.User, creds.Password)
+			},
+		},
+	}, nil
+}
+
+// explainQuery returns the statement explaining the plan of a query, without running it.
+func explainQuery(rawSQL string) string {
+	return "EXPLAIN " + rawSQL
+}
+
+// setApplicationName returns the statement setting the application name of the transaction of an audited query,
+// shown in pg_stat_activity and the logs.
+func setApplicationName(name string) string {
+	return "SET LOCAL application_name = '" + strings.ReplaceAll(name, "'", "''") + "'"
+}
+
 // escape single quotes and backslashes in Postgres connection string parameters.
 func escape(input string) string {
 	return strings.ReplaceAll(strings.ReplaceAll(input, `\`, `\\`), "'", `\'`)
 }
 
 func (s *PostgresService) generateConnectionString(datasource *models.DataSource) (string, error) {
+	return s.generateUserConnectionString(datasource, datasource.User, datasource.DecryptedPassword())
+}
+
+// generateUserConnectionString generates the connection string of a data source with the credentials of a user.
+func (s *PostgresService) generateUserConnectionString(datasource *models.DataSource, user, password string) (string, error) {
 	var host string
 	var port int
 	if strings.HasPrefix(datasource.Url, "/") {
@@ -103,7 +152,7 @@ func (s *PostgresService) generateConnectionString(datasource *models.DataSource
 	}
 
 	connStr := fmt.Sprintf("user='%s' password='%s' host='%s' dbname='%s'",
-		escape(datasource.User), escape(datasource.DecryptedPassword()), escape(host), escape(datasource.Database))
+		escape(user), escape(password), escape(host), escape(datasource.Database))
 	if port > 0 {
 		connStr += fmt.Sprintf(" port=%d", port)
 	}
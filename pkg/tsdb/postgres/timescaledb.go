@@ -0,0 +1,299 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
+	"xorm.io/xorm"
+)
+
+const timescaleDBVersionQuery = `SELECT extversion FROM pg_extension WHERE extname = 'timescaledb'`
+
+const hypertablesQuery = `SELECT h.hypertable_schema, h.hypertable_name, COALESCE(d.column_name, ''), h.num_chunks,
+	h.compression_enabled
+FROM timescaledb_information.hypertables h
+LEFT JOIN timescaledb_information.dimensions d ON d.hypertable_schema = h.hypertable_schema
+	AND d.hypertable_name = h.hypertable_name AND d.dimension_number = 1
+ORDER BY h.hypertable_schema, h.hypertable_name`
+
+const continuousAggregatesQuery = `SELECT view_schema, view_name, hypertable_schema, hypertable_name, materialized_only
+FROM timescaledb_information.continuous_aggregates
+ORDER BY view_schema, view_name`
+
+var errTimescaleDBNotInstalled = errors.New("the TimescaleDB extension is not installed in the database")
+var errTimescaleDBUnsupported = errors.New("TimescaleDB 2.0 or newer is required")
+
+// timescaleDBCache caches whether the TimescaleDB extension is installed in the databases of data sources whose
+// settings don't enable or disable it, by data source version.
+type timescaleDBCache struct {
+	sync.Mutex
+	versions map[int64]int
+	enabled  map[int64]bool
+}
+
+type timescaleDBInfo struct {
+	Installed bool   `json:"installed"`
+	Version   string `json:"version,omitempty"`
+	// Enabled is whether the time_bucket function of TimescaleDB is used by the macros of the data source
+	Enabled bool `json:"enabled"`
+}
+
+type hypertable struct {
+	Schema             string `json:"schema"`
+	Name               string `json:"name"`
+	TimeColumn         string `json:"timeColumn"`
+	NumChunks          int64  `json:"numChunks"`
+	CompressionEnabled bool   `json:"compressionEnabled"`
+}
+
+type continuousAggregate struct {
+	Schema           string `json:"schema"`
+	Name             string `json:"name"`
+	HypertableSchema string `json:"hypertableSchema"`
+	HypertableName   string `json:"hypertableName"`
+	MaterializedOnly bool   `json:"materializedOnly"`
+}
+
+// timescaleDBEnabled returns whether the macros of the data source use TimescaleDB, as set by the timescaledb
+// property of its settings or, when it's not set, whether the extension is installed in its database.
+func (s *PostgresService) timescaleDBEnabled(config sqleng.DataPluginConfiguration) bool {
+	ds := config.Datasource
+	if value, ok := ds.JsonData.CheckGet("timescaledb"); ok {
+		return value.MustBool(false)
+	}
+
+	s.timescaleDB.Lock()
+	defer s.timescaleDB.Unlock()
+
+	if version, ok := s.timescaleDB.versions[ds.Id]; ok && version == ds.Version {
+		return s.timescaleDB.enabled[ds.Id]
+	}
+
+	engine, err := sqleng.GetEngine(config)
+	if err != nil {
+		s.logger.Debug("Failed to detect TimescaleDB", "datasource", ds.Name, "error", err)
+		return false
+	}
+
+	version, err := timescaleDBVersion(context.Background(), engine)
+	if err != nil && !errors.Is(err, errTimescaleDBNotInstalled) {
+		// not cached, so detecting it is retried, e.g. once the database is reachable
+		s.logger.Debug("Failed to detect TimescaleDB", "datasource", ds.Name, "error", err)
+		return false
+	}
+
+	enabled := version != ""
+	if s.timescaleDB.versions == nil {
+		s.timescaleDB.versions = map[int64]int{}
+		s.timescaleDB.enabled = map[int64]bool{}
+	}
+	s.timescaleDB.versions[ds.Id] = ds.Version
+	s.timescaleDB.enabled[ds.Id] = enabled
+	if enabled {
+		s.logger.Debug("Detected TimescaleDB", "datasource", ds.Name, "version", version)
+	}
+
+	return enabled
+}
+
+// timescaleDBVersion returns the version of the TimescaleDB extension installed in the database,
+// errTimescaleDBNotInstalled if it's not installed.
+func timescaleDBVersion(ctx context.Context, engine *xorm.Engine) (string, error) {
+	var version string
+	if err := engine.DB().QueryRowContext(ctx, timescaleDBVersionQuery).Scan(&version); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", errTimescaleDBNotInstalled
+		}
+		return "", err
+	}
+
+	return version, nil
+}
+
+func (s *PostgresService) registerRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/timescaledb", s.handleTimescaleDB)
+	mux.HandleFunc("/timescaledb/hypertables", s.handleHypertables)
+	mux.HandleFunc("/timescaledb/continuous-aggregates", s.handleContinuousAggregates)
+}
+
+// handleTimescaleDB returns whether TimescaleDB is installed in the database of the data source.
+func (s *PostgresService) handleTimescaleDB(rw http.ResponseWriter, req *http.Request) {
+	config, engine, ok := s.resourceEngine(rw, req)
+	if !ok {
+		return
+	}
+
+	version, err := timescaleDBVersion(req.Context(), engine)
+	if err != nil && !errors.Is(err, errTimescaleDBNotInstalled) {
+		s.writeError(rw, http.StatusInternalServerError, "Failed to detect TimescaleDB", err)
+		return
+	}
+
+	s.writeJSON(rw, http.StatusOK, timescaleDBInfo{
+		Installed: version != "",
+		Version:   version,
+		Enabled:   s.timescaleDBEnabled(config),
+	})
+}
+
+// handleHypertables returns the hypertables of the database of the data source.
+func (s *PostgresService) handleHypertables(rw http.ResponseWriter, req *http.Request) {
+	_, engine, ok := s.resourceEngine(rw, req)
+	if !ok || !s.checkTimescaleDB(rw, req, engine) {
+		return
+	}
+
+	rows, err := engine.DB().QueryContext(req.Context(), hypertablesQuery)
+	if err != nil {
+		s.writeError(rw, http.StatusInternalServerError, "Failed to list hypertables", err)
+		return
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", "error", err)
+		}
+	}()
+
+	result := []hypertable{}
+	for rows.Next() {
+		var h hypertable
+		if err := rows.Scan(&h.Schema, &h.Name, &h.TimeColumn, &h.NumChunks, &h.CompressionEnabled); err != nil {
+			s.writeError(rw, http.StatusInternalServerError, "Failed to list hypertables", err)
+			return
+		}
+		result = append(result, h)
+	}
+	if err := rows.Err(); err != nil {
+		s.writeError(rw, http.StatusInternalServerError, "Failed to list hypertables", err)
+		return
+	}
+
+	s.writeJSON(rw, http.StatusOK, result)
+}
+
+// handleContinuousAggregates returns the continuous aggregates of the database of the data source.
+func (s *PostgresService) handleContinuousAggregates(rw http.ResponseWriter, req *http.Request) {
+	_, engine, ok := s.resourceEngine(rw, req)
+	if !ok || !s.checkTimescaleDB(rw, req, engine) {
+		return
+	}
+
+	rows, err := engine.DB().QueryContext(req.Context(), continuousAggregatesQuery)
+	if err != nil {
+		s.writeError(rw, http.StatusInternalServerError, "Failed to list continuous aggregates", err)
+		return
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", "error", err)
+		}
+	}()
+
+	result := []continuousAggregate{}
+	for rows.Next() {
+		var a continuousAggregate
+		if err := rows.Scan(&a.Schema, &a.Name, &a.HypertableSchema, &a.HypertableName, &a.MaterializedOnly); err != nil {
+			s.writeError(rw, http.StatusInternalServerError, "Failed to list continuous aggregates", err)
+			return
+		}
+		result = append(result, a)
+	}
+	if err := rows.Err(); err != nil {
+		s.writeError(rw, http.StatusInternalServerError, "Failed to list continuous aggregates", err)
+		return
+	}
+
+	s.writeJSON(rw, http.StatusOK, result)
+}
+
+// resourceEngine returns the engine of the data source of a resource call, writing the error response when it
+// can't be created.
+func (s *PostgresService) resourceEngine(rw http.ResponseWriter, req *http.Request) (
+	sqleng.DataPluginConfiguration, *xorm.Engine, bool) {
+	if req.Method != http.MethodGet {
+		rw.WriteHeader(http.StatusMethodNotAllowed)
+		return sqleng.DataPluginConfiguration{}, nil, false
+	}
+
+	pluginConfig := httpadapter.PluginConfigFromContext(req.Context())
+	if pluginConfig.DataSourceInstanceSettings == nil {
+		s.writeError(rw, http.StatusBadRequest, "Missing data source", nil)
+		return sqleng.DataPluginConfiguration{}, nil, false
+	}
+
+	// the data source is loaded since the settings of the plugin context lack the legacy password
+	query := &models.GetDataSourceQuery{Id: pluginConfig.DataSourceInstanceSettings.ID, OrgId: pluginConfig.OrgID}
+	if err := bus.Dispatch(query); err != nil {
+		if errors.Is(err, models.ErrDataSourceNotFound) {
+			s.writeError(rw, http.StatusNotFound, "Data source not found", nil)
+			return sqleng.DataPluginConfiguration{}, nil, false
+		}
+		s.writeError(rw, http.StatusInternalServerError, "Failed to get data source", err)
+		return sqleng.DataPluginConfiguration{}, nil, false
+	}
+
+	config, err := s.newDataPluginConfiguration(query.Result)
+	if err != nil {
+		s.writeError(rw, http.StatusBadRequest, "Invalid data source settings", err)
+		return sqleng.DataPluginConfiguration{}, nil, false
+	}
+
+	engine, err := sqleng.GetEngine(config)
+	if err != nil {
+		s.writeError(rw, http.StatusInternalServerError, "Failed to connect to the database", err)
+		return sqleng.DataPluginConfiguration{}, nil, false
+	}
+
+	return config, engine, true
+}
+
+// checkTimescaleDB writes the error response when a supported version of TimescaleDB isn't installed in the
+// database.
+func (s *PostgresService) checkTimescaleDB(rw http.ResponseWriter, req *http.Request, engine *xorm.Engine) bool {
+	version, err := timescaleDBVersion(req.Context(), engine)
+	switch {
+	case errors.Is(err, errTimescaleDBNotInstalled):
+		s.writeError(rw, http.StatusBadRequest, errTimescaleDBNotInstalled.Error(), nil)
+		return false
+	case err != nil:
+		s.writeError(rw, http.StatusInternalServerError, "Failed to detect TimescaleDB", err)
+		return false
+	case strings.HasPrefix(version, "0.") || strings.HasPrefix(version, "1."):
+		// the information views of older versions have different columns
+		s.writeError(rw, http.StatusBadRequest, errTimescaleDBUnsupported.Error(), nil)
+		return false
+	}
+
+	return true
+}
+
+func (s *PostgresService) writeError(rw http.ResponseWriter, status int, message string, err error) {
+	if err != nil {
+		s.logger.Error(message, "error", err)
+	}
+	s.writeJSON(rw, status, map[string]string{"message": message})
+}
+
+func (s *PostgresService) writeJSON(rw http.ResponseWriter, status int, body interface{}) {
+	bytes, err := json.Marshal(body)
+	if err != nil {
+		s.logger.Error("Failed to marshal response body to JSON", "error", err)
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	if _, err := rw.Write(bytes); err != nil {
+		s.logger.Error("Failed to write response", "error", err)
+	}
+}
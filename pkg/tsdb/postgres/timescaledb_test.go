@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+	"xorm.io/xorm"
+)
+
+func TestTimescaleDB(t *testing.T) {
+	// the engines of the data sources are an SQLite database with the pg_extension table of Postgres
+	db, err := xorm.NewEngine("sqlite3", "file:timescaledb?mode=memory&cache=shared")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, db.Close())
+	})
+	_, err = db.Exec("CREATE TABLE pg_extension (extname TEXT, extversion TEXT)")
+	require.NoError(t, err)
+
+	origXormEngine := sqleng.NewXormEngine
+	t.Cleanup(func() {
+		sqleng.NewXormEngine = origXormEngine
+	})
+	sqleng.NewXormEngine = func(string, string) (*xorm.Engine, error) {
+		return xorm.NewEngine("sqlite3", "file:timescaledb?mode=memory&cache=shared")
+	}
+
+	s := &PostgresService{Cfg: setting.NewCfg(), logger: log.New("test")}
+	s.tlsManager = newTLSManager(s.logger, t.TempDir())
+
+	newDatasource := func(id int64, jsonData map[string]interface{}) *models.DataSource {
+		jsonData["sslmode"] = "disable"
+		return &models.DataSource{Id: id, OrgId: 1, Name: "Postgres", Type: "postgres", Url: "localhost",
+			JsonData: simplejson.NewFromAny(jsonData)}
+	}
+	enabled := func(t *testing.T, ds *models.DataSource) bool {
+		config, err := s.newDataPluginConfiguration(ds)
+		require.NoError(t, err)
+		return s.timescaleDBEnabled(config)
+	}
+
+	t.Run("Should not detect TimescaleDB when it's not installed", func(t *testing.T) {
+		require.False(t, enabled(t, newDatasource(8001, map[string]interface{}{})))
+	})
+
+	_, err = db.Exec("INSERT INTO pg_extension VALUES ('timescaledb', '2.3.0')")
+	require.NoError(t, err)
+
+	t.Run("Should detect TimescaleDB when it's installed", func(t *testing.T) {
+		require.True(t, enabled(t, newDatasource(8002, map[string]interface{}{})))
+	})
+
+	t.Run("Should use the settings of the data source when they set it", func(t *testing.T) {
+		require.False(t, enabled(t, newDatasource(8003, map[string]interface{}{"timescaledb": false})))
+	})
+
+	t.Run("Should cache the detection by data source version", func(t *testing.T) {
+		ds := newDatasource(8001, map[string]interface{}{})
+		require.False(t, enabled(t, ds))
+
+		ds.Version++
+		require.True(t, enabled(t, ds))
+	})
+
+	t.Run("Should return the TimescaleDB information of the data source", func(t *testing.T) {
+		bus.ClearBusHandlers()
+		t.Cleanup(bus.ClearBusHandlers)
+		bus.AddHandler("test", func(query *models.GetDataSourceQuery) error {
+			query.Result = newDatasource(query.Id, map[string]interface{}{})
+			return nil
+		})
+
+		mux := http.NewServeMux()
+		s.registerRoutes(mux)
+
+		var resp *backend.CallResourceResponse
+		err := httpadapter.New(mux).CallResource(context.Background(), &backend.CallResourceRequest{
+			PluginContext: backend.PluginContext{
+				OrgID:                      1,
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{ID: 8004},
+			},
+			Path:   "timescaledb",
+			Method: http.MethodGet,
+			URL:    "/timescaledb",
+		}, resourceResponseSender(func(res *backend.CallResourceResponse) error {
+			resp = res
+			return nil
+		}))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.Status)
+		require.JSONEq(t, `{"installed": true, "version": "2.3.0", "enabled": true}`, string(resp.Body))
+	})
+}
+
+type resourceResponseSender func(*backend.CallResourceResponse) error
+
+func (fn resourceResponseSender) Send(res *backend.CallResourceResponse) error {
+	return fn(res)
+}
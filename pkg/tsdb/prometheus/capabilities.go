@@ -0,0 +1,302 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// Applications of the Prometheus query API the capabilities are detected for.
+const (
+	ApplicationPrometheus = "prometheus"
+	ApplicationMimir      = "mimir"
+	ApplicationCortex     = "cortex"
+	ApplicationThanos     = "thanos"
+)
+
+// capabilitiesTTL is how long the capabilities of a data source are cached, so that upgrades of the server are
+// eventually detected without probing it on every request.
+const capabilitiesTTL = 10 * time.Minute
+
+// Capabilities are the APIs supported by the server of a Prometheus data source. Features depending on them are
+// enabled conditionally instead of failing at query time.
+type Capabilities struct {
+	// Application is the implementation of the Prometheus query API, prometheus when it can't be told apart.
+	Application string `json:"application"`
+	// Version is the version of the build info API, empty for servers without it.
+	Version string `json:"version,omitempty"`
+	// BuildInfo is whether the server has the build info API, added in Prometheus 2.14.
+	BuildInfo bool `json:"buildInfo"`
+	// Labels is whether the server has the label names API, added in Prometheus 2.6.
+	Labels bool `json:"labels"`
+	// Exemplars is whether the server has the exemplars query API, added in Prometheus 2.26.
+	Exemplars bool `json:"exemplars"`
+	// Rules is whether the alerting and recording rules of the server can be listed.
+	Rules bool `json:"rules"`
+	// Ruler is whether the rule groups can be managed through the ruler config API of Mimir and Cortex.
+	Ruler bool `json:"ruler"`
+	// RulerConfigPath is the path of the ruler config API, relative to the URL of the data source, when Ruler is true.
+	RulerConfigPath string `json:"rulerConfigPath,omitempty"`
+}
+
+// Paths of the ruler config API, relative to the URL of the data source like the other APIs. Cortex serves it under
+// its legacy prefix, Mimir under its Prometheus prefix.
+const (
+	cortexRulerConfigPath = "/rules"
+	mimirRulerConfigPath  = "/config/v1/rules"
+)
+
+type cachedCapabilities struct {
+	version      int
+	expires      time.Time
+	capabilities Capabilities
+}
+
+type capabilitiesCache struct {
+	sync.Mutex
+	byDatasource map[int64]cachedCapabilities
+}
+
+type buildInfoResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		Application string            `json:"application"`
+		Version     string            `json:"version"`
+		Features    map[string]string `json:"features"`
+	} `json:"data"`
+}
+
+type apiResponse struct {
+	Status string `json:"status"`
+}
+
+// Capabilities returns the capabilities of the server of the data source, detecting them unless they're cached.
+// Failures to reach the server aren't cached, so detecting them is retried.
+func (s *PrometheusService) Capabilities(ctx context.Context, ds *models.DataSource) (Capabilities, error) {
+	s.capabilities.Lock()
+	cached, ok := s.capabilities.byDatasource[ds.Id]
+	s.capabilities.Unlock()
+	if ok && cached.version == ds.Version && time.Now().Before(cached.expires) {
+		return cached.capabilities, nil
+	}
+
+	client, err := s.httpClient(ds)
+	if err != nil {
+		return Capabilities{}, err
+	}
+
+	capabilities, err := detectCapabilities(ctx, client, ds.Url)
+	if err != nil {
+		return Capabilities{}, err
+	}
+
+	s.capabilities.Lock()
+	if s.capabilities.byDatasource == nil {
+		s.capabilities.byDatasource = map[int64]cachedCapabilities{}
+	}
+	s.capabilities.byDatasource[ds.Id] = cachedCapabilities{
+		version:      ds.Version,
+		expires:      time.Now().Add(capabilitiesTTL),
+		capabilities: capabilities,
+	}
+	s.capabilities.Unlock()
+
+	s.logger.Debug("Detected Prometheus capabilities", "datasource", ds.Name, "application",
+		capabilities.Application, "version", capabilities.Version)
+	return capabilities, nil
+}
+
+func (s *PrometheusService) httpClient(ds *models.DataSource) (*http.Client, error) {
+	client, err := ds.GetHTTPClient(s.HTTPClientProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	// the basic auth of the data source is set by its transport
+	client.Transport = &prometheusTransport{
+		Transport:             client.Transport,
+		customQueryParameters: ds.JsonData.Get("customQueryParameters").MustString(""),
+	}
+	return client, nil
+}
+
+func detectCapabilities(ctx context.Context, client *http.Client, baseURL string) (Capabilities, error) {
+	p := prober{ctx: ctx, client: client, baseURL: strings.TrimSuffix(baseURL, "/")}
+	capabilities := Capabilities{Application: ApplicationPrometheus}
+
+	// the build info is probed first, so that servers which can't be reached fail the detection
+	var buildInfo buildInfoResponse
+	ok, err := p.get("/api/v1/status/buildinfo", nil, &buildInfo)
+	if err != nil {
+		return Capabilities{}, err
+	}
+	if ok && buildInfo.Status == "success" {
+		capabilities.BuildInfo = true
+		capabilities.Version = buildInfo.Data.Version
+		if strings.Contains(strings.ToLower(buildInfo.Data.Application), ApplicationMimir) {
+			capabilities.Application = ApplicationMimir
+		}
+	}
+
+	end := time.Now()
+	start := end.Add(-time.Hour)
+	timeRange := url.Values{
+		"start": []string{formatTime(start)},
+		"end":   []string{formatTime(end)},
+	}
+
+	capabilities.Labels = p.success("/api/v1/labels", timeRange)
+	capabilities.Exemplars = p.success("/api/v1/query_exemplars",
+		url.Values{"query": []string{"up"}, "start": timeRange["start"], "end": timeRange["end"]})
+	capabilities.Rules = p.success("/api/v1/rules", nil)
+
+	// the ruler config API answers with the rule groups in YAML, rather than the JSON of the Prometheus API
+	rulerConfigPath := cortexRulerConfigPath
+	if capabilities.Application == ApplicationMimir {
+		rulerConfigPath = mimirRulerConfigPath
+	}
+	if buildInfo.Data.Features["ruler_config_api"] == "true" {
+		capabilities.Ruler = true
+	} else if ok, _ := p.get(rulerConfigPath, nil, nil); ok {
+		capabilities.Ruler = true
+		if capabilities.Application == ApplicationPrometheus {
+			capabilities.Application = ApplicationCortex
+		}
+	}
+	if capabilities.Ruler {
+		capabilities.RulerConfigPath = rulerConfigPath
+	}
+
+	// the stores API is specific to the Thanos querier
+	if capabilities.Application == ApplicationPrometheus && p.success("/api/v1/stores", nil) {
+		capabilities.Application = ApplicationThanos
+	}
+
+	return capabilities, nil
+}
+
+func formatTime(t time.Time) string {
+	return fmt.Sprintf("%d", t.Unix())
+}
+
+// prober requests the APIs of a Prometheus server.
+type prober struct {
+	ctx     context.Context
+	client  *http.Client
+	baseURL string
+}
+
+// get requests the path, decoding the JSON response body into result unless it's nil. It returns whether the
+// server answered successfully, and an error only when it couldn't be reached.
+func (p prober) get(path string, params url.Values, result interface{}) (bool, error) {
+	u := p.baseURL + path
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(p.ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return false, err
+	}
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to reach Prometheus: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			plog.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return false, fmt.Errorf("failed to read Prometheus response: %w", err)
+	}
+	if res.StatusCode/100 != 2 {
+		return false, nil
+	}
+	if result == nil {
+		return true, nil
+	}
+
+	// servers implementing only parts of the API may answer other paths with e.g. an HTML page
+	return json.Unmarshal(body, result) == nil, nil
+}
+
+// success returns whether the API of the path answers with a successful Prometheus API response.
+func (p prober) success(path string, params url.Values) bool {
+	var response apiResponse
+	ok, err := p.get(path, params, &response)
+	return err == nil && ok && response.Status == "success"
+}
+
+func (s *PrometheusService) registerRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/capabilities", s.handleCapabilities)
+}
+
+// handleCapabilities returns the capabilities of the server of the data source.
+func (s *PrometheusService) handleCapabilities(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		rw.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	pluginConfig := httpadapter.PluginConfigFromContext(req.Context())
+	if pluginConfig.DataSourceInstanceSettings == nil {
+		s.writeError(rw, http.StatusBadRequest, "Missing data source", nil)
+		return
+	}
+
+	// the data source is loaded since the settings of the plugin context lack the legacy password
+	query := &models.GetDataSourceQuery{Id: pluginConfig.DataSourceInstanceSettings.ID, OrgId: pluginConfig.OrgID}
+	if err := bus.Dispatch(query); err != nil {
+		if errors.Is(err, models.ErrDataSourceNotFound) {
+			s.writeError(rw, http.StatusNotFound, "Data source not found", nil)
+			return
+		}
+		s.writeError(rw, http.StatusInternalServerError, "Failed to get data source", err)
+		return
+	}
+
+	capabilities, err := s.Capabilities(req.Context(), query.Result)
+	if err != nil {
+		s.writeError(rw, http.StatusBadGateway, "Failed to detect Prometheus capabilities", err)
+		return
+	}
+
+	s.writeJSON(rw, http.StatusOK, capabilities)
+}
+
+func (s *PrometheusService) writeError(rw http.ResponseWriter, status int, message string, err error) {
+	if err != nil {
+		s.logger.Error(message, "error", err)
+	}
+	s.writeJSON(rw, status, map[string]string{"message": message})
+}
+
+func (s *PrometheusService) writeJSON(rw http.ResponseWriter, status int, body interface{}) {
+	bytes, err := json.Marshal(body)
+	if err != nil {
+		s.logger.Error("Failed to marshal response body to JSON", "error", err)
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	if _, err := rw.Write(bytes); err != nil {
+		s.logger.Error("Failed to write response", "error", err)
+	}
+}
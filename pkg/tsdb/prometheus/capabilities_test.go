@@ -0,0 +1,130 @@
+package prometheus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapabilities(t *testing.T) {
+	newServer := func(t *testing.T, routes map[string]string) (*httptest.Server, *int) {
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			requests++
+			body, ok := routes[req.URL.Path]
+			if !ok {
+				rw.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, err := rw.Write([]byte(body))
+			require.NoError(t, err)
+		}))
+		t.Cleanup(server.Close)
+		return server, &requests
+	}
+	newService := func() *PrometheusService {
+		return &PrometheusService{HTTPClientProvider: httpclient.NewProvider(), logger: log.New("test")}
+	}
+	newDataSource := func(id int64, url string) *models.DataSource {
+		return &models.DataSource{Id: id, Url: url, JsonData: simplejson.New()}
+	}
+	success := `{"status": "success", "data": []}`
+
+	t.Run("Should detect the APIs of Prometheus", func(t *testing.T) {
+		server, _ := newServer(t, map[string]string{
+			"/api/v1/status/buildinfo": `{"status": "success", "data": {"version": "2.26.0"}}`,
+			"/api/v1/labels":           success,
+			"/api/v1/query_exemplars":  success,
+			"/api/v1/rules":            success,
+		})
+
+		capabilities, err := newService().Capabilities(context.Background(), newDataSource(1, server.URL))
+		require.NoError(t, err)
+		require.Equal(t, Capabilities{
+			Application: ApplicationPrometheus,
+			Version:     "2.26.0",
+			BuildInfo:   true,
+			Labels:      true,
+			Exemplars:   true,
+			Rules:       true,
+		}, capabilities)
+	})
+
+	t.Run("Should detect the ruler of Mimir from its features", func(t *testing.T) {
+		server, _ := newServer(t, map[string]string{
+			"/api/v1/status/buildinfo": `{"status": "success", "data": {
+				"application": "Grafana Mimir", "version": "2.0.0", "features": {"ruler_config_api": "true"}
+			}}`,
+			"/api/v1/rules": success,
+		})
+
+		capabilities, err := newService().Capabilities(context.Background(), newDataSource(1, server.URL))
+		require.NoError(t, err)
+		require.Equal(t, ApplicationMimir, capabilities.Application)
+		require.True(t, capabilities.Ruler)
+		require.Equal(t, mimirRulerConfigPath, capabilities.RulerConfigPath)
+		require.False(t, capabilities.Labels)
+	})
+
+	t.Run("Should detect Cortex from its ruler config API", func(t *testing.T) {
+		server, _ := newServer(t, map[string]string{
+			"/api/v1/labels":      success,
+			cortexRulerConfigPath: "namespace:\n  - name: group\n",
+		})
+
+		capabilities, err := newService().Capabilities(context.Background(), newDataSource(1, server.URL))
+		require.NoError(t, err)
+		require.Equal(t, Capabilities{
+			Application:     ApplicationCortex,
+			Labels:          true,
+			Ruler:           true,
+			RulerConfigPath: cortexRulerConfigPath,
+		}, capabilities)
+	})
+
+	t.Run("Should detect Thanos from its stores API", func(t *testing.T) {
+		server, _ := newServer(t, map[string]string{
+			"/api/v1/stores": `{"status": "success", "data": {}}`,
+		})
+
+		capabilities, err := newService().Capabilities(context.Background(), newDataSource(1, server.URL))
+		require.NoError(t, err)
+		require.Equal(t, ApplicationThanos, capabilities.Application)
+	})
+
+	t.Run("Should cache the capabilities until the data source is updated", func(t *testing.T) {
+		server, requests := newServer(t, map[string]string{"/api/v1/labels": success})
+		s := newService()
+		ds := newDataSource(1, server.URL)
+
+		_, err := s.Capabilities(context.Background(), ds)
+		require.NoError(t, err)
+		probes := *requests
+
+		_, err = s.Capabilities(context.Background(), ds)
+		require.NoError(t, err)
+		require.Equal(t, probes, *requests)
+
+		ds.Version++
+		_, err = s.Capabilities(context.Background(), ds)
+		require.NoError(t, err)
+		require.Equal(t, 2*probes, *requests)
+	})
+
+	t.Run("Should fail when the server can't be reached", func(t *testing.T) {
+		server, _ := newServer(t, nil)
+		server.Close()
+		s := newService()
+
+		_, err := s.Capabilities(context.Background(), newDataSource(2, server.URL))
+		require.Error(t, err)
+		require.Empty(t, s.capabilities.byDatasource)
+	})
+}
@@ -0,0 +1,45 @@
+package prometheus
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/tsdb/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint: staticcheck // plugins.DataQuery deprecated
+func TestContract(t *testing.T) {
+	testutil.RunContractTests(t, filepath.Join("testdata", "contract"), func(t *testing.T, f testutil.ContractFixture) *backend.DataResponse {
+		dsInfo := &models.DataSource{Url: f.URL, JsonData: simplejson.New()}
+		plug, err := New(httpclient.NewProvider())(dsInfo)
+		require.NoError(t, err)
+
+		model, err := simplejson.NewJson(f.Query.JSON)
+		require.NoError(t, err)
+		query := plugins.DataQuery{
+			TimeRange: &plugins.DataTimeRange{
+				From: strconv.FormatInt(f.Query.TimeRange.From.UnixNano()/int64(time.Millisecond), 10),
+				To:   strconv.FormatInt(f.Query.TimeRange.To.UnixNano()/int64(time.Millisecond), 10),
+			},
+			Queries: []plugins.DataSubQuery{{RefID: f.Query.RefID, Model: model}},
+		}
+
+		resp, err := plug.DataQuery(context.Background(), dsInfo, query)
+		if err != nil {
+			return &backend.DataResponse{Error: err}
+		}
+		qdr, err := resp.ToBackendDataResponse()
+		require.NoError(t, err)
+		dr := qdr.Responses[f.Query.RefID]
+		return &dr
+	})
+}
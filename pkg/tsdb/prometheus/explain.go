@@ -0,0 +1,65 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+)
+
+// ExplainDataQuery explains the range queries of the queries, the requests to the Prometheus API with the step
+// calculated for the time range.
+// nolint: staticcheck // plugins.DataQuery deprecated
+func (e *PrometheusExecutor) ExplainDataQuery(ctx context.Context, dsInfo *models.DataSource,
+	tsdbQuery plugins.DataQuery) ([]plugins.DataQueryExplanation, error) {
+	queries, err := e.parseQuery(dsInfo, tsdbQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	// the custom query parameters are added by prometheusTransport
+	rawURL := strings.TrimSuffix(dsInfo.Url, "/") + "/api/v1/query_range"
+	if params := dsInfo.JsonData.Get("customQueryParameters").MustString(""); params != "" {
+		rawURL += "?" + customQueryParams(params).Encode()
+	}
+
+	explanations := make([]plugins.DataQueryExplanation, 0, len(queries))
+	for _, query := range queries {
+		form := url.Values{}
+		form.Set("query", query.Expr)
+		form.Set("start", formatRangeTime(query.Start))
+		form.Set("end", formatRangeTime(query.End))
+		form.Set("step", strconv.FormatFloat(query.Step.Seconds(), 'f', -1, 64))
+
+		// the client falls back to GET when the server doesn't allow POST
+		req, err := plugins.ExplainHTTPRequest(dsInfo, http.MethodPost, rawURL,
+			map[string]string{"Content-Type": "application/x-www-form-urlencoded"}, form.Encode())
+		if err != nil {
+			return nil, err
+		}
+
+		frames := "a time series frame per series of the matrix, named by its labels"
+		if query.LegendFormat != "" {
+			frames = fmt.Sprintf("a time series frame per series of the matrix, named by the legend format %q",
+				query.LegendFormat)
+		}
+		explanations = append(explanations, plugins.DataQueryExplanation{
+			RefID:     query.RefId,
+			Statement: query.Expr,
+			Request:   req,
+			Frames:    frames,
+		})
+	}
+	return explanations, nil
+}
+
+// formatRangeTime formats a time like the Prometheus client, in seconds since the epoch.
+func formatRangeTime(t time.Time) string {
+	return strconv.FormatFloat(float64(t.Unix())+float64(t.Nanosecond())/1e9, 'f', -1, 64)
+}
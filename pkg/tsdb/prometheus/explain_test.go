@@ -0,0 +1,57 @@
+package prometheus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+)
+
+func TestExplainDataQuery(t *testing.T) {
+	dsInfo := &models.DataSource{
+		Url:           "http://prometheus:9090/",
+		BasicAuth:     true,
+		BasicAuthUser: "admin",
+		JsonData: simplejson.NewFromAny(map[string]interface{}{
+			"customQueryParameters": "api_key=secret&dedup=true",
+		}),
+	}
+	plug, err := New(httpclient.NewProvider())(dsInfo)
+	require.NoError(t, err)
+	executor := plug.(*PrometheusExecutor)
+
+	timeRange := plugins.NewDataTimeRange("1600000000000", "1600003600000")
+	query := plugins.DataQuery{
+		TimeRange: &timeRange,
+		Queries: []plugins.DataSubQuery{
+			{RefID: "A", Model: simplejson.NewFromAny(map[string]interface{}{
+				"expr": "rate(http_requests_total[5m])", "legendFormat": "{{job}}", "interval": "30s",
+			})},
+			{RefID: "B", Model: simplejson.NewFromAny(map[string]interface{}{"expr": "up"})},
+		},
+	}
+
+	explanations, err := executor.ExplainDataQuery(context.Background(), dsInfo, query)
+	require.NoError(t, err)
+	require.Len(t, explanations, 2)
+
+	a := explanations[0]
+	assert.Equal(t, "A", a.RefID)
+	assert.Equal(t, "rate(http_requests_total[5m])", a.Statement)
+	assert.Equal(t, `a time series frame per series of the matrix, named by the legend format "{{job}}"`, a.Frames)
+	require.NotNil(t, a.Request)
+	assert.Equal(t, "POST", a.Request.Method)
+	assert.Equal(t, "http://prometheus:9090/api/v1/query_range?api_key=redacted&dedup=true", a.Request.URL)
+	assert.Equal(t, "Basic redacted", a.Request.Headers["Authorization"])
+	assert.Equal(t, "end=1600003600&query=rate%28http_requests_total%5B5m%5D%29&start=1600000000&step=30", a.Request.Body)
+
+	b := explanations[1]
+	assert.Equal(t, "B", b.RefID)
+	assert.Equal(t, "a time series frame per series of the matrix, named by its labels", b.Frames)
+}
@@ -13,12 +13,18 @@ import (
 
 	"net/http"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin/coreplugin"
+	"github.com/grafana/grafana/pkg/registry"
 	"github.com/grafana/grafana/pkg/tsdb/interval"
+	"github.com/grafana/grafana/pkg/tsdb/responselimit"
 	"github.com/prometheus/client_golang/api"
 	apiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/prometheus/common/model"
@@ -45,16 +51,7 @@ func (transport *prometheusTransport) RoundTrip(req *http.Request) (*http.Respon
 	}
 
 	if transport.customQueryParameters != "" {
-		params := url.Values{}
-		for _, param := range strings.Split(transport.customQueryParameters, "&") {
-			parts := strings.Split(param, "=")
-			if len(parts) == 1 {
-				// This is probably a mistake on the users part in defining the params but we don't want to crash.
-				params.Add(parts[0], "")
-			} else {
-				params.Add(parts[0], parts[1])
-			}
-		}
+		params := customQueryParams(transport.customQueryParameters)
 		if req.URL.RawQuery != "" {
 			req.URL.RawQuery = fmt.Sprintf("%s&%s", req.URL.RawQuery, params.Encode())
 		} else {
@@ -65,6 +62,21 @@ func (transport *prometheusTransport) RoundTrip(req *http.Request) (*http.Respon
 	return transport.Transport.RoundTrip(req)
 }
 
+// customQueryParams parses the custom query parameters of a data source, added to the requests.
+func customQueryParams(customQueryParameters string) url.Values {
+	params := url.Values{}
+	for _, param := range strings.Split(customQueryParameters, "&") {
+		parts := strings.Split(param, "=")
+		if len(parts) == 1 {
+			// This is probably a mistake on the users part in defining the params but we don't want to crash.
+			params.Add(parts[0], "")
+		} else {
+			params.Add(parts[0], parts[1])
+		}
+	}
+	return params
+}
+
 //nolint: staticcheck // plugins.DataPlugin deprecated
 func New(provider httpclient.Provider) func(*models.DataSource) (plugins.DataPlugin, error) {
 	return func(dsInfo *models.DataSource) (plugins.DataPlugin, error) {
@@ -89,6 +101,35 @@ var (
 
 func init() {
 	plog = log.New("tsdb.prometheus")
+
+	registry.Register(&registry.Descriptor{
+		Name:         "PrometheusService",
+		InitPriority: registry.Low,
+		Instance:     &PrometheusService{},
+	})
+}
+
+// PrometheusService serves the resources of Prometheus data sources, the queries are handled by the data plugin of
+// New.
+type PrometheusService struct {
+	BackendPluginManager backendplugin.Manager `inject:""`
+	HTTPClientProvider   httpclient.Provider   `inject:""`
+	logger               log.Logger
+	capabilities         capabilitiesCache
+}
+
+func (s *PrometheusService) Init() error {
+	s.logger = plog
+
+	resourceMux := http.NewServeMux()
+	s.registerRoutes(resourceMux)
+	factory := coreplugin.New(backend.ServeOpts{
+		CallResourceHandler: httpadapter.New(resourceMux),
+	})
+	if err := s.BackendPluginManager.RegisterAndStart(context.Background(), "prometheus", factory); err != nil {
+		s.logger.Error("Failed to register plugin", "error", err)
+	}
+	return nil
 }
 
 func (e *PrometheusExecutor) getClient(dsInfo *models.DataSource) (apiv1.API, error) {
@@ -157,7 +198,7 @@ func (e *PrometheusExecutor) DataQuery(ctx context.Context, dsInfo *models.DataS
 			return result, err
 		}
 
-		queryResult, err := parseResponse(value, query)
+		queryResult, err := parseResponse(value, query, responselimit.ForQuery(ctx, query.RefId))
 		if err != nil {
 			return result, err
 		}
@@ -228,8 +269,11 @@ func (e *PrometheusExecutor) parseQuery(dsInfo *models.DataSource, query plugins
 	return qs, nil
 }
 
+// parseResponse converts the matrix of a query to data frames, and stops at the response limits of the query, with
+// a notice on the last frame.
 //nolint: staticcheck // plugins.DataQueryResult deprecated
-func parseResponse(value model.Value, query *PrometheusQuery) (plugins.DataQueryResult, error) {
+func parseResponse(value model.Value, query *PrometheusQuery, limiter *responselimit.Limiter) (
+	plugins.DataQueryResult, error) {
 	var queryRes plugins.DataQueryResult
 	frames := data.Frames{}
 
@@ -239,6 +283,9 @@ func parseResponse(value model.Value, query *PrometheusQuery) (plugins.DataQuery
 	}
 
 	for _, v := range matrix {
+		if !limiter.AddSeries() {
+			break
+		}
 		name := formatLegend(v.Metric, query)
 		tags := make(map[string]string, len(v.Metric))
 		timeVector := make([]time.Time, 0, len(v.Values))
@@ -249,12 +296,25 @@ func parseResponse(value model.Value, query *PrometheusQuery) (plugins.DataQuery
 		}
 
 		for _, k := range v.Values {
+			// a time and a float64 value
+			if !limiter.AddRow(16) {
+				break
+			}
 			timeVector = append(timeVector, time.Unix(k.Timestamp.Unix(), 0).UTC())
 			values = append(values, float64(k.Value))
 		}
 		frames = append(frames, data.NewFrame(name,
 			data.NewField("time", nil, timeVector),
 			data.NewField("value", tags, values).SetConfig(&data.FieldConfig{DisplayNameFromDS: name})))
+		if limiter.Exceeded() != "" {
+			break
+		}
+	}
+	if limiter.Exceeded() != "" {
+		if len(frames) == 0 {
+			frames = append(frames, data.NewFrame(""))
+		}
+		frames[len(frames)-1].AppendNotices(limiter.Notice())
 	}
 	queryRes.Dataframes = plugins.NewDecodedDataFrames(frames)
 
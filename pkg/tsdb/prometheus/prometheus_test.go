@@ -7,10 +7,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/tsdb/responselimit"
 	p "github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 )
@@ -153,7 +155,7 @@ func TestParseResponse(t *testing.T) {
 		//nolint: staticcheck // plugins.DataQueryResult deprecated
 		queryRes := plugins.DataQueryResult{}
 		value := p.Vector{}
-		res, err := parseResponse(value, nil)
+		res, err := parseResponse(value, nil, nil)
 
 		require.Equal(t, queryRes, res)
 		require.Error(t, err)
@@ -176,7 +178,7 @@ func TestParseResponse(t *testing.T) {
 		query := &PrometheusQuery{
 			LegendFormat: "legend {{app}}",
 		}
-		res, err := parseResponse(value, query)
+		res, err := parseResponse(value, query, nil)
 		require.NoError(t, err)
 
 		decoded, _ := res.Dataframes.Decoded()
@@ -194,4 +196,23 @@ func TestParseResponse(t *testing.T) {
 		testValue := decoded[0].Fields[0].At(0)
 		require.Equal(t, "UTC", testValue.(time.Time).Location().String())
 	})
+
+	t.Run("response should be cut off at the limits of the query", func(t *testing.T) {
+		value := p.Matrix{
+			&p.SampleStream{Metric: p.Metric{"app": "a"}, Values: make([]p.SamplePair, 60)},
+			&p.SampleStream{Metric: p.Metric{"app": "b"}, Values: make([]p.SamplePair, 60)},
+			&p.SampleStream{Metric: p.Metric{"app": "c"}, Values: make([]p.SamplePair, 60)},
+		}
+		limiter := responselimit.New(responselimit.Limits{MaxRows: 100})
+		res, err := parseResponse(value, &PrometheusQuery{}, limiter)
+		require.NoError(t, err)
+
+		decoded, err := res.Dataframes.Decoded()
+		require.NoError(t, err)
+		require.Len(t, decoded, 2)
+		require.Equal(t, 60, decoded[0].Rows())
+		require.Equal(t, 40, decoded[1].Rows())
+		require.Equal(t, []data.Notice{limiter.Notice()}, decoded[1].Meta.Notices)
+		require.Equal(t, "100 rows", limiter.Exceeded())
+	})
 }
@@ -0,0 +1,146 @@
+package tsdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"golang.org/x/sync/semaphore"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// bytesPerDataPoint is the estimated memory of a data point, a time and
+	// a value.
+	bytesPerDataPoint = 16
+	// defaultMaxDataPoints is the max data points of the queries without
+	// max data points in the estimate of their memory.
+	defaultMaxDataPoints = 1000
+)
+
+// ErrQueryBudgetExceeded is returned for the requests rejected because too many queries are running.
+var ErrQueryBudgetExceeded = errors.New("too many queries are running")
+
+var queryBudgetLogger = log.New("tsdb.query_budget")
+
+// queryBudget limits the number and the estimated memory of the data source queries running at the same time. The
+// requests exceeding the budget wait for it in a queue, and are rejected when the queue is full or they waited for
+// too long.
+type queryBudget struct {
+	settings setting.QueryBudgetSettings
+	// queries and memory are nil when they aren't limited
+	queries *semaphore.Weighted
+	memory  *semaphore.Weighted
+	queued  int64
+}
+
+func newQueryBudget(settings setting.QueryBudgetSettings) *queryBudget {
+	b := &queryBudget{settings: settings}
+	if settings.MaxConcurrentQueries > 0 {
+		b.queries = semaphore.NewWeighted(settings.MaxConcurrentQueries)
+	}
+	if settings.MaxInflightMemory > 0 {
+		b.memory = semaphore.NewWeighted(settings.MaxInflightMemory)
+	}
+	return b
+}
+
+// acquire reserves the budget of the queries of a request, waiting for it if needed, and returns the function
+// releasing it. The requests larger than the whole budget wait for all of it.
+//nolint: staticcheck // plugins.DataQuery deprecated
+func (b *queryBudget) acquire(ctx context.Context, ds *models.DataSource, query plugins.DataQuery) (func(), error) {
+	if b == nil || (b.queries == nil && b.memory == nil) {
+		return func() {}, nil
+	}
+
+	count := int64(len(query.Queries))
+	if count > b.settings.MaxConcurrentQueries {
+		count = b.settings.MaxConcurrentQueries
+	}
+	memory := b.estimateMemory(query)
+	if memory > b.settings.MaxInflightMemory {
+		memory = b.settings.MaxInflightMemory
+	}
+
+	if b.tryAcquire(count, memory) {
+		return func() { b.release(count, memory) }, nil
+	}
+
+	if atomic.AddInt64(&b.queued, 1) > b.settings.MaxQueuedRequests {
+		atomic.AddInt64(&b.queued, -1)
+		return nil, b.rejected(ds, query, "the queue is full")
+	}
+	defer atomic.AddInt64(&b.queued, -1)
+
+	ctx, cancel := context.WithTimeout(ctx, b.settings.QueueTimeout)
+	defer cancel()
+	// the queries are always acquired before the memory, so that the
+	// requests holding memory aren't waiting
+	if b.queries != nil {
+		if err := b.queries.Acquire(ctx, count); err != nil {
+			return nil, b.rejected(ds, query, "the queue timed out")
+		}
+	}
+	if b.memory != nil {
+		if err := b.memory.Acquire(ctx, memory); err != nil {
+			if b.queries != nil {
+				b.queries.Release(count)
+			}
+			return nil, b.rejected(ds, query, "the queue timed out")
+		}
+	}
+	return func() { b.release(count, memory) }, nil
+}
+
+func (b *queryBudget) tryAcquire(count, memory int64) bool {
+	if b.queries != nil && !b.queries.TryAcquire(count) {
+		return false
+	}
+	if b.memory != nil && !b.memory.TryAcquire(memory) {
+		if b.queries != nil {
+			b.queries.Release(count)
+		}
+		return false
+	}
+	return true
+}
+
+func (b *queryBudget) release(count, memory int64) {
+	if b.queries != nil {
+		b.queries.Release(count)
+	}
+	if b.memory != nil {
+		b.memory.Release(memory)
+	}
+}
+
+//nolint: staticcheck // plugins.DataQuery deprecated
+func (b *queryBudget) rejected(ds *models.DataSource, query plugins.DataQuery, reason string) error {
+	queryBudgetLogger.Warn("Request rejected by the query budget", "reason", reason, "datasource", ds.Name,
+		"datasourceId", ds.Id, "orgId", ds.OrgId, "queries", len(query.Queries))
+	return fmt.Errorf("%w, %s", ErrQueryBudgetExceeded, reason)
+}
+
+// estimateMemory returns the estimated memory of the queries of a request, their max data points for the estimated
+// series per query, at most the max response size of the queries.
+//nolint: staticcheck // plugins.DataQuery deprecated
+func (b *queryBudget) estimateMemory(query plugins.DataQuery) int64 {
+	var memory int64
+	for _, q := range query.Queries {
+		maxDataPoints := q.MaxDataPoints
+		if maxDataPoints <= 0 {
+			maxDataPoints = defaultMaxDataPoints
+		}
+		estimate := maxDataPoints * b.settings.EstimatedSeriesPerQuery * bytesPerDataPoint
+		if max := b.settings.MaxQueryResponseSize; max > 0 && estimate > max {
+			estimate = max
+		}
+		memory += estimate
+	}
+	return memory
+}
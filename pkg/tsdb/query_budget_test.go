@@ -0,0 +1,108 @@
+package tsdb
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint: staticcheck // plugins.DataQuery deprecated
+func TestQueryBudget(t *testing.T) {
+	ds := &models.DataSource{Id: 1, Name: "prometheus", OrgId: 1}
+	query := func(refIDs ...string) plugins.DataQuery {
+		q := plugins.DataQuery{}
+		for _, refID := range refIDs {
+			q.Queries = append(q.Queries, plugins.DataSubQuery{RefID: refID, MaxDataPoints: 100})
+		}
+		return q
+	}
+
+	t.Run("A nil budget doesn't limit the queries", func(t *testing.T) {
+		var budget *queryBudget
+		release, err := budget.acquire(context.Background(), ds, query("A", "B"))
+		require.NoError(t, err)
+		release()
+	})
+
+	t.Run("Releasing the budget lets the queued requests run", func(t *testing.T) {
+		budget := newQueryBudget(setting.QueryBudgetSettings{
+			MaxConcurrentQueries: 2,
+			MaxQueuedRequests:    1,
+			QueueTimeout:         time.Minute,
+		})
+		release, err := budget.acquire(context.Background(), ds, query("A", "B"))
+		require.NoError(t, err)
+
+		acquired := make(chan error)
+		go func() {
+			release, err := budget.acquire(context.Background(), ds, query("A"))
+			if err == nil {
+				release()
+			}
+			acquired <- err
+		}()
+
+		select {
+		case <-acquired:
+			t.Fatal("the request should wait for the budget")
+		case <-time.After(50 * time.Millisecond):
+		}
+		release()
+		require.NoError(t, <-acquired)
+	})
+
+	t.Run("Rejects the requests when the queue is full", func(t *testing.T) {
+		budget := newQueryBudget(setting.QueryBudgetSettings{
+			MaxConcurrentQueries: 1,
+			MaxQueuedRequests:    0,
+			QueueTimeout:         time.Minute,
+		})
+		release, err := budget.acquire(context.Background(), ds, query("A"))
+		require.NoError(t, err)
+		defer release()
+
+		_, err = budget.acquire(context.Background(), ds, query("A"))
+		require.True(t, errors.Is(err, ErrQueryBudgetExceeded))
+	})
+
+	t.Run("Rejects the requests waiting longer than the queue timeout", func(t *testing.T) {
+		budget := newQueryBudget(setting.QueryBudgetSettings{
+			MaxInflightMemory:       200000,
+			EstimatedSeriesPerQuery: 100,
+			MaxQueuedRequests:       1,
+			QueueTimeout:            10 * time.Millisecond,
+		})
+		release, err := budget.acquire(context.Background(), ds, query("A"))
+		require.NoError(t, err)
+		defer release()
+
+		_, err = budget.acquire(context.Background(), ds, query("A"))
+		require.True(t, errors.Is(err, ErrQueryBudgetExceeded))
+	})
+
+	t.Run("The requests larger than the budget wait for all of it", func(t *testing.T) {
+		budget := newQueryBudget(setting.QueryBudgetSettings{
+			MaxConcurrentQueries:    1,
+			MaxInflightMemory:       1024,
+			EstimatedSeriesPerQuery: 100,
+			MaxQueuedRequests:       1,
+			QueueTimeout:            10 * time.Millisecond,
+		})
+		release, err := budget.acquire(context.Background(), ds, query("A", "B", "C"))
+		require.NoError(t, err)
+
+		_, err = budget.acquire(context.Background(), ds, query("A"))
+		require.True(t, errors.Is(err, ErrQueryBudgetExceeded))
+
+		release()
+		release, err = budget.acquire(context.Background(), ds, query("A"))
+		require.NoError(t, err)
+		release()
+	})
+}
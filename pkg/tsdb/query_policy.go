@@ -0,0 +1,53 @@
+package tsdb
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/components/querypolicy"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+)
+
+var queryPolicyAuditLogger = log.New("audit.query_policy")
+
+// ErrQueryPolicyViolation is returned for the queries violating the query policy of their data source.
+var ErrQueryPolicyViolation = errors.New("query violates the query policy of the data source")
+
+// checkQueryPolicy checks the queries against the query policy of their data source, the queryPolicy of its JSON
+// data. The violations are recorded in the audit log, and fail the queries.
+//nolint: staticcheck // plugins.DataQuery deprecated
+func checkQueryPolicy(ds *models.DataSource, query plugins.DataQuery) error {
+	policy, err := querypolicy.New(ds.JsonData)
+	if err != nil {
+		return fmt.Errorf("invalid query policy of data source %q: %w", ds.Name, err)
+	}
+	if policy == nil {
+		return nil
+	}
+
+	for _, q := range query.Queries {
+		if q.Model == nil {
+			continue
+		}
+		violations := policy.Check(ds.Type, q.Model)
+		if len(violations) == 0 {
+			continue
+		}
+
+		var userID int64
+		var login string
+		if query.User != nil {
+			userID, login = query.User.UserId, query.User.Login
+		}
+		for _, violation := range violations {
+			queryPolicyAuditLogger.Warn("Query denied by the query policy of the data source", "datasource", ds.Name,
+				"datasourceId", ds.Id, "orgId", ds.OrgId, "userId", userID, "uname", login, "refId", q.RefID,
+				"rule", violation.Rule, "message", violation.Message)
+		}
+		return fmt.Errorf("%w: query %s of data source %q: %s", ErrQueryPolicyViolation, q.RefID, ds.Name,
+			violations[0].Message)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package tsdb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryPolicy(t *testing.T) {
+	//nolint: staticcheck // plugins.DataPlugin deprecated
+	query := func(t *testing.T, jsonData map[string]interface{}, rawSQL string) (bool, error) {
+		t.Helper()
+
+		svc, exe := createService()
+		ran := false
+		exe.HandleQuery("A", func(query plugins.DataQuery) plugins.DataQueryResult {
+			ran = true
+			return plugins.DataQueryResult{}
+		})
+
+		ds := &models.DataSource{Id: 1, Name: "warehouse", Type: "test", JsonData: simplejson.NewFromAny(jsonData)}
+		_, err := svc.HandleRequest(context.Background(), ds, plugins.DataQuery{
+			Queries: []plugins.DataSubQuery{{
+				RefID:      "A",
+				DataSource: ds,
+				Model:      simplejson.NewFromAny(map[string]interface{}{"rawSql": rawSQL}),
+			}},
+			User: &models.SignedInUser{UserId: 2, Login: "viewer"},
+		})
+		return ran, err
+	}
+	policy := map[string]interface{}{
+		"queryPolicy": map[string]interface{}{
+			"rules": []interface{}{
+				map[string]interface{}{"type": "deny", "pattern": `(?i)\bDROP\b`, "message": "the tables are read-only"},
+			},
+		},
+	}
+
+	t.Run("Should run the queries without a query policy", func(t *testing.T) {
+		ran, err := query(t, map[string]interface{}{}, "DROP TABLE orders")
+		require.NoError(t, err)
+		require.True(t, ran)
+	})
+
+	t.Run("Should run the queries complying with the query policy", func(t *testing.T) {
+		ran, err := query(t, policy, "SELECT total FROM orders")
+		require.NoError(t, err)
+		require.True(t, ran)
+	})
+
+	t.Run("Should fail the queries violating the query policy", func(t *testing.T) {
+		ran, err := query(t, policy, "drop TABLE orders")
+		require.True(t, errors.Is(err, ErrQueryPolicyViolation))
+		require.Contains(t, err.Error(), `query A of data source "warehouse": the tables are read-only`)
+		require.False(t, ran)
+	})
+
+	t.Run("Should fail with an invalid query policy", func(t *testing.T) {
+		_, err := query(t, map[string]interface{}{
+			"queryPolicy": map[string]interface{}{"rules": []interface{}{map[string]interface{}{"type": "block"}}},
+		}, "SELECT total FROM orders")
+		require.EqualError(t, err, `invalid query policy of data source "warehouse": rule 1: unknown type "block"`)
+	})
+}
@@ -0,0 +1,266 @@
+package tsdb
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// ErrRecordingNotFound is returned in the replay mode for the queries without a recorded response.
+var ErrRecordingNotFound = errors.New("no recorded response of the query")
+
+var recordingLogger = log.New("tsdb.recording")
+
+// recorder records the responses of the data source queries to disk, keyed by the hash of the queries, and replays
+// them instead of querying the data sources, so that dashboards can be tested without the databases. The mode can be
+// changed while the server runs.
+type recorder struct {
+	path string
+	mode atomic.Value
+}
+
+func newRecorder(settings setting.DataSourceRecordingSettings) *recorder {
+	r := &recorder{path: settings.Path}
+	r.mode.Store(settings.Mode)
+	return r
+}
+
+func (r *recorder) getMode() string {
+	if r == nil {
+		return setting.DataSourceRecordingOff
+	}
+	return r.mode.Load().(string)
+}
+
+// recording is a recorded response, with the queries it responds to.
+type recording struct {
+	DataSource string                    `json:"datasource"`
+	From       string                    `json:"from,omitempty"`
+	To         string                    `json:"to,omitempty"`
+	Queries    []recordedQuery           `json:"queries"`
+	Results    map[string]recordedResult `json:"results"`
+	Message    string                    `json:"message,omitempty"`
+}
+
+type recordedQuery struct {
+	RefID         string           `json:"refId"`
+	Model         *simplejson.Json `json:"model,omitempty"`
+	MaxDataPoints int64            `json:"maxDataPoints,omitempty"`
+	IntervalMS    int64            `json:"intervalMs,omitempty"`
+	QueryType     string           `json:"queryType,omitempty"`
+}
+
+type recordedResult struct {
+	Error      string                      `json:"error,omitempty"`
+	Meta       *simplejson.Json            `json:"meta,omitempty"`
+	Series     plugins.DataTimeSeriesSlice `json:"series,omitempty"`
+	Tables     []plugins.DataTable         `json:"tables,omitempty"`
+	Dataframes [][]byte                    `json:"dataframes,omitempty"`
+}
+
+// recordingKey returns the hash of the queries of a request, their data source, models and time range as requested,
+// so that the relative time ranges of the same queries get the same response.
+//nolint: staticcheck // plugins.DataQuery deprecated
+func recordingKey(ds *models.DataSource, query plugins.DataQuery) (string, recording, error) {
+	rec := recording{DataSource: ds.Type + "/" + ds.Uid}
+	if query.TimeRange != nil {
+		rec.From, rec.To = query.TimeRange.From, query.TimeRange.To
+	}
+	for _, q := range query.Queries {
+		rec.Queries = append(rec.Queries, recordedQuery{
+			RefID:         q.RefID,
+			Model:         q.Model,
+			MaxDataPoints: q.MaxDataPoints,
+			IntervalMS:    q.IntervalMS,
+			QueryType:     q.QueryType,
+		})
+	}
+
+	content, err := json.Marshal(rec)
+	if err != nil {
+		return "", rec, fmt.Errorf("failed to marshal the queries: %w", err)
+	}
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:]), rec, nil
+}
+
+// replay returns the recorded response of the queries of a request.
+//nolint: staticcheck // plugins.DataQuery deprecated
+func (r *recorder) replay(ds *models.DataSource, query plugins.DataQuery) (plugins.DataResponse, error) {
+	key, _, err := recordingKey(ds, query)
+	if err != nil {
+		return plugins.DataResponse{}, err
+	}
+
+	content, err := os.ReadFile(filepath.Join(r.path, key+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		recordingLogger.Warn("No recorded response of the query", "datasource", ds.Name, "key", key)
+		return plugins.DataResponse{}, fmt.Errorf("%w of data source %q, %s", ErrRecordingNotFound, ds.Name, key)
+	}
+	if err != nil {
+		return plugins.DataResponse{}, fmt.Errorf("failed to read the recorded response %s: %w", key, err)
+	}
+
+	var rec recording
+	if err := json.Unmarshal(content, &rec); err != nil {
+		return plugins.DataResponse{}, fmt.Errorf("invalid recorded response %s: %w", key, err)
+	}
+
+	resp := plugins.DataResponse{Results: make(map[string]plugins.DataQueryResult, len(rec.Results)), Message: rec.Message}
+	for refID, recorded := range rec.Results {
+		result := plugins.DataQueryResult{
+			RefID:       refID,
+			ErrorString: recorded.Error,
+			Meta:        recorded.Meta,
+			Series:      recorded.Series,
+			Tables:      recorded.Tables,
+		}
+		if recorded.Error != "" {
+			result.Error = errors.New(recorded.Error)
+		}
+		if recorded.Dataframes != nil {
+			result.Dataframes = plugins.NewEncodedDataFrames(recorded.Dataframes)
+		}
+		resp.Results[refID] = result
+	}
+	return resp, nil
+}
+
+// record writes the response of the queries of a request to disk, replacing the previous recording.
+//nolint: staticcheck // plugins.DataQuery deprecated
+func (r *recorder) record(ds *models.DataSource, query plugins.DataQuery, resp plugins.DataResponse) error {
+	key, rec, err := recordingKey(ds, query)
+	if err != nil {
+		return err
+	}
+
+	rec.Message = resp.Message
+	rec.Results = make(map[string]recordedResult, len(resp.Results))
+	for refID, result := range resp.Results {
+		recorded := recordedResult{
+			Error:  result.ErrorString,
+			Meta:   result.Meta,
+			Series: result.Series,
+			Tables: result.Tables,
+		}
+		if result.Error != nil {
+			recorded.Error = result.Error.Error()
+		}
+		if result.Dataframes != nil {
+			if recorded.Dataframes, err = result.Dataframes.Encoded(); err != nil {
+				return fmt.Errorf("failed to encode the frames of query %s: %w", refID, err)
+			}
+		}
+		rec.Results[refID] = recorded
+	}
+
+	content, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the response: %w", err)
+	}
+	if err := os.MkdirAll(r.path, 0750); err != nil {
+		return fmt.Errorf("failed to create the recordings directory: %w", err)
+	}
+
+	// the recording is renamed once written, so that it's never replayed partially
+	tmp, err := os.CreateTemp(r.path, key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create the recording: %w", err)
+	}
+	if _, err := tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write the recording: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write the recording: %w", err)
+	}
+	return os.Rename(tmp.Name(), filepath.Join(r.path, key+".json"))
+}
+
+// recordings returns the keys of the recorded responses.
+func (r *recorder) recordings() ([]string, error) {
+	entries, err := os.ReadDir(r.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for _, entry := range entries {
+		if name := entry.Name(); !entry.IsDir() && strings.HasSuffix(name, ".json") {
+			keys = append(keys, strings.TrimSuffix(name, ".json"))
+		}
+	}
+	return keys, nil
+}
+
+// RecordingStatus is the mode of the recording of the data source responses, and the number of recorded responses.
+type RecordingStatus struct {
+	Mode       string `json:"mode"`
+	Path       string `json:"path"`
+	Recordings int    `json:"recordings"`
+}
+
+// RecordingStatus returns the status of the recording of the data source responses.
+func (s *Service) RecordingStatus() (RecordingStatus, error) {
+	if s.recorder == nil {
+		return RecordingStatus{Mode: setting.DataSourceRecordingOff}, nil
+	}
+
+	keys, err := s.recorder.recordings()
+	if err != nil {
+		return RecordingStatus{}, fmt.Errorf("failed to list the recordings: %w", err)
+	}
+	return RecordingStatus{Mode: s.recorder.getMode(), Path: s.recorder.path, Recordings: len(keys)}, nil
+}
+
+// SetRecordingMode changes the mode of the recording of the data source responses, until the server restarts.
+func (s *Service) SetRecordingMode(mode string) error {
+	switch mode {
+	case setting.DataSourceRecordingOff, setting.DataSourceRecordingRecord, setting.DataSourceRecordingReplay:
+	default:
+		return fmt.Errorf("invalid recording mode %q, must be %s, %s or %s", mode, setting.DataSourceRecordingOff,
+			setting.DataSourceRecordingRecord, setting.DataSourceRecordingReplay)
+	}
+	if s.recorder == nil {
+		return errors.New("the recording of the data source responses isn't available")
+	}
+
+	s.recorder.mode.Store(mode)
+	recordingLogger.Info("Data source recording mode changed", "mode", mode)
+	return nil
+}
+
+// DeleteRecordings deletes the recorded responses, and returns how many were deleted.
+func (s *Service) DeleteRecordings() (int, error) {
+	if s.recorder == nil {
+		return 0, nil
+	}
+
+	keys, err := s.recorder.recordings()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list the recordings: %w", err)
+	}
+	for i, key := range keys {
+		if err := os.Remove(filepath.Join(s.recorder.path, key+".json")); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return i, fmt.Errorf("failed to delete the recording %s: %w", key, err)
+		}
+	}
+	return len(keys), nil
+}
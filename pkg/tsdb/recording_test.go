@@ -0,0 +1,94 @@
+package tsdb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/null"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint: staticcheck // plugins.DataQuery deprecated
+func TestRecording(t *testing.T) {
+	ds := &models.DataSource{Id: 1, Uid: "prometheus", Type: "test"}
+	query := func(expr string) plugins.DataQuery {
+		return plugins.DataQuery{
+			TimeRange: &plugins.DataTimeRange{From: "now-1h", To: "now"},
+			Queries: []plugins.DataSubQuery{
+				{RefID: "A", Model: simplejson.NewFromAny(map[string]interface{}{"expr": expr}), MaxDataPoints: 100},
+			},
+		}
+	}
+
+	svc, exe := createService()
+	svc.recorder = newRecorder(setting.DataSourceRecordingSettings{
+		Mode: setting.DataSourceRecordingRecord,
+		Path: t.TempDir(),
+	})
+	exe.Return("A", plugins.DataTimeSeriesSlice{{
+		Name:   "up",
+		Points: plugins.DataTimeSeriesPoints{{null.FloatFrom(1), null.FloatFrom(1000)}, {null.Float{}, null.FloatFrom(2000)}},
+	}})
+
+	_, err := svc.HandleRequest(context.Background(), ds, query("up"))
+	require.NoError(t, err)
+
+	status, err := svc.RecordingStatus()
+	require.NoError(t, err)
+	assert.Equal(t, setting.DataSourceRecordingRecord, status.Mode)
+	assert.Equal(t, 1, status.Recordings)
+
+	require.NoError(t, svc.SetRecordingMode(setting.DataSourceRecordingReplay))
+	exe.Return("A", nil)
+
+	t.Run("Recorded responses are replayed", func(t *testing.T) {
+		resp, err := svc.HandleRequest(context.Background(), ds, query("up"))
+		require.NoError(t, err)
+		require.Len(t, resp.Results["A"].Series, 1)
+		assert.Equal(t, "up", resp.Results["A"].Series[0].Name)
+		assert.Equal(t, plugins.DataTimeSeriesPoints{{null.FloatFrom(1), null.FloatFrom(1000)}, {null.Float{}, null.FloatFrom(2000)}},
+			resp.Results["A"].Series[0].Points)
+	})
+
+	t.Run("Queries without recorded responses fail", func(t *testing.T) {
+		_, err := svc.HandleRequest(context.Background(), ds, query("down"))
+		require.True(t, errors.Is(err, ErrRecordingNotFound))
+	})
+
+	t.Run("Frames and errors are recorded", func(t *testing.T) {
+		frame := data.NewFrame("cpu", data.NewField("value", nil, []float64{0.5}))
+		resp := plugins.DataResponse{Results: map[string]plugins.DataQueryResult{
+			"A": {RefID: "A", Dataframes: plugins.NewDecodedDataFrames(data.Frames{frame})},
+			"B": {RefID: "B", Error: errors.New("bad query")},
+		}}
+		require.NoError(t, svc.recorder.record(ds, query("cpu"), resp))
+
+		replayed, err := svc.recorder.replay(ds, query("cpu"))
+		require.NoError(t, err)
+		frames, err := replayed.Results["A"].Dataframes.Decoded()
+		require.NoError(t, err)
+		assert.Equal(t, data.Frames{frame}, frames)
+		assert.EqualError(t, replayed.Results["B"].Error, "bad query")
+	})
+
+	t.Run("Invalid modes fail", func(t *testing.T) {
+		require.Error(t, svc.SetRecordingMode("playback"))
+	})
+
+	t.Run("Recordings are deleted", func(t *testing.T) {
+		deleted, err := svc.DeleteRecordings()
+		require.NoError(t, err)
+		assert.Equal(t, 2, deleted)
+
+		status, err := svc.RecordingStatus()
+		require.NoError(t, err)
+		assert.Equal(t, 0, status.Recordings)
+	})
+}
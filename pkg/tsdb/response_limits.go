@@ -0,0 +1,166 @@
+package tsdb
+
+import (
+	"context"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb/responselimit"
+)
+
+func newResponseLimits(settings setting.QueryBudgetSettings) responselimit.Limits {
+	return responselimit.Limits{
+		MaxRows:   settings.MaxQueryRows,
+		MaxSeries: settings.MaxQuerySeries,
+		MaxSize:   settings.MaxQueryResponseSize,
+	}
+}
+
+// truncateResponse flags the results of the queries exceeding the response limits with the truncated field of
+// their meta. The data sources limiting the responses with the limiters of the context stop building the results
+// at the limits, with a notice on the frame they stopped at. The results of the other data sources are truncated
+// here instead of failing them, with a notice on their last frame.
+//nolint: staticcheck // plugins.DataResponse deprecated
+func truncateResponse(ctx context.Context, ds *models.DataSource, resp plugins.DataResponse,
+	limits responselimit.Limits) {
+	if !limits.Enabled() {
+		return
+	}
+
+	for refID, result := range resp.Results {
+		if result.Error != nil {
+			continue
+		}
+		truncated, limit := result, ""
+		if limiter := responselimit.ForQuery(ctx, refID); limiter.Used() {
+			limit = limiter.Exceeded()
+		} else {
+			truncated, limit = truncateResult(result, limits)
+		}
+		if limit == "" {
+			continue
+		}
+
+		queryBudgetLogger.Warn("Query response truncated", "datasource", ds.Name, "datasourceId", ds.Id,
+			"orgId", ds.OrgId, "refId", refID, "limit", limit)
+		if truncated.Meta == nil {
+			truncated.Meta = simplejson.New()
+		}
+		truncated.Meta.Set("truncated", true)
+		resp.Results[refID] = truncated
+	}
+}
+
+// truncateResult returns the result of a query truncated to the limits, and the exceeded limit, empty when the
+// result is within the limits.
+//nolint: staticcheck // plugins.DataQueryResult deprecated
+func truncateResult(result plugins.DataQueryResult, limits responselimit.Limits) (plugins.DataQueryResult, string) {
+	limiter := responselimit.New(limits)
+
+	for i, series := range result.Series {
+		if !limiter.AddSeries() {
+			result.Series = result.Series[:i]
+			break
+		}
+		points := int64(len(series.Points))
+		if keep := limiter.AddRows(points, points*bytesPerDataPoint); keep < points {
+			series.Points = series.Points[:keep]
+			result.Series = append(result.Series[:i:i], series)
+			break
+		}
+	}
+
+	for i, table := range result.Tables {
+		var size int64
+		for _, row := range table.Rows {
+			size += int64(len(row)) * 8
+		}
+		rows := int64(len(table.Rows))
+		if keep := limiter.AddRows(rows, size); keep < rows {
+			table.Rows = table.Rows[:keep]
+			result.Tables = append(result.Tables[:i:i], table)
+			break
+		}
+	}
+
+	if result.Dataframes == nil || limiter.Exceeded() != "" {
+		return result, limiter.Exceeded()
+	}
+	// the encoded frames within the max size are kept as they are, unless their rows or series are limited
+	if encodedSize, ok := plugins.EncodedDataFramesSize(result.Dataframes); ok && limits.MaxRows <= 0 &&
+		limits.MaxSeries <= 0 && len(result.Series) == 0 && len(result.Tables) == 0 && encodedSize <= limits.MaxSize {
+		return result, ""
+	}
+	frames, err := result.Dataframes.Decoded()
+	if err != nil {
+		return result, ""
+	}
+	if frames, truncated := truncateFrames(frames, limiter); truncated {
+		result.Dataframes = plugins.NewDecodedDataFrames(frames)
+	}
+	return result, limiter.Exceeded()
+}
+
+// truncateFrames truncates frames to the limits of a limiter, with a notice on the last frame.
+func truncateFrames(frames data.Frames, limiter *responselimit.Limiter) (data.Frames, bool) {
+	if len(frames) == 0 {
+		return frames, false
+	}
+
+	for i, frame := range frames {
+		if !limiter.AddSeries() {
+			// an empty frame is kept for the notice
+			frames = append(frames[:i:i], truncateFrame(frame, 0))
+			break
+		}
+		rows := int64(frame.Rows())
+		if keep := limiter.AddRows(rows, frameSize(frame)); keep < rows {
+			frames = append(frames[:i:i], truncateFrame(frame, int(keep)))
+			break
+		}
+	}
+	if limiter.Exceeded() == "" {
+		return frames, false
+	}
+
+	frames[len(frames)-1].AppendNotices(limiter.Notice())
+	return frames, true
+}
+
+// truncateFrame returns a copy of the first rows of a frame.
+func truncateFrame(frame *data.Frame, rows int) *data.Frame {
+	truncated := frame.EmptyCopy()
+	if frame.Meta != nil {
+		meta := *frame.Meta
+		truncated.Meta = &meta
+	}
+	for i, field := range frame.Fields {
+		truncated.Fields[i].Config = field.Config
+	}
+	for i := 0; i < rows; i++ {
+		truncated.AppendRow(frame.RowCopy(i)...)
+	}
+	return truncated
+}
+
+// frameSize returns the estimated size in bytes of a frame, 8 bytes per value besides the strings.
+func frameSize(frame *data.Frame) int64 {
+	var size int64
+	for _, field := range frame.Fields {
+		switch field.Type() {
+		case data.FieldTypeString, data.FieldTypeNullableString:
+			for i := 0; i < field.Len(); i++ {
+				if v, ok := field.ConcreteAt(i); ok {
+					size += int64(len(v.(string)))
+				}
+			}
+		default:
+			size += int64(field.Len()) * 8
+		}
+	}
+	return size
+}
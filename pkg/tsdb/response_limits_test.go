@@ -0,0 +1,136 @@
+package tsdb
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/tsdb/responselimit"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint: staticcheck // plugins.DataResponse deprecated
+func TestTruncateResponse(t *testing.T) {
+	ds := &models.DataSource{Id: 1, Name: "prometheus", OrgId: 1}
+	frame := func(name string, rows int) *data.Frame {
+		return data.NewFrame(name,
+			data.NewField("time", nil, make([]time.Time, rows)),
+			data.NewField("value", nil, make([]float64, rows)),
+		)
+	}
+	decoded := func(t *testing.T, result plugins.DataQueryResult) data.Frames {
+		t.Helper()
+		frames, err := result.Dataframes.Decoded()
+		require.NoError(t, err)
+		return frames
+	}
+	requireTruncated := func(t *testing.T, result plugins.DataQueryResult, notice string) {
+		t.Helper()
+		require.NoError(t, result.Error)
+		require.True(t, result.Meta.Get("truncated").MustBool())
+		if notice == "" {
+			return
+		}
+		frames := decoded(t, result)
+		meta := frames[len(frames)-1].Meta
+		require.NotNil(t, meta)
+		require.Equal(t, []data.Notice{{Severity: data.NoticeSeverityWarning, Text: notice}}, meta.Notices)
+	}
+
+	t.Run("Truncates the rows of the frames", func(t *testing.T) {
+		resp := plugins.DataResponse{Results: map[string]plugins.DataQueryResult{
+			"A": {RefID: "A", Dataframes: plugins.NewDecodedDataFrames(data.Frames{frame("a", 60), frame("b", 60), frame("c", 60)})},
+			"B": {RefID: "B", Dataframes: plugins.NewDecodedDataFrames(data.Frames{frame("a", 60)})},
+		}}
+		truncateResponse(context.Background(), ds, resp, responselimit.Limits{MaxRows: 100})
+
+		requireTruncated(t, resp.Results["A"], "The results were truncated to the max 100 rows of the query")
+		frames := decoded(t, resp.Results["A"])
+		require.Len(t, frames, 2)
+		require.Equal(t, 60, frames[0].Rows())
+		require.Equal(t, 40, frames[1].Rows())
+
+		require.Nil(t, resp.Results["B"].Meta)
+		require.Equal(t, 60, decoded(t, resp.Results["B"])[0].Rows())
+	})
+
+	t.Run("Truncates the series of the frames", func(t *testing.T) {
+		resp := plugins.DataResponse{Results: map[string]plugins.DataQueryResult{
+			"A": {RefID: "A", Dataframes: plugins.NewDecodedDataFrames(data.Frames{frame("a", 10), frame("b", 10), frame("c", 10)})},
+		}}
+		truncateResponse(context.Background(), ds, resp, responselimit.Limits{MaxSeries: 2})
+
+		requireTruncated(t, resp.Results["A"], "The results were truncated to the max 2 series of the query")
+		frames := decoded(t, resp.Results["A"])
+		require.Len(t, frames, 3)
+		require.Equal(t, 10, frames[1].Rows())
+		require.Equal(t, 0, frames[2].Rows())
+	})
+
+	t.Run("Truncates the encoded frames larger than the max size", func(t *testing.T) {
+		large, err := data.Frames{frame("a", 100000)}.MarshalArrow()
+		require.NoError(t, err)
+		small, err := data.Frames{frame("a", 10)}.MarshalArrow()
+		require.NoError(t, err)
+		resp := plugins.DataResponse{Results: map[string]plugins.DataQueryResult{
+			"A": {RefID: "A", Dataframes: plugins.NewEncodedDataFrames(large)},
+			"B": {RefID: "B", Dataframes: plugins.NewEncodedDataFrames(small)},
+		}}
+		truncateResponse(context.Background(), ds, resp, responselimit.Limits{MaxSize: 1024 * 1024})
+
+		requireTruncated(t, resp.Results["A"], "The results were truncated to the max 1 MB of the query")
+		require.Equal(t, 1024*1024/16, decoded(t, resp.Results["A"])[0].Rows())
+		require.Nil(t, resp.Results["B"].Meta)
+	})
+
+	t.Run("Truncates the series and tables", func(t *testing.T) {
+		resp := plugins.DataResponse{Results: map[string]plugins.DataQueryResult{
+			"A": {RefID: "A", Series: plugins.DataTimeSeriesSlice{
+				{Name: "a", Points: make(plugins.DataTimeSeriesPoints, 60)},
+				{Name: "b", Points: make(plugins.DataTimeSeriesPoints, 60)},
+			}},
+			"B": {RefID: "B", Tables: []plugins.DataTable{
+				{Rows: make([]plugins.DataRowValues, 150)},
+			}},
+		}}
+		truncateResponse(context.Background(), ds, resp, responselimit.Limits{MaxRows: 100})
+
+		requireTruncated(t, resp.Results["A"], "")
+		require.Len(t, resp.Results["A"].Series, 2)
+		require.Len(t, resp.Results["A"].Series[1].Points, 40)
+		requireTruncated(t, resp.Results["B"], "")
+		require.Len(t, resp.Results["B"].Tables[0].Rows, 100)
+	})
+
+	t.Run("Flags the results truncated by the data sources while built", func(t *testing.T) {
+		limits := responselimit.Limits{MaxRows: 100, MaxSize: 1024}
+		ctx := responselimit.WithLimits(context.Background(), limits)
+		// the data source stopped at the max rows, its estimate of the size being within the max size
+		limiter := responselimit.ForQuery(ctx, "A")
+		require.Equal(t, int64(100), limiter.AddRows(150, 150))
+		truncated := frame("a", 100)
+		truncated.AppendNotices(limiter.Notice())
+		resp := plugins.DataResponse{Results: map[string]plugins.DataQueryResult{
+			"A": {RefID: "A", Dataframes: plugins.NewDecodedDataFrames(data.Frames{truncated})},
+		}}
+		truncateResponse(ctx, ds, resp, limits)
+
+		requireTruncated(t, resp.Results["A"], "The results were truncated to the max 100 rows of the query")
+		require.Equal(t, 100, decoded(t, resp.Results["A"])[0].Rows())
+	})
+
+	t.Run("Keeps the errors", func(t *testing.T) {
+		errTest := errors.New("query failed")
+		resp := plugins.DataResponse{Results: map[string]plugins.DataQueryResult{
+			"A": {RefID: "A", Error: errTest, Series: plugins.DataTimeSeriesSlice{{Points: make(plugins.DataTimeSeriesPoints, 200)}}},
+		}}
+		truncateResponse(context.Background(), ds, resp, responselimit.Limits{MaxRows: 100})
+
+		require.Equal(t, errTest, resp.Results["A"].Error)
+		require.Len(t, resp.Results["A"].Series[0].Points, 200)
+	})
+}
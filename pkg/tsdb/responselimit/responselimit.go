@@ -0,0 +1,161 @@
+// Package responselimit limits the rows, series and size of the responses of the queries while the data sources
+// build them, so that the queries exceeding the limits stop reading the results at the limits instead of loading
+// them whole.
+package responselimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// Limits are the maximum rows, series and size in bytes of the response of a query, 0 for no limit.
+type Limits struct {
+	MaxRows   int64
+	MaxSeries int64
+	MaxSize   int64
+}
+
+// Enabled returns whether any of the limits is set.
+func (l Limits) Enabled() bool {
+	return l.MaxRows > 0 || l.MaxSeries > 0 || l.MaxSize > 0
+}
+
+// Limiter counts the series, rows and size of the response of a query while it's built, and tracks the limit it
+// exceeded. A nil Limiter keeps everything.
+type Limiter struct {
+	mu     sync.Mutex
+	limits Limits
+	series int64
+	rows   int64
+	size   int64
+	// used is whether the response was counted by the limiter
+	used bool
+	// exceeded is the exceeded limit, empty while the response is within the limits
+	exceeded string
+}
+
+// New returns a limiter of the response of a query.
+func New(limits Limits) *Limiter {
+	return &Limiter{limits: limits}
+}
+
+// AddSeries returns whether the next series of the response is within the max series.
+func (l *Limiter) AddSeries() bool {
+	if l == nil {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.used = true
+	if l.exceeded != "" {
+		return false
+	}
+	if l.limits.MaxSeries > 0 && l.series >= l.limits.MaxSeries {
+		l.exceeded = fmt.Sprintf("%d series", l.limits.MaxSeries)
+		return false
+	}
+	l.series++
+	return true
+}
+
+// AddRows returns how many of the next rows of the response, of a size in bytes, are within the limits.
+func (l *Limiter) AddRows(rows, size int64) int64 {
+	if l == nil {
+		return rows
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.used = true
+	if l.exceeded != "" {
+		return 0
+	}
+
+	keep := rows
+	if l.limits.MaxRows > 0 && l.rows+keep > l.limits.MaxRows {
+		keep = l.limits.MaxRows - l.rows
+		l.exceeded = fmt.Sprintf("%d rows", l.limits.MaxRows)
+	}
+	if l.limits.MaxSize > 0 && rows > 0 && l.size+size*keep/rows > l.limits.MaxSize {
+		keep = (l.limits.MaxSize - l.size) * rows / size
+		l.exceeded = fmt.Sprintf("%d MB", l.limits.MaxSize/1024/1024)
+	}
+
+	l.rows += keep
+	if rows > 0 {
+		l.size += size * keep / rows
+	}
+	return keep
+}
+
+// AddRow returns whether the next row of the response, of a size in bytes, is within the limits.
+func (l *Limiter) AddRow(size int64) bool {
+	return l.AddRows(1, size) == 1
+}
+
+// Used returns whether the response was counted by the limiter, the data sources not using it are truncated
+// after their response is built.
+func (l *Limiter) Used() bool {
+	if l == nil {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.used
+}
+
+// Exceeded returns the exceeded limit, empty while the response is within the limits.
+func (l *Limiter) Exceeded() string {
+	if l == nil {
+		return ""
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.exceeded
+}
+
+// Notice returns the notice of the frame the response was truncated at.
+func (l *Limiter) Notice() data.Notice {
+	return data.Notice{
+		Severity: data.NoticeSeverityWarning,
+		Text:     fmt.Sprintf("The results were truncated to the max %s of the query", l.Exceeded()),
+	}
+}
+
+type contextKey struct{}
+
+// queryLimiters are the limiters of the queries of a request, by ref ID.
+type queryLimiters struct {
+	mu       sync.Mutex
+	limits   Limits
+	limiters map[string]*Limiter
+}
+
+// WithLimits returns a context limiting the responses of the queries of a request, if any of the limits is set.
+func WithLimits(ctx context.Context, limits Limits) context.Context {
+	if !limits.Enabled() {
+		return ctx
+	}
+	return context.WithValue(ctx, contextKey{}, &queryLimiters{limits: limits, limiters: map[string]*Limiter{}})
+}
+
+// ForQuery returns the limiter of the response of the query of a ref ID, nil if the context has no limits.
+func ForQuery(ctx context.Context, refID string) *Limiter {
+	queries, ok := ctx.Value(contextKey{}).(*queryLimiters)
+	if !ok {
+		return nil
+	}
+	queries.mu.Lock()
+	defer queries.mu.Unlock()
+
+	limiter, ok := queries.limiters[refID]
+	if !ok {
+		limiter = New(queries.limits)
+		queries.limiters[refID] = limiter
+	}
+	return limiter
+}
@@ -0,0 +1,55 @@
+package responselimit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter(t *testing.T) {
+	t.Run("Keeps the rows within the max rows", func(t *testing.T) {
+		limiter := New(Limits{MaxRows: 100})
+		require.Equal(t, int64(60), limiter.AddRows(60, 0))
+		require.Equal(t, int64(40), limiter.AddRows(60, 0))
+		require.False(t, limiter.AddRow(0))
+		require.Equal(t, "100 rows", limiter.Exceeded())
+		require.Equal(t, "The results were truncated to the max 100 rows of the query", limiter.Notice().Text)
+	})
+
+	t.Run("Keeps the rows within the max size", func(t *testing.T) {
+		limiter := New(Limits{MaxSize: 1024 * 1024})
+		require.Equal(t, int64(1024*1024/16), limiter.AddRows(1024*1024, 16*1024*1024))
+		require.Equal(t, "1 MB", limiter.Exceeded())
+	})
+
+	t.Run("Keeps the series within the max series", func(t *testing.T) {
+		limiter := New(Limits{MaxSeries: 2})
+		require.True(t, limiter.AddSeries())
+		require.True(t, limiter.AddSeries())
+		require.False(t, limiter.AddSeries())
+		require.Equal(t, "2 series", limiter.Exceeded())
+		require.Equal(t, int64(0), limiter.AddRows(10, 0))
+	})
+
+	t.Run("Keeps everything without limiter", func(t *testing.T) {
+		var limiter *Limiter
+		require.True(t, limiter.AddSeries())
+		require.Equal(t, int64(60), limiter.AddRows(60, 0))
+		require.False(t, limiter.Used())
+		require.Empty(t, limiter.Exceeded())
+	})
+}
+
+func TestForQuery(t *testing.T) {
+	require.Nil(t, ForQuery(context.Background(), "A"))
+	require.Nil(t, ForQuery(WithLimits(context.Background(), Limits{}), "A"))
+
+	ctx := WithLimits(context.Background(), Limits{MaxRows: 10})
+	limiter := ForQuery(ctx, "A")
+	require.Same(t, limiter, ForQuery(ctx, "A"))
+	require.NotSame(t, limiter, ForQuery(ctx, "B"))
+	require.False(t, limiter.Used())
+	limiter.AddRow(8)
+	require.True(t, ForQuery(ctx, "A").Used())
+}
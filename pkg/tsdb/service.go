@@ -2,9 +2,11 @@ package tsdb
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/registry"
@@ -21,9 +23,14 @@ import (
 	"github.com/grafana/grafana/pkg/tsdb/opentsdb"
 	"github.com/grafana/grafana/pkg/tsdb/postgres"
 	"github.com/grafana/grafana/pkg/tsdb/prometheus"
+	"github.com/grafana/grafana/pkg/tsdb/responselimit"
 	"github.com/grafana/grafana/pkg/tsdb/tempo"
 )
 
+// ErrExplainNotSupported is returned when explaining the queries of the data sources whose plugin doesn't explain
+// them.
+var ErrExplainNotSupported = errors.New("the queries of the data source type can't be explained")
+
 // NewService returns a new Service.
 func NewService() Service {
 	return Service{
@@ -52,6 +59,8 @@ type Service struct {
 
 	//nolint: staticcheck // plugins.DataPlugin deprecated
 	registry map[string]func(*models.DataSource) (plugins.DataPlugin, error)
+	budget   *queryBudget
+	recorder *recorder
 }
 
 // Init initialises the service.
@@ -68,29 +77,104 @@ func (s *Service) Init() error {
 	s.registry["grafana-azure-monitor-datasource"] = s.AzureMonitorService.NewExecutor
 	s.registry["loki"] = loki.New(s.HTTPClientProvider)
 	s.registry["tempo"] = tempo.New(s.HTTPClientProvider)
+	if s.Cfg != nil && s.Cfg.QueryBudget.Enabled() {
+		s.budget = newQueryBudget(s.Cfg.QueryBudget)
+	}
+	if s.Cfg != nil {
+		s.recorder = newRecorder(s.Cfg.DataSourceRecording)
+	}
 	return nil
 }
 
 //nolint: staticcheck // plugins.DataPlugin deprecated
 func (s *Service) HandleRequest(ctx context.Context, ds *models.DataSource, query plugins.DataQuery) (
 	plugins.DataResponse, error) {
-	plugin := s.PluginManager.GetDataPlugin(ds.Type)
-	if plugin == nil {
-		factory, exists := s.registry[ds.Type]
-		if !exists {
-			return plugins.DataResponse{}, fmt.Errorf(
-				"could not find plugin corresponding to data source type: %q", ds.Type)
-		}
+	// the recordings are keyed by the queries as requested
+	requested := query
+	query, err := applyMaxDataAge(ds, query)
+	if err != nil {
+		return plugins.DataResponse{}, err
+	}
+	if err := checkQueryPolicy(ds, query); err != nil {
+		return plugins.DataResponse{}, err
+	}
+	metrics.MDataSourceQueryTotal.Add(float64(len(query.Queries)))
+
+	mode := s.recorder.getMode()
+	if mode == setting.DataSourceRecordingReplay {
+		return s.recorder.replay(ds, requested)
+	}
+
+	release, err := s.budget.acquire(ctx, ds, query)
+	if err != nil {
+		return plugins.DataResponse{}, err
+	}
+	defer release()
 
-		var err error
-		plugin, err = factory(ds)
-		if err != nil {
-			return plugins.DataResponse{}, fmt.Errorf("could not instantiate endpoint for data plugin %q: %w",
-				ds.Type, err)
+	plugin, err := s.getDataPlugin(ds)
+	if err != nil {
+		return plugins.DataResponse{}, err
+	}
+
+	var limits responselimit.Limits
+	if s.Cfg != nil {
+		limits = newResponseLimits(s.Cfg.QueryBudget)
+	}
+	// the data sources stop building the responses at the limits
+	ctx = responselimit.WithLimits(ctx, limits)
+	resp, err := plugin.DataQuery(ctx, ds, query)
+	if err != nil {
+		return resp, err
+	}
+	truncateResponse(ctx, ds, resp, limits)
+	if mode == setting.DataSourceRecordingRecord {
+		if err := s.recorder.record(ds, requested, resp); err != nil {
+			recordingLogger.Error("Failed to record the response of the query", "datasource", ds.Name, "error", err)
 		}
 	}
+	return resp, nil
+}
+
+// ExplainRequest explains what the queries of a request will do, without running them. The max data age and the
+// query policy of the data source apply to the queries explained too.
+//nolint: staticcheck // plugins.DataQuery deprecated
+func (s *Service) ExplainRequest(ctx context.Context, ds *models.DataSource, query plugins.DataQuery) (
+	[]plugins.DataQueryExplanation, error) {
+	query, err := applyMaxDataAge(ds, query)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkQueryPolicy(ds, query); err != nil {
+		return nil, err
+	}
 
-	return plugin.DataQuery(ctx, ds, query)
+	plugin, err := s.getDataPlugin(ds)
+	if err != nil {
+		return nil, err
+	}
+	explainer, ok := plugin.(plugins.DataQueryExplainer)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrExplainNotSupported, ds.Type)
+	}
+	return explainer.ExplainDataQuery(ctx, ds, query)
+}
+
+// getDataPlugin returns the data plugin of a data source, the plugin of its type or the core data plugin.
+//nolint: staticcheck // plugins.DataPlugin deprecated
+func (s *Service) getDataPlugin(ds *models.DataSource) (plugins.DataPlugin, error) {
+	if plugin := s.PluginManager.GetDataPlugin(ds.Type); plugin != nil {
+		return plugin, nil
+	}
+
+	factory, exists := s.registry[ds.Type]
+	if !exists {
+		return nil, fmt.Errorf("could not find plugin corresponding to data source type: %q", ds.Type)
+	}
+	plugin, err := factory(ds)
+	if err != nil {
+		return nil, fmt.Errorf("could not instantiate endpoint for data plugin %q: %w", ds.Type, err)
+	}
+	return plugin, nil
 }
 
 // RegisterQueryHandler registers a query handler factory.
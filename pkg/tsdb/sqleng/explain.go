@@ -0,0 +1,103 @@
+package sqleng
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+)
+
+// macroRegExp matches the macros of the queries, the global macros like $__interval and the macros of the data
+// sources like $__timeFilter(time).
+var macroRegExp = regexp.MustCompile(`\$__[_a-zA-Z0-9]+(\([^\)]*\))?`)
+
+// ExplainDataQuery explains the queries, their macros and the SQL statements of the interpolated macros run on the
+// database, without connecting to it.
+// nolint: staticcheck // plugins.DataQuery deprecated
+func (e *dataPlugin) ExplainDataQuery(ctx context.Context, dsInfo *models.DataSource,
+	queryContext plugins.DataQuery) ([]plugins.DataQueryExplanation, error) {
+	var timeRange plugins.DataTimeRange
+	if queryContext.TimeRange != nil {
+		timeRange = *queryContext.TimeRange
+	}
+
+	explanations := make([]plugins.DataQueryExplanation, 0, len(queryContext.Queries))
+	for _, query := range queryContext.Queries {
+		rawSQL := query.Model.Get("rawSql").MustString()
+		if rawSQL == "" {
+			continue
+		}
+
+		explanation := plugins.DataQueryExplanation{RefID: query.RefID}
+		statement, err := e.interpolate(query, timeRange, rawSQL)
+		if err != nil {
+			explanation.Error = err.Error()
+			explanations = append(explanations, explanation)
+			continue
+		}
+		explanation.Statement = e.auditedQuery(queryContext, statement)
+
+		for _, macro := range macroRegExp.FindAllString(rawSQL, -1) {
+			if explanation.Macros == nil {
+				explanation.Macros = map[string]string{}
+			}
+			if _, ok := explanation.Macros[macro]; ok {
+				continue
+			}
+			value, err := e.interpolate(query, timeRange, macro)
+			if err != nil {
+				value = "error: " + err.Error()
+			}
+			explanation.Macros[macro] = value
+		}
+
+		frames, err := e.explainFrames(query)
+		if err != nil {
+			explanation.Error = err.Error()
+		}
+		explanation.Frames = frames
+		explanations = append(explanations, explanation)
+	}
+	return explanations, nil
+}
+
+// interpolate interpolates the global macros and the macros of the data source, like the queries run.
+// nolint: staticcheck // plugins.DataSubQuery deprecated
+func (e *dataPlugin) interpolate(query plugins.DataSubQuery, timeRange plugins.DataTimeRange, sql string) (string,
+	error) {
+	sql, err := Interpolate(query, timeRange, sql)
+	if err != nil {
+		return "", err
+	}
+	return e.macroEngine.Interpolate(query, timeRange, sql)
+}
+
+// explainFrames describes the frames of the response of a query, once its macros are interpolated, since the
+// $__timeGroup macros set the fill mode.
+// nolint: staticcheck // plugins.DataSubQuery deprecated
+func (e *dataPlugin) explainFrames(query plugins.DataSubQuery) (string, error) {
+	switch format := query.Model.Get("format").MustString("time_series"); format {
+	case string(dataQueryFormatTable):
+		return "a table frame with a field per column of the result", nil
+	case string(dataQueryFormatSeries):
+	default:
+		return "", fmt.Errorf("unrecognized query model format: %q", format)
+	}
+
+	frames := fmt.Sprintf("time series frames of the time column %s, a series per numeric column, named by the "+
+		"metric column or the first text column", strings.Join(e.timeColumnNames, " or "))
+	if !query.Model.Get("fill").MustBool(false) {
+		return frames, nil
+	}
+
+	interval := time.Duration(query.Model.Get("fillInterval").MustFloat64() * float64(time.Second))
+	fill := query.Model.Get("fillMode").MustString()
+	if fill == "value" {
+		fill = fmt.Sprint(query.Model.Get("fillValue").MustFloat64())
+	}
+	return fmt.Sprintf("%s, the missing points every %s filled with %s", frames, interval, fill), nil
+}
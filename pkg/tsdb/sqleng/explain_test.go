@@ -0,0 +1,110 @@
+package sqleng
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/queryaudit"
+)
+
+// timeMacroEngine interpolates $__timeFilter(column) and $__timeGroup(column, interval, fill) like the data sources.
+type timeMacroEngine struct{}
+
+var timeMacroRegExp = regexp.MustCompile(`\$__(timeFilter|timeGroup)\(([^\)]*)\)`)
+
+func (timeMacroEngine) Interpolate(query plugins.DataSubQuery, timeRange plugins.DataTimeRange, sql string) (string,
+	error) {
+	var err error
+	sql = timeMacroRegExp.ReplaceAllStringFunc(sql, func(macro string) string {
+		match := timeMacroRegExp.FindStringSubmatch(macro)
+		args := regexp.MustCompile(`\s*,\s*`).Split(match[2], -1)
+		if match[1] == "timeFilter" {
+			return fmt.Sprintf("%s BETWEEN %d AND %d", args[0], timeRange.GetFromAsSecondsEpoch(),
+				timeRange.GetToAsSecondsEpoch())
+		}
+		if len(args) < 2 {
+			err = fmt.Errorf("macro %s needs time column and interval", match[1])
+			return macro
+		}
+		interval, _ := time.ParseDuration(args[1])
+		if len(args) == 3 {
+			err = SetupFillmode(query, interval, args[2])
+		}
+		return fmt.Sprintf("floor(%s/%d)*%d", args[0], int(interval.Seconds()), int(interval.Seconds()))
+	})
+	return sql, err
+}
+
+func TestExplainDataQuery(t *testing.T) {
+	plugin := &dataPlugin{macroEngine: timeMacroEngine{}, timeColumnNames: []string{"time", "ts"}, log: log.New("test")}
+	ds := &models.DataSource{JsonData: simplejson.New()}
+	timeRange := plugins.NewDataTimeRange("1600000000000", "1600003600000")
+
+	explain := func(t *testing.T, model map[string]interface{}, headers map[string]string) plugins.DataQueryExplanation {
+		t.Helper()
+		explanations, err := plugin.ExplainDataQuery(context.Background(), ds, plugins.DataQuery{
+			TimeRange: &timeRange,
+			Queries:   []plugins.DataSubQuery{{RefID: "A", Model: simplejson.NewFromAny(model), DataSource: ds}},
+			Headers:   headers,
+		})
+		require.NoError(t, err)
+		require.Len(t, explanations, 1)
+		return explanations[0]
+	}
+
+	t.Run("Should explain the macros and the statement", func(t *testing.T) {
+		explanation := explain(t, map[string]interface{}{
+			"rawSql": "SELECT $__timeGroup(time, 5m, 0) AS time, avg(value) FROM metrics " +
+				"WHERE $__timeFilter(time) AND $__timeFilter(time) GROUP BY 1",
+			"format": "time_series",
+		}, nil)
+
+		assert.Equal(t, "A", explanation.RefID)
+		assert.Empty(t, explanation.Error)
+		assert.Equal(t, "SELECT floor(time/300)*300 AS time, avg(value) FROM metrics "+
+			"WHERE time BETWEEN 1600000000 AND 1600003600 AND time BETWEEN 1600000000 AND 1600003600 GROUP BY 1",
+			explanation.Statement)
+		assert.Equal(t, map[string]string{
+			"$__timeGroup(time, 5m, 0)": "floor(time/300)*300",
+			"$__timeFilter(time)":       "time BETWEEN 1600000000 AND 1600003600",
+		}, explanation.Macros)
+		assert.Equal(t, "time series frames of the time column time or ts, a series per numeric column, named by the "+
+			"metric column or the first text column, the missing points every 5m0s filled with 0", explanation.Frames)
+	})
+
+	t.Run("Should explain the global macros", func(t *testing.T) {
+		explanation := explain(t, map[string]interface{}{
+			"rawSql": "SELECT $__unixEpochFrom() AS start, '$__interval' AS interval",
+			"format": "table",
+		}, nil)
+
+		assert.Equal(t, "SELECT 1600000000 AS start, '1m' AS interval", explanation.Statement)
+		assert.Equal(t, map[string]string{"$__unixEpochFrom()": "1600000000", "$__interval": "1m"}, explanation.Macros)
+		assert.Equal(t, "a table frame with a field per column of the result", explanation.Frames)
+	})
+
+	t.Run("Should explain the audit comment of the statement", func(t *testing.T) {
+		info := queryaudit.Info{DashboardUID: "nErXDvCkzz", PanelID: 2, UserLogin: "admin", OrgID: 1}
+		explanation := explain(t, map[string]interface{}{"rawSql": "SELECT 1", "format": "table"}, info.Headers())
+
+		assert.Equal(t, "/* grafana dashboard=nErXDvCkzz panel=2 user=admin org=1 */\nSELECT 1", explanation.Statement)
+		assert.Nil(t, explanation.Macros)
+	})
+
+	t.Run("Should explain the errors of the macros", func(t *testing.T) {
+		explanation := explain(t, map[string]interface{}{"rawSql": "SELECT $__timeGroup(time) FROM metrics"}, nil)
+
+		assert.Equal(t, "macro timeGroup needs time column and interval", explanation.Error)
+		assert.Empty(t, explanation.Statement)
+	})
+}
@@ -0,0 +1,188 @@
+package sqleng
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/rds/rdsutils"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/oauthtoken"
+	"xorm.io/xorm"
+)
+
+// The IAM authentications of the data sources forwarding the identity of the users, the iamAuth of their JSON data.
+const (
+	// IAMAuthRDS connects the users to Amazon RDS with an IAM authentication token, signed with the credentials of
+	// the role of the iamRoleArn of the JSON data assumed with the OAuth token of the user. The database user is the
+	// login of the user.
+	IAMAuthRDS = "rds"
+	// IAMAuthCloudSQL connects the users to Google Cloud SQL with the IAM database authentication, the password being
+	// the OAuth access token of the user. The database user is the email of the user.
+	IAMAuthCloudSQL = "cloudsql"
+)
+
+// rdsRoleDuration is the duration of the credentials of the role assumed for the RDS authentication tokens, the
+// minimum of AWS STS. The tokens are valid for 15 minutes, and only needed to open the connections.
+const rdsRoleDuration = 15 * time.Minute
+
+var (
+	// ErrNoUserIdentity is returned when a data source forwards the identity of the users, and the user has no OAuth
+	// token, e.g. not logged in with OAuth.
+	ErrNoUserIdentity = errors.New("the data source connects with the identity of the user, log in with OAuth to query it")
+
+	invalidRoleSessionChars = regexp.MustCompile(`[^\w+=,.@-]`)
+)
+
+// IdentityCredentials are the credentials of a user connecting to a database with their cloud identity.
+type IdentityCredentials struct {
+	User     string
+	Password string
+}
+
+// IdentityConfiguration is the configuration of the connections of the users to a database with their cloud
+// identity.
+type IdentityConfiguration struct {
+	// DefaultPort is the port of the database when the URL of the data source has none.
+	DefaultPort int
+	// CloudSQLUser returns the database user of the email of a user for the Cloud SQL IAM authentication, the email
+	// when nil.
+	CloudSQLUser func(email string) string
+	// ConnectionString returns the connection string of a user.
+	ConnectionString func(creds IdentityCredentials) (string, error)
+}
+
+// IAMAuth returns the IAM authentication of a data source forwarding the identity of the users, or else "".
+func IAMAuth(ds *models.DataSource) string {
+	if ds.JsonData == nil {
+		return ""
+	}
+	switch iamAuth := ds.JsonData.Get("iamAuth").MustString(); iamAuth {
+	case IAMAuthRDS, IAMAuthCloudSQL:
+		return iamAuth
+	default:
+		return ""
+	}
+}
+
+// assumeRoleWithWebIdentity returns the credentials of a role assumed with the OAuth token of a user. It can be
+// stubbed by tests.
+var assumeRoleWithWebIdentity = func(ctx context.Context, region, roleARN, sessionName, token string) (*credentials.Credentials, error) {
+	sess, err := session.NewSession(&aws.Config{Region: aws.String(region)})
+	if err != nil {
+		return nil, err
+	}
+	out, err := sts.New(sess).AssumeRoleWithWebIdentityWithContext(ctx, &sts.AssumeRoleWithWebIdentityInput{
+		RoleArn:          aws.String(roleARN),
+		RoleSessionName:  aws.String(sessionName),
+		WebIdentityToken: aws.String(token),
+		DurationSeconds:  aws.Int64(int64(rdsRoleDuration.Seconds())),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewStaticCredentials(aws.StringValue(out.Credentials.AccessKeyId),
+		aws.StringValue(out.Credentials.SecretAccessKey), aws.StringValue(out.Credentials.SessionToken)), nil
+}
+
+// getOAuthToken returns the OAuth access token of a user, or else "". It can be stubbed by tests.
+var getOAuthToken = func(ctx context.Context, user *models.SignedInUser) string {
+	if token := oauthtoken.GetCurrentOAuthToken(ctx, user); token != nil {
+		return token.AccessToken
+	}
+	return ""
+}
+
+// identityCredentials exchanges the OAuth token of a user for short-lived database credentials of the user.
+func (c *IdentityConfiguration) identityCredentials(ctx context.Context, ds *models.DataSource,
+	user *models.SignedInUser) (IdentityCredentials, error) {
+	if user == nil {
+		return IdentityCredentials{}, ErrNoUserIdentity
+	}
+	token := getOAuthToken(ctx, user)
+	if token == "" {
+		return IdentityCredentials{}, ErrNoUserIdentity
+	}
+
+	switch IAMAuth(ds) {
+	case IAMAuthRDS:
+		region := ds.JsonData.Get("iamRegion").MustString()
+		roleARN := ds.JsonData.Get("iamRoleArn").MustString()
+		if region == "" || roleARN == "" {
+			return IdentityCredentials{}, fmt.Errorf("the RDS IAM authentication requires the region and the role ARN")
+		}
+
+		sessionName := invalidRoleSessionChars.ReplaceAllString("grafana-"+user.Login, "_")
+		if len(sessionName) > 64 {
+			sessionName = sessionName[:64]
+		}
+		creds, err := assumeRoleWithWebIdentity(ctx, region, roleARN, sessionName, token)
+		if err != nil {
+			return IdentityCredentials{}, fmt.Errorf("failed to assume the role %q with the identity of the user: %w",
+				roleARN, err)
+		}
+
+		endpoint := ds.Url
+		if _, _, err := net.SplitHostPort(endpoint); err != nil {
+			endpoint = net.JoinHostPort(endpoint, strconv.Itoa(c.DefaultPort))
+		}
+		authToken, err := rdsutils.BuildAuthToken(endpoint, region, user.Login, creds)
+		if err != nil {
+			return IdentityCredentials{}, fmt.Errorf("failed to build the RDS authentication token: %w", err)
+		}
+		return IdentityCredentials{User: user.Login, Password: authToken}, nil
+	case IAMAuthCloudSQL:
+		if user.Email == "" {
+			return IdentityCredentials{}, fmt.Errorf("the Cloud SQL IAM authentication requires the email of the user")
+		}
+		dbUser := user.Email
+		if c.CloudSQLUser != nil {
+			dbUser = c.CloudSQLUser(user.Email)
+		}
+		return IdentityCredentials{User: dbUser, Password: token}, nil
+	default:
+		return IdentityCredentials{}, fmt.Errorf("unsupported IAM authentication %q", IAMAuth(ds))
+	}
+}
+
+// usesIdentity returns whether the queries connect to the database with the identity of their user.
+func (e *dataPlugin) usesIdentity() bool {
+	return e.identity != nil && IAMAuth(e.datasource) != ""
+}
+
+// identityEngine returns a new engine connecting to the database with the identity of a user, to be closed after
+// their query. The credentials are short-lived, so they're exchanged for every query rather than pooled.
+func (e *dataPlugin) identityEngine(ctx context.Context, user *models.SignedInUser) (*xorm.Engine, error) {
+	creds, err := e.identity.identityCredentials(ctx, e.datasource, user)
+	if err != nil {
+		return nil, err
+	}
+	cnnstr, err := e.identity.ConnectionString(creds)
+	if err != nil {
+		return nil, err
+	}
+	engine, err := NewXormEngine(e.driverName, cnnstr)
+	if err != nil {
+		return nil, err
+	}
+	engine.SetMaxOpenConns(1)
+	engine.SetMaxIdleConns(0)
+	return engine, nil
+}
+
+// TrimEmailDomain returns the local part of an email, the database user of the Cloud SQL IAM users of MySQL.
+func TrimEmailDomain(email string) string {
+	if i := strings.Index(email, "@"); i >= 0 {
+		return email[:i]
+	}
+	return email
+}
@@ -0,0 +1,135 @@
+package sqleng
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdentityCredentials(t *testing.T) {
+	origGetOAuthToken, origAssumeRole := getOAuthToken, assumeRoleWithWebIdentity
+	t.Cleanup(func() {
+		getOAuthToken, assumeRoleWithWebIdentity = origGetOAuthToken, origAssumeRole
+	})
+	getOAuthToken = func(ctx context.Context, user *models.SignedInUser) string {
+		if user.Login == "anonymous" {
+			return ""
+		}
+		return "oauth-token"
+	}
+	var assumed []string
+	assumeRoleWithWebIdentity = func(ctx context.Context, region, roleARN, sessionName, token string) (*credentials.Credentials, error) {
+		assumed = []string{region, roleARN, sessionName, token}
+		return credentials.NewStaticCredentials("AKID", "SECRET", "SESSION"), nil
+	}
+
+	config := &IdentityConfiguration{DefaultPort: 3306, CloudSQLUser: TrimEmailDomain}
+	user := &models.SignedInUser{Login: "jane doe", Email: "jane@example.com"}
+	newDS := func(jsonData map[string]interface{}) *models.DataSource {
+		return &models.DataSource{Url: "db.example.com", JsonData: simplejson.NewFromAny(jsonData)}
+	}
+
+	t.Run("Should return the IAM authentication of a data source", func(t *testing.T) {
+		assert.Equal(t, IAMAuthRDS, IAMAuth(newDS(map[string]interface{}{"iamAuth": "rds"})))
+		assert.Empty(t, IAMAuth(newDS(map[string]interface{}{"iamAuth": "kerberos"})))
+		assert.Empty(t, IAMAuth(&models.DataSource{}))
+	})
+
+	t.Run("Should exchange the OAuth token for an RDS authentication token", func(t *testing.T) {
+		ds := newDS(map[string]interface{}{
+			"iamAuth":    "rds",
+			"iamRegion":  "eu-west-1",
+			"iamRoleArn": "arn:aws:iam::123456789012:role/grafana",
+		})
+		creds, err := config.identityCredentials(context.Background(), ds, user)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"eu-west-1", "arn:aws:iam::123456789012:role/grafana", "grafana-jane_doe", "oauth-token"}, assumed)
+		assert.Equal(t, "jane doe", creds.User)
+		assert.Contains(t, creds.Password, "db.example.com:3306?Action=connect")
+		assert.Contains(t, creds.Password, "DBUser=jane%20doe")
+		assert.Contains(t, creds.Password, "X-Amz-Security-Token=SESSION")
+	})
+
+	t.Run("Should require the region and the role of the RDS authentication", func(t *testing.T) {
+		_, err := config.identityCredentials(context.Background(), newDS(map[string]interface{}{"iamAuth": "rds"}), user)
+		require.Error(t, err)
+	})
+
+	t.Run("Should connect to Cloud SQL with the OAuth token", func(t *testing.T) {
+		creds, err := config.identityCredentials(context.Background(), newDS(map[string]interface{}{"iamAuth": "cloudsql"}), user)
+		require.NoError(t, err)
+		assert.Equal(t, IdentityCredentials{User: "jane", Password: "oauth-token"}, creds)
+	})
+
+	t.Run("Should require an OAuth token", func(t *testing.T) {
+		ds := newDS(map[string]interface{}{"iamAuth": "cloudsql"})
+		_, err := config.identityCredentials(context.Background(), ds, &models.SignedInUser{Login: "anonymous"})
+		assert.Equal(t, ErrNoUserIdentity, err)
+		_, err = config.identityCredentials(context.Background(), ds, nil)
+		assert.Equal(t, ErrNoUserIdentity, err)
+	})
+
+	t.Run("Should query with a connection of the user", func(t *testing.T) {
+		ds := &models.DataSource{Id: 84, JsonData: simplejson.NewFromAny(map[string]interface{}{"iamAuth": "cloudsql"})}
+		path := filepath.Join(t.TempDir(), "identity.db")
+		var connected []IdentityCredentials
+		plugin, err := NewDataPlugin(DataPluginConfiguration{
+			DriverName: "sqlite3",
+			Datasource: ds,
+			// the shared engine has no credentials
+			ConnectionString: filepath.Join(t.TempDir(), "missing", "shared.db"),
+			Identity: &IdentityConfiguration{
+				ConnectionString: func(creds IdentityCredentials) (string, error) {
+					connected = append(connected, creds)
+					return path, nil
+				},
+			},
+		}, &integerResultTransformer{}, testMacroEngine{}, log.New("test"))
+		require.NoError(t, err)
+		engine, err := NewXormEngine("sqlite3", path)
+		require.NoError(t, err)
+		_, err = engine.Exec("CREATE TABLE numbers (x INTEGER)")
+		require.NoError(t, err)
+		_, err = engine.Exec("INSERT INTO numbers VALUES (1)")
+		require.NoError(t, err)
+		require.NoError(t, engine.Close())
+		t.Cleanup(func() {
+			engineCache.Lock()
+			defer engineCache.Unlock()
+			delete(engineCache.cache, ds.Id)
+			delete(engineCache.versions, ds.Id)
+			delete(engineCache.datasources, ds.Id)
+		})
+
+		query := func(user *models.SignedInUser) plugins.DataQueryResult {
+			timeRange := plugins.NewDataTimeRange("now-1h", "now")
+			resp, err := plugin.DataQuery(context.Background(), ds, plugins.DataQuery{
+				TimeRange: &timeRange,
+				Queries: []plugins.DataSubQuery{{
+					RefID:      "A",
+					Model:      simplejson.NewFromAny(map[string]interface{}{"rawSql": "SELECT x FROM numbers", "format": "table"}),
+					DataSource: ds,
+				}},
+				User: user,
+			})
+			require.NoError(t, err)
+			return resp.Results["A"]
+		}
+
+		result := query(user)
+		require.NoError(t, result.Error)
+		assert.Equal(t, []IdentityCredentials{{User: "jane@example.com", Password: "oauth-token"}}, connected)
+
+		result = query(&models.SignedInUser{Login: "anonymous"})
+		assert.Equal(t, ErrNoUserIdentity, result.Error)
+		assert.Len(t, connected, 1)
+	})
+}
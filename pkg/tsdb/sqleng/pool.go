@@ -0,0 +1,165 @@
+package sqleng
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/metrics/metricutil"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"xorm.io/xorm"
+)
+
+// ErrPoolNotFound is returned when a data source has no connection pool, since it hasn't been queried yet.
+var ErrPoolNotFound = errors.New("data source has no connection pool")
+
+// PoolSettings are the connection pool settings of a data source, from its jsonData.
+type PoolSettings struct {
+	MaxOpenConns int `json:"maxOpenConns"`
+	MaxIdleConns int `json:"maxIdleConns"`
+	// ConnMaxLifetime is the maximum number of seconds a connection is reused
+	ConnMaxLifetime int `json:"connMaxLifetime"`
+	// ConnMaxIdleTime is the maximum number of seconds a connection is idle before it's closed
+	ConnMaxIdleTime int `json:"connMaxIdleTime"`
+}
+
+// PoolStats are the statistics of the connection pool of a data source.
+type PoolStats struct {
+	MaxOpenConnections int `json:"maxOpenConnections"`
+
+	OpenConnections int `json:"openConnections"`
+	InUse           int `json:"inUse"`
+	Idle            int `json:"idle"`
+
+	WaitCount         int64 `json:"waitCount"`
+	WaitDurationMs    int64 `json:"waitDurationMs"`
+	MaxIdleClosed     int64 `json:"maxIdleClosed"`
+	MaxIdleTimeClosed int64 `json:"maxIdleTimeClosed"`
+	MaxLifetimeClosed int64 `json:"maxLifetimeClosed"`
+}
+
+// PoolSettingsFromJSONData returns the connection pool settings of the jsonData of a data source, negative values
+// are ignored in favor of the defaults.
+func PoolSettingsFromJSONData(jsonData *simplejson.Json) PoolSettings {
+	get := func(key string, def int) int {
+		if value := jsonData.Get(key).MustInt(def); value >= 0 {
+			return value
+		}
+		return def
+	}
+
+	return PoolSettings{
+		MaxOpenConns:    get("maxOpenConns", 0),
+		MaxIdleConns:    get("maxIdleConns", 2),
+		ConnMaxLifetime: get("connMaxLifetime", 14400),
+		ConnMaxIdleTime: get("connMaxIdleTime", 0),
+	}
+}
+
+func (s PoolSettings) apply(engine *xorm.Engine) {
+	engine.SetMaxOpenConns(s.MaxOpenConns)
+	engine.SetMaxIdleConns(s.MaxIdleConns)
+	engine.SetConnMaxLifetime(time.Duration(s.ConnMaxLifetime) * time.Second)
+	engine.DB().SetConnMaxIdleTime(time.Duration(s.ConnMaxIdleTime) * time.Second)
+}
+
+// GetPoolStats returns the statistics of the connection pool of a data source.
+func GetPoolStats(ds *models.DataSource) (PoolStats, error) {
+	engineCache.Lock()
+	defer engineCache.Unlock()
+
+	engine, ok := engineCache.cache[ds.Id]
+	if !ok {
+		return PoolStats{}, ErrPoolNotFound
+	}
+
+	stats := engine.DB().Stats()
+	return PoolStats{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDurationMs:     stats.WaitDuration.Milliseconds(),
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+	}, nil
+}
+
+var poolLabels = []string{"datasource_id", "datasource", "datasource_type"}
+
+func newPoolDesc(name, help string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName("grafana", "datasource_sql_pool", name), help, poolLabels, nil)
+}
+
+// poolCollector collects the statistics of the connection pools of the SQL data sources.
+type poolCollector struct {
+	maxOpen           *prometheus.Desc
+	open              *prometheus.Desc
+	inUse             *prometheus.Desc
+	idle              *prometheus.Desc
+	waitCount         *prometheus.Desc
+	waitDuration      *prometheus.Desc
+	maxIdleClosed     *prometheus.Desc
+	maxIdleTimeClosed *prometheus.Desc
+	maxLifetimeClosed *prometheus.Desc
+}
+
+func newPoolCollector() *poolCollector {
+	return &poolCollector{
+		maxOpen:           newPoolDesc("max_open_connections", "Maximum number of open connections to the database"),
+		open:              newPoolDesc("open_connections", "The number of established connections both in use and idle"),
+		inUse:             newPoolDesc("in_use_connections", "The number of connections currently in use"),
+		idle:              newPoolDesc("idle_connections", "The number of idle connections"),
+		waitCount:         newPoolDesc("wait_count_total", "The total number of connections waited for"),
+		waitDuration:      newPoolDesc("wait_duration_seconds_total", "The total time blocked waiting for a new connection"),
+		maxIdleClosed:     newPoolDesc("max_idle_closed_total", "The total number of connections closed due to the max idle connections"),
+		maxIdleTimeClosed: newPoolDesc("max_idle_time_closed_total", "The total number of connections closed due to the max idle time"),
+		maxLifetimeClosed: newPoolDesc("max_lifetime_closed_total", "The total number of connections closed due to the max lifetime"),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.maxOpen
+	ch <- c.open
+	ch <- c.inUse
+	ch <- c.idle
+	ch <- c.waitCount
+	ch <- c.waitDuration
+	ch <- c.maxIdleClosed
+	ch <- c.maxIdleTimeClosed
+	ch <- c.maxLifetimeClosed
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	engineCache.Lock()
+	defer engineCache.Unlock()
+
+	for id, engine := range engineCache.cache {
+		ds := engineCache.datasources[id]
+		// the same data source label as the metrics of the requests to data sources
+		name, err := metricutil.SanitizeLabelName(ds.Name)
+		if err != nil {
+			name = ds.Name
+		}
+		labels := []string{strconv.FormatInt(id, 10), name, ds.Type}
+		stats := engine.DB().Stats()
+
+		ch <- prometheus.MustNewConstMetric(c.maxOpen, prometheus.GaugeValue, float64(stats.MaxOpenConnections), labels...)
+		ch <- prometheus.MustNewConstMetric(c.open, prometheus.GaugeValue, float64(stats.OpenConnections), labels...)
+		ch <- prometheus.MustNewConstMetric(c.inUse, prometheus.GaugeValue, float64(stats.InUse), labels...)
+		ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stats.Idle), labels...)
+		ch <- prometheus.MustNewConstMetric(c.waitCount, prometheus.CounterValue, float64(stats.WaitCount), labels...)
+		ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.CounterValue, stats.WaitDuration.Seconds(), labels...)
+		ch <- prometheus.MustNewConstMetric(c.maxIdleClosed, prometheus.CounterValue, float64(stats.MaxIdleClosed), labels...)
+		ch <- prometheus.MustNewConstMetric(c.maxIdleTimeClosed, prometheus.CounterValue, float64(stats.MaxIdleTimeClosed), labels...)
+		ch <- prometheus.MustNewConstMetric(c.maxLifetimeClosed, prometheus.CounterValue, float64(stats.MaxLifetimeClosed), labels...)
+	}
+}
+
+func init() {
+	prometheus.MustRegister(newPoolCollector())
+}
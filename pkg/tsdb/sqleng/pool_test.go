@@ -0,0 +1,88 @@
+package sqleng
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"xorm.io/xorm"
+)
+
+func TestPoolSettingsFromJSONData(t *testing.T) {
+	t.Run("Should default missing settings", func(t *testing.T) {
+		require.Equal(t, PoolSettings{
+			MaxOpenConns:    0,
+			MaxIdleConns:    2,
+			ConnMaxLifetime: 14400,
+			ConnMaxIdleTime: 0,
+		}, PoolSettingsFromJSONData(simplejson.New()))
+	})
+
+	t.Run("Should read settings and ignore negative values", func(t *testing.T) {
+		jsonData := simplejson.NewFromAny(map[string]interface{}{
+			"maxOpenConns":    10,
+			"maxIdleConns":    -1,
+			"connMaxLifetime": 60,
+			"connMaxIdleTime": 30,
+		})
+
+		require.Equal(t, PoolSettings{
+			MaxOpenConns:    10,
+			MaxIdleConns:    2,
+			ConnMaxLifetime: 60,
+			ConnMaxIdleTime: 30,
+		}, PoolSettingsFromJSONData(jsonData))
+	})
+}
+
+func TestPoolStats(t *testing.T) {
+	origXormEngine := NewXormEngine
+	t.Cleanup(func() {
+		NewXormEngine = origXormEngine
+	})
+	NewXormEngine = func(string, string) (*xorm.Engine, error) {
+		return xorm.NewEngine("sqlite3", ":memory:")
+	}
+
+	ds := &models.DataSource{
+		Id:       7001,
+		Name:     "Reporting DB",
+		Type:     "mysql",
+		JsonData: simplejson.NewFromAny(map[string]interface{}{"maxOpenConns": 5}),
+	}
+
+	_, err := GetPoolStats(ds)
+	require.ErrorIs(t, err, ErrPoolNotFound)
+
+	_, err = NewDataPlugin(DataPluginConfiguration{DriverName: "sqlite3", Datasource: ds}, nil, nil, log.New("test"))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		engineCache.Lock()
+		defer engineCache.Unlock()
+		delete(engineCache.cache, ds.Id)
+		delete(engineCache.versions, ds.Id)
+		delete(engineCache.datasources, ds.Id)
+	})
+
+	t.Run("Should return the statistics of the pool", func(t *testing.T) {
+		stats, err := GetPoolStats(ds)
+		require.NoError(t, err)
+		require.Equal(t, 5, stats.MaxOpenConnections)
+	})
+
+	t.Run("Should collect the statistics of the pool", func(t *testing.T) {
+		expected := `
+# HELP grafana_datasource_sql_pool_max_open_connections Maximum number of open connections to the database
+# TYPE grafana_datasource_sql_pool_max_open_connections gauge
+grafana_datasource_sql_pool_max_open_connections{datasource="Reporting_DB",datasource_id="7001",datasource_type="mysql"} 5
+`
+		err := testutil.CollectAndCompare(newPoolCollector(), strings.NewReader(expected),
+			"grafana_datasource_sql_pool_max_open_connections")
+		require.NoError(t, err)
+	})
+}
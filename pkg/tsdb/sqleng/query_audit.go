@@ -0,0 +1,49 @@
+package sqleng
+
+import (
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/queryaudit"
+	"xorm.io/core"
+)
+
+// auditedQuery returns the SQL of the query of an audited request, prefixed by the comment of its audit info when the
+// application name of the data source can't be set for the query, see queryaudit.FromHeaders.
+// nolint: staticcheck // plugins.DataQuery deprecated
+func (e *dataPlugin) auditedQuery(queryContext plugins.DataQuery, rawSQL string) string {
+	info, ok := queryaudit.FromHeaders(queryContext.Headers)
+	if !ok || e.setApplicationName != nil {
+		return rawSQL
+	}
+	return info.Comment() + "\n" + rawSQL
+}
+
+// query runs the query on the database, in a transaction setting the application name of the audit info of the
+// request when audited and supported by the data source. The returned function must be called after the rows are
+// closed.
+// nolint: staticcheck // plugins.DataQuery deprecated
+func (e *dataPlugin) query(db *core.DB, queryContext plugins.DataQuery, rawSQL string) (*core.Rows, func(), error) {
+	info, ok := queryaudit.FromHeaders(queryContext.Headers)
+	if !ok || e.setApplicationName == nil {
+		rows, err := db.Query(rawSQL)
+		return rows, func() {}, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := tx.Exec(e.setApplicationName(info.Name())); err != nil {
+		_ = tx.Rollback()
+		return nil, nil, err
+	}
+	rows, err := tx.Query(rawSQL)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, nil, err
+	}
+	return rows, func() {
+		if err := tx.Commit(); err != nil {
+			e.log.Warn("Failed to commit the transaction of an audited query", "err", err)
+		}
+	}, nil
+}
@@ -0,0 +1,94 @@
+package sqleng
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/queryaudit"
+	"github.com/stretchr/testify/require"
+	"xorm.io/xorm"
+)
+
+func TestQueryAudit(t *testing.T) {
+	info := queryaudit.Info{DashboardUID: "nErXDvCkzz", PanelID: 2, UserLogin: "admin", OrgID: 1}
+
+	newPlugin := func(t *testing.T, ds *models.DataSource, setApplicationName func(name string) string) (plugins.DataPlugin, *xorm.Engine) {
+		path := filepath.Join(t.TempDir(), "audit.db")
+		plugin, err := NewDataPlugin(DataPluginConfiguration{
+			DriverName:         "sqlite3",
+			ConnectionString:   path,
+			Datasource:         ds,
+			SetApplicationName: setApplicationName,
+		}, &integerResultTransformer{}, testMacroEngine{}, log.New("test"))
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			engineCache.Lock()
+			defer engineCache.Unlock()
+			delete(engineCache.cache, ds.Id)
+			delete(engineCache.versions, ds.Id)
+			delete(engineCache.datasources, ds.Id)
+		})
+		engine, err := GetEngine(DataPluginConfiguration{Datasource: ds})
+		require.NoError(t, err)
+		_, err = engine.Exec("CREATE TABLE audit (name TEXT)")
+		require.NoError(t, err)
+		_, err = engine.Exec("CREATE TABLE numbers (x INTEGER)")
+		require.NoError(t, err)
+		_, err = engine.Exec("INSERT INTO numbers VALUES (1)")
+		require.NoError(t, err)
+		return plugin, engine
+	}
+
+	query := func(t *testing.T, plugin plugins.DataPlugin, ds *models.DataSource, headers map[string]string) plugins.DataQueryResult {
+		timeRange := plugins.NewDataTimeRange("now-1h", "now")
+		resp, err := plugin.DataQuery(context.Background(), ds, plugins.DataQuery{
+			TimeRange: &timeRange,
+			Queries: []plugins.DataSubQuery{{
+				RefID:      "A",
+				Model:      simplejson.NewFromAny(map[string]interface{}{"rawSql": "SELECT x FROM numbers", "format": "table"}),
+				DataSource: ds,
+			}},
+			Headers: headers,
+			User:    &models.SignedInUser{Login: "admin"},
+		})
+		require.NoError(t, err)
+		return resp.Results["A"]
+	}
+
+	t.Run("Should comment the audited queries", func(t *testing.T) {
+		ds := &models.DataSource{Id: 7201, JsonData: simplejson.New()}
+		plugin, _ := newPlugin(t, ds, nil)
+		p := plugin.(*dataPlugin)
+
+		require.Equal(t, "SELECT 1", p.auditedQuery(plugins.DataQuery{}, "SELECT 1"))
+		require.Equal(t, "/* grafana dashboard=nErXDvCkzz panel=2 user=admin org=1 */\nSELECT 1",
+			p.auditedQuery(plugins.DataQuery{Headers: info.Headers()}, "SELECT 1"))
+
+		result := query(t, plugin, ds, info.Headers())
+		require.NoError(t, result.Error)
+		frames, err := result.Dataframes.Decoded()
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		require.Equal(t, "SELECT x FROM numbers", frames[0].Meta.ExecutedQueryString)
+	})
+
+	t.Run("Should set the application name of the audited queries", func(t *testing.T) {
+		ds := &models.DataSource{Id: 7202, JsonData: simplejson.New()}
+		plugin, engine := newPlugin(t, ds, func(name string) string {
+			return "INSERT INTO audit VALUES ('" + name + "')"
+		})
+
+		require.NoError(t, query(t, plugin, ds, nil).Error)
+		require.NoError(t, query(t, plugin, ds, info.Headers()).Error)
+		require.Equal(t, "SELECT 1", plugin.(*dataPlugin).auditedQuery(plugins.DataQuery{Headers: info.Headers()}, "SELECT 1"))
+
+		names, err := engine.QueryString("SELECT name FROM audit")
+		require.NoError(t, err)
+		require.Equal(t, []map[string]string{{"name": info.Name()}}, names)
+	})
+}
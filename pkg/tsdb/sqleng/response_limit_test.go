@@ -0,0 +1,150 @@
+package sqleng
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/tsdb/responselimit"
+	"github.com/stretchr/testify/require"
+	"xorm.io/xorm"
+)
+
+// countingRowsDriver is a database driver returning countingRowsTotal rows for every query, and counting the rows
+// read in countingRowsRead.
+type countingRowsDriver struct{}
+
+const countingRowsTotal = 1000000
+
+var countingRowsRead int
+
+func (countingRowsDriver) Open(string) (driver.Conn, error) { return countingRowsConn{}, nil }
+
+type countingRowsConn struct{}
+
+func (countingRowsConn) Prepare(string) (driver.Stmt, error) { return countingRowsStmt{}, nil }
+func (countingRowsConn) Close() error                        { return nil }
+func (countingRowsConn) Begin() (driver.Tx, error)           { return nil, driver.ErrSkip }
+
+type countingRowsStmt struct{}
+
+func (countingRowsStmt) Close() error                               { return nil }
+func (countingRowsStmt) NumInput() int                              { return -1 }
+func (countingRowsStmt) Exec([]driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+func (countingRowsStmt) Query([]driver.Value) (driver.Rows, error) {
+	countingRowsRead = 0
+	return countingRows{}, nil
+}
+
+type countingRows struct{}
+
+func (countingRows) Columns() []string { return []string{"x"} }
+func (countingRows) Close() error      { return nil }
+func (countingRows) Next(dest []driver.Value) error {
+	if countingRowsRead == countingRowsTotal {
+		return io.EOF
+	}
+	countingRowsRead++
+	dest[0] = int64(countingRowsRead)
+	return nil
+}
+
+func init() {
+	sql.Register("countingrows", countingRowsDriver{})
+}
+
+func TestFrameFromRows(t *testing.T) {
+	db, err := sql.Open("countingrows", "")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, db.Close())
+	})
+
+	t.Run("Should stop reading the rows at the limits of the query", func(t *testing.T) {
+		rows, err := db.Query("SELECT x")
+		require.NoError(t, err)
+		defer func() { require.NoError(t, rows.Close()) }()
+
+		limiter := responselimit.New(responselimit.Limits{MaxRows: 100})
+		frame, err := frameFromRows(rows, limiter)
+		require.NoError(t, err)
+
+		require.Equal(t, 100, frame.Rows())
+		require.Equal(t, []data.Notice{limiter.Notice()}, frame.Meta.Notices)
+		// the row exceeding the limit is the only one read past it
+		require.Equal(t, 101, countingRowsRead)
+	})
+
+	t.Run("Should read the rows up to the row limit without limits", func(t *testing.T) {
+		rows, err := db.Query("SELECT x")
+		require.NoError(t, err)
+		defer func() { require.NoError(t, rows.Close()) }()
+
+		frame, err := frameFromRows(rows, nil)
+		require.NoError(t, err)
+
+		require.Equal(t, rowLimit, frame.Rows())
+		require.Nil(t, frame.Meta)
+		require.Equal(t, countingRowsTotal, countingRowsRead)
+	})
+}
+
+func TestDataQueryResponseLimits(t *testing.T) {
+	ds := &models.DataSource{Id: 7201, Name: "Warehouse", Type: "postgres", JsonData: simplejson.New()}
+	path := filepath.Join(t.TempDir(), "numbers.db")
+	engine, err := xorm.NewEngine("sqlite3", path)
+	require.NoError(t, err)
+	_, err = engine.Exec("CREATE TABLE numbers (x INTEGER)")
+	require.NoError(t, err)
+	_, err = engine.Exec("WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c WHERE x < 1000) " +
+		"INSERT INTO numbers SELECT x FROM c")
+	require.NoError(t, err)
+	require.NoError(t, engine.Close())
+
+	plugin, err := NewDataPlugin(DataPluginConfiguration{
+		DriverName:       "sqlite3",
+		ConnectionString: path,
+		Datasource:       ds,
+	}, &integerResultTransformer{}, testMacroEngine{}, log.New("test"))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		engineCache.Lock()
+		defer engineCache.Unlock()
+		delete(engineCache.cache, ds.Id)
+		delete(engineCache.versions, ds.Id)
+		delete(engineCache.datasources, ds.Id)
+	})
+
+	ctx := responselimit.WithLimits(context.Background(), responselimit.Limits{MaxRows: 100})
+	timeRange := plugins.NewDataTimeRange("now-1h", "now")
+	resp, err := plugin.DataQuery(ctx, ds, plugins.DataQuery{
+		TimeRange: &timeRange,
+		Queries: []plugins.DataSubQuery{{
+			RefID:      "A",
+			Model:      simplejson.NewFromAny(map[string]interface{}{"rawSql": "SELECT x FROM numbers", "format": "table"}),
+			DataSource: ds,
+		}},
+	})
+	require.NoError(t, err)
+
+	result := resp.Results["A"]
+	require.NoError(t, result.Error)
+	frames, err := result.Dataframes.Decoded()
+	require.NoError(t, err)
+	require.Len(t, frames, 1)
+	require.Equal(t, 100, frames[0].Rows())
+	require.Equal(t, "SELECT x FROM numbers", frames[0].Meta.ExecutedQueryString)
+	require.Equal(t, []data.Notice{{
+		Severity: data.NoticeSeverityWarning,
+		Text:     "The results were truncated to the max 100 rows of the query",
+	}}, frames[0].Meta.Notices)
+	require.Equal(t, "100 rows", responselimit.ForQuery(ctx, "A").Exceeded())
+}
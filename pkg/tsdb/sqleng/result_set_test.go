@@ -0,0 +1,92 @@
+package sqleng
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// resultSetsDriver is a database driver returning the result sets of resultSetsRows for every query.
+type resultSetsDriver struct{}
+
+func (resultSetsDriver) Open(string) (driver.Conn, error) { return resultSetsConn{}, nil }
+
+type resultSetsConn struct{}
+
+func (resultSetsConn) Prepare(string) (driver.Stmt, error) { return resultSetsStmt{}, nil }
+func (resultSetsConn) Close() error                        { return nil }
+func (resultSetsConn) Begin() (driver.Tx, error)           { return nil, driver.ErrSkip }
+
+type resultSetsStmt struct{}
+
+func (resultSetsStmt) Close() error                               { return nil }
+func (resultSetsStmt) NumInput() int                              { return -1 }
+func (resultSetsStmt) Exec([]driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+func (resultSetsStmt) Query([]driver.Value) (driver.Rows, error) {
+	// like a stored procedure setting a variable, returning two result sets and its status
+	return &resultSetsRows{sets: [][]string{nil, {"first"}, {"second"}, nil}}, nil
+}
+
+type resultSetsRows struct {
+	sets    [][]string
+	current int
+}
+
+func (r *resultSetsRows) Columns() []string         { return r.sets[r.current] }
+func (r *resultSetsRows) Close() error              { return nil }
+func (r *resultSetsRows) Next([]driver.Value) error { return io.EOF }
+func (r *resultSetsRows) HasNextResultSet() bool    { return r.current < len(r.sets)-1 }
+func (r *resultSetsRows) NextResultSet() error {
+	if !r.HasNextResultSet() {
+		return io.EOF
+	}
+	r.current++
+	return nil
+}
+
+func init() {
+	sql.Register("resultsets", resultSetsDriver{})
+}
+
+func TestSelectResultSet(t *testing.T) {
+	db, err := sql.Open("resultsets", "")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, db.Close())
+	})
+
+	query := func(t *testing.T) *sql.Rows {
+		rows, err := db.Query("CALL report()")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			require.NoError(t, rows.Close())
+		})
+		return rows
+	}
+
+	t.Run("Should select the first result set with columns by default", func(t *testing.T) {
+		rows := query(t)
+		require.NoError(t, selectResultSet(rows, 0))
+
+		columns, err := rows.Columns()
+		require.NoError(t, err)
+		require.Equal(t, []string{"first"}, columns)
+	})
+
+	t.Run("Should select the result set at the index", func(t *testing.T) {
+		rows := query(t)
+		require.NoError(t, selectResultSet(rows, 1))
+
+		columns, err := rows.Columns()
+		require.NoError(t, err)
+		require.Equal(t, []string{"second"}, columns)
+	})
+
+	t.Run("Should fail when the result set doesn't exist", func(t *testing.T) {
+		rows := query(t)
+		require.EqualError(t, selectResultSet(rows, 2), "result set 2 not found, the query returned 2 result sets")
+	})
+}
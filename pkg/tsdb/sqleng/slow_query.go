@@ -0,0 +1,197 @@
+package sqleng
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/plugins"
+)
+
+const (
+	// slowQueryLogSize is the number of slow queries kept, the oldest are dropped first.
+	slowQueryLogSize = 100
+	// explainTimeout is the maximum time the plan of a slow query is explained for.
+	explainTimeout = 10 * time.Second
+	// maxPlanRows is the maximum number of rows of a plan kept.
+	maxPlanRows = 200
+)
+
+// SlowQuerySettings are the slow query log settings of a data source, from its jsonData.
+type SlowQuerySettings struct {
+	// ThresholdMs is the number of milliseconds above which a query is logged, zero disables the log
+	ThresholdMs int64 `json:"slowQueryThresholdMs"`
+	// Explain explains the plan of the logged queries, which runs an EXPLAIN statement on the database
+	Explain bool `json:"slowQueryExplain"`
+}
+
+// SlowQuerySettingsFromJSONData returns the slow query log settings of the jsonData of a data source.
+func SlowQuerySettingsFromJSONData(jsonData *simplejson.Json) SlowQuerySettings {
+	settings := SlowQuerySettings{
+		ThresholdMs: jsonData.Get("slowQueryThresholdMs").MustInt64(0),
+		Explain:     jsonData.Get("slowQueryExplain").MustBool(false),
+	}
+	if settings.ThresholdMs < 0 {
+		settings.ThresholdMs = 0
+	}
+	return settings
+}
+
+// SlowQuery is a query of a SQL data source which took longer than its threshold.
+type SlowQuery struct {
+	DatasourceID   int64  `json:"datasourceId"`
+	DatasourceName string `json:"datasourceName"`
+	DatasourceType string `json:"datasourceType"`
+	OrgID          int64  `json:"orgId"`
+	// DashboardID and PanelID are zero when the query isn't from a dashboard
+	DashboardID int64     `json:"dashboardId"`
+	PanelID     int64     `json:"panelId"`
+	User        string    `json:"user"`
+	Query       string    `json:"query"`
+	Time        time.Time `json:"time"`
+	DurationMs  int64     `json:"durationMs"`
+	Error       string    `json:"error,omitempty"`
+	// Plan is the output of the EXPLAIN statement of the query, if enabled
+	Plan string `json:"plan,omitempty"`
+}
+
+type slowQueryLog struct {
+	sync.Mutex
+	// queries is a ring buffer of the slow queries, next is the index of the next one
+	queries []*SlowQuery
+	next    int
+}
+
+var slowQueries = slowQueryLog{}
+
+func (l *slowQueryLog) add(query *SlowQuery) {
+	l.Lock()
+	defer l.Unlock()
+
+	if len(l.queries) < slowQueryLogSize {
+		l.queries = append(l.queries, query)
+	} else {
+		l.queries[l.next] = query
+	}
+	l.next = (l.next + 1) % slowQueryLogSize
+}
+
+func (l *slowQueryLog) setPlan(query *SlowQuery, plan string) {
+	l.Lock()
+	defer l.Unlock()
+	query.Plan = plan
+}
+
+// GetSlowQueries returns the slow queries of the SQL data sources, the latest first.
+func GetSlowQueries() []SlowQuery {
+	slowQueries.Lock()
+	defer slowQueries.Unlock()
+
+	result := make([]SlowQuery, 0, len(slowQueries.queries))
+	for i := 1; i <= len(slowQueries.queries); i++ {
+		index := (slowQueries.next - i + len(slowQueries.queries)) % len(slowQueries.queries)
+		result = append(result, *slowQueries.queries[index])
+	}
+	return result
+}
+
+// logSlowQuery logs the query if it took longer than the threshold of its data source, explaining its plan in
+// the background if enabled.
+// nolint: staticcheck // plugins.DataQuery deprecated
+func (e *dataPlugin) logSlowQuery(queryContext plugins.DataQuery, interpolatedQuery string, duration time.Duration,
+	queryErr error) {
+	if e.slowQuery.ThresholdMs == 0 || duration.Milliseconds() < e.slowQuery.ThresholdMs {
+		return
+	}
+
+	query := &SlowQuery{
+		DatasourceID:   e.datasource.Id,
+		DatasourceName: e.datasource.Name,
+		DatasourceType: e.datasource.Type,
+		OrgID:          e.datasource.OrgId,
+		DashboardID:    headerInt64(queryContext.Headers, "X-Dashboard-Id"),
+		PanelID:        headerInt64(queryContext.Headers, "X-Panel-Id"),
+		Query:          interpolatedQuery,
+		Time:           time.Now(),
+		DurationMs:     duration.Milliseconds(),
+	}
+	if queryContext.User != nil {
+		query.User = queryContext.User.Login
+	}
+	if queryErr != nil {
+		query.Error = queryErr.Error()
+	}
+
+	e.log.Warn("Slow query", "datasource", query.DatasourceName, "dashboardId", query.DashboardID,
+		"panelId", query.PanelID, "duration", duration)
+	slowQueries.add(query)
+
+	// the shared engine of the data sources connecting with the identity of the users has no credentials
+	if e.slowQuery.Explain && e.explainQuery != nil && !e.usesIdentity() {
+		go func() {
+			plan, err := e.explain(e.explainQuery(interpolatedQuery))
+			if err != nil {
+				e.log.Warn("Failed to explain slow query", "datasource", query.DatasourceName, "err", err)
+				return
+			}
+			slowQueries.setPlan(query, plan)
+		}()
+	}
+}
+
+// explain runs the EXPLAIN statement of a query and returns its rows as tab separated lines, after a line with
+// its columns.
+func (e *dataPlugin) explain(statement string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), explainTimeout)
+	defer cancel()
+
+	rows, err := e.engine.DB().QueryContext(ctx, statement)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			e.log.Warn("Failed to close rows", "err", err)
+		}
+	}()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+	lines := []string{strings.Join(columns, "\t")}
+
+	values := make([]sql.NullString, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() && len(lines) <= maxPlanRows {
+		if err := rows.Scan(dest...); err != nil {
+			return "", err
+		}
+		fields := make([]string, len(values))
+		for i, value := range values {
+			fields[i] = value.String
+		}
+		lines = append(lines, strings.Join(fields, "\t"))
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("failed to read the plan: %w", err)
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+func headerInt64(headers map[string]string, name string) int64 {
+	value, err := strconv.ParseInt(headers[name], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return value
+}
@@ -0,0 +1,172 @@
+package sqleng
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/stretchr/testify/require"
+	"xorm.io/xorm"
+)
+
+type testMacroEngine struct{}
+
+func (testMacroEngine) Interpolate(_ plugins.DataSubQuery, _ plugins.DataTimeRange, sql string) (string, error) {
+	return sql, nil
+}
+
+// integerResultTransformer converts the integer columns of sqlite, which has no scan types before the rows are read.
+type integerResultTransformer struct {
+	testQueryResultTransformer
+}
+
+func (t *integerResultTransformer) GetConverterList() []sqlutil.StringConverter {
+	return []sqlutil.StringConverter{{
+		Name:          "integer",
+		InputScanKind: reflect.Interface,
+		InputTypeName: "INTEGER",
+		Replacer: &sqlutil.StringFieldReplacer{
+			OutputFieldType: data.FieldTypeNullableString,
+			ReplaceFunc: func(in *string) (interface{}, error) {
+				return in, nil
+			},
+		},
+	}}
+}
+
+func TestSlowQuerySettingsFromJSONData(t *testing.T) {
+	require.Equal(t, SlowQuerySettings{}, SlowQuerySettingsFromJSONData(simplejson.New()))
+	require.Equal(t, SlowQuerySettings{ThresholdMs: 500, Explain: true}, SlowQuerySettingsFromJSONData(
+		simplejson.NewFromAny(map[string]interface{}{"slowQueryThresholdMs": 500, "slowQueryExplain": true})))
+	require.Equal(t, SlowQuerySettings{}, SlowQuerySettingsFromJSONData(
+		simplejson.NewFromAny(map[string]interface{}{"slowQueryThresholdMs": -1})))
+}
+
+func TestSlowQueryLog(t *testing.T) {
+	t.Cleanup(func() {
+		slowQueries.Lock()
+		defer slowQueries.Unlock()
+		slowQueries.queries = nil
+		slowQueries.next = 0
+	})
+	// sorting the numbers takes longer than a millisecond
+	const slowSQL = "SELECT x FROM numbers ORDER BY x * 7 % 1000 DESC LIMIT 1"
+
+	newPlugin := func(t *testing.T, ds *models.DataSource) plugins.DataPlugin {
+		path := filepath.Join(t.TempDir(), "numbers.db")
+		engine, err := xorm.NewEngine("sqlite3", path)
+		require.NoError(t, err)
+		_, err = engine.Exec("CREATE TABLE numbers (x INTEGER)")
+		require.NoError(t, err)
+		_, err = engine.Exec("WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c WHERE x < 200000) " +
+			"INSERT INTO numbers SELECT x FROM c")
+		require.NoError(t, err)
+		require.NoError(t, engine.Close())
+
+		plugin, err := NewDataPlugin(DataPluginConfiguration{
+			DriverName:       "sqlite3",
+			ConnectionString: path,
+			Datasource:       ds,
+			ExplainQuery: func(rawSQL string) string {
+				return "EXPLAIN QUERY PLAN " + rawSQL
+			},
+		}, &integerResultTransformer{}, testMacroEngine{}, log.New("test"))
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			engineCache.Lock()
+			defer engineCache.Unlock()
+			delete(engineCache.cache, ds.Id)
+			delete(engineCache.versions, ds.Id)
+			delete(engineCache.datasources, ds.Id)
+		})
+		return plugin
+	}
+
+	query := func(t *testing.T, plugin plugins.DataPlugin, ds *models.DataSource, sql string) {
+		timeRange := plugins.NewDataTimeRange("now-1h", "now")
+		_, err := plugin.DataQuery(context.Background(), ds, plugins.DataQuery{
+			TimeRange: &timeRange,
+			Queries: []plugins.DataSubQuery{{
+				RefID:      "A",
+				Model:      simplejson.NewFromAny(map[string]interface{}{"rawSql": sql, "format": "table"}),
+				DataSource: ds,
+			}},
+			Headers: map[string]string{"X-Dashboard-Id": "3", "X-Panel-Id": "4"},
+			User:    &models.SignedInUser{Login: "analyst"},
+		})
+		require.NoError(t, err)
+	}
+
+	t.Run("Should log the queries slower than the threshold", func(t *testing.T) {
+		ds := &models.DataSource{
+			Id:       7101,
+			OrgId:    2,
+			Name:     "Warehouse",
+			Type:     "postgres",
+			JsonData: simplejson.NewFromAny(map[string]interface{}{"slowQueryThresholdMs": 1}),
+		}
+		plugin := newPlugin(t, ds)
+		query(t, plugin, ds, slowSQL)
+		query(t, plugin, ds, "SELECT * FROM missing")
+
+		queries := GetSlowQueries()
+		require.NotEmpty(t, queries)
+		slow := queries[len(queries)-1]
+		require.Equal(t, int64(7101), slow.DatasourceID)
+		require.Equal(t, "Warehouse", slow.DatasourceName)
+		require.Equal(t, int64(2), slow.OrgID)
+		require.Equal(t, int64(3), slow.DashboardID)
+		require.Equal(t, int64(4), slow.PanelID)
+		require.Equal(t, "analyst", slow.User)
+		require.Equal(t, slowSQL, slow.Query)
+		require.GreaterOrEqual(t, slow.DurationMs, int64(1))
+		require.Empty(t, slow.Plan)
+	})
+
+	t.Run("Should explain the plans of the slow queries if enabled", func(t *testing.T) {
+		ds := &models.DataSource{
+			Id:   7102,
+			Name: "Reporting",
+			Type: "postgres",
+			JsonData: simplejson.NewFromAny(map[string]interface{}{
+				"slowQueryThresholdMs": 1,
+				"slowQueryExplain":     true,
+			}),
+		}
+		query(t, newPlugin(t, ds), ds, slowSQL)
+
+		require.Eventually(t, func() bool {
+			queries := GetSlowQueries()
+			return len(queries) > 0 && queries[0].DatasourceID == ds.Id && queries[0].Plan != ""
+		}, time.Second*5, 10*time.Millisecond)
+		require.Contains(t, GetSlowQueries()[0].Plan, "detail")
+	})
+
+	t.Run("Should not log the queries when disabled", func(t *testing.T) {
+		ds := &models.DataSource{Id: 7103, Name: "Fast", Type: "mysql", JsonData: simplejson.New()}
+		query(t, newPlugin(t, ds), ds, slowSQL)
+
+		for _, slow := range GetSlowQueries() {
+			require.NotEqual(t, ds.Id, slow.DatasourceID)
+		}
+	})
+
+	t.Run("Should keep the latest slow queries", func(t *testing.T) {
+		for i := 0; i < slowQueryLogSize+10; i++ {
+			slowQueries.add(&SlowQuery{DurationMs: int64(i)})
+		}
+
+		queries := GetSlowQueries()
+		require.Len(t, queries, slowQueryLogSize)
+		require.Equal(t, int64(slowQueryLogSize+9), queries[0].DurationMs)
+		require.Equal(t, int64(10), queries[slowQueryLogSize-1].DurationMs)
+	})
+}
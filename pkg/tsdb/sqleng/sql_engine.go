@@ -20,6 +20,7 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/tsdb/interval"
+	"github.com/grafana/grafana/pkg/tsdb/responselimit"
 	"xorm.io/core"
 	"xorm.io/xorm"
 )
@@ -44,19 +45,22 @@ type SqlQueryResultTransformer interface {
 }
 
 type engineCacheType struct {
-	cache    map[int64]*xorm.Engine
-	versions map[int64]int
+	cache       map[int64]*xorm.Engine
+	versions    map[int64]int
+	datasources map[int64]*models.DataSource
 	sync.Mutex
 }
 
 var engineCache = engineCacheType{
-	cache:    make(map[int64]*xorm.Engine),
-	versions: make(map[int64]int),
+	cache:       make(map[int64]*xorm.Engine),
+	versions:    make(map[int64]int),
+	datasources: make(map[int64]*models.DataSource),
 }
 
 var sqlIntervalCalculator = interval.NewCalculator()
 
 // NewXormEngine is an xorm.Engine factory, that can be stubbed by tests.
+//
 //nolint:gocritic
 var NewXormEngine = func(driverName string, connectionString string) (*xorm.Engine, error) {
 	return xorm.NewEngine(driverName, connectionString)
@@ -68,6 +72,13 @@ type dataPlugin struct {
 	engine                 *xorm.Engine
 	timeColumnNames        []string
 	metricColumnTypes      []string
+	multipleResultSets     bool
+	datasource             *models.DataSource
+	slowQuery              SlowQuerySettings
+	explainQuery           func(rawSQL string) string
+	setApplicationName     func(name string) string
+	driverName             string
+	identity               *IdentityConfiguration
 	log                    log.Logger
 }
 
@@ -77,6 +88,18 @@ type DataPluginConfiguration struct {
 	ConnectionString  string
 	TimeColumnNames   []string
 	MetricColumnTypes []string
+	// MultipleResultSets allows queries returning several result sets, like calls of stored procedures. The result
+	// set of the resultSet property of the query is returned, the first one by default.
+	MultipleResultSets bool
+	// ExplainQuery returns the statement explaining the plan of a query, for the slow query log. The plans of the
+	// slow queries aren't explained when nil.
+	ExplainQuery func(rawSQL string) string
+	// SetApplicationName returns the statement setting the application name of the transaction of an audited query,
+	// see queryaudit. The audited queries are prefixed by a comment of their audit info instead when nil.
+	SetApplicationName func(name string) string
+	// Identity configures the connections of the users with their cloud identity, for the data sources with an IAM
+	// authentication, see IAMAuth. The IAM authentication isn't supported when nil.
+	Identity *IdentityConfiguration
 }
 
 func (e *dataPlugin) transformQueryError(err error) error {
@@ -94,13 +117,20 @@ func (e *dataPlugin) transformQueryError(err error) error {
 }
 
 // NewDataPlugin returns a new plugins.DataPlugin
-//nolint: staticcheck // plugins.DataPlugin deprecated
+// nolint: staticcheck // plugins.DataPlugin deprecated
 func NewDataPlugin(config DataPluginConfiguration, queryResultTransformer SqlQueryResultTransformer,
 	macroEngine SQLMacroEngine, log log.Logger) (plugins.DataPlugin, error) {
 	plugin := dataPlugin{
 		queryResultTransformer: queryResultTransformer,
 		macroEngine:            macroEngine,
 		timeColumnNames:        []string{"time"},
+		multipleResultSets:     config.MultipleResultSets,
+		datasource:             config.Datasource,
+		slowQuery:              SlowQuerySettingsFromJSONData(config.Datasource.JsonData),
+		explainQuery:           config.ExplainQuery,
+		setApplicationName:     config.SetApplicationName,
+		driverName:             config.DriverName,
+		identity:               config.Identity,
 		log:                    log,
 	}
 
@@ -112,13 +142,24 @@ func NewDataPlugin(config DataPluginConfiguration, queryResultTransformer SqlQue
 		plugin.metricColumnTypes = config.MetricColumnTypes
 	}
 
+	engine, err := GetEngine(config)
+	if err != nil {
+		return nil, err
+	}
+	plugin.engine = engine
+
+	return &plugin, nil
+}
+
+// GetEngine returns the engine of the data source of the configuration, shared with its data plugin, creating it
+// when the data source has none or it changed since.
+func GetEngine(config DataPluginConfiguration) (*xorm.Engine, error) {
 	engineCache.Lock()
 	defer engineCache.Unlock()
 
 	if engine, present := engineCache.cache[config.Datasource.Id]; present {
 		if version := engineCache.versions[config.Datasource.Id]; version == config.Datasource.Version {
-			plugin.engine = engine
-			return &plugin, nil
+			return engine, nil
 		}
 	}
 
@@ -127,24 +168,19 @@ func NewDataPlugin(config DataPluginConfiguration, queryResultTransformer SqlQue
 		return nil, err
 	}
 
-	maxOpenConns := config.Datasource.JsonData.Get("maxOpenConns").MustInt(0)
-	engine.SetMaxOpenConns(maxOpenConns)
-	maxIdleConns := config.Datasource.JsonData.Get("maxIdleConns").MustInt(2)
-	engine.SetMaxIdleConns(maxIdleConns)
-	connMaxLifetime := config.Datasource.JsonData.Get("connMaxLifetime").MustInt(14400)
-	engine.SetConnMaxLifetime(time.Duration(connMaxLifetime) * time.Second)
+	PoolSettingsFromJSONData(config.Datasource.JsonData).apply(engine)
 
 	engineCache.versions[config.Datasource.Id] = config.Datasource.Version
 	engineCache.cache[config.Datasource.Id] = engine
-	plugin.engine = engine
+	engineCache.datasources[config.Datasource.Id] = config.Datasource
 
-	return &plugin, nil
+	return engine, nil
 }
 
 const rowLimit = 1000000
 
 // DataQuery queries for data.
-//nolint: staticcheck // plugins.DataPlugin deprecated
+// nolint: staticcheck // plugins.DataPlugin deprecated
 func (e *dataPlugin) DataQuery(ctx context.Context, dsInfo *models.DataSource,
 	queryContext plugins.DataQuery) (plugins.DataResponse, error) {
 	ch := make(chan plugins.DataQueryResult, len(queryContext.Queries))
@@ -156,7 +192,7 @@ func (e *dataPlugin) DataQuery(ctx context.Context, dsInfo *models.DataSource,
 		}
 
 		wg.Add(1)
-		go e.executeQuery(query, &wg, queryContext, ch)
+		go e.executeQuery(ctx, query, &wg, queryContext, ch)
 	}
 
 	wg.Wait()
@@ -173,9 +209,9 @@ func (e *dataPlugin) DataQuery(ctx context.Context, dsInfo *models.DataSource,
 	return result, nil
 }
 
-//nolint: staticcheck // plugins.DataQueryResult deprecated
-func (e *dataPlugin) executeQuery(query plugins.DataSubQuery, wg *sync.WaitGroup, queryContext plugins.DataQuery,
-	ch chan plugins.DataQueryResult) {
+// nolint: staticcheck // plugins.DataQueryResult deprecated
+func (e *dataPlugin) executeQuery(ctx context.Context, query plugins.DataSubQuery, wg *sync.WaitGroup,
+	queryContext plugins.DataQuery, ch chan plugins.DataQueryResult) {
 	defer wg.Done()
 
 	queryResult := plugins.DataQueryResult{
@@ -231,12 +267,28 @@ func (e *dataPlugin) executeQuery(query plugins.DataSubQuery, wg *sync.WaitGroup
 		queryResult.Dataframes = plugins.NewDecodedDataFrames(data.Frames{&emptyFrame})
 		ch <- queryResult
 	}
-	session := e.engine.NewSession()
+	engine := e.engine
+	if e.usesIdentity() {
+		engine, err = e.identityEngine(ctx, queryContext.User)
+		if err != nil {
+			queryResult.Error = err
+			ch <- queryResult
+			return
+		}
+		defer func() {
+			if err := engine.Close(); err != nil {
+				e.log.Warn("Failed to close engine", "err", err)
+			}
+		}()
+	}
+	session := engine.NewSession()
 	defer session.Close()
 	db := session.DB()
 
-	rows, err := db.Query(interpolatedQuery)
+	start := time.Now()
+	rows, release, err := e.query(db, queryContext, e.auditedQuery(queryContext, interpolatedQuery))
 	if err != nil {
+		e.logSlowQuery(queryContext, interpolatedQuery, time.Since(start), err)
 		errAppendDebug("db query error", e.transformQueryError(err))
 		return
 	}
@@ -244,8 +296,16 @@ func (e *dataPlugin) executeQuery(query plugins.DataSubQuery, wg *sync.WaitGroup
 		if err := rows.Close(); err != nil {
 			e.log.Warn("Failed to close rows", "err", err)
 		}
+		release()
 	}()
 
+	if e.multipleResultSets {
+		if err := selectResultSet(rows.Rows, query.Model.Get("resultSet").MustInt(0)); err != nil {
+			errAppendDebug("failed to select result set", err)
+			return
+		}
+	}
+
 	qm, err := e.newProcessCfg(query, queryContext, rows, interpolatedQuery)
 	if err != nil {
 		errAppendDebug("failed to get configurations", err)
@@ -254,15 +314,23 @@ func (e *dataPlugin) executeQuery(query plugins.DataSubQuery, wg *sync.WaitGroup
 
 	// Convert row.Rows to dataframe
 	stringConverters := e.queryResultTransformer.GetConverterList()
-	frame, err := sqlutil.FrameFromRows(rows.Rows, rowLimit, sqlutil.ToConverters(stringConverters...)...)
+	frame, err := frameFromRows(rows.Rows, responselimit.ForQuery(ctx, query.RefID),
+		sqlutil.ToConverters(stringConverters...)...)
+	// the rows are fetched while converted, so the duration of the query includes the conversion
+	e.logSlowQuery(queryContext, interpolatedQuery, time.Since(start), err)
 	if err != nil {
 		errAppendDebug("convert frame from rows error", err)
 		return
 	}
 
-	frame.SetMeta(&data.FrameMeta{
+	meta := &data.FrameMeta{
 		ExecutedQueryString: interpolatedQuery,
-	})
+	}
+	if frame.Meta != nil {
+		// the notices of the limits the rows were truncated at
+		meta.Notices = frame.Meta.Notices
+	}
+	frame.SetMeta(meta)
 
 	// If no rows were returned, no point checking anything else.
 	if frame.Rows() == 0 {
@@ -321,6 +389,79 @@ func (e *dataPlugin) executeQuery(query plugins.DataSubQuery, wg *sync.WaitGroup
 	ch <- queryResult
 }
 
+// frameFromRows converts rows to a data frame like sqlutil.FrameFromRows, and stops reading the rows at the row
+// limit or at the response limits of the query, with a notice on the frame.
+func frameFromRows(rows *sql.Rows, limiter *responselimit.Limiter, converters ...sqlutil.Converter) (*data.Frame,
+	error) {
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	names, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	scanner, converters, err := sqlutil.MakeScanRow(types, names, converters...)
+	if err != nil {
+		return nil, err
+	}
+
+	frame := sqlutil.NewFrame(names, converters...)
+	if !limiter.AddSeries() {
+		frame.AppendNotices(limiter.Notice())
+		return frame, nil
+	}
+	// the size of the rows is estimated at 8 bytes per value
+	rowSize := int64(len(names)) * 8
+	for i := 0; rows.Next(); i++ {
+		if i == rowLimit {
+			frame.AppendNotices(data.Notice{
+				Severity: data.NoticeSeverityWarning,
+				Text:     fmt.Sprintf("Results have been limited to %v because the SQL row limit was reached", rowLimit),
+			})
+			break
+		}
+		if !limiter.AddRow(rowSize) {
+			frame.AppendNotices(limiter.Notice())
+			break
+		}
+
+		r := scanner.NewScannableRow()
+		if err := rows.Scan(r...); err != nil {
+			return nil, err
+		}
+		if err := sqlutil.Append(frame, r, converters...); err != nil {
+			return nil, err
+		}
+	}
+	return frame, nil
+}
+
+// selectResultSet advances rows to the result set at index. Only result sets with columns are counted, since
+// statements like SET, or the status of a stored procedure call, don't return rows.
+func selectResultSet(rows *sql.Rows, index int) error {
+	for count := 0; ; {
+		columns, err := rows.Columns()
+		if err != nil {
+			return err
+		}
+
+		if len(columns) > 0 {
+			if count == index {
+				return nil
+			}
+			count++
+		}
+
+		if !rows.NextResultSet() {
+			if err := rows.Err(); err != nil {
+				return err
+			}
+			return fmt.Errorf("result set %d not found, the query returned %d result sets", index, count)
+		}
+	}
+}
+
 // Interpolate provides global macros/substitutions for all sql datasources.
 var Interpolate = func(query plugins.DataSubQuery, timeRange plugins.DataTimeRange, sql string) (string, error) {
 	minInterval, err := interval.GetIntervalFrom(query.DataSource, query.Model, time.Second*60)
@@ -337,7 +478,7 @@ var Interpolate = func(query plugins.DataSubQuery, timeRange plugins.DataTimeRan
 	return sql, nil
 }
 
-//nolint: staticcheck // plugins.DataPlugin deprecated
+// nolint: staticcheck // plugins.DataPlugin deprecated
 func (e *dataPlugin) newProcessCfg(query plugins.DataSubQuery, queryContext plugins.DataQuery,
 	rows *core.Rows, interpolatedQuery string) (*dataQueryModel, error) {
 	columnNames, err := rows.Columns()
@@ -815,7 +956,7 @@ func convertSQLTimeColumnToEpochMS(frame *data.Frame, timeIndex int) error {
 }
 
 // convertSQLValueColumnToFloat converts timeseries value column to float.
-//nolint: gocyclo
+// nolint: gocyclo
 func convertSQLValueColumnToFloat(frame *data.Frame, Index int) (*data.Frame, error) {
 	if Index < 0 || Index >= len(frame.Fields) {
 		return frame, fmt.Errorf("metricIndex %d is out of range", Index)
@@ -0,0 +1,213 @@
+package testdatasource
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+)
+
+// The failures of the chaos scenario.
+const (
+	chaosFailureNone      = "none"
+	chaosFailureError     = "error"
+	chaosFailurePartial   = "partial"
+	chaosFailureOversized = "oversized"
+)
+
+// The health statuses simulated by the health checks of the data sources.
+const (
+	chaosHealthOK          = "ok"
+	chaosHealthError       = "error"
+	chaosHealthFail        = "fail"
+	chaosHealthUnavailable = "unavailable"
+)
+
+const (
+	defaultChaosRows = 1000000
+	maxChaosRows     = 5000000
+)
+
+// chaosOptions are the options of a query of the chaos scenario.
+type chaosOptions struct {
+	// Failure is the failure of the query, a random walk when none
+	Failure string
+	// Probability is the probability of the failure
+	Probability float64
+	// Latency delays the response of the query
+	Latency time.Duration
+	// Jitter is the maximum random latency added to the latency
+	Jitter time.Duration
+	// Rows is the number of rows of the oversized frame
+	Rows int
+}
+
+// chaosOptionsFromQuery returns the chaosOptions of a query of the chaos scenario.
+func chaosOptionsFromQuery(model *simplejson.Json) (chaosOptions, error) {
+	json := model.Get("chaos")
+	opts := chaosOptions{
+		Failure:     json.Get("failure").MustString(chaosFailureNone),
+		Probability: json.Get("probability").MustFloat64(1),
+		Rows:        json.Get("rows").MustInt(defaultChaosRows),
+	}
+
+	switch opts.Failure {
+	case "", chaosFailureNone:
+		opts.Failure = chaosFailureNone
+	case chaosFailureError, chaosFailurePartial, chaosFailureOversized:
+	default:
+		return opts, fmt.Errorf("invalid chaos failure: %q", opts.Failure)
+	}
+	if opts.Probability < 0 || opts.Probability > 1 {
+		return opts, fmt.Errorf("chaos probability must be between 0 and 1: %v", opts.Probability)
+	}
+	if opts.Rows < 1 || opts.Rows > maxChaosRows {
+		return opts, fmt.Errorf("the rows of an oversized response must be between 1 and %d: %d", maxChaosRows, opts.Rows)
+	}
+
+	var err error
+	if opts.Latency, err = parseChaosDuration(json.Get("latency").MustString(), "latency"); err != nil {
+		return opts, err
+	}
+	if opts.Jitter, err = parseChaosDuration(json.Get("jitter").MustString(), "jitter"); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+func parseChaosDuration(text, name string) (time.Duration, error) {
+	if text == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(text)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid chaos %s, e.g. 500ms: %q", name, text)
+	}
+	return d, nil
+}
+
+// handleChaosScenario returns the failures of the queries of the chaos scenario, after their latency. The queries fail
+// with the error of the context when the request is canceled before.
+func (p *testDataPlugin) handleChaosScenario(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	resp := backend.NewQueryDataResponse()
+
+	for _, q := range req.Queries {
+		model, err := simplejson.NewJson(q.JSON)
+		if err != nil {
+			continue
+		}
+
+		respD := resp.Responses[q.RefID]
+		opts, err := chaosOptionsFromQuery(model)
+		if err != nil {
+			respD.Error = err
+			resp.Responses[q.RefID] = respD
+			continue
+		}
+
+		seed := querySeed(model)
+		r := newRand(seed, 0)
+		if err := chaosDelay(ctx, r, opts.Latency, opts.Jitter); err != nil {
+			respD.Error = err
+			resp.Responses[q.RefID] = respD
+			continue
+		}
+
+		failure := opts.Failure
+		if failure != chaosFailureNone && r.Float64() >= opts.Probability {
+			failure = chaosFailureNone
+		}
+
+		switch failure {
+		case chaosFailureError:
+			respD.Error = fmt.Errorf("chaos: query %s failed", q.RefID)
+		case chaosFailurePartial:
+			respD.Frames = append(respD.Frames, randomWalk(q, model, randomWalkOptions{Seed: seed}, 0, parseLabels(model)))
+			respD.Error = fmt.Errorf("chaos: query %s returned partial results", q.RefID)
+		case chaosFailureOversized:
+			respD.Frames = append(respD.Frames, oversizedFrame(q, r, opts.Rows))
+		default:
+			respD.Frames = append(respD.Frames, randomWalk(q, model, randomWalkOptions{Seed: seed}, 0, parseLabels(model)))
+		}
+		resp.Responses[q.RefID] = respD
+	}
+
+	return resp, nil
+}
+
+// oversizedFrame returns a frame of rows over the time range of the query.
+func oversizedFrame(query backend.DataQuery, r *rand.Rand, rows int) *data.Frame {
+	from := query.TimeRange.From
+	step := query.TimeRange.To.Sub(from) / time.Duration(rows)
+	times := make([]time.Time, rows)
+	values := make([]float64, rows)
+	value := r.Float64() * 100
+	for i := range times {
+		times[i] = from.Add(time.Duration(i) * step)
+		values[i] = value
+		value += r.Float64() - 0.5
+	}
+	return data.NewFrame(query.RefID,
+		data.NewField("time", nil, times),
+		data.NewField("value", nil, values),
+	)
+}
+
+// chaosDelay waits for the latency plus a random jitter, or returns the error of the context when it's done before.
+func chaosDelay(ctx context.Context, r *rand.Rand, latency, jitter time.Duration) error {
+	if jitter > 0 {
+		latency += time.Duration(r.Int63n(int64(jitter)))
+	}
+	if latency <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(latency)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// CheckHealth returns the health status of the healthStatus setting of the data source, after the healthLatency:
+// ok by default, error for a failed health check, fail for a failing handler and unavailable for an unavailable plugin.
+func (p *testDataPlugin) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	status, latency := chaosHealthOK, ""
+	if settings := req.PluginContext.DataSourceInstanceSettings; settings != nil && len(settings.JSONData) > 0 {
+		jsonData, err := simplejson.NewJson(settings.JSONData)
+		if err != nil {
+			return nil, err
+		}
+		status = jsonData.Get("healthStatus").MustString(chaosHealthOK)
+		latency = jsonData.Get("healthLatency").MustString()
+	}
+
+	delay, err := parseChaosDuration(latency, "health latency")
+	if err != nil {
+		return nil, err
+	}
+	if err := chaosDelay(ctx, nil, delay, 0); err != nil {
+		return nil, err
+	}
+
+	switch status {
+	case "", chaosHealthOK:
+		return &backend.CheckHealthResult{Status: backend.HealthStatusOk, Message: "Data source is working"}, nil
+	case chaosHealthError:
+		return &backend.CheckHealthResult{Status: backend.HealthStatusError, Message: "chaos: the health check failed"}, nil
+	case chaosHealthFail:
+		return nil, fmt.Errorf("chaos: the health check handler failed")
+	case chaosHealthUnavailable:
+		return nil, backendplugin.ErrPluginUnavailable
+	default:
+		return nil, fmt.Errorf("invalid health status: %q", status)
+	}
+}
@@ -0,0 +1,114 @@
+package testdatasource
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChaosScenario(t *testing.T) {
+	p := &testDataPlugin{}
+	from := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	query := func(ctx context.Context, t *testing.T, json string) backend.DataResponse {
+		t.Helper()
+		q := backend.DataQuery{
+			RefID:         "A",
+			TimeRange:     backend.TimeRange{From: from, To: from.Add(time.Hour)},
+			Interval:      time.Minute,
+			MaxDataPoints: 60,
+			JSON:          []byte(json),
+		}
+		resp, err := p.handleChaosScenario(ctx, &backend.QueryDataRequest{Queries: []backend.DataQuery{q}})
+		require.NoError(t, err)
+		return resp.Responses["A"]
+	}
+
+	t.Run("Should return a random walk without failure", func(t *testing.T) {
+		dr := query(context.Background(), t, `{}`)
+		require.NoError(t, dr.Error)
+		require.Len(t, dr.Frames, 1)
+		assert.Equal(t, 60, dr.Frames[0].Fields[0].Len())
+	})
+
+	t.Run("Should return the failures", func(t *testing.T) {
+		dr := query(context.Background(), t, `{"chaos": {"failure": "error"}}`)
+		require.EqualError(t, dr.Error, "chaos: query A failed")
+		require.Empty(t, dr.Frames)
+
+		dr = query(context.Background(), t, `{"chaos": {"failure": "partial"}}`)
+		require.EqualError(t, dr.Error, "chaos: query A returned partial results")
+		require.Len(t, dr.Frames, 1)
+
+		dr = query(context.Background(), t, `{"chaos": {"failure": "oversized", "rows": 100000}}`)
+		require.NoError(t, dr.Error)
+		require.Len(t, dr.Frames, 1)
+		assert.Equal(t, 100000, dr.Frames[0].Fields[0].Len())
+	})
+
+	t.Run("Should not fail without probability", func(t *testing.T) {
+		dr := query(context.Background(), t, `{"chaos": {"failure": "error", "probability": 0}}`)
+		require.NoError(t, dr.Error)
+		require.Len(t, dr.Frames, 1)
+	})
+
+	t.Run("Should delay the response", func(t *testing.T) {
+		start := time.Now()
+		dr := query(context.Background(), t, `{"chaos": {"latency": "50ms", "jitter": "10ms"}}`)
+		require.NoError(t, dr.Error)
+		assert.GreaterOrEqual(t, int64(time.Since(start)), int64(50*time.Millisecond))
+	})
+
+	t.Run("Should fail when the request is canceled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		dr := query(ctx, t, `{"chaos": {"latency": "1m"}}`)
+		require.True(t, errors.Is(dr.Error, context.DeadlineExceeded))
+	})
+
+	t.Run("Should return an error with invalid options", func(t *testing.T) {
+		for _, json := range []string{
+			`{"chaos": {"failure": "fire"}}`,
+			`{"chaos": {"probability": 2}}`,
+			`{"chaos": {"rows": 0}}`,
+			`{"chaos": {"latency": "soon"}}`,
+			`{"chaos": {"jitter": "-1s"}}`,
+		} {
+			dr := query(context.Background(), t, json)
+			assert.Error(t, dr.Error, json)
+		}
+	})
+}
+
+func TestChaosCheckHealth(t *testing.T) {
+	p := &testDataPlugin{}
+	checkHealth := func(jsonData string) (*backend.CheckHealthResult, error) {
+		return p.CheckHealth(context.Background(), &backend.CheckHealthRequest{
+			PluginContext: backend.PluginContext{
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{JSONData: []byte(jsonData)},
+			},
+		})
+	}
+
+	res, err := checkHealth(`{}`)
+	require.NoError(t, err)
+	assert.Equal(t, backend.HealthStatusOk, res.Status)
+
+	res, err = checkHealth(`{"healthStatus": "error", "healthLatency": "1ms"}`)
+	require.NoError(t, err)
+	assert.Equal(t, backend.HealthStatusError, res.Status)
+
+	_, err = checkHealth(`{"healthStatus": "fail"}`)
+	require.Error(t, err)
+
+	_, err = checkHealth(`{"healthStatus": "unavailable"}`)
+	require.True(t, errors.Is(err, backendplugin.ErrPluginUnavailable))
+
+	_, err = checkHealth(`{"healthStatus": "unknown"}`)
+	require.Error(t, err)
+}
@@ -1,14 +1,18 @@
 package testdatasource
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/csv"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -29,14 +33,18 @@ func (p *testDataPlugin) handleCsvContentScenario(ctx context.Context, req *back
 
 		csvContent := model.Get("csvContent").MustString()
 		alias := model.Get("alias").MustString(q.RefID)
+		opts, err := csvOptionsFromQuery(model)
+		if err != nil {
+			return nil, err
+		}
 
-		frame, err := p.loadCsvContent(strings.NewReader(csvContent), alias)
+		frames, err := p.loadCsvFrames(strings.NewReader(csvContent), alias, opts)
 		if err != nil {
 			return nil, err
 		}
 
 		respD := resp.Responses[q.RefID]
-		respD.Frames = append(respD.Frames, frame)
+		respD.Frames = append(respD.Frames, frames...)
 		resp.Responses[q.RefID] = respD
 	}
 
@@ -53,33 +61,96 @@ func (p *testDataPlugin) handleCsvFileScenario(ctx context.Context, req *backend
 		}
 
 		fileName := model.Get("csvFileName").MustString()
+		csvURL := model.Get("csvUrl").MustString()
 
-		if len(fileName) == 0 {
+		if len(fileName) == 0 && len(csvURL) == 0 {
 			continue
 		}
 
-		frame, err := p.loadCsvFile(fileName)
+		opts, err := csvOptionsFromQuery(model)
+		if err != nil {
+			return nil, err
+		}
+		var frames data.Frames
+		if csvURL != "" {
+			// a remote file instead of a file of the server
+			frames, err = p.loadCsvURL(ctx, csvURL, opts)
+		} else {
+			frames, err = p.loadCsvFileWithOptions(req.PluginContext.OrgID, fileName, opts)
+		}
 
 		if err != nil {
 			return nil, err
 		}
 
 		respD := resp.Responses[q.RefID]
-		respD.Frames = append(respD.Frames, frame)
+		respD.Frames = append(respD.Frames, frames...)
 		resp.Responses[q.RefID] = respD
 	}
 
 	return resp, nil
 }
 
-func (p *testDataPlugin) loadCsvFile(fileName string) (*data.Frame, error) {
-	validFileName := regexp.MustCompile(`([\w_]+)\.csv`)
+// validCsvFileName matches the names of the CSV files the scenarios can read.
+var validCsvFileName = regexp.MustCompile(`^[\w_]+\.csv$`)
 
-	if !validFileName.MatchString(fileName) {
-		return nil, fmt.Errorf("invalid csv file name: %q", fileName)
+// csvUploadsDirName is the name of the directory of the uploaded CSV files of an organization, which shadows a
+// directory of the csv_dirs setting with the same name.
+const csvUploadsDirName = "uploads"
+
+// csvUploadMaxBytes is the max size of the uploaded CSV files.
+const csvUploadMaxBytes = 10 << 20
+
+// csvDirs returns the paths of the CSV directories of an organization by name, the directories of the csv_dirs
+// setting and the one of the files uploaded by the organization under the data path.
+func (p *testDataPlugin) csvDirs(orgID int64) map[string]string {
+	dirs := make(map[string]string, len(p.Cfg.TestData.CSVDirs)+1)
+	for name, path := range p.Cfg.TestData.CSVDirs {
+		dirs[name] = path
+	}
+	if p.Cfg.DataPath != "" {
+		dirs[csvUploadsDirName] = filepath.Join(p.Cfg.DataPath, "testdata", "csv", strconv.FormatInt(orgID, 10))
+	}
+	return dirs
+}
+
+// csvFilePath returns the path of a CSV file, which is either the name of an example file of the testdata
+// directory, or <directory name>/<file name> for a file of a directory of the csv_dirs setting or a file uploaded by
+// the organization.
+func (p *testDataPlugin) csvFilePath(orgID int64, fileName string) (string, error) {
+	dir := filepath.Join(p.Cfg.StaticRootPath, "testdata")
+	name := fileName
+	if i := strings.Index(fileName, "/"); i >= 0 {
+		var ok bool
+		if dir, ok = p.csvDirs(orgID)[fileName[:i]]; !ok {
+			return "", fmt.Errorf("unknown csv directory: %q", fileName[:i])
+		}
+		name = fileName[i+1:]
+	}
+
+	if !validCsvFileName.MatchString(name) {
+		return "", fmt.Errorf("invalid csv file name: %q", fileName)
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// loadCsvFile loads a CSV file of an organization of a single frame, like the streams replay.
+func (p *testDataPlugin) loadCsvFile(orgID int64, fileName string) (*data.Frame, error) {
+	frames, err := p.loadCsvFileWithOptions(orgID, fileName, csvOptions{})
+	if err != nil {
+		return nil, err
 	}
+	if len(frames) != 1 {
+		return nil, fmt.Errorf("csv file %q has %d frames, expected one", fileName, len(frames))
+	}
+	return frames[0], nil
+}
 
-	filePath := filepath.Join(p.Cfg.StaticRootPath, "testdata", fileName)
+func (p *testDataPlugin) loadCsvFileWithOptions(orgID int64, fileName string, opts csvOptions) (data.Frames, error) {
+	filePath, err := p.csvFilePath(orgID, fileName)
+	if err != nil {
+		return nil, err
+	}
 
 	// Can ignore gosec G304 here, because we check the file pattern above
 	// nolint:gosec
@@ -94,11 +165,206 @@ func (p *testDataPlugin) loadCsvFile(fileName string) (*data.Frame, error) {
 		}
 	}()
 
-	return p.loadCsvContent(fileReader, fileName)
+	return p.loadCsvFrames(fileReader, fileName, opts)
+}
+
+// csvDirectory is a directory of CSV files, with the names the scenarios read the files with.
+type csvDirectory struct {
+	Name  string   `json:"name"`
+	Files []string `json:"files"`
+}
+
+// listCsvFiles returns the CSV files of the testdata directory, followed by the ones of the directories of the
+// csv_dirs setting and of the files uploaded by the organization sorted by name.
+func (p *testDataPlugin) listCsvFiles(orgID int64) []csvDirectory {
+	csvDirs := p.csvDirs(orgID)
+	dirNames := make([]string, 0, len(csvDirs))
+	for name := range csvDirs {
+		dirNames = append(dirNames, name)
+	}
+	sort.Strings(dirNames)
+
+	dirs := []csvDirectory{{Name: "testdata", Files: p.readCsvDir(filepath.Join(p.Cfg.StaticRootPath, "testdata"), "")}}
+	for _, name := range dirNames {
+		if name == csvUploadsDirName {
+			// the directory of the uploaded files is created by the first upload
+			if _, err := os.Stat(csvDirs[name]); os.IsNotExist(err) {
+				continue
+			}
+		}
+		dirs = append(dirs, csvDirectory{Name: name, Files: p.readCsvDir(csvDirs[name], name+"/")})
+	}
+	return dirs
+}
+
+func (p *testDataPlugin) readCsvDir(path string, prefix string) []string {
+	files := []string{}
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		p.logger.Warn("Failed to read csv directory", "path", path, "err", err)
+		return files
+	}
+
+	for _, entry := range entries {
+		if entry.Mode().IsRegular() && validCsvFileName.MatchString(entry.Name()) {
+			files = append(files, prefix+entry.Name())
+		}
+	}
+	return files
 }
 
-func (p *testDataPlugin) loadCsvContent(ioReader io.Reader, name string) (*data.Frame, error) {
+// saveCsvUpload validates the content of an uploaded CSV file and stores it in the directory of the files uploaded by
+// the organization, replacing the file with the same name. It returns the frame of the file, named like the scenarios
+// read it.
+func (p *testDataPlugin) saveCsvUpload(orgID int64, fileName string, content []byte) (*data.Frame, error) {
+	dir, ok := p.csvDirs(orgID)[csvUploadsDirName]
+	if !ok {
+		return nil, fmt.Errorf("no data path to store the csv files in")
+	}
+	if !validCsvFileName.MatchString(fileName) {
+		return nil, fmt.Errorf("invalid csv file name: %q", fileName)
+	}
+
+	// the uploaded files are replayed by the streams too, which replay a single frame
+	frames, err := p.loadCsvFrames(bytes.NewReader(content), csvUploadsDirName+"/"+fileName, csvOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if len(frames) != 1 {
+		return nil, fmt.Errorf("csv file %q has %d frames, expected one", fileName, len(frames))
+	}
+	frame := frames[0]
+	if len(frame.Fields) == 0 {
+		return nil, fmt.Errorf("csv file %q has no fields", fileName)
+	}
+
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("failed to create csv directory: %v", err)
+	}
+	// write to a temporary file first, so that the queries never read a partial file
+	tmp, err := ioutil.TempFile(dir, ".upload-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create csv file: %v", err)
+	}
+	defer func() {
+		if err := os.Remove(tmp.Name()); err != nil && !os.IsNotExist(err) {
+			p.logger.Warn("Failed to remove temporary file", "err", err, "path", tmp.Name())
+		}
+	}()
+
+	_, err = tmp.Write(content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write csv file: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, fileName)); err != nil {
+		return nil, fmt.Errorf("failed to write csv file: %v", err)
+	}
+	return frame, nil
+}
+
+// csvFrameComment starts a new frame in CSV content like a blank line does, naming the frame after the rest of the
+// line, e.g. #frame: errors.
+const csvFrameComment = "#frame:"
+
+// csvSection is the content of a frame of multi-frame CSV content.
+type csvSection struct {
+	name    string
+	content strings.Builder
+}
+
+// splitCsvContent splits CSV content into the sections of its frames, which are separated by blank lines or
+// #frame: comments. The blank lines of the quoted values don't separate the sections.
+func splitCsvContent(ioReader io.Reader) ([]*csvSection, error) {
+	reader := bufio.NewReader(ioReader)
+	sections := []*csvSection{}
+	current := &csvSection{}
+	flush := func() error {
+		if current.content.Len() > 0 {
+			sections = append(sections, current)
+		} else if current.name != "" {
+			return fmt.Errorf("csv frame %q has no header line", current.name)
+		}
+		return nil
+	}
+
+	quoted := false
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to read line: %v", err)
+		}
+		if line == "" && err != nil {
+			break
+		}
+
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case !quoted && trimmed == "":
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			current = &csvSection{}
+		case !quoted && strings.HasPrefix(trimmed, csvFrameComment):
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			current = &csvSection{name: strings.TrimSpace(strings.TrimPrefix(trimmed, csvFrameComment))}
+		default:
+			current.content.WriteString(line)
+			// the escaped quotes of the quoted values are doubled, so an odd count opens or closes a quoted value
+			if strings.Count(line, `"`)%2 == 1 {
+				quoted = !quoted
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return sections, nil
+}
+
+// loadCsvFrames converts CSV content to frames, one per section of the content, see splitCsvContent. The frames are
+// named after their #frame: comments, or else the name.
+func (p *testDataPlugin) loadCsvFrames(ioReader io.Reader, name string, opts csvOptions) (data.Frames, error) {
+	sections, err := splitCsvContent(ioReader)
+	if err != nil {
+		return nil, err
+	}
+	if len(sections) == 0 {
+		return nil, fmt.Errorf("failed to read header line: %v", io.EOF)
+	}
+
+	frames := make(data.Frames, 0, len(sections))
+	for i, section := range sections {
+		frameName := name
+		if section.name != "" {
+			frameName = section.name
+		}
+		frame, err := p.loadCsvContent(strings.NewReader(section.content.String()), frameName, opts)
+		if err != nil {
+			if len(sections) > 1 {
+				return nil, fmt.Errorf("csv frame %d: %v", i+1, err)
+			}
+			return nil, err
+		}
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
+
+// loadCsvContent converts CSV content to a frame. The types of the fields are guessed by their values, or forced by
+// the type hints annotating the names of the header, see parseCsvHeader.
+func (p *testDataPlugin) loadCsvContent(ioReader io.Reader, name string, opts csvOptions) (*data.Frame, error) {
 	reader := csv.NewReader(ioReader)
+	if opts.Delimiter != 0 {
+		reader.Comma = opts.Delimiter
+	}
 
 	// Read the header records
 	headerFields, err := reader.Read()
@@ -108,10 +374,13 @@ func (p *testDataPlugin) loadCsvContent(ioReader io.Reader, name string) (*data.
 
 	fields := []*data.Field{}
 	fieldNames := []string{}
+	fieldHints := []string{}
 	fieldRawValues := [][]string{}
 
-	for _, fieldName := range headerFields {
-		fieldNames = append(fieldNames, strings.Trim(fieldName, " "))
+	for _, header := range headerFields {
+		fieldName, hint := parseCsvHeader(strings.Trim(header, " "))
+		fieldNames = append(fieldNames, fieldName)
+		fieldHints = append(fieldHints, hint)
 		fieldRawValues = append(fieldRawValues, []string{})
 	}
 
@@ -129,23 +398,34 @@ func (p *testDataPlugin) loadCsvContent(ioReader io.Reader, name string) (*data.
 	}
 
 	longest := 0
+	hasLabels := false
 	for fieldIndex, rawValues := range fieldRawValues {
 		fieldName := fieldNames[fieldIndex]
-		field, err := csvValuesToField(rawValues)
-		if err == nil {
+		var field *data.Field
+		if hint := fieldHints[fieldIndex]; hint != "" {
+			field, err = csvValuesToHintedField(rawValues, hint, opts)
+			if err != nil {
+				return nil, fmt.Errorf("invalid values of field %q: %v", fieldName, err)
+			}
+			hasLabels = hasLabels || hint == csvHintLabel
+		} else {
+			field, err = csvValuesToField(rawValues, opts)
+			if err != nil {
+				continue
+			}
 			// Check if the values are actually a time field
 			if strings.Contains(strings.ToLower(fieldName), "time") {
-				timeField := toTimeField(field)
+				timeField := toTimeField(field, opts)
 				if timeField != nil {
 					field = timeField
 				}
 			}
+		}
 
-			field.Name = fieldName
-			fields = append(fields, field)
-			if field.Len() > longest {
-				longest = field.Len()
-			}
+		field.Name = fieldName
+		fields = append(fields, field)
+		if field.Len() > longest {
+			longest = field.Len()
 		}
 	}
 
@@ -158,14 +438,22 @@ func (p *testDataPlugin) loadCsvContent(ioReader io.Reader, name string) (*data.
 	}
 
 	frame := data.NewFrame(name, fields...)
+	if hasLabels {
+		// the values of the label fields, and of the other string and bool fields, become the labels of the others
+		wide, err := data.LongToWide(frame, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert the label fields of csv %q: %v", name, err)
+		}
+		return wide, nil
+	}
 	return frame, nil
 }
 
 func csvLineToField(stringInput string) (*data.Field, error) {
-	return csvValuesToField(strings.Split(strings.ReplaceAll(stringInput, " ", ""), ","))
+	return csvValuesToField(strings.Split(strings.ReplaceAll(stringInput, " ", ""), ","), csvOptions{})
 }
 
-func csvValuesToField(parts []string) (*data.Field, error) {
+func csvValuesToField(parts []string, opts csvOptions) (*data.Field, error) {
 	if len(parts) < 1 {
 		return nil, fmt.Errorf("csv must have at least one value")
 	}
@@ -174,10 +462,10 @@ func csvValuesToField(parts []string) (*data.Field, error) {
 	if first == "T" || first == "F" || first == "TRUE" || first == "FALSE" {
 		field := data.NewFieldFromFieldType(data.FieldTypeNullableBool, len(parts))
 		for idx, strVal := range parts {
-			strVal = strings.ToUpper(strVal)
-			if strVal == "NULL" || strVal == "" {
+			if opts.isNull(strVal) {
 				continue
 			}
+			strVal = strings.ToUpper(strVal)
 			field.SetConcrete(idx, strVal == "T" || strVal == "TRUE")
 		}
 		return field, nil
@@ -187,7 +475,7 @@ func csvValuesToField(parts []string) (*data.Field, error) {
 	ok := false
 	field := data.NewFieldFromFieldType(data.FieldTypeNullableInt64, len(parts))
 	for idx, strVal := range parts {
-		if strVal == "null" || strVal == "" {
+		if opts.isNull(strVal) {
 			continue
 		}
 
@@ -206,11 +494,11 @@ func csvValuesToField(parts []string) (*data.Field, error) {
 	// Maybe floats
 	field = data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, len(parts))
 	for idx, strVal := range parts {
-		if strVal == "null" || strVal == "" {
+		if opts.isNull(strVal) {
 			continue
 		}
 
-		val, err := strconv.ParseFloat(strVal, 64)
+		val, err := opts.parseFloat(strVal)
 		if err != nil {
 			ok = false
 			break
@@ -225,7 +513,7 @@ func csvValuesToField(parts []string) (*data.Field, error) {
 	// Replace empty strings with null
 	field = data.NewFieldFromFieldType(data.FieldTypeNullableString, len(parts))
 	for idx, strVal := range parts {
-		if strVal == "null" || strVal == "" {
+		if opts.isNull(strVal) {
 			continue
 		}
 		field.SetConcrete(idx, strVal)
@@ -234,7 +522,7 @@ func csvValuesToField(parts []string) (*data.Field, error) {
 }
 
 // This will try to convert the values to a timestamp
-func toTimeField(field *data.Field) *data.Field {
+func toTimeField(field *data.Field, opts csvOptions) *data.Field {
 	found := false
 	count := field.Len()
 	timeField := data.NewFieldFromFieldType(data.FieldTypeNullableTime, count)
@@ -260,7 +548,7 @@ func toTimeField(field *data.Field) *data.Field {
 		for i := 0; i < count; i++ {
 			v, ok := field.ConcreteAt(i)
 			if ok && v != nil {
-				t, err := time.Parse(time.RFC3339, v.(string))
+				t, err := opts.parseTime(v.(string))
 				if err == nil {
 					timeField.SetConcrete(i, t.UTC())
 					found = true
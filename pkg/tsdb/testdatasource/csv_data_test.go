@@ -1,14 +1,23 @@
 package testdatasource
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
-	"github.com/grafana/grafana-plugin-sdk-go/experimental"
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb/testutil"
 	"github.com/stretchr/testify/require"
 )
 
@@ -25,17 +34,11 @@ func TestCSVFileScenario(t *testing.T) {
 		files := []string{"population_by_state.csv", "city_stats.csv"}
 		for _, name := range files {
 			t.Run("Should load file and convert to DataFrame", func(t *testing.T) {
-				frame, err := p.loadCsvFile(name)
+				frame, err := p.loadCsvFile(1, name)
 				require.NoError(t, err)
 				require.NotNil(t, frame)
 
-				dr := &backend.DataResponse{
-					Frames: data.Frames{frame},
-				}
-				err = experimental.CheckGoldenDataResponse(
-					filepath.Join("testdata", name+".golden.txt"), dr, true,
-				)
-				require.NoError(t, err)
+				testutil.CheckGoldenFrames(t, filepath.Join("testdata", name+".golden.txt"), frame)
 			})
 		}
 
@@ -52,25 +55,224 @@ func TestCSVFileScenario(t *testing.T) {
 					_ = fileReader.Close()
 				}()
 
-				frame, err := p.loadCsvContent(fileReader, name)
+				frame, err := p.loadCsvContent(fileReader, name, csvOptions{})
 				require.NoError(t, err)
 				require.NotNil(t, frame)
 
-				dr := &backend.DataResponse{
-					Frames: data.Frames{frame},
-				}
-				err = experimental.CheckGoldenDataResponse(
-					filepath.Join("testdata", name+".golden.txt"), dr, true,
-				)
-				require.NoError(t, err)
+				testutil.CheckGoldenFrames(t, filepath.Join("testdata", name+".golden.txt"), frame)
 			})
 		}
 
+		t.Run("Should load a frame per section of CSV Text", func(t *testing.T) {
+			// Can ignore gosec G304 here, because this is a constant path
+			// nolint:gosec
+			fileReader, err := os.Open(filepath.Join("testdata", "multi_frame.csv"))
+			require.NoError(t, err)
+
+			defer func() {
+				_ = fileReader.Close()
+			}()
+
+			frames, err := p.loadCsvFrames(fileReader, "multi_frame", csvOptions{})
+			require.NoError(t, err)
+			require.Len(t, frames, 3)
+			require.Equal(t, []string{"multi_frame", "errors", "multi_frame"}, []string{frames[0].Name, frames[1].Name, frames[2].Name})
+
+			testutil.CheckGoldenFrames(t, filepath.Join("testdata", "multi_frame.golden.txt"), frames...)
+		})
+
+		t.Run("Should load a single frame of CSV Text without sections", func(t *testing.T) {
+			frames, err := p.loadCsvFrames(strings.NewReader("\n\nTime,Value\n1,2\n\n\n"), "test", csvOptions{})
+			require.NoError(t, err)
+			require.Len(t, frames, 1)
+			require.Equal(t, 1, frames[0].Rows())
+		})
+
+		t.Run("Should not load the sections without a header", func(t *testing.T) {
+			for _, content := range []string{
+				"",
+				"\n\n",
+				"Time,Value\n1,2\n#frame: empty\n",
+				"#frame: first\n#frame: second\nTime,Value\n1,2\n",
+			} {
+				_, err := p.loadCsvFrames(strings.NewReader(content), "test", csvOptions{})
+				require.Error(t, err, content)
+			}
+		})
+
+		t.Run("Should only replay the files of a single frame", func(t *testing.T) {
+			require.NoError(t, ioutil.WriteFile(filepath.Join(cfg.DataPath, "multi.csv"), []byte("A\n1\n\nB\n2\n"), 0600))
+			p := &testDataPlugin{Cfg: setting.NewCfg()}
+			p.Cfg.TestData.CSVDirs = map[string]string{"data": cfg.DataPath}
+
+			frames, err := p.loadCsvFileWithOptions(1, "data/multi.csv", csvOptions{})
+			require.NoError(t, err)
+			require.Len(t, frames, 2)
+
+			_, err = p.loadCsvFile(1, "data/multi.csv")
+			require.Error(t, err)
+		})
+
 		t.Run("Should not allow non file name chars", func(t *testing.T) {
-			_, err := p.loadCsvFile("../population_by_state.csv")
+			_, err := p.loadCsvFile(1, "../population_by_state.csv")
 			require.Error(t, err)
 		})
 	})
+
+	t.Run("csv_dirs", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "samples")
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "nested"), 0750))
+		for _, name := range []string{"sales.csv", "notes.txt", "nested/other.csv"} {
+			require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte("Region, Sales\nEMEA, 10\n"), 0600))
+		}
+		require.NoError(t, ioutil.WriteFile(filepath.Join(cfg.DataPath, "secret.csv"), []byte("Key\nvalue\n"), 0600))
+
+		p := &testDataPlugin{Cfg: setting.NewCfg(), logger: log.New("test")}
+		p.Cfg.StaticRootPath = cfg.StaticRootPath
+		p.Cfg.TestData.CSVDirs = map[string]string{"samples": dir}
+
+		t.Run("Should load the files of the directories", func(t *testing.T) {
+			frame, err := p.loadCsvFile(1, "samples/sales.csv")
+			require.NoError(t, err)
+			require.Equal(t, "samples/sales.csv", frame.Name)
+			require.Equal(t, "Region", frame.Fields[0].Name)
+
+			_, err = p.loadCsvFile(1, "city_stats.csv")
+			require.NoError(t, err)
+		})
+
+		t.Run("Should not allow files outside of the directories", func(t *testing.T) {
+			for _, name := range []string{
+				"samples/../secret.csv",
+				"samples/nested/other.csv",
+				"samples/notes.txt",
+				"samples/sales.csv/../../secret.csv",
+				"unknown/sales.csv",
+				"/samples/sales.csv",
+				"nested/other.csv",
+			} {
+				_, err := p.loadCsvFile(1, name)
+				require.Error(t, err, name)
+			}
+		})
+
+		t.Run("Should list the files of the directories", func(t *testing.T) {
+			require.Equal(t, []csvDirectory{
+				{Name: "testdata", Files: []string{"city_stats.csv", "population_by_state.csv", "sensor_readings.csv"}},
+				{Name: "samples", Files: []string{"samples/sales.csv"}},
+			}, p.listCsvFiles(1))
+		})
+	})
+}
+
+type fakeCallResourceResponseSender struct {
+	resp *backend.CallResourceResponse
+}
+
+func (s *fakeCallResourceResponseSender) Send(resp *backend.CallResourceResponse) error {
+	s.resp = resp
+	return nil
+}
+
+func TestCSVUpload(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.DataPath = t.TempDir()
+	cfg.StaticRootPath = "../../../public"
+	p := &testDataPlugin{Cfg: cfg, logger: log.New("test")}
+
+	mux := http.NewServeMux()
+	p.registerRoutes(mux)
+	handler := httpadapter.New(mux)
+
+	upload := func(t *testing.T, orgID int64, role string, fileName string, content string) (int, map[string]interface{}) {
+		t.Helper()
+
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		file, err := form.CreateFormFile("file", fileName)
+		require.NoError(t, err)
+		_, err = file.Write([]byte(content))
+		require.NoError(t, err)
+		require.NoError(t, form.Close())
+
+		sender := &fakeCallResourceResponseSender{}
+		err = handler.CallResource(context.Background(), &backend.CallResourceRequest{
+			PluginContext: backend.PluginContext{OrgID: orgID, User: &backend.User{Login: "user", Role: role}},
+			Path:          "csv",
+			Method:        http.MethodPost,
+			URL:           "csv",
+			Headers:       map[string][]string{"Content-Type": {form.FormDataContentType()}},
+			Body:          body.Bytes(),
+		}, sender)
+		require.NoError(t, err)
+
+		result := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(sender.resp.Body, &result))
+		return sender.resp.Status, result
+	}
+
+	t.Run("Should store the uploaded files as queryable files", func(t *testing.T) {
+		status, result := upload(t, 1, "Editor", "sales.csv", "Time, Sales\n2021-06-01T08:00:00Z, 10\n")
+		require.Equal(t, http.StatusOK, status, result)
+		require.Equal(t, "uploads/sales.csv", result["fileName"])
+		require.Equal(t, []interface{}{"Time", "Sales"}, result["fields"])
+
+		frame, err := p.loadCsvFile(1, "uploads/sales.csv")
+		require.NoError(t, err)
+		require.Equal(t, "Sales", frame.Fields[1].Name)
+		require.Equal(t, 1, frame.Fields[1].Len())
+
+		require.Equal(t, csvDirectory{Name: "uploads", Files: []string{"uploads/sales.csv"}}, p.listCsvFiles(1)[1])
+	})
+
+	t.Run("Should replace the uploaded files with the same name", func(t *testing.T) {
+		status, _ := upload(t, 1, "Admin", "sales.csv", "Region, Sales\nEMEA, 10\nAPAC, 20\n")
+		require.Equal(t, http.StatusOK, status)
+
+		frame, err := p.loadCsvFile(1, "uploads/sales.csv")
+		require.NoError(t, err)
+		require.Equal(t, 2, frame.Fields[1].Len())
+	})
+
+	t.Run("Should keep the uploaded files of the organizations apart", func(t *testing.T) {
+		status, _ := upload(t, 2, "Editor", "costs.csv", "Region, Costs\nEMEA, 5\n")
+		require.Equal(t, http.StatusOK, status)
+
+		frame, err := p.loadCsvFile(2, "uploads/costs.csv")
+		require.NoError(t, err)
+		require.Equal(t, "Costs", frame.Fields[1].Name)
+		require.Equal(t, csvDirectory{Name: "uploads", Files: []string{"uploads/costs.csv"}}, p.listCsvFiles(2)[1])
+
+		_, err = p.loadCsvFile(1, "uploads/costs.csv")
+		require.Error(t, err)
+		_, err = p.loadCsvFile(2, "uploads/sales.csv")
+		require.Error(t, err)
+		require.Equal(t, csvDirectory{Name: "uploads", Files: []string{"uploads/sales.csv"}}, p.listCsvFiles(1)[1])
+	})
+
+	t.Run("Should not allow the viewers to upload files", func(t *testing.T) {
+		status, _ := upload(t, 1, "Viewer", "other.csv", "Region, Sales\nEMEA, 10\n")
+		require.Equal(t, http.StatusForbidden, status)
+	})
+
+	t.Run("Should not store the invalid files", func(t *testing.T) {
+		for name, content := range map[string]string{
+			"my sales.csv": "Region, Sales\nEMEA, 10\n",
+			"other.txt":    "Region, Sales\nEMEA, 10\n",
+			"ragged.csv":   "Region, Sales\nEMEA, 10, 20\n",
+			"empty.csv":    "",
+			"frames.csv":   "Region, Sales\nEMEA, 10\n\nHost, Up\nweb-1, true\n",
+		} {
+			status, _ := upload(t, 1, "Editor", name, content)
+			require.Equal(t, http.StatusBadRequest, status, name)
+		}
+
+		status, result := upload(t, 1, "Editor", "large.csv", "Value\n"+strings.Repeat("1\n", csvUploadMaxBytes/2))
+		require.Equal(t, http.StatusRequestEntityTooLarge, status)
+		require.Equal(t, "CSV files are limited to 10 MB", result["message"])
+
+		require.Equal(t, csvDirectory{Name: "uploads", Files: []string{"uploads/sales.csv"}}, p.listCsvFiles(1)[1])
+	})
 }
 
 func TestReadCSV(t *testing.T) {
@@ -0,0 +1,199 @@
+package testdatasource
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// csvOptions are the options of the parsing of CSV content. The zero value parses the content like the example files.
+type csvOptions struct {
+	// Delimiter separates the values of the lines, a comma when zero
+	Delimiter rune
+	// DecimalComma parses the numbers with a decimal comma, and optional dots separating the thousands, e.g. 1.234,5
+	DecimalComma bool
+	// NullValues are the case-insensitive values parsed as nulls in addition to the empty values, null when empty
+	NullValues []string
+	// Location is the timezone of the times without one, UTC when nil
+	Location *time.Location
+}
+
+// csvOptionsFromQuery returns the csvOptions of a query of the csv scenarios.
+func csvOptionsFromQuery(model *simplejson.Json) (csvOptions, error) {
+	json := model.Get("csvOptions")
+	opts := csvOptions{DecimalComma: json.Get("decimalComma").MustBool()}
+
+	if delimiter := json.Get("delimiter").MustString(); delimiter != "" {
+		if delimiter == `\t` {
+			delimiter = "\t"
+		}
+		r, size := utf8.DecodeRuneInString(delimiter)
+		if size != len(delimiter) || r == utf8.RuneError || r == '"' || r == '\r' || r == '\n' {
+			return opts, fmt.Errorf("invalid csv delimiter: %q", delimiter)
+		}
+		opts.Delimiter = r
+	}
+	if opts.DecimalComma && (opts.Delimiter == 0 || opts.Delimiter == ',') {
+		return opts, fmt.Errorf("csv values with a decimal comma need another delimiter than a comma")
+	}
+
+	for _, value := range json.Get("nullValues").MustStringArray() {
+		if value = strings.TrimSpace(value); value != "" {
+			opts.NullValues = append(opts.NullValues, value)
+		}
+	}
+
+	if timezone := json.Get("timezone").MustString(); timezone != "" {
+		location, err := time.LoadLocation(timezone)
+		if err != nil {
+			return opts, fmt.Errorf("invalid csv timezone: %q", timezone)
+		}
+		opts.Location = location
+	}
+	return opts, nil
+}
+
+func (o csvOptions) isNull(value string) bool {
+	if value == "" {
+		return true
+	}
+	if len(o.NullValues) == 0 {
+		return strings.EqualFold(value, "null")
+	}
+	for _, null := range o.NullValues {
+		if strings.EqualFold(value, null) {
+			return true
+		}
+	}
+	return false
+}
+
+func (o csvOptions) parseFloat(value string) (float64, error) {
+	if o.DecimalComma {
+		value = strings.ReplaceAll(strings.ReplaceAll(value, ".", ""), ",", ".")
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+// csvTimeLayouts are the layouts of the times of CSV values, besides the unix times in milliseconds.
+var csvTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// parseTime parses a unix time in milliseconds, or a time of the csvTimeLayouts in the timezone of the options when
+// it has none.
+func (o csvOptions) parseTime(value string) (time.Time, error) {
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(0, ms*int64(time.Millisecond)).UTC(), nil
+	}
+	if ms, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Unix(0, int64(ms*float64(time.Millisecond))).UTC(), nil
+	}
+
+	location := o.Location
+	if location == nil {
+		location = time.UTC
+	}
+	for _, layout := range csvTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, location); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", value)
+}
+
+// The type hints of the columns of CSV content, which annotate the names of the header, e.g. time#time or
+// host#label. The hints force the types of the fields, instead of guessing them by the values.
+const (
+	csvHintTime   = "time"
+	csvHintNumber = "number"
+	csvHintString = "string"
+	csvHintBool   = "bool"
+	// csvHintLabel takes the values as the labels of the other fields, converting the frame to the wide format
+	csvHintLabel = "label"
+)
+
+var csvHints = map[string]string{
+	"time":    csvHintTime,
+	"ts":      csvHintTime,
+	"number":  csvHintNumber,
+	"string":  csvHintString,
+	"bool":    csvHintBool,
+	"boolean": csvHintBool,
+	"label":   csvHintLabel,
+}
+
+// parseCsvHeader returns the name and type hint of a name of the header. The names with an unknown hint are kept
+// as is, since # is a valid character of names.
+func parseCsvHeader(header string) (string, string) {
+	i := strings.LastIndex(header, "#")
+	if i < 0 {
+		return header, ""
+	}
+	hint, ok := csvHints[strings.ToLower(strings.TrimSpace(header[i+1:]))]
+	if !ok {
+		return header, ""
+	}
+	return strings.TrimSpace(header[:i]), hint
+}
+
+// csvValuesToHintedField converts the values of a column to a field of the type of its hint, failing on the values
+// which aren't of the type.
+func csvValuesToHintedField(values []string, hint string, opts csvOptions) (*data.Field, error) {
+	var field *data.Field
+	var parse func(string) (interface{}, error)
+	switch hint {
+	case csvHintTime:
+		field = data.NewFieldFromFieldType(data.FieldTypeNullableTime, len(values))
+		parse = func(value string) (interface{}, error) {
+			return opts.parseTime(value)
+		}
+	case csvHintNumber:
+		field = data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, len(values))
+		parse = func(value string) (interface{}, error) {
+			v, err := opts.parseFloat(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q", value)
+			}
+			return v, nil
+		}
+	case csvHintBool:
+		field = data.NewFieldFromFieldType(data.FieldTypeNullableBool, len(values))
+		parse = func(value string) (interface{}, error) {
+			v, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid bool %q", value)
+			}
+			return v, nil
+		}
+	case csvHintString, csvHintLabel:
+		field = data.NewFieldFromFieldType(data.FieldTypeNullableString, len(values))
+		parse = func(value string) (interface{}, error) {
+			return value, nil
+		}
+	default:
+		return nil, fmt.Errorf("unknown csv type hint: %q", hint)
+	}
+
+	for idx, strVal := range values {
+		if opts.isNull(strVal) {
+			continue
+		}
+		val, err := parse(strVal)
+		if err != nil {
+			// the first line is the header
+			return nil, fmt.Errorf("line %d: %v", idx+2, err)
+		}
+		field.SetConcrete(idx, val)
+	}
+	return field, nil
+}
@@ -0,0 +1,133 @@
+package testdatasource
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSVTypeHints(t *testing.T) {
+	p := &testDataPlugin{}
+	load := func(t *testing.T, content string, opts csvOptions) *data.Frame {
+		t.Helper()
+		frame, err := p.loadCsvContent(strings.NewReader(content), "test", opts)
+		require.NoError(t, err)
+		return frame
+	}
+
+	t.Run("Should force the types of the hinted fields", func(t *testing.T) {
+		frame := load(t, "ts#time,value#number,code#string,up#bool,count,note#unknown\n"+
+			"2021-06-01 10:00:00,1,200,true,3,a\n"+
+			"2021-06-01T10:01:00+02:00,2.5,404,0,4,b\n"+
+			"1622541720000,,,null,5,c\n", csvOptions{})
+
+		require.Len(t, frame.Fields, 6)
+		assert.Equal(t, "ts", frame.Fields[0].Name)
+		assert.Equal(t, data.FieldTypeNullableTime, frame.Fields[0].Type())
+		assert.Equal(t, time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC), *frame.Fields[0].At(0).(*time.Time))
+		assert.Equal(t, time.Date(2021, 6, 1, 8, 1, 0, 0, time.UTC), *frame.Fields[0].At(1).(*time.Time))
+		assert.Equal(t, time.Date(2021, 6, 1, 10, 2, 0, 0, time.UTC), *frame.Fields[0].At(2).(*time.Time))
+
+		assert.Equal(t, data.FieldTypeNullableFloat64, frame.Fields[1].Type())
+		assert.Equal(t, 1.0, *frame.Fields[1].At(0).(*float64))
+		assert.Nil(t, frame.Fields[1].At(2))
+		assert.Equal(t, data.FieldTypeNullableString, frame.Fields[2].Type())
+		assert.Equal(t, "404", *frame.Fields[2].At(1).(*string))
+		assert.Equal(t, data.FieldTypeNullableBool, frame.Fields[3].Type())
+		assert.Equal(t, false, *frame.Fields[3].At(1).(*bool))
+		assert.Nil(t, frame.Fields[3].At(2))
+
+		// the fields without hints are still guessed, and the unknown hints are part of the names
+		assert.Equal(t, data.FieldTypeNullableInt64, frame.Fields[4].Type())
+		assert.Equal(t, "note#unknown", frame.Fields[5].Name)
+	})
+
+	t.Run("Should fail with the values which aren't of the hinted type", func(t *testing.T) {
+		_, err := p.loadCsvContent(strings.NewReader("time,value#number\n1,2\n2,abc\n"), "test", csvOptions{})
+		require.EqualError(t, err, `invalid values of field "value": line 3: invalid number "abc"`)
+
+		_, err = p.loadCsvContent(strings.NewReader("time#time,value\nyesterday,1\n"), "test", csvOptions{})
+		require.EqualError(t, err, `invalid values of field "time": line 2: invalid time "yesterday"`)
+	})
+
+	t.Run("Should parse the columns with the options", func(t *testing.T) {
+		berlin, err := time.LoadLocation("Europe/Berlin")
+		require.NoError(t, err)
+
+		frame := load(t, "time;value#number;ratio;name\n"+
+			"2021-06-01 10:00:00;1.234,5;0,5;a\n"+
+			"2021-06-01 10:01:00;-;n/a;N/A\n", csvOptions{
+			Delimiter:    ';',
+			DecimalComma: true,
+			NullValues:   []string{"-", "n/a"},
+			Location:     berlin,
+		})
+
+		require.Len(t, frame.Fields, 4)
+		assert.Equal(t, data.FieldTypeNullableTime, frame.Fields[0].Type())
+		assert.Equal(t, time.Date(2021, 6, 1, 8, 0, 0, 0, time.UTC), *frame.Fields[0].At(0).(*time.Time))
+		assert.Equal(t, 1234.5, *frame.Fields[1].At(0).(*float64))
+		assert.Nil(t, frame.Fields[1].At(1))
+		assert.Equal(t, data.FieldTypeNullableFloat64, frame.Fields[2].Type())
+		assert.Equal(t, 0.5, *frame.Fields[2].At(0).(*float64))
+		assert.Nil(t, frame.Fields[3].At(1))
+	})
+
+	t.Run("Should convert the label fields to the labels of the others", func(t *testing.T) {
+		frame := load(t, "time#time,host#label,value#number\n"+
+			"1622541600000,web-1,1\n"+
+			"1622541600000,web-2,2\n"+
+			"1622541660000,web-1,3\n"+
+			"1622541660000,web-2,4\n", csvOptions{})
+
+		require.Len(t, frame.Fields, 3)
+		assert.Equal(t, 2, frame.Fields[0].Len())
+		assert.Equal(t, data.Labels{"host": "web-1"}, frame.Fields[1].Labels)
+		assert.Equal(t, data.Labels{"host": "web-2"}, frame.Fields[2].Labels)
+		assert.Equal(t, 3.0, *frame.Fields[1].At(1).(*float64))
+		assert.Equal(t, 4.0, *frame.Fields[2].At(1).(*float64))
+
+		_, err := p.loadCsvContent(strings.NewReader("host#label,value\nweb-1,1\n"), "test", csvOptions{})
+		require.Error(t, err)
+	})
+}
+
+func TestCSVOptionsFromQuery(t *testing.T) {
+	options := func(csvOpts map[string]interface{}) (csvOptions, error) {
+		return csvOptionsFromQuery(simplejson.NewFromAny(map[string]interface{}{"csvOptions": csvOpts}))
+	}
+
+	t.Run("Should default to the options of the example files", func(t *testing.T) {
+		opts, err := csvOptionsFromQuery(simplejson.New())
+		require.NoError(t, err)
+		require.Equal(t, csvOptions{}, opts)
+	})
+
+	t.Run("Should read the options", func(t *testing.T) {
+		opts, err := options(map[string]interface{}{
+			"delimiter":    `\t`,
+			"decimalComma": true,
+			"nullValues":   []interface{}{"-", " "},
+			"timezone":     "America/New_York",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, '\t', opts.Delimiter)
+		assert.True(t, opts.DecimalComma)
+		assert.Equal(t, []string{"-"}, opts.NullValues)
+		assert.Equal(t, "America/New_York", opts.Location.String())
+	})
+
+	t.Run("Should fail with invalid options", func(t *testing.T) {
+		_, err := options(map[string]interface{}{"delimiter": ";;"})
+		assert.EqualError(t, err, `invalid csv delimiter: ";;"`)
+		_, err = options(map[string]interface{}{"decimalComma": true})
+		assert.EqualError(t, err, "csv values with a decimal comma need another delimiter than a comma")
+		_, err = options(map[string]interface{}{"timezone": "Mars/Olympus"})
+		assert.EqualError(t, err, `invalid csv timezone: "Mars/Olympus"`)
+	})
+}
@@ -0,0 +1,212 @@
+package testdatasource
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// csvStreamPathPrefix is the prefix of the paths of the streams replaying CSV files. The paths are
+// csv/<file name>/speed=<speed>/loop=<loop>/shift=<time shift>, so that the queries with the same options
+// share a stream.
+const csvStreamPathPrefix = "csv/"
+
+// csvStreamDefaultInterval is the interval between the rows of a CSV file replayed at speed 1 when the rows
+// have no time, or their times aren't increasing.
+const csvStreamDefaultInterval = time.Second
+
+type csvStreamConfig struct {
+	FileName string
+	// Speed is the replay speed, e.g. 2 replays the rows twice as fast as the intervals of their times
+	Speed float64
+	// Loop restarts the replay from the first row after the last one
+	Loop bool
+	// TimeShift replaces the times of the rows with the times they're replayed at
+	TimeShift bool
+}
+
+func (c csvStreamConfig) path() string {
+	return fmt.Sprintf("%s%s/speed=%s/loop=%t/shift=%t", csvStreamPathPrefix, c.FileName,
+		strconv.FormatFloat(c.Speed, 'f', -1, 64), c.Loop, c.TimeShift)
+}
+
+func parseCsvStreamPath(path string) (csvStreamConfig, error) {
+	// the file names of the csv directories are <directory name>/<file name>
+	parts := strings.Split(strings.TrimPrefix(path, csvStreamPathPrefix), "/")
+	if !strings.HasPrefix(path, csvStreamPathPrefix) || len(parts) < 4 || len(parts) > 5 {
+		return csvStreamConfig{}, fmt.Errorf("invalid csv stream path: %q", path)
+	}
+
+	conf := csvStreamConfig{FileName: strings.Join(parts[:len(parts)-3], "/")}
+	for _, part := range parts[len(parts)-3:] {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return conf, fmt.Errorf("invalid csv stream option: %q", part)
+		}
+
+		var err error
+		switch kv[0] {
+		case "speed":
+			conf.Speed, err = strconv.ParseFloat(kv[1], 64)
+			if err == nil && conf.Speed <= 0 {
+				err = fmt.Errorf("speed must be positive")
+			}
+		case "loop":
+			conf.Loop, err = strconv.ParseBool(kv[1])
+		case "shift":
+			conf.TimeShift, err = strconv.ParseBool(kv[1])
+		default:
+			err = fmt.Errorf("unknown option")
+		}
+		if err != nil {
+			return conf, fmt.Errorf("invalid csv stream option %q: %v", part, err)
+		}
+	}
+	return conf, nil
+}
+
+// handleCsvStreamScenario returns the schema of the CSV files of the queries with the channel replaying their rows,
+// which the frontend subscribes to.
+func (p *testDataPlugin) handleCsvStreamScenario(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	resp := backend.NewQueryDataResponse()
+
+	for _, q := range req.Queries {
+		model, err := simplejson.NewJson(q.JSON)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse query json %v", err)
+		}
+
+		fileName := model.Get("csvFileName").MustString()
+		if len(fileName) == 0 {
+			continue
+		}
+
+		conf := csvStreamConfig{
+			FileName:  fileName,
+			Speed:     model.GetPath("csvStream", "speed").MustFloat64(1),
+			Loop:      model.GetPath("csvStream", "loop").MustBool(false),
+			TimeShift: model.GetPath("csvStream", "timeShift").MustBool(false),
+		}
+		if conf.Speed <= 0 {
+			return nil, fmt.Errorf("invalid csv stream speed: %v", conf.Speed)
+		}
+
+		frame, err := p.loadCsvFile(req.PluginContext.OrgID, fileName)
+		if err != nil {
+			return nil, err
+		}
+
+		schema := emptyCsvFrame(frame)
+		schema.Meta = &data.FrameMeta{Channel: "plugin/testdata/" + conf.path()}
+
+		respD := resp.Responses[q.RefID]
+		respD.Frames = append(respD.Frames, schema)
+		resp.Responses[q.RefID] = respD
+	}
+
+	return resp, nil
+}
+
+// runCsvStream sends the rows of a CSV file one by one, at the intervals of their times divided by the speed.
+func (p *testStreamHandler) runCsvStream(ctx context.Context, orgID int64, path string, conf csvStreamConfig, sender *backend.StreamSender) error {
+	frame, err := p.loadCsvFile(orgID, conf.FileName)
+	if err != nil {
+		return err
+	}
+	rows, err := frame.RowLen()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("csv file %q has no rows", conf.FileName)
+	}
+
+	timeIndex := -1
+	for i, field := range frame.Fields {
+		if field.Type().Time() {
+			timeIndex = i
+			break
+		}
+	}
+
+	rowTime := func(row int) (time.Time, bool) {
+		if timeIndex < 0 {
+			return time.Time{}, false
+		}
+		v, ok := frame.Fields[timeIndex].ConcreteAt(row)
+		if !ok {
+			return time.Time{}, false
+		}
+		return v.(time.Time), true
+	}
+	interval := func(from, to int) time.Duration {
+		fromTime, fromOK := rowTime(from)
+		toTime, toOK := rowTime(to)
+		d := toTime.Sub(fromTime)
+		if !fromOK || !toOK || d <= 0 {
+			d = csvStreamDefaultInterval
+		}
+		return time.Duration(float64(d) / conf.Speed)
+	}
+	// loopInterval is the interval between the last and the first rows, the average interval of the rows
+	loopInterval := time.Duration(float64(csvStreamDefaultInterval) / conf.Speed)
+	if rows > 1 {
+		first, firstOK := rowTime(0)
+		last, lastOK := rowTime(rows - 1)
+		if firstOK && lastOK && last.After(first) {
+			loopInterval = time.Duration(float64(last.Sub(first)) / float64(rows-1) / conf.Speed)
+		}
+	}
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	next := time.Now()
+	for row := 0; ; {
+		select {
+		case <-ctx.Done():
+			p.logger.Debug("Stop streaming data for path", "path", path)
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		out := emptyCsvFrame(frame)
+		out.AppendRow(frame.RowCopy(row)...)
+		if conf.TimeShift && timeIndex >= 0 {
+			out.Fields[timeIndex].SetConcrete(0, next)
+		}
+		if err := sender.SendFrame(out, data.IncludeDataOnly); err != nil {
+			return err
+		}
+
+		if row == rows-1 {
+			if !conf.Loop {
+				p.logger.Debug("Replayed csv file", "path", path)
+				return nil
+			}
+			row = 0
+			next = next.Add(loopInterval)
+		} else {
+			row++
+			next = next.Add(interval(row-1, row))
+		}
+		timer.Reset(time.Until(next))
+	}
+}
+
+// emptyCsvFrame returns a copy of the frame of a CSV file without rows, nor the empty labels of the fields copied.
+func emptyCsvFrame(frame *data.Frame) *data.Frame {
+	empty := frame.EmptyCopy()
+	for _, field := range empty.Fields {
+		if len(field.Labels) == 0 {
+			field.Labels = nil
+		}
+	}
+	return empty
+}
@@ -0,0 +1,137 @@
+package testdatasource
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStreamPacketSender struct {
+	// rows are the values of the rows sent, stopping the stream after max rows
+	rows   [][]interface{}
+	max    int
+	cancel context.CancelFunc
+}
+
+func (s *fakeStreamPacketSender) Send(packet *backend.StreamPacket) error {
+	var frame struct {
+		Data struct {
+			Values [][]interface{} `json:"values"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(packet.Data, &frame); err != nil {
+		return err
+	}
+
+	row := []interface{}{}
+	for _, values := range frame.Data.Values {
+		row = append(row, values[0])
+	}
+	s.rows = append(s.rows, row)
+	if s.max > 0 && len(s.rows) >= s.max {
+		s.cancel()
+	}
+	return nil
+}
+
+func TestCSVStreamScenario(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.StaticRootPath = "../../../public"
+	p := &testDataPlugin{Cfg: cfg}
+	handler := newTestStreamHandler(log.New("test"), p.loadCsvFile, newSimEngine())
+
+	t.Run("Should return the schema of the file with the channel of the stream", func(t *testing.T) {
+		resp, err := p.handleCsvStreamScenario(context.Background(), &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{{
+				RefID: "A",
+				JSON: []byte(`{"csvFileName": "sensor_readings.csv",
+					"csvStream": {"speed": 2.5, "loop": true, "timeShift": true}}`),
+			}},
+		})
+		require.NoError(t, err)
+
+		frame := resp.Responses["A"].Frames[0]
+		assert.Equal(t, "plugin/testdata/csv/sensor_readings.csv/speed=2.5/loop=true/shift=true", frame.Meta.Channel)
+		require.Len(t, frame.Fields, 3)
+		assert.Equal(t, "Temperature", frame.Fields[1].Name)
+		assert.Equal(t, 0, frame.Fields[0].Len())
+	})
+
+	t.Run("Should fail with an invalid speed", func(t *testing.T) {
+		_, err := p.handleCsvStreamScenario(context.Background(), &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{{RefID: "A", JSON: []byte(`{"csvFileName": "city_stats.csv", "csvStream": {"speed": 0}}`)}},
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("Should parse the stream paths", func(t *testing.T) {
+		conf := csvStreamConfig{FileName: "city_stats.csv", Speed: 0.5, Loop: true}
+		parsed, err := parseCsvStreamPath(conf.path())
+		require.NoError(t, err)
+		assert.Equal(t, conf, parsed)
+
+		conf.FileName = "samples/sales.csv"
+		parsed, err = parseCsvStreamPath(conf.path())
+		require.NoError(t, err)
+		assert.Equal(t, conf, parsed)
+
+		for _, path := range []string{
+			"csv/city_stats.csv",
+			"csv/samples/nested/sales.csv/speed=1/loop=true/shift=false",
+			"csv/city_stats.csv/speed=0/loop=true/shift=false",
+			"csv/city_stats.csv/speed=1/loop=yes/shift=false",
+			"csv/city_stats.csv/speed=1/loop=true/drop=false",
+		} {
+			_, err := parseCsvStreamPath(path)
+			assert.Error(t, err, path)
+		}
+	})
+
+	t.Run("Should not subscribe to the streams of unknown files", func(t *testing.T) {
+		resp, err := handler.SubscribeStream(context.Background(), &backend.SubscribeStreamRequest{
+			Path: "csv/unknown.csv/speed=1/loop=false/shift=false",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, backend.SubscribeStreamStatusNotFound, resp.Status)
+	})
+
+	t.Run("Should replay the rows of the file", func(t *testing.T) {
+		sender := &fakeStreamPacketSender{}
+		err := handler.RunStream(context.Background(), &backend.RunStreamRequest{
+			Path: "csv/sensor_readings.csv/speed=10000/loop=false/shift=false",
+		}, backend.NewStreamSender(sender))
+		require.NoError(t, err)
+
+		require.Len(t, sender.rows, 60)
+		assert.Equal(t, []interface{}{float64(1622534400000), 21.5, 53.0}, sender.rows[0])
+		assert.Equal(t, float64(1622534410000), sender.rows[1][0])
+	})
+
+	t.Run("Should loop the rows of the file shifted to now", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		sender := &fakeStreamPacketSender{max: 70, cancel: cancel}
+
+		start := time.Now()
+		err := handler.RunStream(ctx, &backend.RunStreamRequest{
+			Path: "csv/sensor_readings.csv/speed=10000/loop=true/shift=true",
+		}, backend.NewStreamSender(sender))
+		require.ErrorIs(t, err, context.Canceled)
+
+		require.Len(t, sender.rows, 70)
+		assert.Equal(t, sender.rows[0][1:], sender.rows[60][1:])
+		first := sender.rows[0][0].(float64)
+		assert.GreaterOrEqual(t, first, float64(start.Add(-time.Millisecond).UnixNano()/int64(time.Millisecond)))
+		for i := 1; i < len(sender.rows); i++ {
+			// the rows are 10s apart, replayed at 1ms intervals
+			assert.Equal(t, first+float64(i), sender.rows[i][0])
+		}
+	})
+}
@@ -0,0 +1,297 @@
+package testdatasource
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	sdkhttpclient "github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"golang.org/x/sync/singleflight"
+)
+
+// csvURLTimeout is the timeout of the requests of the remote CSV files.
+const csvURLTimeout = 30 * time.Second
+
+// csvURLCache reads the remote CSV files of the allowed hosts, cached in a directory of the data path. The cached
+// files are read without requests during the cache TTL, and revalidated with their ETag or last modification time
+// after it.
+type csvURLCache struct {
+	// mu guards the files of the cache directory
+	mu      sync.Mutex
+	fetches singleflight.Group
+	hosts   []string
+	ttl     time.Duration
+	dir     string
+	client  *http.Client
+	now     func() time.Time
+}
+
+// csvURLCacheEntry is the metadata of a cached remote CSV file.
+type csvURLCacheEntry struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"lastModified,omitempty"`
+	Fetched      time.Time `json:"fetched"`
+}
+
+// newCsvURLCache returns the cache of the remote CSV files of the hosts, in the directory, which are only read from
+// the remote hosts without directory.
+func newCsvURLCache(hosts []string, ttl time.Duration, dir string) (*csvURLCache, error) {
+	c := &csvURLCache{hosts: hosts, ttl: ttl, dir: dir, now: time.Now}
+	timeouts := sdkhttpclient.DefaultTimeoutOptions
+	timeouts.Timeout = csvURLTimeout
+	client, err := sdkhttpclient.New(sdkhttpclient.Options{
+		Timeouts: &timeouts,
+		ConfigureClient: func(opts sdkhttpclient.Options, client *http.Client) {
+			// the redirects must stay on the allowed hosts too
+			client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+				if len(via) >= 10 {
+					return errors.New("stopped after 10 redirects")
+				}
+				return c.checkURL(req.URL)
+			}
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.client = client
+	return c, nil
+}
+
+// checkURL returns an error if the URL isn't an HTTP URL of an allowed host.
+func (c *csvURLCache) checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid csv url, only http and https urls are allowed: %q", u.Redacted())
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, allowed := range c.hosts {
+		if host == allowed || strings.HasPrefix(allowed, "*.") && strings.HasSuffix(host, allowed[1:]) {
+			return nil
+		}
+	}
+	return fmt.Errorf("the host of the csv url isn't allowed by the csv_url_hosts setting: %q", host)
+}
+
+func (c *csvURLCache) paths(rawURL string) (content string, meta string) {
+	sum := sha256.Sum256([]byte(rawURL))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(c.dir, name+".csv"), filepath.Join(c.dir, name+".json")
+}
+
+// read returns the content of the remote CSV file of the URL, from the cache when fresh or not modified. The stale
+// cached file is returned when the remote host fails. The concurrent reads of a URL share a single request, and the
+// cache is only locked to read and write its files, so that the slow hosts don't hold up the reads of the other URLs.
+func (c *csvURLCache) read(ctx context.Context, rawURL string) ([]byte, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv url: %v", err)
+	}
+	if err := c.checkURL(u); err != nil {
+		return nil, err
+	}
+	if c.dir == "" {
+		content, _, err := c.fetch(ctx, rawURL, nil)
+		return content, err
+	}
+
+	if content, ok := c.readFresh(rawURL); ok {
+		return content, nil
+	}
+
+	// the shared request outlives the queries it's made for, within the timeout of the client
+	result := c.fetches.DoChan(rawURL, func() (interface{}, error) {
+		return c.refresh(rawURL)
+	})
+	select {
+	case res := <-result:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.([]byte), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// readFresh returns the cached file of the URL when it was fetched during the TTL.
+func (c *csvURLCache) readFresh(rawURL string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	contentPath, metaPath := c.paths(rawURL)
+	entry, cached := c.readEntry(metaPath, rawURL)
+	if !cached || c.now().Sub(entry.Fetched) >= c.ttl {
+		return nil, false
+	}
+	// nolint:gosec
+	content, err := ioutil.ReadFile(contentPath)
+	return content, err == nil
+}
+
+// refresh fetches the remote CSV file of the URL, conditionally to the cached file if any, and caches it.
+func (c *csvURLCache) refresh(rawURL string) ([]byte, error) {
+	contentPath, metaPath := c.paths(rawURL)
+
+	var previous *csvURLCacheEntry
+	var stale []byte
+	c.mu.Lock()
+	if entry, cached := c.readEntry(metaPath, rawURL); cached {
+		// nolint:gosec
+		if content, err := ioutil.ReadFile(contentPath); err == nil {
+			previous, stale = &entry, content
+		}
+	}
+	c.mu.Unlock()
+
+	content, fetched, err := c.fetch(context.Background(), rawURL, previous)
+	if err != nil {
+		if stale != nil {
+			return stale, nil
+		}
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if content == nil {
+		// not modified
+		content = stale
+	} else if err := c.writeFile(contentPath, content); err != nil {
+		return nil, err
+	}
+
+	metaContent, err := json.Marshal(fetched)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.writeFile(metaPath, metaContent); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+func (c *csvURLCache) readEntry(metaPath string, rawURL string) (csvURLCacheEntry, bool) {
+	var entry csvURLCacheEntry
+	// nolint:gosec
+	content, err := ioutil.ReadFile(metaPath)
+	if err != nil || json.Unmarshal(content, &entry) != nil || entry.URL != rawURL {
+		return csvURLCacheEntry{}, false
+	}
+	return entry, true
+}
+
+// fetch requests the remote CSV file, conditionally to the previous entry if any. It returns nil content when the
+// file wasn't modified, and the entry of the response.
+func (c *csvURLCache) fetch(ctx context.Context, rawURL string, previous *csvURLCacheEntry) ([]byte, csvURLCacheEntry, error) {
+	entry := csvURLCacheEntry{URL: rawURL, Fetched: c.now()}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, entry, err
+	}
+	if previous != nil {
+		if previous.ETag != "" {
+			req.Header.Set("If-None-Match", previous.ETag)
+		}
+		if previous.LastModified != "" {
+			req.Header.Set("If-Modified-Since", previous.LastModified)
+		}
+	}
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, entry, fmt.Errorf("failed to fetch csv url: %w", err)
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode == http.StatusNotModified && previous != nil {
+		entry.ETag, entry.LastModified = previous.ETag, previous.LastModified
+		return nil, entry, nil
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, entry, fmt.Errorf("failed to fetch csv url: %s", res.Status)
+	}
+
+	content, err := ioutil.ReadAll(io.LimitReader(res.Body, csvUploadMaxBytes+1))
+	if err != nil {
+		return nil, entry, fmt.Errorf("failed to fetch csv url: %w", err)
+	}
+	if len(content) > csvUploadMaxBytes {
+		return nil, entry, fmt.Errorf("the csv file of the url is larger than %d bytes", csvUploadMaxBytes)
+	}
+	entry.ETag = res.Header.Get("ETag")
+	entry.LastModified = res.Header.Get("Last-Modified")
+	return content, entry, nil
+}
+
+// writeFile writes a file of the cache through a temporary file, so that the queries never read a partial file.
+func (c *csvURLCache) writeFile(path string, content []byte) error {
+	if err := os.MkdirAll(c.dir, 0750); err != nil {
+		return fmt.Errorf("failed to create csv cache directory: %v", err)
+	}
+	tmp, err := ioutil.TempFile(c.dir, ".fetch-*")
+	if err != nil {
+		return fmt.Errorf("failed to create csv cache file: %v", err)
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	_, err = tmp.Write(content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write csv cache file: %v", err)
+	}
+	return nil
+}
+
+// initCsvURLs creates the cache of the remote CSV files when the csv_url_hosts setting allows some hosts.
+func (p *testDataPlugin) initCsvURLs() error {
+	if len(p.Cfg.TestData.CSVURLHosts) == 0 {
+		return nil
+	}
+	dir := ""
+	if p.Cfg.DataPath != "" {
+		dir = filepath.Join(p.Cfg.DataPath, "testdata", "csv-cache")
+	}
+	cache, err := newCsvURLCache(p.Cfg.TestData.CSVURLHosts, p.Cfg.TestData.CSVURLCacheTTL, dir)
+	if err != nil {
+		return err
+	}
+	p.csvURLs = cache
+	return nil
+}
+
+// loadCsvURL loads the frames of a remote CSV file, named after the URL.
+func (p *testDataPlugin) loadCsvURL(ctx context.Context, rawURL string, opts csvOptions) (data.Frames, error) {
+	if p.csvURLs == nil {
+		return nil, errors.New("remote csv files are disabled, their hosts must be allowed by the csv_url_hosts setting")
+	}
+
+	content, err := p.csvURLs.read(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return p.loadCsvFrames(bytes.NewReader(content), rawURL, opts)
+}
@@ -0,0 +1,223 @@
+package testdatasource
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCsvURLCache(t *testing.T) {
+	requests := 0
+	etag := `"v1"`
+	content := "time,value\n1609459200000,1\n1609459260000,2\n"
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests++
+		switch req.URL.Path {
+		case "/data.csv":
+			if req.Header.Get("If-None-Match") == etag {
+				rw.WriteHeader(http.StatusNotModified)
+				return
+			}
+			rw.Header().Set("ETag", etag)
+			_, _ = rw.Write([]byte(content))
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	cache, err := newCsvURLCache([]string{serverURL.Hostname()}, time.Minute, t.TempDir())
+	require.NoError(t, err)
+	cache.now = func() time.Time { return now }
+	read := func(t *testing.T, path string) (string, error) {
+		t.Helper()
+		content, err := cache.read(context.Background(), server.URL+path)
+		return string(content), err
+	}
+
+	t.Run("Should read the remote files from the cache during the TTL", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			res, err := read(t, "/data.csv")
+			require.NoError(t, err)
+			assert.Equal(t, content, res)
+		}
+		assert.Equal(t, 1, requests)
+	})
+
+	t.Run("Should revalidate the remote files after the TTL", func(t *testing.T) {
+		now = now.Add(2 * time.Minute)
+		res, err := read(t, "/data.csv")
+		require.NoError(t, err)
+		assert.Equal(t, content, res)
+		assert.Equal(t, 2, requests)
+
+		etag, content = `"v2"`, "time,value\n1609459200000,3\n"
+		now = now.Add(2 * time.Minute)
+		res, err = read(t, "/data.csv")
+		require.NoError(t, err)
+		assert.Equal(t, content, res)
+		assert.Equal(t, 3, requests)
+	})
+
+	t.Run("Should return the stale files when the remote host fails", func(t *testing.T) {
+		server.Close()
+		now = now.Add(2 * time.Minute)
+		res, err := read(t, "/data.csv")
+		require.NoError(t, err)
+		assert.Equal(t, content, res)
+	})
+
+	t.Run("Should only read the urls of the allowed hosts", func(t *testing.T) {
+		for _, rawURL := range []string{
+			"http://example.com/data.csv",
+			"ftp://" + serverURL.Host + "/data.csv",
+			"file:///etc/passwd",
+			"http://127.0.0.1.example.com/data.csv",
+		} {
+			_, err := cache.read(context.Background(), rawURL)
+			assert.Error(t, err, rawURL)
+		}
+
+		wildcard := &csvURLCache{hosts: []string{"*.s3.amazonaws.com"}}
+		for rawURL, allowed := range map[string]bool{
+			"https://bucket.s3.amazonaws.com/data.csv":   true,
+			"https://BUCKET.S3.amazonaws.com/data.csv":   true,
+			"https://s3.amazonaws.com/data.csv":          false,
+			"https://bucket.s3.amazonaws.com.evil/a.csv": false,
+		} {
+			u, err := url.Parse(rawURL)
+			require.NoError(t, err)
+			assert.Equal(t, allowed, wildcard.checkURL(u) == nil, rawURL)
+		}
+	})
+}
+
+func TestCsvURLCacheConcurrentReads(t *testing.T) {
+	var slowRequests int32
+	slowStarted, releaseSlow := make(chan struct{}, 1), make(chan struct{})
+	content := "time,value\n1609459200000,1\n"
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/slow.csv" {
+			atomic.AddInt32(&slowRequests, 1)
+			select {
+			case slowStarted <- struct{}{}:
+			default:
+			}
+			<-releaseSlow
+		}
+		_, _ = rw.Write([]byte(content))
+	}))
+	t.Cleanup(server.Close)
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	cache, err := newCsvURLCache([]string{serverURL.Hostname()}, time.Minute, t.TempDir())
+	require.NoError(t, err)
+	_, err = cache.read(context.Background(), server.URL+"/fast.csv")
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	results := make(chan string, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := cache.read(context.Background(), server.URL+"/slow.csv")
+			assert.NoError(t, err)
+			results <- string(res)
+		}()
+	}
+	<-slowStarted
+
+	t.Run("Should read the cached files while another url is being fetched", func(t *testing.T) {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			res, err := cache.read(context.Background(), server.URL+"/fast.csv")
+			assert.NoError(t, err)
+			assert.Equal(t, content, string(res))
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the cached file wasn't read while another url was being fetched")
+		}
+	})
+
+	t.Run("Should stop waiting for the fetch when the query is canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := cache.read(ctx, server.URL+"/slow.csv")
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("Should fetch a url once for the concurrent reads", func(t *testing.T) {
+		close(releaseSlow)
+		wg.Wait()
+		close(results)
+		for res := range results {
+			assert.Equal(t, content, res)
+		}
+		assert.Equal(t, int32(1), atomic.LoadInt32(&slowRequests))
+	})
+}
+
+func TestCsvURLScenario(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/redirect.csv" {
+			http.Redirect(rw, req, "http://example.com/data.csv", http.StatusFound)
+			return
+		}
+		_, _ = rw.Write([]byte("name,value\na,1\nb,2\n"))
+	}))
+	t.Cleanup(server.Close)
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	query := func(p *testDataPlugin, csvURL string) (backend.DataResponse, error) {
+		resp, err := p.handleCsvFileScenario(context.Background(), &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{{RefID: "A", JSON: []byte(fmt.Sprintf(`{"csvUrl": %q}`, csvURL))}},
+		})
+		if err != nil {
+			return backend.DataResponse{}, err
+		}
+		return resp.Responses["A"], nil
+	}
+
+	t.Run("Should return the frames of the remote files", func(t *testing.T) {
+		cache, err := newCsvURLCache([]string{serverURL.Hostname()}, time.Minute, "")
+		require.NoError(t, err)
+		p := &testDataPlugin{logger: log.New("test"), csvURLs: cache}
+
+		dr, err := query(p, server.URL+"/data.csv")
+		require.NoError(t, err)
+		require.NoError(t, dr.Error)
+		require.Len(t, dr.Frames, 1)
+		assert.Equal(t, []string{"name", "value"}, fieldNames(dr.Frames[0]))
+		assert.Equal(t, 2, dr.Frames[0].Rows())
+
+		// the redirects to the other hosts aren't followed
+		_, err = query(p, server.URL+"/redirect.csv")
+		require.Error(t, err)
+	})
+
+	t.Run("Should fail when the remote files are disabled", func(t *testing.T) {
+		p := &testDataPlugin{logger: log.New("test")}
+		_, err := query(p, server.URL+"/data.csv")
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,403 @@
+package testdatasource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/common"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/types"
+)
+
+func (p *testDataPlugin) handleJSONFileScenario(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	return p.handleFileScenario(req, "jsonFileName", p.loadJSONFile)
+}
+
+func (p *testDataPlugin) handleParquetFileScenario(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	return p.handleFileScenario(req, "parquetFileName", p.loadParquetFile)
+}
+
+// handleFileScenario returns the frames of the files of the queries, with the names in the fileNameKey property.
+func (p *testDataPlugin) handleFileScenario(req *backend.QueryDataRequest, fileNameKey string,
+	load func(fileName string) (*data.Frame, error)) (*backend.QueryDataResponse, error) {
+	resp := backend.NewQueryDataResponse()
+
+	for _, q := range req.Queries {
+		model, err := simplejson.NewJson(q.JSON)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse query json %v", err)
+		}
+
+		fileName := model.Get(fileNameKey).MustString()
+
+		if len(fileName) == 0 {
+			continue
+		}
+
+		frame, err := load(fileName)
+		if err != nil {
+			return nil, err
+		}
+
+		respD := resp.Responses[q.RefID]
+		respD.Frames = append(respD.Frames, frame)
+		resp.Responses[q.RefID] = respD
+	}
+
+	return resp, nil
+}
+
+// testDataFilePath returns the path of a file of the testdata directory with the extension.
+func (p *testDataPlugin) testDataFilePath(fileName string, extension string) (string, error) {
+	validFileName := regexp.MustCompile(`^[\w_]+\.` + extension + `$`)
+
+	if !validFileName.MatchString(fileName) {
+		return "", fmt.Errorf("invalid %s file name: %q", extension, fileName)
+	}
+
+	return filepath.Join(p.Cfg.StaticRootPath, "testdata", fileName), nil
+}
+
+func (p *testDataPlugin) loadJSONFile(fileName string) (*data.Frame, error) {
+	filePath, err := p.testDataFilePath(fileName, "json")
+	if err != nil {
+		return nil, err
+	}
+
+	// Can ignore gosec G304 here, because we check the file pattern above
+	// nolint:gosec
+	fileReader, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed open file: %v", err)
+	}
+
+	defer func() {
+		if err := fileReader.Close(); err != nil {
+			p.logger.Warn("Failed to close file", "err", err, "path", fileName)
+		}
+	}()
+
+	return loadJSONContent(fileReader, fileName)
+}
+
+// loadJSONContent converts an array of JSON objects to a frame with a field by property. The properties of the
+// nested objects are flattened to fields named with the path of the property, e.g. "location.city", and the
+// nested arrays are converted to JSON strings.
+func loadJSONContent(ioReader io.Reader, name string) (*data.Frame, error) {
+	decoder := json.NewDecoder(ioReader)
+	decoder.UseNumber()
+
+	var rows []map[string]interface{}
+	if err := decoder.Decode(&rows); err != nil {
+		return nil, fmt.Errorf("failed to read JSON, expecting an array of objects: %v", err)
+	}
+
+	fieldNames := []string{}
+	fieldValues := map[string][]interface{}{}
+	for rowIndex, row := range rows {
+		flat := map[string]interface{}{}
+		flattenJSONObject("", row, flat)
+
+		keys := make([]string, 0, len(flat))
+		for key := range flat {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if _, ok := fieldValues[key]; !ok {
+				fieldNames = append(fieldNames, key)
+				fieldValues[key] = make([]interface{}, rowIndex, len(rows))
+			}
+		}
+		for _, key := range fieldNames {
+			fieldValues[key] = append(fieldValues[key], flat[key])
+		}
+	}
+
+	fields := make([]*data.Field, 0, len(fieldNames))
+	for _, fieldName := range fieldNames {
+		field := jsonValuesToField(fieldValues[fieldName])
+		// Check if the values are actually a time field
+		if strings.Contains(strings.ToLower(fieldName), "time") {
+			if timeField := toTimeField(field, csvOptions{}); timeField != nil {
+				field = timeField
+			}
+		}
+		field.Name = fieldName
+		fields = append(fields, field)
+	}
+
+	return data.NewFrame(name, fields...), nil
+}
+
+func flattenJSONObject(prefix string, object map[string]interface{}, flat map[string]interface{}) {
+	for key, value := range object {
+		if nested, ok := value.(map[string]interface{}); ok {
+			flattenJSONObject(prefix+key+".", nested, flat)
+			continue
+		}
+		flat[prefix+key] = value
+	}
+}
+
+// jsonValuesToField returns a field of the type of the values: bool, int64 when all the numbers are integers,
+// float64, or string, with the other values converted to JSON.
+func jsonValuesToField(values []interface{}) *data.Field {
+	isBool, isInt, isFloat := true, true, true
+	for _, value := range values {
+		switch v := value.(type) {
+		case nil:
+		case bool:
+			isInt, isFloat = false, false
+		case json.Number:
+			isBool = false
+			if _, err := v.Int64(); err != nil {
+				isInt = false
+			}
+		default:
+			isBool, isInt, isFloat = false, false, false
+		}
+	}
+
+	switch {
+	case isBool && !isInt:
+		field := data.NewFieldFromFieldType(data.FieldTypeNullableBool, len(values))
+		for idx, value := range values {
+			if v, ok := value.(bool); ok {
+				field.SetConcrete(idx, v)
+			}
+		}
+		return field
+	case isInt:
+		field := data.NewFieldFromFieldType(data.FieldTypeNullableInt64, len(values))
+		for idx, value := range values {
+			if v, ok := value.(json.Number); ok {
+				i, _ := v.Int64()
+				field.SetConcrete(idx, i)
+			}
+		}
+		return field
+	case isFloat:
+		field := data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, len(values))
+		for idx, value := range values {
+			if v, ok := value.(json.Number); ok {
+				f, _ := v.Float64()
+				field.SetConcrete(idx, f)
+			}
+		}
+		return field
+	}
+
+	field := data.NewFieldFromFieldType(data.FieldTypeNullableString, len(values))
+	for idx, value := range values {
+		switch v := value.(type) {
+		case nil:
+		case string:
+			field.SetConcrete(idx, v)
+		default:
+			b, err := json.Marshal(v)
+			if err == nil {
+				field.SetConcrete(idx, string(b))
+			}
+		}
+	}
+	return field
+}
+
+func (p *testDataPlugin) loadParquetFile(fileName string) (*data.Frame, error) {
+	filePath, err := p.testDataFilePath(fileName, "parquet")
+	if err != nil {
+		return nil, err
+	}
+
+	fileReader, err := local.NewLocalFileReader(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed open file: %v", err)
+	}
+
+	defer func() {
+		if err := fileReader.Close(); err != nil {
+			p.logger.Warn("Failed to close file", "err", err, "path", fileName)
+		}
+	}()
+
+	pr, err := reader.NewParquetColumnReader(fileReader, 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read parquet file: %v", err)
+	}
+	defer pr.ReadStop()
+
+	numRows := pr.GetNumRows()
+	fields := []*data.Field{}
+	for index, path := range pr.SchemaHandler.ValueColumns {
+		values, rls, _, err := pr.ReadColumnByIndex(int64(index), numRows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read parquet column %q: %v", path, err)
+		}
+
+		// The name of the nested columns is the path of the column, e.g. "location.city"
+		exPath := common.StrToPath(pr.SchemaHandler.InPathToExPath[path])
+		fieldName := strings.Join(exPath[1:], ".")
+
+		maxRL, err := pr.SchemaHandler.MaxRepetitionLevel(common.StrToPath(path))
+		if err != nil {
+			return nil, err
+		}
+		var field *data.Field
+		if maxRL > 0 {
+			// The values of the lists are in the element of the repeated group list of the column
+			fieldName = strings.TrimSuffix(fieldName, ".list.element")
+			field, err = parquetRepeatedValuesToField(values, rls)
+		} else {
+			schema := pr.SchemaHandler.SchemaElements[pr.SchemaHandler.MapIndex[path]]
+			field, err = parquetValuesToField(schema, values)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert parquet column %q: %v", fieldName, err)
+		}
+		field.Name = fieldName
+		fields = append(fields, field)
+	}
+
+	return data.NewFrame(fileName, fields...), nil
+}
+
+// parquetValuesToField returns a field with the values of a column, of the type of the column: bool, int32, int64,
+// float32, float64, time for the dates and the timestamps, float64 for the decimals, or string.
+func parquetValuesToField(schema *parquet.SchemaElement, values []interface{}) (*data.Field, error) {
+	var convert func(value interface{}) (interface{}, error)
+	var fieldType data.FieldType
+
+	logicalType := schema.GetLogicalType()
+	if logicalType == nil {
+		logicalType = parquet.NewLogicalType()
+	}
+	convertedType := parquet.ConvertedType(-1)
+	if schema.IsSetConvertedType() {
+		convertedType = schema.GetConvertedType()
+	}
+
+	switch schema.GetType() {
+	case parquet.Type_BOOLEAN:
+		fieldType = data.FieldTypeNullableBool
+	case parquet.Type_INT32:
+		switch {
+		case convertedType == parquet.ConvertedType_DATE || logicalType.IsSetDATE():
+			fieldType = data.FieldTypeNullableTime
+			convert = func(value interface{}) (interface{}, error) {
+				return time.Unix(int64(value.(int32))*24*60*60, 0).UTC(), nil
+			}
+		case convertedType == parquet.ConvertedType_DECIMAL || logicalType.IsSetDECIMAL():
+			fieldType = data.FieldTypeNullableFloat64
+			convert = func(value interface{}) (interface{}, error) {
+				return parseParquetDecimal(types.DECIMAL_INT_ToString(int64(value.(int32)),
+					int(schema.GetPrecision()), int(schema.GetScale())))
+			}
+		default:
+			fieldType = data.FieldTypeNullableInt32
+		}
+	case parquet.Type_INT64:
+		switch {
+		case convertedType == parquet.ConvertedType_TIMESTAMP_MILLIS || convertedType == parquet.ConvertedType_TIMESTAMP_MICROS ||
+			logicalType.IsSetTIMESTAMP():
+			unit := time.Millisecond
+			if convertedType == parquet.ConvertedType_TIMESTAMP_MICROS ||
+				(logicalType.IsSetTIMESTAMP() && logicalType.GetTIMESTAMP().GetUnit().IsSetMICROS()) {
+				unit = time.Microsecond
+			} else if logicalType.IsSetTIMESTAMP() && logicalType.GetTIMESTAMP().GetUnit().IsSetNANOS() {
+				unit = time.Nanosecond
+			}
+			fieldType = data.FieldTypeNullableTime
+			convert = func(value interface{}) (interface{}, error) {
+				return time.Unix(0, value.(int64)*int64(unit)).UTC(), nil
+			}
+		case convertedType == parquet.ConvertedType_DECIMAL || logicalType.IsSetDECIMAL():
+			fieldType = data.FieldTypeNullableFloat64
+			convert = func(value interface{}) (interface{}, error) {
+				return parseParquetDecimal(types.DECIMAL_INT_ToString(value.(int64),
+					int(schema.GetPrecision()), int(schema.GetScale())))
+			}
+		default:
+			fieldType = data.FieldTypeNullableInt64
+		}
+	case parquet.Type_INT96:
+		fieldType = data.FieldTypeNullableTime
+		convert = func(value interface{}) (interface{}, error) {
+			return types.INT96ToTime(value.(string)).UTC(), nil
+		}
+	case parquet.Type_FLOAT:
+		fieldType = data.FieldTypeNullableFloat32
+	case parquet.Type_DOUBLE:
+		fieldType = data.FieldTypeNullableFloat64
+	case parquet.Type_BYTE_ARRAY, parquet.Type_FIXED_LEN_BYTE_ARRAY:
+		if convertedType == parquet.ConvertedType_DECIMAL || logicalType.IsSetDECIMAL() {
+			fieldType = data.FieldTypeNullableFloat64
+			convert = func(value interface{}) (interface{}, error) {
+				return parseParquetDecimal(types.DECIMAL_BYTE_ARRAY_ToString([]byte(value.(string)),
+					int(schema.GetPrecision()), int(schema.GetScale())))
+			}
+		} else {
+			fieldType = data.FieldTypeNullableString
+		}
+	default:
+		return nil, fmt.Errorf("unsupported type %s", schema.GetType())
+	}
+
+	field := data.NewFieldFromFieldType(fieldType, len(values))
+	for idx, value := range values {
+		if value == nil {
+			continue
+		}
+		if convert != nil {
+			var err error
+			if value, err = convert(value); err != nil {
+				return nil, err
+			}
+		}
+		field.SetConcrete(idx, value)
+	}
+	return field, nil
+}
+
+func parseParquetDecimal(s string) (interface{}, error) {
+	return strconv.ParseFloat(s, 64)
+}
+
+// parquetRepeatedValuesToField returns a field with the values of a repeated column as JSON arrays by row. The
+// repetition level of the first value of a row is 0.
+func parquetRepeatedValuesToField(values []interface{}, rls []int32) (*data.Field, error) {
+	rows := [][]interface{}{}
+	for idx, value := range values {
+		if rls[idx] == 0 {
+			rows = append(rows, []interface{}{})
+		}
+		if value != nil {
+			rows[len(rows)-1] = append(rows[len(rows)-1], value)
+		}
+	}
+
+	field := data.NewFieldFromFieldType(data.FieldTypeNullableString, len(rows))
+	for idx, row := range rows {
+		b, err := json.Marshal(row)
+		if err != nil {
+			return nil, err
+		}
+		field.SetConcrete(idx, string(b))
+	}
+	return field, nil
+}
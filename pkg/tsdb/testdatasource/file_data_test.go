@@ -0,0 +1,83 @@
+package testdatasource
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileScenarios(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.DataPath = t.TempDir()
+	cfg.StaticRootPath = "../../../public"
+
+	p := &testDataPlugin{
+		Cfg: cfg,
+	}
+
+	t.Run("Should load files and convert to DataFrame", func(t *testing.T) {
+		loaders := map[string]func(string) (*data.Frame, error){
+			"server_inventory.json":    p.loadJSONFile,
+			"weather_stations.parquet": p.loadParquetFile,
+		}
+		for name, load := range loaders {
+			t.Run(name, func(t *testing.T) {
+				frame, err := load(name)
+				require.NoError(t, err)
+				require.NotNil(t, frame)
+
+				testutil.CheckGoldenFrames(t, filepath.Join("testdata", name+".golden.txt"), frame)
+			})
+		}
+	})
+
+	t.Run("Should not allow non file name chars", func(t *testing.T) {
+		_, err := p.loadJSONFile("../server_inventory.json")
+		require.Error(t, err)
+
+		_, err = p.loadParquetFile("../weather_stations.parquet")
+		require.Error(t, err)
+
+		_, err = p.loadParquetFile("population_by_state.csv")
+		require.Error(t, err)
+	})
+}
+
+func TestReadJSON(t *testing.T) {
+	frame, err := loadJSONContent(strings.NewReader(`[
+		{"a": 1, "b": 1.5, "c": true, "d": "x", "e": {"f": [1, 2]}},
+		{"a": null, "b": 2, "d": 3, "g": "y"}
+	]`), "test")
+	require.NoError(t, err)
+
+	frameToJSON, err := data.FrameToJSON(frame)
+	require.NoError(t, err)
+	out := frameToJSON.Bytes(data.IncludeAll)
+
+	require.JSONEq(t, `{"schema":{
+		"name":"test",
+		"fields":[
+			{"name":"a","type":"number","typeInfo":{"frame":"int64","nullable":true}},
+			{"name":"b","type":"number","typeInfo":{"frame":"float64","nullable":true}},
+			{"name":"c","type":"boolean","typeInfo":{"frame":"bool","nullable":true}},
+			{"name":"d","type":"string","typeInfo":{"frame":"string","nullable":true}},
+			{"name":"e.f","type":"string","typeInfo":{"frame":"string","nullable":true}},
+			{"name":"g","type":"string","typeInfo":{"frame":"string","nullable":true}}
+		]},"data":{
+			"values":[
+				[1,null],
+				[1.5,2],
+				[true,null],
+				["x","3"],
+				["[1,2]",null],
+				[null,"y"]
+		]}}`, string(out))
+
+	_, err = loadJSONContent(strings.NewReader(`{"a": 1}`), "test")
+	require.Error(t, err)
+}
@@ -0,0 +1,170 @@
+package testdatasource
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/gtime"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// maxRandomWalkSeries is the maximum number of series of the expanded series labels of a random walk query.
+const maxRandomWalkSeries = 1000
+
+// randomWalkOptions are the options of the random walk scenario simulating the pathologies of real data. The zero
+// value returns a plain random walk.
+type randomWalkOptions struct {
+	// Seed makes the walks of the query the same for the same time range, random walks when zero
+	Seed int64
+	// GapProbability is the probability of a point to be null
+	GapProbability float64
+	// SpikeProbability is the probability of a point to be an outlier
+	SpikeProbability float64
+	// CounterReset makes the series counters of the walk, reset to zero at every multiple of the interval
+	CounterReset time.Duration
+	// SeriesLabels are the labels of the series of the query, expanded from the seriesLabels of the query, which
+	// replace the seriesCount when set
+	SeriesLabels []data.Labels
+}
+
+// randomWalkOptionsFromQuery returns the randomWalkOptions of a query of the random walk scenario.
+func randomWalkOptionsFromQuery(model *simplejson.Json) (randomWalkOptions, error) {
+	opts := randomWalkOptions{
+		Seed:             querySeed(model),
+		GapProbability:   model.Get("gapProbability").MustFloat64(0),
+		SpikeProbability: model.Get("spikeProbability").MustFloat64(0),
+	}
+	if opts.GapProbability < 0 || opts.GapProbability > 1 {
+		return opts, fmt.Errorf("gap probability must be between 0 and 1: %v", opts.GapProbability)
+	}
+	if opts.SpikeProbability < 0 || opts.SpikeProbability > 1 {
+		return opts, fmt.Errorf("spike probability must be between 0 and 1: %v", opts.SpikeProbability)
+	}
+
+	if counterReset := model.Get("counterReset").MustString(""); counterReset != "" {
+		interval, err := gtime.ParseDuration(counterReset)
+		if err != nil || interval < time.Second {
+			return opts, fmt.Errorf("invalid counter reset interval, e.g. 1h: %q", counterReset)
+		}
+		opts.CounterReset = interval
+	}
+
+	labels, err := expandSeriesLabels(model.Get("seriesLabels").MustString(""))
+	if err != nil {
+		return opts, err
+	}
+	opts.SeriesLabels = labels
+	return opts, nil
+}
+
+// expandSeriesLabels returns the label sets of every combination of the values of the labels, e.g.
+// `host=*2, region=eu|us` expands to the 4 label sets of the hosts host-1 and host-2 in the regions eu and us, in the
+// order of the labels and their values.
+func expandSeriesLabels(text string) ([]data.Labels, error) {
+	text = strings.TrimSpace(strings.Trim(strings.TrimSpace(text), "{}"))
+	if text == "" {
+		return nil, nil
+	}
+
+	type label struct {
+		key    string
+		values []string
+	}
+	var labels []label
+	seriesCount := 1
+	for _, keyval := range strings.Split(text, ",") {
+		idx := strings.Index(keyval, "=")
+		if idx < 0 {
+			return nil, fmt.Errorf("invalid series label, e.g. host=*10 or region=eu|us: %q", strings.TrimSpace(keyval))
+		}
+		key := strings.TrimSpace(keyval[:idx])
+		values, err := seriesLabelValues(key, strings.Trim(strings.TrimSpace(keyval[idx+1:]), `"`))
+		if err != nil {
+			return nil, err
+		}
+		if seriesCount *= len(values); seriesCount > maxRandomWalkSeries {
+			return nil, fmt.Errorf("the series labels expand to more than %d series", maxRandomWalkSeries)
+		}
+		labels = append(labels, label{key: key, values: values})
+	}
+
+	sets := []data.Labels{{}}
+	for _, l := range labels {
+		expanded := make([]data.Labels, 0, len(sets)*len(l.values))
+		for _, set := range sets {
+			for _, value := range l.values {
+				next := set.Copy()
+				next[l.key] = value
+				expanded = append(expanded, next)
+			}
+		}
+		sets = expanded
+	}
+	return sets, nil
+}
+
+// seriesLabelValues returns the values of a series label, either N values key-1 to key-N for *N, or the values
+// separated by |.
+func seriesLabelValues(key, text string) ([]string, error) {
+	if key == "" {
+		return nil, fmt.Errorf("invalid series label without name: %q", text)
+	}
+
+	if strings.HasPrefix(text, "*") {
+		count, err := strconv.Atoi(text[1:])
+		if err != nil || count < 1 {
+			return nil, fmt.Errorf("invalid cardinality of series label %s: %q", key, text)
+		}
+		if count > maxRandomWalkSeries {
+			return nil, fmt.Errorf("the series labels expand to more than %d series", maxRandomWalkSeries)
+		}
+		values := make([]string, count)
+		for i := range values {
+			values[i] = fmt.Sprintf("%s-%d", key, i+1)
+		}
+		return values, nil
+	}
+
+	var values []string
+	seen := map[string]bool{}
+	for _, value := range strings.Split(text, "|") {
+		if value = strings.TrimSpace(value); value != "" && !seen[value] {
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("invalid series label %s without values", key)
+	}
+	return values, nil
+}
+
+// seriesLabels returns the labels of the series of the query, which also have the labels of the query.
+func (o randomWalkOptions) seriesLabels(model *simplejson.Json) []data.Labels {
+	if len(o.SeriesLabels) == 0 {
+		series := make([]data.Labels, model.Get("seriesCount").MustInt(1))
+		for i := range series {
+			series[i] = parseLabels(model)
+		}
+		return series
+	}
+
+	series := make([]data.Labels, len(o.SeriesLabels))
+	for i, set := range o.SeriesLabels {
+		labels := parseLabels(model)
+		for k, v := range set {
+			labels[k] = v
+		}
+		series[i] = labels
+	}
+	return series
+}
+
+// rand returns the source of the random values of a series of the query.
+func (o randomWalkOptions) rand(index int) *rand.Rand {
+	return newRand(o.Seed, index)
+}
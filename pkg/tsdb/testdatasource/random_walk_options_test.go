@@ -0,0 +1,154 @@
+package testdatasource
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRandomWalkOptions(t *testing.T) {
+	options := func(t *testing.T, json string) (randomWalkOptions, error) {
+		t.Helper()
+		model, err := simplejson.NewJson([]byte(json))
+		require.NoError(t, err)
+		return randomWalkOptionsFromQuery(model)
+	}
+
+	t.Run("Should parse the options", func(t *testing.T) {
+		opts, err := options(t, `{"seed": 42, "gapProbability": 0.1, "spikeProbability": 0.05, "counterReset": "1h"}`)
+		require.NoError(t, err)
+		assert.Equal(t, randomWalkOptions{
+			Seed:             42,
+			GapProbability:   0.1,
+			SpikeProbability: 0.05,
+			CounterReset:     time.Hour,
+		}, opts)
+	})
+
+	t.Run("Should fail with invalid options", func(t *testing.T) {
+		for _, json := range []string{
+			`{"gapProbability": 1.5}`,
+			`{"spikeProbability": -0.1}`,
+			`{"counterReset": "soon"}`,
+			`{"counterReset": "10ms"}`,
+			`{"seriesLabels": "host"}`,
+			`{"seriesLabels": "host=*0"}`,
+			`{"seriesLabels": "=a|b"}`,
+			`{"seriesLabels": "host=|"}`,
+			`{"seriesLabels": "host=*100, pod=*20"}`,
+		} {
+			_, err := options(t, json)
+			assert.Error(t, err, json)
+		}
+	})
+
+	t.Run("Should expand the series labels", func(t *testing.T) {
+		labels, err := expandSeriesLabels(`{host=*2, region="eu|us"}`)
+		require.NoError(t, err)
+		assert.Equal(t, []data.Labels{
+			{"host": "host-1", "region": "eu"},
+			{"host": "host-1", "region": "us"},
+			{"host": "host-2", "region": "eu"},
+			{"host": "host-2", "region": "us"},
+		}, labels)
+
+		labels, err = expandSeriesLabels(" ")
+		require.NoError(t, err)
+		assert.Nil(t, labels)
+	})
+}
+
+func TestRandomWalkScenarioOptions(t *testing.T) {
+	p := &testDataPlugin{}
+	from := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	query := func(t *testing.T, json string) backend.DataResponse {
+		t.Helper()
+		q := backend.DataQuery{
+			RefID:         "A",
+			TimeRange:     backend.TimeRange{From: from, To: from.Add(2 * time.Hour)},
+			Interval:      time.Minute,
+			MaxDataPoints: 120,
+			JSON:          []byte(json),
+		}
+		resp, err := p.handleRandomWalkScenario(context.Background(), &backend.QueryDataRequest{Queries: []backend.DataQuery{q}})
+		require.NoError(t, err)
+		return resp.Responses["A"]
+	}
+	values := func(frame *data.Frame) []*float64 {
+		values := make([]*float64, frame.Fields[1].Len())
+		for i := range values {
+			values[i] = frame.Fields[1].At(i).(*float64)
+		}
+		return values
+	}
+
+	t.Run("Should return the same walks for the same seed", func(t *testing.T) {
+		first := query(t, `{"seed": 7, "seriesCount": 2}`)
+		second := query(t, `{"seed": 7, "seriesCount": 2}`)
+		require.NoError(t, first.Error)
+		require.Len(t, first.Frames, 2)
+		assert.Equal(t, values(first.Frames[0]), values(second.Frames[0]))
+		assert.Equal(t, values(first.Frames[1]), values(second.Frames[1]))
+		assert.NotEqual(t, values(first.Frames[0]), values(first.Frames[1]))
+	})
+
+	t.Run("Should add gaps and spikes without changing the walk", func(t *testing.T) {
+		plain := values(query(t, `{"seed": 7}`).Frames[0])
+		gaps := values(query(t, `{"seed": 7, "gapProbability": 0.2}`).Frames[0])
+		spikes := values(query(t, `{"seed": 7, "spikeProbability": 0.1}`).Frames[0])
+		require.Len(t, gaps, len(plain))
+		require.Len(t, spikes, len(plain))
+
+		nulls, outliers := 0, 0
+		for i := range plain {
+			if gaps[i] == nil {
+				nulls++
+			} else {
+				assert.Equal(t, *plain[i], *gaps[i])
+			}
+			if *spikes[i] != *plain[i] {
+				outliers++
+				assert.GreaterOrEqual(t, math.Abs(*spikes[i]-*plain[i]), 10.0)
+			}
+		}
+		assert.Greater(t, nulls, 0)
+		assert.Less(t, nulls, len(plain))
+		assert.Greater(t, outliers, 0)
+		assert.Less(t, outliers, len(plain))
+	})
+
+	t.Run("Should return counters reset at the interval", func(t *testing.T) {
+		dr := query(t, `{"seed": 7, "counterReset": "30m", "min": 1}`)
+		require.NoError(t, dr.Error)
+		counter := values(dr.Frames[0])
+		times := dr.Frames[0].Fields[0]
+		for i := 1; i < len(counter); i++ {
+			if times.At(i).(*time.Time).Minute()%30 == 0 {
+				assert.Less(t, *counter[i], *counter[i-1], "reset at %d", i)
+			} else {
+				assert.Greater(t, *counter[i], *counter[i-1], "increase at %d", i)
+			}
+		}
+	})
+
+	t.Run("Should return a series per label set", func(t *testing.T) {
+		dr := query(t, `{"seriesCount": 5, "labels": "{job=\"api\"}", "seriesLabels": "host=*3, region=eu|us"}`)
+		require.NoError(t, dr.Error)
+		require.Len(t, dr.Frames, 6)
+		assert.Equal(t, data.Labels{"job": "api", "host": "host-1", "region": "eu"}, dr.Frames[0].Fields[1].Labels)
+		assert.Equal(t, data.Labels{"job": "api", "host": "host-3", "region": "us"}, dr.Frames[5].Fields[1].Labels)
+	})
+
+	t.Run("Should return an error with invalid options", func(t *testing.T) {
+		dr := query(t, `{"gapProbability": 2}`)
+		require.Error(t, dr.Error)
+		require.Empty(t, dr.Frames)
+	})
+}
@@ -2,15 +2,18 @@ package testdatasource
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"time"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
 )
@@ -18,6 +21,8 @@ import (
 func (p *testDataPlugin) registerRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/", p.testGetHandler)
 	mux.HandleFunc("/scenarios", p.getScenariosHandler)
+	mux.HandleFunc("/csv-files", p.getCsvFilesHandler)
+	mux.HandleFunc("/csv", p.uploadCsvHandler)
 	mux.HandleFunc("/stream", p.testStreamHandler)
 	mux.Handle("/test", createJSONHandler(p.logger))
 	mux.Handle("/test/json", createJSONHandler(p.logger))
@@ -50,10 +55,11 @@ func (p *testDataPlugin) getScenariosHandler(rw http.ResponseWriter, req *http.R
 	for _, scenarioID := range scenarioIds {
 		scenario := p.scenarios[scenarioID]
 		result = append(result, map[string]interface{}{
-			"id":          scenario.ID,
-			"name":        scenario.Name,
-			"description": scenario.Description,
-			"stringInput": scenario.StringInput,
+			"id":           scenario.ID,
+			"name":         scenario.Name,
+			"description":  scenario.Description,
+			"stringInput":  scenario.StringInput,
+			"defaultQuery": scenario.DefaultQuery,
 		})
 	}
 
@@ -69,6 +75,105 @@ func (p *testDataPlugin) getScenariosHandler(rw http.ResponseWriter, req *http.R
 	}
 }
 
+func (p *testDataPlugin) getCsvFilesHandler(rw http.ResponseWriter, req *http.Request) {
+	bytes, err := json.Marshal(p.listCsvFiles(httpadapter.PluginConfigFromContext(req.Context()).OrgID))
+	if err != nil {
+		p.logger.Error("Failed to marshal response body to JSON", "error", err)
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	if _, err := rw.Write(bytes); err != nil {
+		p.logger.Error("Failed to write response", "error", err)
+	}
+}
+
+// uploadCsvHandler stores the CSV file of the "file" field of a multipart form, so that the CSV scenarios of the
+// organization can read it as uploads/<file name>. Only the editors and admins can upload files.
+func (p *testDataPlugin) uploadCsvHandler(rw http.ResponseWriter, req *http.Request) {
+	p.logger.Debug("Received resource call", "url", req.URL.String(), "method", req.Method)
+
+	if req.Method != http.MethodPost {
+		p.writeJSONMessage(rw, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	user := httpadapter.UserFromContext(req.Context())
+	if user == nil || (user.Role != string(models.ROLE_EDITOR) && user.Role != string(models.ROLE_ADMIN)) {
+		p.writeJSONMessage(rw, http.StatusForbidden, "Permission denied")
+		return
+	}
+	orgID := httpadapter.PluginConfigFromContext(req.Context()).OrgID
+
+	// leave room for the boundaries and headers of the form
+	req.Body = http.MaxBytesReader(rw, req.Body, csvUploadMaxBytes+1<<20)
+	reader, err := req.MultipartReader()
+	if err != nil {
+		p.writeJSONMessage(rw, http.StatusBadRequest, "Expected a multipart form")
+		return
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			p.writeJSONMessage(rw, http.StatusBadRequest, "Missing file field")
+			return
+		}
+		if err != nil {
+			p.writeJSONMessage(rw, http.StatusBadRequest, "Failed to read form: "+err.Error())
+			return
+		}
+		if part.FormName() != "file" {
+			continue
+		}
+
+		content, err := ioutil.ReadAll(io.LimitReader(part, csvUploadMaxBytes+1))
+		if err != nil {
+			p.writeJSONMessage(rw, http.StatusBadRequest, "Failed to read file: "+err.Error())
+			return
+		}
+		if len(content) > csvUploadMaxBytes {
+			p.writeJSONMessage(rw, http.StatusRequestEntityTooLarge,
+				fmt.Sprintf("CSV files are limited to %d MB", csvUploadMaxBytes>>20))
+			return
+		}
+
+		frame, err := p.saveCsvUpload(orgID, filepath.Base(part.FileName()), content)
+		if err != nil {
+			p.writeJSONMessage(rw, http.StatusBadRequest, "Invalid CSV file: "+err.Error())
+			return
+		}
+		p.logger.Info("Uploaded csv file", "file", frame.Name, "user", user.Login, "orgId", orgID)
+
+		fields := make([]string, 0, len(frame.Fields))
+		for _, field := range frame.Fields {
+			fields = append(fields, field.Name)
+		}
+		p.writeJSON(rw, http.StatusOK, map[string]interface{}{
+			"message":  "CSV file uploaded",
+			"fileName": frame.Name,
+			"fields":   fields,
+		})
+		return
+	}
+}
+
+func (p *testDataPlugin) writeJSONMessage(rw http.ResponseWriter, status int, message string) {
+	p.writeJSON(rw, status, map[string]interface{}{"message": message})
+}
+
+func (p *testDataPlugin) writeJSON(rw http.ResponseWriter, status int, body interface{}) {
+	bytes, err := json.Marshal(body)
+	if err != nil {
+		p.logger.Error("Failed to marshal response body to JSON", "error", err)
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	if _, err := rw.Write(bytes); err != nil {
+		p.logger.Error("Failed to write response", "error", err)
+	}
+}
+
 func (p *testDataPlugin) testStreamHandler(rw http.ResponseWriter, req *http.Request) {
 	p.logger.Debug("Received resource call", "url", req.URL.String(), "method", req.Method)
 
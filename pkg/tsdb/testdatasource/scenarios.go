@@ -40,46 +40,67 @@ const (
 	nodeGraphQuery                    queryType = "node_graph"
 	csvFileQueryType                  queryType = "csv_file"
 	csvContentQueryType               queryType = "csv_content"
+	csvStreamQueryType                queryType = "csv_stream"
+	jsonFileQueryType                 queryType = "json_file"
+	parquetFileQueryType              queryType = "parquet_file"
+	chaosQueryType                    queryType = "chaos"
+	simQueryType                      queryType = "sim"
 )
 
 type queryType string
 
+// Scenario is a scenario of the testdata data source, selected by the scenarioId or queryType of the queries.
 type Scenario struct {
 	ID          string `json:"id"`
 	Name        string `json:"name"`
 	StringInput string `json:"stringInput"`
 	Description string `json:"description"`
-	handler     backend.QueryDataHandlerFunc
+	// DefaultQuery is the JSON of the query model of the new queries of the scenario, optional.
+	DefaultQuery json.RawMessage `json:"defaultQuery,omitempty"`
+	// Handler handles the queries of the scenario.
+	Handler backend.QueryDataHandlerFunc `json:"-"`
 }
 
 func (p *testDataPlugin) registerScenario(scenario *Scenario) {
 	p.scenarios[scenario.ID] = scenario
-	p.queryMux.HandleFunc(scenario.ID, scenario.handler)
+	p.queryMux.HandleFunc(scenario.ID, scenario.Handler)
+}
+
+// registerCustomScenarios registers the scenarios of other packages, except the ones with the ID of a built-in
+// scenario, which must be registered before.
+func (p *testDataPlugin) registerCustomScenarios(scenarios []*Scenario) {
+	for _, scenario := range scenarios {
+		if _, exists := p.scenarios[scenario.ID]; exists {
+			p.logger.Warn("Skipping scenario with the ID of another scenario", "scenarioId", scenario.ID)
+			continue
+		}
+		p.registerScenario(scenario)
+	}
 }
 
 func (p *testDataPlugin) registerScenarios() {
 	p.registerScenario(&Scenario{
 		ID:      string(exponentialHeatmapBucketDataQuery),
 		Name:    "Exponential heatmap bucket data",
-		handler: p.handleExponentialHeatmapBucketDataScenario,
+		Handler: p.handleExponentialHeatmapBucketDataScenario,
 	})
 
 	p.registerScenario(&Scenario{
 		ID:      string(linearHeatmapBucketDataQuery),
 		Name:    "Linear heatmap bucket data",
-		handler: p.handleLinearHeatmapBucketDataScenario,
+		Handler: p.handleLinearHeatmapBucketDataScenario,
 	})
 
 	p.registerScenario(&Scenario{
 		ID:      string(randomWalkQuery),
 		Name:    "Random Walk",
-		handler: p.handleRandomWalkScenario,
+		Handler: p.handleRandomWalkScenario,
 	})
 
 	p.registerScenario(&Scenario{
 		ID:      string(predictablePulseQuery),
 		Name:    "Predictable Pulse",
-		handler: p.handlePredictablePulseScenario,
+		Handler: p.handlePredictablePulseScenario,
 		Description: `Predictable Pulse returns a pulse wave where there is a datapoint every timeStepSeconds.
 The wave cycles at timeStepSeconds*(onCount+offCount).
 The cycle of the wave is based off of absolute time (from the epoch) which makes it predictable.
@@ -89,93 +110,93 @@ Timestamps will line up evenly on timeStepSeconds (For example, 60 seconds means
 	p.registerScenario(&Scenario{
 		ID:      string(predictableCSVWaveQuery),
 		Name:    "Predictable CSV Wave",
-		handler: p.handlePredictableCSVWaveScenario,
+		Handler: p.handlePredictableCSVWaveScenario,
 	})
 
 	p.registerScenario(&Scenario{
 		ID:      string(randomWalkTableQuery),
 		Name:    "Random Walk Table",
-		handler: p.handleRandomWalkTableScenario,
+		Handler: p.handleRandomWalkTableScenario,
 	})
 
 	p.registerScenario(&Scenario{
 		ID:          string(randomWalkSlowQuery),
 		Name:        "Slow Query",
 		StringInput: "5s",
-		handler:     p.handleRandomWalkSlowScenario,
+		Handler:     p.handleRandomWalkSlowScenario,
 	})
 
 	p.registerScenario(&Scenario{
 		ID:      string(noDataPointsQuery),
 		Name:    "No Data Points",
-		handler: p.handleClientSideScenario,
+		Handler: p.handleClientSideScenario,
 	})
 
 	p.registerScenario(&Scenario{
 		ID:      string(datapointsOutsideRangeQuery),
 		Name:    "Datapoints Outside Range",
-		handler: p.handleDatapointsOutsideRangeScenario,
+		Handler: p.handleDatapointsOutsideRangeScenario,
 	})
 
 	p.registerScenario(&Scenario{
 		ID:          string(csvMetricValuesQuery),
 		Name:        "CSV Metric Values",
 		StringInput: "1,20,90,30,5,0",
-		handler:     p.handleCSVMetricValuesScenario,
+		Handler:     p.handleCSVMetricValuesScenario,
 	})
 
 	p.registerScenario(&Scenario{
 		ID:      string(streamingClientQuery),
 		Name:    "Streaming Client",
-		handler: p.handleClientSideScenario,
+		Handler: p.handleClientSideScenario,
 	})
 
 	p.registerScenario(&Scenario{
 		ID:      string(liveQuery),
 		Name:    "Grafana Live",
-		handler: p.handleClientSideScenario,
+		Handler: p.handleClientSideScenario,
 	})
 
 	p.registerScenario(&Scenario{
 		ID:      string(grafanaAPIQuery),
 		Name:    "Grafana API",
-		handler: p.handleClientSideScenario,
+		Handler: p.handleClientSideScenario,
 	})
 
 	p.registerScenario(&Scenario{
 		ID:      string(arrowQuery),
 		Name:    "Load Apache Arrow Data",
-		handler: p.handleArrowScenario,
+		Handler: p.handleArrowScenario,
 	})
 
 	p.registerScenario(&Scenario{
 		ID:      string(annotationsQuery),
 		Name:    "Annotations",
-		handler: p.handleClientSideScenario,
+		Handler: p.handleClientSideScenario,
 	})
 
 	p.registerScenario(&Scenario{
 		ID:      string(tableStaticQuery),
 		Name:    "Table Static",
-		handler: p.handleTableStaticScenario,
+		Handler: p.handleTableStaticScenario,
 	})
 
 	p.registerScenario(&Scenario{
 		ID:      string(randomWalkWithErrorQuery),
 		Name:    "Random Walk (with error)",
-		handler: p.handleRandomWalkWithErrorScenario,
+		Handler: p.handleRandomWalkWithErrorScenario,
 	})
 
 	p.registerScenario(&Scenario{
 		ID:      string(serverError500Query),
 		Name:    "Server Error (500)",
-		handler: p.handleServerError500Scenario,
+		Handler: p.handleServerError500Scenario,
 	})
 
 	p.registerScenario(&Scenario{
 		ID:      string(logsQuery),
 		Name:    "Logs",
-		handler: p.handleLogsScenario,
+		Handler: p.handleLogsScenario,
 	})
 
 	p.registerScenario(&Scenario{
@@ -186,13 +207,47 @@ Timestamps will line up evenly on timeStepSeconds (For example, 60 seconds means
 	p.registerScenario(&Scenario{
 		ID:      string(csvFileQueryType),
 		Name:    "CSV File",
-		handler: p.handleCsvFileScenario,
+		Handler: p.handleCsvFileScenario,
 	})
 
 	p.registerScenario(&Scenario{
 		ID:      string(csvContentQueryType),
 		Name:    "CSV Content",
-		handler: p.handleCsvContentScenario,
+		Handler: p.handleCsvContentScenario,
+	})
+
+	p.registerScenario(&Scenario{
+		ID:      string(csvStreamQueryType),
+		Name:    "CSV Stream",
+		Handler: p.handleCsvStreamScenario,
+	})
+
+	p.registerScenario(&Scenario{
+		ID:      string(jsonFileQueryType),
+		Name:    "JSON File",
+		Handler: p.handleJSONFileScenario,
+	})
+
+	p.registerScenario(&Scenario{
+		ID:      string(parquetFileQueryType),
+		Name:    "Parquet File",
+		Handler: p.handleParquetFileScenario,
+	})
+
+	p.registerScenario(&Scenario{
+		ID:      string(chaosQueryType),
+		Name:    "Chaos",
+		Handler: p.handleChaosScenario,
+		Description: `Chaos returns the failures of real data sources to test the query caching, timeouts and mixed panels:
+query errors, partial results with an error, or oversized responses, with a probability and after a latency with jitter.`,
+	})
+
+	p.registerScenario(&Scenario{
+		ID:      string(simQueryType),
+		Name:    "Simulation",
+		Handler: p.handleSimScenario,
+		Description: `Simulation returns the values of a stateful simulation: a tank, the requests of an HTTP server or a CPU.
+The queries and streams with the same simulation and key share its state between the refreshes.`,
 	})
 
 	p.queryMux.HandleFunc("", p.handleFallbackScenario)
@@ -229,7 +284,7 @@ func (p *testDataPlugin) handleFallbackScenario(ctx context.Context, req *backen
 				Headers:       req.Headers,
 				Queries:       queries,
 			}
-			if sResp, err := scenario.handler(ctx, sReq); err != nil {
+			if sResp, err := scenario.Handler(ctx, sReq); err != nil {
 				p.logger.Error("Failed to handle scenario", "scenarioId", scenarioID, "error", err)
 			} else {
 				for refID, dr := range sResp.Responses {
@@ -250,13 +305,18 @@ func (p *testDataPlugin) handleRandomWalkScenario(ctx context.Context, req *back
 		if err != nil {
 			continue
 		}
-		seriesCount := model.Get("seriesCount").MustInt(1)
-
-		for i := 0; i < seriesCount; i++ {
-			respD := resp.Responses[q.RefID]
-			respD.Frames = append(respD.Frames, randomWalk(q, model, i))
+		respD := resp.Responses[q.RefID]
+		opts, err := randomWalkOptionsFromQuery(model)
+		if err != nil {
+			respD.Error = err
 			resp.Responses[q.RefID] = respD
+			continue
+		}
+
+		for i, labels := range opts.seriesLabels(model) {
+			respD.Frames = append(respD.Frames, randomWalk(q, model, opts, i, labels))
 		}
+		resp.Responses[q.RefID] = respD
 	}
 
 	return resp, nil
@@ -340,7 +400,7 @@ func (p *testDataPlugin) handleRandomWalkWithErrorScenario(ctx context.Context,
 		}
 
 		respD := resp.Responses[q.RefID]
-		respD.Frames = append(respD.Frames, randomWalk(q, model, 0))
+		respD.Frames = append(respD.Frames, randomWalk(q, model, randomWalkOptions{Seed: querySeed(model)}, 0, parseLabels(model)))
 		respD.Error = fmt.Errorf("this is an error and it can include URLs http://grafana.com/")
 		resp.Responses[q.RefID] = respD
 	}
@@ -362,7 +422,7 @@ func (p *testDataPlugin) handleRandomWalkSlowScenario(ctx context.Context, req *
 		time.Sleep(parsedInterval)
 
 		respD := resp.Responses[q.RefID]
-		respD.Frames = append(respD.Frames, randomWalk(q, model, 0))
+		respD.Frames = append(respD.Frames, randomWalk(q, model, randomWalkOptions{Seed: querySeed(model)}, 0, parseLabels(model)))
 		resp.Responses[q.RefID] = respD
 	}
 
@@ -379,7 +439,7 @@ func (p *testDataPlugin) handleRandomWalkTableScenario(ctx context.Context, req
 		}
 
 		respD := resp.Responses[q.RefID]
-		respD.Frames = append(respD.Frames, randomWalkTable(q, model))
+		respD.Frames = append(respD.Frames, randomWalkTable(q, model, newRand(querySeed(model), 0)))
 		resp.Responses[q.RefID] = respD
 	}
 
@@ -465,7 +525,7 @@ func (p *testDataPlugin) handleExponentialHeatmapBucketDataScenario(ctx context.
 
 	for _, q := range req.Queries {
 		respD := resp.Responses[q.RefID]
-		frame := randomHeatmapData(q, func(index int) float64 {
+		frame := randomHeatmapData(q, newRand(heatmapSeed(q), 0), func(index int) float64 {
 			return math.Exp2(float64(index))
 		})
 		respD.Frames = append(respD.Frames, frame)
@@ -480,7 +540,7 @@ func (p *testDataPlugin) handleLinearHeatmapBucketDataScenario(ctx context.Conte
 
 	for _, q := range req.Queries {
 		respD := resp.Responses[q.RefID]
-		frame := randomHeatmapData(q, func(index int) float64 {
+		frame := randomHeatmapData(q, newRand(heatmapSeed(q), 0), func(index int) float64 {
 			return float64(index * 10)
 		})
 		respD.Frames = append(respD.Frames, frame)
@@ -534,7 +594,8 @@ func (p *testDataPlugin) handleLogsScenario(ctx context.Context, req *backend.Qu
 		lines := model.Get("lines").MustInt64(10)
 		includeLevelColumn := model.Get("levelColumn").MustBool(false)
 
-		logLevelGenerator := newRandomStringProvider([]string{
+		r := newRand(querySeed(model), 0)
+		logLevelGenerator := newRandomStringProvider(r, []string{
 			"emerg",
 			"alert",
 			"crit",
@@ -551,11 +612,11 @@ func (p *testDataPlugin) handleLogsScenario(ctx context.Context, req *backend.Qu
 			"trace",
 			"",
 		})
-		containerIDGenerator := newRandomStringProvider([]string{
+		containerIDGenerator := newRandomStringProvider(r, []string{
 			"f36a9eaa6d34310686f2b851655212023a216de955cbcc764210cefa71179b1a",
 			"5a354a630364f3742c602f315132e16def594fe68b1e4a195b2fce628e24c97a",
 		})
-		hostnameGenerator := newRandomStringProvider([]string{
+		hostnameGenerator := newRandomStringProvider(r, []string{
 			"srv-001",
 			"srv-002",
 		})
@@ -604,10 +665,14 @@ func (p *testDataPlugin) handleLogsScenario(ctx context.Context, req *backend.Qu
 	return resp, nil
 }
 
-func randomWalk(query backend.DataQuery, model *simplejson.Json, index int) *data.Frame {
+// randomWalk returns a series of the query, the walk of the values or, with a counter reset interval, of the increments
+// of a counter. The gaps and spikes are drawn for every point, so that their probabilities don't change the walk of a
+// seed.
+func randomWalk(query backend.DataQuery, model *simplejson.Json, opts randomWalkOptions, index int, labels data.Labels) *data.Frame {
 	timeWalkerMs := query.TimeRange.From.UnixNano() / int64(time.Millisecond)
 	to := query.TimeRange.To.UnixNano() / int64(time.Millisecond)
-	startValue := model.Get("startValue").MustFloat64(rand.Float64() * 100)
+	r := opts.rand(index)
+	startValue := model.Get("startValue").MustFloat64(r.Float64() * 100)
 	spread := model.Get("spread").MustFloat64(1)
 	noise := model.Get("noise").MustFloat64(0)
 
@@ -620,9 +685,11 @@ func randomWalk(query backend.DataQuery, model *simplejson.Json, index int) *dat
 	floatVec := make([]*float64, 0)
 
 	walker := startValue
+	counter := 0.0
+	resetMs := opts.CounterReset.Milliseconds()
 
 	for i := int64(0); i < 10000 && timeWalkerMs < to; i++ {
-		nextValue := walker + (rand.Float64() * noise)
+		nextValue := walker + (r.Float64() * noise)
 
 		if hasMin && nextValue < min {
 			nextValue = min
@@ -634,25 +701,53 @@ func randomWalk(query backend.DataQuery, model *simplejson.Json, index int) *dat
 			walker = max
 		}
 
+		gap := r.Float64() < opts.GapProbability
+		spike := r.Float64() < opts.SpikeProbability
+		spikeSize := 10 + r.Float64()*10
+		if r.Float64() < 0.5 {
+			spikeSize = -spikeSize
+		}
+
+		if resetMs > 0 {
+			// the walk is the rate of the counter, and the spikes are bursts of increments
+			increment := math.Max(nextValue, 0)
+			if spike {
+				increment *= math.Abs(spikeSize)
+			}
+			if i > 0 && timeWalkerMs/resetMs != (timeWalkerMs-query.Interval.Milliseconds())/resetMs {
+				counter = 0
+			}
+			counter += increment
+			nextValue = counter
+		} else if spike {
+			// the outliers don't move the walk, and aren't limited by the min and max
+			nextValue += spikeSize * math.Max(spread, 1)
+		}
+
 		t := time.Unix(timeWalkerMs/int64(1e+3), (timeWalkerMs%int64(1e+3))*int64(1e+6))
 		timeVec = append(timeVec, &t)
-		floatVec = append(floatVec, &nextValue)
+		if gap {
+			floatVec = append(floatVec, nil)
+		} else {
+			value := nextValue
+			floatVec = append(floatVec, &value)
+		}
 
-		walker += (rand.Float64() - 0.5) * spread
+		walker += (r.Float64() - 0.5) * spread
 		timeWalkerMs += query.Interval.Milliseconds()
 	}
 
 	return data.NewFrame("",
 		data.NewField("time", nil, timeVec),
-		data.NewField(frameNameForQuery(query, model, index), parseLabels(model), floatVec),
+		data.NewField(frameNameForQuery(query, model, index), labels, floatVec),
 	)
 }
 
-func randomWalkTable(query backend.DataQuery, model *simplejson.Json) *data.Frame {
+func randomWalkTable(query backend.DataQuery, model *simplejson.Json, r *rand.Rand) *data.Frame {
 	timeWalkerMs := query.TimeRange.From.UnixNano() / int64(time.Millisecond)
 	to := query.TimeRange.To.UnixNano() / int64(time.Millisecond)
 	withNil := model.Get("withNil").MustBool(false)
-	walker := model.Get("startValue").MustFloat64(rand.Float64() * 100)
+	walker := model.Get("startValue").MustFloat64(r.Float64() * 100)
 	spread := 2.5
 
 	frame := data.NewFrame(query.RefID,
@@ -666,7 +761,7 @@ func randomWalkTable(query backend.DataQuery, model *simplejson.Json) *data.Fram
 	var info strings.Builder
 
 	for i := int64(0); i < query.MaxDataPoints && timeWalkerMs < to; i++ {
-		delta := rand.Float64() - 0.5
+		delta := r.Float64() - 0.5
 		walker += delta
 
 		info.Reset()
@@ -681,15 +776,15 @@ func randomWalkTable(query backend.DataQuery, model *simplejson.Json) *data.Fram
 
 		t := time.Unix(timeWalkerMs/int64(1e+3), (timeWalkerMs%int64(1e+3))*int64(1e+6))
 		val := walker
-		min := walker - ((rand.Float64() * spread) + 0.01)
-		max := walker + ((rand.Float64() * spread) + 0.01)
+		min := walker - ((r.Float64() * spread) + 0.01)
+		max := walker + ((r.Float64() * spread) + 0.01)
 		infoString := info.String()
 
 		vals := []*float64{&val, &min, &max}
 		// Add some random null values
-		if withNil && rand.Float64() > 0.8 {
+		if withNil && r.Float64() > 0.8 {
 			for i := range vals {
-				if rand.Float64() > .2 {
+				if r.Float64() > .2 {
 					vals[i] = nil
 				}
 			}
@@ -860,7 +955,16 @@ func predictablePulse(query backend.DataQuery, model *simplejson.Json) (*data.Fr
 	return frame, nil
 }
 
-func randomHeatmapData(query backend.DataQuery, fnBucketGen func(index int) float64) *data.Frame {
+// heatmapSeed returns the seed of a query of the heatmap scenarios, whose model is optional.
+func heatmapSeed(query backend.DataQuery) int64 {
+	model, err := simplejson.NewJson(query.JSON)
+	if err != nil {
+		return 0
+	}
+	return querySeed(model)
+}
+
+func randomHeatmapData(query backend.DataQuery, r *rand.Rand, fnBucketGen func(index int) float64) *data.Frame {
 	frame := data.NewFrame("data", data.NewField("time", nil, []*time.Time{}))
 	for i := 0; i < 10; i++ {
 		frame.Fields = append(frame.Fields, data.NewField(strconv.FormatInt(int64(fnBucketGen(i)), 10), nil, []*float64{}))
@@ -873,7 +977,7 @@ func randomHeatmapData(query backend.DataQuery, fnBucketGen func(index int) floa
 		t := time.Unix(timeWalkerMs/int64(1e+3), (timeWalkerMs%int64(1e+3))*int64(1e+6))
 		vals := []interface{}{&t}
 		for n := 1; n < len(frame.Fields); n++ {
-			v := float64(rand.Int63n(100))
+			v := float64(r.Int63n(100))
 			vals = append(vals, &v)
 		}
 		frame.AppendRow(vals...)
@@ -3,6 +3,7 @@ package testdatasource
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/tsdb/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -212,3 +214,54 @@ func TestParseLabels(t *testing.T) {
 		assert.Equal(t, expectedTags, parseLabels(model), fmt.Sprintf("Actual tags in test case %d doesn't match expected tags", i+1))
 	}
 }
+
+func TestSeededScenarios(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	timeRange := backend.TimeRange{From: now.Add(-time.Hour), To: now}
+
+	scenarios := []struct {
+		queryType queryType
+		json      string
+	}{
+		{randomWalkQuery, `{"seed": 42, "seriesCount": 2, "gapProbability": 0.1, "spikeProbability": 0.05}`},
+		{randomWalkTableQuery, `{"seed": 42, "withNil": true}`},
+		{randomWalkWithErrorQuery, `{"seed": 42}`},
+		{randomWalkSlowQuery, `{"seed": 42, "stringInput": "1ms"}`},
+		{exponentialHeatmapBucketDataQuery, `{"seed": 42}`},
+		{linearHeatmapBucketDataQuery, `{"seed": 42}`},
+		{logsQuery, `{"seed": 42, "lines": 5, "levelColumn": true}`},
+		{chaosQueryType, `{"seed": 42, "chaos": {"failure": "partial", "probability": 0.5}}`},
+		{simQueryType, `{"seed": 42, "sim": {"type": "server", "key": "golden", "tick": "5m"}}`},
+	}
+
+	for _, sc := range scenarios {
+		sc := sc
+		t.Run(string(sc.queryType), func(t *testing.T) {
+			query := func() backend.DataResponse {
+				// a new data source, so that the simulations start again
+				handler := New(WithClock(func() time.Time { return now }))
+				resp, err := handler.QueryData(context.Background(), &backend.QueryDataRequest{
+					Queries: []backend.DataQuery{{
+						RefID:         "A",
+						QueryType:     string(sc.queryType),
+						TimeRange:     timeRange,
+						Interval:      time.Minute,
+						MaxDataPoints: 100,
+						JSON:          []byte(sc.json),
+					}},
+				})
+				require.NoError(t, err)
+				return resp.Responses["A"]
+			}
+
+			dr := query()
+			require.NotEmpty(t, dr.Frames)
+			// the golden files can't have the errors of the responses
+			golden := backend.DataResponse{Frames: dr.Frames}
+			testutil.CheckGoldenDataResponse(t, filepath.Join("testdata", "seeded_"+string(sc.queryType)+".golden.txt"), &golden)
+
+			again := query()
+			require.Equal(t, dr, again, "the queries with the same seed must return the same response")
+		})
+	}
+}
@@ -0,0 +1,288 @@
+package testdatasource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// simStreamPathPrefix is the prefix of the paths of the streams of the simulations. The paths are
+// sim/<type>/<key>/<tick>, so that the queries and streams of a simulation share its state.
+const simStreamPathPrefix = "sim/"
+
+const (
+	defaultSimTick = 10 * time.Second
+	minSimTick     = 100 * time.Millisecond
+	maxSimTick     = time.Hour
+	// maxSimSamples is the number of samples kept by a simulation, and the maximum number of steps of a query
+	maxSimSamples = 10000
+	// simStateTTL is the time after which the simulations which aren't queried or streamed are removed
+	simStateTTL = time.Hour
+)
+
+var simKeyPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,40}$`)
+
+// simKey identifies the state of a simulation.
+type simKey struct {
+	Type string
+	Key  string
+	Tick time.Duration
+}
+
+func (k simKey) path() string {
+	return fmt.Sprintf("%s%s/%s/%s", simStreamPathPrefix, k.Type, k.Key, k.Tick)
+}
+
+func (k simKey) validate() error {
+	if newSimulation(k.Type) == nil {
+		return fmt.Errorf("unknown simulation: %q", k.Type)
+	}
+	if !simKeyPattern.MatchString(k.Key) {
+		return fmt.Errorf("invalid simulation key, only letters, digits, _ and - are allowed: %q", k.Key)
+	}
+	if k.Tick < minSimTick || k.Tick > maxSimTick {
+		return fmt.Errorf("the tick of a simulation must be between %s and %s: %s", minSimTick, maxSimTick, k.Tick)
+	}
+	return nil
+}
+
+func parseSimStreamPath(path string) (simKey, error) {
+	parts := strings.Split(strings.TrimPrefix(path, simStreamPathPrefix), "/")
+	if !strings.HasPrefix(path, simStreamPathPrefix) || len(parts) != 3 {
+		return simKey{}, fmt.Errorf("invalid simulation stream path: %q", path)
+	}
+	tick, err := time.ParseDuration(parts[2])
+	if err != nil {
+		return simKey{}, fmt.Errorf("invalid simulation tick: %q", parts[2])
+	}
+	key := simKey{Type: parts[0], Key: parts[1], Tick: tick}
+	return key, key.validate()
+}
+
+// simState is the state of a simulation with its recent samples.
+type simState struct {
+	sim simulation
+	// rand is the source of the random values of the simulation, seeded by the seed of its first query
+	rand *rand.Rand
+	// time is the time of the last step of the simulation, zero before the first step
+	time       time.Time
+	times      []time.Time
+	samples    [][]float64
+	lastAccess time.Time
+}
+
+// simEngine keeps the states of the simulations, shared by the queries and streams with the same key.
+type simEngine struct {
+	mu     sync.Mutex
+	states map[simKey]*simState
+	now    func() time.Time
+}
+
+func newSimEngine() *simEngine {
+	return &simEngine{states: map[simKey]*simState{}, now: time.Now}
+}
+
+// state returns the state of the simulation of the key, created with the random values of the seed when missing. The
+// engine must be locked.
+func (e *simEngine) state(key simKey, seed int64) *simState {
+	now := e.now()
+	for k, s := range e.states {
+		if now.Sub(s.lastAccess) > simStateTTL {
+			delete(e.states, k)
+		}
+	}
+
+	s, ok := e.states[key]
+	if !ok {
+		s = &simState{sim: newSimulation(key.Type), rand: newRand(seed, 0)}
+		e.states[key] = s
+	}
+	s.lastAccess = now
+	return s
+}
+
+// advance steps the simulation to the time t, from t when it has no step yet, and returns the number of new samples.
+// The steps are at the multiples of the tick, and at most maxSimSamples.
+func (s *simState) advance(t time.Time, tick time.Duration) int {
+	t = t.Truncate(tick)
+	if s.time.IsZero() {
+		s.time = t.Add(-tick)
+	} else if t.Sub(s.time) > maxSimSamples*tick {
+		// the simulation continues from its state, skipping the time without steps
+		s.time = t.Add(-maxSimSamples * tick)
+	}
+
+	count := 0
+	for next := s.time.Add(tick); !next.After(t); next = next.Add(tick) {
+		s.sim.step(s.rand, next, tick)
+		s.time = next
+		s.times = append(s.times, next)
+		s.samples = append(s.samples, s.sim.values())
+		count++
+	}
+	if extra := len(s.times) - maxSimSamples; extra > 0 {
+		s.times = s.times[extra:]
+		s.samples = s.samples[extra:]
+	}
+	return count
+}
+
+// frame returns a frame of the samples of the simulation between from and to.
+func (s *simState) frame(name string, from, to time.Time) *data.Frame {
+	frame := emptySimFrame(name, s.sim)
+	for i, t := range s.times {
+		if t.Before(from) || t.After(to) {
+			continue
+		}
+		appendSimSample(frame, t, s.samples[i])
+	}
+	return frame
+}
+
+func emptySimFrame(name string, sim simulation) *data.Frame {
+	frame := data.NewFrame(name, data.NewField("time", nil, []time.Time{}))
+	for _, n := range sim.names() {
+		frame.Fields = append(frame.Fields, data.NewField(n, nil, []float64{}))
+	}
+	return frame
+}
+
+func appendSimSample(frame *data.Frame, t time.Time, values []float64) {
+	row := make([]interface{}, 0, len(values)+1)
+	row = append(row, t)
+	for _, v := range values {
+		row = append(row, v)
+	}
+	frame.AppendRow(row...)
+}
+
+// query updates the config of the simulation of the key, and returns its samples of the time range after stepping it
+// to the end of the time range, or to now for the time ranges ending in the future. The simulation starts at the start
+// of the time range of its first query, with the random values of its seed.
+func (e *simEngine) query(key simKey, seed int64, config json.RawMessage, tr backend.TimeRange) (*data.Frame, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	s := e.state(key, seed)
+	if err := s.sim.setConfig(config); err != nil {
+		return nil, fmt.Errorf("invalid %s simulation config: %v", key.Type, err)
+	}
+
+	to := tr.To
+	if now := e.now(); to.After(now) {
+		to = now
+	}
+	if s.time.IsZero() {
+		s.time = tr.From.Truncate(key.Tick).Add(-key.Tick)
+	}
+	s.advance(to, key.Tick)
+	return s.frame(key.Key, tr.From, tr.To), nil
+}
+
+// step steps the simulation of the key to the time t, and returns a frame of the new samples.
+func (e *simEngine) step(key simKey, t time.Time) *data.Frame {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	s := e.state(key, 0)
+	count := s.advance(t, key.Tick)
+	frame := emptySimFrame(key.Key, s.sim)
+	for i := len(s.times) - count; i < len(s.times); i++ {
+		appendSimSample(frame, s.times[i], s.samples[i])
+	}
+	return frame
+}
+
+// schema returns a frame without samples of the simulation of the key.
+func (e *simEngine) schema(key simKey) *data.Frame {
+	return emptySimFrame(key.Key, newSimulation(key.Type))
+}
+
+// handleSimScenario returns the samples of the simulations of the queries over their time ranges, with the channel of
+// the stream of the simulation for the streaming queries.
+func (p *testDataPlugin) handleSimScenario(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	resp := backend.NewQueryDataResponse()
+
+	for _, q := range req.Queries {
+		model, err := simplejson.NewJson(q.JSON)
+		if err != nil {
+			continue
+		}
+
+		respD := resp.Responses[q.RefID]
+		frame, err := p.querySim(model.Get("sim"), querySeed(model), q.TimeRange)
+		if err != nil {
+			respD.Error = err
+		} else {
+			respD.Frames = append(respD.Frames, frame)
+		}
+		resp.Responses[q.RefID] = respD
+	}
+
+	return resp, nil
+}
+
+func (p *testDataPlugin) querySim(sim *simplejson.Json, seed int64, tr backend.TimeRange) (*data.Frame, error) {
+	key := simKey{
+		Type: sim.Get("type").MustString("tank"),
+		Key:  sim.Get("key").MustString("default"),
+		Tick: defaultSimTick,
+	}
+	if tick := sim.Get("tick").MustString(); tick != "" {
+		var err error
+		if key.Tick, err = time.ParseDuration(tick); err != nil {
+			return nil, fmt.Errorf("invalid simulation tick, e.g. 10s: %q", tick)
+		}
+	}
+	if err := key.validate(); err != nil {
+		return nil, err
+	}
+
+	var config json.RawMessage
+	if c, ok := sim.CheckGet("config"); ok {
+		var err error
+		if config, err = c.MarshalJSON(); err != nil {
+			return nil, err
+		}
+	}
+
+	frame, err := p.sims.query(key, seed, config, tr)
+	if err != nil {
+		return nil, err
+	}
+	if sim.Get("stream").MustBool(false) {
+		frame.Meta = &data.FrameMeta{Channel: "plugin/testdata/" + key.path()}
+	}
+	return frame, nil
+}
+
+// runSimStream sends the new samples of a simulation at every tick.
+func (p *testStreamHandler) runSimStream(ctx context.Context, path string, key simKey, sender *backend.StreamSender) error {
+	ticker := time.NewTicker(key.Tick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			p.logger.Debug("Stop streaming simulation", "path", path)
+			return ctx.Err()
+		case t := <-ticker.C:
+			frame := p.sims.step(key, t)
+			if frame.Rows() == 0 {
+				continue
+			}
+			if err := sender.SendFrame(frame, data.IncludeDataOnly); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package testdatasource
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// simulation is the model of a stateful simulation, whose state is advanced by steps of the tick of the simulation.
+type simulation interface {
+	// names returns the names of the values of the simulation
+	names() []string
+	// setConfig updates the config of the simulation with the JSON config of a query, keeping its state
+	setConfig(config json.RawMessage) error
+	// step advances the state of the simulation by the duration, to the time t, with the random values of r
+	step(r *rand.Rand, t time.Time, dt time.Duration)
+	// values returns the current values of the simulation, in the order of the names
+	values() []float64
+}
+
+// newSimulation returns the simulation of a type, or nil for unknown types.
+func newSimulation(simType string) simulation {
+	switch simType {
+	case "tank":
+		return &tankSim{tankConfig: tankConfig{Capacity: 1000, Inflow: 5, Outflow: 12, High: 0.9, Low: 0.1}, level: 500}
+	case "server":
+		return &serverSim{serverConfig: serverConfig{Requests: 100, ErrorRate: 0.01, Latency: 120, IncidentProbability: 0.001}}
+	case "cpu":
+		return &cpuSim{cpuConfig: cpuConfig{Cores: 4, Usage: 25, SpikeProbability: 0.01, SpikeDuration: 60}}
+	}
+	return nil
+}
+
+// setSimConfig decodes the JSON config of a query on the config of a simulation, so that the missing options keep
+// their values.
+func setSimConfig(config json.RawMessage, v interface{}) error {
+	if len(config) == 0 {
+		return nil
+	}
+	return json.Unmarshal(config, v)
+}
+
+type tankConfig struct {
+	// Capacity is the volume of the tank in liters
+	Capacity float64 `json:"capacity"`
+	// Inflow is the average flow filling the tank in liters per second
+	Inflow float64 `json:"inflow"`
+	// Outflow is the flow of the drain valve in liters per second
+	Outflow float64 `json:"outflow"`
+	// High and Low are the ratios of the capacity opening and closing the drain valve
+	High float64 `json:"high"`
+	Low  float64 `json:"low"`
+}
+
+// tankSim is a tank filled by a varying inflow, whose drain valve opens when the level is high and closes when it's
+// low.
+type tankSim struct {
+	tankConfig
+	level   float64
+	inflow  float64
+	outflow float64
+	open    bool
+}
+
+func (s *tankSim) names() []string {
+	return []string{"level", "inflow", "outflow", "valve"}
+}
+
+func (s *tankSim) setConfig(config json.RawMessage) error {
+	return setSimConfig(config, &s.tankConfig)
+}
+
+func (s *tankSim) step(r *rand.Rand, _ time.Time, dt time.Duration) {
+	seconds := dt.Seconds()
+	s.inflow = math.Max(0, s.Inflow*(1+0.4*(r.Float64()-0.5)))
+	if s.level >= s.Capacity*s.High {
+		s.open = true
+	} else if s.level <= s.Capacity*s.Low {
+		s.open = false
+	}
+	s.outflow = 0
+	if s.open {
+		s.outflow = s.Outflow
+	}
+	s.level = math.Min(s.Capacity, math.Max(0, s.level+(s.inflow-s.outflow)*seconds))
+}
+
+func (s *tankSim) values() []float64 {
+	valve := 0.0
+	if s.open {
+		valve = 1
+	}
+	return []float64{s.level, s.inflow, s.outflow, valve}
+}
+
+type serverConfig struct {
+	// Requests is the average rate of the requests per second
+	Requests float64 `json:"requests"`
+	// ErrorRate is the ratio of the failed requests
+	ErrorRate float64 `json:"errorRate"`
+	// Latency is the median duration of the requests in milliseconds
+	Latency float64 `json:"latency"`
+	// IncidentProbability is the probability of an incident to start at every tick
+	IncidentProbability float64 `json:"incidentProbability"`
+}
+
+// serverSim is the rate, errors and duration of the requests of an HTTP server, with a daily traffic cycle and
+// incidents increasing the errors and durations for a few minutes.
+type serverSim struct {
+	serverConfig
+	rate     float64
+	errors   float64
+	p50      float64
+	p95      float64
+	incident time.Duration
+}
+
+func (s *serverSim) names() []string {
+	return []string{"requests", "errors", "duration_p50", "duration_p95"}
+}
+
+func (s *serverSim) setConfig(config json.RawMessage) error {
+	return setSimConfig(config, &s.serverConfig)
+}
+
+func (s *serverSim) step(r *rand.Rand, t time.Time, dt time.Duration) {
+	if s.incident > 0 {
+		s.incident -= dt
+	} else if r.Float64() < s.IncidentProbability {
+		s.incident = time.Duration(2+r.Intn(8)) * time.Minute
+	}
+
+	// the traffic peaks in the afternoon
+	hour := float64(t.UTC().Hour()) + float64(t.UTC().Minute())/60
+	cycle := 1 + 0.5*math.Sin((hour-9)/24*2*math.Pi)
+	s.rate = math.Max(0, s.Requests*cycle*(1+0.1*(r.Float64()-0.5)))
+
+	errorRate, latency := s.ErrorRate, s.Latency
+	if s.incident > 0 {
+		errorRate = math.Min(1, errorRate*10+0.05)
+		latency *= 4
+	}
+	s.errors = s.rate * errorRate * (0.5 + r.Float64())
+	// the durations increase with the load
+	s.p50 = latency * cycle * (0.9 + 0.2*r.Float64())
+	s.p95 = s.p50 * (2 + r.Float64())
+}
+
+func (s *serverSim) values() []float64 {
+	return []float64{s.rate, s.errors, s.p50, s.p95}
+}
+
+type cpuConfig struct {
+	// Cores is the number of cores of the load
+	Cores float64 `json:"cores"`
+	// Usage is the average usage of the CPU in percent outside of the spikes
+	Usage float64 `json:"usage"`
+	// SpikeProbability is the probability of a load spike to start at every tick
+	SpikeProbability float64 `json:"spikeProbability"`
+	// SpikeDuration is the average duration of the load spikes in seconds
+	SpikeDuration float64 `json:"spikeDuration"`
+}
+
+// cpuSim is the usage and load of a CPU, which reverts to the average usage between load spikes.
+type cpuSim struct {
+	cpuConfig
+	usage float64
+	load  float64
+	spike time.Duration
+}
+
+func (s *cpuSim) names() []string {
+	return []string{"usage", "load1"}
+}
+
+func (s *cpuSim) setConfig(config json.RawMessage) error {
+	return setSimConfig(config, &s.cpuConfig)
+}
+
+func (s *cpuSim) step(r *rand.Rand, _ time.Time, dt time.Duration) {
+	if s.spike > 0 {
+		s.spike -= dt
+	} else if r.Float64() < s.SpikeProbability {
+		s.spike = time.Duration(s.SpikeDuration*(0.5+r.Float64())) * time.Second
+	}
+
+	target := s.Usage
+	if s.spike > 0 {
+		target = 90 + r.Float64()*10
+	}
+	// the usage reverts to the target, faster for the spikes
+	reversion := 0.2
+	if s.spike > 0 {
+		reversion = 0.6
+	}
+	s.usage = math.Min(100, math.Max(0, s.usage+(target-s.usage)*reversion+(r.Float64()-0.5)*5))
+
+	// the load average over a minute of the running cores
+	decay := math.Exp(-dt.Seconds() / 60)
+	s.load = s.load*decay + s.usage/100*s.Cores*(1-decay)
+}
+
+func (s *cpuSim) values() []float64 {
+	return []float64{s.usage, s.load}
+}
@@ -0,0 +1,126 @@
+package testdatasource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimScenario(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	p := &testDataPlugin{sims: newSimEngine()}
+	p.sims.now = func() time.Time { return now }
+
+	query := func(t *testing.T, json string, from, to time.Time) backend.DataResponse {
+		t.Helper()
+		q := backend.DataQuery{
+			RefID:     "A",
+			TimeRange: backend.TimeRange{From: from, To: to},
+			Interval:  time.Minute,
+			JSON:      []byte(json),
+		}
+		resp, err := p.handleSimScenario(context.Background(), &backend.QueryDataRequest{Queries: []backend.DataQuery{q}})
+		require.NoError(t, err)
+		return resp.Responses["A"]
+	}
+	times := func(frame *data.Frame) []time.Time {
+		times := make([]time.Time, frame.Rows())
+		for i := range times {
+			times[i] = frame.Fields[0].At(i).(time.Time)
+		}
+		return times
+	}
+
+	t.Run("Should keep the state of the simulation between the queries", func(t *testing.T) {
+		json := `{"sim": {"type": "tank", "key": "demo", "tick": "1m"}}`
+		first := query(t, json, now.Add(-time.Hour), now)
+		require.NoError(t, first.Error)
+		require.Len(t, first.Frames, 1)
+		frame := first.Frames[0]
+		require.Equal(t, "demo", frame.Name)
+		require.Len(t, frame.Fields, 5)
+		assert.Equal(t, "level", frame.Fields[1].Name)
+		require.Equal(t, 61, frame.Rows())
+		assert.Equal(t, now.Add(-time.Hour), times(frame)[0])
+		assert.Equal(t, now, times(frame)[60])
+
+		now = now.Add(10 * time.Minute)
+		second := query(t, json, now.Add(-time.Hour), now.Add(time.Hour))
+		require.NoError(t, second.Error)
+		next := second.Frames[0]
+		require.Equal(t, 61, next.Rows())
+		// the samples of the first query are kept
+		assert.Equal(t, frame.Fields[1].At(10), next.Fields[1].At(0))
+		assert.Equal(t, now, times(next)[60])
+		for i := 0; i < next.Rows(); i++ {
+			level := next.Fields[1].At(i).(float64)
+			assert.True(t, level >= 0 && level <= 1000, "level %v", level)
+		}
+	})
+
+	t.Run("Should apply the config to the simulation", func(t *testing.T) {
+		dr := query(t, `{"sim": {"type": "cpu", "key": "busy", "tick": "10s", "config": {"spikeProbability": 1, "spikeDuration": 3600}}}`,
+			now.Add(-10*time.Minute), now)
+		require.NoError(t, dr.Error)
+		frame := dr.Frames[0]
+		assert.Greater(t, frame.Fields[1].At(frame.Rows()-1).(float64), 80.0)
+		assert.Greater(t, frame.Fields[2].At(frame.Rows()-1).(float64), 2.0)
+
+		dr = query(t, `{"sim": {"type": "cpu", "key": "busy", "config": {"cores": "many"}}}`, now.Add(-time.Minute), now)
+		require.Error(t, dr.Error)
+	})
+
+	t.Run("Should return the channel of the streaming queries", func(t *testing.T) {
+		dr := query(t, `{"sim": {"type": "server", "key": "api", "stream": true}}`, now.Add(-time.Minute), now)
+		require.NoError(t, dr.Error)
+		require.Equal(t, "plugin/testdata/sim/server/api/10s", dr.Frames[0].Meta.Channel)
+		assert.Equal(t, []string{"time", "requests", "errors", "duration_p50", "duration_p95"}, fieldNames(dr.Frames[0]))
+	})
+
+	t.Run("Should return an error with invalid simulations", func(t *testing.T) {
+		for _, json := range []string{
+			`{"sim": {"type": "reactor"}}`,
+			`{"sim": {"type": "tank", "key": "a/b"}}`,
+			`{"sim": {"type": "tank", "tick": "1ms"}}`,
+			`{"sim": {"type": "tank", "tick": "often"}}`,
+		} {
+			dr := query(t, json, now.Add(-time.Minute), now)
+			assert.Error(t, dr.Error, json)
+		}
+	})
+}
+
+func TestSimStream(t *testing.T) {
+	key, err := parseSimStreamPath("sim/tank/demo/1s")
+	require.NoError(t, err)
+	require.Equal(t, simKey{Type: "tank", Key: "demo", Tick: time.Second}, key)
+	require.Equal(t, "sim/tank/demo/1s", key.path())
+
+	for _, path := range []string{"sim/tank/demo", "sim/tank/demo/soon", "sim/reactor/demo/1s", "csv/tank/demo/1s"} {
+		_, err := parseSimStreamPath(path)
+		require.Error(t, err, path)
+	}
+
+	engine := newSimEngine()
+	start := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	frame := engine.step(key, start)
+	require.Equal(t, 1, frame.Rows())
+	frame = engine.step(key, start.Add(3500*time.Millisecond))
+	require.Equal(t, 3, frame.Rows())
+	assert.Equal(t, start.Add(3*time.Second), frame.Fields[0].At(2))
+	frame = engine.step(key, start.Add(3900*time.Millisecond))
+	require.Equal(t, 0, frame.Rows())
+}
+
+func fieldNames(frame *data.Frame) []string {
+	names := make([]string, len(frame.Fields))
+	for i, f := range frame.Fields {
+		names[i] = f.Name
+	}
+	return names
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"strings"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
@@ -15,9 +16,13 @@ import (
 type testStreamHandler struct {
 	logger log.Logger
 	frame  *data.Frame
+	// loadCsvFile loads the CSV files replayed by the csv/ streams
+	loadCsvFile func(orgID int64, fileName string) (*data.Frame, error)
+	// sims are the simulations of the sim/ streams
+	sims *simEngine
 }
 
-func newTestStreamHandler(logger log.Logger) *testStreamHandler {
+func newTestStreamHandler(logger log.Logger, loadCsvFile func(orgID int64, fileName string) (*data.Frame, error), sims *simEngine) *testStreamHandler {
 	frame := data.NewFrame("testdata",
 		data.NewField("Time", nil, make([]time.Time, 1)),
 		data.NewField("Value", nil, make([]float64, 1)),
@@ -25,14 +30,35 @@ func newTestStreamHandler(logger log.Logger) *testStreamHandler {
 		data.NewField("Max", nil, make([]float64, 1)),
 	)
 	return &testStreamHandler{
-		frame:  frame,
-		logger: logger,
+		frame:       frame,
+		logger:      logger,
+		loadCsvFile: loadCsvFile,
+		sims:        sims,
 	}
 }
 
 func (p *testStreamHandler) SubscribeStream(_ context.Context, req *backend.SubscribeStreamRequest) (*backend.SubscribeStreamResponse, error) {
 	p.logger.Debug("Allowing access to stream", "path", req.Path, "user", req.PluginContext.User)
-	initialData, err := backend.NewInitialFrame(p.frame, data.IncludeSchemaOnly)
+	frame := p.frame
+	if strings.HasPrefix(req.Path, csvStreamPathPrefix) {
+		conf, err := parseCsvStreamPath(req.Path)
+		if err != nil {
+			return &backend.SubscribeStreamResponse{Status: backend.SubscribeStreamStatusNotFound}, nil
+		}
+		csvFrame, err := p.loadCsvFile(req.PluginContext.OrgID, conf.FileName)
+		if err != nil {
+			return &backend.SubscribeStreamResponse{Status: backend.SubscribeStreamStatusNotFound}, nil
+		}
+		frame = emptyCsvFrame(csvFrame)
+	}
+	if strings.HasPrefix(req.Path, simStreamPathPrefix) {
+		key, err := parseSimStreamPath(req.Path)
+		if err != nil {
+			return &backend.SubscribeStreamResponse{Status: backend.SubscribeStreamStatusNotFound}, nil
+		}
+		frame = p.sims.schema(key)
+	}
+	initialData, err := backend.NewInitialFrame(frame, data.IncludeSchemaOnly)
 	if err != nil {
 		return nil, err
 	}
@@ -51,6 +77,21 @@ func (p *testStreamHandler) PublishStream(_ context.Context, req *backend.Publis
 
 func (p *testStreamHandler) RunStream(ctx context.Context, request *backend.RunStreamRequest, sender *backend.StreamSender) error {
 	p.logger.Debug("New stream call", "path", request.Path)
+	if strings.HasPrefix(request.Path, csvStreamPathPrefix) {
+		conf, err := parseCsvStreamPath(request.Path)
+		if err != nil {
+			return err
+		}
+		return p.runCsvStream(ctx, request.PluginContext.OrgID, request.Path, conf, sender)
+	}
+	if strings.HasPrefix(request.Path, simStreamPathPrefix) {
+		key, err := parseSimStreamPath(request.Path)
+		if err != nil {
+			return err
+		}
+		return p.runSimStream(ctx, request.Path, key, sender)
+	}
+
 	var conf testStreamConfig
 	switch request.Path {
 	case "random-2s-stream":
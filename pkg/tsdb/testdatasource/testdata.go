@@ -3,6 +3,8 @@ package testdatasource
 import (
 	"context"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
@@ -24,19 +26,85 @@ type testDataPlugin struct {
 	logger               log.Logger
 	scenarios            map[string]*Scenario
 	queryMux             *datasource.QueryTypeMux
+	sims                 *simEngine
+	csvURLs              *csvURLCache
+	// now is the clock of the scenarios depending on the current time, like the simulations
+	now func() time.Time
 }
 
-func (p *testDataPlugin) Init() error {
+// customScenarios are the scenarios registered by other packages.
+var customScenarios struct {
+	sync.Mutex
+	scenarios []*Scenario
+}
+
+// RegisterScenario registers a scenario of the testdata data source for other packages, like the fixtures of the
+// integration tests. It must be called before the services are initialized, e.g. in an init function, and panics
+// without ID or handler. The scenarios with the ID of a built-in scenario are skipped.
+func RegisterScenario(scenario *Scenario) {
+	if scenario.ID == "" || scenario.Handler == nil {
+		panic("testdata scenarios must have an ID and a handler")
+	}
+	customScenarios.Lock()
+	defer customScenarios.Unlock()
+	customScenarios.scenarios = append(customScenarios.scenarios, scenario)
+}
+
+// Option configures the testdata data source returned by New.
+type Option func(p *testDataPlugin)
+
+// WithScenarios adds scenarios to the testdata data source, see RegisterScenario.
+func WithScenarios(scenarios ...*Scenario) Option {
+	return func(p *testDataPlugin) {
+		p.registerCustomScenarios(scenarios)
+	}
+}
+
+// WithClock replaces the clock of the scenarios depending on the current time, e.g. to test the outputs of their
+// seeded queries against golden files.
+func WithClock(now func() time.Time) Option {
+	return func(p *testDataPlugin) {
+		p.now = now
+	}
+}
+
+// New returns the query handler of a testdata data source, with the built-in scenarios, the registered scenarios
+// and the scenarios of the options, without registering it as a backend plugin.
+func New(opts ...Option) backend.QueryDataHandler {
+	p := &testDataPlugin{}
+	p.setup(opts...)
+	return p.queryMux
+}
+
+func (p *testDataPlugin) setup(opts ...Option) {
 	p.logger = log.New("tsdb.testdata")
 	p.scenarios = map[string]*Scenario{}
 	p.queryMux = datasource.NewQueryTypeMux()
+	p.now = time.Now
+	p.sims = newSimEngine()
+	p.sims.now = func() time.Time { return p.now() }
 	p.registerScenarios()
+
+	customScenarios.Lock()
+	p.registerCustomScenarios(customScenarios.scenarios)
+	customScenarios.Unlock()
+	for _, opt := range opts {
+		opt(p)
+	}
+}
+
+func (p *testDataPlugin) Init() error {
+	p.setup()
+	if err := p.initCsvURLs(); err != nil {
+		p.logger.Error("Failed to create the cache of the remote csv files", "error", err)
+	}
 	resourceMux := http.NewServeMux()
 	p.registerRoutes(resourceMux)
 	factory := coreplugin.New(backend.ServeOpts{
 		QueryDataHandler:    p.queryMux,
+		CheckHealthHandler:  p,
 		CallResourceHandler: httpadapter.New(resourceMux),
-		StreamHandler:       newTestStreamHandler(p.logger),
+		StreamHandler:       newTestStreamHandler(p.logger, p.loadCsvFile, p.sims),
 	})
 	err := p.BackendPluginManager.RegisterAndStart(context.Background(), "testdata", factory)
 	if err != nil {
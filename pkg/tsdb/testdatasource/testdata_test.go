@@ -0,0 +1,88 @@
+package testdatasource
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomScenarios(t *testing.T) {
+	t.Cleanup(func() {
+		customScenarios.Lock()
+		defer customScenarios.Unlock()
+		customScenarios.scenarios = nil
+	})
+
+	constant := func(value float64) backend.QueryDataHandlerFunc {
+		return func(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+			resp := backend.NewQueryDataResponse()
+			for _, q := range req.Queries {
+				resp.Responses[q.RefID] = backend.DataResponse{
+					Frames: data.Frames{data.NewFrame("", data.NewField("value", nil, []float64{value}))},
+				}
+			}
+			return resp, nil
+		}
+	}
+	RegisterScenario(&Scenario{ID: "registered", Name: "Registered", Handler: constant(1)})
+	RegisterScenario(&Scenario{ID: string(randomWalkQuery), Name: "Not a random walk", Handler: constant(-1)})
+
+	query := func(t *testing.T, handler backend.QueryDataHandler, queryType, json string) float64 {
+		t.Helper()
+		resp, err := handler.QueryData(context.Background(), &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{{RefID: "A", QueryType: queryType, JSON: []byte(json)}},
+		})
+		require.NoError(t, err)
+		require.NoError(t, resp.Responses["A"].Error)
+		require.Len(t, resp.Responses["A"].Frames, 1)
+		return resp.Responses["A"].Frames[0].Fields[0].At(0).(float64)
+	}
+
+	t.Run("Should query the registered scenarios and the scenarios of the options", func(t *testing.T) {
+		handler := New(WithScenarios(&Scenario{ID: "option", Name: "Option", Handler: constant(2)}))
+		assert.Equal(t, 1.0, query(t, handler, "registered", `{}`))
+		assert.Equal(t, 1.0, query(t, handler, "", `{"scenarioId": "registered"}`))
+		assert.Equal(t, 2.0, query(t, handler, "", `{"scenarioId": "option"}`))
+	})
+
+	t.Run("Should not replace the built-in scenarios", func(t *testing.T) {
+		handler := New()
+		resp, err := handler.QueryData(context.Background(), &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{{RefID: "A", QueryType: string(randomWalkQuery), JSON: []byte(`{}`)}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "A-series", resp.Responses["A"].Frames[0].Fields[1].Name)
+	})
+
+	t.Run("Should list the default queries of the scenarios", func(t *testing.T) {
+		p := &testDataPlugin{}
+		p.setup(WithScenarios(&Scenario{
+			ID:           "defaults",
+			Name:         "Defaults",
+			DefaultQuery: json.RawMessage(`{"stringInput":"1,2,3"}`),
+			Handler:      constant(3),
+		}))
+
+		rw := httptest.NewRecorder()
+		p.getScenariosHandler(rw, httptest.NewRequest(http.MethodGet, "/scenarios", nil))
+		var scenarios []map[string]interface{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &scenarios))
+		ids := map[string]interface{}{}
+		for _, s := range scenarios {
+			ids[s["id"].(string)] = s["defaultQuery"]
+		}
+		assert.Contains(t, ids, "registered")
+		assert.Equal(t, map[string]interface{}{"stringInput": "1,2,3"}, ids["defaults"])
+	})
+
+	t.Run("Should panic without handler", func(t *testing.T) {
+		assert.Panics(t, func() { RegisterScenario(&Scenario{ID: "incomplete"}) })
+	})
+}
@@ -2,17 +2,32 @@ package testdatasource
 
 import (
 	"math/rand"
-	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
 )
 
+// querySeed returns the seed of the random values of a query, 0 when the values are random.
+func querySeed(model *simplejson.Json) int64 {
+	return model.Get("seed").MustInt64(0)
+}
+
+// newRand returns the source of the random values of the series index of a query, the same for the same seed, and
+// random without seed.
+func newRand(seed int64, index int) *rand.Rand {
+	if seed == 0 {
+		return rand.New(rand.NewSource(rand.Int63()))
+	}
+	return rand.New(rand.NewSource(seed + int64(index)))
+}
+
 type randomStringProvider struct {
 	r    *rand.Rand
 	data []string
 }
 
-func newRandomStringProvider(data []string) *randomStringProvider {
+func newRandomStringProvider(r *rand.Rand, data []string) *randomStringProvider {
 	return &randomStringProvider{
-		r:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		r:    r,
 		data: data,
 	}
 }
@@ -0,0 +1,216 @@
+package testutil
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+// The files of the fixtures of the contract tests.
+const (
+	contractQueryFile    = "query.json"
+	contractUpstreamFile = "upstream.json"
+	contractGoldenFile   = "response.golden.txt"
+)
+
+// ContractFixture is a fixture of the contract tests of a data source, a query and the recorded responses of the
+// upstream server of the data source to it.
+//
+// The fixtures are the directories of the contract tests directory, with the files:
+//
+//	query.json           the query, see contractQuery
+//	upstream.json        the recorded upstream exchanges, see contractExchange, optional
+//	response.golden.txt  the golden response of the data source
+//
+// The payloads of the exchanges are other files of the fixture.
+type ContractFixture struct {
+	Name string
+	Dir  string
+	// Query is the query of the fixture.
+	Query backend.DataQuery
+	// URL is the URL of the test server responding with the recorded upstream payloads, the URL of the data source.
+	URL string
+}
+
+// Payload returns the content of a file of the fixture, e.g. a recorded payload parsed by the data source without
+// the test server.
+func (f ContractFixture) Payload(t *testing.T, name string) []byte {
+	t.Helper()
+
+	// Can ignore gosec G304 here, because the path is set by the tests
+	// nolint:gosec
+	content, err := os.ReadFile(filepath.Join(f.Dir, name))
+	if err != nil {
+		t.Fatalf("failed to read payload %s of fixture %s: %v", name, f.Name, err)
+	}
+	return content
+}
+
+// contractQuery is the query.json of a fixture. The time range is absolute, so that the responses are deterministic.
+type contractQuery struct {
+	RefID         string `json:"refId"`
+	QueryType     string `json:"queryType"`
+	MaxDataPoints int64  `json:"maxDataPoints"`
+	IntervalMS    int64  `json:"intervalMs"`
+	TimeRange     struct {
+		From time.Time `json:"from"`
+		To   time.Time `json:"to"`
+	} `json:"timeRange"`
+	// Model is the JSON model of the query, as saved in the dashboards
+	Model json.RawMessage `json:"model"`
+}
+
+// contractExchange is a recorded upstream exchange of upstream.json, a request of the data source and the response of
+// the upstream server.
+type contractExchange struct {
+	// Method and Path match the requests, any method when the method is empty
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	// Params are the query or form parameters the requests must have
+	Params map[string]string `json:"params"`
+
+	Status      int    `json:"status"`
+	ContentType string `json:"contentType"`
+	// Body is the file of the fixture with the payload of the response
+	Body string `json:"body"`
+}
+
+// RunContractTests runs a subtest per fixture of the contract tests directory dir. The query of each fixture is run
+// by run, with a test server responding to the upstream requests with the recorded payloads, and the response is
+// checked against the golden file of the fixture. The golden files are written instead when the tests run with the
+// -update-golden flag. The upstream requests without a recorded exchange fail the test.
+func RunContractTests(t *testing.T, dir string, run func(t *testing.T, f ContractFixture) *backend.DataResponse) {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read contract fixtures %s: %v", dir, err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	if len(names) == 0 {
+		t.Fatalf("no contract fixtures in %s", dir)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		f := ContractFixture{Name: name, Dir: filepath.Join(dir, name)}
+		t.Run(name, func(t *testing.T) {
+			f.Query = readContractQuery(t, f)
+
+			server := httptest.NewServer(contractHandler(t, f, readContractExchanges(t, f)))
+			defer server.Close()
+			f.URL = server.URL
+
+			dr := run(t, f)
+			if dr == nil {
+				t.Fatalf("no response for fixture %s", name)
+			}
+			CheckGoldenDataResponse(t, filepath.Join(f.Dir, contractGoldenFile), dr)
+		})
+	}
+}
+
+func readContractQuery(t *testing.T, f ContractFixture) backend.DataQuery {
+	t.Helper()
+
+	var q contractQuery
+	if err := json.Unmarshal(f.Payload(t, contractQueryFile), &q); err != nil {
+		t.Fatalf("invalid %s of fixture %s: %v", contractQueryFile, f.Name, err)
+	}
+	if q.RefID == "" {
+		q.RefID = "A"
+	}
+	return backend.DataQuery{
+		RefID:         q.RefID,
+		QueryType:     q.QueryType,
+		MaxDataPoints: q.MaxDataPoints,
+		Interval:      time.Duration(q.IntervalMS) * time.Millisecond,
+		TimeRange:     backend.TimeRange{From: q.TimeRange.From, To: q.TimeRange.To},
+		JSON:          q.Model,
+	}
+}
+
+func readContractExchanges(t *testing.T, f ContractFixture) []contractExchange {
+	t.Helper()
+
+	if _, err := os.Stat(filepath.Join(f.Dir, contractUpstreamFile)); os.IsNotExist(err) {
+		return nil
+	}
+	var exchanges []contractExchange
+	if err := json.Unmarshal(f.Payload(t, contractUpstreamFile), &exchanges); err != nil {
+		t.Fatalf("invalid %s of fixture %s: %v", contractUpstreamFile, f.Name, err)
+	}
+	return exchanges
+}
+
+// contractHandler responds to the upstream requests with the payload of the first matching exchange.
+func contractHandler(t *testing.T, f ContractFixture, exchanges []contractExchange) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("fixture %s: invalid upstream request %s %s: %v", f.Name, r.Method, r.URL, err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		for _, e := range exchanges {
+			if !e.matches(r) {
+				continue
+			}
+
+			contentType := e.ContentType
+			if contentType == "" {
+				contentType = "application/json"
+			}
+			status := e.Status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			var body []byte
+			if e.Body != "" {
+				// Can ignore gosec G304 here, because the path is set by the tests
+				// nolint:gosec
+				content, err := os.ReadFile(filepath.Join(f.Dir, e.Body))
+				if err != nil {
+					t.Errorf("fixture %s: failed to read payload %s: %v", f.Name, e.Body, err)
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				body = content
+			}
+			w.Header().Set("Content-Type", contentType)
+			w.WriteHeader(status)
+			_, _ = w.Write(body)
+			return
+		}
+
+		t.Errorf("fixture %s: no recorded exchange for the upstream request %s %s", f.Name, r.Method, r.URL)
+		w.WriteHeader(http.StatusNotFound)
+	})
+}
+
+func (e contractExchange) matches(r *http.Request) bool {
+	if e.Method != "" && e.Method != r.Method {
+		return false
+	}
+	if e.Path != r.URL.Path {
+		return false
+	}
+	for key, value := range e.Params {
+		if r.Form.Get(key) != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,269 @@
+// Package testutil provides helpers for the tests of the data sources.
+//
+// The golden files of the responses of the data sources are checked by CheckGoldenDataResponse, and updated instead
+// when the tests run with the -update-golden flag, e.g.:
+//
+//	go test ./pkg/tsdb/testdatasource/ -update-golden
+//
+// The flag is only defined by the test binaries of the packages importing testutil.
+//
+// RunContractTests runs the queries of fixtures against the recorded payloads of the upstream servers of a data source,
+// and checks the responses against the golden files of the fixtures, so that the conversion of the payloads to frames
+// is tested without the upstream servers.
+package testutil
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/experimental"
+)
+
+var updateGolden = flag.Bool("update-golden", false, "update the golden files instead of checking them")
+
+// goldenDataSection starts the frames encoded with Arrow in the golden files written by the plugin SDK.
+const goldenDataSection = "====== TEST DATA RESPONSE (arrow base64) ======"
+
+// maxValueDiffs is the maximum number of different values reported per field.
+const maxValueDiffs = 5
+
+// CheckGoldenDataResponse fails the test when the data response doesn't match the golden file at path, with a diff of
+// the frames and of the error. The golden file is written instead when the tests run with the -update-golden flag.
+func CheckGoldenDataResponse(t *testing.T, path string, dr *backend.DataResponse) {
+	t.Helper()
+
+	if *updateGolden {
+		// the SDK only writes the file when it doesn't match, and can't read the errors of the responses
+		if dr.Error != nil {
+			if err := writeGoldenErrorFile(path, dr); err != nil {
+				t.Fatalf("failed to write golden file %s: %v", path, err)
+			}
+			t.Logf("updated golden file %s", path)
+		} else if err := experimental.CheckGoldenDataResponse(path, dr, true); err != nil {
+			t.Logf("updated golden file %s", path)
+		}
+		return
+	}
+
+	err := experimental.CheckGoldenDataResponse(path, dr, false)
+	if err == nil {
+		return
+	}
+
+	want, wantErr, readErr := readGoldenFrames(path)
+	if readErr != nil {
+		t.Fatalf("failed to read golden file %s, run the tests with -update-golden to write it: %v", path, readErr)
+	}
+	var diffs []string
+	if gotErr := responseError(dr); gotErr != wantErr {
+		diffs = append(diffs, fmt.Sprintf("error: want %q, got %q", wantErr, gotErr))
+	}
+	if diff := DiffFrames(want, dr.Frames); diff != "" {
+		diffs = append(diffs, diff)
+	}
+	if len(diffs) == 0 {
+		if wantErr != "" {
+			// the SDK fails on the golden files of errors
+			return
+		}
+		diffs = append(diffs, err.Error())
+	}
+	t.Fatalf("response doesn't match golden file %s, run the tests with -update-golden to update it:\n%s", path,
+		strings.Join(diffs, "\n"))
+}
+
+// CheckGoldenFrames calls CheckGoldenDataResponse with a data response of the frames.
+func CheckGoldenFrames(t *testing.T, path string, frames ...*data.Frame) {
+	t.Helper()
+	CheckGoldenDataResponse(t, path, &backend.DataResponse{Frames: frames})
+}
+
+// DiffFrames returns a description of the differences of the frames, one per line, or an empty string when they're
+// equal. The names, metadata, fields, labels, configs and values of the frames are compared.
+func DiffFrames(want, got data.Frames) string {
+	var diffs []string
+	add := func(format string, args ...interface{}) {
+		diffs = append(diffs, fmt.Sprintf(format, args...))
+	}
+
+	if len(want) != len(got) {
+		add("frame count: want %d, got %d", len(want), len(got))
+	}
+	for i := 0; i < len(want) && i < len(got); i++ {
+		w, g := want[i], got[i]
+		prefix := fmt.Sprintf("frame[%d]", i)
+		if w.Name != g.Name {
+			add("%s name: want %q, got %q", prefix, w.Name, g.Name)
+		}
+		if wm, gm := toJSON(w.Meta), toJSON(g.Meta); wm != gm {
+			add("%s meta: want %s, got %s", prefix, wm, gm)
+		}
+		if len(w.Fields) != len(g.Fields) {
+			add("%s field count: want %d, got %d", prefix, len(w.Fields), len(g.Fields))
+		}
+		if wr, gr := frameRows(w), frameRows(g); wr != gr {
+			add("%s row count: want %d, got %d", prefix, wr, gr)
+		}
+		for j := 0; j < len(w.Fields) && j < len(g.Fields); j++ {
+			for _, d := range diffFields(w.Fields[j], g.Fields[j]) {
+				add("%s field[%d] %s", prefix, j, d)
+			}
+		}
+	}
+
+	return strings.Join(diffs, "\n")
+}
+
+func diffFields(w, g *data.Field) []string {
+	var diffs []string
+	add := func(format string, args ...interface{}) {
+		diffs = append(diffs, fmt.Sprintf(format, args...))
+	}
+
+	if w.Name != g.Name {
+		add("name: want %q, got %q", w.Name, g.Name)
+	}
+	if w.Type() != g.Type() {
+		add("%q type: want %s, got %s", w.Name, w.Type(), g.Type())
+	}
+	if w.Labels.String() != g.Labels.String() {
+		add("%q labels: want %s, got %s", w.Name, w.Labels, g.Labels)
+	}
+	if wc, gc := toJSON(w.Config), toJSON(g.Config); wc != gc {
+		add("%q config: want %s, got %s", w.Name, wc, gc)
+	}
+
+	valueDiffs := 0
+	for row := 0; row < w.Len() && row < g.Len(); row++ {
+		wv, gv := fieldValue(w, row), fieldValue(g, row)
+		if wv == gv {
+			continue
+		}
+		if valueDiffs == maxValueDiffs {
+			add("%q has more different values", w.Name)
+			break
+		}
+		add("%q row %d: want %s, got %s", w.Name, row, wv, gv)
+		valueDiffs++
+	}
+	return diffs
+}
+
+// fieldValue returns the value of a row of a field formatted for the diffs, "null" for the null values.
+func fieldValue(f *data.Field, row int) string {
+	v, ok := f.ConcreteAt(row)
+	if !ok {
+		return "null"
+	}
+	if s, ok := v.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+func frameRows(f *data.Frame) int {
+	if len(f.Fields) == 0 {
+		return 0
+	}
+	return f.Fields[0].Len()
+}
+
+func toJSON(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
+
+// readGoldenFrames returns the frames and the error of a golden file written by the plugin SDK or
+// writeGoldenErrorFile.
+func readGoldenFrames(path string) (data.Frames, string, error) {
+	// Can ignore gosec G304 here, because the path is set by the tests
+	// nolint:gosec
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, "", err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	var frames data.Frames
+	var responseErr string
+	found := false
+	scanner := bufio.NewScanner(file)
+	// the encoded frames are on single lines, which may exceed the default buffer
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !found {
+			// the SDK writes the section after the description of the error without a line break
+			found = strings.Contains(line, goldenDataSection)
+			continue
+		}
+		if errString := strings.TrimPrefix(line, "ERROR="); errString != line {
+			responseErr = errString
+			continue
+		}
+		encoded := strings.TrimPrefix(line, "FRAME=")
+		if encoded == line {
+			continue
+		}
+		b, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, "", err
+		}
+		frame, err := data.UnmarshalArrowFrame(b)
+		if err != nil {
+			return nil, "", err
+		}
+		frames = append(frames, frame)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, "", err
+	}
+	if !found {
+		return nil, "", fmt.Errorf("no response found in golden file %s", path)
+	}
+	return frames, responseErr, nil
+}
+
+// writeGoldenErrorFile writes the golden file of a data response with an error, in the format of the plugin SDK.
+func writeGoldenErrorFile(path string, dr *backend.DataResponse) error {
+	var b strings.Builder
+	b.WriteString("🌟 This was machine generated.  Do not edit. 🌟\n")
+	fmt.Fprintf(&b, "\nERROR: %s\n", dr.Error)
+	for i, frame := range dr.Frames {
+		table, _ := frame.StringTable(100, 10)
+		fmt.Fprintf(&b, "\nFrame[%d]\n%s\n", i, table)
+	}
+
+	b.WriteString("\n" + goldenDataSection)
+	b.WriteString("\nERROR=" + dr.Error.Error())
+	for _, frame := range dr.Frames {
+		encoded, err := frame.MarshalArrow()
+		if err != nil {
+			return err
+		}
+		b.WriteString("\nFRAME=" + base64.StdEncoding.EncodeToString(encoded))
+	}
+	b.WriteString("\n")
+
+	return os.WriteFile(path, []byte(b.String()), 0600)
+}
+
+func responseError(dr *backend.DataResponse) string {
+	if dr.Error == nil {
+		return ""
+	}
+	return dr.Error.Error()
+}
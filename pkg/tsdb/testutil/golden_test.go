@@ -0,0 +1,86 @@
+package testutil
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/experimental"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffFrames(t *testing.T) {
+	newFrame := func(name string, values []*float64, labels data.Labels) *data.Frame {
+		return data.NewFrame(name,
+			data.NewField("time", nil, []int64{1, 2, 3}),
+			data.NewField("value", labels, values),
+		)
+	}
+	f := func(v float64) *float64 { return &v }
+
+	t.Run("Equal frames have no diff", func(t *testing.T) {
+		want := data.Frames{newFrame("a", []*float64{f(1), nil, f(3)}, nil)}
+		got := data.Frames{newFrame("a", []*float64{f(1), nil, f(3)}, nil)}
+		require.Empty(t, DiffFrames(want, got))
+	})
+
+	t.Run("Describes the differences of the frames", func(t *testing.T) {
+		want := data.Frames{newFrame("a", []*float64{f(1), nil, f(3)}, data.Labels{"host": "a"})}
+		got := data.Frames{
+			newFrame("b", []*float64{f(1), f(2), f(3)}, data.Labels{"host": "b"}),
+			newFrame("c", nil, nil),
+		}
+		require.Equal(t, `frame count: want 1, got 2
+frame[0] name: want "a", got "b"
+frame[0] field[1] "value" labels: want host=a, got host=b
+frame[0] field[1] "value" row 1: want null, got 2`, DiffFrames(want, got))
+	})
+
+	t.Run("Describes the differences of the fields", func(t *testing.T) {
+		want := data.Frames{data.NewFrame("a", data.NewField("value", nil, []string{"x", "y"}))}
+		got := data.Frames{data.NewFrame("a",
+			data.NewField("val", nil, []int64{1}),
+			data.NewField("extra", nil, []int64{1}),
+		)}
+		require.Equal(t, `frame[0] field count: want 1, got 2
+frame[0] row count: want 2, got 1
+frame[0] field[0] name: want "value", got "val"
+frame[0] field[0] "value" type: want []string, got []int64
+frame[0] field[0] "value" row 0: want "x", got 1`, DiffFrames(want, got))
+	})
+}
+
+func TestReadGoldenFrames(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.golden.txt")
+	frames := data.Frames{
+		data.NewFrame("a", data.NewField("value", nil, []float64{1, 2})),
+		data.NewFrame("b", data.NewField("value", nil, []string{"x"})),
+	}
+	// writes the missing golden file
+	require.Error(t, experimental.CheckGoldenDataResponse(path, &backend.DataResponse{Frames: frames}, true))
+
+	saved, savedErr, err := readGoldenFrames(path)
+	require.NoError(t, err)
+	require.Empty(t, DiffFrames(frames, saved))
+	require.Empty(t, savedErr)
+
+	CheckGoldenFrames(t, path, frames...)
+
+	_, _, err = readGoldenFrames(filepath.Join(t.TempDir(), "missing.golden.txt"))
+	require.Error(t, err)
+
+	t.Run("The errors of the responses are read", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "error.golden.txt")
+		dr := &backend.DataResponse{Error: errors.New("bad query")}
+		require.NoError(t, writeGoldenErrorFile(path, dr))
+
+		saved, savedErr, err := readGoldenFrames(path)
+		require.NoError(t, err)
+		require.Empty(t, saved)
+		require.Equal(t, "bad query", savedErr)
+
+		CheckGoldenDataResponse(t, path, dr)
+	})
+}
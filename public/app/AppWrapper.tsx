@@ -14,6 +14,7 @@ import { GrafanaRoute } from './core/navigation/GrafanaRoute';
 import { AppNotificationList } from './core/components/AppNotifications/AppNotificationList';
 import { SearchWrapper } from 'app/features/search';
 import { LiveConnectionWarning } from './features/live/LiveConnectionWarning';
+import { ImpersonationBanner } from './features/admin/ImpersonationBanner';
 
 interface AppWrapperProps {
   app: GrafanaApp;
@@ -114,6 +115,7 @@ export class AppWrapper extends React.Component<AppWrapperProps, AppWrapperState
                   </Router>
                 </div>
                 <LiveConnectionWarning />
+                <ImpersonationBanner />
                 <ModalRoot />
               </ModalsProvider>
             </ThemeProvider>
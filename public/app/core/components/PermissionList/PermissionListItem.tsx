@@ -15,6 +15,9 @@ function ItemAvatar({ item }: { item: DashboardAcl }) {
   if (item.teamAvatarUrl) {
     return <img className="filter-table__avatar" src={item.teamAvatarUrl} />;
   }
+  if (item.externalGroup) {
+    return <Icon size="lg" name="users-alt" />;
+  }
   if (item.role === 'Editor') {
     return <Icon size="lg" name="edit" />;
   }
@@ -29,6 +32,9 @@ function ItemDescription({ item }: { item: DashboardAcl }) {
   if (item.teamId) {
     return <span className="filter-table__weak-italic">(Team)</span>;
   }
+  if (item.externalGroup) {
+    return <span className="filter-table__weak-italic">(External group)</span>;
+  }
   return <span className="filter-table__weak-italic">(Role)</span>;
 }
 
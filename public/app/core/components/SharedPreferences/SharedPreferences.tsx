@@ -29,6 +29,8 @@ export interface State {
   homeDashboardId: number;
   theme: string;
   timezone: string;
+  weekStart: string;
+  fiscalYearStartMonth: number;
   dashboards: DashboardSearchHit[];
 }
 
@@ -38,6 +40,32 @@ const themes: SelectableValue[] = [
   { value: 'light', label: 'Light' },
 ];
 
+const weekStarts: SelectableValue[] = [
+  { value: '', label: 'Default' },
+  { value: 'browser', label: 'Local browser day' },
+  { value: 'monday', label: 'Monday' },
+  { value: 'saturday', label: 'Saturday' },
+  { value: 'sunday', label: 'Sunday' },
+];
+
+const months: SelectableValue[] = [
+  { value: 0, label: 'Default' },
+  ...[
+    'January',
+    'February',
+    'March',
+    'April',
+    'May',
+    'June',
+    'July',
+    'August',
+    'September',
+    'October',
+    'November',
+    'December',
+  ].map((label, index) => ({ value: index + 1, label })),
+];
+
 export class SharedPreferences extends PureComponent<Props, State> {
   service: PreferencesService;
 
@@ -49,6 +77,8 @@ export class SharedPreferences extends PureComponent<Props, State> {
       homeDashboardId: 0,
       theme: '',
       timezone: '',
+      weekStart: '',
+      fiscalYearStartMonth: 0,
       dashboards: [],
     };
   }
@@ -84,13 +114,15 @@ export class SharedPreferences extends PureComponent<Props, State> {
       homeDashboardId: prefs.homeDashboardId,
       theme: prefs.theme,
       timezone: prefs.timezone,
+      weekStart: prefs.weekStart ?? '',
+      fiscalYearStartMonth: prefs.fiscalYearStartMonth ?? 0,
       dashboards: [defaultDashboardHit, ...dashboards],
     });
   }
 
   onSubmitForm = async () => {
-    const { homeDashboardId, theme, timezone } = this.state;
-    this.service.update({ homeDashboardId, theme, timezone });
+    const { homeDashboardId, theme, timezone, weekStart, fiscalYearStartMonth } = this.state;
+    this.service.update({ homeDashboardId, theme, timezone, weekStart, fiscalYearStartMonth });
     window.location.reload();
   };
 
@@ -105,6 +137,14 @@ export class SharedPreferences extends PureComponent<Props, State> {
     this.setState({ timezone: timezone });
   };
 
+  onWeekStartChanged = (weekStart: string) => {
+    this.setState({ weekStart });
+  };
+
+  onFiscalYearStartMonthChanged = (fiscalYearStartMonth: number) => {
+    this.setState({ fiscalYearStartMonth });
+  };
+
   onHomeDashboardChanged = (dashboardId: number) => {
     this.setState({ homeDashboardId: dashboardId });
   };
@@ -117,7 +157,7 @@ export class SharedPreferences extends PureComponent<Props, State> {
   };
 
   render() {
-    const { theme, timezone, homeDashboardId, dashboards } = this.state;
+    const { theme, timezone, weekStart, fiscalYearStartMonth, homeDashboardId, dashboards } = this.state;
     const styles = getStyles();
 
     return (
@@ -157,6 +197,28 @@ export class SharedPreferences extends PureComponent<Props, State> {
               <Field label="Timezone" aria-label={selectors.components.TimeZonePicker.container}>
                 <TimeZonePicker includeInternal={true} value={timezone} onChange={this.onTimeZoneChanged} />
               </Field>
+
+              <Field
+                label="Week start"
+                description="First day of the week of the relative time ranges, such as This week"
+              >
+                <Select
+                  value={weekStarts.find((item) => item.value === weekStart)}
+                  options={weekStarts}
+                  onChange={(item: SelectableValue<string>) => this.onWeekStartChanged(item.value ?? '')}
+                />
+              </Field>
+
+              <Field
+                label="Fiscal year start month"
+                description="First month of the fiscal year of the fiscal time ranges, such as This fiscal year"
+              >
+                <Select
+                  value={months.find((item) => item.value === fiscalYearStartMonth)}
+                  options={months}
+                  onChange={(item: SelectableValue<number>) => this.onFiscalYearStartMonthChanged(item.value ?? 0)}
+                />
+              </Field>
               <div className="gf-form-button-row">
                 <Button variant="primary" aria-label="User preferences save button">
                   Save
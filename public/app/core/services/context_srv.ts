@@ -19,6 +19,7 @@ export class User {
   hasEditPermissionInFolders: boolean;
   email?: string;
   permissions?: UserPermission;
+  impersonatorLogin?: string;
 
   constructor() {
     this.id = 0;
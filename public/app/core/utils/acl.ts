@@ -15,6 +15,9 @@ function processAclItem(dto: DashboardAclDTO): DashboardAcl {
   } else if (item.teamId! > 0) {
     item.name = item.team;
     item.sortRank = 20;
+  } else if (item.externalGroup) {
+    item.name = item.externalGroup;
+    item.sortRank = 25;
   } else if (item.role) {
     item.icon = 'fa fa-fw fa-street-view';
     item.name = item.role;
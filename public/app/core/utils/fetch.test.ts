@@ -66,6 +66,7 @@ describe('parseHeaders', () => {
     ${{ method: 'PUT', headers: { 'Content-Type': 'application/x-www-form-urlencoded' } }}  | ${{ map: { accept: 'application/json, text/plain, */*', 'content-type': 'application/x-www-form-urlencoded' } }}
     ${{ headers: { Accept: 'text/plain' } }}                                                | ${{ map: { accept: 'text/plain' } }}
     ${{ headers: { Auth: 'Basic asdasdasd' } }}                                             | ${{ map: { accept: 'application/json, text/plain, */*', auth: 'Basic asdasdasd' } }}
+    ${{ method: 'POST', data: new FormData() }}                                             | ${{ map: { accept: 'application/json, text/plain, */*' } }}
   `("when called with options: '$options' then the result should be '$expected'", ({ options, expected }) => {
     expect(parseHeaders(options)).toEqual(expected);
   });
@@ -86,6 +87,9 @@ describe('isContentTypeApplicationJson', () => {
 });
 
 describe('parseBody', () => {
+  const formData = new FormData();
+  formData.append('file', 'a,b');
+
   it.each`
     options                  | isAppJson | expected
     ${undefined}             | ${false}  | ${undefined}
@@ -96,6 +100,7 @@ describe('parseBody', () => {
     ${{ data: 'some data' }} | ${true}   | ${'some data'}
     ${{ data: { id: '0' } }} | ${false}  | ${new URLSearchParams({ id: '0' })}
     ${{ data: { id: '0' } }} | ${true}   | ${'{"id":"0"}'}
+    ${{ data: formData }}    | ${false}  | ${formData}
   `(
     "when called with options: '$options' and isAppJson: '$isAppJson' then the result should be '$expected'",
     ({ options, isAppJson, expected }) => {
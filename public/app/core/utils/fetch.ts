@@ -38,7 +38,8 @@ const defaultHeaderParser: HeaderParser = {
 const parseHeaderByMethodFactory = (methodPredicate: string): HeaderParser => ({
   canParse: (options) => {
     const method = options?.method ? options?.method.toLowerCase() : '';
-    return method === methodPredicate;
+    // the browser sets the content type of forms with their boundary
+    return method === methodPredicate && !(options?.data instanceof FormData);
   },
   parse: (headers) => {
     const contentType = headers.get('content-type');
@@ -85,7 +86,7 @@ export const parseBody = (options: BackendSrvRequest, isAppJson: boolean) => {
     return options;
   }
 
-  if (!options.data || typeof options.data === 'string') {
+  if (!options.data || typeof options.data === 'string' || options.data instanceof FormData) {
     return options.data;
   }
 
@@ -0,0 +1,44 @@
+import React from 'react';
+import { css } from '@emotion/css';
+import { GrafanaTheme2 } from '@grafana/data';
+import { config, getBackendSrv } from '@grafana/runtime';
+import { Alert, useStyles2 } from '@grafana/ui';
+import { contextSrv } from 'app/core/services/context_srv';
+
+/**
+ * Shows the Grafana admins impersonating a user who they impersonate, with a button stopping the impersonation.
+ */
+export function ImpersonationBanner() {
+  const styles = useStyles2(getStyles);
+  const { impersonatorLogin, login } = contextSrv.user;
+  if (!impersonatorLogin) {
+    return null;
+  }
+
+  const onStop = async () => {
+    await getBackendSrv().post('/api/user/impersonation/stop');
+    window.location.href = config.appSubUrl + '/';
+  };
+
+  return (
+    <div className={styles.banner}>
+      <Alert
+        severity="warning"
+        title={`${impersonatorLogin} is impersonating ${login}`}
+        buttonContent="Stop impersonation"
+        onRemove={onStop}
+      />
+    </div>
+  );
+}
+
+const getStyles = (theme: GrafanaTheme2) => ({
+  banner: css`
+    position: fixed;
+    top: ${theme.spacing(1)};
+    left: 50%;
+    transform: translateX(-50%);
+    z-index: ${theme.zIndex.navbarFixed};
+    min-width: 400px;
+  `,
+});
@@ -25,7 +25,11 @@ export const useDashboardSave = (dashboard: DashboardModel) => {
   );
 
   useEffect(() => {
-    if (state.value) {
+    if (state.value?.status === 'pending') {
+      // the changes to the dashboards of protected folders are published once a reviewer approves them
+      appEvents.publish(new DashboardSavedEvent());
+      appEvents.emit(AppEvents.alertSuccess, ['Changes submitted for review', state.value.message]);
+    } else if (state.value) {
       dashboard.version = state.value.version;
       // important that these happen before location redirect below
       appEvents.publish(new DashboardSavedEvent());
@@ -171,6 +171,14 @@ export function initDashboard(args: InitDashboardArgs): ThunkResult<void> {
       locationService.partial({ orgId: storeState.user.orgId }, true);
     }
 
+    // apply the annotation toggles of shared links and panel images
+    for (const annotation of dashboard.annotations.list) {
+      const toggle = queryParams[`annotation-${annotation.name}`];
+      if (toggle === 'on' || toggle === 'off') {
+        annotation.enable = toggle === 'on';
+      }
+    }
+
     // init services
     const timeSrv: TimeSrv = getTimeSrv();
     const dashboardSrv: DashboardSrv = getDashboardSrv();
@@ -4,6 +4,7 @@ import { isString } from 'lodash';
 import Page from 'app/core/components/Page/Page';
 import { PluginSettings } from './PluginSettings';
 import BasicSettings from './BasicSettings';
+import QueryLimitsSettings from './QueryLimitsSettings';
 import ButtonRow from './ButtonRow';
 // Services & Utils
 import appEvents from 'app/core/app_events';
@@ -252,6 +253,8 @@ export class DataSourceSettingsPage extends PureComponent<Props> {
           />
         )}
 
+        {dataSourceMeta.metrics && <QueryLimitsSettings dataSource={dataSource} onChange={this.onModelChange} />}
+
         {testingStatus?.message && (
           <div className="gf-form-group p-t-2">
             <Alert
@@ -0,0 +1,56 @@
+import React, { FC } from 'react';
+import { DataSourceSettings } from '@grafana/data';
+import { InlineFormLabel, LegacyForms } from '@grafana/ui';
+
+const { Input, Switch } = LegacyForms;
+
+export interface Props {
+  dataSource: DataSourceSettings;
+  onChange: (dataSource: DataSourceSettings) => void;
+}
+
+/**
+ * The max data age of the queries of a data source, enforced by the server on the queries it runs.
+ */
+const QueryLimitsSettings: FC<Props> = ({ dataSource, onChange }) => {
+  const onJsonDataChange = (key: string, value: string | boolean) => {
+    onChange({ ...dataSource, jsonData: { ...dataSource.jsonData, [key]: value } });
+  };
+
+  return (
+    <>
+      <h3 className="page-heading">Query limits</h3>
+      <div className="gf-form-group" aria-label="Datasource settings page query limits">
+        <div className="gf-form-inline">
+          <div className="gf-form max-width-30" style={{ marginRight: '3px' }}>
+            <InlineFormLabel
+              width={10}
+              tooltip={
+                'How far back the queries run by the server can reach, e.g. 90d. The queries starting before ' +
+                'fail, or start at the max data age when snapped. Leave empty for no limit.'
+              }
+            >
+              Max data age
+            </InlineFormLabel>
+            <Input
+              className="gf-form-input max-width-10"
+              type="text"
+              value={dataSource.jsonData.maxDataAge ?? ''}
+              placeholder="90d"
+              onChange={(event) => onJsonDataChange('maxDataAge', event.target.value)}
+            />
+          </div>
+          <Switch
+            label="Snap to max data age"
+            labelClass="width-12"
+            tooltip="Start the queries reaching further back than the max data age at it instead of failing them"
+            checked={!!dataSource.jsonData.snapToMaxDataAge}
+            onChange={(event) => onJsonDataChange('snapToMaxDataAge', event.currentTarget.checked)}
+          />
+        </div>
+      </div>
+    </>
+  );
+};
+
+export default QueryLimitsSettings;
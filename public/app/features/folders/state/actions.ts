@@ -47,6 +47,7 @@ function toUpdateItem(item: DashboardAcl): DashboardAclUpdateDTO {
   return {
     userId: item.userId,
     teamId: item.teamId,
+    externalGroup: item.externalGroup,
     role: item.role,
     permission: item.permission,
   };
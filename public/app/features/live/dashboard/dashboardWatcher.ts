@@ -24,6 +24,7 @@ class DashboardWatcher {
   editing = false;
   lastEditing?: DashboardEvent;
   subscription?: Unsubscribable;
+  editingHeartbeat?: ReturnType<typeof setInterval>;
 
   setEditingState(state: boolean) {
     const changed = (this.editing = state);
@@ -32,6 +33,15 @@ class DashboardWatcher {
     if (changed) {
       this.sendEditingState();
     }
+
+    // the server forgets the editors who don't tell they are still editing
+    if (this.editingHeartbeat) {
+      clearInterval(this.editingHeartbeat);
+      this.editingHeartbeat = undefined;
+    }
+    if (state) {
+      this.editingHeartbeat = setInterval(() => this.sendEditingState(), 60 * 1000);
+    }
   }
 
   private sendEditingState() {
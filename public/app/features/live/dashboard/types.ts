@@ -2,6 +2,7 @@ export enum DashboardEventAction {
   Saved = 'saved',
   EditingStarted = 'editing-started', // Sent when someone (who can save!) opens the editor
   EditingCanceled = 'editing-cancelled', // Sent when someone discards changes, or unsubscribes while editing
+  EditingTakeover = 'editing-takeover', // Sent when someone takes over the edit lock
   Deleted = 'deleted',
 }
 
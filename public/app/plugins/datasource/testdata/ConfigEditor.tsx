@@ -1,15 +1,54 @@
 // Libraries
-import React, { PureComponent } from 'react';
+import React, { FocusEvent, PureComponent } from 'react';
 
-import { DataSourcePluginOptionsEditorProps } from '@grafana/data';
+import { DataSourcePluginOptionsEditorProps, SelectableValue } from '@grafana/data';
+import { InlineField, InlineFieldRow, Input, Select } from '@grafana/ui';
+import { TestDataJsonData } from './types';
 
-type Props = DataSourcePluginOptionsEditorProps<any>;
+type Props = DataSourcePluginOptionsEditorProps<TestDataJsonData>;
+
+const healthStatuses: Array<SelectableValue<TestDataJsonData['healthStatus']>> = [
+  { value: 'ok', label: 'OK', description: 'The data source is working' },
+  { value: 'error', label: 'Error', description: 'The health check fails' },
+  { value: 'fail', label: 'Fail', description: 'The health check handler fails, with an HTTP 500 error' },
+  { value: 'unavailable', label: 'Unavailable', description: 'The plugin is unavailable, with an HTTP 503 error' },
+];
 
 /**
- * Empty Config Editor -- settings to save
+ * Config Editor -- simulates the failures of the health checks
  */
 export class ConfigEditor extends PureComponent<Props> {
+  onJsonDataChange = (jsonData: TestDataJsonData) => {
+    const { onOptionsChange, options } = this.props;
+    onOptionsChange({ ...options, jsonData: { ...options.jsonData, ...jsonData } });
+  };
+
+  onHealthLatencyChange = (e: FocusEvent<HTMLInputElement>) => {
+    this.onJsonDataChange({ healthLatency: e.currentTarget.value || undefined });
+  };
+
   render() {
-    return <div />;
+    const { jsonData } = this.props.options;
+
+    return (
+      <InlineFieldRow>
+        <InlineField label="Health status" labelWidth={16} tooltip="Status returned by Save & Test">
+          <Select
+            width={32}
+            options={healthStatuses}
+            value={healthStatuses.find((s) => s.value === (jsonData.healthStatus ?? 'ok'))}
+            onChange={({ value }) => this.onJsonDataChange({ healthStatus: value })}
+          />
+        </InlineField>
+        <InlineField label="Health latency" labelWidth={16} tooltip="Delay of the health check, e.g. 2s">
+          <Input
+            width={20}
+            placeholder="0s"
+            defaultValue={jsonData.healthLatency}
+            onBlur={this.onHealthLatencyChange}
+          />
+        </InlineField>
+      </InlineFieldRow>
+    );
   }
 }
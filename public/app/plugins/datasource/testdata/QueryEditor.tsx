@@ -19,8 +19,23 @@ import { NodeGraphEditor } from './components/NodeGraphEditor';
 import { defaultStreamQuery } from './runStreams';
 import { CSVFileEditor } from './components/CSVFileEditor';
 import { CSVContentEditor } from './components/CSVContentEditor';
-
-const showLabelsFor = ['random_walk', 'predictable_pulse'];
+import { CSVStreamEditor } from './components/CSVStreamEditor';
+import { FileEditor } from './components/FileEditor';
+import { ChaosEditor } from './components/ChaosEditor';
+import { SimulationEditor } from './components/SimulationEditor';
+
+const showLabelsFor = ['random_walk', 'predictable_pulse', 'chaos'];
+// the random walk editor has its own seed
+const showSeedFor = [
+  'random_walk_table',
+  'random_walk_with_error',
+  'slow_query',
+  'exponential_heatmap_bucket_data',
+  'linear_heatmap_bucket_data',
+  'logs',
+  'chaos',
+  'sim',
+];
 const endpoints = [
   { value: 'datasources', label: 'Data Sources' },
   { value: 'search', label: 'Search' },
@@ -77,6 +92,7 @@ export const QueryEditor = ({ query, datasource, onChange, onRunQuery }: Props)
 
     // Clear model from existing props that belong to other scenarios
     const update: TestDataQuery = {
+      ...scenario.defaultQuery,
       scenarioId: item.value!,
       refId: query.refId,
       alias: query.alias,
@@ -102,6 +118,13 @@ export const QueryEditor = ({ query, datasource, onChange, onRunQuery }: Props)
       case 'predictable_csv_wave':
         update.csvWave = defaultCSVWaveQuery;
         break;
+      case 'csv_stream':
+        update.csvFileName = 'sensor_readings.csv';
+        update.csvStream = { speed: 1, loop: true, timeShift: true };
+        break;
+      case 'sim':
+        update.sim = { type: 'tank' };
+        break;
     }
 
     onUpdate(update);
@@ -152,6 +175,7 @@ export const QueryEditor = ({ query, datasource, onChange, onRunQuery }: Props)
     [scenarioList]
   );
   const showLabels = useMemo(() => showLabelsFor.includes(query.scenarioId), [query]);
+  const showSeed = useMemo(() => showSeedFor.includes(query.scenarioId), [query]);
 
   if (loading) {
     return null;
@@ -219,13 +243,46 @@ export const QueryEditor = ({ query, datasource, onChange, onRunQuery }: Props)
             />
           </InlineField>
         )}
+        {showSeed && (
+          <InlineField label="Seed" labelWidth={14} tooltip="Returns the same random values for the same time range">
+            <Input
+              width={12}
+              id={`seed-${query.refId}`}
+              type="number"
+              step={1}
+              name="seed"
+              placeholder="random"
+              value={query.seed}
+              onChange={onInputChange}
+            />
+          </InlineField>
+        )}
       </InlineFieldRow>
 
       {scenarioId === 'random_walk' && <RandomWalkEditor onChange={onInputChange} query={query} />}
       {scenarioId === 'streaming_client' && <StreamingClientEditor onChange={onStreamClientChange} query={query} />}
       {scenarioId === 'live' && <GrafanaLiveEditor onChange={onUpdate} query={query} />}
-      {scenarioId === 'csv_file' && <CSVFileEditor onChange={onUpdate} query={query} />}
+      {scenarioId === 'csv_file' && <CSVFileEditor onChange={onUpdate} query={query} datasource={datasource} />}
       {scenarioId === 'csv_content' && <CSVContentEditor onChange={onUpdate} query={query} />}
+      {scenarioId === 'csv_stream' && <CSVStreamEditor onChange={onUpdate} query={query} datasource={datasource} />}
+      {scenarioId === 'json_file' && (
+        <FileEditor
+          onChange={onUpdate}
+          query={query}
+          fileNameKey="jsonFileName"
+          fileNames={['server_inventory.json']}
+        />
+      )}
+      {scenarioId === 'parquet_file' && (
+        <FileEditor
+          onChange={onUpdate}
+          query={query}
+          fileNameKey="parquetFileName"
+          fileNames={['weather_stations.parquet']}
+        />
+      )}
+      {scenarioId === 'chaos' && <ChaosEditor onChange={onUpdate} query={query} />}
+      {scenarioId === 'sim' && <SimulationEditor onChange={onUpdate} query={query} />}
       {scenarioId === 'logs' && (
         <InlineFieldRow>
           <InlineField label="Lines" labelWidth={14}>
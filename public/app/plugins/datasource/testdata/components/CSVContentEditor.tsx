@@ -1,6 +1,7 @@
 import React, { ChangeEvent } from 'react';
 import { InlineField, TextArea } from '@grafana/ui';
 import { EditorProps } from '../QueryEditor';
+import { CSVOptionsEditor } from './CSVOptionsEditor';
 
 export const CSVContentEditor = ({ onChange, query }: EditorProps) => {
   const onContent = (e: ChangeEvent<HTMLTextAreaElement>) => {
@@ -8,14 +9,17 @@ export const CSVContentEditor = ({ onChange, query }: EditorProps) => {
   };
 
   return (
-    <InlineField label="CSV" labelWidth={14}>
-      <TextArea
-        width="100%"
-        rows={10}
-        onBlur={onContent}
-        placeholder="CSV content"
-        defaultValue={query.csvContent ?? ''}
-      />
-    </InlineField>
+    <>
+      <InlineField label="CSV" labelWidth={14}>
+        <TextArea
+          width="100%"
+          rows={10}
+          onBlur={onContent}
+          placeholder="CSV content"
+          defaultValue={query.csvContent ?? ''}
+        />
+      </InlineField>
+      <CSVOptionsEditor onChange={onChange} query={query} />
+    </>
   );
 };
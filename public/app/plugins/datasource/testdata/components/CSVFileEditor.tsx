@@ -1,26 +1,86 @@
-import React from 'react';
-import { InlineField, InlineFieldRow, Select } from '@grafana/ui';
+import React, { FormEvent, useMemo, useState } from 'react';
+import { useAsync } from 'react-use';
+import { FileUpload, InlineField, InlineFieldRow, Input, Select } from '@grafana/ui';
 import { SelectableValue } from '@grafana/data';
 import { EditorProps } from '../QueryEditor';
+import { TestDataDataSource } from '../datasource';
+import { CSVOptionsEditor } from './CSVOptionsEditor';
+
+interface Props extends EditorProps {
+  datasource: TestDataDataSource;
+}
+
+/**
+ * Loads the CSV files the CSV scenarios can read, the example files, the ones of the directories of the csv_dirs
+ * setting and the uploaded ones. The files are loaded again when the revision changes.
+ */
+export const useCSVFiles = (datasource: TestDataDataSource, revision = 0): Array<SelectableValue<string>> => {
+  const { value: directories } = useAsync(() => datasource.getCsvFiles(), [datasource, revision]);
+
+  return useMemo(
+    () =>
+      (directories ?? []).flatMap((directory) =>
+        directory.files.map((name) => ({ label: name, value: name, description: directory.name }))
+      ),
+    [directories]
+  );
+};
+
+export const CSVFileEditor = ({ onChange, query, datasource }: Props) => {
+  const [revision, setRevision] = useState(0);
 
-export const CSVFileEditor = ({ onChange, query }: EditorProps) => {
   const onChangeFileName = ({ value }: SelectableValue<string>) => {
     onChange({ ...query, csvFileName: value });
   };
 
-  const files = ['population_by_state.csv', 'city_stats.csv'].map((name) => ({ label: name, value: name }));
+  const onChangeURL = (event: FormEvent<HTMLInputElement>) => {
+    onChange({ ...query, csvUrl: event.currentTarget.value || undefined });
+  };
+
+  const onFileUpload = async (event: FormEvent<HTMLInputElement>) => {
+    const file = event.currentTarget.files?.[0];
+    if (!file) {
+      return;
+    }
+
+    const { fileName } = await datasource.uploadCsvFile(file);
+    setRevision((r) => r + 1);
+    onChange({ ...query, csvFileName: fileName });
+  };
+
+  const files = useCSVFiles(datasource, revision);
 
   return (
-    <InlineFieldRow>
-      <InlineField label="File" labelWidth={14}>
-        <Select
-          width={32}
-          onChange={onChangeFileName}
-          placeholder="Select csv file"
-          options={files}
-          value={files.find((f) => f.value === query.csvFileName)}
-        />
-      </InlineField>
-    </InlineFieldRow>
+    <>
+      <InlineFieldRow>
+        <InlineField label="File" labelWidth={14}>
+          <Select
+            width={32}
+            onChange={onChangeFileName}
+            placeholder="Select csv file"
+            options={files}
+            value={files.find((f) => f.value === query.csvFileName)}
+          />
+        </InlineField>
+        <FileUpload accept=".csv" size="sm" onFileUpload={onFileUpload}>
+          Upload CSV
+        </FileUpload>
+      </InlineFieldRow>
+      <InlineFieldRow>
+        <InlineField
+          label="URL"
+          labelWidth={14}
+          tooltip="Remote CSV file read instead of the file, from the hosts allowed by the csv_url_hosts setting"
+        >
+          <Input
+            width={64}
+            placeholder="https://raw.githubusercontent.com/..."
+            defaultValue={query.csvUrl}
+            onBlur={onChangeURL}
+          />
+        </InlineField>
+      </InlineFieldRow>
+      <CSVOptionsEditor onChange={onChange} query={query} />
+    </>
   );
 };
@@ -0,0 +1,50 @@
+import React, { ChangeEvent, FocusEvent } from 'react';
+import { InlineField, InlineFieldRow, InlineSwitch, Input } from '@grafana/ui';
+import { EditorProps } from '../QueryEditor';
+import { CSVOptions } from '../types';
+
+export const CSVOptionsEditor = ({ onChange, query }: EditorProps) => {
+  const csvOptions: CSVOptions = query.csvOptions ?? {};
+
+  const onOptionsChange = (options: CSVOptions) => {
+    onChange({ ...query, csvOptions: { ...csvOptions, ...options } });
+  };
+
+  const onTextChange = (name: 'delimiter' | 'timezone') => (e: FocusEvent<HTMLInputElement>) => {
+    onOptionsChange({ [name]: e.currentTarget.value || undefined });
+  };
+
+  const onNullValuesChange = (e: FocusEvent<HTMLInputElement>) => {
+    const nullValues = e.currentTarget.value
+      .split(',')
+      .map((v) => v.trim())
+      .filter((v) => v !== '');
+    onOptionsChange({ nullValues: nullValues.length ? nullValues : undefined });
+  };
+
+  const onDecimalCommaChange = (e: ChangeEvent<HTMLInputElement>) => {
+    onOptionsChange({ decimalComma: e.target.checked });
+  };
+
+  return (
+    <InlineFieldRow>
+      <InlineField label="Delimiter" labelWidth={14} tooltip="Separates the values, a comma by default. \t for tabs">
+        <Input width={8} placeholder="," defaultValue={csvOptions.delimiter} onBlur={onTextChange('delimiter')} />
+      </InlineField>
+      <InlineField label="Decimal comma" tooltip="Parse the numbers with a decimal comma, e.g. 1.234,5">
+        <InlineSwitch value={csvOptions.decimalComma ?? false} onChange={onDecimalCommaChange} />
+      </InlineField>
+      <InlineField label="Null values" tooltip="Comma separated values parsed as nulls, besides the empty values">
+        <Input
+          width={20}
+          placeholder="null"
+          defaultValue={csvOptions.nullValues?.join(', ')}
+          onBlur={onNullValuesChange}
+        />
+      </InlineField>
+      <InlineField label="Timezone" tooltip="Timezone of the times without one, e.g. Europe/Berlin">
+        <Input width={20} placeholder="UTC" defaultValue={csvOptions.timezone} onBlur={onTextChange('timezone')} />
+      </InlineField>
+    </InlineFieldRow>
+  );
+};
@@ -0,0 +1,68 @@
+import React, { ChangeEvent } from 'react';
+import { InlineField, InlineFieldRow, InlineSwitch, Input, Select } from '@grafana/ui';
+import { SelectableValue } from '@grafana/data';
+import { EditorProps } from '../QueryEditor';
+import { CSVStreamQuery } from '../types';
+import { TestDataDataSource } from '../datasource';
+import { useCSVFiles } from './CSVFileEditor';
+
+interface Props extends EditorProps {
+  datasource: TestDataDataSource;
+}
+
+export const CSVStreamEditor = ({ onChange, query, datasource }: Props) => {
+  const files = useCSVFiles(datasource);
+  const csvStream: CSVStreamQuery = { speed: 1, loop: false, timeShift: false, ...query.csvStream };
+
+  const onChangeFileName = ({ value }: SelectableValue<string>) => {
+    onChange({ ...query, csvFileName: value });
+  };
+
+  const onSpeedChange = (e: ChangeEvent<HTMLInputElement>) => {
+    onChange({ ...query, csvStream: { ...csvStream, speed: Number(e.target.value) } });
+  };
+
+  const onSwitchChange = (name: 'loop' | 'timeShift') => (e: ChangeEvent<HTMLInputElement>) => {
+    onChange({ ...query, csvStream: { ...csvStream, [name]: e.target.checked } });
+  };
+
+  return (
+    <InlineFieldRow>
+      <InlineField label="File" labelWidth={14}>
+        <Select
+          width={32}
+          onChange={onChangeFileName}
+          placeholder="Select csv file"
+          options={files}
+          value={files.find((f) => f.value === query.csvFileName)}
+        />
+      </InlineField>
+      <InlineField
+        label="Speed"
+        labelWidth={14}
+        tooltip="Replay speed, 2 replays the rows twice as fast as their times"
+      >
+        <Input
+          width={32}
+          type="number"
+          id={`csvStream.speed-${query.refId}`}
+          name="speed"
+          min={0.1}
+          step={0.1}
+          value={csvStream.speed}
+          onChange={onSpeedChange}
+        />
+      </InlineField>
+      <InlineField label="Loop" labelWidth={14}>
+        <InlineSwitch value={csvStream.loop} onChange={onSwitchChange('loop')} />
+      </InlineField>
+      <InlineField
+        label="Time shift"
+        labelWidth={14}
+        tooltip="Shift the times of the rows to the times they're replayed at"
+      >
+        <InlineSwitch value={csvStream.timeShift} onChange={onSwitchChange('timeShift')} />
+      </InlineField>
+    </InlineFieldRow>
+  );
+};
@@ -0,0 +1,75 @@
+import React, { ChangeEvent, FocusEvent } from 'react';
+import { InlineField, InlineFieldRow, Input, Select } from '@grafana/ui';
+import { SelectableValue } from '@grafana/data';
+import { EditorProps } from '../QueryEditor';
+import { ChaosQuery } from '../types';
+
+const failures: Array<SelectableValue<ChaosQuery['failure']>> = [
+  { value: 'none', label: 'None', description: 'Return a random walk' },
+  { value: 'error', label: 'Error', description: 'Fail the query' },
+  { value: 'partial', label: 'Partial', description: 'Return a random walk with an error' },
+  { value: 'oversized', label: 'Oversized', description: 'Return a frame with many rows' },
+];
+
+export const ChaosEditor = ({ onChange, query }: EditorProps) => {
+  const chaos: ChaosQuery = query.chaos ?? {};
+
+  const onChaosChange = (options: ChaosQuery) => {
+    onChange({ ...query, chaos: { ...chaos, ...options } });
+  };
+
+  const onDurationChange = (name: 'latency' | 'jitter') => (e: FocusEvent<HTMLInputElement>) => {
+    onChaosChange({ [name]: e.currentTarget.value || undefined });
+  };
+
+  const onNumberChange = (name: 'probability' | 'rows') => (e: ChangeEvent<HTMLInputElement>) => {
+    const value = e.target.value;
+    onChaosChange({ [name]: value === '' ? undefined : Number(value) });
+  };
+
+  return (
+    <InlineFieldRow>
+      <InlineField label="Failure" labelWidth={14}>
+        <Select
+          width={32}
+          options={failures}
+          value={failures.find((f) => f.value === (chaos.failure ?? 'none'))}
+          onChange={({ value }) => onChaosChange({ failure: value })}
+        />
+      </InlineField>
+      <InlineField label="Probability" labelWidth={14} tooltip="Probability of the failure, between 0 and 1">
+        <Input
+          width={32}
+          type="number"
+          id={`chaos.probability-${query.refId}`}
+          min={0}
+          max={1}
+          step={0.05}
+          placeholder="1"
+          value={chaos.probability ?? ''}
+          onChange={onNumberChange('probability')}
+        />
+      </InlineField>
+      <InlineField label="Latency" labelWidth={14} tooltip="Delay of the response, e.g. 500ms or 2s">
+        <Input width={32} placeholder="0s" defaultValue={chaos.latency} onBlur={onDurationChange('latency')} />
+      </InlineField>
+      <InlineField label="Jitter" labelWidth={14} tooltip="Maximum random delay added to the latency">
+        <Input width={32} placeholder="0s" defaultValue={chaos.jitter} onBlur={onDurationChange('jitter')} />
+      </InlineField>
+      {chaos.failure === 'oversized' && (
+        <InlineField label="Rows" labelWidth={14} tooltip="Rows of the oversized frame, at most 5000000">
+          <Input
+            width={32}
+            type="number"
+            id={`chaos.rows-${query.refId}`}
+            min={1}
+            max={5000000}
+            placeholder="1000000"
+            value={chaos.rows ?? ''}
+            onChange={onNumberChange('rows')}
+          />
+        </InlineField>
+      )}
+    </InlineFieldRow>
+  );
+};
@@ -0,0 +1,32 @@
+import React from 'react';
+import { InlineField, InlineFieldRow, Select } from '@grafana/ui';
+import { SelectableValue } from '@grafana/data';
+import { EditorProps } from '../QueryEditor';
+import { TestDataQuery } from '../types';
+
+interface Props extends EditorProps {
+  fileNameKey: 'jsonFileName' | 'parquetFileName';
+  fileNames: string[];
+}
+
+export const FileEditor = ({ onChange, query, fileNameKey, fileNames }: Props) => {
+  const onChangeFileName = ({ value }: SelectableValue<string>) => {
+    onChange({ ...query, [fileNameKey]: value } as TestDataQuery);
+  };
+
+  const files = fileNames.map((name) => ({ label: name, value: name }));
+
+  return (
+    <InlineFieldRow>
+      <InlineField label="File" labelWidth={14}>
+        <Select
+          width={32}
+          onChange={onChangeFileName}
+          placeholder="Select file"
+          options={files}
+          value={files.find((f) => f.value === query[fileNameKey])}
+        />
+      </InlineField>
+    </InlineFieldRow>
+  );
+};
@@ -11,6 +11,24 @@ const randomWalkFields = [
   { label: 'Noise', id: 'noise', placeholder: '0', min: 0, step: 0.1 },
   { label: 'Min', id: 'min', placeholder: 'none', step: 0.1 },
   { label: 'Max', id: 'max', placeholder: 'none', step: 0.1 },
+  { label: 'Seed', id: 'seed', placeholder: 'random', step: 1 },
+  { label: 'Gaps', id: 'gapProbability', placeholder: '0', min: 0, max: 1, step: 0.01 },
+  { label: 'Spikes', id: 'spikeProbability', placeholder: '0', min: 0, max: 1, step: 0.01 },
+];
+
+const randomWalkTextFields = [
+  {
+    label: 'Series labels',
+    id: 'seriesLabels',
+    placeholder: 'host=*10, region=eu|us',
+    tooltip: 'A series per combination of the label values, *N for N values. Replaces the series count',
+  },
+  {
+    label: 'Counter reset',
+    id: 'counterReset',
+    placeholder: 'none',
+    tooltip: 'Return counters of the walk, reset at every multiple of the interval, e.g. 1h',
+  },
 ];
 
 const testSelectors = selectors.components.DataSource.TestData.QueryTab;
@@ -19,7 +37,7 @@ type Selector = 'max' | 'min' | 'noise' | 'seriesCount' | 'spread' | 'startValue
 export const RandomWalkEditor = ({ onChange, query }: EditorProps) => {
   return (
     <InlineFieldRow>
-      {randomWalkFields.map(({ label, id, min, step, placeholder }) => {
+      {randomWalkFields.map(({ label, id, min, max, step, placeholder }) => {
         const selector = testSelectors?.[id as Selector];
         return (
           <InlineField label={label} labelWidth={14} key={id} aria-label={selector}>
@@ -29,6 +47,7 @@ export const RandomWalkEditor = ({ onChange, query }: EditorProps) => {
               type="number"
               id={`randomWalk-${id}-${query.refId}`}
               min={min}
+              max={max}
               step={step}
               value={(query as any)[id as keyof TestDataQuery] || placeholder}
               placeholder={placeholder}
@@ -37,6 +56,18 @@ export const RandomWalkEditor = ({ onChange, query }: EditorProps) => {
           </InlineField>
         );
       })}
+      {randomWalkTextFields.map(({ label, id, placeholder, tooltip }) => (
+        <InlineField label={label} labelWidth={14} key={id} tooltip={tooltip}>
+          <Input
+            width={32}
+            name={id}
+            id={`randomWalk-${id}-${query.refId}`}
+            value={(query as any)[id as keyof TestDataQuery] || ''}
+            placeholder={placeholder}
+            onChange={onChange}
+          />
+        </InlineField>
+      ))}
     </InlineFieldRow>
   );
 };
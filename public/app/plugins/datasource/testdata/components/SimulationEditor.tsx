@@ -0,0 +1,80 @@
+import React, { ChangeEvent, FocusEvent } from 'react';
+import { InlineField, InlineFieldRow, InlineSwitch, Input, Select, TextArea } from '@grafana/ui';
+import { SelectableValue } from '@grafana/data';
+import { EditorProps } from '../QueryEditor';
+import { SimulationQuery } from '../types';
+
+const simulations: Array<SelectableValue<SimulationQuery['type']>> = [
+  { value: 'tank', label: 'Tank', description: 'Level of a tank with a drain valve' },
+  { value: 'server', label: 'HTTP server', description: 'Rate, errors and durations of the requests' },
+  { value: 'cpu', label: 'CPU', description: 'Usage and load with load spikes' },
+];
+
+const configPlaceholders: Record<SimulationQuery['type'], string> = {
+  tank: '{"capacity": 1000, "inflow": 5, "outflow": 12, "high": 0.9, "low": 0.1}',
+  server: '{"requests": 100, "errorRate": 0.01, "latency": 120, "incidentProbability": 0.001}',
+  cpu: '{"cores": 4, "usage": 25, "spikeProbability": 0.01, "spikeDuration": 60}',
+};
+
+export const SimulationEditor = ({ onChange, query }: EditorProps) => {
+  const sim: SimulationQuery = { type: 'tank', ...query.sim };
+
+  const onSimChange = (options: Partial<SimulationQuery>) => {
+    onChange({ ...query, sim: { ...sim, ...options } });
+  };
+
+  const onTextChange = (name: 'key' | 'tick') => (e: FocusEvent<HTMLInputElement>) => {
+    onSimChange({ [name]: e.currentTarget.value || undefined });
+  };
+
+  const onConfigChange = (e: FocusEvent<HTMLTextAreaElement>) => {
+    const text = e.currentTarget.value.trim();
+    if (!text) {
+      onSimChange({ config: undefined });
+      return;
+    }
+    try {
+      onSimChange({ config: JSON.parse(text) });
+    } catch {
+      // keep the last valid config
+    }
+  };
+
+  return (
+    <>
+      <InlineFieldRow>
+        <InlineField label="Simulation" labelWidth={14}>
+          <Select
+            width={32}
+            options={simulations}
+            value={simulations.find((s) => s.value === sim.type)}
+            onChange={({ value }) => onSimChange({ type: value!, config: undefined })}
+          />
+        </InlineField>
+        <InlineField label="Key" labelWidth={14} tooltip="The queries and streams with the same key share the state">
+          <Input width={32} placeholder="default" defaultValue={sim.key} onBlur={onTextChange('key')} />
+        </InlineField>
+        <InlineField label="Tick" labelWidth={14} tooltip="Interval between the steps of the simulation">
+          <Input width={32} placeholder="10s" defaultValue={sim.tick} onBlur={onTextChange('tick')} />
+        </InlineField>
+        <InlineField label="Stream" labelWidth={14}>
+          <InlineSwitch
+            value={sim.stream ?? false}
+            onChange={(e: ChangeEvent<HTMLInputElement>) => onSimChange({ stream: e.target.checked })}
+          />
+        </InlineField>
+      </InlineFieldRow>
+      <InlineFieldRow>
+        <InlineField label="Config" labelWidth={14} tooltip="JSON options of the simulation" grow>
+          <TextArea
+            key={sim.type}
+            rows={2}
+            placeholder={configPlaceholders[sim.type]}
+            defaultValue={sim.config ? JSON.stringify(sim.config) : undefined}
+            onBlur={onConfigChange}
+          />
+        </InlineField>
+      </InlineFieldRow>
+    </>
+  );
+};
@@ -13,7 +13,7 @@ import {
   LoadingState,
   TimeRange,
 } from '@grafana/data';
-import { Scenario, TestDataQuery } from './types';
+import { CSVDirectory, CSVUploadResult, Scenario, TestDataQuery } from './types';
 import { DataSourceWithBackend, getBackendSrv, getGrafanaLiveSrv, getTemplateSrv, TemplateSrv } from '@grafana/runtime';
 import { queryMetricTree } from './metricTree';
 import { runStream } from './runStreams';
@@ -135,13 +135,6 @@ export class TestDataDataSource extends DataSourceWithBackend<TestDataQuery> {
     return query.scenarioId;
   }
 
-  testDatasource() {
-    return Promise.resolve({
-      status: 'success',
-      message: 'Data source is working',
-    });
-  }
-
   getScenarios(): Promise<Scenario[]> {
     if (!this.scenariosCache) {
       this.scenariosCache = this.getResource('scenarios');
@@ -150,6 +143,16 @@ export class TestDataDataSource extends DataSourceWithBackend<TestDataQuery> {
     return this.scenariosCache;
   }
 
+  getCsvFiles(): Promise<CSVDirectory[]> {
+    return this.getResource('csv-files');
+  }
+
+  uploadCsvFile(file: File): Promise<CSVUploadResult> {
+    const data = new FormData();
+    data.append('file', file);
+    return getBackendSrv().post(`/api/datasources/${this.id}/resources/csv`, data);
+  }
+
   variablesQuery(target: TestDataQuery, options: DataQueryRequest<TestDataQuery>): Observable<DataQueryResponse> {
     const query = target.stringInput ?? '';
     const interpolatedQuery = this.templateSrv.replace(
@@ -4,6 +4,8 @@ export interface Scenario {
   id: string;
   name: string;
   stringInput: string;
+  description?: string;
+  defaultQuery?: Partial<TestDataQuery> | null;
 }
 
 export interface TestDataQuery extends DataQuery {
@@ -19,7 +21,36 @@ export interface TestDataQuery extends DataQuery {
   channel?: string; // for grafana live
   nodes?: NodesQuery;
   csvFileName?: string;
+  csvUrl?: string;
   csvContent?: string;
+  csvOptions?: CSVOptions;
+  csvStream?: CSVStreamQuery;
+  jsonFileName?: string;
+  parquetFileName?: string;
+  chaos?: ChaosQuery;
+  sim?: SimulationQuery;
+  seed?: number; // returns the same random values for the same time range
+}
+
+export interface SimulationQuery {
+  type: 'tank' | 'server' | 'cpu';
+  key?: string; // the queries and streams with the same key share the state, default by default
+  tick?: string; // interval between the steps, 10s by default
+  stream?: boolean;
+  config?: Record<string, number>;
+}
+
+export interface ChaosQuery {
+  failure?: 'none' | 'error' | 'partial' | 'oversized';
+  probability?: number; // of the failure, 1 by default
+  latency?: string; // delay of the response, e.g. 500ms
+  jitter?: string; // maximum random delay added to the latency
+  rows?: number; // of the oversized frame
+}
+
+export interface TestDataJsonData {
+  healthStatus?: 'ok' | 'error' | 'fail' | 'unavailable';
+  healthLatency?: string; // delay of the health check, e.g. 2s
 }
 
 export interface NodesQuery {
@@ -27,6 +58,29 @@ export interface NodesQuery {
   count?: number;
 }
 
+export interface CSVDirectory {
+  name: string;
+  files: string[]; // the names the scenarios read the files with
+}
+
+export interface CSVUploadResult {
+  fileName: string; // the name the scenarios read the file with
+  fields: string[];
+}
+
+export interface CSVOptions {
+  delimiter?: string; // a comma by default, \t for tabs
+  decimalComma?: boolean;
+  nullValues?: string[]; // parsed as nulls besides the empty values, null by default
+  timezone?: string; // of the times without one, UTC by default
+}
+
+export interface CSVStreamQuery {
+  speed: number; // replay speed, 2 replays the rows twice as fast as their times
+  loop: boolean;
+  timeShift: boolean; // shift the times of the rows to the times they're replayed at
+}
+
 export interface StreamingQuery {
   type: 'signal' | 'logs' | 'fetch';
   speed: number;
@@ -12,6 +12,7 @@ export interface DashboardAclDTO {
   userEmail?: string;
   teamId?: number;
   team?: string;
+  externalGroup?: string;
   permission?: PermissionLevel;
   role?: OrgRole;
   icon?: string;
@@ -21,6 +22,7 @@ export interface DashboardAclDTO {
 export interface DashboardAclUpdateDTO {
   userId?: number;
   teamId?: number;
+  externalGroup?: string;
   role?: OrgRole;
   permission?: PermissionLevel;
 }
@@ -33,6 +35,7 @@ export interface DashboardAcl {
   userEmail?: string;
   teamId?: number;
   team?: string;
+  externalGroup?: string;
   permission?: PermissionLevel;
   role?: OrgRole;
   icon?: string;
@@ -4,4 +4,6 @@ export interface UserPreferencesDTO {
   timezone: TimeZone;
   homeDashboardId: number;
   theme: string;
+  weekStart?: string;
+  fiscalYearStartMonth?: number;
 }